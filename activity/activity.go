@@ -0,0 +1,131 @@
+// Package activity records what users do — runs executed, procedures
+// edited, issues filed — as a feed for profile pages and standup summaries.
+package activity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidUserID is returned when user_id is not set.
+	ErrInvalidUserID = errors.New("user_id is required")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidAction is returned when an action is not supported.
+	ErrInvalidAction = errors.New("action must be run_started, run_completed, procedure_created, procedure_edited or issue_filed")
+)
+
+// Action is what a user did.
+type Action string
+
+const (
+	// ActionRunStarted is a user starting a test run.
+	ActionRunStarted Action = "run_started"
+
+	// ActionRunCompleted is a user completing a test run.
+	ActionRunCompleted Action = "run_completed"
+
+	// ActionProcedureCreated is a user creating a test procedure.
+	ActionProcedureCreated Action = "procedure_created"
+
+	// ActionProcedureEdited is a user committing a new version of a test
+	// procedure. Draft edits are not recorded.
+	ActionProcedureEdited Action = "procedure_edited"
+
+	// ActionIssueFiled is a user filing an issue from a test run.
+	ActionIssueFiled Action = "issue_filed"
+)
+
+// IsValid checks if the action is supported.
+func (a Action) IsValid() bool {
+	switch a {
+	case ActionRunStarted, ActionRunCompleted, ActionProcedureCreated, ActionProcedureEdited, ActionIssueFiled:
+		return true
+	}
+	return false
+}
+
+// Event is one action of a user in a project. SubjectID is the run or
+// procedure acted on; issues are filed from a run, which is their subject.
+type Event struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index:idx_activity_events_user_created,priority:1"`
+	Action    Action    `json:"action" gorm:"type:varchar(30);not null"`
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:char(36);not null;index:idx_activity_events_project_id"`
+	SubjectID uuid.UUID `json:"subject_id" gorm:"type:char(36);not null"`
+	Summary   string    `json:"summary" gorm:"type:varchar(500);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_activity_events_user_created,priority:2"`
+}
+
+// TableName specifies the table name for GORM.
+func (e *Event) TableName() string {
+	return "activity_events"
+}
+
+// BeforeCreate hook to generate UUID before creating a new event
+func (e *Event) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the event has valid required fields.
+func (e *Event) Validate() error {
+	if e.UserID == uuid.Nil {
+		return ErrInvalidUserID
+	}
+	if e.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if !e.Action.IsValid() {
+		return ErrInvalidAction
+	}
+	return nil
+}
+
+// RunStartedEvent is a user starting a run of a procedure.
+func RunStartedEvent(userID, projectID, runID uuid.UUID, procedureName string) *Event {
+	return newEvent(userID, projectID, runID, ActionRunStarted, fmt.Sprintf("Started a run of %s", procedureName))
+}
+
+// RunCompletedEvent is a user completing a run of a procedure with status.
+func RunCompletedEvent(userID, projectID, runID uuid.UUID, procedureName, status string) *Event {
+	return newEvent(userID, projectID, runID, ActionRunCompleted, fmt.Sprintf("Completed a run of %s: %s", procedureName, status))
+}
+
+// ProcedureCreatedEvent is a user creating a procedure.
+func ProcedureCreatedEvent(userID, projectID, procedureID uuid.UUID, name string) *Event {
+	return newEvent(userID, projectID, procedureID, ActionProcedureCreated, fmt.Sprintf("Created %s", name))
+}
+
+// ProcedureEditedEvent is a user committing version of a procedure.
+func ProcedureEditedEvent(userID, projectID, procedureID uuid.UUID, name string, version uint) *Event {
+	return newEvent(userID, projectID, procedureID, ActionProcedureEdited, fmt.Sprintf("Edited %s (version %d)", name, version))
+}
+
+// IssueFiledEvent is a user filing an issue from a run.
+func IssueFiledEvent(userID, projectID, runID uuid.UUID, title string) *Event {
+	return newEvent(userID, projectID, runID, ActionIssueFiled, fmt.Sprintf("Filed %s", title))
+}
+
+func newEvent(userID, projectID, subjectID uuid.UUID, action Action, summary string) *Event {
+	// Summaries are bounded by their column
+	if runes := []rune(summary); len(runes) > 500 {
+		summary = string(runes[:500])
+	}
+	return &Event{
+		UserID:    userID,
+		Action:    action,
+		ProjectID: projectID,
+		SubjectID: subjectID,
+		Summary:   summary,
+	}
+}
@@ -0,0 +1,105 @@
+package activity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvent_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   *Event
+		wantErr error
+	}{
+		{
+			name:  "valid event",
+			event: RunStartedEvent(uuid.New(), uuid.New(), uuid.New(), "Checkout"),
+		},
+		{
+			name:    "missing user",
+			event:   RunStartedEvent(uuid.Nil, uuid.New(), uuid.New(), "Checkout"),
+			wantErr: ErrInvalidUserID,
+		},
+		{
+			name:    "missing project",
+			event:   RunStartedEvent(uuid.New(), uuid.Nil, uuid.New(), "Checkout"),
+			wantErr: ErrInvalidProjectID,
+		},
+		{
+			name:    "invalid action",
+			event:   &Event{UserID: uuid.New(), ProjectID: uuid.New(), Action: "deleted"},
+			wantErr: ErrInvalidAction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.event.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEventConstructors(t *testing.T) {
+	userID, projectID, subjectID := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name        string
+		event       *Event
+		wantAction  Action
+		wantSummary string
+	}{
+		{
+			name:        "run started",
+			event:       RunStartedEvent(userID, projectID, subjectID, "Checkout"),
+			wantAction:  ActionRunStarted,
+			wantSummary: "Started a run of Checkout",
+		},
+		{
+			name:        "run completed",
+			event:       RunCompletedEvent(userID, projectID, subjectID, "Checkout", "failed"),
+			wantAction:  ActionRunCompleted,
+			wantSummary: "Completed a run of Checkout: failed",
+		},
+		{
+			name:        "procedure created",
+			event:       ProcedureCreatedEvent(userID, projectID, subjectID, "Checkout"),
+			wantAction:  ActionProcedureCreated,
+			wantSummary: "Created Checkout",
+		},
+		{
+			name:        "procedure edited",
+			event:       ProcedureEditedEvent(userID, projectID, subjectID, "Checkout", 3),
+			wantAction:  ActionProcedureEdited,
+			wantSummary: "Edited Checkout (version 3)",
+		},
+		{
+			name:        "issue filed",
+			event:       IssueFiledEvent(userID, projectID, subjectID, "Card declined"),
+			wantAction:  ActionIssueFiled,
+			wantSummary: "Filed Card declined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantAction, tt.event.Action)
+			assert.Equal(t, tt.wantSummary, tt.event.Summary)
+			assert.Equal(t, userID, tt.event.UserID)
+			assert.Equal(t, projectID, tt.event.ProjectID)
+			assert.Equal(t, subjectID, tt.event.SubjectID)
+		})
+	}
+
+	t.Run("long summaries are truncated", func(t *testing.T) {
+		event := IssueFiledEvent(userID, projectID, subjectID, strings.Repeat("é", 600))
+		assert.Len(t, []rune(event.Summary), 500)
+	})
+}
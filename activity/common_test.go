@@ -0,0 +1,30 @@
+package activity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and activity store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Event{}, &project.Project{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestProject creates a project owned by ownerID and returns its ID.
+func createTestProject(t *testing.T, db *gorm.DB, ownerID uuid.UUID) uuid.UUID {
+	p := &project.Project{Name: "Project", OwnerID: ownerID, IsActive: true}
+	require.NoError(t, db.Create(p).Error)
+	return p.ID
+}
@@ -0,0 +1,116 @@
+package activity
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed activity store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Record creates a new event.
+func (s *MySQLStore) Record(ctx context.Context, event *Event) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(event).Error; err != nil {
+		s.logger.Error(ctx, "failed to record activity event", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": event.UserID.String(),
+			"action":  string(event.Action),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// ListByUser retrieves a paginated list of a user's events, newest first.
+func (s *MySQLStore) ListByUser(ctx context.Context, userID uuid.UUID, filter Filter, limit, offset int) ([]*Event, error) {
+	var events []*Event
+	err := s.scope(ctx, userID, filter).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list activity events", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ListByUserAfter retrieves up to limit of a user's events, newest first,
+// after the cursor.
+func (s *MySQLStore) ListByUserAfter(ctx context.Context, userID uuid.UUID, filter Filter, after *database.Cursor, limit int) ([]*Event, error) {
+	var events []*Event
+	err := s.scope(ctx, userID, filter).
+		Scopes(database.After(after)).
+		Limit(limit).
+		Find(&events).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list activity events after cursor", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// CountByUser returns the total count of a user's events.
+func (s *MySQLStore) CountByUser(ctx context.Context, userID uuid.UUID, filter Filter) (int, error) {
+	var count int64
+	err := s.scope(ctx, userID, filter).Count(&count).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to count activity events", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// scope selects the events of a user matching filter.
+func (s *MySQLStore) scope(ctx context.Context, userID uuid.UUID, filter Filter) *gorm.DB {
+	db := s.db.WithContext(ctx)
+	q := db.Model(&Event{}).Where("user_id = ?", userID)
+	if filter.Since != nil {
+		q = q.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if filter.ProjectOwnerID != nil {
+		owned := db.Model(&project.Project{}).Select("id").Where("owner_id = ?", *filter.ProjectOwnerID)
+		q = q.Where("project_id IN (?)", owned)
+	}
+	return q
+}
@@ -0,0 +1,98 @@
+package activity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Record(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("records a valid event", func(t *testing.T) {
+		event := RunStartedEvent(uuid.New(), uuid.New(), uuid.New(), "Checkout")
+		require.NoError(t, store.Record(ctx, event))
+		assert.NotEqual(t, uuid.Nil, event.ID)
+	})
+
+	t.Run("rejects an invalid event", func(t *testing.T) {
+		err := store.Record(ctx, RunStartedEvent(uuid.Nil, uuid.New(), uuid.New(), "Checkout"))
+		assert.ErrorIs(t, err, ErrInvalidUserID)
+	})
+}
+
+func TestMySQLStore_ListByUser(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	viewerID := uuid.New()
+	ownProject := createTestProject(t, db, userID)
+	viewerProject := createTestProject(t, db, viewerID)
+
+	now := time.Now()
+	events := []*Event{
+		ProcedureCreatedEvent(userID, ownProject, uuid.New(), "Login"),
+		RunStartedEvent(userID, viewerProject, uuid.New(), "Checkout"),
+		RunCompletedEvent(userID, viewerProject, uuid.New(), "Checkout", "passed"),
+	}
+	for i, e := range events {
+		e.CreatedAt = now.Add(time.Duration(i-len(events)) * time.Hour)
+		require.NoError(t, store.Record(ctx, e))
+	}
+	require.NoError(t, store.Record(ctx, RunStartedEvent(uuid.New(), ownProject, uuid.New(), "Login")))
+
+	t.Run("lists the user's events newest first", func(t *testing.T) {
+		got, err := store.ListByUser(ctx, userID, Filter{}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, events[2].ID, got[0].ID)
+		assert.Equal(t, events[0].ID, got[2].ID)
+
+		count, err := store.CountByUser(ctx, userID, Filter{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("paginates", func(t *testing.T) {
+		got, err := store.ListByUser(ctx, userID, Filter{}, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, events[1].ID, got[0].ID)
+	})
+
+	t.Run("filters by time", func(t *testing.T) {
+		since := now.Add(-90 * time.Minute)
+		got, err := store.ListByUser(ctx, userID, Filter{Since: &since}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, events[2].ID, got[0].ID)
+	})
+
+	t.Run("filters by action", func(t *testing.T) {
+		filter := Filter{Action: ActionProcedureCreated}
+		got, err := store.ListByUser(ctx, userID, filter, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, events[0].ID, got[0].ID)
+	})
+
+	t.Run("filters by project owner", func(t *testing.T) {
+		filter := Filter{ProjectOwnerID: &viewerID}
+		got, err := store.ListByUser(ctx, userID, filter, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		for _, e := range got {
+			assert.Equal(t, viewerProject, e.ProjectID)
+		}
+
+		count, err := store.CountByUser(ctx, userID, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
@@ -0,0 +1,42 @@
+package activity
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// Recorder records events as users act. Recording is best-effort: failures
+// are logged and never surface to the caller, so an activity outage cannot
+// block the action itself. A nil Recorder records nothing.
+type Recorder struct {
+	store  Store
+	logger logger.Logger
+}
+
+// NewRecorder creates a new activity recorder.
+func NewRecorder(store Store, log logger.Logger) *Recorder {
+	return &Recorder{
+		store:  store,
+		logger: log,
+	}
+}
+
+// Record records an event.
+func (r *Recorder) Record(ctx context.Context, event *Event) {
+	if r == nil {
+		return
+	}
+
+	// The action has already happened; it must still be recorded when the
+	// request that did it has been cancelled.
+	ctx = context.WithoutCancel(ctx)
+
+	if err := r.store.Record(ctx, event); err != nil {
+		r.logger.Warn(ctx, "failed to record activity", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": event.UserID.String(),
+			"action":  string(event.Action),
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package activity
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+)
+
+// Filter narrows the events of a user. Zero fields do not filter.
+type Filter struct {
+	// Since only keeps events at or after the time.
+	Since *time.Time
+
+	// Action only keeps events of the action.
+	Action Action
+
+	// ProjectOwnerID only keeps events in projects owned by the user, so
+	// that others see only what a user did in their projects.
+	ProjectOwnerID *uuid.UUID
+}
+
+// Store defines the interface for activity persistence operations.
+type Store interface {
+	// Record creates a new event.
+	Record(ctx context.Context, event *Event) error
+
+	// ListByUser retrieves a paginated list of a user's events, newest
+	// first.
+	ListByUser(ctx context.Context, userID uuid.UUID, filter Filter, limit, offset int) ([]*Event, error)
+
+	// ListByUserAfter retrieves up to limit of a user's events, newest
+	// first, after the cursor.
+	ListByUserAfter(ctx context.Context, userID uuid.UUID, filter Filter, after *database.Cursor, limit int) ([]*Event, error)
+
+	// CountByUser returns the total count of a user's events.
+	CountByUser(ctx context.Context, userID uuid.UUID, filter Filter) (int, error)
+}
@@ -13,10 +13,18 @@ Output: JSON result at {output_dir}/result.json
 
 import json
 import os
+import re
 import sys
 
 import anyio
-from claude_agent_sdk import query, ClaudeAgentOptions, AssistantMessage, TextBlock
+from claude_agent_sdk import (
+    query,
+    ClaudeAgentOptions,
+    AssistantMessage,
+    ResultMessage,
+    TextBlock,
+    ToolUseBlock,
+)
 
 
 COORDINATOR_SYSTEM_PROMPT = """You are a UI exploration coordinator agent. Your job is to explore a web application and create a structured test procedure document.
@@ -35,6 +43,9 @@ Analyze the target URL and plan your exploration strategy. Consider:
 - What interactions to test (forms, buttons, navigation)
 - Whether credentials are needed and when to use them
 
+End your plan with a line of the form `PLANNED_PAGES: <n>`, where <n> is the
+number of distinct pages you expect to visit. It is used to report progress.
+
 ## Phase 2: Exploration
 Navigate the web application using Playwright browser tools:
 1. Use `browser_navigate` to go to pages
@@ -62,7 +73,27 @@ The JSON format MUST be:
       "image_paths": ["screenshots/<filename>.png"]
     }}
   ],
-  "summary": "<overall summary of the exploration>"
+  "summary": "<overall summary of the exploration>",
+  "pages": [
+    {{
+      "url": "<page URL>",
+      "title": "<page title>",
+      "description": "<what the page is for>"
+    }}
+  ],
+  "flows": [
+    {{
+      "name": "<short flow name, e.g. Log in>",
+      "description": "<what the flow accomplishes>",
+      "steps": [
+        {{
+          "name": "<short step name>",
+          "instructions": "<detailed instructions for this step>",
+          "image_paths": ["screenshots/<filename>.png"]
+        }}
+      ]
+    }}
+  ]
 }}
 
 IMPORTANT:
@@ -71,12 +102,74 @@ IMPORTANT:
 - Each step should have clear, actionable instructions a manual tester can follow
 - Group related interactions into logical steps
 - Include verification points (what the tester should observe after each action)
+- List every distinct page you visited in "pages"
+- Split the exploration into independent user flows in "flows" (e.g. "Log in", "Search products"); each flow must be testable on its own
 """
 
+PLANNED_PAGES_PATTERN = re.compile(r"PLANNED_PAGES:\s*(\d+)")
+
+
+class Progress:
+    """Tracks pages explored against the planned number of pages and prints
+    "[progress] <explored>/<planned> pages" lines, which the backend records
+    on the job, whenever either changes."""
+
+    def __init__(self) -> None:
+        self.visited: set = set()
+        self.planned = 0
+
+    def on_text(self, text: str) -> None:
+        match = PLANNED_PAGES_PATTERN.search(text)
+        if match and int(match.group(1)) != self.planned:
+            self.planned = int(match.group(1))
+            self.report()
+
+    def on_tool(self, block: ToolUseBlock) -> None:
+        if not block.name.endswith("browser_navigate"):
+            return
+        url = (block.input or {}).get("url")
+        if url and url not in self.visited:
+            self.visited.add(url)
+            self.report()
+
+    def report(self) -> None:
+        print(
+            f"[progress] {len(self.visited)}/{self.planned} pages",
+            file=sys.stderr,
+            flush=True,
+        )
+
+
+def usage_of(message: ResultMessage) -> dict:
+    """Returns the tokens and cost of the agent's model calls, which the
+    backend meters against the LLM budgets of the project."""
+    usage = message.usage or {}
+    return {
+        "input_tokens": usage.get("input_tokens", 0)
+        + usage.get("cache_creation_input_tokens", 0)
+        + usage.get("cache_read_input_tokens", 0),
+        "output_tokens": usage.get("output_tokens", 0),
+        "cost_usd": message.total_cost_usd or 0,
+    }
+
+
+def add_usage(result_path: str, usage: dict) -> None:
+    """Adds the agent's usage to result.json. A malformed result is left as
+    it is for the backend to reject."""
+    try:
+        with open(result_path) as f:
+            result = json.load(f)
+    except json.JSONDecodeError:
+        return
+    result["usage"] = usage
+    with open(result_path, "w") as f:
+        json.dump(result, f, indent=2)
+
 
 async def run_agent(config: dict) -> None:
     target_url = config["target_url"]
     credentials = config.get("credentials", [])
+    secrets = config.get("secrets", [])
     procedure_name = config.get("procedure_name", "UI Exploration")
     output_dir = config["output_dir"]
     playwright_mcp_url = config.get(
@@ -91,6 +184,13 @@ async def run_agent(config: dict) -> None:
     if credentials:
         cred_lines = [f"  - {c['key']}: {c['value']}" for c in credentials]
         cred_text = "\n\nAvailable credentials:\n" + "\n".join(cred_lines)
+    if secrets:
+        secret_lines = [f"  - {s['key']}: {s['value']}" for s in secrets]
+        cred_text += (
+            "\n\nEnvironment secrets (use the values while exploring, but write "
+            "the placeholder {{KEY}} instead of the value in any step you document):\n"
+            + "\n".join(secret_lines)
+        )
 
     prompt = (
         f'Explore the web application at {target_url} and create a test procedure '
@@ -118,11 +218,21 @@ async def run_agent(config: dict) -> None:
     )
 
     final_text = ""
+    usage = {}
+    progress = Progress()
     async for message in query(prompt=prompt, options=options):
         if isinstance(message, AssistantMessage):
             for block in message.content:
+                # Progress goes to stderr, which the backend stores as the job log.
                 if isinstance(block, TextBlock):
                     final_text = block.text
+                    print(block.text, file=sys.stderr, flush=True)
+                    progress.on_text(block.text)
+                elif isinstance(block, ToolUseBlock):
+                    print(f"[tool] {block.name}", file=sys.stderr, flush=True)
+                    progress.on_tool(block)
+        elif isinstance(message, ResultMessage):
+            usage = usage_of(message)
 
     # Verify result.json was created by the agent
     result_path = os.path.join(output_dir, "result.json")
@@ -145,6 +255,8 @@ async def run_agent(config: dict) -> None:
         with open(result_path, "w") as f:
             json.dump(fallback, f, indent=2)
 
+    add_usage(result_path, usage)
+
 
 def main() -> None:
     # Read config from stdin
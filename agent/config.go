@@ -15,4 +15,11 @@ type Config struct {
 	PlaywrightMCPURL    string
 	AgentScriptPath     string
 	MaxConcurrentWorkers int
+	// HeartbeatInterval is how often running jobs report that they are
+	// alive (defaults to DefaultHeartbeatInterval).
+	HeartbeatInterval time.Duration
 }
+
+// DefaultHeartbeatInterval is how often running jobs send heartbeats when
+// no interval is configured.
+const DefaultHeartbeatInterval = 30 * time.Second
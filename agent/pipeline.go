@@ -1,53 +1,95 @@
 package agent
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/exploration"
 	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/hairizuanbinnoorazman/ui-automation/shutdown"
 	"github.com/hairizuanbinnoorazman/ui-automation/storage"
 	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
 )
 
+// JobRunner executes jobs of a type other than ui_exploration. The returned
+// result is stored on the job when it succeeds; an error fails the job.
+type JobRunner interface {
+	Run(ctx context.Context, j *job.Job) (job.JSONMap, error)
+}
+
 // Pipeline orchestrates UI exploration by spawning a Python agent subprocess.
+// Other job types are delegated to the JobRunner registered for them, sharing
+// the pipeline's time limit, cancellation and metering.
 type Pipeline struct {
 	config             Config
 	jobStore           job.Store
+	logStore           job.LogStore
 	endpointStore      endpoint.Store
+	secretStore        endpoint.SecretStore
 	testProcedureStore testprocedure.Store
 	storage            storage.BlobStorage
+	mcpBreaker         *resilience.Breaker
+	recorder           *metering.Recorder
+	meter              *llmusage.Meter
+	notifier           *notification.Notifier
 	logger             logger.Logger
 	cancelFuncs        sync.Map // map[uuid.UUID]context.CancelFunc
+	runners            map[job.JobType]JobRunner
 }
 
 // NewPipeline creates a new agent pipeline.
 func NewPipeline(
 	config Config,
 	jobStore job.Store,
+	logStore job.LogStore,
 	endpointStore endpoint.Store,
+	secretStore endpoint.SecretStore,
 	testProcedureStore testprocedure.Store,
 	blobStorage storage.BlobStorage,
+	mcpBreaker *resilience.Breaker,
+	recorder *metering.Recorder,
+	meter *llmusage.Meter,
+	notifier *notification.Notifier,
 	log logger.Logger,
 ) *Pipeline {
 	return &Pipeline{
 		config:             config,
 		jobStore:           jobStore,
+		logStore:           logStore,
 		endpointStore:      endpointStore,
+		secretStore:        secretStore,
 		testProcedureStore: testProcedureStore,
 		storage:            blobStorage,
+		mcpBreaker:         mcpBreaker,
+		recorder:           recorder,
+		meter:              meter,
+		notifier:           notifier,
 		logger:             log,
+		runners:            make(map[job.JobType]JobRunner),
 	}
 }
 
+// RegisterRunner sets the runner for a job type. It must be called before the
+// worker pool starts.
+func (p *Pipeline) RegisterRunner(jobType job.JobType, runner JobRunner) {
+	p.runners[jobType] = runner
+}
+
 // Run executes the full exploration pipeline for a given job.
 // It marks the job as running before executing.
 func (p *Pipeline) Run(ctx context.Context, jobID uuid.UUID) {
@@ -71,6 +113,9 @@ func (p *Pipeline) run(ctx context.Context, jobID uuid.UUID, needsStart bool) {
 	p.cancelFuncs.Store(jobID, cancel)
 	defer p.cancelFuncs.Delete(jobID)
 
+	stopHeartbeat := p.startHeartbeat(ctx, jobID)
+	defer stopHeartbeat()
+
 	// 1. Fetch job and parse config
 	j, err := p.jobStore.GetByID(ctx, jobID)
 	if err != nil {
@@ -78,28 +123,114 @@ func (p *Pipeline) run(ctx context.Context, jobID uuid.UUID, needsStart bool) {
 		return
 	}
 
-	endpointIDStr, ok := j.Config["endpoint_id"].(string)
-	if !ok {
-		p.failJob(ctx, jobID, "missing endpoint_id in job config")
+	if runner, ok := p.runners[j.Type]; ok {
+		p.runWithRunner(ctx, j, runner, needsStart)
 		return
 	}
-	endpointID, err := uuid.Parse(endpointIDStr)
+
+	target, err := p.prepareExploration(ctx, j)
 	if err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("invalid endpoint_id: %v", err))
+		p.failJob(ctx, jobID, err.Error())
 		return
 	}
 
-	projectIDStr, ok := j.Config["project_id"].(string)
-	if !ok {
-		p.failJob(ctx, jobID, "missing project_id in job config")
+	// 3. Mark job as running (skip if already claimed)
+	if needsStart {
+		if err := p.jobStore.Start(ctx, jobID); err != nil {
+			p.failJob(ctx, jobID, fmt.Sprintf("failed to start job: %v", err))
+			return
+		}
+	}
+	p.jobLog(ctx, jobID, "Exploring %s", target.endpoint.URL)
+
+	// Agent time is billed however the job ends, including failures and stops.
+	startedAt := time.Now()
+	defer func() {
+		p.recorder.RecordJobDuration(ctx, target.projectID, j.CreatedBy, time.Since(startedAt))
+	}()
+
+	// 4. Create temp directory for this job
+	tmpDir := filepath.Join(os.TempDir(), fmt.Sprintf("agent-job-%s", jobID.String()))
+	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
+		p.failJob(ctx, jobID, fmt.Sprintf("failed to create temp directory: %v", err))
 		return
 	}
-	projectID, err := uuid.Parse(projectIDStr)
+	defer os.RemoveAll(tmpDir)
+
+	// 5. Complete the agent config
+	agentCfg := target.config
+	agentCfg.OutputDir = tmpDir
+	agentCfg.PlaywrightMCPURL = p.config.PlaywrightMCPURL + "/sse"
+
+	// 6. Make sure the Playwright MCP server is reachable before spawning the agent
+	if err := p.checkPlaywrightMCP(ctx); err != nil {
+		p.failJob(ctx, jobID, fmt.Sprintf("playwright MCP unavailable: %v", err))
+		return
+	}
+
+	// 7. Spawn Python agent subprocess; its stderr is the job's progress log,
+	// and its "[progress]" lines update the job's progress
+	p.jobLog(ctx, jobID, "Starting exploration agent")
+	p.logger.Info(ctx, "spawning agent subprocess", map[string]interface{}{
+		"job_id":      jobID.String(),
+		"script_path": p.config.AgentScriptPath,
+		"target_url":  target.endpoint.URL,
+	})
+
+	process := Process{
+		ScriptPath:       p.config.AgentScriptPath,
+		BedrockRegion:    p.config.BedrockRegion,
+		BedrockAccessKey: p.config.BedrockAccessKey,
+		BedrockSecretKey: p.config.BedrockSecretKey,
+	}
+	logWriter := job.NewLogWriter(ctx, p.logStore, jobID, p.logger)
+	err = process.Run(ctx, agentCfg, io.MultiWriter(logWriter, NewProgressWriter(ctx, p.jobStore, jobID, p.logger)))
+	logWriter.Close()
+	if err != nil {
+		p.failJob(ctx, jobID, err.Error())
+		return
+	}
+	p.jobLog(ctx, jobID, "Agent finished, saving results")
+
+	// 8. Read result from output file
+	agentResult, err := ReadResult(tmpDir)
 	if err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("invalid project_id: %v", err))
+		p.failJob(ctx, jobID, err.Error())
 		return
 	}
 
+	// 9-11. Save the procedure and mark the job as succeeded
+	p.saveExploration(ctx, j, target.projectID, tmpDir, agentResult)
+}
+
+// explorationTarget is what a ui_exploration job explores, resolved from
+// its config.
+type explorationTarget struct {
+	projectID uuid.UUID
+	endpoint  *endpoint.Endpoint
+	// config lacks the output directory and Playwright MCP URL, which
+	// depend on where the agent runs.
+	config AgentConfig
+}
+
+// prepareExploration resolves the endpoint and project of a ui_exploration
+// job and builds the agent config, including the endpoint's decrypted
+// secrets.
+func (p *Pipeline) prepareExploration(ctx context.Context, j *job.Job) (*explorationTarget, error) {
+	endpointIDStr, ok := j.Config["endpoint_id"].(string)
+	if !ok {
+		return nil, errors.New("missing endpoint_id in job config")
+	}
+	endpointID, err := uuid.Parse(endpointIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint_id: %v", err)
+	}
+
+	projectID, err := jobProjectID(j)
+	if err != nil {
+		return nil, err
+	}
+
 	procedureName, _ := j.Config["procedure_name"].(string)
 	if procedureName == "" {
 		procedureName = "UI Exploration"
@@ -108,96 +239,195 @@ func (p *Pipeline) run(ctx context.Context, jobID uuid.UUID, needsStart bool) {
 	// 2. Fetch endpoint
 	ep, err := p.endpointStore.GetByID(ctx, endpointID)
 	if err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("failed to fetch endpoint: %v", err))
-		return
+		return nil, fmt.Errorf("failed to fetch endpoint: %v", err)
 	}
 
-	// 3. Mark job as running (skip if already claimed)
-	if needsStart {
-		if err := p.jobStore.Start(ctx, jobID); err != nil {
-			p.failJob(ctx, jobID, fmt.Sprintf("failed to start job: %v", err))
-			return
-		}
+	creds, secrets, err := EndpointCredentials(ctx, p.secretStore, ep)
+	if err != nil {
+		return nil, err
 	}
 
-	// 4. Create temp directory for this job
-	tmpDir := filepath.Join(os.TempDir(), fmt.Sprintf("agent-job-%s", jobID.String()))
-	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("failed to create temp directory: %v", err))
-		return
+	return &explorationTarget{
+		projectID: projectID,
+		endpoint:  ep,
+		config: AgentConfig{
+			TargetURL:     ep.URL,
+			Credentials:   creds,
+			Secrets:       secrets,
+			ProcedureName: procedureName,
+			JobID:         j.ID.String(),
+		},
+	}, nil
+}
+
+// EndpointCredentials returns the credentials of an endpoint and its
+// decrypted secrets, sorted by key, for an agent to log in with. Secrets are
+// decrypted only for the lifetime of a job and are never stored in the job
+// config.
+func EndpointCredentials(ctx context.Context, secretStore endpoint.SecretStore, ep *endpoint.Endpoint) (creds, secrets []Credential, err error) {
+	secretValues, err := secretStore.Values(ctx, ep.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load endpoint secrets: %v", err)
 	}
-	defer os.RemoveAll(tmpDir)
 
-	// 5. Build agent config
-	creds := make([]Credential, len(ep.Credentials))
+	creds = make([]Credential, len(ep.Credentials))
 	for i, c := range ep.Credentials {
 		creds[i] = Credential{Key: c.Key, Value: c.Value}
 	}
 
-	agentCfg := AgentConfig{
-		TargetURL:        ep.URL,
-		Credentials:      creds,
-		ProcedureName:    procedureName,
-		JobID:            jobID.String(),
-		OutputDir:        tmpDir,
-		PlaywrightMCPURL: p.config.PlaywrightMCPURL + "/sse",
+	secrets = make([]Credential, 0, len(secretValues))
+	for key, value := range secretValues {
+		secrets = append(secrets, Credential{Key: key, Value: value})
 	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Key < secrets[j].Key })
+	return creds, secrets, nil
+}
 
-	configJSON, err := json.Marshal(agentCfg)
+// jobProjectID returns the project a job saves its results to.
+func jobProjectID(j *job.Job) (uuid.UUID, error) {
+	projectIDStr, ok := j.Config["project_id"].(string)
+	if !ok {
+		return uuid.Nil, errors.New("missing project_id in job config")
+	}
+	projectID, err := uuid.Parse(projectIDStr)
 	if err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("failed to marshal agent config: %v", err))
-		return
+		return uuid.Nil, fmt.Errorf("invalid project_id: %v", err)
 	}
+	return projectID, nil
+}
 
-	// 6. Spawn Python agent subprocess
-	p.logger.Info(ctx, "spawning agent subprocess", map[string]interface{}{
-		"job_id":      jobID.String(),
-		"script_path": p.config.AgentScriptPath,
-		"target_url":  ep.URL,
-	})
-
-	cmd := exec.CommandContext(ctx, "python3", p.config.AgentScriptPath)
-	cmd.Stdin = bytes.NewReader(configJSON)
+// saveExploration uploads the screenshots of an agent result from dir,
+// saves the discovered procedure and marks the job as succeeded. The job
+// fails if the procedure cannot be saved.
+func (p *Pipeline) saveExploration(ctx context.Context, j *job.Job, projectID uuid.UUID, dir string, agentResult *AgentResult) {
+	p.meter.Record(ctx, projectID, j.CreatedBy, llmusage.FeatureUIExploration, agentResult.Usage)
+
+	// 9. Upload screenshots to storage and build test procedure steps
+	uploaded := make(map[string]string)
+	steps := p.uploadSteps(ctx, dir, projectID, agentResult.Steps, uploaded)
+
+	// Keep the discovered flows so the job can later be converted into
+	// one procedure per flow. Older agents only report a single step list.
+	flows := make([]exploration.Flow, 0, len(agentResult.Flows))
+	for _, flow := range agentResult.Flows {
+		flows = append(flows, exploration.Flow{
+			Name:        flow.Name,
+			Description: flow.Description,
+			Steps:       p.uploadSteps(ctx, dir, projectID, flow.Steps, uploaded),
+		})
+	}
+	if len(flows) == 0 && len(steps) > 0 {
+		flows = append(flows, exploration.Flow{
+			Name:        agentResult.ProcedureName,
+			Description: agentResult.Description,
+			Steps:       steps,
+		})
+	}
 
-	// Set environment variables for Bedrock auth
-	cmd.Env = append(os.Environ(),
-		"CLAUDE_CODE_USE_BEDROCK=1",
-		fmt.Sprintf("AWS_REGION=%s", p.config.BedrockRegion),
-	)
-	if p.config.BedrockAccessKey != "" {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", p.config.BedrockAccessKey))
+	pages := make([]exploration.Page, len(agentResult.Pages))
+	for i, page := range agentResult.Pages {
+		pages[i] = exploration.Page{URL: page.URL, Title: page.Title, Description: page.Description}
 	}
-	if p.config.BedrockSecretKey != "" {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", p.config.BedrockSecretKey))
+
+	// If no steps were generated, create a placeholder
+	if len(steps) == 0 {
+		steps = append(steps, testprocedure.TestStep{
+			Name:         "Initial observation",
+			Instructions: agentResult.Summary,
+			ImagePaths:   []string{},
+		})
 	}
 
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	// 10. Save procedure
+	tp := &testprocedure.TestProcedure{
+		ProjectID:   projectID,
+		Name:        agentResult.ProcedureName,
+		Description: "Auto-generated from UI exploration: " + agentResult.Description,
+		Steps:       steps,
+		CreatedBy:   j.CreatedBy,
+	}
 
-	if err := cmd.Run(); err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("agent subprocess failed: %v; stderr: %s", err, stderr.String()))
+	if err := p.testProcedureStore.Create(ctx, tp); err != nil {
+		p.failJob(ctx, j.ID, fmt.Sprintf("failed to save procedure: %v", err))
 		return
 	}
 
-	// 7. Read result from output file
-	resultPath := filepath.Join(tmpDir, "result.json")
-	resultData, err := os.ReadFile(resultPath)
+	// 11. Mark job success
+	if err := p.jobStore.Complete(ctx, j.ID, job.StatusSuccess, job.JSONMap{
+		"procedure_id":             tp.ID.String(),
+		"procedure_name":           tp.Name,
+		"steps_count":              len(tp.Steps),
+		exploration.ResultKeyPages: pages,
+		exploration.ResultKeyFlows: flows,
+	}); err != nil {
+		p.logger.Error(ctx, "failed to mark job as success", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": j.ID.String(),
+		})
+	}
+
+	p.jobLog(ctx, j.ID, "Created procedure %q with %d steps", tp.Name, len(tp.Steps))
+
+	p.logger.Info(ctx, "agent pipeline completed successfully", map[string]interface{}{
+		"job_id":       j.ID.String(),
+		"procedure_id": tp.ID.String(),
+	})
+}
+
+// runWithRunner executes a job through its registered runner.
+func (p *Pipeline) runWithRunner(ctx context.Context, j *job.Job, runner JobRunner, needsStart bool) {
+	if needsStart {
+		if err := p.jobStore.Start(ctx, j.ID); err != nil {
+			p.failJob(ctx, j.ID, fmt.Sprintf("failed to start job: %v", err))
+			return
+		}
+	}
+
+	if projectIDStr, ok := j.Config["project_id"].(string); ok {
+		if projectID, err := uuid.Parse(projectIDStr); err == nil {
+			startedAt := time.Now()
+			defer func() {
+				p.recorder.RecordJobDuration(ctx, projectID, j.CreatedBy, time.Since(startedAt))
+			}()
+		}
+	}
+
+	p.jobLog(ctx, j.ID, "Running %s job", j.Type)
+	result, err := runner.Run(ctx, j)
 	if err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("failed to read agent result: %v", err))
+		p.failJob(ctx, j.ID, err.Error())
 		return
 	}
 
-	var agentResult AgentResult
-	if err := json.Unmarshal(resultData, &agentResult); err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("failed to parse agent result: %v", err))
+	if err := p.jobStore.Complete(ctx, j.ID, job.StatusSuccess, result); err != nil {
+		p.logger.Error(ctx, "failed to mark job as success", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": j.ID.String(),
+		})
 		return
 	}
 
-	// 8. Upload screenshots to storage and build test procedure steps
-	steps := make(testprocedure.Steps, 0, len(agentResult.Steps))
-	for _, step := range agentResult.Steps {
+	p.jobLog(ctx, j.ID, "Job completed")
+
+	p.logger.Info(ctx, "job completed successfully", map[string]interface{}{
+		"job_id":   j.ID.String(),
+		"job_type": j.Type,
+	})
+}
+
+// uploadSteps uploads the screenshots referenced by the agent steps to blob
+// storage and returns the steps with stored image paths. Screenshots already
+// present in uploaded are reused rather than uploaded again.
+func (p *Pipeline) uploadSteps(ctx context.Context, tmpDir string, projectID uuid.UUID, agentSteps []AgentStep, uploaded map[string]string) testprocedure.Steps {
+	steps := make(testprocedure.Steps, 0, len(agentSteps))
+	for _, step := range agentSteps {
 		storedPaths := make([]string, 0, len(step.ImagePaths))
 		for _, imgPath := range step.ImagePaths {
+			if stored, ok := uploaded[imgPath]; ok {
+				storedPaths = append(storedPaths, stored)
+				continue
+			}
+
 			localPath := filepath.Join(tmpDir, imgPath)
 			if _, err := os.Stat(localPath); err != nil {
 				p.logger.Warn(ctx, "screenshot file not found, skipping", map[string]interface{}{
@@ -225,12 +455,12 @@ func (p *Pipeline) run(ctx context.Context, jobID uuid.UUID, needsStart bool) {
 			}
 			f.Close()
 
-			url, err := p.storage.GetURL(ctx, storagePath)
-			if err != nil {
-				storedPaths = append(storedPaths, storagePath)
-			} else {
-				storedPaths = append(storedPaths, url)
+			stored := storagePath
+			if url, err := p.storage.GetURL(ctx, storagePath); err == nil {
+				stored = url
 			}
+			uploaded[imgPath] = stored
+			storedPaths = append(storedPaths, stored)
 		}
 
 		steps = append(steps, testprocedure.TestStep{
@@ -239,46 +469,58 @@ func (p *Pipeline) run(ctx context.Context, jobID uuid.UUID, needsStart bool) {
 			ImagePaths:   storedPaths,
 		})
 	}
+	return steps
+}
 
-	// If no steps were generated, create a placeholder
-	if len(steps) == 0 {
-		steps = append(steps, testprocedure.TestStep{
-			Name:         "Initial observation",
-			Instructions: agentResult.Summary,
-			ImagePaths:   []string{},
-		})
+// startHeartbeat records a heartbeat for the job every heartbeat interval
+// until the returned function is called, so that the reaper can tell it
+// apart from jobs stranded by a crashed server. A job found to be finished,
+// such as one stopped through another server, is canceled.
+func (p *Pipeline) startHeartbeat(ctx context.Context, jobID uuid.UUID) func() {
+	interval := p.config.HeartbeatInterval
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
 	}
 
-	// 9. Save procedure
-	tp := &testprocedure.TestProcedure{
-		ProjectID:   projectID,
-		Name:        agentResult.ProcedureName,
-		Description: "Auto-generated from UI exploration: " + agentResult.Description,
-		Steps:       steps,
-		CreatedBy:   j.CreatedBy,
-	}
+	ticker := time.NewTicker(interval)
+	stop := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := p.jobStore.Heartbeat(ctx, jobID)
+				if errors.Is(err, job.ErrJobNotRunning) {
+					p.stopIfFinished(ctx, jobID)
+				} else if err != nil {
+					p.logger.Warn(ctx, "failed to record job heartbeat", map[string]interface{}{
+						"error":  err.Error(),
+						"job_id": jobID.String(),
+					})
+				}
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
 
-	if err := p.testProcedureStore.Create(ctx, tp); err != nil {
-		p.failJob(ctx, jobID, fmt.Sprintf("failed to save procedure: %v", err))
+// stopIfFinished cancels a job that is no longer running because it was
+// finished elsewhere, such as stopped through an API server while running on
+// a dedicated worker. Jobs that have not started yet are left alone.
+func (p *Pipeline) stopIfFinished(ctx context.Context, jobID uuid.UUID) {
+	j, err := p.jobStore.GetByID(ctx, jobID)
+	if err != nil || !j.Status.IsFinal() {
 		return
 	}
-
-	// 10. Mark job success
-	if err := p.jobStore.Complete(ctx, jobID, job.StatusSuccess, job.JSONMap{
-		"procedure_id":   tp.ID.String(),
-		"procedure_name": tp.Name,
-		"steps_count":    len(tp.Steps),
-	}); err != nil {
-		p.logger.Error(ctx, "failed to mark job as success", map[string]interface{}{
-			"error":  err.Error(),
-			"job_id": jobID.String(),
-		})
-	}
-
-	p.logger.Info(ctx, "agent pipeline completed successfully", map[string]interface{}{
-		"job_id":       jobID.String(),
-		"procedure_id": tp.ID.String(),
+	p.logger.Info(ctx, "job finished elsewhere, stopping it", map[string]interface{}{
+		"job_id": jobID.String(),
+		"status": string(j.Status),
 	})
+	p.Stop(jobID)
 }
 
 // Stop cancels a running job's agent subprocess.
@@ -288,13 +530,42 @@ func (p *Pipeline) Stop(jobID uuid.UUID) {
 	}
 }
 
-// failJob marks a job as failed with the given reason.
+// checkPlaywrightMCP probes the Playwright MCP server under its circuit breaker.
+// Any HTTP response counts as reachable; only transport errors, timeouts and
+// server errors count as failures.
+func (p *Pipeline) checkPlaywrightMCP(ctx context.Context) error {
+	return p.mcpBreaker.Execute(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, p.config.PlaywrightMCPURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// failJob marks a job as failed with the given reason. Jobs cut short by a
+// server shutdown are marked as interrupted instead.
 func (p *Pipeline) failJob(ctx context.Context, jobID uuid.UUID, reason string) {
+	if errors.Is(context.Cause(ctx), shutdown.ErrInterrupted) {
+		p.interruptJob(ctx, jobID)
+		return
+	}
+
 	p.logger.Error(ctx, "agent pipeline failed", map[string]interface{}{
 		"job_id": jobID.String(),
 		"reason": reason,
 	})
 
+	p.jobLog(ctx, jobID, "Job failed: %s", reason)
+
 	// Truncate long error messages for storage
 	if len(reason) > 1000 {
 		reason = reason[:1000] + "... (truncated)"
@@ -312,4 +583,65 @@ func (p *Pipeline) failJob(ctx context.Context, jobID uuid.UUID, reason string)
 			})
 		}
 	}
+
+	p.notifyJobFailed(ctx, jobID, reason)
+}
+
+// interruptJob marks a job as interrupted so that it is queued again when
+// the server next starts.
+func (p *Pipeline) interruptJob(ctx context.Context, jobID uuid.UUID) {
+	ctx = context.WithoutCancel(ctx)
+	p.logger.Warn(ctx, "job interrupted by server shutdown", map[string]interface{}{
+		"job_id": jobID.String(),
+	})
+
+	p.jobLog(ctx, jobID, "Job interrupted by server shutdown; it will be resumed on the next start")
+
+	if err := p.jobStore.Interrupt(ctx, jobID); err != nil && !errors.Is(err, job.ErrJobNotRunning) {
+		p.logger.Error(ctx, "failed to mark job as interrupted", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID.String(),
+		})
+	}
+}
+
+// notifyJobFailed notifies the user who created a job that it failed. Jobs
+// stopped by a user are not reported.
+func (p *Pipeline) notifyJobFailed(ctx context.Context, jobID uuid.UUID, reason string) {
+	if p.notifier == nil {
+		return
+	}
+	// The job may have failed because its time limit ran out.
+	ctx = context.WithoutCancel(ctx)
+
+	j, err := p.jobStore.GetByID(ctx, jobID)
+	if err != nil {
+		p.logger.Warn(ctx, "failed to get job for failure notification", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID.String(),
+		})
+		return
+	}
+	if j.Status != job.StatusFailed {
+		return
+	}
+	p.notifier.Notify(ctx, notification.JobFailedEvent(j.ID, string(j.Type), reason, j.CreatedBy))
+}
+
+// jobLog appends a progress line to a job's log.
+func (p *Pipeline) jobLog(ctx context.Context, jobID uuid.UUID, format string, args ...interface{}) {
+	if _, err := p.logStore.Append(ctx, jobID, fmt.Sprintf(format, args...)); err != nil {
+		p.logger.Warn(ctx, "failed to append job log", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID.String(),
+		})
+	}
+}
+
+// tail returns at most the last n bytes of s, where errors are usually reported.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
 }
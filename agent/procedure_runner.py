@@ -0,0 +1,261 @@
+#!/usr/bin/env python3
+"""
+Procedure Execution Agent
+
+Uses claude-agent-sdk to perform the steps of a committed test procedure in a
+browser driven through the Playwright MCP server, taking a screenshot after
+each step and judging whether the step passed.
+
+Input:  JSON config via stdin
+Output: JSON result at {output_dir}/result.json
+"""
+
+import json
+import os
+import re
+import sys
+
+import anyio
+from claude_agent_sdk import (
+    query,
+    ClaudeAgentOptions,
+    AssistantMessage,
+    ResultMessage,
+    TextBlock,
+    ToolUseBlock,
+)
+
+
+EXECUTOR_SYSTEM_PROMPT = """You are a manual QA tester executing a written test procedure in a web browser.
+
+You will be given:
+- A target URL of the application under test
+- Optional credentials for authentication
+- The numbered steps of the procedure, each with instructions
+- An output directory for screenshots
+
+Perform the steps IN ORDER using Playwright browser tools:
+1. Use `browser_navigate` to open the target URL before the first step
+2. Use `browser_snapshot` to understand the page before interacting with it
+3. Use `browser_click`, `browser_type`, etc. to follow the step's instructions exactly
+4. After EACH step, take a screenshot and use the Bash tool to save it as
+   {output_dir}/screenshots/step-<n>.png, where <n> is the step number
+5. Decide whether the step passed: every action could be performed, every
+   expectation in the instructions holds on the page and so does the step's
+   expected result, if it has one
+6. Print a line of the form `STEP_RESULT: <n> passed` or `STEP_RESULT: <n> failed`
+
+Instructions may contain placeholders of the form {{{{KEY}}}}; replace them
+with the value of the matching environment secret.
+
+If a step fails, stop: do not perform the remaining steps, they are recorded
+as skipped.
+
+When you are done, write the result as a JSON file to {output_dir}/result.json
+using the Bash tool. The JSON format MUST be:
+{{
+  "steps": [
+    {{
+      "index": <step number minus one>,
+      "status": "passed" | "failed",
+      "notes": "<what you did and observed; for a failed step, what went wrong>",
+      "screenshot": "screenshots/step-<n>.png"
+    }}
+  ],
+  "summary": "<one or two sentences on the outcome of the run>"
+}}
+
+IMPORTANT:
+- You MUST write the result.json file at the end using the Bash tool
+- Only report the steps you performed
+- Do not improvise steps that are not in the procedure
+"""
+
+STEP_RESULT_PATTERN = re.compile(r"STEP_RESULT:\s*(\d+)\s+(passed|failed)")
+
+
+class Progress:
+    """Tracks the steps performed against the number of steps and prints
+    "[progress] <performed>/<total> steps" lines, which the backend records
+    on the job, whenever a step is reported."""
+
+    def __init__(self, total: int) -> None:
+        self.performed: dict = {}
+        self.total = total
+
+    def on_text(self, text: str) -> None:
+        for match in STEP_RESULT_PATTERN.finditer(text):
+            step = int(match.group(1))
+            reported = step in self.performed
+            self.performed[step] = match.group(2)
+            if not reported:
+                self.report()
+
+    def report(self) -> None:
+        print(
+            f"[progress] {len(self.performed)}/{self.total} steps",
+            file=sys.stderr,
+            flush=True,
+        )
+
+
+def format_steps(steps: list) -> str:
+    lines = []
+    for step in steps:
+        lines.append(f"Step {step['index'] + 1}: {step['name']}")
+        lines.append(f"  {step['instructions']}")
+        if step.get("expected_result"):
+            lines.append(f"  Expected result: {step['expected_result']}")
+    return "\n".join(lines)
+
+
+def fallback_result(steps: list, performed: dict, final_text: str) -> dict:
+    """Builds a result from the STEP_RESULT lines when the agent did not
+    write result.json."""
+    results = []
+    for step in steps:
+        n = step["index"] + 1
+        if n not in performed:
+            continue
+        results.append(
+            {
+                "index": step["index"],
+                "status": performed[n],
+                "notes": "",
+                "screenshot": f"screenshots/step-{n}.png",
+            }
+        )
+    return {
+        "steps": results,
+        "summary": final_text or "Execution completed with fallback output",
+    }
+
+
+def usage_of(message: ResultMessage) -> dict:
+    """Returns the tokens and cost of the agent's model calls, which the
+    backend meters against the LLM budgets of the project."""
+    usage = message.usage or {}
+    return {
+        "input_tokens": usage.get("input_tokens", 0)
+        + usage.get("cache_creation_input_tokens", 0)
+        + usage.get("cache_read_input_tokens", 0),
+        "output_tokens": usage.get("output_tokens", 0),
+        "cost_usd": message.total_cost_usd or 0,
+    }
+
+
+def add_usage(result_path: str, usage: dict) -> None:
+    """Adds the agent's usage to result.json. A malformed result is left as
+    it is for the backend to reject."""
+    try:
+        with open(result_path) as f:
+            result = json.load(f)
+    except json.JSONDecodeError:
+        return
+    result["usage"] = usage
+    with open(result_path, "w") as f:
+        json.dump(result, f, indent=2)
+
+
+async def run_agent(config: dict) -> None:
+    target_url = config["target_url"]
+    credentials = config.get("credentials", [])
+    secrets = config.get("secrets", [])
+    procedure_name = config.get("procedure_name", "Test Procedure")
+    preconditions = config.get("preconditions", [])
+    steps = config["steps"]
+    output_dir = config["output_dir"]
+    playwright_mcp_url = config.get(
+        "playwright_mcp_url", "http://playwright-mcp:3000/sse"
+    )
+
+    os.makedirs(os.path.join(output_dir, "screenshots"), exist_ok=True)
+
+    cred_text = ""
+    if credentials:
+        cred_lines = [f"  - {c['key']}: {c['value']}" for c in credentials]
+        cred_text = "\n\nAvailable credentials:\n" + "\n".join(cred_lines)
+    if secrets:
+        secret_lines = [f"  - {s['key']}: {s['value']}" for s in secrets]
+        cred_text += "\n\nEnvironment secrets:\n" + "\n".join(secret_lines)
+
+    precondition_text = ""
+    if preconditions:
+        precondition_lines = [f"  - {p}" for p in preconditions]
+        precondition_text = (
+            "Preconditions (already in place; do not try to set them up):\n"
+            + "\n".join(precondition_lines)
+            + "\n\n"
+        )
+
+    prompt = (
+        f'Execute the test procedure "{procedure_name}" against {target_url}.\n\n'
+        f"{precondition_text}"
+        f"{format_steps(steps)}\n\n"
+        f"Output directory: {output_dir}\n"
+        f"Screenshots directory: {output_dir}/screenshots/\n"
+        f"Result file: {output_dir}/result.json\n"
+        f"{cred_text}\n\n"
+        f"Make sure to write the result.json file when you're done."
+    )
+
+    options = ClaudeAgentOptions(
+        system_prompt=EXECUTOR_SYSTEM_PROMPT.format(output_dir=output_dir),
+        max_turns=20 + 10 * len(steps),
+        allowed_tools=["Bash", "mcp__playwright__*"],
+        permission_mode="bypassPermissions",
+        mcp_servers={
+            "playwright": {
+                "type": "sse",
+                "url": playwright_mcp_url,
+            }
+        },
+    )
+
+    final_text = ""
+    usage = {}
+    progress = Progress(len(steps))
+    async for message in query(prompt=prompt, options=options):
+        if isinstance(message, AssistantMessage):
+            for block in message.content:
+                # Progress goes to stderr, which the backend stores as the job log.
+                if isinstance(block, TextBlock):
+                    final_text = block.text
+                    print(block.text, file=sys.stderr, flush=True)
+                    progress.on_text(block.text)
+                elif isinstance(block, ToolUseBlock):
+                    print(f"[tool] {block.name}", file=sys.stderr, flush=True)
+        elif isinstance(message, ResultMessage):
+            usage = usage_of(message)
+
+    result_path = os.path.join(output_dir, "result.json")
+    if not os.path.exists(result_path):
+        with open(result_path, "w") as f:
+            json.dump(fallback_result(steps, progress.performed, final_text), f, indent=2)
+
+    add_usage(result_path, usage)
+
+
+def main() -> None:
+    config_data = sys.stdin.read()
+    if not config_data.strip():
+        print("Error: no config provided on stdin", file=sys.stderr)
+        sys.exit(1)
+
+    try:
+        config = json.loads(config_data)
+    except json.JSONDecodeError as e:
+        print(f"Error: invalid JSON config: {e}", file=sys.stderr)
+        sys.exit(1)
+
+    required = ["target_url", "output_dir", "steps"]
+    for field in required:
+        if field not in config:
+            print(f"Error: missing required field '{field}'", file=sys.stderr)
+            sys.exit(1)
+
+    anyio.run(run_agent, config)
+
+
+if __name__ == "__main__":
+    main()
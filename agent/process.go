@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Process runs a Python agent script as a subprocess: the exploration agent
+// or the procedure execution agent. It is shared by the server's pipeline,
+// job runners and agent runners.
+type Process struct {
+	ScriptPath       string
+	BedrockRegion    string
+	BedrockAccessKey string
+	BedrockSecretKey string
+}
+
+// Run spawns the agent with cfg as JSON on its stdin and waits for it to
+// exit. The agent's stderr, its progress log, is copied to stderr, and the
+// end of it is included in the error when the agent fails. The agents write
+// their result to result.json in the output directory of their config.
+func (a Process) Run(ctx context.Context, cfg interface{}, stderr io.Writer) error {
+	configJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal agent config: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "python3", a.ScriptPath)
+	cmd.Stdin = bytes.NewReader(configJSON)
+
+	// Set environment variables for Bedrock auth
+	cmd.Env = append(os.Environ(),
+		"CLAUDE_CODE_USE_BEDROCK=1",
+		fmt.Sprintf("AWS_REGION=%s", a.BedrockRegion),
+	)
+	if a.BedrockAccessKey != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", a.BedrockAccessKey))
+	}
+	if a.BedrockSecretKey != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", a.BedrockSecretKey))
+	}
+
+	var output bytes.Buffer
+	cmd.Stderr = io.MultiWriter(&output, stderr)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("agent subprocess failed: %v; stderr: %s", err, tail(output.String(), 800))
+	}
+	return nil
+}
+
+// ReadResult reads the result the agent wrote to dir.
+func ReadResult(dir string) (*AgentResult, error) {
+	resultData, err := os.ReadFile(filepath.Join(dir, "result.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent result: %w", err)
+	}
+
+	var result AgentResult
+	if err := json.Unmarshal(resultData, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse agent result: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// progressPattern matches the progress lines the agents print to stderr,
+// e.g. "[progress] 3/12 pages" or "[progress] 2/5 steps". A total of 0
+// means the agent has not estimated the number of pages yet.
+var progressPattern = regexp.MustCompile(`^\[progress\] (\d+)/(\d+) (?:pages|steps)`)
+
+// progressWriter is an io.Writer for an agent's stderr that records the
+// progress lines it prints on the job.
+type progressWriter struct {
+	ctx      context.Context
+	jobStore job.Store
+	jobID    uuid.UUID
+	logger   logger.Logger
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewProgressWriter creates a writer recording the progress lines written to
+// it on the job jobID.
+func NewProgressWriter(ctx context.Context, jobStore job.Store, jobID uuid.UUID, log logger.Logger) io.Writer {
+	return &progressWriter{
+		ctx:      ctx,
+		jobStore: jobStore,
+		jobID:    jobID,
+		logger:   log,
+	}
+}
+
+// Write buffers p and handles every complete line.
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		w.handle(w.buf.Next(i + 1))
+	}
+	return len(p), nil
+}
+
+// handle records the progress of a single line, ignoring other lines.
+// Callers must hold w.mu.
+func (w *progressWriter) handle(line []byte) {
+	done, total, ok := ParseProgress(string(line))
+	if !ok {
+		return
+	}
+
+	if err := w.jobStore.ReportProgress(w.ctx, w.jobID, done, total); err != nil && !errors.Is(err, job.ErrJobNotRunning) {
+		w.logger.Warn(w.ctx, "failed to record job progress", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": w.jobID.String(),
+		})
+	}
+}
+
+// ParseProgress parses a progress line the agent printed to stderr,
+// returning false for other lines.
+func ParseProgress(line string) (done, total int, ok bool) {
+	m := progressPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return 0, 0, false
+	}
+	done, err1 := strconv.Atoi(m[1])
+	total, err2 := strconv.Atoi(m[2])
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return done, total, true
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/runner"
+)
+
+// RemoteJob is a ui_exploration job claimed by an agent runner, which runs
+// the agent next to the application under test and reports back through
+// the API. Config lacks the output directory and Playwright MCP URL, which
+// the runner fills in.
+type RemoteJob struct {
+	Job    *job.Job    `json:"job"`
+	Config AgentConfig `json:"agent_config"`
+}
+
+// ClaimRemote claims the oldest created ui_exploration job that createdBy
+// queued against an endpoint using agent runners, for a runner with the
+// given labels. Endpoints requiring labels the runner lacks, and endpoints
+// already running as many jobs as they allow, are skipped. Returns nil, nil
+// if there is no such job.
+func (p *Pipeline) ClaimRemote(ctx context.Context, createdBy uuid.UUID, labels runner.Labels) (*RemoteJob, error) {
+	endpoints, err := p.endpointStore.ListUsingRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var runnerEndpoints []uuid.UUID
+	for _, ep := range endpoints {
+		if labels.Satisfies(ep.RunnerLabels) {
+			runnerEndpoints = append(runnerEndpoints, ep.ID)
+		}
+	}
+
+	j, err := p.jobStore.ClaimNextCreatedOn(ctx, job.JobTypeUIExploration, createdBy, runnerEndpoints)
+	if err != nil || j == nil {
+		return nil, err
+	}
+
+	target, err := p.prepareExploration(ctx, j)
+	if err != nil {
+		p.failJob(ctx, j.ID, err.Error())
+		return nil, fmt.Errorf("failed to prepare job %s: %w", j.ID, err)
+	}
+	p.jobLog(ctx, j.ID, "Exploring %s on an agent runner", target.endpoint.URL)
+
+	return &RemoteJob{Job: j, Config: target.config}, nil
+}
+
+// CompleteRemote saves the result of a job run by an agent runner. The
+// screenshots the result refers to must have been saved in dir under their
+// base names. Returns job.ErrJobNotRunning when the job is no longer
+// running, such as when it was stopped while the runner worked on it.
+func (p *Pipeline) CompleteRemote(ctx context.Context, jobID uuid.UUID, result *AgentResult, dir string) error {
+	j, err := p.runningJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	projectID, err := jobProjectID(j)
+	if err != nil {
+		p.failJob(ctx, jobID, err.Error())
+		return nil
+	}
+	defer p.recordRemoteDuration(ctx, j, projectID)
+
+	flattenImagePaths(result.Steps)
+	for _, flow := range result.Flows {
+		flattenImagePaths(flow.Steps)
+	}
+
+	p.jobLog(ctx, jobID, "Agent finished, saving results")
+	p.saveExploration(ctx, j, projectID, dir, result)
+	return nil
+}
+
+// FailRemote fails a job an agent runner could not run. Returns
+// job.ErrJobNotRunning when the job is no longer running.
+func (p *Pipeline) FailRemote(ctx context.Context, jobID uuid.UUID, reason string) error {
+	j, err := p.runningJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if projectID, err := jobProjectID(j); err == nil {
+		defer p.recordRemoteDuration(ctx, j, projectID)
+	}
+
+	p.failJob(ctx, jobID, reason)
+	return nil
+}
+
+// runningJob fetches a job, returning job.ErrJobNotRunning unless it is running.
+func (p *Pipeline) runningJob(ctx context.Context, jobID uuid.UUID) (*job.Job, error) {
+	j, err := p.jobStore.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if j.Status != job.StatusRunning {
+		return nil, job.ErrJobNotRunning
+	}
+	return j, nil
+}
+
+// recordRemoteDuration bills the agent time of a job run by an agent
+// runner, from when the runner claimed it.
+func (p *Pipeline) recordRemoteDuration(ctx context.Context, j *job.Job, projectID uuid.UUID) {
+	if j.StartTime == nil {
+		return
+	}
+	p.recorder.RecordJobDuration(ctx, projectID, j.CreatedBy, time.Since(*j.StartTime))
+}
+
+// flattenImagePaths reduces the image paths of steps to base names, the
+// names screenshots uploaded by agent runners are saved under.
+func flattenImagePaths(steps []AgentStep) {
+	for _, step := range steps {
+		for i, imgPath := range step.ImagePaths {
+			step.ImagePaths[i] = filepath.Base(imgPath)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package agent
 
+import "github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+
 // AgentConfig is the JSON config sent to the Python agent script via stdin.
 type AgentConfig struct {
 	TargetURL       string       `json:"target_url"`
 	Credentials     []Credential `json:"credentials,omitempty"`
+	Secrets         []Credential `json:"secrets,omitempty"`
 	ProcedureName   string       `json:"procedure_name"`
 	JobID           string       `json:"job_id"`
 	OutputDir       string       `json:"output_dir"`
@@ -17,11 +20,31 @@ type Credential struct {
 }
 
 // AgentResult is the JSON result produced by the Python agent script.
+// Pages and Flows are optional; older agent versions only produce Steps.
 type AgentResult struct {
 	ProcedureName string      `json:"procedure_name"`
 	Description   string      `json:"description"`
 	Steps         []AgentStep `json:"steps"`
 	Summary       string      `json:"summary"`
+	Pages         []AgentPage `json:"pages,omitempty"`
+	Flows         []AgentFlow `json:"flows,omitempty"`
+	// Usage is the tokens and cost of the agent's model calls.
+	Usage llmusage.Usage `json:"usage"`
+}
+
+// AgentPage is a page the agent discovered while exploring.
+type AgentPage struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// AgentFlow is a distinct user flow the agent discovered, such as logging in
+// or checking out, described as its own sequence of steps.
+type AgentFlow struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Steps       []AgentStep `json:"steps"`
 }
 
 // AgentStep represents a single step in the agent-generated test procedure.
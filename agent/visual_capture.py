@@ -0,0 +1,101 @@
+#!/usr/bin/env python3
+"""
+Visual Regression Capture
+
+Drives the browser of a Playwright MCP server directly (no LLM) to take a PNG
+screenshot of each configured page for a visual_regression job.
+
+Input:  JSON config via stdin
+Output: page-{n}.png screenshots and a JSON manifest at {output_dir}/result.json
+"""
+
+import base64
+import json
+import os
+import sys
+
+import anyio
+from mcp import ClientSession
+from mcp.client.sse import sse_client
+
+
+async def capture_page(session: ClientSession, page: dict, index: int, config: dict) -> dict:
+    """Navigates to a page and saves its screenshot; errors are reported per page."""
+    entry = {"name": page["name"], "url": page["url"]}
+    try:
+        result = await session.call_tool("browser_navigate", {"url": page["url"]})
+        if result.isError:
+            raise RuntimeError(_text_of(result) or "navigation failed")
+
+        result = await session.call_tool(
+            "browser_take_screenshot",
+            {"type": "png", "fullPage": bool(config.get("full_page", False))},
+        )
+        if result.isError:
+            raise RuntimeError(_text_of(result) or "screenshot failed")
+
+        image = next((c for c in result.content if c.type == "image"), None)
+        if image is None:
+            raise RuntimeError("screenshot returned no image")
+
+        filename = f"page-{index}.png"
+        with open(os.path.join(config["output_dir"], filename), "wb") as f:
+            f.write(base64.b64decode(image.data))
+        entry["file"] = filename
+    except Exception as e:  # noqa: BLE001 - one bad page must not stop the run
+        entry["error"] = str(e)
+    return entry
+
+
+def _text_of(result) -> str:
+    return " ".join(c.text for c in result.content if c.type == "text").strip()
+
+
+async def run_capture(config: dict) -> None:
+    output_dir = config["output_dir"]
+    os.makedirs(output_dir, exist_ok=True)
+    viewport = config.get("viewport") or {}
+
+    async with sse_client(config["playwright_mcp_url"]) as (read, write):
+        async with ClientSession(read, write) as session:
+            await session.initialize()
+
+            if viewport.get("width") and viewport.get("height"):
+                await session.call_tool(
+                    "browser_resize",
+                    {"width": viewport["width"], "height": viewport["height"]},
+                )
+
+            pages = []
+            for index, page in enumerate(config["pages"]):
+                pages.append(await capture_page(session, page, index, config))
+
+    with open(os.path.join(output_dir, "result.json"), "w") as f:
+        json.dump({"pages": pages}, f, indent=2)
+
+
+def main() -> None:
+    # Read config from stdin
+    config_data = sys.stdin.read()
+    if not config_data.strip():
+        print("Error: no config provided on stdin", file=sys.stderr)
+        sys.exit(1)
+
+    try:
+        config = json.loads(config_data)
+    except json.JSONDecodeError as e:
+        print(f"Error: invalid JSON config: {e}", file=sys.stderr)
+        sys.exit(1)
+
+    # Validate required fields
+    required = ["playwright_mcp_url", "output_dir", "pages"]
+    for field in required:
+        if field not in config:
+            print(f"Error: missing required field '{field}'", file=sys.stderr)
+            sys.exit(1)
+
+    anyio.run(run_capture, config)
+
+
+if __name__ == "__main__":
+    main()
@@ -2,30 +2,69 @@ package agent
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
 	"github.com/hairizuanbinnoorazman/ui-automation/job"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/queue"
+	"github.com/hairizuanbinnoorazman/ui-automation/shutdown"
+)
+
+const (
+	// saturatedRetryDelay is how long a queued job whose endpoint is running
+	// its MaxConcurrentJobs is hidden before it is tried again.
+	saturatedRetryDelay = 15 * time.Second
+	// receiveRetryDelay is how long a worker waits after failing to receive
+	// from the queue.
+	receiveRetryDelay = 5 * time.Second
 )
 
 // WorkerPool manages a pool of goroutines that process jobs from the database.
 // Workers are notified via a channel when new jobs are created, and each worker
 // atomically claims jobs using SELECT FOR UPDATE to prevent double-processing.
+// Jobs whose endpoint is already running its MaxConcurrentJobs stay queued
+// until a running job for that endpoint finishes; the limit is checked in
+// the claim's transaction, so it holds across worker nodes. The number of workers can
+// be changed with Resize while the pool runs. Jobs run as work of the
+// shutdown coordinator, and workers stop claiming jobs once it drains.
+//
+// With a queue, jobs are instead dispatched through it so that workers can
+// run on dedicated nodes: workers receive job IDs from the queue and keep
+// the message hidden while the job runs, so a job left by a dead worker is
+// delivered to another one.
+//
+// Jobs against endpoints that use agent runners are left for the runners.
 type WorkerPool struct {
-	Work       chan struct{}
-	maxWorkers int
-	jobStore   job.Store
-	pipeline   *Pipeline
-	logger     logger.Logger
+	Work          chan struct{}
+	maxWorkers    int
+	jobStore      job.Store
+	endpointStore endpoint.Store
+	pipeline      *Pipeline
+	queue         queue.Queue
+	coordinator   *shutdown.Coordinator
+	logger        logger.Logger
+
+	mu      sync.Mutex
+	ctx     context.Context
+	workers []chan struct{} // Stop channel of each running worker
 }
 
-// NewWorkerPool creates a new worker pool.
-func NewWorkerPool(maxWorkers int, jobStore job.Store, pipeline *Pipeline, log logger.Logger) *WorkerPool {
+// NewWorkerPool creates a new worker pool. q may be nil, in which case
+// workers claim created jobs from the database.
+func NewWorkerPool(maxWorkers int, jobStore job.Store, endpointStore endpoint.Store, pipeline *Pipeline, q queue.Queue, coordinator *shutdown.Coordinator, log logger.Logger) *WorkerPool {
 	return &WorkerPool{
-		Work:       make(chan struct{}, maxWorkers),
-		maxWorkers: maxWorkers,
-		jobStore:   jobStore,
-		pipeline:   pipeline,
-		logger:     log,
+		Work:          make(chan struct{}, maxWorkers),
+		maxWorkers:    maxWorkers,
+		jobStore:      jobStore,
+		endpointStore: endpointStore,
+		pipeline:      pipeline,
+		queue:         q,
+		coordinator:   coordinator,
+		logger:        log,
 	}
 }
 
@@ -34,42 +73,298 @@ func (p *WorkerPool) Start(ctx context.Context) {
 	p.logger.Info(ctx, "starting worker pool", map[string]interface{}{
 		"max_workers": p.maxWorkers,
 	})
-	for i := 0; i < p.maxWorkers; i++ {
-		go p.worker(ctx, i)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ctx = ctx
+	p.scale(p.maxWorkers)
+}
+
+// Resize changes the number of workers. Removed workers finish the jobs
+// they are running before they stop; added workers start claiming queued
+// jobs straight away.
+func (p *WorkerPool) Resize(maxWorkers int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ctx == nil {
+		p.maxWorkers = maxWorkers
+		return
+	}
+	if maxWorkers == len(p.workers) {
+		return
+	}
+	p.logger.Info(p.ctx, "resizing worker pool", map[string]interface{}{
+		"from": len(p.workers),
+		"to":   maxWorkers,
+	})
+	p.scale(maxWorkers)
+}
+
+// scale starts or stops workers until maxWorkers are running. Callers must
+// hold p.mu.
+func (p *WorkerPool) scale(maxWorkers int) {
+	added := 0
+	for len(p.workers) < maxWorkers {
+		stop := make(chan struct{})
+		go p.worker(p.ctx, len(p.workers), stop)
+		p.workers = append(p.workers, stop)
+		added++
+	}
+	for len(p.workers) > maxWorkers {
+		last := len(p.workers) - 1
+		close(p.workers[last])
+		p.workers = p.workers[:last]
+	}
+	p.maxWorkers = maxWorkers
+
+	// Wake the new workers in case jobs are already queued.
+	for i := 0; i < added; i++ {
+		select {
+		case p.Work <- struct{}{}:
+		default:
+		}
 	}
 }
 
-func (p *WorkerPool) worker(ctx context.Context, id int) {
+// Wake signals an idle worker to look for queued jobs. It does not block
+// when all workers are busy; they pick the jobs up once they finish.
+func (p *WorkerPool) Wake() {
+	select {
+	case p.Work <- struct{}{}:
+	default:
+	}
+}
+
+// Dispatch hands a created job to the workers. With a queue the job is sent
+// to it; otherwise an idle worker is woken to claim it.
+func (p *WorkerPool) Dispatch(ctx context.Context, jobID uuid.UUID) error {
+	if p.queue == nil {
+		p.Wake()
+		return nil
+	}
+	return p.queue.Send(ctx, jobID)
+}
+
+func (p *WorkerPool) worker(ctx context.Context, id int, stop <-chan struct{}) {
 	p.logger.Info(ctx, "worker started", map[string]interface{}{
 		"worker_id": id,
 	})
+	if p.queue != nil {
+		p.queueWorker(ctx, id, stop)
+		return
+	}
 	for {
 		select {
 		case <-p.Work:
 			// Drain all available created jobs before going back to wait
-			for {
-				j, err := p.jobStore.ClaimNextCreated(ctx)
-				if err != nil {
-					p.logger.Error(ctx, "worker failed to claim job", map[string]interface{}{
-						"worker_id": id,
-						"error":     err.Error(),
-					})
-					break
-				}
-				if j == nil {
-					break
-				}
-				p.logger.Info(ctx, "worker processing job", map[string]interface{}{
-					"worker_id": id,
-					"job_id":    j.ID.String(),
-				})
-				p.pipeline.RunAfterClaim(ctx, j.ID)
+			for p.runNext(ctx, id) {
 			}
+			continue
+		case <-stop:
 		case <-ctx.Done():
-			p.logger.Info(ctx, "worker stopping", map[string]interface{}{
+		case <-p.coordinator.DrainStarted():
+		}
+		p.logger.Info(ctx, "worker stopping", map[string]interface{}{
+			"worker_id": id,
+		})
+		return
+	}
+}
+
+// runNext claims and runs a single job. It returns false when no job was
+// run, either because none is queued or because the server is shutting down.
+func (p *WorkerPool) runNext(ctx context.Context, id int) bool {
+	jobCtx, done, err := p.coordinator.Begin(ctx)
+	if err != nil {
+		return false
+	}
+	defer done()
+
+	// Jobs against endpoints that use agent runners or have no spare
+	// capacity are skipped
+	j, err := p.jobStore.ClaimNextCreated(jobCtx)
+	if err != nil {
+		p.logger.Error(ctx, "worker failed to claim job", map[string]interface{}{
+			"worker_id": id,
+			"error":     err.Error(),
+		})
+		return false
+	}
+	if j == nil {
+		return false
+	}
+	p.logger.Info(ctx, "worker processing job", map[string]interface{}{
+		"worker_id": id,
+		"job_id":    j.ID.String(),
+	})
+	p.pipeline.RunAfterClaim(jobCtx, j.ID)
+	return true
+}
+
+// queueWorker receives jobs from the queue and runs them until the worker is
+// stopped or the server drains.
+func (p *WorkerPool) queueWorker(ctx context.Context, id int, stop <-chan struct{}) {
+	for {
+		retryAfter := time.Duration(0)
+		if !p.receiveNext(ctx, id) {
+			retryAfter = receiveRetryDelay
+		}
+
+		select {
+		case <-time.After(retryAfter):
+			continue
+		case <-stop:
+		case <-ctx.Done():
+		case <-p.coordinator.DrainStarted():
+		}
+		p.logger.Info(ctx, "worker stopping", map[string]interface{}{
+			"worker_id": id,
+		})
+		return
+	}
+}
+
+// receiveNext waits for a job from the queue and runs it. It returns false
+// when receiving failed, so that the worker backs off.
+func (p *WorkerPool) receiveNext(ctx context.Context, id int) bool {
+	msg, err := p.queue.Receive(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error(ctx, "worker failed to receive job", map[string]interface{}{
 				"worker_id": id,
+				"error":     err.Error(),
 			})
+		}
+		return false
+	}
+	if msg == nil {
+		return true
+	}
+
+	jobCtx, done, err := p.coordinator.Begin(ctx)
+	if err != nil {
+		// The server is shutting down; let another worker run the job.
+		p.changeVisibility(ctx, msg, 0)
+		return true
+	}
+	defer done()
+
+	if !p.claimMessage(jobCtx, msg) {
+		return true
+	}
+	p.logger.Info(ctx, "worker processing job", map[string]interface{}{
+		"worker_id": id,
+		"job_id":    msg.JobID.String(),
+	})
+
+	stopExtending := p.extendVisibility(jobCtx, msg)
+	p.pipeline.RunAfterClaim(jobCtx, msg.JobID)
+	stopExtending()
+	p.finishMessage(jobCtx, msg)
+	return true
+}
+
+// claimMessage starts the job of a received message. Messages of jobs that
+// no longer need running, such as jobs already run through an earlier
+// delivery, are deleted, and jobs whose endpoint is saturated are hidden for
+// a while.
+func (p *WorkerPool) claimMessage(ctx context.Context, msg *queue.Message) bool {
+	j, err := p.jobStore.GetByID(ctx, msg.JobID)
+	if errors.Is(err, job.ErrJobNotFound) || (err == nil && j.Status != job.StatusCreated) {
+		p.deleteMessage(ctx, msg)
+		return false
+	}
+	if err != nil {
+		// The message is delivered again once its visibility timeout passes.
+		p.logger.Error(ctx, "worker failed to get queued job", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": msg.JobID.String(),
+		})
+		return false
+	}
+
+	if j.EndpointID != nil {
+		ep, err := p.endpointStore.GetByID(ctx, *j.EndpointID)
+		if err == nil && ep.UseRunner {
+			// Agent runners claim the job through the API.
+			p.deleteMessage(ctx, msg)
+			return false
+		}
+	}
+
+	claimed, err := p.jobStore.ClaimCreated(ctx, j.ID)
+	if err != nil {
+		p.logger.Error(ctx, "worker failed to claim job", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": msg.JobID.String(),
+		})
+		return false
+	}
+	if claimed == nil {
+		// The endpoint is saturated, or another worker started the job,
+		// which the next delivery finds out.
+		p.changeVisibility(ctx, msg, saturatedRetryDelay)
+		return false
+	}
+	return true
+}
+
+// extendVisibility keeps a message hidden while its job runs, until the
+// returned function is called.
+func (p *WorkerPool) extendVisibility(ctx context.Context, msg *queue.Message) func() {
+	timeout := p.queue.VisibilityTimeout()
+	ticker := time.NewTicker(timeout / 3)
+	stop := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.changeVisibility(ctx, msg, timeout)
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// finishMessage deletes the message of a job that has run. Jobs interrupted
+// by a shutdown are queued again and their message released, so that
+// another worker resumes them.
+func (p *WorkerPool) finishMessage(ctx context.Context, msg *queue.Message) {
+	ctx = context.WithoutCancel(ctx)
+
+	j, err := p.jobStore.GetByID(ctx, msg.JobID)
+	if err == nil && j.Status == job.StatusInterrupted {
+		if err := p.jobStore.Requeue(ctx, j.ID); err == nil {
+			p.changeVisibility(ctx, msg, 0)
 			return
 		}
 	}
+	p.deleteMessage(ctx, msg)
+}
+
+// changeVisibility hides a message for d, logging failures. A message that
+// is no longer in flight has been redelivered, and the job's state in the
+// database keeps it from running twice.
+func (p *WorkerPool) changeVisibility(ctx context.Context, msg *queue.Message, d time.Duration) {
+	if err := p.queue.ChangeVisibility(ctx, msg, d); err != nil {
+		p.logger.Warn(ctx, "failed to change queued job visibility", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": msg.JobID.String(),
+		})
+	}
+}
+
+// deleteMessage removes a message from the queue, logging failures.
+func (p *WorkerPool) deleteMessage(ctx context.Context, msg *queue.Message) {
+	if err := p.queue.Delete(ctx, msg); err != nil {
+		p.logger.Warn(ctx, "failed to delete queued job", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": msg.JobID.String(),
+		})
+	}
 }
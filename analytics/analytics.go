@@ -0,0 +1,255 @@
+// Package analytics reports pass rates, durations, failure streaks and the
+// most-failed steps of test procedures. Completed runs are folded into
+// pre-aggregated per-day tables when they complete, so reports never scan
+// the run history.
+package analytics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+const (
+	// DefaultWindowDays is how many days of runs a report covers when no
+	// window is given.
+	DefaultWindowDays = 30
+
+	// MaxWindowDays is the longest window a report can cover.
+	MaxWindowDays = 365
+
+	// DefaultTopSteps is how many of the most-failed steps a procedure
+	// report lists.
+	DefaultTopSteps = 5
+)
+
+var (
+	// ErrInvalidWindow is returned when a report window is out of range.
+	ErrInvalidWindow = errors.New("days must be between 1 and 365")
+
+	// ErrNotCompleted is returned when recording a run that has not completed.
+	ErrNotCompleted = errors.New("test run has not completed")
+)
+
+// ProcedureStats is the running state of a procedure's completed runs. It is
+// keyed by the first version of the procedure, so it covers every version.
+type ProcedureStats struct {
+	ProcedureID   uuid.UUID `json:"procedure_id" gorm:"type:char(36);primaryKey"`
+	ProjectID     uuid.UUID `json:"project_id" gorm:"type:char(36);not null;index:idx_procedure_run_stats_project_id"`
+	ProcedureName string    `json:"procedure_name" gorm:"type:varchar(255);not null"`
+	// LastStatus is the status of the latest completed run, and LastOutcome
+	// the latest that passed or failed. Skipped runs do not break a streak.
+	LastStatus           testrun.Status `json:"last_status" gorm:"type:varchar(20);not null"`
+	LastOutcome          testrun.Status `json:"-" gorm:"type:varchar(20);not null;default:''"`
+	LastCompletedAt      time.Time      `json:"last_completed_at"`
+	CurrentFailureStreak int            `json:"current_failure_streak" gorm:"not null;default:0"`
+	LongestFailureStreak int            `json:"longest_failure_streak" gorm:"not null;default:0"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (s *ProcedureStats) TableName() string {
+	return "procedure_run_stats"
+}
+
+// Apply folds a completed run into the stats. It reports whether the run
+// flipped the procedure between passing and failing.
+func (s *ProcedureStats) Apply(c *Completion) bool {
+	s.ProjectID = c.ProjectID
+	s.ProcedureName = c.ProcedureName
+	s.LastStatus = c.Status
+	s.LastCompletedAt = c.CompletedAt
+
+	if c.Status != testrun.StatusPassed && c.Status != testrun.StatusFailed {
+		return false
+	}
+
+	flipped := s.LastOutcome != "" && s.LastOutcome != c.Status
+	s.LastOutcome = c.Status
+	if c.Status == testrun.StatusFailed {
+		s.CurrentFailureStreak++
+		if s.CurrentFailureStreak > s.LongestFailureStreak {
+			s.LongestFailureStreak = s.CurrentFailureStreak
+		}
+	} else {
+		s.CurrentFailureStreak = 0
+	}
+	return flipped
+}
+
+// DailyStats counts the runs of a procedure completed on one UTC day.
+type DailyStats struct {
+	ProcedureID uuid.UUID `gorm:"type:char(36);primaryKey"`
+	Day         time.Time `gorm:"type:date;primaryKey;index:idx_procedure_run_daily_stats_project_day,priority:2"`
+	ProjectID   uuid.UUID `gorm:"type:char(36);not null;index:idx_procedure_run_daily_stats_project_day,priority:1"`
+	Passed      int       `gorm:"not null;default:0"`
+	Failed      int       `gorm:"not null;default:0"`
+	Skipped     int       `gorm:"not null;default:0"`
+	// Flips counts runs whose outcome differed from the previous run's, the
+	// signal of a flaky procedure.
+	Flips int `gorm:"not null;default:0"`
+	// TimedRuns is the number of runs whose duration is known, and
+	// DurationSeconds their total duration.
+	TimedRuns       int     `gorm:"not null;default:0"`
+	DurationSeconds float64 `gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for GORM.
+func (d *DailyStats) TableName() string {
+	return "procedure_run_daily_stats"
+}
+
+// StepFailures counts the failed runs of a procedure that failed at a step
+// on one UTC day.
+type StepFailures struct {
+	ProcedureID uuid.UUID `gorm:"type:char(36);primaryKey"`
+	Day         time.Time `gorm:"type:date;primaryKey"`
+	StepIndex   int       `gorm:"primaryKey;autoIncrement:false"`
+	StepName    string    `gorm:"type:varchar(255);not null"`
+	Failures    int       `gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for GORM.
+func (f *StepFailures) TableName() string {
+	return "procedure_step_failures"
+}
+
+// Completion describes a completed run as analytics records it.
+type Completion struct {
+	// ProcedureID is the first version of the run's procedure.
+	ProcedureID   uuid.UUID
+	ProjectID     uuid.UUID
+	ProcedureName string
+	Status        testrun.Status
+	CompletedAt   time.Time
+	// Duration is zero if the run has no start time.
+	Duration time.Duration
+	// FailedStep and FailedStepName are set if the run failed at a step.
+	FailedStep     *int
+	FailedStepName string
+}
+
+// NewCompletion describes a completed run of the procedure version proc.
+func NewCompletion(tr *testrun.TestRun, proc *testprocedure.TestProcedure) (*Completion, error) {
+	if tr.CompletedAt == nil || !tr.Status.IsFinal() {
+		return nil, ErrNotCompleted
+	}
+
+	c := &Completion{
+		ProcedureID:   proc.ID,
+		ProjectID:     proc.ProjectID,
+		ProcedureName: proc.Name,
+		Status:        tr.Status,
+		CompletedAt:   *tr.CompletedAt,
+	}
+	if proc.ParentID != nil {
+		c.ProcedureID = *proc.ParentID
+	}
+	if tr.StartedAt != nil {
+		c.Duration = tr.CompletedAt.Sub(*tr.StartedAt)
+	}
+	if tr.Status == testrun.StatusFailed && tr.FailedStepIndex != nil {
+		step := *tr.FailedStepIndex
+		c.FailedStep = &step
+		if step >= 0 && step < len(proc.Steps) {
+			c.FailedStepName = proc.Steps[step].Name
+		}
+	}
+	return c, nil
+}
+
+// Day returns the UTC day t falls on.
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// WindowStart returns the first day of a window of days ending on the day of
+// now.
+func WindowStart(now time.Time, days int) (time.Time, error) {
+	if days < 1 || days > MaxWindowDays {
+		return time.Time{}, ErrInvalidWindow
+	}
+	return Day(now).AddDate(0, 0, -(days - 1)), nil
+}
+
+// Summary aggregates the runs completed in a report window. Rates and the
+// average duration are omitted when there are no runs to compute them from.
+type Summary struct {
+	Runs    int `json:"runs"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	// PassRate is the share of runs that passed among those that passed or
+	// failed.
+	PassRate *float64 `json:"pass_rate,omitempty"`
+	// FlakinessRate is the share of consecutive passed or failed runs whose
+	// outcomes differ.
+	FlakinessRate          *float64 `json:"flakiness_rate,omitempty"`
+	AverageDurationSeconds *float64 `json:"average_duration_seconds,omitempty"`
+
+	flips           int
+	timedRuns       int
+	durationSeconds float64
+}
+
+func (s *Summary) add(d *DailyStats) {
+	s.Passed += d.Passed
+	s.Failed += d.Failed
+	s.Skipped += d.Skipped
+	s.Runs = s.Passed + s.Failed + s.Skipped
+	s.flips += d.Flips
+	s.timedRuns += d.TimedRuns
+	s.durationSeconds += d.DurationSeconds
+}
+
+// finish computes the rates from the counts.
+func (s *Summary) finish() {
+	decisive := s.Passed + s.Failed
+	if decisive > 0 {
+		rate := float64(s.Passed) / float64(decisive)
+		s.PassRate = &rate
+	}
+	if decisive > 1 {
+		rate := float64(s.flips) / float64(decisive-1)
+		if rate > 1 {
+			rate = 1
+		}
+		s.FlakinessRate = &rate
+	}
+	if s.timedRuns > 0 {
+		avg := s.durationSeconds / float64(s.timedRuns)
+		s.AverageDurationSeconds = &avg
+	}
+}
+
+// StepFailureCount is how often runs failed at a step in a report window.
+type StepFailureCount struct {
+	StepIndex int    `json:"step_index"`
+	StepName  string `json:"step_name"`
+	Failures  int    `json:"failures"`
+}
+
+// ProcedureReport reports on the runs of a procedure, across all of its
+// versions, completed since a day.
+type ProcedureReport struct {
+	ProcedureID          uuid.UUID          `json:"procedure_id"`
+	ProcedureName        string             `json:"procedure_name"`
+	Since                time.Time          `json:"since"`
+	LastStatus           testrun.Status     `json:"last_status,omitempty"`
+	CurrentFailureStreak int                `json:"current_failure_streak"`
+	LongestFailureStreak int                `json:"longest_failure_streak"`
+	MostFailedSteps      []StepFailureCount `json:"most_failed_steps,omitempty"`
+	Summary
+}
+
+// ProjectReport reports on the runs of every procedure in a project
+// completed since a day. Procedures are listed flakiest first.
+type ProjectReport struct {
+	ProjectID  uuid.UUID          `json:"project_id"`
+	Since      time.Time          `json:"since"`
+	Procedures []*ProcedureReport `json:"procedures"`
+	Summary
+}
@@ -0,0 +1,99 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcedureStats_Apply(t *testing.T) {
+	procedureID, projectID := uuid.New(), uuid.New()
+	now := time.Now()
+	stats := &ProcedureStats{ProcedureID: procedureID}
+
+	apply := func(status testrun.Status) bool {
+		return stats.Apply(completion(procedureID, projectID, status, now, 0))
+	}
+
+	assert.False(t, apply(testrun.StatusPassed), "first run is not a flip")
+	assert.True(t, apply(testrun.StatusFailed))
+	assert.False(t, apply(testrun.StatusFailed))
+	assert.False(t, apply(testrun.StatusSkipped), "skipped runs are not outcomes")
+	assert.Equal(t, 2, stats.CurrentFailureStreak, "skipped runs do not break a streak")
+	assert.Equal(t, testrun.StatusSkipped, stats.LastStatus)
+
+	assert.False(t, apply(testrun.StatusFailed))
+	assert.True(t, apply(testrun.StatusPassed))
+	assert.Equal(t, 0, stats.CurrentFailureStreak)
+	assert.Equal(t, 3, stats.LongestFailureStreak)
+	assert.Equal(t, projectID, stats.ProjectID)
+}
+
+func TestNewCompletion(t *testing.T) {
+	rootID := uuid.New()
+	proc := &testprocedure.TestProcedure{
+		ID:        uuid.New(),
+		ParentID:  &rootID,
+		ProjectID: uuid.New(),
+		Name:      "Checkout",
+		Steps: testprocedure.Steps{
+			{Name: "Add to cart"},
+			{Name: "Pay"},
+		},
+	}
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	completed := started.Add(90 * time.Second)
+	step := 1
+
+	t.Run("describes a failed run", func(t *testing.T) {
+		tr := &testrun.TestRun{
+			Status:          testrun.StatusFailed,
+			StartedAt:       &started,
+			CompletedAt:     &completed,
+			FailedStepIndex: &step,
+		}
+		c, err := NewCompletion(tr, proc)
+		require.NoError(t, err)
+		assert.Equal(t, rootID, c.ProcedureID, "versions are recorded under the first version")
+		assert.Equal(t, proc.ProjectID, c.ProjectID)
+		assert.Equal(t, 90*time.Second, c.Duration)
+		require.NotNil(t, c.FailedStep)
+		assert.Equal(t, 1, *c.FailedStep)
+		assert.Equal(t, "Pay", c.FailedStepName)
+	})
+
+	t.Run("ignores the failed step of a passed run", func(t *testing.T) {
+		tr := &testrun.TestRun{Status: testrun.StatusPassed, CompletedAt: &completed, FailedStepIndex: &step}
+		c, err := NewCompletion(tr, proc)
+		require.NoError(t, err)
+		assert.Nil(t, c.FailedStep)
+		assert.Zero(t, c.Duration)
+	})
+
+	t.Run("rejects runs that have not completed", func(t *testing.T) {
+		_, err := NewCompletion(&testrun.TestRun{Status: testrun.StatusRunning, StartedAt: &started}, proc)
+		assert.ErrorIs(t, err, ErrNotCompleted)
+	})
+}
+
+func TestWindowStart(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	start, err := WindowStart(now, 1)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), start)
+
+	start, err = WindowStart(now, 30)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC), start)
+
+	_, err = WindowStart(now, 0)
+	assert.ErrorIs(t, err, ErrInvalidWindow)
+	_, err = WindowStart(now, MaxWindowDays+1)
+	assert.ErrorIs(t, err, ErrInvalidWindow)
+}
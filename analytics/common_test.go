@@ -0,0 +1,40 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+)
+
+// setupTestStore creates a test database and analytics store for testing.
+func setupTestStore(t *testing.T) Store {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &ProcedureStats{}, &DailyStats{}, &StepFailures{})
+
+	return NewMySQLStore(db, logger.NewTestLogger())
+}
+
+// completion creates a completion of a procedure in a project that took
+// duration and completed at.
+func completion(procedureID, projectID uuid.UUID, status testrun.Status, at time.Time, duration time.Duration) *Completion {
+	return &Completion{
+		ProcedureID:   procedureID,
+		ProjectID:     projectID,
+		ProcedureName: "Login flow",
+		Status:        status,
+		CompletedAt:   at,
+		Duration:      duration,
+	}
+}
+
+// failedAt creates a completion of a run that failed at a step.
+func failedAt(procedureID, projectID uuid.UUID, step int, name string, at time.Time) *Completion {
+	c := completion(procedureID, projectID, testrun.StatusFailed, at, 0)
+	c.FailedStep = &step
+	c.FailedStepName = name
+	return c
+}
@@ -0,0 +1,253 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed run analytics store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// RecordCompletion folds a completed run into the aggregates of its
+// procedure.
+func (s *MySQLStore) RecordCompletion(ctx context.Context, c *Completion) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stats ProcedureStats
+		err := tx.Where("procedure_id = ?", c.ProcedureID).First(&stats).Error
+		exists := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		stats.ProcedureID = c.ProcedureID
+		flipped := stats.Apply(c)
+
+		if exists {
+			err = tx.Save(&stats).Error
+		} else {
+			err = tx.Create(&stats).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		day := Day(c.CompletedAt)
+		daily := &DailyStats{ProcedureID: c.ProcedureID, Day: day, ProjectID: c.ProjectID}
+		increments := map[string]interface{}{}
+		increment := func(column string, field *int, by int) {
+			*field = by
+			increments[column] = gorm.Expr(column+" + ?", by)
+		}
+		switch c.Status {
+		case testrun.StatusPassed:
+			increment("passed", &daily.Passed, 1)
+		case testrun.StatusFailed:
+			increment("failed", &daily.Failed, 1)
+		case testrun.StatusSkipped:
+			increment("skipped", &daily.Skipped, 1)
+		}
+		if flipped {
+			increment("flips", &daily.Flips, 1)
+		}
+		if c.Duration > 0 {
+			increment("timed_runs", &daily.TimedRuns, 1)
+			daily.DurationSeconds = c.Duration.Seconds()
+			increments["duration_seconds"] = gorm.Expr("duration_seconds + ?", daily.DurationSeconds)
+		}
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "procedure_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(increments),
+		}).Create(daily).Error
+		if err != nil {
+			return err
+		}
+
+		if c.FailedStep == nil {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "procedure_id"}, {Name: "day"}, {Name: "step_index"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"step_name": c.FailedStepName,
+				"failures":  gorm.Expr("failures + ?", 1),
+			}),
+		}).Create(&StepFailures{
+			ProcedureID: c.ProcedureID,
+			Day:         day,
+			StepIndex:   *c.FailedStep,
+			StepName:    c.FailedStepName,
+			Failures:    1,
+		}).Error
+	})
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to record run analytics", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": c.ProcedureID.String(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// sumDaily sums the daily stats matching a query per procedure.
+func (s *MySQLStore) sumDaily(ctx context.Context, where string, args ...interface{}) ([]*DailyStats, error) {
+	var rows []*DailyStats
+	err := s.db.WithContext(ctx).
+		Model(&DailyStats{}).
+		Select("procedure_id, SUM(passed) AS passed, SUM(failed) AS failed, SUM(skipped) AS skipped, "+
+			"SUM(flips) AS flips, SUM(timed_runs) AS timed_runs, SUM(duration_seconds) AS duration_seconds").
+		Where(where, args...).
+		Group("procedure_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ProcedureReport reports on the runs of a procedure completed on or after
+// since, listing up to topSteps of its most-failed steps.
+func (s *MySQLStore) ProcedureReport(ctx context.Context, procedureID uuid.UUID, since time.Time, topSteps int) (*ProcedureReport, error) {
+	report := &ProcedureReport{ProcedureID: procedureID, Since: since}
+
+	var stats ProcedureStats
+	err := s.db.WithContext(ctx).Where("procedure_id = ?", procedureID).First(&stats).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error(ctx, "failed to get procedure run stats", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": procedureID.String(),
+		})
+		return nil, err
+	}
+	report.setStats(&stats)
+
+	rows, err := s.sumDaily(ctx, "procedure_id = ? AND day >= ?", procedureID, since)
+	if err != nil {
+		s.logger.Error(ctx, "failed to sum procedure run stats", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": procedureID.String(),
+		})
+		return nil, err
+	}
+	for _, row := range rows {
+		report.add(row)
+	}
+	report.finish()
+
+	if topSteps > 0 {
+		err = s.db.WithContext(ctx).
+			Model(&StepFailures{}).
+			Select("step_index, MAX(step_name) AS step_name, SUM(failures) AS failures").
+			Where("procedure_id = ? AND day >= ?", procedureID, since).
+			Group("step_index").
+			Order("failures DESC, step_index ASC").
+			Limit(topSteps).
+			Scan(&report.MostFailedSteps).Error
+		if err != nil {
+			s.logger.Error(ctx, "failed to list most-failed steps", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": procedureID.String(),
+			})
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// ProjectReport reports on the runs of every procedure in a project
+// completed on or after since.
+func (s *MySQLStore) ProjectReport(ctx context.Context, projectID uuid.UUID, since time.Time) (*ProjectReport, error) {
+	report := &ProjectReport{ProjectID: projectID, Since: since, Procedures: []*ProcedureReport{}}
+
+	rows, err := s.sumDaily(ctx, "project_id = ? AND day >= ?", projectID, since)
+	if err != nil {
+		s.logger.Error(ctx, "failed to sum project run stats", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+	if len(rows) == 0 {
+		report.finish()
+		return report, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ProcedureID
+	}
+	var stats []*ProcedureStats
+	if err := s.db.WithContext(ctx).Where("procedure_id IN ?", ids).Find(&stats).Error; err != nil {
+		s.logger.Error(ctx, "failed to list procedure run stats", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+	statsByID := make(map[uuid.UUID]*ProcedureStats, len(stats))
+	for _, st := range stats {
+		statsByID[st.ProcedureID] = st
+	}
+
+	for _, row := range rows {
+		proc := &ProcedureReport{ProcedureID: row.ProcedureID, Since: since}
+		if st, ok := statsByID[row.ProcedureID]; ok {
+			proc.setStats(st)
+		}
+		proc.add(row)
+		proc.finish()
+		report.add(row)
+		report.Procedures = append(report.Procedures, proc)
+	}
+	report.finish()
+	sortFlakiestFirst(report.Procedures)
+
+	return report, nil
+}
+
+func (r *ProcedureReport) setStats(stats *ProcedureStats) {
+	r.ProcedureName = stats.ProcedureName
+	r.LastStatus = stats.LastStatus
+	r.CurrentFailureStreak = stats.CurrentFailureStreak
+	r.LongestFailureStreak = stats.LongestFailureStreak
+}
+
+// sortFlakiestFirst orders procedures by flakiness, then by lowest pass
+// rate, then by name. Procedures without a rate sort last.
+func sortFlakiestFirst(procs []*ProcedureReport) {
+	rate := func(r *float64, missing float64) float64 {
+		if r == nil {
+			return missing
+		}
+		return *r
+	}
+	sort.SliceStable(procs, func(i, j int) bool {
+		a, b := procs[i], procs[j]
+		if fa, fb := rate(a.FlakinessRate, -1), rate(b.FlakinessRate, -1); fa != fb {
+			return fa > fb
+		}
+		if pa, pb := rate(a.PassRate, 2), rate(b.PassRate, 2); pa != pb {
+			return pa < pb
+		}
+		return a.ProcedureName < b.ProcedureName
+	})
+}
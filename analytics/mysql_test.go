@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_ProcedureReport(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	since, err := WindowStart(now, DefaultWindowDays)
+	require.NoError(t, err)
+
+	t.Run("aggregates recorded runs", func(t *testing.T) {
+		procedureID, projectID := uuid.New(), uuid.New()
+		for _, c := range []*Completion{
+			completion(procedureID, projectID, testrun.StatusPassed, now, time.Minute),
+			failedAt(procedureID, projectID, 2, "Pay", now),
+			failedAt(procedureID, projectID, 2, "Pay", now),
+			failedAt(procedureID, projectID, 0, "Open shop", now),
+			completion(procedureID, projectID, testrun.StatusSkipped, now, 0),
+			completion(procedureID, projectID, testrun.StatusPassed, now, 3*time.Minute),
+		} {
+			require.NoError(t, store.RecordCompletion(ctx, c))
+		}
+
+		report, err := store.ProcedureReport(ctx, procedureID, since, DefaultTopSteps)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Login flow", report.ProcedureName)
+		assert.Equal(t, 6, report.Runs)
+		assert.Equal(t, 2, report.Passed)
+		assert.Equal(t, 3, report.Failed)
+		assert.Equal(t, 1, report.Skipped)
+		require.NotNil(t, report.PassRate)
+		assert.InDelta(t, 0.4, *report.PassRate, 0.0001)
+		require.NotNil(t, report.FlakinessRate)
+		assert.InDelta(t, 0.5, *report.FlakinessRate, 0.0001)
+		require.NotNil(t, report.AverageDurationSeconds)
+		assert.InDelta(t, 120, *report.AverageDurationSeconds, 0.0001)
+		assert.Equal(t, 0, report.CurrentFailureStreak)
+		assert.Equal(t, 3, report.LongestFailureStreak)
+		assert.Equal(t, testrun.StatusPassed, report.LastStatus)
+
+		require.Len(t, report.MostFailedSteps, 2)
+		assert.Equal(t, StepFailureCount{StepIndex: 2, StepName: "Pay", Failures: 2}, report.MostFailedSteps[0])
+		assert.Equal(t, 0, report.MostFailedSteps[1].StepIndex)
+	})
+
+	t.Run("excludes runs before the window", func(t *testing.T) {
+		procedureID, projectID := uuid.New(), uuid.New()
+		require.NoError(t, store.RecordCompletion(ctx, failedAt(procedureID, projectID, 0, "Old step", since.AddDate(0, 0, -1))))
+		require.NoError(t, store.RecordCompletion(ctx, completion(procedureID, projectID, testrun.StatusPassed, now, 0)))
+
+		report, err := store.ProcedureReport(ctx, procedureID, since, DefaultTopSteps)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Runs)
+		assert.Empty(t, report.MostFailedSteps)
+		assert.Equal(t, 1, report.LongestFailureStreak, "streaks cover all runs")
+	})
+
+	t.Run("procedure without runs", func(t *testing.T) {
+		report, err := store.ProcedureReport(ctx, uuid.New(), since, DefaultTopSteps)
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.Runs)
+		assert.Nil(t, report.PassRate)
+		assert.Nil(t, report.AverageDurationSeconds)
+	})
+}
+
+func TestMySQLStore_ProjectReport(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	since, err := WindowStart(now, DefaultWindowDays)
+	require.NoError(t, err)
+
+	projectID := uuid.New()
+	stable, flaky := uuid.New(), uuid.New()
+	for _, c := range []*Completion{
+		completion(stable, projectID, testrun.StatusPassed, now, 0),
+		completion(stable, projectID, testrun.StatusPassed, now, 0),
+		completion(flaky, projectID, testrun.StatusPassed, now, 0),
+		completion(flaky, projectID, testrun.StatusFailed, now, 0),
+		completion(uuid.New(), uuid.New(), testrun.StatusFailed, now, 0),
+	} {
+		require.NoError(t, store.RecordCompletion(ctx, c))
+	}
+
+	report, err := store.ProjectReport(ctx, projectID, since)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Runs)
+	assert.Equal(t, 3, report.Passed)
+	require.Len(t, report.Procedures, 2)
+	assert.Equal(t, flaky, report.Procedures[0].ProcedureID, "flakiest procedure first")
+	assert.Equal(t, stable, report.Procedures[1].ProcedureID)
+	require.NotNil(t, report.Procedures[1].PassRate)
+	assert.Equal(t, 1.0, *report.Procedures[1].PassRate)
+
+	t.Run("project without runs", func(t *testing.T) {
+		report, err := store.ProjectReport(ctx, uuid.New(), since)
+		require.NoError(t, err)
+		assert.Empty(t, report.Procedures)
+		assert.Nil(t, report.PassRate)
+	})
+}
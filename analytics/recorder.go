@@ -0,0 +1,49 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Recorder folds runs into the analytics aggregates as they complete.
+// Recording is best-effort: failures are logged and never surface to the
+// caller, so an analytics outage cannot block completing a run. A nil
+// Recorder records nothing.
+type Recorder struct {
+	store  Store
+	logger logger.Logger
+}
+
+// NewRecorder creates a new run analytics recorder.
+func NewRecorder(store Store, log logger.Logger) *Recorder {
+	return &Recorder{
+		store:  store,
+		logger: log,
+	}
+}
+
+// RecordCompletion records a completed run of the procedure version proc.
+func (r *Recorder) RecordCompletion(ctx context.Context, tr *testrun.TestRun, proc *testprocedure.TestProcedure) {
+	if r == nil {
+		return
+	}
+
+	// The run has already completed; it must still be recorded when the
+	// request that completed it has been cancelled.
+	ctx = context.WithoutCancel(ctx)
+
+	c, err := NewCompletion(tr, proc)
+	if err != nil {
+		r.logger.Warn(ctx, "skipping run analytics", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": tr.ID.String(),
+		})
+		return
+	}
+
+	// The store logs its own failures
+	_ = r.store.RecordCompletion(ctx, c)
+}
@@ -0,0 +1,23 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for run analytics persistence operations.
+type Store interface {
+	// RecordCompletion folds a completed run into the aggregates of its
+	// procedure.
+	RecordCompletion(ctx context.Context, c *Completion) error
+
+	// ProcedureReport reports on the runs of a procedure completed on or
+	// after since, listing up to topSteps of its most-failed steps.
+	ProcedureReport(ctx context.Context, procedureID uuid.UUID, since time.Time, topSteps int) (*ProcedureReport, error)
+
+	// ProjectReport reports on the runs of every procedure in a project
+	// completed on or after since.
+	ProjectReport(ctx context.Context, projectID uuid.UUID, since time.Time) (*ProjectReport, error)
+}
@@ -0,0 +1,173 @@
+// Package annotate burns asset annotations (rectangles, arrows and text)
+// into images.
+package annotate
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// ErrUnsupportedFormat is returned for images that are not PNG, JPEG or GIF.
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// textBackground keeps text readable over busy screenshots.
+var textBackground = color.RGBA{255, 255, 255, 220}
+
+// Burn decodes the image read from r, draws the annotations over it and
+// encodes the result to w in the image's original format.
+func Burn(r io.Reader, w io.Writer, annotations []*testrun.AssetAnnotation) error {
+	src, format, err := image.Decode(r)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	img := image.NewRGBA(src.Bounds())
+	draw.Draw(img, img.Bounds(), src, src.Bounds().Min, draw.Src)
+	Draw(img, annotations)
+
+	switch format {
+	case "png":
+		return png.Encode(w, img)
+	case "jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 90})
+	case "gif":
+		return gif.Encode(w, img, nil)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// Draw draws the annotations over img in order. Annotation coordinates are
+// relative to the top-left corner of img; shapes beyond its bounds are
+// clipped.
+func Draw(img draw.Image, annotations []*testrun.AssetAnnotation) {
+	origin := img.Bounds().Min
+	stroke := strokeWidth(img.Bounds())
+
+	for _, a := range annotations {
+		c := parseColor(a.Color)
+		x, y := origin.X+a.X, origin.Y+a.Y
+
+		switch a.Shape {
+		case testrun.AnnotationShapeRectangle:
+			drawRect(img, image.Rect(x, y, x+a.Width, y+a.Height), stroke, c)
+		case testrun.AnnotationShapeArrow:
+			drawArrow(img, x, y, origin.X+a.EndX, origin.Y+a.EndY, stroke, c)
+		case testrun.AnnotationShapeText:
+			drawText(img, x, y, a.Text, stroke, c)
+		}
+	}
+}
+
+// strokeWidth scales lines with the image so they stay visible on large
+// screenshots.
+func strokeWidth(bounds image.Rectangle) int {
+	shorter := bounds.Dx()
+	if bounds.Dy() < shorter {
+		shorter = bounds.Dy()
+	}
+	if w := shorter / 250; w > 2 {
+		return w
+	}
+	return 2
+}
+
+// parseColor parses a #RRGGBB color, falling back to the default annotation
+// color.
+func parseColor(s string) color.RGBA {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil || len(s) != 7 {
+		v, _ = strconv.ParseUint(strings.TrimPrefix(testrun.DefaultAnnotationColor, "#"), 16, 32)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color, op draw.Op) {
+	draw.Draw(img, r.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, op)
+}
+
+// drawRect outlines r with the stroke drawn inside it.
+func drawRect(img draw.Image, r image.Rectangle, stroke int, c color.Color) {
+	fill(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+stroke), c, draw.Src)
+	fill(img, image.Rect(r.Min.X, r.Max.Y-stroke, r.Max.X, r.Max.Y), c, draw.Src)
+	fill(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+stroke, r.Max.Y), c, draw.Src)
+	fill(img, image.Rect(r.Max.X-stroke, r.Min.Y, r.Max.X, r.Max.Y), c, draw.Src)
+}
+
+// drawLine stamps a stroke-sized square at every step from (x0, y0) to
+// (x1, y1).
+func drawLine(img draw.Image, x0, y0, x1, y1, stroke int, c color.Color) {
+	dx, dy := float64(x1-x0), float64(y1-y0)
+	steps := int(math.Max(math.Abs(dx), math.Abs(dy)))
+	half := stroke / 2
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		x := x0 + int(math.Round(dx*t))
+		y := y0 + int(math.Round(dy*t))
+		fill(img, image.Rect(x-half, y-half, x-half+stroke, y-half+stroke), c, draw.Src)
+	}
+}
+
+// drawArrow draws a line from the tail to the head with two barbs at the
+// head.
+func drawArrow(img draw.Image, x0, y0, x1, y1, stroke int, c color.Color) {
+	drawLine(img, x0, y0, x1, y1, stroke, c)
+
+	angle := math.Atan2(float64(y1-y0), float64(x1-x0))
+	barb := math.Max(12, float64(stroke*5))
+	for _, spread := range []float64{math.Pi / 6, -math.Pi / 6} {
+		bx := x1 - int(math.Round(barb*math.Cos(angle+spread)))
+		by := y1 - int(math.Round(barb*math.Sin(angle+spread)))
+		drawLine(img, x1, y1, bx, by, stroke, c)
+	}
+}
+
+// drawText writes text on a light background with its top-left corner at
+// (x, y). Each glyph pixel is drawn as a scale x scale block.
+func drawText(img draw.Image, x, y int, text string, scale int, c color.Color) {
+	lines := strings.Split(text, "\n")
+	advance := (glyphWidth + 1) * scale
+	lineHeight := (glyphHeight + 2) * scale
+	pad := scale
+
+	longest := 0
+	for _, line := range lines {
+		if n := len([]rune(line)); n > longest {
+			longest = n
+		}
+	}
+	box := image.Rect(x, y, x+longest*advance+pad*2-scale, y+len(lines)*lineHeight+pad*2-2*scale)
+	fill(img, box, textBackground, draw.Over)
+
+	for row, line := range lines {
+		top := y + pad + row*lineHeight
+		for i, r := range []rune(line) {
+			left := x + pad + i*advance
+			columns := glyph(r)
+			for col, bits := range columns {
+				for bit := 0; bit < glyphHeight; bit++ {
+					if bits&(1<<bit) == 0 {
+						continue
+					}
+					px, py := left+col*scale, top+bit*scale
+					fill(img, image.Rect(px, py, px+scale, py+scale), c, draw.Src)
+				}
+			}
+		}
+	}
+}
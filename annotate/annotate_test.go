@@ -0,0 +1,115 @@
+package annotate
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	white = color.RGBA{255, 255, 255, 255}
+	red   = color.RGBA{255, 0, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+)
+
+// blankImage creates a white w x h image.
+func blankImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+	return img
+}
+
+func TestDraw(t *testing.T) {
+	t.Run("rectangle outline", func(t *testing.T) {
+		img := blankImage(100, 100)
+		Draw(img, []*testrun.AssetAnnotation{
+			{Shape: testrun.AnnotationShapeRectangle, X: 10, Y: 20, Width: 30, Height: 40, Color: "#FF0000"},
+		})
+
+		assert.Equal(t, red, img.RGBAAt(10, 20))
+		assert.Equal(t, red, img.RGBAAt(39, 59))
+		assert.Equal(t, red, img.RGBAAt(25, 21))
+		assert.Equal(t, white, img.RGBAAt(25, 40), "inside is not filled")
+		assert.Equal(t, white, img.RGBAAt(40, 60), "outside is untouched")
+	})
+
+	t.Run("arrow line and head", func(t *testing.T) {
+		img := blankImage(100, 100)
+		Draw(img, []*testrun.AssetAnnotation{
+			{Shape: testrun.AnnotationShapeArrow, X: 10, Y: 50, EndX: 80, EndY: 50, Color: "#0000FF"},
+		})
+
+		assert.Equal(t, blue, img.RGBAAt(10, 50))
+		assert.Equal(t, blue, img.RGBAAt(45, 50))
+		assert.Equal(t, blue, img.RGBAAt(80, 50))
+		// The barbs point back from the head above and below the line.
+		assert.Equal(t, blue, img.RGBAAt(75, 47))
+		assert.Equal(t, blue, img.RGBAAt(75, 53))
+		assert.Equal(t, white, img.RGBAAt(45, 60))
+	})
+
+	t.Run("text on background", func(t *testing.T) {
+		img := blankImage(100, 40)
+		Draw(img, []*testrun.AssetAnnotation{
+			{Shape: testrun.AnnotationShapeText, X: 0, Y: 0, Text: "I", Color: "#FF0000"},
+		})
+
+		// The vertical stroke of I is the middle column of the glyph.
+		assert.Equal(t, red, img.RGBAAt(2+2*2, 2+3*2))
+		assert.Equal(t, white, img.RGBAAt(50, 30))
+	})
+
+	t.Run("shapes beyond the image are clipped", func(t *testing.T) {
+		img := blankImage(20, 20)
+		Draw(img, []*testrun.AssetAnnotation{
+			{Shape: testrun.AnnotationShapeRectangle, X: 10, Y: 10, Width: 500, Height: 500},
+			{Shape: testrun.AnnotationShapeText, X: 15, Y: 15, Text: "overflowing"},
+		})
+		assert.Equal(t, red, img.RGBAAt(10, 10))
+	})
+}
+
+func TestBurn(t *testing.T) {
+	annotations := []*testrun.AssetAnnotation{
+		{Shape: testrun.AnnotationShapeRectangle, X: 0, Y: 0, Width: 10, Height: 10, Color: "#FF0000"},
+	}
+
+	t.Run("png stays png", func(t *testing.T) {
+		var src bytes.Buffer
+		require.NoError(t, png.Encode(&src, blankImage(20, 20)))
+
+		var out bytes.Buffer
+		require.NoError(t, Burn(&src, &out, annotations))
+
+		img, format, err := image.Decode(&out)
+		require.NoError(t, err)
+		assert.Equal(t, "png", format)
+		r, g, b, _ := img.At(0, 0).RGBA()
+		assert.Equal(t, []uint32{0xffff, 0, 0}, []uint32{r, g, b})
+	})
+
+	t.Run("jpeg stays jpeg", func(t *testing.T) {
+		var src bytes.Buffer
+		require.NoError(t, jpeg.Encode(&src, blankImage(20, 20), nil))
+
+		var out bytes.Buffer
+		require.NoError(t, Burn(&src, &out, annotations))
+
+		_, format, err := image.Decode(&out)
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.Error(t, Burn(bytes.NewReader([]byte("not an image")), &out, annotations))
+	})
+}
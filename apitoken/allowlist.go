@@ -0,0 +1,100 @@
+package apitoken
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// MaxAllowedCIDRs is the most CIDR ranges a token's allowlist can hold.
+const MaxAllowedCIDRs = 20
+
+var (
+	ErrInvalidCIDR  = errors.New("invalid CIDR range")
+	ErrTooManyCIDRs = errors.New("too many CIDR ranges")
+	ErrIPNotAllowed = errors.New("api token is not allowed from this IP address")
+)
+
+// CIDRs is the allowlist of a token: the IP ranges it may be used from. An
+// empty allowlist allows every address.
+type CIDRs []string
+
+// ParseCIDRs validates and normalizes CIDR ranges. A bare IP address is a
+// range of one address. The result is sorted and without duplicates.
+func ParseCIDRs(ranges []string) (CIDRs, error) {
+	if len(ranges) > MaxAllowedCIDRs {
+		return nil, ErrTooManyCIDRs
+	}
+	cidrs := make(CIDRs, 0, len(ranges))
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+		prefix, err := netip.ParsePrefix(r)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(r)
+			if addrErr != nil {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidCIDR, r)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		cidrs = append(cidrs, prefix.Masked().String())
+	}
+	slices.Sort(cidrs)
+	return slices.Compact(cidrs), nil
+}
+
+// Allows reports whether ip is in one of the ranges. An empty allowlist
+// allows every address; otherwise an unparseable address is never allowed.
+func (c CIDRs) Allows(ip string) bool {
+	if len(c) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, cidr := range c {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value implements the driver.Valuer interface for database storage.
+func (c CIDRs) Value() (driver.Value, error) {
+	if c == nil {
+		return json.Marshal([]string{})
+	}
+	return json.Marshal([]string(c))
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (c *CIDRs) Scan(value interface{}) error {
+	if value == nil {
+		*c = CIDRs{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("failed to scan CIDRs: not a byte slice")
+	}
+
+	var cidrs []string
+	if err := json.Unmarshal(bytes, &cidrs); err != nil {
+		return err
+	}
+	*c = cidrs
+	return nil
+}
@@ -0,0 +1,86 @@
+package apitoken
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("normalizes ranges", func(t *testing.T) {
+		t.Parallel()
+		got, err := ParseCIDRs([]string{" 10.1.2.3/8 ", "192.0.2.7", "2001:db8::1/32", "10.0.0.0/8"})
+		if err != nil {
+			t.Fatalf("ParseCIDRs() error = %v", err)
+		}
+		want := CIDRs{"10.0.0.0/8", "192.0.2.7/32", "2001:db8::/32"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseCIDRs() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		got, err := ParseCIDRs(nil)
+		if err != nil {
+			t.Fatalf("ParseCIDRs() error = %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("ParseCIDRs(nil) = %v, want empty", got)
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		t.Parallel()
+		if _, err := ParseCIDRs([]string{"10.0.0.0/33"}); !errors.Is(err, ErrInvalidCIDR) {
+			t.Errorf("ParseCIDRs() error = %v, want %v", err, ErrInvalidCIDR)
+		}
+		if _, err := ParseCIDRs([]string{"example.com"}); !errors.Is(err, ErrInvalidCIDR) {
+			t.Errorf("ParseCIDRs() error = %v, want %v", err, ErrInvalidCIDR)
+		}
+	})
+
+	t.Run("too many ranges", func(t *testing.T) {
+		t.Parallel()
+		ranges := make([]string, MaxAllowedCIDRs+1)
+		for i := range ranges {
+			ranges[i] = fmt.Sprintf("10.0.%d.0/24", i)
+		}
+		if _, err := ParseCIDRs(ranges); !errors.Is(err, ErrTooManyCIDRs) {
+			t.Errorf("ParseCIDRs() error = %v, want %v", err, ErrTooManyCIDRs)
+		}
+	})
+}
+
+func TestCIDRsAllows(t *testing.T) {
+	t.Parallel()
+
+	cidrs := CIDRs{"10.0.0.0/8", "192.0.2.7/32", "2001:db8::/32"}
+
+	tests := []struct {
+		name     string
+		cidrs    CIDRs
+		ip       string
+		expected bool
+	}{
+		{name: "empty allowlist allows all", cidrs: nil, ip: "203.0.113.1", expected: true},
+		{name: "in range", cidrs: cidrs, ip: "10.20.30.40", expected: true},
+		{name: "single address", cidrs: cidrs, ip: "192.0.2.7", expected: true},
+		{name: "IPv4-mapped IPv6", cidrs: cidrs, ip: "::ffff:10.0.0.1", expected: true},
+		{name: "IPv6 in range", cidrs: cidrs, ip: "2001:db8::42", expected: true},
+		{name: "out of range", cidrs: cidrs, ip: "192.0.2.8", expected: false},
+		{name: "unparseable address", cidrs: cidrs, ip: "unknown", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.cidrs.Allows(tt.ip); got != tt.expected {
+				t.Errorf("Allows(%q) = %v, want %v", tt.ip, got, tt.expected)
+			}
+		})
+	}
+}
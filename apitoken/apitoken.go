@@ -29,6 +29,10 @@ const (
 	DefaultExpiry = 30 * 24 * time.Hour  // 1 month
 	MinExpiry     = 24 * time.Hour       // 1 day
 	MaxExpiry     = 365 * 24 * time.Hour // 1 year
+
+	// UsageInterval is how often the last use of a token is recorded. A
+	// token used again from the same IP sooner is not written to again.
+	UsageInterval = time.Minute
 )
 
 // APIToken represents an API token for programmatic access.
@@ -40,8 +44,12 @@ type APIToken struct {
 	Scope     string    `json:"scope" gorm:"type:varchar(20);not null;default:read_only"`
 	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
 	IsActive  bool      `json:"is_active" gorm:"not null;default:true"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	// AllowedCIDRs restricts the IP addresses the token can be used from.
+	AllowedCIDRs CIDRs      `json:"allowed_cidrs" gorm:"column:allowed_cidrs;type:json"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	LastUsedIP   string     `json:"last_used_ip" gorm:"type:varchar(45);not null;default:''"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
 // TableName returns the database table name.
@@ -79,6 +87,13 @@ func (t *APIToken) IsExpired() bool {
 	return time.Now().After(t.ExpiresAt)
 }
 
+// NeedsUsageRecorded reports whether a use of the token from ip at now
+// should be recorded: the first use, a use from another IP, or one more
+// than UsageInterval after the last recorded use.
+func (t *APIToken) NeedsUsageRecorded(ip string, now time.Time) bool {
+	return t.LastUsedAt == nil || t.LastUsedIP != ip || now.Sub(*t.LastUsedAt) >= UsageInterval
+}
+
 // GenerateToken creates a new random token with the uat_ prefix.
 // Returns the raw token string and its SHA-256 hash.
 func GenerateToken() (rawToken string, hash string, err error) {
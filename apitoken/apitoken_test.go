@@ -112,3 +112,52 @@ func TestValidateExpiry(t *testing.T) {
 		})
 	}
 }
+
+func TestNeedsUsageRecorded(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	recent := now.Add(-10 * time.Second)
+	stale := now.Add(-UsageInterval)
+
+	tests := []struct {
+		name     string
+		token    APIToken
+		ip       string
+		expected bool
+	}{
+		{
+			name:     "never used",
+			token:    APIToken{},
+			ip:       "192.0.2.1",
+			expected: true,
+		},
+		{
+			name:     "used recently from the same IP",
+			token:    APIToken{LastUsedAt: &recent, LastUsedIP: "192.0.2.1"},
+			ip:       "192.0.2.1",
+			expected: false,
+		},
+		{
+			name:     "used recently from another IP",
+			token:    APIToken{LastUsedAt: &recent, LastUsedIP: "192.0.2.1"},
+			ip:       "192.0.2.2",
+			expected: true,
+		},
+		{
+			name:     "used an interval ago",
+			token:    APIToken{LastUsedAt: &stale, LastUsedIP: "192.0.2.1"},
+			ip:       "192.0.2.1",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.token.NeedsUsageRecorded(tt.ip, now); got != tt.expected {
+				t.Errorf("NeedsUsageRecorded(%q) = %v, want %v", tt.ip, got, tt.expected)
+			}
+		})
+	}
+}
@@ -156,6 +156,85 @@ func (s *MySQLStore) Revoke(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// RevokeByUser sets is_active to false on every active token of a user.
+func (s *MySQLStore) RevokeByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	result := s.db.WithContext(ctx).
+		Model(&APIToken{}).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Update("is_active", false)
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to revoke api tokens of user", map[string]interface{}{
+			"error":   result.Error.Error(),
+			"user_id": userID.String(),
+		})
+		return 0, result.Error
+	}
+
+	s.logger.Info(ctx, "api tokens of user revoked", map[string]interface{}{
+		"user_id": userID.String(),
+		"count":   result.RowsAffected,
+	})
+
+	return int(result.RowsAffected), nil
+}
+
+// RecordUsage sets the last use of a token.
+func (s *MySQLStore) RecordUsage(ctx context.Context, id uuid.UUID, ip string, at time.Time) error {
+	result := s.db.WithContext(ctx).
+		Model(&APIToken{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"last_used_at": at,
+			"last_used_ip": ip,
+		})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to record api token usage", map[string]interface{}{
+			"error":    result.Error.Error(),
+			"token_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// SetAllowedCIDRs replaces the IP allowlist of a token.
+func (s *MySQLStore) SetAllowedCIDRs(ctx context.Context, id uuid.UUID, cidrs CIDRs) error {
+	if len(cidrs) > MaxAllowedCIDRs {
+		return ErrTooManyCIDRs
+	}
+
+	result := s.db.WithContext(ctx).
+		Model(&APIToken{}).
+		Where("id = ?", id).
+		Update("allowed_cidrs", cidrs)
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to set api token allowlist", map[string]interface{}{
+			"error":    result.Error.Error(),
+			"token_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTokenNotFound
+	}
+
+	s.logger.Info(ctx, "api token allowlist updated", map[string]interface{}{
+		"token_id": id.String(),
+		"count":    len(cidrs),
+	})
+
+	return nil
+}
+
 // Delete hard-deletes a token.
 func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
 	result := s.db.WithContext(ctx).
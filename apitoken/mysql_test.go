@@ -355,3 +355,141 @@ func TestRevoke(t *testing.T) {
 		}
 	})
 }
+
+func TestRevokeByUser(t *testing.T) {
+	t.Parallel()
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	var hashes []string
+	for _, name := range []string{"first", "second"} {
+		_, hash, _ := GenerateToken()
+		hashes = append(hashes, hash)
+		store.Create(ctx, &APIToken{
+			UserID:    userID,
+			Name:      name,
+			TokenHash: hash,
+			Scope:     ScopeReadOnly,
+			ExpiresAt: time.Now().Add(DefaultExpiry),
+			IsActive:  true,
+		})
+	}
+	_, otherHash, _ := GenerateToken()
+	store.Create(ctx, &APIToken{
+		UserID:    uuid.New(),
+		Name:      "other",
+		TokenHash: otherHash,
+		Scope:     ScopeReadOnly,
+		ExpiresAt: time.Now().Add(DefaultExpiry),
+		IsActive:  true,
+	})
+
+	count, err := store.RevokeByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("RevokeByUser() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("RevokeByUser() = %d, want 2", count)
+	}
+
+	for _, hash := range hashes {
+		if _, err := store.GetByTokenHash(ctx, hash); err != ErrTokenNotFound {
+			t.Errorf("GetByTokenHash() after revoke: error = %v, want %v", err, ErrTokenNotFound)
+		}
+	}
+	if _, err := store.GetByTokenHash(ctx, otherHash); err != nil {
+		t.Errorf("GetByTokenHash() of another user's token: error = %v", err)
+	}
+
+	count, err = store.RevokeByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("RevokeByUser() again error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("RevokeByUser() again = %d, want 0", count)
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	t.Parallel()
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	_, hash, _ := GenerateToken()
+	token := &APIToken{
+		UserID:    uuid.New(),
+		Name:      "ci",
+		TokenHash: hash,
+		Scope:     ScopeReadOnly,
+		ExpiresAt: time.Now().Add(DefaultExpiry),
+		IsActive:  true,
+	}
+	store.Create(ctx, token)
+
+	at := time.Now().Truncate(time.Second)
+	if err := store.RecordUsage(ctx, token.ID, "192.0.2.1", at); err != nil {
+		t.Fatalf("RecordUsage() error = %v", err)
+	}
+
+	found, err := store.GetByTokenHash(ctx, hash)
+	if err != nil {
+		t.Fatalf("GetByTokenHash() error = %v", err)
+	}
+	if found.LastUsedAt == nil || !found.LastUsedAt.Equal(at) {
+		t.Errorf("LastUsedAt = %v, want %v", found.LastUsedAt, at)
+	}
+	if found.LastUsedIP != "192.0.2.1" {
+		t.Errorf("LastUsedIP = %q, want %q", found.LastUsedIP, "192.0.2.1")
+	}
+
+	if err := store.RecordUsage(ctx, uuid.New(), "192.0.2.1", at); err != ErrTokenNotFound {
+		t.Errorf("RecordUsage() of unknown token: error = %v, want %v", err, ErrTokenNotFound)
+	}
+}
+
+func TestSetAllowedCIDRs(t *testing.T) {
+	t.Parallel()
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	_, hash, _ := GenerateToken()
+	token := &APIToken{
+		UserID:       uuid.New(),
+		Name:         "ci",
+		TokenHash:    hash,
+		Scope:        ScopeReadOnly,
+		AllowedCIDRs: CIDRs{"10.0.0.0/8"},
+		ExpiresAt:    time.Now().Add(DefaultExpiry),
+		IsActive:     true,
+	}
+	store.Create(ctx, token)
+
+	found, err := store.GetByID(ctx, token.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if len(found.AllowedCIDRs) != 1 || found.AllowedCIDRs[0] != "10.0.0.0/8" {
+		t.Errorf("AllowedCIDRs after create = %v, want [10.0.0.0/8]", found.AllowedCIDRs)
+	}
+
+	if err := store.SetAllowedCIDRs(ctx, token.ID, CIDRs{"192.0.2.0/24", "198.51.100.7/32"}); err != nil {
+		t.Fatalf("SetAllowedCIDRs() error = %v", err)
+	}
+	found, _ = store.GetByID(ctx, token.ID)
+	if len(found.AllowedCIDRs) != 2 || found.AllowedCIDRs[0] != "192.0.2.0/24" {
+		t.Errorf("AllowedCIDRs = %v, want [192.0.2.0/24 198.51.100.7/32]", found.AllowedCIDRs)
+	}
+
+	if err := store.SetAllowedCIDRs(ctx, token.ID, CIDRs{}); err != nil {
+		t.Fatalf("SetAllowedCIDRs() to empty error = %v", err)
+	}
+	found, _ = store.GetByID(ctx, token.ID)
+	if len(found.AllowedCIDRs) != 0 {
+		t.Errorf("AllowedCIDRs after clearing = %v, want empty", found.AllowedCIDRs)
+	}
+
+	if err := store.SetAllowedCIDRs(ctx, uuid.New(), CIDRs{}); err != ErrTokenNotFound {
+		t.Errorf("SetAllowedCIDRs() of unknown token: error = %v, want %v", err, ErrTokenNotFound)
+	}
+}
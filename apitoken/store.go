@@ -2,6 +2,7 @@ package apitoken
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -26,6 +27,16 @@ type Store interface {
 	// Revoke sets a token's is_active to false.
 	Revoke(ctx context.Context, id uuid.UUID) error
 
+	// RevokeByUser sets is_active to false on every active token of a user,
+	// returning how many were revoked.
+	RevokeByUser(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// RecordUsage sets the last use of a token.
+	RecordUsage(ctx context.Context, id uuid.UUID, ip string, at time.Time) error
+
+	// SetAllowedCIDRs replaces the IP allowlist of a token.
+	SetAllowedCIDRs(ctx context.Context, id uuid.UUID, cidrs CIDRs) error
+
 	// Delete hard-deletes a token.
 	Delete(ctx context.Context, id uuid.UUID) error
 }
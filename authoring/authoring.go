@@ -0,0 +1,152 @@
+// Package authoring drafts test procedures from a plain-English description
+// of a user flow: a model turns the description into named steps with
+// instructions, which are saved as a procedure flagged for review.
+package authoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+const (
+	// MaxDescriptionLength is the longest flow description an author can give.
+	MaxDescriptionLength = 5000
+
+	// MaxSteps is the most steps kept from one drafted procedure.
+	MaxSteps = 50
+)
+
+var (
+	// ErrDescriptionRequired is returned when a request has no description.
+	ErrDescriptionRequired = errors.New("a description of the flow is required")
+
+	// ErrDescriptionTooLong is returned when the description exceeds
+	// MaxDescriptionLength.
+	ErrDescriptionTooLong = fmt.Errorf("description must be at most %d characters", MaxDescriptionLength)
+
+	// ErrNoProcedure is returned when the model drafted no usable procedure.
+	ErrNoProcedure = errors.New("no procedure could be drafted from this description")
+)
+
+// Request is a description of a flow to draft a procedure for. Name, if
+// set, is used instead of the name the model proposes, and BaseURL tells
+// the model where the application under test lives.
+type Request struct {
+	Description string
+	Name        string
+	BaseURL     string
+}
+
+// Validate checks the request before it is sent to the model.
+func (r Request) Validate() error {
+	if strings.TrimSpace(r.Description) == "" {
+		return ErrDescriptionRequired
+	}
+	if len([]rune(r.Description)) > MaxDescriptionLength {
+		return ErrDescriptionTooLong
+	}
+	return nil
+}
+
+// DraftStep is a step of a drafted procedure.
+type DraftStep struct {
+	Name         string `json:"name"`
+	Instructions string `json:"instructions"`
+}
+
+// Draft is a procedure drafted by the model.
+type Draft struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Steps       []DraftStep `json:"steps"`
+}
+
+// Procedure converts the draft into a review-flagged procedure of projectID,
+// checked with the same validation and limits as procedures sent for script
+// generation. Invalid drafts are reported as ErrNoProcedure.
+func (d Draft) Procedure(projectID, createdBy uuid.UUID, limits testprocedure.ValidationLimits) (*testprocedure.TestProcedure, error) {
+	steps := make(testprocedure.Steps, 0, len(d.Steps))
+	for _, s := range d.Steps {
+		steps = append(steps, testprocedure.TestStep{
+			Name:         s.Name,
+			Instructions: s.Instructions,
+			ImagePaths:   []string{},
+		})
+	}
+	tp := &testprocedure.TestProcedure{
+		ProjectID:   projectID,
+		Name:        d.Name,
+		Description: d.Description,
+		Steps:       steps,
+		CreatedBy:   createdBy,
+		NeedsReview: true,
+	}
+
+	if err := tp.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoProcedure, err)
+	}
+	switch {
+	case len(tp.Name) > limits.MaxNameLength:
+		return nil, fmt.Errorf("%w: %v", ErrNoProcedure, testprocedure.ErrNameTooLong)
+	case len(tp.Description) > limits.MaxDescriptionLength:
+		return nil, fmt.Errorf("%w: %v", ErrNoProcedure, testprocedure.ErrDescriptionTooLong)
+	case len(tp.Steps) > limits.MaxStepsCount:
+		return nil, fmt.Errorf("%w: %v", ErrNoProcedure, testprocedure.ErrTooManySteps)
+	}
+	return tp, nil
+}
+
+// Author drafts procedures from descriptions of flows.
+// Implementations can use different backends (AWS Bedrock, OpenAI, etc.)
+type Author interface {
+	// Draft returns the procedure the model drafts for the request. It
+	// returns ErrNoProcedure when the reply has no usable steps.
+	Draft(ctx context.Context, req Request) (*Draft, error)
+}
+
+// ParseDraft extracts the drafted procedure from the model's reply, which
+// should be a JSON object, possibly wrapped in a code fence or prose. Steps
+// without a name or instructions are dropped, and at most MaxSteps are
+// kept. name, if set, replaces the drafted name.
+func ParseDraft(reply, name string) (*Draft, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, ErrNoProcedure
+	}
+
+	var raw Draft
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse drafted procedure: %w", err)
+	}
+
+	draft := &Draft{
+		Name:        strings.TrimSpace(raw.Name),
+		Description: strings.TrimSpace(raw.Description),
+		Steps:       make([]DraftStep, 0, len(raw.Steps)),
+	}
+	if name = strings.TrimSpace(name); name != "" {
+		draft.Name = name
+	}
+	for _, s := range raw.Steps {
+		s.Name = strings.TrimSpace(s.Name)
+		s.Instructions = strings.TrimSpace(s.Instructions)
+		if s.Name == "" || s.Instructions == "" {
+			continue
+		}
+		draft.Steps = append(draft.Steps, s)
+		if len(draft.Steps) == MaxSteps {
+			break
+		}
+	}
+	if draft.Name == "" || len(draft.Steps) == 0 {
+		return nil, ErrNoProcedure
+	}
+	return draft, nil
+}
@@ -0,0 +1,91 @@
+package authoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+)
+
+// BedrockAuthor implements Author using a model on AWS Bedrock.
+type BedrockAuthor struct {
+	client    *bedrockruntime.Client
+	modelID   string
+	maxTokens int
+}
+
+// NewBedrockAuthor creates a new Bedrock-based procedure author.
+func NewBedrockAuthor(region, modelID string, maxTokens int) (*BedrockAuthor, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &BedrockAuthor{
+		client:    bedrockruntime.NewFromConfig(cfg),
+		modelID:   modelID,
+		maxTokens: maxTokens,
+	}, nil
+}
+
+// Draft sends the description to the model and parses the procedure it
+// drafts.
+func (a *BedrockAuthor) Draft(ctx context.Context, req Request) (*Draft, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	requestBody := map[string]interface{}{
+		"anthropic_version": "bedrock-2023-05-31",
+		"max_tokens":        a.maxTokens,
+		"messages": []map[string]interface{}{
+			{
+				"role": "user",
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": BuildPrompt(req),
+					},
+				},
+			},
+		},
+	}
+
+	payloadBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	output, err := a.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(a.modelID),
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+		Body:        payloadBytes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke Bedrock model: %w", err)
+	}
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage llmusage.Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(output.Body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	llmusage.Add(ctx, response.Usage)
+	if len(response.Content) == 0 {
+		return nil, ErrNoProcedure
+	}
+
+	return ParseDraft(response.Content[0].Text, req.Name)
+}
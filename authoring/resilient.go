@@ -0,0 +1,47 @@
+package authoring
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+)
+
+// ResilientAuthor wraps an Author so that model calls run under a circuit
+// breaker with a per-call deadline.
+type ResilientAuthor struct {
+	author  Author
+	breaker *resilience.Breaker
+}
+
+// NewResilientAuthor wraps author with the given breaker.
+func NewResilientAuthor(author Author, breaker *resilience.Breaker) *ResilientAuthor {
+	return &ResilientAuthor{
+		author:  author,
+		breaker: breaker,
+	}
+}
+
+// Draft drafts a procedure through the breaker. Invalid requests and
+// replies without a usable procedure do not count against the model.
+func (a *ResilientAuthor) Draft(ctx context.Context, req Request) (*Draft, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var draft *Draft
+	var empty error
+	err := a.breaker.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		draft, err = a.author.Draft(ctx, req)
+		if errors.Is(err, ErrNoProcedure) {
+			empty = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return draft, empty
+}
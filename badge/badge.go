@@ -0,0 +1,197 @@
+// Package badge renders the status of projects and test procedures as SVG
+// badges, like those of CI services, for embedding in READMEs and wikis.
+package badge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+var (
+	// ErrInvalidMetric is returned when a metric is not status or
+	// pass_rate.
+	ErrInvalidMetric = errors.New("metric must be status or pass_rate")
+
+	// ErrInvalidLabel is returned when a label is too long.
+	ErrInvalidLabel = fmt.Errorf("label must be at most %d characters", MaxLabelLength)
+)
+
+// MaxLabelLength bounds the label of a badge.
+const MaxLabelLength = 64
+
+// Metric is what a badge shows.
+type Metric string
+
+const (
+	// MetricStatus shows whether the latest runs passed.
+	MetricStatus Metric = "status"
+
+	// MetricPassRate shows the share of runs that passed.
+	MetricPassRate Metric = "pass_rate"
+)
+
+// ParseMetric parses a metric, returning MetricStatus for an empty string.
+func ParseMetric(s string) (Metric, error) {
+	switch Metric(s) {
+	case "":
+		return MetricStatus, nil
+	case MetricStatus, MetricPassRate:
+		return Metric(s), nil
+	}
+	return "", ErrInvalidMetric
+}
+
+// DefaultLabel returns the label of a badge of the metric when none is
+// given.
+func (m Metric) DefaultLabel() string {
+	if m == MetricPassRate {
+		return "pass rate"
+	}
+	return "tests"
+}
+
+// ParseLabel checks a badge label, returning the metric's default label for
+// an empty string.
+func ParseLabel(s string, metric Metric) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return metric.DefaultLabel(), nil
+	}
+	if len([]rune(s)) > MaxLabelLength {
+		return "", ErrInvalidLabel
+	}
+	return s, nil
+}
+
+// Colors of badge messages.
+const (
+	ColorGreen       = "#4c1"
+	ColorYellowGreen = "#a4a61d"
+	ColorYellow      = "#dfb317"
+	ColorRed         = "#e05d44"
+	ColorGrey        = "#9f9f9f"
+
+	labelColor = "#555"
+)
+
+// Badge is a flat badge: a grey label next to a colored message.
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// Status is the badge of a run status. The status of a project or
+// procedure without completed runs is empty.
+func Status(label string, status testrun.Status) Badge {
+	switch status {
+	case testrun.StatusPassed:
+		return Badge{Label: label, Message: "passing", Color: ColorGreen}
+	case testrun.StatusFailed:
+		return Badge{Label: label, Message: "failing", Color: ColorRed}
+	case "":
+		return Badge{Label: label, Message: "no runs", Color: ColorGrey}
+	}
+	return Badge{Label: label, Message: string(status), Color: ColorGrey}
+}
+
+// PassRate is the badge of a pass rate between 0 and 1, or nil without
+// passed or failed runs.
+func PassRate(label string, rate *float64) Badge {
+	if rate == nil {
+		return Badge{Label: label, Message: "no runs", Color: ColorGrey}
+	}
+
+	percent := math.Floor(*rate * 100)
+	color := ColorRed
+	switch {
+	case percent >= 90:
+		color = ColorGreen
+	case percent >= 75:
+		color = ColorYellowGreen
+	case percent >= 50:
+		color = ColorYellow
+	}
+	return Badge{Label: label, Message: fmt.Sprintf("%.0f%%", percent), Color: color}
+}
+
+// CombinedStatus is the status of a project from the latest statuses of its
+// procedures: failed if any failed, otherwise passed if any passed,
+// otherwise skipped if any were skipped, and empty without any.
+func CombinedStatus(statuses []testrun.Status) testrun.Status {
+	var combined testrun.Status
+	for _, status := range statuses {
+		switch {
+		case status == testrun.StatusFailed:
+			return testrun.StatusFailed
+		case status == testrun.StatusPassed:
+			combined = testrun.StatusPassed
+		case status == testrun.StatusSkipped && combined == "":
+			combined = testrun.StatusSkipped
+		}
+	}
+	return combined
+}
+
+// SVG renders the badge.
+func (b Badge) SVG() []byte {
+	labelWidth := textWidth(b.Label) + 10
+	messageWidth := textWidth(b.Message) + 10
+	width := labelWidth + messageWidth
+	title := escape(b.Label + ": " + b.Message)
+	label := escape(b.Label)
+	message := escape(b.Message)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s">`, width, title)
+	fmt.Fprintf(&buf, `<title>%s</title>`, title)
+	buf.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&buf, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, width)
+	fmt.Fprintf(&buf, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="%s"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		labelWidth, labelColor, labelWidth, messageWidth, escape(b.Color), width)
+	buf.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	writeText(&buf, float64(labelWidth)/2, label)
+	writeText(&buf, float64(labelWidth)+float64(messageWidth)/2, message)
+	buf.WriteString(`</g></svg>`)
+	return buf.Bytes()
+}
+
+// writeText writes text centered on x, over its shadow.
+func writeText(buf *bytes.Buffer, x float64, text string) {
+	fmt.Fprintf(buf, `<text x="%.1f" y="15" fill="#010101" fill-opacity=".3">%s</text>`, x, text)
+	fmt.Fprintf(buf, `<text x="%.1f" y="14">%s</text>`, x, text)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// textWidth approximates the width in pixels of text in 11px Verdana.
+func textWidth(s string) int {
+	width := 0.0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("iljI.,:;'!|", r):
+			width += 3.5
+		case strings.ContainsRune("mwMW@%", r):
+			width += 10.5
+		case r == ' ':
+			width += 3.9
+		case r >= 'A' && r <= 'Z':
+			width += 7.5
+		case r >= '0' && r <= '9':
+			width += 7
+		default:
+			width += 6.5
+		}
+	}
+	return int(math.Ceil(width))
+}
@@ -0,0 +1,111 @@
+package badge
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetric(t *testing.T) {
+	metric, err := ParseMetric("")
+	require.NoError(t, err)
+	assert.Equal(t, MetricStatus, metric)
+
+	metric, err = ParseMetric("pass_rate")
+	require.NoError(t, err)
+	assert.Equal(t, MetricPassRate, metric)
+
+	_, err = ParseMetric("coverage")
+	assert.ErrorIs(t, err, ErrInvalidMetric)
+}
+
+func TestParseLabel(t *testing.T) {
+	label, err := ParseLabel("", MetricPassRate)
+	require.NoError(t, err)
+	assert.Equal(t, "pass rate", label)
+
+	label, err = ParseLabel(" checkout ", MetricStatus)
+	require.NoError(t, err)
+	assert.Equal(t, "checkout", label)
+
+	_, err = ParseLabel(strings.Repeat("a", MaxLabelLength+1), MetricStatus)
+	assert.ErrorIs(t, err, ErrInvalidLabel)
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		status  testrun.Status
+		message string
+		color   string
+	}{
+		{status: testrun.StatusPassed, message: "passing", color: ColorGreen},
+		{status: testrun.StatusFailed, message: "failing", color: ColorRed},
+		{status: testrun.StatusSkipped, message: "skipped", color: ColorGrey},
+		{status: "", message: "no runs", color: ColorGrey},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			b := Status("tests", tt.status)
+			assert.Equal(t, tt.message, b.Message)
+			assert.Equal(t, tt.color, b.Color)
+		})
+	}
+}
+
+func TestPassRate(t *testing.T) {
+	rate := func(r float64) *float64 { return &r }
+	tests := []struct {
+		name    string
+		rate    *float64
+		message string
+		color   string
+	}{
+		{name: "all passed", rate: rate(1), message: "100%", color: ColorGreen},
+		{name: "rounded down", rate: rate(0.899), message: "89%", color: ColorYellowGreen},
+		{name: "half", rate: rate(0.5), message: "50%", color: ColorYellow},
+		{name: "mostly failing", rate: rate(0.2), message: "20%", color: ColorRed},
+		{name: "no runs", rate: nil, message: "no runs", color: ColorGrey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := PassRate("pass rate", tt.rate)
+			assert.Equal(t, tt.message, b.Message)
+			assert.Equal(t, tt.color, b.Color)
+		})
+	}
+}
+
+func TestCombinedStatus(t *testing.T) {
+	assert.Equal(t, testrun.StatusFailed, CombinedStatus([]testrun.Status{testrun.StatusPassed, testrun.StatusFailed, testrun.StatusSkipped}))
+	assert.Equal(t, testrun.StatusPassed, CombinedStatus([]testrun.Status{testrun.StatusSkipped, testrun.StatusPassed}))
+	assert.Equal(t, testrun.StatusSkipped, CombinedStatus([]testrun.Status{testrun.StatusSkipped}))
+	assert.Equal(t, testrun.Status(""), CombinedStatus(nil))
+}
+
+func TestBadge_SVG(t *testing.T) {
+	svg := Badge{Label: "<checkout> & pay", Message: "passing", Color: ColorGreen}.SVG()
+
+	// The badge is well-formed XML with its label escaped.
+	decoder := xml.NewDecoder(strings.NewReader(string(svg)))
+	for {
+		_, err := decoder.Token()
+		if err != nil {
+			assert.Equal(t, "EOF", err.Error())
+			break
+		}
+	}
+	assert.Contains(t, string(svg), "&lt;checkout&gt; &amp; pay")
+	assert.Contains(t, string(svg), ">passing</text>")
+	assert.Contains(t, string(svg), `fill="#4c1"`)
+	assert.NotContains(t, string(svg), "<checkout>")
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.Greater(t, textWidth("passing"), textWidth("pass"))
+	assert.Greater(t, textWidth("WWW"), textWidth("iii"))
+	assert.Equal(t, 0, textWidth(""))
+}
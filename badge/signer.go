@@ -0,0 +1,45 @@
+package badge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/google/uuid"
+)
+
+// Signer signs the URLs of badges, so that badges of private projects can
+// be embedded where viewers are not logged in, such as a README rendered by
+// a Git host. A token is only valid for the project or procedure it was
+// signed for; changing the secret invalidates every token.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner creates a new signer of badge tokens.
+func NewSigner(secret string) *Signer {
+	return &Signer{key: []byte(secret)}
+}
+
+// ProjectSubject is the subject of the badges of a project.
+func ProjectSubject(projectID uuid.UUID) string {
+	return "project:" + projectID.String()
+}
+
+// ProcedureSubject is the subject of the badges of a test procedure.
+func ProcedureSubject(procedureID uuid.UUID) string {
+	return "procedure:" + procedureID.String()
+}
+
+// Sign returns the token of the badges of subject.
+func (s *Signer) Sign(subject string) string {
+	mac := hmac.New(sha256.New, s.key)
+	// Prefixed so that tokens are never valid for another use of the key.
+	mac.Write([]byte("badge:" + subject))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether token is the token of the badges of subject.
+func (s *Signer) Verify(subject, token string) bool {
+	return hmac.Equal([]byte(s.Sign(subject)), []byte(token))
+}
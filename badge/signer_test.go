@@ -0,0 +1,31 @@
+package badge
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner("test-secret")
+	projectID := uuid.New()
+	token := signer.Sign(ProjectSubject(projectID))
+
+	assert.True(t, signer.Verify(ProjectSubject(projectID), token))
+	assert.Equal(t, token, signer.Sign(ProjectSubject(projectID)))
+
+	t.Run("other subject", func(t *testing.T) {
+		assert.False(t, signer.Verify(ProjectSubject(uuid.New()), token))
+		assert.False(t, signer.Verify(ProcedureSubject(projectID), token))
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		assert.False(t, NewSigner("other-secret").Verify(ProjectSubject(projectID), token))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		assert.False(t, signer.Verify(ProjectSubject(projectID), ""))
+		assert.False(t, signer.Verify(ProjectSubject(projectID), "not-a-token"))
+	})
+}
@@ -0,0 +1,54 @@
+package cipipeline
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLBuildStore implements the BuildStore interface using GORM and MySQL.
+type MySQLBuildStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLBuildStore creates a new MySQL-backed CI build store.
+func NewMySQLBuildStore(db *gorm.DB, log logger.Logger) *MySQLBuildStore {
+	return &MySQLBuildStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create records a triggered build.
+func (s *MySQLBuildStore) Create(ctx context.Context, build *Build) error {
+	if err := s.db.WithContext(ctx).Create(build).Error; err != nil {
+		s.logger.Error(ctx, "failed to create ci build", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": build.TestRunID.String(),
+			"pipeline_id": build.PipelineID.String(),
+		})
+		return err
+	}
+	return nil
+}
+
+// ListByRun lists the builds triggered for a test run, newest first.
+func (s *MySQLBuildStore) ListByRun(ctx context.Context, testRunID uuid.UUID) ([]*Build, error) {
+	var builds []*Build
+	err := s.db.WithContext(ctx).
+		Where("test_run_id = ?", testRunID).
+		Order("created_at DESC").
+		Find(&builds).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list ci builds", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": testRunID.String(),
+		})
+		return nil, err
+	}
+	return builds, nil
+}
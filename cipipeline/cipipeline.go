@@ -0,0 +1,223 @@
+package cipipeline
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPipelineNotFound is returned when a pipeline is not found.
+	ErrPipelineNotFound = errors.New("ci pipeline not found")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidName is returned when name is empty.
+	ErrInvalidName = errors.New("name is required")
+
+	// ErrInvalidProvider is returned when provider is not github_actions,
+	// gitlab or jenkins.
+	ErrInvalidProvider = errors.New("provider must be github_actions, gitlab or jenkins")
+
+	// ErrInvalidBaseURL is returned when base_url is not an http(s) URL, or
+	// is missing for Jenkins.
+	ErrInvalidBaseURL = errors.New("base_url must be an http or https URL")
+
+	// ErrInvalidRepository is returned when repository is not a path such
+	// as owner/name.
+	ErrInvalidRepository = errors.New("repository must be a path such as owner/name")
+
+	// ErrWorkflowRequired is returned when a GitHub Actions pipeline has no
+	// workflow.
+	ErrWorkflowRequired = errors.New("workflow is required for github_actions pipelines")
+
+	// ErrRefRequired is returned when a GitHub Actions or GitLab pipeline has
+	// no ref to run on.
+	ErrRefRequired = errors.New("ref is required")
+
+	// ErrJobRequired is returned when a Jenkins pipeline has no job.
+	ErrJobRequired = errors.New("job is required for jenkins pipelines")
+
+	// ErrUsernameRequired is returned when a Jenkins pipeline has no user
+	// to authenticate its API token.
+	ErrUsernameRequired = errors.New("username is required for jenkins pipelines")
+
+	// ErrTokenRequired is returned when a pipeline is saved without an
+	// access token.
+	ErrTokenRequired = errors.New("token is required")
+
+	// ErrDecryptFailed is returned when a stored token cannot be decrypted.
+	ErrDecryptFailed = errors.New("failed to decrypt ci pipeline token")
+)
+
+// Provider is the CI service a pipeline runs on.
+type Provider string
+
+const (
+	ProviderGitHubActions Provider = "github_actions"
+	ProviderGitLab        Provider = "gitlab"
+	ProviderJenkins       Provider = "jenkins"
+)
+
+// IsValid checks if the provider is supported.
+func (p Provider) IsValid() bool {
+	switch p {
+	case ProviderGitHubActions, ProviderGitLab, ProviderJenkins:
+		return true
+	}
+	return false
+}
+
+// Pipeline is an external CI pipeline a project's test runs can trigger:
+// a GitHub Actions workflow dispatched on Ref of Repository, a GitLab
+// pipeline run on Ref of the Repository project, or a parameterized Jenkins
+// job.
+type Pipeline struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:char(36);not null;index:idx_ci_pipelines_project_id"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
+	Provider  Provider  `json:"provider" gorm:"type:varchar(20);not null"`
+	// BaseURL is the Jenkins server, or the API of a self-hosted GitHub
+	// Enterprise or GitLab instance; empty uses github.com or gitlab.com.
+	BaseURL    string `json:"base_url,omitempty" gorm:"type:varchar(500);not null;default:''"`
+	Repository string `json:"repository,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	// Workflow is the file name or ID of a GitHub Actions workflow.
+	Workflow string `json:"workflow,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	Ref      string `json:"ref,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	// Job is the path of a Jenkins job, with folders separated by slashes.
+	Job      string `json:"job,omitempty" gorm:"type:varchar(500);not null;default:''"`
+	Username string `json:"username,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	// Token is stored encrypted and never serialized.
+	Token           string    `json:"-" gorm:"-"`
+	EncryptedToken  []byte    `json:"-" gorm:"type:blob;not null"`
+	TokenConfigured bool      `json:"token_configured" gorm:"-"`
+	CreatedBy       uuid.UUID `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (p *Pipeline) TableName() string {
+	return "ci_pipelines"
+}
+
+// BeforeCreate hook to generate UUID before creating a new pipeline
+func (p *Pipeline) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// Normalize trims the pipeline's fields.
+func (p *Pipeline) Normalize() {
+	p.Name = strings.TrimSpace(p.Name)
+	p.BaseURL = strings.TrimRight(strings.TrimSpace(p.BaseURL), "/")
+	p.Repository = strings.Trim(strings.TrimSpace(p.Repository), "/")
+	p.Workflow = strings.TrimSpace(p.Workflow)
+	p.Ref = strings.TrimSpace(p.Ref)
+	p.Job = strings.Trim(strings.TrimSpace(p.Job), "/")
+	p.Username = strings.TrimSpace(p.Username)
+}
+
+// Validate checks if the pipeline has the fields its provider requires.
+func (p *Pipeline) Validate() error {
+	if p.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if p.Name == "" {
+		return ErrInvalidName
+	}
+	if !p.Provider.IsValid() {
+		return ErrInvalidProvider
+	}
+	if p.BaseURL != "" || p.Provider == ProviderJenkins {
+		u, err := url.Parse(p.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidBaseURL
+		}
+	}
+
+	switch p.Provider {
+	case ProviderGitHubActions, ProviderGitLab:
+		if !strings.Contains(p.Repository, "/") || strings.ContainsAny(p.Repository, " \t\n") {
+			return ErrInvalidRepository
+		}
+		if p.Provider == ProviderGitHubActions && p.Workflow == "" {
+			return ErrWorkflowRequired
+		}
+		if p.Ref == "" {
+			return ErrRefRequired
+		}
+	case ProviderJenkins:
+		if p.Job == "" {
+			return ErrJobRequired
+		}
+		if p.Username == "" {
+			return ErrUsernameRequired
+		}
+	}
+	return nil
+}
+
+// Build is a CI build triggered for a test run, kept so that the run can be
+// traced to it. The pipeline's name and provider are copied so that the
+// build stays meaningful if the pipeline is deleted.
+type Build struct {
+	ID           uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	TestRunID    uuid.UUID `json:"test_run_id" gorm:"type:char(36);not null;index:idx_ci_builds_test_run_id"`
+	PipelineID   uuid.UUID `json:"pipeline_id" gorm:"type:char(36);not null"`
+	PipelineName string    `json:"pipeline_name" gorm:"type:varchar(255);not null"`
+	Provider     Provider  `json:"provider" gorm:"type:varchar(20);not null"`
+	// BuildURL is the build's page, or for Jenkins builds that had not
+	// left the queue yet, the queue item's.
+	BuildURL    string    `json:"build_url" gorm:"type:varchar(2048);not null;default:''"`
+	TriggeredBy uuid.UUID `json:"triggered_by" gorm:"type:char(36);not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (b *Build) TableName() string {
+	return "ci_builds"
+}
+
+// BeforeCreate hook to generate UUID before creating a new build
+func (b *Build) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// RunInfo describes the test run a pipeline is triggered for.
+type RunInfo struct {
+	RunID            uuid.UUID
+	ProcedureID      uuid.UUID
+	ProjectID        uuid.UUID
+	ProcedureName    string
+	ProcedureVersion uint
+	Environment      string
+	BaseURL          string
+}
+
+// Parameters returns the run's metadata as the parameters passed to a
+// pipeline: workflow inputs, pipeline variables or build parameters. Every
+// parameter is always passed, since GitHub Actions rejects a dispatch whose
+// inputs differ from those the workflow declares.
+func (i RunInfo) Parameters() map[string]string {
+	return map[string]string{
+		"run_id":            i.RunID.String(),
+		"procedure_id":      i.ProcedureID.String(),
+		"project_id":        i.ProjectID.String(),
+		"procedure_name":    i.ProcedureName,
+		"procedure_version": strconv.FormatUint(uint64(i.ProcedureVersion), 10),
+		"environment":       i.Environment,
+		"base_url":          i.BaseURL,
+	}
+}
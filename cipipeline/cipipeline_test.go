@@ -0,0 +1,95 @@
+package cipipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeline_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *Pipeline)
+		wantErr error
+	}{
+		{name: "valid github actions", modify: func(p *Pipeline) {}},
+		{
+			name: "valid gitlab",
+			modify: func(p *Pipeline) {
+				p.Provider = ProviderGitLab
+				p.Repository = "acme/qa/web"
+				p.Workflow = ""
+			},
+		},
+		{
+			name: "valid jenkins",
+			modify: func(p *Pipeline) {
+				p.Provider = ProviderJenkins
+				p.BaseURL = "https://jenkins.example.com"
+				p.Job = "qa/e2e"
+				p.Username = "bot"
+			},
+		},
+		{name: "missing project", modify: func(p *Pipeline) { p.ProjectID = [16]byte{} }, wantErr: ErrInvalidProjectID},
+		{name: "missing name", modify: func(p *Pipeline) { p.Name = "" }, wantErr: ErrInvalidName},
+		{name: "invalid provider", modify: func(p *Pipeline) { p.Provider = "circleci" }, wantErr: ErrInvalidProvider},
+		{name: "invalid base url", modify: func(p *Pipeline) { p.BaseURL = "ftp://example.com" }, wantErr: ErrInvalidBaseURL},
+		{name: "invalid repository", modify: func(p *Pipeline) { p.Repository = "web" }, wantErr: ErrInvalidRepository},
+		{name: "missing workflow", modify: func(p *Pipeline) { p.Workflow = "" }, wantErr: ErrWorkflowRequired},
+		{name: "missing ref", modify: func(p *Pipeline) { p.Ref = "" }, wantErr: ErrRefRequired},
+		{
+			name: "jenkins without base url",
+			modify: func(p *Pipeline) {
+				p.Provider = ProviderJenkins
+				p.Job = "e2e"
+				p.Username = "bot"
+			},
+			wantErr: ErrInvalidBaseURL,
+		},
+		{
+			name: "jenkins without job",
+			modify: func(p *Pipeline) {
+				p.Provider = ProviderJenkins
+				p.BaseURL = "https://jenkins.example.com"
+				p.Username = "bot"
+			},
+			wantErr: ErrJobRequired,
+		},
+		{
+			name: "jenkins without username",
+			modify: func(p *Pipeline) {
+				p.Provider = ProviderJenkins
+				p.BaseURL = "https://jenkins.example.com"
+				p.Job = "e2e"
+			},
+			wantErr: ErrUsernameRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPipeline()
+			tt.modify(p)
+			p.Normalize()
+			err := p.Validate()
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunInfo_Parameters(t *testing.T) {
+	params := testParameters()
+	assert.Equal(t, map[string]string{
+		"run_id":            "11111111-1111-1111-1111-111111111111",
+		"procedure_id":      "22222222-2222-2222-2222-222222222222",
+		"project_id":        "33333333-3333-3333-3333-333333333333",
+		"procedure_name":    "Login",
+		"procedure_version": "3",
+		"environment":       "staging",
+		"base_url":          "https://staging.example.com",
+	}, params)
+}
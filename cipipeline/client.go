@@ -0,0 +1,132 @@
+package cipipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Client triggers the pipeline of a CI service.
+type Client interface {
+	// Trigger starts the pipeline with parameters and returns the URL of
+	// the build it started, if the service reports one.
+	Trigger(ctx context.Context, parameters map[string]string) (string, error)
+}
+
+// NewClient creates the client of the pipeline's provider, authenticated
+// with its decrypted token.
+func NewClient(p *Pipeline) (Client, error) {
+	if p.Token == "" {
+		return nil, ErrTokenRequired
+	}
+	switch p.Provider {
+	case ProviderGitHubActions:
+		return newGitHubClient(p), nil
+	case ProviderGitLab:
+		return newGitLabClient(p), nil
+	case ProviderJenkins:
+		return newJenkinsClient(p), nil
+	default:
+		return nil, ErrInvalidProvider
+	}
+}
+
+// apiError is an unexpected response of a provider's API.
+type apiError struct {
+	provider   Provider
+	operation  string
+	statusCode int
+	body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s failed with status %d: %s", e.provider, e.operation, e.statusCode, e.body)
+}
+
+// apiResponse is a response with one of the expected statuses.
+type apiResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// decode decodes the JSON body of the response into out.
+func (r *apiResponse) decode(provider Provider, operation string, out interface{}) error {
+	if err := json.Unmarshal(r.body, out); err != nil {
+		return fmt.Errorf("%s: failed to decode %s response: %w", provider, operation, err)
+	}
+	return nil
+}
+
+// apiClient makes requests to a provider's API.
+type apiClient struct {
+	provider   Provider
+	httpClient *http.Client
+	headers    map[string]string
+}
+
+func newAPIClient(provider Provider, headers map[string]string) *apiClient {
+	return &apiClient{
+		provider:   provider,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		headers:    headers,
+	}
+}
+
+// maxResponseSize bounds the response bodies read from providers.
+const maxResponseSize = 1 << 20
+
+// do sends a request with a body of contentType, which may be nil, to url.
+// It returns the response if its status is one of the expected ones, and
+// an *apiError otherwise.
+func (c *apiClient) do(ctx context.Context, operation, method, url, contentType string, body []byte, expected ...int) (*apiResponse, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create request: %w", c.provider, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s failed: %w", c.provider, operation, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read %s response: %w", c.provider, operation, err)
+	}
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return &apiResponse{status: resp.StatusCode, header: resp.Header, body: data}, nil
+		}
+	}
+
+	if len(data) > 1000 {
+		data = data[:1000]
+	}
+	return nil, &apiError{provider: c.provider, operation: operation, statusCode: resp.StatusCode, body: string(data)}
+}
+
+// doJSON sends body as JSON with do.
+func (c *apiClient) doJSON(ctx context.Context, operation, method, url string, body interface{}, expected ...int) (*apiResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to marshal request body: %w", c.provider, err)
+	}
+	return c.do(ctx, operation, method, url, "application/json", data, expected...)
+}
@@ -0,0 +1,174 @@
+package cipipeline
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates the client of pipeline pointed at handler.
+func newTestClient(t *testing.T, pipeline *Pipeline, handler http.Handler) Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	pipeline.BaseURL = server.URL
+	client, err := NewClient(pipeline)
+	require.NoError(t, err)
+	return client
+}
+
+func TestNewClient(t *testing.T) {
+	pipeline := newTestPipeline()
+	pipeline.Token = ""
+	_, err := NewClient(pipeline)
+	assert.ErrorIs(t, err, ErrTokenRequired)
+}
+
+func TestGitHubClient_Trigger(t *testing.T) {
+	t.Run("returns the run", func(t *testing.T) {
+		var body struct {
+			Ref    string            `json:"ref"`
+			Inputs map[string]string `json:"inputs"`
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /repos/acme/web/actions/workflows/e2e.yml/dispatches", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer ghp_secret", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.Write([]byte(`{"workflow_run_id":42,"html_url":"https://github.com/acme/web/actions/runs/42"}`))
+		})
+
+		buildURL, err := newTestClient(t, newTestPipeline(), mux).Trigger(context.Background(), testParameters())
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/acme/web/actions/runs/42", buildURL)
+		assert.Equal(t, "main", body.Ref)
+		assert.Equal(t, testParameters(), body.Inputs)
+	})
+
+	t.Run("falls back to the workflow runs", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /api/v3/repos/acme/web/actions/workflows/e2e.yml/dispatches", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		pipeline := newTestPipeline()
+		pipeline.BaseURL = server.URL + "/api/v3"
+		client, err := NewClient(pipeline)
+		require.NoError(t, err)
+
+		buildURL, err := client.Trigger(context.Background(), testParameters())
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/acme/web/actions/workflows/e2e.yml", buildURL)
+	})
+
+	t.Run("rejected inputs", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /repos/acme/web/actions/workflows/e2e.yml/dispatches", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message":"Unexpected inputs provided: [\"run_id\"]"}`))
+		})
+
+		_, err := newTestClient(t, newTestPipeline(), mux).Trigger(context.Background(), testParameters())
+		assert.ErrorContains(t, err, "dispatch workflow failed with status 422")
+		assert.ErrorContains(t, err, "Unexpected inputs")
+	})
+}
+
+func TestGitLabClient_Trigger(t *testing.T) {
+	var body struct {
+		Ref       string              `json:"ref"`
+		Variables []map[string]string `json:"variables"`
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/projects/acme%2Fqa%2Fweb/pipeline", r.URL.EscapedPath())
+		assert.Equal(t, "glpat_secret", r.Header.Get("PRIVATE-TOKEN"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":7,"web_url":"https://gitlab.com/acme/qa/web/-/pipelines/7"}`))
+	})
+
+	pipeline := newTestPipeline()
+	pipeline.Provider = ProviderGitLab
+	pipeline.Repository = "acme/qa/web"
+	pipeline.Token = "glpat_secret"
+	buildURL, err := newTestClient(t, pipeline, handler).Trigger(context.Background(), testParameters())
+	require.NoError(t, err)
+	assert.Equal(t, "https://gitlab.com/acme/qa/web/-/pipelines/7", buildURL)
+
+	assert.Equal(t, "main", body.Ref)
+	require.Len(t, body.Variables, len(testParameters()))
+	assert.Equal(t, map[string]string{"key": "base_url", "value": "https://staging.example.com"}, body.Variables[0])
+}
+
+func TestJenkinsClient_Trigger(t *testing.T) {
+	newPipeline := func() *Pipeline {
+		pipeline := newTestPipeline()
+		pipeline.Provider = ProviderJenkins
+		pipeline.Job = "qa/e2e"
+		pipeline.Username = "bot"
+		pipeline.Token = "api-token"
+		return pipeline
+	}
+
+	t.Run("build started", func(t *testing.T) {
+		var serverURL string
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /job/qa/job/e2e/buildWithParameters", func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "bot", user)
+			assert.Equal(t, "api-token", pass)
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "Login", r.PostForm.Get("procedure_name"))
+			w.Header().Set("Location", serverURL+"/queue/item/5/")
+			w.WriteHeader(http.StatusCreated)
+		})
+		mux.HandleFunc("GET /queue/item/5/api/json", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"executable":{"number":12,"url":"` + serverURL + `/job/qa/job/e2e/12/"}}`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		serverURL = server.URL
+
+		pipeline := newPipeline()
+		pipeline.BaseURL = server.URL
+		client, err := NewClient(pipeline)
+		require.NoError(t, err)
+
+		buildURL, err := client.Trigger(context.Background(), testParameters())
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/job/qa/job/e2e/12/", buildURL)
+	})
+
+	t.Run("build queued", func(t *testing.T) {
+		var serverURL string
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /job/qa/job/e2e/buildWithParameters", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Location", serverURL+"/queue/item/6/")
+			w.WriteHeader(http.StatusCreated)
+		})
+		mux.HandleFunc("GET /queue/item/6/api/json", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"why":"In the quiet period"}`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		serverURL = server.URL
+
+		pipeline := newPipeline()
+		pipeline.BaseURL = server.URL
+		client, err := NewClient(pipeline)
+		require.NoError(t, err)
+
+		buildURL, err := client.Trigger(context.Background(), testParameters())
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/queue/item/6/", buildURL)
+	})
+}
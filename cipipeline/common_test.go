@@ -0,0 +1,50 @@
+package cipipeline
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStores creates a test database and CI pipeline and build stores
+// for testing.
+func setupTestStores(t *testing.T) (*gorm.DB, *MySQLStore, *MySQLBuildStore) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Pipeline{}, &Build{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, integration.NewKeyring(integration.DeriveKey("test-encryption-key")), log)
+	return db, store, NewMySQLBuildStore(db, log)
+}
+
+// newTestPipeline creates a valid GitHub Actions pipeline for a new
+// project.
+func newTestPipeline() *Pipeline {
+	return &Pipeline{
+		ProjectID:  uuid.New(),
+		Name:       "E2E",
+		Provider:   ProviderGitHubActions,
+		Repository: "acme/web",
+		Workflow:   "e2e.yml",
+		Ref:        "main",
+		Token:      "ghp_secret",
+		CreatedBy:  uuid.New(),
+	}
+}
+
+// testParameters are the parameters of a run passed to pipelines.
+func testParameters() map[string]string {
+	return RunInfo{
+		RunID:            uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		ProcedureID:      uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		ProjectID:        uuid.MustParse("33333333-3333-3333-3333-333333333333"),
+		ProcedureName:    "Login",
+		ProcedureVersion: 3,
+		Environment:      "staging",
+		BaseURL:          "https://staging.example.com",
+	}.Parameters()
+}
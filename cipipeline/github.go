@@ -0,0 +1,83 @@
+package cipipeline
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultGitHubBaseURL = "https://api.github.com"
+	defaultGitHubWebURL  = "https://github.com"
+)
+
+// gitHubClient dispatches GitHub Actions workflows.
+type gitHubClient struct {
+	api      *apiClient
+	baseURL  string
+	webURL   string
+	repo     string
+	workflow string
+	ref      string
+}
+
+func newGitHubClient(p *Pipeline) *gitHubClient {
+	baseURL, webURL := p.BaseURL, defaultGitHubWebURL
+	if baseURL == "" {
+		baseURL = defaultGitHubBaseURL
+	} else {
+		// The API of GitHub Enterprise Server is served under /api/v3.
+		webURL = strings.TrimSuffix(baseURL, "/api/v3")
+	}
+	return &gitHubClient{
+		api: newAPIClient(ProviderGitHubActions, map[string]string{
+			"Authorization":        "Bearer " + p.Token,
+			"Accept":               "application/vnd.github+json",
+			"X-GitHub-Api-Version": "2022-11-28",
+		}),
+		baseURL:  baseURL,
+		webURL:   webURL,
+		repo:     p.Repository,
+		workflow: p.Workflow,
+		ref:      p.Ref,
+	}
+}
+
+// Trigger creates a workflow_dispatch event with the parameters as inputs.
+// Servers that do not return the details of the run it started answer
+// 204, in which case the URL of the workflow's runs is returned instead.
+func (c *gitHubClient) Trigger(ctx context.Context, parameters map[string]string) (string, error) {
+	resp, err := c.api.doJSON(ctx, "dispatch workflow", http.MethodPost,
+		c.baseURL+"/repos/"+escapePath(c.repo)+"/actions/workflows/"+url.PathEscape(c.workflow)+"/dispatches",
+		map[string]interface{}{
+			"ref":                c.ref,
+			"inputs":             parameters,
+			"return_run_details": true,
+		}, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.status == http.StatusOK {
+		var run struct {
+			HTMLURL string `json:"html_url"`
+		}
+		if err := resp.decode(ProviderGitHubActions, "dispatch workflow", &run); err != nil {
+			return "", err
+		}
+		if run.HTMLURL != "" {
+			return run.HTMLURL, nil
+		}
+	}
+	return c.webURL + "/" + escapePath(c.repo) + "/actions/workflows/" + url.PathEscape(c.workflow), nil
+}
+
+// escapePath escapes each segment of a slash-separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,65 @@
+package cipipeline
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+const defaultGitLabBaseURL = "https://gitlab.com/api/v4"
+
+// gitLabClient runs GitLab CI/CD pipelines.
+type gitLabClient struct {
+	api     *apiClient
+	baseURL string
+	project string
+	ref     string
+}
+
+func newGitLabClient(p *Pipeline) *gitLabClient {
+	baseURL := p.BaseURL
+	if baseURL == "" {
+		baseURL = defaultGitLabBaseURL
+	}
+	return &gitLabClient{
+		api: newAPIClient(ProviderGitLab, map[string]string{
+			"PRIVATE-TOKEN": p.Token,
+		}),
+		baseURL: baseURL,
+		// Projects are addressed by their URL-encoded path.
+		project: url.PathEscape(p.Repository),
+		ref:     p.Ref,
+	}
+}
+
+// Trigger creates a pipeline on the ref with the parameters as variables.
+func (c *gitLabClient) Trigger(ctx context.Context, parameters map[string]string) (string, error) {
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	variables := make([]map[string]string, 0, len(keys))
+	for _, key := range keys {
+		variables = append(variables, map[string]string{"key": key, "value": parameters[key]})
+	}
+
+	resp, err := c.api.doJSON(ctx, "create pipeline", http.MethodPost,
+		c.baseURL+"/projects/"+c.project+"/pipeline",
+		map[string]interface{}{
+			"ref":       c.ref,
+			"variables": variables,
+		}, http.StatusCreated)
+	if err != nil {
+		return "", err
+	}
+
+	var pipeline struct {
+		WebURL string `json:"web_url"`
+	}
+	if err := resp.decode(ProviderGitLab, "create pipeline", &pipeline); err != nil {
+		return "", err
+	}
+	return pipeline.WebURL, nil
+}
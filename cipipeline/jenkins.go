@@ -0,0 +1,76 @@
+package cipipeline
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// jenkinsClient builds parameterized Jenkins jobs.
+type jenkinsClient struct {
+	api     *apiClient
+	baseURL string
+	job     string
+}
+
+func newJenkinsClient(p *Pipeline) *jenkinsClient {
+	// API tokens authenticate with basic auth and need no CSRF crumb.
+	auth := base64.StdEncoding.EncodeToString([]byte(p.Username + ":" + p.Token))
+	return &jenkinsClient{
+		api: newAPIClient(ProviderJenkins, map[string]string{
+			"Authorization": "Basic " + auth,
+		}),
+		baseURL: p.BaseURL,
+		job:     p.Job,
+	}
+}
+
+// Trigger queues a build of the job with the parameters. Jenkins answers
+// with the queue item of the build; if the build has already left the queue
+// its URL is returned, and the queue item's otherwise.
+func (c *jenkinsClient) Trigger(ctx context.Context, parameters map[string]string) (string, error) {
+	form := url.Values{}
+	for key, value := range parameters {
+		form.Set(key, value)
+	}
+
+	resp, err := c.api.do(ctx, "build job", http.MethodPost,
+		c.baseURL+jobPath(c.job)+"/buildWithParameters",
+		"application/x-www-form-urlencoded", []byte(form.Encode()),
+		http.StatusCreated)
+	if err != nil {
+		return "", err
+	}
+
+	queueURL := resp.header.Get("Location")
+	if queueURL == "" {
+		return "", nil
+	}
+	queueURL = strings.TrimRight(queueURL, "/") + "/"
+
+	// Best effort: the queue item only names the build once it started.
+	item, err := c.api.do(ctx, "get queue item", http.MethodGet, queueURL+"api/json", "", nil, http.StatusOK)
+	if err == nil {
+		var queued struct {
+			Executable *struct {
+				URL string `json:"url"`
+			} `json:"executable"`
+		}
+		if item.decode(ProviderJenkins, "get queue item", &queued) == nil && queued.Executable != nil && queued.Executable.URL != "" {
+			return queued.Executable.URL, nil
+		}
+	}
+	return queueURL, nil
+}
+
+// jobPath returns the URL path of a job, nested in its folders.
+func jobPath(job string) string {
+	var b strings.Builder
+	for _, segment := range strings.Split(job, "/") {
+		b.WriteString("/job/")
+		b.WriteString(url.PathEscape(segment))
+	}
+	return b.String()
+}
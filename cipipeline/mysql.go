@@ -0,0 +1,218 @@
+package cipipeline
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// tokenKey is the credentials map key the token is encrypted under.
+const tokenKey = "token"
+
+// MySQLStore implements the Store interface using GORM and MySQL. Tokens
+// are encrypted with AES-256-GCM, the same scheme used for integration
+// credentials.
+type MySQLStore struct {
+	db      *gorm.DB
+	keyring *integration.Keyring
+	logger  logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed CI pipeline store that encrypts
+// tokens with the keys in keyring.
+func NewMySQLStore(db *gorm.DB, keyring *integration.Keyring, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:      db,
+		keyring: keyring,
+		logger:  log,
+	}
+}
+
+// encryptToken encrypts the pipeline's token into EncryptedToken.
+func (s *MySQLStore) encryptToken(pipeline *Pipeline) error {
+	encrypted, err := s.keyring.Encrypt(map[string]string{tokenKey: pipeline.Token})
+	if err != nil {
+		return err
+	}
+	pipeline.EncryptedToken = encrypted
+	pipeline.TokenConfigured = true
+	return nil
+}
+
+// Create creates a new pipeline.
+func (s *MySQLStore) Create(ctx context.Context, pipeline *Pipeline) error {
+	pipeline.Normalize()
+	if err := pipeline.Validate(); err != nil {
+		return err
+	}
+	if pipeline.Token == "" {
+		return ErrTokenRequired
+	}
+	if err := s.encryptToken(pipeline); err != nil {
+		s.logger.Error(ctx, "failed to encrypt ci pipeline token", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(pipeline).Error; err != nil {
+		s.logger.Error(ctx, "failed to create ci pipeline", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": pipeline.ProjectID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "ci pipeline created", map[string]interface{}{
+		"pipeline_id": pipeline.ID.String(),
+		"project_id":  pipeline.ProjectID.String(),
+		"provider":    pipeline.Provider,
+	})
+
+	return nil
+}
+
+// GetByID retrieves a pipeline by ID with its token decrypted.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*Pipeline, error) {
+	var pipeline Pipeline
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&pipeline).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPipelineNotFound
+		}
+		s.logger.Error(ctx, "failed to get ci pipeline", map[string]interface{}{
+			"error":       err.Error(),
+			"pipeline_id": id.String(),
+		})
+		return nil, err
+	}
+
+	decrypted, err := s.keyring.Decrypt(pipeline.EncryptedToken)
+	if err != nil {
+		s.logger.Error(ctx, "failed to decrypt ci pipeline token", map[string]interface{}{
+			"error":       err.Error(),
+			"pipeline_id": id.String(),
+		})
+		return nil, ErrDecryptFailed
+	}
+	pipeline.Token = decrypted[tokenKey]
+	pipeline.TokenConfigured = pipeline.Token != ""
+
+	return &pipeline, nil
+}
+
+// ListByProject lists the pipelines of a project by name.
+func (s *MySQLStore) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*Pipeline, error) {
+	var pipelines []*Pipeline
+	err := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("name ASC").
+		Find(&pipelines).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list ci pipelines", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	for _, pipeline := range pipelines {
+		pipeline.TokenConfigured = len(pipeline.EncryptedToken) > 0
+	}
+	return pipelines, nil
+}
+
+// Update replaces a pipeline's configuration. The project and creator of a
+// pipeline never change.
+func (s *MySQLStore) Update(ctx context.Context, pipeline *Pipeline) error {
+	pipeline.Normalize()
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing Pipeline
+		if err := tx.Where("id = ?", pipeline.ID).First(&existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPipelineNotFound
+			}
+			return err
+		}
+		pipeline.ProjectID = existing.ProjectID
+		pipeline.CreatedBy = existing.CreatedBy
+		pipeline.CreatedAt = existing.CreatedAt
+		if err := pipeline.Validate(); err != nil {
+			return err
+		}
+
+		if pipeline.Token != "" {
+			if err := s.encryptToken(pipeline); err != nil {
+				return err
+			}
+		} else {
+			pipeline.EncryptedToken = existing.EncryptedToken
+			pipeline.TokenConfigured = true
+		}
+
+		return tx.Save(pipeline).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrPipelineNotFound) || isValidationError(err) {
+			return err
+		}
+		s.logger.Error(ctx, "failed to update ci pipeline", map[string]interface{}{
+			"error":       err.Error(),
+			"pipeline_id": pipeline.ID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "ci pipeline updated", map[string]interface{}{
+		"pipeline_id": pipeline.ID.String(),
+	})
+
+	return nil
+}
+
+// Delete deletes a pipeline.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&Pipeline{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete ci pipeline", map[string]interface{}{
+			"error":       result.Error.Error(),
+			"pipeline_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrPipelineNotFound
+	}
+
+	s.logger.Info(ctx, "ci pipeline deleted", map[string]interface{}{
+		"pipeline_id": id.String(),
+	})
+
+	return nil
+}
+
+// isValidationError reports whether err is returned by Pipeline.Validate.
+func isValidationError(err error) bool {
+	for _, target := range []error{
+		ErrInvalidProjectID, ErrInvalidName, ErrInvalidProvider, ErrInvalidBaseURL, ErrInvalidRepository,
+		ErrWorkflowRequired, ErrRefRequired, ErrJobRequired, ErrUsernameRequired,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package cipipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore(t *testing.T) {
+	db, store, _ := setupTestStores(t)
+	ctx := context.Background()
+	pipeline := newTestPipeline()
+
+	t.Run("token is required", func(t *testing.T) {
+		noToken := newTestPipeline()
+		noToken.Token = ""
+		assert.ErrorIs(t, store.Create(ctx, noToken), ErrTokenRequired)
+	})
+
+	t.Run("invalid pipeline", func(t *testing.T) {
+		invalid := newTestPipeline()
+		invalid.Workflow = ""
+		assert.ErrorIs(t, store.Create(ctx, invalid), ErrWorkflowRequired)
+	})
+
+	t.Run("token is encrypted at rest", func(t *testing.T) {
+		require.NoError(t, store.Create(ctx, pipeline))
+
+		var raw Pipeline
+		require.NoError(t, db.Where("id = ?", pipeline.ID).First(&raw).Error)
+		assert.NotContains(t, string(raw.EncryptedToken), "ghp_secret")
+
+		retrieved, err := store.GetByID(ctx, pipeline.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_secret", retrieved.Token)
+		assert.True(t, retrieved.TokenConfigured)
+	})
+
+	t.Run("list by project", func(t *testing.T) {
+		other := newTestPipeline()
+		other.ProjectID = pipeline.ProjectID
+		other.Name = "Deploy"
+		require.NoError(t, store.Create(ctx, other))
+		require.NoError(t, store.Create(ctx, newTestPipeline()))
+
+		pipelines, err := store.ListByProject(ctx, pipeline.ProjectID)
+		require.NoError(t, err)
+		require.Len(t, pipelines, 2)
+		assert.Equal(t, "Deploy", pipelines[0].Name)
+		assert.Empty(t, pipelines[0].Token)
+		assert.True(t, pipelines[0].TokenConfigured)
+	})
+
+	t.Run("update keeps token and project", func(t *testing.T) {
+		update := &Pipeline{
+			ID:         pipeline.ID,
+			ProjectID:  uuid.New(),
+			Name:       "Nightly E2E",
+			Provider:   ProviderGitHubActions,
+			Repository: "acme/web",
+			Workflow:   "nightly.yml",
+			Ref:        "develop",
+		}
+		require.NoError(t, store.Update(ctx, update))
+
+		retrieved, err := store.GetByID(ctx, pipeline.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Nightly E2E", retrieved.Name)
+		assert.Equal(t, "nightly.yml", retrieved.Workflow)
+		assert.Equal(t, pipeline.ProjectID, retrieved.ProjectID)
+		assert.Equal(t, "ghp_secret", retrieved.Token)
+
+		update.ID = uuid.New()
+		assert.ErrorIs(t, store.Update(ctx, update), ErrPipelineNotFound)
+	})
+
+	t.Run("rekey", func(t *testing.T) {
+		oldKey := integration.DeriveKey("test-encryption-key")
+		newKey := integration.DeriveKey("new-encryption-key")
+		count, err := RekeyTokens(ctx, db, oldKey, newKey)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		rekeyed := NewMySQLStore(db, integration.NewKeyring(newKey), store.logger)
+		retrieved, err := rekeyed.GetByID(ctx, pipeline.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_secret", retrieved.Token)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, pipeline.ID))
+		_, err := store.GetByID(ctx, pipeline.ID)
+		assert.ErrorIs(t, err, ErrPipelineNotFound)
+		assert.ErrorIs(t, store.Delete(ctx, pipeline.ID), ErrPipelineNotFound)
+	})
+}
+
+func TestMySQLBuildStore(t *testing.T) {
+	_, _, builds := setupTestStores(t)
+	ctx := context.Background()
+	runID := uuid.New()
+
+	for _, url := range []string{"https://ci.example.com/1", "https://ci.example.com/2"} {
+		require.NoError(t, builds.Create(ctx, &Build{
+			TestRunID:    runID,
+			PipelineID:   uuid.New(),
+			PipelineName: "E2E",
+			Provider:     ProviderJenkins,
+			BuildURL:     url,
+			TriggeredBy:  uuid.New(),
+		}))
+	}
+	require.NoError(t, builds.Create(ctx, &Build{TestRunID: uuid.New(), PipelineID: uuid.New(), PipelineName: "E2E", Provider: ProviderGitLab, TriggeredBy: uuid.New()}))
+
+	listed, err := builds.ListByRun(ctx, runID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	for _, build := range listed {
+		assert.Equal(t, runID, build.TestRunID)
+	}
+}
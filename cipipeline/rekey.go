@@ -0,0 +1,39 @@
+package cipipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"gorm.io/gorm"
+)
+
+// RekeyTokens re-encrypts every CI pipeline token from oldKey to newKey and
+// returns how many were re-encrypted. Tokens already encrypted with newKey
+// are left alone. It stops at the first value that decrypts with neither
+// key; run it in a transaction so that nothing is left half rotated.
+func RekeyTokens(ctx context.Context, db *gorm.DB, oldKey, newKey []byte) (int, error) {
+	var pipelines []Pipeline
+	if err := db.WithContext(ctx).Select("id", "encrypted_token").Find(&pipelines).Error; err != nil {
+		return 0, fmt.Errorf("failed to list ci pipelines: %w", err)
+	}
+
+	count := 0
+	for _, pipeline := range pipelines {
+		encrypted, changed, err := integration.Reencrypt(oldKey, newKey, pipeline.EncryptedToken)
+		if err != nil {
+			return 0, fmt.Errorf("ci pipeline %s: %w", pipeline.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		err = db.WithContext(ctx).Model(&Pipeline{}).
+			Where("id = ?", pipeline.ID).
+			UpdateColumn("encrypted_token", encrypted).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to update ci pipeline %s: %w", pipeline.ID, err)
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,36 @@
+package cipipeline
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for CI pipeline persistence operations.
+type Store interface {
+	// Create creates a new pipeline. Its token is required.
+	Create(ctx context.Context, pipeline *Pipeline) error
+
+	// GetByID retrieves a pipeline by ID with its token decrypted.
+	GetByID(ctx context.Context, id uuid.UUID) (*Pipeline, error)
+
+	// ListByProject lists the pipelines of a project by name, without
+	// their tokens.
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*Pipeline, error)
+
+	// Update replaces a pipeline's configuration. An empty Token keeps the
+	// token already saved.
+	Update(ctx context.Context, pipeline *Pipeline) error
+
+	// Delete deletes a pipeline. The builds it triggered are kept.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// BuildStore defines the interface for CI build persistence operations.
+type BuildStore interface {
+	// Create records a triggered build.
+	Create(ctx context.Context, build *Build) error
+
+	// ListByRun lists the builds triggered for a test run, newest first.
+	ListByRun(ctx context.Context, testRunID uuid.UUID) ([]*Build, error)
+}
@@ -0,0 +1,343 @@
+// Package client is a Go client for the UI Automation API. It wraps the REST
+// endpoints under /api/v1 in typed methods, authenticates with an API token,
+// retries rate-limited and transient failures, and iterates over paginated
+// list endpoints.
+//
+//	c := client.New("http://localhost:8080", os.Getenv("UI_AUTOMATION_TOKEN"))
+//	for p, err := range c.AllProjects(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(p.Name)
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultTimeout is the HTTP timeout used when no client is supplied.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMaxRetries is the number of retries after the first attempt.
+	DefaultMaxRetries = 2
+
+	// DefaultRetryBackoff is the wait before the first retry; it doubles on
+	// each subsequent retry unless the server sends Retry-After.
+	DefaultRetryBackoff = 500 * time.Millisecond
+
+	// maxRetryWait caps how long a single retry waits.
+	maxRetryWait = 30 * time.Second
+)
+
+// APIError represents an error response from the API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an API 404 response.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether err is an API 409 response.
+func IsConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// Client is an HTTP client for the UI Automation API. It is safe for
+// concurrent use.
+type Client struct {
+	baseURL      string
+	token        string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+	debug        io.Writer
+	sleep        func(ctx context.Context, d time.Duration) error
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying HTTP client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithRetries sets how many times a failed request is retried and the
+// initial backoff between attempts. A maxRetries of 0 disables retries.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithDebug writes each request and response to w.
+func WithDebug(w io.Writer) Option {
+	return func(c *Client) {
+		c.debug = w
+	}
+}
+
+// New creates a new client for the API at baseURL authenticating with the
+// given API token.
+func New(baseURL, token string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		token:        token,
+		httpClient:   &http.Client{Timeout: DefaultTimeout},
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
+		sleep:        sleepContext,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the API server URL the client talks to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Do sends a request to path (relative to the server root, e.g.
+// "/api/v1/projects") and decodes the JSON response into out. in, when not
+// nil, is sent as the JSON request body; out may be nil to discard the
+// response. Do is the escape hatch for endpoints without a typed method.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	body, err := c.doRaw(ctx, method, path, query, in)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// create sends a POST with a JSON body to a create endpoint that accepts an
+// Idempotency-Key header, and decodes the JSON response into out. A fresh
+// key is sent with the request and its retries, so it is retried like an
+// idempotent method: the server answers a retry of a request it already
+// carried out with the original response instead of creating a duplicate.
+func (c *Client) create(ctx context.Context, path string, in, out interface{}) error {
+	var payload []byte
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		payload = data
+		contentType = "application/json"
+	}
+	body, err := c.executeWithKey(ctx, http.MethodPost, path, nil, contentType, payload, uuid.NewString())
+	if err != nil {
+		return err
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// doRaw sends a request with an optional JSON body and returns the raw body
+// of a successful response.
+func (c *Client) doRaw(ctx context.Context, method, path string, query url.Values, in interface{}) ([]byte, error) {
+	var payload []byte
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		payload = data
+		contentType = "application/json"
+	}
+	return c.execute(ctx, method, path, query, contentType, payload)
+}
+
+// execute sends a request, retrying when allowed, and returns the raw body of
+// a successful response. The payload is held in memory so it can be resent.
+func (c *Client) execute(ctx context.Context, method, path string, query url.Values, contentType string, payload []byte) ([]byte, error) {
+	return c.executeWithKey(ctx, method, path, query, contentType, payload, "")
+}
+
+// executeWithKey is execute with an Idempotency-Key, if key is not empty.
+func (c *Client) executeWithKey(ctx context.Context, method, path string, query url.Values, contentType string, payload []byte, key string) ([]byte, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := c.send(ctx, method, u, contentType, payload, key)
+		if err == nil {
+			return body, nil
+		}
+		if attempt >= c.maxRetries || !retryable(method, key != "", err) {
+			return nil, err
+		}
+
+		wait := c.retryBackoff << attempt
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+		if c.debug != nil {
+			fmt.Fprintf(c.debug, "DEBUG: retrying in %s after: %v\n", wait, err)
+		}
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send performs a single HTTP round trip. For error responses it also
+// returns the server's Retry-After, if any.
+func (c *Client) send(ctx context.Context, method, u, contentType string, payload []byte, key string) ([]byte, time.Duration, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	if c.debug != nil {
+		fmt.Fprintf(c.debug, "DEBUG: %s %s\n", req.Method, req.URL.String())
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, &transportError{err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	if c.debug != nil {
+		fmt.Fprintf(c.debug, "DEBUG: Status %d\n", resp.StatusCode)
+		fmt.Fprintf(c.debug, "DEBUG: Body: %s\n", string(body))
+	}
+
+	if resp.StatusCode >= 400 {
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+
+		var errResp ErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return nil, retryAfter, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+		}
+		return nil, retryAfter, &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+
+	return body, 0, nil
+}
+
+// transportError wraps a failure to get a response from the server.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("request failed: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// retryable reports whether a failed request may be sent again. Rate-limited
+// requests are always retried since the server rejected them before doing
+// any work. Transport errors and gateway errors are only retried for
+// idempotent methods and requests sent with an idempotency key, so a POST
+// that may have been applied is not repeated.
+func retryable(method string, keyed bool, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return true
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return keyed || idempotent(method)
+		}
+		return false
+	}
+
+	var tErr *transportError
+	return errors.As(err, &tErr) && (keyed || idempotent(method))
+}
+
+// idempotent reports whether repeating a request with method has no
+// additional effect.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,590 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/stepsuggest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client for server that records retry waits
+// instead of sleeping.
+func newTestClient(server *httptest.Server, waits *[]time.Duration) *Client {
+	c := New(server.URL+"/", "test-token", WithRetries(2, 100*time.Millisecond))
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		if waits != nil {
+			*waits = append(*waits, d)
+		}
+		return nil
+	}
+	return c
+}
+
+func TestClient_Do(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sends bearer token and decodes response", func(t *testing.T) {
+		t.Parallel()
+		projectID := uuid.New()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+			assert.Equal(t, "/api/v1/projects/"+projectID.String(), r.URL.Path)
+			json.NewEncoder(w).Encode(Project{ID: projectID, Name: "Demo"})
+		}))
+		defer server.Close()
+
+		p, err := newTestClient(server, nil).GetProject(context.Background(), projectID)
+		require.NoError(t, err)
+		assert.Equal(t, projectID, p.ID)
+		assert.Equal(t, "Demo", p.Name)
+	})
+
+	t.Run("returns API errors", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "project not found"})
+		}))
+		defer server.Close()
+
+		_, err := newTestClient(server, nil).GetProject(context.Background(), uuid.New())
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "project not found", apiErr.Message)
+		assert.True(t, IsNotFound(err))
+	})
+
+	t.Run("omits body when there is no input", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Empty(t, body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(TestRun{ID: uuid.New()})
+		}))
+		defer server.Close()
+
+		_, err := newTestClient(server, nil).CreateRun(context.Background(), uuid.New(), nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_Retries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries rate limited requests using Retry-After", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", "3")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "rate limit exceeded"})
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(Job{ID: uuid.New()})
+		}))
+		defer server.Close()
+
+		var waits []time.Duration
+		_, err := newTestClient(server, &waits).CreateJob(context.Background(), CreateJobRequest{Type: "link_check"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		assert.Equal(t, []time.Duration{3 * time.Second}, waits)
+	})
+
+	t.Run("backs off exponentially and gives up", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		var waits []time.Duration
+		_, err := newTestClient(server, &waits).GetJob(context.Background(), uuid.New())
+		require.Error(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, waits)
+	})
+
+	t.Run("does not retry non-idempotent requests on server errors", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := newTestClient(server, nil).CreateProject(context.Background(), CreateProjectRequest{Name: "Demo"})
+		require.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("retries create requests with the same idempotency key", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		keys := make(chan string, 2)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys <- r.Header.Get("Idempotency-Key")
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(Job{ID: uuid.New()})
+		}))
+		defer server.Close()
+
+		_, err := newTestClient(server, nil).CreateJob(context.Background(), CreateJobRequest{Type: "link_check"})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		first, second := <-keys, <-keys
+		assert.NotEmpty(t, first)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		_, err := newTestClient(server, nil).GetJob(context.Background(), uuid.New())
+		require.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestClient_AllProjects(t *testing.T) {
+	t.Parallel()
+
+	const total = 250
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		page := Page[Project]{Total: total, Limit: limit, Offset: offset}
+		for i := offset; i < offset+limit && i < total; i++ {
+			page.Items = append(page.Items, Project{ID: uuid.New(), Name: strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server, nil)
+
+	var names []string
+	for p, err := range c.AllProjects(context.Background()) {
+		require.NoError(t, err)
+		names = append(names, p.Name)
+	}
+	require.Len(t, names, total)
+	assert.Equal(t, "0", names[0])
+	assert.Equal(t, "249", names[total-1])
+
+	t.Run("stops when the caller breaks", func(t *testing.T) {
+		count := 0
+		for range c.AllProjects(context.Background()) {
+			count++
+			if count == 5 {
+				break
+			}
+		}
+		assert.Equal(t, 5, count)
+	})
+
+	t.Run("yields errors", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer failing.Close()
+
+		var errs []error
+		for _, err := range newTestClient(failing, nil).AllProjects(context.Background()) {
+			errs = append(errs, err)
+		}
+		require.Len(t, errs, 1)
+		assert.Error(t, errs[0])
+	})
+}
+
+func TestClient_AllIssues(t *testing.T) {
+	t.Parallel()
+
+	integrationID := uuid.New()
+
+	t.Run("follows offsets", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/integrations/"+integrationID.String()+"/issues", r.URL.Path)
+			assert.Equal(t, "login", r.URL.Query().Get("query"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			page := ExternalIssuePage{Limit: 2, Offset: offset, HasMore: offset < 2}
+			for i := offset; i < offset+2; i++ {
+				page.Items = append(page.Items, ExternalIssue{ExternalID: strconv.Itoa(i)})
+			}
+			json.NewEncoder(w).Encode(page)
+		}))
+		defer server.Close()
+
+		var ids []string
+		opts := SearchIssuesOptions{ListOptions: ListOptions{Limit: 2}, Query: "login"}
+		for issue, err := range newTestClient(server, nil).AllIssues(context.Background(), integrationID, opts) {
+			require.NoError(t, err)
+			ids = append(ids, issue.ExternalID)
+		}
+		assert.Equal(t, []string{"0", "1", "2", "3"}, ids)
+	})
+
+	t.Run("follows cursors", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.URL.Query().Get("offset"))
+			page := ExternalIssuePage{Items: []ExternalIssue{{ExternalID: "a"}}, HasMore: true, NextCursor: "next"}
+			if r.URL.Query().Get("cursor") == "next" {
+				page = ExternalIssuePage{Items: []ExternalIssue{{ExternalID: "b"}}}
+			}
+			json.NewEncoder(w).Encode(page)
+		}))
+		defer server.Close()
+
+		var ids []string
+		for issue, err := range newTestClient(server, nil).AllIssues(context.Background(), integrationID, SearchIssuesOptions{}) {
+			require.NoError(t, err)
+			ids = append(ids, issue.ExternalID)
+		}
+		assert.Equal(t, []string{"a", "b"}, ids)
+	})
+}
+
+func TestClient_UploadRunAsset(t *testing.T) {
+	t.Parallel()
+
+	runID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/runs/"+runID.String()+"/assets", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "image", r.FormValue("asset_type"))
+		assert.Equal(t, "2", r.FormValue("step_index"))
+
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		assert.Equal(t, "shot.png", header.Filename)
+		assert.Equal(t, "image/png", header.Header.Get("Content-Type"))
+		assert.Equal(t, "png-bytes", string(content))
+
+		stepIndex := 2
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(RunAsset{ID: uuid.New(), TestRunID: runID, FileName: header.Filename, StepIndex: &stepIndex})
+	}))
+	defer server.Close()
+
+	stepIndex := 2
+	asset, err := newTestClient(server, nil).UploadRunAsset(context.Background(), runID, UploadRunAssetRequest{
+		AssetType: "image",
+		FileName:  "/tmp/screens/shot.png",
+		Content:   strings.NewReader("png-bytes"),
+		StepIndex: &stepIndex,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "shot.png", asset.FileName)
+	require.NotNil(t, asset.StepIndex)
+	assert.Equal(t, 2, *asset.StepIndex)
+}
+
+func TestClient_UploadStepImage(t *testing.T) {
+	t.Parallel()
+
+	procedureID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/procedures/"+procedureID.String()+"/steps/images", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		file, header, err := r.FormFile("image")
+		require.NoError(t, err)
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		assert.Equal(t, "login.png", header.Filename)
+		assert.Equal(t, "png-bytes", string(content))
+
+		json.NewEncoder(w).Encode(map[string]string{"image_path": "test-procedures/x/steps/abc.png"})
+	}))
+	defer server.Close()
+
+	path, err := newTestClient(server, nil).UploadStepImage(context.Background(), procedureID, "images/login.png", strings.NewReader("png-bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, "test-procedures/x/steps/abc.png", path)
+}
+
+func TestClient_UploadStepAttachment(t *testing.T) {
+	t.Parallel()
+
+	procedureID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/procedures/"+procedureID.String()+"/steps/attachments", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		assert.Equal(t, "items.csv", header.Filename)
+		assert.Equal(t, "sku,qty\n", string(content))
+
+		json.NewEncoder(w).Encode(Attachment{Path: "test-procedures/x/attachments/abc.csv", FileName: "items.csv", ContentType: "text/csv", FileSize: 8})
+	}))
+	defer server.Close()
+
+	a, err := newTestClient(server, nil).UploadStepAttachment(context.Background(), procedureID, "fixtures/items.csv", strings.NewReader("sku,qty\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "test-procedures/x/attachments/abc.csv", a.Path)
+	assert.Equal(t, "items.csv", a.FileName)
+}
+
+func TestClient_SuggestStepsFromImage(t *testing.T) {
+	t.Parallel()
+
+	procedureID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/procedures/"+procedureID.String()+"/steps/suggest", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		file, header, err := r.FormFile("image")
+		require.NoError(t, err)
+		defer file.Close()
+		assert.Equal(t, "login.png", header.Filename)
+		assert.Equal(t, "the login form", r.FormValue("hint"))
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"suggestions": []map[string]string{
+				{"name": "Sign in", "action": "click", "selector": "#submit", "instructions": "Click Sign in"},
+			},
+			"image_path": "test-procedures/x/steps/abc.png",
+			"draft": map[string]interface{}{
+				"id":    procedureID.String(),
+				"steps": []map[string]string{{"name": "Sign in", "instructions": "Click Sign in"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	s, err := newTestClient(server, nil).SuggestStepsFromImage(context.Background(), procedureID, "login.png", strings.NewReader("png-bytes"), "the login form")
+	require.NoError(t, err)
+	require.Len(t, s.Suggestions, 1)
+	assert.Equal(t, stepsuggest.ActionClick, s.Suggestions[0].Action)
+	assert.Equal(t, "#submit", s.Suggestions[0].Selector)
+	assert.Equal(t, "test-procedures/x/steps/abc.png", s.ImagePath)
+	assert.Len(t, s.Draft.Steps, 1)
+}
+
+func TestClient_ClaimRunnerJob(t *testing.T) {
+	t.Parallel()
+
+	jobID := uuid.New()
+	queued := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/runner/jobs/claim":
+			if !queued {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			queued = false
+			json.NewEncoder(w).Encode(RunnerJob{
+				Job:              Job{ID: jobID},
+				AgentConfig:      agent.AgentConfig{TargetURL: "http://10.0.0.5"},
+				TimeLimitSeconds: 600,
+			})
+		case "/api/v1/runner/jobs/" + jobID.String() + "/heartbeat":
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "job is no longer running"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(server, nil)
+	claimed, err := c.ClaimRunnerJob(context.Background(), uuid.Nil)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, jobID, claimed.Job.ID)
+	assert.Equal(t, "http://10.0.0.5", claimed.AgentConfig.TargetURL)
+	assert.Equal(t, 600, claimed.TimeLimitSeconds)
+
+	claimed, err = c.ClaimRunnerJob(context.Background(), uuid.Nil)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	err = c.SendRunnerHeartbeat(context.Background(), jobID, RunnerHeartbeatRequest{})
+	assert.True(t, IsConflict(err))
+}
+
+func TestClient_RegisterRunner(t *testing.T) {
+	t.Parallel()
+
+	runnerID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/runner/runners":
+			var req RegisterRunnerRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, []string{"needs-vpn"}, req.Labels)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(Runner{ID: runnerID, Name: req.Name, Labels: req.Labels, Online: true})
+		case "/api/v1/runner/jobs/claim":
+			var req ClaimRunnerJobRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.NotNil(t, req.RunnerID)
+			assert.Equal(t, runnerID, *req.RunnerID)
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/v1/runner/runners/" + runnerID.String() + "/heartbeat":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "runner not found"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(server, nil)
+	registered, err := c.RegisterRunner(context.Background(), RegisterRunnerRequest{Name: "office", Labels: []string{"needs-vpn"}})
+	require.NoError(t, err)
+	assert.Equal(t, runnerID, registered.ID)
+	assert.True(t, registered.Online)
+
+	claimed, err := c.ClaimRunnerJob(context.Background(), registered.ID)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	err = c.PingRunner(context.Background(), registered.ID)
+	assert.True(t, IsNotFound(err))
+}
+
+func TestClient_CompleteRunnerJob(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shots"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shots", "login.png"), []byte("png-bytes"), 0o644))
+
+	jobID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/runner/jobs/"+jobID.String()+"/result", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		var result agent.AgentResult
+		require.NoError(t, json.Unmarshal([]byte(r.FormValue("result")), &result))
+		assert.Equal(t, "Login", result.ProcedureName)
+
+		files := r.MultipartForm.File["screenshots"]
+		require.Len(t, files, 1)
+		assert.Equal(t, "login.png", files[0].Filename)
+		file, err := files[0].Open()
+		require.NoError(t, err)
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		assert.Equal(t, "png-bytes", string(content))
+
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "result saved"})
+	}))
+	defer server.Close()
+
+	step := agent.AgentStep{Name: "Log in", ImagePaths: []string{"shots/login.png", "shots/missing.png"}}
+	err := newTestClient(server, nil).CompleteRunnerJob(context.Background(), jobID, &agent.AgentResult{
+		ProcedureName: "Login",
+		Steps:         []agent.AgentStep{step},
+		Flows:         []agent.AgentFlow{{Name: "Login", Steps: []agent.AgentStep{step}}},
+	}, dir)
+	require.NoError(t, err)
+}
+
+func TestClient_GitHubActionsReport(t *testing.T) {
+	t.Parallel()
+
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/runs/report", r.URL.Path)
+		assert.Equal(t, "github-actions", r.URL.Query().Get("format"))
+		assert.Equal(t, []string{ids[0].String(), ids[1].String()}, r.URL.Query()["run_id"])
+		json.NewEncoder(w).Encode(GitHubActionsReport{Commands: "::error title=Login v1 failed::Test run failed\n", Summary: "## UI automation test runs\n"})
+	}))
+	defer server.Close()
+
+	report, err := newTestClient(server, nil).GitHubActionsReport(context.Background(), ids)
+	require.NoError(t, err)
+	assert.Equal(t, "::error title=Login v1 failed::Test run failed\n", report.Commands)
+	assert.Equal(t, "## UI automation test runs\n", report.Summary)
+}
+
+func TestClient_JUnitReport(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/runs/report", r.URL.Path)
+		assert.Equal(t, "junit", r.URL.Query().Get("format"))
+		assert.Equal(t, id.String(), r.URL.Query().Get("run_id"))
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte("<testsuites></testsuites>\n"))
+	}))
+	defer server.Close()
+
+	report, err := newTestClient(server, nil).JUnitReport(context.Background(), []uuid.UUID{id})
+	require.NoError(t, err)
+	assert.Equal(t, "<testsuites></testsuites>\n", string(report))
+}
+
+func TestClient_CommitProcedureDraft(t *testing.T) {
+	t.Parallel()
+
+	draftID, reviewID, versionID := uuid.New(), uuid.New(), uuid.New()
+	rev := Review{ID: reviewID, VersionID: versionID, Version: 2, Status: "pending"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/procedures/" + draftID.String() + "/draft/commit":
+			var req CommitDraftRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "Add coupon step", req.Message)
+
+			// The project requires reviews
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(rev)
+		case "/api/v1/reviews/" + reviewID.String():
+			json.NewEncoder(w).Encode(ReviewDetail{Review: rev, PendingVersion: TestProcedure{ID: versionID, Version: 2}})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := newTestClient(server, nil).CommitProcedureDraft(context.Background(), draftID, CommitDraftRequest{Message: "Add coupon step"})
+	require.NoError(t, err)
+	assert.Equal(t, versionID, p.ID)
+	assert.Equal(t, uint(2), p.Version)
+	assert.False(t, p.IsLatest)
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+)
+
+// ListEndpoints returns a page of the caller's endpoints.
+func (c *Client) ListEndpoints(ctx context.Context, opts *ListOptions) (*Page[Endpoint], error) {
+	var page Page[Endpoint]
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/endpoints", opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllEndpoints iterates over all of the caller's endpoints.
+func (c *Client) AllEndpoints(ctx context.Context) iter.Seq2[Endpoint, error] {
+	return paginate(ctx, c.ListEndpoints)
+}
+
+// CreateEndpoint creates a new endpoint.
+func (c *Client) CreateEndpoint(ctx context.Context, req CreateEndpointRequest) (*Endpoint, error) {
+	var ep Endpoint
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/endpoints", nil, req, &ep); err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+// GetEndpoint returns an endpoint by ID.
+func (c *Client) GetEndpoint(ctx context.Context, id uuid.UUID) (*Endpoint, error) {
+	var ep Endpoint
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/endpoints/"+id.String(), nil, nil, &ep); err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+// UpdateEndpoint updates the fields of an endpoint that are set in req.
+func (c *Client) UpdateEndpoint(ctx context.Context, id uuid.UUID, req UpdateEndpointRequest) (*Endpoint, error) {
+	var ep Endpoint
+	if err := c.Do(ctx, http.MethodPut, "/api/v1/endpoints/"+id.String(), nil, req, &ep); err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+// DeleteEndpoint deletes an endpoint.
+func (c *Client) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	return c.Do(ctx, http.MethodDelete, "/api/v1/endpoints/"+id.String(), nil, nil, nil)
+}
+
+// ListEndpointGroups returns the caller's endpoint groups with the endpoint
+// serving each environment.
+func (c *Client) ListEndpointGroups(ctx context.Context) ([]EndpointGroup, error) {
+	var resp listResponse[EndpointGroup]
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/endpoint-groups", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// GetEndpointHealth returns the health status, uptime and recent checks of
+// an endpoint.
+func (c *Client) GetEndpointHealth(ctx context.Context, id uuid.UUID) (*EndpointHealth, error) {
+	var health EndpointHealth
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/endpoints/"+id.String()+"/health", nil, nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// CheckEndpointHealth runs a health check against an endpoint immediately.
+func (c *Client) CheckEndpointHealth(ctx context.Context, id uuid.UUID) (*endpoint.HealthCheck, error) {
+	var check endpoint.HealthCheck
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/endpoints/"+id.String()+"/health/check", nil, nil, &check); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+// ListEndpointSecrets returns the secrets of an endpoint, without values.
+func (c *Client) ListEndpointSecrets(ctx context.Context, id uuid.UUID) ([]EndpointSecret, error) {
+	var resp listResponse[EndpointSecret]
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/endpoints/"+id.String()+"/secrets", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// SetEndpointSecret creates or replaces a secret of an endpoint.
+func (c *Client) SetEndpointSecret(ctx context.Context, id uuid.UUID, key, value string) (*EndpointSecret, error) {
+	req := struct {
+		Value string `json:"value"`
+	}{Value: value}
+
+	var secret EndpointSecret
+	path := "/api/v1/endpoints/" + id.String() + "/secrets/" + url.PathEscape(key)
+	if err := c.Do(ctx, http.MethodPut, path, nil, req, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// DeleteEndpointSecret deletes a secret of an endpoint.
+func (c *Client) DeleteEndpointSecret(ctx context.Context, id uuid.UUID, key string) error {
+	path := "/api/v1/endpoints/" + id.String() + "/secrets/" + url.PathEscape(key)
+	return c.Do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// SearchIssuesOptions filters an issue search and selects a page of it.
+// Cursor continues from ExternalIssuePage.NextCursor on trackers that page
+// by cursor, in place of Offset.
+type SearchIssuesOptions struct {
+	ListOptions
+	Query      string
+	Status     string
+	ProjectKey string
+	Repository string
+	Cursor     string
+}
+
+// query returns the options as URL query parameters.
+func (o *SearchIssuesOptions) query() url.Values {
+	if o == nil {
+		return url.Values{}
+	}
+	query := o.ListOptions.query()
+	for key, value := range map[string]string{
+		"query":       o.Query,
+		"status":      o.Status,
+		"project_key": o.ProjectKey,
+		"repository":  o.Repository,
+		"cursor":      o.Cursor,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	return query
+}
+
+// SearchIssues returns a page of the issues in an integration's tracker.
+func (c *Client) SearchIssues(ctx context.Context, integrationID uuid.UUID, opts *SearchIssuesOptions) (*ExternalIssuePage, error) {
+	var page ExternalIssuePage
+	path := fmt.Sprintf("/api/v1/integrations/%s/issues", integrationID)
+	if err := c.Do(ctx, http.MethodGet, path, opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllIssues iterates over every issue matching opts, following offsets or
+// cursors as the tracker pages. The offset and cursor in opts are the
+// starting point.
+func (c *Client) AllIssues(ctx context.Context, integrationID uuid.UUID, opts SearchIssuesOptions) iter.Seq2[ExternalIssue, error] {
+	return func(yield func(ExternalIssue, error) bool) {
+		if opts.Limit <= 0 {
+			opts.Limit = pageSize
+		}
+		for {
+			page, err := c.SearchIssues(ctx, integrationID, &opts)
+			if err != nil {
+				yield(ExternalIssue{}, err)
+				return
+			}
+			for _, issue := range page.Items {
+				if !yield(issue, nil) {
+					return
+				}
+			}
+			if !page.HasMore || len(page.Items) == 0 {
+				return
+			}
+			if page.NextCursor != "" {
+				opts.Cursor = page.NextCursor
+			} else {
+				opts.Offset += len(page.Items)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ListJobs returns a page of the caller's jobs.
+func (c *Client) ListJobs(ctx context.Context, opts *ListOptions) (*Page[Job], error) {
+	var page Page[Job]
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/jobs", opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllJobs iterates over all of the caller's jobs.
+func (c *Client) AllJobs(ctx context.Context) iter.Seq2[Job, error] {
+	return paginate(ctx, c.ListJobs)
+}
+
+// CreateJob creates and starts a new job.
+func (c *Client) CreateJob(ctx context.Context, req CreateJobRequest) (*Job, error) {
+	var j Job
+	if err := c.create(ctx, "/api/v1/jobs", req, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// GetJob returns a job by ID.
+func (c *Client) GetJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	var j Job
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/jobs/"+id.String(), nil, nil, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// StopJob stops a running job.
+func (c *Client) StopJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	var j Job
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/jobs/"+id.String()+"/stop", nil, nil, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// ListJobLogs returns the log lines of a job numbered after afterSeq, with
+// the job's current status. Pass the Seq of the last line seen to tail a log.
+func (c *Client) ListJobLogs(ctx context.Context, id uuid.UUID, afterSeq int) (*JobLogs, error) {
+	query := url.Values{}
+	if afterSeq > 0 {
+		query.Set("after", strconv.Itoa(afterSeq))
+	}
+
+	var logs JobLogs
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/jobs/"+id.String()+"/logs", query, nil, &logs); err != nil {
+		return nil, err
+	}
+	return &logs, nil
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// pageSize is the page size used by the All* iterators; it is the maximum
+// the API accepts.
+const pageSize = 100
+
+// ListOptions selects a page of a paginated list. Zero values use the
+// server defaults (20 items from offset 0).
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+// query returns the options as URL query parameters.
+func (o *ListOptions) query() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return query
+}
+
+// Page matches handlers.PaginatedResponse.
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// paginate walks every page returned by list, yielding items one at a time.
+// Iteration stops at the first error, which is yielded with a zero item.
+func paginate[T any](ctx context.Context, list func(ctx context.Context, opts *ListOptions) (*Page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		opts := &ListOptions{Limit: pageSize}
+		for {
+			page, err := list(ctx, opts)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			opts.Offset += len(page.Items)
+			if len(page.Items) == 0 || opts.Offset >= page.Total {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// procedurePath returns the path of a procedure within a project.
+func procedurePath(projectID, id uuid.UUID) string {
+	return fmt.Sprintf("/api/v1/projects/%s/procedures/%s", projectID, id)
+}
+
+// ListProcedures returns a page of the latest versions of a project's test
+// procedures, leaving out archived ones.
+func (c *Client) ListProcedures(ctx context.Context, projectID uuid.UUID, opts *ListOptions) (*Page[TestProcedure], error) {
+	return c.listProcedures(ctx, projectID, opts, false)
+}
+
+// ListProceduresIncludingArchived returns a page of the latest versions of
+// all of a project's test procedures, archived or not.
+func (c *Client) ListProceduresIncludingArchived(ctx context.Context, projectID uuid.UUID, opts *ListOptions) (*Page[TestProcedure], error) {
+	return c.listProcedures(ctx, projectID, opts, true)
+}
+
+func (c *Client) listProcedures(ctx context.Context, projectID uuid.UUID, opts *ListOptions, includeArchived bool) (*Page[TestProcedure], error) {
+	query := opts.query()
+	if includeArchived {
+		query.Set("include_archived", "true")
+	}
+
+	var page Page[TestProcedure]
+	path := fmt.Sprintf("/api/v1/projects/%s/procedures", projectID)
+	if err := c.Do(ctx, http.MethodGet, path, query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllProcedures iterates over all of a project's test procedures.
+func (c *Client) AllProcedures(ctx context.Context, projectID uuid.UUID) iter.Seq2[TestProcedure, error] {
+	return paginate(ctx, func(ctx context.Context, opts *ListOptions) (*Page[TestProcedure], error) {
+		return c.ListProcedures(ctx, projectID, opts)
+	})
+}
+
+// CreateProcedure creates a new test procedure in a project.
+func (c *Client) CreateProcedure(ctx context.Context, projectID uuid.UUID, req CreateTestProcedureRequest) (*TestProcedure, error) {
+	var p TestProcedure
+	path := fmt.Sprintf("/api/v1/projects/%s/procedures", projectID)
+	if err := c.Do(ctx, http.MethodPost, path, nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GenerateProcedure drafts a test procedure in a project from a
+// plain-English description of a flow. The procedure is created flagged for
+// review.
+func (c *Client) GenerateProcedure(ctx context.Context, projectID uuid.UUID, req GenerateProcedureRequest) (*TestProcedure, error) {
+	var p TestProcedure
+	path := fmt.Sprintf("/api/v1/projects/%s/procedures/generate", projectID)
+	if err := c.Do(ctx, http.MethodPost, path, nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetProcedure returns a test procedure by ID. When draft is true the
+// procedure's working draft is returned instead of the committed version.
+func (c *Client) GetProcedure(ctx context.Context, projectID, id uuid.UUID, draft bool) (*TestProcedure, error) {
+	query := url.Values{}
+	if draft {
+		query.Set("draft", "true")
+	}
+
+	var p TestProcedure
+	if err := c.Do(ctx, http.MethodGet, procedurePath(projectID, id), query, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdateProcedure updates the draft of a test procedure with the fields set
+// in req.
+func (c *Client) UpdateProcedure(ctx context.Context, projectID, id uuid.UUID, req UpdateTestProcedureRequest) (*TestProcedure, error) {
+	var p TestProcedure
+	if err := c.Do(ctx, http.MethodPut, procedurePath(projectID, id), nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeleteProcedure deletes a test procedure.
+func (c *Client) DeleteProcedure(ctx context.Context, projectID, id uuid.UUID) error {
+	return c.Do(ctx, http.MethodDelete, procedurePath(projectID, id), nil, nil, nil)
+}
+
+// ArchiveProcedure archives a test procedure, hiding it from lists and
+// blocking new runs of it, and returns its latest version.
+func (c *Client) ArchiveProcedure(ctx context.Context, projectID, id uuid.UUID) (*TestProcedure, error) {
+	var p TestProcedure
+	if err := c.Do(ctx, http.MethodPost, procedurePath(projectID, id)+"/archive", nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UnarchiveProcedure restores an archived test procedure and returns its
+// latest version.
+func (c *Client) UnarchiveProcedure(ctx context.Context, projectID, id uuid.UUID) (*TestProcedure, error) {
+	var p TestProcedure
+	if err := c.Do(ctx, http.MethodPost, procedurePath(projectID, id)+"/unarchive", nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreateProcedureVersion commits the procedure's draft as a new version.
+func (c *Client) CreateProcedureVersion(ctx context.Context, projectID, id uuid.UUID) (*TestProcedure, error) {
+	var p TestProcedure
+	if err := c.Do(ctx, http.MethodPost, procedurePath(projectID, id)+"/versions", nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListProcedureVersions returns the version history of a test procedure.
+func (c *Client) ListProcedureVersions(ctx context.Context, projectID, id uuid.UUID) ([]TestProcedure, error) {
+	var versions []TestProcedure
+	if err := c.Do(ctx, http.MethodGet, procedurePath(projectID, id)+"/versions", nil, nil, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// CommitProcedureDraft commits the procedure's working draft as a new
+// version described by req. In projects that require reviews the version is
+// pending review, with IsLatest false, until it is approved.
+func (c *Client) CommitProcedureDraft(ctx context.Context, id uuid.UUID, req CommitDraftRequest) (*TestProcedure, error) {
+	// Projects that require reviews respond with the review instead
+	var resp struct {
+		TestProcedure
+		VersionID *uuid.UUID `json:"version_id"`
+	}
+	path := "/api/v1/procedures/" + id.String() + "/draft/commit"
+	if err := c.Do(ctx, http.MethodPost, path, nil, req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.VersionID != nil {
+		rev, err := c.GetReview(ctx, resp.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &rev.PendingVersion, nil
+	}
+	return &resp.TestProcedure, nil
+}
+
+// ListDraftSnapshots returns the snapshots kept of the procedure's draft,
+// newest first.
+func (c *Client) ListDraftSnapshots(ctx context.Context, id uuid.UUID) ([]DraftSnapshot, error) {
+	var resp struct {
+		Items []DraftSnapshot `json:"items"`
+	}
+	path := "/api/v1/procedures/" + id.String() + "/draft/snapshots"
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// RestoreDraftSnapshot sets the procedure's draft back to a snapshot and
+// returns the draft.
+func (c *Client) RestoreDraftSnapshot(ctx context.Context, id, snapshotID uuid.UUID) (*TestProcedure, error) {
+	var p TestProcedure
+	path := "/api/v1/procedures/" + id.String() + "/draft/snapshots/" + snapshotID.String() + "/restore"
+	if err := c.Do(ctx, http.MethodPost, path, nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UploadStepImage uploads an image for use in a procedure step and returns
+// the stored path to reference from Step.ImagePaths. The path is derived
+// from the image content, so uploading the same image again returns the
+// same path.
+func (c *Client) UploadStepImage(ctx context.Context, id uuid.UUID, fileName string, content io.Reader) (string, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	part, err := form.CreateFormFile("image", filepath.Base(fileName))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	path := "/api/v1/procedures/" + id.String() + "/steps/images"
+	body, err := c.execute(ctx, http.MethodPost, path, nil, form.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		ImagePath string `json:"image_path"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return resp.ImagePath, nil
+}
+
+// UploadStepAttachment uploads a file to attach to a procedure step and
+// returns the attachment to add to Step.Attachments.
+func (c *Client) UploadStepAttachment(ctx context.Context, id uuid.UUID, fileName string, content io.Reader) (*Attachment, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	part, err := form.CreateFormFile("file", filepath.Base(fileName))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	path := "/api/v1/procedures/" + id.String() + "/steps/attachments"
+	body, err := c.execute(ctx, http.MethodPost, path, nil, form.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var a Attachment
+	if err := json.Unmarshal(body, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &a, nil
+}
+
+// DownloadStepAttachment returns the contents of the attachment at
+// attachmentIndex of the step at stepIndex of a procedure version or draft.
+func (c *Client) DownloadStepAttachment(ctx context.Context, id uuid.UUID, stepIndex, attachmentIndex int) ([]byte, error) {
+	path := fmt.Sprintf("/api/v1/procedures/%s/steps/%d/attachments/%d", id, stepIndex, attachmentIndex)
+	return c.doRaw(ctx, http.MethodGet, path, nil, nil)
+}
+
+// SuggestSteps has the server capture a page of an endpoint and appends the
+// steps the model proposes for it to the procedure's draft.
+func (c *Client) SuggestSteps(ctx context.Context, id uuid.UUID, req SuggestStepsRequest) (*StepSuggestions, error) {
+	var s StepSuggestions
+	path := "/api/v1/procedures/" + id.String() + "/steps/suggest"
+	if err := c.Do(ctx, http.MethodPost, path, nil, req, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SuggestStepsFromImage uploads a screenshot of the page under test and
+// appends the steps the model proposes for it to the procedure's draft.
+// hint may be empty.
+func (c *Client) SuggestStepsFromImage(ctx context.Context, id uuid.UUID, fileName string, content io.Reader, hint string) (*StepSuggestions, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	part, err := form.CreateFormFile("image", filepath.Base(fileName))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if hint != "" {
+		if err := form.WriteField("hint", hint); err != nil {
+			return nil, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	path := "/api/v1/procedures/" + id.String() + "/steps/suggest"
+	body, err := c.execute(ctx, http.MethodPost, path, nil, form.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var s StepSuggestions
+	if err := json.Unmarshal(body, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// ListProjects returns a page of the caller's projects.
+func (c *Client) ListProjects(ctx context.Context, opts *ListOptions) (*Page[Project], error) {
+	var page Page[Project]
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/projects", opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllProjects iterates over all of the caller's projects.
+func (c *Client) AllProjects(ctx context.Context) iter.Seq2[Project, error] {
+	return paginate(ctx, c.ListProjects)
+}
+
+// CreateProject creates a new project.
+func (c *Client) CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error) {
+	var p Project
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/projects", nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetProject returns a project by ID.
+func (c *Client) GetProject(ctx context.Context, id uuid.UUID) (*Project, error) {
+	var p Project
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/projects/"+id.String(), nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdateProject updates the fields of a project that are set in req.
+func (c *Client) UpdateProject(ctx context.Context, id uuid.UUID, req UpdateProjectRequest) (*Project, error) {
+	var p Project
+	if err := c.Do(ctx, http.MethodPut, "/api/v1/projects/"+id.String(), nil, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeleteProject deletes a project.
+func (c *Client) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	return c.Do(ctx, http.MethodDelete, "/api/v1/projects/"+id.String(), nil, nil, nil)
+}
+
+// GetProjectStorageUsage returns the storage a project uses and its quota.
+func (c *Client) GetProjectStorageUsage(ctx context.Context, id uuid.UUID) (*StorageUsage, error) {
+	var usage StorageUsage
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/projects/"+id.String()+"/storage-usage", nil, nil, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// GetRetentionPolicy returns a project's retention policy.
+func (c *Client) GetRetentionPolicy(ctx context.Context, id uuid.UUID) (*RetentionPolicy, error) {
+	var policy RetentionPolicy
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/projects/"+id.String()+"/retention", nil, nil, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SetRetentionPolicy sets how many days a project's run assets are kept. 0
+// keeps them forever.
+func (c *Client) SetRetentionPolicy(ctx context.Context, id uuid.UUID, assetRetentionDays int) (*RetentionPolicy, error) {
+	var policy RetentionPolicy
+	req := UpdateRetentionPolicyRequest{AssetRetentionDays: assetRetentionDays}
+	if err := c.Do(ctx, http.MethodPut, "/api/v1/projects/"+id.String()+"/retention", nil, req, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// PreviewRetention reports the assets a retention policy would delete now
+// without deleting them. If assetRetentionDays is nil the project's saved
+// policy is previewed.
+func (c *Client) PreviewRetention(ctx context.Context, id uuid.UUID, assetRetentionDays *int) (*RetentionReport, error) {
+	q := url.Values{}
+	if assetRetentionDays != nil {
+		q.Set("asset_retention_days", fmt.Sprint(*assetRetentionDays))
+	}
+	var report RetentionReport
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/projects/"+id.String()+"/retention/preview", q, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ExportProject returns a project's backup archive. The archive is returned
+// as raw JSON so it can be stored and passed to ImportProject unchanged.
+func (c *Client) ExportProject(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	return c.doRaw(ctx, http.MethodGet, "/api/v1/projects/"+id.String()+"/export", nil, nil)
+}
+
+// ImportProject creates a new project from an archive returned by
+// ExportProject. If name is not empty it replaces the archived project name.
+func (c *Client) ImportProject(ctx context.Context, archive []byte, name string) (*ProjectImport, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+
+	body, err := c.execute(ctx, http.MethodPost, "/api/v1/projects/import", query, "application/json", archive)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ProjectImport
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/review"
+)
+
+// reviewPath returns the path of a procedure review.
+func reviewPath(id uuid.UUID) string {
+	return "/api/v1/reviews/" + id.String()
+}
+
+// RequestProcedureReview commits the procedure's working draft as a version
+// pending review, whether or not the project requires reviews.
+func (c *Client) RequestProcedureReview(ctx context.Context, id uuid.UUID, req RequestReviewRequest) (*Review, error) {
+	var rev Review
+	path := "/api/v1/procedures/" + id.String() + "/reviews"
+	if err := c.Do(ctx, http.MethodPost, path, nil, req, &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// ListProcedureReviews returns the reviews of a procedure, most recent
+// first.
+func (c *Client) ListProcedureReviews(ctx context.Context, id uuid.UUID) ([]Review, error) {
+	var resp listResponse[Review]
+	path := "/api/v1/procedures/" + id.String() + "/reviews"
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// ListReviews returns the reviews the caller was asked for, most recent
+// first. An empty status lists them all.
+func (c *Client) ListReviews(ctx context.Context, status review.Status) ([]Review, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", string(status))
+	}
+
+	var resp listResponse[Review]
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/reviews", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// GetReview returns a review with the version under review and the
+// procedure's latest version.
+func (c *Client) GetReview(ctx context.Context, id uuid.UUID) (*ReviewDetail, error) {
+	var rev ReviewDetail
+	if err := c.Do(ctx, http.MethodGet, reviewPath(id), nil, nil, &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// ApproveReview approves a review, publishing the version under review as
+// the procedure's latest version.
+func (c *Client) ApproveReview(ctx context.Context, id uuid.UUID, comment string) (*Review, error) {
+	return c.reviewAction(ctx, id, "approve", map[string]string{"comment": comment})
+}
+
+// RejectReview rejects a review, leaving the version under review
+// unpublished.
+func (c *Client) RejectReview(ctx context.Context, id uuid.UUID, comment string) (*Review, error) {
+	return c.reviewAction(ctx, id, "reject", map[string]string{"comment": comment})
+}
+
+// WithdrawReview withdraws a review the caller requested.
+func (c *Client) WithdrawReview(ctx context.Context, id uuid.UUID) (*Review, error) {
+	return c.reviewAction(ctx, id, "withdraw", nil)
+}
+
+// reviewAction posts in to an action on a review.
+func (c *Client) reviewAction(ctx context.Context, id uuid.UUID, action string, in interface{}) (*Review, error) {
+	var rev Review
+	if err := c.Do(ctx, http.MethodPost, reviewPath(id)+"/"+action, nil, in, &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+)
+
+// runnerJobPath returns the API path of a job claimed by an agent runner.
+func runnerJobPath(id uuid.UUID, action string) string {
+	return "/api/v1/runner/jobs/" + id.String() + "/" + action
+}
+
+// RegisterRunner registers an agent runner with the labels that decide
+// which endpoints' jobs it may claim.
+func (c *Client) RegisterRunner(ctx context.Context, req RegisterRunnerRequest) (*Runner, error) {
+	var r Runner
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/runner/runners", nil, req, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListRunners lists the caller's registered agent runners.
+func (c *Client) ListRunners(ctx context.Context) ([]Runner, error) {
+	var runners []Runner
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/runner/runners", nil, nil, &runners); err != nil {
+		return nil, err
+	}
+	return runners, nil
+}
+
+// PingRunner reports that a registered runner is still running. It
+// fails with a not found error (see IsNotFound) once the runner has been
+// deregistered.
+func (c *Client) PingRunner(ctx context.Context, runnerID uuid.UUID) error {
+	return c.Do(ctx, http.MethodPost, "/api/v1/runner/runners/"+runnerID.String()+"/heartbeat", nil, nil, nil)
+}
+
+// DeregisterRunner deregisters a runner.
+func (c *Client) DeregisterRunner(ctx context.Context, runnerID uuid.UUID) error {
+	return c.Do(ctx, http.MethodDelete, "/api/v1/runner/runners/"+runnerID.String(), nil, nil, nil)
+}
+
+// ClaimRunnerJob claims the caller's oldest queued job against an endpoint
+// that uses agent runners and whose required labels the registered runner
+// runnerID has. A runner that has not registered passes uuid.Nil and only
+// claims the jobs of endpoints requiring no labels. It returns nil when
+// there is no such job.
+func (c *Client) ClaimRunnerJob(ctx context.Context, runnerID uuid.UUID) (*RunnerJob, error) {
+	var req interface{}
+	if runnerID != uuid.Nil {
+		req = ClaimRunnerJobRequest{RunnerID: &runnerID}
+	}
+	body, err := c.doRaw(ctx, http.MethodPost, "/api/v1/runner/jobs/claim", nil, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var j RunnerJob
+	if err := json.Unmarshal(body, &j); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &j, nil
+}
+
+// SendRunnerHeartbeat reports that a claimed job is still running. It fails
+// with a conflict error (see IsConflict) once the job is no longer running,
+// such as when it was stopped, and the runner should then abandon it.
+func (c *Client) SendRunnerHeartbeat(ctx context.Context, id uuid.UUID, req RunnerHeartbeatRequest) error {
+	return c.Do(ctx, http.MethodPost, runnerJobPath(id, "heartbeat"), nil, req, nil)
+}
+
+// AppendRunnerLogs appends the agent's log lines to a claimed job's log.
+func (c *Client) AppendRunnerLogs(ctx context.Context, id uuid.UUID, lines []string) error {
+	req := struct {
+		Lines []string `json:"lines"`
+	}{Lines: lines}
+	return c.Do(ctx, http.MethodPost, runnerJobPath(id, "logs"), nil, req, nil)
+}
+
+// CompleteRunnerJob uploads the agent's result for a claimed job, with the
+// screenshots it refers to read from outputDir, the agent's output
+// directory.
+func (c *Client) CompleteRunnerJob(ctx context.Context, id uuid.UUID, result *agent.AgentResult, outputDir string) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	if err := form.WriteField("result", string(resultJSON)); err != nil {
+		return err
+	}
+
+	// Screenshots are sent under their base names, which the server
+	// resolves the result's image paths against.
+	sent := make(map[string]bool)
+	addScreenshots := func(steps []agent.AgentStep) error {
+		for _, step := range steps {
+			for _, imgPath := range step.ImagePaths {
+				name := filepath.Base(imgPath)
+				if sent[name] {
+					continue
+				}
+				f, err := os.Open(filepath.Join(outputDir, imgPath))
+				if os.IsNotExist(err) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				part, err := form.CreateFormFile("screenshots", name)
+				if err == nil {
+					_, err = io.Copy(part, f)
+				}
+				f.Close()
+				if err != nil {
+					return fmt.Errorf("failed to read screenshot: %w", err)
+				}
+				sent[name] = true
+			}
+		}
+		return nil
+	}
+	if err := addScreenshots(result.Steps); err != nil {
+		return err
+	}
+	for _, flow := range result.Flows {
+		if err := addScreenshots(flow.Steps); err != nil {
+			return err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	_, err = c.execute(ctx, http.MethodPost, runnerJobPath(id, "result"), nil, form.FormDataContentType(), buf.Bytes())
+	return err
+}
+
+// FailRunnerJob fails a claimed job the runner could not run.
+func (c *Client) FailRunnerJob(ctx context.Context, id uuid.UUID, reason string) error {
+	req := struct {
+		Error string `json:"error"`
+	}{Error: reason}
+	return c.Do(ctx, http.MethodPost, runnerJobPath(id, "fail"), nil, req, nil)
+}
@@ -0,0 +1,310 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListRuns returns a page of the test runs of a procedure.
+func (c *Client) ListRuns(ctx context.Context, procedureID uuid.UUID, opts *ListOptions) (*Page[TestRun], error) {
+	var page Page[TestRun]
+	path := fmt.Sprintf("/api/v1/procedures/%s/runs", procedureID)
+	if err := c.Do(ctx, http.MethodGet, path, opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllRuns iterates over all test runs of a procedure.
+func (c *Client) AllRuns(ctx context.Context, procedureID uuid.UUID) iter.Seq2[TestRun, error] {
+	return paginate(ctx, func(ctx context.Context, opts *ListOptions) (*Page[TestRun], error) {
+		return c.ListRuns(ctx, procedureID, opts)
+	})
+}
+
+// CreateRun creates a test run against the latest committed version of a
+// procedure. target optionally selects the endpoint the run executes against.
+func (c *Client) CreateRun(ctx context.Context, procedureID uuid.UUID, target *EndpointTarget) (*TestRun, error) {
+	var in interface{}
+	if target != nil {
+		in = target
+	}
+	return c.createRun(ctx, procedureID, in)
+}
+
+// CreateRunWith creates a test run against the latest committed version of
+// a procedure, with the endpoint, parameter values and environment details
+// given in req.
+func (c *Client) CreateRunWith(ctx context.Context, procedureID uuid.UUID, req CreateRunRequest) (*TestRun, error) {
+	return c.createRun(ctx, procedureID, req)
+}
+
+// createRun posts a test run creation request body for a procedure.
+func (c *Client) createRun(ctx context.Context, procedureID uuid.UUID, in interface{}) (*TestRun, error) {
+	var r TestRun
+	path := fmt.Sprintf("/api/v1/procedures/%s/runs", procedureID)
+	if err := c.create(ctx, path, in, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetRunTemplate returns the run template of a procedure.
+func (c *Client) GetRunTemplate(ctx context.Context, procedureID uuid.UUID) (*RunTemplate, error) {
+	var t RunTemplate
+	path := fmt.Sprintf("/api/v1/procedures/%s/run-template", procedureID)
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SaveRunTemplate sets the run template new runs of a procedure are set up
+// from, replacing the one it has.
+func (c *Client) SaveRunTemplate(ctx context.Context, procedureID uuid.UUID, req SaveRunTemplateRequest) (*RunTemplate, error) {
+	var t RunTemplate
+	path := fmt.Sprintf("/api/v1/procedures/%s/run-template", procedureID)
+	if err := c.Do(ctx, http.MethodPut, path, nil, req, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteRunTemplate deletes the run template of a procedure.
+func (c *Client) DeleteRunTemplate(ctx context.Context, procedureID uuid.UUID) error {
+	path := fmt.Sprintf("/api/v1/procedures/%s/run-template", procedureID)
+	return c.Do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+// GetRun returns a test run by ID.
+func (c *Client) GetRun(ctx context.Context, id uuid.UUID) (*TestRun, error) {
+	var r TestRun
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/runs/"+id.String(), nil, nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UpdateRun updates the fields of a test run that are set in req.
+func (c *Client) UpdateRun(ctx context.Context, id uuid.UUID, req UpdateTestRunRequest) (*TestRun, error) {
+	var r TestRun
+	if err := c.Do(ctx, http.MethodPut, "/api/v1/runs/"+id.String(), nil, req, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// PauseRun marks a running test run as paused, with an optional reason.
+func (c *Client) PauseRun(ctx context.Context, id uuid.UUID, reason string) (*TestRun, error) {
+	return c.changeRunStatus(ctx, id, "pause", RunStatusReasonRequest{Reason: reason})
+}
+
+// ResumeRun marks a paused test run as running again.
+func (c *Client) ResumeRun(ctx context.Context, id uuid.UUID) (*TestRun, error) {
+	return c.changeRunStatus(ctx, id, "resume", nil)
+}
+
+// BlockRun marks a test run that has not completed as blocked for the
+// reason.
+func (c *Client) BlockRun(ctx context.Context, id uuid.UUID, reason string) (*TestRun, error) {
+	return c.changeRunStatus(ctx, id, "block", RunStatusReasonRequest{Reason: reason})
+}
+
+// UnblockRun returns a blocked test run to running, or to pending if it had
+// not started.
+func (c *Client) UnblockRun(ctx context.Context, id uuid.UUID) (*TestRun, error) {
+	return c.changeRunStatus(ctx, id, "unblock", nil)
+}
+
+// changeRunStatus posts a status change action for a test run.
+func (c *Client) changeRunStatus(ctx context.Context, id uuid.UUID, action string, in interface{}) (*TestRun, error) {
+	var r TestRun
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/runs/"+id.String()+"/"+action, nil, in, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ClaimRun assigns an unassigned test run to the caller.
+func (c *Client) ClaimRun(ctx context.Context, id uuid.UUID) (*TestRun, error) {
+	var r TestRun
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/runs/"+id.String()+"/claim", nil, nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// RunQueueOptions filters the caller's run queue and selects a page of it.
+type RunQueueOptions struct {
+	ListOptions
+	IncludeCompleted bool
+	DueBefore        *time.Time
+}
+
+// query returns the options as URL query parameters.
+func (o *RunQueueOptions) query() url.Values {
+	if o == nil {
+		return url.Values{}
+	}
+	query := o.ListOptions.query()
+	if o.IncludeCompleted {
+		query.Set("include_completed", "true")
+	}
+	if o.DueBefore != nil {
+		query.Set("due_before", o.DueBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListRunQueue returns a page of the test runs assigned to the caller across
+// projects, soonest due first.
+func (c *Client) ListRunQueue(ctx context.Context, opts *RunQueueOptions) (*Page[QueuedRun], error) {
+	var page Page[QueuedRun]
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/runs/queue", opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// StartRun marks a pending test run as running. Runs of procedures with
+// preconditions only start if req acknowledges them.
+func (c *Client) StartRun(ctx context.Context, id uuid.UUID, req StartTestRunRequest) (*TestRun, error) {
+	var r TestRun
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/runs/"+id.String()+"/start", nil, req, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// CompleteRun finishes a running test run with a final status.
+func (c *Client) CompleteRun(ctx context.Context, id uuid.UUID, req CompleteTestRunRequest) (*TestRun, error) {
+	var r TestRun
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/runs/"+id.String()+"/complete", nil, req, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetRunProcedure returns the procedure a test run executes. Started runs
+// return the procedure as it was when the run started.
+func (c *Client) GetRunProcedure(ctx context.Context, id uuid.UUID) (*TestProcedure, error) {
+	var p TestProcedure
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/runs/"+id.String()+"/procedure", nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GitHubActionsReport reports test runs to a GitHub Actions job: workflow
+// commands annotating failed and unfinished runs, and a job summary.
+func (c *Client) GitHubActionsReport(ctx context.Context, ids []uuid.UUID) (*GitHubActionsReport, error) {
+	query := url.Values{"format": {"github-actions"}}
+	for _, id := range ids {
+		query.Add("run_id", id.String())
+	}
+
+	var report GitHubActionsReport
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/runs/report", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// JUnitReport returns test runs as a JUnit XML document with a test suite
+// per run.
+func (c *Client) JUnitReport(ctx context.Context, ids []uuid.UUID) ([]byte, error) {
+	query := url.Values{"format": {"junit"}}
+	for _, id := range ids {
+		query.Add("run_id", id.String())
+	}
+	return c.doRaw(ctx, http.MethodGet, "/api/v1/runs/report", query, nil)
+}
+
+// ListStepNotes returns the step notes of a test run.
+func (c *Client) ListStepNotes(ctx context.Context, id uuid.UUID) ([]StepNote, error) {
+	var notes []StepNote
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/runs/"+id.String()+"/steps/notes", nil, nil, &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// SetStepNote creates or replaces the note on a step (0-based) of a test run.
+func (c *Client) SetStepNote(ctx context.Context, id uuid.UUID, stepIndex int, notes string) (*StepNote, error) {
+	req := struct {
+		Notes string `json:"notes"`
+	}{Notes: notes}
+
+	var note StepNote
+	path := fmt.Sprintf("/api/v1/runs/%s/steps/%d/notes", id, stepIndex)
+	if err := c.Do(ctx, http.MethodPut, path, nil, req, &note); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// ListRunAssets returns the files attached to a test run.
+func (c *Client) ListRunAssets(ctx context.Context, id uuid.UUID) ([]RunAsset, error) {
+	var assets []RunAsset
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/runs/"+id.String()+"/assets", nil, nil, &assets); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// UploadRunAsset attaches a file to a test run.
+func (c *Client) UploadRunAsset(ctx context.Context, id uuid.UUID, req UploadRunAssetRequest) (*RunAsset, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	form.WriteField("asset_type", string(req.AssetType))
+	if req.Description != "" {
+		form.WriteField("description", req.Description)
+	}
+	if req.StepIndex != nil {
+		form.WriteField("step_index", strconv.Itoa(*req.StepIndex))
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(req.FileName))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filepath.Base(req.FileName)))
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, req.Content); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	body, err := c.execute(ctx, http.MethodPost, "/api/v1/runs/"+id.String()+"/assets", nil, form.FormDataContentType(), buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var asset RunAsset
+	if err := json.Unmarshal(body, &asset); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &asset, nil
+}
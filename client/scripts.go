@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// ListScripts returns a page of the scripts generated for a procedure.
+func (c *Client) ListScripts(ctx context.Context, procedureID uuid.UUID, opts *ListOptions) (*Page[Script], error) {
+	var page Page[Script]
+	path := fmt.Sprintf("/api/v1/procedures/%s/scripts", procedureID)
+	if err := c.Do(ctx, http.MethodGet, path, opts.query(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllScripts iterates over all scripts generated for a procedure.
+func (c *Client) AllScripts(ctx context.Context, procedureID uuid.UUID) iter.Seq2[Script, error] {
+	return paginate(ctx, func(ctx context.Context, opts *ListOptions) (*Page[Script], error) {
+		return c.ListScripts(ctx, procedureID, opts)
+	})
+}
+
+// GenerateScript starts generating an automation script for a procedure.
+// Generation runs in the background; poll GetScript until the script's
+// generation status is completed or failed.
+func (c *Client) GenerateScript(ctx context.Context, procedureID uuid.UUID, req GenerateScriptRequest) (*Script, error) {
+	var s Script
+	path := fmt.Sprintf("/api/v1/procedures/%s/scripts", procedureID)
+	if err := c.create(ctx, path, req, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetScript returns a generated script by ID.
+func (c *Client) GetScript(ctx context.Context, id uuid.UUID) (*Script, error) {
+	var s Script
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/scripts/"+id.String(), nil, nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DownloadScript returns the contents of a generated script.
+func (c *Client) DownloadScript(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	return c.doRaw(ctx, http.MethodGet, "/api/v1/scripts/"+id.String()+"/download", nil, nil)
+}
+
+// DeleteScript deletes a generated script.
+func (c *Client) DeleteScript(ctx context.Context, id uuid.UUID) error {
+	return c.Do(ctx, http.MethodDelete, "/api/v1/scripts/"+id.String(), nil, nil, nil)
+}
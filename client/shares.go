@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/procedureshare"
+)
+
+// procedureSharePath returns the path of a procedure's share with a user.
+func procedureSharePath(procedureID, userID uuid.UUID) string {
+	return "/api/v1/procedures/" + procedureID.String() + "/shares/" + userID.String()
+}
+
+// ListProcedureShares returns the users a procedure is shared with, oldest
+// share first.
+func (c *Client) ListProcedureShares(ctx context.Context, procedureID uuid.UUID) ([]ProcedureShare, error) {
+	var resp listResponse[ProcedureShare]
+	path := "/api/v1/procedures/" + procedureID.String() + "/shares"
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// ShareProcedure shares a procedure with a user outside its project, or
+// changes the access they have to it.
+func (c *Client) ShareProcedure(ctx context.Context, procedureID, userID uuid.UUID, access procedureshare.Access) (*ProcedureShare, error) {
+	var share ProcedureShare
+	body := map[string]procedureshare.Access{"access": access}
+	if err := c.Do(ctx, http.MethodPut, procedureSharePath(procedureID, userID), nil, body, &share); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// UnshareProcedure stops sharing a procedure with a user.
+func (c *Client) UnshareProcedure(ctx context.Context, procedureID, userID uuid.UUID) error {
+	return c.Do(ctx, http.MethodDelete, procedureSharePath(procedureID, userID), nil, nil, nil)
+}
+
+// ListSharedProcedures returns the procedures shared with the caller, most
+// recently shared first.
+func (c *Client) ListSharedProcedures(ctx context.Context) ([]SharedProcedure, error) {
+	var resp listResponse[SharedProcedure]
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/procedures/shared", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListTokens returns the caller's API tokens.
+func (c *Client) ListTokens(ctx context.Context) (*TokenListResponse, error) {
+	var resp TokenListResponse
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/tokens", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateToken creates a new API token. The raw token is only returned here.
+func (c *Client) CreateToken(ctx context.Context, req CreateTokenRequest) (*CreateTokenResponse, error) {
+	var resp CreateTokenResponse
+	if err := c.Do(ctx, http.MethodPost, "/api/v1/tokens", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RevokeToken revokes an API token.
+func (c *Client) RevokeToken(ctx context.Context, id string) error {
+	return c.Do(ctx, http.MethodDelete, "/api/v1/tokens/"+id, nil, nil, nil)
+}
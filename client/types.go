@@ -0,0 +1,680 @@
+package client
+
+import (
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/procedureshare"
+	"github.com/hairizuanbinnoorazman/ui-automation/review"
+	"github.com/hairizuanbinnoorazman/ui-automation/stepsuggest"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// ErrorResponse matches handlers.ErrorResponse.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// SuccessResponse matches handlers.SuccessResponse.
+type SuccessResponse struct {
+	Message string `json:"message"`
+}
+
+// Project is a project as returned by the API.
+type Project struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	IsActive    bool      `json:"is_active"`
+	// RequireProcedureReview holds committed procedure changes for review.
+	RequireProcedureReview bool      `json:"require_procedure_review"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// ProjectImport matches handlers.ImportProjectResponse.
+type ProjectImport struct {
+	Project       Project `json:"project"`
+	Procedures    int     `json:"procedures"`
+	Runs          int     `json:"runs"`
+	StepNotes     int     `json:"step_notes"`
+	Assets        int     `json:"assets"`
+	MissingAssets int     `json:"missing_assets"`
+}
+
+// StorageUsage matches quota.Usage.
+type StorageUsage struct {
+	ProjectID           uuid.UUID `json:"project_id"`
+	AssetBytes          int64     `json:"asset_bytes"`
+	ScriptBytes         int64     `json:"script_bytes"`
+	StepImageBytes      int64     `json:"step_image_bytes"`
+	StepAttachmentBytes int64     `json:"step_attachment_bytes"`
+	TotalBytes          int64     `json:"total_bytes"`
+	QuotaBytes          int64     `json:"quota_bytes"`
+}
+
+// RetentionPolicy matches retention.Policy.
+type RetentionPolicy struct {
+	ProjectID          uuid.UUID `json:"project_id"`
+	AssetRetentionDays int       `json:"asset_retention_days"`
+	UpdatedBy          uuid.UUID `json:"updated_by"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// UpdateRetentionPolicyRequest matches handlers.UpdateRetentionPolicyRequest.
+type UpdateRetentionPolicyRequest struct {
+	AssetRetentionDays int `json:"asset_retention_days"`
+}
+
+// RetentionReport matches retention.Report.
+type RetentionReport struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	DryRun    bool      `json:"dry_run"`
+	Cutoff    time.Time `json:"cutoff"`
+	Assets    int       `json:"assets"`
+	Runs      int       `json:"runs"`
+	Bytes     int64     `json:"bytes"`
+}
+
+// CreateProjectRequest matches handlers.CreateProjectRequest.
+type CreateProjectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateProjectRequest matches handlers.UpdateProjectRequest.
+type UpdateProjectRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// RequireProcedureReview turns procedure reviews on or off.
+	RequireProcedureReview *bool `json:"require_procedure_review,omitempty"`
+}
+
+// Step is a single step of a test procedure. Steps with a GroupID stand
+// in for a step group from the project's step library.
+type Step struct {
+	Name           string         `json:"name"`
+	Instructions   string         `json:"instructions"`
+	ImagePaths     []string       `json:"image_paths"`
+	ExpectedResult string         `json:"expected_result,omitempty"`
+	Attachments    []Attachment   `json:"attachments,omitempty"`
+	GroupID        *uuid.UUID     `json:"group_id,omitempty"`
+	GroupRevision  uint           `json:"group_revision,omitempty"`
+	Condition      *StepCondition `json:"condition,omitempty"`
+}
+
+// Attachment matches testprocedure.Attachment.
+type Attachment struct {
+	Path        string `json:"path"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	FileSize    int64  `json:"file_size"`
+}
+
+// StepCondition matches testprocedure.StepCondition.
+type StepCondition struct {
+	Check     string `json:"check"`
+	Selector  string `json:"selector,omitempty"`
+	Text      string `json:"text,omitempty"`
+	ThenSteps int    `json:"then_steps"`
+	ElseSteps int    `json:"else_steps,omitempty"`
+}
+
+// Parameter matches testprocedure.Parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Default     *string `json:"default,omitempty"`
+}
+
+// Precondition matches testprocedure.Precondition.
+type Precondition struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+}
+
+// TestProcedure is a test procedure as returned by the API.
+type TestProcedure struct {
+	ID            uuid.UUID      `json:"id"`
+	ProjectID     uuid.UUID      `json:"project_id"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Steps         []Step         `json:"steps"`
+	Parameters    []Parameter    `json:"parameters"`
+	Preconditions []Precondition `json:"preconditions"`
+	CreatedBy     uuid.UUID      `json:"created_by"`
+	Version       uint           `json:"version"`
+	IsLatest      bool           `json:"is_latest"`
+	ParentID      *uuid.UUID     `json:"parent_id,omitempty"`
+	NeedsReview   bool           `json:"needs_review"`
+	Revision      uint           `json:"revision"`
+	CommitMessage string         `json:"commit_message,omitempty"`
+	ChangeSummary string         `json:"change_summary,omitempty"`
+	ArchivedAt    *time.Time     `json:"archived_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// DraftSnapshot is the content of a procedure's draft before one of its
+// saves, as returned by the API.
+type DraftSnapshot struct {
+	ID            uuid.UUID      `json:"id"`
+	DraftID       uuid.UUID      `json:"draft_id"`
+	Revision      uint           `json:"revision"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Steps         []Step         `json:"steps"`
+	Parameters    []Parameter    `json:"parameters"`
+	Preconditions []Precondition `json:"preconditions"`
+	NeedsReview   bool           `json:"needs_review"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// CommitDraftRequest matches handlers.CommitDraftRequest. Message is
+// required.
+type CommitDraftRequest struct {
+	Message string `json:"message"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// CreateTestProcedureRequest matches handlers.CreateTestProcedureRequest.
+type CreateTestProcedureRequest struct {
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Steps         []Step         `json:"steps"`
+	Parameters    []Parameter    `json:"parameters,omitempty"`
+	Preconditions []Precondition `json:"preconditions,omitempty"`
+}
+
+// UpdateTestProcedureRequest matches handlers.UpdateTestProcedureRequest.
+type UpdateTestProcedureRequest struct {
+	Name          *string         `json:"name,omitempty"`
+	Description   *string         `json:"description,omitempty"`
+	Steps         *[]Step         `json:"steps,omitempty"`
+	NeedsReview   *bool           `json:"needs_review,omitempty"`
+	Parameters    *[]Parameter    `json:"parameters,omitempty"`
+	Preconditions *[]Precondition `json:"preconditions,omitempty"`
+	// Revision, if set, makes the update fail with 409 Conflict unless the
+	// draft is still at this revision.
+	Revision *uint `json:"revision,omitempty"`
+}
+
+// GenerateProcedureRequest matches handlers.GenerateProcedureRequest.
+type GenerateProcedureRequest struct {
+	Description string     `json:"description"`
+	Name        string     `json:"name,omitempty"`
+	EndpointID  *uuid.UUID `json:"endpoint_id,omitempty"`
+}
+
+// SuggestStepsRequest matches handlers.SuggestStepsRequest.
+type SuggestStepsRequest struct {
+	EndpointID uuid.UUID `json:"endpoint_id"`
+	Path       string    `json:"path,omitempty"`
+	Hint       string    `json:"hint,omitempty"`
+}
+
+// StepSuggestions matches handlers.SuggestStepsResponse.
+type StepSuggestions struct {
+	Suggestions []stepsuggest.Suggestion `json:"suggestions"`
+	ImagePath   string                   `json:"image_path"`
+	Draft       TestProcedure            `json:"draft"`
+}
+
+// Review is a request to approve a pending version of a test procedure.
+// ProcedureID is the procedure's first version and VersionID the version
+// under review.
+type Review struct {
+	ID          uuid.UUID     `json:"id"`
+	ProjectID   uuid.UUID     `json:"project_id"`
+	ProcedureID uuid.UUID     `json:"procedure_id"`
+	VersionID   uuid.UUID     `json:"version_id"`
+	Version     uint          `json:"version"`
+	Status      review.Status `json:"status"`
+	RequestedBy uuid.UUID     `json:"requested_by"`
+	ReviewerID  *uuid.UUID    `json:"reviewer_id,omitempty"`
+	Message     string        `json:"message,omitempty"`
+	DecidedBy   *uuid.UUID    `json:"decided_by,omitempty"`
+	Comment     string        `json:"comment,omitempty"`
+	DecidedAt   *time.Time    `json:"decided_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// ReviewDetail matches handlers.ReviewResponse.
+type ReviewDetail struct {
+	Review
+	PendingVersion TestProcedure  `json:"pending_version"`
+	LatestVersion  *TestProcedure `json:"latest_version,omitempty"`
+}
+
+// RequestReviewRequest matches handlers.RequestReviewRequest.
+type RequestReviewRequest struct {
+	CommitDraftRequest
+	ReviewerID *uuid.UUID `json:"reviewer_id,omitempty"`
+}
+
+// ProcedureShare is a procedure shared with a user outside its project.
+// ProcedureID is the procedure's first version.
+type ProcedureShare struct {
+	ID          uuid.UUID             `json:"id"`
+	ProcedureID uuid.UUID             `json:"procedure_id"`
+	UserID      uuid.UUID             `json:"user_id"`
+	Access      procedureshare.Access `json:"access"`
+	SharedBy    uuid.UUID             `json:"shared_by"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// SharedProcedure is a procedure shared with the caller, with the project it
+// belongs to and its name.
+type SharedProcedure struct {
+	ProcedureShare
+	ProjectID     uuid.UUID `json:"project_id"`
+	ProcedureName string    `json:"procedure_name"`
+}
+
+// TestRun is a test run as returned by the API, including the version of
+// the procedure it was run against.
+type TestRun struct {
+	ID                          uuid.UUID         `json:"id"`
+	TestProcedureID             uuid.UUID         `json:"test_procedure_id"`
+	ExecutedBy                  uuid.UUID         `json:"executed_by"`
+	AssignedTo                  *uuid.UUID        `json:"assigned_to"`
+	Status                      testrun.Status    `json:"status"`
+	Notes                       string            `json:"notes"`
+	EndpointID                  *uuid.UUID        `json:"endpoint_id,omitempty"`
+	Environment                 string            `json:"environment,omitempty"`
+	BaseURL                     string            `json:"base_url,omitempty"`
+	Parameters                  map[string]string `json:"parameters,omitempty"`
+	EnvironmentDetails          map[string]string `json:"environment_details,omitempty"`
+	Checklist                   []string          `json:"checklist,omitempty"`
+	StartedAt                   *time.Time        `json:"started_at,omitempty"`
+	CompletedAt                 *time.Time        `json:"completed_at,omitempty"`
+	Duration                    *int64            `json:"duration,omitempty"`
+	FailedStepIndex             *int              `json:"failed_step_index,omitempty"`
+	ReleaseID                   *uuid.UUID        `json:"release_id,omitempty"`
+	DueAt                       *time.Time        `json:"due_at,omitempty"`
+	StatusReason                string            `json:"status_reason,omitempty"`
+	PausedAt                    *time.Time        `json:"paused_at,omitempty"`
+	PausedDuration              int64             `json:"paused_duration,omitempty"`
+	MaxDurationMinutes          *int              `json:"max_duration_minutes,omitempty"`
+	OverdueAfter                *time.Time        `json:"overdue_after,omitempty"`
+	OverdueAt                   *time.Time        `json:"overdue_at,omitempty"`
+	PreconditionsAcknowledgedBy *uuid.UUID        `json:"preconditions_acknowledged_by,omitempty"`
+	ProcedureVersion            uint              `json:"procedure_version"`
+	CreatedAt                   time.Time         `json:"created_at"`
+	UpdatedAt                   time.Time         `json:"updated_at"`
+}
+
+// GitHubActionsReport matches testrun.GitHubActionsReport.
+type GitHubActionsReport struct {
+	Commands string `json:"commands"`
+	Summary  string `json:"summary"`
+}
+
+// EndpointTarget matches handlers.EndpointTarget. It selects an endpoint
+// either by ID or by group and environment.
+type EndpointTarget struct {
+	EndpointID  string `json:"endpoint_id,omitempty"`
+	Group       string `json:"endpoint_group,omitempty"`
+	Environment string `json:"environment,omitempty"`
+}
+
+// CreateRunRequest matches handlers.CreateTestRunRequest.
+type CreateRunRequest struct {
+	EndpointTarget
+	Parameters         map[string]string `json:"parameters,omitempty"`
+	EnvironmentDetails map[string]string `json:"environment_details,omitempty"`
+	AssignedTo         *uuid.UUID        `json:"assigned_to,omitempty"`
+	DueAt              *time.Time        `json:"due_at,omitempty"`
+}
+
+// QueuedRun is a run in the caller's queue, with the procedure and project
+// it belongs to.
+type QueuedRun struct {
+	TestRun
+	ProjectID     uuid.UUID `json:"project_id"`
+	ProcedureName string    `json:"procedure_name"`
+}
+
+// RunTemplate is a procedure's run template as returned by the API.
+type RunTemplate struct {
+	ID                    uuid.UUID                  `json:"id"`
+	TestProcedureID       uuid.UUID                  `json:"test_procedure_id"`
+	Notes                 string                     `json:"notes"`
+	EnvironmentFields     []testrun.EnvironmentField `json:"environment_fields"`
+	Checklist             []string                   `json:"checklist"`
+	MaxDurationMinutes    int                        `json:"max_duration_minutes,omitempty"`
+	PreventConcurrentRuns bool                       `json:"prevent_concurrent_runs,omitempty"`
+	UpdatedBy             uuid.UUID                  `json:"updated_by"`
+	CreatedAt             time.Time                  `json:"created_at"`
+	UpdatedAt             time.Time                  `json:"updated_at"`
+}
+
+// SaveRunTemplateRequest matches handlers.SaveRunTemplateRequest.
+type SaveRunTemplateRequest struct {
+	Notes                 string                     `json:"notes"`
+	EnvironmentFields     []testrun.EnvironmentField `json:"environment_fields"`
+	Checklist             []string                   `json:"checklist"`
+	MaxDurationMinutes    int                        `json:"max_duration_minutes"`
+	PreventConcurrentRuns bool                       `json:"prevent_concurrent_runs"`
+}
+
+// UpdateTestRunRequest matches handlers.UpdateTestRunRequest.
+type UpdateTestRunRequest struct {
+	Notes      *string `json:"notes,omitempty"`
+	AssignedTo *string `json:"assigned_to,omitempty"`
+	DueAt      *string `json:"due_at,omitempty"`
+}
+
+// RunStatusReasonRequest matches handlers.RunStatusReasonRequest.
+type RunStatusReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// StartTestRunRequest matches handlers.StartTestRunRequest.
+type StartTestRunRequest struct {
+	AcknowledgePreconditions bool `json:"acknowledge_preconditions"`
+}
+
+// CompleteTestRunRequest matches handlers.CompleteTestRunRequest.
+type CompleteTestRunRequest struct {
+	Status          testrun.Status `json:"status"`
+	Notes           string         `json:"notes"`
+	FailedStepIndex *int           `json:"failed_step_index,omitempty"`
+}
+
+// CreateTokenRequest matches handlers.CreateTokenRequest.
+type CreateTokenRequest struct {
+	Name           string `json:"name"`
+	Scope          string `json:"scope"`
+	ExpiresInHours int    `json:"expires_in_hours"`
+}
+
+// CreateTokenResponse matches handlers.CreateTokenResponse.
+type CreateTokenResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Scope     string `json:"scope"`
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+// TokenListItem matches handlers.TokenListItem.
+type TokenListItem struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Scope     string `json:"scope"`
+	ExpiresAt string `json:"expires_at"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at"`
+}
+
+// TokenListResponse matches handlers.TokenListResponse.
+type TokenListResponse struct {
+	Tokens []TokenListItem `json:"tokens"`
+	Total  int             `json:"total"`
+}
+
+// Endpoint is an endpoint as returned by the API.
+type Endpoint struct {
+	ID                  uuid.UUID             `json:"id"`
+	Name                string                `json:"name"`
+	URL                 string                `json:"url"`
+	Credentials         endpoint.Credentials  `json:"credentials"`
+	MaxConcurrentJobs   int                   `json:"max_concurrent_jobs"`
+	Group               string                `json:"group"`
+	Environment         string                `json:"environment"`
+	HealthCheckEnabled  bool                  `json:"health_check_enabled"`
+	HealthCheckInterval int                   `json:"health_check_interval_seconds"`
+	ExpectedStatus      int                   `json:"health_check_expected_status"`
+	ExpectedText        string                `json:"health_check_expected_text"`
+	HealthStatus        endpoint.HealthStatus `json:"health_status"`
+	LastHealthCheckAt   *time.Time            `json:"last_health_check_at,omitempty"`
+	UseRunner           bool                  `json:"use_runner"`
+	RunnerLabels        []string              `json:"runner_labels"`
+	CreatedBy           uuid.UUID             `json:"created_by"`
+	CreatedAt           time.Time             `json:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at"`
+}
+
+// CreateEndpointRequest matches handlers.CreateEndpointRequest.
+type CreateEndpointRequest struct {
+	Name              string               `json:"name"`
+	URL               string               `json:"url"`
+	Credentials       endpoint.Credentials `json:"credentials,omitempty"`
+	MaxConcurrentJobs int                  `json:"max_concurrent_jobs,omitempty"`
+	Group             string               `json:"group,omitempty"`
+	Environment       string               `json:"environment,omitempty"`
+
+	HealthCheckEnabled  bool   `json:"health_check_enabled,omitempty"`
+	HealthCheckInterval int    `json:"health_check_interval_seconds,omitempty"`
+	ExpectedStatus      int    `json:"health_check_expected_status,omitempty"`
+	ExpectedText        string `json:"health_check_expected_text,omitempty"`
+
+	UseRunner    bool     `json:"use_runner,omitempty"`
+	RunnerLabels []string `json:"runner_labels,omitempty"`
+}
+
+// UpdateEndpointRequest matches handlers.UpdateEndpointRequest.
+type UpdateEndpointRequest struct {
+	Name              *string               `json:"name,omitempty"`
+	URL               *string               `json:"url,omitempty"`
+	Credentials       *endpoint.Credentials `json:"credentials,omitempty"`
+	MaxConcurrentJobs *int                  `json:"max_concurrent_jobs,omitempty"`
+	Group             *string               `json:"group,omitempty"`
+	Environment       *string               `json:"environment,omitempty"`
+
+	HealthCheckEnabled  *bool   `json:"health_check_enabled,omitempty"`
+	HealthCheckInterval *int    `json:"health_check_interval_seconds,omitempty"`
+	ExpectedStatus      *int    `json:"health_check_expected_status,omitempty"`
+	ExpectedText        *string `json:"health_check_expected_text,omitempty"`
+
+	UseRunner    *bool     `json:"use_runner,omitempty"`
+	RunnerLabels *[]string `json:"runner_labels,omitempty"`
+}
+
+// EndpointHealth matches handlers.EndpointHealthResponse.
+type EndpointHealth struct {
+	EndpointID          uuid.UUID                        `json:"endpoint_id"`
+	Status              endpoint.HealthStatus            `json:"status"`
+	LastCheckedAt       *time.Time                       `json:"last_checked_at"`
+	HealthCheckEnabled  bool                             `json:"health_check_enabled"`
+	HealthCheckInterval int                              `json:"health_check_interval_seconds"`
+	Uptime              map[string]*endpoint.UptimeStats `json:"uptime"`
+	Recent              []*endpoint.HealthCheck          `json:"recent"`
+}
+
+// EndpointSecret is a secret of an endpoint; the value is never returned.
+type EndpointSecret struct {
+	ID          uuid.UUID `json:"id"`
+	EndpointID  uuid.UUID `json:"endpoint_id"`
+	Key         string    `json:"key"`
+	Placeholder string    `json:"placeholder"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// EndpointGroupEnvironment matches handlers.EndpointGroupEnvironment.
+type EndpointGroupEnvironment struct {
+	Environment  string                `json:"environment"`
+	EndpointID   uuid.UUID             `json:"endpoint_id"`
+	Name         string                `json:"name"`
+	URL          string                `json:"url"`
+	HealthStatus endpoint.HealthStatus `json:"health_status"`
+}
+
+// EndpointGroup matches handlers.EndpointGroup.
+type EndpointGroup struct {
+	Group        string                     `json:"group"`
+	Environments []EndpointGroupEnvironment `json:"environments"`
+}
+
+// Job is a background job as returned by the API.
+type Job struct {
+	ID         uuid.UUID              `json:"id"`
+	Type       job.JobType            `json:"type"`
+	Status     job.Status             `json:"status"`
+	Config     map[string]interface{} `json:"config"`
+	Result     map[string]interface{} `json:"result"`
+	EndpointID *uuid.UUID             `json:"endpoint_id,omitempty"`
+	StartTime  *time.Time             `json:"start_time,omitempty"`
+	EndTime    *time.Time             `json:"end_time,omitempty"`
+	Duration   *int64                 `json:"duration,omitempty"`
+	CreatedBy  uuid.UUID              `json:"created_by"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+// CreateJobRequest matches handlers.CreateJobRequest.
+type CreateJobRequest struct {
+	Type   job.JobType            `json:"type"`
+	Config map[string]interface{} `json:"config"`
+}
+
+// Script is a generated automation script as returned by the API.
+type Script struct {
+	ID               uuid.UUID `json:"id"`
+	TestProcedureID  uuid.UUID `json:"test_procedure_id"`
+	Framework        string    `json:"framework"`
+	FileName         string    `json:"file_name"`
+	FileSize         int64     `json:"file_size"`
+	GenerationStatus string    `json:"generation_status"`
+	ErrorMessage     *string   `json:"error_message,omitempty"`
+	GeneratedBy      uuid.UUID `json:"generated_by"`
+	GeneratedAt      time.Time `json:"generated_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// GenerateScriptRequest matches handlers.GenerateScriptRequest.
+type GenerateScriptRequest struct {
+	Framework  string            `json:"framework"`
+	EndpointID string            `json:"endpoint_id,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// listResponse is the shape of non-paginated list responses.
+type listResponse[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+// StepNote is a tester's note on one step of a test run.
+type StepNote struct {
+	ID        uuid.UUID          `json:"id"`
+	TestRunID uuid.UUID          `json:"test_run_id"`
+	StepIndex int                `json:"step_index"`
+	Notes     string             `json:"notes"`
+	Status    testrun.StepStatus `json:"status,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// RunAsset is a file attached to a test run.
+type RunAsset struct {
+	ID          uuid.UUID         `json:"id"`
+	TestRunID   uuid.UUID         `json:"test_run_id"`
+	AssetType   testrun.AssetType `json:"asset_type"`
+	FileName    string            `json:"file_name"`
+	FileSize    int64             `json:"file_size"`
+	MimeType    string            `json:"mime_type,omitempty"`
+	Description string            `json:"description,omitempty"`
+	StepIndex   *int              `json:"step_index,omitempty"`
+	UploadedAt  time.Time         `json:"uploaded_at"`
+}
+
+// UploadRunAssetRequest describes a file to attach to a test run.
+type UploadRunAssetRequest struct {
+	AssetType   testrun.AssetType
+	FileName    string
+	Content     io.Reader
+	Description string
+	StepIndex   *int // Step the file belongs to (0-based), if any
+}
+
+// JobLogEntry is one line of a job's log.
+type JobLogEntry struct {
+	ID        uuid.UUID `json:"id"`
+	JobID     uuid.UUID `json:"job_id"`
+	Seq       int       `json:"seq"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JobLogs matches handlers.JobLogsResponse.
+type JobLogs struct {
+	Items  []JobLogEntry `json:"items"`
+	Total  int           `json:"total"`
+	Status job.Status    `json:"status"`
+}
+
+// Runner matches handlers.RunnerResponse: a registered agent runner.
+type Runner struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Labels     []string  `json:"labels"`
+	Version    string    `json:"version"`
+	Online     bool      `json:"online"`
+	CreatedBy  uuid.UUID `json:"created_by"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RegisterRunnerRequest matches handlers.RegisterRunnerRequest.
+type RegisterRunnerRequest struct {
+	Name    string   `json:"name"`
+	Labels  []string `json:"labels,omitempty"`
+	Version string   `json:"version,omitempty"`
+}
+
+// ClaimRunnerJobRequest matches handlers.ClaimRunnerJobRequest.
+type ClaimRunnerJobRequest struct {
+	RunnerID *uuid.UUID `json:"runner_id,omitempty"`
+}
+
+// RunnerJob matches handlers.ClaimRunnerJobResponse: a job claimed by an
+// agent runner with the config to run the agent with.
+type RunnerJob struct {
+	Job              Job               `json:"job"`
+	AgentConfig      agent.AgentConfig `json:"agent_config"`
+	TimeLimitSeconds int               `json:"time_limit_seconds"`
+}
+
+// RunnerHeartbeatRequest matches handlers.RunnerHeartbeatRequest.
+type RunnerHeartbeatRequest struct {
+	ProgressDone  *int `json:"progress_done,omitempty"`
+	ProgressTotal *int `json:"progress_total,omitempty"`
+}
+
+// ExternalIssue is an issue in an integration's issue tracker.
+type ExternalIssue struct {
+	ExternalID  string    `json:"external_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	URL         string    `json:"url"`
+	Provider    string    `json:"provider"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ExternalIssuePage matches handlers.ExternalIssuePage. Total is nil for
+// trackers that do not report it.
+type ExternalIssuePage struct {
+	Items      []ExternalIssue `json:"items"`
+	Total      *int            `json:"total"`
+	Limit      int             `json:"limit"`
+	Offset     int             `json:"offset"`
+	HasMore    bool            `json:"has_more"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
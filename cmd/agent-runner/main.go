@@ -0,0 +1,182 @@
+// Command agent-runner runs the ui_exploration jobs of endpoints that use
+// agent runners. It runs next to the application under test, such as inside
+// a private network the backend cannot reach: it registers with the backend
+// with an API token and its labels, claims the jobs of endpoints whose
+// required labels it has, runs the exploration agent against a local
+// Playwright MCP server and reports logs, progress and results back.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	Version   = "dev"
+	Commit    = "none"
+	BuildDate = "unknown"
+)
+
+var configFile string
+
+// keepAliveInterval is how often the runner tells the backend it is online,
+// well within the backend's runner.OfflineAfter.
+const keepAliveInterval = 30 * time.Second
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "agent-runner",
+		Short: "Run exploration jobs next to the application under test",
+		Long: `Run the ui_exploration jobs of endpoints that use agent runners.
+
+The runner claims jobs from the backend using an API token with read_write
+scope, owned by the user who creates the jobs. Each job runs the exploration
+agent against a Playwright MCP server reachable from the runner, so the
+application under test only needs to be reachable from the runner. Logs,
+progress and results are reported back to the backend.
+
+The runner registers under a name with labels describing what it can reach
+or run on, such as needs-vpn or windows. It only claims the jobs of
+endpoints requiring only labels it has, and deregisters when it stops.
+
+Settings are read from flags, AGENT_RUNNER_* environment variables (such as
+AGENT_RUNNER_TOKEN) or a YAML config file.`,
+		RunE: runRunner,
+	}
+
+	flags := rootCmd.Flags()
+	flags.StringVarP(&configFile, "config", "c", "", "config file path")
+	flags.String("url", "http://localhost:8080", "backend URL")
+	flags.String("token", "", "API token with read_write scope")
+	flags.String("name", "", "runner name shown in the backend (default: hostname)")
+	flags.StringSlice("labels", nil, "comma-separated runner labels, such as needs-vpn,windows")
+	flags.String("script-path", "agent/agent_runner.py", "path to the exploration agent script")
+	flags.String("playwright-mcp-url", "http://localhost:3000", "Playwright MCP server URL")
+	flags.String("bedrock-region", "us-east-1", "AWS region of Amazon Bedrock")
+	flags.String("bedrock-access-key", "", "AWS access key for Amazon Bedrock (default: AWS credential chain)")
+	flags.String("bedrock-secret-key", "", "AWS secret key for Amazon Bedrock")
+	flags.Int("workers", 1, "number of jobs to run at the same time")
+	flags.Duration("poll-interval", 10*time.Second, "how often to look for jobs when idle")
+	flags.Duration("report-interval", 5*time.Second, "how often to send logs, progress and heartbeats of running jobs")
+	flags.String("log-level", "info", "log level")
+
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version information",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("agent-runner %s (commit: %s, built: %s)\n", Version, Commit, BuildDate)
+		},
+	}
+	rootCmd.AddCommand(versionCmd)
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func runRunner(cmd *cobra.Command, args []string) error {
+	v := viper.New()
+	v.SetEnvPrefix("AGENT_RUNNER")
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		return err
+	}
+	if configFile != "" {
+		v.SetConfigFile(configFile)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	token := v.GetString("token")
+	if token == "" {
+		return fmt.Errorf("an API token is required; set --token or AGENT_RUNNER_TOKEN")
+	}
+	workers := v.GetInt("workers")
+	if workers < 1 {
+		return fmt.Errorf("workers must be at least 1")
+	}
+	if v.GetDuration("poll-interval") <= 0 || v.GetDuration("report-interval") <= 0 {
+		return fmt.Errorf("poll-interval and report-interval must be positive")
+	}
+
+	name := v.GetString("name")
+	if name == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to get hostname, set --name: %w", err)
+		}
+		name = hostname
+	}
+
+	log := logger.NewLogrusLogger(v.GetString("log-level"))
+	c := client.New(strings.TrimRight(v.GetString("url"), "/"), token)
+	reg := &registration{
+		client: c,
+		request: client.RegisterRunnerRequest{
+			Name:    name,
+			Labels:  v.GetStringSlice("labels"),
+			Version: Version,
+		},
+		logger: log,
+	}
+	r := &runner{
+		client:       c,
+		registration: reg,
+		process: agent.Process{
+			ScriptPath:       v.GetString("script-path"),
+			BedrockRegion:    v.GetString("bedrock-region"),
+			BedrockAccessKey: v.GetString("bedrock-access-key"),
+			BedrockSecretKey: v.GetString("bedrock-secret-key"),
+		},
+		playwrightMCPURL: strings.TrimRight(v.GetString("playwright-mcp-url"), "/"),
+		pollInterval:     v.GetDuration("poll-interval"),
+		reportInterval:   v.GetDuration("report-interval"),
+		logger:           log,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := reg.register(ctx); err != nil {
+		return fmt.Errorf("failed to register runner: %w", err)
+	}
+	defer reg.deregister(context.Background())
+
+	log.Info(ctx, "agent runner started", map[string]interface{}{
+		"version": Version,
+		"url":     v.GetString("url"),
+		"workers": workers,
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reg.keepAlive(ctx, keepAliveInterval)
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			r.work(ctx, id)
+		}(i)
+	}
+	wg.Wait()
+
+	log.Info(context.Background(), "agent runner stopped", nil)
+	return nil
+}
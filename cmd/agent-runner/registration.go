@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// registration is the runner's registration with the backend, whose labels
+// decide which endpoints' jobs the runner may claim. It registers again
+// when the backend no longer knows the runner, such as after a user
+// deregistered it.
+type registration struct {
+	client  *client.Client
+	request client.RegisterRunnerRequest
+	logger  logger.Logger
+
+	mu sync.Mutex
+	id uuid.UUID
+}
+
+// ID returns the ID the runner is registered under.
+func (g *registration) ID() uuid.UUID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.id
+}
+
+// register registers the runner, replacing any previous registration.
+func (g *registration) register(ctx context.Context) error {
+	registered, err := g.client.RegisterRunner(ctx, g.request)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	g.id = registered.ID
+	g.mu.Unlock()
+
+	g.logger.Info(ctx, "runner registered", map[string]interface{}{
+		"runner_id": registered.ID.String(),
+		"name":      registered.Name,
+		"labels":    registered.Labels,
+	})
+	return nil
+}
+
+// renew registers the runner again if the backend reports err for a
+// registration it no longer knows.
+func (g *registration) renew(ctx context.Context, err error) {
+	if !client.IsNotFound(err) {
+		return
+	}
+	if err := g.register(ctx); err != nil && ctx.Err() == nil {
+		g.logger.Error(ctx, "failed to register runner again", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// keepAlive tells the backend the runner is online every interval until ctx
+// is done, even while every worker is busy running jobs.
+func (g *registration) keepAlive(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := g.client.PingRunner(ctx, g.ID())
+			if err != nil && ctx.Err() == nil {
+				g.logger.Warn(ctx, "failed to send runner heartbeat", map[string]interface{}{
+					"error": err.Error(),
+				})
+				g.renew(ctx, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deregister removes the runner's registration.
+func (g *registration) deregister(ctx context.Context) {
+	if err := g.client.DeregisterRunner(ctx, g.ID()); err != nil && !client.IsNotFound(err) {
+		g.logger.Warn(ctx, "failed to deregister runner", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
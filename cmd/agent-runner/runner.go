@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// maxLogBatch is the most log lines sent in one request, matching the
+// backend's limit.
+const maxLogBatch = 500
+
+// errJobAbandoned is the cancellation cause of a job the backend no longer
+// considers running, such as one stopped by its user.
+var errJobAbandoned = errors.New("job is no longer running")
+
+// runner claims jobs from the backend and runs the exploration agent for them.
+type runner struct {
+	client           *client.Client
+	registration     *registration
+	process          agent.Process
+	playwrightMCPURL string
+	pollInterval     time.Duration
+	reportInterval   time.Duration
+	logger           logger.Logger
+}
+
+// work runs jobs one at a time until ctx is done, polling for new jobs
+// while there are none.
+func (r *runner) work(ctx context.Context, id int) {
+	for {
+		if !r.runNext(ctx, id) {
+			select {
+			case <-time.After(r.pollInterval):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// runNext claims and runs a single job. It returns false when no job was run.
+func (r *runner) runNext(ctx context.Context, id int) bool {
+	claimed, err := r.client.ClaimRunnerJob(ctx, r.registration.ID())
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error(ctx, "failed to claim job", map[string]interface{}{
+				"worker_id": id,
+				"error":     err.Error(),
+			})
+			r.registration.renew(ctx, err)
+		}
+		return false
+	}
+	if claimed == nil {
+		return false
+	}
+
+	r.logger.Info(ctx, "running job", map[string]interface{}{
+		"worker_id":  id,
+		"job_id":     claimed.Job.ID.String(),
+		"target_url": claimed.AgentConfig.TargetURL,
+	})
+	r.run(ctx, claimed)
+	return true
+}
+
+// run runs the agent for a claimed job and reports the outcome. The job is
+// abandoned without a report once the backend says it is no longer running.
+func (r *runner) run(ctx context.Context, claimed *client.RunnerJob) {
+	jobID := claimed.Job.ID
+	// Reports are sent even when the runner is shutting down.
+	reportCtx := context.WithoutCancel(ctx)
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if claimed.TimeLimitSeconds > 0 {
+		var cancelTimeout context.CancelFunc
+		jobCtx, cancelTimeout = context.WithTimeout(jobCtx, time.Duration(claimed.TimeLimitSeconds)*time.Second)
+		defer cancelTimeout()
+	}
+
+	dir, err := os.MkdirTemp("", "agent-runner-job-")
+	if err != nil {
+		r.fail(reportCtx, jobID, fmt.Sprintf("failed to create temp directory: %v", err))
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := claimed.AgentConfig
+	cfg.OutputDir = dir
+	cfg.PlaywrightMCPURL = r.playwrightMCPURL + "/sse"
+
+	rep := newReporter(r.client, jobID, r.logger)
+	stopReporting := rep.start(jobCtx, reportCtx, r.reportInterval, cancel)
+	err = r.process.Run(jobCtx, cfg, rep)
+	stopReporting()
+
+	switch {
+	case errors.Is(context.Cause(jobCtx), errJobAbandoned):
+		r.logger.Info(ctx, "job is no longer running, abandoned it", map[string]interface{}{
+			"job_id": jobID.String(),
+		})
+		return
+	case ctx.Err() != nil:
+		r.fail(reportCtx, jobID, "agent runner shut down while the job was running")
+		return
+	case err != nil:
+		r.fail(reportCtx, jobID, err.Error())
+		return
+	}
+
+	result, err := agent.ReadResult(dir)
+	if err != nil {
+		r.fail(reportCtx, jobID, err.Error())
+		return
+	}
+	if err := r.client.CompleteRunnerJob(reportCtx, jobID, result, dir); err != nil {
+		r.logger.Error(ctx, "failed to upload job result", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID.String(),
+		})
+		if !client.IsConflict(err) {
+			r.fail(reportCtx, jobID, fmt.Sprintf("failed to upload result: %v", err))
+		}
+		return
+	}
+
+	r.logger.Info(ctx, "job completed", map[string]interface{}{
+		"job_id": jobID.String(),
+	})
+}
+
+// fail reports that a job failed, logging the reason.
+func (r *runner) fail(ctx context.Context, jobID uuid.UUID, reason string) {
+	r.logger.Error(ctx, "job failed", map[string]interface{}{
+		"job_id": jobID.String(),
+		"reason": reason,
+	})
+	if err := r.client.FailRunnerJob(ctx, jobID, reason); err != nil && !client.IsConflict(err) {
+		r.logger.Error(ctx, "failed to report job failure", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID.String(),
+		})
+	}
+}
+
+// reporter is an io.Writer for the agent's stderr that sends its lines to
+// the job's log and its progress lines as the job's progress, along with a
+// heartbeat every report interval.
+type reporter struct {
+	client *client.Client
+	jobID  uuid.UUID
+	logger logger.Logger
+
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	lines    []string
+	progress *[2]int
+}
+
+func newReporter(c *client.Client, jobID uuid.UUID, log logger.Logger) *reporter {
+	return &reporter{
+		client: c,
+		jobID:  jobID,
+		logger: log,
+	}
+}
+
+// Write buffers p and queues every complete line.
+func (w *reporter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		w.queue(string(w.buf.Next(i + 1)))
+	}
+	return len(p), nil
+}
+
+// queue queues a single line, dropping blank ones. Callers must hold w.mu.
+func (w *reporter) queue(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	w.lines = append(w.lines, line)
+	if done, total, ok := agent.ParseProgress(line); ok {
+		w.progress = &[2]int{done, total}
+	}
+}
+
+// start sends reports every interval until the returned function is
+// called, which sends the remaining lines. jobCtx is canceled with
+// errJobAbandoned when the backend no longer considers the job running.
+func (w *reporter) start(jobCtx, reportCtx context.Context, interval time.Duration, cancel context.CancelCauseFunc) func() {
+	ticker := time.NewTicker(interval)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.report(reportCtx); client.IsConflict(err) {
+					cancel(errJobAbandoned)
+					return
+				}
+			case <-stop:
+				return
+			case <-jobCtx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+
+		w.mu.Lock()
+		if w.buf.Len() > 0 {
+			w.queue(w.buf.String())
+			w.buf.Reset()
+		}
+		w.mu.Unlock()
+		w.report(reportCtx)
+	}
+}
+
+// report sends the queued lines and progress with a heartbeat. It returns
+// the heartbeat's error, which is a conflict once the job is no longer
+// running.
+func (w *reporter) report(ctx context.Context) error {
+	w.mu.Lock()
+	lines, progress := w.lines, w.progress
+	w.lines, w.progress = nil, nil
+	w.mu.Unlock()
+
+	for len(lines) > 0 {
+		n := min(len(lines), maxLogBatch)
+		if err := w.client.AppendRunnerLogs(ctx, w.jobID, lines[:n]); err != nil {
+			if client.IsConflict(err) {
+				return err
+			}
+			w.logger.Warn(ctx, "failed to send job logs", map[string]interface{}{
+				"error":  err.Error(),
+				"job_id": w.jobID.String(),
+			})
+			break
+		}
+		lines = lines[n:]
+	}
+
+	var req client.RunnerHeartbeatRequest
+	if progress != nil {
+		req.ProgressDone, req.ProgressTotal = &progress[0], &progress[1]
+	}
+	err := w.client.SendRunnerHeartbeat(ctx, w.jobID, req)
+	if err != nil && !client.IsConflict(err) {
+		w.logger.Warn(ctx, "failed to send job heartbeat", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": w.jobID.String(),
+		})
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/spf13/cobra"
+)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Manage platform admins",
+	Long: `Grants or revokes the admin role of a user by email. Admins manage every
+user and project through the /api/v1/admin API; use this command to make the
+first admin, or to recover when no admin can log in.`,
+}
+
+var adminGrantCmd = &cobra.Command{
+	Use:   "grant EMAIL",
+	Short: "Make a user a platform admin",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetRole(cmd, args[0], user.RoleAdmin)
+	},
+}
+
+var adminRevokeCmd = &cobra.Command{
+	Use:   "revoke EMAIL",
+	Short: "Make a platform admin an ordinary user",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetRole(cmd, args[0], user.RoleUser)
+	},
+}
+
+func init() {
+	adminCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file path")
+	adminCmd.PersistentFlags().BoolVar(&devMode, "dev", false, "use the SQLite database of --dev servers")
+	adminCmd.AddCommand(adminGrantCmd, adminRevokeCmd)
+	rootCmd.AddCommand(adminCmd)
+}
+
+func runSetRole(cmd *cobra.Command, email string, role user.Role) error {
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if devMode {
+		applyDevMode(cfg)
+	}
+	log := logger.NewLogrusLogger(cfg.Log.Level)
+	if _, err := loadSecrets(cmd.Context(), cfg, log); err != nil {
+		return err
+	}
+
+	db, err := database.Connect(cfg.Database.connectionConfig())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	defer sqlDB.Close()
+
+	store := user.NewMySQLStore(db, log)
+	u, err := store.GetByEmail(cmd.Context(), email)
+	if err != nil {
+		return fmt.Errorf("failed to get user %s: %w", email, err)
+	}
+	if err := store.Update(cmd.Context(), u.ID, user.SetRole(role)); err != nil {
+		return fmt.Errorf("failed to set role: %w", err)
+	}
+
+	fmt.Printf("%s is now a platform %s\n", email, role)
+	return nil
+}
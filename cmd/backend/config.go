@@ -2,9 +2,20 @@ package main
 
 import (
 	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/media"
+	"github.com/hairizuanbinnoorazman/ui-automation/queue"
+	"github.com/hairizuanbinnoorazman/ui-automation/retention"
+	"github.com/hairizuanbinnoorazman/ui-automation/saml"
+	"github.com/hairizuanbinnoorazman/ui-automation/secrets"
 	"github.com/spf13/viper"
 )
 
@@ -18,24 +29,244 @@ type AgentConfig struct {
 	BedrockSecretKey    string
 	PlaywrightMCPURL    string
 	AgentScriptPath     string
+	VisualCaptureScriptPath string
+	ProcedureScriptPath     string
 	MaxConcurrentWorkers int
 }
 
 // IntegrationConfig holds issue tracker integration configuration.
 type IntegrationConfig struct {
 	EncryptionKey string
+	// CheckInterval is how often the credentials of active integrations are
+	// validated. The check worker is not started if it is 0.
+	CheckInterval time.Duration
+	ExpiryWarning time.Duration // How long before expiry owners are warned
+}
+
+// ResilienceConfig holds timeouts and circuit breaker settings for calls to
+// external services (issue trackers, the LLM and the Playwright MCP server).
+type ResilienceConfig struct {
+	IssueTrackerTimeout time.Duration
+	LLMTimeout          time.Duration
+	MCPTimeout          time.Duration
+	FailureThreshold    int           // Consecutive failures before a circuit opens
+	OpenDuration        time.Duration // How long a circuit stays open before a trial call
+}
+
+// HealthConfig holds endpoint health monitoring configuration.
+type HealthConfig struct {
+	PollInterval time.Duration // How often due health checks are looked for
+	Timeout      time.Duration // Timeout of a single health check request
+	Retention    time.Duration // How long health check history is kept
+}
+
+// RateLimitConfig holds per-user and per-token rate limits. A rate of 0
+// disables the corresponding limit.
+type RateLimitConfig struct {
+	RequestsPerMinute          int // Sustained rate for all API routes
+	Burst                      int // Requests allowed at once on all API routes
+	ExpensiveRequestsPerMinute int // Sustained rate for script/guide generation and job creation
+	ExpensiveBurst             int // Requests allowed at once on expensive routes
+}
+
+// OwnershipCacheConfig holds configuration for the cache of resource owners
+// used by authorization checks.
+type OwnershipCacheConfig struct {
+	Type          string        // "memory", "redis" or "none"
+	TTL           time.Duration // How long a resolved owner is cached
+	MaxEntries    int           // Entries kept by the memory cache
+	RedisAddr     string        // Redis host:port for the redis cache
+	RedisPassword string
+	RedisDB       int
+}
+
+// SAMLConfig holds SAML single sign-on configuration. The identity provider
+// itself is configured at runtime by uploading its metadata.
+type SAMLConfig struct {
+	Enabled bool
+	// BaseURL is the external URL users reach the server at, such as
+	// https://qa.example.com. The ACS URL and default entity ID derive
+	// from it.
+	BaseURL string
+	// EntityID identifies this deployment to the identity provider.
+	EntityID string
+	// EnforceSSO disables registration and password login except for
+	// AdminEmails.
+	EnforceSSO  bool
+	AdminEmails []string
+	// EmailAttribute and UsernameAttribute name the assertion attributes
+	// mapped to user fields.
+	EmailAttribute    string
+	UsernameAttribute string
+	ClockSkew         time.Duration
+}
+
+// NotificationsConfig holds configuration for email and Slack notifications.
+type NotificationsConfig struct {
+	// BaseURL is the external URL of the frontend, used to link to the
+	// resource a notification is about. Links are left out if empty.
+	BaseURL   string
+	Workers   int
+	QueueSize int
+	Timeout   time.Duration // Per-message SMTP and Slack timeout
+	// SMTPHost is the mail server used for email. Email is disabled if it
+	// is empty.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// SlackEnabled allows users to be notified through Slack webhooks.
+	SlackEnabled bool
+}
+
+// MediaConfig holds configuration for generating thumbnails and transcodes
+// of uploaded video assets.
+type MediaConfig struct {
+	// Enabled turns on video processing. It is skipped with a warning if
+	// FFmpegPath cannot be found.
+	Enabled    bool
+	FFmpegPath string
+	// TranscodeFormats are the web-friendly formats videos are transcoded
+	// to. Only a thumbnail is generated if empty.
+	TranscodeFormats []media.Format
+	ThumbnailWidth   int
+	Workers          int
+	QueueSize        int
+	Timeout          time.Duration // Per-video processing timeout
+}
+
+// UploadsConfig holds configuration for resumable uploads.
+type UploadsConfig struct {
+	PartSize int64 // Size of every part but the last, in bytes
+	MaxSize  int64 // Largest file that can be uploaded, in bytes
+	// SessionTTL is how long an upload may take before it is abandoned
+	// and its parts are removed.
+	SessionTTL      time.Duration
+	CleanupInterval time.Duration
+}
+
+// IdempotencyConfig holds configuration for the responses kept for create
+// requests sent with an Idempotency-Key header.
+type IdempotencyConfig struct {
+	TTL             time.Duration // How long a response is replayed to retries
+	CleanupInterval time.Duration // How often expired responses are deleted
+}
+
+// PresenceConfig holds configuration for showing who has a procedure draft
+// open and for draft edit locks.
+type PresenceConfig struct {
+	Timeout time.Duration // How long an editor stays present after their last heartbeat
+	LockTTL time.Duration // How long an edit lock lasts unless it is renewed
+}
+
+// RetentionConfig holds configuration for enforcing per-project retention
+// policies on run assets.
+type RetentionConfig struct {
+	// Interval is how often retention policies are enforced. The cleanup
+	// worker is not started if it is 0.
+	Interval  time.Duration
+	BatchSize int // Expired assets deleted per query
+	// DryRun logs what the cleanup worker would delete without deleting
+	// anything.
+	DryRun bool
+}
+
+// JobsConfig holds configuration for detecting jobs stranded by a crashed
+// server.
+type JobsConfig struct {
+	HeartbeatInterval time.Duration // How often running jobs send a heartbeat
+	// StaleTimeout is how long a running job may go without a heartbeat
+	// before it is reaped.
+	StaleTimeout time.Duration
+	StaleAction  string // "fail" or "requeue"
+	// ReapInterval is how often stale jobs are looked for. They are only
+	// looked for at startup if it is 0.
+	ReapInterval time.Duration
+}
+
+// RunsConfig holds configuration for checking test runs against the
+// maximum durations their procedures expect.
+type RunsConfig struct {
+	// OverdueCheckInterval is how often runs still running after their
+	// expected maximum duration are looked for. The check worker is not
+	// started if it is 0.
+	OverdueCheckInterval time.Duration
+}
+
+// QueueConfig holds configuration for dispatching jobs through an external
+// queue to workers that may run on dedicated nodes.
+type QueueConfig struct {
+	Type string // "redis", "sqs" or "" to claim jobs from the database
+	// VisibilityTimeout is how long a received job is hidden from other
+	// workers; running jobs keep extending it.
+	VisibilityTimeout time.Duration
+	WaitTime          time.Duration // How long a worker waits for a job per receive
+	RedisAddr         string
+	RedisPassword     string
+	RedisDB           int
+	RedisKey          string // Prefix of the Redis keys holding the queue
+	SQSQueueURL       string
+	SQSRegion         string
+	// ServeWorkers runs workers in the API server too. When false, jobs are
+	// only run by `backend worker` nodes.
+	ServeWorkers bool
+}
+
+// CORSConfig holds which other origins may call the API from a browser.
+// Without allowed origins, browsers only let the API's own origin call it.
+type CORSConfig struct {
+	AllowedOrigins   []string      // e.g. "https://app.example.com", or "*"
+	AllowCredentials bool          // Lets allowed origins send the session cookie
+	MaxAge           time.Duration // How long browsers cache preflight responses
+}
+
+// SecretsConfig holds configuration for reading the encryption key and
+// database password from a secrets manager instead of the config file.
+type SecretsConfig struct {
+	Provider string // "aws", "gcp", "vault" or "" to use the config file
+	// EncryptionKey and DatabasePassword reference the secrets holding
+	// integration.encryption_key and database.password, as a secret name
+	// optionally followed by "#field" for a field of a JSON secret. The
+	// config file value is used when a reference is empty.
+	EncryptionKey    string
+	DatabasePassword string
+	// RefreshInterval is how often the server re-reads the secrets to pick
+	// up rotations. They are read once at startup if it is 0.
+	RefreshInterval time.Duration
+	AWSRegion       string
+	GCPProject      string
+	VaultAddress    string
+	VaultToken      string
+	VaultMount      string // Mount path of the KV version 2 secrets engine
 }
 
 // Config holds all application configuration.
 type Config struct {
-	Server      ServerConfig
-	Database    DatabaseConfig
-	Session     SessionConfig
-	Storage     StorageConfig
-	ScriptGen   ScriptGenConfig
-	Log         LogConfig
-	Agent       AgentConfig
-	Integration IntegrationConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Session       SessionConfig
+	Storage       StorageConfig
+	ScriptGen     ScriptGenConfig
+	Log           LogConfig
+	Agent         AgentConfig
+	Integration   IntegrationConfig
+	Resilience    ResilienceConfig
+	Health        HealthConfig
+	RateLimit     RateLimitConfig
+	Ownership     OwnershipCacheConfig
+	SAML          SAMLConfig
+	Notifications NotificationsConfig
+	Media         MediaConfig
+	Uploads       UploadsConfig
+	Idempotency   IdempotencyConfig
+	Presence      PresenceConfig
+	Retention     RetentionConfig
+	Secrets       SecretsConfig
+	Jobs          JobsConfig
+	Runs          RunsConfig
+	Queue         QueueConfig
+	CORS          CORSConfig
 }
 
 // ServerConfig holds HTTP server configuration.
@@ -44,10 +275,40 @@ type ServerConfig struct {
 	Port         int
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// DrainTimeout bounds how long shutdown waits for running jobs and
+	// script generations before interrupting them.
+	DrainTimeout time.Duration
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For headers
+	// are believed when resolving the IP address of a client.
+	TrustedProxies []netip.Prefix
+	TLS            ServerTLSConfig
+}
+
+// ServerTLSConfig holds native HTTPS configuration, so that small
+// deployments need no reverse proxy. HTTPS is served on server.port with
+// either certificate files or certificates from Let's Encrypt.
+type ServerTLSConfig struct {
+	CertFile string // PEM certificate chain; re-read when it changes
+	KeyFile  string // PEM private key of CertFile
+	// AutocertDomains are the domains to get Let's Encrypt certificates
+	// for, instead of using certificate files.
+	AutocertDomains  []string
+	AutocertEmail    string // Contact for expiry notices from Let's Encrypt
+	AutocertCacheDir string // Where certificates and the account key are kept
+	// RedirectAddr is the address of a plain HTTP listener redirecting to
+	// HTTPS, which also answers Let's Encrypt challenges; empty disables it.
+	RedirectAddr string
+}
+
+// Enabled reports whether the server serves HTTPS itself.
+func (c ServerTLSConfig) Enabled() bool {
+	return c.CertFile != "" || len(c.AutocertDomains) > 0
 }
 
 // DatabaseConfig holds database connection configuration.
 type DatabaseConfig struct {
+	// Driver is "mysql" or "sqlite".
+	Driver       string
 	Host         string
 	Port         int
 	User         string
@@ -55,6 +316,17 @@ type DatabaseConfig struct {
 	Database     string
 	MaxOpenConns int
 	MaxIdleConns int
+	// MigrationsPath is the directory of versioned SQL migrations the
+	// server checks the schema against on startup.
+	MigrationsPath string
+	// SQLitePath is the database file used when Driver is "sqlite".
+	SQLitePath string
+	// Replicas are MySQL read replicas for heavy list and count queries.
+	Replicas []database.ReplicaConfig
+	// ReplicaMaxLag is the most a replica may lag and still be read from.
+	ReplicaMaxLag time.Duration
+	// ReplicaCheckInterval is how often replica lag is measured.
+	ReplicaCheckInterval time.Duration
 }
 
 // SessionConfig holds session management configuration.
@@ -63,6 +335,10 @@ type SessionConfig struct {
 	CookieSecret string
 	Duration     time.Duration
 	Secure       bool
+	// CSRF requires session requests that change state to echo the
+	// CSRF cookie, named CSRFCookieName, in the X-CSRF-Token header.
+	CSRF           bool
+	CSRFCookieName string
 }
 
 // StorageConfig holds blob storage configuration.
@@ -72,6 +348,9 @@ type StorageConfig struct {
 	S3Bucket        string        // For S3: bucket name
 	S3Region        string        // For S3: AWS region
 	S3PresignExpiry time.Duration // Presigned URL expiration
+	// ProjectQuotaBytes limits the run assets, scripts and step images
+	// stored per project. Uploads over it are rejected; 0 is unlimited.
+	ProjectQuotaBytes int64
 }
 
 // ScriptGenConfig holds script generation configuration.
@@ -82,6 +361,8 @@ type ScriptGenConfig struct {
 	MaxTokens  int                        // Max tokens for generation
 	Validation ScriptGenValidationConfig  // Validation configuration
 	Monitoring ScriptGenMonitoringConfig  // Monitoring configuration
+	Pricing    ScriptGenPricingConfig     // Token prices for LLM usage metering
+	PostProcessing ScriptGenPostProcessingConfig // Transforms applied to generated scripts
 }
 
 // ScriptGenValidationConfig holds validation limits for script generation.
@@ -97,6 +378,24 @@ type ScriptGenMonitoringConfig struct {
 	LogSuspiciousPatterns bool // Whether to log suspicious patterns
 }
 
+// ScriptGenPricingConfig holds the price of the model's tokens, used to
+// meter the cost of model calls against LLM budgets.
+type ScriptGenPricingConfig struct {
+	InputPerMillion  float64 // USD per million input tokens
+	OutputPerMillion float64 // USD per million output tokens
+}
+
+// ScriptGenPostProcessingConfig holds the transforms applied to generated
+// scripts before they are stored. Headers and boilerplate are text/templates
+// given the procedure's name and version, the framework and the year.
+type ScriptGenPostProcessingConfig struct {
+	LicenseHeader    string        // Comment put at the top of scripts; empty disables it
+	Prologue         string        // Code injected after the scripts' imports
+	Epilogue         string        // Code appended to scripts
+	FormatterURL     string        // Sandboxed formatter service scripts are POSTed to; empty disables it
+	FormatterTimeout time.Duration // Timeout of formatter service calls
+}
+
 // LogConfig holds logging configuration.
 type LogConfig struct {
 	Level string
@@ -125,7 +424,16 @@ func LoadConfig(configPath string) (*Config, error) {
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.read_timeout", "15s")
 	v.SetDefault("server.write_timeout", "15s")
-
+	v.SetDefault("server.drain_timeout", "2m")
+	v.SetDefault("server.trusted_proxies", []string{})
+	v.SetDefault("server.tls.cert_file", "")
+	v.SetDefault("server.tls.key_file", "")
+	v.SetDefault("server.tls.autocert_domains", []string{})
+	v.SetDefault("server.tls.autocert_email", "")
+	v.SetDefault("server.tls.autocert_cache_dir", "autocert-cache")
+	v.SetDefault("server.tls.redirect_addr", ":80")
+
+	v.SetDefault("database.driver", "mysql")
 	v.SetDefault("database.host", "localhost")
 	v.SetDefault("database.port", 3306)
 	v.SetDefault("database.user", "root")
@@ -133,17 +441,24 @@ func LoadConfig(configPath string) (*Config, error) {
 	v.SetDefault("database.database", "ui_automation")
 	v.SetDefault("database.max_open_conns", 25)
 	v.SetDefault("database.max_idle_conns", 5)
+	v.SetDefault("database.migrations_path", "database/migrations")
+	v.SetDefault("database.sqlite_path", "ui_automation.db")
+	v.SetDefault("database.replica_max_lag", "5s")
+	v.SetDefault("database.replica_check_interval", "10s")
 
 	v.SetDefault("session.cookie_name", "session_id")
 	v.SetDefault("session.cookie_secret", "change-this-secret-in-production-min-32-chars")
 	v.SetDefault("session.duration", "24h")
 	v.SetDefault("session.secure", false)
+	v.SetDefault("session.csrf", true)
+	v.SetDefault("session.csrf_cookie_name", "csrf_token")
 
 	v.SetDefault("storage.type", "local")
 	v.SetDefault("storage.base_dir", "./uploads")
 	v.SetDefault("storage.s3_bucket", "")
 	v.SetDefault("storage.s3_region", "us-east-1")
 	v.SetDefault("storage.s3_presign_expiry", "15m")
+	v.SetDefault("storage.project_quota_bytes", 0)
 
 	v.SetDefault("script_gen.provider", "bedrock")
 	v.SetDefault("script_gen.region", "us-east-1")
@@ -154,6 +469,13 @@ func LoadConfig(configPath string) (*Config, error) {
 	v.SetDefault("script_gen.validation.max_steps_json_length", 50000)
 	v.SetDefault("script_gen.validation.max_steps_count", 200)
 	v.SetDefault("script_gen.monitoring.log_suspicious_patterns", true)
+	v.SetDefault("script_gen.pricing.input_per_million", 3.0)
+	v.SetDefault("script_gen.pricing.output_per_million", 15.0)
+	v.SetDefault("script_gen.post_processing.license_header", "")
+	v.SetDefault("script_gen.post_processing.prologue", "")
+	v.SetDefault("script_gen.post_processing.epilogue", "")
+	v.SetDefault("script_gen.post_processing.formatter_url", "")
+	v.SetDefault("script_gen.post_processing.formatter_timeout", "10s")
 
 	v.SetDefault("log.level", "info")
 
@@ -165,9 +487,110 @@ func LoadConfig(configPath string) (*Config, error) {
 	v.SetDefault("agent.bedrock_secret_key", "")
 	v.SetDefault("agent.playwright_mcp_url", "http://localhost:3000")
 	v.SetDefault("agent.script_path", "/app/agent/agent_runner.py")
+	v.SetDefault("agent.visual_capture_script_path", "/app/agent/visual_capture.py")
+	v.SetDefault("agent.procedure_script_path", "/app/agent/procedure_runner.py")
 	v.SetDefault("agent.max_concurrent_workers", 1)
 
 	v.SetDefault("integration.encryption_key", "change-this-encryption-key-in-production-min32")
+	v.SetDefault("integration.check_interval", "24h")
+	v.SetDefault("integration.expiry_warning", "168h")
+
+	v.SetDefault("resilience.issue_tracker_timeout", "15s")
+	v.SetDefault("resilience.llm_timeout", "5m")
+	v.SetDefault("resilience.mcp_timeout", "5s")
+	v.SetDefault("resilience.failure_threshold", 5)
+	v.SetDefault("resilience.open_duration", "30s")
+
+	v.SetDefault("health.poll_interval", "30s")
+	v.SetDefault("health.timeout", "10s")
+	v.SetDefault("health.retention", "720h")
+
+	v.SetDefault("rate_limit.requests_per_minute", 600)
+	v.SetDefault("rate_limit.burst", 120)
+	v.SetDefault("rate_limit.expensive_requests_per_minute", 60)
+	v.SetDefault("rate_limit.expensive_burst", 30)
+
+	v.SetDefault("ownership_cache.type", "memory")
+	v.SetDefault("ownership_cache.ttl", "5m")
+	v.SetDefault("ownership_cache.max_entries", 100000)
+	v.SetDefault("ownership_cache.redis_addr", "localhost:6379")
+	v.SetDefault("ownership_cache.redis_password", "")
+	v.SetDefault("ownership_cache.redis_db", 0)
+
+	v.SetDefault("saml.enabled", false)
+	v.SetDefault("saml.base_url", "")
+	v.SetDefault("saml.entity_id", "")
+	v.SetDefault("saml.enforce_sso", false)
+	v.SetDefault("saml.admin_emails", []string{})
+	v.SetDefault("saml.attributes.email", "email")
+	v.SetDefault("saml.attributes.username", "username")
+	v.SetDefault("saml.clock_skew", "3m")
+
+	v.SetDefault("notifications.base_url", "")
+	v.SetDefault("notifications.workers", 2)
+	v.SetDefault("notifications.queue_size", 1000)
+	v.SetDefault("notifications.timeout", "10s")
+	v.SetDefault("notifications.smtp_host", "")
+	v.SetDefault("notifications.smtp_port", 587)
+	v.SetDefault("notifications.smtp_username", "")
+	v.SetDefault("notifications.smtp_password", "")
+	v.SetDefault("notifications.smtp_from", "")
+	v.SetDefault("notifications.slack_enabled", true)
+
+	v.SetDefault("media.enabled", true)
+	v.SetDefault("media.ffmpeg_path", "ffmpeg")
+	v.SetDefault("media.transcode_formats", []string{})
+	v.SetDefault("media.thumbnail_width", media.DefaultThumbnailWidth)
+	v.SetDefault("media.workers", 1)
+	v.SetDefault("media.queue_size", 100)
+	v.SetDefault("media.timeout", "10m")
+
+	v.SetDefault("uploads.part_size", 5*1024*1024)
+	v.SetDefault("uploads.max_size", 1024*1024*1024)
+	v.SetDefault("uploads.session_ttl", "24h")
+	v.SetDefault("uploads.cleanup_interval", "1h")
+
+	v.SetDefault("idempotency.ttl", "24h")
+	v.SetDefault("idempotency.cleanup_interval", "1h")
+
+	v.SetDefault("presence.timeout", "30s")
+	v.SetDefault("presence.lock_ttl", "5m")
+
+	v.SetDefault("retention.interval", "6h")
+	v.SetDefault("retention.batch_size", retention.DefaultBatchSize)
+	v.SetDefault("retention.dry_run", false)
+
+	v.SetDefault("jobs.heartbeat_interval", "30s")
+	v.SetDefault("jobs.stale_timeout", "5m")
+	v.SetDefault("jobs.stale_action", string(job.StaleActionFail))
+	v.SetDefault("jobs.reap_interval", "1m")
+
+	v.SetDefault("runs.overdue_check_interval", "5m")
+
+	v.SetDefault("queue.type", "")
+	v.SetDefault("queue.visibility_timeout", "2m")
+	v.SetDefault("queue.wait_time", "20s")
+	v.SetDefault("queue.redis_addr", "localhost:6379")
+	v.SetDefault("queue.redis_password", "")
+	v.SetDefault("queue.redis_db", 0)
+	v.SetDefault("queue.redis_key", "ui-automation:jobs")
+	v.SetDefault("queue.sqs_queue_url", "")
+	v.SetDefault("queue.sqs_region", "us-east-1")
+	v.SetDefault("queue.serve_workers", true)
+
+	v.SetDefault("cors.allowed_origins", []string{})
+	v.SetDefault("cors.allow_credentials", false)
+	v.SetDefault("cors.max_age", "10m")
+
+	v.SetDefault("secrets.provider", "")
+	v.SetDefault("secrets.encryption_key", "")
+	v.SetDefault("secrets.database_password", "")
+	v.SetDefault("secrets.refresh_interval", "5m")
+	v.SetDefault("secrets.aws_region", "us-east-1")
+	v.SetDefault("secrets.gcp_project", "")
+	v.SetDefault("secrets.vault_address", "")
+	v.SetDefault("secrets.vault_token", "")
+	v.SetDefault("secrets.vault_mount", "secret")
 
 	// Read config file
 	if err := v.ReadInConfig(); err != nil {
@@ -184,7 +607,34 @@ func LoadConfig(configPath string) (*Config, error) {
 	config.Server.Port = v.GetInt("server.port")
 	config.Server.ReadTimeout = v.GetDuration("server.read_timeout")
 	config.Server.WriteTimeout = v.GetDuration("server.write_timeout")
+	config.Server.DrainTimeout = v.GetDuration("server.drain_timeout")
+	if config.Server.DrainTimeout < 0 {
+		return nil, fmt.Errorf("server.drain_timeout must not be negative")
+	}
+	for _, cidr := range v.GetStringSlice("server.trusted_proxies") {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid server.trusted_proxies: %w", err)
+		}
+		config.Server.TrustedProxies = append(config.Server.TrustedProxies, prefix.Masked())
+	}
+	config.Server.TLS.CertFile = v.GetString("server.tls.cert_file")
+	config.Server.TLS.KeyFile = v.GetString("server.tls.key_file")
+	config.Server.TLS.AutocertDomains = v.GetStringSlice("server.tls.autocert_domains")
+	config.Server.TLS.AutocertEmail = v.GetString("server.tls.autocert_email")
+	config.Server.TLS.AutocertCacheDir = v.GetString("server.tls.autocert_cache_dir")
+	config.Server.TLS.RedirectAddr = v.GetString("server.tls.redirect_addr")
+	if (config.Server.TLS.CertFile == "") != (config.Server.TLS.KeyFile == "") {
+		return nil, fmt.Errorf("server.tls.cert_file and server.tls.key_file must be set together")
+	}
+	if config.Server.TLS.CertFile != "" && len(config.Server.TLS.AutocertDomains) > 0 {
+		return nil, fmt.Errorf("server.tls.autocert_domains cannot be used with certificate files")
+	}
+	if len(config.Server.TLS.AutocertDomains) > 0 && config.Server.TLS.AutocertCacheDir == "" {
+		return nil, fmt.Errorf("server.tls.autocert_cache_dir is required with server.tls.autocert_domains")
+	}
 
+	config.Database.Driver = v.GetString("database.driver")
 	config.Database.Host = v.GetString("database.host")
 	config.Database.Port = v.GetInt("database.port")
 	config.Database.User = v.GetString("database.user")
@@ -192,17 +642,32 @@ func LoadConfig(configPath string) (*Config, error) {
 	config.Database.Database = v.GetString("database.database")
 	config.Database.MaxOpenConns = v.GetInt("database.max_open_conns")
 	config.Database.MaxIdleConns = v.GetInt("database.max_idle_conns")
+	config.Database.MigrationsPath = v.GetString("database.migrations_path")
+	config.Database.SQLitePath = v.GetString("database.sqlite_path")
+	if err := v.UnmarshalKey("database.replicas", &config.Database.Replicas); err != nil {
+		return nil, fmt.Errorf("invalid database.replicas: %w", err)
+	}
+	for i := range config.Database.Replicas {
+		if config.Database.Replicas[i].Port == 0 {
+			config.Database.Replicas[i].Port = config.Database.Port
+		}
+	}
+	config.Database.ReplicaMaxLag = v.GetDuration("database.replica_max_lag")
+	config.Database.ReplicaCheckInterval = v.GetDuration("database.replica_check_interval")
 
 	config.Session.CookieName = v.GetString("session.cookie_name")
 	config.Session.CookieSecret = v.GetString("session.cookie_secret")
 	config.Session.Duration = v.GetDuration("session.duration")
 	config.Session.Secure = v.GetBool("session.secure")
+	config.Session.CSRF = v.GetBool("session.csrf")
+	config.Session.CSRFCookieName = v.GetString("session.csrf_cookie_name")
 
 	config.Storage.Type = v.GetString("storage.type")
 	config.Storage.BaseDir = v.GetString("storage.base_dir")
 	config.Storage.S3Bucket = v.GetString("storage.s3_bucket")
 	config.Storage.S3Region = v.GetString("storage.s3_region")
 	config.Storage.S3PresignExpiry = v.GetDuration("storage.s3_presign_expiry")
+	config.Storage.ProjectQuotaBytes = v.GetInt64("storage.project_quota_bytes")
 
 	config.ScriptGen.Provider = v.GetString("script_gen.provider")
 	config.ScriptGen.Region = v.GetString("script_gen.region")
@@ -213,6 +678,16 @@ func LoadConfig(configPath string) (*Config, error) {
 	config.ScriptGen.Validation.MaxStepsJSONLength = v.GetInt("script_gen.validation.max_steps_json_length")
 	config.ScriptGen.Validation.MaxStepsCount = v.GetInt("script_gen.validation.max_steps_count")
 	config.ScriptGen.Monitoring.LogSuspiciousPatterns = v.GetBool("script_gen.monitoring.log_suspicious_patterns")
+	config.ScriptGen.Pricing.InputPerMillion = v.GetFloat64("script_gen.pricing.input_per_million")
+	config.ScriptGen.Pricing.OutputPerMillion = v.GetFloat64("script_gen.pricing.output_per_million")
+	config.ScriptGen.PostProcessing.LicenseHeader = v.GetString("script_gen.post_processing.license_header")
+	config.ScriptGen.PostProcessing.Prologue = v.GetString("script_gen.post_processing.prologue")
+	config.ScriptGen.PostProcessing.Epilogue = v.GetString("script_gen.post_processing.epilogue")
+	config.ScriptGen.PostProcessing.FormatterURL = v.GetString("script_gen.post_processing.formatter_url")
+	config.ScriptGen.PostProcessing.FormatterTimeout = v.GetDuration("script_gen.post_processing.formatter_timeout")
+	if config.ScriptGen.PostProcessing.FormatterURL != "" && config.ScriptGen.PostProcessing.FormatterTimeout <= 0 {
+		return nil, fmt.Errorf("script_gen.post_processing.formatter_timeout must be positive")
+	}
 
 	config.Log.Level = v.GetString("log.level")
 
@@ -224,9 +699,264 @@ func LoadConfig(configPath string) (*Config, error) {
 	config.Agent.BedrockSecretKey = v.GetString("agent.bedrock_secret_key")
 	config.Agent.PlaywrightMCPURL = v.GetString("agent.playwright_mcp_url")
 	config.Agent.AgentScriptPath = v.GetString("agent.script_path")
+	config.Agent.VisualCaptureScriptPath = v.GetString("agent.visual_capture_script_path")
+	config.Agent.ProcedureScriptPath = v.GetString("agent.procedure_script_path")
 	config.Agent.MaxConcurrentWorkers = v.GetInt("agent.max_concurrent_workers")
 
 	config.Integration.EncryptionKey = v.GetString("integration.encryption_key")
+	config.Integration.CheckInterval = v.GetDuration("integration.check_interval")
+	config.Integration.ExpiryWarning = v.GetDuration("integration.expiry_warning")
+
+	config.Resilience.IssueTrackerTimeout = v.GetDuration("resilience.issue_tracker_timeout")
+	config.Resilience.LLMTimeout = v.GetDuration("resilience.llm_timeout")
+	config.Resilience.MCPTimeout = v.GetDuration("resilience.mcp_timeout")
+	config.Resilience.FailureThreshold = v.GetInt("resilience.failure_threshold")
+	config.Resilience.OpenDuration = v.GetDuration("resilience.open_duration")
+
+	config.Health.PollInterval = v.GetDuration("health.poll_interval")
+	config.Health.Timeout = v.GetDuration("health.timeout")
+	config.Health.Retention = v.GetDuration("health.retention")
+
+	config.RateLimit.RequestsPerMinute = v.GetInt("rate_limit.requests_per_minute")
+	config.RateLimit.Burst = v.GetInt("rate_limit.burst")
+	config.RateLimit.ExpensiveRequestsPerMinute = v.GetInt("rate_limit.expensive_requests_per_minute")
+	config.RateLimit.ExpensiveBurst = v.GetInt("rate_limit.expensive_burst")
+
+	config.Ownership.Type = v.GetString("ownership_cache.type")
+	config.Ownership.TTL = v.GetDuration("ownership_cache.ttl")
+	config.Ownership.MaxEntries = v.GetInt("ownership_cache.max_entries")
+	config.Ownership.RedisAddr = v.GetString("ownership_cache.redis_addr")
+	config.Ownership.RedisPassword = v.GetString("ownership_cache.redis_password")
+	config.Ownership.RedisDB = v.GetInt("ownership_cache.redis_db")
+
+	config.SAML.Enabled = v.GetBool("saml.enabled")
+	config.SAML.BaseURL = strings.TrimSuffix(v.GetString("saml.base_url"), "/")
+	config.SAML.EntityID = v.GetString("saml.entity_id")
+	config.SAML.EnforceSSO = v.GetBool("saml.enforce_sso")
+	config.SAML.AdminEmails = v.GetStringSlice("saml.admin_emails")
+	config.SAML.EmailAttribute = v.GetString("saml.attributes.email")
+	config.SAML.UsernameAttribute = v.GetString("saml.attributes.username")
+	config.SAML.ClockSkew = v.GetDuration("saml.clock_skew")
+	if config.SAML.Enabled && config.SAML.BaseURL == "" {
+		return nil, fmt.Errorf("saml.base_url is required when saml.enabled is true")
+	}
+	if config.SAML.EntityID == "" {
+		config.SAML.EntityID = config.SAML.BaseURL + samlMetadataPath
+	}
+
+	config.Notifications.BaseURL = strings.TrimSuffix(v.GetString("notifications.base_url"), "/")
+	config.Notifications.Workers = v.GetInt("notifications.workers")
+	config.Notifications.QueueSize = v.GetInt("notifications.queue_size")
+	config.Notifications.Timeout = v.GetDuration("notifications.timeout")
+	config.Notifications.SMTPHost = v.GetString("notifications.smtp_host")
+	config.Notifications.SMTPPort = v.GetInt("notifications.smtp_port")
+	config.Notifications.SMTPUsername = v.GetString("notifications.smtp_username")
+	config.Notifications.SMTPPassword = v.GetString("notifications.smtp_password")
+	config.Notifications.SMTPFrom = v.GetString("notifications.smtp_from")
+	config.Notifications.SlackEnabled = v.GetBool("notifications.slack_enabled")
+	if config.Notifications.SMTPHost != "" && config.Notifications.SMTPFrom == "" {
+		return nil, fmt.Errorf("notifications.smtp_from is required when notifications.smtp_host is set")
+	}
+
+	config.Media.Enabled = v.GetBool("media.enabled")
+	config.Media.FFmpegPath = v.GetString("media.ffmpeg_path")
+	for _, name := range v.GetStringSlice("media.transcode_formats") {
+		format, err := media.ParseFormat(strings.ToLower(strings.TrimSpace(name)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid media.transcode_formats: %w", err)
+		}
+		config.Media.TranscodeFormats = append(config.Media.TranscodeFormats, format)
+	}
+	config.Media.ThumbnailWidth = v.GetInt("media.thumbnail_width")
+	config.Media.Workers = v.GetInt("media.workers")
+	config.Media.QueueSize = v.GetInt("media.queue_size")
+	config.Media.Timeout = v.GetDuration("media.timeout")
+
+	config.Uploads.PartSize = v.GetInt64("uploads.part_size")
+	config.Uploads.MaxSize = v.GetInt64("uploads.max_size")
+	config.Uploads.SessionTTL = v.GetDuration("uploads.session_ttl")
+	config.Uploads.CleanupInterval = v.GetDuration("uploads.cleanup_interval")
+	if config.Uploads.PartSize <= 0 {
+		return nil, fmt.Errorf("uploads.part_size must be positive")
+	}
+
+	config.Idempotency.TTL = v.GetDuration("idempotency.ttl")
+	config.Idempotency.CleanupInterval = v.GetDuration("idempotency.cleanup_interval")
+	if config.Idempotency.TTL <= 0 {
+		return nil, fmt.Errorf("idempotency.ttl must be positive")
+	}
+	if config.Idempotency.CleanupInterval <= 0 {
+		return nil, fmt.Errorf("idempotency.cleanup_interval must be positive")
+	}
+
+	config.Presence.Timeout = v.GetDuration("presence.timeout")
+	config.Presence.LockTTL = v.GetDuration("presence.lock_ttl")
+	if config.Presence.Timeout <= 0 {
+		return nil, fmt.Errorf("presence.timeout must be positive")
+	}
+	if config.Presence.LockTTL <= 0 {
+		return nil, fmt.Errorf("presence.lock_ttl must be positive")
+	}
+
+	config.Retention.Interval = v.GetDuration("retention.interval")
+	config.Retention.BatchSize = v.GetInt("retention.batch_size")
+	config.Retention.DryRun = v.GetBool("retention.dry_run")
+	if config.Retention.Interval < 0 {
+		return nil, fmt.Errorf("retention.interval must not be negative")
+	}
+	if config.Integration.CheckInterval < 0 {
+		return nil, fmt.Errorf("integration.check_interval must not be negative")
+	}
+
+	config.Jobs.HeartbeatInterval = v.GetDuration("jobs.heartbeat_interval")
+	config.Jobs.StaleTimeout = v.GetDuration("jobs.stale_timeout")
+	config.Jobs.StaleAction = v.GetString("jobs.stale_action")
+	config.Jobs.ReapInterval = v.GetDuration("jobs.reap_interval")
+	if config.Jobs.HeartbeatInterval <= 0 {
+		return nil, fmt.Errorf("jobs.heartbeat_interval must be positive")
+	}
+	if config.Jobs.StaleTimeout <= config.Jobs.HeartbeatInterval {
+		return nil, fmt.Errorf("jobs.stale_timeout must be longer than jobs.heartbeat_interval")
+	}
+	if !job.StaleAction(config.Jobs.StaleAction).IsValid() {
+		return nil, fmt.Errorf("jobs.stale_action must be fail or requeue")
+	}
+	if config.Jobs.ReapInterval < 0 {
+		return nil, fmt.Errorf("jobs.reap_interval must not be negative")
+	}
+
+	config.Runs.OverdueCheckInterval = v.GetDuration("runs.overdue_check_interval")
+	if config.Runs.OverdueCheckInterval < 0 {
+		return nil, fmt.Errorf("runs.overdue_check_interval must not be negative")
+	}
+
+	config.Queue.Type = v.GetString("queue.type")
+	config.Queue.VisibilityTimeout = v.GetDuration("queue.visibility_timeout")
+	config.Queue.WaitTime = v.GetDuration("queue.wait_time")
+	config.Queue.RedisAddr = v.GetString("queue.redis_addr")
+	config.Queue.RedisPassword = v.GetString("queue.redis_password")
+	config.Queue.RedisDB = v.GetInt("queue.redis_db")
+	config.Queue.RedisKey = v.GetString("queue.redis_key")
+	config.Queue.SQSQueueURL = v.GetString("queue.sqs_queue_url")
+	config.Queue.SQSRegion = v.GetString("queue.sqs_region")
+	config.Queue.ServeWorkers = v.GetBool("queue.serve_workers")
+	switch config.Queue.Type {
+	case "", queue.TypeRedis:
+	case queue.TypeSQS:
+		if config.Queue.SQSQueueURL == "" {
+			return nil, fmt.Errorf("queue.sqs_queue_url is required when queue.type is sqs")
+		}
+		if config.Queue.WaitTime > queue.MaxSQSWaitTime {
+			return nil, fmt.Errorf("queue.wait_time must not exceed 20s with sqs")
+		}
+	default:
+		return nil, fmt.Errorf("queue.type must be redis or sqs")
+	}
+	if config.Queue.VisibilityTimeout < time.Second {
+		return nil, fmt.Errorf("queue.visibility_timeout must be at least 1s")
+	}
+	if config.Queue.WaitTime <= 0 {
+		return nil, fmt.Errorf("queue.wait_time must be positive")
+	}
+
+	config.Secrets.Provider = v.GetString("secrets.provider")
+	config.Secrets.EncryptionKey = v.GetString("secrets.encryption_key")
+	config.Secrets.DatabasePassword = v.GetString("secrets.database_password")
+	config.Secrets.RefreshInterval = v.GetDuration("secrets.refresh_interval")
+	config.Secrets.AWSRegion = v.GetString("secrets.aws_region")
+	config.Secrets.GCPProject = v.GetString("secrets.gcp_project")
+	config.Secrets.VaultAddress = v.GetString("secrets.vault_address")
+	config.Secrets.VaultToken = v.GetString("secrets.vault_token")
+	config.Secrets.VaultMount = v.GetString("secrets.vault_mount")
+	// Fall back to the environment variables the Vault CLI uses.
+	if config.Secrets.VaultAddress == "" {
+		config.Secrets.VaultAddress = os.Getenv("VAULT_ADDR")
+	}
+	if config.Secrets.VaultToken == "" {
+		config.Secrets.VaultToken = os.Getenv("VAULT_TOKEN")
+	}
+	switch config.Secrets.Provider {
+	case "", secrets.ProviderAWS, secrets.ProviderGCP, secrets.ProviderVault:
+	default:
+		return nil, fmt.Errorf("secrets.provider must be aws, gcp or vault")
+	}
+	if config.Secrets.Provider == "" && (config.Secrets.EncryptionKey != "" || config.Secrets.DatabasePassword != "") {
+		return nil, fmt.Errorf("secrets.provider is required when secret references are set")
+	}
+	if config.Secrets.RefreshInterval < 0 {
+		return nil, fmt.Errorf("secrets.refresh_interval must not be negative")
+	}
+
+	for _, origin := range v.GetStringSlice("cors.allowed_origins") {
+		origin = strings.TrimSpace(origin)
+		if origin != "*" {
+			// Browsers send origins as scheme://host[:port], which is all
+			// an allowed origin may be.
+			u, err := url.Parse(origin)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Scheme+"://"+u.Host != origin {
+				return nil, fmt.Errorf("invalid cors.allowed_origins entry %q: must be scheme://host[:port] or *", origin)
+			}
+		}
+		config.CORS.AllowedOrigins = append(config.CORS.AllowedOrigins, origin)
+	}
+	config.CORS.AllowCredentials = v.GetBool("cors.allow_credentials")
+	config.CORS.MaxAge = v.GetDuration("cors.max_age")
+	if config.CORS.AllowCredentials && slices.Contains(config.CORS.AllowedOrigins, "*") {
+		return nil, fmt.Errorf("cors.allow_credentials cannot be used with the * origin")
+	}
+	if config.CORS.MaxAge < 0 {
+		return nil, fmt.Errorf("cors.max_age must not be negative")
+	}
 
 	return &config, nil
 }
+
+// serviceProviderConfig converts c to the SAML service provider's identity.
+func (c SAMLConfig) serviceProviderConfig() saml.Config {
+	return saml.Config{
+		EntityID:  c.EntityID,
+		ACSURL:    c.BaseURL + samlACSPath,
+		ClockSkew: c.ClockSkew,
+	}
+}
+
+// providerConfig converts c to the configuration used by
+// secrets.NewProvider.
+func (c SecretsConfig) providerConfig() secrets.Config {
+	return secrets.Config{
+		Provider:     c.Provider,
+		AWSRegion:    c.AWSRegion,
+		GCPProject:   c.GCPProject,
+		VaultAddress: c.VaultAddress,
+		VaultToken:   c.VaultToken,
+		VaultMount:   c.VaultMount,
+	}
+}
+
+// blobStorageConfig converts c to the configuration used by
+// storage.NewBlobStorage.
+func (c StorageConfig) blobStorageConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"base_dir":       c.BaseDir,
+		"bucket":         c.S3Bucket,
+		"region":         c.S3Region,
+		"presign_expiry": c.S3PresignExpiry,
+	}
+}
+
+// connectionConfig converts c to the configuration used by database.Connect.
+func (c DatabaseConfig) connectionConfig() database.Config {
+	return database.Config{
+		Driver:        c.Driver,
+		Host:          c.Host,
+		Port:          c.Port,
+		User:          c.User,
+		Password:      c.Password,
+		Database:      c.Database,
+		MaxOpenConns:  c.MaxOpenConns,
+		MaxIdleConns:  c.MaxIdleConns,
+		SQLitePath:    c.SQLitePath,
+		Replicas:      c.Replicas,
+		ReplicaMaxLag: c.ReplicaMaxLag,
+	}
+}
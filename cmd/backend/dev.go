@@ -0,0 +1,107 @@
+package main
+
+import (
+	"github.com/hairizuanbinnoorazman/ui-automation/activity"
+	"github.com/hairizuanbinnoorazman/ui-automation/analytics"
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"github.com/hairizuanbinnoorazman/ui-automation/cipipeline"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/dataset"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/idempotency"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/label"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
+	"github.com/hairizuanbinnoorazman/ui-automation/procedureshare"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/release"
+	"github.com/hairizuanbinnoorazman/ui-automation/requirement"
+	"github.com/hairizuanbinnoorazman/ui-automation/retention"
+	"github.com/hairizuanbinnoorazman/ui-automation/review"
+	"github.com/hairizuanbinnoorazman/ui-automation/runner"
+	"github.com/hairizuanbinnoorazman/ui-automation/saml"
+	"github.com/hairizuanbinnoorazman/ui-automation/savedview"
+	"github.com/hairizuanbinnoorazman/ui-automation/scriptgen"
+	"github.com/hairizuanbinnoorazman/ui-automation/scriptrepo"
+	"github.com/hairizuanbinnoorazman/ui-automation/serviceaccount"
+	"github.com/hairizuanbinnoorazman/ui-automation/share"
+	"github.com/hairizuanbinnoorazman/ui-automation/steplibrary"
+	"github.com/hairizuanbinnoorazman/ui-automation/testmanagement"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/upload"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/hairizuanbinnoorazman/ui-automation/visualregression"
+)
+
+// schemaModels returns every persisted model. SQLite databases are created
+// from these with GORM auto-migration instead of the MySQL SQL migrations,
+// so a table added by a migration must also be added here.
+func schemaModels() []interface{} {
+	return []interface{}{
+		&user.User{},
+		&project.Project{},
+		&retention.Policy{},
+		&release.Release{},
+		&analytics.ProcedureStats{},
+		&analytics.DailyStats{},
+		&analytics.StepFailures{},
+		&label.Label{},
+		&requirement.Link{},
+		&testprocedure.TestProcedure{},
+		&testprocedure.StepImage{},
+		&testprocedure.StepAttachment{},
+		&testprocedure.DraftSnapshot{},
+		&review.Review{},
+		&procedureshare.Share{},
+		&testrun.TestRun{},
+		&testrun.TestRunAsset{},
+		&testrun.StepNote{},
+		&testrun.Comment{},
+		&testrun.AssetAnnotation{},
+		&endpoint.Endpoint{},
+		&endpoint.HealthCheck{},
+		&endpoint.Secret{},
+		&job.Job{},
+		&job.LogEntry{},
+		&apitoken.APIToken{},
+		&serviceaccount.ServiceAccount{},
+		&serviceaccount.Grant{},
+		&integration.Integration{},
+		&integration.IssueLink{},
+		&savedview.SavedView{},
+		&metering.Event{},
+		&llmusage.Event{},
+		&llmusage.Budget{},
+		&visualregression.Baseline{},
+		&scriptgen.GeneratedScript{},
+		&scriptgen.Revision{},
+		&scriptrepo.Repository{},
+		&cipipeline.Pipeline{},
+		&cipipeline.Build{},
+		&testmanagement.Connection{},
+		&testmanagement.Link{},
+		&share.Link{},
+		&activity.Event{},
+		&saml.IdentityProvider{},
+		&notification.Preferences{},
+		&upload.Session{},
+		&upload.Part{},
+		&runner.Runner{},
+		&idempotency.Record{},
+		&steplibrary.StepGroup{},
+		&dataset.Dataset{},
+		&testrun.RunGroup{},
+		&testrun.RunTemplate{},
+	}
+}
+
+// applyDevMode switches cfg to a SQLite database and local blob storage so
+// the server runs on a single machine without MySQL or S3.
+func applyDevMode(cfg *Config) {
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Storage.Type = "local"
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/activity"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// ActivityHandler handles the activity feeds of users.
+type ActivityHandler struct {
+	store     activity.Store
+	userStore user.Store
+	logger    logger.Logger
+}
+
+// NewActivityHandler creates a new activity handler.
+func NewActivityHandler(store activity.Store, userStore user.Store, log logger.Logger) *ActivityHandler {
+	return &ActivityHandler{
+		store:     store,
+		userStore: userStore,
+		logger:    log,
+	}
+}
+
+// ListByUser handles GET /users/{id}/activity, a paginated feed of what a
+// user did, newest first, by offset or ?cursor=. ?since= (RFC 3339) and
+// ?action= narrow it. Users and admins see all of a user's activity;
+// others see only what the user did in their projects.
+func (h *ActivityHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "user")
+	if !ok {
+		return
+	}
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var filter activity.Filter
+	if s := r.URL.Query().Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "since must be an RFC 3339 time")
+			return
+		}
+		filter.Since = &since
+	}
+	if a := activity.Action(r.URL.Query().Get("action")); a != "" {
+		if !a.IsValid() {
+			respondError(w, http.StatusBadRequest, activity.ErrInvalidAction.Error())
+			return
+		}
+		filter.Action = a
+	}
+
+	if _, err := h.userStore.GetAnyByID(r.Context(), id); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get user")
+		return
+	}
+	if callerID != id {
+		caller, err := h.userStore.GetByID(r.Context(), callerID)
+		if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusInternalServerError, "failed to get user")
+			return
+		}
+		if err != nil || !caller.IsAdmin() {
+			filter.ProjectOwnerID = &callerID
+		}
+	}
+
+	limit, offset := parsePagination(r)
+	if usesCursor(r) {
+		cursor, ok := parseCursor(w, r)
+		if !ok {
+			return
+		}
+		events, err := h.store.ListByUserAfter(r.Context(), id, filter, cursor, limit+1)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to list activity")
+			return
+		}
+		events, next := cursorPage(events, limit, func(e *activity.Event) (time.Time, uuid.UUID) {
+			return e.CreatedAt, e.ID
+		})
+		respondJSON(w, http.StatusOK, NewCursorResponse(events, limit, next))
+		return
+	}
+
+	total, err := h.store.CountByUser(r.Context(), id, filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to count activity")
+		return
+	}
+	events, err := h.store.ListByUser(r.Context(), id, filter, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list activity")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, NewPaginatedResponse(events, total, limit, offset))
+}
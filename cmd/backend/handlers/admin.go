@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"github.com/hairizuanbinnoorazman/ui-automation/handover"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/session"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// AdminMiddleware only lets platform admins through. The role is read from
+// the store on every request, so a demoted admin loses access at once.
+type AdminMiddleware struct {
+	userStore user.Store
+	logger    logger.Logger
+}
+
+// NewAdminMiddleware creates a new admin middleware.
+func NewAdminMiddleware(userStore user.Store, log logger.Logger) *AdminMiddleware {
+	return &AdminMiddleware{
+		userStore: userStore,
+		logger:    log,
+	}
+}
+
+// Handler wraps an HTTP handler with the admin check.
+func (m *AdminMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := GetUserID(r.Context())
+		if !ok {
+			respondError(w, http.StatusUnauthorized, "user not authenticated")
+			return
+		}
+
+		caller, err := m.userStore.GetByID(r.Context(), userID)
+		if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusInternalServerError, "failed to get user")
+			return
+		}
+		if err != nil || !caller.IsAdmin() {
+			m.logger.Warn(r.Context(), "admin access denied", map[string]interface{}{
+				"user_id": userID.String(),
+				"path":    r.URL.Path,
+			})
+			respondError(w, http.StatusForbidden, "admin access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminHandler handles the admin API, which manages every user and project
+// on the platform. Users manage their own account through UserHandler.
+type AdminHandler struct {
+	userStore      user.Store
+	projectStore   project.Store
+	handoverStore  handover.Store
+	sessionManager *session.Manager
+	tokenStore     apitoken.Store
+	owners         *ownership.Resolver
+	logger         logger.Logger
+}
+
+// NewAdminHandler creates a new admin handler. Deactivated users are logged
+// out of sessionManager and their tokens in tokenStore revoked.
+func NewAdminHandler(userStore user.Store, projectStore project.Store, handoverStore handover.Store, sessionManager *session.Manager, tokenStore apitoken.Store, owners *ownership.Resolver, log logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		userStore:      userStore,
+		projectStore:   projectStore,
+		handoverStore:  handoverStore,
+		sessionManager: sessionManager,
+		tokenStore:     tokenStore,
+		owners:         owners,
+		logger:         log,
+	}
+}
+
+// ResetPasswordRequest represents an admin setting a user's password.
+type ResetPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// SetRoleRequest represents an admin changing a user's role.
+type SetRoleRequest struct {
+	Role user.Role `json:"role"`
+}
+
+// TransferHoldingsRequest represents an admin moving everything a user owns
+// to another user.
+type TransferHoldingsRequest struct {
+	ToUserID uuid.UUID `json:"to_user_id"`
+}
+
+// TransferProjectRequest represents an admin moving a project to another
+// owner.
+type TransferProjectRequest struct {
+	OwnerID uuid.UUID `json:"owner_id"`
+}
+
+// parsePagination parses ?limit= (default 20, at most 100) and ?offset=.
+func parsePagination(r *http.Request) (int, int) {
+	limit := 20 // default
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	offset := 0 // default
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+	return limit, offset
+}
+
+// ListUsers handles GET /admin/users, including deactivated users. ?search=
+// matches usernames and emails.
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+	query := r.URL.Query().Get("search")
+
+	total, err := h.userStore.CountAll(r.Context(), query)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to count users")
+		return
+	}
+
+	users, err := h.userStore.ListAll(r.Context(), query, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list users")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, NewPaginatedResponse(users, total, limit, offset))
+}
+
+// GetUser handles GET /admin/users/{id}, including deactivated users.
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "user")
+	if !ok {
+		return
+	}
+
+	target, ok := h.loadUser(w, r, id)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, target)
+}
+
+// DeactivateUser handles POST /admin/users/{id}/deactivate. The user can no
+// longer log in, their sessions end and their API tokens are revoked; what
+// they own stays theirs until transferred. Admins cannot deactivate
+// themselves, nor the last active admin.
+func (h *AdminHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "user")
+	if !ok {
+		return
+	}
+	if callerID, _ := GetUserID(r.Context()); callerID == id {
+		respondError(w, http.StatusBadRequest, "cannot deactivate yourself")
+		return
+	}
+
+	target, ok := h.loadUser(w, r, id)
+	if !ok {
+		return
+	}
+	if target.IsAdmin() && !h.checkNotLastAdmin(w, r) {
+		return
+	}
+
+	if err := h.userStore.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusConflict, "user already deactivated")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to deactivate user")
+		return
+	}
+	endUserAccess(r, h.sessionManager, h.tokenStore, id, h.logger)
+
+	h.logAction(r, "user deactivated", id)
+	respondSuccess(w, "user deactivated successfully")
+}
+
+// ReactivateUser handles POST /admin/users/{id}/reactivate. Tokens revoked
+// on deactivation stay revoked.
+func (h *AdminHandler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "user")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.loadUser(w, r, id); !ok {
+		return
+	}
+	if err := h.userStore.Reactivate(r.Context(), id); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusConflict, "user already active")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to reactivate user")
+		return
+	}
+
+	h.logAction(r, "user reactivated", id)
+	respondSuccess(w, "user reactivated successfully")
+}
+
+// ResetPassword handles POST /admin/users/{id}/password, setting the
+// password of an active user without their current one.
+func (h *AdminHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "user")
+	if !ok {
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.userStore.Update(r.Context(), id, user.SetPassword(req.Password)); err != nil {
+		switch {
+		case errors.Is(err, user.ErrUserNotFound):
+			respondError(w, http.StatusNotFound, "user not found")
+		case errors.Is(err, user.ErrPasswordTooShort), errors.Is(err, user.ErrServiceAccount):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to reset password")
+		}
+		return
+	}
+
+	h.logAction(r, "user password reset", id)
+	respondSuccess(w, "password reset successfully")
+}
+
+// SetRole handles PUT /admin/users/{id}/role. The last active admin cannot
+// be demoted.
+func (h *AdminHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "user")
+	if !ok {
+		return
+	}
+
+	var req SetRoleRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !req.Role.IsAssignable() {
+		respondError(w, http.StatusBadRequest, user.ErrInvalidRole.Error())
+		return
+	}
+
+	target, err := h.userStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get user")
+		return
+	}
+	if target.IsAdmin() && req.Role != user.RoleAdmin && !h.checkNotLastAdmin(w, r) {
+		return
+	}
+
+	if err := h.userStore.Update(r.Context(), id, user.SetRole(req.Role)); err != nil {
+		switch {
+		case errors.Is(err, user.ErrUserNotFound):
+			respondError(w, http.StatusNotFound, "user not found")
+		case errors.Is(err, user.ErrServiceAccount):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to set role")
+		}
+		return
+	}
+	target.Role = req.Role
+
+	h.logger.Info(r.Context(), "user role changed", map[string]interface{}{
+		"user_id":  id.String(),
+		"role":     string(req.Role),
+		"admin_id": adminID(r),
+	})
+	respondJSON(w, http.StatusOK, target)
+}
+
+// TransferHoldings handles POST /admin/users/{id}/transfer, making another
+// active user the owner of every project and integration of a user, active
+// or deactivated, in one transaction.
+func (h *AdminHandler) TransferHoldings(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "user")
+	if !ok {
+		return
+	}
+
+	var req TransferHoldingsRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.ToUserID == uuid.Nil {
+		respondError(w, http.StatusBadRequest, "to_user_id is required")
+		return
+	}
+
+	if _, ok := h.loadUser(w, r, id); !ok {
+		return
+	}
+
+	transfer, err := h.handoverStore.Transfer(r.Context(), id, req.ToUserID)
+	if err != nil {
+		if errors.Is(err, handover.ErrSameUser) || errors.Is(err, handover.ErrRecipientNotFound) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to transfer holdings")
+		return
+	}
+	for _, projectID := range transfer.ProjectIDs {
+		h.owners.ForgetProject(r.Context(), projectID)
+	}
+
+	h.logger.Info(r.Context(), "user holdings transferred", map[string]interface{}{
+		"user_id":      id.String(),
+		"to_user_id":   req.ToUserID.String(),
+		"projects":     len(transfer.ProjectIDs),
+		"integrations": len(transfer.IntegrationIDs),
+		"admin_id":     adminID(r),
+	})
+	respondJSON(w, http.StatusOK, transfer)
+}
+
+// ListProjects handles GET /admin/projects, listing the projects of every
+// owner.
+func (h *AdminHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+
+	total, err := h.projectStore.CountAll(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to count projects")
+		return
+	}
+
+	projects, err := h.projectStore.ListAll(r.Context(), limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list projects")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, NewPaginatedResponse(projects, total, limit, offset))
+}
+
+// TransferProject handles POST /admin/projects/{id}/transfer, making an
+// active user the owner of a project.
+func (h *AdminHandler) TransferProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	var req TransferProjectRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.OwnerID == uuid.Nil {
+		respondError(w, http.StatusBadRequest, project.ErrInvalidOwner.Error())
+		return
+	}
+
+	owner, err := h.userStore.GetByID(r.Context(), req.OwnerID)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to get user")
+		return
+	}
+	if err != nil || owner.IsServiceAccount() {
+		respondError(w, http.StatusBadRequest, "new owner must be an active user")
+		return
+	}
+
+	if err := h.projectStore.Update(r.Context(), id, project.SetOwner(req.OwnerID)); err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to transfer project")
+		return
+	}
+	h.owners.ForgetProject(r.Context(), id)
+
+	proj, err := h.projectStore.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get transferred project")
+		return
+	}
+
+	h.logger.Info(r.Context(), "project transferred", map[string]interface{}{
+		"project_id": id.String(),
+		"owner_id":   req.OwnerID.String(),
+		"admin_id":   adminID(r),
+	})
+	respondJSON(w, http.StatusOK, proj)
+}
+
+// loadUser returns a user, active or not. Returns false if it is not found
+// (response already written).
+func (h *AdminHandler) loadUser(w http.ResponseWriter, r *http.Request, id uuid.UUID) (*user.User, bool) {
+	target, err := h.userStore.GetAnyByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusNotFound, "user not found")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get user")
+		return nil, false
+	}
+	return target, true
+}
+
+// checkNotLastAdmin refuses to take away the role of an admin when no other
+// active admin would be left. Returns false if refused (response already
+// written).
+func (h *AdminHandler) checkNotLastAdmin(w http.ResponseWriter, r *http.Request) bool {
+	admins, err := h.userStore.CountAdmins(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to count admins")
+		return false
+	}
+	if admins <= 1 {
+		respondError(w, http.StatusConflict, "cannot remove the last admin")
+		return false
+	}
+	return true
+}
+
+// logAction logs an admin action on a user.
+func (h *AdminHandler) logAction(r *http.Request, msg string, userID uuid.UUID) {
+	h.logger.Info(r.Context(), msg, map[string]interface{}{
+		"user_id":  userID.String(),
+		"admin_id": adminID(r),
+	})
+}
+
+// endUserAccess logs a deactivated user out everywhere and revokes their API
+// tokens. Failures are logged, not returned: the user is already
+// deactivated.
+func endUserAccess(r *http.Request, sessionManager *session.Manager, tokenStore apitoken.Store, userID uuid.UUID, log logger.Logger) {
+	sessionManager.DeleteByUser(userID)
+	if _, err := tokenStore.RevokeByUser(r.Context(), userID); err != nil {
+		log.Error(r.Context(), "failed to revoke api tokens of deactivated user", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+	}
+}
+
+func adminID(r *http.Request) string {
+	id, _ := GetUserID(r.Context())
+	return id.String()
+}
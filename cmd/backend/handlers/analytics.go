@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/analytics"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// AnalyticsHandler handles run analytics requests for procedures and
+// projects.
+type AnalyticsHandler struct {
+	store              analytics.Store
+	testProcedureStore testprocedure.Store
+	testProcedures     *TestProcedureHandler
+	logger             logger.Logger
+}
+
+// NewAnalyticsHandler creates a new analytics handler. Procedure ownership
+// is checked through the test procedure handler.
+func NewAnalyticsHandler(store analytics.Store, testProcedureStore testprocedure.Store, testProcedures *TestProcedureHandler, log logger.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		store:              store,
+		testProcedureStore: testProcedureStore,
+		testProcedures:     testProcedures,
+		logger:             log,
+	}
+}
+
+// windowStart parses the ?days= report window, defaulting to
+// analytics.DefaultWindowDays. Returns false if it is invalid (response
+// already written).
+func windowStart(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	days := analytics.DefaultWindowDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, analytics.ErrInvalidWindow.Error())
+			return time.Time{}, false
+		}
+		days = parsed
+	}
+
+	since, err := analytics.WindowStart(time.Now(), days)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+// GetProcedureAnalytics handles GET /procedures/{procedure_id}/analytics.
+// It reports on the runs of every version of the procedure completed in the
+// last ?days= days.
+func (h *AnalyticsHandler) GetProcedureAnalytics(w http.ResponseWriter, r *http.Request) {
+	procedureID, ok := parseUUIDOrRespond(w, r, "procedure_id", "test procedure")
+	if !ok {
+		return
+	}
+	if !h.testProcedures.checkProcedureOwnership(w, r, procedureID) {
+		return
+	}
+	since, ok := windowStart(w, r)
+	if !ok {
+		return
+	}
+
+	proc, err := h.testProcedureStore.GetByID(r.Context(), procedureID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return
+	}
+	rootID := proc.ID
+	if proc.ParentID != nil {
+		rootID = *proc.ParentID
+	}
+
+	report, err := h.store.ProcedureReport(r.Context(), rootID, since, analytics.DefaultTopSteps)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get procedure analytics")
+		return
+	}
+	if report.ProcedureName == "" {
+		report.ProcedureName = proc.Name
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// GetProjectAnalytics handles GET /projects/{id}/analytics. It reports on
+// the runs of every procedure in the project completed in the last ?days=
+// days, flakiest procedure first.
+func (h *AnalyticsHandler) GetProjectAnalytics(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	since, ok := windowStart(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.store.ProjectReport(r.Context(), projectID, since)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get project analytics")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
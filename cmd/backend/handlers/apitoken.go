@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
 )
@@ -28,6 +29,9 @@ type CreateTokenRequest struct {
 	Name          string `json:"name"`
 	Scope         string `json:"scope"`
 	ExpiresInHours int    `json:"expires_in_hours"`
+	// AllowedCIDRs optionally restricts the IP ranges the token can be
+	// used from.
+	AllowedCIDRs []string `json:"allowed_cidrs"`
 }
 
 // CreateTokenResponse includes the raw token (shown once).
@@ -36,6 +40,7 @@ type CreateTokenResponse struct {
 	Name      string `json:"name"`
 	Scope     string `json:"scope"`
 	Token     string `json:"token"`
+	AllowedCIDRs []string `json:"allowed_cidrs"`
 	ExpiresAt string `json:"expires_at"`
 	CreatedAt string `json:"created_at"`
 }
@@ -45,11 +50,20 @@ type TokenListItem struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
 	Scope     string `json:"scope"`
+	AllowedCIDRs []string `json:"allowed_cidrs"`
 	ExpiresAt string `json:"expires_at"`
 	IsActive  bool   `json:"is_active"`
+	LastUsedAt *string `json:"last_used_at"`
+	LastUsedIP string `json:"last_used_ip"`
 	CreatedAt string `json:"created_at"`
 }
 
+// SetAllowedCIDRsRequest replaces the IP allowlist of a token. An empty
+// list allows every address.
+type SetAllowedCIDRsRequest struct {
+	AllowedCIDRs []string `json:"allowed_cidrs"`
+}
+
 // TokenListResponse is the response for listing tokens.
 type TokenListResponse struct {
 	Tokens []TokenListItem `json:"tokens"`
@@ -64,6 +78,12 @@ func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.create(w, r, userID)
+}
+
+// create creates an API token of a user, which may be the user behind a
+// service account.
+func (h *APITokenHandler) create(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
 	var req CreateTokenRequest
 	if err := parseJSON(r, &req, h.logger); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid request body")
@@ -95,6 +115,12 @@ func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	allowedCIDRs, err := apitoken.ParseCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Generate token
 	rawToken, hash, err := apitoken.GenerateToken()
 	if err != nil {
@@ -110,6 +136,7 @@ func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Name:      req.Name,
 		TokenHash: hash,
 		Scope:     req.Scope,
+		AllowedCIDRs: allowedCIDRs,
 		ExpiresAt: time.Now().Add(expiryDuration),
 		IsActive:  true,
 	}
@@ -136,6 +163,7 @@ func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Name:      token.Name,
 		Scope:     token.Scope,
 		Token:     rawToken,
+		AllowedCIDRs: token.AllowedCIDRs,
 		ExpiresAt: token.ExpiresAt.Format(time.RFC3339),
 		CreatedAt: token.CreatedAt.Format(time.RFC3339),
 	})
@@ -149,6 +177,11 @@ func (h *APITokenHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.list(w, r, userID)
+}
+
+// list lists the active tokens of a user.
+func (h *APITokenHandler) list(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
 	tokens, err := h.tokenStore.ListByUser(r.Context(), userID)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to list tokens", map[string]interface{}{
@@ -164,10 +197,16 @@ func (h *APITokenHandler) List(w http.ResponseWriter, r *http.Request) {
 			ID:        t.ID.String(),
 			Name:      t.Name,
 			Scope:     t.Scope,
+			AllowedCIDRs: t.AllowedCIDRs,
 			ExpiresAt: t.ExpiresAt.Format(time.RFC3339),
 			IsActive:  t.IsActive,
+			LastUsedIP: t.LastUsedIP,
 			CreatedAt: t.CreatedAt.Format(time.RFC3339),
 		}
+		if t.LastUsedAt != nil {
+			lastUsedAt := t.LastUsedAt.Format(time.RFC3339)
+			items[i].LastUsedAt = &lastUsedAt
+		}
 	}
 
 	respondJSON(w, http.StatusOK, TokenListResponse{
@@ -184,48 +223,102 @@ func (h *APITokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.revoke(w, r, userID)
+}
+
+// revoke revokes the token in the URL, which must belong to userID.
+func (h *APITokenHandler) revoke(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
 	tokenID, ok := parseUUIDOrRespond(w, r, "token_id", "token")
 	if !ok {
 		return
 	}
 
-	// Verify ownership
-	token, err := h.tokenStore.GetByID(r.Context(), tokenID)
-	if err != nil {
+	if !h.checkTokenOwnership(w, r, userID, tokenID) {
+		return
+	}
+
+	if err := h.tokenStore.Revoke(r.Context(), tokenID); err != nil {
 		if errors.Is(err, apitoken.ErrTokenNotFound) {
 			respondError(w, http.StatusNotFound, "token not found")
 			return
 		}
-		h.logger.Error(r.Context(), "failed to get token for authorization", map[string]interface{}{
+		h.logger.Error(r.Context(), "failed to revoke token", map[string]interface{}{
 			"error":    err.Error(),
 			"token_id": tokenID.String(),
 		})
-		respondError(w, http.StatusInternalServerError, "failed to verify token ownership")
+		respondError(w, http.StatusInternalServerError, "failed to revoke token")
 		return
 	}
 
-	if token.UserID != userID {
-		h.logger.Warn(r.Context(), "unauthorized token revoke attempt", map[string]interface{}{
-			"user_id":  userID.String(),
-			"token_id": tokenID.String(),
-			"owner_id": token.UserID.String(),
-		})
-		respondError(w, http.StatusForbidden, "you don't have access to this token")
+	respondSuccess(w, "token revoked successfully")
+}
+
+// SetAllowedCIDRs handles PUT /tokens/{token_id}/allowed-cidrs, replacing
+// the IP allowlist of a token.
+func (h *APITokenHandler) SetAllowedCIDRs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
 		return
 	}
 
-	if err := h.tokenStore.Revoke(r.Context(), tokenID); err != nil {
+	tokenID, ok := parseUUIDOrRespond(w, r, "token_id", "token")
+	if !ok {
+		return
+	}
+
+	var req SetAllowedCIDRsRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	allowedCIDRs, err := apitoken.ParseCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.checkTokenOwnership(w, r, userID, tokenID) {
+		return
+	}
+
+	if err := h.tokenStore.SetAllowedCIDRs(r.Context(), tokenID, allowedCIDRs); err != nil {
 		if errors.Is(err, apitoken.ErrTokenNotFound) {
 			respondError(w, http.StatusNotFound, "token not found")
 			return
 		}
-		h.logger.Error(r.Context(), "failed to revoke token", map[string]interface{}{
+		respondError(w, http.StatusInternalServerError, "failed to update token allowlist")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, SetAllowedCIDRsRequest{AllowedCIDRs: allowedCIDRs})
+}
+
+// checkTokenOwnership verifies that a token belongs to the user. Returns
+// false if it does not (response already written).
+func (h *APITokenHandler) checkTokenOwnership(w http.ResponseWriter, r *http.Request, userID, tokenID uuid.UUID) bool {
+	token, err := h.tokenStore.GetByID(r.Context(), tokenID)
+	if err != nil {
+		if errors.Is(err, apitoken.ErrTokenNotFound) {
+			respondError(w, http.StatusNotFound, "token not found")
+			return false
+		}
+		h.logger.Error(r.Context(), "failed to get token for authorization", map[string]interface{}{
 			"error":    err.Error(),
 			"token_id": tokenID.String(),
 		})
-		respondError(w, http.StatusInternalServerError, "failed to revoke token")
-		return
+		respondError(w, http.StatusInternalServerError, "failed to verify token ownership")
+		return false
 	}
 
-	respondSuccess(w, "token revoked successfully")
+	if token.UserID != userID {
+		h.logger.Warn(r.Context(), "unauthorized token access attempt", map[string]interface{}{
+			"user_id":  userID.String(),
+			"token_id": tokenID.String(),
+			"owner_id": token.UserID.String(),
+		})
+		respondError(w, http.StatusForbidden, "you don't have access to this token")
+		return false
+	}
+	return true
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/securecookie"
@@ -18,16 +19,48 @@ type AuthHandler struct {
 	secureCookie   *securecookie.SecureCookie
 	cookieName     string
 	cookieSecure   bool
+	ssoPolicy      SSOPolicy
+	csrf           *CSRFProtection
 	logger         logger.Logger
 }
 
-// NewAuthHandler creates a new authentication handler.
+// SSOPolicy is the deployment's single sign-on policy.
+type SSOPolicy struct {
+	// Enforced disables registration and password login for everyone except
+	// admins, so users must sign in through the SAML identity provider.
+	Enforced bool
+	// Admins are the emails of users who may configure the identity
+	// provider. They keep password login when SSO is enforced, so a broken
+	// identity provider cannot lock everyone out.
+	Admins []string
+}
+
+// IsAdmin reports whether email belongs to an SSO admin.
+func (p SSOPolicy) IsAdmin(email string) bool {
+	for _, admin := range p.Admins {
+		if strings.EqualFold(admin, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordLoginAllowed reports whether the user with email may register or
+// log in with a password.
+func (p SSOPolicy) PasswordLoginAllowed(email string) bool {
+	return !p.Enforced || p.IsAdmin(email)
+}
+
+// NewAuthHandler creates a new authentication handler. New sessions get
+// the CSRF cookie of csrf, if not nil.
 func NewAuthHandler(
 	userStore user.Store,
 	sessionManager *session.Manager,
 	cookieSecret string,
 	cookieName string,
 	cookieSecure bool,
+	ssoPolicy SSOPolicy,
+	csrf *CSRFProtection,
 	log logger.Logger,
 ) *AuthHandler {
 	return &AuthHandler{
@@ -36,6 +69,8 @@ func NewAuthHandler(
 		secureCookie:   securecookie.New([]byte(cookieSecret), nil),
 		cookieName:     cookieName,
 		cookieSecure:   cookieSecure,
+		ssoPolicy:      ssoPolicy,
+		csrf:           csrf,
 		logger:         log,
 	}
 }
@@ -61,6 +96,11 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.ssoPolicy.PasswordLoginAllowed(req.Email) {
+		respondError(w, http.StatusForbidden, "registration is disabled; sign in with SSO")
+		return
+	}
+
 	// Create user
 	newUser := &user.User{
 		Email:    req.Email,
@@ -100,6 +140,8 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.sessionManager.Touch(sess.ID, clientIP(r), r.UserAgent())
+
 	// Set session cookie
 	h.setSessionCookie(w, sess.ID)
 
@@ -119,6 +161,11 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.ssoPolicy.PasswordLoginAllowed(req.Email) {
+		respondError(w, http.StatusForbidden, "password login is disabled; sign in with SSO")
+		return
+	}
+
 	// Get user by email
 	existingUser, err := h.userStore.GetByEmail(r.Context(), req.Email)
 	if err != nil {
@@ -154,6 +201,8 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.sessionManager.Touch(sess.ID, clientIP(r), r.UserAgent())
+
 	// Set session cookie
 	h.setSessionCookie(w, sess.ID)
 
@@ -242,6 +291,9 @@ func (h *AuthHandler) setSessionCookie(w http.ResponseWriter, sessionID uuid.UUI
 		Secure:   h.cookieSecure,
 		SameSite: http.SameSiteStrictMode,
 	})
+	if h.csrf != nil {
+		h.csrf.SetCookie(w, sessionID)
+	}
 }
 
 // clearSessionCookie clears the session cookie.
@@ -255,4 +307,7 @@ func (h *AuthHandler) clearSessionCookie(w http.ResponseWriter) {
 		Secure:   h.cookieSecure,
 		SameSite: http.SameSiteStrictMode,
 	})
+	if h.csrf != nil {
+		h.csrf.ClearCookie(w)
+	}
 }
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/authoring"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// ProcedureAuthoringHandler drafts test procedures from plain-English
+// descriptions of flows.
+type ProcedureAuthoringHandler struct {
+	projectStore       project.Store
+	endpointStore      endpoint.Store
+	testProcedureStore testprocedure.Store
+	author             authoring.Author
+	limits             testprocedure.ValidationLimits
+	meter              *llmusage.Meter
+	timeout            time.Duration
+	logger             logger.Logger
+}
+
+// NewProcedureAuthoringHandler creates a new procedure authoring handler.
+// Drafted procedures must fit limits, the limits of procedures sent for
+// script generation, and the model's usage is metered by meter. timeout
+// bounds drafting a procedure, for which the response's write deadline is
+// extended.
+func NewProcedureAuthoringHandler(
+	projectStore project.Store,
+	endpointStore endpoint.Store,
+	testProcedureStore testprocedure.Store,
+	author authoring.Author,
+	limits testprocedure.ValidationLimits,
+	meter *llmusage.Meter,
+	timeout time.Duration,
+	log logger.Logger,
+) *ProcedureAuthoringHandler {
+	return &ProcedureAuthoringHandler{
+		projectStore:       projectStore,
+		endpointStore:      endpointStore,
+		testProcedureStore: testProcedureStore,
+		author:             author,
+		limits:             limits,
+		meter:              meter,
+		timeout:            timeout,
+		logger:             log,
+	}
+}
+
+// GenerateProcedureRequest describes the flow to draft a procedure for.
+// Name replaces the name the model proposes, and EndpointID names an
+// endpoint whose URL tells the model where the application lives.
+type GenerateProcedureRequest struct {
+	Description string `json:"description"`
+	Name        string `json:"name,omitempty"`
+	EndpointID  string `json:"endpoint_id,omitempty"`
+}
+
+// Generate handles POST /projects/{project_id}/procedures/generate. The
+// drafted procedure is created flagged for review, and returned like a
+// procedure created by hand.
+func (h *ProcedureAuthoringHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return
+	}
+
+	proj, err := h.projectStore.GetByID(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get project")
+		return
+	}
+	if !canAccessProject(r, proj.ID, proj.OwnerID) {
+		respondError(w, http.StatusForbidden, "you don't have access to this project")
+		return
+	}
+
+	var req GenerateProcedureRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	authorReq := authoring.Request{
+		Description: req.Description,
+		Name:        req.Name,
+	}
+	if err := authorReq.Validate(); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.EndpointID != "" {
+		baseURL, ok := h.endpointURL(w, r, req.EndpointID, userID)
+		if !ok {
+			return
+		}
+		authorReq.BaseURL = baseURL
+	}
+
+	if !checkLLMBudget(w, r, h.meter, projectID, h.logger) {
+		return
+	}
+
+	// The model is slower than the server's write timeout allows for
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(h.timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Warn(r.Context(), "failed to extend write deadline", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	ctx, tracker := llmusage.Track(r.Context())
+	draft, err := h.author.Draft(ctx, authorReq)
+	h.meter.Record(r.Context(), projectID, userID, llmusage.FeatureProcedureAuthoring, tracker.Usage())
+	if err != nil {
+		switch {
+		case errors.Is(err, authoring.ErrDescriptionRequired), errors.Is(err, authoring.ErrDescriptionTooLong):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, authoring.ErrNoProcedure):
+			respondError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, resilience.ErrCircuitOpen):
+			respondError(w, http.StatusServiceUnavailable, "procedure generation temporarily unavailable")
+		default:
+			h.logger.Error(r.Context(), "failed to generate test procedure", map[string]interface{}{
+				"error":      err.Error(),
+				"project_id": projectID.String(),
+			})
+			respondError(w, http.StatusBadGateway, "failed to generate test procedure")
+		}
+		return
+	}
+
+	// The drafted procedure must pass the same checks as one written by hand
+	tp, err := draft.Procedure(projectID, userID, h.limits)
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.testProcedureStore.Create(r.Context(), tp); err != nil {
+		h.logger.Error(r.Context(), "failed to create generated test procedure", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create test procedure")
+		return
+	}
+
+	h.logger.Info(r.Context(), "test procedure generated", map[string]interface{}{
+		"test_procedure_id": tp.ID.String(),
+		"project_id":        projectID.String(),
+		"steps":             len(tp.Steps),
+	})
+	respondJSON(w, http.StatusCreated, tp)
+}
+
+// endpointURL returns the URL of an endpoint the user owns. Returns false
+// if it cannot be used (response already written).
+func (h *ProcedureAuthoringHandler) endpointURL(w http.ResponseWriter, r *http.Request, endpointIDStr string, userID uuid.UUID) (string, bool) {
+	endpointID, err := uuid.Parse(endpointIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "endpoint_id must be a valid UUID")
+		return "", false
+	}
+
+	ep, err := h.endpointStore.GetByID(r.Context(), endpointID)
+	if err != nil {
+		if errors.Is(err, endpoint.ErrEndpointNotFound) {
+			respondError(w, http.StatusNotFound, "endpoint not found")
+			return "", false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get endpoint")
+		return "", false
+	}
+	if ep.CreatedBy != userID {
+		respondError(w, http.StatusNotFound, "endpoint not found")
+		return "", false
+	}
+	return ep.URL, true
+}
@@ -7,16 +7,52 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
 	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
 )
 
 const (
 	// ProjectKey is the context key for project.
 	ProjectKey ContextKey = "project"
+
+	// RunOwnerKey is the context key for the owner of the test run or
+	// procedure a request is for.
+	RunOwnerKey ContextKey = "run_owner"
 )
 
-// ProjectAuthorizationMiddleware validates that the current user owns the project.
+// canAccessProject reports whether the caller may access a project owned
+// by ownerID: the owner can, and so can a service account granted the
+// project, though a read-only grant only allows GET and HEAD requests.
+// Guests never can, even of projects they owned before becoming guests.
+func canAccessProject(r *http.Request, projectID, ownerID uuid.UUID) bool {
+	if IsGuest(r.Context()) {
+		return false
+	}
+	if userID, ok := GetUserID(r.Context()); ok && userID == ownerID {
+		return true
+	}
+	sa, ok := GetServiceAccount(r.Context())
+	if !ok {
+		return false
+	}
+	scope, ok := sa.Scope(projectID)
+	if !ok {
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	}
+	return scope == apitoken.ScopeReadWrite
+}
+
+// ProjectAuthorizationMiddleware validates that the current user owns the
+// project. Project settings stay with the owner, so service accounts are
+// never let through.
 type ProjectAuthorizationMiddleware struct {
 	projectStore project.Store
 	logger       logger.Logger
@@ -94,3 +130,199 @@ func GetProject(ctx context.Context) (*project.Project, bool) {
 	proj, ok := ctx.Value(ProjectKey).(*project.Project)
 	return proj, ok
 }
+
+// RunAuthorizationMiddleware validates that the caller can access the
+// project of the test run in the URL or, on routes for a procedure's runs
+// without one, of the procedure. Guests instead need the run to be assigned
+// to them. The owner is resolved once per request and added to the context
+// for handlers.
+type RunAuthorizationMiddleware struct {
+	owners       *ownership.Resolver
+	testRunStore testrun.Store
+	logger       logger.Logger
+}
+
+// NewRunAuthorizationMiddleware creates a new test run authorization
+// middleware. Runs are looked up in testRunStore for their assignee.
+func NewRunAuthorizationMiddleware(owners *ownership.Resolver, testRunStore testrun.Store, log logger.Logger) *RunAuthorizationMiddleware {
+	return &RunAuthorizationMiddleware{
+		owners:       owners,
+		testRunStore: testRunStore,
+		logger:       log,
+	}
+}
+
+// Handler wraps an HTTP handler with test run authorization.
+func (m *RunAuthorizationMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var owner ownership.Owner
+		vars := mux.Vars(r)
+		switch {
+		case vars["run_id"] != "":
+			runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+			if !ok {
+				return
+			}
+			if IsGuest(r.Context()) && !m.checkRunAssignee(w, r, runID) {
+				return
+			}
+			if owner, ok = m.runOwner(w, r, runID); !ok {
+				return
+			}
+		case vars["procedure_id"] != "":
+			procedureID, ok := parseUUIDOrRespond(w, r, "procedure_id", "test procedure")
+			if !ok {
+				return
+			}
+			if owner, ok = m.checkProcedureOwner(w, r, procedureID); !ok {
+				return
+			}
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), RunOwnerKey, owner)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// runOwner returns the owner of the run, checking that the caller can
+// access its project unless they are a guest, whose access was already
+// checked by checkRunAssignee. Returns false if the check fails (response
+// already written).
+func (m *RunAuthorizationMiddleware) runOwner(w http.ResponseWriter, r *http.Request, runID uuid.UUID) (ownership.Owner, bool) {
+	if !IsGuest(r.Context()) {
+		return checkRunOwner(w, r, m.owners, runID)
+	}
+	owner, err := m.owners.RunOwner(r.Context(), runID)
+	if err != nil {
+		m.logger.Error(r.Context(), "failed to resolve test run owner for authorization", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": runID,
+		})
+		respondError(w, http.StatusInternalServerError, "authorization check failed")
+		return ownership.Owner{}, false
+	}
+	return owner, true
+}
+
+// checkRunAssignee verifies that the run is assigned to the caller. Returns
+// false if the check fails (response already written).
+func (m *RunAuthorizationMiddleware) checkRunAssignee(w http.ResponseWriter, r *http.Request, runID uuid.UUID) bool {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return false
+	}
+
+	tr, err := m.testRunStore.GetByID(r.Context(), runID)
+	if err != nil {
+		if errors.Is(err, testrun.ErrTestRunNotFound) {
+			respondError(w, http.StatusNotFound, "test run not found")
+			return false
+		}
+		m.logger.Error(r.Context(), "failed to get test run for authorization", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": runID,
+		})
+		respondError(w, http.StatusInternalServerError, "authorization check failed")
+		return false
+	}
+	if tr.AssignedTo == nil || *tr.AssignedTo != userID {
+		respondError(w, http.StatusForbidden, "access denied")
+		return false
+	}
+	return true
+}
+
+// checkProcedureOwner verifies that the caller can access the procedure's
+// project and returns the owner. Returns false if the check fails (response
+// already written).
+func (m *RunAuthorizationMiddleware) checkProcedureOwner(w http.ResponseWriter, r *http.Request, procedureID uuid.UUID) (ownership.Owner, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return ownership.Owner{}, false
+	}
+
+	owner, err := m.owners.ProcedureOwner(r.Context(), procedureID)
+	if err != nil {
+		switch {
+		case errors.Is(err, testprocedure.ErrTestProcedureNotFound):
+			respondError(w, http.StatusNotFound, "test procedure not found")
+		case errors.Is(err, project.ErrProjectNotFound):
+			respondError(w, http.StatusNotFound, "project not found")
+		default:
+			m.logger.Error(r.Context(), "failed to resolve test procedure owner for authorization", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": procedureID,
+			})
+			respondError(w, http.StatusInternalServerError, "authorization check failed")
+		}
+		return ownership.Owner{}, false
+	}
+
+	if !canAccessProject(r, owner.ProjectID, owner.UserID) {
+		m.logger.Warn(r.Context(), "unauthorized procedure access attempt", map[string]interface{}{
+			"user_id":           userID,
+			"project_id":        owner.ProjectID,
+			"test_procedure_id": procedureID,
+		})
+		respondError(w, http.StatusForbidden, "you don't have access to this test procedure")
+		return ownership.Owner{}, false
+	}
+	return owner, true
+}
+
+// Participants wraps an HTTP handler with authorization for a test run's
+// discussion, which is also open to the user who executed the run and the
+// user it is assigned to, guests included, without access to its project.
+func (m *RunAuthorizationMiddleware) Participants(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+		if !ok {
+			return
+		}
+		userID, ok := GetUserID(r.Context())
+		if !ok {
+			respondError(w, http.StatusUnauthorized, "user not authenticated")
+			return
+		}
+
+		tr, err := m.testRunStore.GetByID(r.Context(), runID)
+		if err != nil {
+			if errors.Is(err, testrun.ErrTestRunNotFound) {
+				respondError(w, http.StatusNotFound, "test run not found")
+				return
+			}
+			m.logger.Error(r.Context(), "failed to get test run for authorization", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": runID,
+			})
+			respondError(w, http.StatusInternalServerError, "authorization check failed")
+			return
+		}
+		owner, err := m.owners.RunOwner(r.Context(), runID)
+		if err != nil {
+			respondRunOwnerError(w, err)
+			return
+		}
+
+		isParticipant := tr.ExecutedBy == userID || (tr.AssignedTo != nil && *tr.AssignedTo == userID)
+		if !isParticipant && !canAccessProject(r, owner.ProjectID, owner.UserID) {
+			respondError(w, http.StatusForbidden, "access denied")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), RunOwnerKey, owner)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetRunOwner extracts the owner of the request's test run or procedure
+// from the request context.
+func GetRunOwner(ctx context.Context) (ownership.Owner, bool) {
+	owner, ok := ctx.Value(RunOwnerKey).(ownership.Owner)
+	return owner, ok
+}
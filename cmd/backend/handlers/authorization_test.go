@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
+	"github.com/hairizuanbinnoorazman/ui-automation/procedureshare"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/serviceaccount"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+func TestCanAccessProject(t *testing.T) {
+	t.Parallel()
+
+	ownerID := uuid.New()
+	readOnlyProject := uuid.New()
+	readWriteProject := uuid.New()
+	sa := &serviceaccount.ServiceAccount{
+		ID: uuid.New(),
+		Grants: []serviceaccount.Grant{
+			{ProjectID: readOnlyProject, Scope: apitoken.ScopeReadOnly},
+			{ProjectID: readWriteProject, Scope: apitoken.ScopeReadWrite},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		userID    uuid.UUID
+		sa        *serviceaccount.ServiceAccount
+		method    string
+		projectID uuid.UUID
+		want      bool
+	}{
+		{
+			name:      "owner",
+			userID:    ownerID,
+			method:    http.MethodDelete,
+			projectID: uuid.New(),
+			want:      true,
+		},
+		{
+			name:      "other user",
+			userID:    uuid.New(),
+			method:    http.MethodGet,
+			projectID: uuid.New(),
+			want:      false,
+		},
+		{
+			name:      "service account reads read_only project",
+			userID:    sa.ID,
+			sa:        sa,
+			method:    http.MethodGet,
+			projectID: readOnlyProject,
+			want:      true,
+		},
+		{
+			name:      "service account writes read_only project",
+			userID:    sa.ID,
+			sa:        sa,
+			method:    http.MethodPost,
+			projectID: readOnlyProject,
+			want:      false,
+		},
+		{
+			name:      "service account writes read_write project",
+			userID:    sa.ID,
+			sa:        sa,
+			method:    http.MethodPut,
+			projectID: readWriteProject,
+			want:      true,
+		},
+		{
+			name:      "service account without grant",
+			userID:    sa.ID,
+			sa:        sa,
+			method:    http.MethodGet,
+			projectID: uuid.New(),
+			want:      false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tc.method, "/test", nil)
+			ctx := context.WithValue(req.Context(), UserIDKey, tc.userID)
+			if tc.sa != nil {
+				ctx = context.WithValue(ctx, ServiceAccountKey, tc.sa)
+			}
+			req = req.WithContext(ctx)
+
+			if got := canAccessProject(req, tc.projectID, ownerID); got != tc.want {
+				t.Errorf("canAccessProject() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRejectServiceAccounts(t *testing.T) {
+	t.Parallel()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/projects", nil)
+	w := httptest.NewRecorder()
+	RejectServiceAccounts(next).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("user: status code = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	ctx := context.WithValue(req.Context(), ServiceAccountKey, &serviceaccount.ServiceAccount{ID: uuid.New()})
+	w = httptest.NewRecorder()
+	RejectServiceAccounts(next).ServeHTTP(w, req.WithContext(ctx))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("service account: status code = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestRunAuthorizationMiddleware(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &project.Project{}, &testprocedure.TestProcedure{}, &testrun.TestRun{})
+	log := logger.NewTestLogger()
+	ctx := context.Background()
+	projectStore := project.NewMySQLStore(db, log)
+	procedureStore := testprocedure.NewMySQLStore(db, log)
+	runStore := testrun.NewMySQLStore(db, log)
+	resolver := ownership.NewResolver(runStore, procedureStore, projectStore, ownership.NewMemoryCache(time.Minute, 0))
+
+	ownerID := uuid.New()
+	proj := &project.Project{Name: "Checkout", OwnerID: ownerID, IsActive: true}
+	if err := projectStore.Create(ctx, proj); err != nil {
+		t.Fatal(err)
+	}
+	tp := &testprocedure.TestProcedure{ProjectID: proj.ID, Name: "Pay", CreatedBy: ownerID}
+	if err := procedureStore.Create(ctx, tp); err != nil {
+		t.Fatal(err)
+	}
+	tr := &testrun.TestRun{TestProcedureID: tp.ID, ExecutedBy: ownerID}
+	if err := runStore.Create(ctx, tr); err != nil {
+		t.Fatal(err)
+	}
+	testerID := uuid.New()
+	assigned := &testrun.TestRun{TestProcedureID: tp.ID, ExecutedBy: ownerID, AssignedTo: &testerID}
+	if err := runStore.Create(ctx, assigned); err != nil {
+		t.Fatal(err)
+	}
+
+	// Routes are registered as in serve: run routes of every handler on a
+	// subrouter, and the run's discussion on one open to its participants
+	var gotOwner ownership.Owner
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotOwner, _ = GetRunOwner(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	auth := NewRunAuthorizationMiddleware(resolver, runStore, log)
+	router := mux.NewRouter()
+	router.Handle("/procedures/{procedure_id}/runs", auth.Handler(ok)).Methods("GET")
+	runRouter := router.PathPrefix("/runs/{run_id}").Subrouter()
+	runRouter.Use(auth.Handler)
+	runRouter.Handle("/assets/{asset_id}", ok).Methods("GET")
+	runRouter.Handle("/issues", ok).Methods("GET")
+	runRouter.Handle("/labels", ok).Methods("GET")
+	discussionRouter := router.PathPrefix("/runs/{run_id}/comments").Subrouter()
+	discussionRouter.Use(auth.Participants)
+	discussionRouter.Handle("", ok).Methods("GET")
+
+	send := func(userID uuid.UUID, path string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		path   string
+		want   int
+	}{
+		{"owner reads a run's asset", ownerID, "/runs/" + tr.ID.String() + "/assets/" + uuid.NewString(), http.StatusOK},
+		{"other user reads a run's asset", uuid.New(), "/runs/" + tr.ID.String() + "/assets/" + uuid.NewString(), http.StatusForbidden},
+		{"missing run", ownerID, "/runs/" + uuid.NewString() + "/assets/" + uuid.NewString(), http.StatusNotFound},
+		{"invalid run ID", ownerID, "/runs/nope/assets/" + uuid.NewString(), http.StatusBadRequest},
+		{"owner lists a procedure's runs", ownerID, "/procedures/" + tp.ID.String() + "/runs", http.StatusOK},
+		{"other user lists a procedure's runs", uuid.New(), "/procedures/" + tp.ID.String() + "/runs", http.StatusForbidden},
+		{"other user lists a run's issues", uuid.New(), "/runs/" + tr.ID.String() + "/issues", http.StatusForbidden},
+		{"other user lists a run's labels", uuid.New(), "/runs/" + tr.ID.String() + "/labels", http.StatusForbidden},
+		{"owner lists a run's labels", ownerID, "/runs/" + tr.ID.String() + "/labels", http.StatusOK},
+		{"other user reads a run's discussion", uuid.New(), "/runs/" + tr.ID.String() + "/comments", http.StatusForbidden},
+		{"owner reads a run's discussion", ownerID, "/runs/" + tr.ID.String() + "/comments", http.StatusOK},
+		{"assignee reads a run's discussion", testerID, "/runs/" + assigned.ID.String() + "/comments", http.StatusOK},
+		{"assignee lists a run's labels", testerID, "/runs/" + assigned.ID.String() + "/labels", http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := send(tc.userID, tc.path); got != tc.want {
+				t.Errorf("status code = %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	want := ownership.Owner{ProjectID: proj.ID, UserID: ownerID}
+	send(ownerID, "/runs/"+tr.ID.String()+"/assets/"+uuid.NewString())
+	if gotOwner != want {
+		t.Errorf("owner in context = %+v, want %+v", gotOwner, want)
+	}
+}
+
+func TestCheckProcedureOwnership_Shares(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &project.Project{}, &testprocedure.TestProcedure{}, &testrun.TestRun{}, &procedureshare.Share{})
+	log := logger.NewTestLogger()
+	ctx := context.Background()
+	projectStore := project.NewMySQLStore(db, log)
+	procedureStore := testprocedure.NewMySQLStore(db, log)
+	shareStore := procedureshare.NewMySQLStore(db, log)
+	resolver := ownership.NewResolver(testrun.NewMySQLStore(db, log), procedureStore, projectStore, ownership.NewMemoryCache(time.Minute, 0))
+	h := NewTestProcedureHandler(procedureStore, nil, nil, nil, resolver, shareStore, nil, nil, nil, nil, nil, log)
+
+	ownerID := uuid.New()
+	proj := &project.Project{Name: "Checkout", OwnerID: ownerID, IsActive: true}
+	if err := projectStore.Create(ctx, proj); err != nil {
+		t.Fatal(err)
+	}
+	tp := &testprocedure.TestProcedure{ProjectID: proj.ID, Name: "Pay", CreatedBy: ownerID}
+	if err := procedureStore.Create(ctx, tp); err != nil {
+		t.Fatal(err)
+	}
+	version, err := procedureStore.CreateVersion(ctx, tp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, editor := uuid.New(), uuid.New()
+	for userID, access := range map[uuid.UUID]procedureshare.Access{reader: procedureshare.AccessReadOnly, editor: procedureshare.AccessReadWrite} {
+		if err := shareStore.Save(ctx, &procedureshare.Share{ProcedureID: tp.ID, UserID: userID, Access: access, SharedBy: ownerID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send := func(userID uuid.UUID, method string, procedureID uuid.UUID, strict bool) int {
+		req := httptest.NewRequest(method, "/", nil)
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+		w := httptest.NewRecorder()
+		check := h.checkProcedureOwnership
+		if strict {
+			check = h.checkProcedureOwner
+		}
+		if check(w, req, procedureID) {
+			w.WriteHeader(http.StatusOK)
+		}
+		return w.Code
+	}
+
+	tests := []struct {
+		name        string
+		userID      uuid.UUID
+		method      string
+		procedureID uuid.UUID
+		strict      bool
+		want        int
+	}{
+		{"owner edits", ownerID, http.MethodPut, tp.ID, false, http.StatusOK},
+		{"owner manages shares", ownerID, http.MethodPut, tp.ID, true, http.StatusOK},
+		{"read-only share reads", reader, http.MethodGet, tp.ID, false, http.StatusOK},
+		{"read-only share reads a later version", reader, http.MethodGet, version.ID, false, http.StatusOK},
+		{"read-only share edits", reader, http.MethodPut, tp.ID, false, http.StatusForbidden},
+		{"read-write share edits a later version", editor, http.MethodPut, version.ID, false, http.StatusOK},
+		{"read-write share manages shares", editor, http.MethodPut, tp.ID, true, http.StatusForbidden},
+		{"read-write share reads owner-only data", editor, http.MethodGet, tp.ID, true, http.StatusForbidden},
+		{"no share", uuid.New(), http.MethodGet, tp.ID, false, http.StatusForbidden},
+		{"missing procedure", reader, http.MethodGet, uuid.New(), false, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := send(tc.userID, tc.method, tc.procedureID, tc.strict); got != tc.want {
+				t.Errorf("status code = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGuestMiddleware(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &user.User{}, &project.Project{}, &testprocedure.TestProcedure{}, &testrun.TestRun{})
+	log := logger.NewTestLogger()
+	ctx := context.Background()
+	userStore := user.NewMySQLStore(db, log)
+	projectStore := project.NewMySQLStore(db, log)
+	procedureStore := testprocedure.NewMySQLStore(db, log)
+	runStore := testrun.NewMySQLStore(db, log)
+	resolver := ownership.NewResolver(runStore, procedureStore, projectStore, ownership.NewMemoryCache(time.Minute, 0))
+
+	owner := &user.User{Email: "owner@example.com", Username: "owner", PasswordHash: "x", IsActive: true}
+	guest := &user.User{Email: "tester@example.com", Username: "tester", PasswordHash: "x", IsActive: true, Role: user.RoleGuest}
+	for _, u := range []*user.User{owner, guest} {
+		if err := userStore.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	proj := &project.Project{Name: "Checkout", OwnerID: owner.ID, IsActive: true}
+	if err := projectStore.Create(ctx, proj); err != nil {
+		t.Fatal(err)
+	}
+	tp := &testprocedure.TestProcedure{ProjectID: proj.ID, Name: "Pay", CreatedBy: owner.ID}
+	if err := procedureStore.Create(ctx, tp); err != nil {
+		t.Fatal(err)
+	}
+	assigned := &testrun.TestRun{TestProcedureID: tp.ID, ExecutedBy: owner.ID, AssignedTo: &guest.ID}
+	unassigned := &testrun.TestRun{TestProcedureID: tp.ID, ExecutedBy: owner.ID}
+	for _, tr := range []*testrun.TestRun{assigned, unassigned} {
+		if err := runStore.Create(ctx, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Routes are registered as in serve, with the guest check on the API
+	// router and run authorization on the run subrouter
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router := mux.NewRouter()
+	apiRouter := router.PathPrefix("/api/v1").Subrouter()
+	apiRouter.Use(NewGuestMiddleware(userStore, log).Handler)
+	apiRouter.Handle("/projects", ok).Methods("GET")
+	apiRouter.Handle("/runs/queue", ok).Methods("GET")
+	runRouter := apiRouter.PathPrefix("/runs/{run_id}").Subrouter()
+	runRouter.Use(NewRunAuthorizationMiddleware(resolver, runStore, log).Handler)
+	runRouter.Handle("", ok).Methods("GET")
+	runRouter.Handle("/guide", ok).Methods("GET")
+	runRouter.Handle("/start", ok).Methods("POST")
+
+	send := func(userID uuid.UUID, method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		method string
+		path   string
+		want   int
+	}{
+		{"guest lists their queue", guest.ID, http.MethodGet, "/api/v1/runs/queue", http.StatusOK},
+		{"guest starts an assigned run", guest.ID, http.MethodPost, "/api/v1/runs/" + assigned.ID.String() + "/start", http.StatusOK},
+		{"guest reads an unassigned run", guest.ID, http.MethodGet, "/api/v1/runs/" + unassigned.ID.String(), http.StatusForbidden},
+		{"guest generates a guide for an assigned run", guest.ID, http.MethodGet, "/api/v1/runs/" + assigned.ID.String() + "/guide", http.StatusForbidden},
+		{"guest lists projects", guest.ID, http.MethodGet, "/api/v1/projects", http.StatusForbidden},
+		{"owner lists projects", owner.ID, http.MethodGet, "/api/v1/projects", http.StatusOK},
+		{"owner generates a guide for a run", owner.ID, http.MethodGet, "/api/v1/runs/" + assigned.ID.String() + "/guide", http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := send(tc.userID, tc.method, tc.path); got != tc.want {
+				t.Errorf("status code = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
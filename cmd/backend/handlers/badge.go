@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/analytics"
+	"github.com/hairizuanbinnoorazman/ui-automation/badge"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// BadgeHandler handles status badges of projects and procedures. Badge
+// images are public, authorized by a token signed for their project or
+// procedure; owners get the signed badge URLs from the protected routes.
+type BadgeHandler struct {
+	analyticsStore     analytics.Store
+	testProcedureStore testprocedure.Store
+	testProcedures     *TestProcedureHandler
+	signer             *badge.Signer
+	linkBaseURL        string
+	logger             logger.Logger
+}
+
+// NewBadgeHandler creates a new badge handler. Badges are computed from run
+// analytics, and badge URLs are built under linkBaseURL, the external URL
+// of the server; without one they are relative.
+func NewBadgeHandler(analyticsStore analytics.Store, testProcedureStore testprocedure.Store, testProcedures *TestProcedureHandler, signer *badge.Signer, linkBaseURL string, log logger.Logger) *BadgeHandler {
+	return &BadgeHandler{
+		analyticsStore:     analyticsStore,
+		testProcedureStore: testProcedureStore,
+		testProcedures:     testProcedures,
+		signer:             signer,
+		linkBaseURL:        linkBaseURL,
+		logger:             log,
+	}
+}
+
+// BadgeLinksResponse lists the signed URLs of the badges of a project or
+// procedure, with Markdown embedding the status badge.
+type BadgeLinksResponse struct {
+	StatusURL   string `json:"status_url"`
+	PassRateURL string `json:"pass_rate_url"`
+	Markdown    string `json:"markdown"`
+}
+
+func (h *BadgeHandler) badgeLinks(path, subject string) BadgeLinksResponse {
+	base := h.linkBaseURL + path + "?token=" + url.QueryEscape(h.signer.Sign(subject))
+	return BadgeLinksResponse{
+		StatusURL:   base,
+		PassRateURL: base + "&metric=" + string(badge.MetricPassRate),
+		Markdown:    fmt.Sprintf("![tests](%s)", base),
+	}
+}
+
+// GetProjectBadgeLinks handles GET /projects/{id}/badge.
+func (h *BadgeHandler) GetProjectBadgeLinks(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	path := "/api/v1/projects/" + projectID.String() + "/badge.svg"
+	respondJSON(w, http.StatusOK, h.badgeLinks(path, badge.ProjectSubject(projectID)))
+}
+
+// GetProcedureBadgeLinks handles GET /procedures/{procedure_id}/badge.
+func (h *BadgeHandler) GetProcedureBadgeLinks(w http.ResponseWriter, r *http.Request) {
+	procedureID, ok := parseUUIDOrRespond(w, r, "procedure_id", "test procedure")
+	if !ok {
+		return
+	}
+	if !h.testProcedures.checkProcedureOwnership(w, r, procedureID) {
+		return
+	}
+
+	path := "/api/v1/procedures/" + procedureID.String() + "/badge.svg"
+	respondJSON(w, http.StatusOK, h.badgeLinks(path, badge.ProcedureSubject(procedureID)))
+}
+
+// badgeRequest checks the token of a badge request for subject and parses
+// its ?metric= and ?label=. Returns false if it is invalid (response already
+// written).
+func (h *BadgeHandler) badgeRequest(w http.ResponseWriter, r *http.Request, subject string) (badge.Metric, string, bool) {
+	if !h.signer.Verify(subject, r.URL.Query().Get("token")) {
+		respondError(w, http.StatusForbidden, "invalid badge token")
+		return "", "", false
+	}
+
+	metric, err := badge.ParseMetric(r.URL.Query().Get("metric"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return "", "", false
+	}
+	label, err := badge.ParseLabel(r.URL.Query().Get("label"), metric)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return "", "", false
+	}
+	return metric, label, true
+}
+
+// respondBadge writes a badge image. Badges are not cached, so READMEs show
+// the latest status.
+func respondBadge(w http.ResponseWriter, b badge.Badge) {
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-cache, max-age=0")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.SVG())
+}
+
+// ProjectBadge handles GET /projects/{id}/badge.svg (public, with a signed
+// ?token=). The status badge fails if the latest run of any procedure run
+// in the last ?days= days failed; the pass rate badge covers the same runs.
+func (h *BadgeHandler) ProjectBadge(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	metric, label, ok := h.badgeRequest(w, r, badge.ProjectSubject(projectID))
+	if !ok {
+		return
+	}
+	since, ok := windowStart(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.analyticsStore.ProjectReport(r.Context(), projectID, since)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get project analytics")
+		return
+	}
+
+	if metric == badge.MetricPassRate {
+		respondBadge(w, badge.PassRate(label, report.PassRate))
+		return
+	}
+	statuses := make([]testrun.Status, len(report.Procedures))
+	for i, proc := range report.Procedures {
+		statuses[i] = proc.LastStatus
+	}
+	respondBadge(w, badge.Status(label, badge.CombinedStatus(statuses)))
+}
+
+// ProcedureBadge handles GET /procedures/{procedure_id}/badge.svg (public,
+// with a signed ?token=). The status badge shows the latest run of any
+// version of the procedure; the pass rate badge covers the last ?days=
+// days.
+func (h *BadgeHandler) ProcedureBadge(w http.ResponseWriter, r *http.Request) {
+	procedureID, ok := parseUUIDOrRespond(w, r, "procedure_id", "test procedure")
+	if !ok {
+		return
+	}
+	metric, label, ok := h.badgeRequest(w, r, badge.ProcedureSubject(procedureID))
+	if !ok {
+		return
+	}
+	since, ok := windowStart(w, r)
+	if !ok {
+		return
+	}
+
+	rootID, ok := h.rootProcedureID(w, r, procedureID)
+	if !ok {
+		return
+	}
+	report, err := h.analyticsStore.ProcedureReport(r.Context(), rootID, since, 0)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get procedure analytics")
+		return
+	}
+
+	if metric == badge.MetricPassRate {
+		respondBadge(w, badge.PassRate(label, report.PassRate))
+		return
+	}
+	respondBadge(w, badge.Status(label, report.LastStatus))
+}
+
+// rootProcedureID returns the first version of a procedure, which its
+// analytics are keyed by. Returns false if it is not found (response
+// already written).
+func (h *BadgeHandler) rootProcedureID(w http.ResponseWriter, r *http.Request, procedureID uuid.UUID) (uuid.UUID, bool) {
+	proc, err := h.testProcedureStore.GetByID(r.Context(), procedureID)
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+			respondError(w, http.StatusNotFound, "test procedure not found")
+			return uuid.Nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return uuid.Nil, false
+	}
+	if proc.ParentID != nil {
+		return *proc.ParentID, true
+	}
+	return proc.ID, true
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/cipipeline"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// CIPipelineHandler handles requests for the external CI pipelines of a
+// project and the builds its test runs trigger. Pipeline routes are
+// registered on the project router, whose authorization middleware has
+// already verified ownership; build routes are on the run router, whose
+// middleware checks the run's owner.
+type CIPipelineHandler struct {
+	store              cipipeline.Store
+	buildStore         cipipeline.BuildStore
+	testRunStore       testrun.Store
+	testProcedureStore testprocedure.Store
+	newClient          func(pipeline *cipipeline.Pipeline) (cipipeline.Client, error)
+	logger             logger.Logger
+}
+
+// NewCIPipelineHandler creates a new CI pipeline handler.
+func NewCIPipelineHandler(
+	store cipipeline.Store,
+	buildStore cipipeline.BuildStore,
+	testRunStore testrun.Store,
+	testProcedureStore testprocedure.Store,
+	log logger.Logger,
+) *CIPipelineHandler {
+	return &CIPipelineHandler{
+		store:              store,
+		buildStore:         buildStore,
+		testRunStore:       testRunStore,
+		testProcedureStore: testProcedureStore,
+		newClient:          cipipeline.NewClient,
+		logger:             log,
+	}
+}
+
+// SaveCIPipelineRequest represents a CI pipeline create or update request.
+// On update, an empty token keeps the token already saved.
+type SaveCIPipelineRequest struct {
+	Name       string              `json:"name"`
+	Provider   cipipeline.Provider `json:"provider"`
+	BaseURL    string              `json:"base_url"`
+	Repository string              `json:"repository"`
+	Workflow   string              `json:"workflow"`
+	Ref        string              `json:"ref"`
+	Job        string              `json:"job"`
+	Username   string              `json:"username"`
+	Token      string              `json:"token"`
+}
+
+// pipeline returns the pipeline described by the request.
+func (req *SaveCIPipelineRequest) pipeline() *cipipeline.Pipeline {
+	return &cipipeline.Pipeline{
+		Name:       req.Name,
+		Provider:   req.Provider,
+		BaseURL:    req.BaseURL,
+		Repository: req.Repository,
+		Workflow:   req.Workflow,
+		Ref:        req.Ref,
+		Job:        req.Job,
+		Username:   req.Username,
+		Token:      req.Token,
+	}
+}
+
+// TriggerCIBuildRequest represents a request to trigger a pipeline for a
+// test run.
+type TriggerCIBuildRequest struct {
+	PipelineID string `json:"pipeline_id"`
+}
+
+// isCIPipelineValidationError reports whether err is caused by an invalid
+// pipeline configuration.
+func isCIPipelineValidationError(err error) bool {
+	for _, target := range []error{
+		cipipeline.ErrInvalidName,
+		cipipeline.ErrInvalidProvider,
+		cipipeline.ErrInvalidBaseURL,
+		cipipeline.ErrInvalidRepository,
+		cipipeline.ErrWorkflowRequired,
+		cipipeline.ErrRefRequired,
+		cipipeline.ErrJobRequired,
+		cipipeline.ErrUsernameRequired,
+		cipipeline.ErrTokenRequired,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectPipeline returns a pipeline of the project in the URL. Pipelines
+// of other projects are reported as not found. Returns false if it fails
+// (response already written).
+func (h *CIPipelineHandler) projectPipeline(w http.ResponseWriter, r *http.Request, projectID uuid.UUID, pipelineID uuid.UUID) (*cipipeline.Pipeline, bool) {
+	pipeline, err := h.store.GetByID(r.Context(), pipelineID)
+	if err != nil {
+		if errors.Is(err, cipipeline.ErrPipelineNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get ci pipeline")
+		return nil, false
+	}
+	if pipeline.ProjectID != projectID {
+		respondError(w, http.StatusNotFound, cipipeline.ErrPipelineNotFound.Error())
+		return nil, false
+	}
+	return pipeline, true
+}
+
+// List handles GET /projects/{id}/ci-pipelines.
+func (h *CIPipelineHandler) List(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	pipelines, err := h.store.ListByProject(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list ci pipelines")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, pipelines)
+}
+
+// Create handles POST /projects/{id}/ci-pipelines.
+func (h *CIPipelineHandler) Create(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req SaveCIPipelineRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	pipeline := req.pipeline()
+	pipeline.ProjectID = projectID
+	pipeline.CreatedBy = userID
+	if err := h.store.Create(r.Context(), pipeline); err != nil {
+		if isCIPipelineValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to create ci pipeline")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, pipeline)
+}
+
+// Update handles PUT /projects/{id}/ci-pipelines/{pipeline_id}.
+func (h *CIPipelineHandler) Update(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	pipelineID, ok := parseUUIDOrRespond(w, r, "pipeline_id", "ci pipeline")
+	if !ok {
+		return
+	}
+	if _, ok := h.projectPipeline(w, r, projectID, pipelineID); !ok {
+		return
+	}
+
+	var req SaveCIPipelineRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	pipeline := req.pipeline()
+	pipeline.ID = pipelineID
+	if err := h.store.Update(r.Context(), pipeline); err != nil {
+		switch {
+		case errors.Is(err, cipipeline.ErrPipelineNotFound):
+			respondError(w, http.StatusNotFound, err.Error())
+		case isCIPipelineValidationError(err):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to update ci pipeline")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, pipeline)
+}
+
+// Delete handles DELETE /projects/{id}/ci-pipelines/{pipeline_id}.
+func (h *CIPipelineHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	pipelineID, ok := parseUUIDOrRespond(w, r, "pipeline_id", "ci pipeline")
+	if !ok {
+		return
+	}
+	if _, ok := h.projectPipeline(w, r, projectID, pipelineID); !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), pipelineID); err != nil {
+		if errors.Is(err, cipipeline.ErrPipelineNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete ci pipeline")
+		return
+	}
+
+	respondSuccess(w, "ci pipeline deleted successfully")
+}
+
+// Trigger handles POST /runs/{run_id}/ci-builds, triggering a pipeline of
+// the run's project with the run's metadata as parameters and recording
+// the build it started on the run.
+func (h *CIPipelineHandler) Trigger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+	owner, _ := GetRunOwner(r.Context())
+
+	var req TriggerCIBuildRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	pipelineID, err := uuid.Parse(req.PipelineID)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid pipeline_id")
+		return
+	}
+	pipeline, ok := h.projectPipeline(w, r, owner.ProjectID, pipelineID)
+	if !ok {
+		return
+	}
+
+	run, err := h.testRunStore.GetByID(ctx, runID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get test run")
+		return
+	}
+	procedure, err := h.testProcedureStore.GetByID(ctx, run.TestProcedureID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return
+	}
+
+	client, err := h.newClient(pipeline)
+	if err != nil {
+		h.logger.Error(ctx, "failed to create ci client", map[string]interface{}{
+			"error":       err.Error(),
+			"pipeline_id": pipeline.ID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create client")
+		return
+	}
+
+	info := cipipeline.RunInfo{
+		RunID:            run.ID,
+		ProcedureID:      procedure.ID,
+		ProjectID:        owner.ProjectID,
+		ProcedureName:    procedure.Name,
+		ProcedureVersion: procedure.Version,
+		Environment:      run.Environment,
+		BaseURL:          run.BaseURL,
+	}
+	buildURL, err := client.Trigger(ctx, info.Parameters())
+	if err != nil {
+		h.logger.Warn(ctx, "failed to trigger ci pipeline", map[string]interface{}{
+			"error":       err.Error(),
+			"pipeline_id": pipeline.ID.String(),
+			"run_id":      runID.String(),
+		})
+		respondError(w, http.StatusBadGateway, "failed to trigger ci pipeline: "+err.Error())
+		return
+	}
+
+	userID, _ := GetUserID(ctx)
+	build := &cipipeline.Build{
+		TestRunID:    runID,
+		PipelineID:   pipeline.ID,
+		PipelineName: pipeline.Name,
+		Provider:     pipeline.Provider,
+		BuildURL:     buildURL,
+		TriggeredBy:  userID,
+	}
+	if err := h.buildStore.Create(ctx, build); err != nil {
+		respondError(w, http.StatusInternalServerError, "ci pipeline triggered but failed to record the build")
+		return
+	}
+
+	h.logger.Info(ctx, "ci pipeline triggered", map[string]interface{}{
+		"pipeline_id": pipeline.ID.String(),
+		"run_id":      runID.String(),
+		"build_url":   buildURL,
+	})
+
+	respondJSON(w, http.StatusCreated, build)
+}
+
+// ListBuilds handles GET /runs/{run_id}/ci-builds.
+func (h *CIPipelineHandler) ListBuilds(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	builds, err := h.buildStore.ListByRun(r.Context(), runID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list ci builds")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, builds)
+}
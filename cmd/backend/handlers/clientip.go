@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ClientIPKey is the context key for the IP address a request came from.
+const ClientIPKey ContextKey = "client_ip"
+
+// ClientIPMiddleware resolves the IP address each request came from. Only
+// requests from trustedProxies have their X-Forwarded-For header believed:
+// the client is the rightmost forwarded address that is not itself a trusted
+// proxy. Without trusted proxies, the client is the connection's address.
+func ClientIPMiddleware(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trustedProxies)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ClientIPKey, ip)))
+		})
+	}
+}
+
+// resolveClientIP walks X-Forwarded-For from the right while the hops are
+// trusted proxies. A malformed entry stops the walk at the last trusted hop.
+func resolveClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	ip := remoteIP(r)
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return ip
+		}
+		ip = addr.Unmap().String()
+		if !isTrustedProxy(ip, trustedProxies) {
+			return ip
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP address of the connection a request came on.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientIP returns the IP address the request came from, as resolved by
+// ClientIPMiddleware, or the connection's address outside of it.
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ClientIPKey).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIPMiddleware(t *testing.T) {
+	t.Parallel()
+
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		trusted    []netip.Prefix
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{
+			name:       "direct connection",
+			trusted:    trusted,
+			remoteAddr: "203.0.113.5:4000",
+			want:       "203.0.113.5",
+		},
+		{
+			name:       "forwarded header from untrusted peer is ignored",
+			trusted:    trusted,
+			remoteAddr: "203.0.113.5:4000",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "203.0.113.5",
+		},
+		{
+			name:       "no trusted proxies",
+			trusted:    nil,
+			remoteAddr: "10.0.0.2:4000",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "trusted proxy",
+			trusted:    trusted,
+			remoteAddr: "10.0.0.2:4000",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "spoofed leftmost entry is skipped",
+			trusted:    trusted,
+			remoteAddr: "10.0.0.2:4000",
+			forwarded:  []string{"192.0.2.66, 198.51.100.1, 10.0.0.3"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "multiple headers",
+			trusted:    trusted,
+			remoteAddr: "10.0.0.2:4000",
+			forwarded:  []string{"198.51.100.1", "10.0.0.3"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "malformed entry stops at the last trusted hop",
+			trusted:    trusted,
+			remoteAddr: "10.0.0.2:4000",
+			forwarded:  []string{"198.51.100.1, bogus"},
+			want:       "10.0.0.2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got string
+			handler := ClientIPMiddleware(tc.trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = clientIP(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tokens", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, header := range tc.forwarded {
+				req.Header.Add("X-Forwarded-For", header)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tc.want {
+				t.Errorf("clientIP() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
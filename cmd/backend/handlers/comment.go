@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// CommentHandler handles test run discussion requests. A run's discussion is
+// open to the owner of its project, the user who executed it and the user it
+// is assigned to, as checked by RunAuthorizationMiddleware.Participants.
+type CommentHandler struct {
+	commentStore       testrun.CommentStore
+	testRunStore       testrun.Store
+	testProcedureStore testprocedure.Store
+	userStore          user.Store
+	notifier           *notification.Notifier
+	logger             logger.Logger
+}
+
+// NewCommentHandler creates a new comment handler.
+func NewCommentHandler(commentStore testrun.CommentStore, testRunStore testrun.Store, testProcedureStore testprocedure.Store, userStore user.Store, notifier *notification.Notifier, log logger.Logger) *CommentHandler {
+	return &CommentHandler{
+		commentStore:       commentStore,
+		testRunStore:       testRunStore,
+		testProcedureStore: testProcedureStore,
+		userStore:          userStore,
+		notifier:           notifier,
+		logger:             log,
+	}
+}
+
+// CreateCommentRequest represents a comment creation request.
+type CreateCommentRequest struct {
+	Body string `json:"body"`
+	// StepIndex is the zero-based procedure step the comment is about. The
+	// comment is about the whole run when it is omitted. Replies default to
+	// their parent's step.
+	StepIndex *int    `json:"step_index,omitempty"`
+	ParentID  *string `json:"parent_id,omitempty"`
+}
+
+// UpdateCommentRequest represents a comment edit request.
+type UpdateCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// getRunComment loads a comment on the run in the URL and reports whether
+// the user owns the run's project. Returns false if the comment cannot be
+// loaded (response already written).
+func (h *CommentHandler) getRunComment(w http.ResponseWriter, r *http.Request) (*testrun.Comment, bool, bool) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return nil, false, false
+	}
+	commentID, ok := parseUUIDOrRespond(w, r, "comment_id", "comment")
+	if !ok {
+		return nil, false, false
+	}
+
+	comment, err := h.commentStore.GetByID(r.Context(), commentID)
+	if err != nil {
+		if errors.Is(err, testrun.ErrCommentNotFound) {
+			respondError(w, http.StatusNotFound, "comment not found")
+			return nil, false, false
+		}
+		h.logger.Error(r.Context(), "failed to get comment", map[string]interface{}{
+			"error":      err.Error(),
+			"comment_id": commentID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get comment")
+		return nil, false, false
+	}
+	if comment.TestRunID != runID {
+		respondError(w, http.StatusNotFound, "comment not found")
+		return nil, false, false
+	}
+
+	userID, _ := GetUserID(r.Context())
+	owner, _ := GetRunOwner(r.Context())
+	return comment, owner.UserID == userID, true
+}
+
+// List handles listing the comments on a test run, oldest first. The
+// step_index query parameter limits the list to one step.
+func (h *CommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	var stepIndex *int
+	if s := r.URL.Query().Get("step_index"); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid step index")
+			return
+		}
+		stepIndex = &i
+	}
+
+	comments, err := h.commentStore.ListByTestRun(r.Context(), runID, stepIndex)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to list comments", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": runID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list comments")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, comments)
+}
+
+// Create handles adding a comment or reply to a test run's discussion.
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID, _ := GetUserID(r.Context())
+
+	comment := &testrun.Comment{
+		TestRunID: runID,
+		StepIndex: req.StepIndex,
+		AuthorID:  userID,
+		Body:      req.Body,
+	}
+
+	if req.ParentID != nil {
+		parentID, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid parent_id")
+			return
+		}
+		comment.ParentID = &parentID
+
+		if comment.StepIndex == nil {
+			parent, err := h.commentStore.GetByID(r.Context(), parentID)
+			if err == nil && parent.TestRunID == runID {
+				comment.StepIndex = parent.StepIndex
+			}
+		}
+	}
+
+	if comment.StepIndex != nil {
+		proc, err := h.runProcedure(r.Context(), runID)
+		if err != nil {
+			h.logger.Error(r.Context(), "failed to get procedure for comment", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": runID,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to create comment")
+			return
+		}
+		if *comment.StepIndex < 0 || *comment.StepIndex >= len(proc.Steps) {
+			respondError(w, http.StatusBadRequest, "step_index is out of range")
+			return
+		}
+	}
+
+	if err := h.commentStore.Create(r.Context(), comment); err != nil {
+		if errors.Is(err, testrun.ErrInvalidCommentBody) || errors.Is(err, testrun.ErrInvalidCommentParent) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error(r.Context(), "failed to create comment", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": runID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create comment")
+		return
+	}
+
+	h.notifyMentions(r.Context(), comment, comment.Mentions())
+
+	respondJSON(w, http.StatusCreated, comment)
+}
+
+// Update handles editing a comment. Only its author may edit it. Users
+// mentioned for the first time by the edit are notified.
+func (h *CommentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req UpdateCommentRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	comment, _, ok := h.getRunComment(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+	if comment.AuthorID != userID {
+		respondError(w, http.StatusForbidden, "only the author can edit this comment")
+		return
+	}
+
+	previous := make(map[string]bool)
+	for _, name := range comment.Mentions() {
+		previous[name] = true
+	}
+
+	if err := h.commentStore.UpdateBody(r.Context(), comment.ID, req.Body); err != nil {
+		if errors.Is(err, testrun.ErrInvalidCommentBody) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, testrun.ErrCommentNotFound) {
+			respondError(w, http.StatusNotFound, "comment not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to update comment", map[string]interface{}{
+			"error":      err.Error(),
+			"comment_id": comment.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to update comment")
+		return
+	}
+
+	updated, err := h.commentStore.GetByID(r.Context(), comment.ID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get updated comment", map[string]interface{}{
+			"error":      err.Error(),
+			"comment_id": comment.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get updated comment")
+		return
+	}
+
+	var added []string
+	for _, name := range updated.Mentions() {
+		if !previous[name] {
+			added = append(added, name)
+		}
+	}
+	h.notifyMentions(r.Context(), updated, added)
+
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// Delete handles deleting a comment and its replies. The author and the
+// owner of the run's project may delete it.
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	comment, isOwner, ok := h.getRunComment(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+	if comment.AuthorID != userID && !isOwner {
+		respondError(w, http.StatusForbidden, "only the author or project owner can delete this comment")
+		return
+	}
+
+	if err := h.commentStore.Delete(r.Context(), comment.ID); err != nil {
+		if errors.Is(err, testrun.ErrCommentNotFound) {
+			respondError(w, http.StatusNotFound, "comment not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to delete comment", map[string]interface{}{
+			"error":      err.Error(),
+			"comment_id": comment.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to delete comment")
+		return
+	}
+
+	respondSuccess(w, "comment deleted successfully")
+}
+
+// runProcedure returns the procedure a test run executes, preferring the
+// snapshot taken when the run started.
+func (h *CommentHandler) runProcedure(ctx context.Context, runID uuid.UUID) (*testprocedure.TestProcedure, error) {
+	tr, err := h.testRunStore.GetByID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if tr.ProcedureSnapshot != nil {
+		return tr.ProcedureSnapshot.Procedure(), nil
+	}
+	return h.testProcedureStore.GetByID(ctx, tr.TestProcedureID)
+}
+
+// notifyMentions notifies the users with the given usernames that the
+// comment's author mentioned them. Authors are not notified of their own
+// mentions.
+func (h *CommentHandler) notifyMentions(ctx context.Context, comment *testrun.Comment, usernames []string) {
+	if len(usernames) == 0 {
+		return
+	}
+
+	author, err := h.userStore.GetByID(ctx, comment.AuthorID)
+	if err != nil {
+		h.logger.Error(ctx, "failed to get comment author for mentions", map[string]interface{}{
+			"error":      err.Error(),
+			"comment_id": comment.ID,
+		})
+		return
+	}
+	mentioned, err := h.userStore.ListByUsernames(ctx, usernames)
+	if err != nil {
+		h.logger.Error(ctx, "failed to resolve mentioned users", map[string]interface{}{
+			"error":      err.Error(),
+			"comment_id": comment.ID,
+		})
+		return
+	}
+
+	var recipients []uuid.UUID
+	for _, u := range mentioned {
+		if u.ID != author.ID {
+			recipients = append(recipients, u.ID)
+		}
+	}
+	h.notifier.Notify(ctx, notification.MentionedEvent(comment.TestRunID, author.Username, comment.Body, recipients...))
+}
@@ -2,12 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/quota"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
 )
 
 // ErrorResponse represents an error response.
@@ -20,6 +27,25 @@ type SuccessResponse struct {
 	Message string `json:"message"`
 }
 
+// QuotaExceededResponse represents an upload rejected by the project's
+// storage quota.
+type QuotaExceededResponse struct {
+	Error          string `json:"error"`
+	UsedBytes      int64  `json:"used_bytes"`
+	QuotaBytes     int64  `json:"quota_bytes"`
+	RequestedBytes int64  `json:"requested_bytes"`
+}
+
+// BudgetExceededResponse represents a model call rejected by a spent monthly
+// LLM budget.
+type BudgetExceededResponse struct {
+	Error           string  `json:"error"`
+	Scope           string  `json:"scope"`
+	Month           string  `json:"month"`
+	SpentUSD        float64 `json:"spent_usd"`
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+}
+
 // PaginatedResponse represents a standardized paginated API response.
 // All list endpoints should return this format to match frontend expectations.
 type PaginatedResponse struct {
@@ -85,3 +111,101 @@ func parseUUIDOrRespond(w http.ResponseWriter, r *http.Request, paramName, entit
 	}
 	return id, true
 }
+
+// checkRunOwner verifies that the caller can access the run's project and
+// returns the owner. Returns false if the check fails (response already
+// written).
+func checkRunOwner(w http.ResponseWriter, r *http.Request, owners *ownership.Resolver, runID uuid.UUID) (ownership.Owner, bool) {
+	if _, ok := GetUserID(r.Context()); !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return ownership.Owner{}, false
+	}
+
+	owner, err := owners.RunOwner(r.Context(), runID)
+	if err != nil {
+		respondRunOwnerError(w, err)
+		return ownership.Owner{}, false
+	}
+	if !canAccessProject(r, owner.ProjectID, owner.UserID) {
+		respondError(w, http.StatusForbidden, "access denied")
+		return ownership.Owner{}, false
+	}
+	return owner, true
+}
+
+// respondRunOwnerError responds to an error resolving a test run's owner.
+func respondRunOwnerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, testrun.ErrTestRunNotFound):
+		respondError(w, http.StatusNotFound, "test run not found")
+	case errors.Is(err, testprocedure.ErrTestProcedureNotFound):
+		respondError(w, http.StatusNotFound, "test procedure not found")
+	case errors.Is(err, project.ErrProjectNotFound):
+		respondError(w, http.StatusNotFound, "project not found")
+	default:
+		respondError(w, http.StatusInternalServerError, "failed to verify test run")
+	}
+}
+
+// checkStorageQuota verifies that storing incomingBytes more keeps a project
+// within its storage quota. Returns false if the check fails (response
+// already written).
+func checkStorageQuota(w http.ResponseWriter, r *http.Request, quotas *quota.Enforcer, projectID uuid.UUID, incomingBytes int64, log logger.Logger) bool {
+	return handleStorageQuotaError(w, r, quotas.Check(r.Context(), projectID, incomingBytes), projectID, log)
+}
+
+// handleStorageQuotaError responds to the error of a storage quota check,
+// answering 413 with the usage if the quota would be exceeded. Returns true
+// if err is nil (no response written).
+func handleStorageQuotaError(w http.ResponseWriter, r *http.Request, err error, projectID uuid.UUID, log logger.Logger) bool {
+	if err == nil {
+		return true
+	}
+
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		respondJSON(w, http.StatusRequestEntityTooLarge, QuotaExceededResponse{
+			Error:          exceeded.Error() + "; delete unused assets or ask an administrator to raise the quota",
+			UsedBytes:      exceeded.Usage.TotalBytes,
+			QuotaBytes:     exceeded.Usage.QuotaBytes,
+			RequestedBytes: exceeded.RequestedBytes,
+		})
+		return false
+	}
+
+	log.Error(r.Context(), "failed to check storage quota", map[string]interface{}{
+		"error":      err.Error(),
+		"project_id": projectID.String(),
+	})
+	respondError(w, http.StatusInternalServerError, "failed to check storage quota")
+	return false
+}
+
+// checkLLMBudget verifies that the monthly LLM budgets covering a project are
+// not spent, answering 402 if one is. Returns false if the check fails
+// (response already written).
+func checkLLMBudget(w http.ResponseWriter, r *http.Request, meter *llmusage.Meter, projectID uuid.UUID, log logger.Logger) bool {
+	err := meter.Check(r.Context(), projectID)
+	if err == nil {
+		return true
+	}
+
+	var exceeded *llmusage.ExceededError
+	if errors.As(err, &exceeded) {
+		respondJSON(w, http.StatusPaymentRequired, BudgetExceededResponse{
+			Error:           exceeded.Error() + "; wait for next month or ask the account owner to raise the budget",
+			Scope:           exceeded.Budget.Scope(),
+			Month:           exceeded.Month,
+			SpentUSD:        exceeded.SpentUSD,
+			MonthlyLimitUSD: exceeded.Budget.MonthlyLimitUSD,
+		})
+		return false
+	}
+
+	log.Error(r.Context(), "failed to check llm budget", map[string]interface{}{
+		"error":      err.Error(),
+		"project_id": projectID.String(),
+	})
+	respondError(w, http.StatusInternalServerError, "failed to check llm budget")
+	return false
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The methods and headers cross-origin requests may use, and the response
+// headers they may read; they cover every route of the API.
+var (
+	corsAllowedMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}
+	corsAllowedHeaders = []string{"Authorization", "Content-Type", "If-Match", CSRFHeader, IdempotencyKeyHeader}
+	corsExposedHeaders = []string{"Content-Disposition", "ETag", "Retry-After", CSRFHeader, IdempotentReplayedHeader}
+)
+
+// CORSPolicy is which other origins may call the API from a browser.
+type CORSPolicy struct {
+	// AllowedOrigins are origins such as "https://app.example.com", or "*"
+	// for any origin.
+	AllowedOrigins []string
+	// AllowCredentials lets allowed origins send the session cookie. It
+	// cannot be combined with "*".
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// allows reports whether origin may call the API.
+func (p CORSPolicy) allows(origin string) bool {
+	return slices.Contains(p.AllowedOrigins, "*") || slices.Contains(p.AllowedOrigins, origin)
+}
+
+// CORSMiddleware answers preflight requests and adds CORS headers to the
+// responses of requests from allowed origins. Preflights from other origins
+// get 403; their other requests are served without CORS headers, so
+// browsers hide the response. It must wrap the router rather than be added
+// with Use, since preflights match no route.
+func CORSMiddleware(policy CORSPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Add("Vary", "Origin")
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !policy.allows(origin) {
+				if preflight {
+					respondError(w, http.StatusForbidden, "origin not allowed")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if policy.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if preflight {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(corsAllowedMethods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+				if policy.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	t.Parallel()
+
+	policy := CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	nextCalled := false
+	handler := CORSMiddleware(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name            string
+		method          string
+		origin          string
+		preflight       bool
+		wantStatus      int
+		wantNext        bool
+		wantAllowOrigin string
+	}{
+		{
+			name:       "same-origin request passes",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+		},
+		{
+			name:            "allowed origin gets CORS headers",
+			method:          http.MethodPost,
+			origin:          "https://app.example.com",
+			wantStatus:      http.StatusOK,
+			wantNext:        true,
+			wantAllowOrigin: "https://app.example.com",
+		},
+		{
+			name:       "other origin gets no CORS headers",
+			method:     http.MethodPost,
+			origin:     "https://evil.example.com",
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+		},
+		{
+			name:            "allowed preflight is answered",
+			method:          http.MethodOptions,
+			origin:          "https://app.example.com",
+			preflight:       true,
+			wantStatus:      http.StatusNoContent,
+			wantAllowOrigin: "https://app.example.com",
+		},
+		{
+			name:       "other preflight returns 403",
+			method:     http.MethodOptions,
+			origin:     "https://evil.example.com",
+			preflight:  true,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			nextCalled = false
+			req := httptest.NewRequest(tc.method, "/api/v1/projects", nil)
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			if tc.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("status code = %d, want %d", w.Code, tc.wantStatus)
+			}
+			if nextCalled != tc.wantNext {
+				t.Errorf("next called = %v, want %v", nextCalled, tc.wantNext)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tc.wantAllowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tc.wantAllowOrigin)
+			}
+			if tc.wantAllowOrigin != "" && w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+				t.Error("Access-Control-Allow-Credentials not set")
+			}
+			if tc.preflight && tc.wantAllowOrigin != "" && w.Header().Get("Access-Control-Max-Age") != "600" {
+				t.Errorf("Access-Control-Max-Age = %q, want 600", w.Header().Get("Access-Control-Max-Age"))
+			}
+		})
+	}
+}
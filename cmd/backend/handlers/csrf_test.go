@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+func TestCSRFProtection(t *testing.T) {
+	t.Parallel()
+
+	csrf := NewCSRFProtection("test-secret", "csrf_token", false, logger.NewTestLogger())
+	sessionID := uuid.New()
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		authMethod string
+		header     string
+		wantStatus int
+	}{
+		{
+			name:       "session GET without token passes",
+			method:     http.MethodGet,
+			authMethod: "session",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "session POST without token returns 403",
+			method:     http.MethodPost,
+			authMethod: "session",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "session POST with another session's token returns 403",
+			method:     http.MethodPost,
+			authMethod: "session",
+			header:     csrf.Token(uuid.New()),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "session DELETE with token passes",
+			method:     http.MethodDelete,
+			authMethod: "session",
+			header:     csrf.Token(sessionID),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bearer POST without token passes",
+			method:     http.MethodPost,
+			authMethod: "bearer",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tc.method, "/test", nil)
+			ctx := context.WithValue(req.Context(), AuthMethodKey, tc.authMethod)
+			if tc.authMethod == "session" {
+				ctx = context.WithValue(ctx, SessionIDKey, sessionID)
+			}
+			req = req.WithContext(ctx)
+			if tc.header != "" {
+				req.Header.Set(CSRFHeader, tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			csrf.Handler(okHandler).ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("status code = %d, want %d", w.Code, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCSRFProtection_IssuesMissingCookie(t *testing.T) {
+	t.Parallel()
+
+	csrf := NewCSRFProtection("test-secret", "csrf_token", false, logger.NewTestLogger())
+	sessionID := uuid.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	ctx := context.WithValue(req.Context(), AuthMethodKey, "session")
+	req = req.WithContext(context.WithValue(ctx, SessionIDKey, sessionID))
+	w := httptest.NewRecorder()
+
+	csrf.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, req)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "csrf_token" || cookies[0].Value != csrf.Token(sessionID) {
+		t.Fatalf("cookies = %v, want csrf_token with the session's token", cookies)
+	}
+	if cookies[0].HttpOnly {
+		t.Error("CSRF cookie is HttpOnly, want readable by scripts")
+	}
+}
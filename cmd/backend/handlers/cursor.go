@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+)
+
+// CursorResponse is a page of a list paginated by cursor, for lists too
+// long to page through by offset. NextCursor is passed as ?cursor= for the
+// next page and is empty on the last page. There is no total, since
+// counting is what makes long lists slow.
+type CursorResponse struct {
+	Items      interface{} `json:"items"`
+	Limit      int         `json:"limit"`
+	NextCursor string      `json:"next_cursor"`
+}
+
+// NewCursorResponse creates a new cursor paginated response.
+func NewCursorResponse(items interface{}, limit int, nextCursor string) CursorResponse {
+	return CursorResponse{
+		Items:      items,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
+}
+
+// usesCursor reports whether a list request asks for cursor pagination
+// with ?cursor=, which is empty for the first page. Lists without it are
+// paginated by offset.
+func usesCursor(r *http.Request) bool {
+	return r.URL.Query().Has("cursor")
+}
+
+// parseCursor returns the ?cursor= of a request, nil for the first page.
+// Returns false if it is invalid (response already written).
+func parseCursor(w http.ResponseWriter, r *http.Request) (*database.Cursor, bool) {
+	s := r.URL.Query().Get("cursor")
+	if s == "" {
+		return nil, true
+	}
+	cursor, err := database.DecodeCursor(s)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return cursor, true
+}
+
+// cursorPage trims items, listed with a limit of limit+1, to a page of
+// limit, and returns the cursor of the next page, empty if there is none.
+// key returns the creation time and ID of an item.
+func cursorPage[T any](items []T, limit int, key func(T) (time.Time, uuid.UUID)) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	return items, database.CursorOf(key(items[limit-1])).Encode()
+}
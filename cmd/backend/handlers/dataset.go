@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/dataset"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// DatasetHandler handles the datasets attached to test procedures, from
+// which batches of runs are launched.
+type DatasetHandler struct {
+	datasetStore   dataset.Store
+	testProcedures *TestProcedureHandler
+	logger         logger.Logger
+}
+
+// NewDatasetHandler creates a new dataset handler. Procedure ownership is
+// checked through the test procedure handler.
+func NewDatasetHandler(datasetStore dataset.Store, testProcedures *TestProcedureHandler, log logger.Logger) *DatasetHandler {
+	return &DatasetHandler{
+		datasetStore:   datasetStore,
+		testProcedures: testProcedures,
+		logger:         log,
+	}
+}
+
+// CreateDatasetRequest represents a dataset creation request. The rows are
+// given either as CSV, whose first line names the columns, or as a JSON
+// array of objects.
+type CreateDatasetRequest struct {
+	Name string          `json:"name"`
+	CSV  string          `json:"csv,omitempty"`
+	Rows json.RawMessage `json:"rows,omitempty"`
+}
+
+// isDatasetValidationError reports whether err is caused by invalid input.
+func isDatasetValidationError(err error) bool {
+	return errors.Is(err, dataset.ErrInvalidName) ||
+		errors.Is(err, dataset.ErrNoRows) ||
+		errors.Is(err, dataset.ErrTooManyRows) ||
+		errors.Is(err, dataset.ErrInvalidData)
+}
+
+// getDataset loads the dataset in the URL after checking access to its
+// procedure. Returns false if the check fails (response already written).
+func (h *DatasetHandler) getDataset(w http.ResponseWriter, r *http.Request) (*dataset.Dataset, bool) {
+	return loadProcedureDataset(w, r, h.testProcedures, h.datasetStore, h.logger)
+}
+
+// loadProcedureDataset loads the dataset in the URL after checking access to
+// the procedure in the URL, which the dataset must be attached to. Returns
+// false if the check fails (response already written).
+func loadProcedureDataset(w http.ResponseWriter, r *http.Request, testProcedures *TestProcedureHandler, store dataset.Store, log logger.Logger) (*dataset.Dataset, bool) {
+	datasetID, ok := parseUUIDOrRespond(w, r, "dataset_id", "dataset")
+	if !ok {
+		return nil, false
+	}
+	_, rootID, ok := testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return nil, false
+	}
+
+	ds, err := store.GetByID(r.Context(), datasetID)
+	if err != nil {
+		if errors.Is(err, dataset.ErrDatasetNotFound) {
+			respondError(w, http.StatusNotFound, "dataset not found")
+			return nil, false
+		}
+		log.Error(r.Context(), "failed to get dataset", map[string]interface{}{
+			"error":      err.Error(),
+			"dataset_id": datasetID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get dataset")
+		return nil, false
+	}
+	if ds.TestProcedureID != rootID {
+		respondError(w, http.StatusNotFound, "dataset not found")
+		return nil, false
+	}
+
+	return ds, true
+}
+
+// List handles GET /procedures/{procedure_id}/datasets.
+func (h *DatasetHandler) List(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return
+	}
+
+	datasets, err := h.datasetStore.ListByTestProcedure(r.Context(), rootID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list datasets")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": datasets,
+		"total": len(datasets),
+	})
+}
+
+// Create handles POST /procedures/{procedure_id}/datasets.
+func (h *DatasetHandler) Create(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	var req CreateDatasetRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var columns dataset.Columns
+	var rows dataset.Rows
+	var err error
+	switch {
+	case req.CSV != "" && len(req.Rows) > 0:
+		respondError(w, http.StatusBadRequest, "give either csv or rows, not both")
+		return
+	case req.CSV != "":
+		columns, rows, err = dataset.ParseCSV(strings.NewReader(req.CSV))
+	case len(req.Rows) > 0:
+		columns, rows, err = dataset.ParseJSON(req.Rows)
+	default:
+		respondError(w, http.StatusBadRequest, "csv or rows is required")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ds := &dataset.Dataset{
+		TestProcedureID: rootID,
+		Name:            req.Name,
+		Columns:         columns,
+		Rows:            rows,
+		CreatedBy:       userID,
+	}
+	if err := h.datasetStore.Create(r.Context(), ds); err != nil {
+		if isDatasetValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to create dataset")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, ds)
+}
+
+// GetByID handles GET /procedures/{procedure_id}/datasets/{dataset_id}.
+func (h *DatasetHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	ds, ok := h.getDataset(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, ds)
+}
+
+// Delete handles DELETE /procedures/{procedure_id}/datasets/{dataset_id}.
+// Run groups launched from the dataset are kept.
+func (h *DatasetHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ds, ok := h.getDataset(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.datasetStore.Delete(r.Context(), ds.ID); err != nil {
+		if errors.Is(err, dataset.ErrDatasetNotFound) {
+			respondError(w, http.StatusNotFound, "dataset not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete dataset")
+		return
+	}
+
+	respondSuccess(w, "dataset deleted")
+}
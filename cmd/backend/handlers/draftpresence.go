@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/presence"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// DraftPresenceHandler shows who else has a procedure draft open and hands
+// out its edit lock. Editors poll the heartbeat while the draft is open.
+type DraftPresenceHandler struct {
+	tracker        *presence.Tracker
+	testProcedures *TestProcedureHandler
+	userStore      user.Store
+	logger         logger.Logger
+}
+
+// NewDraftPresenceHandler creates a new draft presence handler. Procedure
+// ownership is checked through the test procedure handler.
+func NewDraftPresenceHandler(tracker *presence.Tracker, testProcedures *TestProcedureHandler, userStore user.Store, log logger.Logger) *DraftPresenceHandler {
+	return &DraftPresenceHandler{
+		tracker:        tracker,
+		testProcedures: testProcedures,
+		userStore:      userStore,
+		logger:         log,
+	}
+}
+
+// DraftPresenceResponse lists who has a draft open and who holds its edit
+// lock, null if nobody does.
+type DraftPresenceResponse struct {
+	Viewers []presence.Viewer `json:"viewers"`
+	Lock    *presence.Lock    `json:"lock"`
+}
+
+// DraftLockedResponse is the 423 response to a change to a draft whose
+// edit lock is held by another user.
+type DraftLockedResponse struct {
+	Error string         `json:"error"`
+	Lock  *presence.Lock `json:"lock"`
+}
+
+// Heartbeat handles POST /procedures/{id}/draft/presence, sent every few
+// seconds while the caller has the draft open. It responds like Get.
+func (h *DraftPresenceHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	rootID, userID, ok := h.checkDraft(w, r)
+	if !ok {
+		return
+	}
+	username, ok := h.username(w, r, userID)
+	if !ok {
+		return
+	}
+
+	h.tracker.Heartbeat(rootID, userID, username)
+	h.respondPresence(w, rootID)
+}
+
+// Get handles GET /procedures/{id}/draft/presence.
+func (h *DraftPresenceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	rootID, _, ok := h.checkDraft(w, r)
+	if !ok {
+		return
+	}
+	h.respondPresence(w, rootID)
+}
+
+// Leave handles DELETE /procedures/{id}/draft/presence, sent when the
+// caller closes the draft. Their edit lock is released.
+func (h *DraftPresenceHandler) Leave(w http.ResponseWriter, r *http.Request) {
+	rootID, userID, ok := h.checkDraft(w, r)
+	if !ok {
+		return
+	}
+	h.tracker.Leave(rootID, userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcquireLock handles POST /procedures/{id}/draft/lock, which locks the
+// draft for the caller or renews their lock. Locks expire unless renewed,
+// so a closed browser does not lock others out for long.
+func (h *DraftPresenceHandler) AcquireLock(w http.ResponseWriter, r *http.Request) {
+	rootID, userID, ok := h.checkDraft(w, r)
+	if !ok {
+		return
+	}
+	username, ok := h.username(w, r, userID)
+	if !ok {
+		return
+	}
+
+	lock, err := h.tracker.AcquireLock(rootID, userID, username)
+	if err != nil {
+		respondDraftLocked(w, lock)
+		return
+	}
+	respondJSON(w, http.StatusOK, lock)
+}
+
+// ReleaseLock handles DELETE /procedures/{id}/draft/lock.
+func (h *DraftPresenceHandler) ReleaseLock(w http.ResponseWriter, r *http.Request) {
+	rootID, userID, ok := h.checkDraft(w, r)
+	if !ok {
+		return
+	}
+	if err := h.tracker.ReleaseLock(rootID, userID); err != nil {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkDraft checks access to the procedure in the URL and returns the ID
+// its presence is tracked by, its first version's, and the caller. Returns
+// false if the check fails (response already written).
+func (h *DraftPresenceHandler) checkDraft(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "id")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return rootID, userID, true
+}
+
+// username returns the name the caller is shown to other editors by.
+func (h *DraftPresenceHandler) username(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (string, bool) {
+	u, err := h.userStore.GetByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusUnauthorized, "user not found")
+			return "", false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get user")
+		return "", false
+	}
+	return u.Username, true
+}
+
+func (h *DraftPresenceHandler) respondPresence(w http.ResponseWriter, rootID uuid.UUID) {
+	respondJSON(w, http.StatusOK, DraftPresenceResponse{
+		Viewers: h.tracker.Viewers(rootID),
+		Lock:    h.tracker.Lock(rootID),
+	})
+}
+
+// respondDraftLocked responds 423 to a change to a draft locked by another
+// user, with their lock.
+func respondDraftLocked(w http.ResponseWriter, lock *presence.Lock) {
+	respondJSON(w, http.StatusLocked, DraftLockedResponse{
+		Error: presence.ErrLocked.Error(),
+		Lock:  lock,
+	})
+}
+
+// checkDraftLock checks that no other user holds the edit lock of the draft
+// of the procedure with the given ID. Returns false if one does (response
+// already written).
+func (h *TestProcedureHandler) checkDraftLock(w http.ResponseWriter, r *http.Request, id uuid.UUID) bool {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return false
+	}
+
+	proc, err := h.testProcedureStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+			respondError(w, http.StatusNotFound, "test procedure not found")
+			return false
+		}
+		h.logger.Error(r.Context(), "failed to get test procedure", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return false
+	}
+	rootID := proc.ID
+	if proc.ParentID != nil {
+		rootID = *proc.ParentID
+	}
+
+	if lock, err := h.presence.CheckLock(rootID, userID); err != nil {
+		respondDraftLocked(w, lock)
+		return false
+	}
+	return true
+}
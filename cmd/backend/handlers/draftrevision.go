@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// DraftConflictResponse is the 409 response to a draft update made from a
+// stale revision. It carries the current draft, so that the client can
+// merge its changes into it and retry with its revision.
+type DraftConflictResponse struct {
+	Error string                       `json:"error"`
+	Draft *testprocedure.TestProcedure `json:"draft"`
+}
+
+// setDraftETag sets the ETag of a draft response, its quoted revision.
+func setDraftETag(w http.ResponseWriter, draft *testprocedure.TestProcedure) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatUint(uint64(draft.Revision), 10)))
+}
+
+// parseDraftRevision returns the revision a draft update was made from:
+// the ETag in the If-Match header or, for clients that cannot set headers,
+// the revision field of the request. The second result is false if the
+// update does not name one and may overwrite any revision. Returns false
+// as the third result if If-Match is invalid (response already written).
+func parseDraftRevision(w http.ResponseWriter, r *http.Request, field *uint) (uint, bool, bool) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		if field != nil {
+			return *field, true, true
+		}
+		return 0, false, true
+	}
+
+	tag, err := strconv.Unquote(strings.TrimPrefix(ifMatch, "W/"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "If-Match must be the ETag of the draft")
+		return 0, false, false
+	}
+	revision, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "If-Match must be the ETag of the draft")
+		return 0, false, false
+	}
+	return uint(revision), true, true
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseDraftRevision(t *testing.T) {
+	t.Parallel()
+
+	field := uint(4)
+	tests := []struct {
+		name         string
+		ifMatch      string
+		field        *uint
+		wantRevision uint
+		wantSet      bool
+		wantOK       bool
+	}{
+		{name: "neither", wantOK: true},
+		{name: "any revision", ifMatch: "*", wantOK: true},
+		{name: "If-Match", ifMatch: `"7"`, wantRevision: 7, wantSet: true, wantOK: true},
+		{name: "weak If-Match", ifMatch: `W/"7"`, wantRevision: 7, wantSet: true, wantOK: true},
+		{name: "If-Match wins over field", ifMatch: `"7"`, field: &field, wantRevision: 7, wantSet: true, wantOK: true},
+		{name: "field", field: &field, wantRevision: 4, wantSet: true, wantOK: true},
+		{name: "unquoted If-Match", ifMatch: "7"},
+		{name: "foreign ETag", ifMatch: `"abc"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPut, "/test", nil)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			revision, set, ok := parseDraftRevision(w, req, tc.field)
+			if ok != tc.wantOK || set != tc.wantSet || revision != tc.wantRevision {
+				t.Errorf("parseDraftRevision() = %d, %v, %v, want %d, %v, %v", revision, set, ok, tc.wantRevision, tc.wantSet, tc.wantOK)
+			}
+			if !ok && w.Code != http.StatusBadRequest {
+				t.Errorf("status code = %d, want 400", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// ListDraftSnapshots handles GET /procedures/{id}/draft/snapshots: the
+// content of the draft before each of its last changes, newest first.
+func (h *TestProcedureHandler) ListDraftSnapshots(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
+	if !ok {
+		return
+	}
+	if !h.checkProcedureOwnership(w, r, id) {
+		return
+	}
+
+	snapshots, err := h.testProcedureStore.ListDraftSnapshots(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrDraftNotFound) {
+			respondError(w, http.StatusNotFound, "draft not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to list draft snapshots", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list draft snapshots")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": snapshots,
+		"total": len(snapshots),
+	})
+}
+
+// RestoreDraftSnapshot handles POST
+// /procedures/{id}/draft/snapshots/{snapshot_id}/restore: the draft's
+// content is set back to the snapshot's and saved as its next revision.
+// Responds with the draft.
+func (h *TestProcedureHandler) RestoreDraftSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
+	if !ok {
+		return
+	}
+	snapshotID, ok := parseUUIDOrRespond(w, r, "snapshot_id", "draft snapshot")
+	if !ok {
+		return
+	}
+	if !h.checkProcedureOwnership(w, r, id) {
+		return
+	}
+	if !h.checkDraftLock(w, r, id) {
+		return
+	}
+
+	draft, err := h.testProcedureStore.RestoreDraftSnapshot(r.Context(), id, snapshotID)
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrDraftSnapshotNotFound) {
+			respondError(w, http.StatusNotFound, "draft snapshot not found")
+			return
+		}
+		if errors.Is(err, testprocedure.ErrDraftNotFound) {
+			respondError(w, http.StatusNotFound, "draft not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to restore draft snapshot", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id,
+			"snapshot_id":       snapshotID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to restore draft snapshot")
+		return
+	}
+
+	setDraftETag(w, draft)
+	respondJSON(w, http.StatusOK, draft)
+}
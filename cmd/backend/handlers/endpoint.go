@@ -4,26 +4,61 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/runner"
 )
 
 // EndpointHandler handles endpoint-related requests.
 type EndpointHandler struct {
 	endpointStore endpoint.Store
+	healthStore   endpoint.HealthStore
+	secretStore   endpoint.SecretStore
+	monitor       *endpoint.Monitor
 	logger        logger.Logger
 }
 
 // NewEndpointHandler creates a new endpoint handler.
-func NewEndpointHandler(endpointStore endpoint.Store, log logger.Logger) *EndpointHandler {
+func NewEndpointHandler(endpointStore endpoint.Store, healthStore endpoint.HealthStore, secretStore endpoint.SecretStore, monitor *endpoint.Monitor, log logger.Logger) *EndpointHandler {
 	return &EndpointHandler{
 		endpointStore: endpointStore,
+		healthStore:   healthStore,
+		secretStore:   secretStore,
+		monitor:       monitor,
 		logger:        log,
 	}
 }
 
+// recentHealthChecks is the number of recent checks returned with health stats.
+const recentHealthChecks = 20
+
+// uptimeWindows are the windows health stats are reported for.
+var uptimeWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// isEndpointValidationError reports whether err is caused by invalid endpoint input.
+func isEndpointValidationError(err error) bool {
+	return errors.Is(err, endpoint.ErrInvalidEndpointName) ||
+		errors.Is(err, endpoint.ErrInvalidEndpointURL) ||
+		errors.Is(err, endpoint.ErrInvalidMaxJobs) ||
+		errors.Is(err, endpoint.ErrInvalidHealthCheckInterval) ||
+		errors.Is(err, endpoint.ErrInvalidExpectedStatus) ||
+		errors.Is(err, endpoint.ErrInvalidGroup) ||
+		errors.Is(err, endpoint.ErrInvalidEnvironment) ||
+		errors.Is(err, endpoint.ErrEnvironmentRequired) ||
+		errors.Is(err, runner.ErrInvalidLabel)
+}
+
 // checkEndpointOwnership verifies that the authenticated user owns the endpoint.
 // Returns false if the check fails (response already written).
 func (h *EndpointHandler) checkEndpointOwnership(w http.ResponseWriter, r *http.Request, endpointID uuid.UUID) bool {
@@ -60,18 +95,97 @@ func (h *EndpointHandler) checkEndpointOwnership(w http.ResponseWriter, r *http.
 	return true
 }
 
+// EndpointTarget selects the endpoint a job or test run executes against,
+// either directly by ID or by group and environment so the same procedure
+// can run against each environment of an application.
+type EndpointTarget struct {
+	EndpointID  string `json:"endpoint_id,omitempty"`
+	Group       string `json:"endpoint_group,omitempty"`
+	Environment string `json:"environment,omitempty"`
+}
+
+// IsZero reports whether no endpoint was selected.
+func (t EndpointTarget) IsZero() bool {
+	return t.EndpointID == "" && t.Group == "" && t.Environment == ""
+}
+
+// resolveEndpointTarget loads the endpoint selected by target and verifies the
+// user owns it. Returns false if resolution fails (response already written).
+func resolveEndpointTarget(w http.ResponseWriter, r *http.Request, store endpoint.Store, log logger.Logger, userID uuid.UUID, target EndpointTarget) (*endpoint.Endpoint, bool) {
+	var ep *endpoint.Endpoint
+	var err error
+	switch {
+	case target.EndpointID != "":
+		endpointID, parseErr := uuid.Parse(target.EndpointID)
+		if parseErr != nil {
+			respondError(w, http.StatusBadRequest, "endpoint_id must be a valid UUID")
+			return nil, false
+		}
+		ep, err = store.GetByID(r.Context(), endpointID)
+	case target.Group != "" && target.Environment != "":
+		ep, err = store.GetByGroupEnvironment(r.Context(), userID, target.Group, target.Environment)
+	default:
+		respondError(w, http.StatusBadRequest, "endpoint_id, or endpoint_group and environment, is required")
+		return nil, false
+	}
+
+	if err != nil {
+		if errors.Is(err, endpoint.ErrEndpointNotFound) {
+			respondError(w, http.StatusNotFound, "endpoint not found")
+			return nil, false
+		}
+		log.Error(r.Context(), "failed to verify endpoint", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": target.EndpointID,
+			"group":       target.Group,
+			"environment": target.Environment,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to verify endpoint")
+		return nil, false
+	}
+
+	if ep.CreatedBy != userID {
+		respondError(w, http.StatusForbidden, "you don't have access to this endpoint")
+		return nil, false
+	}
+
+	return ep, true
+}
+
 // CreateEndpointRequest represents an endpoint creation request.
 type CreateEndpointRequest struct {
-	Name        string               `json:"name"`
-	URL         string               `json:"url"`
-	Credentials endpoint.Credentials `json:"credentials,omitempty"`
+	Name              string               `json:"name"`
+	URL               string               `json:"url"`
+	Credentials       endpoint.Credentials `json:"credentials,omitempty"`
+	MaxConcurrentJobs int                  `json:"max_concurrent_jobs,omitempty"`
+	Group             string               `json:"group,omitempty"`
+	Environment       string               `json:"environment,omitempty"`
+
+	HealthCheckEnabled  bool   `json:"health_check_enabled,omitempty"`
+	HealthCheckInterval int    `json:"health_check_interval_seconds,omitempty"`
+	ExpectedStatus      int    `json:"health_check_expected_status,omitempty"`
+	ExpectedText        string `json:"health_check_expected_text,omitempty"`
+
+	UseRunner    bool          `json:"use_runner,omitempty"`
+	RunnerLabels runner.Labels `json:"runner_labels,omitempty"`
 }
 
 // UpdateEndpointRequest represents an endpoint update request.
 type UpdateEndpointRequest struct {
-	Name        *string               `json:"name,omitempty"`
-	URL         *string               `json:"url,omitempty"`
-	Credentials *endpoint.Credentials `json:"credentials,omitempty"`
+	Name              *string               `json:"name,omitempty"`
+	URL               *string               `json:"url,omitempty"`
+	Credentials       *endpoint.Credentials `json:"credentials,omitempty"`
+	MaxConcurrentJobs *int                  `json:"max_concurrent_jobs,omitempty"`
+	Group             *string               `json:"group,omitempty"`
+	Environment       *string               `json:"environment,omitempty"`
+
+	HealthCheckEnabled  *bool   `json:"health_check_enabled,omitempty"`
+	HealthCheckInterval *int    `json:"health_check_interval_seconds,omitempty"`
+	ExpectedStatus      *int    `json:"health_check_expected_status,omitempty"`
+	ExpectedText        *string `json:"health_check_expected_text,omitempty"`
+
+	UseRunner    *bool          `json:"use_runner,omitempty"`
+	RunnerLabels *runner.Labels `json:"runner_labels,omitempty"`
 }
 
 // Create handles creating a new endpoint.
@@ -89,18 +203,32 @@ func (h *EndpointHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ep := &endpoint.Endpoint{
-		Name:        req.Name,
-		URL:         req.URL,
-		Credentials: req.Credentials,
-		CreatedBy:   userID,
+		Name:              req.Name,
+		URL:               req.URL,
+		Credentials:       req.Credentials,
+		MaxConcurrentJobs: req.MaxConcurrentJobs,
+		CreatedBy:         userID,
+		Group:             req.Group,
+		Environment:       req.Environment,
+
+		HealthCheckEnabled:  req.HealthCheckEnabled,
+		HealthCheckInterval: req.HealthCheckInterval,
+		ExpectedStatus:      req.ExpectedStatus,
+		ExpectedText:        req.ExpectedText,
+
+		UseRunner:    req.UseRunner,
+		RunnerLabels: req.RunnerLabels.Normalize(),
 	}
 
 	if err := h.endpointStore.Create(r.Context(), ep); err != nil {
-		if errors.Is(err, endpoint.ErrInvalidEndpointName) ||
-			errors.Is(err, endpoint.ErrInvalidEndpointURL) {
+		if isEndpointValidationError(err) {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if errors.Is(err, endpoint.ErrDuplicateEnvironment) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
 		h.logger.Error(r.Context(), "failed to create endpoint", map[string]interface{}{
 			"error": err.Error(),
 		})
@@ -212,6 +340,33 @@ func (h *EndpointHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Credentials != nil {
 		setters = append(setters, endpoint.SetCredentials(*req.Credentials))
 	}
+	if req.MaxConcurrentJobs != nil {
+		setters = append(setters, endpoint.SetMaxConcurrentJobs(*req.MaxConcurrentJobs))
+	}
+	if req.Group != nil {
+		setters = append(setters, endpoint.SetGroup(*req.Group))
+	}
+	if req.Environment != nil {
+		setters = append(setters, endpoint.SetEnvironment(*req.Environment))
+	}
+	if req.HealthCheckEnabled != nil {
+		setters = append(setters, endpoint.SetHealthCheckEnabled(*req.HealthCheckEnabled))
+	}
+	if req.HealthCheckInterval != nil {
+		setters = append(setters, endpoint.SetHealthCheckInterval(*req.HealthCheckInterval))
+	}
+	if req.ExpectedStatus != nil {
+		setters = append(setters, endpoint.SetExpectedStatus(*req.ExpectedStatus))
+	}
+	if req.ExpectedText != nil {
+		setters = append(setters, endpoint.SetExpectedText(*req.ExpectedText))
+	}
+	if req.UseRunner != nil {
+		setters = append(setters, endpoint.SetUseRunner(*req.UseRunner))
+	}
+	if req.RunnerLabels != nil {
+		setters = append(setters, endpoint.SetRunnerLabels(*req.RunnerLabels))
+	}
 
 	if len(setters) == 0 {
 		respondError(w, http.StatusBadRequest, "no fields to update")
@@ -223,11 +378,14 @@ func (h *EndpointHandler) Update(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusNotFound, "endpoint not found")
 			return
 		}
-		if errors.Is(err, endpoint.ErrInvalidEndpointName) ||
-			errors.Is(err, endpoint.ErrInvalidEndpointURL) {
+		if isEndpointValidationError(err) {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if errors.Is(err, endpoint.ErrDuplicateEnvironment) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
 		h.logger.Error(r.Context(), "failed to update endpoint", map[string]interface{}{
 			"error":       err.Error(),
 			"endpoint_id": id,
@@ -275,3 +433,230 @@ func (h *EndpointHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	respondSuccess(w, "endpoint deleted successfully")
 }
+
+// EndpointHealthResponse reports the health of an endpoint.
+type EndpointHealthResponse struct {
+	EndpointID          uuid.UUID                        `json:"endpoint_id"`
+	Status              endpoint.HealthStatus            `json:"status"`
+	LastCheckedAt       *time.Time                       `json:"last_checked_at"`
+	HealthCheckEnabled  bool                             `json:"health_check_enabled"`
+	HealthCheckInterval int                              `json:"health_check_interval_seconds"`
+	Uptime              map[string]*endpoint.UptimeStats `json:"uptime"`
+	Recent              []*endpoint.HealthCheck          `json:"recent"`
+}
+
+// GetHealth handles GET /endpoints/{id}/health, returning the current status,
+// uptime over the last 24 hours, 7 days and 30 days, and the most recent checks.
+func (h *EndpointHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "endpoint")
+	if !ok {
+		return
+	}
+
+	if !h.checkEndpointOwnership(w, r, id) {
+		return
+	}
+
+	ep, err := h.endpointStore.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get endpoint", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get endpoint")
+		return
+	}
+
+	now := time.Now()
+	uptime := make(map[string]*endpoint.UptimeStats, len(uptimeWindows))
+	for _, window := range uptimeWindows {
+		stats, err := h.healthStore.Uptime(r.Context(), id, now.Add(-window.duration))
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to compute uptime")
+			return
+		}
+		uptime[window.name] = stats
+	}
+
+	recent, err := h.healthStore.ListRecent(r.Context(), id, recentHealthChecks)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list health checks")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, EndpointHealthResponse{
+		EndpointID:          ep.ID,
+		Status:              ep.HealthStatus,
+		LastCheckedAt:       ep.LastHealthCheckAt,
+		HealthCheckEnabled:  ep.HealthCheckEnabled,
+		HealthCheckInterval: ep.HealthCheckInterval,
+		Uptime:              uptime,
+		Recent:              recent,
+	})
+}
+
+// CheckHealth handles POST /endpoints/{id}/health/check, checking the endpoint
+// immediately whether or not periodic checks are enabled.
+func (h *EndpointHandler) CheckHealth(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "endpoint")
+	if !ok {
+		return
+	}
+
+	if !h.checkEndpointOwnership(w, r, id) {
+		return
+	}
+
+	ep, err := h.endpointStore.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get endpoint", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get endpoint")
+		return
+	}
+
+	check, err := h.monitor.Check(r.Context(), ep)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to record health check")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, check)
+}
+
+// ListSecrets handles GET /endpoints/{id}/secrets. Only keys and placeholders
+// are returned; secret values are never exposed through the API.
+func (h *EndpointHandler) ListSecrets(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "endpoint")
+	if !ok {
+		return
+	}
+
+	if !h.checkEndpointOwnership(w, r, id) {
+		return
+	}
+
+	secrets, err := h.secretStore.List(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list secrets")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": secrets,
+		"total": len(secrets),
+	})
+}
+
+// SetSecretRequest represents a request to set an endpoint secret.
+type SetSecretRequest struct {
+	Value string `json:"value"`
+}
+
+// SetSecret handles PUT /endpoints/{id}/secrets/{key}, creating the secret or
+// replacing its value.
+func (h *EndpointHandler) SetSecret(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "endpoint")
+	if !ok {
+		return
+	}
+
+	var req SetSecretRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if !h.checkEndpointOwnership(w, r, id) {
+		return
+	}
+
+	secret, err := h.secretStore.Set(r.Context(), id, mux.Vars(r)["key"], req.Value)
+	if err != nil {
+		if errors.Is(err, endpoint.ErrInvalidSecretKey) || errors.Is(err, endpoint.ErrInvalidSecretValue) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to set secret")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, secret)
+}
+
+// DeleteSecret handles DELETE /endpoints/{id}/secrets/{key}.
+func (h *EndpointHandler) DeleteSecret(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "endpoint")
+	if !ok {
+		return
+	}
+
+	if !h.checkEndpointOwnership(w, r, id) {
+		return
+	}
+
+	if err := h.secretStore.Delete(r.Context(), id, mux.Vars(r)["key"]); err != nil {
+		if errors.Is(err, endpoint.ErrSecretNotFound) {
+			respondError(w, http.StatusNotFound, "secret not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete secret")
+		return
+	}
+
+	respondSuccess(w, "secret deleted successfully")
+}
+
+// EndpointGroupEnvironment is one environment of an endpoint group.
+type EndpointGroupEnvironment struct {
+	Environment  string                `json:"environment"`
+	EndpointID   uuid.UUID             `json:"endpoint_id"`
+	Name         string                `json:"name"`
+	URL          string                `json:"url"`
+	HealthStatus endpoint.HealthStatus `json:"health_status"`
+}
+
+// EndpointGroup lists the environments of an endpoint group.
+type EndpointGroup struct {
+	Group        string                     `json:"group"`
+	Environments []EndpointGroupEnvironment `json:"environments"`
+}
+
+// ListGroups handles GET /endpoint-groups, returning the user's endpoint
+// groups with the endpoint serving each environment.
+func (h *EndpointHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	endpoints, err := h.endpointStore.ListGrouped(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list endpoint groups")
+		return
+	}
+
+	// Endpoints arrive ordered by group, so each group is contiguous.
+	groups := []*EndpointGroup{}
+	for _, ep := range endpoints {
+		if len(groups) == 0 || groups[len(groups)-1].Group != ep.Group {
+			groups = append(groups, &EndpointGroup{Group: ep.Group})
+		}
+		group := groups[len(groups)-1]
+		group.Environments = append(group.Environments, EndpointGroupEnvironment{
+			Environment:  ep.Environment,
+			EndpointID:   ep.ID,
+			Name:         ep.Name,
+			URL:          ep.URL,
+			HealthStatus: ep.HealthStatus,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": groups,
+		"total": len(groups),
+	})
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// GuestKey is the context key marking requests made by guests.
+const GuestKey ContextKey = "guest"
+
+// guestRoutes are the only routes guests may use, by path template and
+// method: their own account, the procedures shared with them and the runs
+// assigned to them. Project settings, integrations and tokens are left out.
+var guestRoutes = map[string][]string{
+	"/api/v1/auth/me":                                        {http.MethodGet},
+	"/api/v1/auth/sessions":                                  {http.MethodGet, http.MethodDelete},
+	"/api/v1/auth/sessions/{session_id}":                     {http.MethodDelete},
+	"/api/v1/users/{id}":                                     {http.MethodGet, http.MethodPut},
+	"/api/v1/notifications/preferences":                      {http.MethodGet, http.MethodPut},
+	"/api/v1/procedures/shared":                              {http.MethodGet},
+	"/api/v1/projects/{project_id}/procedures/{id}":          {http.MethodGet},
+	"/api/v1/projects/{project_id}/procedures/{id}/versions": {http.MethodGet},
+	"/api/v1/procedures/{id}/steps/{step_index}/attachments/{attachment_index}":        {http.MethodGet},
+	"/api/v1/procedures/{id}/steps/{step_index}/attachments/{attachment_index}/stream": {http.MethodGet},
+	"/api/v1/runs/queue":                                  {http.MethodGet},
+	"/api/v1/runs/{run_id}":                               {http.MethodGet, http.MethodPut},
+	"/api/v1/runs/{run_id}/start":                         {http.MethodPost},
+	"/api/v1/runs/{run_id}/complete":                      {http.MethodPost},
+	"/api/v1/runs/{run_id}/pause":                         {http.MethodPost},
+	"/api/v1/runs/{run_id}/resume":                        {http.MethodPost},
+	"/api/v1/runs/{run_id}/block":                         {http.MethodPost},
+	"/api/v1/runs/{run_id}/unblock":                       {http.MethodPost},
+	"/api/v1/runs/{run_id}/assets":                        {http.MethodGet, http.MethodPost},
+	"/api/v1/runs/{run_id}/assets/{asset_id}":             {http.MethodGet, http.MethodDelete},
+	"/api/v1/runs/{run_id}/assets/{asset_id}/annotations": {http.MethodGet, http.MethodPut},
+	"/api/v1/runs/{run_id}/procedure":                     {http.MethodGet},
+	"/api/v1/runs/{run_id}/steps/notes":                   {http.MethodGet},
+	"/api/v1/runs/{run_id}/steps/{step_index}/notes":      {http.MethodPut},
+	"/api/v1/runs/{run_id}/comments":                      {http.MethodGet, http.MethodPost},
+	"/api/v1/runs/{run_id}/comments/{comment_id}":         {http.MethodPut, http.MethodDelete},
+}
+
+// guestAllowed reports whether guests may use the request's route.
+func guestAllowed(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return false
+	}
+	for _, method := range guestRoutes[tpl] {
+		if r.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+// GuestMiddleware marks requests made by guests in the context and refuses
+// them every route but those of their account, the procedures shared with
+// them and the runs assigned to them. It must run after AuthMiddleware and
+// ServiceAccountMiddleware.
+type GuestMiddleware struct {
+	userStore user.Store
+	logger    logger.Logger
+}
+
+// NewGuestMiddleware creates a new guest middleware.
+func NewGuestMiddleware(userStore user.Store, log logger.Logger) *GuestMiddleware {
+	return &GuestMiddleware{
+		userStore: userStore,
+		logger:    log,
+	}
+}
+
+// Handler wraps an HTTP handler with the guest check.
+func (m *GuestMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := GetUserID(r.Context())
+		if _, isServiceAccount := GetServiceAccount(r.Context()); !ok || isServiceAccount {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		caller, err := m.userStore.GetByID(r.Context(), userID)
+		if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusInternalServerError, "failed to get user")
+			return
+		}
+		if err != nil || !caller.IsGuest() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !guestAllowed(r) {
+			m.logger.Warn(r.Context(), "guest access denied", map[string]interface{}{
+				"user_id": userID.String(),
+				"method":  r.Method,
+				"path":    r.URL.Path,
+			})
+			respondError(w, http.StatusForbidden, "not available to guests")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), GuestKey, true)))
+	})
+}
+
+// IsGuest reports whether the request is made by a guest.
+func IsGuest(ctx context.Context) bool {
+	guest, _ := ctx.Value(GuestKey).(bool)
+	return guest
+}
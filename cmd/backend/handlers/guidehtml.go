@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"html/template"
+	"io"
+	"strings"
+)
+
+// guidePage is the content of a guide's guide.html, the same guide as
+// guide.md with its images, videos and screen recordings shown in place.
+// The description, overview and entry texts are rendered from Markdown.
+type guidePage struct {
+	Title              string
+	Description        template.HTML
+	OverviewHeading    string
+	Overview           template.HTML
+	AttachmentsHeading string
+	Attachments        []guideMedia
+	Entries            []guideEntry
+}
+
+// guideMedia is a file in the guide's ZIP archive, shown as an image, a
+// video or a link.
+type guideMedia struct {
+	Name  string
+	Href  string
+	Image bool
+	Video bool
+}
+
+// guideEntry is one asset of the run, with its text.
+type guideEntry struct {
+	Heading string
+	Media   guideMedia
+	Text    template.HTML
+}
+
+// newGuideMedia describes the file at href, named name, shown according to
+// its content type.
+func newGuideMedia(name, href, contentType string) guideMedia {
+	return guideMedia{
+		Name:  name,
+		Href:  href,
+		Image: strings.HasPrefix(contentType, "image/"),
+		Video: strings.HasPrefix(contentType, "video/"),
+	}
+}
+
+// guideTemplate renders a guidePage.
+var guideTemplate = template.Must(template.New("guide").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; }
+pre { background: #f5f5f5; padding: 0.5em; overflow-x: auto; }
+img, video { max-width: 100%; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{.Description}}<h2>{{.OverviewHeading}}</h2>
+{{.Overview}}{{if .Attachments}}<h2>{{.AttachmentsHeading}}</h2>
+<ul>
+{{range .Attachments}}<li>{{template "media" .}}</li>
+{{end}}</ul>
+{{end}}<hr>
+{{range .Entries}}<h2>{{.Heading}}</h2>
+{{template "media" .Media}}
+{{.Text}}<hr>
+{{end}}</body>
+</html>
+{{define "media"}}{{if .Image}}<figure><img src="{{.Href}}" alt="{{.Name}}"><figcaption>{{.Name}}</figcaption></figure>{{else if .Video}}<figure><video src="{{.Href}}" controls></video><figcaption><a href="{{.Href}}">{{.Name}}</a></figcaption></figure>{{else}}<a href="{{.Href}}">{{.Name}}</a>{{end}}{{end}}
+`))
+
+// writeGuideHTML renders page as guide.html.
+func writeGuideHTML(w io.Writer, page guidePage) error {
+	return guideTemplate.Execute(w, page)
+}
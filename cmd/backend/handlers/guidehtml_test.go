@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteGuideHTML(t *testing.T) {
+	t.Parallel()
+
+	page := guidePage{
+		Title:              "Log in <script>",
+		Description:        exportHTML("# Setup\n\nLog in as **admin** <script>alert(1)</script>", 1),
+		OverviewHeading:    "Overview",
+		Overview:           exportHTML("Passed\nwithout issues", 2),
+		AttachmentsHeading: "Attachments",
+		Attachments: []guideMedia{
+			newGuideMedia("login.webm", "./attachments/step1_1_login.webm", "video/webm"),
+			newGuideMedia("users.csv", "./attachments/step2_1_users.csv", ""),
+		},
+		Entries: []guideEntry{
+			{Heading: "Step 1", Media: newGuideMedia("form.png", "./assets/1_form.png", "image/png"), Text: exportHTML("Fill in the form", 2)},
+		},
+	}
+
+	var out strings.Builder
+	if err := writeGuideHTML(&out, page); err != nil {
+		t.Fatalf("writeGuideHTML() error = %v", err)
+	}
+	html := out.String()
+
+	for _, want := range []string{
+		"<h1>Log in &lt;script&gt;</h1>",
+		`<video src="./attachments/step1_1_login.webm" controls>`,
+		`<a href="./attachments/step2_1_users.csv">users.csv</a>`,
+		`<img src="./assets/1_form.png" alt="form.png">`,
+		"<h2>Setup</h2>",
+		"<p>Log in as <strong>admin</strong> &lt;script&gt;alert(1)&lt;/script&gt;</p>",
+		"<h2>Step 1</h2>",
+		"<p>Passed\nwithout issues</p>",
+		"<p>Fill in the form</p>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("guide.html does not contain %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("guide.html contains unescaped text")
+	}
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
 )
 
 // HealthResponse represents the health check response.
@@ -13,3 +15,25 @@ type HealthResponse struct {
 func HealthHandler(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, HealthResponse{Status: "healthy"})
 }
+
+// ProviderHealthHandler reports circuit breaker state for external providers
+// such as the LLM and the Playwright MCP server.
+type ProviderHealthHandler struct {
+	breakers *resilience.Registry
+}
+
+// NewProviderHealthHandler creates a new provider health handler.
+func NewProviderHealthHandler(breakers *resilience.Registry) *ProviderHealthHandler {
+	return &ProviderHealthHandler{
+		breakers: breakers,
+	}
+}
+
+// List handles GET /health/providers.
+func (h *ProviderHealthHandler) List(w http.ResponseWriter, r *http.Request) {
+	statuses := h.breakers.Statuses()
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": statuses,
+		"total": len(statuses),
+	})
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// HTTPSRedirect redirects plain HTTP requests to the same URL over HTTPS on
+// httpsPort. The redirect is permanent and keeps the method, so clients
+// that followed it once go straight to HTTPS.
+func HTTPSRedirect(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if host == "" {
+			respondError(w, http.StatusBadRequest, "missing host")
+			return
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		} else if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+			host = "[" + host + "]"
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		httpsPort int
+		host      string
+		target    string
+		want      string
+	}{
+		{
+			name:      "default port",
+			httpsPort: 443,
+			host:      "qa.example.com",
+			target:    "/api/v1/projects?limit=5",
+			want:      "https://qa.example.com/api/v1/projects?limit=5",
+		},
+		{
+			name:      "host port is replaced",
+			httpsPort: 8443,
+			host:      "qa.example.com:8080",
+			target:    "/health",
+			want:      "https://qa.example.com:8443/health",
+		},
+		{
+			name:      "IPv6 host",
+			httpsPort: 443,
+			host:      "[2001:db8::1]:80",
+			target:    "/",
+			want:      "https://[2001:db8::1]/",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, tc.target, nil)
+			req.Host = tc.host
+			w := httptest.NewRecorder()
+
+			HTTPSRedirect(tc.httpsPort).ServeHTTP(w, req)
+
+			if w.Code != http.StatusPermanentRedirect {
+				t.Errorf("status code = %d, want %d", w.Code, http.StatusPermanentRedirect)
+			}
+			if got := w.Header().Get("Location"); got != tc.want {
+				t.Errorf("Location = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
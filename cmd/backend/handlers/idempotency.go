@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/idempotency"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+const (
+	// IdempotencyKeyHeader is the header clients send a key in to make a
+	// create request safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses replayed for a retry.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotentBodySize caps the request bodies read to hash a request.
+	maxIdempotentBodySize = 1 << 20
+)
+
+// Idempotency makes create requests sent with an Idempotency-Key header safe
+// to retry. The first request with a key is carried out and, if it
+// succeeds, its response is kept for the TTL; retries with the same key
+// get that response back, marked with Idempotent-Replayed, instead of
+// creating a duplicate. Failed requests release their key so they can be
+// retried. Keys belong to the caller, per API token or per user.
+type Idempotency struct {
+	store  idempotency.Store
+	ttl    time.Duration
+	logger logger.Logger
+}
+
+// NewIdempotency creates a new idempotency middleware keeping responses in
+// store for ttl.
+func NewIdempotency(store idempotency.Store, ttl time.Duration, log logger.Logger) *Idempotency {
+	if ttl <= 0 {
+		ttl = idempotency.DefaultTTL
+	}
+	return &Idempotency{
+		store:  store,
+		ttl:    ttl,
+		logger: log,
+	}
+}
+
+// Handler wraps a create handler. Requests without the header are passed
+// through. It must run after AuthMiddleware so the caller is known.
+func (i *Idempotency) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := idempotency.ValidateKey(key); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		scope, ok := rateLimitKey(r)
+		if !ok {
+			respondError(w, http.StatusUnauthorized, "user not authenticated")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			respondError(w, http.StatusRequestEntityTooLarge, "request body too large for Idempotency-Key")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := &idempotency.Record{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: idempotency.HashRequest(r.Method, r.URL.RequestURI(), body),
+			ExpiresAt:   time.Now().Add(i.ttl),
+		}
+		if err := i.store.Reserve(r.Context(), record); err != nil {
+			if errors.Is(err, idempotency.ErrKeyInUse) {
+				i.replay(w, r, record)
+				return
+			}
+			respondError(w, http.StatusInternalServerError, "failed to reserve idempotency key")
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// The request may outlive a client that gave up on it; keep its
+		// response for the retry regardless.
+		ctx := context.WithoutCancel(r.Context())
+		if rec.status >= 200 && rec.status < 300 {
+			err = i.store.Complete(ctx, record.ID, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes())
+		} else {
+			err = i.store.Delete(ctx, record.ID)
+		}
+		if err != nil {
+			i.logger.Warn(ctx, "failed to save idempotent response", map[string]interface{}{
+				"error": err.Error(),
+				"scope": scope,
+				"path":  r.URL.Path,
+			})
+		}
+	})
+}
+
+// replay answers a request whose key is held by another request: with the
+// kept response if it was the same request and has completed.
+func (i *Idempotency) replay(w http.ResponseWriter, r *http.Request, record *idempotency.Record) {
+	held, err := i.store.Get(r.Context(), record.Scope, record.Key)
+	if err != nil {
+		if errors.Is(err, idempotency.ErrRecordNotFound) {
+			// Released or expired since the reservation failed.
+			respondError(w, http.StatusConflict, "a request with this Idempotency-Key is in progress, retry it")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get idempotent response")
+		return
+	}
+	if held.RequestHash != record.RequestHash {
+		respondError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+		return
+	}
+	if !held.Completed() {
+		respondError(w, http.StatusConflict, "a request with this Idempotency-Key is in progress, retry it")
+		return
+	}
+
+	if held.ContentType != "" {
+		w.Header().Set("Content-Type", held.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(held.StatusCode)
+	_, _ = w.Write(held.Body)
+}
+
+// responseRecorder passes a response through while keeping its status and
+// body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/idempotency"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+)
+
+func TestIdempotency(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &idempotency.Record{})
+	log := logger.NewTestLogger()
+	middleware := NewIdempotency(idempotency.NewMySQLStore(db, log), time.Hour, log)
+
+	created := 0
+	failNext := false
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failNext {
+			failNext = false
+			respondError(w, http.StatusInternalServerError, "failed to create")
+			return
+		}
+		created++
+		respondJSON(w, http.StatusCreated, map[string]int{"n": created})
+	}))
+
+	userID := uuid.New()
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("retry replays the response", func(t *testing.T) {
+		first := send("retry", `{"type":"a"}`)
+		second := send("retry", `{"type":"a"}`)
+
+		if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+			t.Fatalf("status codes = %d, %d, want 201 twice", first.Code, second.Code)
+		}
+		if second.Body.String() != first.Body.String() {
+			t.Errorf("replayed body = %q, want %q", second.Body.String(), first.Body.String())
+		}
+		if second.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Error("replayed response is not marked as replayed")
+		}
+		if second.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("replayed Content-Type = %q, want application/json", second.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("key reused for another request returns 422", func(t *testing.T) {
+		send("reused", `{"type":"a"}`)
+		if w := send("reused", `{"type":"b"}`); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status code = %d, want 422", w.Code)
+		}
+	})
+
+	t.Run("failed request releases its key", func(t *testing.T) {
+		failNext = true
+		if w := send("failed", `{}`); w.Code != http.StatusInternalServerError {
+			t.Fatalf("status code = %d, want 500", w.Code)
+		}
+		w := send("failed", `{}`)
+		if w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("retry after failure: status code = %d, replayed = %q, want a fresh 201", w.Code, w.Header().Get(IdempotentReplayedHeader))
+		}
+	})
+
+	t.Run("requests without a key are not deduplicated", func(t *testing.T) {
+		before := created
+		send("", `{}`)
+		send("", `{}`)
+		if created != before+2 {
+			t.Errorf("created %d, want 2", created-before)
+		}
+	})
+
+	t.Run("overlong key returns 400", func(t *testing.T) {
+		if w := send(strings.Repeat("k", idempotency.MaxKeyLength+1), `{}`); w.Code != http.StatusBadRequest {
+			t.Errorf("status code = %d, want 400", w.Code)
+		}
+	})
+}
@@ -1,47 +1,82 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/activity"
 	"github.com/hairizuanbinnoorazman/ui-automation/integration"
 	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
 	"github.com/hairizuanbinnoorazman/ui-automation/project"
-	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
-	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
 )
 
 // IntegrationHandler handles integration and issue link requests.
 type IntegrationHandler struct {
-	integrationStore   integration.Store
-	clientFactory      issuetracker.ClientFactory
-	encryptionKey      []byte
-	testRunStore       testrun.Store
-	testProcedureStore testprocedure.Store
-	projectStore       project.Store
-	logger             logger.Logger
+	integrationStore integration.Store
+	clientFactory    issuetracker.ClientFactory
+	webhookParser    issuetracker.WebhookParser
+	breakers         *resilience.Registry
+	checker          *integration.Checker
+	keyring          *integration.Keyring
+	projectStore     project.Store
+	activity         *activity.Recorder
+	logger           logger.Logger
 }
 
-// NewIntegrationHandler creates a new integration handler.
+// NewIntegrationHandler creates a new integration handler. The checker
+// decides the credential status reported for integrations. Filing issues is
+// recorded as activity.
 func NewIntegrationHandler(
 	integrationStore integration.Store,
 	clientFactory issuetracker.ClientFactory,
-	encryptionKey []byte,
-	testRunStore testrun.Store,
-	testProcedureStore testprocedure.Store,
+	webhookParser issuetracker.WebhookParser,
+	breakers *resilience.Registry,
+	checker *integration.Checker,
+	keyring *integration.Keyring,
 	projectStore project.Store,
+	activityRecorder *activity.Recorder,
 	log logger.Logger,
 ) *IntegrationHandler {
 	return &IntegrationHandler{
-		integrationStore:   integrationStore,
-		clientFactory:      clientFactory,
-		encryptionKey:      encryptionKey,
-		testRunStore:       testRunStore,
-		testProcedureStore: testProcedureStore,
-		projectStore:       projectStore,
-		logger:             log,
+		integrationStore: integrationStore,
+		clientFactory:    clientFactory,
+		webhookParser:    webhookParser,
+		breakers:         breakers,
+		checker:          checker,
+		keyring:          keyring,
+		projectStore:     projectStore,
+		activity:         activityRecorder,
+		logger:           log,
+	}
+}
+
+// newClient builds an issue tracker client for the integration whose calls run
+// under that integration's circuit breaker.
+func (h *IntegrationHandler) newClient(integ *integration.Integration, creds map[string]string) (issuetracker.Client, error) {
+	client, err := h.clientFactory.NewClient(integ.Provider, creds)
+	if err != nil {
+		return nil, err
+	}
+	return issuetracker.NewResilientClient(client, h.breakers.Get(integ.ID.String())), nil
+}
+
+// respondTrackerError writes the response for a failed issue tracker call,
+// distinguishing an open circuit or an expired deadline from other failures.
+func respondTrackerError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, resilience.ErrCircuitOpen):
+		respondError(w, http.StatusServiceUnavailable, "issue tracker temporarily unavailable")
+	case errors.Is(err, context.DeadlineExceeded):
+		respondError(w, http.StatusGatewayTimeout, "issue tracker request timed out")
+	default:
+		respondError(w, http.StatusInternalServerError, message)
 	}
 }
 
@@ -71,51 +106,21 @@ func (h *IntegrationHandler) checkIntegrationOwnership(w http.ResponseWriter, r
 	return integ, true
 }
 
-// checkRunOwnership verifies that the authenticated user owns the project
-// associated with the given test run via test run -> procedure -> project -> owner.
-func (h *IntegrationHandler) checkRunOwnership(w http.ResponseWriter, r *http.Request, runID uuid.UUID) bool {
-	userID, ok := GetUserID(r.Context())
-	if !ok {
-		respondError(w, http.StatusUnauthorized, "user not authenticated")
-		return false
-	}
-
-	tr, err := h.testRunStore.GetByID(r.Context(), runID)
-	if err != nil {
-		if errors.Is(err, testrun.ErrTestRunNotFound) {
-			respondError(w, http.StatusNotFound, "test run not found")
-			return false
-		}
-		respondError(w, http.StatusInternalServerError, "failed to verify test run")
-		return false
-	}
-
-	tp, err := h.testProcedureStore.GetByID(r.Context(), tr.TestProcedureID)
-	if err != nil {
-		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
-			respondError(w, http.StatusNotFound, "test procedure not found")
-			return false
-		}
-		respondError(w, http.StatusInternalServerError, "failed to verify test procedure")
-		return false
-	}
-
-	proj, err := h.projectStore.GetByID(r.Context(), tp.ProjectID)
+// runProject returns the project of the test run in the URL, whose access
+// RunAuthorizationMiddleware already checked. Returns false if it cannot be
+// loaded (response already written).
+func (h *IntegrationHandler) runProject(w http.ResponseWriter, r *http.Request) (*project.Project, bool) {
+	owner, _ := GetRunOwner(r.Context())
+	proj, err := h.projectStore.GetByID(r.Context(), owner.ProjectID)
 	if err != nil {
 		if errors.Is(err, project.ErrProjectNotFound) {
 			respondError(w, http.StatusNotFound, "project not found")
-			return false
+			return nil, false
 		}
-		respondError(w, http.StatusInternalServerError, "failed to verify project")
-		return false
-	}
-
-	if proj.OwnerID != userID {
-		respondError(w, http.StatusForbidden, "access denied")
-		return false
+		respondError(w, http.StatusInternalServerError, "failed to get project")
+		return nil, false
 	}
-
-	return true
+	return proj, true
 }
 
 // credentialEntry represents a single credential key-value pair from the frontend.
@@ -129,6 +134,9 @@ type CreateIntegrationRequest struct {
 	Name        string                    `json:"name"`
 	Provider    issuetracker.ProviderType `json:"provider"`
 	Credentials []credentialEntry         `json:"credentials"`
+	// CredentialsExpireAt is when the token in the credentials expires, so
+	// the owner can be warned before it does.
+	CredentialsExpireAt *time.Time `json:"credentials_expire_at,omitempty"`
 }
 
 // toMap converts a credential entry list to a map.
@@ -142,9 +150,11 @@ func credentialsToMap(entries []credentialEntry) map[string]string {
 
 // UpdateIntegrationRequest represents the request body for updating an integration.
 type UpdateIntegrationRequest struct {
-	Name        *string           `json:"name,omitempty"`
-	IsActive    *bool             `json:"is_active,omitempty"`
-	Credentials []credentialEntry `json:"credentials,omitempty"`
+	Name                     *string           `json:"name,omitempty"`
+	IsActive                 *bool             `json:"is_active,omitempty"`
+	Credentials              []credentialEntry `json:"credentials,omitempty"`
+	CredentialsExpireAt      *time.Time        `json:"credentials_expire_at,omitempty"`
+	ClearCredentialsExpireAt bool              `json:"clear_credentials_expire_at,omitempty"`
 }
 
 // CreateAndLinkIssueRequest represents the request body for creating and linking an issue.
@@ -176,28 +186,62 @@ type SearchExternalIssuesRequest struct {
 	Repository string `json:"repository"`
 	Status     string `json:"status"`
 	Query      string `json:"query"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Cursor     string `json:"cursor"`
+}
+
+// ExternalIssuePage represents a page of issues from an issue tracker. Total
+// is null for trackers that do not report it; has_more tells whether to ask
+// for the next page, with next_cursor when the tracker pages by cursor.
+type ExternalIssuePage struct {
+	Items      []*issuetracker.Issue `json:"items"`
+	Total      *int                  `json:"total"`
+	Limit      int                   `json:"limit"`
+	Offset     int                   `json:"offset"`
+	HasMore    bool                  `json:"has_more"`
+	NextCursor string                `json:"next_cursor,omitempty"`
 }
 
 // IntegrationResponse represents an integration in API responses (without encrypted credentials).
 type IntegrationResponse struct {
-	ID        uuid.UUID                 `json:"id"`
-	UserID    uuid.UUID                 `json:"user_id"`
-	Name      string                    `json:"name"`
-	Provider  issuetracker.ProviderType `json:"provider"`
-	IsActive  bool                      `json:"is_active"`
-	CreatedAt string                    `json:"created_at"`
-	UpdatedAt string                    `json:"updated_at"`
+	ID                   uuid.UUID                    `json:"id"`
+	UserID               uuid.UUID                    `json:"user_id"`
+	Name                 string                       `json:"name"`
+	Provider             issuetracker.ProviderType    `json:"provider"`
+	IsActive             bool                         `json:"is_active"`
+	CredentialStatus     integration.CredentialStatus `json:"credential_status"`
+	CredentialsUpdatedAt string                       `json:"credentials_updated_at"`
+	CredentialsExpireAt  string                       `json:"credentials_expire_at,omitempty"`
+	LastCheckedAt        string                       `json:"last_checked_at,omitempty"`
+	LastCheckError       string                       `json:"last_check_error,omitempty"`
+	CreatedAt            string                       `json:"created_at"`
+	UpdatedAt            string                       `json:"updated_at"`
+}
+
+// formatOptionalTime formats t like the other integration timestamps, or
+// returns "" if it is not set.
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02T15:04:05Z")
 }
 
-func toIntegrationResponse(integ *integration.Integration) IntegrationResponse {
+func (h *IntegrationHandler) toIntegrationResponse(integ *integration.Integration) IntegrationResponse {
 	return IntegrationResponse{
-		ID:        integ.ID,
-		UserID:    integ.UserID,
-		Name:      integ.Name,
-		Provider:  integ.Provider,
-		IsActive:  integ.IsActive,
-		CreatedAt: integ.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: integ.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		ID:                   integ.ID,
+		UserID:               integ.UserID,
+		Name:                 integ.Name,
+		Provider:             integ.Provider,
+		IsActive:             integ.IsActive,
+		CredentialStatus:     integ.CredentialStatusAt(time.Now(), h.checker.WarnWithin()),
+		CredentialsUpdatedAt: integ.CredentialsUpdatedAt.Format("2006-01-02T15:04:05Z"),
+		CredentialsExpireAt:  formatOptionalTime(integ.CredentialsExpireAt),
+		LastCheckedAt:        formatOptionalTime(integ.LastCheckedAt),
+		LastCheckError:       integ.LastCheckError,
+		CreatedAt:            integ.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:            integ.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 }
 
@@ -221,7 +265,7 @@ func (h *IntegrationHandler) ListIntegrations(w http.ResponseWriter, r *http.Req
 
 	result := make([]IntegrationResponse, len(integrations))
 	for i, integ := range integrations {
-		result[i] = toIntegrationResponse(integ)
+		result[i] = h.toIntegrationResponse(integ)
 	}
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
@@ -259,7 +303,7 @@ func (h *IntegrationHandler) CreateIntegration(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	encrypted, err := integration.EncryptCredentials(h.encryptionKey, credentialsToMap(req.Credentials))
+	encrypted, err := h.keyring.Encrypt(credentialsToMap(req.Credentials))
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to encrypt credentials", map[string]interface{}{
 			"error": err.Error(),
@@ -274,6 +318,7 @@ func (h *IntegrationHandler) CreateIntegration(w http.ResponseWriter, r *http.Re
 		Provider:             req.Provider,
 		EncryptedCredentials: encrypted,
 		IsActive:             true,
+		CredentialsExpireAt:  req.CredentialsExpireAt,
 	}
 
 	if err := h.integrationStore.CreateIntegration(r.Context(), integ); err != nil {
@@ -284,7 +329,7 @@ func (h *IntegrationHandler) CreateIntegration(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, toIntegrationResponse(integ))
+	respondJSON(w, http.StatusCreated, h.toIntegrationResponse(integ))
 }
 
 // GetIntegration handles GET /integrations/{integration_id}.
@@ -299,7 +344,27 @@ func (h *IntegrationHandler) GetIntegration(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	respondJSON(w, http.StatusOK, toIntegrationResponse(integ))
+	respondJSON(w, http.StatusOK, h.toIntegrationResponse(integ))
+}
+
+// GetIntegrationHealth handles GET /integrations/{integration_id}/health.
+// It reports the circuit breaker state for calls to the integration's tracker.
+func (h *IntegrationHandler) GetIntegrationHealth(w http.ResponseWriter, r *http.Request) {
+	integrationID, ok := parseUUIDOrRespond(w, r, "integration_id", "integration")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.checkIntegrationOwnership(w, r, integrationID); !ok {
+		return
+	}
+
+	status := resilience.Status{Name: integrationID.String(), State: resilience.StateClosed}
+	if b, ok := h.breakers.Lookup(integrationID.String()); ok {
+		status = b.Status()
+	}
+
+	respondJSON(w, http.StatusOK, status)
 }
 
 // UpdateIntegration handles PUT /integrations/{integration_id}.
@@ -330,7 +395,7 @@ func (h *IntegrationHandler) UpdateIntegration(w http.ResponseWriter, r *http.Re
 	}
 
 	if len(req.Credentials) > 0 {
-		encrypted, err := integration.EncryptCredentials(h.encryptionKey, credentialsToMap(req.Credentials))
+		encrypted, err := h.keyring.Encrypt(credentialsToMap(req.Credentials))
 		if err != nil {
 			h.logger.Error(r.Context(), "failed to encrypt credentials", map[string]interface{}{
 				"error": err.Error(),
@@ -341,6 +406,12 @@ func (h *IntegrationHandler) UpdateIntegration(w http.ResponseWriter, r *http.Re
 		setters = append(setters, integration.SetEncryptedCredentials(encrypted))
 	}
 
+	if req.ClearCredentialsExpireAt {
+		setters = append(setters, integration.SetCredentialsExpireAt(nil))
+	} else if req.CredentialsExpireAt != nil {
+		setters = append(setters, integration.SetCredentialsExpireAt(req.CredentialsExpireAt))
+	}
+
 	if len(setters) == 0 {
 		respondError(w, http.StatusBadRequest, "no fields to update")
 		return
@@ -361,7 +432,7 @@ func (h *IntegrationHandler) UpdateIntegration(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	respondJSON(w, http.StatusOK, toIntegrationResponse(updated))
+	respondJSON(w, http.StatusOK, h.toIntegrationResponse(updated))
 }
 
 // DeleteIntegration handles DELETE /integrations/{integration_id}.
@@ -399,7 +470,7 @@ func (h *IntegrationHandler) TestConnection(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	creds, err := integration.DecryptCredentials(h.encryptionKey, integ.EncryptedCredentials)
+	creds, err := h.keyring.Decrypt(integ.EncryptedCredentials)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to decrypt credentials", map[string]interface{}{
 			"error":          err.Error(),
@@ -409,7 +480,7 @@ func (h *IntegrationHandler) TestConnection(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	client, err := h.clientFactory.NewClient(integ.Provider, creds)
+	client, err := h.newClient(integ, creds)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to create issue tracker client", map[string]interface{}{
 			"error":    err.Error(),
@@ -419,7 +490,16 @@ func (h *IntegrationHandler) TestConnection(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if err := client.ValidateConnection(r.Context()); err != nil {
+	err = client.ValidateConnection(r.Context())
+	if !integration.IsUnreachable(err) {
+		if err := h.integrationStore.UpdateIntegration(r.Context(), integrationID, integration.SetCheckResult(time.Now(), err)); err != nil {
+			h.logger.Warn(r.Context(), "failed to record credential check", map[string]interface{}{
+				"error":          err.Error(),
+				"integration_id": integrationID.String(),
+			})
+		}
+	}
+	if err != nil {
 		respondJSON(w, http.StatusOK, map[string]interface{}{
 			"success": false,
 			"message": err.Error(),
@@ -433,6 +513,56 @@ func (h *IntegrationHandler) TestConnection(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// fetchIssue looks up an issue in the external tracker of an integration the
+// authenticated user owns. Returns false if the lookup fails (response
+// already written).
+func (h *IntegrationHandler) fetchIssue(w http.ResponseWriter, r *http.Request, integrationIDStr, externalID string) (*integration.Integration, *issuetracker.Issue, bool) {
+	integrationID, err := uuid.Parse(integrationIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid integration_id")
+		return nil, nil, false
+	}
+
+	integ, ok := h.checkIntegrationOwnership(w, r, integrationID)
+	if !ok {
+		return nil, nil, false
+	}
+
+	creds, err := h.keyring.Decrypt(integ.EncryptedCredentials)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to decrypt credentials", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to decrypt credentials")
+		return nil, nil, false
+	}
+
+	client, err := h.newClient(integ, creds)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to create issue tracker client", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create client")
+		return nil, nil, false
+	}
+
+	issue, err := client.GetIssue(r.Context(), externalID)
+	if err != nil {
+		if errors.Is(err, issuetracker.ErrIssueNotFound) {
+			respondError(w, http.StatusNotFound, "issue not found in external tracker")
+			return nil, nil, false
+		}
+		h.logger.Error(r.Context(), "failed to get issue from external tracker", map[string]interface{}{
+			"error":       err.Error(),
+			"external_id": externalID,
+		})
+		respondTrackerError(w, err, "failed to get issue from external tracker")
+		return nil, nil, false
+	}
+
+	return integ, issue, true
+}
+
 // ListIssueLinks handles GET /runs/{run_id}/issues.
 func (h *IntegrationHandler) ListIssueLinks(w http.ResponseWriter, r *http.Request) {
 	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
@@ -440,10 +570,6 @@ func (h *IntegrationHandler) ListIssueLinks(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if !h.checkRunOwnership(w, r, runID) {
-		return
-	}
-
 	links, err := h.integrationStore.ListIssueLinksByTestRun(r.Context(), runID)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to list issue links", map[string]interface{}{
@@ -464,7 +590,8 @@ func (h *IntegrationHandler) CreateAndLinkIssue(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if !h.checkRunOwnership(w, r, runID) {
+	proj, ok := h.runProject(w, r)
+	if !ok {
 		return
 	}
 
@@ -474,18 +601,34 @@ func (h *IntegrationHandler) CreateAndLinkIssue(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	integrationID, err := uuid.Parse(req.IntegrationID)
-	if err != nil {
-		respondError(w, http.StatusBadRequest, "invalid integration_id")
+	// Fields left out fall back to the project's issue defaults.
+	var integrationID uuid.UUID
+	if req.IntegrationID != "" {
+		var err error
+		integrationID, err = uuid.Parse(req.IntegrationID)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid integration_id")
+			return
+		}
+	} else if proj.DefaultIntegrationID != nil {
+		integrationID = *proj.DefaultIntegrationID
+	} else {
+		respondError(w, http.StatusBadRequest, "integration_id is required when the project has no default integration")
 		return
 	}
+	if req.ProjectKey == "" {
+		req.ProjectKey = proj.DefaultIssueProjectKey
+	}
+	if req.Repository == "" {
+		req.Repository = proj.DefaultIssueRepository
+	}
 
 	integ, ok := h.checkIntegrationOwnership(w, r, integrationID)
 	if !ok {
 		return
 	}
 
-	creds, err := integration.DecryptCredentials(h.encryptionKey, integ.EncryptedCredentials)
+	creds, err := h.keyring.Decrypt(integ.EncryptedCredentials)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to decrypt credentials", map[string]interface{}{
 			"error": err.Error(),
@@ -494,7 +637,7 @@ func (h *IntegrationHandler) CreateAndLinkIssue(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	client, err := h.clientFactory.NewClient(integ.Provider, creds)
+	client, err := h.newClient(integ, creds)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to create issue tracker client", map[string]interface{}{
 			"error": err.Error(),
@@ -515,7 +658,7 @@ func (h *IntegrationHandler) CreateAndLinkIssue(w http.ResponseWriter, r *http.R
 		h.logger.Error(r.Context(), "failed to create issue", map[string]interface{}{
 			"error": err.Error(),
 		})
-		respondError(w, http.StatusInternalServerError, "failed to create issue in external tracker")
+		respondTrackerError(w, err, "failed to create issue in external tracker")
 		return
 	}
 
@@ -536,6 +679,8 @@ func (h *IntegrationHandler) CreateAndLinkIssue(w http.ResponseWriter, r *http.R
 		respondError(w, http.StatusInternalServerError, "failed to link issue")
 		return
 	}
+	userID, _ := GetUserID(r.Context())
+	h.activity.Record(r.Context(), activity.IssueFiledEvent(userID, proj.ID, runID, issue.Title))
 
 	respondJSON(w, http.StatusCreated, link)
 }
@@ -547,10 +692,6 @@ func (h *IntegrationHandler) LinkExistingIssue(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if !h.checkRunOwnership(w, r, runID) {
-		return
-	}
-
 	var req LinkExistingIssueRequest
 	if err := parseJSON(r, &req, h.logger); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid request body")
@@ -562,52 +703,14 @@ func (h *IntegrationHandler) LinkExistingIssue(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	integrationID, err := uuid.Parse(req.IntegrationID)
-	if err != nil {
-		respondError(w, http.StatusBadRequest, "invalid integration_id")
-		return
-	}
-
-	integ, ok := h.checkIntegrationOwnership(w, r, integrationID)
+	integ, issue, ok := h.fetchIssue(w, r, req.IntegrationID, req.ExternalID)
 	if !ok {
 		return
 	}
 
-	creds, err := integration.DecryptCredentials(h.encryptionKey, integ.EncryptedCredentials)
-	if err != nil {
-		h.logger.Error(r.Context(), "failed to decrypt credentials", map[string]interface{}{
-			"error": err.Error(),
-		})
-		respondError(w, http.StatusInternalServerError, "failed to decrypt credentials")
-		return
-	}
-
-	client, err := h.clientFactory.NewClient(integ.Provider, creds)
-	if err != nil {
-		h.logger.Error(r.Context(), "failed to create issue tracker client", map[string]interface{}{
-			"error": err.Error(),
-		})
-		respondError(w, http.StatusInternalServerError, "failed to create client")
-		return
-	}
-
-	issue, err := client.GetIssue(r.Context(), req.ExternalID)
-	if err != nil {
-		if errors.Is(err, issuetracker.ErrIssueNotFound) {
-			respondError(w, http.StatusNotFound, "issue not found in external tracker")
-			return
-		}
-		h.logger.Error(r.Context(), "failed to get issue from external tracker", map[string]interface{}{
-			"error":       err.Error(),
-			"external_id": req.ExternalID,
-		})
-		respondError(w, http.StatusInternalServerError, "failed to get issue from external tracker")
-		return
-	}
-
 	link := &integration.IssueLink{
 		TestRunID:     runID,
-		IntegrationID: integrationID,
+		IntegrationID: integ.ID,
 		ExternalID:    issue.ExternalID,
 		Title:         issue.Title,
 		Status:        issue.Status,
@@ -626,30 +729,50 @@ func (h *IntegrationHandler) LinkExistingIssue(w http.ResponseWriter, r *http.Re
 	respondJSON(w, http.StatusCreated, link)
 }
 
-// UnlinkIssue handles DELETE /runs/{run_id}/issues/{link_id}.
-func (h *IntegrationHandler) UnlinkIssue(w http.ResponseWriter, r *http.Request) {
+// runIssueLink loads the issue link in the URL, which must belong to the
+// test run in the URL. Returns false if it cannot be loaded (response
+// already written).
+func (h *IntegrationHandler) runIssueLink(w http.ResponseWriter, r *http.Request) (*integration.IssueLink, bool) {
 	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
 	if !ok {
-		return
+		return nil, false
+	}
+	linkID, ok := parseUUIDOrRespond(w, r, "link_id", "issue link")
+	if !ok {
+		return nil, false
 	}
 
-	if !h.checkRunOwnership(w, r, runID) {
-		return
+	link, err := h.integrationStore.GetIssueLinkByID(r.Context(), linkID)
+	if err != nil {
+		if errors.Is(err, integration.ErrIssueLinkNotFound) {
+			respondError(w, http.StatusNotFound, "issue link not found")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get issue link")
+		return nil, false
+	}
+	if link.TestRunID != runID {
+		respondError(w, http.StatusNotFound, "issue link not found")
+		return nil, false
 	}
+	return link, true
+}
 
-	linkID, ok := parseUUIDOrRespond(w, r, "link_id", "issue link")
+// UnlinkIssue handles DELETE /runs/{run_id}/issues/{link_id}.
+func (h *IntegrationHandler) UnlinkIssue(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.runIssueLink(w, r)
 	if !ok {
 		return
 	}
 
-	if err := h.integrationStore.DeleteIssueLink(r.Context(), linkID); err != nil {
+	if err := h.integrationStore.DeleteIssueLink(r.Context(), link.ID); err != nil {
 		if errors.Is(err, integration.ErrIssueLinkNotFound) {
 			respondError(w, http.StatusNotFound, "issue link not found")
 			return
 		}
 		h.logger.Error(r.Context(), "failed to delete issue link", map[string]interface{}{
 			"error":         err.Error(),
-			"issue_link_id": linkID.String(),
+			"issue_link_id": link.ID.String(),
 		})
 		respondError(w, http.StatusInternalServerError, "failed to unlink issue")
 		return
@@ -660,30 +783,11 @@ func (h *IntegrationHandler) UnlinkIssue(w http.ResponseWriter, r *http.Request)
 
 // ResolveLinkedIssue handles POST /runs/{run_id}/issues/{link_id}/resolve.
 func (h *IntegrationHandler) ResolveLinkedIssue(w http.ResponseWriter, r *http.Request) {
-	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
-	if !ok {
-		return
-	}
-
-	if !h.checkRunOwnership(w, r, runID) {
-		return
-	}
-
-	linkID, ok := parseUUIDOrRespond(w, r, "link_id", "issue link")
+	link, ok := h.runIssueLink(w, r)
 	if !ok {
 		return
 	}
 
-	link, err := h.integrationStore.GetIssueLinkByID(r.Context(), linkID)
-	if err != nil {
-		if errors.Is(err, integration.ErrIssueLinkNotFound) {
-			respondError(w, http.StatusNotFound, "issue link not found")
-			return
-		}
-		respondError(w, http.StatusInternalServerError, "failed to get issue link")
-		return
-	}
-
 	var req ResolveLinkedIssueRequest
 	if err := parseJSON(r, &req, h.logger); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid request body")
@@ -696,13 +800,13 @@ func (h *IntegrationHandler) ResolveLinkedIssue(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	creds, err := integration.DecryptCredentials(h.encryptionKey, integ.EncryptedCredentials)
+	creds, err := h.keyring.Decrypt(integ.EncryptedCredentials)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to decrypt credentials")
 		return
 	}
 
-	client, err := h.clientFactory.NewClient(integ.Provider, creds)
+	client, err := h.newClient(integ, creds)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to create client")
 		return
@@ -721,23 +825,23 @@ func (h *IntegrationHandler) ResolveLinkedIssue(w http.ResponseWriter, r *http.R
 			"error":       err.Error(),
 			"external_id": link.ExternalID,
 		})
-		respondError(w, http.StatusInternalServerError, "failed to resolve issue")
+		respondTrackerError(w, err, "failed to resolve issue")
 		return
 	}
 
 	// Update the link with latest status.
-	if err := h.integrationStore.UpdateIssueLink(r.Context(), linkID,
+	if err := h.integrationStore.UpdateIssueLink(r.Context(), link.ID,
 		integration.SetStatus(issue.Status),
 		integration.SetTitle(issue.Title),
 		integration.SetURL(issue.URL),
 	); err != nil {
 		h.logger.Warn(r.Context(), "failed to update issue link after resolve", map[string]interface{}{
 			"error":         err.Error(),
-			"issue_link_id": linkID.String(),
+			"issue_link_id": link.ID.String(),
 		})
 	}
 
-	updatedLink, err := h.integrationStore.GetIssueLinkByID(r.Context(), linkID)
+	updatedLink, err := h.integrationStore.GetIssueLinkByID(r.Context(), link.ID)
 	if err != nil {
 		respondJSON(w, http.StatusOK, link)
 		return
@@ -748,43 +852,24 @@ func (h *IntegrationHandler) ResolveLinkedIssue(w http.ResponseWriter, r *http.R
 
 // SyncIssueStatus handles POST /runs/{run_id}/issues/{link_id}/sync.
 func (h *IntegrationHandler) SyncIssueStatus(w http.ResponseWriter, r *http.Request) {
-	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	link, ok := h.runIssueLink(w, r)
 	if !ok {
 		return
 	}
 
-	if !h.checkRunOwnership(w, r, runID) {
-		return
-	}
-
-	linkID, ok := parseUUIDOrRespond(w, r, "link_id", "issue link")
-	if !ok {
-		return
-	}
-
-	link, err := h.integrationStore.GetIssueLinkByID(r.Context(), linkID)
-	if err != nil {
-		if errors.Is(err, integration.ErrIssueLinkNotFound) {
-			respondError(w, http.StatusNotFound, "issue link not found")
-			return
-		}
-		respondError(w, http.StatusInternalServerError, "failed to get issue link")
-		return
-	}
-
 	integ, err := h.integrationStore.GetIntegrationByID(r.Context(), link.IntegrationID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to get integration")
 		return
 	}
 
-	creds, err := integration.DecryptCredentials(h.encryptionKey, integ.EncryptedCredentials)
+	creds, err := h.keyring.Decrypt(integ.EncryptedCredentials)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to decrypt credentials")
 		return
 	}
 
-	client, err := h.clientFactory.NewClient(integ.Provider, creds)
+	client, err := h.newClient(integ, creds)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to create client")
 		return
@@ -800,24 +885,24 @@ func (h *IntegrationHandler) SyncIssueStatus(w http.ResponseWriter, r *http.Requ
 			"error":       err.Error(),
 			"external_id": link.ExternalID,
 		})
-		respondError(w, http.StatusInternalServerError, "failed to sync issue status")
+		respondTrackerError(w, err, "failed to sync issue status")
 		return
 	}
 
-	if err := h.integrationStore.UpdateIssueLink(r.Context(), linkID,
+	if err := h.integrationStore.UpdateIssueLink(r.Context(), link.ID,
 		integration.SetStatus(issue.Status),
 		integration.SetTitle(issue.Title),
 		integration.SetURL(issue.URL),
 	); err != nil {
 		h.logger.Error(r.Context(), "failed to update issue link", map[string]interface{}{
 			"error":         err.Error(),
-			"issue_link_id": linkID.String(),
+			"issue_link_id": link.ID.String(),
 		})
 		respondError(w, http.StatusInternalServerError, "failed to update issue link")
 		return
 	}
 
-	updatedLink, err := h.integrationStore.GetIssueLinkByID(r.Context(), linkID)
+	updatedLink, err := h.integrationStore.GetIssueLinkByID(r.Context(), link.ID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to get updated issue link")
 		return
@@ -838,7 +923,7 @@ func (h *IntegrationHandler) SearchExternalIssues(w http.ResponseWriter, r *http
 		return
 	}
 
-	creds, err := integration.DecryptCredentials(h.encryptionKey, integ.EncryptedCredentials)
+	creds, err := h.keyring.Decrypt(integ.EncryptedCredentials)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to decrypt credentials", map[string]interface{}{
 			"error": err.Error(),
@@ -847,7 +932,7 @@ func (h *IntegrationHandler) SearchExternalIssues(w http.ResponseWriter, r *http
 		return
 	}
 
-	client, err := h.clientFactory.NewClient(integ.Provider, creds)
+	client, err := h.newClient(integ, creds)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to create issue tracker client", map[string]interface{}{
 			"error": err.Error(),
@@ -857,24 +942,129 @@ func (h *IntegrationHandler) SearchExternalIssues(w http.ResponseWriter, r *http
 	}
 
 	query := r.URL.Query()
-	issues, total, err := client.ListIssues(r.Context(), issuetracker.ListIssuesInput{
+	limit := 20 // default
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	offset := 0 // default
+	if o, err := strconv.Atoi(query.Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	page, err := client.ListIssues(r.Context(), issuetracker.ListIssuesInput{
 		ProjectKey: query.Get("project_key"),
 		Repository: query.Get("repository"),
 		Status:     query.Get("status"),
 		Query:      query.Get("query"),
-		Limit:      20,
-		Offset:     0,
+		Limit:      limit,
+		Offset:     offset,
+		Cursor:     query.Get("cursor"),
 	})
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to search issues", map[string]interface{}{
 			"error": err.Error(),
 		})
-		respondError(w, http.StatusInternalServerError, "failed to search issues")
+		respondTrackerError(w, err, "failed to search issues")
+		return
+	}
+
+	resp := ExternalIssuePage{
+		Items:      page.Issues,
+		Limit:      limit,
+		Offset:     offset,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+	}
+	if page.Total != issuetracker.UnknownTotal {
+		resp.Total = &page.Total
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// maxWebhookBodySize bounds the payload of an issue tracker webhook delivery.
+const maxWebhookBodySize = 1 << 20
+
+// webhookSecretKey is the credential holding the secret webhook deliveries
+// are signed with. Webhooks are disabled for integrations without it.
+const webhookSecretKey = "webhook_secret"
+
+// ReceiveWebhook handles POST /integrations/{integration_id}/webhook. It is
+// called by the issue tracker instead of a user and is authenticated by the
+// delivery's signature. The reported issue's status, title and URL are
+// pushed into every link to it, without waiting for a sync.
+func (h *IntegrationHandler) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	integrationID, ok := parseUUIDOrRespond(w, r, "integration_id", "integration")
+	if !ok {
 		return
 	}
 
+	integ, err := h.integrationStore.GetIntegrationByID(r.Context(), integrationID)
+	if err != nil {
+		if errors.Is(err, integration.ErrIntegrationNotFound) {
+			respondError(w, http.StatusNotFound, "integration not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get integration")
+		return
+	}
+
+	creds, err := h.keyring.Decrypt(integ.EncryptedCredentials)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to decrypt credentials")
+		return
+	}
+	secret := creds[webhookSecretKey]
+	if secret == "" {
+		respondError(w, http.StatusNotFound, "webhooks are not enabled for this integration")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		respondError(w, http.StatusRequestEntityTooLarge, "webhook payload too large")
+		return
+	}
+
+	issue, err := h.webhookParser.ParseWebhook(integ.Provider, secret, r.Header, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, issuetracker.ErrInvalidSignature):
+			h.logger.Warn(r.Context(), "rejected webhook with invalid signature", map[string]interface{}{
+				"integration_id": integrationID.String(),
+			})
+			respondError(w, http.StatusUnauthorized, "invalid webhook signature")
+		case errors.Is(err, issuetracker.ErrUnsupportedEvent):
+			respondSuccess(w, "event ignored")
+		default:
+			respondError(w, http.StatusBadRequest, "invalid webhook payload")
+		}
+		return
+	}
+
+	if !integ.IsActive {
+		respondSuccess(w, "integration is inactive")
+		return
+	}
+
+	links, err := h.integrationStore.ListIssueLinksByExternalID(r.Context(), integ.ID, issue.ExternalID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list issue links")
+		return
+	}
+
+	for _, link := range links {
+		if err := h.integrationStore.UpdateIssueLink(r.Context(), link.ID,
+			integration.SetStatus(issue.Status),
+			integration.SetTitle(issue.Title),
+			integration.SetURL(issue.URL),
+		); err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to update issue link")
+			return
+		}
+	}
+
 	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"items": issues,
-		"total": total,
+		"external_id": issue.ExternalID,
+		"updated":     len(links),
 	})
 }
@@ -2,36 +2,53 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hairizuanbinnoorazman/ui-automation/agent"
 	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/execution"
+	"github.com/hairizuanbinnoorazman/ui-automation/exploration"
 	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/linkcheck"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
 	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/visualregression"
 )
 
 // JobHandler handles job-related requests.
 type JobHandler struct {
-	jobStore      job.Store
-	endpointStore endpoint.Store
-	projectStore  project.Store
-	workerPool    *agent.WorkerPool
-	pipeline      *agent.Pipeline
-	logger        logger.Logger
+	jobStore           job.Store
+	logStore           job.LogStore
+	endpointStore      endpoint.Store
+	projectStore       project.Store
+	testProcedureStore testprocedure.Store
+	workerPool         *agent.WorkerPool
+	pipeline           *agent.Pipeline
+	converter          *exploration.Converter
+	meter              *llmusage.Meter
+	logger             logger.Logger
 }
 
-// NewJobHandler creates a new job handler.
-func NewJobHandler(jobStore job.Store, endpointStore endpoint.Store, projectStore project.Store, pool *agent.WorkerPool, pipeline *agent.Pipeline, log logger.Logger) *JobHandler {
+// NewJobHandler creates a new job handler. Jobs run by the agents are only
+// created while meter's LLM budgets allow.
+func NewJobHandler(jobStore job.Store, logStore job.LogStore, endpointStore endpoint.Store, projectStore project.Store, testProcedureStore testprocedure.Store, pool *agent.WorkerPool, pipeline *agent.Pipeline, converter *exploration.Converter, meter *llmusage.Meter, log logger.Logger) *JobHandler {
 	return &JobHandler{
-		jobStore:      jobStore,
-		endpointStore: endpointStore,
-		projectStore:  projectStore,
-		workerPool:    pool,
-		pipeline:      pipeline,
-		logger:        log,
+		jobStore:           jobStore,
+		logStore:           logStore,
+		endpointStore:      endpointStore,
+		projectStore:       projectStore,
+		testProcedureStore: testProcedureStore,
+		workerPool:         pool,
+		pipeline:           pipeline,
+		converter:          converter,
+		meter:              meter,
+		logger:             log,
 	}
 }
 
@@ -71,6 +88,42 @@ func (h *JobHandler) checkJobOwnership(w http.ResponseWriter, r *http.Request, j
 	return true
 }
 
+// checkExecutedProcedure verifies that the procedure of a procedure_execution
+// job belongs to the job's project, is not archived and has a committed
+// version to execute.
+// Returns false if the check fails (response already written).
+func (h *JobHandler) checkExecutedProcedure(w http.ResponseWriter, r *http.Request, cfg *execution.Config) bool {
+	proc, err := h.testProcedureStore.GetLatestCommitted(r.Context(), cfg.ProcedureID)
+	if err != nil {
+		switch {
+		case errors.Is(err, testprocedure.ErrTestProcedureNotFound):
+			respondError(w, http.StatusNotFound, "test procedure not found")
+		case errors.Is(err, testprocedure.ErrNoCommittedVersion):
+			respondError(w, http.StatusBadRequest, "test procedure has no committed version to execute")
+		default:
+			h.logger.Error(r.Context(), "failed to verify test procedure", map[string]interface{}{
+				"error":        err.Error(),
+				"procedure_id": cfg.ProcedureID.String(),
+			})
+			respondError(w, http.StatusInternalServerError, "failed to verify test procedure")
+		}
+		return false
+	}
+	if proc.ProjectID != cfg.ProjectID {
+		respondError(w, http.StatusNotFound, "test procedure not found")
+		return false
+	}
+	if proc.IsArchived() {
+		respondError(w, http.StatusConflict, testprocedure.ErrProcedureArchived.Error())
+		return false
+	}
+	if len(proc.Steps) == 0 {
+		respondError(w, http.StatusBadRequest, execution.ErrNoSteps.Error())
+		return false
+	}
+	return true
+}
+
 // CreateJobRequest represents a job creation request.
 type CreateJobRequest struct {
 	Type   string                 `json:"type"`
@@ -97,22 +150,24 @@ func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// For ui_exploration jobs, validate required config fields
-	if jobType == job.JobTypeUIExploration {
-		endpointIDStr, ok := req.Config["endpoint_id"].(string)
-		if !ok || endpointIDStr == "" {
-			respondError(w, http.StatusBadRequest, "endpoint_id is required in config for ui_exploration jobs")
-			return
-		}
-		endpointID, err := uuid.Parse(endpointIDStr)
-		if err != nil {
-			respondError(w, http.StatusBadRequest, "endpoint_id must be a valid UUID")
+	// Jobs that run against an endpoint need an endpoint and a project the
+	// user owns
+	var jobEndpointID *uuid.UUID
+	useRunner := false
+	if jobType == job.JobTypeUIExploration || jobType == job.JobTypeVisualRegression || jobType == job.JobTypeLinkCheck || jobType == job.JobTypeProcedureExecution {
+		// The endpoint is chosen by ID, or by group and environment.
+		target := EndpointTarget{}
+		target.EndpointID, _ = req.Config["endpoint_id"].(string)
+		target.Group, _ = req.Config["endpoint_group"].(string)
+		target.Environment, _ = req.Config["environment"].(string)
+		if target.EndpointID == "" && (target.Group == "" || target.Environment == "") {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("endpoint_id, or endpoint_group and environment, is required in config for %s jobs", jobType))
 			return
 		}
 
 		projectIDStr, ok := req.Config["project_id"].(string)
 		if !ok || projectIDStr == "" {
-			respondError(w, http.StatusBadRequest, "project_id is required in config for ui_exploration jobs")
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("project_id is required in config for %s jobs", jobType))
 			return
 		}
 		projectID, err := uuid.Parse(projectIDStr)
@@ -122,23 +177,21 @@ func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Verify user owns the endpoint
-		ep, err := h.endpointStore.GetByID(r.Context(), endpointID)
-		if err != nil {
-			if errors.Is(err, endpoint.ErrEndpointNotFound) {
-				respondError(w, http.StatusNotFound, "endpoint not found")
-				return
-			}
-			h.logger.Error(r.Context(), "failed to verify endpoint", map[string]interface{}{
-				"error":       err.Error(),
-				"endpoint_id": endpointID,
-			})
-			respondError(w, http.StatusInternalServerError, "failed to verify endpoint")
+		ep, ok := resolveEndpointTarget(w, r, h.endpointStore, h.logger, userID, target)
+		if !ok {
 			return
 		}
-		if ep.CreatedBy != userID {
-			respondError(w, http.StatusForbidden, "you don't have access to this endpoint")
+		if ep.UseRunner && jobType != job.JobTypeUIExploration {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("%s jobs cannot run on endpoints that use agent runners", jobType))
 			return
 		}
+		useRunner = ep.UseRunner
+
+		// Runners read the resolved endpoint from the config.
+		req.Config["endpoint_id"] = ep.ID.String()
+		if ep.Environment != "" {
+			req.Config["environment"] = ep.Environment
+		}
 
 		// Verify user owns the project
 		proj, err := h.projectStore.GetByID(r.Context(), projectID)
@@ -154,17 +207,42 @@ func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusInternalServerError, "failed to verify project")
 			return
 		}
-		if proj.OwnerID != userID {
+		if !canAccessProject(r, proj.ID, proj.OwnerID) {
 			respondError(w, http.StatusForbidden, "you don't have access to this project")
 			return
 		}
+
+		// The agents of these jobs call the model
+		if (jobType == job.JobTypeUIExploration || jobType == job.JobTypeProcedureExecution) && !checkLLMBudget(w, r, h.meter, projectID, h.logger) {
+			return
+		}
+
+		jobEndpointID = &ep.ID
+	}
+
+	var configErr error
+	switch jobType {
+	case job.JobTypeVisualRegression:
+		_, configErr = visualregression.ParseConfig(req.Config)
+	case job.JobTypeLinkCheck:
+		_, configErr = linkcheck.ParseConfig(req.Config)
+	case job.JobTypeProcedureExecution:
+		var cfg *execution.Config
+		if cfg, configErr = execution.ParseConfig(req.Config); configErr == nil && !h.checkExecutedProcedure(w, r, cfg) {
+			return
+		}
+	}
+	if configErr != nil {
+		respondError(w, http.StatusBadRequest, configErr.Error())
+		return
 	}
 
 	j := &job.Job{
-		Type:      jobType,
-		Status:    job.StatusCreated,
-		Config:    job.JSONMap(req.Config),
-		CreatedBy: userID,
+		Type:       jobType,
+		Status:     job.StatusCreated,
+		Config:     job.JSONMap(req.Config),
+		EndpointID: jobEndpointID,
+		CreatedBy:  userID,
 	}
 
 	if err := h.jobStore.Create(r.Context(), j); err != nil {
@@ -175,12 +253,15 @@ func (h *JobHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Notify worker pool that a new job is available
-	if jobType == job.JobTypeUIExploration && h.workerPool != nil {
-		select {
-		case h.workerPool.Work <- struct{}{}:
-		default:
-			// All workers busy; job stays in DB as 'created' until a worker is free
+	// Hand the job to the workers; if all are busy it stays in DB as
+	// 'created' until a worker is free. Agent runners claim their jobs
+	// through the runner API instead.
+	if jobEndpointID != nil && !useRunner && h.workerPool != nil {
+		if err := h.workerPool.Dispatch(r.Context(), j.ID); err != nil {
+			h.logger.Error(r.Context(), "failed to dispatch job", map[string]interface{}{
+				"error":  err.Error(),
+				"job_id": j.ID.String(),
+			})
 		}
 	}
 
@@ -212,6 +293,27 @@ func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// ?cursor= pages through long job histories without counting them.
+	if usesCursor(r) {
+		cursor, ok := parseCursor(w, r)
+		if !ok {
+			return
+		}
+		jobs, err := h.jobStore.ListByCreatorAfter(r.Context(), userID, cursor, limit+1)
+		if err != nil {
+			h.logger.Error(r.Context(), "failed to list jobs", map[string]interface{}{
+				"error": err.Error(),
+			})
+			respondError(w, http.StatusInternalServerError, "failed to list jobs")
+			return
+		}
+		jobs, next := cursorPage(jobs, limit, func(j *job.Job) (time.Time, uuid.UUID) {
+			return j.CreatedAt, j.ID
+		})
+		respondJSON(w, http.StatusOK, NewCursorResponse(jobs, limit, next))
+		return
+	}
+
 	total, err := h.jobStore.CountByCreator(r.Context(), userID)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to count jobs", map[string]interface{}{
@@ -261,6 +363,71 @@ func (h *JobHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, j)
 }
 
+// JobLogsResponse is a batch of job log lines with the job's current status,
+// so a client tailing the log knows when the job has finished.
+type JobLogsResponse struct {
+	Items  []*job.LogEntry `json:"items"`
+	Total  int             `json:"total"`
+	Status job.Status      `json:"status"`
+}
+
+// Logs handles GET /jobs/{id}/logs, returning the log lines after the
+// sequence number given by the after query parameter.
+func (h *JobHandler) Logs(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "job")
+	if !ok {
+		return
+	}
+
+	if !h.checkJobOwnership(w, r, id) {
+		return
+	}
+
+	after := 0
+	if afterStr := r.URL.Query().Get("after"); afterStr != "" {
+		a, err := strconv.Atoi(afterStr)
+		if err != nil || a < 0 {
+			respondError(w, http.StatusBadRequest, "invalid after")
+			return
+		}
+		after = a
+	}
+
+	limit := 500
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	// Read the status before the lines so a finished status guarantees the
+	// batch includes the job's final lines.
+	j, err := h.jobStore.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get job", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get job")
+		return
+	}
+
+	entries, err := h.logStore.ListAfter(r.Context(), id, after, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list job logs")
+		return
+	}
+	if entries == nil {
+		entries = []*job.LogEntry{}
+	}
+
+	respondJSON(w, http.StatusOK, JobLogsResponse{
+		Items:  entries,
+		Total:  len(entries),
+		Status: j.Status,
+	})
+}
+
 // Stop handles stopping a running job.
 func (h *JobHandler) Stop(w http.ResponseWriter, r *http.Request) {
 	id, ok := parseUUIDOrRespond(w, r, "id", "job")
@@ -323,3 +490,78 @@ func (h *JobHandler) Stop(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, stopped)
 }
+
+// ConvertJobRequest represents a request to convert an exploration job into procedures.
+type ConvertJobRequest struct {
+	ProjectID string `json:"project_id"`
+}
+
+// ConvertToProcedures handles POST /jobs/{id}/convert-to-procedures. Each flow
+// discovered by a successful ui_exploration job becomes a draft procedure in
+// the chosen project, flagged for review.
+func (h *JobHandler) ConvertToProcedures(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "job")
+	if !ok {
+		return
+	}
+
+	if !h.checkJobOwnership(w, r, id) {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	var req ConvertJobRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	projectID, err := uuid.Parse(req.ProjectID)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid project_id")
+		return
+	}
+
+	proj, err := h.projectStore.GetByID(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to verify project", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to verify project")
+		return
+	}
+	if !canAccessProject(r, proj.ID, proj.OwnerID) {
+		respondError(w, http.StatusForbidden, "you don't have access to this project")
+		return
+	}
+
+	procedures, err := h.converter.Convert(r.Context(), id, projectID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, job.ErrJobNotFound):
+			respondError(w, http.StatusNotFound, "job not found")
+		case errors.Is(err, exploration.ErrJobNotConvertible), errors.Is(err, exploration.ErrNoFlows):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, exploration.ErrAlreadyConverted):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error(r.Context(), "failed to convert job to procedures", map[string]interface{}{
+				"error":      err.Error(),
+				"job_id":     id,
+				"project_id": projectID,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to convert job to procedures")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"items": procedures,
+		"total": len(procedures),
+	})
+}
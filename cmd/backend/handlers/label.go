@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/label"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// LabelHandler handles label requests for procedures and runs.
+type LabelHandler struct {
+	store          label.Store
+	testProcedures *TestProcedureHandler
+	logger         logger.Logger
+}
+
+// NewLabelHandler creates a new label handler. Procedure ownership is
+// checked through the test procedure handler; run routes are on the run
+// router, whose middleware checks the run's owner.
+func NewLabelHandler(store label.Store, testProcedures *TestProcedureHandler, log logger.Logger) *LabelHandler {
+	return &LabelHandler{
+		store:          store,
+		testProcedures: testProcedures,
+		logger:         log,
+	}
+}
+
+// SetLabelRequest represents a request to set a label's value.
+type SetLabelRequest struct {
+	Value string `json:"value"`
+}
+
+// labelMatches parses the repeated ?label= selectors of a list request and
+// returns the IDs of the project's resources that carry every label. It
+// returns nil if no labels were given. Returns false if the selectors are
+// invalid or cannot be matched (response already written).
+func labelMatches(w http.ResponseWriter, r *http.Request, store label.Store, log logger.Logger, projectID uuid.UUID, resourceType label.ResourceType) ([]uuid.UUID, bool) {
+	values := r.URL.Query()["label"]
+	if len(values) == 0 {
+		return nil, true
+	}
+
+	selectors, err := label.ParseSelectors(values)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	ids, err := store.Match(r.Context(), projectID, resourceType, selectors)
+	if err != nil {
+		log.Error(r.Context(), "failed to match labels", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to match labels")
+		return nil, false
+	}
+	return ids, true
+}
+
+// resolveProcedure checks access to the procedure in the URL and returns its
+// project and the first version, which procedure labels are attached to.
+// Returns false if the check fails (response already written).
+func (h *LabelHandler) resolveProcedure(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	proc, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return proc.ProjectID, rootID, true
+}
+
+// resolveRun returns the project and ID of the run in the URL, whose access
+// RunAuthorizationMiddleware already checked. Returns false if the run ID is
+// invalid (response already written).
+func (h *LabelHandler) resolveRun(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	owner, _ := GetRunOwner(r.Context())
+	return owner.ProjectID, runID, true
+}
+
+// list responds with the labels of a resource.
+func (h *LabelHandler) list(w http.ResponseWriter, r *http.Request, resourceType label.ResourceType, resourceID uuid.UUID) {
+	labels, err := h.store.List(r.Context(), resourceType, resourceID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list labels")
+		return
+	}
+	respondJSON(w, http.StatusOK, labels)
+}
+
+// set attaches the label with the key in the URL to a resource.
+func (h *LabelHandler) set(w http.ResponseWriter, r *http.Request, projectID uuid.UUID, resourceType label.ResourceType, resourceID uuid.UUID) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	// The body is optional; a label without one is a bare key.
+	var req SetLabelRequest
+	if r.ContentLength != 0 {
+		if err := parseJSON(r, &req, h.logger); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	l := &label.Label{
+		ProjectID:    projectID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Key:          mux.Vars(r)["key"],
+		Value:        req.Value,
+		CreatedBy:    userID,
+	}
+	if err := h.store.Set(r.Context(), l); err != nil {
+		if errors.Is(err, label.ErrInvalidKey) || errors.Is(err, label.ErrInvalidValue) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to set label")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, l)
+}
+
+// remove detaches the label with the key in the URL from a resource.
+func (h *LabelHandler) remove(w http.ResponseWriter, r *http.Request, resourceType label.ResourceType, resourceID uuid.UUID) {
+	if err := h.store.Remove(r.Context(), resourceType, resourceID, mux.Vars(r)["key"]); err != nil {
+		if errors.Is(err, label.ErrLabelNotFound) {
+			respondError(w, http.StatusNotFound, "label not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to remove label")
+		return
+	}
+
+	respondSuccess(w, "label removed")
+}
+
+// ListProcedureLabels handles GET /procedures/{procedure_id}/labels.
+func (h *LabelHandler) ListProcedureLabels(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.resolveProcedure(w, r)
+	if !ok {
+		return
+	}
+	h.list(w, r, label.ResourceProcedure, rootID)
+}
+
+// SetProcedureLabel handles PUT /procedures/{procedure_id}/labels/{key}. The
+// label applies to every version of the procedure.
+func (h *LabelHandler) SetProcedureLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, rootID, ok := h.resolveProcedure(w, r)
+	if !ok {
+		return
+	}
+	h.set(w, r, projectID, label.ResourceProcedure, rootID)
+}
+
+// RemoveProcedureLabel handles DELETE /procedures/{procedure_id}/labels/{key}.
+func (h *LabelHandler) RemoveProcedureLabel(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.resolveProcedure(w, r)
+	if !ok {
+		return
+	}
+	h.remove(w, r, label.ResourceProcedure, rootID)
+}
+
+// ListRunLabels handles GET /runs/{run_id}/labels.
+func (h *LabelHandler) ListRunLabels(w http.ResponseWriter, r *http.Request) {
+	_, runID, ok := h.resolveRun(w, r)
+	if !ok {
+		return
+	}
+	h.list(w, r, label.ResourceRun, runID)
+}
+
+// SetRunLabel handles PUT /runs/{run_id}/labels/{key}.
+func (h *LabelHandler) SetRunLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, runID, ok := h.resolveRun(w, r)
+	if !ok {
+		return
+	}
+	h.set(w, r, projectID, label.ResourceRun, runID)
+}
+
+// RemoveRunLabel handles DELETE /runs/{run_id}/labels/{key}.
+func (h *LabelHandler) RemoveRunLabel(w http.ResponseWriter, r *http.Request) {
+	_, runID, ok := h.resolveRun(w, r)
+	if !ok {
+		return
+	}
+	h.remove(w, r, label.ResourceRun, runID)
+}
+
+// ListProjectLabels handles GET /projects/{id}/labels. It lists the labels
+// used in the project with how many procedures and runs carry each.
+func (h *LabelHandler) ListProjectLabels(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	usage, err := h.store.ListUsage(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list labels")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, usage)
+}
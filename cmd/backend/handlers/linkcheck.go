@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/linkcheck"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// LinkCheckHandler exports the reports of link_check jobs and attaches them
+// to test runs.
+type LinkCheckHandler struct {
+	jobStore   job.Store
+	assetStore testrun.AssetStore
+	storage    storage.BlobStorage
+	recorder   *metering.Recorder
+	logger     logger.Logger
+}
+
+// NewLinkCheckHandler creates a new link check handler.
+func NewLinkCheckHandler(
+	jobStore job.Store,
+	assetStore testrun.AssetStore,
+	storage storage.BlobStorage,
+	recorder *metering.Recorder,
+	log logger.Logger,
+) *LinkCheckHandler {
+	return &LinkCheckHandler{
+		jobStore:   jobStore,
+		assetStore: assetStore,
+		storage:    storage,
+		recorder:   recorder,
+		logger:     log,
+	}
+}
+
+// getReportJob loads a successful link_check job created by the authenticated
+// user. Returns false if the check fails (response already written).
+func (h *LinkCheckHandler) getReportJob(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) (*job.Job, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return nil, false
+	}
+
+	j, err := h.jobStore.GetByID(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, job.ErrJobNotFound) {
+			respondError(w, http.StatusNotFound, "job not found")
+			return nil, false
+		}
+		h.logger.Error(r.Context(), "failed to get job for authorization", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID,
+		})
+		respondError(w, http.StatusInternalServerError, "authorization check failed")
+		return nil, false
+	}
+
+	if j.CreatedBy != userID {
+		respondError(w, http.StatusForbidden, "you don't have access to this job")
+		return nil, false
+	}
+
+	if j.Type != job.JobTypeLinkCheck || j.Status != job.StatusSuccess {
+		respondError(w, http.StatusBadRequest, linkcheck.ErrJobNotReportable.Error())
+		return nil, false
+	}
+
+	return j, true
+}
+
+// renderCSV renders the issues of a link_check job as CSV.
+func (h *LinkCheckHandler) renderCSV(w http.ResponseWriter, r *http.Request, j *job.Job) ([]byte, bool) {
+	issues, err := linkcheck.IssuesFromResult(j.Result)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to decode link check issues", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": j.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to read job result")
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	if err := linkcheck.WriteCSV(&buf, issues); err != nil {
+		h.logger.Error(r.Context(), "failed to render link report", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": j.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to render link report")
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// ExportReport handles GET /jobs/{id}/link-report, returning the report of a
+// link_check job as a CSV download.
+func (h *LinkCheckHandler) ExportReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "job")
+	if !ok {
+		return
+	}
+
+	j, ok := h.getReportJob(w, r, id)
+	if !ok {
+		return
+	}
+
+	data, ok := h.renderCSV(w, r, j)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"link-report-%s.csv\"", j.ID))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// AttachReportRequest represents a request to attach a link report to a test run.
+type AttachReportRequest struct {
+	JobID string `json:"job_id"`
+}
+
+// AttachReport handles POST /runs/{run_id}/link-reports, storing the CSV
+// report of a link_check job as a document asset of the test run.
+func (h *LinkCheckHandler) AttachReport(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	var req AttachReportRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	jobID, err := uuid.Parse(req.JobID)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid job_id")
+		return
+	}
+
+	j, ok := h.getReportJob(w, r, jobID)
+	if !ok {
+		return
+	}
+
+	data, ok := h.renderCSV(w, r, j)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("link-report-%s.csv", j.ID)
+	storagePath := fmt.Sprintf("test-runs/%s/%s/%s", runID, testrun.AssetTypeDocument, filename)
+	if err := h.storage.Upload(r.Context(), storagePath, bytes.NewReader(data)); err != nil {
+		h.logger.Error(r.Context(), "failed to upload link report to storage", map[string]interface{}{
+			"error": err.Error(),
+			"path":  storagePath,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to upload link report")
+		return
+	}
+
+	issues, _ := linkcheck.IssuesFromResult(j.Result)
+	asset := &testrun.TestRunAsset{
+		TestRunID:   runID,
+		AssetType:   testrun.AssetTypeDocument,
+		AssetPath:   storagePath,
+		FileName:    filename,
+		FileSize:    int64(len(data)),
+		MimeType:    "text/csv",
+		Description: fmt.Sprintf("Broken link report (%d issues)", len(issues)),
+		UploadedAt:  time.Now(),
+	}
+
+	if err := h.assetStore.Create(r.Context(), asset); err != nil {
+		// Clean up uploaded file on database error
+		h.storage.Delete(r.Context(), storagePath)
+		h.logger.Error(r.Context(), "failed to create asset record", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create asset record")
+		return
+	}
+
+	userID, _ := GetUserID(r.Context())
+	owner, _ := GetRunOwner(r.Context())
+	h.recorder.RecordStorage(r.Context(), owner.ProjectID, userID, asset.FileSize)
+
+	respondJSON(w, http.StatusCreated, asset)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+)
+
+// LLMUsageHandler handles LLM usage report and budget requests. Like usage
+// reports, they only ever cover the authenticated user's own account, whose
+// owner is the only one who may set its budgets. Project budget routes are
+// registered on the project router, whose authorization middleware has
+// already verified ownership.
+type LLMUsageHandler struct {
+	store  llmusage.Store
+	logger logger.Logger
+}
+
+// NewLLMUsageHandler creates a new LLM usage handler.
+func NewLLMUsageHandler(store llmusage.Store, log logger.Logger) *LLMUsageHandler {
+	return &LLMUsageHandler{
+		store:  store,
+		logger: log,
+	}
+}
+
+// SetLLMBudgetRequest represents a monthly LLM budget update request.
+type SetLLMBudgetRequest struct {
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+}
+
+// GetReport handles GET /usage/llm?month=YYYY-MM, defaulting to the current
+// month.
+func (h *LLMUsageHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	month := time.Now().UTC()
+	if monthStr := r.URL.Query().Get("month"); monthStr != "" {
+		parsed, err := metering.ParseMonth(monthStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		month = parsed
+	}
+
+	report, err := llmusage.BuildReport(r.Context(), h.store, userID, month)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to build llm usage report", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to build llm usage report")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// SetAccountBudget handles PUT /usage/llm/budget, the budget covering all of
+// the account's projects.
+func (h *LLMUsageHandler) SetAccountBudget(w http.ResponseWriter, r *http.Request) {
+	h.setBudget(w, r, uuid.Nil)
+}
+
+// DeleteAccountBudget handles DELETE /usage/llm/budget.
+func (h *LLMUsageHandler) DeleteAccountBudget(w http.ResponseWriter, r *http.Request) {
+	h.deleteBudget(w, r, uuid.Nil)
+}
+
+// GetProjectBudget handles GET /projects/{id}/llm-budget.
+func (h *LLMUsageHandler) GetProjectBudget(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	budgets, err := h.store.ListBudgets(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get llm budget")
+		return
+	}
+	for _, budget := range budgets {
+		if budget.ProjectID == projectID {
+			h.respondBudget(w, r, budget)
+			return
+		}
+	}
+
+	respondError(w, http.StatusNotFound, llmusage.ErrBudgetNotFound.Error())
+}
+
+// SetProjectBudget handles PUT /projects/{id}/llm-budget.
+func (h *LLMUsageHandler) SetProjectBudget(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	h.setBudget(w, r, projectID)
+}
+
+// DeleteProjectBudget handles DELETE /projects/{id}/llm-budget.
+func (h *LLMUsageHandler) DeleteProjectBudget(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	h.deleteBudget(w, r, projectID)
+}
+
+// setBudget creates or replaces a budget of the user's account, of one
+// project or, when projectID is uuid.Nil, of all of them.
+func (h *LLMUsageHandler) setBudget(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req SetLLMBudgetRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	budget := &llmusage.Budget{
+		AccountID:       userID,
+		ProjectID:       projectID,
+		MonthlyLimitUSD: req.MonthlyLimitUSD,
+	}
+	if err := h.store.SetBudget(r.Context(), budget); err != nil {
+		if errors.Is(err, llmusage.ErrInvalidLimit) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to save llm budget")
+		return
+	}
+
+	h.respondBudget(w, r, budget)
+}
+
+// deleteBudget deletes a budget of the user's account.
+func (h *LLMUsageHandler) deleteBudget(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	if err := h.store.DeleteBudget(r.Context(), userID, projectID); err != nil {
+		if errors.Is(err, llmusage.ErrBudgetNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete llm budget")
+		return
+	}
+
+	respondSuccess(w, "llm budget deleted successfully")
+}
+
+// respondBudget responds with how much of budget is spent this month.
+func (h *LLMUsageHandler) respondBudget(w http.ResponseWriter, r *http.Request, budget *llmusage.Budget) {
+	start := llmusage.MonthStart(time.Now().UTC())
+	spent, err := h.store.SpentUSD(r.Context(), budget.AccountID, budget.ProjectID, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get llm spend")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, llmusage.NewBudgetStatus(budget, spent))
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/markdown"
+)
+
+// maxMarkdownPreviewSize bounds the Markdown sent for a preview.
+const maxMarkdownPreviewSize = 1 << 20
+
+// MarkdownPreviewRequest is the Markdown of a procedure description or step
+// instructions to preview.
+type MarkdownPreviewRequest struct {
+	Markdown string `json:"markdown"`
+}
+
+// MarkdownPreviewResponse is the Markdown as it will be saved, with what is
+// unsafe to show removed, and that Markdown rendered as HTML.
+type MarkdownPreviewResponse struct {
+	Markdown string `json:"markdown"`
+	HTML     string `json:"html"`
+}
+
+// MarkdownHandler renders Markdown for editors to preview.
+type MarkdownHandler struct {
+	logger logger.Logger
+}
+
+// NewMarkdownHandler creates a new Markdown handler.
+func NewMarkdownHandler(log logger.Logger) *MarkdownHandler {
+	return &MarkdownHandler{
+		logger: log,
+	}
+}
+
+// Preview handles POST /markdown/preview. It sanitizes the Markdown the way
+// saving a procedure does and renders the result, so an editor can show
+// what will be saved and how it reads.
+func (h *MarkdownHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxMarkdownPreviewSize)
+	var req MarkdownPreviewRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sanitized := markdown.Sanitize(req.Markdown)
+	respondJSON(w, http.StatusOK, MarkdownPreviewResponse{
+		Markdown: sanitized,
+		HTML:     markdown.ToHTML(sanitized),
+	})
+}
+
+// exportMarkdown prepares Markdown written by users for an exported
+// document: what is unsafe to show is removed, in case it was saved before
+// sanitizing or added by narration, and its headings are pushed down by
+// levels to nest under the document's own.
+func exportMarkdown(src string, levels int) string {
+	return markdown.Demote(markdown.Sanitize(src), levels)
+}
+
+// exportHTML renders Markdown written by users for an exported HTML
+// document, with its headings pushed down by levels.
+func exportHTML(src string, levels int) template.HTML {
+	return template.HTML(markdown.ToHTML(markdown.Demote(src, levels)))
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+func TestMarkdownHandler_Preview(t *testing.T) {
+	t.Parallel()
+
+	handler := NewMarkdownHandler(logger.NewTestLogger())
+
+	t.Run("sanitizes and renders", func(t *testing.T) {
+		t.Parallel()
+		body := `{"markdown": "Click **Save**<script>alert(1)</script>"}`
+		w := httptest.NewRecorder()
+		handler.Preview(w, httptest.NewRequest(http.MethodPost, "/api/v1/markdown/preview", strings.NewReader(body)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		var resp MarkdownPreviewResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Markdown != "Click **Save**" {
+			t.Errorf("markdown = %q, want %q", resp.Markdown, "Click **Save**")
+		}
+		if resp.HTML != "<p>Click <strong>Save</strong></p>\n" {
+			t.Errorf("html = %q", resp.HTML)
+		}
+	})
+
+	t.Run("rejects malformed bodies", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		handler.Preview(w, httptest.NewRequest(http.MethodPost, "/api/v1/markdown/preview", strings.NewReader("{")))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
@@ -26,6 +27,12 @@ const (
 
 	// AuthMethodKey is the context key for the authentication method.
 	AuthMethodKey ContextKey = "auth_method"
+
+	// TokenIDKey is the context key for the API token ID on bearer requests.
+	TokenIDKey ContextKey = "token_id"
+
+	// SessionIDKey is the context key for the session ID on session requests.
+	SessionIDKey ContextKey = "session_id"
 )
 
 // AuthMiddleware validates session cookies or Bearer tokens and adds user info to context.
@@ -74,9 +81,25 @@ func (m *AuthMiddleware) handleBearerAuth(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	ip := clientIP(r)
+	if !token.AllowedCIDRs.Allows(ip) {
+		m.logger.Warn(r.Context(), "bearer token used from disallowed IP", map[string]interface{}{
+			"token_id": token.ID.String(),
+			"ip":       ip,
+			"path":     r.URL.Path,
+		})
+		respondError(w, http.StatusForbidden, apitoken.ErrIPNotAllowed.Error())
+		return
+	}
+	if now := time.Now(); token.NeedsUsageRecorded(ip, now) {
+		// Best effort: the store logs failures, which never fail the request.
+		m.tokenStore.RecordUsage(context.WithoutCancel(r.Context()), token.ID, ip, now)
+	}
+
 	ctx := context.WithValue(r.Context(), UserIDKey, token.UserID)
 	ctx = context.WithValue(ctx, ScopeKey, token.Scope)
 	ctx = context.WithValue(ctx, AuthMethodKey, "bearer")
+	ctx = context.WithValue(ctx, TokenIDKey, token.ID)
 
 	next.ServeHTTP(w, r.WithContext(ctx))
 }
@@ -111,10 +134,13 @@ func (m *AuthMiddleware) handleSessionAuth(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	m.sessionManager.Touch(sessionID, clientIP(r), r.UserAgent())
+
 	ctx := context.WithValue(r.Context(), UserIDKey, sess.UserID)
 	ctx = context.WithValue(ctx, UserEmailKey, sess.Email)
 	ctx = context.WithValue(ctx, ScopeKey, apitoken.ScopeReadWrite)
 	ctx = context.WithValue(ctx, AuthMethodKey, "session")
+	ctx = context.WithValue(ctx, SessionIDKey, sessionID)
 
 	next.ServeHTTP(w, r.WithContext(ctx))
 }
@@ -149,6 +175,20 @@ func GetAuthMethod(ctx context.Context) string {
 	return method
 }
 
+// GetTokenID extracts the API token ID from the request context.
+// Only set for requests authenticated with a bearer token.
+func GetTokenID(ctx context.Context) (uuid.UUID, bool) {
+	tokenID, ok := ctx.Value(TokenIDKey).(uuid.UUID)
+	return tokenID, ok
+}
+
+// GetSessionID extracts the session ID from the request context.
+// Only set for requests authenticated with a session cookie.
+func GetSessionID(ctx context.Context) (uuid.UUID, bool) {
+	sessionID, ok := ctx.Value(SessionIDKey).(uuid.UUID)
+	return sessionID, ok
+}
+
 // RequireWriteScope checks if the current request has write scope.
 // Returns true if the scope is read_write, false otherwise (and writes a 403 response).
 func RequireWriteScope(w http.ResponseWriter, r *http.Request) bool {
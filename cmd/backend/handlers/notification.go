@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
+)
+
+// NotificationHandler handles notification preference requests.
+type NotificationHandler struct {
+	store  notification.Store
+	logger logger.Logger
+}
+
+// NewNotificationHandler creates a new notification handler.
+func NewNotificationHandler(store notification.Store, log logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		store:  store,
+		logger: log,
+	}
+}
+
+// UpdateNotificationPreferencesRequest represents a notification preference
+// update request.
+type UpdateNotificationPreferencesRequest struct {
+	Events notification.EventChannels `json:"events"`
+	// SlackWebhookURL replaces the stored webhook. It is kept when omitted
+	// and removed when empty.
+	SlackWebhookURL *string `json:"slack_webhook_url,omitempty"`
+}
+
+// GetPreferences returns the authenticated user's notification preferences.
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	prefs, err := h.store.Get(r.Context(), userID)
+	if errors.Is(err, notification.ErrPreferencesNotFound) {
+		prefs = notification.DefaultPreferences(userID)
+	} else if err != nil {
+		h.logger.Error(r.Context(), "failed to get notification preferences", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get notification preferences")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences replaces the authenticated user's notification
+// preferences.
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req UpdateNotificationPreferencesRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	prefs, err := h.store.Get(r.Context(), userID)
+	if errors.Is(err, notification.ErrPreferencesNotFound) {
+		prefs = notification.DefaultPreferences(userID)
+	} else if err != nil {
+		h.logger.Error(r.Context(), "failed to get notification preferences", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to update notification preferences")
+		return
+	}
+
+	if req.Events == nil {
+		req.Events = notification.EventChannels{}
+	}
+	prefs.Events = req.Events
+	if req.SlackWebhookURL != nil {
+		prefs.SlackWebhookURL = *req.SlackWebhookURL
+	}
+
+	if err := h.store.Save(r.Context(), prefs); err != nil {
+		if errors.Is(err, notification.ErrInvalidEventType) ||
+			errors.Is(err, notification.ErrInvalidChannel) ||
+			errors.Is(err, notification.ErrInvalidSlackWebhook) ||
+			errors.Is(err, notification.ErrSlackWebhookRequired) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error(r.Context(), "failed to save notification preferences", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to update notification preferences")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, prefs)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// isParameterSchemaError reports whether err is a problem with a
+// procedure's parameter schema or its placeholders.
+func isParameterSchemaError(err error) bool {
+	return errors.Is(err, testprocedure.ErrInvalidParameter) || errors.Is(err, testprocedure.ErrUndeclaredParameter)
+}
+
+// resolveParameters checks the values given for the parameters of tp, with
+// base_url defaulting to baseURL, and returns them with defaults filled in.
+// Returns false if they do not match the schema (response already written).
+func resolveParameters(w http.ResponseWriter, tp *testprocedure.TestProcedure, values map[string]string, baseURL string) (testprocedure.ParameterValues, bool) {
+	resolved, err := tp.Parameters.ResolveWithBaseURL(values, baseURL)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return resolved, true
+}
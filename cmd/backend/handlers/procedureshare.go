@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/procedureshare"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// ProcedureShareHandler handles sharing single procedures with users outside
+// their project. Only those with access to the project manage the shares;
+// the users a procedure is shared with get through the test procedure
+// handler's access checks.
+type ProcedureShareHandler struct {
+	store              procedureshare.Store
+	testProcedureStore testprocedure.Store
+	userStore          user.Store
+	testProcedures     *TestProcedureHandler
+	logger             logger.Logger
+}
+
+// NewProcedureShareHandler creates a new procedure share handler.
+func NewProcedureShareHandler(store procedureshare.Store, testProcedureStore testprocedure.Store, userStore user.Store, testProcedures *TestProcedureHandler, log logger.Logger) *ProcedureShareHandler {
+	return &ProcedureShareHandler{
+		store:              store,
+		testProcedureStore: testProcedureStore,
+		userStore:          userStore,
+		testProcedures:     testProcedures,
+		logger:             log,
+	}
+}
+
+// ShareProcedureRequest represents a request to share a procedure with a
+// user, or change what they may do with it.
+type ShareProcedureRequest struct {
+	Access procedureshare.Access `json:"access"`
+}
+
+// sharedProcedure is a procedure shared with the caller, with the project it
+// belongs to and its name, since shared procedures span projects.
+type sharedProcedure struct {
+	procedureshare.Share
+	ProjectID     uuid.UUID `json:"project_id"`
+	ProcedureName string    `json:"procedure_name"`
+}
+
+// List handles GET /procedures/{id}/shares.
+func (h *ProcedureShareHandler) List(w http.ResponseWriter, r *http.Request) {
+	proc, ok := h.procedureOwner(w, r)
+	if !ok {
+		return
+	}
+
+	shares, err := h.store.ListByProcedure(r.Context(), proc.ID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to list procedure shares", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": proc.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list procedure shares")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": shares,
+		"total": len(shares),
+	})
+}
+
+// Save handles PUT /procedures/{id}/shares/{user_id}: the procedure is
+// shared with the user with the access in the ShareProcedureRequest, or the
+// access of its share with them changes.
+func (h *ProcedureShareHandler) Save(w http.ResponseWriter, r *http.Request) {
+	proc, ok := h.procedureOwner(w, r)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDOrRespond(w, r, "user_id", "user")
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	var req ShareProcedureRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !req.Access.IsValid() {
+		respondError(w, http.StatusBadRequest, procedureshare.ErrInvalidAccess.Error())
+		return
+	}
+
+	owner, err := h.testProcedures.owners.ProcedureOwner(r.Context(), proc.ID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to resolve test procedure owner", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": proc.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to share test procedure")
+		return
+	}
+	if targetID == userID || targetID == owner.UserID {
+		respondError(w, http.StatusBadRequest, "the procedure cannot be shared with the project's owner")
+		return
+	}
+	target, err := h.userStore.GetByID(r.Context(), targetID)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to get user")
+		return
+	}
+	if err != nil || target.IsServiceAccount() {
+		respondError(w, http.StatusBadRequest, "procedures can only be shared with active users")
+		return
+	}
+
+	share := &procedureshare.Share{
+		ProcedureID: proc.ID,
+		UserID:      targetID,
+		Access:      req.Access,
+		SharedBy:    userID,
+	}
+	if err := h.store.Save(r.Context(), share); err != nil {
+		if errors.Is(err, procedureshare.ErrInvalidShare) || errors.Is(err, procedureshare.ErrInvalidAccess) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error(r.Context(), "failed to share test procedure", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": proc.ID,
+			"user_id":           targetID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to share test procedure")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, share)
+}
+
+// Delete handles DELETE /procedures/{id}/shares/{user_id}: the procedure is
+// no longer shared with the user.
+func (h *ProcedureShareHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	proc, ok := h.procedureOwner(w, r)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDOrRespond(w, r, "user_id", "user")
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), proc.ID, targetID); err != nil {
+		if errors.Is(err, procedureshare.ErrShareNotFound) {
+			respondError(w, http.StatusNotFound, "share not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to delete procedure share", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": proc.ID,
+			"user_id":           targetID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to delete procedure share")
+		return
+	}
+
+	respondSuccess(w, "procedure share deleted successfully")
+}
+
+// ListMine handles GET /procedures/shared: the procedures shared with the
+// caller across projects, most recently shared first, named after their
+// latest committed version.
+func (h *ProcedureShareHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	shares, err := h.store.ListByUser(r.Context(), userID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to list shared procedures", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list shared procedures")
+		return
+	}
+
+	items := make([]sharedProcedure, 0, len(shares))
+	for _, share := range shares {
+		tp, err := h.testProcedureStore.GetLatestCommitted(r.Context(), share.ProcedureID)
+		if errors.Is(err, testprocedure.ErrNoCommittedVersion) {
+			tp, err = h.testProcedureStore.GetByID(r.Context(), share.ProcedureID)
+		}
+		if err != nil {
+			if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+				continue
+			}
+			h.logger.Error(r.Context(), "failed to get shared test procedure", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": share.ProcedureID,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to list shared procedures")
+			return
+		}
+		items = append(items, sharedProcedure{
+			Share:         *share,
+			ProjectID:     tp.ProjectID,
+			ProcedureName: tp.Name,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// procedureOwner checks that the caller can access the project of the
+// procedure in the URL, which users it is shared with cannot, and returns
+// the procedure's first version, which its shares are attached to. Returns
+// false if the check fails (response already written).
+func (h *ProcedureShareHandler) procedureOwner(w http.ResponseWriter, r *http.Request) (*testprocedure.TestProcedure, bool) {
+	procedureID, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
+	if !ok {
+		return nil, false
+	}
+	if !h.testProcedures.checkProcedureOwner(w, r, procedureID) {
+		return nil, false
+	}
+
+	proc, err := h.testProcedureStore.GetByID(r.Context(), procedureID)
+	if err == nil && proc.ParentID != nil {
+		proc, err = h.testProcedureStore.GetByID(r.Context(), *proc.ParentID)
+	}
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get test procedure", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": procedureID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return nil, false
+	}
+	return proc, true
+}
@@ -5,21 +5,29 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
 	"github.com/hairizuanbinnoorazman/ui-automation/project"
 )
 
 // ProjectHandler handles project-related requests.
 type ProjectHandler struct {
-	projectStore project.Store
-	logger       logger.Logger
+	projectStore     project.Store
+	integrationStore integration.Store
+	owners           *ownership.Resolver
+	logger           logger.Logger
 }
 
-// NewProjectHandler creates a new project handler.
-func NewProjectHandler(projectStore project.Store, log logger.Logger) *ProjectHandler {
+// NewProjectHandler creates a new project handler. The integration store is
+// used to check default integrations set on projects.
+func NewProjectHandler(projectStore project.Store, integrationStore integration.Store, owners *ownership.Resolver, log logger.Logger) *ProjectHandler {
 	return &ProjectHandler{
-		projectStore: projectStore,
-		logger:       log,
+		projectStore:     projectStore,
+		integrationStore: integrationStore,
+		owners:           owners,
+		logger:           log,
 	}
 }
 
@@ -29,10 +37,15 @@ type CreateProjectRequest struct {
 	Description string `json:"description"`
 }
 
-// UpdateProjectRequest represents a project update request.
+// UpdateProjectRequest represents a project update request. An empty
+// default_integration_id removes the default integration.
 type UpdateProjectRequest struct {
-	Name        *string `json:"name,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Name                   *string `json:"name,omitempty"`
+	Description            *string `json:"description,omitempty"`
+	DefaultIntegrationID   *string `json:"default_integration_id,omitempty"`
+	DefaultIssueProjectKey *string `json:"default_issue_project_key,omitempty"`
+	DefaultIssueRepository *string `json:"default_issue_repository,omitempty"`
+	RequireProcedureReview *bool   `json:"require_procedure_review,omitempty"`
 }
 
 // Create handles creating a new project.
@@ -176,6 +189,22 @@ func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Description != nil {
 		setters = append(setters, project.SetDescription(*req.Description))
 	}
+	if req.DefaultIntegrationID != nil {
+		integrationID, ok := h.parseDefaultIntegration(w, r, *req.DefaultIntegrationID)
+		if !ok {
+			return
+		}
+		setters = append(setters, project.SetDefaultIntegration(integrationID))
+	}
+	if req.DefaultIssueProjectKey != nil {
+		setters = append(setters, project.SetDefaultIssueProjectKey(*req.DefaultIssueProjectKey))
+	}
+	if req.DefaultIssueRepository != nil {
+		setters = append(setters, project.SetDefaultIssueRepository(*req.DefaultIssueRepository))
+	}
+	if req.RequireProcedureReview != nil {
+		setters = append(setters, project.SetRequireProcedureReview(*req.RequireProcedureReview))
+	}
 
 	if len(setters) == 0 {
 		respondError(w, http.StatusBadRequest, "no fields to update")
@@ -183,12 +212,14 @@ func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update project
-	if err := h.projectStore.Update(r.Context(), id, setters...); err != nil {
+	err := h.projectStore.Update(r.Context(), id, setters...)
+	h.owners.ForgetProject(r.Context(), id)
+	if err != nil {
 		if errors.Is(err, project.ErrProjectNotFound) {
 			respondError(w, http.StatusNotFound, "project not found")
 			return
 		}
-		if errors.Is(err, project.ErrInvalidProjectName) {
+		if errors.Is(err, project.ErrInvalidProjectName) || errors.Is(err, project.ErrInvalidIssueRepository) {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -214,6 +245,40 @@ func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, updatedProject)
 }
 
+// parseDefaultIntegration parses the default integration of an update
+// request, which must be an integration of the authenticated user. It
+// returns nil for an empty ID, which removes the default. Returns false if
+// the integration is invalid (response already written).
+func (h *ProjectHandler) parseDefaultIntegration(w http.ResponseWriter, r *http.Request, id string) (*uuid.UUID, bool) {
+	if id == "" {
+		return nil, true
+	}
+
+	integrationID, err := uuid.Parse(id)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid default_integration_id")
+		return nil, false
+	}
+
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return nil, false
+	}
+
+	integ, err := h.integrationStore.GetIntegrationByID(r.Context(), integrationID)
+	if err != nil && !errors.Is(err, integration.ErrIntegrationNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to verify integration")
+		return nil, false
+	}
+	if err != nil || integ.UserID != userID {
+		respondError(w, http.StatusBadRequest, "default integration not found")
+		return nil, false
+	}
+
+	return &integrationID, true
+}
+
 // Delete handles soft deleting a project.
 func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	// Extract project ID from URL
@@ -223,7 +288,9 @@ func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete project
-	if err := h.projectStore.Delete(r.Context(), id); err != nil {
+	err := h.projectStore.Delete(r.Context(), id)
+	h.owners.ForgetProject(r.Context(), id)
+	if err != nil {
 		if errors.Is(err, project.ErrProjectNotFound) {
 			respondError(w, http.StatusNotFound, "project not found")
 			return
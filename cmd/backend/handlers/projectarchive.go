@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/projectarchive"
+	"github.com/hairizuanbinnoorazman/ui-automation/quota"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+)
+
+// maxProjectArchiveSize is the largest project archive accepted by Import.
+const maxProjectArchiveSize = 100 << 20
+
+// ProjectArchiveHandler handles exporting and importing whole projects.
+type ProjectArchiveHandler struct {
+	archiveStore projectarchive.Store
+	storage      storage.BlobStorage
+	quotas       *quota.Enforcer
+	logger       logger.Logger
+}
+
+// NewProjectArchiveHandler creates a new project archive handler.
+func NewProjectArchiveHandler(archiveStore projectarchive.Store, storage storage.BlobStorage, quotas *quota.Enforcer, log logger.Logger) *ProjectArchiveHandler {
+	return &ProjectArchiveHandler{
+		archiveStore: archiveStore,
+		storage:      storage,
+		quotas:       quotas,
+		logger:       log,
+	}
+}
+
+// ImportProjectResponse is the response for Import. MissingAssets counts
+// assets whose content could not be copied, either because it is not in blob
+// storage or because it belongs to a project the importer does not own;
+// their records are imported regardless.
+type ImportProjectResponse struct {
+	*projectarchive.ImportResult
+	MissingAssets int `json:"missing_assets"`
+}
+
+// Export handles GET /projects/{id}/export, returning the project's archive
+// as a JSON download. Project ownership is checked by the project
+// authorization middleware.
+func (h *ProjectArchiveHandler) Export(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	archive, err := h.archiveStore.Export(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to export project")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%s.json"`, id))
+	respondJSON(w, http.StatusOK, archive)
+}
+
+// Import handles POST /projects/import, creating a new project owned by the
+// authenticated user from an archive produced by Export. The optional name
+// query parameter renames the imported project.
+func (h *ProjectArchiveHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxProjectArchiveSize)
+	var archive projectarchive.Archive
+	if err := parseJSON(r, &archive, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid archive")
+		return
+	}
+
+	if !handleStorageQuotaError(w, r, h.quotas.CheckNew(r.Context(), archive.AssetBytes()), uuid.Nil, h.logger) {
+		return
+	}
+
+	result, err := h.archiveStore.Import(r.Context(), &archive, userID, r.URL.Query().Get("name"))
+	if err != nil {
+		if errors.Is(err, projectarchive.ErrUnsupportedFormat) ||
+			errors.Is(err, projectarchive.ErrInvalidArchive) ||
+			errors.Is(err, project.ErrInvalidProjectName) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to import project")
+		return
+	}
+
+	// Copy asset content so the imported runs do not share blobs with the
+	// originals, which would be removed when an original asset is deleted.
+	missing := result.Assets - len(result.AssetCopies)
+	for _, c := range result.AssetCopies {
+		if err := h.copyBlob(r, c); err != nil {
+			h.logger.Warn(r.Context(), "failed to copy asset for imported project", map[string]interface{}{
+				"error":      err.Error(),
+				"from":       c.From,
+				"to":         c.To,
+				"project_id": result.Project.ID,
+			})
+			missing++
+		}
+	}
+
+	respondJSON(w, http.StatusCreated, ImportProjectResponse{
+		ImportResult:  result,
+		MissingAssets: missing,
+	})
+}
+
+// copyBlob copies one asset's content to its imported path.
+func (h *ProjectArchiveHandler) copyBlob(r *http.Request, c projectarchive.AssetCopy) error {
+	reader, err := h.storage.Download(r.Context(), c.From)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return h.storage.Upload(r.Context(), c.To, reader)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ratelimit"
+)
+
+// RateLimitMiddleware rejects requests that exceed the limiter's rule with
+// 429 Too Many Requests and a Retry-After header. Requests made with an API
+// token are limited per token; session requests are limited per user. It must
+// run after AuthMiddleware so the caller is known. The limiter's rule may
+// change while the server runs.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := rateLimitKey(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter := limiter.Allow(key)
+			if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				if seconds < 1 {
+					seconds = 1
+				}
+				log.Warn(r.Context(), "rate limit exceeded", map[string]interface{}{
+					"key":         key,
+					"path":        r.URL.Path,
+					"retry_after": seconds,
+				})
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				respondError(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey returns the bucket key for the authenticated caller.
+func rateLimitKey(r *http.Request) (string, bool) {
+	if tokenID, ok := GetTokenID(r.Context()); ok {
+		return "token:" + tokenID.String(), true
+	}
+	if userID, ok := GetUserID(r.Context()); ok {
+		return "user:" + userID.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ratelimit"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	userID := uuid.New()
+	tokenA := uuid.New()
+	tokenB := uuid.New()
+
+	newRequest := func(tokenID *uuid.UUID) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs", nil)
+		ctx := context.WithValue(req.Context(), UserIDKey, userID)
+		if tokenID != nil {
+			ctx = context.WithValue(ctx, TokenIDKey, *tokenID)
+		}
+		return req.WithContext(ctx)
+	}
+
+	t.Run("rejects over limit with retry after", func(t *testing.T) {
+		t.Parallel()
+		limiter := ratelimit.NewLimiter(ratelimit.Rule{RequestsPerMinute: 1, Burst: 1})
+		handler := RateLimitMiddleware(limiter, logger.NewTestLogger())(okHandler)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("first request status = %d, want %d", w.Code, http.StatusOK)
+		}
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(nil))
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("second request status = %d, want %d", w.Code, http.StatusTooManyRequests)
+		}
+		if got := w.Header().Get("Retry-After"); got == "" || got == "0" {
+			t.Errorf("Retry-After = %q, want positive seconds", got)
+		}
+	})
+
+	t.Run("tokens are limited separately from each other and the session", func(t *testing.T) {
+		t.Parallel()
+		limiter := ratelimit.NewLimiter(ratelimit.Rule{RequestsPerMinute: 1, Burst: 1})
+		handler := RateLimitMiddleware(limiter, logger.NewTestLogger())(okHandler)
+
+		for _, req := range []*http.Request{newRequest(&tokenA), newRequest(&tokenB), newRequest(nil)} {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest(&tokenA))
+		if w.Code != http.StatusTooManyRequests {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+		}
+	})
+
+	t.Run("disabled limiter passes through", func(t *testing.T) {
+		t.Parallel()
+		limiter := ratelimit.NewLimiter(ratelimit.Rule{})
+		handler := RateLimitMiddleware(limiter, logger.NewTestLogger())(okHandler)
+
+		for i := 0; i < 10; i++ {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newRequest(nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+		}
+	})
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/release"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// ReleaseHandler handles release requests and tagging runs with releases.
+type ReleaseHandler struct {
+	store        release.Store
+	testRunStore testrun.Store
+	logger       logger.Logger
+}
+
+// NewReleaseHandler creates a new release handler. Run routes are on the run
+// router, whose middleware checks the run's owner.
+func NewReleaseHandler(store release.Store, testRunStore testrun.Store, log logger.Logger) *ReleaseHandler {
+	return &ReleaseHandler{
+		store:        store,
+		testRunStore: testRunStore,
+		logger:       log,
+	}
+}
+
+// CreateReleaseRequest represents a release creation request.
+type CreateReleaseRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+}
+
+// UpdateReleaseRequest represents a release update request.
+type UpdateReleaseRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	// ClearDueDate removes the due date.
+	ClearDueDate bool `json:"clear_due_date,omitempty"`
+}
+
+// TagRunRequest represents a request to tag a run with a release. A null
+// release_id untags the run.
+type TagRunRequest struct {
+	ReleaseID *uuid.UUID `json:"release_id"`
+}
+
+// isReleaseValidationError reports whether err is a release validation error.
+func isReleaseValidationError(err error) bool {
+	return errors.Is(err, release.ErrInvalidName) ||
+		errors.Is(err, release.ErrDuplicateName)
+}
+
+// getRelease loads the release in the URL and checks that it belongs to the
+// project in the URL. Returns false if it does not (response already
+// written).
+func (h *ReleaseHandler) getRelease(w http.ResponseWriter, r *http.Request) (*release.Release, bool) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return nil, false
+	}
+	releaseID, ok := parseUUIDOrRespond(w, r, "release_id", "release")
+	if !ok {
+		return nil, false
+	}
+
+	rel, err := h.store.GetByID(r.Context(), releaseID)
+	if err != nil {
+		if errors.Is(err, release.ErrReleaseNotFound) {
+			respondError(w, http.StatusNotFound, "release not found")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get release")
+		return nil, false
+	}
+	if rel.ProjectID != projectID {
+		respondError(w, http.StatusNotFound, "release not found")
+		return nil, false
+	}
+
+	return rel, true
+}
+
+// List handles GET /projects/{id}/releases.
+func (h *ReleaseHandler) List(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	releases, err := h.store.ListByProject(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list releases")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": releases,
+		"total": len(releases),
+	})
+}
+
+// Create handles POST /projects/{id}/releases.
+func (h *ReleaseHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	var req CreateReleaseRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rel := &release.Release{
+		ProjectID:   projectID,
+		Name:        req.Name,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+		CreatedBy:   userID,
+	}
+	if err := h.store.Create(r.Context(), rel); err != nil {
+		if isReleaseValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to create release")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, rel)
+}
+
+// GetByID handles GET /projects/{id}/releases/{release_id}.
+func (h *ReleaseHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	rel, ok := h.getRelease(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rel)
+}
+
+// Update handles PUT /projects/{id}/releases/{release_id}.
+func (h *ReleaseHandler) Update(w http.ResponseWriter, r *http.Request) {
+	rel, ok := h.getRelease(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateReleaseRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var setters []release.UpdateSetter
+	if req.Name != nil {
+		setters = append(setters, release.SetName(*req.Name))
+	}
+	if req.Description != nil {
+		setters = append(setters, release.SetDescription(*req.Description))
+	}
+	if req.DueDate != nil {
+		setters = append(setters, release.SetDueDate(*req.DueDate))
+	}
+	if req.ClearDueDate {
+		setters = append(setters, release.ClearDueDate())
+	}
+
+	if len(setters) == 0 {
+		respondError(w, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	if err := h.store.Update(r.Context(), rel.ID, setters...); err != nil {
+		if errors.Is(err, release.ErrReleaseNotFound) {
+			respondError(w, http.StatusNotFound, "release not found")
+			return
+		}
+		if isReleaseValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to update release")
+		return
+	}
+
+	updated, err := h.store.GetByID(r.Context(), rel.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get updated release")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// Delete handles DELETE /projects/{id}/releases/{release_id}. Runs tagged
+// with the release are kept and untagged.
+func (h *ReleaseHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	rel, ok := h.getRelease(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), rel.ID); err != nil {
+		if errors.Is(err, release.ErrReleaseNotFound) {
+			respondError(w, http.StatusNotFound, "release not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete release")
+		return
+	}
+
+	respondSuccess(w, "release deleted successfully")
+}
+
+// Report handles GET /projects/{id}/releases/{release_id}/report. It
+// reports how many of the project's procedures the release's runs covered
+// and how many of those passed, for go/no-go decisions.
+func (h *ReleaseHandler) Report(w http.ResponseWriter, r *http.Request) {
+	rel, ok := h.getRelease(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.store.Report(r.Context(), rel)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get release report")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// TagRun handles PUT /runs/{run_id}/release. It tags the run with a release
+// of the run's project, or untags it if release_id is null.
+func (h *ReleaseHandler) TagRun(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	var req TagRunRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	setter := testrun.ClearRelease()
+	if req.ReleaseID != nil {
+		owner, _ := GetRunOwner(r.Context())
+		rel, err := h.store.GetByID(r.Context(), *req.ReleaseID)
+		if err != nil && !errors.Is(err, release.ErrReleaseNotFound) {
+			respondError(w, http.StatusInternalServerError, "failed to get release")
+			return
+		}
+		if err != nil || rel.ProjectID != owner.ProjectID {
+			respondError(w, http.StatusBadRequest, "release not found in the run's project")
+			return
+		}
+		setter = testrun.SetRelease(rel.ID)
+	}
+
+	if err := h.testRunStore.Update(r.Context(), runID, setter); err != nil {
+		if errors.Is(err, testrun.ErrTestRunNotFound) {
+			respondError(w, http.StatusNotFound, "test run not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to tag test run")
+		return
+	}
+
+	tr, err := h.testRunStore.GetByID(r.Context(), runID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get updated test run")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tr)
+}
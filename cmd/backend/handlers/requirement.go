@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/requirement"
+)
+
+// RequirementHandler handles requirement links of procedures and the
+// traceability matrix.
+type RequirementHandler struct {
+	store          requirement.Store
+	testProcedures *TestProcedureHandler
+	integrations   *IntegrationHandler
+	logger         logger.Logger
+}
+
+// NewRequirementHandler creates a new requirement handler. Procedure
+// ownership is checked through the test procedure handler, and issues of
+// linked trackers are looked up through the integration handler.
+func NewRequirementHandler(store requirement.Store, testProcedures *TestProcedureHandler, integrations *IntegrationHandler, log logger.Logger) *RequirementHandler {
+	return &RequirementHandler{
+		store:          store,
+		testProcedures: testProcedures,
+		integrations:   integrations,
+		logger:         log,
+	}
+}
+
+// LinkRequirementRequest represents a request to link a procedure to a
+// requirement. With integration_id, requirement is the ID of an issue in
+// that integration's tracker, and the title and URL are taken from the
+// issue.
+type LinkRequirementRequest struct {
+	Requirement   string `json:"requirement"`
+	Title         string `json:"title,omitempty"`
+	URL           string `json:"url,omitempty"`
+	IntegrationID string `json:"integration_id,omitempty"`
+}
+
+// List handles GET /procedures/{procedure_id}/requirements.
+func (h *RequirementHandler) List(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return
+	}
+
+	links, err := h.store.ListByProcedure(r.Context(), rootID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list requirements")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, links)
+}
+
+// Create handles POST /procedures/{procedure_id}/requirements. The link
+// applies to every version of the procedure.
+func (h *RequirementHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	proc, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return
+	}
+
+	var req LinkRequirementRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	link := &requirement.Link{
+		ProjectID:   proc.ProjectID,
+		ProcedureID: rootID,
+		Requirement: req.Requirement,
+		Title:       req.Title,
+		URL:         req.URL,
+		CreatedBy:   userID,
+	}
+	if req.IntegrationID != "" {
+		if req.Requirement == "" {
+			respondError(w, http.StatusBadRequest, requirement.ErrInvalidRequirement.Error())
+			return
+		}
+		integ, issue, ok := h.integrations.fetchIssue(w, r, req.IntegrationID, req.Requirement)
+		if !ok {
+			return
+		}
+		link.Requirement = issue.ExternalID
+		link.Title = issue.Title
+		link.URL = issue.URL
+		link.IntegrationID = &integ.ID
+	}
+
+	if err := h.store.Create(r.Context(), link); err != nil {
+		if errors.Is(err, requirement.ErrInvalidRequirement) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, requirement.ErrDuplicateLink) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to link requirement")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, link)
+}
+
+// Delete handles DELETE /procedures/{procedure_id}/requirements/{link_id}.
+func (h *RequirementHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return
+	}
+	linkID, ok := parseUUIDOrRespond(w, r, "link_id", "requirement link")
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), rootID, linkID); err != nil {
+		if errors.Is(err, requirement.ErrLinkNotFound) {
+			respondError(w, http.StatusNotFound, "requirement link not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to unlink requirement")
+		return
+	}
+
+	respondSuccess(w, "requirement unlinked successfully")
+}
+
+// Matrix handles GET /projects/{id}/traceability. It maps each requirement
+// to the procedures that verify it and the status of their latest runs.
+func (h *RequirementHandler) Matrix(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	matrix, err := h.store.Matrix(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to build traceability matrix")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, matrix)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/retention"
+)
+
+// RetentionHandler handles project retention policy requests. All routes are
+// registered on the project router, whose authorization middleware has
+// already verified ownership.
+type RetentionHandler struct {
+	store   retention.Store
+	cleaner *retention.Cleaner
+	logger  logger.Logger
+}
+
+// NewRetentionHandler creates a new retention policy handler.
+func NewRetentionHandler(store retention.Store, cleaner *retention.Cleaner, log logger.Logger) *RetentionHandler {
+	return &RetentionHandler{
+		store:   store,
+		cleaner: cleaner,
+		logger:  log,
+	}
+}
+
+// UpdateRetentionPolicyRequest represents a retention policy update request.
+type UpdateRetentionPolicyRequest struct {
+	AssetRetentionDays int `json:"asset_retention_days"`
+}
+
+// getPolicy returns the project's retention policy, or a policy that keeps
+// everything if none has been set. Returns false if it fails (response
+// already written).
+func (h *RetentionHandler) getPolicy(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) (*retention.Policy, bool) {
+	policy, err := h.store.Get(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, retention.ErrPolicyNotFound) {
+			return &retention.Policy{ProjectID: projectID}, true
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get retention policy")
+		return nil, false
+	}
+	return policy, true
+}
+
+// Get handles GET /projects/{id}/retention.
+func (h *RetentionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	policy, ok := h.getPolicy(w, r, projectID)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+// Update handles PUT /projects/{id}/retention. Setting asset_retention_days
+// to 0 keeps assets forever.
+func (h *RetentionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req UpdateRetentionPolicyRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	policy := &retention.Policy{
+		ProjectID:          projectID,
+		AssetRetentionDays: req.AssetRetentionDays,
+		UpdatedBy:          userID,
+	}
+	if err := h.store.Upsert(r.Context(), policy); err != nil {
+		if errors.Is(err, retention.ErrInvalidRetentionDays) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to save retention policy")
+		return
+	}
+
+	policy, ok = h.getPolicy(w, r, projectID)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+// Preview handles GET /projects/{id}/retention/preview. It reports what the
+// project's policy would delete now, without deleting anything. An
+// ?asset_retention_days= override previews a policy before it is saved.
+func (h *RetentionHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	policy, ok := h.getPolicy(w, r, projectID)
+	if !ok {
+		return
+	}
+
+	if daysStr := r.URL.Query().Get("asset_retention_days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, retention.ErrInvalidRetentionDays.Error())
+			return
+		}
+		policy.AssetRetentionDays = days
+		if err := policy.Validate(); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	report, err := h.cleaner.Preview(r.Context(), policy, time.Now())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to preview retention policy")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
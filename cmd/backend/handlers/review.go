@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/activity"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/review"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// ReviewHandler handles procedure review requests. In projects that require
+// reviews, committing a draft creates a pending version that only becomes
+// the latest version once another user approves it.
+type ReviewHandler struct {
+	store              review.Store
+	testProcedureStore testprocedure.Store
+	projectStore       project.Store
+	userStore          user.Store
+	testProcedures     *TestProcedureHandler
+	logger             logger.Logger
+}
+
+// NewReviewHandler creates a new review handler. Procedure access is checked
+// and drafts of projects without reviews are committed through the test
+// procedure handler.
+func NewReviewHandler(store review.Store, testProcedureStore testprocedure.Store, projectStore project.Store, userStore user.Store, testProcedures *TestProcedureHandler, log logger.Logger) *ReviewHandler {
+	return &ReviewHandler{
+		store:              store,
+		testProcedureStore: testProcedureStore,
+		projectStore:       projectStore,
+		userStore:          userStore,
+		testProcedures:     testProcedures,
+		logger:             log,
+	}
+}
+
+// RequestReviewRequest represents a review request: the commit of the
+// pending version and, optionally, who is asked to review it. Without a
+// reviewer_id anyone else with access to the project can decide the review.
+type RequestReviewRequest struct {
+	CommitDraftRequest
+	ReviewerID *uuid.UUID `json:"reviewer_id,omitempty"`
+}
+
+// DecideReviewRequest represents an approval or rejection of a review.
+type DecideReviewRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// ReviewResponse is a review with the version under review and the
+// procedure's latest version, if it has one, to compare it against.
+type ReviewResponse struct {
+	*review.Review
+	PendingVersion *testprocedure.TestProcedure `json:"pending_version"`
+	LatestVersion  *testprocedure.TestProcedure `json:"latest_version,omitempty"`
+}
+
+// CommitDraft handles POST /procedures/{id}/draft/commit. Projects that
+// require reviews get a pending version and a review request, with 202
+// Accepted; others get the new version as before. The request body is a
+// RequestReviewRequest.
+func (h *ReviewHandler) CommitDraft(w http.ResponseWriter, r *http.Request) {
+	proc, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "id")
+	if !ok {
+		return
+	}
+	var req RequestReviewRequest
+	if err := decodeCommitRequest(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !h.checkNoPendingReview(w, r, rootID) {
+		return
+	}
+
+	proj, err := h.projectStore.GetByID(r.Context(), proc.ProjectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get project")
+		return
+	}
+	if !proj.RequireProcedureReview {
+		h.testProcedures.commitDraft(w, r, proc.ID, req.note())
+		return
+	}
+
+	h.requestReview(w, r, proc, rootID, req, http.StatusAccepted)
+}
+
+// RequestReview handles POST /procedures/{id}/reviews: it commits the draft
+// as a pending version and asks for it to be reviewed, whether or not the
+// project requires reviews.
+func (h *ReviewHandler) RequestReview(w http.ResponseWriter, r *http.Request) {
+	proc, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "id")
+	if !ok {
+		return
+	}
+	var req RequestReviewRequest
+	if err := decodeCommitRequest(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !h.checkNoPendingReview(w, r, rootID) {
+		return
+	}
+
+	h.requestReview(w, r, proc, rootID, req, http.StatusCreated)
+}
+
+// checkNoPendingReview checks that the procedure has no pending review, as
+// its draft cannot be committed until the review is decided. Returns false
+// if it has one (response already written).
+func (h *ReviewHandler) checkNoPendingReview(w http.ResponseWriter, r *http.Request, procedureID uuid.UUID) bool {
+	_, err := h.store.GetPending(r.Context(), procedureID)
+	if err == nil {
+		respondError(w, http.StatusConflict, review.ErrReviewPending.Error())
+		return false
+	}
+	if !errors.Is(err, review.ErrReviewNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to get pending review")
+		return false
+	}
+	return true
+}
+
+// requestReview commits the draft of proc as a pending version and creates
+// a review of it, responding with status. The review's message is the
+// version's commit message.
+func (h *ReviewHandler) requestReview(w http.ResponseWriter, r *http.Request, proc *testprocedure.TestProcedure, rootID uuid.UUID, req RequestReviewRequest, status int) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	if req.ReviewerID != nil && !h.checkReviewer(w, r, userID, *req.ReviewerID) {
+		return
+	}
+
+	version, err := h.testProcedureStore.CommitDraftPending(r.Context(), proc.ID, req.note())
+	if err != nil {
+		h.testProcedures.respondCommitError(w, r, proc.ID, err)
+		return
+	}
+
+	rev := &review.Review{
+		ProjectID:   proc.ProjectID,
+		ProcedureID: rootID,
+		VersionID:   version.ID,
+		Version:     version.Version,
+		RequestedBy: userID,
+		ReviewerID:  req.ReviewerID,
+		Message:     version.CommitMessage,
+	}
+	if err := h.store.Create(r.Context(), rev); err != nil {
+		if errors.Is(err, review.ErrReviewPending) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to request review")
+		return
+	}
+
+	respondJSON(w, status, rev)
+}
+
+// checkReviewer checks that reviewerID is an active user other than the
+// requester. Returns false if not (response already written).
+func (h *ReviewHandler) checkReviewer(w http.ResponseWriter, r *http.Request, userID, reviewerID uuid.UUID) bool {
+	if reviewerID == userID {
+		respondError(w, http.StatusBadRequest, "you cannot review your own changes")
+		return false
+	}
+	reviewer, err := h.userStore.GetByID(r.Context(), reviewerID)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to get user")
+		return false
+	}
+	if err != nil || reviewer.IsServiceAccount() {
+		respondError(w, http.StatusBadRequest, "reviewer must be an active user")
+		return false
+	}
+	return true
+}
+
+// ListByProcedure handles GET /procedures/{id}/reviews.
+func (h *ReviewHandler) ListByProcedure(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "id")
+	if !ok {
+		return
+	}
+
+	reviews, err := h.store.ListByProcedure(r.Context(), rootID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list reviews")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": reviews,
+		"total": len(reviews),
+	})
+}
+
+// ListMine handles GET /reviews: the reviews the caller was asked for,
+// optionally filtered by ?status=.
+func (h *ReviewHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	status := review.Status(r.URL.Query().Get("status"))
+	if status != "" && !status.IsValid() {
+		respondError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+
+	reviews, err := h.store.ListByReviewer(r.Context(), userID, status)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list reviews")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": reviews,
+		"total": len(reviews),
+	})
+}
+
+// getReview loads the review in the URL and checks that the caller can see
+// it: its requester, its reviewer and anyone with access to the project
+// can. It also reports whether the caller has access to the project.
+// Returns false if the check fails (response already written).
+func (h *ReviewHandler) getReview(w http.ResponseWriter, r *http.Request) (*review.Review, bool, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return nil, false, false
+	}
+	reviewID, ok := parseUUIDOrRespond(w, r, "review_id", "review")
+	if !ok {
+		return nil, false, false
+	}
+
+	rev, err := h.store.GetByID(r.Context(), reviewID)
+	if err != nil {
+		if errors.Is(err, review.ErrReviewNotFound) {
+			respondError(w, http.StatusNotFound, "review not found")
+			return nil, false, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get review")
+		return nil, false, false
+	}
+
+	proj, err := h.projectStore.GetByID(r.Context(), rev.ProjectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "review not found")
+			return nil, false, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get project")
+		return nil, false, false
+	}
+
+	canAccess := canAccessProject(r, proj.ID, proj.OwnerID)
+	isReviewer := rev.ReviewerID != nil && *rev.ReviewerID == userID
+	if !canAccess && !isReviewer && rev.RequestedBy != userID {
+		respondError(w, http.StatusNotFound, "review not found")
+		return nil, false, false
+	}
+
+	return rev, canAccess, true
+}
+
+// GetByID handles GET /reviews/{review_id}.
+func (h *ReviewHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	rev, _, ok := h.getReview(w, r)
+	if !ok {
+		return
+	}
+
+	pending, err := h.testProcedureStore.GetByID(r.Context(), rev.VersionID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get version under review")
+		return
+	}
+	latest, err := h.testProcedureStore.GetLatestCommitted(r.Context(), rev.ProcedureID)
+	if err != nil && !errors.Is(err, testprocedure.ErrNoCommittedVersion) {
+		respondError(w, http.StatusInternalServerError, "failed to get latest version")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ReviewResponse{
+		Review:         rev,
+		PendingVersion: pending,
+		LatestVersion:  latest,
+	})
+}
+
+// Approve handles POST /reviews/{review_id}/approve: the version under
+// review becomes the procedure's latest version.
+func (h *ReviewHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	rev, req, ok := h.decision(w, r)
+	if !ok {
+		return
+	}
+
+	// Versions published since the review was requested would be hidden
+	latest, err := h.testProcedureStore.GetLatestCommitted(r.Context(), rev.ProcedureID)
+	if err != nil && !errors.Is(err, testprocedure.ErrNoCommittedVersion) {
+		respondError(w, http.StatusInternalServerError, "failed to get latest version")
+		return
+	}
+	if latest != nil && latest.Version > rev.Version {
+		respondError(w, http.StatusConflict, "a newer version has been published since the review was requested")
+		return
+	}
+
+	userID, _ := GetUserID(r.Context())
+	decided, ok := h.decide(w, r, rev, review.StatusApproved, userID, req.Comment)
+	if !ok {
+		return
+	}
+
+	if err := h.testProcedureStore.PublishVersion(r.Context(), rev.VersionID); err != nil {
+		h.logger.Error(r.Context(), "failed to publish approved version", map[string]interface{}{
+			"error":      err.Error(),
+			"review_id":  rev.ID,
+			"version_id": rev.VersionID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to publish approved version")
+		return
+	}
+
+	// The change is the requester's, published now
+	if version, err := h.testProcedureStore.GetByID(r.Context(), rev.VersionID); err == nil {
+		h.testProcedures.activity.Record(r.Context(), activity.ProcedureEditedEvent(rev.RequestedBy, version.ProjectID, version.ID, version.Name, version.Version))
+	}
+
+	respondJSON(w, http.StatusOK, decided)
+}
+
+// Reject handles POST /reviews/{review_id}/reject: the version under review
+// is left unpublished, and the draft can be changed and committed again.
+func (h *ReviewHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	rev, req, ok := h.decision(w, r)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(r.Context())
+	decided, ok := h.decide(w, r, rev, review.StatusRejected, userID, req.Comment)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, decided)
+}
+
+// decision loads the review in the URL and the decision in the optional
+// request body, and checks that the caller may decide the review. Returns
+// false if not (response already written).
+func (h *ReviewHandler) decision(w http.ResponseWriter, r *http.Request) (*review.Review, DecideReviewRequest, bool) {
+	var req DecideReviewRequest
+	rev, canAccess, ok := h.getReview(w, r)
+	if !ok {
+		return nil, req, false
+	}
+
+	userID, _ := GetUserID(r.Context())
+	if !rev.CanReview(userID, canAccess) {
+		if rev.RequestedBy == userID {
+			respondError(w, http.StatusForbidden, "you cannot review your own changes")
+			return nil, req, false
+		}
+		respondError(w, http.StatusForbidden, "you don't have access to review this change")
+		return nil, req, false
+	}
+	if rev.Status != review.StatusPending {
+		respondError(w, http.StatusConflict, review.ErrAlreadyDecided.Error())
+		return nil, req, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return nil, req, false
+	}
+
+	return rev, req, true
+}
+
+// decide records the decision on rev. Returns false if it fails (response
+// already written).
+func (h *ReviewHandler) decide(w http.ResponseWriter, r *http.Request, rev *review.Review, decision review.Status, userID uuid.UUID, comment string) (*review.Review, bool) {
+	decided, err := h.store.Decide(r.Context(), rev.ID, decision, userID, comment)
+	if err != nil {
+		if errors.Is(err, review.ErrAlreadyDecided) {
+			respondError(w, http.StatusConflict, err.Error())
+			return nil, false
+		}
+		if errors.Is(err, review.ErrCommentTooLong) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to decide review")
+		return nil, false
+	}
+	return decided, true
+}
+
+// Withdraw handles POST /reviews/{review_id}/withdraw. Only the requester
+// can withdraw a review; the version under review is left unpublished.
+func (h *ReviewHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	rev, _, ok := h.getReview(w, r)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(r.Context())
+	if rev.RequestedBy != userID {
+		respondError(w, http.StatusForbidden, "only the requester can withdraw a review")
+		return
+	}
+
+	withdrawn, err := h.store.Withdraw(r.Context(), rev.ID)
+	if err != nil {
+		if errors.Is(err, review.ErrAlreadyDecided) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to withdraw review")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, withdrawn)
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/dataset"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// RunGroupHandler handles run groups: batches of runs of a procedure
+// launched from a dataset, one per row, and reported on together.
+type RunGroupHandler struct {
+	runGroupStore      testrun.RunGroupStore
+	datasetStore       dataset.Store
+	testProcedureStore testprocedure.Store
+	endpointStore      endpoint.Store
+	testProcedures     *TestProcedureHandler
+	testRuns           *TestRunHandler
+	logger             logger.Logger
+}
+
+// NewRunGroupHandler creates a new run group handler. Procedure ownership
+// is checked through the test procedure handler, and runs are reported
+// through the test run handler.
+func NewRunGroupHandler(runGroupStore testrun.RunGroupStore, datasetStore dataset.Store, testProcedureStore testprocedure.Store, endpointStore endpoint.Store, testProcedures *TestProcedureHandler, testRuns *TestRunHandler, log logger.Logger) *RunGroupHandler {
+	return &RunGroupHandler{
+		runGroupStore:      runGroupStore,
+		datasetStore:       datasetStore,
+		testProcedureStore: testProcedureStore,
+		endpointStore:      endpointStore,
+		testProcedures:     testProcedures,
+		testRuns:           testRuns,
+		logger:             log,
+	}
+}
+
+// LaunchRunGroupRequest represents a request to launch a run per row of a
+// dataset. Parameters gives values shared by every row; a row's own values
+// take precedence. EnvironmentDetails gives every run the values of the
+// procedure's run template environment fields. Name defaults to the
+// dataset's name.
+type LaunchRunGroupRequest struct {
+	EndpointTarget
+	Name               string            `json:"name,omitempty"`
+	Parameters         map[string]string `json:"parameters,omitempty"`
+	EnvironmentDetails map[string]string `json:"environment_details,omitempty"`
+}
+
+// RunGroupResponse is a run group with the combined outcome of its runs.
+type RunGroupResponse struct {
+	*testrun.RunGroup
+	Summary testrun.GroupSummary `json:"summary"`
+	Runs    []*testrun.TestRun   `json:"runs,omitempty"`
+}
+
+// getGroup loads the run group in the URL after checking access to its
+// procedure. Returns false if the check fails (response already written).
+func (h *RunGroupHandler) getGroup(w http.ResponseWriter, r *http.Request) (*testrun.RunGroup, bool) {
+	id, ok := parseUUIDOrRespond(w, r, "group_id", "run group")
+	if !ok {
+		return nil, false
+	}
+
+	group, err := h.runGroupStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, testrun.ErrRunGroupNotFound) {
+			respondError(w, http.StatusNotFound, "run group not found")
+			return nil, false
+		}
+		h.logger.Error(r.Context(), "failed to get run group", map[string]interface{}{
+			"error":        err.Error(),
+			"run_group_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get run group")
+		return nil, false
+	}
+	if !h.testProcedures.checkProcedureOwnership(w, r, group.TestProcedureID) {
+		return nil, false
+	}
+
+	return group, true
+}
+
+// Launch handles POST /procedures/{procedure_id}/datasets/{dataset_id}/runs,
+// creating a run group with a pending run of the latest committed version
+// of the procedure for each row of the dataset. Every row is checked
+// against the procedure's parameters before any run is created.
+func (h *RunGroupHandler) Launch(w http.ResponseWriter, r *http.Request) {
+	ds, ok := loadProcedureDataset(w, r, h.testProcedures, h.datasetStore, h.logger)
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	latestProc, err := h.testProcedureStore.GetLatestCommitted(r.Context(), ds.TestProcedureID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to resolve latest procedure version", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": ds.TestProcedureID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return
+	}
+	if latestProc.IsArchived() {
+		respondError(w, http.StatusConflict, "test procedure is archived")
+		return
+	}
+
+	var req LaunchRunGroupRequest
+	if r.ContentLength != 0 {
+		if err := parseJSON(r, &req, h.logger); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	var ep *endpoint.Endpoint
+	if !req.EndpointTarget.IsZero() {
+		if ep, ok = resolveEndpointTarget(w, r, h.endpointStore, h.logger, userID, req.EndpointTarget); !ok {
+			return
+		}
+	}
+	tpl, ok := h.testRuns.runTemplate(w, r, ds.TestProcedureID)
+	if !ok {
+		return
+	}
+	environment := ""
+	if ep != nil {
+		environment = ep.Environment
+	}
+	if !h.testRuns.checkConcurrentRun(w, r, tpl, ds.TestProcedureID, environment) {
+		return
+	}
+
+	runs := make([]*testrun.TestRun, len(ds.Rows))
+	for i, row := range ds.Rows {
+		values := make(map[string]string, len(req.Parameters)+len(row))
+		for name, value := range req.Parameters {
+			values[name] = value
+		}
+		for name, value := range row {
+			values[name] = value
+		}
+
+		run := &testrun.TestRun{
+			TestProcedureID: latestProc.ID,
+			ExecutedBy:      userID,
+			Status:          testrun.StatusPending,
+			DatasetRow:      &i,
+		}
+		if ep != nil {
+			run.EndpointID = &ep.ID
+			run.Environment = ep.Environment
+			run.BaseURL = ep.URL
+		}
+		run.Parameters, err = latestProc.Parameters.ResolveWithBaseURL(values, run.BaseURL)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("row %d: %v", i+1, err))
+			return
+		}
+		if err := tpl.Apply(run, req.EnvironmentDetails); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		runs[i] = run
+	}
+
+	name := req.Name
+	if name == "" {
+		name = ds.Name
+	}
+	group := &testrun.RunGroup{
+		TestProcedureID: latestProc.ID,
+		DatasetID:       &ds.ID,
+		Name:            name,
+		CreatedBy:       userID,
+	}
+	if err := h.runGroupStore.Create(r.Context(), group, runs); err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to create run group")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, RunGroupResponse{
+		RunGroup: group,
+		Summary:  testrun.Summarize(runs),
+		Runs:     runs,
+	})
+}
+
+// List handles GET /procedures/{procedure_id}/run-groups, listing the run
+// groups of every version of the procedure, newest first.
+func (h *RunGroupHandler) List(w http.ResponseWriter, r *http.Request) {
+	procedureID, ok := parseUUIDOrRespond(w, r, "procedure_id", "test procedure")
+	if !ok {
+		return
+	}
+	if !h.testProcedures.checkProcedureOwnership(w, r, procedureID) {
+		return
+	}
+
+	versions, err := h.testProcedureStore.GetVersionHistory(r.Context(), procedureID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure versions")
+		return
+	}
+	ids := make([]uuid.UUID, len(versions))
+	for i, version := range versions {
+		ids[i] = version.ID
+	}
+
+	limit, offset := parsePagination(r)
+	total, err := h.runGroupStore.CountByTestProcedures(r.Context(), ids)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to count run groups")
+		return
+	}
+	groups, err := h.runGroupStore.ListByTestProcedures(r.Context(), ids, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list run groups")
+		return
+	}
+
+	items := make([]RunGroupResponse, len(groups))
+	for i, group := range groups {
+		runs, err := h.runGroupStore.ListRuns(r.Context(), group.ID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to list run group runs")
+			return
+		}
+		items[i] = RunGroupResponse{RunGroup: group, Summary: testrun.Summarize(runs)}
+	}
+
+	respondJSON(w, http.StatusOK, NewPaginatedResponse(items, total, limit, offset))
+}
+
+// GetByID handles GET /run-groups/{group_id}, returning the group with its
+// runs and their combined outcome.
+func (h *RunGroupHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.getGroup(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := h.runGroupStore.ListRuns(r.Context(), group.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list run group runs")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, RunGroupResponse{
+		RunGroup: group,
+		Summary:  testrun.Summarize(runs),
+		Runs:     runs,
+	})
+}
+
+// Report handles GET /run-groups/{group_id}/report, reporting every run of
+// the group at once as JUnit XML (?format=junit) or to a GitHub Actions job
+// (?format=github-actions, the default).
+func (h *RunGroupHandler) Report(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "github-actions"
+	}
+	if format != "github-actions" && format != "junit" {
+		respondError(w, http.StatusBadRequest, "format must be github-actions or junit")
+		return
+	}
+
+	group, ok := h.getGroup(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := h.runGroupStore.ListRuns(r.Context(), group.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list run group runs")
+		return
+	}
+	reported := make([]*testrun.ReportedRun, len(runs))
+	for i, run := range runs {
+		if reported[i], ok = h.testRuns.loadReportedRun(w, r, run.ID); !ok {
+			return
+		}
+	}
+
+	if format == "junit" {
+		h.testRuns.respondJUnit(w, r, testrun.NewJUnitReport(reported), "run-group-junit.xml")
+		return
+	}
+	respondJSON(w, http.StatusOK, testrun.NewGitHubActionsReport(reported))
+}
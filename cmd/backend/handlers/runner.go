@@ -0,0 +1,500 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/runner"
+)
+
+// maxRunnerLogLines is the most log lines a runner may send in one request.
+const maxRunnerLogLines = 500
+
+// RunnerHandler handles requests from agent runners, which claim the
+// ui_exploration jobs of endpoints that use them, run the agent next to the
+// application under test and report back. Runners authenticate with an API
+// token of the user whose jobs they run, and register with the labels that
+// decide which endpoints' jobs they may claim.
+type RunnerHandler struct {
+	runnerStore runner.Store
+	jobStore    job.Store
+	logStore    job.LogStore
+	pipeline    *agent.Pipeline
+	timeLimit   time.Duration
+	logger      logger.Logger
+}
+
+// NewRunnerHandler creates a new runner handler. timeLimit is how long a
+// runner may run a job.
+func NewRunnerHandler(runnerStore runner.Store, jobStore job.Store, logStore job.LogStore, pipeline *agent.Pipeline, timeLimit time.Duration, log logger.Logger) *RunnerHandler {
+	return &RunnerHandler{
+		runnerStore: runnerStore,
+		jobStore:    jobStore,
+		logStore:    logStore,
+		pipeline:    pipeline,
+		timeLimit:   timeLimit,
+		logger:      log,
+	}
+}
+
+// RegisterRunnerRequest represents a request to register a runner.
+type RegisterRunnerRequest struct {
+	Name    string        `json:"name"`
+	Labels  runner.Labels `json:"labels"`
+	Version string        `json:"version"`
+}
+
+// RunnerResponse is a registered runner with whether it is online.
+type RunnerResponse struct {
+	*runner.Runner
+	Online bool `json:"online"`
+}
+
+// ClaimRunnerJobRequest identifies the registered runner claiming a job.
+// Runners that have not registered may only claim the jobs of endpoints
+// that require no labels.
+type ClaimRunnerJobRequest struct {
+	RunnerID *uuid.UUID `json:"runner_id,omitempty"`
+}
+
+// ClaimRunnerJobResponse is a job claimed by a runner.
+type ClaimRunnerJobResponse struct {
+	agent.RemoteJob
+	TimeLimitSeconds int `json:"time_limit_seconds"`
+}
+
+// RunnerHeartbeatRequest reports that a runner is still running a job,
+// with the agent's latest progress when it has reported any.
+type RunnerHeartbeatRequest struct {
+	ProgressDone  *int `json:"progress_done,omitempty"`
+	ProgressTotal *int `json:"progress_total,omitempty"`
+}
+
+// RunnerLogsRequest is a batch of the agent's log lines.
+type RunnerLogsRequest struct {
+	Lines []string `json:"lines"`
+}
+
+// RunnerFailRequest reports that a runner could not run a job.
+type RunnerFailRequest struct {
+	Error string `json:"error"`
+}
+
+// checkRunnerJob verifies that the authenticated user created the job and
+// that it is still running. A job that is no longer running, such as one
+// stopped by the user, gets 409 Conflict so the runner stops working on it.
+// Returns false if the check fails (response already written).
+func (h *RunnerHandler) checkRunnerJob(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) bool {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return false
+	}
+
+	j, err := h.jobStore.GetByID(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, job.ErrJobNotFound) {
+			respondError(w, http.StatusNotFound, "job not found")
+			return false
+		}
+		h.logger.Error(r.Context(), "failed to get job for runner", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get job")
+		return false
+	}
+
+	if j.CreatedBy != userID {
+		respondError(w, http.StatusForbidden, "you don't have access to this job")
+		return false
+	}
+	if j.Status != job.StatusRunning {
+		respondError(w, http.StatusConflict, "job is no longer running")
+		return false
+	}
+	return true
+}
+
+// getOwnRunner fetches a runner registered by the authenticated user.
+// Runners registered by other users are reported as not found. Returns
+// false if the lookup fails (response already written).
+func (h *RunnerHandler) getOwnRunner(w http.ResponseWriter, r *http.Request, id uuid.UUID) (*runner.Runner, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return nil, false
+	}
+
+	rn, err := h.runnerStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, runner.ErrRunnerNotFound) {
+			respondError(w, http.StatusNotFound, "runner not found")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get runner")
+		return nil, false
+	}
+	if rn.CreatedBy != userID {
+		respondError(w, http.StatusNotFound, "runner not found")
+		return nil, false
+	}
+	return rn, true
+}
+
+// Register handles POST /runner/runners, registering a runner of the
+// authenticated user.
+func (h *RunnerHandler) Register(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req RegisterRunnerRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rn := &runner.Runner{
+		Name:      req.Name,
+		Labels:    req.Labels,
+		Version:   req.Version,
+		CreatedBy: userID,
+	}
+	if err := h.runnerStore.Create(r.Context(), rn); err != nil {
+		if errors.Is(err, runner.ErrInvalidRunnerName) || errors.Is(err, runner.ErrInvalidLabel) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to register runner")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, RunnerResponse{Runner: rn, Online: true})
+}
+
+// List handles GET /runner/runners, listing the authenticated user's runners.
+func (h *RunnerHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	runners, err := h.runnerStore.ListByCreator(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list runners")
+		return
+	}
+
+	now := time.Now()
+	response := make([]RunnerResponse, 0, len(runners))
+	for _, rn := range runners {
+		response = append(response, RunnerResponse{Runner: rn, Online: rn.Online(now)})
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// RunnerHeartbeat handles POST /runner/runners/{id}/heartbeat, recording that
+// a registered runner is still running. Responds with 404 Not Found once the
+// runner has been deregistered, so it registers again.
+func (h *RunnerHandler) RunnerHeartbeat(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "runner")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.getOwnRunner(w, r, id); !ok {
+		return
+	}
+
+	if err := h.runnerStore.Heartbeat(r.Context(), id, time.Now()); err != nil {
+		if errors.Is(err, runner.ErrRunnerNotFound) {
+			respondError(w, http.StatusNotFound, "runner not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to record heartbeat")
+		return
+	}
+
+	respondSuccess(w, "heartbeat recorded")
+}
+
+// Deregister handles DELETE /runner/runners/{id}.
+func (h *RunnerHandler) Deregister(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "runner")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.getOwnRunner(w, r, id); !ok {
+		return
+	}
+
+	if err := h.runnerStore.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, runner.ErrRunnerNotFound) {
+			respondError(w, http.StatusNotFound, "runner not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to deregister runner")
+		return
+	}
+
+	respondSuccess(w, "runner deregistered successfully")
+}
+
+// Claim handles POST /runner/jobs/claim, claiming the caller's oldest queued
+// ui_exploration job against an endpoint that uses agent runners and whose
+// required labels the claiming runner has. The request body is optional.
+// Responds with 204 No Content when there is no such job.
+func (h *RunnerHandler) Claim(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req ClaimRunnerJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var labels runner.Labels
+	if req.RunnerID != nil {
+		rn, ok := h.getOwnRunner(w, r, *req.RunnerID)
+		if !ok {
+			return
+		}
+		if err := h.runnerStore.Heartbeat(r.Context(), rn.ID, time.Now()); err != nil && !errors.Is(err, runner.ErrRunnerNotFound) {
+			h.logger.Warn(r.Context(), "failed to record runner heartbeat", map[string]interface{}{
+				"error":     err.Error(),
+				"runner_id": rn.ID,
+			})
+		}
+		labels = rn.Labels
+	}
+
+	remote, err := h.pipeline.ClaimRemote(r.Context(), userID, labels)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to claim job for runner", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to claim job")
+		return
+	}
+	if remote == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if req.RunnerID != nil {
+		h.logger.Info(r.Context(), "runner claimed job", map[string]interface{}{
+			"job_id":    remote.Job.ID,
+			"runner_id": *req.RunnerID,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, ClaimRunnerJobResponse{
+		RemoteJob:        *remote,
+		TimeLimitSeconds: int(h.timeLimit / time.Second),
+	})
+}
+
+// Heartbeat handles POST /runner/jobs/{id}/heartbeat.
+func (h *RunnerHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "job")
+	if !ok {
+		return
+	}
+
+	var req RunnerHeartbeatRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if !h.checkRunnerJob(w, r, id) {
+		return
+	}
+
+	if err := h.jobStore.Heartbeat(r.Context(), id); err != nil {
+		if errors.Is(err, job.ErrJobNotRunning) {
+			respondError(w, http.StatusConflict, "job is no longer running")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to record heartbeat")
+		return
+	}
+
+	if req.ProgressDone != nil && req.ProgressTotal != nil {
+		if err := h.jobStore.ReportProgress(r.Context(), id, *req.ProgressDone, *req.ProgressTotal); err != nil && !errors.Is(err, job.ErrJobNotRunning) {
+			respondError(w, http.StatusInternalServerError, "failed to record progress")
+			return
+		}
+	}
+
+	respondSuccess(w, "heartbeat recorded")
+}
+
+// Logs handles POST /runner/jobs/{id}/logs, appending the agent's log lines
+// to the job's log.
+func (h *RunnerHandler) Logs(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "job")
+	if !ok {
+		return
+	}
+
+	var req RunnerLogsRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Lines) > maxRunnerLogLines {
+		respondError(w, http.StatusBadRequest, "too many log lines")
+		return
+	}
+
+	if !h.checkRunnerJob(w, r, id) {
+		return
+	}
+
+	for _, line := range req.Lines {
+		if _, err := h.logStore.Append(r.Context(), id, line); err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to append job log")
+			return
+		}
+	}
+
+	respondSuccess(w, "logs appended")
+}
+
+// Result handles POST /runner/jobs/{id}/result. The multipart form holds
+// the agent's result.json as the result field and the screenshots it refers
+// to as screenshots files, named after the base names of their paths.
+func (h *RunnerHandler) Result(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "job")
+	if !ok {
+		return
+	}
+
+	if !h.checkRunnerJob(w, r, id) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
+	if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+		respondError(w, http.StatusBadRequest, "result too large or invalid form data")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var result agent.AgentResult
+	if err := json.Unmarshal([]byte(r.FormValue("result")), &result); err != nil {
+		respondError(w, http.StatusBadRequest, "result must be the agent's result JSON")
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "runner-job-")
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to save screenshots")
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	for _, header := range r.MultipartForm.File["screenshots"] {
+		filename := sanitizeFilename(header.Filename)
+		if filename == "" || filename == "." || filename == ".." {
+			respondError(w, http.StatusBadRequest, "invalid screenshot filename")
+			return
+		}
+		if err := saveMultipartFile(header, filepath.Join(dir, filename)); err != nil {
+			h.logger.Error(r.Context(), "failed to save runner screenshot", map[string]interface{}{
+				"error":  err.Error(),
+				"job_id": id,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to save screenshots")
+			return
+		}
+	}
+
+	if err := h.pipeline.CompleteRemote(r.Context(), id, &result, dir); err != nil {
+		if errors.Is(err, job.ErrJobNotRunning) {
+			respondError(w, http.StatusConflict, "job is no longer running")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to complete runner job", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to save result")
+		return
+	}
+
+	respondSuccess(w, "result saved")
+}
+
+// Fail handles POST /runner/jobs/{id}/fail.
+func (h *RunnerHandler) Fail(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "job")
+	if !ok {
+		return
+	}
+
+	var req RunnerFailRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Error == "" {
+		respondError(w, http.StatusBadRequest, "error is required")
+		return
+	}
+
+	if !h.checkRunnerJob(w, r, id) {
+		return
+	}
+
+	if err := h.pipeline.FailRemote(r.Context(), id, req.Error); err != nil {
+		if errors.Is(err, job.ErrJobNotRunning) {
+			respondError(w, http.StatusConflict, "job is no longer running")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to fail job")
+		return
+	}
+
+	respondSuccess(w, "job failed")
+}
+
+// saveMultipartFile copies an uploaded file to path.
+func saveMultipartFile(header *multipart.FileHeader, path string) error {
+	src, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// queuedRun is a run in a tester's queue, with the procedure and project it
+// belongs to, since the queue spans projects.
+type queuedRun struct {
+	testrun.TestRun
+	ProjectID        uuid.UUID `json:"project_id"`
+	ProcedureName    string    `json:"procedure_name"`
+	ProcedureVersion uint      `json:"procedure_version"`
+}
+
+// Claim handles POST /runs/{run_id}/claim: the caller takes an unassigned
+// run that has not completed.
+func (h *TestRunHandler) Claim(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	if err := h.testRunStore.Claim(r.Context(), id, userID); err != nil {
+		switch {
+		case errors.Is(err, testrun.ErrTestRunNotFound):
+			respondError(w, http.StatusNotFound, "test run not found")
+		case errors.Is(err, testrun.ErrRunAlreadyAssigned), errors.Is(err, testrun.ErrTestRunCompleted):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error(r.Context(), "failed to claim test run", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": id,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to claim test run")
+		}
+		return
+	}
+
+	tr, err := h.testRunStore.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get claimed test run", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get claimed test run")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tr)
+}
+
+// Queue handles GET /runs/queue: the runs assigned to the caller across
+// projects, soonest due first. Completed runs are only listed with
+// ?include_completed=true; ?due_before= (RFC 3339) narrows the queue to runs
+// due before then.
+func (h *TestRunHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	limit := 20 // default
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	offset := 0 // default
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	filter := testrun.QueueFilter{
+		IncludeCompleted: r.URL.Query().Get("include_completed") == "true",
+	}
+	if s := r.URL.Query().Get("due_before"); s != "" {
+		dueBefore, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "due_before must be an RFC 3339 time")
+			return
+		}
+		filter.DueBefore = &dueBefore
+	}
+
+	total, err := h.testRunStore.CountAssigned(r.Context(), userID, filter)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to count assigned test runs", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list run queue")
+		return
+	}
+
+	runs, err := h.testRunStore.ListAssigned(r.Context(), userID, filter, limit, offset)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to list assigned test runs", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list run queue")
+		return
+	}
+
+	// Runs of the same procedure version share its lookup
+	procedures := make(map[uuid.UUID]*testprocedure.TestProcedure)
+	items := make([]queuedRun, 0, len(runs))
+	for _, run := range runs {
+		tp, seen := procedures[run.TestProcedureID]
+		if !seen {
+			tp, err = h.testProcedureStore.GetByID(r.Context(), run.TestProcedureID)
+			if err != nil && !errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+				h.logger.Error(r.Context(), "failed to get test procedure", map[string]interface{}{
+					"error":             err.Error(),
+					"test_procedure_id": run.TestProcedureID,
+				})
+				respondError(w, http.StatusInternalServerError, "failed to list run queue")
+				return
+			}
+			procedures[run.TestProcedureID] = tp
+		}
+		item := queuedRun{TestRun: *run}
+		if tp != nil {
+			item.ProjectID = tp.ProjectID
+			item.ProcedureName = tp.Name
+			item.ProcedureVersion = tp.Version
+		}
+		items = append(items, item)
+	}
+
+	respondJSON(w, http.StatusOK, NewPaginatedResponse(items, total, limit, offset))
+}
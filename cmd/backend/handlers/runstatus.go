@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// RunStatusReasonRequest represents a request to pause or block a test run,
+// with why it is being stopped.
+type RunStatusReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Pause handles POST /runs/{run_id}/pause: the run's tester steps away from
+// a running run, with an optional reason.
+func (h *TestRunHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.changeRunStatus(w, r, true, func(id uuid.UUID, reason string) error {
+		return h.testRunStore.Pause(r.Context(), id, reason)
+	})
+}
+
+// Resume handles POST /runs/{run_id}/resume: a paused run carries on.
+func (h *TestRunHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.changeRunStatus(w, r, false, func(id uuid.UUID, _ string) error {
+		return h.changeRunExclusively(r.Context(), id, func(store testrun.Store) error {
+			return store.Resume(r.Context(), id)
+		})
+	})
+}
+
+// Block handles POST /runs/{run_id}/block: a run that has not completed
+// cannot go on until what blocks it, given as the reason, is resolved.
+func (h *TestRunHandler) Block(w http.ResponseWriter, r *http.Request) {
+	h.changeRunStatus(w, r, true, func(id uuid.UUID, reason string) error {
+		return h.testRunStore.Block(r.Context(), id, reason)
+	})
+}
+
+// Unblock handles POST /runs/{run_id}/unblock: a blocked run goes back to
+// running, or to pending if it had not started.
+func (h *TestRunHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	h.changeRunStatus(w, r, false, func(id uuid.UUID, _ string) error {
+		return h.changeRunExclusively(r.Context(), id, func(store testrun.Store) error {
+			return store.Unblock(r.Context(), id)
+		})
+	})
+}
+
+// changeRunStatus applies a status change to the run in the URL and
+// responds with the run. withReason reads the reason for it from the
+// optional request body.
+func (h *TestRunHandler) changeRunStatus(w http.ResponseWriter, r *http.Request, withReason bool, change func(id uuid.UUID, reason string) error) {
+	id, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	var req RunStatusReasonRequest
+	if withReason && r.ContentLength != 0 {
+		if err := parseJSON(r, &req, h.logger); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	if err := change(id, req.Reason); err != nil {
+		if respondConcurrentRun(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, testrun.ErrTestRunNotFound):
+			respondError(w, http.StatusNotFound, "test run not found")
+		case errors.Is(err, testrun.ErrInvalidTransition):
+			respondError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, testrun.ErrStatusReasonRequired), errors.Is(err, testrun.ErrStatusReasonTooLong):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error(r.Context(), "failed to change test run status", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": id,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to change test run status")
+		}
+		return
+	}
+
+	tr, err := h.testRunStore.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get test run", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test run")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tr)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// RunTemplateHandler handles the run templates of test procedures, which
+// new runs of the procedure are set up from.
+type RunTemplateHandler struct {
+	store          testrun.RunTemplateStore
+	testProcedures *TestProcedureHandler
+	logger         logger.Logger
+}
+
+// NewRunTemplateHandler creates a new run template handler. Procedure
+// ownership is checked through the test procedure handler.
+func NewRunTemplateHandler(store testrun.RunTemplateStore, testProcedures *TestProcedureHandler, log logger.Logger) *RunTemplateHandler {
+	return &RunTemplateHandler{
+		store:          store,
+		testProcedures: testProcedures,
+		logger:         log,
+	}
+}
+
+// SaveRunTemplateRequest represents a request to set a procedure's run
+// template. It replaces the template the procedure has.
+type SaveRunTemplateRequest struct {
+	Notes                 string                    `json:"notes"`
+	EnvironmentFields     testrun.EnvironmentFields `json:"environment_fields"`
+	Checklist             testrun.Checklist         `json:"checklist"`
+	MaxDurationMinutes    int                       `json:"max_duration_minutes"`
+	PreventConcurrentRuns bool                      `json:"prevent_concurrent_runs"`
+}
+
+// Get handles GET /procedures/{procedure_id}/run-template.
+func (h *RunTemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return
+	}
+
+	tpl, err := h.store.Get(r.Context(), rootID)
+	if err != nil {
+		if errors.Is(err, testrun.ErrRunTemplateNotFound) {
+			respondError(w, http.StatusNotFound, "run template not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get run template")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tpl)
+}
+
+// Save handles PUT /procedures/{procedure_id}/run-template, creating or
+// replacing the procedure's run template. Runs created before keep what
+// they were set up with.
+func (h *RunTemplateHandler) Save(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	var req SaveRunTemplateRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	tpl := &testrun.RunTemplate{
+		TestProcedureID:       rootID,
+		Notes:                 req.Notes,
+		EnvironmentFields:     req.EnvironmentFields,
+		Checklist:             req.Checklist,
+		MaxDurationMinutes:    req.MaxDurationMinutes,
+		PreventConcurrentRuns: req.PreventConcurrentRuns,
+		UpdatedBy:             userID,
+	}
+	if err := h.store.Save(r.Context(), tpl); err != nil {
+		if errors.Is(err, testrun.ErrInvalidRunTemplate) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to save run template")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tpl)
+}
+
+// Delete handles DELETE /procedures/{procedure_id}/run-template.
+func (h *RunTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	_, rootID, ok := h.testProcedures.checkProcedureRoot(w, r, "procedure_id")
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), rootID); err != nil {
+		if errors.Is(err, testrun.ErrRunTemplateNotFound) {
+			respondError(w, http.StatusNotFound, "run template not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete run template")
+		return
+	}
+
+	respondSuccess(w, "run template deleted")
+}
@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/saml"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// maxMetadataSize bounds uploaded identity provider metadata documents.
+const maxMetadataSize = 1 << 20
+
+// SAMLAttributeMapping names the assertion attributes that fill user fields.
+type SAMLAttributeMapping struct {
+	// Email is the attribute holding the user's email. The NameID is used
+	// when the attribute is absent and the NameID is an email address.
+	Email string
+	// Username is the attribute holding the user's display name.
+	Username string
+}
+
+// SAMLHandler handles SAML single sign-on and identity provider
+// configuration.
+type SAMLHandler struct {
+	idpStore  saml.Store
+	sp        *saml.ServiceProvider
+	auth      *AuthHandler
+	userStore user.Store
+	mapping   SAMLAttributeMapping
+	logger    logger.Logger
+}
+
+// NewSAMLHandler creates a new SAML handler. Sessions are created and SSO
+// admins identified through auth.
+func NewSAMLHandler(
+	idpStore saml.Store,
+	sp *saml.ServiceProvider,
+	auth *AuthHandler,
+	userStore user.Store,
+	mapping SAMLAttributeMapping,
+	log logger.Logger,
+) *SAMLHandler {
+	return &SAMLHandler{
+		idpStore:  idpStore,
+		sp:        sp,
+		auth:      auth,
+		userStore: userStore,
+		mapping:   mapping,
+		logger:    log,
+	}
+}
+
+// SSOStatusResponse tells the login page how users sign in.
+type SSOStatusResponse struct {
+	Configured bool   `json:"configured"`
+	EnforceSSO bool   `json:"enforce_sso"`
+	LoginURL   string `json:"login_url,omitempty"`
+}
+
+// IdentityProviderResponse describes the configured identity provider.
+type IdentityProviderResponse struct {
+	*saml.IdentityProvider
+	SSOURL       string                `json:"sso_url"`
+	Certificates []CertificateResponse `json:"certificates"`
+}
+
+// CertificateResponse describes an identity provider signing certificate.
+type CertificateResponse struct {
+	Subject   string    `json:"subject"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+}
+
+// UploadMetadataRequest represents an identity provider metadata upload.
+type UploadMetadataRequest struct {
+	MetadataXML string `json:"metadata_xml"`
+}
+
+// Status handles reporting whether SSO is configured and enforced.
+func (h *SAMLHandler) Status(w http.ResponseWriter, r *http.Request) {
+	resp := SSOStatusResponse{EnforceSSO: h.auth.ssoPolicy.Enforced}
+	if _, err := h.idpStore.Get(r.Context()); err == nil {
+		resp.Configured = true
+		resp.LoginURL = "/api/v1/auth/saml/login"
+	} else if !errors.Is(err, saml.ErrIdentityProviderNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to get identity provider")
+		return
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// Metadata handles serving the service provider metadata for registering
+// this deployment with the identity provider.
+func (h *SAMLHandler) Metadata(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.sp.Metadata())
+}
+
+// Login handles starting a login by redirecting to the identity provider.
+// The optional redirect query parameter is the path to return to after
+// login.
+func (h *SAMLHandler) Login(w http.ResponseWriter, r *http.Request) {
+	idp, ok := h.getIdPMetadata(w, r)
+	if !ok {
+		return
+	}
+
+	redirectTo := r.URL.Query().Get("redirect")
+	if !isLocalPath(redirectTo) {
+		redirectTo = "/"
+	}
+
+	loginURL, err := h.sp.AuthnRequestURL(idp, redirectTo)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to create SAML authentication request", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to start SSO login")
+		return
+	}
+
+	http.Redirect(w, r, loginURL, http.StatusFound)
+}
+
+// ACS handles the identity provider posting a SAML response to the
+// assertion consumer service. Users are matched by email and created on
+// their first login.
+func (h *SAMLHandler) ACS(w http.ResponseWriter, r *http.Request) {
+	idp, ok := h.getIdPMetadata(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+	assertion, redirectTo, err := h.sp.ParseResponse(idp, r.PostForm.Get("SAMLResponse"))
+	if err != nil {
+		h.logger.Warn(r.Context(), "rejected SAML response", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusUnauthorized, "SSO login failed")
+		return
+	}
+
+	email := strings.TrimSpace(assertion.Attribute(h.mapping.Email))
+	if email == "" && strings.Contains(assertion.NameID, "@") {
+		email = assertion.NameID
+	}
+	if email == "" {
+		h.logger.Warn(r.Context(), "SAML assertion has no email", map[string]interface{}{
+			"name_id":   assertion.NameID,
+			"attribute": h.mapping.Email,
+		})
+		respondError(w, http.StatusUnauthorized, "identity provider did not provide an email")
+		return
+	}
+	username := strings.TrimSpace(assertion.Attribute(h.mapping.Username))
+
+	u, err := h.provisionUser(r, email, username)
+	if errors.Is(err, user.ErrDuplicateEmail) {
+		// Deactivated users are not found by email, so provisioning them
+		// again collides with their account.
+		h.logger.Warn(r.Context(), "inactive user attempted SSO login", map[string]interface{}{
+			"email": email,
+		})
+		respondError(w, http.StatusUnauthorized, "user account is inactive")
+		return
+	}
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to provision SSO user", map[string]interface{}{
+			"error": err.Error(),
+			"email": email,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to sign in")
+		return
+	}
+	if !u.IsActive {
+		h.logger.Warn(r.Context(), "inactive user attempted SSO login", map[string]interface{}{
+			"user_id": u.ID.String(),
+			"email":   u.Email,
+		})
+		respondError(w, http.StatusUnauthorized, "user account is inactive")
+		return
+	}
+	if u.IsServiceAccount() {
+		respondError(w, http.StatusUnauthorized, user.ErrServiceAccount.Error())
+		return
+	}
+
+	sess, err := h.auth.sessionManager.Create(u.ID, u.Email)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to create session", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": u.ID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create session")
+		return
+	}
+	h.auth.sessionManager.Touch(sess.ID, clientIP(r), r.UserAgent())
+	h.auth.setSessionCookie(w, sess.ID)
+
+	h.logger.Info(r.Context(), "user logged in with SSO", map[string]interface{}{
+		"user_id": u.ID.String(),
+		"email":   u.Email,
+	})
+
+	http.Redirect(w, r, redirectTo, http.StatusSeeOther)
+}
+
+// provisionUser returns the user with email, creating them or updating
+// their username from the mapped attribute.
+func (h *SAMLHandler) provisionUser(r *http.Request, email, username string) (*user.User, error) {
+	ctx := r.Context()
+	existing, err := h.userStore.GetByEmail(ctx, email)
+	if err == nil {
+		if username != "" && username != existing.Username {
+			if err := h.userStore.Update(ctx, existing.ID, user.SetUsername(username)); err != nil {
+				return nil, err
+			}
+			existing.Username = username
+		}
+		return existing, nil
+	}
+	if !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if username == "" {
+		username = strings.SplitN(email, "@", 2)[0]
+	}
+	newUser := &user.User{
+		Email:    email,
+		Username: username,
+		IsActive: true,
+	}
+	// SSO users sign in through the identity provider, so they get a
+	// random password nobody knows.
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return nil, err
+	}
+	if err := newUser.SetPassword(hex.EncodeToString(password)); err != nil {
+		return nil, err
+	}
+	if err := h.userStore.Create(ctx, newUser); err != nil {
+		return nil, err
+	}
+
+	h.logger.Info(ctx, "user created from SSO login", map[string]interface{}{
+		"user_id": newUser.ID.String(),
+		"email":   newUser.Email,
+	})
+	return newUser, nil
+}
+
+// GetIdentityProvider handles retrieving the configured identity provider.
+func (h *SAMLHandler) GetIdentityProvider(w http.ResponseWriter, r *http.Request) {
+	if !h.requireSSOAdmin(w, r) {
+		return
+	}
+
+	idp, err := h.idpStore.Get(r.Context())
+	if err != nil {
+		if errors.Is(err, saml.ErrIdentityProviderNotFound) {
+			respondError(w, http.StatusNotFound, "identity provider not configured")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get identity provider")
+		return
+	}
+
+	h.respondIdentityProvider(w, r, http.StatusOK, idp)
+}
+
+// UploadMetadata handles configuring the identity provider by uploading its
+// metadata, replacing any identity provider already configured. The body is
+// either the metadata document with an XML content type or an
+// UploadMetadataRequest.
+func (h *SAMLHandler) UploadMetadata(w http.ResponseWriter, r *http.Request) {
+	if !h.requireSSOAdmin(w, r) {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxMetadataSize)
+	var metadataXML string
+	contentType := r.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "application/xml") || strings.HasPrefix(contentType, "text/xml") ||
+		strings.HasPrefix(contentType, "application/samlmetadata+xml") {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		metadataXML = string(data)
+	} else {
+		var req UploadMetadataRequest
+		if err := parseJSON(r, &req, h.logger); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		metadataXML = req.MetadataXML
+	}
+
+	idp := &saml.IdentityProvider{
+		MetadataXML: metadataXML,
+		UpdatedBy:   userID,
+	}
+	if err := h.idpStore.Save(r.Context(), idp); err != nil {
+		if errors.Is(err, saml.ErrInvalidMetadata) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to save identity provider")
+		return
+	}
+
+	h.respondIdentityProvider(w, r, http.StatusOK, idp)
+}
+
+// DeleteIdentityProvider handles removing the identity provider, which
+// disables SSO login.
+func (h *SAMLHandler) DeleteIdentityProvider(w http.ResponseWriter, r *http.Request) {
+	if !h.requireSSOAdmin(w, r) {
+		return
+	}
+
+	if err := h.idpStore.Delete(r.Context()); err != nil {
+		if errors.Is(err, saml.ErrIdentityProviderNotFound) {
+			respondError(w, http.StatusNotFound, "identity provider not configured")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete identity provider")
+		return
+	}
+
+	respondSuccess(w, "identity provider deleted successfully")
+}
+
+// respondIdentityProvider writes an identity provider with the details of
+// its parsed metadata.
+func (h *SAMLHandler) respondIdentityProvider(w http.ResponseWriter, r *http.Request, status int, idp *saml.IdentityProvider) {
+	md, err := idp.Metadata()
+	if err != nil {
+		h.logger.Error(r.Context(), "stored identity provider metadata is invalid", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusInternalServerError, "stored identity provider metadata is invalid")
+		return
+	}
+
+	resp := IdentityProviderResponse{IdentityProvider: idp, SSOURL: md.SSOURL}
+	for _, cert := range md.Certificates {
+		resp.Certificates = append(resp.Certificates, CertificateResponse{
+			Subject:   cert.Subject.String(),
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+		})
+	}
+	respondJSON(w, status, resp)
+}
+
+// getIdPMetadata loads the configured identity provider's metadata and
+// responds with an error if there is none.
+func (h *SAMLHandler) getIdPMetadata(w http.ResponseWriter, r *http.Request) (*saml.IdPMetadata, bool) {
+	idp, err := h.idpStore.Get(r.Context())
+	if err != nil {
+		if errors.Is(err, saml.ErrIdentityProviderNotFound) {
+			respondError(w, http.StatusNotFound, "SSO is not configured")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get identity provider")
+		return nil, false
+	}
+
+	md, err := idp.Metadata()
+	if err != nil {
+		h.logger.Error(r.Context(), "stored identity provider metadata is invalid", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusInternalServerError, "SSO is misconfigured")
+		return nil, false
+	}
+	return md, true
+}
+
+// requireSSOAdmin responds with an error unless the authenticated user is
+// an SSO admin. Returns true if the request may proceed.
+func (h *SAMLHandler) requireSSOAdmin(w http.ResponseWriter, r *http.Request) bool {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "authentication required")
+		return false
+	}
+
+	u, err := h.userStore.GetByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusUnauthorized, "user not found")
+			return false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get user")
+		return false
+	}
+
+	if !h.auth.ssoPolicy.IsAdmin(u.Email) {
+		respondError(w, http.StatusForbidden, "access denied")
+		return false
+	}
+	return true
+}
+
+// isLocalPath reports whether p is a path on this server, which makes it
+// safe to redirect to.
+func isLocalPath(p string) bool {
+	return strings.HasPrefix(p, "/") && !strings.HasPrefix(p, "//") && !strings.HasPrefix(p, "/\\")
+}
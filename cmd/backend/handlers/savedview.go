@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/savedview"
+)
+
+// SavedViewHandler handles saved view-related requests.
+type SavedViewHandler struct {
+	savedViewStore savedview.Store
+	projectStore   project.Store
+	logger         logger.Logger
+}
+
+// NewSavedViewHandler creates a new saved view handler.
+func NewSavedViewHandler(savedViewStore savedview.Store, projectStore project.Store, log logger.Logger) *SavedViewHandler {
+	return &SavedViewHandler{
+		savedViewStore: savedViewStore,
+		projectStore:   projectStore,
+		logger:         log,
+	}
+}
+
+// checkProjectAccess verifies that the caller can access the project.
+// Returns false if the check fails (response already written).
+func (h *SavedViewHandler) checkProjectAccess(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) bool {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return false
+	}
+
+	proj, err := h.projectStore.GetByID(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return false
+		}
+		h.logger.Error(r.Context(), "failed to get project for authorization", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "authorization check failed")
+		return false
+	}
+
+	if !canAccessProject(r, proj.ID, proj.OwnerID) {
+		h.logger.Warn(r.Context(), "unauthorized saved view access attempt", map[string]interface{}{
+			"user_id":    userID,
+			"project_id": projectID,
+			"owner_id":   proj.OwnerID,
+		})
+		respondError(w, http.StatusForbidden, "you don't have access to this project")
+		return false
+	}
+
+	return true
+}
+
+// getVisibleView loads a saved view from the project and verifies the user may
+// see it. When requireOwner is set, only the view's owner passes the check.
+// Returns false if the check fails (response already written).
+func (h *SavedViewHandler) getVisibleView(w http.ResponseWriter, r *http.Request, requireOwner bool) (*savedview.SavedView, bool) {
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return nil, false
+	}
+	viewID, ok := parseUUIDOrRespond(w, r, "view_id", "saved view")
+	if !ok {
+		return nil, false
+	}
+
+	if !h.checkProjectAccess(w, r, projectID) {
+		return nil, false
+	}
+	userID, _ := GetUserID(r.Context())
+
+	view, err := h.savedViewStore.GetByID(r.Context(), viewID)
+	if err != nil {
+		if errors.Is(err, savedview.ErrSavedViewNotFound) {
+			respondError(w, http.StatusNotFound, "saved view not found")
+			return nil, false
+		}
+		h.logger.Error(r.Context(), "failed to get saved view", map[string]interface{}{
+			"error":         err.Error(),
+			"saved_view_id": viewID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get saved view")
+		return nil, false
+	}
+
+	if view.ProjectID != projectID || !view.VisibleTo(userID) {
+		respondError(w, http.StatusNotFound, "saved view not found")
+		return nil, false
+	}
+	if requireOwner && view.OwnerID != userID {
+		respondError(w, http.StatusForbidden, "only the owner can modify this saved view")
+		return nil, false
+	}
+
+	return view, true
+}
+
+// CreateSavedViewRequest represents a saved view creation request.
+type CreateSavedViewRequest struct {
+	Name     string             `json:"name"`
+	Resource savedview.Resource `json:"resource"`
+	Filters  savedview.Filters  `json:"filters"`
+	Shared   bool               `json:"shared"`
+}
+
+// UpdateSavedViewRequest represents a saved view update request.
+type UpdateSavedViewRequest struct {
+	Name    *string            `json:"name,omitempty"`
+	Filters *savedview.Filters `json:"filters,omitempty"`
+	Shared  *bool              `json:"shared,omitempty"`
+}
+
+// isSavedViewValidationError reports whether err is caused by invalid input.
+func isSavedViewValidationError(err error) bool {
+	return errors.Is(err, savedview.ErrInvalidName) ||
+		errors.Is(err, savedview.ErrInvalidResource) ||
+		errors.Is(err, savedview.ErrInvalidStatusFilter) ||
+		errors.Is(err, savedview.ErrInvalidDateRange)
+}
+
+// List handles GET /projects/{project_id}/views. Returns the user's own views
+// and the views shared in the project, optionally filtered by ?resource=.
+func (h *SavedViewHandler) List(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return
+	}
+
+	if !h.checkProjectAccess(w, r, projectID) {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	resource := savedview.Resource(r.URL.Query().Get("resource"))
+	if resource != "" && !resource.IsValid() {
+		respondError(w, http.StatusBadRequest, savedview.ErrInvalidResource.Error())
+		return
+	}
+
+	views, err := h.savedViewStore.ListVisible(r.Context(), projectID, userID, resource)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to list saved views", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list saved views")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": views,
+		"total": len(views),
+	})
+}
+
+// Create handles POST /projects/{project_id}/views.
+func (h *SavedViewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return
+	}
+
+	if !h.checkProjectAccess(w, r, projectID) {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	var req CreateSavedViewRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	view := &savedview.SavedView{
+		ProjectID: projectID,
+		OwnerID:   userID,
+		Name:      req.Name,
+		Resource:  req.Resource,
+		Filters:   req.Filters,
+		Shared:    req.Shared,
+	}
+
+	if err := h.savedViewStore.Create(r.Context(), view); err != nil {
+		if isSavedViewValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error(r.Context(), "failed to create saved view", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create saved view")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, view)
+}
+
+// GetByID handles GET /projects/{project_id}/views/{view_id}.
+func (h *SavedViewHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.getVisibleView(w, r, false)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, view)
+}
+
+// Update handles PUT /projects/{project_id}/views/{view_id}. Only the owner
+// of a view can change it, including shared views.
+func (h *SavedViewHandler) Update(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.getVisibleView(w, r, true)
+	if !ok {
+		return
+	}
+
+	var req UpdateSavedViewRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var setters []savedview.UpdateSetter
+	if req.Name != nil {
+		setters = append(setters, savedview.SetName(*req.Name))
+	}
+	if req.Filters != nil {
+		setters = append(setters, savedview.SetFilters(*req.Filters))
+	}
+	if req.Shared != nil {
+		setters = append(setters, savedview.SetShared(*req.Shared))
+	}
+
+	if len(setters) == 0 {
+		respondError(w, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	if err := h.savedViewStore.Update(r.Context(), view.ID, setters...); err != nil {
+		if errors.Is(err, savedview.ErrSavedViewNotFound) {
+			respondError(w, http.StatusNotFound, "saved view not found")
+			return
+		}
+		if isSavedViewValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error(r.Context(), "failed to update saved view", map[string]interface{}{
+			"error":         err.Error(),
+			"saved_view_id": view.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to update saved view")
+		return
+	}
+
+	updated, err := h.savedViewStore.GetByID(r.Context(), view.ID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get updated saved view", map[string]interface{}{
+			"error":         err.Error(),
+			"saved_view_id": view.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get updated saved view")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// Delete handles DELETE /projects/{project_id}/views/{view_id}.
+func (h *SavedViewHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.getVisibleView(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := h.savedViewStore.Delete(r.Context(), view.ID); err != nil {
+		if errors.Is(err, savedview.ErrSavedViewNotFound) {
+			respondError(w, http.StatusNotFound, "saved view not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to delete saved view", map[string]interface{}{
+			"error":         err.Error(),
+			"saved_view_id": view.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to delete saved view")
+		return
+	}
+
+	respondSuccess(w, "saved view deleted successfully")
+}
@@ -7,15 +7,23 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
 	"github.com/hairizuanbinnoorazman/ui-automation/project"
 	"github.com/hairizuanbinnoorazman/ui-automation/scriptgen"
+	"github.com/hairizuanbinnoorazman/ui-automation/scriptrepo"
+	"github.com/hairizuanbinnoorazman/ui-automation/shutdown"
+	"github.com/hairizuanbinnoorazman/ui-automation/steplibrary"
 	"github.com/hairizuanbinnoorazman/ui-automation/storage"
 	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
 )
@@ -27,40 +35,74 @@ const generatingTimeout = 10 * time.Minute
 // ScriptGenHandler handles script generation requests.
 type ScriptGenHandler struct {
 	scriptStore    scriptgen.Store
+	revisionStore  scriptgen.RevisionStore
 	procedureStore testprocedure.Store
+	stepGroupStore steplibrary.Store
 	projectStore   project.Store
+	endpointStore  endpoint.Store
+	secretStore    endpoint.SecretStore
 	generator      scriptgen.ScriptGenerator
+	postProcessor  scriptgen.PostProcessor
+	pusher         *scriptrepo.Pusher
 	storage        storage.BlobStorage
+	recorder       *metering.Recorder
+	meter          *llmusage.Meter
+	notifier       *notification.Notifier
+	coordinator    *shutdown.Coordinator
 	logger         logger.Logger
 }
 
-// NewScriptGenHandler creates a new script generation handler.
+// NewScriptGenHandler creates a new script generation handler. Scripts are
+// generated from procedures with their step group references expanded from
+// stepGroupStore.
 func NewScriptGenHandler(
 	scriptStore scriptgen.Store,
+	revisionStore scriptgen.RevisionStore,
 	procedureStore testprocedure.Store,
+	stepGroupStore steplibrary.Store,
 	projectStore project.Store,
+	endpointStore endpoint.Store,
+	secretStore endpoint.SecretStore,
 	generator scriptgen.ScriptGenerator,
+	postProcessor scriptgen.PostProcessor,
+	pusher *scriptrepo.Pusher,
 	storage storage.BlobStorage,
+	recorder *metering.Recorder,
+	meter *llmusage.Meter,
+	notifier *notification.Notifier,
+	coordinator *shutdown.Coordinator,
 	log logger.Logger,
 ) *ScriptGenHandler {
 	return &ScriptGenHandler{
 		scriptStore:    scriptStore,
+		revisionStore:  revisionStore,
 		procedureStore: procedureStore,
+		stepGroupStore: stepGroupStore,
 		projectStore:   projectStore,
+		endpointStore:  endpointStore,
+		secretStore:    secretStore,
 		generator:      generator,
+		postProcessor:  postProcessor,
+		pusher:         pusher,
 		storage:        storage,
+		recorder:       recorder,
+		meter:          meter,
+		notifier:       notifier,
+		coordinator:    coordinator,
 		logger:         log,
 	}
 }
 
-// verifyProcedureOwnership checks if the authenticated user owns the project
+// verifyProcedureOwnership checks if the caller can access the project
 // containing the specified test procedure. Returns the procedure if authorized.
 func (h *ScriptGenHandler) verifyProcedureOwnership(
 	w http.ResponseWriter,
-	ctx context.Context,
+	r *http.Request,
 	procedureID uuid.UUID,
 	userID uuid.UUID,
 ) (*testprocedure.TestProcedure, bool) {
+	ctx := r.Context()
+
 	// Fetch the test procedure
 	procedure, err := h.procedureStore.GetByID(ctx, procedureID)
 	if err != nil {
@@ -92,7 +134,7 @@ func (h *ScriptGenHandler) verifyProcedureOwnership(
 	}
 
 	// Verify ownership
-	if proj.OwnerID != userID {
+	if !canAccessProject(r, proj.ID, proj.OwnerID) {
 		h.logger.Warn(ctx, "unauthorized procedure access attempt", map[string]interface{}{
 			"user_id":           userID.String(),
 			"test_procedure_id": procedureID.String(),
@@ -106,9 +148,56 @@ func (h *ScriptGenHandler) verifyProcedureOwnership(
 	return procedure, true
 }
 
-// GenerateScriptRequest represents a script generation request.
+// endpointSecretKeys returns the secret names of an endpoint owned by the user.
+// Returns false if the check fails (response already written).
+func (h *ScriptGenHandler) endpointSecretKeys(w http.ResponseWriter, ctx context.Context, endpointIDStr string, userID uuid.UUID) ([]string, bool) {
+	endpointID, err := uuid.Parse(endpointIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid endpoint_id")
+		return nil, false
+	}
+
+	ep, err := h.endpointStore.GetByID(ctx, endpointID)
+	if err != nil {
+		if errors.Is(err, endpoint.ErrEndpointNotFound) {
+			respondError(w, http.StatusNotFound, "endpoint not found")
+			return nil, false
+		}
+		h.logger.Error(ctx, "failed to get endpoint", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": endpointID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get endpoint")
+		return nil, false
+	}
+
+	if ep.CreatedBy != userID {
+		respondError(w, http.StatusForbidden, "you don't have access to this endpoint")
+		return nil, false
+	}
+
+	secrets, err := h.secretStore.List(ctx, endpointID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list endpoint secrets")
+		return nil, false
+	}
+
+	keys := make([]string, len(secrets))
+	for i, secret := range secrets {
+		keys[i] = secret.Key
+	}
+	return keys, true
+}
+
+// GenerateScriptRequest represents a script generation request. When
+// EndpointID is set, the endpoint's secrets are offered to the script as
+// environment variables.
 type GenerateScriptRequest struct {
-	Framework scriptgen.Framework `json:"framework"`
+	Framework  scriptgen.Framework `json:"framework"`
+	EndpointID string              `json:"endpoint_id,omitempty"`
+	// Parameters gives the values of the procedure's parameters, which are
+	// filled into its steps before the script is generated.
+	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
 // ListScriptsResponse represents a list scripts response.
@@ -147,11 +236,28 @@ func (h *ScriptGenHandler) Generate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify user owns the procedure's project BEFORE checking for existing scripts
-	procedure, ok := h.verifyProcedureOwnership(w, ctx, procedureID, userID)
+	procedure, ok := h.verifyProcedureOwnership(w, r, procedureID, userID)
 	if !ok {
 		// Helper already logged and responded with appropriate error
 		return
 	}
+	procedure, ok = expandStepGroups(w, r, h.stepGroupStore, procedure, h.logger)
+	if !ok {
+		return
+	}
+	values, ok := resolveParameters(w, procedure, req.Parameters, "")
+	if !ok {
+		return
+	}
+	procedure = procedure.WithParameters(values)
+
+	var secretKeys []string
+	if req.EndpointID != "" {
+		secretKeys, ok = h.endpointSecretKeys(w, ctx, req.EndpointID, userID)
+		if !ok {
+			return
+		}
+	}
 
 	// Check if script already exists (including any in-progress generation)
 	existingScript, err := h.scriptStore.GetByProcedureAndFramework(ctx, procedureID, req.Framework)
@@ -217,6 +323,16 @@ func (h *ScriptGenHandler) Generate(w http.ResponseWriter, r *http.Request) {
 		filename,
 	)
 
+	if !checkLLMBudget(w, r, h.meter, procedure.ProjectID, h.logger) {
+		return
+	}
+
+	// Generations started now would be interrupted by the shutdown.
+	if h.coordinator.Draining() {
+		respondError(w, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
+
 	// Create the DB record immediately so the client can track progress.
 	script := &scriptgen.GeneratedScript{
 		TestProcedureID:  procedureID,
@@ -238,9 +354,25 @@ func (h *ScriptGenHandler) Generate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Kick off background generation. A detached context is used so the goroutine
-	// is not cancelled when the HTTP request context expires.
-	go h.generateInBackground(context.Background(), script.ID, procedure, req.Framework, storagePath)
+	// Kick off background generation. It runs as work of the shutdown
+	// coordinator, whose context is not cancelled when the HTTP request
+	// context expires.
+	err = h.coordinator.Go(func(bgCtx context.Context) {
+		h.generateInBackground(bgCtx, script.ID, procedure, req.Framework, secretKeys, storagePath, userID)
+	})
+	if err != nil {
+		if updateErr := h.scriptStore.Update(ctx, script.ID,
+			scriptgen.SetStatus(scriptgen.StatusFailed),
+			scriptgen.SetErrorMessage(err.Error()),
+		); updateErr != nil {
+			h.logger.Error(ctx, "failed to mark script as failed", map[string]interface{}{
+				"error":     updateErr.Error(),
+				"script_id": script.ID.String(),
+			})
+		}
+		respondError(w, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
 
 	h.logger.Info(ctx, "script generation started", map[string]interface{}{
 		"script_id":         script.ID.String(),
@@ -253,15 +385,24 @@ func (h *ScriptGenHandler) Generate(w http.ResponseWriter, r *http.Request) {
 
 // generateInBackground performs the LLM call, storage upload, and final DB update
 // for an async script generation request. It must be called in a goroutine and
-// must use a context that is not tied to an HTTP request lifetime.
+// must use a context that is not tied to an HTTP request lifetime. Generations
+// interrupted by a server shutdown are marked as failed so that they can be
+// generated again.
 func (h *ScriptGenHandler) generateInBackground(
 	ctx context.Context,
 	scriptID uuid.UUID,
 	procedure *testprocedure.TestProcedure,
 	framework scriptgen.Framework,
+	secretKeys []string,
 	storagePath string,
+	userID uuid.UUID,
 ) {
 	markFailed := func(reason error) {
+		if cause := context.Cause(ctx); errors.Is(cause, shutdown.ErrInterrupted) {
+			reason = cause
+		}
+		// The context is already cancelled when the generation was interrupted.
+		ctx := context.WithoutCancel(ctx)
 		if updateErr := h.scriptStore.Update(ctx, scriptID,
 			scriptgen.SetStatus(scriptgen.StatusFailed),
 			scriptgen.SetErrorMessage(reason.Error()),
@@ -271,6 +412,9 @@ func (h *ScriptGenHandler) generateInBackground(
 				"script_id": scriptID.String(),
 			})
 		}
+		h.notifier.Notify(ctx, notification.ScriptGenerationFailedEvent(
+			procedure.ProjectID, procedure.ID, procedure.Name, string(framework), reason.Error(), userID,
+		))
 	}
 
 	defer func() {
@@ -283,7 +427,9 @@ func (h *ScriptGenHandler) generateInBackground(
 		}
 	}()
 
-	scriptContent, err := h.generator.Generate(ctx, procedure, framework)
+	trackedCtx, tracker := llmusage.Track(ctx)
+	scriptContent, err := h.generator.Generate(trackedCtx, procedure, framework, secretKeys)
+	h.meter.Record(ctx, procedure.ProjectID, userID, llmusage.FeatureScriptGeneration, tracker.Usage())
 	if err != nil {
 		h.logger.Error(ctx, "background script generation failed", map[string]interface{}{
 			"error":     err.Error(),
@@ -293,6 +439,16 @@ func (h *ScriptGenHandler) generateInBackground(
 		return
 	}
 
+	scriptContent, err = h.postProcessor.Process(ctx, scriptContent, scriptgen.NewScriptInfo(procedure, framework))
+	if err != nil {
+		h.logger.Error(ctx, "script post-processing failed", map[string]interface{}{
+			"error":     err.Error(),
+			"script_id": scriptID.String(),
+		})
+		markFailed(fmt.Errorf("post-processing failed: %w", err))
+		return
+	}
+
 	reader := bytes.NewReader(scriptContent)
 	if err := h.storage.Upload(ctx, storagePath, reader); err != nil {
 		h.logger.Error(ctx, "failed to upload script to storage", map[string]interface{}{
@@ -322,12 +478,58 @@ func (h *ScriptGenHandler) generateInBackground(
 		return
 	}
 
+	h.recordRevision(ctx, procedure, framework, path.Base(storagePath), scriptContent, userID)
+	h.pusher.AutoPush(ctx, procedure.ProjectID, scriptContent, scriptrepo.ScriptInfo{
+		ProcedureName:    procedure.Name,
+		ProcedureVersion: procedure.Version,
+		Framework:        string(framework),
+		FileName:         path.Base(storagePath),
+	})
+
+	h.recorder.RecordScriptGeneration(ctx, procedure.ProjectID, userID)
+	h.recorder.RecordStorage(ctx, procedure.ProjectID, userID, int64(len(scriptContent)))
+
 	h.logger.Info(ctx, "script generated successfully", map[string]interface{}{
 		"script_id": scriptID.String(),
 		"file_size": len(scriptContent),
 	})
 }
 
+// recordRevision keeps the code of a completed script as a revision of its
+// procedure, so that it can be diffed against later regenerations. Failures
+// are logged; the script itself is already stored.
+func (h *ScriptGenHandler) recordRevision(
+	ctx context.Context,
+	procedure *testprocedure.TestProcedure,
+	framework scriptgen.Framework,
+	fileName string,
+	content []byte,
+	userID uuid.UUID,
+) {
+	rootID := procedure.ID
+	if procedure.ParentID != nil {
+		rootID = *procedure.ParentID
+	}
+
+	revision := &scriptgen.Revision{
+		ProcedureID:      rootID,
+		Framework:        framework,
+		TestProcedureID:  procedure.ID,
+		ProcedureVersion: procedure.Version,
+		FileName:         fileName,
+		FileSize:         int64(len(content)),
+		Content:          string(content),
+		GeneratedBy:      userID,
+	}
+	if err := h.revisionStore.Create(ctx, revision); err != nil {
+		h.logger.Warn(ctx, "failed to record script revision", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": procedure.ID.String(),
+			"framework":         framework,
+		})
+	}
+}
+
 // List handles listing all scripts for a test procedure.
 func (h *ScriptGenHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -346,7 +548,7 @@ func (h *ScriptGenHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify user owns the procedure's project
-	if _, ok := h.verifyProcedureOwnership(w, ctx, procedureID, userID); !ok {
+	if _, ok := h.verifyProcedureOwnership(w, r, procedureID, userID); !ok {
 		// Helper already logged and responded with appropriate error
 		return
 	}
@@ -431,7 +633,7 @@ func (h *ScriptGenHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify user owns the procedure's project
-	if _, ok := h.verifyProcedureOwnership(w, ctx, script.TestProcedureID, userID); !ok {
+	if _, ok := h.verifyProcedureOwnership(w, r, script.TestProcedureID, userID); !ok {
 		// Helper already logged and responded with appropriate error
 		return
 	}
@@ -472,7 +674,7 @@ func (h *ScriptGenHandler) Download(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify user owns the procedure's project
-	if _, ok := h.verifyProcedureOwnership(w, ctx, script.TestProcedureID, userID); !ok {
+	if _, ok := h.verifyProcedureOwnership(w, r, script.TestProcedureID, userID); !ok {
 		// Helper already logged and responded with appropriate error
 		return
 	}
@@ -519,6 +721,397 @@ func (h *ScriptGenHandler) Download(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Bundle handles GET /procedures/{procedure_id}/scripts/bundle, downloading
+// a completed script as a ZIP with the scaffolding to run it. The framework
+// query parameter picks the script and may be omitted when the procedure has
+// only one completed script. When endpoint_id is set, the endpoint's secrets
+// are listed as the environment the script needs.
+func (h *ScriptGenHandler) Bundle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := GetUserID(ctx)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	procedureID, ok := parseUUIDOrRespond(w, r, "procedure_id", "test procedure")
+	if !ok {
+		return
+	}
+
+	procedure, ok := h.verifyProcedureOwnership(w, r, procedureID, userID)
+	if !ok {
+		return
+	}
+
+	var secretKeys []string
+	if endpointID := r.URL.Query().Get("endpoint_id"); endpointID != "" {
+		secretKeys, ok = h.endpointSecretKeys(w, ctx, endpointID, userID)
+		if !ok {
+			return
+		}
+	}
+
+	script, ok := h.bundledScript(w, ctx, procedureID, scriptgen.Framework(r.URL.Query().Get("framework")))
+	if !ok {
+		return
+	}
+
+	reader, err := h.storage.Download(ctx, script.ScriptPath)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			respondError(w, http.StatusNotFound, "script file not found in storage")
+			return
+		}
+		h.logger.Error(ctx, "failed to download script from storage", map[string]interface{}{
+			"error":     err.Error(),
+			"script_id": script.ID.String(),
+			"path":      script.ScriptPath,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to download script")
+		return
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		h.logger.Error(ctx, "failed to read script from storage", map[string]interface{}{
+			"error":     err.Error(),
+			"script_id": script.ID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to download script")
+		return
+	}
+
+	bundle := &scriptgen.Bundle{
+		Script:        script,
+		Content:       content,
+		ProcedureName: procedure.Name,
+		SecretKeys:    secretKeys,
+	}
+
+	// Build ZIP into a buffer so errors can still return proper HTTP responses
+	var buf bytes.Buffer
+	if err := bundle.WriteZip(&buf); err != nil {
+		h.logger.Error(ctx, "failed to build script bundle", map[string]interface{}{
+			"error":     err.Error(),
+			"script_id": script.ID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create zip")
+		return
+	}
+
+	fileName := strings.TrimSuffix(script.FileName, ".py") + "-bundle.zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		h.logger.Error(ctx, "failed to write script bundle to response", map[string]interface{}{
+			"error":     err.Error(),
+			"script_id": script.ID.String(),
+		})
+		return
+	}
+
+	h.logger.Info(ctx, "script bundle downloaded", map[string]interface{}{
+		"script_id": script.ID.String(),
+		"filename":  fileName,
+	})
+}
+
+// bundledScript returns the completed script of the procedure to bundle.
+// Returns false if there is none (response already written).
+func (h *ScriptGenHandler) bundledScript(w http.ResponseWriter, ctx context.Context, procedureID uuid.UUID, framework scriptgen.Framework) (*scriptgen.GeneratedScript, bool) {
+	var script *scriptgen.GeneratedScript
+	if framework != "" {
+		if !framework.IsValid() {
+			respondError(w, http.StatusBadRequest, "invalid framework (must be 'selenium' or 'playwright')")
+			return nil, false
+		}
+		var err error
+		script, err = h.scriptStore.GetByProcedureAndFramework(ctx, procedureID, framework)
+		if err != nil {
+			if errors.Is(err, scriptgen.ErrScriptNotFound) {
+				respondError(w, http.StatusNotFound, "script not found")
+				return nil, false
+			}
+			h.logger.Error(ctx, "failed to get script", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": procedureID.String(),
+				"framework":         framework,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to get script")
+			return nil, false
+		}
+	} else {
+		scripts, err := h.scriptStore.ListByProcedure(ctx, procedureID)
+		if err != nil {
+			h.logger.Error(ctx, "failed to list scripts", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": procedureID.String(),
+			})
+			respondError(w, http.StatusInternalServerError, "failed to list scripts")
+			return nil, false
+		}
+		for _, s := range scripts {
+			if s.GenerationStatus != scriptgen.StatusCompleted {
+				continue
+			}
+			if script != nil {
+				respondError(w, http.StatusBadRequest, "framework is required when the procedure has scripts for several frameworks")
+				return nil, false
+			}
+			script = s
+		}
+		if script == nil {
+			respondError(w, http.StatusNotFound, "no completed script to bundle")
+			return nil, false
+		}
+	}
+
+	if script.GenerationStatus != scriptgen.StatusCompleted {
+		respondError(w, http.StatusConflict, "script is not ready for download: generation status is "+string(script.GenerationStatus))
+		return nil, false
+	}
+	return script, true
+}
+
+// revisionsRequest checks access to the procedure in the URL and returns the
+// ID of its first version, which revisions of all its versions are recorded
+// against, and the framework query parameter. Returns false if the check
+// fails (response already written).
+func (h *ScriptGenHandler) revisionsRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, scriptgen.Framework, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return uuid.Nil, "", false
+	}
+
+	procedureID, ok := parseUUIDOrRespond(w, r, "procedure_id", "test procedure")
+	if !ok {
+		return uuid.Nil, "", false
+	}
+
+	framework := scriptgen.Framework(r.URL.Query().Get("framework"))
+	if !framework.IsValid() {
+		respondError(w, http.StatusBadRequest, "invalid framework (must be 'selenium' or 'playwright')")
+		return uuid.Nil, "", false
+	}
+
+	procedure, ok := h.verifyProcedureOwnership(w, r, procedureID, userID)
+	if !ok {
+		return uuid.Nil, "", false
+	}
+
+	rootID := procedure.ID
+	if procedure.ParentID != nil {
+		rootID = *procedure.ParentID
+	}
+	return rootID, framework, true
+}
+
+// ListRevisions handles GET /procedures/{procedure_id}/scripts/revisions,
+// listing the code revisions of the procedure's scripts for the framework
+// query parameter across all of its versions, newest first.
+func (h *ScriptGenHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	rootID, framework, ok := h.revisionsRequest(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.revisionStore.List(r.Context(), rootID, framework)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list script revisions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, revisions)
+}
+
+// Diff handles GET /procedures/{procedure_id}/scripts/diff, returning the
+// unified diff of the code of the procedure's script for the framework query
+// parameter between the revisions from and to. to defaults to the latest
+// revision and from to the one before to.
+func (h *ScriptGenHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	rootID, framework, ok := h.revisionsRequest(w, r)
+	if !ok {
+		return
+	}
+
+	toNumber, ok := parseRevisionNumber(w, r, "to")
+	if !ok {
+		return
+	}
+	if toNumber == 0 {
+		revisions, err := h.revisionStore.List(ctx, rootID, framework)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to list script revisions")
+			return
+		}
+		if len(revisions) > 0 {
+			toNumber = revisions[0].Number
+		}
+	}
+	fromNumber, ok := parseRevisionNumber(w, r, "from")
+	if !ok {
+		return
+	}
+	if fromNumber == 0 {
+		fromNumber = toNumber - 1
+	}
+	if fromNumber < 1 {
+		respondError(w, http.StatusNotFound, "no earlier script revision to compare with")
+		return
+	}
+
+	from, ok := h.getRevision(w, ctx, rootID, framework, fromNumber)
+	if !ok {
+		return
+	}
+	to, ok := h.getRevision(w, ctx, rootID, framework, toNumber)
+	if !ok {
+		return
+	}
+
+	diff, err := scriptgen.DiffRevisions(from, to)
+	if err != nil {
+		h.logger.Error(ctx, "failed to diff script revisions", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": rootID.String(),
+			"framework":    framework,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to diff script revisions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, diff)
+}
+
+// parseRevisionNumber parses the revision number in a query parameter, which
+// is 0 when it is absent. Returns false if it is invalid (response already
+// written).
+func parseRevisionNumber(w http.ResponseWriter, r *http.Request, param string) (int, bool) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return 0, true
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		respondError(w, http.StatusBadRequest, "invalid "+param+" revision")
+		return 0, false
+	}
+	return number, true
+}
+
+// getRevision retrieves a revision with its code. Returns false if it cannot
+// be retrieved (response already written).
+func (h *ScriptGenHandler) getRevision(w http.ResponseWriter, ctx context.Context, rootID uuid.UUID, framework scriptgen.Framework, number int) (*scriptgen.Revision, bool) {
+	revision, err := h.revisionStore.Get(ctx, rootID, framework, number)
+	if err != nil {
+		if errors.Is(err, scriptgen.ErrRevisionNotFound) {
+			respondError(w, http.StatusNotFound, fmt.Sprintf("script revision %d not found", number))
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get script revision")
+		return nil, false
+	}
+	return revision, true
+}
+
+// Push handles POST /scripts/{script_id}/push, committing a completed
+// script to the Git repository configured for its project.
+func (h *ScriptGenHandler) Push(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := GetUserID(ctx)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	scriptID, ok := parseUUIDOrRespond(w, r, "script_id", "script")
+	if !ok {
+		return
+	}
+
+	script, err := h.scriptStore.GetByID(ctx, scriptID)
+	if err != nil {
+		if errors.Is(err, scriptgen.ErrScriptNotFound) {
+			respondError(w, http.StatusNotFound, "script not found")
+			return
+		}
+		h.logger.Error(ctx, "failed to get script", map[string]interface{}{
+			"error":     err.Error(),
+			"script_id": scriptID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get script")
+		return
+	}
+
+	procedure, ok := h.verifyProcedureOwnership(w, r, script.TestProcedureID, userID)
+	if !ok {
+		return
+	}
+
+	if script.GenerationStatus != scriptgen.StatusCompleted {
+		respondError(w, http.StatusConflict, "script is not ready to push: generation status is "+string(script.GenerationStatus))
+		return
+	}
+
+	reader, err := h.storage.Download(ctx, script.ScriptPath)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			respondError(w, http.StatusNotFound, "script file not found in storage")
+			return
+		}
+		h.logger.Error(ctx, "failed to download script from storage", map[string]interface{}{
+			"error":     err.Error(),
+			"script_id": scriptID.String(),
+			"path":      script.ScriptPath,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to download script")
+		return
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		h.logger.Error(ctx, "failed to read script from storage", map[string]interface{}{
+			"error":     err.Error(),
+			"script_id": scriptID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to download script")
+		return
+	}
+
+	result, err := h.pusher.Push(ctx, procedure.ProjectID, content, scriptrepo.ScriptInfo{
+		ProcedureName:    procedure.Name,
+		ProcedureVersion: procedure.Version,
+		Framework:        string(script.Framework),
+		FileName:         script.FileName,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, scriptrepo.ErrRepositoryNotFound):
+			respondError(w, http.StatusNotFound, "no script repository is configured for the project")
+		case errors.Is(err, scriptrepo.ErrInvalidCommitMessage), errors.Is(err, scriptrepo.ErrTokenRequired):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, scriptrepo.ErrDecryptFailed):
+			respondError(w, http.StatusInternalServerError, "failed to get script repository")
+		default:
+			h.logger.Warn(ctx, "failed to push script", map[string]interface{}{
+				"error":     err.Error(),
+				"script_id": scriptID.String(),
+			})
+			respondError(w, http.StatusBadGateway, "failed to push script: "+err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
 // Delete handles deleting a script.
 func (h *ScriptGenHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -552,7 +1145,8 @@ func (h *ScriptGenHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify user owns the procedure's project
-	if _, ok := h.verifyProcedureOwnership(w, ctx, script.TestProcedureID, userID); !ok {
+	procedure, ok := h.verifyProcedureOwnership(w, r, script.TestProcedureID, userID)
+	if !ok {
 		// Helper already logged and responded with appropriate error
 		return
 	}
@@ -577,6 +1171,8 @@ func (h *ScriptGenHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		// Don't fail the request - DB record is already deleted
 	}
 
+	h.recorder.RecordStorage(ctx, procedure.ProjectID, userID, -script.FileSize)
+
 	h.logger.Info(ctx, "script deleted", map[string]interface{}{
 		"script_id": scriptID.String(),
 	})
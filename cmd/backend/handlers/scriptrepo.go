@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/scriptrepo"
+)
+
+// ScriptRepositoryHandler handles requests for the Git repository a
+// project's generated scripts are pushed to. All routes are registered on
+// the project router, whose authorization middleware has already verified
+// ownership.
+type ScriptRepositoryHandler struct {
+	store  scriptrepo.Store
+	logger logger.Logger
+}
+
+// NewScriptRepositoryHandler creates a new script repository handler.
+func NewScriptRepositoryHandler(store scriptrepo.Store, log logger.Logger) *ScriptRepositoryHandler {
+	return &ScriptRepositoryHandler{
+		store:  store,
+		logger: log,
+	}
+}
+
+// SaveScriptRepositoryRequest represents a script repository configuration
+// request. An empty token keeps the token already saved.
+type SaveScriptRepositoryRequest struct {
+	Provider          scriptrepo.Provider `json:"provider"`
+	BaseURL           string              `json:"base_url"`
+	Repository        string              `json:"repository"`
+	Branch            string              `json:"branch"`
+	BaseBranch        string              `json:"base_branch"`
+	Directory         string              `json:"directory"`
+	CommitMessage     string              `json:"commit_message"`
+	CreatePullRequest bool                `json:"create_pull_request"`
+	AutoPush          bool                `json:"auto_push"`
+	Token             string              `json:"token"`
+}
+
+// isScriptRepositoryValidationError reports whether err is caused by an
+// invalid repository configuration.
+func isScriptRepositoryValidationError(err error) bool {
+	for _, target := range []error{
+		scriptrepo.ErrInvalidProvider,
+		scriptrepo.ErrInvalidRepository,
+		scriptrepo.ErrInvalidBranch,
+		scriptrepo.ErrInvalidDirectory,
+		scriptrepo.ErrInvalidCommitMessage,
+		scriptrepo.ErrPullRequestBaseRequired,
+		scriptrepo.ErrTokenRequired,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get handles GET /projects/{id}/script-repository.
+func (h *ScriptRepositoryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	repo, err := h.store.GetByProject(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, scriptrepo.ErrRepositoryNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get script repository")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, repo)
+}
+
+// Save handles PUT /projects/{id}/script-repository.
+func (h *ScriptRepositoryHandler) Save(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req SaveScriptRepositoryRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	repo := &scriptrepo.Repository{
+		ProjectID:         projectID,
+		Provider:          req.Provider,
+		BaseURL:           req.BaseURL,
+		Repository:        req.Repository,
+		Branch:            req.Branch,
+		BaseBranch:        req.BaseBranch,
+		Directory:         req.Directory,
+		CommitMessage:     req.CommitMessage,
+		CreatePullRequest: req.CreatePullRequest,
+		AutoPush:          req.AutoPush,
+		Token:             req.Token,
+		CreatedBy:         userID,
+	}
+	if err := h.store.Save(r.Context(), repo); err != nil {
+		if isScriptRepositoryValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to save script repository")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, repo)
+}
+
+// Delete handles DELETE /projects/{id}/script-repository.
+func (h *ScriptRepositoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), projectID); err != nil {
+		if errors.Is(err, scriptrepo.ErrRepositoryNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete script repository")
+		return
+	}
+
+	respondSuccess(w, "script repository deleted successfully")
+}
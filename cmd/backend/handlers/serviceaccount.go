@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/serviceaccount"
+)
+
+// ServiceAccountKey is the context key for the service account making a
+// request.
+const ServiceAccountKey ContextKey = "service_account"
+
+// ServiceAccountMiddleware adds the service account making a bearer request,
+// with its grants, to the context, so that canAccessProject lets it into
+// the projects it is granted. It must run after AuthMiddleware.
+func ServiceAccountMiddleware(store serviceaccount.Store, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserID(r.Context())
+			if !ok || GetAuthMethod(r.Context()) != "bearer" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			sa, err := store.GetByID(r.Context(), userID)
+			if err != nil {
+				if errors.Is(err, serviceaccount.ErrServiceAccountNotFound) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				respondError(w, http.StatusInternalServerError, "authorization check failed")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ServiceAccountKey, sa)))
+		})
+	}
+}
+
+// GetServiceAccount extracts the service account making the request from
+// the request context. Only set for requests made with a service account's
+// API token.
+func GetServiceAccount(ctx context.Context) (*serviceaccount.ServiceAccount, bool) {
+	sa, ok := ctx.Value(ServiceAccountKey).(*serviceaccount.ServiceAccount)
+	return sa, ok
+}
+
+// RejectServiceAccounts refuses requests made by service accounts, for
+// routes that manage a person's own account or create what a person owns.
+func RejectServiceAccounts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetServiceAccount(r.Context()); ok {
+			respondError(w, http.StatusForbidden, "not allowed for service accounts")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServiceAccountHandler handles the admin API of service accounts: their
+// project grants and API tokens.
+type ServiceAccountHandler struct {
+	store        serviceaccount.Store
+	projectStore project.Store
+	tokenStore   apitoken.Store
+	tokens       *APITokenHandler
+	logger       logger.Logger
+}
+
+// NewServiceAccountHandler creates a new service account handler. Tokens of
+// service accounts are created and listed like those of users by tokens.
+func NewServiceAccountHandler(store serviceaccount.Store, projectStore project.Store, tokenStore apitoken.Store, tokens *APITokenHandler, log logger.Logger) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		store:        store,
+		projectStore: projectStore,
+		tokenStore:   tokenStore,
+		tokens:       tokens,
+		logger:       log,
+	}
+}
+
+// CreateServiceAccountRequest represents a service account creation
+// request.
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// GrantProjectRequest represents granting a service account a project.
+type GrantProjectRequest struct {
+	Scope string `json:"scope"`
+}
+
+// List handles GET /admin/service-accounts.
+func (h *ServiceAccountHandler) List(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.store.List(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list service accounts")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, accounts)
+}
+
+// Create handles POST /admin/service-accounts. The service account has no
+// grants or tokens until they are added.
+func (h *ServiceAccountHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req CreateServiceAccountRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sa := &serviceaccount.ServiceAccount{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   userID,
+		Grants:      []serviceaccount.Grant{},
+	}
+	if err := h.store.Create(r.Context(), sa); err != nil {
+		if errors.Is(err, serviceaccount.ErrInvalidName) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to create service account")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, sa)
+}
+
+// GetByID handles GET /admin/service-accounts/{id}.
+func (h *ServiceAccountHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	sa, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, sa)
+}
+
+// Delete handles DELETE /admin/service-accounts/{id}, revoking its grants
+// and tokens. Runs and jobs it started are kept.
+func (h *ServiceAccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "service account")
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, serviceaccount.ErrServiceAccountNotFound) {
+			respondError(w, http.StatusNotFound, "service account not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete service account")
+		return
+	}
+	if _, err := h.tokenStore.RevokeByUser(r.Context(), id); err != nil {
+		h.logger.Error(r.Context(), "failed to revoke api tokens of deleted service account", map[string]interface{}{
+			"error":              err.Error(),
+			"service_account_id": id.String(),
+		})
+	}
+
+	h.logger.Info(r.Context(), "service account deleted", map[string]interface{}{
+		"service_account_id": id.String(),
+		"admin_id":           adminID(r),
+	})
+	respondSuccess(w, "service account deleted successfully")
+}
+
+// GrantProject handles PUT /admin/service-accounts/{id}/grants/{project_id},
+// granting the project or changing the scope it is granted.
+func (h *ServiceAccountHandler) GrantProject(w http.ResponseWriter, r *http.Request) {
+	sa, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return
+	}
+
+	var req GrantProjectRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if _, err := h.projectStore.GetByID(r.Context(), projectID); err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get project")
+		return
+	}
+
+	grant := &serviceaccount.Grant{ServiceAccountID: sa.ID, ProjectID: projectID, Scope: req.Scope}
+	if err := h.store.Grant(r.Context(), grant); err != nil {
+		if errors.Is(err, serviceaccount.ErrInvalidScope) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to grant project")
+		return
+	}
+
+	h.logger.Info(r.Context(), "service account granted project", map[string]interface{}{
+		"service_account_id": sa.ID.String(),
+		"project_id":         projectID.String(),
+		"scope":              req.Scope,
+		"admin_id":           adminID(r),
+	})
+	respondJSON(w, http.StatusOK, grant)
+}
+
+// UngrantProject handles DELETE
+// /admin/service-accounts/{id}/grants/{project_id}.
+func (h *ServiceAccountHandler) UngrantProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "service account")
+	if !ok {
+		return
+	}
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return
+	}
+
+	if err := h.store.Ungrant(r.Context(), id, projectID); err != nil {
+		if errors.Is(err, serviceaccount.ErrGrantNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to revoke grant")
+		return
+	}
+
+	h.logger.Info(r.Context(), "service account grant revoked", map[string]interface{}{
+		"service_account_id": id.String(),
+		"project_id":         projectID.String(),
+		"admin_id":           adminID(r),
+	})
+	respondSuccess(w, "grant revoked successfully")
+}
+
+// ListTokens handles GET /admin/service-accounts/{id}/tokens.
+func (h *ServiceAccountHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	sa, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+
+	h.tokens.list(w, r, sa.ID)
+}
+
+// CreateToken handles POST /admin/service-accounts/{id}/tokens, taking the
+// same request as POST /tokens. A token's scope caps what each grant
+// allows.
+func (h *ServiceAccountHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	sa, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+
+	h.tokens.create(w, r, sa.ID)
+}
+
+// RevokeToken handles DELETE /admin/service-accounts/{id}/tokens/{token_id}.
+func (h *ServiceAccountHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	sa, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+
+	h.tokens.revoke(w, r, sa.ID)
+}
+
+// loadServiceAccount returns the service account in the URL. Returns false
+// if it is not found (response already written).
+func (h *ServiceAccountHandler) loadServiceAccount(w http.ResponseWriter, r *http.Request) (*serviceaccount.ServiceAccount, bool) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "service account")
+	if !ok {
+		return nil, false
+	}
+
+	sa, err := h.store.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, serviceaccount.ErrServiceAccountNotFound) {
+			respondError(w, http.StatusNotFound, "service account not found")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get service account")
+		return nil, false
+	}
+	return sa, true
+}
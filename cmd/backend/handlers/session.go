@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/session"
+)
+
+// SessionHandler handles the login sessions of the authenticated user, so
+// that they can log out devices they no longer use or a leaked cookie.
+type SessionHandler struct {
+	sessionManager *session.Manager
+	logger         logger.Logger
+}
+
+// NewSessionHandler creates a new session handler.
+func NewSessionHandler(sessionManager *session.Manager, log logger.Logger) *SessionHandler {
+	return &SessionHandler{
+		sessionManager: sessionManager,
+		logger:         log,
+	}
+}
+
+// SessionResponse describes an active session. ID is the session's public
+// ID, not its cookie.
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// RevokeSessionsResponse reports how many sessions were revoked.
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// List handles GET /auth/sessions, listing the caller's active sessions,
+// most recently seen first.
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	currentID, _ := GetSessionID(r.Context())
+
+	sessions := h.sessionManager.ListByUser(userID)
+	resp := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		resp[i] = SessionResponse{
+			ID:         s.PublicID,
+			Device:     session.Device(s.UserAgent),
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == currentID,
+		}
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// Revoke handles DELETE /auth/sessions/{session_id}, logging out one of the
+// caller's sessions. Revoking the current session logs the caller out.
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	publicID, ok := parseUUIDOrRespond(w, r, "session_id", "session")
+	if !ok {
+		return
+	}
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	if err := h.sessionManager.Revoke(userID, publicID); err != nil {
+		if errors.Is(err, session.ErrSessionNotFound) {
+			respondError(w, http.StatusNotFound, "session not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to revoke session")
+		return
+	}
+
+	respondSuccess(w, "session revoked successfully")
+}
+
+// RevokeOthers handles DELETE /auth/sessions, logging out every session of
+// the caller but the current one. With an API token, every session is
+// logged out.
+func (h *SessionHandler) RevokeOthers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	currentID, _ := GetSessionID(r.Context())
+
+	respondJSON(w, http.StatusOK, RevokeSessionsResponse{
+		Revoked: h.sessionManager.RevokeOthers(userID, currentID),
+	})
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/narration"
+	"github.com/hairizuanbinnoorazman/ui-automation/share"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// ShareHandler handles share links, which show a run's guide or summary to
+// anyone who has the link. Links are managed by the run's owner, checked by
+// RunAuthorizationMiddleware; shared routes are public and read-only,
+// authorized by the link's token alone.
+type ShareHandler struct {
+	store       share.Store
+	testRuns    *TestRunHandler
+	linkBaseURL string
+	logger      logger.Logger
+}
+
+// NewShareHandler creates a new share handler. Share URLs are built under
+// linkBaseURL, the external URL of the server; without one they are
+// relative.
+func NewShareHandler(store share.Store, testRuns *TestRunHandler, linkBaseURL string, log logger.Logger) *ShareHandler {
+	return &ShareHandler{
+		store:       store,
+		testRuns:    testRuns,
+		linkBaseURL: linkBaseURL,
+		logger:      log,
+	}
+}
+
+// CreateShareLinkRequest represents a request to share a run. Links expire
+// after a week unless ExpiresInHours is given.
+type CreateShareLinkRequest struct {
+	Kind           share.Kind `json:"kind"`
+	ExpiresInHours int        `json:"expires_in_hours,omitempty"`
+}
+
+// CreateShareLinkResponse is a new share link with its token and URL, which
+// are only returned once.
+type CreateShareLinkResponse struct {
+	*share.Link
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// Create handles POST /runs/{run_id}/shares.
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	var req CreateShareLinkRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = share.KindSummary
+	}
+	if !req.Kind.IsValid() {
+		respondError(w, http.StatusBadRequest, share.ErrInvalidKind.Error())
+		return
+	}
+	if req.ExpiresInHours < 0 {
+		respondError(w, http.StatusBadRequest, "expires_in_hours must not be negative")
+		return
+	}
+
+	rawToken, hash, err := share.GenerateToken()
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to generate share token", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create share link")
+		return
+	}
+
+	userID, _ := GetUserID(r.Context())
+	link := &share.Link{
+		TestRunID: runID,
+		Kind:      req.Kind,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(share.ValidateExpiry(time.Duration(req.ExpiresInHours) * time.Hour)),
+		CreatedBy: userID,
+	}
+	if err := h.store.Create(r.Context(), link); err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to create share link")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, CreateShareLinkResponse{
+		Link:  link,
+		Token: rawToken,
+		URL:   h.linkBaseURL + "/api/v1/shared/" + rawToken,
+	})
+}
+
+// List handles GET /runs/{run_id}/shares, including expired and revoked
+// links with their view counts.
+func (h *ShareHandler) List(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	links, err := h.store.ListByRun(r.Context(), runID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list share links")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, links)
+}
+
+// Revoke handles DELETE /runs/{run_id}/shares/{share_id}. Links of other
+// runs are reported as not found.
+func (h *ShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+	shareID, ok := parseUUIDOrRespond(w, r, "share_id", "share link")
+	if !ok {
+		return
+	}
+
+	link, err := h.store.GetByID(r.Context(), shareID)
+	if err != nil {
+		if errors.Is(err, share.ErrLinkNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get share link")
+		return
+	}
+	if link.TestRunID != runID {
+		respondError(w, http.StatusNotFound, share.ErrLinkNotFound.Error())
+		return
+	}
+
+	if err := h.store.Revoke(r.Context(), link.ID, time.Now()); err != nil {
+		switch {
+		case errors.Is(err, share.ErrLinkNotFound):
+			respondError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, share.ErrAlreadyRevoked):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to revoke share link")
+		}
+		return
+	}
+
+	respondSuccess(w, "share link revoked successfully")
+}
+
+// sharedLink returns the active share link of the token in the URL. Shared
+// responses are not cached and do not pass the token on as a referrer.
+// Returns false if there is none (response already written).
+func (h *ShareHandler) sharedLink(w http.ResponseWriter, r *http.Request) (*share.Link, bool) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	link, err := h.store.GetByTokenHash(r.Context(), share.HashToken(mux.Vars(r)["token"]))
+	if err != nil {
+		if errors.Is(err, share.ErrLinkNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get share link")
+		return nil, false
+	}
+	return link, true
+}
+
+// recordView counts a view of a share link. Failures are logged, never
+// returned: the viewer still sees the run.
+func (h *ShareHandler) recordView(r *http.Request, link *share.Link) {
+	if err := h.store.RecordView(r.Context(), link.ID, time.Now()); err != nil {
+		h.logger.Warn(r.Context(), "failed to record share link view", map[string]interface{}{
+			"error":    err.Error(),
+			"share_id": link.ID.String(),
+		})
+	}
+}
+
+// GetShared handles GET /shared/{token} (public), returning the summary of
+// the shared run.
+func (h *ShareHandler) GetShared(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.sharedLink(w, r)
+	if !ok {
+		return
+	}
+
+	run, ok := h.testRuns.loadReportedRun(w, r, link.TestRunID)
+	if !ok {
+		return
+	}
+	assets, err := h.testRuns.assetStore.ListByTestRun(r.Context(), link.TestRunID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list assets")
+		return
+	}
+
+	h.recordView(r, link)
+	respondJSON(w, http.StatusOK, share.NewSummary(link, run, assets))
+}
+
+// GetSharedGuide handles GET /shared/{token}/guide (public), returning the
+// guide ZIP of a run shared with a guide link. The guide is built from the
+// run's notes as they are, without the model.
+func (h *ShareHandler) GetSharedGuide(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.sharedLink(w, r)
+	if !ok {
+		return
+	}
+	if link.Kind != share.KindGuide {
+		respondError(w, http.StatusNotFound, "guide not shared")
+		return
+	}
+
+	h.recordView(r, link)
+	h.testRuns.respondGuide(w, r, link.TestRunID, guideOptions{tone: narration.DefaultTone})
+}
+
+// GetSharedAsset handles GET /shared/{token}/assets/{asset_id} (public),
+// downloading an asset of the shared run. Downloads are not counted as
+// views.
+func (h *ShareHandler) GetSharedAsset(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.sharedLink(w, r)
+	if !ok {
+		return
+	}
+	assetID, ok := parseUUIDOrRespond(w, r, "asset_id", "asset")
+	if !ok {
+		return
+	}
+
+	asset, err := h.testRuns.assetStore.GetByID(r.Context(), assetID)
+	if err != nil && !errors.Is(err, testrun.ErrAssetNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to get asset")
+		return
+	}
+	if err != nil || asset.TestRunID != link.TestRunID {
+		respondError(w, http.StatusNotFound, "asset not found")
+		return
+	}
+
+	h.testRuns.respondAsset(w, r, asset)
+}
@@ -0,0 +1,476 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/steplibrary"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// StepGroupHandler handles the step library of a project: step groups that
+// procedures reference instead of repeating their steps.
+type StepGroupHandler struct {
+	stepGroupStore     steplibrary.Store
+	testProcedureStore testprocedure.Store
+	projectStore       project.Store
+	testProcedures     *TestProcedureHandler
+	logger             logger.Logger
+}
+
+// NewStepGroupHandler creates a new step group handler. Procedure ownership
+// is checked through the test procedure handler.
+func NewStepGroupHandler(stepGroupStore steplibrary.Store, testProcedureStore testprocedure.Store, projectStore project.Store, testProcedures *TestProcedureHandler, log logger.Logger) *StepGroupHandler {
+	return &StepGroupHandler{
+		stepGroupStore:     stepGroupStore,
+		testProcedureStore: testProcedureStore,
+		projectStore:       projectStore,
+		testProcedures:     testProcedures,
+		logger:             log,
+	}
+}
+
+// CreateStepGroupRequest represents a step group creation request.
+type CreateStepGroupRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Steps       testprocedure.Steps `json:"steps"`
+}
+
+// UpdateStepGroupRequest represents a step group update request.
+type UpdateStepGroupRequest struct {
+	Name        *string              `json:"name,omitempty"`
+	Description *string              `json:"description,omitempty"`
+	Steps       *testprocedure.Steps `json:"steps,omitempty"`
+}
+
+// StepGroupUsage is a procedure version that references a step group.
+// Changed is set if the group has changed since the version was reviewed
+// against it.
+type StepGroupUsage struct {
+	ProcedureID    uuid.UUID `json:"procedure_id"`
+	VersionID      uuid.UUID `json:"version_id"`
+	Name           string    `json:"name"`
+	Version        uint      `json:"version"`
+	PinnedRevision uint      `json:"pinned_revision"`
+	Changed        bool      `json:"changed"`
+}
+
+// StepGroupUpdateResponse is the response to a step group update. When the
+// update changed the group's steps, AffectedProcedures lists the procedure
+// versions that will run the new steps without having been reviewed
+// against them.
+type StepGroupUpdateResponse struct {
+	*steplibrary.StepGroup
+	AffectedProcedures []StepGroupUsage `json:"affected_procedures"`
+}
+
+// StepGroupInUseResponse is the 409 response to deleting a step group that
+// procedures still reference.
+type StepGroupInUseResponse struct {
+	Error      string           `json:"error"`
+	Procedures []StepGroupUsage `json:"procedures"`
+}
+
+// isStepGroupValidationError reports whether err is caused by invalid input.
+func isStepGroupValidationError(err error) bool {
+	return errors.Is(err, steplibrary.ErrInvalidName) ||
+		errors.Is(err, steplibrary.ErrNoSteps) ||
+		errors.Is(err, steplibrary.ErrNestedGroup) ||
+		errors.Is(err, testprocedure.ErrInvalidStepName) ||
+		errors.Is(err, testprocedure.ErrInvalidCondition) ||
+		errors.Is(err, testprocedure.ErrInvalidAttachment)
+}
+
+// checkProjectAccess verifies that the caller can access the project.
+// Returns false if the check fails (response already written).
+func (h *StepGroupHandler) checkProjectAccess(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) bool {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return false
+	}
+
+	proj, err := h.projectStore.GetByID(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return false
+		}
+		h.logger.Error(r.Context(), "failed to get project for authorization", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "authorization check failed")
+		return false
+	}
+
+	if !canAccessProject(r, proj.ID, proj.OwnerID) {
+		h.logger.Warn(r.Context(), "unauthorized step group access attempt", map[string]interface{}{
+			"user_id":    userID,
+			"project_id": projectID,
+			"owner_id":   proj.OwnerID,
+		})
+		respondError(w, http.StatusForbidden, "you don't have access to this project")
+		return false
+	}
+
+	return true
+}
+
+// getGroup loads the step group in the URL from its project after checking
+// access to the project. Returns false if the check fails (response already
+// written).
+func (h *StepGroupHandler) getGroup(w http.ResponseWriter, r *http.Request) (*steplibrary.StepGroup, bool) {
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return nil, false
+	}
+	groupID, ok := parseUUIDOrRespond(w, r, "group_id", "step group")
+	if !ok {
+		return nil, false
+	}
+	if !h.checkProjectAccess(w, r, projectID) {
+		return nil, false
+	}
+
+	group, err := h.stepGroupStore.GetByID(r.Context(), groupID)
+	if err != nil {
+		if errors.Is(err, steplibrary.ErrStepGroupNotFound) {
+			respondError(w, http.StatusNotFound, "step group not found")
+			return nil, false
+		}
+		h.logger.Error(r.Context(), "failed to get step group", map[string]interface{}{
+			"error":         err.Error(),
+			"step_group_id": groupID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get step group")
+		return nil, false
+	}
+	if group.ProjectID != projectID {
+		respondError(w, http.StatusNotFound, "step group not found")
+		return nil, false
+	}
+
+	return group, true
+}
+
+// List handles GET /projects/{project_id}/step-groups.
+func (h *StepGroupHandler) List(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return
+	}
+	if !h.checkProjectAccess(w, r, projectID) {
+		return
+	}
+
+	limit, offset := parsePagination(r)
+	total, err := h.stepGroupStore.CountByProject(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to count step groups")
+		return
+	}
+	groups, err := h.stepGroupStore.ListByProject(r.Context(), projectID, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list step groups")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, NewPaginatedResponse(groups, total, limit, offset))
+}
+
+// Create handles POST /projects/{project_id}/step-groups.
+func (h *StepGroupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return
+	}
+	if !h.checkProjectAccess(w, r, projectID) {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	var req CreateStepGroupRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	group := &steplibrary.StepGroup{
+		ProjectID:   projectID,
+		Name:        req.Name,
+		Description: req.Description,
+		Steps:       req.Steps,
+		CreatedBy:   userID,
+	}
+	if err := h.stepGroupStore.Create(r.Context(), group); err != nil {
+		if isStepGroupValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error(r.Context(), "failed to create step group", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to create step group")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, group)
+}
+
+// GetByID handles GET /projects/{project_id}/step-groups/{group_id}.
+func (h *StepGroupHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.getGroup(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, group)
+}
+
+// Update handles PUT /projects/{project_id}/step-groups/{group_id}. Changed
+// steps reach every procedure referencing the group the next time it is
+// run, exported or scripted; the response lists them so they can be
+// reviewed.
+func (h *StepGroupHandler) Update(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.getGroup(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateStepGroupRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var setters []steplibrary.UpdateSetter
+	if req.Name != nil {
+		setters = append(setters, steplibrary.SetName(*req.Name))
+	}
+	if req.Description != nil {
+		setters = append(setters, steplibrary.SetDescription(*req.Description))
+	}
+	if req.Steps != nil {
+		setters = append(setters, steplibrary.SetSteps(*req.Steps))
+	}
+	if len(setters) == 0 {
+		respondError(w, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	if err := h.stepGroupStore.Update(r.Context(), group.ID, setters...); err != nil {
+		if errors.Is(err, steplibrary.ErrStepGroupNotFound) {
+			respondError(w, http.StatusNotFound, "step group not found")
+			return
+		}
+		if isStepGroupValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error(r.Context(), "failed to update step group", map[string]interface{}{
+			"error":         err.Error(),
+			"step_group_id": group.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to update step group")
+		return
+	}
+
+	updated, err := h.stepGroupStore.GetByID(r.Context(), group.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get updated step group")
+		return
+	}
+
+	response := StepGroupUpdateResponse{StepGroup: updated, AffectedProcedures: []StepGroupUsage{}}
+	if updated.Revision != group.Revision {
+		usages, ok := h.usages(w, r, updated)
+		if !ok {
+			return
+		}
+		for _, u := range usages {
+			if u.Changed {
+				response.AffectedProcedures = append(response.AffectedProcedures, u)
+			}
+		}
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// Delete handles DELETE /projects/{project_id}/step-groups/{group_id}.
+// Groups still referenced by a procedure draft or latest version cannot be
+// deleted, since those procedures could no longer run.
+func (h *StepGroupHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.getGroup(w, r)
+	if !ok {
+		return
+	}
+
+	usages, ok := h.usages(w, r, group)
+	if !ok {
+		return
+	}
+	if len(usages) > 0 {
+		respondJSON(w, http.StatusConflict, StepGroupInUseResponse{
+			Error:      "step group is used by procedures",
+			Procedures: usages,
+		})
+		return
+	}
+
+	if err := h.stepGroupStore.Delete(r.Context(), group.ID); err != nil {
+		if errors.Is(err, steplibrary.ErrStepGroupNotFound) {
+			respondError(w, http.StatusNotFound, "step group not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to delete step group", map[string]interface{}{
+			"error":         err.Error(),
+			"step_group_id": group.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to delete step group")
+		return
+	}
+
+	respondSuccess(w, "step group deleted successfully")
+}
+
+// ListProcedures handles GET
+// /projects/{project_id}/step-groups/{group_id}/procedures, the procedure
+// drafts and latest versions that reference a step group.
+func (h *StepGroupHandler) ListProcedures(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.getGroup(w, r)
+	if !ok {
+		return
+	}
+
+	usages, ok := h.usages(w, r, group)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": usages,
+		"total": len(usages),
+	})
+}
+
+// ProcedureReferences handles GET /procedures/{id}/step-groups, the step
+// groups the latest version of a procedure references, or its draft with
+// ?draft=true, flagging those changed since it was reviewed against them.
+// Saving a reference with the group's current revision marks it reviewed.
+func (h *StepGroupHandler) ProcedureReferences(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
+	if !ok {
+		return
+	}
+	if !h.testProcedures.checkProcedureOwnership(w, r, id) {
+		return
+	}
+
+	var tp *testprocedure.TestProcedure
+	var err error
+	if r.URL.Query().Get("draft") == "true" {
+		tp, err = h.testProcedureStore.GetDraft(r.Context(), id)
+	} else {
+		tp, err = h.testProcedureStore.GetLatestCommitted(r.Context(), id)
+	}
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrDraftNotFound) || errors.Is(err, testprocedure.ErrNoCommittedVersion) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error(r.Context(), "failed to get test procedure", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return
+	}
+
+	refs, err := steplibrary.References(r.Context(), h.stepGroupStore, tp.ProjectID, tp.Steps)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get step groups")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": refs,
+		"total": len(refs),
+	})
+}
+
+// usages returns the procedure versions that reference a group. Returns
+// false if they cannot be listed (response already written).
+func (h *StepGroupHandler) usages(w http.ResponseWriter, r *http.Request, group *steplibrary.StepGroup) ([]StepGroupUsage, bool) {
+	procedures, err := h.testProcedureStore.ListByStepGroup(r.Context(), group.ProjectID, group.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list procedures using step group")
+		return nil, false
+	}
+
+	usages := make([]StepGroupUsage, 0, len(procedures))
+	for _, tp := range procedures {
+		rootID := tp.ID
+		if tp.ParentID != nil {
+			rootID = *tp.ParentID
+		}
+		usage := StepGroupUsage{
+			ProcedureID: rootID,
+			VersionID:   tp.ID,
+			Name:        tp.Name,
+			Version:     tp.Version,
+		}
+		for _, step := range tp.Steps {
+			if step.GroupID == nil || *step.GroupID != group.ID {
+				continue
+			}
+			if usage.PinnedRevision == 0 || step.GroupRevision < usage.PinnedRevision {
+				usage.PinnedRevision = step.GroupRevision
+			}
+		}
+		usage.Changed = usage.PinnedRevision < group.Revision
+		usages = append(usages, usage)
+	}
+	return usages, true
+}
+
+// respondStepGroupError responds to an error checking or expanding the
+// step group references of a procedure.
+func respondStepGroupError(w http.ResponseWriter, r *http.Request, err error, log logger.Logger) {
+	if errors.Is(err, steplibrary.ErrStepGroupNotFound) || errors.Is(err, steplibrary.ErrInvalidReference) {
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	log.Error(r.Context(), "failed to get step groups", map[string]interface{}{
+		"error": err.Error(),
+	})
+	respondError(w, http.StatusInternalServerError, "failed to get step groups")
+}
+
+// expandStepGroups returns tp with its step group references replaced by
+// the groups' steps. Returns false if they cannot be expanded (response
+// already written).
+func expandStepGroups(w http.ResponseWriter, r *http.Request, store steplibrary.Store, tp *testprocedure.TestProcedure, log logger.Logger) (*testprocedure.TestProcedure, bool) {
+	expanded, err := steplibrary.Expand(r.Context(), store, tp)
+	if err != nil {
+		respondStepGroupError(w, r, err, log)
+		return nil, false
+	}
+	return expanded, true
+}
+
+// pinStepGroups checks the step group references in steps saved to a
+// procedure in a project and returns the steps to save, with new
+// references pinned to their group's current revision. Returns false if a
+// reference is invalid (response already written).
+func (h *TestProcedureHandler) pinStepGroups(w http.ResponseWriter, r *http.Request, projectID uuid.UUID, steps testprocedure.Steps) (testprocedure.Steps, bool) {
+	pinned, err := steplibrary.Pin(r.Context(), h.stepGroupStore, projectID, steps)
+	if err != nil {
+		respondStepGroupError(w, r, err, h.logger)
+		return nil, false
+	}
+	return pinned, true
+}
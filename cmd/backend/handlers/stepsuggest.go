@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/hairizuanbinnoorazman/ui-automation/stepsuggest"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/visualregression"
+)
+
+// StepSuggestionHandler proposes procedure steps from a screenshot of the
+// page under test and appends them to the procedure's draft for the author
+// to review.
+type StepSuggestionHandler struct {
+	testProcedureStore testprocedure.Store
+	endpointStore      endpoint.Store
+	testProcedures     *TestProcedureHandler
+	suggester          stepsuggest.Suggester
+	capturer           visualregression.Capturer
+	meter              *llmusage.Meter
+	timeout            time.Duration
+	logger             logger.Logger
+}
+
+// NewStepSuggestionHandler creates a new step suggestion handler. Procedure
+// ownership is checked and screenshots are stored through the test
+// procedure handler, pages of endpoints are captured with capturer and the
+// model's usage is metered by meter. timeout bounds a suggestion, for which the response's write deadline is
+// extended.
+func NewStepSuggestionHandler(
+	testProcedureStore testprocedure.Store,
+	endpointStore endpoint.Store,
+	testProcedures *TestProcedureHandler,
+	suggester stepsuggest.Suggester,
+	capturer visualregression.Capturer,
+	meter *llmusage.Meter,
+	timeout time.Duration,
+	log logger.Logger,
+) *StepSuggestionHandler {
+	return &StepSuggestionHandler{
+		testProcedureStore: testProcedureStore,
+		endpointStore:      endpointStore,
+		testProcedures:     testProcedures,
+		suggester:          suggester,
+		capturer:           capturer,
+		meter:              meter,
+		timeout:            timeout,
+		logger:             log,
+	}
+}
+
+// SuggestStepsRequest asks for steps for a page of an endpoint, captured by
+// the server, instead of an uploaded screenshot.
+type SuggestStepsRequest struct {
+	EndpointID string `json:"endpoint_id"`
+	Path       string `json:"path,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+}
+
+// SuggestStepsResponse is the response for a step suggestion: the suggested
+// steps, the stored screenshot attached to the first of them and the draft
+// they were appended to.
+type SuggestStepsResponse struct {
+	Suggestions []stepsuggest.Suggestion     `json:"suggestions"`
+	ImagePath   string                       `json:"image_path"`
+	Draft       *testprocedure.TestProcedure `json:"draft"`
+}
+
+// screenshot is the page steps are suggested for.
+type screenshot struct {
+	filename string
+	data     []byte
+	pageURL  string
+	hint     string
+}
+
+// Suggest handles POST /procedures/{id}/steps/suggest. The screenshot is
+// either uploaded as the "image" field of a multipart form, with optional
+// "page_url" and "hint" fields, or captured from an endpoint given in a
+// JSON body.
+func (h *StepSuggestionHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
+	if !ok {
+		return
+	}
+	if !h.testProcedures.checkProcedureOwnership(w, r, id) {
+		return
+	}
+	if !h.testProcedures.checkDraftLock(w, r, id) {
+		return
+	}
+
+	// The model and the browser are slower than the server's write timeout
+	// allows for
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(h.timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Warn(r.Context(), "failed to extend write deadline", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	var shot *screenshot
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		shot, ok = h.uploadedScreenshot(w, r)
+	} else {
+		shot, ok = h.capturedScreenshot(w, r)
+	}
+	if !ok {
+		return
+	}
+
+	// Invalid images are rejected before they reach the model
+	if !validStepImage(w, shot.filename, shot.data) {
+		return
+	}
+
+	draft, err := h.testProcedureStore.GetDraft(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrDraftNotFound) {
+			respondError(w, http.StatusNotFound, "draft not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get draft")
+		return
+	}
+
+	if !checkLLMBudget(w, r, h.meter, draft.ProjectID, h.logger) {
+		return
+	}
+
+	userID, _ := GetUserID(r.Context())
+	ctx, tracker := llmusage.Track(r.Context())
+	suggestions, err := h.suggester.Suggest(ctx, stepsuggest.Request{
+		Image:                shot.data,
+		MediaType:            http.DetectContentType(shot.data),
+		PageURL:              shot.pageURL,
+		ProcedureName:        draft.Name,
+		ProcedureDescription: draft.Description,
+		ExistingSteps:        draft.Steps,
+		Hint:                 shot.hint,
+	})
+	h.meter.Record(r.Context(), draft.ProjectID, userID, llmusage.FeatureStepSuggestion, tracker.Usage())
+	if err != nil {
+		switch {
+		case errors.Is(err, stepsuggest.ErrHintTooLong), errors.Is(err, stepsuggest.ErrImageRequired):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, stepsuggest.ErrNoSuggestions):
+			respondError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, resilience.ErrCircuitOpen):
+			respondError(w, http.StatusServiceUnavailable, "step suggestions temporarily unavailable")
+		default:
+			h.logger.Error(r.Context(), "failed to suggest steps", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": id.String(),
+			})
+			respondError(w, http.StatusBadGateway, "failed to suggest steps")
+		}
+		return
+	}
+
+	imagePath, ok := h.testProcedures.saveStepImage(w, r, id, shot.filename, shot.data)
+	if !ok {
+		return
+	}
+
+	steps := append(testprocedure.Steps{}, draft.Steps...)
+	for i, s := range suggestions {
+		var imagePaths []string
+		if i == 0 {
+			imagePaths = []string{imagePath}
+		}
+		steps = append(steps, s.Step(imagePaths))
+	}
+	if err := h.testProcedureStore.UpdateDraft(r.Context(), id, testprocedure.SetSteps(steps)); err != nil {
+		h.logger.Error(r.Context(), "failed to append suggested steps", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to update draft")
+		return
+	}
+
+	updated, err := h.testProcedureStore.GetDraft(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get updated draft")
+		return
+	}
+
+	h.logger.Info(r.Context(), "steps suggested", map[string]interface{}{
+		"test_procedure_id": id.String(),
+		"suggestions":       len(suggestions),
+	})
+	respondJSON(w, http.StatusOK, SuggestStepsResponse{
+		Suggestions: suggestions,
+		ImagePath:   imagePath,
+		Draft:       updated,
+	})
+}
+
+// uploadedScreenshot reads a screenshot uploaded as a multipart form.
+// Returns false if it cannot be read (response already written).
+func (h *StepSuggestionHandler) uploadedScreenshot(w http.ResponseWriter, r *http.Request) (*screenshot, bool) {
+	if err := r.ParseMultipartForm(MaxStepImageSize); err != nil {
+		respondError(w, http.StatusBadRequest, "failed to parse multipart form")
+		return nil, false
+	}
+
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "image file is required")
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to process file")
+		return nil, false
+	}
+
+	return &screenshot{
+		filename: header.Filename,
+		data:     data,
+		pageURL:  r.FormValue("page_url"),
+		hint:     r.FormValue("hint"),
+	}, true
+}
+
+// capturedScreenshot captures the page of an endpoint the authenticated user
+// owns. Returns false if it cannot be captured (response already written).
+func (h *StepSuggestionHandler) capturedScreenshot(w http.ResponseWriter, r *http.Request) (*screenshot, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return nil, false
+	}
+
+	var req SuggestStepsRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return nil, false
+	}
+	if req.EndpointID == "" {
+		respondError(w, http.StatusBadRequest, "an image upload or endpoint_id is required")
+		return nil, false
+	}
+	endpointID, err := uuid.Parse(req.EndpointID)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "endpoint_id must be a valid UUID")
+		return nil, false
+	}
+
+	ep, err := h.endpointStore.GetByID(r.Context(), endpointID)
+	if err != nil {
+		if errors.Is(err, endpoint.ErrEndpointNotFound) {
+			respondError(w, http.StatusNotFound, "endpoint not found")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get endpoint")
+		return nil, false
+	}
+	if ep.CreatedBy != userID {
+		respondError(w, http.StatusNotFound, "endpoint not found")
+		return nil, false
+	}
+	if ep.UseRunner {
+		respondError(w, http.StatusBadRequest, "pages of endpoints that use agent runners cannot be captured; upload a screenshot instead")
+		return nil, false
+	}
+
+	dir, err := os.MkdirTemp("", "step-suggestion-")
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to capture page")
+		return nil, false
+	}
+	defer os.RemoveAll(dir)
+
+	pageURL := visualregression.PageURL(ep.URL, req.Path)
+	captures, err := h.capturer.Capture(r.Context(), visualregression.CaptureRequest{
+		Targets: []visualregression.Target{{Name: "page", URL: pageURL}},
+		Viewport: visualregression.Viewport{
+			Width:  visualregression.DefaultViewportWidth,
+			Height: visualregression.DefaultViewportHeight,
+		},
+		OutputDir: dir,
+	})
+	if err != nil || len(captures) == 0 || captures[0].File == "" {
+		reason := "no screenshot taken"
+		if err != nil {
+			reason = err.Error()
+		} else if len(captures) > 0 && captures[0].Error != "" {
+			reason = captures[0].Error
+		}
+		h.logger.Warn(r.Context(), "failed to capture page for step suggestion", map[string]interface{}{
+			"error":       reason,
+			"endpoint_id": ep.ID.String(),
+			"url":         pageURL,
+		})
+		respondError(w, http.StatusBadGateway, "failed to capture page: "+reason)
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.Base(captures[0].File)))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to read captured page")
+		return nil, false
+	}
+
+	return &screenshot{
+		filename: "page.png",
+		data:     data,
+		pageURL:  pageURL,
+		hint:     req.Hint,
+	}, true
+}
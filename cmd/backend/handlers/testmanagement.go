@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
+	"github.com/hairizuanbinnoorazman/ui-automation/testmanagement"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// TestManagementHandler handles requests for the TestRail or Xray
+// connection of a project and the result links of its test runs.
+// Connection routes are registered on the project router, whose
+// authorization middleware has already verified ownership; link routes are
+// on the run router, whose middleware checks the run's owner.
+type TestManagementHandler struct {
+	store        testmanagement.ConnectionStore
+	linkStore    testmanagement.LinkStore
+	testRunStore testrun.Store
+	pusher       *testmanagement.Pusher
+	logger       logger.Logger
+}
+
+// NewTestManagementHandler creates a new test management handler.
+func NewTestManagementHandler(
+	store testmanagement.ConnectionStore,
+	linkStore testmanagement.LinkStore,
+	testRunStore testrun.Store,
+	pusher *testmanagement.Pusher,
+	log logger.Logger,
+) *TestManagementHandler {
+	return &TestManagementHandler{
+		store:        store,
+		linkStore:    linkStore,
+		testRunStore: testRunStore,
+		pusher:       pusher,
+		logger:       log,
+	}
+}
+
+// SaveTestManagementConnectionRequest represents a test management
+// connection request. An empty token keeps the token already saved.
+type SaveTestManagementConnectionRequest struct {
+	Provider       testmanagement.Provider `json:"provider"`
+	BaseURL        string                  `json:"base_url"`
+	Username       string                  `json:"username"`
+	Token          string                  `json:"token"`
+	PushOnComplete bool                    `json:"push_on_complete"`
+}
+
+// CreateResultLinkRequest represents a request to link a test run to a
+// TestRail case of a run, or an Xray test of a test execution.
+type CreateResultLinkRequest struct {
+	Target   string `json:"target"`
+	TestCase string `json:"test_case"`
+}
+
+// isTestManagementValidationError reports whether err is caused by an
+// invalid connection or link.
+func isTestManagementValidationError(err error) bool {
+	for _, target := range []error{
+		testmanagement.ErrInvalidProvider,
+		testmanagement.ErrInvalidBaseURL,
+		testmanagement.ErrUsernameRequired,
+		testmanagement.ErrTokenRequired,
+		testmanagement.ErrInvalidTarget,
+		testmanagement.ErrInvalidTestCase,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetConnection handles GET /projects/{id}/test-management.
+func (h *TestManagementHandler) GetConnection(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	conn, err := h.store.GetByProject(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, testmanagement.ErrConnectionNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get test management connection")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, conn)
+}
+
+// SaveConnection handles PUT /projects/{id}/test-management.
+func (h *TestManagementHandler) SaveConnection(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req SaveTestManagementConnectionRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	conn := &testmanagement.Connection{
+		ProjectID:      projectID,
+		Provider:       req.Provider,
+		BaseURL:        req.BaseURL,
+		Username:       req.Username,
+		Token:          req.Token,
+		PushOnComplete: req.PushOnComplete,
+		CreatedBy:      userID,
+	}
+	if err := h.store.Save(r.Context(), conn); err != nil {
+		if isTestManagementValidationError(err) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to save test management connection")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, conn)
+}
+
+// DeleteConnection handles DELETE /projects/{id}/test-management.
+func (h *TestManagementHandler) DeleteConnection(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	if err := h.store.Delete(r.Context(), projectID); err != nil {
+		if errors.Is(err, testmanagement.ErrConnectionNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete test management connection")
+		return
+	}
+
+	respondSuccess(w, "test management connection deleted successfully")
+}
+
+// ListLinks handles GET /runs/{run_id}/result-links.
+func (h *TestManagementHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	links, err := h.linkStore.ListByRun(r.Context(), runID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list result links")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, links)
+}
+
+// CreateLink handles POST /runs/{run_id}/result-links, linking the run to a
+// test case of the project's test management tool.
+func (h *TestManagementHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+	owner, _ := GetRunOwner(r.Context())
+
+	var req CreateResultLinkRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	conn, err := h.store.GetByProject(r.Context(), owner.ProjectID)
+	if err != nil {
+		if errors.Is(err, testmanagement.ErrConnectionNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get test management connection")
+		return
+	}
+
+	userID, _ := GetUserID(r.Context())
+	link := &testmanagement.Link{
+		TestRunID: runID,
+		Target:    req.Target,
+		TestCase:  req.TestCase,
+		CreatedBy: userID,
+	}
+	if err := conn.ValidateLink(link); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.linkStore.Create(r.Context(), link); err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to create result link")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, link)
+}
+
+// runLink returns a link of the run in the URL with the run's owner. Links of other runs are reported as not found. Returns false if it
+// fails (response already written).
+func (h *TestManagementHandler) runLink(w http.ResponseWriter, r *http.Request) (ownership.Owner, *testmanagement.Link, bool) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return ownership.Owner{}, nil, false
+	}
+	linkID, ok := parseUUIDOrRespond(w, r, "link_id", "result link")
+	if !ok {
+		return ownership.Owner{}, nil, false
+	}
+	owner, _ := GetRunOwner(r.Context())
+
+	link, err := h.linkStore.GetByID(r.Context(), linkID)
+	if err != nil {
+		if errors.Is(err, testmanagement.ErrLinkNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return ownership.Owner{}, nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get result link")
+		return ownership.Owner{}, nil, false
+	}
+	if link.TestRunID != runID {
+		respondError(w, http.StatusNotFound, testmanagement.ErrLinkNotFound.Error())
+		return ownership.Owner{}, nil, false
+	}
+	return owner, link, true
+}
+
+// DeleteLink handles DELETE /runs/{run_id}/result-links/{link_id}.
+func (h *TestManagementHandler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	_, link, ok := h.runLink(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.linkStore.Delete(r.Context(), link.ID); err != nil {
+		if errors.Is(err, testmanagement.ErrLinkNotFound) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to delete result link")
+		return
+	}
+
+	respondSuccess(w, "result link deleted successfully")
+}
+
+// PushLink handles POST /runs/{run_id}/result-links/{link_id}/push,
+// pushing the result of the completed run to the linked test case.
+func (h *TestManagementHandler) PushLink(w http.ResponseWriter, r *http.Request) {
+	owner, link, ok := h.runLink(w, r)
+	if !ok {
+		return
+	}
+
+	run, err := h.testRunStore.GetByID(r.Context(), link.TestRunID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get test run")
+		return
+	}
+
+	pushed, err := h.pusher.Push(r.Context(), owner.ProjectID, run, link)
+	if err != nil {
+		switch {
+		case errors.Is(err, testmanagement.ErrConnectionNotFound):
+			respondError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, testmanagement.ErrRunNotCompleted), errors.Is(err, testmanagement.ErrProviderMismatch):
+			respondError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, testmanagement.ErrTokenRequired), errors.Is(err, testmanagement.ErrDecryptFailed):
+			respondError(w, http.StatusInternalServerError, "failed to push test run result")
+		default:
+			h.logger.Warn(r.Context(), "failed to push test run result", map[string]interface{}{
+				"error":   err.Error(),
+				"link_id": link.ID.String(),
+				"run_id":  link.TestRunID.String(),
+			})
+			respondError(w, http.StatusBadGateway, "failed to push test run result: "+err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, pushed)
+}
@@ -3,6 +3,10 @@ package handlers
 import (
 	"archive/zip"
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,75 +14,167 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/activity"
+	"github.com/hairizuanbinnoorazman/ui-automation/label"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
+	"github.com/hairizuanbinnoorazman/ui-automation/presence"
+	"github.com/hairizuanbinnoorazman/ui-automation/procedureshare"
 	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/quota"
+	"github.com/hairizuanbinnoorazman/ui-automation/steplibrary"
 	"github.com/hairizuanbinnoorazman/ui-automation/storage"
 	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
 )
 
+const (
+	// MaxStepImageSize is the maximum step image upload size (10MB)
+	MaxStepImageSize = 10 << 20
+
+	// MaxStepAttachmentSize is the maximum step attachment upload size (25MB)
+	MaxStepAttachmentSize = 25 << 20
+
+	// MaxStepRecordingSize is the maximum size of a screen recording
+	// attached to a step (20MB)
+	MaxStepRecordingSize = 20 << 20
+)
+
+// stepAttachmentType is a kind of file steps can have attached: the content
+// type it is downloaded as, the types its content may be detected as, and
+// whether it is a screen recording.
+type stepAttachmentType struct {
+	contentType string
+	detected    []string
+	recording   bool
+}
+
+// stepAttachmentTypes are the file types steps can have attached, by
+// extension. Text files must not be detected as HTML, and documents must
+// match their format, so an attachment cannot smuggle in another kind of
+// file.
+var stepAttachmentTypes = map[string]stepAttachmentType{
+	".csv":  {"text/csv", []string{"text/plain"}, false},
+	".txt":  {"text/plain", []string{"text/plain"}, false},
+	".json": {"application/json", []string{"text/plain"}, false},
+	".xml":  {"application/xml", []string{"text/xml", "text/plain"}, false},
+	".yaml": {"application/yaml", []string{"text/plain"}, false},
+	".yml":  {"application/yaml", []string{"text/plain"}, false},
+	".pdf":  {"application/pdf", []string{"application/pdf"}, false},
+	".zip":  {"application/zip", []string{"application/zip"}, false},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []string{"application/zip"}, false},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", []string{"application/zip"}, false},
+	".mp4":  {"video/mp4", []string{"video/mp4"}, true},
+	".webm": {"video/webm", []string{"video/webm"}, true},
+	".gif":  {"image/gif", []string{"image/gif"}, true},
+}
+
 // TestProcedureHandler handles test procedure-related requests.
 type TestProcedureHandler struct {
 	testProcedureStore testprocedure.Store
-	projectStore       project.Store
+	stepImageStore     testprocedure.StepImageStore
+	attachmentStore    testprocedure.StepAttachmentStore
+	stepGroupStore     steplibrary.Store
+	owners             *ownership.Resolver
+	shares             procedureshare.Store
 	storage            storage.BlobStorage
+	quotas             *quota.Enforcer
+	labelStore         label.Store
+	activity           *activity.Recorder
+	presence           *presence.Tracker
 	logger             logger.Logger
 }
 
-// NewTestProcedureHandler creates a new test procedure handler.
-func NewTestProcedureHandler(testProcedureStore testprocedure.Store, projectStore project.Store, storage storage.BlobStorage, log logger.Logger) *TestProcedureHandler {
+// NewTestProcedureHandler creates a new test procedure handler. Creating
+// procedures and committing versions is recorded as activity. Drafts locked
+// in presenceTracker by another user cannot be changed. Step group
+// references are checked against stepGroupStore. Users outside the project
+// can access the procedures shared with them in shareStore.
+func NewTestProcedureHandler(testProcedureStore testprocedure.Store, stepImageStore testprocedure.StepImageStore, attachmentStore testprocedure.StepAttachmentStore, stepGroupStore steplibrary.Store, owners *ownership.Resolver, shareStore procedureshare.Store, storage storage.BlobStorage, quotas *quota.Enforcer, labelStore label.Store, activityRecorder *activity.Recorder, presenceTracker *presence.Tracker, log logger.Logger) *TestProcedureHandler {
 	return &TestProcedureHandler{
 		testProcedureStore: testProcedureStore,
-		projectStore:       projectStore,
+		stepImageStore:     stepImageStore,
+		attachmentStore:    attachmentStore,
+		stepGroupStore:     stepGroupStore,
+		owners:             owners,
+		shares:             shareStore,
 		storage:            storage,
+		quotas:             quotas,
+		labelStore:         labelStore,
+		activity:           activityRecorder,
+		presence:           presenceTracker,
 		logger:             log,
 	}
 }
 
-// checkProcedureOwnership verifies that the authenticated user owns the project
-// associated with the given procedure. Returns false if the check fails (response
-// already written).
+// checkProcedureOwnership verifies that the caller can access the project
+// associated with the given procedure, or that the procedure was shared with
+// them. A read-only share only lets GET and HEAD requests through. Returns
+// false if the check fails (response already written).
 func (h *TestProcedureHandler) checkProcedureOwnership(w http.ResponseWriter, r *http.Request, procedureID uuid.UUID) bool {
+	return h.checkProcedureAccess(w, r, procedureID, true)
+}
+
+// checkProcedureOwner is checkProcedureOwnership without shares, for what
+// stays with the project: deleting and archiving the procedure and managing
+// who it is shared with.
+func (h *TestProcedureHandler) checkProcedureOwner(w http.ResponseWriter, r *http.Request, procedureID uuid.UUID) bool {
+	return h.checkProcedureAccess(w, r, procedureID, false)
+}
+
+// checkProcedureAccess checks access to the procedure, letting users it was
+// shared with through if allowShared is set.
+func (h *TestProcedureHandler) checkProcedureAccess(w http.ResponseWriter, r *http.Request, procedureID uuid.UUID, allowShared bool) bool {
 	userID, ok := GetUserID(r.Context())
 	if !ok {
 		respondError(w, http.StatusUnauthorized, "user not authenticated")
 		return false
 	}
 
-	tp, err := h.testProcedureStore.GetByID(r.Context(), procedureID)
+	owner, err := h.owners.ProcedureOwner(r.Context(), procedureID)
 	if err != nil {
 		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
 			respondError(w, http.StatusNotFound, "test procedure not found")
 			return false
 		}
-		h.logger.Error(r.Context(), "failed to get test procedure for authorization", map[string]interface{}{
-			"error":             err.Error(),
-			"test_procedure_id": procedureID,
-		})
-		respondError(w, http.StatusInternalServerError, "authorization check failed")
-		return false
-	}
-
-	proj, err := h.projectStore.GetByID(r.Context(), tp.ProjectID)
-	if err != nil {
 		if errors.Is(err, project.ErrProjectNotFound) {
 			respondError(w, http.StatusNotFound, "project not found")
 			return false
 		}
-		h.logger.Error(r.Context(), "failed to get project for authorization", map[string]interface{}{
-			"error":      err.Error(),
-			"project_id": tp.ProjectID,
+		h.logger.Error(r.Context(), "failed to resolve test procedure owner for authorization", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": procedureID,
 		})
 		respondError(w, http.StatusInternalServerError, "authorization check failed")
 		return false
 	}
 
-	if proj.OwnerID != userID {
+	if !canAccessProject(r, owner.ProjectID, owner.UserID) {
+		if allowShared {
+			share, err := h.procedureShare(r, procedureID, userID)
+			if err != nil {
+				h.logger.Error(r.Context(), "failed to get procedure share for authorization", map[string]interface{}{
+					"error":             err.Error(),
+					"test_procedure_id": procedureID,
+				})
+				respondError(w, http.StatusInternalServerError, "authorization check failed")
+				return false
+			}
+			if share != nil {
+				if share.AllowsWrites() || r.Method == http.MethodGet || r.Method == http.MethodHead {
+					return true
+				}
+				respondError(w, http.StatusForbidden, "this test procedure is shared with you read-only")
+				return false
+			}
+		}
 		h.logger.Warn(r.Context(), "unauthorized procedure access attempt", map[string]interface{}{
 			"user_id":           userID,
-			"project_id":        tp.ProjectID,
-			"owner_id":          proj.OwnerID,
+			"project_id":        owner.ProjectID,
+			"owner_id":          owner.UserID,
 			"test_procedure_id": procedureID,
 		})
 		respondError(w, http.StatusForbidden, "you don't have access to this test procedure")
@@ -88,11 +184,70 @@ func (h *TestProcedureHandler) checkProcedureOwnership(w http.ResponseWriter, r
 	return true
 }
 
+// procedureShare returns the share of the procedure with the user, or nil if
+// it is not shared with them. Shares are made with people, so service
+// accounts never get one.
+func (h *TestProcedureHandler) procedureShare(r *http.Request, procedureID, userID uuid.UUID) (*procedureshare.Share, error) {
+	if h.shares == nil {
+		return nil, nil
+	}
+	if _, ok := GetServiceAccount(r.Context()); ok {
+		return nil, nil
+	}
+
+	proc, err := h.testProcedureStore.GetByID(r.Context(), procedureID)
+	if err != nil {
+		return nil, err
+	}
+	rootID := proc.ID
+	if proc.ParentID != nil {
+		rootID = *proc.ParentID
+	}
+
+	share, err := h.shares.Get(r.Context(), rootID, userID)
+	if errors.Is(err, procedureshare.ErrShareNotFound) {
+		return nil, nil
+	}
+	return share, err
+}
+
+// checkProcedureRoot checks access to the procedure in the URL parameter and
+// returns it with its first version, which per-procedure data shared by all
+// versions is attached to. Returns false if the check fails (response
+// already written).
+func (h *TestProcedureHandler) checkProcedureRoot(w http.ResponseWriter, r *http.Request, paramName string) (*testprocedure.TestProcedure, uuid.UUID, bool) {
+	procedureID, ok := parseUUIDOrRespond(w, r, paramName, "test procedure")
+	if !ok {
+		return nil, uuid.Nil, false
+	}
+	if !h.checkProcedureOwnership(w, r, procedureID) {
+		return nil, uuid.Nil, false
+	}
+
+	proc, err := h.testProcedureStore.GetByID(r.Context(), procedureID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get test procedure", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": procedureID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return nil, uuid.Nil, false
+	}
+
+	rootID := proc.ID
+	if proc.ParentID != nil {
+		rootID = *proc.ParentID
+	}
+	return proc, rootID, true
+}
+
 // CreateTestProcedureRequest represents a test procedure creation request.
 type CreateTestProcedureRequest struct {
 	Name        string                       `json:"name"`
 	Description string                       `json:"description"`
 	Steps       testprocedure.Steps          `json:"steps"`
+	Parameters  testprocedure.Parameters     `json:"parameters"`
+	Preconditions testprocedure.Preconditions `json:"preconditions"`
 }
 
 // UpdateTestProcedureRequest represents a test procedure update request.
@@ -100,6 +255,12 @@ type UpdateTestProcedureRequest struct {
 	Name        *string                      `json:"name,omitempty"`
 	Description *string                      `json:"description,omitempty"`
 	Steps       *testprocedure.Steps         `json:"steps,omitempty"`
+	NeedsReview *bool                        `json:"needs_review,omitempty"`
+	Parameters  *testprocedure.Parameters    `json:"parameters,omitempty"`
+	Preconditions *testprocedure.Preconditions `json:"preconditions,omitempty"`
+	// Revision is the draft revision the update was made from, like
+	// If-Match. Without either, the update overwrites any revision.
+	Revision    *uint                        `json:"revision,omitempty"`
 }
 
 // Create handles creating a new test procedure.
@@ -124,17 +285,25 @@ func (h *TestProcedureHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check the step groups the steps reference
+	steps, ok := h.pinStepGroups(w, r, projectID, req.Steps)
+	if !ok {
+		return
+	}
+
 	// Create test procedure
 	tp := &testprocedure.TestProcedure{
 		Name:        req.Name,
 		Description: req.Description,
-		Steps:       req.Steps,
+		Steps:       steps,
+		Parameters:  req.Parameters,
+		Preconditions: req.Preconditions,
 		ProjectID:   projectID,
 		CreatedBy:   userID,
 	}
 
 	if err := h.testProcedureStore.Create(r.Context(), tp); err != nil {
-		if errors.Is(err, testprocedure.ErrInvalidTestProcedureName) || errors.Is(err, testprocedure.ErrInvalidSteps) || errors.Is(err, testprocedure.ErrInvalidStepName) {
+		if errors.Is(err, testprocedure.ErrInvalidTestProcedureName) || errors.Is(err, testprocedure.ErrInvalidSteps) || errors.Is(err, testprocedure.ErrInvalidStepName) || errors.Is(err, testprocedure.ErrInvalidCondition) || errors.Is(err, testprocedure.ErrInvalidAttachment) || errors.Is(err, testprocedure.ErrInvalidPrecondition) || isParameterSchemaError(err) {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -145,6 +314,7 @@ func (h *TestProcedureHandler) Create(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "failed to create test procedure")
 		return
 	}
+	h.activity.Record(r.Context(), activity.ProcedureCreatedEvent(userID, projectID, tp.ID, tp.Name))
 
 	respondJSON(w, http.StatusCreated, tp)
 }
@@ -175,8 +345,18 @@ func (h *TestProcedureHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Narrow the list to procedures carrying every ?label= selector
+	rootIDs, ok := labelMatches(w, r, h.labelStore, h.logger, projectID, label.ResourceProcedure)
+	if !ok {
+		return
+	}
+	filter := testprocedure.ListFilter{
+		RootIDs:         rootIDs,
+		IncludeArchived: r.URL.Query().Get("include_archived") == "true",
+	}
+
 	// Get total count of test procedures
-	total, err := h.testProcedureStore.CountByProject(r.Context(), projectID)
+	total, err := h.testProcedureStore.CountByProjectFiltered(r.Context(), projectID, filter)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to count test procedures", map[string]interface{}{
 			"error":      err.Error(),
@@ -187,7 +367,7 @@ func (h *TestProcedureHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// List test procedures
-	procedures, err := h.testProcedureStore.ListByProject(r.Context(), projectID, limit, offset)
+	procedures, err := h.testProcedureStore.ListByProjectFiltered(r.Context(), projectID, filter, limit, offset)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to list test procedures", map[string]interface{}{
 			"error":      err.Error(),
@@ -208,6 +388,9 @@ func (h *TestProcedureHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	if !h.checkProcedureOwnership(w, r, id) {
+		return
+	}
 
 	// Check if draft version is requested
 	isDraft := r.URL.Query().Get("draft") == "true"
@@ -229,6 +412,7 @@ func (h *TestProcedureHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusInternalServerError, "failed to get draft")
 			return
 		}
+		setDraftETag(w, tp)
 	} else {
 		tp, err = h.testProcedureStore.GetLatestCommitted(r.Context(), id)
 		if err != nil {
@@ -260,6 +444,9 @@ func (h *TestProcedureHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	if !h.checkProcedureOwnership(w, r, id) {
+		return
+	}
 
 	// Parse request body
 	var req UpdateTestProcedureRequest
@@ -276,17 +463,55 @@ func (h *TestProcedureHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Description != nil {
 		setters = append(setters, testprocedure.SetDescription(*req.Description))
 	}
-	if req.Steps != nil {
-		setters = append(setters, testprocedure.SetSteps(*req.Steps))
+	if req.NeedsReview != nil {
+		setters = append(setters, testprocedure.SetNeedsReview(*req.NeedsReview))
+	}
+	if req.Parameters != nil {
+		setters = append(setters, testprocedure.SetParameters(*req.Parameters))
+	}
+	if req.Preconditions != nil {
+		setters = append(setters, testprocedure.SetPreconditions(*req.Preconditions))
 	}
 
-	if len(setters) == 0 {
+	if len(setters) == 0 && req.Steps == nil {
 		respondError(w, http.StatusBadRequest, "no fields to update")
 		return
 	}
 
+	// Only update the revision the changes were made from, if named
+	revision, hasRevision, ok := parseDraftRevision(w, r, req.Revision)
+	if !ok {
+		return
+	}
+	if hasRevision {
+		setters = append([]testprocedure.UpdateSetter{testprocedure.RequireRevision(revision)}, setters...)
+	}
+
+	// Only the holder of the edit lock, if any, may change the draft
+	if !h.checkDraftLock(w, r, id) {
+		return
+	}
+
+	// Check the step groups the steps reference
+	if req.Steps != nil {
+		proc, err := h.testProcedureStore.GetByID(r.Context(), id)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+			return
+		}
+		steps, ok := h.pinStepGroups(w, r, proc.ProjectID, *req.Steps)
+		if !ok {
+			return
+		}
+		setters = append(setters, testprocedure.SetSteps(steps))
+	}
+
 	// Update draft
 	if err := h.testProcedureStore.UpdateDraft(r.Context(), id, setters...); err != nil {
+		if errors.Is(err, testprocedure.ErrDraftConflict) {
+			h.respondDraftConflict(w, r, id)
+			return
+		}
 		if errors.Is(err, testprocedure.ErrDraftNotFound) {
 			respondError(w, http.StatusNotFound, "draft not found")
 			return
@@ -295,7 +520,7 @@ func (h *TestProcedureHandler) Update(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusNotFound, "test procedure not found")
 			return
 		}
-		if errors.Is(err, testprocedure.ErrInvalidTestProcedureName) || errors.Is(err, testprocedure.ErrInvalidSteps) {
+		if errors.Is(err, testprocedure.ErrInvalidTestProcedureName) || errors.Is(err, testprocedure.ErrInvalidSteps) || errors.Is(err, testprocedure.ErrInvalidCondition) || errors.Is(err, testprocedure.ErrInvalidAttachment) || errors.Is(err, testprocedure.ErrInvalidPrecondition) || isParameterSchemaError(err) {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -318,9 +543,30 @@ func (h *TestProcedureHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setDraftETag(w, updatedDraft)
 	respondJSON(w, http.StatusOK, updatedDraft)
 }
 
+// respondDraftConflict responds 409 to a draft update made from a stale
+// revision, with the current draft.
+func (h *TestProcedureHandler) respondDraftConflict(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	current, err := h.testProcedureStore.GetDraft(r.Context(), id)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get current draft", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get current draft")
+		return
+	}
+
+	setDraftETag(w, current)
+	respondJSON(w, http.StatusConflict, DraftConflictResponse{
+		Error: testprocedure.ErrDraftConflict.Error(),
+		Draft: current,
+	})
+}
+
 // Delete handles deleting a test procedure.
 func (h *TestProcedureHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	// Extract test procedure ID from URL
@@ -328,9 +574,23 @@ func (h *TestProcedureHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	if !h.checkProcedureOwner(w, r, id) {
+		return
+	}
+
+	// Every version is deleted with the procedure, so all of them are
+	// forgotten by the ownership cache.
+	forget := []uuid.UUID{id}
+	if versions, err := h.testProcedureStore.GetVersionHistory(r.Context(), id); err == nil {
+		for _, v := range versions {
+			forget = append(forget, v.ID)
+		}
+	}
 
 	// Delete test procedure
-	if err := h.testProcedureStore.Delete(r.Context(), id); err != nil {
+	err := h.testProcedureStore.Delete(r.Context(), id)
+	h.owners.ForgetProcedures(r.Context(), forget...)
+	if err != nil {
 		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
 			respondError(w, http.StatusNotFound, "test procedure not found")
 			return
@@ -346,6 +606,61 @@ func (h *TestProcedureHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	respondSuccess(w, "test procedure deleted successfully")
 }
 
+// Archive handles POST /projects/{project_id}/procedures/{id}/archive,
+// hiding the procedure from lists and blocking new runs of it. Its versions
+// and runs stay readable. Responds with the latest committed version.
+func (h *TestProcedureHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// Unarchive handles POST /projects/{project_id}/procedures/{id}/unarchive,
+// restoring an archived procedure. Responds with the latest committed
+// version.
+func (h *TestProcedureHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+// setArchived archives or unarchives the procedure in the URL.
+func (h *TestProcedureHandler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
+	if !ok {
+		return
+	}
+	if !h.checkProcedureOwner(w, r, id) {
+		return
+	}
+
+	set := h.testProcedureStore.Unarchive
+	if archived {
+		set = h.testProcedureStore.Archive
+	}
+	if err := set(r.Context(), id); err != nil {
+		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+			respondError(w, http.StatusNotFound, "test procedure not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to set test procedure archived state", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id,
+			"archived":          archived,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to update test procedure")
+		return
+	}
+
+	tp, err := h.testProcedureStore.GetLatestCommitted(r.Context(), id)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get test procedure", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tp)
+}
+
 // CreateVersion handles creating a new version of a test procedure.
 func (h *TestProcedureHandler) CreateVersion(w http.ResponseWriter, r *http.Request) {
 	// Extract test procedure ID from URL
@@ -353,6 +668,9 @@ func (h *TestProcedureHandler) CreateVersion(w http.ResponseWriter, r *http.Requ
 	if !ok {
 		return
 	}
+	if !h.checkProcedureOwnership(w, r, id) {
+		return
+	}
 
 	// Create version
 	newVersion, err := h.testProcedureStore.CreateVersion(r.Context(), id)
@@ -368,6 +686,7 @@ func (h *TestProcedureHandler) CreateVersion(w http.ResponseWriter, r *http.Requ
 		respondError(w, http.StatusInternalServerError, "failed to create version")
 		return
 	}
+	h.recordEdit(r, newVersion)
 
 	respondJSON(w, http.StatusCreated, newVersion)
 }
@@ -379,6 +698,9 @@ func (h *TestProcedureHandler) GetVersionHistory(w http.ResponseWriter, r *http.
 	if !ok {
 		return
 	}
+	if !h.checkProcedureOwnership(w, r, id) {
+		return
+	}
 
 	// Get version history
 	versions, err := h.testProcedureStore.GetVersionHistory(r.Context(), id)
@@ -412,7 +734,7 @@ func (h *TestProcedureHandler) UploadStepImage(w http.ResponseWriter, r *http.Re
 	}
 
 	// Parse multipart form (max 10MB)
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
+	if err := r.ParseMultipartForm(MaxStepImageSize); err != nil {
 		respondError(w, http.StatusBadRequest, "failed to parse multipart form")
 		return
 	}
@@ -425,8 +747,39 @@ func (h *TestProcedureHandler) UploadStepImage(w http.ResponseWriter, r *http.Re
 	}
 	defer file.Close()
 
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to process file")
+		return
+	}
+
+	path, ok := h.saveStepImage(w, r, id, header.Filename, data)
+	if !ok {
+		return
+	}
+
+	// Return the image path
+	respondJSON(w, http.StatusOK, map[string]string{
+		"image_path": path,
+	})
+}
+
+// checkProcedureQuota verifies that storing incomingBytes more for a test
+// procedure keeps its project within the storage quota. Returns false if the
+// check fails (response already written).
+func (h *TestProcedureHandler) checkProcedureQuota(w http.ResponseWriter, r *http.Request, procedureID uuid.UUID, incomingBytes int64) bool {
+	owner, err := h.owners.ProcedureOwner(r.Context(), procedureID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to resolve test procedure project")
+		return false
+	}
+	return checkStorageQuota(w, r, h.quotas, owner.ProjectID, incomingBytes, h.logger)
+}
+
+// validStepImage validates a step image by its file name and content.
+// Returns false if it is invalid (response already written).
+func validStepImage(w http.ResponseWriter, name string, data []byte) bool {
 	// Validate file type
-	ext := strings.ToLower(filepath.Ext(header.Filename))
 	validExts := map[string]bool{
 		".jpg":  true,
 		".jpeg": true,
@@ -434,58 +787,330 @@ func (h *TestProcedureHandler) UploadStepImage(w http.ResponseWriter, r *http.Re
 		".gif":  true,
 		".webp": true,
 	}
-	if !validExts[ext] {
+	if !validExts[strings.ToLower(filepath.Ext(name))] {
 		respondError(w, http.StatusBadRequest, "invalid file type, must be JPEG, PNG, GIF, or WebP")
-		return
+		return false
 	}
 
 	// Validate file content using magic bytes (not just the extension)
-	buf := make([]byte, 512)
-	n, err := file.Read(buf)
-	if err != nil && err != io.EOF {
-		respondError(w, http.StatusBadRequest, "failed to read file")
-		return
-	}
-	contentType := http.DetectContentType(buf[:n])
 	validMimeTypes := map[string]bool{
 		"image/jpeg": true,
 		"image/png":  true,
 		"image/gif":  true,
 		"image/webp": true,
 	}
-	if !validMimeTypes[contentType] {
+	if !validMimeTypes[http.DetectContentType(data)] {
 		respondError(w, http.StatusBadRequest, "invalid file content, must be JPEG, PNG, GIF, or WebP")
-		return
+		return false
 	}
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to process file")
-		return
+	return true
+}
+
+// saveStepImage validates a step image by its file name and content and
+// stores it. It returns the image path and writes the error response itself.
+func (h *TestProcedureHandler) saveStepImage(w http.ResponseWriter, r *http.Request, id uuid.UUID, name string, data []byte) (string, bool) {
+	if !validStepImage(w, name, data) {
+		return "", false
 	}
+	ext := strings.ToLower(filepath.Ext(name))
 
-	// Generate unique filename
-	filename := fmt.Sprintf("%s%s", uuid.New().String(), ext)
+	// Name the file after its content so that uploading the same image
+	// again yields the same path, which lets importers detect unchanged steps
+	sum := sha256.Sum256(data)
+	filename := fmt.Sprintf("%s%s", hex.EncodeToString(sum[:]), ext)
 	path := fmt.Sprintf("test-procedures/%s/steps/%s", id.String(), filename)
 
+	// An image that is already stored takes up no more space
+	_, err := h.stepImageStore.GetByPath(r.Context(), path)
+	if err != nil && !errors.Is(err, testprocedure.ErrStepImageNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to check existing image")
+		return "", false
+	}
+	if err != nil && !h.checkProcedureQuota(w, r, id, int64(len(data))) {
+		return "", false
+	}
+
 	// Upload to storage
-	if err := h.storage.Upload(r.Context(), path, file); err != nil {
+	if err := h.storage.Upload(r.Context(), path, bytes.NewReader(data)); err != nil {
 		h.logger.Error(r.Context(), "failed to upload image", map[string]interface{}{
 			"error":             err.Error(),
 			"test_procedure_id": id.String(),
 			"path":              path,
 		})
 		respondError(w, http.StatusInternalServerError, "failed to upload image")
-		return
+		return "", false
+	}
+
+	// Record the image so that its size counts towards the project's usage
+	image := &testprocedure.StepImage{Path: path, TestProcedureID: id, FileSize: int64(len(data))}
+	if err := h.stepImageStore.Create(r.Context(), image); err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to record image")
+		return "", false
 	}
 
 	h.logger.Info(r.Context(), "image uploaded", map[string]interface{}{
 		"test_procedure_id": id.String(),
 		"path":              path,
 	})
+	return path, true
+}
 
-	// Return the image path
-	respondJSON(w, http.StatusOK, map[string]string{
-		"image_path": path,
+// UploadStepAttachment handles uploading a file to attach to a test
+// procedure step. It responds with the attachment to add to the step.
+func (h *TestProcedureHandler) UploadStepAttachment(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
+	if !ok {
+		return
+	}
+
+	if !h.checkProcedureOwnership(w, r, id) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxStepAttachmentSize+(1<<20))
+	if err := r.ParseMultipartForm(MaxStepAttachmentSize); err != nil {
+		respondError(w, http.StatusBadRequest, "failed to parse multipart form")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > MaxStepAttachmentSize {
+		respondError(w, http.StatusBadRequest, "file exceeds maximum size of 25MB")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to process file")
+		return
+	}
+
+	attachment, ok := h.saveStepAttachment(w, r, id, header.Filename, data)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, attachment)
+}
+
+// validStepAttachment validates a step attachment by its file name and
+// content, and returns its type. Returns false if it is invalid (response
+// already written).
+func validStepAttachment(w http.ResponseWriter, name string, data []byte) (stepAttachmentType, bool) {
+	kind, ok := stepAttachmentTypes[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		respondError(w, http.StatusBadRequest, "invalid file type, must be CSV, TXT, JSON, XML, YAML, PDF, ZIP, XLSX, DOCX, MP4, WebM or GIF")
+		return stepAttachmentType{}, false
+	}
+	if len(data) == 0 {
+		respondError(w, http.StatusBadRequest, "file is empty")
+		return stepAttachmentType{}, false
+	}
+	if kind.recording && len(data) > MaxStepRecordingSize {
+		respondError(w, http.StatusBadRequest, "recording exceeds maximum size of 20MB")
+		return stepAttachmentType{}, false
+	}
+
+	// Validate file content using magic bytes (not just the extension)
+	detected, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	for _, allowed := range kind.detected {
+		if detected == allowed {
+			return kind, true
+		}
+	}
+	respondError(w, http.StatusBadRequest, "file content does not match its type")
+	return stepAttachmentType{}, false
+}
+
+// saveStepAttachment validates a step attachment by its file name and
+// content and stores it. It returns the attachment and writes the error
+// response itself.
+func (h *TestProcedureHandler) saveStepAttachment(w http.ResponseWriter, r *http.Request, id uuid.UUID, name string, data []byte) (*testprocedure.Attachment, bool) {
+	kind, ok := validStepAttachment(w, name, data)
+	if !ok {
+		return nil, false
+	}
+	name = filepath.Base(name)
+
+	// Name the file after its content, as step images are
+	sum := sha256.Sum256(data)
+	path := testprocedure.AttachmentPath(id, hex.EncodeToString(sum[:])+strings.ToLower(filepath.Ext(name)))
+
+	// A file that is already stored takes up no more space
+	_, err := h.attachmentStore.GetByPath(r.Context(), path)
+	if err != nil && !errors.Is(err, testprocedure.ErrStepAttachmentNotFound) {
+		respondError(w, http.StatusInternalServerError, "failed to check existing attachment")
+		return nil, false
+	}
+	if err != nil && !h.checkProcedureQuota(w, r, id, int64(len(data))) {
+		return nil, false
+	}
+
+	if err := h.storage.Upload(r.Context(), path, bytes.NewReader(data)); err != nil {
+		h.logger.Error(r.Context(), "failed to upload attachment", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id.String(),
+			"path":              path,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to upload attachment")
+		return nil, false
+	}
+
+	// Record the attachment so that its size counts towards the project's usage
+	record := &testprocedure.StepAttachment{Path: path, TestProcedureID: id, FileSize: int64(len(data))}
+	if err := h.attachmentStore.Create(r.Context(), record); err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to record attachment")
+		return nil, false
+	}
+
+	h.logger.Info(r.Context(), "attachment uploaded", map[string]interface{}{
+		"test_procedure_id": id.String(),
+		"path":              path,
 	})
+	return &testprocedure.Attachment{
+		Path:        path,
+		FileName:    name,
+		ContentType: kind.contentType,
+		FileSize:    int64(len(data)),
+	}, true
+}
+
+// DownloadStepAttachment handles downloading a file attached to a step of a
+// procedure version or draft, by the positions of the step and of the
+// attachment in it.
+func (h *TestProcedureHandler) DownloadStepAttachment(w http.ResponseWriter, r *http.Request) {
+	attachment, contentType, reader, ok := h.openStepAttachment(w, r)
+	if !ok {
+		return
+	}
+	defer reader.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(attachment.FileName)))
+	if _, err := io.Copy(w, reader); err != nil {
+		h.logger.Error(r.Context(), "failed to stream file", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// StreamStepRecording handles playing back a screen recording attached to a
+// step. Unlike downloads, recordings are served inline and support range
+// requests, so players can seek.
+func (h *TestProcedureHandler) StreamStepRecording(w http.ResponseWriter, r *http.Request) {
+	attachment, contentType, reader, ok := h.openStepAttachment(w, r)
+	if !ok {
+		return
+	}
+	defer reader.Close()
+
+	if !attachment.IsRecording() {
+		respondError(w, http.StatusBadRequest, "attachment is not a recording")
+		return
+	}
+	content, err := seekable(reader, MaxStepRecordingSize)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to read recording", map[string]interface{}{
+			"error": err.Error(),
+			"path":  attachment.Path,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to read recording")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filepath.Base(attachment.FileName)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", time.Time{}, content)
+}
+
+// openStepAttachment opens the attachment named by the request's step and
+// attachment positions, with the content type of its stored extension. Only
+// files uploaded to a procedure of the same project are served. Returns
+// false if it cannot be opened (response already written).
+func (h *TestProcedureHandler) openStepAttachment(w http.ResponseWriter, r *http.Request) (testprocedure.Attachment, string, io.ReadCloser, bool) {
+	id, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
+	if !ok {
+		return testprocedure.Attachment{}, "", nil, false
+	}
+	stepIndex, err := strconv.Atoi(mux.Vars(r)["step_index"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid step index")
+		return testprocedure.Attachment{}, "", nil, false
+	}
+	attachmentIndex, err := strconv.Atoi(mux.Vars(r)["attachment_index"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid attachment index")
+		return testprocedure.Attachment{}, "", nil, false
+	}
+
+	if !h.checkProcedureOwnership(w, r, id) {
+		return testprocedure.Attachment{}, "", nil, false
+	}
+
+	tp, err := h.testProcedureStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+			respondError(w, http.StatusNotFound, "test procedure not found")
+			return testprocedure.Attachment{}, "", nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return testprocedure.Attachment{}, "", nil, false
+	}
+	if stepIndex < 0 || stepIndex >= len(tp.Steps) || attachmentIndex < 0 || attachmentIndex >= len(tp.Steps[stepIndex].Attachments) {
+		respondError(w, http.StatusNotFound, "attachment not found")
+		return testprocedure.Attachment{}, "", nil, false
+	}
+	attachment := tp.Steps[stepIndex].Attachments[attachmentIndex]
+
+	uploadedTo, _ := testprocedure.AttachmentProcedure(attachment.Path)
+	owner, err := h.owners.ProcedureOwner(r.Context(), uploadedTo)
+	if err != nil || owner.ProjectID != tp.ProjectID {
+		respondError(w, http.StatusNotFound, "attachment not found")
+		return testprocedure.Attachment{}, "", nil, false
+	}
+	contentType := "application/octet-stream"
+	if kind, ok := stepAttachmentTypes[filepath.Ext(attachment.Path)]; ok {
+		contentType = kind.contentType
+	}
+
+	reader, err := h.storage.Download(r.Context(), attachment.Path)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			respondError(w, http.StatusNotFound, "file not found in storage")
+			return testprocedure.Attachment{}, "", nil, false
+		}
+		h.logger.Error(r.Context(), "failed to download from storage", map[string]interface{}{
+			"error": err.Error(),
+			"path":  attachment.Path,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to download file")
+		return testprocedure.Attachment{}, "", nil, false
+	}
+	return attachment, contentType, reader, true
+}
+
+// seekable returns reader as an io.ReadSeeker for range requests. Readers
+// that cannot seek, such as downloads from S3, are read into memory, up to
+// limit bytes.
+func seekable(reader io.Reader, limit int64) (io.ReadSeeker, error) {
+	if rs, ok := reader.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("file exceeds %d bytes", limit)
+	}
+	return bytes.NewReader(data), nil
 }
 
 // DraftDiffResponse represents the response for GetDiff.
@@ -556,6 +1181,9 @@ func (h *TestProcedureHandler) ResetDraft(w http.ResponseWriter, r *http.Request
 	if !h.checkProcedureOwnership(w, r, id) {
 		return
 	}
+	if !h.checkDraftLock(w, r, id) {
+		return
+	}
 
 	// Reset draft
 	if err := h.testProcedureStore.ResetDraft(r.Context(), id); err != nil {
@@ -582,8 +1210,92 @@ func (h *TestProcedureHandler) ResetDraft(w http.ResponseWriter, r *http.Request
 	respondSuccess(w, "draft reset successfully")
 }
 
+// branchNote says when the step at index i of a procedure with the given
+// branches is run, or what it decides if it is a conditional step. Steps
+// outside any branch get no note.
+func branchNote(branches []testprocedure.Branch, i int) string {
+	note := ""
+	for _, b := range branches {
+		if b.Step == i {
+			return "Check: " + b.String() + "."
+		}
+		// Later branches are nested inside earlier ones, so the innermost
+		// branch containing the step decides its note
+		if inThen, ok := b.Contains(i); ok {
+			if inThen {
+				note = fmt.Sprintf("Only if %s (step %d).", b.Condition, b.Step+1)
+			} else {
+				note = fmt.Sprintf("Only if %s (step %d).", b.Condition.Negation(), b.Step+1)
+			}
+		}
+	}
+	return note
+}
+
+// preconditionList renders preconditions as a Markdown list, one
+// "- <kind>: <description>" line each.
+func preconditionList(preconditions testprocedure.Preconditions) string {
+	var list strings.Builder
+	for _, precondition := range preconditions {
+		fmt.Fprintf(&list, "- %s\n", precondition)
+	}
+	return list.String()
+}
+
+// attachmentEntry names the jth attachment of the ith step in an export,
+// prefixed with their positions to avoid name collisions.
+func attachmentEntry(i, j int, attachment testprocedure.Attachment) string {
+	return fmt.Sprintf("step%d_%d_%s", i+1, j+1, filepath.Base(attachment.FileName))
+}
+
+// attachmentMarkdown links the jth attachment of the ith step in an export.
+// GIF recordings are shown in place, as images are.
+func attachmentMarkdown(i, j int, attachment testprocedure.Attachment) string {
+	link := fmt.Sprintf("[%s](./attachments/%s)", attachment.FileName, attachmentEntry(i, j, attachment))
+	if recordingContentType(attachment) == "image/gif" {
+		return "!" + link
+	}
+	return link
+}
+
+// recordingContentType returns the content type of a screen recording, or
+// an empty string if the attachment is not one.
+func recordingContentType(attachment testprocedure.Attachment) string {
+	if !attachment.IsRecording() {
+		return ""
+	}
+	return stepAttachmentTypes[strings.ToLower(filepath.Ext(attachment.Path))].contentType
+}
+
+// writeAttachments writes the attachments of steps into the attachments/
+// folder of an export. Attachments that cannot be downloaded are left out.
+func writeAttachments(ctx context.Context, zw *zip.Writer, blobs storage.BlobStorage, steps testprocedure.Steps, log logger.Logger) error {
+	for i, step := range steps {
+		for j, attachment := range step.Attachments {
+			reader, err := blobs.Download(ctx, attachment.Path)
+			if err != nil {
+				log.Warn(ctx, "failed to download step attachment for export", map[string]interface{}{
+					"error": err.Error(),
+					"path":  attachment.Path,
+				})
+				continue
+			}
+			entry, err := zw.Create("attachments/" + attachmentEntry(i, j, attachment))
+			if err == nil {
+				_, err = io.Copy(entry, reader)
+			}
+			reader.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // ExportMarkdown exports the latest committed procedure as a ZIP archive containing
-// procedure.md and an images/ folder with all step images.
+// procedure.md, an images/ folder with all step images and an attachments/
+// folder with all step attachments.
 func (h *TestProcedureHandler) ExportMarkdown(w http.ResponseWriter, r *http.Request) {
 	id, ok := parseUUIDOrRespond(w, r, "id", "test procedure")
 	if !ok {
@@ -613,23 +1325,40 @@ func (h *TestProcedureHandler) ExportMarkdown(w http.ResponseWriter, r *http.Req
 		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
 		return
 	}
+	tp, ok = expandStepGroups(w, r, h.stepGroupStore, tp, h.logger)
+	if !ok {
+		return
+	}
 
 	// Build procedure.md content with step-indexed image references to avoid name collisions
 	var md strings.Builder
 	fmt.Fprintf(&md, "# %s\n\n", tp.Name)
 	if tp.Description != "" {
-		fmt.Fprintf(&md, "%s\n\n", tp.Description)
+		fmt.Fprintf(&md, "%s\n\n", exportMarkdown(tp.Description, 1))
+	}
+	if len(tp.Preconditions) > 0 {
+		fmt.Fprintf(&md, "## Preconditions\n\n%s\n", preconditionList(tp.Preconditions))
 	}
+	branches := tp.Steps.Branches()
 	for i, step := range tp.Steps {
 		fmt.Fprintf(&md, "## Step %d: %s\n\n", i+1, step.Name)
+		if note := branchNote(branches, i); note != "" {
+			fmt.Fprintf(&md, "_%s_\n\n", note)
+		}
 		if step.Instructions != "" {
-			fmt.Fprintf(&md, "%s\n\n", step.Instructions)
+			fmt.Fprintf(&md, "%s\n\n", exportMarkdown(step.Instructions, 2))
+		}
+		if step.ExpectedResult != "" {
+			fmt.Fprintf(&md, "**Expected result:** %s\n\n", step.ExpectedResult)
 		}
 		for j, imagePath := range step.ImagePaths {
 			basename := filepath.Base(imagePath)
 			imgName := fmt.Sprintf("step%d_%d_%s", i+1, j+1, basename)
 			fmt.Fprintf(&md, "![%s](./images/%s)\n\n", step.Name, imgName)
 		}
+		for j, attachment := range step.Attachments {
+			fmt.Fprintf(&md, "%s\n\n", attachmentMarkdown(i, j, attachment))
+		}
 	}
 
 	// Build ZIP into a buffer so errors can still return proper HTTP responses
@@ -676,6 +1405,11 @@ func (h *TestProcedureHandler) ExportMarkdown(w http.ResponseWriter, r *http.Req
 			reader.Close()
 		}
 	}
+	if err := writeAttachments(ctx, zw, h.storage, tp.Steps, h.logger); err != nil {
+		h.logger.Error(ctx, "failed to write attachment to zip", map[string]interface{}{"error": err.Error()})
+		respondError(w, http.StatusInternalServerError, "failed to create zip")
+		return
+	}
 
 	if err := zw.Close(); err != nil {
 		h.logger.Error(ctx, "failed to finalize zip", map[string]interface{}{"error": err.Error()})
@@ -688,6 +1422,27 @@ func (h *TestProcedureHandler) ExportMarkdown(w http.ResponseWriter, r *http.Req
 	w.Write(buf.Bytes())
 }
 
+// CommitDraftRequest represents a draft commit request. The message is
+// required; the summary can describe the change at more length.
+type CommitDraftRequest struct {
+	Message string `json:"message"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// note returns the commit note of the request.
+func (req CommitDraftRequest) note() testprocedure.CommitNote {
+	return testprocedure.CommitNote{Message: req.Message, Summary: req.Summary}
+}
+
+// decodeCommitRequest decodes the body of a commit request into req. An
+// empty body leaves req as it is, for the missing message to be reported.
+func decodeCommitRequest(r *http.Request, req interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
 // CommitDraft handles committing the draft as a new version.
 func (h *TestProcedureHandler) CommitDraft(w http.ResponseWriter, r *http.Request) {
 	// Extract test procedure ID from URL
@@ -701,28 +1456,56 @@ func (h *TestProcedureHandler) CommitDraft(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Commit draft
-	newVersion, err := h.testProcedureStore.CommitDraft(r.Context(), id)
+	var req CommitDraftRequest
+	if err := decodeCommitRequest(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	h.commitDraft(w, r, id, req.note())
+}
+
+// commitDraft commits the draft of the procedure id as a new version
+// described by note, and responds with the version.
+func (h *TestProcedureHandler) commitDraft(w http.ResponseWriter, r *http.Request, id uuid.UUID, note testprocedure.CommitNote) {
+	newVersion, err := h.testProcedureStore.CommitDraft(r.Context(), id, note)
 	if err != nil {
-		if errors.Is(err, testprocedure.ErrDraftNotFound) {
-			respondError(w, http.StatusNotFound, "draft not found")
-			return
-		}
-		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
-			respondError(w, http.StatusNotFound, "test procedure not found")
-			return
-		}
-		if errors.Is(err, testprocedure.ErrInvalidStepName) {
-			respondError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		h.logger.Error(r.Context(), "failed to commit draft", map[string]interface{}{
-			"error":             err.Error(),
-			"test_procedure_id": id,
-		})
-		respondError(w, http.StatusInternalServerError, "failed to commit draft")
+		h.respondCommitError(w, r, id, err)
 		return
 	}
+	h.recordEdit(r, newVersion)
 
 	respondJSON(w, http.StatusCreated, newVersion)
 }
+
+// respondCommitError responds with the error from committing the draft of
+// the procedure id.
+func (h *TestProcedureHandler) respondCommitError(w http.ResponseWriter, r *http.Request, id uuid.UUID, err error) {
+	if errors.Is(err, testprocedure.ErrDraftNotFound) {
+		respondError(w, http.StatusNotFound, "draft not found")
+		return
+	}
+	if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+		respondError(w, http.StatusNotFound, "test procedure not found")
+		return
+	}
+	if errors.Is(err, testprocedure.ErrInvalidStepName) || errors.Is(err, testprocedure.ErrInvalidCondition) || errors.Is(err, testprocedure.ErrInvalidAttachment) || errors.Is(err, testprocedure.ErrInvalidPrecondition) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, testprocedure.ErrMissingCommitMessage) || errors.Is(err, testprocedure.ErrCommitMessageTooLong) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.logger.Error(r.Context(), "failed to commit draft", map[string]interface{}{
+		"error":             err.Error(),
+		"test_procedure_id": id,
+	})
+	respondError(w, http.StatusInternalServerError, "failed to commit draft")
+}
+
+// recordEdit records the caller committing a new version of a procedure.
+func (h *TestProcedureHandler) recordEdit(r *http.Request, version *testprocedure.TestProcedure) {
+	userID, _ := GetUserID(r.Context())
+	h.activity.Record(r.Context(), activity.ProcedureEditedEvent(userID, version.ProjectID, version.ID, version.Name, version.Version))
+}
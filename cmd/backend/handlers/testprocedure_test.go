@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidStepAttachment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		fileName        string
+		data            string
+		wantContentType string
+		wantOK          bool
+	}{
+		{name: "csv", fileName: "items.csv", data: "sku,qty\nA-1,3\n", wantContentType: "text/csv", wantOK: true},
+		{name: "upper-case extension", fileName: "CONFIG.JSON", data: `{"debug": true}`, wantContentType: "application/json", wantOK: true},
+		{name: "xml", fileName: "feed.xml", data: `<?xml version="1.0"?><feed/>`, wantContentType: "application/xml", wantOK: true},
+		{name: "pdf", fileName: "terms.pdf", data: "%PDF-1.7\n", wantContentType: "application/pdf", wantOK: true},
+		{name: "unsupported type", fileName: "run.sh", data: "#!/bin/sh\n"},
+		{name: "html disguised as csv", fileName: "items.csv", data: "<html><script>alert(1)</script></html>"},
+		{name: "binary disguised as text", fileName: "notes.txt", data: "\x00\x01\x02\x03"},
+		{name: "empty", fileName: "items.csv"},
+		{name: "gif recording", fileName: "login.gif", data: "GIF89a\x01\x00\x01\x00", wantContentType: "image/gif", wantOK: true},
+		{name: "webm recording", fileName: "login.webm", data: "\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x84webm", wantContentType: "video/webm", wantOK: true},
+		{name: "text disguised as video", fileName: "login.mp4", data: "not a video"},
+		{name: "recording over the size limit", fileName: "login.gif", data: "GIF89a" + strings.Repeat("\x00", MaxStepRecordingSize)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			kind, ok := validStepAttachment(w, tc.fileName, []byte(tc.data))
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !ok {
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+				}
+				return
+			}
+			if kind.contentType != tc.wantContentType {
+				t.Errorf("content type = %q, want %q", kind.contentType, tc.wantContentType)
+			}
+		})
+	}
+}
+
+func TestSeekable(t *testing.T) {
+	t.Parallel()
+
+	// Readers that can seek are used as they are
+	file := bytes.NewReader([]byte("recording"))
+	rs, err := seekable(file, 4)
+	if err != nil || rs != file {
+		t.Fatalf("seekable() = %v, %v, want the reader itself", rs, err)
+	}
+
+	// Others are read into memory, up to the limit
+	rs, err = seekable(io.NopCloser(strings.NewReader("recording")), 16)
+	if err != nil {
+		t.Fatalf("seekable() error = %v", err)
+	}
+	if _, err := rs.Seek(2, io.SeekStart); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	rest, _ := io.ReadAll(rs)
+	if string(rest) != "cording" {
+		t.Errorf("read %q after seeking, want %q", rest, "cording")
+	}
+
+	if _, err := seekable(io.NopCloser(strings.NewReader("recording")), 4); err == nil {
+		t.Error("seekable() over the limit: want an error")
+	}
+}
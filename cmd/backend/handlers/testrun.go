@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"archive/zip"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -14,9 +16,23 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/activity"
+	"github.com/hairizuanbinnoorazman/ui-automation/analytics"
+	"github.com/hairizuanbinnoorazman/ui-automation/annotate"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/label"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
-	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/media"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/narration"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
+	"github.com/hairizuanbinnoorazman/ui-automation/quota"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/hairizuanbinnoorazman/ui-automation/steplibrary"
 	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testmanagement"
 	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
 	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
 	"github.com/hairizuanbinnoorazman/ui-automation/user"
@@ -32,73 +48,102 @@ type TestRunHandler struct {
 	testRunStore       testrun.Store
 	assetStore         testrun.AssetStore
 	testProcedureStore testprocedure.Store
-	projectStore       project.Store
+	stepGroupStore     steplibrary.Store
+	owners             *ownership.Resolver
+	endpointStore      endpoint.Store
 	stepNoteStore      testrun.StepNoteStore
+	annotationStore    testrun.AnnotationStore
+	runTemplateStore   testrun.RunTemplateStore
 	userStore          user.Store
 	storage            storage.BlobStorage
+	recorder           *metering.Recorder
+	analytics          *analytics.Recorder
+	notifier           *notification.Notifier
+	mediaProcessor     *media.Processor
+	quotas             *quota.Enforcer
+	labelStore         label.Store
+	narrator           narration.Narrator
+	narrationTimeout   time.Duration
+	meter              *llmusage.Meter
+	resultPusher       *testmanagement.Pusher
+	activity           *activity.Recorder
 	logger             logger.Logger
 }
 
-// NewTestRunHandler creates a new test run handler.
-func NewTestRunHandler(testRunStore testrun.Store, assetStore testrun.AssetStore, testProcedureStore testprocedure.Store, projectStore project.Store, stepNoteStore testrun.StepNoteStore, userStore user.Store, storage storage.BlobStorage, log logger.Logger) *TestRunHandler {
+// NewTestRunHandler creates a new test run handler. Guides are narrated
+// with narrator, for which the response's write deadline is extended by
+// narrationTimeout, and the model's usage is metered by meter. The results
+// of completed runs are pushed to their test management links by
+// resultPusher, and starting and completing runs is recorded as activity.
+// Runs execute procedures with their step group references expanded from
+// stepGroupStore, and are set up from their procedure's run template in
+// runTemplateStore when created.
+func NewTestRunHandler(testRunStore testrun.Store, assetStore testrun.AssetStore, testProcedureStore testprocedure.Store, stepGroupStore steplibrary.Store, owners *ownership.Resolver, endpointStore endpoint.Store, stepNoteStore testrun.StepNoteStore, annotationStore testrun.AnnotationStore, runTemplateStore testrun.RunTemplateStore, userStore user.Store, storage storage.BlobStorage, recorder *metering.Recorder, analyticsRecorder *analytics.Recorder, notifier *notification.Notifier, mediaProcessor *media.Processor, quotas *quota.Enforcer, labelStore label.Store, narrator narration.Narrator, narrationTimeout time.Duration, meter *llmusage.Meter, resultPusher *testmanagement.Pusher, activityRecorder *activity.Recorder, log logger.Logger) *TestRunHandler {
 	return &TestRunHandler{
 		testRunStore:       testRunStore,
 		assetStore:         assetStore,
 		testProcedureStore: testProcedureStore,
-		projectStore:       projectStore,
+		stepGroupStore:     stepGroupStore,
+		owners:             owners,
+		endpointStore:      endpointStore,
 		stepNoteStore:      stepNoteStore,
+		annotationStore:    annotationStore,
+		runTemplateStore:   runTemplateStore,
 		userStore:          userStore,
 		storage:            storage,
+		recorder:           recorder,
+		analytics:          analyticsRecorder,
+		notifier:           notifier,
+		mediaProcessor:     mediaProcessor,
+		quotas:             quotas,
+		labelStore:         labelStore,
+		narrator:           narrator,
+		narrationTimeout:   narrationTimeout,
+		meter:              meter,
+		resultPusher:       resultPusher,
+		activity:           activityRecorder,
 		logger:             log,
 	}
 }
 
-// checkTestRunOwnership verifies that the authenticated user owns the project
-// associated with the given test run. Returns false if the check fails (response
-// already written).
+// checkTestRunOwnership verifies that the caller can access the project
+// associated with the given test run, for runs not named by the URL's
+// run_id, which RunAuthorizationMiddleware checks. Returns false if the
+// check fails (response already written).
 func (h *TestRunHandler) checkTestRunOwnership(w http.ResponseWriter, r *http.Request, runID uuid.UUID) bool {
-	userID, ok := GetUserID(r.Context())
-	if !ok {
-		respondError(w, http.StatusUnauthorized, "user not authenticated")
-		return false
-	}
+	_, ok := checkRunOwner(w, r, h.owners, runID)
+	return ok
+}
 
-	tr, err := h.testRunStore.GetByID(r.Context(), runID)
+// checkRunQuota verifies that storing incomingBytes more for a test run keeps
+// its project within the storage quota. Returns false if the check fails
+// (response already written).
+func (h *TestRunHandler) checkRunQuota(w http.ResponseWriter, r *http.Request, runID uuid.UUID, incomingBytes int64) bool {
+	owner, err := h.owners.RunOwner(r.Context(), runID)
 	if err != nil {
-		if errors.Is(err, testrun.ErrTestRunNotFound) {
-			respondError(w, http.StatusNotFound, "test run not found")
-			return false
-		}
-		respondError(w, http.StatusInternalServerError, "failed to verify test run")
+		respondError(w, http.StatusInternalServerError, "failed to resolve test run project")
 		return false
 	}
+	return checkStorageQuota(w, r, h.quotas, owner.ProjectID, incomingBytes, h.logger)
+}
 
-	tp, err := h.testProcedureStore.GetByID(r.Context(), tr.TestProcedureID)
-	if err != nil {
-		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
-			respondError(w, http.StatusNotFound, "test procedure not found")
-			return false
-		}
-		respondError(w, http.StatusInternalServerError, "failed to verify test procedure")
-		return false
+// runProcedure returns the procedure a test run executes. Started runs use the
+// snapshot taken at start time; runs that have not started yet fall back to the
+// live procedure version, with its step groups expanded and the run's
+// parameter values filled in.
+func (h *TestRunHandler) runProcedure(ctx context.Context, tr *testrun.TestRun) (*testprocedure.TestProcedure, error) {
+	if tr.ProcedureSnapshot != nil {
+		return tr.ProcedureSnapshot.Procedure(), nil
 	}
-
-	proj, err := h.projectStore.GetByID(r.Context(), tp.ProjectID)
+	tp, err := h.testProcedureStore.GetByID(ctx, tr.TestProcedureID)
 	if err != nil {
-		if errors.Is(err, project.ErrProjectNotFound) {
-			respondError(w, http.StatusNotFound, "project not found")
-			return false
-		}
-		respondError(w, http.StatusInternalServerError, "failed to verify project")
-		return false
+		return nil, err
 	}
-
-	if proj.OwnerID != userID {
-		respondError(w, http.StatusForbidden, "access denied")
-		return false
+	tp, err = steplibrary.Expand(ctx, h.stepGroupStore, tp)
+	if err != nil {
+		return nil, err
 	}
-
-	return true
+	return tp.WithParameters(tr.Parameters), nil
 }
 
 // testRunWithVersion wraps a TestRun with the resolved procedure version number.
@@ -111,12 +156,182 @@ type testRunWithVersion struct {
 type UpdateTestRunRequest struct {
 	Notes      *string `json:"notes,omitempty"`
 	AssignedTo *string `json:"assigned_to,omitempty"`
+	// DueAt is an RFC 3339 timestamp; an empty string clears the due date.
+	DueAt *string `json:"due_at,omitempty"`
+}
+
+// StartTestRunRequest represents a test run start request. Runs of
+// procedures with preconditions only start once the caller acknowledges
+// they are in place.
+type StartTestRunRequest struct {
+	AcknowledgePreconditions bool `json:"acknowledge_preconditions"`
+}
+
+// PreconditionsRequiredResponse is the 409 response to starting a run
+// without acknowledging its procedure's preconditions or its checklist.
+type PreconditionsRequiredResponse struct {
+	Error         string                      `json:"error"`
+	Preconditions testprocedure.Preconditions `json:"preconditions"`
+	Checklist     testrun.Checklist           `json:"checklist,omitempty"`
+}
+
+// ConcurrentRunResponse is the 409 response to creating a run while another
+// run of a procedure that prevents concurrent runs is running in the same
+// environment.
+type ConcurrentRunResponse struct {
+	Error string    `json:"error"`
+	RunID uuid.UUID `json:"run_id"`
 }
 
 // CompleteTestRunRequest represents a test run completion request.
 type CompleteTestRunRequest struct {
 	Status testrun.Status `json:"status"`
 	Notes  string         `json:"notes"`
+	// FailedStepIndex optionally records the step a failed run failed at.
+	FailedStepIndex *int `json:"failed_step_index,omitempty"`
+}
+
+// CreateTestRunRequest represents a test run creation request. It selects
+// the endpoint the run executes against and gives the values of the
+// procedure's parameters and of its run template's environment fields.
+// Runs are created unassigned unless AssignedTo is given.
+type CreateTestRunRequest struct {
+	EndpointTarget
+	Parameters         map[string]string `json:"parameters,omitempty"`
+	EnvironmentDetails map[string]string `json:"environment_details,omitempty"`
+	AssignedTo         *uuid.UUID        `json:"assigned_to,omitempty"`
+	DueAt              *time.Time        `json:"due_at,omitempty"`
+}
+
+// checkAssignee verifies the user a run is assigned to exists. Returns
+// false if not (response already written).
+func (h *TestRunHandler) checkAssignee(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	if _, err := h.userStore.GetByID(r.Context(), userID); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(w, http.StatusBadRequest, "assigned user not found")
+			return false
+		}
+		h.logger.Error(r.Context(), "failed to verify assigned user", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to verify assigned user")
+		return false
+	}
+	return true
+}
+
+// runTemplate returns the run template of the procedure whose first version
+// is rootID, or an empty template if it has none. Returns false if it
+// cannot be loaded (response already written).
+func (h *TestRunHandler) runTemplate(w http.ResponseWriter, r *http.Request, rootID uuid.UUID) (*testrun.RunTemplate, bool) {
+	tpl, err := h.runTemplateStore.Get(r.Context(), rootID)
+	if err != nil {
+		if errors.Is(err, testrun.ErrRunTemplateNotFound) {
+			return &testrun.RunTemplate{TestProcedureID: rootID}, true
+		}
+		h.logger.Error(r.Context(), "failed to get run template", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": rootID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get run template")
+		return nil, false
+	}
+	return tpl, true
+}
+
+// checkConcurrentRun refuses new runs in the environment if the template
+// prevents concurrent runs and another run of the procedure whose first
+// version is rootID is running, paused or blocked in it. Returns false if
+// the runs are refused (response already written).
+func (h *TestRunHandler) checkConcurrentRun(w http.ResponseWriter, r *http.Request, tpl *testrun.RunTemplate, rootID uuid.UUID, environment string) bool {
+	if !tpl.PreventConcurrentRuns {
+		return true
+	}
+
+	versions, err := h.testProcedureStore.GetVersionHistory(r.Context(), rootID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to get procedure versions", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": rootID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to check for running test runs")
+		return false
+	}
+	versionIDs := make([]uuid.UUID, 0, len(versions))
+	for _, v := range versions {
+		versionIDs = append(versionIDs, v.ID)
+	}
+
+	running, err := h.testRunStore.GetRunning(r.Context(), versionIDs, environment)
+	if err != nil {
+		if errors.Is(err, testrun.ErrTestRunNotFound) {
+			return true
+		}
+		h.logger.Error(r.Context(), "failed to check for running test runs", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": rootID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to check for running test runs")
+		return false
+	}
+
+	respondConcurrentRun(w, &testrun.ConcurrentRunError{Run: running})
+	return false
+}
+
+// changeExclusively makes change, which creates tr or may leave it running,
+// through the test run store. If the run template of tr's procedure
+// prevents concurrent runs, the change is only made if no other run of the
+// procedure is running, paused or blocked in tr's environment, in one
+// transaction with that check; a *testrun.ConcurrentRunError is returned
+// otherwise.
+func (h *TestRunHandler) changeExclusively(ctx context.Context, tr *testrun.TestRun, change func(testrun.Store) error) error {
+	versions, err := h.testProcedureStore.GetVersionHistory(ctx, tr.TestProcedureID)
+	if err != nil {
+		return err
+	}
+	rootID := tr.TestProcedureID
+	versionIDs := make([]uuid.UUID, 0, len(versions))
+	for _, v := range versions {
+		versionIDs = append(versionIDs, v.ID)
+		if v.ParentID == nil {
+			rootID = v.ID
+		}
+	}
+
+	tpl, err := h.runTemplateStore.Get(ctx, rootID)
+	if errors.Is(err, testrun.ErrRunTemplateNotFound) || (err == nil && !tpl.PreventConcurrentRuns) {
+		return change(h.testRunStore)
+	}
+	if err != nil {
+		return err
+	}
+	return h.testRunStore.Exclusive(ctx, rootID, versionIDs, tr.Environment, tr.ID, change)
+}
+
+// changeRunExclusively makes change to the run with the given ID through
+// changeExclusively.
+func (h *TestRunHandler) changeRunExclusively(ctx context.Context, id uuid.UUID, change func(testrun.Store) error) error {
+	tr, err := h.testRunStore.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return h.changeExclusively(ctx, tr, change)
+}
+
+// respondConcurrentRun responds 409 with the other run if err is a
+// *testrun.ConcurrentRunError. Returns true if it responded.
+func respondConcurrentRun(w http.ResponseWriter, err error) bool {
+	var concurrent *testrun.ConcurrentRunError
+	if !errors.As(err, &concurrent) {
+		return false
+	}
+	respondJSON(w, http.StatusConflict, ConcurrentRunResponse{
+		Error: concurrent.Error(),
+		RunID: concurrent.Run.ID,
+	})
+	return true
 }
 
 // Create handles creating a new test run.
@@ -148,6 +363,10 @@ func (h *TestRunHandler) Create(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
 		return
 	}
+	if latestProc.IsArchived() {
+		respondError(w, http.StatusConflict, "test procedure is archived")
+		return
+	}
 
 	// Create test run against the resolved latest committed version.
 	tr := &testrun.TestRun{
@@ -156,7 +375,55 @@ func (h *TestRunHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Status:          testrun.StatusPending,
 	}
 
-	if err := h.testRunStore.Create(r.Context(), tr); err != nil {
+	// The body is optional; it selects the endpoint the run executes against
+	// and gives parameter values.
+	var req CreateTestRunRequest
+	if r.ContentLength != 0 {
+		if err := parseJSON(r, &req, h.logger); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	if !req.EndpointTarget.IsZero() {
+		ep, ok := resolveEndpointTarget(w, r, h.endpointStore, h.logger, userID, req.EndpointTarget)
+		if !ok {
+			return
+		}
+		tr.EndpointID = &ep.ID
+		tr.Environment = ep.Environment
+		tr.BaseURL = ep.URL
+	}
+	if tr.Parameters, ok = resolveParameters(w, latestProc, req.Parameters, tr.BaseURL); !ok {
+		return
+	}
+	if req.AssignedTo != nil {
+		if !h.checkAssignee(w, r, *req.AssignedTo) {
+			return
+		}
+		tr.AssignedTo = req.AssignedTo
+	}
+	tr.DueAt = req.DueAt
+
+	rootID := latestProc.ID
+	if latestProc.ParentID != nil {
+		rootID = *latestProc.ParentID
+	}
+	tpl, ok := h.runTemplate(w, r, rootID)
+	if !ok {
+		return
+	}
+	if err := tpl.Apply(tr, req.EnvironmentDetails); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.changeExclusively(r.Context(), tr, func(store testrun.Store) error {
+		return store.Create(r.Context(), tr)
+	})
+	if err != nil {
+		if respondConcurrentRun(w, err) {
+			return
+		}
 		h.logger.Error(r.Context(), "failed to create test run", map[string]interface{}{
 			"error":             err.Error(),
 			"test_procedure_id": latestProc.ID,
@@ -208,8 +475,66 @@ func (h *TestRunHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Narrow the list to runs tagged with ?release_id= and carrying every
+	// ?label= selector.
+	var filter testrun.ListFilter
+	if releaseIDStr := r.URL.Query().Get("release_id"); releaseIDStr != "" {
+		releaseID, err := uuid.Parse(releaseIDStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid release_id")
+			return
+		}
+		filter.ReleaseID = &releaseID
+	}
+	if !parseRunListFilter(w, r, &filter) {
+		return
+	}
+	if len(r.URL.Query()["label"]) > 0 {
+		owner, _ := GetRunOwner(r.Context())
+		if filter.RunIDs, ok = labelMatches(w, r, h.labelStore, h.logger, owner.ProjectID, label.ResourceRun); !ok {
+			return
+		}
+	}
+
+	withVersions := func(runs []*testrun.TestRun) []testRunWithVersion {
+		runsWithVersion := make([]testRunWithVersion, len(runs))
+		for i, run := range runs {
+			runsWithVersion[i] = testRunWithVersion{
+				TestRun:          *run,
+				ProcedureVersion: versionMap[run.TestProcedureID],
+			}
+		}
+		return runsWithVersion
+	}
+
+	// ?cursor= pages through long run histories without counting them.
+	if usesCursor(r) {
+		if filter.Sort != "" {
+			respondError(w, http.StatusBadRequest, "sort cannot be combined with cursor")
+			return
+		}
+		cursor, ok := parseCursor(w, r)
+		if !ok {
+			return
+		}
+		runs, err := h.testRunStore.ListByTestProceduresAfter(r.Context(), procedureIDs, filter, cursor, limit+1)
+		if err != nil {
+			h.logger.Error(r.Context(), "failed to list test runs", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": procedureID,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to list test runs")
+			return
+		}
+		runs, next := cursorPage(runs, limit, func(run *testrun.TestRun) (time.Time, uuid.UUID) {
+			return run.CreatedAt, run.ID
+		})
+		respondJSON(w, http.StatusOK, NewCursorResponse(withVersions(runs), limit, next))
+		return
+	}
+
 	// Get total count of test runs across all versions.
-	total, err := h.testRunStore.CountByTestProcedures(r.Context(), procedureIDs)
+	total, err := h.testRunStore.CountByTestProceduresFiltered(r.Context(), procedureIDs, filter)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to count test runs", map[string]interface{}{
 			"error":             err.Error(),
@@ -220,7 +545,7 @@ func (h *TestRunHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// List test runs across all versions.
-	runs, err := h.testRunStore.ListByTestProcedures(r.Context(), procedureIDs, limit, offset)
+	runs, err := h.testRunStore.ListByTestProceduresFiltered(r.Context(), procedureIDs, filter, limit, offset)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to list test runs", map[string]interface{}{
 			"error":             err.Error(),
@@ -230,14 +555,78 @@ func (h *TestRunHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	runsWithVersion := make([]testRunWithVersion, len(runs))
-	for i, run := range runs {
-		runsWithVersion[i] = testRunWithVersion{
-			TestRun:          *run,
-			ProcedureVersion: versionMap[run.TestProcedureID],
+	respondJSON(w, http.StatusOK, NewPaginatedResponse(withVersions(runs), total, limit, offset))
+}
+
+// parseRunListFilter adds the ?status=, ?executed_by=, ?group_id=, ?since=,
+// ?until= (RFC 3339), ?overdue=, ?has_failed_steps= and ?sort= of a run list
+// request to filter. Returns false if one is invalid (response already
+// written).
+func parseRunListFilter(w http.ResponseWriter, r *http.Request, filter *testrun.ListFilter) bool {
+	q := r.URL.Query()
+	if s := testrun.Status(q.Get("status")); s != "" {
+		if !s.IsValid() {
+			respondError(w, http.StatusBadRequest, testrun.ErrInvalidStatus.Error())
+			return false
+		}
+		filter.Status = s
+	}
+	if s := q.Get("executed_by"); s != "" {
+		executedBy, err := uuid.Parse(s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid executed_by")
+			return false
+		}
+		filter.ExecutedBy = &executedBy
+	}
+	if s := q.Get("group_id"); s != "" {
+		groupID, err := uuid.Parse(s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid group_id")
+			return false
+		}
+		filter.GroupID = &groupID
+	}
+	if s := q.Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "since must be an RFC 3339 time")
+			return false
+		}
+		filter.Since = &since
+	}
+	if s := q.Get("until"); s != "" {
+		until, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "until must be an RFC 3339 time")
+			return false
+		}
+		filter.Until = &until
+	}
+	if s := q.Get("overdue"); s != "" {
+		overdue, err := strconv.ParseBool(s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "overdue must be true or false")
+			return false
+		}
+		filter.Overdue = overdue
+	}
+	if s := q.Get("has_failed_steps"); s != "" {
+		hasFailedSteps, err := strconv.ParseBool(s)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "has_failed_steps must be true or false")
+			return false
+		}
+		filter.HasFailedSteps = hasFailedSteps
+	}
+	if s := testrun.ListSort(q.Get("sort")); s != "" {
+		if !s.IsValid() {
+			respondError(w, http.StatusBadRequest, testrun.ErrInvalidSort.Error())
+			return false
 		}
+		filter.Sort = s
 	}
-	respondJSON(w, http.StatusOK, NewPaginatedResponse(runsWithVersion, total, limit, offset))
+	return true
 }
 
 // GetByID handles getting a single test run by ID.
@@ -274,16 +663,16 @@ func (h *TestRunHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.checkTestRunOwnership(w, r, id) {
-		return
-	}
-
 	// Parse request body
 	var req UpdateTestRunRequest
 	if err := parseJSON(r, &req, h.logger); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if IsGuest(r.Context()) && (req.AssignedTo != nil || req.DueAt != nil) {
+		respondError(w, http.StatusForbidden, "guests can only change a run's notes")
+		return
+	}
 
 	// Build setters
 	var setters []testrun.UpdateSetter
@@ -302,24 +691,26 @@ func (h *TestRunHandler) Update(w http.ResponseWriter, r *http.Request) {
 				respondError(w, http.StatusBadRequest, "invalid assigned_to user ID")
 				return
 			}
-			// Validate user exists
-			_, err = h.userStore.GetByID(r.Context(), assignedToID)
-			if err != nil {
-				if errors.Is(err, user.ErrUserNotFound) {
-					respondError(w, http.StatusBadRequest, "assigned user not found")
-					return
-				}
-				h.logger.Error(r.Context(), "failed to verify assigned user", map[string]interface{}{
-					"error":   err.Error(),
-					"user_id": assignedToID,
-				})
-				respondError(w, http.StatusInternalServerError, "failed to verify assigned user")
+			if !h.checkAssignee(w, r, assignedToID) {
 				return
 			}
 			setters = append(setters, testrun.SetAssignedTo(assignedToID))
 		}
 	}
 
+	if req.DueAt != nil {
+		if *req.DueAt == "" {
+			setters = append(setters, testrun.ClearDueAt())
+		} else {
+			dueAt, err := time.Parse(time.RFC3339, *req.DueAt)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "due_at must be an RFC 3339 timestamp")
+				return
+			}
+			setters = append(setters, testrun.SetDueAt(dueAt))
+		}
+	}
+
 	if len(setters) == 0 {
 		respondError(w, http.StatusBadRequest, "no fields to update")
 		return
@@ -353,7 +744,9 @@ func (h *TestRunHandler) Update(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, updatedRun)
 }
 
-// Start handles starting a test run.
+// Start handles starting a test run. If the procedure has preconditions,
+// the request must acknowledge them, otherwise it gets 409 with the
+// preconditions to check.
 func (h *TestRunHandler) Start(w http.ResponseWriter, r *http.Request) {
 	// Extract test run ID from URL
 	id, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
@@ -361,16 +754,84 @@ func (h *TestRunHandler) Start(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var req StartTestRunRequest
+	if r.ContentLength != 0 {
+		if err := parseJSON(r, &req, h.logger); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	tr, err := h.testRunStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, testrun.ErrTestRunNotFound) {
+			respondError(w, http.StatusNotFound, "test run not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to get test run", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test run")
+		return
+	}
+
+	// Snapshot the procedure so later edits don't change what this run shows
+	proc, err := h.testProcedureStore.GetByID(r.Context(), tr.TestProcedureID)
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+			respondError(w, http.StatusNotFound, "test procedure not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to get test procedure", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": tr.TestProcedureID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return
+	}
+	// The snapshot keeps the steps its step groups have now
+	proc, ok = expandStepGroups(w, r, h.stepGroupStore, proc, h.logger)
+	if !ok {
+		return
+	}
+	proc = proc.WithParameters(tr.Parameters)
+
+	var acknowledgedBy *uuid.UUID
+	if req.AcknowledgePreconditions {
+		if userID, ok := GetUserID(r.Context()); ok {
+			acknowledgedBy = &userID
+		}
+	}
+
 	// Start test run
-	if err := h.testRunStore.Start(r.Context(), id); err != nil {
+	err = h.changeExclusively(r.Context(), tr, func(store testrun.Store) error {
+		return store.Start(r.Context(), id, testrun.NewProcedureSnapshot(proc), acknowledgedBy)
+	})
+	if err != nil {
+		if respondConcurrentRun(w, err) {
+			return
+		}
 		if errors.Is(err, testrun.ErrTestRunNotFound) {
 			respondError(w, http.StatusNotFound, "test run not found")
 			return
 		}
+		if errors.Is(err, testrun.ErrPreconditionsNotAcknowledged) || errors.Is(err, testrun.ErrChecklistNotCompleted) {
+			respondJSON(w, http.StatusConflict, PreconditionsRequiredResponse{
+				Error:         err.Error(),
+				Preconditions: proc.Preconditions,
+				Checklist:     tr.Checklist,
+			})
+			return
+		}
 		if errors.Is(err, testrun.ErrTestRunAlreadyStarted) {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if errors.Is(err, testrun.ErrInvalidTransition) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
 		h.logger.Error(r.Context(), "failed to start test run", map[string]interface{}{
 			"error":       err.Error(),
 			"test_run_id": id,
@@ -389,6 +850,7 @@ func (h *TestRunHandler) Start(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "failed to get started test run")
 		return
 	}
+	h.recordRunActivity(r, startedRun)
 
 	respondJSON(w, http.StatusOK, startedRun)
 }
@@ -408,6 +870,10 @@ func (h *TestRunHandler) Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.FailedStepIndex != nil && !h.validFailedStep(w, r, id, req) {
+		return
+	}
+
 	// Complete test run
 	if err := h.testRunStore.Complete(r.Context(), id, req.Status, req.Notes); err != nil {
 		if errors.Is(err, testrun.ErrTestRunNotFound) {
@@ -418,6 +884,10 @@ func (h *TestRunHandler) Complete(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if errors.Is(err, testrun.ErrInvalidTransition) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
 		h.logger.Error(r.Context(), "failed to complete test run", map[string]interface{}{
 			"error":       err.Error(),
 			"test_run_id": id,
@@ -426,6 +896,17 @@ func (h *TestRunHandler) Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.FailedStepIndex != nil {
+		if err := h.testRunStore.Update(r.Context(), id, testrun.SetFailedStepIndex(*req.FailedStepIndex)); err != nil {
+			h.logger.Error(r.Context(), "failed to record failed step", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": id,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to complete test run")
+			return
+		}
+	}
+
 	// Get the completed test run to return it
 	completedRun, err := h.testRunStore.GetByID(r.Context(), id)
 	if err != nil {
@@ -437,25 +918,145 @@ func (h *TestRunHandler) Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if completedRun.Status == testrun.StatusFailed {
+		h.notifyRunFailed(r.Context(), completedRun)
+	}
+	h.recordRunAnalytics(r.Context(), completedRun)
+	h.pushRunResult(r.Context(), completedRun)
+	h.recordRunActivity(r, completedRun)
+
 	respondJSON(w, http.StatusOK, completedRun)
 }
 
-// UploadAsset handles uploading an asset for a test run.
-func (h *TestRunHandler) UploadAsset(w http.ResponseWriter, r *http.Request) {
-	// Extract test run ID from URL
-	id, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
-	if !ok {
-		return
+// validFailedStep checks that a completion's failed step is a step of the
+// run's procedure and is only given for failed runs. Returns false if it is
+// not (response already written).
+func (h *TestRunHandler) validFailedStep(w http.ResponseWriter, r *http.Request, runID uuid.UUID, req CompleteTestRunRequest) bool {
+	if req.Status != testrun.StatusFailed {
+		respondError(w, http.StatusBadRequest, "failed_step_index is only allowed for failed runs")
+		return false
 	}
 
-	// Verify test run exists
-	_, err := h.testRunStore.GetByID(r.Context(), id)
+	tr, err := h.testRunStore.GetByID(r.Context(), runID)
 	if err != nil {
 		if errors.Is(err, testrun.ErrTestRunNotFound) {
 			respondError(w, http.StatusNotFound, "test run not found")
-			return
+			return false
 		}
-		respondError(w, http.StatusInternalServerError, "failed to verify test run")
+		respondError(w, http.StatusInternalServerError, "failed to get test run")
+		return false
+	}
+	proc, err := h.runProcedure(r.Context(), tr)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get test procedure")
+		return false
+	}
+
+	if *req.FailedStepIndex < 0 || *req.FailedStepIndex >= len(proc.Steps) {
+		respondError(w, http.StatusBadRequest, "invalid failed_step_index")
+		return false
+	}
+	return true
+}
+
+// recordRunAnalytics folds a completed run into the procedure's analytics.
+func (h *TestRunHandler) recordRunAnalytics(ctx context.Context, tr *testrun.TestRun) {
+	proc, err := h.runProcedure(ctx, tr)
+	if err != nil {
+		h.logger.Warn(ctx, "failed to resolve test procedure for run analytics", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": tr.ID,
+		})
+		return
+	}
+
+	h.analytics.RecordCompletion(ctx, tr, proc)
+}
+
+// recordRunActivity records the caller starting or, once it has a result,
+// completing a run.
+func (h *TestRunHandler) recordRunActivity(r *http.Request, tr *testrun.TestRun) {
+	userID, _ := GetUserID(r.Context())
+	owner, err := h.owners.RunOwner(r.Context(), tr.ID)
+	if err != nil {
+		h.logger.Warn(r.Context(), "failed to resolve test run project for activity", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": tr.ID,
+		})
+		return
+	}
+
+	procedureName := tr.TestProcedureID.String()
+	if tr.ProcedureSnapshot != nil {
+		procedureName = tr.ProcedureSnapshot.Name
+	}
+	if tr.CompletedAt != nil {
+		h.activity.Record(r.Context(), activity.RunCompletedEvent(userID, owner.ProjectID, tr.ID, procedureName, string(tr.Status)))
+		return
+	}
+	h.activity.Record(r.Context(), activity.RunStartedEvent(userID, owner.ProjectID, tr.ID, procedureName))
+}
+
+// pushRunResult pushes the result of a completed run to its test management
+// links in the background, so that completing a run does not wait for the
+// test management tool.
+func (h *TestRunHandler) pushRunResult(ctx context.Context, tr *testrun.TestRun) {
+	if h.resultPusher == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		owner, err := h.owners.RunOwner(ctx, tr.ID)
+		if err != nil {
+			h.logger.Warn(ctx, "failed to resolve test run project for result push", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": tr.ID,
+			})
+			return
+		}
+		h.resultPusher.PushCompleted(ctx, owner.ProjectID, tr)
+	}()
+}
+
+// notifyRunFailed notifies the project owner, the user who executed the run
+// and its assignee that the run failed.
+func (h *TestRunHandler) notifyRunFailed(ctx context.Context, tr *testrun.TestRun) {
+	recipients := []uuid.UUID{tr.ExecutedBy}
+	if tr.AssignedTo != nil {
+		recipients = append(recipients, *tr.AssignedTo)
+	}
+	if owner, err := h.owners.RunOwner(ctx, tr.ID); err == nil {
+		recipients = append(recipients, owner.UserID)
+	} else {
+		h.logger.Warn(ctx, "failed to resolve run owner for notification", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": tr.ID,
+		})
+	}
+
+	procedureName := tr.TestProcedureID.String()
+	if tr.ProcedureSnapshot != nil {
+		procedureName = tr.ProcedureSnapshot.Name
+	}
+	h.notifier.Notify(ctx, notification.RunFailedEvent(tr.ID, procedureName, tr.Notes, recipients...))
+}
+
+// UploadAsset handles uploading an asset for a test run.
+func (h *TestRunHandler) UploadAsset(w http.ResponseWriter, r *http.Request) {
+	// Extract test run ID from URL
+	id, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return
+	}
+
+	// Verify test run exists
+	_, err := h.testRunStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, testrun.ErrTestRunNotFound) {
+			respondError(w, http.StatusNotFound, "test run not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to verify test run")
 		return
 	}
 
@@ -495,6 +1096,12 @@ func (h *TestRunHandler) UploadAsset(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Get optional step_note_id, which attaches the asset to a step note
+	stepNoteID, stepIndex, ok := h.resolveStepNote(w, r, id, r.FormValue("step_note_id"), stepIndex)
+	if !ok {
+		return
+	}
+
 	// Get file from form
 	file, header, err := r.FormFile("file")
 	if err != nil {
@@ -510,8 +1117,13 @@ func (h *TestRunHandler) UploadAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject uploads that would take the project over its storage quota
+	if !h.checkRunQuota(w, r, id, header.Size) {
+		return
+	}
+
 	// Generate storage path
-	storagePath := fmt.Sprintf("test-runs/%d/%s/%s", id, assetType, filename)
+	storagePath := assetStoragePath(id, assetType, filename)
 
 	// Upload to storage
 	if err := h.storage.Upload(r.Context(), storagePath, file); err != nil {
@@ -536,20 +1148,78 @@ func (h *TestRunHandler) UploadAsset(w http.ResponseWriter, r *http.Request) {
 		MimeType:    header.Header.Get("Content-Type"),
 		Description: description,
 		StepIndex:   stepIndex,
+		StepNoteID:  stepNoteID,
 		UploadedAt:  time.Now(),
 	}
+	if !h.createAsset(w, r, asset) {
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, asset)
+}
+
+// assetStoragePath returns the blob storage path of an uploaded asset.
+func assetStoragePath(runID uuid.UUID, assetType testrun.AssetType, filename string) string {
+	return fmt.Sprintf("test-runs/%d/%s/%s", runID, assetType, filename)
+}
+
+// resolveStepNote validates the step_note_id an asset is attached to. It
+// returns the note's ID and step index, or nil pointers and the given step
+// index if stepNoteIDStr is empty. It writes the error response itself.
+func (h *TestRunHandler) resolveStepNote(w http.ResponseWriter, r *http.Request, runID uuid.UUID, stepNoteIDStr string, stepIndex *int) (*uuid.UUID, *int, bool) {
+	if stepNoteIDStr == "" {
+		return nil, stepIndex, true
+	}
+
+	noteID, err := uuid.Parse(stepNoteIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid step_note_id")
+		return nil, nil, false
+	}
+	note, err := h.stepNoteStore.GetByID(r.Context(), noteID)
+	if err != nil && !errors.Is(err, testrun.ErrStepNoteNotFound) {
+		h.logger.Error(r.Context(), "failed to get step note", map[string]interface{}{
+			"error":        err.Error(),
+			"step_note_id": noteID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to verify step note")
+		return nil, nil, false
+	}
+	if err != nil || note.TestRunID != runID {
+		respondError(w, http.StatusBadRequest, "step note not found in this test run")
+		return nil, nil, false
+	}
+	if stepIndex != nil && *stepIndex != note.StepIndex {
+		respondError(w, http.StatusBadRequest, "step_index does not match the step note")
+		return nil, nil, false
+	}
+	return &note.ID, &note.StepIndex, true
+}
+
+// createAsset records an asset whose file has been stored at its AssetPath,
+// meters its storage and queues videos for processing. The file is removed
+// if the asset cannot be recorded. It writes the error response itself.
+func (h *TestRunHandler) createAsset(w http.ResponseWriter, r *http.Request, asset *testrun.TestRunAsset) bool {
+	// Thumbnails and transcodes of videos are generated in the background
+	if asset.AssetType == testrun.AssetTypeVideo && h.mediaProcessor != nil {
+		asset.ProcessingStatus = testrun.ProcessingStatusPending
+	}
 
 	if err := h.assetStore.Create(r.Context(), asset); err != nil {
 		// Clean up uploaded file on database error
-		h.storage.Delete(r.Context(), storagePath)
+		h.storage.Delete(r.Context(), asset.AssetPath)
 		h.logger.Error(r.Context(), "failed to create asset record", map[string]interface{}{
 			"error": err.Error(),
 		})
 		respondError(w, http.StatusInternalServerError, "failed to create asset record")
-		return
+		return false
 	}
 
-	respondJSON(w, http.StatusCreated, asset)
+	h.recordAssetStorage(r.Context(), asset.TestRunID, asset.FileSize)
+	if asset.ProcessingStatus == testrun.ProcessingStatusPending {
+		h.mediaProcessor.Enqueue(r.Context(), asset.ID)
+	}
+	return true
 }
 
 // ListAssets handles listing assets for a test run.
@@ -571,32 +1241,52 @@ func (h *TestRunHandler) ListAssets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusOK, assets)
+	// Derived artifacts are listed under the asset they were derived from
+	respondJSON(w, http.StatusOK, testrun.NestDerivatives(assets))
 }
 
 // DownloadAsset handles downloading an asset.
 func (h *TestRunHandler) DownloadAsset(w http.ResponseWriter, r *http.Request) {
-	// Extract asset ID from URL
-	assetID, ok := parseUUIDOrRespond(w, r, "asset_id", "asset")
+	asset, ok := h.runAsset(w, r)
 	if !ok {
 		return
 	}
 
-	// Get asset
+	h.respondAsset(w, r, asset)
+}
+
+// runAsset loads the asset in the URL, which must belong to the run in the
+// URL: assets of other runs are not found, so they cannot be reached
+// through a run the caller can access. Returns false if it cannot be
+// loaded (response already written).
+func (h *TestRunHandler) runAsset(w http.ResponseWriter, r *http.Request) (*testrun.TestRunAsset, bool) {
+	runID, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
+		return nil, false
+	}
+	assetID, ok := parseUUIDOrRespond(w, r, "asset_id", "asset")
+	if !ok {
+		return nil, false
+	}
+
 	asset, err := h.assetStore.GetByID(r.Context(), assetID)
-	if err != nil {
-		if errors.Is(err, testrun.ErrAssetNotFound) {
-			respondError(w, http.StatusNotFound, "asset not found")
-			return
-		}
+	if err != nil && !errors.Is(err, testrun.ErrAssetNotFound) {
 		h.logger.Error(r.Context(), "failed to get asset", map[string]interface{}{
 			"error":    err.Error(),
 			"asset_id": assetID,
 		})
 		respondError(w, http.StatusInternalServerError, "failed to get asset")
-		return
+		return nil, false
 	}
+	if err != nil || asset.TestRunID != runID {
+		respondError(w, http.StatusNotFound, "asset not found")
+		return nil, false
+	}
+	return asset, true
+}
 
+// respondAsset streams an asset's file from storage.
+func (h *TestRunHandler) respondAsset(w http.ResponseWriter, r *http.Request, asset *testrun.TestRunAsset) {
 	// Download from storage
 	reader, err := h.storage.Download(r.Context(), asset.AssetPath)
 	if err != nil {
@@ -628,24 +1318,20 @@ func (h *TestRunHandler) DownloadAsset(w http.ResponseWriter, r *http.Request) {
 
 // DeleteAsset handles deleting an asset.
 func (h *TestRunHandler) DeleteAsset(w http.ResponseWriter, r *http.Request) {
-	// Extract asset ID from URL
-	assetID, ok := parseUUIDOrRespond(w, r, "asset_id", "asset")
+	// Get asset to get storage path
+	asset, ok := h.runAsset(w, r)
 	if !ok {
 		return
 	}
+	assetID := asset.ID
 
-	// Get asset to get storage path
-	asset, err := h.assetStore.GetByID(r.Context(), assetID)
+	derived, err := h.assetStore.ListDerived(r.Context(), assetID)
 	if err != nil {
-		if errors.Is(err, testrun.ErrAssetNotFound) {
-			respondError(w, http.StatusNotFound, "asset not found")
-			return
-		}
-		h.logger.Error(r.Context(), "failed to get asset", map[string]interface{}{
+		h.logger.Error(r.Context(), "failed to list derived assets", map[string]interface{}{
 			"error":    err.Error(),
 			"asset_id": assetID,
 		})
-		respondError(w, http.StatusInternalServerError, "failed to get asset")
+		respondError(w, http.StatusInternalServerError, "failed to delete asset")
 		return
 	}
 
@@ -667,10 +1353,71 @@ func (h *TestRunHandler) DeleteAsset(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	if err := h.annotationStore.Replace(r.Context(), assetID, nil); err != nil {
+		h.logger.Warn(r.Context(), "failed to delete asset annotations", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": assetID,
+		})
+	}
+
+	// Derived artifacts go with the asset they were derived from
+	for _, d := range derived {
+		if err := h.assetStore.Delete(r.Context(), d.ID); err != nil && !errors.Is(err, testrun.ErrAssetNotFound) {
+			h.logger.Warn(r.Context(), "failed to delete derived asset record", map[string]interface{}{
+				"error":    err.Error(),
+				"asset_id": d.ID,
+			})
+			continue
+		}
+		if err := h.storage.Delete(r.Context(), d.AssetPath); err != nil {
+			h.logger.Warn(r.Context(), "failed to delete file from storage", map[string]interface{}{
+				"error": err.Error(),
+				"path":  d.AssetPath,
+			})
+		}
+	}
+
+	// Derived artifacts are not metered, only uploads
+	if !asset.IsDerived() {
+		h.recordAssetStorage(r.Context(), asset.TestRunID, -asset.FileSize)
+	}
+
 	respondSuccess(w, "asset deleted successfully")
 }
 
-// GenerateGuide creates a ZIP archive containing a guide.md and all run assets.
+// recordAssetStorage meters a change in the bytes stored for a test run's
+// assets against the project the run belongs to.
+func (h *TestRunHandler) recordAssetStorage(ctx context.Context, runID uuid.UUID, deltaBytes int64) {
+	userID, _ := GetUserID(ctx)
+
+	tr, err := h.testRunStore.GetByID(ctx, runID)
+	if err != nil {
+		h.logger.Warn(ctx, "failed to resolve test run for usage metering", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": runID,
+		})
+		return
+	}
+	tp, err := h.runProcedure(ctx, tr)
+	if err != nil {
+		h.logger.Warn(ctx, "failed to resolve test procedure for usage metering", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": runID,
+		})
+		return
+	}
+
+	h.recorder.RecordStorage(ctx, tp.ProjectID, userID, deltaBytes)
+}
+
+// GenerateGuide creates a ZIP archive containing a guide.md, the same guide
+// as guide.html, and all run assets.
+// With ?narrate=true the run's notes are rewritten into documentation prose
+// by the model before the guide is built, in the ?tone= given
+// (professional, friendly or concise). With ?language= the guide's text is
+// translated by the model, such as into Japanese or German, while asset
+// references are kept. Both cost model tokens, so they are off unless asked
+// for.
 func (h *TestRunHandler) GenerateGuide(w http.ResponseWriter, r *http.Request) {
 	// Extract test run ID from URL
 	id, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
@@ -678,6 +1425,39 @@ func (h *TestRunHandler) GenerateGuide(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	narrate := false
+	if v := r.URL.Query().Get("narrate"); v != "" {
+		var err error
+		if narrate, err = strconv.ParseBool(v); err != nil {
+			respondError(w, http.StatusBadRequest, "narrate must be true or false")
+			return
+		}
+	}
+	tone, err := narration.ParseTone(r.URL.Query().Get("tone"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	language, err := narration.ParseLanguage(r.URL.Query().Get("language"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.respondGuide(w, r, id, guideOptions{narrate: narrate, tone: tone, language: language})
+}
+
+// guideOptions are how the model rewrites the text of a guide. The zero
+// value builds the guide from the run's notes as they are.
+type guideOptions struct {
+	narrate  bool
+	tone     narration.Tone
+	language string
+}
+
+// respondGuide streams the guide of a run as a ZIP archive of guide.md,
+// guide.html, the run's assets and its procedure's step attachments.
+func (h *TestRunHandler) respondGuide(w http.ResponseWriter, r *http.Request, id uuid.UUID, opts guideOptions) {
 	ctx := r.Context()
 
 	// Fetch test run
@@ -696,7 +1476,7 @@ func (h *TestRunHandler) GenerateGuide(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch test procedure
-	proc, err := h.testProcedureStore.GetByID(ctx, tr.TestProcedureID)
+	proc, err := h.runProcedure(ctx, tr)
 	if err != nil {
 		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
 			respondError(w, http.StatusNotFound, "test procedure not found")
@@ -720,31 +1500,110 @@ func (h *TestRunHandler) GenerateGuide(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "failed to list assets")
 		return
 	}
+	// The guide only includes uploaded assets, not thumbnails and transcodes
+	assets = testrun.NestDerivatives(assets)
+
+	// Fetch annotations to burn into the guide's images
+	assetIDs := make([]uuid.UUID, len(assets))
+	for i, asset := range assets {
+		assetIDs[i] = asset.ID
+	}
+	annotations, err := h.annotationStore.ListByAssets(ctx, assetIDs)
+	if err != nil {
+		h.logger.Error(ctx, "failed to list annotations", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list annotations")
+		return
+	}
+
+	// The guide's text: the run's notes and each asset's description
+	notes := narration.Request{
+		ProcedureName:        proc.Name,
+		ProcedureDescription: proc.Description,
+		Overview:             tr.Notes,
+		Steps:                make([]string, len(assets)),
+		Rewrite:              opts.narrate,
+		Tone:                 opts.tone,
+		Language:             opts.language,
+	}
+	// The description also lists the preconditions the run was started with
+	if len(proc.Preconditions) > 0 {
+		notes.ProcedureDescription = strings.TrimSpace(proc.Description + "\n\nPreconditions:\n\n" + preconditionList(proc.Preconditions))
+	}
+	// The first asset of each step also carries the step's expected result
+	described := make(map[int]bool)
+	for i, asset := range assets {
+		notes.Steps[i] = asset.Description
+		if asset.StepIndex == nil || described[*asset.StepIndex] || *asset.StepIndex < 0 || *asset.StepIndex >= len(proc.Steps) {
+			continue
+		}
+		described[*asset.StepIndex] = true
+		if expected := proc.Steps[*asset.StepIndex].ExpectedResult; expected != "" {
+			notes.Steps[i] = strings.TrimSpace(asset.Description + "\n\nExpected result: " + expected)
+		}
+	}
+	text := narration.Unchanged(notes)
+	if opts.narrate || opts.language != "" {
+		narrated, ok := h.narrateGuide(w, r, id, proc.ProjectID, notes)
+		if !ok {
+			return
+		}
+		text = narrated
+	}
 
-	// Build guide.md content
+	// Build guide.md content, and guide.html from the same text
+	page := guidePage{
+		Title:              text.Name,
+		Description:        exportHTML(text.Description, 1),
+		OverviewHeading:    text.Headings.Overview,
+		Overview:           exportHTML(text.Overview, 2),
+		AttachmentsHeading: text.Headings.Attachments,
+	}
 	var md strings.Builder
-	fmt.Fprintf(&md, "# %s\n\n", proc.Name)
-	if proc.Description != "" {
-		fmt.Fprintf(&md, "%s\n\n", proc.Description)
+	fmt.Fprintf(&md, "# %s\n\n", text.Name)
+	if text.Description != "" {
+		fmt.Fprintf(&md, "%s\n\n", exportMarkdown(text.Description, 1))
+	}
+	fmt.Fprintf(&md, "## %s\n\n", text.Headings.Overview)
+	if text.Overview != "" {
+		fmt.Fprintf(&md, "%s\n\n", exportMarkdown(text.Overview, 2))
+	}
+	// The procedure's step attachments are listed before the assets
+	attachments := false
+	for i, step := range proc.Steps {
+		for j, attachment := range step.Attachments {
+			if !attachments {
+				fmt.Fprintf(&md, "## %s\n\n", text.Headings.Attachments)
+				attachments = true
+			}
+			fmt.Fprintf(&md, "- %s\n", attachmentMarkdown(i, j, attachment))
+			page.Attachments = append(page.Attachments, newGuideMedia(attachment.FileName, "./attachments/"+attachmentEntry(i, j, attachment), recordingContentType(attachment)))
+		}
 	}
-	fmt.Fprintf(&md, "## Overview\n\n")
-	if tr.Notes != "" {
-		fmt.Fprintf(&md, "%s\n\n", tr.Notes)
+	if attachments {
+		fmt.Fprintf(&md, "\n")
 	}
 	fmt.Fprintf(&md, "---\n\n")
 
 	for i, asset := range assets {
 		assetEntry := fmt.Sprintf("%s_%s", asset.ID.String(), asset.FileName)
-		fmt.Fprintf(&md, "## Step %d\n\n", i+1)
+		fmt.Fprintf(&md, "## %s %d\n\n", text.Headings.Step, i+1)
 		if asset.AssetType == testrun.AssetTypeImage {
-			fmt.Fprintf(&md, "![Step %d](./assets/%s)\n\n", i+1, assetEntry)
+			fmt.Fprintf(&md, "![%s %d](./assets/%s)\n\n", text.Headings.Step, i+1, assetEntry)
 		} else {
 			fmt.Fprintf(&md, "[%s](./assets/%s)\n\n", asset.FileName, assetEntry)
 		}
-		if asset.Description != "" {
-			fmt.Fprintf(&md, "%s\n\n", asset.Description)
+		if text.Steps[i] != "" {
+			fmt.Fprintf(&md, "%s\n\n", exportMarkdown(text.Steps[i], 2))
 		}
 		fmt.Fprintf(&md, "---\n\n")
+		page.Entries = append(page.Entries, guideEntry{
+			Heading: fmt.Sprintf("%s %d", text.Headings.Step, i+1),
+			Media:   newGuideMedia(asset.FileName, "./assets/"+assetEntry, asset.MimeType),
+			Text:    exportHTML(text.Steps[i], 2),
+		})
 	}
 
 	// Stream ZIP archive directly to the response writer
@@ -768,6 +1627,17 @@ func (h *TestRunHandler) GenerateGuide(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Write guide.html
+	htmlWriter, err := zw.Create("guide.html")
+	if err != nil {
+		h.logger.Error(ctx, "failed to create guide.html in zip", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if err := writeGuideHTML(htmlWriter, page); err != nil {
+		h.logger.Error(ctx, "failed to write guide.html", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
 	// Write each asset into assets/ folder
 	for _, asset := range assets {
 		reader, err := h.storage.Download(ctx, asset.AssetPath)
@@ -787,7 +1657,7 @@ func (h *TestRunHandler) GenerateGuide(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if _, err := io.Copy(assetWriter, reader); err != nil {
+		if err := h.writeGuideAsset(ctx, assetWriter, reader, asset, annotations[asset.ID]); err != nil {
 			reader.Close()
 			h.logger.Error(ctx, "failed to write asset to zip", map[string]interface{}{"error": err.Error()})
 			return
@@ -795,6 +1665,46 @@ func (h *TestRunHandler) GenerateGuide(w http.ResponseWriter, r *http.Request) {
 		reader.Close()
 	}
 
+	// Write the procedure's step attachments into attachments/ folder
+	if err := writeAttachments(ctx, zw, h.storage, proc.Steps, h.logger); err != nil {
+		h.logger.Error(ctx, "failed to write attachment to zip", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// narrateGuide rewrites or translates the text of a guide with the model.
+// Returns false if it cannot be rewritten (response already written).
+func (h *TestRunHandler) narrateGuide(w http.ResponseWriter, r *http.Request, runID, projectID uuid.UUID, notes narration.Request) (narration.Narration, bool) {
+	if !checkLLMBudget(w, r, h.meter, projectID, h.logger) {
+		return narration.Narration{}, false
+	}
+
+	// The model is slower than the server's write timeout allows for
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(h.narrationTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Warn(r.Context(), "failed to extend write deadline", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	userID, _ := GetUserID(r.Context())
+	ctx, tracker := llmusage.Track(r.Context())
+	narrated, err := h.narrator.Narrate(ctx, notes)
+	h.meter.Record(r.Context(), projectID, userID, llmusage.FeatureGuideNarration, tracker.Usage())
+	if err != nil {
+		switch {
+		case errors.Is(err, narration.ErrNoNarration):
+			respondError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, resilience.ErrCircuitOpen):
+			respondError(w, http.StatusServiceUnavailable, "guide narration temporarily unavailable")
+		default:
+			h.logger.Error(r.Context(), "failed to narrate guide", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": runID,
+			})
+			respondError(w, http.StatusBadGateway, "failed to narrate guide")
+		}
+		return narration.Narration{}, false
+	}
+	return narrated.Apply(notes), true
 }
 
 // SetStepNoteRequest represents the body for setting a step note.
@@ -802,14 +1712,233 @@ type SetStepNoteRequest struct {
 	Notes string `json:"notes"`
 }
 
-// GetRunProcedure handles getting the test procedure associated with a test run.
-func (h *TestRunHandler) GetRunProcedure(w http.ResponseWriter, r *http.Request) {
+// loadComparedRun loads a run with its procedure version, step notes and
+// assets for comparison.
+func (h *TestRunHandler) loadComparedRun(ctx context.Context, id uuid.UUID) (*testrun.ComparedRun, error) {
+	tr, err := h.testRunStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	proc, err := h.runProcedure(ctx, tr)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := h.stepNoteStore.ListByTestRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := h.assetStore.ListByTestRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &testrun.ComparedRun{Run: tr, Procedure: proc, StepNotes: notes, Assets: assets}, nil
+}
+
+// Compare handles GET /runs/compare?base={id}&target={id}. It compares two
+// runs of the same procedure step by step, for regression triage.
+func (h *TestRunHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	ids := make([]uuid.UUID, 2)
+	for i, param := range []string{"base", "target"} {
+		id, err := uuid.Parse(r.URL.Query().Get(param))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid "+param+" test run ID")
+			return
+		}
+		if !h.checkTestRunOwnership(w, r, id) {
+			return
+		}
+		ids[i] = id
+	}
+
+	runs := make([]*testrun.ComparedRun, 2)
+	for i, id := range ids {
+		run, err := h.loadComparedRun(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, testrun.ErrTestRunNotFound):
+				respondError(w, http.StatusNotFound, "test run not found")
+			case errors.Is(err, testprocedure.ErrTestProcedureNotFound):
+				respondError(w, http.StatusNotFound, "test procedure not found")
+			default:
+				h.logger.Error(r.Context(), "failed to load test run for comparison", map[string]interface{}{
+					"error":       err.Error(),
+					"test_run_id": id,
+				})
+				respondError(w, http.StatusInternalServerError, "failed to compare test runs")
+			}
+			return
+		}
+		runs[i] = run
+	}
+
+	comparison, err := testrun.Compare(runs[0], runs[1])
+	if err != nil {
+		if errors.Is(err, testrun.ErrDifferentProcedures) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to compare test runs")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, comparison)
+}
+
+// maxReportedRuns bounds the runs of one CI report.
+const maxReportedRuns = 100
+
+// Report handles GET /runs/report?run_id={id}&format=github-actions,
+// reporting the runs given by repeated run_id parameters to a CI system.
+// The github-actions format returns workflow commands that annotate failed
+// and unfinished runs and a Markdown job summary; the junit format returns
+// JUnit XML with a test suite per run.
+func (h *TestRunHandler) Report(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "github-actions"
+	}
+	if format != "github-actions" && format != "junit" {
+		respondError(w, http.StatusBadRequest, "format must be github-actions or junit")
+		return
+	}
+
+	params := r.URL.Query()["run_id"]
+	if len(params) == 0 || len(params) > maxReportedRuns {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("between 1 and %d run_id parameters are required", maxReportedRuns))
+		return
+	}
+
+	runs := make([]*testrun.ReportedRun, 0, len(params))
+	for _, param := range params {
+		id, err := uuid.Parse(param)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid run_id")
+			return
+		}
+		if !h.checkTestRunOwnership(w, r, id) {
+			return
+		}
+
+		run, ok := h.loadReportedRun(w, r, id)
+		if !ok {
+			return
+		}
+		runs = append(runs, run)
+	}
+
+	if format == "junit" {
+		h.respondJUnit(w, r, testrun.NewJUnitReport(runs), "runs-junit.xml")
+		return
+	}
+	respondJSON(w, http.StatusOK, testrun.NewGitHubActionsReport(runs))
+}
+
+// JUnit handles GET /procedures/{procedure_id}/runs/{run_id}/junit,
+// exporting a run of any version of the procedure as JUnit XML.
+func (h *TestRunHandler) JUnit(w http.ResponseWriter, r *http.Request) {
+	procedureID, ok := parseUUIDOrRespond(w, r, "procedure_id", "test procedure")
+	if !ok {
+		return
+	}
 	id, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
 	if !ok {
 		return
 	}
 
-	if !h.checkTestRunOwnership(w, r, id) {
+	run, ok := h.loadReportedRun(w, r, id)
+	if !ok {
+		return
+	}
+
+	// The run must belong to the procedure of the URL.
+	versions, err := h.testProcedureStore.GetVersionHistory(r.Context(), procedureID)
+	if err != nil {
+		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
+			respondError(w, http.StatusNotFound, "test procedure not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to get test procedure versions", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": procedureID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to export test run")
+		return
+	}
+	found := false
+	for _, version := range versions {
+		if version.ID == run.Run.TestProcedureID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "test run not found")
+		return
+	}
+
+	h.respondJUnit(w, r, testrun.NewJUnitReport([]*testrun.ReportedRun{run}), "run-"+id.String()+"-junit.xml")
+}
+
+// respondJUnit writes a JUnit report as an XML attachment.
+func (h *TestRunHandler) respondJUnit(w http.ResponseWriter, r *http.Request, report *testrun.JUnitTestSuites, fileName string) {
+	var buf bytes.Buffer
+	if err := report.WriteXML(&buf); err != nil {
+		h.logger.Error(r.Context(), "failed to write JUnit report", map[string]interface{}{
+			"error": err.Error(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to export test runs")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// loadReportedRun loads a run with the procedure version it executes and
+// its step notes. Returns false if loading fails (response already written).
+func (h *TestRunHandler) loadReportedRun(w http.ResponseWriter, r *http.Request, id uuid.UUID) (*testrun.ReportedRun, bool) {
+	run, err := h.reportedRun(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, testrun.ErrTestRunNotFound):
+			respondError(w, http.StatusNotFound, "test run not found")
+		case errors.Is(err, testprocedure.ErrTestProcedureNotFound):
+			respondError(w, http.StatusNotFound, "test procedure not found")
+		default:
+			h.logger.Error(r.Context(), "failed to load test run for report", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": id,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to report test runs")
+		}
+		return nil, false
+	}
+	return run, true
+}
+
+func (h *TestRunHandler) reportedRun(ctx context.Context, id uuid.UUID) (*testrun.ReportedRun, error) {
+	tr, err := h.testRunStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	proc, err := h.runProcedure(ctx, tr)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := h.stepNoteStore.ListByTestRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &testrun.ReportedRun{Run: tr, Procedure: proc, StepNotes: notes}, nil
+}
+
+// GetRunProcedure handles getting the test procedure associated with a test run.
+// Started runs return the procedure as it was when the run started.
+func (h *TestRunHandler) GetRunProcedure(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDOrRespond(w, r, "run_id", "test run")
+	if !ok {
 		return
 	}
 
@@ -827,7 +1956,7 @@ func (h *TestRunHandler) GetRunProcedure(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	proc, err := h.testProcedureStore.GetByID(r.Context(), tr.TestProcedureID)
+	proc, err := h.runProcedure(r.Context(), tr)
 	if err != nil {
 		if errors.Is(err, testprocedure.ErrTestProcedureNotFound) {
 			respondError(w, http.StatusNotFound, "test procedure not found")
@@ -851,10 +1980,6 @@ func (h *TestRunHandler) GetStepNotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.checkTestRunOwnership(w, r, id) {
-		return
-	}
-
 	notes, err := h.stepNoteStore.ListByTestRun(r.Context(), id)
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to list step notes", map[string]interface{}{
@@ -865,6 +1990,15 @@ func (h *TestRunHandler) GetStepNotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.loadStepNoteAttachments(r.Context(), id, notes...); err != nil {
+		h.logger.Error(r.Context(), "failed to list step note attachments", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list step notes")
+		return
+	}
+
 	respondJSON(w, http.StatusOK, notes)
 }
 
@@ -888,10 +2022,6 @@ func (h *TestRunHandler) SetStepNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.checkTestRunOwnership(w, r, id) {
-		return
-	}
-
 	note := &testrun.StepNote{
 		TestRunID: id,
 		StepIndex: stepIndex,
@@ -908,9 +2038,164 @@ func (h *TestRunHandler) SetStepNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.loadStepNoteAttachments(r.Context(), id, note); err != nil {
+		h.logger.Error(r.Context(), "failed to list step note attachments", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get step note attachments")
+		return
+	}
+
 	respondJSON(w, http.StatusOK, note)
 }
 
+// loadStepNoteAttachments sets the attachments of step notes on a test run
+// from the run's assets.
+func (h *TestRunHandler) loadStepNoteAttachments(ctx context.Context, runID uuid.UUID, notes ...*testrun.StepNote) error {
+	assets, err := h.assetStore.ListByTestRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	byNote := make(map[uuid.UUID][]*testrun.TestRunAsset)
+	for _, a := range testrun.NestDerivatives(assets) {
+		if a.StepNoteID != nil {
+			byNote[*a.StepNoteID] = append(byNote[*a.StepNoteID], a)
+		}
+	}
+	for _, note := range notes {
+		note.Attachments = byNote[note.ID]
+		if note.Attachments == nil {
+			note.Attachments = []*testrun.TestRunAsset{}
+		}
+	}
+	return nil
+}
+
+// writeGuideAsset copies an asset into a guide, burning its annotations into
+// it. Images that cannot be annotated are copied as they are.
+func (h *TestRunHandler) writeGuideAsset(ctx context.Context, w io.Writer, r io.Reader, asset *testrun.TestRunAsset, annotations []*testrun.AssetAnnotation) error {
+	if asset.AssetType != testrun.AssetTypeImage || len(annotations) == 0 {
+		_, err := io.Copy(w, r)
+		return err
+	}
+
+	original, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var annotated bytes.Buffer
+	if err := annotate.Burn(bytes.NewReader(original), &annotated, annotations); err != nil {
+		h.logger.Warn(ctx, "failed to annotate guide image", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": asset.ID,
+		})
+		_, err := w.Write(original)
+		return err
+	}
+	_, err = annotated.WriteTo(w)
+	return err
+}
+
+// GetAnnotations handles listing the annotations of an image asset.
+func (h *TestRunHandler) GetAnnotations(w http.ResponseWriter, r *http.Request) {
+	asset, ok := h.annotatableAsset(w, r)
+	if !ok {
+		return
+	}
+
+	annotations, err := h.annotationStore.ListByAsset(r.Context(), asset.ID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to list annotations", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": asset.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list annotations")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, annotations)
+}
+
+// SetAnnotationsRequest represents the request to replace the annotations of
+// an image asset.
+type SetAnnotationsRequest struct {
+	Annotations []*testrun.AssetAnnotation `json:"annotations"`
+}
+
+// SetAnnotations handles replacing the annotations of an image asset.
+func (h *TestRunHandler) SetAnnotations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req SetAnnotationsRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	asset, ok := h.annotatableAsset(w, r)
+	if !ok {
+		return
+	}
+
+	for _, a := range req.Annotations {
+		if a == nil {
+			respondError(w, http.StatusBadRequest, "invalid annotation")
+			return
+		}
+		a.CreatedBy = userID
+	}
+
+	if err := h.annotationStore.Replace(r.Context(), asset.ID, req.Annotations); err != nil {
+		switch {
+		case errors.Is(err, testrun.ErrInvalidAnnotationShape),
+			errors.Is(err, testrun.ErrInvalidAnnotation),
+			errors.Is(err, testrun.ErrInvalidAnnotationColor),
+			errors.Is(err, testrun.ErrTooManyAnnotations):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error(r.Context(), "failed to save annotations", map[string]interface{}{
+				"error":    err.Error(),
+				"asset_id": asset.ID,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to save annotations")
+		}
+		return
+	}
+
+	annotations, err := h.annotationStore.ListByAsset(r.Context(), asset.ID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to list annotations", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": asset.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list annotations")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, annotations)
+}
+
+// annotatableAsset returns the image asset addressed by the request.
+// Returns false if it is not one (response already written).
+func (h *TestRunHandler) annotatableAsset(w http.ResponseWriter, r *http.Request) (*testrun.TestRunAsset, bool) {
+	asset, ok := h.runAsset(w, r)
+	if !ok {
+		return nil, false
+	}
+	if asset.AssetType != testrun.AssetTypeImage {
+		respondError(w, http.StatusBadRequest, "only image assets can be annotated")
+		return nil, false
+	}
+
+	return asset, true
+}
+
 // sanitizeFilename removes potentially dangerous characters from filenames.
 func sanitizeFilename(filename string) string {
 	// Get base name to remove any directory paths
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/upload"
+)
+
+// errResponseWritten is returned from a completion callback that has already
+// written its error response.
+var errResponseWritten = errors.New("response already written")
+
+// UploadHandler handles resumable uploads. A file is sent as numbered parts
+// that can be retried independently and becomes a run asset or step image
+// once the upload is completed.
+type UploadHandler struct {
+	manager        *upload.Manager
+	testRuns       *TestRunHandler
+	testProcedures *TestProcedureHandler
+	logger         logger.Logger
+}
+
+// NewUploadHandler creates a new upload handler. Completed uploads are
+// stored through the test run and test procedure handlers.
+func NewUploadHandler(manager *upload.Manager, testRuns *TestRunHandler, testProcedures *TestProcedureHandler, log logger.Logger) *UploadHandler {
+	return &UploadHandler{
+		manager:        manager,
+		testRuns:       testRuns,
+		testProcedures: testProcedures,
+		logger:         log,
+	}
+}
+
+// CreateUploadRequest represents a resumable upload creation request.
+type CreateUploadRequest struct {
+	Target   upload.Target `json:"target"`
+	FileName string        `json:"file_name"`
+	FileSize int64         `json:"file_size"`
+	MimeType string        `json:"mime_type"`
+	// TestRunID, AssetType, Description, StepIndex and StepNoteID describe
+	// the asset a run_asset upload becomes, as in UploadAsset.
+	TestRunID   string `json:"test_run_id,omitempty"`
+	AssetType   string `json:"asset_type,omitempty"`
+	Description string `json:"description,omitempty"`
+	StepIndex   *int   `json:"step_index,omitempty"`
+	StepNoteID  string `json:"step_note_id,omitempty"`
+	// TestProcedureID is the procedure a step_image upload is for.
+	TestProcedureID string `json:"test_procedure_id,omitempty"`
+}
+
+// Create handles starting a resumable upload. The response carries the part
+// size and number of parts the client should send.
+func (h *UploadHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req CreateUploadRequest
+	if err := parseJSON(r, &req, h.logger); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	session := &upload.Session{
+		UserID:   userID,
+		Target:   req.Target,
+		FileName: req.FileName,
+		FileSize: req.FileSize,
+		MimeType: req.MimeType,
+	}
+
+	switch req.Target {
+	case upload.TargetRunAsset:
+		runID, err := uuid.Parse(req.TestRunID)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid test_run_id")
+			return
+		}
+		if !h.testRuns.checkTestRunOwnership(w, r, runID) {
+			return
+		}
+		if !h.testRuns.checkRunQuota(w, r, runID, req.FileSize) {
+			return
+		}
+		assetType := testrun.AssetType(req.AssetType)
+		if !assetType.IsValid() {
+			respondError(w, http.StatusBadRequest, "invalid asset_type")
+			return
+		}
+		stepNoteID, stepIndex, ok := h.testRuns.resolveStepNote(w, r, runID, req.StepNoteID, req.StepIndex)
+		if !ok {
+			return
+		}
+		session.FileName = sanitizeFilename(req.FileName)
+		if session.FileName == "" {
+			respondError(w, http.StatusBadRequest, "invalid filename")
+			return
+		}
+		session.TestRunID = &runID
+		session.AssetType = string(assetType)
+		session.Description = req.Description
+		session.StepIndex = stepIndex
+		session.StepNoteID = stepNoteID
+
+	case upload.TargetStepImage:
+		procedureID, err := uuid.Parse(req.TestProcedureID)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid test_procedure_id")
+			return
+		}
+		if !h.testProcedures.checkProcedureOwnership(w, r, procedureID) {
+			return
+		}
+		if req.FileSize > MaxStepImageSize {
+			respondError(w, http.StatusBadRequest, "step images must be at most 10MB")
+			return
+		}
+		if !h.testProcedures.checkProcedureQuota(w, r, procedureID, req.FileSize) {
+			return
+		}
+		session.TestProcedureID = &procedureID
+
+	default:
+		respondError(w, http.StatusBadRequest, upload.ErrInvalidTarget.Error())
+		return
+	}
+
+	if err := h.manager.Create(r.Context(), session); err != nil {
+		if errors.Is(err, upload.ErrInvalidSize) || errors.Is(err, upload.ErrInvalidFileName) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to create upload")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, session)
+}
+
+// loadSession loads the upload named by the upload_id URL parameter. Uploads
+// of other users are reported as not found. Returns false if the upload
+// cannot be loaded (response already written).
+func (h *UploadHandler) loadSession(w http.ResponseWriter, r *http.Request) (*upload.Session, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return nil, false
+	}
+
+	id, ok := parseUUIDOrRespond(w, r, "upload_id", "upload")
+	if !ok {
+		return nil, false
+	}
+
+	session, err := h.manager.Get(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, upload.ErrSessionNotFound):
+			respondError(w, http.StatusNotFound, "upload not found")
+		case errors.Is(err, upload.ErrSessionExpired):
+			respondError(w, http.StatusGone, "upload expired")
+		default:
+			respondError(w, http.StatusInternalServerError, "failed to get upload")
+		}
+		return nil, false
+	}
+
+	if session.UserID != userID {
+		respondError(w, http.StatusNotFound, "upload not found")
+		return nil, false
+	}
+
+	return session, true
+}
+
+// Get handles getting an upload, including the parts received so far. A
+// client resuming an interrupted upload sends the parts missing from
+// received_parts.
+func (h *UploadHandler) Get(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, session)
+}
+
+// PutPart handles uploading a part. The request body is the raw content of
+// the part.
+func (h *UploadHandler) PutPart(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	number, err := strconv.Atoi(mux.Vars(r)["part_number"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid part number")
+		return
+	}
+
+	if err := h.manager.PutPart(r.Context(), session, number, r.Body); err != nil {
+		switch {
+		case errors.Is(err, upload.ErrInvalidPart):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, upload.ErrSessionBusy):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error(r.Context(), "failed to store upload part", map[string]interface{}{
+				"error":     err.Error(),
+				"upload_id": session.ID.String(),
+				"part":      number,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to store part")
+		}
+		return
+	}
+
+	session, err = h.manager.Get(r.Context(), session.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get upload")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, session)
+}
+
+// Complete handles completing an upload. Run asset uploads respond with the
+// created asset and step image uploads with the image path, as their
+// single-request counterparts do.
+func (h *UploadHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	switch session.Target {
+	case upload.TargetRunAsset:
+		err = h.completeRunAsset(w, r, session)
+	case upload.TargetStepImage:
+		err = h.completeStepImage(w, r, session)
+	default:
+		respondError(w, http.StatusBadRequest, upload.ErrInvalidTarget.Error())
+		return
+	}
+	if err == nil {
+		return
+	}
+
+	switch {
+	case errors.Is(err, errResponseWritten):
+	case errors.Is(err, upload.ErrIncomplete):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, upload.ErrSessionBusy):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.Error(r.Context(), "failed to complete upload", map[string]interface{}{
+			"error":     err.Error(),
+			"upload_id": session.ID.String(),
+		})
+		respondError(w, http.StatusInternalServerError, "failed to complete upload")
+	}
+}
+
+// completeRunAsset assembles a run asset upload into its storage path and
+// records the asset.
+func (h *UploadHandler) completeRunAsset(w http.ResponseWriter, r *http.Request, session *upload.Session) error {
+	runID := *session.TestRunID
+	if !h.testRuns.checkTestRunOwnership(w, r, runID) {
+		return errResponseWritten
+	}
+
+	// The step note may have been deleted since the upload started
+	stepNoteIDStr := ""
+	if session.StepNoteID != nil {
+		stepNoteIDStr = session.StepNoteID.String()
+	}
+	stepNoteID, stepIndex, ok := h.testRuns.resolveStepNote(w, r, runID, stepNoteIDStr, session.StepIndex)
+	if !ok {
+		return errResponseWritten
+	}
+
+	// Other uploads may have used up the quota since the upload started
+	if !h.testRuns.checkRunQuota(w, r, runID, session.FileSize) {
+		return errResponseWritten
+	}
+
+	assetType := testrun.AssetType(session.AssetType)
+	asset := &testrun.TestRunAsset{
+		TestRunID:   runID,
+		AssetType:   assetType,
+		AssetPath:   assetStoragePath(runID, assetType, session.FileName),
+		FileName:    session.FileName,
+		FileSize:    session.FileSize,
+		MimeType:    session.MimeType,
+		Description: session.Description,
+		StepIndex:   stepIndex,
+		StepNoteID:  stepNoteID,
+	}
+
+	err := h.manager.Complete(r.Context(), session, func(file io.Reader) error {
+		if err := h.testRuns.storage.Upload(r.Context(), asset.AssetPath, file); err != nil {
+			return err
+		}
+		asset.UploadedAt = time.Now()
+		if !h.testRuns.createAsset(w, r, asset) {
+			return errResponseWritten
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	respondJSON(w, http.StatusCreated, asset)
+	return nil
+}
+
+// completeStepImage assembles a step image upload and stores it as
+// UploadStepImage does.
+func (h *UploadHandler) completeStepImage(w http.ResponseWriter, r *http.Request, session *upload.Session) error {
+	procedureID := *session.TestProcedureID
+	if !h.testProcedures.checkProcedureOwnership(w, r, procedureID) {
+		return errResponseWritten
+	}
+
+	var path string
+	err := h.manager.Complete(r.Context(), session, func(file io.Reader) error {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		path, ok = h.testProcedures.saveStepImage(w, r, procedureID, session.FileName, data)
+		if !ok {
+			return errResponseWritten
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		"image_path": path,
+	})
+	return nil
+}
+
+// Abort handles cancelling an upload and discarding its parts.
+func (h *UploadHandler) Abort(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.manager.Abort(r.Context(), session); err != nil {
+		if errors.Is(err, upload.ErrSessionBusy) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "failed to abort upload")
+		return
+	}
+
+	respondSuccess(w, "upload aborted")
+}
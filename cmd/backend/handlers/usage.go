@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/quota"
+)
+
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// UsageHandler handles usage report requests. Reports only ever cover the
+// authenticated user's own account.
+type UsageHandler struct {
+	usageStore metering.Store
+	quotas     *quota.Enforcer
+	logger     logger.Logger
+}
+
+// NewUsageHandler creates a new usage handler.
+func NewUsageHandler(usageStore metering.Store, quotas *quota.Enforcer, log logger.Logger) *UsageHandler {
+	return &UsageHandler{
+		usageStore: usageStore,
+		quotas:     quotas,
+		logger:     log,
+	}
+}
+
+// buildReport builds the report for the month in ?month=YYYY-MM, defaulting
+// to the current month. Returns false if it fails (response already written).
+func (h *UsageHandler) buildReport(w http.ResponseWriter, r *http.Request) (*metering.Report, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return nil, false
+	}
+
+	month := time.Now().UTC()
+	if monthStr := r.URL.Query().Get("month"); monthStr != "" {
+		parsed, err := metering.ParseMonth(monthStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return nil, false
+		}
+		month = parsed
+	}
+
+	report, err := metering.BuildReport(r.Context(), h.usageStore, userID, month)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to build usage report", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to build usage report")
+		return nil, false
+	}
+
+	return report, true
+}
+
+// GetReport handles GET /usage?month=YYYY-MM.
+func (h *UsageHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.buildReport(w, r)
+	if !ok {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// Export handles GET /usage/export?month=YYYY-MM&format=csv|openmetrics.
+// CSV is returned as a file download; OpenMetrics is returned inline so it can
+// be scraped.
+func (h *UsageHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "openmetrics" {
+		respondError(w, http.StatusBadRequest, "format must be one of: csv, openmetrics")
+		return
+	}
+
+	report, ok := h.buildReport(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if format == "openmetrics" {
+		err = report.WriteOpenMetrics(&buf)
+	} else {
+		err = report.WriteCSV(&buf)
+	}
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to export usage report", map[string]interface{}{
+			"error":  err.Error(),
+			"format": format,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to export usage report")
+		return
+	}
+
+	if format == "openmetrics" {
+		w.Header().Set("Content-Type", openMetricsContentType)
+	} else {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "usage-"+report.Month+".csv"))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// GetProjectStorage handles GET /projects/{id}/storage-usage. The project
+// route's authorization middleware has already verified ownership.
+func (h *UsageHandler) GetProjectStorage(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "id", "project")
+	if !ok {
+		return
+	}
+
+	usage, err := h.quotas.Usage(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get storage usage")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, usage)
+}
@@ -5,24 +5,41 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"github.com/hairizuanbinnoorazman/ui-automation/handover"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/session"
 	"github.com/hairizuanbinnoorazman/ui-automation/user"
 )
 
 // UserHandler handles user-related requests.
 type UserHandler struct {
-	userStore user.Store
-	logger    logger.Logger
+	userStore      user.Store
+	handoverStore  handover.Store
+	sessionManager *session.Manager
+	tokenStore     apitoken.Store
+	logger         logger.Logger
 }
 
 // NewUserHandler creates a new user handler.
-func NewUserHandler(userStore user.Store, log logger.Logger) *UserHandler {
+func NewUserHandler(userStore user.Store, handoverStore handover.Store, sessionManager *session.Manager, tokenStore apitoken.Store, log logger.Logger) *UserHandler {
 	return &UserHandler{
-		userStore: userStore,
-		logger:    log,
+		userStore:      userStore,
+		handoverStore:  handoverStore,
+		sessionManager: sessionManager,
+		tokenStore:     tokenStore,
+		logger:         log,
 	}
 }
 
+// HoldingsResponse represents an account that cannot be deleted while it
+// still owns projects or integrations.
+type HoldingsResponse struct {
+	Error string `json:"error"`
+	handover.Holdings
+}
+
 // UpdateUserRequest represents a user update request.
 type UpdateUserRequest struct {
 	Email    *string `json:"email,omitempty"`
@@ -106,13 +123,17 @@ func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, foundUser)
 }
 
-// Update handles updating a user.
+// Update handles updating the caller's own account. Admins manage other
+// users through AdminHandler.
 func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from URL
 	id, ok := parseUUIDOrRespond(w, r, "id", "user")
 	if !ok {
 		return
 	}
+	if !checkSelf(w, r, id) {
+		return
+	}
 
 	// Parse request body
 	var req UpdateUserRequest
@@ -181,13 +202,32 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, updatedUser)
 }
 
-// Delete handles soft deleting a user.
+// Delete handles soft deleting the caller's own account, which logs them
+// out everywhere and revokes their API tokens. Accounts that still own
+// active projects or integrations cannot be deleted until they are deleted
+// or an admin transfers them to another user.
 func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from URL
 	id, ok := parseUUIDOrRespond(w, r, "id", "user")
 	if !ok {
 		return
 	}
+	if !checkSelf(w, r, id) {
+		return
+	}
+
+	holdings, err := h.handoverStore.Holdings(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to delete user")
+		return
+	}
+	if !holdings.IsEmpty() {
+		respondJSON(w, http.StatusConflict, HoldingsResponse{
+			Error:    "user still owns projects or integrations; delete or transfer them first",
+			Holdings: holdings,
+		})
+		return
+	}
 
 	// Delete user
 	if err := h.userStore.Delete(r.Context(), id); err != nil {
@@ -202,6 +242,7 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "failed to delete user")
 		return
 	}
+	endUserAccess(r, h.sessionManager, h.tokenStore, id, h.logger)
 
 	h.logger.Info(r.Context(), "user deleted", map[string]interface{}{
 		"user_id": id,
@@ -209,3 +250,18 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	respondSuccess(w, "user deleted successfully")
 }
+
+// checkSelf refuses changes to any account but the caller's. Returns false
+// if refused (response already written).
+func checkSelf(w http.ResponseWriter, r *http.Request, id uuid.UUID) bool {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return false
+	}
+	if userID != id {
+		respondError(w, http.StatusForbidden, "you can only change your own account")
+		return false
+	}
+	return true
+}
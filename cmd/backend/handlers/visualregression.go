@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/visualregression"
+)
+
+// VisualRegressionHandler handles visual regression baselines and the images
+// produced by visual_regression jobs.
+type VisualRegressionHandler struct {
+	baselineStore visualregression.Store
+	jobStore      job.Store
+	projectStore  project.Store
+	approver      *visualregression.Approver
+	storage       storage.BlobStorage
+	recorder      *metering.Recorder
+	logger        logger.Logger
+}
+
+// NewVisualRegressionHandler creates a new visual regression handler.
+func NewVisualRegressionHandler(
+	baselineStore visualregression.Store,
+	jobStore job.Store,
+	projectStore project.Store,
+	approver *visualregression.Approver,
+	storage storage.BlobStorage,
+	recorder *metering.Recorder,
+	log logger.Logger,
+) *VisualRegressionHandler {
+	return &VisualRegressionHandler{
+		baselineStore: baselineStore,
+		jobStore:      jobStore,
+		projectStore:  projectStore,
+		approver:      approver,
+		storage:       storage,
+		recorder:      recorder,
+		logger:        log,
+	}
+}
+
+// getOwnedJob loads a visual_regression job created by the authenticated user.
+// Returns false if the check fails (response already written).
+func (h *VisualRegressionHandler) getOwnedJob(w http.ResponseWriter, r *http.Request) (*job.Job, bool) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return nil, false
+	}
+
+	id, ok := parseUUIDOrRespond(w, r, "id", "job")
+	if !ok {
+		return nil, false
+	}
+
+	j, err := h.jobStore.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, job.ErrJobNotFound) {
+			respondError(w, http.StatusNotFound, "job not found")
+			return nil, false
+		}
+		h.logger.Error(r.Context(), "failed to get job for authorization", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": id,
+		})
+		respondError(w, http.StatusInternalServerError, "authorization check failed")
+		return nil, false
+	}
+
+	if j.CreatedBy != userID {
+		h.logger.Warn(r.Context(), "unauthorized job access attempt", map[string]interface{}{
+			"user_id":    userID,
+			"job_id":     id,
+			"created_by": j.CreatedBy,
+		})
+		respondError(w, http.StatusForbidden, "you don't have access to this job")
+		return nil, false
+	}
+
+	if j.Type != job.JobTypeVisualRegression {
+		respondError(w, http.StatusBadRequest, "job is not a visual_regression job")
+		return nil, false
+	}
+
+	return j, true
+}
+
+// checkProjectAccess verifies that the caller can access the project.
+// Returns false if the check fails (response already written).
+func (h *VisualRegressionHandler) checkProjectAccess(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) bool {
+	if _, ok := GetUserID(r.Context()); !ok {
+		respondError(w, http.StatusUnauthorized, "user not authenticated")
+		return false
+	}
+
+	proj, err := h.projectStore.GetByID(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			respondError(w, http.StatusNotFound, "project not found")
+			return false
+		}
+		h.logger.Error(r.Context(), "failed to get project for authorization", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "authorization check failed")
+		return false
+	}
+
+	if !canAccessProject(r, proj.ID, proj.OwnerID) {
+		respondError(w, http.StatusForbidden, "you don't have access to this project")
+		return false
+	}
+
+	return true
+}
+
+// getProjectBaseline loads a baseline of a project the user owns.
+// Returns false if the check fails (response already written).
+func (h *VisualRegressionHandler) getProjectBaseline(w http.ResponseWriter, r *http.Request) (*visualregression.Baseline, bool) {
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return nil, false
+	}
+	baselineID, ok := parseUUIDOrRespond(w, r, "baseline_id", "baseline")
+	if !ok {
+		return nil, false
+	}
+
+	if !h.checkProjectAccess(w, r, projectID) {
+		return nil, false
+	}
+
+	baseline, err := h.baselineStore.GetByID(r.Context(), baselineID)
+	if err != nil {
+		if errors.Is(err, visualregression.ErrBaselineNotFound) {
+			respondError(w, http.StatusNotFound, "baseline not found")
+			return nil, false
+		}
+		h.logger.Error(r.Context(), "failed to get baseline", map[string]interface{}{
+			"error":       err.Error(),
+			"baseline_id": baselineID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to get baseline")
+		return nil, false
+	}
+
+	if baseline.ProjectID != projectID {
+		respondError(w, http.StatusNotFound, "baseline not found")
+		return nil, false
+	}
+
+	return baseline, true
+}
+
+// streamImage writes a PNG from blob storage to the response.
+func (h *VisualRegressionHandler) streamImage(w http.ResponseWriter, r *http.Request, path string) {
+	reader, err := h.storage.Download(r.Context(), path)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			respondError(w, http.StatusNotFound, "image not found in storage")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to download image from storage", map[string]interface{}{
+			"error": err.Error(),
+			"path":  path,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to download image")
+		return
+	}
+	defer reader.Close()
+
+	w.Header().Set("Content-Type", "image/png")
+	if _, err := io.Copy(w, reader); err != nil {
+		h.logger.Error(r.Context(), "failed to stream image", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// ApproveBaselinesRequest represents a request to approve job captures as baselines.
+type ApproveBaselinesRequest struct {
+	Pages []string `json:"pages"`
+}
+
+// ApproveBaselines handles POST /jobs/{id}/baselines. The captures of the
+// listed pages become their new baselines; with no pages, every changed or
+// new page is approved.
+func (h *VisualRegressionHandler) ApproveBaselines(w http.ResponseWriter, r *http.Request) {
+	j, ok := h.getOwnedJob(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	var req ApproveBaselinesRequest
+	if r.ContentLength != 0 {
+		if err := parseJSON(r, &req, h.logger); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	baselines, err := h.approver.Approve(r.Context(), j.ID, req.Pages, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, visualregression.ErrJobNotApprovable),
+			errors.Is(err, visualregression.ErrPageNotFound),
+			errors.Is(err, visualregression.ErrNoCapture):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error(r.Context(), "failed to approve baselines", map[string]interface{}{
+				"error":  err.Error(),
+				"job_id": j.ID,
+			})
+			respondError(w, http.StatusInternalServerError, "failed to approve baselines")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"items": baselines,
+		"total": len(baselines),
+	})
+}
+
+// GetPageImage handles GET /jobs/{id}/pages/{page}/{kind}, where kind is one
+// of actual, diff or baseline.
+func (h *VisualRegressionHandler) GetPageImage(w http.ResponseWriter, r *http.Request) {
+	j, ok := h.getOwnedJob(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+
+	pages, err := visualregression.PagesFromResult(j.Result)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to decode job pages", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": j.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to read job result")
+		return
+	}
+
+	var path string
+	found := false
+	for _, page := range pages {
+		if page.Name != vars["page"] {
+			continue
+		}
+		found = true
+		switch vars["kind"] {
+		case "actual":
+			path = page.ActualPath
+		case "diff":
+			path = page.DiffPath
+		case "baseline":
+			path = page.BaselinePath
+		default:
+			respondError(w, http.StatusBadRequest, "image kind must be one of: actual, diff, baseline")
+			return
+		}
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "page not found")
+		return
+	}
+	if path == "" {
+		respondError(w, http.StatusNotFound, "page has no "+vars["kind"]+" image")
+		return
+	}
+
+	h.streamImage(w, r, path)
+}
+
+// ListBaselines handles GET /projects/{project_id}/baselines, optionally
+// filtered by ?endpoint_id=.
+func (h *VisualRegressionHandler) ListBaselines(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := parseUUIDOrRespond(w, r, "project_id", "project")
+	if !ok {
+		return
+	}
+
+	if !h.checkProjectAccess(w, r, projectID) {
+		return
+	}
+
+	endpointID := uuid.Nil
+	if endpointIDStr := r.URL.Query().Get("endpoint_id"); endpointIDStr != "" {
+		parsed, err := uuid.Parse(endpointIDStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid endpoint_id")
+			return
+		}
+		endpointID = parsed
+	}
+
+	baselines, err := h.baselineStore.ListByProject(r.Context(), projectID, endpointID)
+	if err != nil {
+		h.logger.Error(r.Context(), "failed to list baselines", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to list baselines")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"items": baselines,
+		"total": len(baselines),
+	})
+}
+
+// GetBaselineImage handles GET /projects/{project_id}/baselines/{baseline_id}/image.
+func (h *VisualRegressionHandler) GetBaselineImage(w http.ResponseWriter, r *http.Request) {
+	baseline, ok := h.getProjectBaseline(w, r)
+	if !ok {
+		return
+	}
+
+	h.streamImage(w, r, baseline.ImagePath)
+}
+
+// DeleteBaseline handles DELETE /projects/{project_id}/baselines/{baseline_id}.
+// The next capture of the page will be reported as new.
+func (h *VisualRegressionHandler) DeleteBaseline(w http.ResponseWriter, r *http.Request) {
+	baseline, ok := h.getProjectBaseline(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	if err := h.baselineStore.Delete(r.Context(), baseline.ID); err != nil {
+		if errors.Is(err, visualregression.ErrBaselineNotFound) {
+			respondError(w, http.StatusNotFound, "baseline not found")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to delete baseline", map[string]interface{}{
+			"error":       err.Error(),
+			"baseline_id": baseline.ID,
+		})
+		respondError(w, http.StatusInternalServerError, "failed to delete baseline")
+		return
+	}
+
+	// Delete from storage (best effort - log error but don't fail request)
+	if err := h.storage.Delete(r.Context(), baseline.ImagePath); err != nil {
+		h.logger.Warn(r.Context(), "failed to delete baseline image from storage", map[string]interface{}{
+			"error": err.Error(),
+			"path":  baseline.ImagePath,
+		})
+	} else {
+		h.recorder.RecordStorage(r.Context(), baseline.ProjectID, userID, -baseline.FileSize)
+	}
+
+	respondSuccess(w, "baseline deleted successfully")
+}
@@ -1,55 +1,45 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
 	"github.com/spf13/cobra"
 )
 
 var (
-	migrationsPath string
+	migrationsPath   string
+	migrateUpSteps   int
+	migrateDownSteps int
 )
 
 var migrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Database migration commands",
+	Long: `Manage the database schema with the versioned SQL migrations in the
+migrations directory. Each migration is a pair of NNNNNN_name.up.sql and
+NNNNNN_name.down.sql files; the applied version is tracked in the
+schema_migrations table.`,
 }
 
 var migrateUpCmd = &cobra.Command{
 	Use:   "up",
-	Short: "Apply all pending migrations",
+	Short: "Apply pending migrations",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Load config
-		cfg, err := LoadConfig(configFile)
+		sqlDB, err := openMigrationDB()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
-		}
-
-		// Connect to database
-		dbCfg := database.Config{
-			Host:         cfg.Database.Host,
-			Port:         cfg.Database.Port,
-			User:         cfg.Database.User,
-			Password:     cfg.Database.Password,
-			Database:     cfg.Database.Database,
-			MaxOpenConns: cfg.Database.MaxOpenConns,
-			MaxIdleConns: cfg.Database.MaxIdleConns,
-		}
-
-		db, err := database.Connect(dbCfg)
-		if err != nil {
-			return fmt.Errorf("failed to connect to database: %w", err)
-		}
-
-		sqlDB, err := db.DB()
-		if err != nil {
-			return fmt.Errorf("failed to get database instance: %w", err)
+			return err
 		}
 		defer sqlDB.Close()
 
 		// Run migrations
-		if err := database.RunMigrations(sqlDB, migrationsPath); err != nil {
+		if err := database.RunMigrations(sqlDB, migrationsPath, migrateUpSteps); err != nil {
 			return fmt.Errorf("failed to run migrations: %w", err)
 		}
 
@@ -60,52 +50,132 @@ var migrateUpCmd = &cobra.Command{
 
 var migrateDownCmd = &cobra.Command{
 	Use:   "down",
-	Short: "Rollback the most recent migration",
+	Short: "Rollback the most recent migrations",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Load config
-		cfg, err := LoadConfig(configFile)
+		sqlDB, err := openMigrationDB()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return err
 		}
+		defer sqlDB.Close()
+
+		// Rollback migrations
+		if err := database.RollbackMigrations(sqlDB, migrationsPath, migrateDownSteps); err != nil {
+			return fmt.Errorf("failed to rollback migration: %w", err)
+		}
+
+		fmt.Println("Migration rolled back successfully")
+		return nil
+	},
+}
 
-		// Connect to database
-		dbCfg := database.Config{
-			Host:         cfg.Database.Host,
-			Port:         cfg.Database.Port,
-			User:         cfg.Database.User,
-			Password:     cfg.Database.Password,
-			Database:     cfg.Database.Database,
-			MaxOpenConns: cfg.Database.MaxOpenConns,
-			MaxIdleConns: cfg.Database.MaxIdleConns,
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sqlDB, err := openMigrationDB()
+		if err != nil {
+			return err
 		}
+		defer sqlDB.Close()
 
-		db, err := database.Connect(dbCfg)
+		status, err := database.GetMigrationStatus(sqlDB, migrationsPath)
 		if err != nil {
-			return fmt.Errorf("failed to connect to database: %w", err)
+			return err
+		}
+
+		state := "clean"
+		if status.Dirty {
+			state = "dirty (repair the schema, then run 'migrate force VERSION')"
+		}
+		fmt.Printf("Current version: %d\n", status.Version)
+		fmt.Printf("State: %s\n", state)
+		fmt.Printf("Pending migrations: %d\n\n", len(status.Pending))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, migration := range status.Available {
+			applied := "applied"
+			switch {
+			case migration.Version > status.Version:
+				applied = "pending"
+			case migration.Version == status.Version && status.Dirty:
+				applied = "dirty"
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\n", migration.Version, migration.Name, applied)
+		}
+		return w.Flush()
+	},
+}
+
+var migrateForceCmd = &cobra.Command{
+	Use:   "force VERSION",
+	Short: "Set the schema version without running migrations",
+	Long: `Records VERSION as the applied migration and clears the dirty flag without
+running any SQL. Use it after manually repairing a migration that failed part
+way through.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version: %s", args[0])
 		}
 
-		sqlDB, err := db.DB()
+		sqlDB, err := openMigrationDB()
 		if err != nil {
-			return fmt.Errorf("failed to get database instance: %w", err)
+			return err
 		}
 		defer sqlDB.Close()
 
-		// Rollback migration
-		if err := database.RollbackMigration(sqlDB, migrationsPath); err != nil {
-			return fmt.Errorf("failed to rollback migration: %w", err)
+		if err := database.ForceMigrationVersion(sqlDB, migrationsPath, version); err != nil {
+			return err
 		}
 
-		fmt.Println("Migration rolled back successfully")
+		fmt.Printf("Schema version set to %d\n", version)
 		return nil
 	},
 }
 
+// openMigrationDB loads the config and connects to the configured database.
+func openMigrationDB() (*sql.DB, error) {
+	// Load config
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// The SQL migrations are written for MySQL; SQLite databases are
+	// created from the models when the server starts.
+	if cfg.Database.Driver == database.DriverSQLite {
+		return nil, fmt.Errorf("migrations only apply to MySQL; the sqlite schema is created when the server starts")
+	}
+
+	if _, err := loadSecrets(context.Background(), cfg, logger.NewLogrusLogger(cfg.Log.Level)); err != nil {
+		return nil, err
+	}
+
+	// Connect to database
+	db, err := database.Connect(cfg.Database.connectionConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return sqlDB, nil
+}
+
 func init() {
 	migrateCmd.AddCommand(migrateUpCmd)
 	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateForceCmd)
 
 	migrateCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file path")
 	migrateCmd.PersistentFlags().StringVarP(&migrationsPath, "path", "p", "database/migrations", "migrations directory path")
+	migrateUpCmd.Flags().IntVarP(&migrateUpSteps, "steps", "n", 0, "number of migrations to apply (default all)")
+	migrateDownCmd.Flags().IntVarP(&migrateDownSteps, "steps", "n", 1, "number of migrations to roll back")
 
 	rootCmd.AddCommand(migrateCmd)
 }
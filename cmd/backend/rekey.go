@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/cipipeline"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
+	"github.com/hairizuanbinnoorazman/ui-automation/scriptrepo"
+	"github.com/hairizuanbinnoorazman/ui-automation/testmanagement"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// newEncryptionKeyEnv is read for the new key when --new-key is not given,
+// so the key does not have to appear in the process list.
+const newEncryptionKeyEnv = "INTEGRATION_NEW_ENCRYPTION_KEY"
+
+var (
+	rekeyOldKey string
+	rekeyNewKey string
+	rekeyDryRun bool
+)
+
+// errRekeyDryRun rolls back the transaction of a dry run.
+var errRekeyDryRun = errors.New("dry run")
+
+var rekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Re-encrypt stored secrets with a new encryption key",
+	Long: `Decrypts every integration credential, endpoint secret, Slack webhook
+URL, script repository token, CI pipeline token and test management token
+with the old encryption key and encrypts it again with the new one, in a
+single transaction. If any value does not decrypt with the old key nothing is
+changed.
+
+The old key defaults to integration.encryption_key, or the secret
+secrets.encryption_key references. The new key is taken from --new-key or the
+` + newEncryptionKeyEnv + ` environment variable. Stop the server before
+rekeying and start it with the new key afterwards. --dry-run checks that every
+value decrypts and rolls back. Values already encrypted with the new key are
+skipped, so an interrupted rekey can be run again.
+
+Servers reading the key from a secrets manager rekey by themselves when the
+secret is rotated.`,
+	RunE: runRekey,
+}
+
+func init() {
+	rekeyCmd.Flags().StringVarP(&configFile, "config", "c", "", "config file path")
+	rekeyCmd.Flags().BoolVar(&devMode, "dev", false, "use the SQLite database of --dev servers")
+	rekeyCmd.Flags().StringVar(&rekeyOldKey, "old-key", "", "current encryption key (default integration.encryption_key)")
+	rekeyCmd.Flags().StringVar(&rekeyNewKey, "new-key", "", "new encryption key (default $"+newEncryptionKeyEnv+")")
+	rekeyCmd.Flags().BoolVar(&rekeyDryRun, "dry-run", false, "check every value decrypts without changing anything")
+	rootCmd.AddCommand(rekeyCmd)
+}
+
+func runRekey(cmd *cobra.Command, args []string) error {
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if devMode {
+		applyDevMode(cfg)
+	}
+	if _, err := loadSecrets(cmd.Context(), cfg, logger.NewLogrusLogger(cfg.Log.Level)); err != nil {
+		return err
+	}
+
+	oldKey := rekeyOldKey
+	if oldKey == "" {
+		oldKey = cfg.Integration.EncryptionKey
+	}
+	newKey := rekeyNewKey
+	if newKey == "" {
+		newKey = os.Getenv(newEncryptionKeyEnv)
+	}
+	if newKey == "" && !rekeyDryRun {
+		return fmt.Errorf("a new key is required: set --new-key or %s", newEncryptionKeyEnv)
+	}
+	if newKey == oldKey {
+		return fmt.Errorf("the new key must differ from the old key")
+	}
+
+	db, err := database.Connect(cfg.Database.connectionConfig())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	defer sqlDB.Close()
+
+	counts, err := rekey(cmd.Context(), db, integration.DeriveKey(oldKey), integration.DeriveKey(newKey), rekeyDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to rekey, nothing was changed: %w", err)
+	}
+
+	verb := "Re-encrypted"
+	if rekeyDryRun {
+		verb = "Dry run: would re-encrypt"
+	}
+	fmt.Printf("%s %d integration credentials, %d endpoint secrets, %d Slack webhooks, %d script repository tokens, %d CI pipeline tokens and %d test management tokens\n",
+		verb, counts.integrations, counts.endpointSecrets, counts.slackWebhooks, counts.scriptRepositoryTokens, counts.ciPipelineTokens, counts.testManagementTokens)
+	if !rekeyDryRun {
+		fmt.Println("Set integration.encryption_key to the new key before starting the server")
+	}
+	return nil
+}
+
+// rekeyCounts is the number of values rekey re-encrypted in each table.
+type rekeyCounts struct {
+	integrations           int
+	endpointSecrets        int
+	slackWebhooks          int
+	scriptRepositoryTokens int
+	ciPipelineTokens       int
+	testManagementTokens   int
+}
+
+// rekey re-encrypts every stored secret from oldKey to newKey in one
+// transaction. A dry run re-encrypts and then rolls back.
+func rekey(ctx context.Context, db *gorm.DB, oldKey, newKey []byte, dryRun bool) (rekeyCounts, error) {
+	var counts rekeyCounts
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if counts.integrations, err = integration.RekeyCredentials(ctx, tx, oldKey, newKey); err != nil {
+			return err
+		}
+		if counts.endpointSecrets, err = endpoint.RekeySecrets(ctx, tx, oldKey, newKey); err != nil {
+			return err
+		}
+		if counts.slackWebhooks, err = notification.RekeySlackWebhooks(ctx, tx, oldKey, newKey); err != nil {
+			return err
+		}
+		if counts.scriptRepositoryTokens, err = scriptrepo.RekeyTokens(ctx, tx, oldKey, newKey); err != nil {
+			return err
+		}
+		if counts.ciPipelineTokens, err = cipipeline.RekeyTokens(ctx, tx, oldKey, newKey); err != nil {
+			return err
+		}
+		if counts.testManagementTokens, err = testmanagement.RekeyTokens(ctx, tx, oldKey, newKey); err != nil {
+			return err
+		}
+		if dryRun {
+			return errRekeyDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errRekeyDryRun) {
+		err = nil
+	}
+	return counts, err
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/ratelimit"
+)
+
+// configReloader re-reads the config file when the server receives SIGHUP
+// and applies the settings that can change without a restart: the log
+// level, rate limits and agent concurrency. Other changes are logged as
+// needing a restart.
+type configReloader struct {
+	path             string
+	logger           *logger.LogrusLogger
+	apiLimiter       *ratelimit.Limiter
+	expensiveLimiter *ratelimit.Limiter
+	workerPool       *agent.WorkerPool
+
+	mu sync.Mutex
+	// loaded is the configuration as read from the file, before dev mode
+	// and secrets were applied, with the reloaded settings updated.
+	loaded Config
+}
+
+// reload re-reads the config file and applies its reloadable settings. The
+// current settings are kept if the file is invalid.
+func (r *configReloader) reload(ctx context.Context) error {
+	next, err := LoadConfig(r.path)
+	if err != nil {
+		return err
+	}
+	if next.Agent.MaxConcurrentWorkers < 1 {
+		return fmt.Errorf("agent.max_concurrent_workers must be at least 1")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if changed := restartRequired(&r.loaded, next); len(changed) > 0 {
+		r.logger.Warn(ctx, "config changes need a restart to take effect", map[string]interface{}{
+			"sections": changed,
+		})
+	}
+
+	if err := r.logger.SetLevel(next.Log.Level); err != nil {
+		r.logger.Warn(ctx, "invalid log level, keeping the current level", map[string]interface{}{
+			"level": next.Log.Level,
+		})
+	} else {
+		r.loaded.Log = next.Log
+	}
+	r.apiLimiter.SetRule(ratelimit.Rule{
+		RequestsPerMinute: next.RateLimit.RequestsPerMinute,
+		Burst:             next.RateLimit.Burst,
+	})
+	r.expensiveLimiter.SetRule(ratelimit.Rule{
+		RequestsPerMinute: next.RateLimit.ExpensiveRequestsPerMinute,
+		Burst:             next.RateLimit.ExpensiveBurst,
+	})
+	r.loaded.RateLimit = next.RateLimit
+	r.workerPool.Resize(next.Agent.MaxConcurrentWorkers)
+	r.loaded.Agent.MaxConcurrentWorkers = next.Agent.MaxConcurrentWorkers
+
+	r.logger.Info(ctx, "config reloaded", map[string]interface{}{
+		"log_level":              r.loaded.Log.Level,
+		"requests_per_minute":    r.loaded.RateLimit.RequestsPerMinute,
+		"max_concurrent_workers": r.loaded.Agent.MaxConcurrentWorkers,
+	})
+	return nil
+}
+
+// restartRequired returns the sections of the configuration that differ
+// between current and next in settings that cannot be reloaded.
+func restartRequired(current, next *Config) []string {
+	a, b := *current, *next
+	for _, c := range []*Config{&a, &b} {
+		c.Log = LogConfig{}
+		c.RateLimit = RateLimitConfig{}
+		c.Agent.MaxConcurrentWorkers = 0
+	}
+
+	var changed []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, va.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/secrets"
+	"gorm.io/gorm"
+)
+
+// configSecrets are the configuration values read from a secrets manager.
+// A value is nil when it is taken from the config file.
+type configSecrets struct {
+	watcher          *secrets.Watcher
+	encryptionKey    *secrets.Value
+	databasePassword *secrets.Value
+}
+
+// loadSecrets reads the secrets cfg.Secrets references and stores them in
+// cfg in place of the config file values. It returns nil if no secrets
+// manager is configured.
+func loadSecrets(ctx context.Context, cfg *Config, log logger.Logger) (*configSecrets, error) {
+	if cfg.Secrets.Provider == "" {
+		return nil, nil
+	}
+
+	provider, err := secrets.NewProvider(ctx, cfg.Secrets.providerConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize secrets provider: %w", err)
+	}
+
+	s := &configSecrets{watcher: secrets.NewWatcher(provider, log)}
+	if ref := cfg.Secrets.EncryptionKey; ref != "" {
+		if s.encryptionKey, err = s.watcher.Watch(ctx, ref); err != nil {
+			return nil, fmt.Errorf("failed to read secrets.encryption_key: %w", err)
+		}
+		cfg.Integration.EncryptionKey = s.encryptionKey.Get()
+	}
+	if ref := cfg.Secrets.DatabasePassword; ref != "" {
+		if s.databasePassword, err = s.watcher.Watch(ctx, ref); err != nil {
+			return nil, fmt.Errorf("failed to read secrets.database_password: %w", err)
+		}
+		cfg.Database.Password = s.databasePassword.Get()
+	}
+	return s, nil
+}
+
+// rotateEncryptionKey switches keyring to the new key whenever the
+// encryption key secret is rotated, and re-encrypts the stored secrets with
+// it so that they still decrypt once the server restarts with only the new
+// key. Rekeying is idempotent, so every server may do it.
+func (s *configSecrets) rotateEncryptionKey(db *gorm.DB, keyring *integration.Keyring, log logger.Logger) {
+	if s == nil || s.encryptionKey == nil {
+		return
+	}
+
+	s.encryptionKey.OnChange(func(value string) {
+		ctx := context.Background()
+		oldKey := keyring.Current()
+		newKey := integration.DeriveKey(value)
+		keyring.Rotate(newKey)
+
+		counts, err := rekey(ctx, db, oldKey, newKey, false)
+		if err != nil {
+			log.Error(ctx, "failed to re-encrypt secrets with the rotated encryption key", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		log.Info(ctx, "re-encrypted secrets with the rotated encryption key", map[string]interface{}{
+			"integrations":     counts.integrations,
+			"endpoint_secrets": counts.endpointSecrets,
+			"slack_webhooks":   counts.slackWebhooks,
+		})
+	})
+}
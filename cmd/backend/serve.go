@@ -5,42 +5,100 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/hairizuanbinnoorazman/ui-automation/activity"
 	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/analytics"
 	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"github.com/hairizuanbinnoorazman/ui-automation/cipipeline"
+	"github.com/hairizuanbinnoorazman/ui-automation/authoring"
+	"github.com/hairizuanbinnoorazman/ui-automation/badge"
 	"github.com/hairizuanbinnoorazman/ui-automation/cmd/backend/handlers"
 	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/dataset"
 	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/execution"
+	"github.com/hairizuanbinnoorazman/ui-automation/exploration"
+	"github.com/hairizuanbinnoorazman/ui-automation/handover"
+	"github.com/hairizuanbinnoorazman/ui-automation/idempotency"
 	"github.com/hairizuanbinnoorazman/ui-automation/integration"
 	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+	customclient "github.com/hairizuanbinnoorazman/ui-automation/issuetracker/custom"
 	githubclient "github.com/hairizuanbinnoorazman/ui-automation/issuetracker/github"
 	jiraclient "github.com/hairizuanbinnoorazman/ui-automation/issuetracker/jira"
 	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/label"
+	"github.com/hairizuanbinnoorazman/ui-automation/linkcheck"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/media"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/narration"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
+	"github.com/hairizuanbinnoorazman/ui-automation/ownership"
+	"github.com/hairizuanbinnoorazman/ui-automation/presence"
+	"github.com/hairizuanbinnoorazman/ui-automation/procedureshare"
 	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/projectarchive"
+	"github.com/hairizuanbinnoorazman/ui-automation/quota"
+	"github.com/hairizuanbinnoorazman/ui-automation/ratelimit"
+	"github.com/hairizuanbinnoorazman/ui-automation/release"
+	"github.com/hairizuanbinnoorazman/ui-automation/requirement"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/hairizuanbinnoorazman/ui-automation/retention"
+	"github.com/hairizuanbinnoorazman/ui-automation/review"
+	"github.com/hairizuanbinnoorazman/ui-automation/runner"
+	"github.com/hairizuanbinnoorazman/ui-automation/saml"
+	"github.com/hairizuanbinnoorazman/ui-automation/savedview"
 	"github.com/hairizuanbinnoorazman/ui-automation/scriptgen"
+	"github.com/hairizuanbinnoorazman/ui-automation/scriptrepo"
+	"github.com/hairizuanbinnoorazman/ui-automation/serviceaccount"
+	"github.com/hairizuanbinnoorazman/ui-automation/share"
 	"github.com/hairizuanbinnoorazman/ui-automation/session"
+	"github.com/hairizuanbinnoorazman/ui-automation/shutdown"
+	"github.com/hairizuanbinnoorazman/ui-automation/steplibrary"
+	"github.com/hairizuanbinnoorazman/ui-automation/stepsuggest"
 	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testmanagement"
 	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
 	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/upload"
 	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/hairizuanbinnoorazman/ui-automation/visualregression"
 	"github.com/spf13/cobra"
 )
 
-var configFile string
+var (
+	configFile string
+	devMode    bool
+)
+
+// SAML endpoints, which are registered with the identity provider.
+const (
+	samlMetadataPath = "/api/v1/auth/saml/metadata"
+	samlACSPath      = "/api/v1/auth/saml/acs"
+)
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the HTTP server",
-	RunE:  runServer,
+	Long: `Start the HTTP server.
+
+With --dev the server uses a SQLite database (database.sqlite_path, default
+ui_automation.db) and local storage (storage.base_dir, default ./uploads) and
+creates the schema itself, so it runs on a laptop without any configuration.`,
+	RunE: runServer,
 }
 
 func init() {
 	serveCmd.Flags().StringVarP(&configFile, "config", "c", "", "config file path")
+	serveCmd.Flags().BoolVar(&devMode, "dev", false, "run with a SQLite database and local storage, for single-machine use")
 	rootCmd.AddCommand(serveCmd)
 }
 
@@ -61,18 +119,33 @@ func runServer(cmd *cobra.Command, args []string) error {
 		"date":    BuildDate,
 	})
 
-	// Connect to database
-	dbCfg := database.Config{
-		Host:         cfg.Database.Host,
-		Port:         cfg.Database.Port,
-		User:         cfg.Database.User,
-		Password:     cfg.Database.Password,
-		Database:     cfg.Database.Database,
-		MaxOpenConns: cfg.Database.MaxOpenConns,
-		MaxIdleConns: cfg.Database.MaxIdleConns,
+	// Keep the settings as read from the file to tell which settings a
+	// reload changes.
+	loadedConfig := *cfg
+
+	if devMode {
+		applyDevMode(cfg)
 	}
 
-	db, err := database.Connect(dbCfg)
+	// Read the encryption key and database password from the secrets
+	// manager, if one is configured, and keep them current as they rotate.
+	configSecrets, err := loadSecrets(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	dbConfig := cfg.Database.connectionConfig()
+	if configSecrets != nil {
+		if configSecrets.databasePassword != nil {
+			dbConfig.PasswordFunc = configSecrets.databasePassword.Get
+		}
+		if cfg.Secrets.RefreshInterval > 0 {
+			configSecrets.watcher.Start(cfg.Secrets.RefreshInterval)
+			defer configSecrets.watcher.Stop()
+		}
+	}
+
+	// Connect to database
+	db, err := database.Connect(dbConfig)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -83,21 +156,59 @@ func runServer(cmd *cobra.Command, args []string) error {
 	}
 	defer sqlDB.Close()
 
-	log.Info(ctx, "database connected", map[string]interface{}{
-		"host":     cfg.Database.Host,
-		"port":     cfg.Database.Port,
-		"database": cfg.Database.Database,
-	})
+	if cfg.Database.Driver == database.DriverSQLite {
+		log.Info(ctx, "database connected", map[string]interface{}{
+			"driver": cfg.Database.Driver,
+			"path":   cfg.Database.SQLitePath,
+		})
 
-	// Initialize storage
-	storageConfig := map[string]interface{}{
-		"base_dir":       cfg.Storage.BaseDir,
-		"bucket":         cfg.Storage.S3Bucket,
-		"region":         cfg.Storage.S3Region,
-		"presign_expiry": cfg.Storage.S3PresignExpiry,
+		// The SQL migrations are MySQL specific, so SQLite databases are
+		// created and kept up to date from the models instead.
+		if err := database.CreateSchema(db, schemaModels()...); err != nil {
+			return err
+		}
+	} else {
+		log.Info(ctx, "database connected", map[string]interface{}{
+			"host":     cfg.Database.Host,
+			"port":     cfg.Database.Port,
+			"database": cfg.Database.Database,
+		})
+
+		// Check the schema is migrated. Migrations are never applied here;
+		// they are applied explicitly with the migrate command.
+		migrationStatus, err := database.GetMigrationStatus(sqlDB, cfg.Database.MigrationsPath)
+		if err != nil {
+			log.Warn(ctx, "failed to check database migrations", map[string]interface{}{
+				"error": err.Error(),
+				"path":  cfg.Database.MigrationsPath,
+			})
+		} else if migrationStatus.Dirty {
+			return fmt.Errorf("database schema is dirty at migration %d: repair it and run 'migrate force'", migrationStatus.Version)
+		} else if len(migrationStatus.Pending) > 0 {
+			log.Warn(ctx, "database has pending migrations, run 'migrate up'", map[string]interface{}{
+				"version": migrationStatus.Version,
+				"pending": len(migrationStatus.Pending),
+			})
+		}
+
+		// Route heavy list and count queries to read replicas, if any.
+		replicaRouter, err := database.ConnectReplicas(db, dbConfig, log)
+		if err != nil {
+			return err
+		}
+		if replicaRouter != nil {
+			replicaRouter.Start(cfg.Database.ReplicaCheckInterval)
+			defer replicaRouter.Stop()
+
+			log.Info(ctx, "read replicas configured", map[string]interface{}{
+				"replicas": len(cfg.Database.Replicas),
+				"max_lag":  cfg.Database.ReplicaMaxLag.String(),
+			})
+		}
 	}
 
-	blobStorage, err := storage.NewBlobStorage(cfg.Storage.Type, storageConfig)
+	// Initialize storage
+	blobStorage, err := storage.NewBlobStorage(cfg.Storage.Type, cfg.Storage.blobStorageConfig())
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -119,34 +230,279 @@ func runServer(cmd *cobra.Command, args []string) error {
 	testRunStore := testrun.NewMySQLStore(db, log)
 	assetStore := testrun.NewMySQLAssetStore(db, log)
 	stepNoteStore := testrun.NewMySQLStepNoteStore(db, log)
+	stepImageStore := testprocedure.NewMySQLStepImageStore(db, log)
+	stepAttachmentStore := testprocedure.NewMySQLStepAttachmentStore(db, log)
+	stepGroupStore := steplibrary.NewMySQLStore(db, log)
+	reviewStore := review.NewMySQLStore(db, log)
+	procedureShareStore := procedureshare.NewMySQLStore(db, log)
+	datasetStore := dataset.NewMySQLStore(db, log)
+	runGroupStore := testrun.NewMySQLRunGroupStore(db, log)
+	commentStore := testrun.NewMySQLCommentStore(db, log)
+	annotationStore := testrun.NewMySQLAnnotationStore(db, log)
+	runTemplateStore := testrun.NewMySQLRunTemplateStore(db, log)
 	endpointStore := endpoint.NewMySQLStore(db, log)
+	endpointHealthStore := endpoint.NewMySQLHealthStore(db, log)
+	// Endpoint secrets share the encryption key of integration credentials.
+	keyring := integration.NewKeyring(integration.DeriveKey(cfg.Integration.EncryptionKey))
+	configSecrets.rotateEncryptionKey(db, keyring, log)
+	endpointSecretStore := endpoint.NewMySQLSecretStore(db, keyring, log)
 	jobStore := job.NewMySQLStore(db, log)
+	jobLogStore := job.NewMySQLLogStore(db, log)
 	apiTokenStore := apitoken.NewMySQLStore(db, log)
 	integrationStore := integration.NewMySQLStore(db, log)
 	scriptStore := scriptgen.NewMySQLStore(db, log)
+	scriptRevisionStore := scriptgen.NewMySQLRevisionStore(db, log)
+	savedViewStore := savedview.NewMySQLStore(db, log)
+	usageStore := metering.NewMySQLStore(db, log)
+	llmUsageStore := llmusage.NewMySQLStore(db, log)
+	projectArchiveStore := projectarchive.NewMySQLStore(db, log)
+	samlIdPStore := saml.NewMySQLStore(db, log)
+	// Slack webhooks also share the encryption key of integration credentials.
+	notificationStore := notification.NewMySQLStore(db, keyring, log)
+	// So do the access tokens of script repositories.
+	scriptRepoStore := scriptrepo.NewMySQLStore(db, keyring, log)
+	// And the tokens of CI pipelines.
+	ciPipelineStore := cipipeline.NewMySQLStore(db, keyring, log)
+	ciBuildStore := cipipeline.NewMySQLBuildStore(db, log)
+	// And the tokens of TestRail and Xray connections. Pushed results link
+	// to their runs under the frontend URL notifications link to.
+	testManagementStore := testmanagement.NewMySQLStore(db, keyring, log)
+	resultLinkStore := testmanagement.NewMySQLLinkStore(db, log)
+	resultPusher := testmanagement.NewPusher(testManagementStore, resultLinkStore, assetStore, cfg.Notifications.BaseURL, log)
+	shareStore := share.NewMySQLStore(db, log)
+	uploadStore := upload.NewMySQLStore(db, log)
+	retentionStore := retention.NewMySQLStore(db, log)
+	analyticsStore := analytics.NewMySQLStore(db, log)
+	activityStore := activity.NewMySQLStore(db, log)
+	labelStore := label.NewMySQLStore(db, log)
+	releaseStore := release.NewMySQLStore(db, log)
+	requirementStore := requirement.NewMySQLStore(db, log)
+	runnerStore := runner.NewMySQLStore(db, log)
+
+	// Initialize the cache of resource owners for authorization checks
+	var ownershipCache ownership.Cache
+	switch cfg.Ownership.Type {
+	case "memory":
+		ownershipCache = ownership.NewMemoryCache(cfg.Ownership.TTL, cfg.Ownership.MaxEntries)
+	case "redis":
+		redisCache := ownership.NewRedisCache(ownership.RedisConfig{
+			Addr:     cfg.Ownership.RedisAddr,
+			Password: cfg.Ownership.RedisPassword,
+			DB:       cfg.Ownership.RedisDB,
+		}, cfg.Ownership.TTL, log)
+		defer redisCache.Close()
+		ownershipCache = redisCache
+	case "none":
+		ownershipCache = ownership.NoopCache{}
+	default:
+		return fmt.Errorf("unsupported ownership cache type: %s", cfg.Ownership.Type)
+	}
+	ownershipResolver := ownership.NewResolver(testRunStore, testProcedureStore, projectStore, ownershipCache)
+	log.Info(ctx, "ownership cache initialized", map[string]interface{}{
+		"type": cfg.Ownership.Type,
+		"ttl":  cfg.Ownership.TTL.String(),
+	})
+
+	// Initialize usage metering for billable actions
+	usageRecorder := metering.NewRecorder(usageStore, projectStore, log)
+
+	// Initialize LLM usage metering and monthly budgets for model calls
+	llmMeter := llmusage.NewMeter(llmUsageStore, projectStore, llmusage.Pricing{
+		InputPerMillion:  cfg.ScriptGen.Pricing.InputPerMillion,
+		OutputPerMillion: cfg.ScriptGen.Pricing.OutputPerMillion,
+	}, log)
+
+	// Initialize run analytics, aggregated as runs complete
+	analyticsRecorder := analytics.NewRecorder(analyticsStore, log)
+
+	// Initialize the activity feed of users, recorded as they act
+	activityRecorder := activity.NewRecorder(activityStore, log)
+
+	// Initialize the per-project storage quota checked on uploads
+	storageQuotas := quota.NewEnforcer(quota.NewMySQLStore(db, log), cfg.Storage.ProjectQuotaBytes, log)
+
+	// Initialize email and Slack notifications
+	notifier, err := newNotifier(ctx, cfg, notificationStore, userStore, log)
+	if err != nil {
+		return err
+	}
+	notifier.Start(cfg.Notifications.Workers)
+	defer notifier.Stop()
+
+	// Initialize background processing of uploaded videos
+	var mediaProcessor *media.Processor
+	if cfg.Media.Enabled {
+		ffmpegPath, err := exec.LookPath(cfg.Media.FFmpegPath)
+		if err != nil {
+			log.Warn(ctx, "ffmpeg not found, video thumbnails and transcodes disabled", map[string]interface{}{
+				"ffmpeg_path": cfg.Media.FFmpegPath,
+			})
+		} else {
+			mediaProcessor = media.NewProcessor(assetStore, blobStorage, media.NewFFmpeg(ffmpegPath, cfg.Media.ThumbnailWidth), media.Config{
+				Formats:   cfg.Media.TranscodeFormats,
+				Timeout:   cfg.Media.Timeout,
+				QueueSize: cfg.Media.QueueSize,
+			}, log)
+			mediaProcessor.Start(cfg.Media.Workers)
+			defer mediaProcessor.Stop()
+			if err := mediaProcessor.Resume(ctx); err != nil {
+				log.Warn(ctx, "failed to resume pending video processing", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			log.Info(ctx, "video processing initialized", map[string]interface{}{
+				"ffmpeg_path": ffmpegPath,
+				"formats":     cfg.Media.TranscodeFormats,
+			})
+		}
+	}
+
+	// Initialize resumable uploads and the removal of abandoned ones
+	uploadManager := upload.NewManager(uploadStore, blobStorage, upload.Config{
+		PartSize:   cfg.Uploads.PartSize,
+		MaxSize:    cfg.Uploads.MaxSize,
+		SessionTTL: cfg.Uploads.SessionTTL,
+	}, log)
+	uploadManager.StartCleanup(cfg.Uploads.CleanupInterval)
+	defer uploadManager.StopCleanup()
+
+	// Initialize the responses kept for retried create requests
+	idempotencyStore := idempotency.NewMySQLStore(db, log)
+	idempotencySweeper := idempotency.NewSweeper(idempotencyStore, log)
+	idempotencySweeper.Start(cfg.Idempotency.CleanupInterval)
+	defer idempotencySweeper.Stop()
+
+	// Initialize the removal of run assets expired by retention policies
+	retentionCleaner := retention.NewCleaner(retentionStore, assetStore, annotationStore, blobStorage, usageRecorder, cfg.Retention.BatchSize, cfg.Retention.DryRun, log)
+	if cfg.Retention.Interval > 0 {
+		retentionCleaner.Start(cfg.Retention.Interval)
+		defer retentionCleaner.Stop()
+		log.Info(ctx, "retention cleanup initialized", map[string]interface{}{
+			"interval": cfg.Retention.Interval.String(),
+			"dry_run":  cfg.Retention.DryRun,
+		})
+	}
+
+	// Initialize circuit breakers for external calls
+	providerBreakers := resilience.NewRegistry(resilience.Config{
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+		OpenDuration:     cfg.Resilience.OpenDuration,
+	})
+	llmBreaker := providerBreakers.Register("llm", resilience.Config{
+		Timeout:          cfg.Resilience.LLMTimeout,
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+		OpenDuration:     cfg.Resilience.OpenDuration,
+	})
+	mcpBreaker := providerBreakers.Register("playwright_mcp", resilience.Config{
+		Timeout:          cfg.Resilience.MCPTimeout,
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+		OpenDuration:     cfg.Resilience.OpenDuration,
+	})
+	integrationBreakers := resilience.NewRegistry(resilience.Config{
+		Timeout:          cfg.Resilience.IssueTrackerTimeout,
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+		OpenDuration:     cfg.Resilience.OpenDuration,
+	})
+
+	// Initialize periodic validation of integration credentials; owners are
+	// warned before tokens expire and once they start being rejected
+	clientFactory := &defaultClientFactory{}
+	credentialChecker := integration.NewChecker(integrationStore, clientFactory, integrationBreakers, keyring, cfg.Integration.ExpiryWarning,
+		func(ctx context.Context, integ *integration.Integration, status integration.CredentialStatus) {
+			notifier.Notify(ctx, notification.IntegrationCredentialsEvent(integ, status, integ.UserID))
+		}, log)
+	if cfg.Integration.CheckInterval > 0 {
+		credentialChecker.Start(cfg.Integration.CheckInterval)
+		defer credentialChecker.Stop()
+		log.Info(ctx, "integration credential checks initialized", map[string]interface{}{
+			"interval":       cfg.Integration.CheckInterval.String(),
+			"expiry_warning": credentialChecker.WarnWithin().String(),
+		})
+	}
+
+	// Initialize periodic checks for runs going over the maximum duration
+	// their procedure expects; their executor and assignee are notified
+	overdueChecker := testrun.NewOverdueChecker(testRunStore, func(ctx context.Context, tr *testrun.TestRun) {
+		recipients := []uuid.UUID{tr.ExecutedBy}
+		if tr.AssignedTo != nil {
+			recipients = append(recipients, *tr.AssignedTo)
+		}
+		procedureName := tr.TestProcedureID.String()
+		if tr.ProcedureSnapshot != nil {
+			procedureName = tr.ProcedureSnapshot.Name
+		}
+		notifier.Notify(ctx, notification.RunOverdueEvent(tr.ID, procedureName, *tr.MaxDurationMinutes, recipients...))
+	}, log)
+	if cfg.Runs.OverdueCheckInterval > 0 {
+		overdueChecker.Start(cfg.Runs.OverdueCheckInterval)
+		defer overdueChecker.Stop()
+		log.Info(ctx, "overdue run checks initialized", map[string]interface{}{
+			"interval": cfg.Runs.OverdueCheckInterval.String(),
+		})
+	}
 
 	// Initialize agent pipeline
-	agentCfg := agent.Config{
-		MaxIterations:       cfg.Agent.MaxIterations,
-		TimeLimit:           cfg.Agent.TimeLimit,
-		BedrockRegion:       cfg.Agent.BedrockRegion,
-		BedrockModel:        cfg.Agent.BedrockModel,
-		BedrockAccessKey:    cfg.Agent.BedrockAccessKey,
-		BedrockSecretKey:    cfg.Agent.BedrockSecretKey,
-		PlaywrightMCPURL:    cfg.Agent.PlaywrightMCPURL,
-		AgentScriptPath:     cfg.Agent.AgentScriptPath,
-		MaxConcurrentWorkers: cfg.Agent.MaxConcurrentWorkers,
+	baselineStore := visualregression.NewMySQLStore(db, log)
+	agentPipeline := newAgentPipeline(cfg, jobStore, jobLogStore, endpointStore, endpointSecretStore, testProcedureStore, testRunStore, stepNoteStore, assetStore, baselineStore, blobStorage, mcpBreaker, usageRecorder, llmMeter, analyticsRecorder, notifier, log)
+
+	// Jobs and script generations run as work of the shutdown coordinator,
+	// which lets them finish when the server stops
+	coordinator := shutdown.NewCoordinator()
+
+	// Jobs are dispatched through the queue when one is configured, so that
+	// dedicated worker nodes can run them
+	jobQueue, err := newJobQueue(ctx, cfg.Queue)
+	if err != nil {
+		return err
+	}
+	if jobQueue != nil {
+		defer jobQueue.Close()
+	}
+
+	// Jobs interrupted by the previous shutdown are run again
+	if _, err := jobStore.RequeueInterrupted(ctx); err != nil {
+		return fmt.Errorf("failed to requeue interrupted jobs: %w", err)
+	}
+
+	workerPool := agent.NewWorkerPool(cfg.Agent.MaxConcurrentWorkers, jobStore, endpointStore, agentPipeline, jobQueue, coordinator, log)
+
+	// Jobs left running by a crashed server stop sending heartbeats; they
+	// are reaped before the workers start and then periodically
+	jobReaper := job.NewReaper(jobStore, jobLogStore, cfg.Jobs.StaleTimeout, job.StaleAction(cfg.Jobs.StaleAction), func(ctx context.Context, jobs []*job.Job) {
+		dispatchJobs(ctx, workerPool, jobs, log)
+	}, log)
+	if _, err := jobReaper.Run(ctx, time.Now()); err != nil {
+		return fmt.Errorf("failed to reap stale jobs: %w", err)
+	}
+	if cfg.Jobs.ReapInterval > 0 {
+		jobReaper.Start(cfg.Jobs.ReapInterval)
+		defer jobReaper.Stop()
+	}
+	log.Info(ctx, "stale job reaper initialized", map[string]interface{}{
+		"stale_timeout": cfg.Jobs.StaleTimeout.String(),
+		"stale_action":  cfg.Jobs.StaleAction,
+		"reap_interval": cfg.Jobs.ReapInterval.String(),
+	})
+
+	// Queued jobs may have been created without reaching the queue, such as
+	// jobs requeued above or created while the queue was unreachable
+	if jobQueue != nil {
+		if err := dispatchCreatedJobs(ctx, jobStore, workerPool, log); err != nil {
+			return err
+		}
 	}
-	agentPipeline := agent.NewPipeline(agentCfg, jobStore, endpointStore, testProcedureStore, blobStorage, log)
 
-	// Initialize and start worker pool
-	workerPool := agent.NewWorkerPool(agentCfg.MaxConcurrentWorkers, jobStore, agentPipeline, log)
 	poolCtx, poolCancel := context.WithCancel(ctx)
 	defer poolCancel()
-	workerPool.Start(poolCtx)
+	if jobQueue == nil || cfg.Queue.ServeWorkers {
+		workerPool.Start(poolCtx)
+	}
 
 	// Initialize script generator based on config provider
 	var scriptGenerator scriptgen.ScriptGenerator
+	var stepSuggester stepsuggest.Suggester
+	var procedureAuthor authoring.Author
+	var guideNarrator narration.Narrator
 	switch cfg.ScriptGen.Provider {
 	case "bedrock":
 		bedrockGen, err := scriptgen.NewBedrockGenerator(
@@ -167,7 +523,40 @@ func runServer(cmd *cobra.Command, args []string) error {
 		}
 		bedrockGen.SetValidationConfig(validationCfg)
 
-		scriptGenerator = bedrockGen
+		scriptGenerator = scriptgen.NewResilientGenerator(bedrockGen, llmBreaker)
+
+		// Step suggestions use the same model, which must accept images
+		bedrockSuggester, err := stepsuggest.NewBedrockSuggester(
+			cfg.ScriptGen.Region,
+			cfg.ScriptGen.ModelID,
+			cfg.ScriptGen.MaxTokens,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to initialize Bedrock step suggester: %w", err)
+		}
+		stepSuggester = stepsuggest.NewResilientSuggester(bedrockSuggester, llmBreaker)
+
+		// Procedures are drafted from descriptions with the same model
+		bedrockAuthor, err := authoring.NewBedrockAuthor(
+			cfg.ScriptGen.Region,
+			cfg.ScriptGen.ModelID,
+			cfg.ScriptGen.MaxTokens,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to initialize Bedrock procedure author: %w", err)
+		}
+		procedureAuthor = authoring.NewResilientAuthor(bedrockAuthor, llmBreaker)
+
+		// Guides are narrated with the same model, when asked for
+		bedrockNarrator, err := narration.NewBedrockNarrator(
+			cfg.ScriptGen.Region,
+			cfg.ScriptGen.ModelID,
+			cfg.ScriptGen.MaxTokens,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to initialize Bedrock guide narrator: %w", err)
+		}
+		guideNarrator = narration.NewResilientNarrator(bedrockNarrator, llmBreaker)
 
 		log.Info(ctx, "script generator initialized", map[string]interface{}{
 			"provider":                "bedrock",
@@ -182,17 +571,33 @@ func runServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unsupported script generator provider: %s", cfg.ScriptGen.Provider)
 	}
 
+	scriptPostProcessor, err := newScriptPostProcessor(cfg.ScriptGen.PostProcessing, log)
+	if err != nil {
+		return err
+	}
+
 	// Initialize session manager
 	sessionManager := session.NewManager(cfg.Session.Duration, log)
 	sessionManager.StartCleanup(5 * time.Minute)
 	defer sessionManager.StopCleanup()
 
+	// Who has procedure drafts open and who holds their edit locks
+	presenceTracker := presence.NewTracker(cfg.Presence.Timeout, cfg.Presence.LockTTL, log)
+	presenceTracker.StartCleanup(time.Minute)
+	defer presenceTracker.StopCleanup()
+
+	// Initialize endpoint health monitoring
+	healthMonitor := endpoint.NewMonitor(endpointStore, endpointHealthStore, cfg.Health.Timeout, cfg.Health.Retention, log)
+	healthMonitor.Start(cfg.Health.PollInterval)
+	defer healthMonitor.Stop()
+
 	log.Info(ctx, "session manager initialized", map[string]interface{}{
 		"duration": cfg.Session.Duration.String(),
 	})
 
 	// Setup router
 	router := mux.NewRouter()
+	router.Use(handlers.ClientIPMiddleware(cfg.Server.TrustedProxies))
 
 	// Serve uploaded static files (local storage only)
 	if cfg.Storage.Type == "local" {
@@ -204,6 +609,12 @@ func runServer(cmd *cobra.Command, args []string) error {
 	// Health check endpoint (public)
 	router.HandleFunc("/health", handlers.HealthHandler).Methods("GET")
 
+	// CSRF protection of session requests; bearer requests are exempt
+	var csrf *handlers.CSRFProtection
+	if cfg.Session.CSRF {
+		csrf = handlers.NewCSRFProtection(cfg.Session.CookieSecret, cfg.Session.CSRFCookieName, cfg.Session.Secure, log)
+	}
+
 	// Auth handlers (public)
 	authHandler := handlers.NewAuthHandler(
 		userStore,
@@ -211,6 +622,11 @@ func runServer(cmd *cobra.Command, args []string) error {
 		cfg.Session.CookieSecret,
 		cfg.Session.CookieName,
 		cfg.Session.Secure,
+		handlers.SSOPolicy{
+			Enforced: cfg.SAML.Enabled && cfg.SAML.EnforceSSO,
+			Admins:   cfg.SAML.AdminEmails,
+		},
+		csrf,
 		log,
 	)
 
@@ -218,28 +634,118 @@ func runServer(cmd *cobra.Command, args []string) error {
 	router.HandleFunc("/api/v1/auth/login", authHandler.Login).Methods("POST")
 	router.HandleFunc("/api/v1/auth/logout", authHandler.Logout).Methods("POST")
 
+	// SAML single sign-on (public); the identity provider is configured
+	// by SSO admins through the protected routes below
+	var samlHandler *handlers.SAMLHandler
+	if cfg.SAML.Enabled {
+		samlHandler = handlers.NewSAMLHandler(
+			samlIdPStore,
+			saml.NewServiceProvider(cfg.SAML.serviceProviderConfig()),
+			authHandler,
+			userStore,
+			handlers.SAMLAttributeMapping{
+				Email:    cfg.SAML.EmailAttribute,
+				Username: cfg.SAML.UsernameAttribute,
+			},
+			log,
+		)
+		router.HandleFunc("/api/v1/auth/saml", samlHandler.Status).Methods("GET")
+		router.HandleFunc(samlMetadataPath, samlHandler.Metadata).Methods("GET")
+		router.HandleFunc("/api/v1/auth/saml/login", samlHandler.Login).Methods("GET")
+		router.HandleFunc(samlACSPath, samlHandler.ACS).Methods("POST")
+		log.Info(ctx, "SAML single sign-on enabled", map[string]interface{}{
+			"entity_id":   cfg.SAML.EntityID,
+			"enforce_sso": cfg.SAML.EnforceSSO,
+		})
+	}
+
 	// Protected user routes
-	userHandler := handlers.NewUserHandler(userStore, log)
+	handoverStore := handover.NewMySQLStore(db, log)
+	userHandler := handlers.NewUserHandler(userStore, handoverStore, sessionManager, apiTokenStore, log)
 	authMiddleware := handlers.NewAuthMiddleware(sessionManager, apiTokenStore, cfg.Session.CookieName, log)
 
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
 	apiRouter.Use(authMiddleware.Handler)
+	if csrf != nil {
+		apiRouter.Use(csrf.Handler)
+	}
+	serviceAccountStore := serviceaccount.NewMySQLStore(db, log)
+	apiRouter.Use(handlers.ServiceAccountMiddleware(serviceAccountStore, log))
+	apiRouter.Use(handlers.NewGuestMiddleware(userStore, log).Handler)
 	apiRouter.Use(handlers.WriteScopeMiddleware)
 
+	// Rate limiting (per user or API token); expensive routes get a tighter
+	// limit on top of the default one
+	apiLimiter := ratelimit.NewLimiter(ratelimit.Rule{
+		RequestsPerMinute: cfg.RateLimit.RequestsPerMinute,
+		Burst:             cfg.RateLimit.Burst,
+	})
+	expensiveLimiter := ratelimit.NewLimiter(ratelimit.Rule{
+		RequestsPerMinute: cfg.RateLimit.ExpensiveRequestsPerMinute,
+		Burst:             cfg.RateLimit.ExpensiveBurst,
+	})
+	apiRouter.Use(handlers.RateLimitMiddleware(apiLimiter, log))
+	expensiveRateLimit := handlers.RateLimitMiddleware(expensiveLimiter, log)
+
+	// Create routes replay their response to retries sent with the same
+	// Idempotency-Key; replays do not count against the expensive limit.
+	idempotent := handlers.NewIdempotency(idempotencyStore, cfg.Idempotency.TTL, log).Handler
+
 	// Session validation endpoint (protected by AuthMiddleware)
 	apiRouter.HandleFunc("/auth/me", authHandler.GetMe).Methods("GET")
 
+	// Session management (protected)
+	sessionHandler := handlers.NewSessionHandler(sessionManager, log)
+	apiRouter.Handle("/auth/sessions", handlers.RejectServiceAccounts(http.HandlerFunc(sessionHandler.List))).Methods("GET")
+	apiRouter.Handle("/auth/sessions", handlers.RejectServiceAccounts(http.HandlerFunc(sessionHandler.RevokeOthers))).Methods("DELETE")
+	apiRouter.Handle("/auth/sessions/{session_id}", handlers.RejectServiceAccounts(http.HandlerFunc(sessionHandler.Revoke))).Methods("DELETE")
+
+	// SAML identity provider configuration (SSO admins only)
+	if samlHandler != nil {
+		apiRouter.HandleFunc("/auth/saml/idp", samlHandler.GetIdentityProvider).Methods("GET")
+		apiRouter.HandleFunc("/auth/saml/idp", samlHandler.UploadMetadata).Methods("PUT")
+		apiRouter.HandleFunc("/auth/saml/idp", samlHandler.DeleteIdentityProvider).Methods("DELETE")
+	}
+
+	// External provider health (protected)
+	providerHealthHandler := handlers.NewProviderHealthHandler(providerBreakers)
+	apiRouter.HandleFunc("/health/providers", providerHealthHandler.List).Methods("GET")
+
 	apiRouter.HandleFunc("/users", userHandler.List).Methods("GET")
 	apiRouter.HandleFunc("/users/{id}", userHandler.GetByID).Methods("GET")
-	apiRouter.HandleFunc("/users/{id}", userHandler.Update).Methods("PUT")
-	apiRouter.HandleFunc("/users/{id}", userHandler.Delete).Methods("DELETE")
+	// Service accounts are managed by admins, not through their own user
+	apiRouter.Handle("/users/{id}", handlers.RejectServiceAccounts(http.HandlerFunc(userHandler.Update))).Methods("PUT")
+	apiRouter.Handle("/users/{id}", handlers.RejectServiceAccounts(http.HandlerFunc(userHandler.Delete))).Methods("DELETE")
+
+	// User activity feeds (protected)
+	activityHandler := handlers.NewActivityHandler(activityStore, userStore, log)
+	apiRouter.HandleFunc("/users/{id}/activity", activityHandler.ListByUser).Methods("GET")
+
+	// Admin routes (platform admins only)
+	adminHandler := handlers.NewAdminHandler(userStore, projectStore, handoverStore, sessionManager, apiTokenStore, ownershipResolver, log)
+	adminRouter := apiRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(handlers.NewAdminMiddleware(userStore, log).Handler)
+	adminRouter.HandleFunc("/users", adminHandler.ListUsers).Methods("GET")
+	adminRouter.HandleFunc("/users/{id}", adminHandler.GetUser).Methods("GET")
+	adminRouter.HandleFunc("/users/{id}/deactivate", adminHandler.DeactivateUser).Methods("POST")
+	adminRouter.HandleFunc("/users/{id}/reactivate", adminHandler.ReactivateUser).Methods("POST")
+	adminRouter.HandleFunc("/users/{id}/password", adminHandler.ResetPassword).Methods("POST")
+	adminRouter.HandleFunc("/users/{id}/role", adminHandler.SetRole).Methods("PUT")
+	adminRouter.HandleFunc("/users/{id}/transfer", adminHandler.TransferHoldings).Methods("POST")
+	adminRouter.HandleFunc("/projects", adminHandler.ListProjects).Methods("GET")
+	adminRouter.HandleFunc("/projects/{id}/transfer", adminHandler.TransferProject).Methods("POST")
 
 	// Project routes (protected)
-	projectHandler := handlers.NewProjectHandler(projectStore, log)
+	projectHandler := handlers.NewProjectHandler(projectStore, integrationStore, ownershipResolver, log)
 	projectAuth := handlers.NewProjectAuthorizationMiddleware(projectStore, log)
 
 	apiRouter.HandleFunc("/projects", projectHandler.List).Methods("GET")
-	apiRouter.HandleFunc("/projects", projectHandler.Create).Methods("POST")
+	apiRouter.Handle("/projects", handlers.RejectServiceAccounts(http.HandlerFunc(projectHandler.Create))).Methods("POST")
+
+	// Project export and import; import is registered before the
+	// project-specific routes so "import" is not taken as a project ID
+	projectArchiveHandler := handlers.NewProjectArchiveHandler(projectArchiveStore, blobStorage, storageQuotas, log)
+	apiRouter.Handle("/projects/import", handlers.RejectServiceAccounts(http.HandlerFunc(projectArchiveHandler.Import))).Methods("POST")
 
 	// Project-specific routes with authorization
 	projectRouter := apiRouter.PathPrefix("/projects/{id}").Subrouter()
@@ -247,26 +753,88 @@ func runServer(cmd *cobra.Command, args []string) error {
 	projectRouter.HandleFunc("", projectHandler.GetByID).Methods("GET")
 	projectRouter.HandleFunc("", projectHandler.Update).Methods("PUT")
 	projectRouter.HandleFunc("", projectHandler.Delete).Methods("DELETE")
+	projectRouter.HandleFunc("/export", projectArchiveHandler.Export).Methods("GET")
 
 	// Test Procedure routes (protected by project authorization)
-	testProcedureHandler := handlers.NewTestProcedureHandler(testProcedureStore, projectStore, blobStorage, log)
+	testProcedureHandler := handlers.NewTestProcedureHandler(testProcedureStore, stepImageStore, stepAttachmentStore, stepGroupStore, ownershipResolver, procedureShareStore, blobStorage, storageQuotas, labelStore, activityRecorder, presenceTracker, log)
 
 	// List and create procedures for a project
 	apiRouter.HandleFunc("/projects/{project_id}/procedures", testProcedureHandler.List).Methods("GET")
 	apiRouter.HandleFunc("/projects/{project_id}/procedures", testProcedureHandler.Create).Methods("POST")
 
+	// Draft a procedure from a plain-English description of a flow
+	procedureAuthoringHandler := handlers.NewProcedureAuthoringHandler(projectStore, endpointStore, testProcedureStore, procedureAuthor, testprocedure.ValidationLimits{
+		MaxNameLength:        cfg.ScriptGen.Validation.MaxNameLength,
+		MaxDescriptionLength: cfg.ScriptGen.Validation.MaxDescriptionLength,
+		MaxStepsJSONLength:   cfg.ScriptGen.Validation.MaxStepsJSONLength,
+		MaxStepsCount:        cfg.ScriptGen.Validation.MaxStepsCount,
+	}, llmMeter, cfg.Resilience.LLMTimeout, log)
+	apiRouter.Handle("/projects/{project_id}/procedures/generate", expensiveRateLimit(http.HandlerFunc(procedureAuthoringHandler.Generate))).Methods("POST")
+
 	// Individual procedure operations
 	apiRouter.HandleFunc("/projects/{project_id}/procedures/{id}", testProcedureHandler.GetByID).Methods("GET")
 	apiRouter.HandleFunc("/projects/{project_id}/procedures/{id}", testProcedureHandler.Update).Methods("PUT")
 	apiRouter.HandleFunc("/projects/{project_id}/procedures/{id}", testProcedureHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/projects/{project_id}/procedures/{id}/archive", testProcedureHandler.Archive).Methods("POST")
+	apiRouter.HandleFunc("/projects/{project_id}/procedures/{id}/unarchive", testProcedureHandler.Unarchive).Methods("POST")
+
+	// Markdown previews for procedure descriptions and step instructions
+	markdownHandler := handlers.NewMarkdownHandler(log)
+	apiRouter.HandleFunc("/markdown/preview", markdownHandler.Preview).Methods("POST")
 
 	// Image uploads for steps
 	apiRouter.HandleFunc("/procedures/{id}/steps/images", testProcedureHandler.UploadStepImage).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{id}/steps/attachments", testProcedureHandler.UploadStepAttachment).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{id}/steps/{step_index}/attachments/{attachment_index}", testProcedureHandler.DownloadStepAttachment).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{id}/steps/{step_index}/attachments/{attachment_index}/stream", testProcedureHandler.StreamStepRecording).Methods("GET")
+
+	// Step suggestions from a screenshot of the page under test
+	suggestionCapturer := visualregression.NewScriptCapturer(cfg.Agent.VisualCaptureScriptPath, cfg.Agent.PlaywrightMCPURL)
+	stepSuggestionHandler := handlers.NewStepSuggestionHandler(testProcedureStore, endpointStore, testProcedureHandler, stepSuggester, suggestionCapturer, llmMeter, cfg.Resilience.LLMTimeout+cfg.Resilience.MCPTimeout, log)
+	apiRouter.HandleFunc("/procedures/{id}/steps/suggest", stepSuggestionHandler.Suggest).Methods("POST")
 
 	// Draft operations
 	apiRouter.HandleFunc("/procedures/{id}/diff", testProcedureHandler.GetDiff).Methods("GET")
 	apiRouter.HandleFunc("/procedures/{id}/draft/reset", testProcedureHandler.ResetDraft).Methods("POST")
-	apiRouter.HandleFunc("/procedures/{id}/draft/commit", testProcedureHandler.CommitDraft).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{id}/draft/snapshots", testProcedureHandler.ListDraftSnapshots).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{id}/draft/snapshots/{snapshot_id}/restore", testProcedureHandler.RestoreDraftSnapshot).Methods("POST")
+
+	// Procedure reviews: drafts of projects that require them are committed
+	// as pending versions, published once another user approves them
+	reviewHandler := handlers.NewReviewHandler(reviewStore, testProcedureStore, projectStore, userStore, testProcedureHandler, log)
+	apiRouter.HandleFunc("/procedures/{id}/draft/commit", reviewHandler.CommitDraft).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{id}/reviews", reviewHandler.ListByProcedure).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{id}/reviews", reviewHandler.RequestReview).Methods("POST")
+	apiRouter.HandleFunc("/reviews", reviewHandler.ListMine).Methods("GET")
+	apiRouter.HandleFunc("/reviews/{review_id}", reviewHandler.GetByID).Methods("GET")
+	apiRouter.Handle("/reviews/{review_id}/approve", handlers.RejectServiceAccounts(http.HandlerFunc(reviewHandler.Approve))).Methods("POST")
+	apiRouter.Handle("/reviews/{review_id}/reject", handlers.RejectServiceAccounts(http.HandlerFunc(reviewHandler.Reject))).Methods("POST")
+	apiRouter.HandleFunc("/reviews/{review_id}/withdraw", reviewHandler.Withdraw).Methods("POST")
+
+	// Sharing single procedures with users outside their project
+	procedureShareHandler := handlers.NewProcedureShareHandler(procedureShareStore, testProcedureStore, userStore, testProcedureHandler, log)
+	apiRouter.HandleFunc("/procedures/shared", procedureShareHandler.ListMine).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{id}/shares", procedureShareHandler.List).Methods("GET")
+	apiRouter.Handle("/procedures/{id}/shares/{user_id}", handlers.RejectServiceAccounts(http.HandlerFunc(procedureShareHandler.Save))).Methods("PUT")
+	apiRouter.Handle("/procedures/{id}/shares/{user_id}", handlers.RejectServiceAccounts(http.HandlerFunc(procedureShareHandler.Delete))).Methods("DELETE")
+
+	// Draft presence and edit locks
+	draftPresenceHandler := handlers.NewDraftPresenceHandler(presenceTracker, testProcedureHandler, userStore, log)
+	apiRouter.HandleFunc("/procedures/{id}/draft/presence", draftPresenceHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{id}/draft/presence", draftPresenceHandler.Heartbeat).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{id}/draft/presence", draftPresenceHandler.Leave).Methods("DELETE")
+	apiRouter.HandleFunc("/procedures/{id}/draft/lock", draftPresenceHandler.AcquireLock).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{id}/draft/lock", draftPresenceHandler.ReleaseLock).Methods("DELETE")
+
+	// Step library: step groups shared by the procedures of a project
+	stepGroupHandler := handlers.NewStepGroupHandler(stepGroupStore, testProcedureStore, projectStore, testProcedureHandler, log)
+	apiRouter.HandleFunc("/projects/{project_id}/step-groups", stepGroupHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/projects/{project_id}/step-groups", stepGroupHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/projects/{project_id}/step-groups/{group_id}", stepGroupHandler.GetByID).Methods("GET")
+	apiRouter.HandleFunc("/projects/{project_id}/step-groups/{group_id}", stepGroupHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/projects/{project_id}/step-groups/{group_id}", stepGroupHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/projects/{project_id}/step-groups/{group_id}/procedures", stepGroupHandler.ListProcedures).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{id}/step-groups", stepGroupHandler.ProcedureReferences).Methods("GET")
 
 	// Export operations
 	apiRouter.HandleFunc("/procedures/{id}/export/markdown", testProcedureHandler.ExportMarkdown).Methods("GET")
@@ -275,62 +843,209 @@ func runServer(cmd *cobra.Command, args []string) error {
 	apiRouter.HandleFunc("/projects/{project_id}/procedures/{id}/versions", testProcedureHandler.CreateVersion).Methods("POST")
 	apiRouter.HandleFunc("/projects/{project_id}/procedures/{id}/versions", testProcedureHandler.GetVersionHistory).Methods("GET")
 
+	// Saved view routes (protected by handler-level project authorization)
+	savedViewHandler := handlers.NewSavedViewHandler(savedViewStore, projectStore, log)
+	apiRouter.HandleFunc("/projects/{project_id}/views", savedViewHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/projects/{project_id}/views", savedViewHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/projects/{project_id}/views/{view_id}", savedViewHandler.GetByID).Methods("GET")
+	apiRouter.HandleFunc("/projects/{project_id}/views/{view_id}", savedViewHandler.Update).Methods("PUT")
+	apiRouter.HandleFunc("/projects/{project_id}/views/{view_id}", savedViewHandler.Delete).Methods("DELETE")
+
 	// Test Run routes (protected)
-	testRunHandler := handlers.NewTestRunHandler(testRunStore, assetStore, testProcedureStore, projectStore, stepNoteStore, userStore, blobStorage, log)
+	testRunHandler := handlers.NewTestRunHandler(testRunStore, assetStore, testProcedureStore, stepGroupStore, ownershipResolver, endpointStore, stepNoteStore, annotationStore, runTemplateStore, userStore, blobStorage, usageRecorder, analyticsRecorder, notifier, mediaProcessor, storageQuotas, labelStore, guideNarrator, cfg.Resilience.LLMTimeout, llmMeter, resultPusher, activityRecorder, log)
 
-	// List and create runs for a procedure
-	apiRouter.HandleFunc("/procedures/{procedure_id}/runs", testRunHandler.List).Methods("GET")
-	apiRouter.HandleFunc("/procedures/{procedure_id}/runs", testRunHandler.Create).Methods("POST")
+	// Runs and their sub-resources are authorized once per request by
+	// resolving the project of the run, or procedure, in the URL
+	runAuth := handlers.NewRunAuthorizationMiddleware(ownershipResolver, testRunStore, log)
 
-	// Individual run operations
-	apiRouter.HandleFunc("/runs/{run_id}", testRunHandler.GetByID).Methods("GET")
-	apiRouter.HandleFunc("/runs/{run_id}", testRunHandler.Update).Methods("PUT")
-	apiRouter.HandleFunc("/runs/{run_id}/start", testRunHandler.Start).Methods("POST")
-	apiRouter.HandleFunc("/runs/{run_id}/complete", testRunHandler.Complete).Methods("POST")
+	// List and create runs for a procedure
+	apiRouter.Handle("/procedures/{procedure_id}/runs", runAuth.Handler(http.HandlerFunc(testRunHandler.List))).Methods("GET")
+	apiRouter.Handle("/procedures/{procedure_id}/runs", runAuth.Handler(idempotent(http.HandlerFunc(testRunHandler.Create)))).Methods("POST")
+	apiRouter.Handle("/procedures/{procedure_id}/runs/{run_id}/junit", runAuth.Handler(http.HandlerFunc(testRunHandler.JUnit))).Methods("GET")
+
+	// Datasets of a procedure and the run groups launched from them
+	datasetHandler := handlers.NewDatasetHandler(datasetStore, testProcedureHandler, log)
+	runGroupHandler := handlers.NewRunGroupHandler(runGroupStore, datasetStore, testProcedureStore, endpointStore, testProcedureHandler, testRunHandler, log)
+	apiRouter.HandleFunc("/procedures/{procedure_id}/datasets", datasetHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/datasets", datasetHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/datasets/{dataset_id}", datasetHandler.GetByID).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/datasets/{dataset_id}", datasetHandler.Delete).Methods("DELETE")
+	apiRouter.Handle("/procedures/{procedure_id}/datasets/{dataset_id}/runs", idempotent(http.HandlerFunc(runGroupHandler.Launch))).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/run-groups", runGroupHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/run-groups/{group_id}", runGroupHandler.GetByID).Methods("GET")
+	apiRouter.HandleFunc("/run-groups/{group_id}/report", runGroupHandler.Report).Methods("GET")
+
+	// Run templates: what new runs of a procedure start with
+	runTemplateHandler := handlers.NewRunTemplateHandler(runTemplateStore, testProcedureHandler, log)
+	apiRouter.HandleFunc("/procedures/{procedure_id}/run-template", runTemplateHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/run-template", runTemplateHandler.Save).Methods("PUT")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/run-template", runTemplateHandler.Delete).Methods("DELETE")
+
+	// Run comparison, registered before /runs/{run_id} so "compare" is not
+	// taken for a run ID
+	apiRouter.HandleFunc("/runs/compare", testRunHandler.Compare).Methods("GET")
+	apiRouter.HandleFunc("/runs/report", testRunHandler.Report).Methods("GET")
+
+	// The caller's queue of assigned runs, also registered before
+	// /runs/{run_id}
+	apiRouter.HandleFunc("/runs/queue", testRunHandler.Queue).Methods("GET")
+
+	// Individual run operations and the run routes of other handlers below,
+	// with run authorization
+	runRouter := apiRouter.PathPrefix("/runs/{run_id}").Subrouter()
+	runRouter.Use(runAuth.Handler)
+	runRouter.HandleFunc("", testRunHandler.GetByID).Methods("GET")
+	runRouter.HandleFunc("", testRunHandler.Update).Methods("PUT")
+	runRouter.HandleFunc("/start", testRunHandler.Start).Methods("POST")
+	runRouter.HandleFunc("/complete", testRunHandler.Complete).Methods("POST")
+	runRouter.HandleFunc("/claim", testRunHandler.Claim).Methods("POST")
+	runRouter.HandleFunc("/pause", testRunHandler.Pause).Methods("POST")
+	runRouter.HandleFunc("/resume", testRunHandler.Resume).Methods("POST")
+	runRouter.HandleFunc("/block", testRunHandler.Block).Methods("POST")
+	runRouter.HandleFunc("/unblock", testRunHandler.Unblock).Methods("POST")
 
 	// Guide generation
-	apiRouter.HandleFunc("/runs/{run_id}/guide", testRunHandler.GenerateGuide).Methods("GET")
+	runRouter.Handle("/guide", expensiveRateLimit(http.HandlerFunc(testRunHandler.GenerateGuide))).Methods("GET")
 
 	// Asset operations
-	apiRouter.HandleFunc("/runs/{run_id}/assets", testRunHandler.UploadAsset).Methods("POST")
-	apiRouter.HandleFunc("/runs/{run_id}/assets", testRunHandler.ListAssets).Methods("GET")
-	apiRouter.HandleFunc("/runs/{run_id}/assets/{asset_id}", testRunHandler.DownloadAsset).Methods("GET")
-	apiRouter.HandleFunc("/runs/{run_id}/assets/{asset_id}", testRunHandler.DeleteAsset).Methods("DELETE")
+	runRouter.HandleFunc("/assets", testRunHandler.UploadAsset).Methods("POST")
+	runRouter.HandleFunc("/assets", testRunHandler.ListAssets).Methods("GET")
+	runRouter.HandleFunc("/assets/{asset_id}", testRunHandler.DownloadAsset).Methods("GET")
+	runRouter.HandleFunc("/assets/{asset_id}", testRunHandler.DeleteAsset).Methods("DELETE")
+	runRouter.HandleFunc("/assets/{asset_id}/annotations", testRunHandler.GetAnnotations).Methods("GET")
+	runRouter.HandleFunc("/assets/{asset_id}/annotations", testRunHandler.SetAnnotations).Methods("PUT")
 
 	// Procedure for a run
-	apiRouter.HandleFunc("/runs/{run_id}/procedure", testRunHandler.GetRunProcedure).Methods("GET")
+	runRouter.HandleFunc("/procedure", testRunHandler.GetRunProcedure).Methods("GET")
 
 	// Step notes
-	apiRouter.HandleFunc("/runs/{run_id}/steps/notes", testRunHandler.GetStepNotes).Methods("GET")
-	apiRouter.HandleFunc("/runs/{run_id}/steps/{step_index}/notes", testRunHandler.SetStepNote).Methods("PUT")
+	runRouter.HandleFunc("/steps/notes", testRunHandler.GetStepNotes).Methods("GET")
+	runRouter.HandleFunc("/steps/{step_index}/notes", testRunHandler.SetStepNote).Methods("PUT")
+
+	// Run discussion, also open to the run's executor and assignee
+	commentHandler := handlers.NewCommentHandler(commentStore, testRunStore, testProcedureStore, userStore, notifier, log)
+	discussionRouter := apiRouter.PathPrefix("/runs/{run_id}/comments").Subrouter()
+	discussionRouter.Use(runAuth.Participants)
+	discussionRouter.HandleFunc("", commentHandler.List).Methods("GET")
+	discussionRouter.HandleFunc("", commentHandler.Create).Methods("POST")
+	discussionRouter.HandleFunc("/{comment_id}", commentHandler.Update).Methods("PUT")
+	discussionRouter.HandleFunc("/{comment_id}", commentHandler.Delete).Methods("DELETE")
+
+	// CI pipelines of a project (owner-only via projectRouter) and the
+	// builds runs trigger
+	ciPipelineHandler := handlers.NewCIPipelineHandler(ciPipelineStore, ciBuildStore, testRunStore, testProcedureStore, log)
+	projectRouter.HandleFunc("/ci-pipelines", ciPipelineHandler.List).Methods("GET")
+	projectRouter.HandleFunc("/ci-pipelines", ciPipelineHandler.Create).Methods("POST")
+	projectRouter.HandleFunc("/ci-pipelines/{pipeline_id}", ciPipelineHandler.Update).Methods("PUT")
+	projectRouter.HandleFunc("/ci-pipelines/{pipeline_id}", ciPipelineHandler.Delete).Methods("DELETE")
+	runRouter.HandleFunc("/ci-builds", ciPipelineHandler.ListBuilds).Methods("GET")
+	runRouter.HandleFunc("/ci-builds", ciPipelineHandler.Trigger).Methods("POST")
+
+	// TestRail or Xray connection of a project (owner-only via
+	// projectRouter) and the result links of its runs
+	testManagementHandler := handlers.NewTestManagementHandler(testManagementStore, resultLinkStore, testRunStore, resultPusher, log)
+	projectRouter.HandleFunc("/test-management", testManagementHandler.GetConnection).Methods("GET")
+	projectRouter.HandleFunc("/test-management", testManagementHandler.SaveConnection).Methods("PUT")
+	projectRouter.HandleFunc("/test-management", testManagementHandler.DeleteConnection).Methods("DELETE")
+	runRouter.HandleFunc("/result-links", testManagementHandler.ListLinks).Methods("GET")
+	runRouter.HandleFunc("/result-links", testManagementHandler.CreateLink).Methods("POST")
+	runRouter.HandleFunc("/result-links/{link_id}", testManagementHandler.DeleteLink).Methods("DELETE")
+	runRouter.HandleFunc("/result-links/{link_id}/push", testManagementHandler.PushLink).Methods("POST")
+
+	// Share links of runs (owner-only) and the read-only views they open
+	// (public; authorized by the link's token)
+	shareHandler := handlers.NewShareHandler(shareStore, testRunHandler, cfg.Notifications.BaseURL, log)
+	runRouter.HandleFunc("/shares", shareHandler.List).Methods("GET")
+	runRouter.HandleFunc("/shares", shareHandler.Create).Methods("POST")
+	runRouter.HandleFunc("/shares/{share_id}", shareHandler.Revoke).Methods("DELETE")
+	router.HandleFunc("/api/v1/shared/{token}", shareHandler.GetShared).Methods("GET")
+	router.HandleFunc("/api/v1/shared/{token}/guide", shareHandler.GetSharedGuide).Methods("GET")
+	router.HandleFunc("/api/v1/shared/{token}/assets/{asset_id}", shareHandler.GetSharedAsset).Methods("GET")
+
+	// Resumable uploads of run assets and step images
+	uploadHandler := handlers.NewUploadHandler(uploadManager, testRunHandler, testProcedureHandler, log)
+	apiRouter.HandleFunc("/uploads", uploadHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/uploads/{upload_id}", uploadHandler.Get).Methods("GET")
+	apiRouter.HandleFunc("/uploads/{upload_id}", uploadHandler.Abort).Methods("DELETE")
+	apiRouter.HandleFunc("/uploads/{upload_id}/parts/{part_number}", uploadHandler.PutPart).Methods("PUT")
+	apiRouter.HandleFunc("/uploads/{upload_id}/complete", uploadHandler.Complete).Methods("POST")
 
 	// Endpoint routes (protected)
-	endpointHandler := handlers.NewEndpointHandler(endpointStore, log)
+	endpointHandler := handlers.NewEndpointHandler(endpointStore, endpointHealthStore, endpointSecretStore, healthMonitor, log)
 	apiRouter.HandleFunc("/endpoints", endpointHandler.List).Methods("GET")
 	apiRouter.HandleFunc("/endpoints", endpointHandler.Create).Methods("POST")
 	apiRouter.HandleFunc("/endpoints/{id}", endpointHandler.GetByID).Methods("GET")
 	apiRouter.HandleFunc("/endpoints/{id}", endpointHandler.Update).Methods("PUT")
 	apiRouter.HandleFunc("/endpoints/{id}", endpointHandler.Delete).Methods("DELETE")
+	apiRouter.HandleFunc("/endpoints/{id}/health", endpointHandler.GetHealth).Methods("GET")
+	apiRouter.HandleFunc("/endpoints/{id}/health/check", endpointHandler.CheckHealth).Methods("POST")
+	apiRouter.HandleFunc("/endpoints/{id}/secrets", endpointHandler.ListSecrets).Methods("GET")
+	apiRouter.HandleFunc("/endpoints/{id}/secrets/{key}", endpointHandler.SetSecret).Methods("PUT")
+	apiRouter.HandleFunc("/endpoints/{id}/secrets/{key}", endpointHandler.DeleteSecret).Methods("DELETE")
+	apiRouter.HandleFunc("/endpoint-groups", endpointHandler.ListGroups).Methods("GET")
 
 	// Job routes (protected)
-	jobHandler := handlers.NewJobHandler(jobStore, endpointStore, projectStore, workerPool, agentPipeline, log)
+	explorationConverter := exploration.NewConverter(jobStore, testProcedureStore, log)
+	jobHandler := handlers.NewJobHandler(jobStore, jobLogStore, endpointStore, projectStore, testProcedureStore, workerPool, agentPipeline, explorationConverter, llmMeter, log)
 	apiRouter.HandleFunc("/jobs", jobHandler.List).Methods("GET")
-	apiRouter.HandleFunc("/jobs", jobHandler.Create).Methods("POST")
+	apiRouter.Handle("/jobs", idempotent(expensiveRateLimit(http.HandlerFunc(jobHandler.Create)))).Methods("POST")
 	apiRouter.HandleFunc("/jobs/{id}", jobHandler.GetByID).Methods("GET")
+	apiRouter.HandleFunc("/jobs/{id}/logs", jobHandler.Logs).Methods("GET")
 	apiRouter.HandleFunc("/jobs/{id}/stop", jobHandler.Stop).Methods("POST")
-
-	// API Token routes (protected)
+	apiRouter.HandleFunc("/jobs/{id}/convert-to-procedures", jobHandler.ConvertToProcedures).Methods("POST")
+
+	// Agent runner routes (protected)
+	runnerHandler := handlers.NewRunnerHandler(runnerStore, jobStore, jobLogStore, agentPipeline, cfg.Agent.TimeLimit, log)
+	apiRouter.HandleFunc("/runner/runners", runnerHandler.Register).Methods("POST")
+	apiRouter.HandleFunc("/runner/runners", runnerHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/runner/runners/{id}/heartbeat", runnerHandler.RunnerHeartbeat).Methods("POST")
+	apiRouter.HandleFunc("/runner/runners/{id}", runnerHandler.Deregister).Methods("DELETE")
+	apiRouter.HandleFunc("/runner/jobs/claim", runnerHandler.Claim).Methods("POST")
+	apiRouter.HandleFunc("/runner/jobs/{id}/heartbeat", runnerHandler.Heartbeat).Methods("POST")
+	apiRouter.HandleFunc("/runner/jobs/{id}/logs", runnerHandler.Logs).Methods("POST")
+	apiRouter.HandleFunc("/runner/jobs/{id}/result", runnerHandler.Result).Methods("POST")
+	apiRouter.HandleFunc("/runner/jobs/{id}/fail", runnerHandler.Fail).Methods("POST")
+
+	// Visual regression routes (protected)
+	baselineApprover := visualregression.NewApprover(jobStore, baselineStore, blobStorage, usageRecorder, log)
+	visualHandler := handlers.NewVisualRegressionHandler(baselineStore, jobStore, projectStore, baselineApprover, blobStorage, usageRecorder, log)
+	apiRouter.HandleFunc("/jobs/{id}/baselines", visualHandler.ApproveBaselines).Methods("POST")
+	apiRouter.HandleFunc("/jobs/{id}/pages/{page}/{kind}", visualHandler.GetPageImage).Methods("GET")
+	apiRouter.HandleFunc("/projects/{project_id}/baselines", visualHandler.ListBaselines).Methods("GET")
+	apiRouter.HandleFunc("/projects/{project_id}/baselines/{baseline_id}", visualHandler.DeleteBaseline).Methods("DELETE")
+	apiRouter.HandleFunc("/projects/{project_id}/baselines/{baseline_id}/image", visualHandler.GetBaselineImage).Methods("GET")
+
+	// Link check report routes (protected)
+	linkCheckHandler := handlers.NewLinkCheckHandler(jobStore, assetStore, blobStorage, usageRecorder, log)
+	apiRouter.HandleFunc("/jobs/{id}/link-report", linkCheckHandler.ExportReport).Methods("GET")
+	runRouter.HandleFunc("/link-reports", linkCheckHandler.AttachReport).Methods("POST")
+
+	// API Token routes (protected); service accounts' tokens are managed
+	// by admins
 	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenStore, log)
-	apiRouter.HandleFunc("/tokens", apiTokenHandler.List).Methods("GET")
-	apiRouter.HandleFunc("/tokens", apiTokenHandler.Create).Methods("POST")
-	apiRouter.HandleFunc("/tokens/{token_id}", apiTokenHandler.Revoke).Methods("DELETE")
+	tokenRouter := apiRouter.PathPrefix("/tokens").Subrouter()
+	tokenRouter.Use(handlers.RejectServiceAccounts)
+	tokenRouter.HandleFunc("", apiTokenHandler.List).Methods("GET")
+	tokenRouter.HandleFunc("", apiTokenHandler.Create).Methods("POST")
+	tokenRouter.HandleFunc("/{token_id}", apiTokenHandler.Revoke).Methods("DELETE")
+	tokenRouter.HandleFunc("/{token_id}/allowed-cidrs", apiTokenHandler.SetAllowedCIDRs).Methods("PUT")
+
+	// Service accounts (platform admins only)
+	serviceAccountHandler := handlers.NewServiceAccountHandler(serviceAccountStore, projectStore, apiTokenStore, apiTokenHandler, log)
+	adminRouter.HandleFunc("/service-accounts", serviceAccountHandler.List).Methods("GET")
+	adminRouter.HandleFunc("/service-accounts", serviceAccountHandler.Create).Methods("POST")
+	adminRouter.HandleFunc("/service-accounts/{id}", serviceAccountHandler.GetByID).Methods("GET")
+	adminRouter.HandleFunc("/service-accounts/{id}", serviceAccountHandler.Delete).Methods("DELETE")
+	adminRouter.HandleFunc("/service-accounts/{id}/grants/{project_id}", serviceAccountHandler.GrantProject).Methods("PUT")
+	adminRouter.HandleFunc("/service-accounts/{id}/grants/{project_id}", serviceAccountHandler.UngrantProject).Methods("DELETE")
+	adminRouter.HandleFunc("/service-accounts/{id}/tokens", serviceAccountHandler.ListTokens).Methods("GET")
+	adminRouter.HandleFunc("/service-accounts/{id}/tokens", serviceAccountHandler.CreateToken).Methods("POST")
+	adminRouter.HandleFunc("/service-accounts/{id}/tokens/{token_id}", serviceAccountHandler.RevokeToken).Methods("DELETE")
 
 	// Integration routes (protected)
-	encryptionKey := integration.DeriveKey(cfg.Integration.EncryptionKey)
-	clientFactory := &defaultClientFactory{}
 	integrationHandler := handlers.NewIntegrationHandler(
-		integrationStore, clientFactory, encryptionKey,
-		testRunStore, testProcedureStore, projectStore, log,
+		integrationStore, clientFactory, clientFactory, integrationBreakers, credentialChecker, keyring,
+		projectStore, activityRecorder, log,
 	)
 
 	apiRouter.HandleFunc("/integrations", integrationHandler.ListIntegrations).Methods("GET")
@@ -339,81 +1054,391 @@ func runServer(cmd *cobra.Command, args []string) error {
 	apiRouter.HandleFunc("/integrations/{integration_id}", integrationHandler.UpdateIntegration).Methods("PUT")
 	apiRouter.HandleFunc("/integrations/{integration_id}", integrationHandler.DeleteIntegration).Methods("DELETE")
 	apiRouter.HandleFunc("/integrations/{integration_id}/test", integrationHandler.TestConnection).Methods("POST")
+	apiRouter.HandleFunc("/integrations/{integration_id}/health", integrationHandler.GetIntegrationHealth).Methods("GET")
 	apiRouter.HandleFunc("/integrations/{integration_id}/issues", integrationHandler.SearchExternalIssues).Methods("GET")
 
+	// Issue tracker webhooks (public; authenticated by the delivery signature)
+	router.HandleFunc("/api/v1/integrations/{integration_id}/webhook", integrationHandler.ReceiveWebhook).Methods("POST")
+
 	// Issue link routes (protected)
-	apiRouter.HandleFunc("/runs/{run_id}/issues", integrationHandler.ListIssueLinks).Methods("GET")
-	apiRouter.HandleFunc("/runs/{run_id}/issues", integrationHandler.CreateAndLinkIssue).Methods("POST")
-	apiRouter.HandleFunc("/runs/{run_id}/issues/link", integrationHandler.LinkExistingIssue).Methods("POST")
-	apiRouter.HandleFunc("/runs/{run_id}/issues/{link_id}", integrationHandler.UnlinkIssue).Methods("DELETE")
-	apiRouter.HandleFunc("/runs/{run_id}/issues/{link_id}/resolve", integrationHandler.ResolveLinkedIssue).Methods("POST")
-	apiRouter.HandleFunc("/runs/{run_id}/issues/{link_id}/sync", integrationHandler.SyncIssueStatus).Methods("POST")
+	runRouter.HandleFunc("/issues", integrationHandler.ListIssueLinks).Methods("GET")
+	runRouter.Handle("/issues", idempotent(http.HandlerFunc(integrationHandler.CreateAndLinkIssue))).Methods("POST")
+	runRouter.HandleFunc("/issues/link", integrationHandler.LinkExistingIssue).Methods("POST")
+	runRouter.HandleFunc("/issues/{link_id}", integrationHandler.UnlinkIssue).Methods("DELETE")
+	runRouter.HandleFunc("/issues/{link_id}/resolve", integrationHandler.ResolveLinkedIssue).Methods("POST")
+	runRouter.HandleFunc("/issues/{link_id}/sync", integrationHandler.SyncIssueStatus).Methods("POST")
 
 	// Script Generation routes (protected)
 	scriptGenHandler := handlers.NewScriptGenHandler(
 		scriptStore,
+		scriptRevisionStore,
 		testProcedureStore,
+		stepGroupStore,
 		projectStore,
+		endpointStore,
+		endpointSecretStore,
 		scriptGenerator,
+		scriptPostProcessor,
+		scriptrepo.NewPusher(scriptRepoStore, log),
 		blobStorage,
+		usageRecorder,
+		llmMeter,
+		notifier,
+		coordinator,
 		log,
 	)
 
 	// Generate and list scripts for a procedure
 	apiRouter.HandleFunc("/procedures/{procedure_id}/scripts", scriptGenHandler.List).Methods("GET")
-	apiRouter.HandleFunc("/procedures/{procedure_id}/scripts", scriptGenHandler.Generate).Methods("POST")
+	apiRouter.Handle("/procedures/{procedure_id}/scripts", idempotent(expensiveRateLimit(http.HandlerFunc(scriptGenHandler.Generate)))).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/scripts/bundle", scriptGenHandler.Bundle).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/scripts/revisions", scriptGenHandler.ListRevisions).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/scripts/diff", scriptGenHandler.Diff).Methods("GET")
 
 	// Individual script operations
 	apiRouter.HandleFunc("/scripts/{script_id}", scriptGenHandler.GetByID).Methods("GET")
 	apiRouter.HandleFunc("/scripts/{script_id}/download", scriptGenHandler.Download).Methods("GET")
+	apiRouter.HandleFunc("/scripts/{script_id}/push", scriptGenHandler.Push).Methods("POST")
 	apiRouter.HandleFunc("/scripts/{script_id}", scriptGenHandler.Delete).Methods("DELETE")
 
+	// Notification preference routes (protected)
+	notificationHandler := handlers.NewNotificationHandler(notificationStore, log)
+	apiRouter.HandleFunc("/notifications/preferences", notificationHandler.GetPreferences).Methods("GET")
+	apiRouter.HandleFunc("/notifications/preferences", notificationHandler.UpdatePreferences).Methods("PUT")
+
+	// Usage report routes (protected)
+	usageHandler := handlers.NewUsageHandler(usageStore, storageQuotas, log)
+	apiRouter.HandleFunc("/usage", usageHandler.GetReport).Methods("GET")
+	apiRouter.HandleFunc("/usage/export", usageHandler.Export).Methods("GET")
+	projectRouter.HandleFunc("/storage-usage", usageHandler.GetProjectStorage).Methods("GET")
+
+	// LLM usage reports and monthly budgets
+	llmUsageHandler := handlers.NewLLMUsageHandler(llmUsageStore, log)
+	apiRouter.HandleFunc("/usage/llm", llmUsageHandler.GetReport).Methods("GET")
+	apiRouter.HandleFunc("/usage/llm/budget", llmUsageHandler.SetAccountBudget).Methods("PUT")
+	apiRouter.HandleFunc("/usage/llm/budget", llmUsageHandler.DeleteAccountBudget).Methods("DELETE")
+	projectRouter.HandleFunc("/llm-budget", llmUsageHandler.GetProjectBudget).Methods("GET")
+	projectRouter.HandleFunc("/llm-budget", llmUsageHandler.SetProjectBudget).Methods("PUT")
+	projectRouter.HandleFunc("/llm-budget", llmUsageHandler.DeleteProjectBudget).Methods("DELETE")
+
+	// Script repository routes (owner-only via projectRouter)
+	scriptRepoHandler := handlers.NewScriptRepositoryHandler(scriptRepoStore, log)
+	projectRouter.HandleFunc("/script-repository", scriptRepoHandler.Get).Methods("GET")
+	projectRouter.HandleFunc("/script-repository", scriptRepoHandler.Save).Methods("PUT")
+	projectRouter.HandleFunc("/script-repository", scriptRepoHandler.Delete).Methods("DELETE")
+
+	// Retention policy routes (protected by project authorization)
+	retentionHandler := handlers.NewRetentionHandler(retentionStore, retentionCleaner, log)
+	projectRouter.HandleFunc("/retention", retentionHandler.Get).Methods("GET")
+	projectRouter.HandleFunc("/retention", retentionHandler.Update).Methods("PUT")
+	projectRouter.HandleFunc("/retention/preview", retentionHandler.Preview).Methods("GET")
+
+	// Run analytics routes
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsStore, testProcedureStore, testProcedureHandler, log)
+	apiRouter.HandleFunc("/procedures/{procedure_id}/analytics", analyticsHandler.GetProcedureAnalytics).Methods("GET")
+	projectRouter.HandleFunc("/analytics", analyticsHandler.GetProjectAnalytics).Methods("GET")
+
+	// Status badges (public, authorized by a token signed with the session
+	// secret) and their signed URLs (owner-only)
+	badgeHandler := handlers.NewBadgeHandler(analyticsStore, testProcedureStore, testProcedureHandler, badge.NewSigner(cfg.Session.CookieSecret), cfg.Notifications.BaseURL, log)
+	projectRouter.HandleFunc("/badge", badgeHandler.GetProjectBadgeLinks).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/badge", badgeHandler.GetProcedureBadgeLinks).Methods("GET")
+	router.HandleFunc("/api/v1/projects/{id}/badge.svg", badgeHandler.ProjectBadge).Methods("GET")
+	router.HandleFunc("/api/v1/procedures/{procedure_id}/badge.svg", badgeHandler.ProcedureBadge).Methods("GET")
+
+	// Label routes; ?label= filters the procedure and run lists
+	labelHandler := handlers.NewLabelHandler(labelStore, testProcedureHandler, log)
+	apiRouter.HandleFunc("/procedures/{procedure_id}/labels", labelHandler.ListProcedureLabels).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/labels/{key}", labelHandler.SetProcedureLabel).Methods("PUT")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/labels/{key}", labelHandler.RemoveProcedureLabel).Methods("DELETE")
+	runRouter.HandleFunc("/labels", labelHandler.ListRunLabels).Methods("GET")
+	runRouter.HandleFunc("/labels/{key}", labelHandler.SetRunLabel).Methods("PUT")
+	runRouter.HandleFunc("/labels/{key}", labelHandler.RemoveRunLabel).Methods("DELETE")
+	projectRouter.HandleFunc("/labels", labelHandler.ListProjectLabels).Methods("GET")
+
+	// Release routes (protected by project authorization); runs are tagged
+	// with a release through /runs/{run_id}/release
+	releaseHandler := handlers.NewReleaseHandler(releaseStore, testRunStore, log)
+	projectRouter.HandleFunc("/releases", releaseHandler.List).Methods("GET")
+	projectRouter.HandleFunc("/releases", releaseHandler.Create).Methods("POST")
+	projectRouter.HandleFunc("/releases/{release_id}", releaseHandler.GetByID).Methods("GET")
+	projectRouter.HandleFunc("/releases/{release_id}", releaseHandler.Update).Methods("PUT")
+	projectRouter.HandleFunc("/releases/{release_id}", releaseHandler.Delete).Methods("DELETE")
+	projectRouter.HandleFunc("/releases/{release_id}/report", releaseHandler.Report).Methods("GET")
+	runRouter.HandleFunc("/release", releaseHandler.TagRun).Methods("PUT")
+
+	// Requirement traceability routes; requirements linked from an issue
+	// tracker are looked up through the integration handler
+	requirementHandler := handlers.NewRequirementHandler(requirementStore, testProcedureHandler, integrationHandler, log)
+	apiRouter.HandleFunc("/procedures/{procedure_id}/requirements", requirementHandler.List).Methods("GET")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/requirements", requirementHandler.Create).Methods("POST")
+	apiRouter.HandleFunc("/procedures/{procedure_id}/requirements/{link_id}", requirementHandler.Delete).Methods("DELETE")
+	projectRouter.HandleFunc("/traceability", requirementHandler.Matrix).Methods("GET")
+
 	// Create HTTP server
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+	// CORS wraps the router, since preflight requests match no route
+	var handler http.Handler = router
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		handler = handlers.CORSMiddleware(handlers.CORSPolicy{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		})(router)
+	}
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      router,
+		Handler:      handler,
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
+	// Native HTTPS, with a plain HTTP listener redirecting to it
+	serverTLS, err := newServerTLS(cfg.Server.TLS, cfg.Server.Port, log)
+	if err != nil {
+		return err
+	}
+	var redirectServer *http.Server
+	if serverTLS != nil {
+		server.TLSConfig = serverTLS.config
+		if !cfg.Session.Secure {
+			log.Warn(ctx, "serving HTTPS with session.secure false; session cookies are also sent over plain HTTP", nil)
+		}
+		if cfg.Server.TLS.RedirectAddr != "" {
+			redirectServer = &http.Server{
+				Addr:         cfg.Server.TLS.RedirectAddr,
+				Handler:      serverTLS.redirect,
+				ReadTimeout:  cfg.Server.ReadTimeout,
+				WriteTimeout: cfg.Server.WriteTimeout,
+			}
+			go func() {
+				log.Info(ctx, "redirecting HTTP to HTTPS", map[string]interface{}{
+					"address": cfg.Server.TLS.RedirectAddr,
+				})
+				if err := redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Error(ctx, "redirect server error", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
+			}()
+		}
+	}
+
 	// Start server in a goroutine
 	go func() {
 		log.Info(ctx, "server listening", map[string]interface{}{
 			"address": addr,
+			"tls":     serverTLS != nil,
 		})
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if serverTLS != nil {
+			// The certificates come from server.TLSConfig
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Error(ctx, "server error", map[string]interface{}{
 				"error": err.Error(),
 			})
 		}
 	}()
 
-	// Wait for interrupt signal for graceful shutdown
+	// Reload the config on SIGHUP and wait for interrupt signal for
+	// graceful shutdown
+	reloader := &configReloader{
+		path:             configFile,
+		logger:           log,
+		apiLimiter:       apiLimiter,
+		expensiveLimiter: expensiveLimiter,
+		workerPool:       workerPool,
+		loaded:           loadedConfig,
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	for running := true; running; {
+		select {
+		case <-hup:
+			if err := reloader.reload(ctx); err != nil {
+				log.Error(ctx, "failed to reload config, keeping the current settings", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		case <-quit:
+			running = false
+		}
+	}
+	signal.Stop(hup)
 
 	log.Info(ctx, "shutting down server", nil)
 
-	// Stop worker pool
-	poolCancel()
+	// Stop taking new jobs and script generations and wait for the running
+	// ones while the HTTP server shuts down. Work still running after the
+	// drain timeout is interrupted; interrupted jobs are resumed on the next
+	// start.
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		drainCtx, cancel := context.WithTimeout(ctx, cfg.Server.DrainTimeout)
+		defer cancel()
+		if err := coordinator.Drain(drainCtx); err != nil {
+			log.Warn(ctx, "drain timeout reached, interrupted running work", map[string]interface{}{
+				"drain_timeout": cfg.Server.DrainTimeout.String(),
+			})
+		}
+	}()
 
 	// Graceful shutdown with timeout
 	shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("server forced to shutdown: %w", err)
+	if redirectServer != nil {
+		redirectServer.Shutdown(shutdownCtx)
+	}
+	shutdownErr := server.Shutdown(shutdownCtx)
+	<-drained
+
+	// Stop worker pool
+	poolCancel()
+
+	if shutdownErr != nil {
+		return fmt.Errorf("server forced to shutdown: %w", shutdownErr)
 	}
 
 	log.Info(ctx, "server stopped", nil)
 	return nil
 }
 
+// newNotifier creates the email and Slack notifier. It is not started.
+func newNotifier(ctx context.Context, cfg *Config, store notification.Store, userStore user.Store, log logger.Logger) (*notification.Notifier, error) {
+	notifier := notification.NewNotifier(store, userStore, cfg.Notifications.BaseURL, cfg.Notifications.QueueSize, log)
+	if cfg.Notifications.SMTPHost != "" {
+		emailSender, err := notification.NewEmailSender(notification.SMTPConfig{
+			Host:     cfg.Notifications.SMTPHost,
+			Port:     cfg.Notifications.SMTPPort,
+			Username: cfg.Notifications.SMTPUsername,
+			Password: cfg.Notifications.SMTPPassword,
+			From:     cfg.Notifications.SMTPFrom,
+			Timeout:  cfg.Notifications.Timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize email notifications: %w", err)
+		}
+		notifier.RegisterSender(notification.ChannelEmail, emailSender)
+	}
+	if cfg.Notifications.SlackEnabled {
+		notifier.RegisterSender(notification.ChannelSlack, notification.NewSlackSender(cfg.Notifications.Timeout))
+	}
+	log.Info(ctx, "notifications initialized", map[string]interface{}{
+		"email": cfg.Notifications.SMTPHost != "",
+		"slack": cfg.Notifications.SlackEnabled,
+	})
+	return notifier, nil
+}
+
+// newScriptPostProcessor chains the configured transforms of generated
+// scripts: boilerplate is injected first so the formatter formats it, and the
+// license header goes last so it stays exactly as configured.
+func newScriptPostProcessor(cfg ScriptGenPostProcessingConfig, log logger.Logger) (*scriptgen.PostProcessChain, error) {
+	var processors []scriptgen.PostProcessor
+	if cfg.Prologue != "" || cfg.Epilogue != "" {
+		boilerplate, err := scriptgen.NewBoilerplateProcessor(cfg.Prologue, cfg.Epilogue)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, boilerplate)
+	}
+	if cfg.FormatterURL != "" {
+		processors = append(processors, scriptgen.NewFormatterProcessor(cfg.FormatterURL, cfg.FormatterTimeout))
+	}
+	if cfg.LicenseHeader != "" {
+		header, err := scriptgen.NewHeaderProcessor(cfg.LicenseHeader)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, header)
+	}
+	return scriptgen.NewPostProcessChain(log, processors...), nil
+}
+
+// newAgentPipeline creates the pipeline running jobs, with the runners of
+// every job type registered.
+func newAgentPipeline(
+	cfg *Config,
+	jobStore job.Store,
+	jobLogStore job.LogStore,
+	endpointStore endpoint.Store,
+	endpointSecretStore endpoint.SecretStore,
+	testProcedureStore testprocedure.Store,
+	testRunStore testrun.Store,
+	stepNoteStore testrun.StepNoteStore,
+	assetStore testrun.AssetStore,
+	baselineStore visualregression.Store,
+	blobStorage storage.BlobStorage,
+	mcpBreaker *resilience.Breaker,
+	usageRecorder *metering.Recorder,
+	llmMeter *llmusage.Meter,
+	analyticsRecorder *analytics.Recorder,
+	notifier *notification.Notifier,
+	log logger.Logger,
+) *agent.Pipeline {
+	agentCfg := agent.Config{
+		MaxIterations:       cfg.Agent.MaxIterations,
+		TimeLimit:           cfg.Agent.TimeLimit,
+		BedrockRegion:       cfg.Agent.BedrockRegion,
+		BedrockModel:        cfg.Agent.BedrockModel,
+		BedrockAccessKey:    cfg.Agent.BedrockAccessKey,
+		BedrockSecretKey:    cfg.Agent.BedrockSecretKey,
+		PlaywrightMCPURL:    cfg.Agent.PlaywrightMCPURL,
+		AgentScriptPath:     cfg.Agent.AgentScriptPath,
+		MaxConcurrentWorkers: cfg.Agent.MaxConcurrentWorkers,
+		HeartbeatInterval:    cfg.Jobs.HeartbeatInterval,
+	}
+	agentPipeline := agent.NewPipeline(agentCfg, jobStore, jobLogStore, endpointStore, endpointSecretStore, testProcedureStore, blobStorage, mcpBreaker, usageRecorder, llmMeter, notifier, log)
+
+	// Visual regression jobs capture pages directly instead of running the agent
+	visualCapturer := visualregression.NewScriptCapturer(cfg.Agent.VisualCaptureScriptPath, cfg.Agent.PlaywrightMCPURL)
+	visualRunner := visualregression.NewRunner(endpointStore, baselineStore, blobStorage, visualCapturer, usageRecorder, log)
+	agentPipeline.RegisterRunner(job.JobTypeVisualRegression, visualRunner)
+
+	// Link check jobs crawl the endpoint over plain HTTP
+	linkCheckRunner := linkcheck.NewRunner(endpointStore, linkcheck.NewCrawler(linkcheck.DefaultRequestTimeout), log)
+	agentPipeline.RegisterRunner(job.JobTypeLinkCheck, linkCheckRunner)
+
+	// Procedure execution jobs run the procedure execution agent and record
+	// its outcome as a test run
+	procedureExecutor := execution.NewScriptExecutor(agent.Process{
+		ScriptPath:       cfg.Agent.ProcedureScriptPath,
+		BedrockRegion:    cfg.Agent.BedrockRegion,
+		BedrockAccessKey: cfg.Agent.BedrockAccessKey,
+		BedrockSecretKey: cfg.Agent.BedrockSecretKey,
+	}, cfg.Agent.PlaywrightMCPURL)
+	executionRunner := execution.NewRunner(
+		testProcedureStore,
+		endpointStore,
+		endpointSecretStore,
+		jobStore,
+		jobLogStore,
+		testRunStore,
+		stepNoteStore,
+		assetStore,
+		blobStorage,
+		procedureExecutor,
+		usageRecorder,
+		llmMeter,
+		analyticsRecorder,
+		log,
+	)
+	agentPipeline.RegisterRunner(job.JobTypeProcedureExecution, executionRunner)
+
+	return agentPipeline
+}
+
 // defaultClientFactory implements issuetracker.ClientFactory by delegating to
-// the github and jira sub-packages. It lives here (not in the issuetracker
-// package) to avoid an import cycle.
+// the github, jira and custom sub-packages. It lives here (not in the
+// issuetracker package) to avoid an import cycle.
 type defaultClientFactory struct{}
 
 func (f *defaultClientFactory) NewClient(provider issuetracker.ProviderType, credentials map[string]string) (issuetracker.Client, error) {
@@ -422,6 +1447,20 @@ func (f *defaultClientFactory) NewClient(provider issuetracker.ProviderType, cre
 		return githubclient.NewClient(credentials)
 	case issuetracker.ProviderJira:
 		return jiraclient.NewClient(credentials)
+	case issuetracker.ProviderCustom:
+		return customclient.NewClient(credentials)
+	default:
+		return nil, issuetracker.ErrInvalidProvider
+	}
+}
+
+// ParseWebhook implements issuetracker.WebhookParser.
+func (f *defaultClientFactory) ParseWebhook(provider issuetracker.ProviderType, secret string, header http.Header, body []byte) (*issuetracker.Issue, error) {
+	switch provider {
+	case issuetracker.ProviderGitHub:
+		return githubclient.ParseWebhook(secret, header, body)
+	case issuetracker.ProviderJira:
+		return jiraclient.ParseWebhook(secret, header, body)
 	default:
 		return nil, issuetracker.ErrInvalidProvider
 	}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/cmd/backend/handlers"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// serverTLS is how the server serves HTTPS itself.
+type serverTLS struct {
+	config *tls.Config
+	// redirect serves the plain HTTP listener: Let's Encrypt challenges,
+	// with autocert, and redirects to HTTPS.
+	redirect http.Handler
+}
+
+// newServerTLS sets up HTTPS from cfg. It returns nil if the server serves
+// plain HTTP. HTTP/2 is negotiated on the TLS connections.
+func newServerTLS(cfg ServerTLSConfig, port int, log logger.Logger) (*serverTLS, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	redirect := handlers.HTTPSRedirect(port)
+
+	if len(cfg.AutocertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+		}
+		config := manager.TLSConfig()
+		config.MinVersion = tls.VersionTLS12
+		return &serverTLS{config: config, redirect: manager.HTTPHandler(redirect)}, nil
+	}
+
+	keyPair := &keyPairReloader{certFile: cfg.CertFile, keyFile: cfg.KeyFile, logger: log}
+	if err := keyPair.load(); err != nil {
+		return nil, err
+	}
+	return &serverTLS{
+		config: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: keyPair.getCertificate,
+		},
+		redirect: redirect,
+	}, nil
+}
+
+// keyPairReloader serves a certificate from files, re-reading them when
+// the certificate file changes so that renewed certificates are picked up
+// without a restart.
+type keyPairReloader struct {
+	certFile string
+	keyFile  string
+	logger   logger.Logger
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// keyPairCheckInterval is how often the certificate file is checked for
+// changes.
+const keyPairCheckInterval = time.Minute
+
+// load reads the key pair.
+func (k *keyPairReloader) load() error {
+	info, err := os.Stat(k.certFile)
+	if err != nil {
+		return fmt.Errorf("failed to read server.tls.cert_file: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	k.cert = &cert
+	k.modTime = info.ModTime()
+	return nil
+}
+
+// getCertificate returns the key pair, reloading it if the certificate
+// file changed. A key pair that fails to load, such as one caught half
+// written, is retried on the next check while the old one keeps serving.
+func (k *keyPairReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if now := time.Now(); now.Sub(k.checked) >= keyPairCheckInterval {
+		k.checked = now
+		if info, err := os.Stat(k.certFile); err == nil && !info.ModTime().Equal(k.modTime) {
+			if err := k.load(); err != nil {
+				k.logger.Warn(context.Background(), "failed to reload TLS certificate, keeping the current one", map[string]interface{}{
+					"error": err.Error(),
+				})
+			} else {
+				k.logger.Info(context.Background(), "TLS certificate reloaded", nil)
+			}
+		}
+	}
+	return k.cert, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/spf13/cobra"
+)
+
+// validateCheckTimeout bounds each check of config validate.
+const validateCheckTimeout = 30 * time.Second
+
+// storageProbePath is written and deleted to check blob storage.
+const storageProbePath = "config-validate/probe"
+
+var validateSkipLLM bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration commands",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration and the services it points to",
+	Long: `Loads the config file and checks that secrets can be read from the secrets
+manager, the database accepts connections, blob storage can be written to and
+the Bedrock models of script generation and the agent accept their
+credentials. Every check is run and the command fails if any of them failed.
+
+The Bedrock checks make a one-token request to each model; --skip-llm leaves
+them out.`,
+	RunE: runConfigValidate,
+}
+
+func init() {
+	configValidateCmd.Flags().StringVarP(&configFile, "config", "c", "", "config file path")
+	configValidateCmd.Flags().BoolVar(&devMode, "dev", false, "validate the SQLite database and local storage of --dev servers")
+	configValidateCmd.Flags().BoolVar(&validateSkipLLM, "skip-llm", false, "skip the Bedrock credential checks")
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// configCheck is a single check of config validate.
+type configCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if devMode {
+		applyDevMode(cfg)
+	}
+
+	log := logger.NewLogrusLogger("error")
+	checks := []configCheck{
+		{"secrets", func(ctx context.Context) error {
+			_, err := loadSecrets(ctx, cfg, log)
+			return err
+		}},
+		{"database", func(ctx context.Context) error {
+			return checkDatabase(ctx, cfg.Database.connectionConfig())
+		}},
+		{"storage", func(ctx context.Context) error {
+			return checkStorage(ctx, cfg.Storage)
+		}},
+	}
+	if !validateSkipLLM {
+		if cfg.ScriptGen.Provider == "bedrock" {
+			checks = append(checks, configCheck{"script_gen", func(ctx context.Context) error {
+				return checkBedrock(ctx, cfg.ScriptGen.Region, cfg.ScriptGen.ModelID, "", "")
+			}})
+		}
+		checks = append(checks, configCheck{"agent", func(ctx context.Context) error {
+			return checkBedrock(ctx, cfg.Agent.BedrockRegion, cfg.Agent.BedrockModel, cfg.Agent.BedrockAccessKey, cfg.Agent.BedrockSecretKey)
+		}})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "config\tok\t%s\n", configSource(configFile))
+	failed := 0
+	for _, check := range checks {
+		ctx, cancel := context.WithTimeout(cmd.Context(), validateCheckTimeout)
+		err := check.run(ctx)
+		cancel()
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\tFAILED\t%s\n", check.name, err)
+		} else {
+			fmt.Fprintf(w, "%s\tok\t\n", check.name)
+		}
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks)+1)
+	}
+	return nil
+}
+
+// configSource describes where the config was loaded from.
+func configSource(path string) string {
+	if path == "" {
+		return "config.yaml from . or ./config, if present"
+	}
+	return path
+}
+
+// checkDatabase connects to the database and pings it.
+func checkDatabase(ctx context.Context, cfg database.Config) error {
+	db, err := database.Connect(cfg)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	return sqlDB.PingContext(ctx)
+}
+
+// checkStorage writes and deletes a probe object in blob storage.
+func checkStorage(ctx context.Context, cfg StorageConfig) error {
+	blobStorage, err := storage.NewBlobStorage(cfg.Type, cfg.blobStorageConfig())
+	if err != nil {
+		return err
+	}
+	if err := blobStorage.Upload(ctx, storageProbePath, strings.NewReader("ok")); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	if err := blobStorage.Delete(ctx, storageProbePath); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
+
+// checkBedrock makes a one-token request to a Bedrock model. Static
+// credentials are used when accessKey is set; otherwise the default AWS
+// credential chain is.
+func checkBedrock(ctx context.Context, region, modelID, accessKey, secretKey string) error {
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(region)}
+	if accessKey != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(aws.CredentialsProviderFunc(
+			func(ctx context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: accessKey, SecretAccessKey: secretKey}, nil
+			})))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	_, err = bedrockruntime.NewFromConfig(awsCfg).Converse(ctx, &bedrockruntime.ConverseInput{
+		ModelId: aws.String(modelID),
+		Messages: []types.Message{{
+			Role:    types.ConversationRoleUser,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: "ping"}},
+		}},
+		InferenceConfig: &types.InferenceConfiguration{MaxTokens: aws.Int32(1)},
+	})
+	if err != nil {
+		return fmt.Errorf("model %s: %w", modelID, err)
+	}
+	return nil
+}
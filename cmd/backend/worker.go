@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/analytics"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/notification"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/queue"
+	"github.com/hairizuanbinnoorazman/ui-automation/redisclient"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/hairizuanbinnoorazman/ui-automation/shutdown"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/hairizuanbinnoorazman/ui-automation/visualregression"
+	"github.com/spf13/cobra"
+)
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run jobs from the job queue without serving the API",
+	Long: `Run jobs received from the job queue (queue.type) on a dedicated node,
+separate from the API servers. Workers need the same database, storage,
+agent and notification settings as the API servers.
+
+On SIGINT or SIGTERM the worker stops receiving jobs and waits up to
+server.drain_timeout for the running ones. Jobs still running then are
+interrupted and queued again for another worker.`,
+	RunE: runWorker,
+}
+
+func init() {
+	workerCmd.Flags().StringVarP(&configFile, "config", "c", "", "config file path")
+	rootCmd.AddCommand(workerCmd)
+}
+
+func runWorker(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Queue.Type == "" {
+		return fmt.Errorf("queue.type is required to run a worker")
+	}
+	if cfg.Database.Driver == database.DriverSQLite {
+		return fmt.Errorf("workers need a database shared with the API servers; sqlite is not supported")
+	}
+
+	log := logger.NewLogrusLogger(cfg.Log.Level)
+	log.Info(ctx, "starting worker", map[string]interface{}{
+		"version": Version,
+		"commit":  Commit,
+		"date":    BuildDate,
+	})
+
+	configSecrets, err := loadSecrets(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	dbConfig := cfg.Database.connectionConfig()
+	if configSecrets != nil {
+		if configSecrets.databasePassword != nil {
+			dbConfig.PasswordFunc = configSecrets.databasePassword.Get
+		}
+		if cfg.Secrets.RefreshInterval > 0 {
+			configSecrets.watcher.Start(cfg.Secrets.RefreshInterval)
+			defer configSecrets.watcher.Stop()
+		}
+	}
+
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	defer sqlDB.Close()
+
+	migrationStatus, err := database.GetMigrationStatus(sqlDB, cfg.Database.MigrationsPath)
+	if err != nil {
+		log.Warn(ctx, "failed to check database migrations", map[string]interface{}{
+			"error": err.Error(),
+			"path":  cfg.Database.MigrationsPath,
+		})
+	} else if migrationStatus.Dirty {
+		return fmt.Errorf("database schema is dirty at migration %d: repair it and run 'migrate force'", migrationStatus.Version)
+	}
+
+	blobStorage, err := storage.NewBlobStorage(cfg.Storage.Type, cfg.Storage.blobStorageConfig())
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	userStore := user.NewMySQLStore(db, log)
+	projectStore := project.NewMySQLStore(db, log)
+	testProcedureStore := testprocedure.NewMySQLStore(db, log)
+	endpointStore := endpoint.NewMySQLStore(db, log)
+	keyring := integration.NewKeyring(integration.DeriveKey(cfg.Integration.EncryptionKey))
+	configSecrets.rotateEncryptionKey(db, keyring, log)
+	endpointSecretStore := endpoint.NewMySQLSecretStore(db, keyring, log)
+	jobStore := job.NewMySQLStore(db, log)
+	jobLogStore := job.NewMySQLLogStore(db, log)
+	notificationStore := notification.NewMySQLStore(db, keyring, log)
+	usageRecorder := metering.NewRecorder(metering.NewMySQLStore(db, log), projectStore, log)
+	llmMeter := llmusage.NewMeter(llmusage.NewMySQLStore(db, log), projectStore, llmusage.Pricing{
+		InputPerMillion:  cfg.ScriptGen.Pricing.InputPerMillion,
+		OutputPerMillion: cfg.ScriptGen.Pricing.OutputPerMillion,
+	}, log)
+
+	notifier, err := newNotifier(ctx, cfg, notificationStore, userStore, log)
+	if err != nil {
+		return err
+	}
+	notifier.Start(cfg.Notifications.Workers)
+	defer notifier.Stop()
+
+	mcpBreaker := resilience.NewBreaker("playwright_mcp", resilience.Config{
+		Timeout:          cfg.Resilience.MCPTimeout,
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+		OpenDuration:     cfg.Resilience.OpenDuration,
+	})
+	testRunStore := testrun.NewMySQLStore(db, log)
+	stepNoteStore := testrun.NewMySQLStepNoteStore(db, log)
+	assetStore := testrun.NewMySQLAssetStore(db, log)
+	analyticsRecorder := analytics.NewRecorder(analytics.NewMySQLStore(db, log), log)
+	baselineStore := visualregression.NewMySQLStore(db, log)
+	agentPipeline := newAgentPipeline(cfg, jobStore, jobLogStore, endpointStore, endpointSecretStore, testProcedureStore, testRunStore, stepNoteStore, assetStore, baselineStore, blobStorage, mcpBreaker, usageRecorder, llmMeter, analyticsRecorder, notifier, log)
+
+	jobQueue, err := newJobQueue(ctx, cfg.Queue)
+	if err != nil {
+		return err
+	}
+	defer jobQueue.Close()
+
+	coordinator := shutdown.NewCoordinator()
+	workerPool := agent.NewWorkerPool(cfg.Agent.MaxConcurrentWorkers, jobStore, endpointStore, agentPipeline, jobQueue, coordinator, log)
+	poolCtx, poolCancel := context.WithCancel(ctx)
+	defer poolCancel()
+	workerPool.Start(poolCtx)
+
+	log.Info(ctx, "worker receiving jobs", map[string]interface{}{
+		"queue":   cfg.Queue.Type,
+		"workers": cfg.Agent.MaxConcurrentWorkers,
+	})
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Info(ctx, "shutting down worker", nil)
+
+	// Stop receiving jobs and wait for the running ones. Jobs still running
+	// after the drain timeout are interrupted and queued again.
+	drainCtx, cancel := context.WithTimeout(ctx, cfg.Server.DrainTimeout)
+	defer cancel()
+	if err := coordinator.Drain(drainCtx); err != nil {
+		log.Warn(ctx, "drain timeout reached, interrupted running jobs", map[string]interface{}{
+			"drain_timeout": cfg.Server.DrainTimeout.String(),
+		})
+	}
+	poolCancel()
+
+	log.Info(ctx, "worker stopped", nil)
+	return nil
+}
+
+// newJobQueue creates the queue jobs are dispatched through, or returns nil
+// when jobs are claimed from the database.
+func newJobQueue(ctx context.Context, cfg QueueConfig) (queue.Queue, error) {
+	var (
+		q   queue.Queue
+		err error
+	)
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case queue.TypeRedis:
+		q, err = queue.NewRedisQueue(redisclient.Config{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}, cfg.RedisKey, cfg.VisibilityTimeout, cfg.WaitTime)
+	case queue.TypeSQS:
+		q, err = queue.NewSQSQueue(ctx, cfg.SQSQueueURL, cfg.SQSRegion, cfg.VisibilityTimeout, cfg.WaitTime)
+	default:
+		return nil, fmt.Errorf("unsupported queue type: %s", cfg.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize job queue: %w", err)
+	}
+	return q, nil
+}
+
+// dispatchJobs hands jobs to the worker pool, logging the ones that could
+// not be dispatched.
+func dispatchJobs(ctx context.Context, pool *agent.WorkerPool, jobs []*job.Job, log logger.Logger) {
+	for _, j := range jobs {
+		if err := pool.Dispatch(ctx, j.ID); err != nil {
+			log.Error(ctx, "failed to dispatch job", map[string]interface{}{
+				"error":  err.Error(),
+				"job_id": j.ID.String(),
+			})
+		}
+	}
+}
+
+// dispatchCreatedJobs sends every created job to the queue. Jobs already
+// queued are sent again, and workers skip the duplicates.
+func dispatchCreatedJobs(ctx context.Context, jobStore job.Store, pool *agent.WorkerPool, log logger.Logger) error {
+	ids, err := jobStore.ListIDsByStatus(ctx, job.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("failed to list created jobs: %w", err)
+	}
+	for _, id := range ids {
+		if err := pool.Dispatch(ctx, id); err != nil {
+			return fmt.Errorf("failed to dispatch created jobs: %w", err)
+		}
+	}
+	if len(ids) > 0 {
+		log.Info(ctx, "dispatched created jobs", map[string]interface{}{
+			"count": len(ids),
+		})
+	}
+	return nil
+}
@@ -1,35 +1,14 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 	"os"
-	"time"
-)
-
-// APIError represents an error response from the API.
-type APIError struct {
-	StatusCode int
-	Message    string
-}
 
-func (e *APIError) Error() string {
-	return fmt.Sprintf("API error (%d): %s", e.StatusCode, e.Message)
-}
-
-// Client is an HTTP client for the UI Automation API.
-type Client struct {
-	baseURL    string
-	token      string
-	httpClient *http.Client
-	debug      bool
-}
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+)
 
-func getClient() (*Client, error) {
+func getClient() (*client.Client, error) {
 	baseURL := getConfigURL()
 	token := getConfigToken()
 
@@ -37,96 +16,18 @@ func getClient() (*Client, error) {
 		return nil, fmt.Errorf("API token is required. Set it via --token flag, UI_AUTOMATION_TOKEN env var, or ~/.ui-automation.yaml")
 	}
 
-	return &Client{
-		baseURL: baseURL,
-		token:   token,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		debug: flagDebug,
-	}, nil
-}
-
-func (c *Client) do(req *http.Request) ([]byte, error) {
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Content-Type", "application/json")
-
-	if c.debug {
-		fmt.Fprintf(os.Stderr, "DEBUG: %s %s\n", req.Method, req.URL.String())
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if c.debug {
-		fmt.Fprintf(os.Stderr, "DEBUG: Status %d\n", resp.StatusCode)
-		fmt.Fprintf(os.Stderr, "DEBUG: Body: %s\n", string(body))
-	}
-
-	if resp.StatusCode >= 400 {
-		var errResp struct {
-			Error string `json:"error"`
-		}
-		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
-			return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
-		}
-		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(body)}
-	}
-
-	return body, nil
-}
-
-func (c *Client) Get(path string, query url.Values) ([]byte, error) {
-	u := c.baseURL + path
-	if len(query) > 0 {
-		u += "?" + query.Encode()
-	}
-
-	req, err := http.NewRequest(http.MethodGet, u, nil)
-	if err != nil {
-		return nil, err
-	}
-	return c.do(req)
-}
-
-func (c *Client) Post(path string, body interface{}) ([]byte, error) {
-	data, err := json.Marshal(body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(data))
-	if err != nil {
-		return nil, err
-	}
-	return c.do(req)
-}
-
-func (c *Client) Put(path string, body interface{}) ([]byte, error) {
-	data, err := json.Marshal(body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest(http.MethodPut, c.baseURL+path, bytes.NewReader(data))
-	if err != nil {
-		return nil, err
+	var opts []client.Option
+	if flagDebug {
+		opts = append(opts, client.WithDebug(os.Stderr))
 	}
-	return c.do(req)
+	return client.New(baseURL, token, opts...), nil
 }
 
-func (c *Client) Delete(path string) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodDelete, c.baseURL+path, nil)
+// parseID parses the value of an ID flag.
+func parseID(flag, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
 	if err != nil {
-		return nil, err
+		return uuid.Nil, fmt.Errorf("invalid --%s: %w", flag, err)
 	}
-	return c.do(req)
+	return id, nil
 }
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/spf13/cobra"
+)
+
+// errSessionQuit is returned when the tester quits before the last step.
+var errSessionQuit = errors.New("session quit")
+
+// stepResult is the outcome the tester recorded for one step.
+type stepResult struct {
+	Name   string
+	Status testrun.Status
+	Notes  string
+}
+
+// prompter reads answers from the tester. A single reader is kept for the
+// whole session so piped input is not lost between prompts.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask prints prompt and returns the trimmed answer. It returns io.EOF once
+// input is closed.
+func (p *prompter) ask(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// askResult asks for a step result until a valid answer is given. Quitting
+// is reported as errSessionQuit.
+func (p *prompter) askResult() (testrun.Status, error) {
+	for {
+		answer, err := p.ask("Result [p]ass / [f]ail / [s]kip / [q]uit: ")
+		if err != nil {
+			return "", err
+		}
+		switch strings.ToLower(answer) {
+		case "p", "pass", "passed":
+			return testrun.StatusPassed, nil
+		case "f", "fail", "failed":
+			return testrun.StatusFailed, nil
+		case "s", "skip", "skipped":
+			return testrun.StatusSkipped, nil
+		case "q", "quit":
+			return "", errSessionQuit
+		}
+		fmt.Fprintln(p.out, "Please answer p, f, s or q.")
+	}
+}
+
+func newRunsExecuteCmd() *cobra.Command {
+	var procedureID string
+
+	cmd := &cobra.Command{
+		Use:   "execute",
+		Short: "Run a test procedure interactively in the terminal",
+		Long: "Starts a test run and walks through the procedure step by step, recording a " +
+			"pass/fail/skip result, notes and an optional screenshot for each step, then " +
+			"completes the run with the overall result.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, err := parseID("procedure-id", procedureID)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			return executeRun(cmd.Context(), c, pid, newPrompter(os.Stdin, os.Stdout))
+		},
+	}
+
+	cmd.Flags().StringVar(&procedureID, "procedure-id", "", "Test procedure ID (required)")
+	cmd.MarkFlagRequired("procedure-id")
+	return cmd
+}
+
+// confirmPreconditions lists the preconditions of the procedure a pending
+// run executes, and the run's checklist, and asks the tester whether they
+// are in place. Runs with neither need no confirmation.
+func confirmPreconditions(ctx context.Context, c *client.Client, run *client.TestRun, p *prompter) (bool, error) {
+	runID := run.ID
+	proc, err := c.GetRunProcedure(ctx, runID)
+	if err != nil {
+		return false, err
+	}
+	if len(proc.Preconditions) == 0 && len(run.Checklist) == 0 {
+		return true, nil
+	}
+
+	if len(proc.Preconditions) > 0 {
+		printMessage("Preconditions:")
+		for _, precondition := range proc.Preconditions {
+			printMessage(fmt.Sprintf("  - %s: %s", precondition.Kind, precondition.Description))
+		}
+	}
+	if len(run.Checklist) > 0 {
+		printMessage("Checklist:")
+		for _, item := range run.Checklist {
+			printMessage("  - " + item)
+		}
+	}
+	answer, err := p.ask("Are these in place? [y/N]: ")
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	}
+	printMessage(fmt.Sprintf("Run %s was not started; start it once the preconditions are in place.", runID))
+	return false, nil
+}
+
+// executeRun drives a manual test session for a procedure.
+func executeRun(ctx context.Context, c *client.Client, procedureID uuid.UUID, p *prompter) error {
+	run, err := c.CreateRun(ctx, procedureID, nil)
+	if err != nil {
+		return err
+	}
+
+	// The tester confirms the preconditions are in place before the run
+	// starts. The procedure is fetched again afterwards, from the run's
+	// snapshot.
+	acknowledged, err := confirmPreconditions(ctx, c, run, p)
+	if err != nil || !acknowledged {
+		return err
+	}
+	if _, err := c.StartRun(ctx, run.ID, client.StartTestRunRequest{AcknowledgePreconditions: true}); err != nil {
+		return err
+	}
+
+	proc, err := c.GetRunProcedure(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	printMessage(fmt.Sprintf("Test run %s started: %s (v%d)", run.ID, proc.Name, proc.Version))
+	if proc.Description != "" {
+		printMessage(proc.Description)
+	}
+	if run.BaseURL != "" {
+		printMessage(fmt.Sprintf("Target: %s", run.BaseURL))
+	}
+
+	var results []stepResult
+	for i, step := range proc.Steps {
+		result, err := executeStep(ctx, c, run.ID, proc.ID, i, len(proc.Steps), step, p)
+		if err != nil {
+			if errors.Is(err, errSessionQuit) || errors.Is(err, io.EOF) {
+				printMessage(fmt.Sprintf("\nSession stopped after %d of %d steps. Run %s is still in progress;", len(results), len(proc.Steps), run.ID))
+				printMessage("finish it in the web UI or with 'uictl runs complete'.")
+				return nil
+			}
+			return err
+		}
+		results = append(results, result)
+	}
+
+	status := overallStatus(results)
+
+	printMessage("\nSummary:")
+	headers := []string{"STEP", "NAME", "RESULT"}
+	var rows [][]string
+	for i, r := range results {
+		rows = append(rows, []string{fmt.Sprintf("%d", i+1), r.Name, string(r.Status)})
+	}
+	printTable(headers, rows)
+	printMessage(fmt.Sprintf("Overall result: %s", status))
+
+	runNotes, err := p.ask("Run notes (optional): ")
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	completed, err := c.CompleteRun(ctx, run.ID, client.CompleteTestRunRequest{
+		Status: status,
+		Notes:  completionNotes(results, runNotes),
+	})
+	if err != nil {
+		return err
+	}
+
+	printMessage(fmt.Sprintf("Test run completed: %s (status: %s)", completed.ID, completed.Status))
+	return nil
+}
+
+// executeStep shows one step, records the tester's result as a step note and
+// uploads an optional screenshot.
+func executeStep(ctx context.Context, c *client.Client, runID, procedureID uuid.UUID, index, total int, step client.Step, p *prompter) (stepResult, error) {
+	printMessage(fmt.Sprintf("\nStep %d/%d: %s", index+1, total, step.Name))
+	if step.Instructions != "" {
+		for _, line := range strings.Split(step.Instructions, "\n") {
+			printMessage("  " + line)
+		}
+	}
+	if step.ExpectedResult != "" {
+		printMessage("  Expected: " + step.ExpectedResult)
+	}
+	if len(step.ImagePaths) > 0 {
+		printMessage(fmt.Sprintf("  (%d reference image(s) available in the web UI)", len(step.ImagePaths)))
+	}
+	for j, attachment := range step.Attachments {
+		printMessage(fmt.Sprintf("  Attachment: %s (uictl procedures attachment --id %s --step %d --index %d -o %q)", attachment.FileName, procedureID, index+1, j+1, attachment.FileName))
+	}
+
+	status, err := p.askResult()
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	notes, err := p.ask("Notes (optional): ")
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	note := strings.ToUpper(string(status))
+	if notes != "" {
+		note += ": " + notes
+	}
+	if _, err := c.SetStepNote(ctx, runID, index, note); err != nil {
+		return stepResult{}, err
+	}
+
+	for {
+		path, err := p.ask("Screenshot path (optional): ")
+		if err != nil {
+			return stepResult{}, err
+		}
+		if path == "" {
+			break
+		}
+		if err := uploadScreenshot(ctx, c, runID, index, path); err != nil {
+			printMessage(fmt.Sprintf("  Upload failed: %v", err))
+			continue
+		}
+		printMessage("  Screenshot uploaded.")
+		break
+	}
+
+	return stepResult{Name: step.Name, Status: status, Notes: notes}, nil
+}
+
+// uploadScreenshot attaches the file at path to a step of the run.
+func uploadScreenshot(ctx context.Context, c *client.Client, runID uuid.UUID, index int, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = c.UploadRunAsset(ctx, runID, client.UploadRunAssetRequest{
+		AssetType:   testrun.AssetTypeImage,
+		FileName:    path,
+		Content:     f,
+		Description: fmt.Sprintf("Step %d screenshot", index+1),
+		StepIndex:   &index,
+	})
+	return err
+}
+
+// overallStatus is failed if any step failed, passed if any step passed and
+// skipped otherwise.
+func overallStatus(results []stepResult) testrun.Status {
+	status := testrun.StatusSkipped
+	for _, r := range results {
+		switch r.Status {
+		case testrun.StatusFailed:
+			return testrun.StatusFailed
+		case testrun.StatusPassed:
+			status = testrun.StatusPassed
+		}
+	}
+	return status
+}
+
+// completionNotes lists each step's result followed by the tester's notes.
+func completionNotes(results []stepResult, runNotes string) string {
+	var b strings.Builder
+	for i, r := range results {
+		fmt.Fprintf(&b, "Step %d (%s): %s", i+1, r.Name, r.Status)
+		if r.Notes != "" {
+			fmt.Fprintf(&b, " - %s", r.Notes)
+		}
+		b.WriteString("\n")
+	}
+	if runNotes != "" {
+		b.WriteString("\n" + runNotes)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
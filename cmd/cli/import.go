@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Import actions reported for each procedure definition.
+const (
+	importActionCreate    = "create"
+	importActionUpdate    = "update"
+	importActionUnchanged = "unchanged"
+)
+
+// stepImageExts are the image types the backend accepts for step images.
+var stepImageExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+}
+
+// procedureDefinition is a test procedure as written in an import file.
+type procedureDefinition struct {
+	Name        string           `json:"name" yaml:"name"`
+	Description string           `json:"description" yaml:"description"`
+	Steps       []stepDefinition `json:"steps" yaml:"steps"`
+
+	file string
+}
+
+// stepDefinition is a step of a procedureDefinition. Images are paths to
+// local files, relative to the definition file.
+type stepDefinition struct {
+	Name           string   `json:"name" yaml:"name"`
+	Instructions   string   `json:"instructions" yaml:"instructions"`
+	ExpectedResult string   `json:"expected_result" yaml:"expected_result"`
+	Images         []string `json:"images" yaml:"images"`
+
+	images []localImage
+}
+
+// localImage is a step image read from disk. Name is the file name the
+// backend stores it under, which is derived from its content.
+type localImage struct {
+	Path string
+	Name string
+	Data []byte
+}
+
+// importResult reports what the import did with one definition.
+type importResult struct {
+	File        string    `json:"file"`
+	Name        string    `json:"name"`
+	Action      string    `json:"action"`
+	ProcedureID uuid.UUID `json:"procedure_id,omitempty"`
+	Version     uint      `json:"version,omitempty"`
+}
+
+func newProceduresImportCmd() *cobra.Command {
+	var projectID, dir string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create or update test procedures from a directory of definitions",
+		Long: `Reads every .yaml, .yml and .json file in a directory as a test procedure
+definition and makes the project's procedures match them. Procedures are
+matched by name: missing ones are created, changed ones get a new version and
+unchanged ones are left alone, so the command can be re-run safely.
+
+A definition looks like:
+
+  name: Login
+  description: Sign in with a valid account
+  steps:
+    - name: Open the login page
+      instructions: Go to /login
+      images:
+        - images/login.png
+
+Image paths are relative to the definition file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, err := parseID("project-id", projectID)
+			if err != nil {
+				return err
+			}
+
+			defs, err := loadProcedureDefinitions(dir)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			existing := make(map[string][]client.TestProcedure)
+			for p, err := range c.AllProcedures(cmd.Context(), pid) {
+				if err != nil {
+					return err
+				}
+				existing[p.Name] = append(existing[p.Name], p)
+			}
+
+			var results []importResult
+			for _, def := range defs {
+				matches := existing[def.Name]
+				if len(matches) > 1 {
+					return fmt.Errorf("%s: %d procedures in the project are named %q", def.file, len(matches), def.Name)
+				}
+				var current *client.TestProcedure
+				if len(matches) == 1 {
+					current = &matches[0]
+				}
+
+				result, err := importProcedure(cmd.Context(), c, pid, def, current, dryRun)
+				if err != nil {
+					return fmt.Errorf("%s: %w", def.file, err)
+				}
+				results = append(results, result)
+			}
+
+			if flagJSON {
+				printJSON(results)
+				return nil
+			}
+
+			headers := []string{"FILE", "NAME", "ACTION", "ID", "VERSION"}
+			var rows [][]string
+			counts := make(map[string]int)
+			for _, r := range results {
+				id, version := "-", "-"
+				if r.ProcedureID != uuid.Nil {
+					id = r.ProcedureID.String()
+				}
+				if r.Version > 0 {
+					version = fmt.Sprintf("%d", r.Version)
+				}
+				rows = append(rows, []string{r.File, r.Name, r.Action, id, version})
+				counts[r.Action]++
+			}
+			printTable(headers, rows)
+
+			summary := fmt.Sprintf("\n%d created, %d updated, %d unchanged",
+				counts[importActionCreate], counts[importActionUpdate], counts[importActionUnchanged])
+			if dryRun {
+				summary += " (dry run, nothing was changed)"
+			}
+			printMessage(summary)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectID, "project-id", "", "Project ID (required)")
+	cmd.MarkFlagRequired("project-id")
+	cmd.Flags().StringVar(&dir, "dir", "", "Directory containing procedure definitions (required)")
+	cmd.MarkFlagRequired("dir")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report what would change without changing anything")
+	return cmd
+}
+
+// loadProcedureDefinitions reads and validates every definition in dir,
+// sorted by file name, so that nothing is changed if any file is invalid.
+func loadProcedureDefinitions(dir string) ([]*procedureDefinition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var defs []*procedureDefinition
+	files := make(map[string]string)
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		def, err := loadProcedureDefinition(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := files[def.Name]; ok {
+			return nil, fmt.Errorf("%s: procedure %q is also defined in %s", def.file, def.Name, other)
+		}
+		files[def.Name] = def.file
+		defs = append(defs, def)
+	}
+
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("no procedure definitions found in %s", dir)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].file < defs[j].file })
+	return defs, nil
+}
+
+// loadProcedureDefinition reads one definition file and the images its
+// steps refer to.
+func loadProcedureDefinition(path string) (*procedureDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	def := &procedureDefinition{file: path}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(data, def)
+	} else {
+		err = yaml.Unmarshal(data, def)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	def.Name = strings.TrimSpace(def.Name)
+	if def.Name == "" {
+		return nil, fmt.Errorf("%s: name is required", path)
+	}
+
+	for i := range def.Steps {
+		step := &def.Steps[i]
+		if strings.TrimSpace(step.Name) == "" {
+			return nil, fmt.Errorf("%s: step %d: name is required", path, i+1)
+		}
+		for _, imagePath := range step.Images {
+			img, err := readStepImage(filepath.Join(filepath.Dir(path), imagePath))
+			if err != nil {
+				return nil, fmt.Errorf("%s: step %d: %w", path, i+1, err)
+			}
+			step.images = append(step.images, img)
+		}
+	}
+	return def, nil
+}
+
+// readStepImage reads an image file and names it the way the backend will
+// store it: by the SHA-256 of its content.
+func readStepImage(path string) (localImage, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if !stepImageExts[ext] {
+		return localImage{}, fmt.Errorf("image %s must be JPEG, PNG, GIF or WebP", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return localImage{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return localImage{Path: path, Name: hex.EncodeToString(sum[:]) + ext, Data: data}, nil
+}
+
+// importProcedure creates def if current is nil, or commits a new version of
+// current if it differs from def. Images already stored for current are
+// reused rather than uploaded again.
+func importProcedure(ctx context.Context, c *client.Client, projectID uuid.UUID, def *procedureDefinition, current *client.TestProcedure, dryRun bool) (importResult, error) {
+	result := importResult{File: def.file, Name: def.Name}
+
+	if current != nil {
+		result.ProcedureID = current.ID
+		result.Version = current.Version
+		if procedureMatches(def, current) {
+			result.Action = importActionUnchanged
+			return result, nil
+		}
+		result.Action = importActionUpdate
+	} else {
+		result.Action = importActionCreate
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	if current == nil {
+		steps := make([]client.Step, len(def.Steps))
+		for i, step := range def.Steps {
+			steps[i] = client.Step{Name: step.Name, Instructions: step.Instructions, ExpectedResult: step.ExpectedResult, ImagePaths: []string{}}
+		}
+		p, err := c.CreateProcedure(ctx, projectID, client.CreateTestProcedureRequest{
+			Name:        def.Name,
+			Description: def.Description,
+			Steps:       steps,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.ProcedureID = p.ID
+		result.Version = p.Version
+		if !definitionHasImages(def) {
+			return result, nil
+		}
+		// Images can only be uploaded once the procedure exists, so they are
+		// added in a second version.
+		current = p
+	}
+
+	stored := make(map[string]string)
+	for _, step := range current.Steps {
+		for _, path := range step.ImagePaths {
+			stored[filepath.Base(path)] = path
+		}
+	}
+
+	steps := make([]client.Step, len(def.Steps))
+	for i, step := range def.Steps {
+		paths := make([]string, 0, len(step.images))
+		for _, img := range step.images {
+			path, ok := stored[img.Name]
+			if !ok {
+				var err error
+				path, err = c.UploadStepImage(ctx, current.ID, img.Path, bytes.NewReader(img.Data))
+				if err != nil {
+					return result, fmt.Errorf("failed to upload %s: %w", img.Path, err)
+				}
+				stored[img.Name] = path
+			}
+			paths = append(paths, path)
+		}
+		steps[i] = client.Step{Name: step.Name, Instructions: step.Instructions, ExpectedResult: step.ExpectedResult, ImagePaths: paths}
+	}
+
+	if _, err := c.UpdateProcedure(ctx, projectID, current.ID, client.UpdateTestProcedureRequest{
+		Name:        &def.Name,
+		Description: &def.Description,
+		Steps:       &steps,
+	}); err != nil {
+		return result, err
+	}
+	p, err := c.CommitProcedureDraft(ctx, current.ID, client.CommitDraftRequest{Message: "Import " + filepath.Base(def.file)})
+	if err != nil {
+		return result, err
+	}
+	result.Version = p.Version
+	return result, nil
+}
+
+// procedureMatches reports whether p already has the content of def.
+// Images are compared by stored file name, which is a hash of the content.
+func procedureMatches(def *procedureDefinition, p *client.TestProcedure) bool {
+	if def.Description != p.Description || len(def.Steps) != len(p.Steps) {
+		return false
+	}
+	for i, step := range def.Steps {
+		current := p.Steps[i]
+		if step.Name != current.Name || step.Instructions != current.Instructions || step.ExpectedResult != current.ExpectedResult || len(step.images) != len(current.ImagePaths) {
+			return false
+		}
+		for j, img := range step.images {
+			if img.Name != filepath.Base(current.ImagePaths[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// definitionHasImages reports whether any step of def has images.
+func definitionHasImages(def *procedureDefinition) bool {
+	for _, step := range def.Steps {
+		if len(step.images) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/spf13/cobra"
+)
+
+func newIssuesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "issues",
+		Short: "Browse issues in an integration's issue tracker",
+	}
+
+	cmd.AddCommand(newIssuesSearchCmd())
+	return cmd
+}
+
+func newIssuesSearchCmd() *cobra.Command {
+	var integrationID string
+	var opts client.SearchIssuesOptions
+
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Search issues in an integration's issue tracker",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			iid, err := parseID("integration-id", integrationID)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			resp, err := c.SearchIssues(cmd.Context(), iid, &opts)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(resp)
+				return nil
+			}
+
+			headers := []string{"ID", "STATUS", "TITLE", "URL"}
+			var rows [][]string
+			for _, i := range resp.Items {
+				rows = append(rows, []string{i.ExternalID, i.Status, i.Title, i.URL})
+			}
+			printTable(headers, rows)
+			if resp.Total != nil {
+				printMessage(fmt.Sprintf("\nShowing %d of %d issues", len(resp.Items), *resp.Total))
+			} else {
+				printMessage(fmt.Sprintf("\nShowing %d issues", len(resp.Items)))
+			}
+			switch {
+			case resp.NextCursor != "":
+				printMessage(fmt.Sprintf("More issues: --cursor %s", resp.NextCursor))
+			case resp.HasMore:
+				printMessage(fmt.Sprintf("More issues: --offset %d", resp.Offset+len(resp.Items)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&integrationID, "integration-id", "", "Integration ID (required)")
+	cmd.MarkFlagRequired("integration-id")
+	cmd.Flags().StringVar(&opts.Query, "query", "", "Text to search for")
+	cmd.Flags().StringVar(&opts.Status, "status", "", "Issue status")
+	cmd.Flags().StringVar(&opts.ProjectKey, "project-key", "", "Jira project key")
+	cmd.Flags().StringVar(&opts.Repository, "repository", "", "GitHub repository (owner/repo)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "Maximum number of results")
+	cmd.Flags().IntVar(&opts.Offset, "offset", 0, "Offset for pagination")
+	cmd.Flags().StringVar(&opts.Cursor, "cursor", "", "Cursor for pagination, from a previous search")
+	return cmd
+}
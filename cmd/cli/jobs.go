@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/spf13/cobra"
+)
+
+func newJobsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Manage background jobs",
+	}
+
+	cmd.AddCommand(newJobsCreateCmd())
+	cmd.AddCommand(newJobsListCmd())
+	cmd.AddCommand(newJobsGetCmd())
+	cmd.AddCommand(newJobsStopCmd())
+	cmd.AddCommand(newJobsLogsCmd())
+	return cmd
+}
+
+func newJobsCreateCmd() *cobra.Command {
+	var jobType, projectID, endpointID, endpointGroup, environment, procedureName, procedureID, configFile string
+	var follow bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a job",
+		Long: "Creates a job. Job-specific settings can be given as a JSON object in --config-file; " +
+			"flags override the same keys in the file. With --follow the job's log is tailed " +
+			"until it finishes and the command fails unless the job succeeds.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := map[string]interface{}{}
+			if configFile != "" {
+				data, err := os.ReadFile(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to read config file: %w", err)
+				}
+				if err := json.Unmarshal(data, &config); err != nil {
+					return fmt.Errorf("failed to parse config file: %w", err)
+				}
+			}
+			for key, value := range map[string]string{
+				"project_id":     projectID,
+				"endpoint_id":    endpointID,
+				"endpoint_group": endpointGroup,
+				"environment":    environment,
+				"procedure_name": procedureName,
+				"procedure_id":   procedureID,
+			} {
+				if value != "" {
+					config[key] = value
+				}
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			j, err := c.CreateJob(cmd.Context(), client.CreateJobRequest{
+				Type:   job.JobType(jobType),
+				Config: config,
+			})
+			if err != nil {
+				return err
+			}
+
+			if flagJSON && !follow {
+				printJSON(j)
+				return nil
+			}
+
+			printMessage(fmt.Sprintf("Job created: %s (type: %s, status: %s)", j.ID, j.Type, j.Status))
+			if !follow {
+				return nil
+			}
+			return followJobLogs(cmd.Context(), c, j.ID, interval)
+		},
+	}
+
+	cmd.Flags().StringVar(&jobType, "type", string(job.JobTypeUIExploration), "Job type: ui_exploration, visual_regression, link_check or procedure_execution")
+	cmd.Flags().StringVar(&projectID, "project-id", "", "Project ID")
+	cmd.Flags().StringVar(&endpointID, "endpoint-id", "", "Endpoint ID to run against")
+	cmd.Flags().StringVar(&endpointGroup, "endpoint-group", "", "Endpoint group to run against (with --environment)")
+	cmd.Flags().StringVar(&environment, "environment", "", "Environment of the endpoint group")
+	cmd.Flags().StringVar(&procedureName, "procedure-name", "", "Name of the procedure an exploration job creates")
+	cmd.Flags().StringVar(&procedureID, "procedure-id", "", "Procedure a procedure_execution job executes")
+	cmd.Flags().StringVar(&configFile, "config-file", "", "JSON file containing the job config")
+	cmd.Flags().BoolVar(&follow, "follow", false, "Tail the job log until the job finishes")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Polling interval when following")
+	return cmd
+}
+
+func newJobsListCmd() *cobra.Command {
+	var limit, offset int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List jobs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			resp, err := c.ListJobs(cmd.Context(), &client.ListOptions{Limit: limit, Offset: offset})
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(resp)
+				return nil
+			}
+
+			headers := []string{"ID", "TYPE", "STATUS", "DURATION", "CREATED AT"}
+			var rows [][]string
+			for _, j := range resp.Items {
+				rows = append(rows, []string{
+					j.ID.String(),
+					string(j.Type),
+					string(j.Status),
+					formatJobDuration(j),
+					j.CreatedAt.Format("2006-01-02 15:04:05"),
+				})
+			}
+			printTable(headers, rows)
+			printMessage(fmt.Sprintf("\nShowing %d of %d jobs", len(resp.Items), resp.Total))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of results")
+	cmd.Flags().IntVar(&offset, "offset", 0, "Offset for pagination")
+	return cmd
+}
+
+func newJobsGetCmd() *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get a job by ID",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			j, err := c.GetJob(cmd.Context(), jobID)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(j)
+				return nil
+			}
+
+			endpointID := "-"
+			if j.EndpointID != nil {
+				endpointID = j.EndpointID.String()
+			}
+			startTime := "-"
+			if j.StartTime != nil {
+				startTime = j.StartTime.Format("2006-01-02 15:04:05")
+			}
+			endTime := "-"
+			if j.EndTime != nil {
+				endTime = j.EndTime.Format("2006-01-02 15:04:05")
+			}
+
+			headers := []string{"FIELD", "VALUE"}
+			rows := [][]string{
+				{"ID", j.ID.String()},
+				{"Type", string(j.Type)},
+				{"Status", string(j.Status)},
+				{"Endpoint ID", endpointID},
+				{"Started At", startTime},
+				{"Ended At", endTime},
+				{"Duration", formatJobDuration(*j)},
+				{"Created At", j.CreatedAt.Format("2006-01-02 15:04:05")},
+			}
+			printTable(headers, rows)
+
+			if len(j.Result) > 0 {
+				printMessage("\nResult:")
+				keys := make([]string, 0, len(j.Result))
+				for key := range j.Result {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					printMessage(fmt.Sprintf("  %s: %s", key, formatResultValue(j.Result[key])))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Job ID (required)")
+	cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+func newJobsStopCmd() *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a running job",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			j, err := c.StopJob(cmd.Context(), jobID)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(j)
+				return nil
+			}
+
+			printMessage(fmt.Sprintf("Job stopped: %s (status: %s)", j.ID, j.Status))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Job ID (required)")
+	cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+func newJobsLogsCmd() *cobra.Command {
+	var id string
+	var follow bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print a job's log",
+		Long: "Prints a job's log. With --follow the log is tailed until the job finishes " +
+			"and the command fails unless the job succeeds.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			if follow {
+				return followJobLogs(cmd.Context(), c, jobID, interval)
+			}
+
+			after := 0
+			for {
+				logs, err := c.ListJobLogs(cmd.Context(), jobID, after)
+				if err != nil {
+					return err
+				}
+				if len(logs.Items) == 0 {
+					return nil
+				}
+				after = printJobLogs(logs.Items)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Job ID (required)")
+	cmd.MarkFlagRequired("id")
+	cmd.Flags().BoolVar(&follow, "follow", false, "Keep printing new lines until the job finishes")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Polling interval when following")
+	return cmd
+}
+
+// followJobLogs prints a job's log as it is written until the job finishes.
+// It returns an error unless the job succeeded, so CI pipelines fail with it.
+func followJobLogs(ctx context.Context, c *client.Client, jobID uuid.UUID, interval time.Duration) error {
+	after := 0
+	for {
+		logs, err := c.ListJobLogs(ctx, jobID, after)
+		if err != nil {
+			return err
+		}
+		if len(logs.Items) > 0 {
+			after = printJobLogs(logs.Items)
+			continue
+		}
+
+		if logs.Status.IsFinal() {
+			if logs.Status != job.StatusSuccess {
+				return fmt.Errorf("job %s finished with status %s", jobID, logs.Status)
+			}
+			printMessage(fmt.Sprintf("Job %s finished with status %s", jobID, logs.Status))
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printJobLogs prints log lines and returns the sequence number of the last.
+func printJobLogs(entries []client.JobLogEntry) int {
+	for _, e := range entries {
+		printMessage(fmt.Sprintf("%s  %s", e.CreatedAt.Local().Format("15:04:05"), e.Message))
+	}
+	return entries[len(entries)-1].Seq
+}
+
+// formatJobDuration formats a job's duration in seconds, or "-" if unknown.
+func formatJobDuration(j client.Job) string {
+	if j.Duration == nil {
+		return "-"
+	}
+	return (time.Duration(*j.Duration) * time.Second).String()
+}
+
+// formatResultValue renders a job result value on one line.
+func formatResultValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []interface{}:
+		return fmt.Sprintf("%d item(s)", len(val))
+	case map[string]interface{}:
+		data, _ := json.Marshal(val)
+		return truncate(string(data), 80)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -24,7 +24,7 @@ func main() {
 	rootCmd := &cobra.Command{
 		Use:   "uictl",
 		Short: "CLI for UI Automation backend",
-		Long:  "A command-line interface for managing projects, test procedures, test runs, and API tokens in the UI Automation system.",
+		Long:  "A command-line interface for managing projects, test procedures, test runs, API tokens, and linked issues in the UI Automation system.",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return initConfig()
 		},
@@ -49,6 +49,8 @@ func main() {
 	rootCmd.AddCommand(newProceduresCmd())
 	rootCmd.AddCommand(newRunsCmd())
 	rootCmd.AddCommand(newTokensCmd())
+	rootCmd.AddCommand(newJobsCmd())
+	rootCmd.AddCommand(newIssuesCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
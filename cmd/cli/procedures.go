@@ -3,10 +3,11 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
 	"strconv"
 
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/hairizuanbinnoorazman/ui-automation/quota"
 	"github.com/spf13/cobra"
 )
 
@@ -21,49 +22,62 @@ func newProceduresCmd() *cobra.Command {
 	cmd.AddCommand(newProceduresGetCmd())
 	cmd.AddCommand(newProceduresUpdateCmd())
 	cmd.AddCommand(newProceduresDeleteCmd())
+	cmd.AddCommand(newProceduresArchiveCmd(true))
+	cmd.AddCommand(newProceduresArchiveCmd(false))
 	cmd.AddCommand(newProceduresCreateVersionCmd())
 	cmd.AddCommand(newProceduresVersionsCmd())
+	cmd.AddCommand(newProceduresImportCmd())
+	cmd.AddCommand(newProceduresAttachmentCmd())
 	return cmd
 }
 
+// readStepsFile reads a JSON array of steps from path.
+func readStepsFile(path string) ([]client.Step, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read steps file: %w", err)
+	}
+	var steps []client.Step
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("failed to parse steps file: %w", err)
+	}
+	return steps, nil
+}
+
 func newProceduresListCmd() *cobra.Command {
 	var projectID string
 	var limit, offset int
+	var includeArchived bool
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List test procedures for a project",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			pid, err := parseID("project-id", projectID)
 			if err != nil {
 				return err
 			}
 
-			query := url.Values{}
-			if limit > 0 {
-				query.Set("limit", strconv.Itoa(limit))
-			}
-			if offset > 0 {
-				query.Set("offset", strconv.Itoa(offset))
+			c, err := getClient()
+			if err != nil {
+				return err
 			}
 
-			body, err := client.Get(fmt.Sprintf("/api/v1/projects/%s/procedures", projectID), query)
+			opts := &client.ListOptions{Limit: limit, Offset: offset}
+			list := c.ListProcedures
+			if includeArchived {
+				list = c.ListProceduresIncludingArchived
+			}
+			resp, err := list(cmd.Context(), pid, opts)
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(resp)
 				return nil
 			}
 
-			var resp PaginatedResponse[TestProcedureResponse]
-			if err := json.Unmarshal(body, &resp); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			headers := []string{"ID", "NAME", "VERSION", "IS LATEST", "CREATED AT"}
 			var rows [][]string
 			for _, p := range resp.Items {
@@ -85,6 +99,7 @@ func newProceduresListCmd() *cobra.Command {
 	cmd.MarkFlagRequired("project-id")
 	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of results")
 	cmd.Flags().IntVar(&offset, "offset", 0, "Offset for pagination")
+	cmd.Flags().BoolVar(&includeArchived, "include-archived", false, "Include archived procedures")
 	return cmd
 }
 
@@ -95,43 +110,38 @@ func newProceduresCreateCmd() *cobra.Command {
 		Use:   "create",
 		Short: "Create a new test procedure",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			pid, err := parseID("project-id", projectID)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			req := CreateTestProcedureRequest{
+			req := client.CreateTestProcedureRequest{
 				Name:        name,
 				Description: description,
 			}
 
 			if stepsFile != "" {
-				data, err := os.ReadFile(stepsFile)
+				req.Steps, err = readStepsFile(stepsFile)
 				if err != nil {
-					return fmt.Errorf("failed to read steps file: %w", err)
-				}
-				if err := json.Unmarshal(data, &req.Steps); err != nil {
-					return fmt.Errorf("failed to parse steps file: %w", err)
+					return err
 				}
 			}
 
-			body, err := client.Post(fmt.Sprintf("/api/v1/projects/%s/procedures", projectID), req)
+			p, err := c.CreateProcedure(cmd.Context(), pid, req)
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(p)
 				return nil
 			}
 
-			var p TestProcedureResponse
-			if err := json.Unmarshal(body, &p); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			printMessage(fmt.Sprintf("Test procedure created: %s (%s)", p.Name, p.ID))
 			return nil
 		},
@@ -154,33 +164,30 @@ func newProceduresGetCmd() *cobra.Command {
 		Use:   "get",
 		Short: "Get a test procedure by ID",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			pid, err := parseID("project-id", projectID)
+			if err != nil {
+				return err
+			}
+			procedureID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			query := url.Values{}
-			if draft {
-				query.Set("draft", "true")
+			c, err := getClient()
+			if err != nil {
+				return err
 			}
 
-			body, err := client.Get(fmt.Sprintf("/api/v1/projects/%s/procedures/%s", projectID, id), query)
+			p, err := c.GetProcedure(cmd.Context(), pid, procedureID, draft)
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(p)
 				return nil
 			}
 
-			var p TestProcedureResponse
-			if err := json.Unmarshal(body, &p); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			headers := []string{"FIELD", "VALUE"}
 			rows := [][]string{
 				{"ID", p.ID.String()},
@@ -195,6 +202,13 @@ func newProceduresGetCmd() *cobra.Command {
 			}
 			printTable(headers, rows)
 
+			if len(p.Preconditions) > 0 {
+				printMessage("\nPreconditions:")
+				for _, precondition := range p.Preconditions {
+					printMessage(fmt.Sprintf("  - %s: %s", precondition.Kind, precondition.Description))
+				}
+			}
+
 			if len(p.Steps) > 0 {
 				printMessage("\nSteps:")
 				for i, step := range p.Steps {
@@ -202,6 +216,12 @@ func newProceduresGetCmd() *cobra.Command {
 					if step.Instructions != "" {
 						printMessage(fmt.Sprintf("     %s", step.Instructions))
 					}
+					if step.ExpectedResult != "" {
+						printMessage(fmt.Sprintf("     Expected: %s", step.ExpectedResult))
+					}
+					for _, attachment := range step.Attachments {
+						printMessage(fmt.Sprintf("     Attachment: %s (%s)", attachment.FileName, quota.FormatBytes(attachment.FileSize)))
+					}
 				}
 			}
 			return nil
@@ -223,12 +243,21 @@ func newProceduresUpdateCmd() *cobra.Command {
 		Use:   "update",
 		Short: "Update a test procedure draft",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			pid, err := parseID("project-id", projectID)
+			if err != nil {
+				return err
+			}
+			procedureID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			req := UpdateTestProcedureRequest{}
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			req := client.UpdateTestProcedureRequest{}
 			if cmd.Flags().Changed("name") {
 				req.Name = &name
 			}
@@ -236,38 +265,23 @@ func newProceduresUpdateCmd() *cobra.Command {
 				req.Description = &description
 			}
 			if stepsFile != "" {
-				data, err := os.ReadFile(stepsFile)
+				steps, err := readStepsFile(stepsFile)
 				if err != nil {
-					return fmt.Errorf("failed to read steps file: %w", err)
-				}
-				var steps []struct {
-					Name         string   `json:"name"`
-					Instructions string   `json:"instructions"`
-					ImagePaths   []string `json:"image_paths"`
-				}
-				if err := json.Unmarshal(data, &steps); err != nil {
-					return fmt.Errorf("failed to parse steps file: %w", err)
+					return err
 				}
 				req.Steps = &steps
 			}
 
-			body, err := client.Put(fmt.Sprintf("/api/v1/projects/%s/procedures/%s", projectID, id), req)
+			p, err := c.UpdateProcedure(cmd.Context(), pid, procedureID, req)
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(p)
 				return nil
 			}
 
-			var p TestProcedureResponse
-			if err := json.Unmarshal(body, &p); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			printMessage(fmt.Sprintf("Test procedure updated: %s (%s)", p.Name, p.ID))
 			return nil
 		},
@@ -291,18 +305,26 @@ func newProceduresDeleteCmd() *cobra.Command {
 		Use:   "delete",
 		Short: "Delete a test procedure",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, err := parseID("project-id", projectID)
+			if err != nil {
+				return err
+			}
+			procedureID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
 			if !confirmAction(fmt.Sprintf("Delete test procedure %s?", id), yes) {
 				printMessage("Aborted.")
 				return nil
 			}
 
-			client, err := getClient()
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			_, err = client.Delete(fmt.Sprintf("/api/v1/projects/%s/procedures/%s", projectID, id))
-			if err != nil {
+			if err := c.DeleteProcedure(cmd.Context(), pid, procedureID); err != nil {
 				return err
 			}
 
@@ -319,6 +341,60 @@ func newProceduresDeleteCmd() *cobra.Command {
 	return cmd
 }
 
+// newProceduresArchiveCmd returns the archive command, or the unarchive
+// command when archive is false.
+func newProceduresArchiveCmd(archive bool) *cobra.Command {
+	var projectID, id string
+
+	use, short, done := "unarchive", "Restore an archived test procedure", "unarchived"
+	if archive {
+		use, short, done = "archive", "Archive a test procedure, hiding it from lists and blocking new runs", "archived"
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, err := parseID("project-id", projectID)
+			if err != nil {
+				return err
+			}
+			procedureID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			set := c.UnarchiveProcedure
+			if archive {
+				set = c.ArchiveProcedure
+			}
+			p, err := set(cmd.Context(), pid, procedureID)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(p)
+				return nil
+			}
+
+			printMessage(fmt.Sprintf("Test procedure %s.", done))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectID, "project-id", "", "Project ID (required)")
+	cmd.MarkFlagRequired("project-id")
+	cmd.Flags().StringVar(&id, "id", "", "Procedure ID (required)")
+	cmd.MarkFlagRequired("id")
+	return cmd
+}
+
 func newProceduresCreateVersionCmd() *cobra.Command {
 	var projectID, id string
 
@@ -326,26 +402,28 @@ func newProceduresCreateVersionCmd() *cobra.Command {
 		Use:   "create-version",
 		Short: "Commit the draft as a new version",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			pid, err := parseID("project-id", projectID)
+			if err != nil {
+				return err
+			}
+			procedureID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			body, err := client.Post(fmt.Sprintf("/api/v1/projects/%s/procedures/%s/versions", projectID, id), nil)
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
-				return nil
+			p, err := c.CreateProcedureVersion(cmd.Context(), pid, procedureID)
+			if err != nil {
+				return err
 			}
 
-			var p TestProcedureResponse
-			if err := json.Unmarshal(body, &p); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
+			if flagJSON {
+				printJSON(p)
+				return nil
 			}
 
 			printMessage(fmt.Sprintf("New version created: v%d (%s)", p.Version, p.ID))
@@ -367,36 +445,43 @@ func newProceduresVersionsCmd() *cobra.Command {
 		Use:   "versions",
 		Short: "List version history for a test procedure",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			pid, err := parseID("project-id", projectID)
+			if err != nil {
+				return err
+			}
+			procedureID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			body, err := client.Get(fmt.Sprintf("/api/v1/projects/%s/procedures/%s/versions", projectID, id), nil)
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
-				return nil
+			versions, err := c.ListProcedureVersions(cmd.Context(), pid, procedureID)
+			if err != nil {
+				return err
 			}
 
-			var versions []TestProcedureResponse
-			if err := json.Unmarshal(body, &versions); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
+			if flagJSON {
+				printJSON(versions)
+				return nil
 			}
 
-			headers := []string{"ID", "VERSION", "NAME", "IS LATEST", "CREATED AT"}
+			headers := []string{"ID", "VERSION", "NAME", "IS LATEST", "MESSAGE", "CREATED AT"}
 			var rows [][]string
 			for _, v := range versions {
+				message := v.CommitMessage
+				if message == "" {
+					message = "-"
+				}
 				rows = append(rows, []string{
 					v.ID.String(),
 					strconv.Itoa(int(v.Version)),
 					v.Name,
 					fmt.Sprintf("%v", v.IsLatest),
+					message,
 					v.CreatedAt.Format("2006-01-02 15:04:05"),
 				})
 			}
@@ -411,3 +496,47 @@ func newProceduresVersionsCmd() *cobra.Command {
 	cmd.MarkFlagRequired("id")
 	return cmd
 }
+
+func newProceduresAttachmentCmd() *cobra.Command {
+	var id, output string
+	var step, index int
+
+	cmd := &cobra.Command{
+		Use:   "attachment",
+		Short: "Download a file attached to a step of a procedure version or draft",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			procedureID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+			if step < 1 || index < 1 {
+				return fmt.Errorf("--step and --index are numbered from 1")
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			content, err := c.DownloadStepAttachment(cmd.Context(), procedureID, step-1, index-1)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(output, content, 0o644); err != nil {
+				return fmt.Errorf("failed to write attachment: %w", err)
+			}
+
+			printMessage(fmt.Sprintf("Attachment saved to %s", output))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Procedure version or draft ID (required)")
+	cmd.MarkFlagRequired("id")
+	cmd.Flags().IntVar(&step, "step", 0, "Step number, from 1 (required)")
+	cmd.MarkFlagRequired("step")
+	cmd.Flags().IntVar(&index, "index", 1, "Attachment number within the step, from 1")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write (required)")
+	cmd.MarkFlagRequired("output")
+	return cmd
+}
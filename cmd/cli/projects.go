@@ -1,11 +1,11 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/url"
-	"strconv"
+	"os"
 
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
+	"github.com/hairizuanbinnoorazman/ui-automation/quota"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +20,10 @@ func newProjectsCmd() *cobra.Command {
 	cmd.AddCommand(newProjectsGetCmd())
 	cmd.AddCommand(newProjectsUpdateCmd())
 	cmd.AddCommand(newProjectsDeleteCmd())
+	cmd.AddCommand(newProjectsExportCmd())
+	cmd.AddCommand(newProjectsImportCmd())
+	cmd.AddCommand(newProjectsUsageCmd())
+	cmd.AddCommand(newProjectsRetentionCmd())
 	return cmd
 }
 
@@ -30,36 +34,21 @@ func newProjectsListCmd() *cobra.Command {
 		Use:   "list",
 		Short: "List projects",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			query := url.Values{}
-			if limit > 0 {
-				query.Set("limit", strconv.Itoa(limit))
-			}
-			if offset > 0 {
-				query.Set("offset", strconv.Itoa(offset))
-			}
-
-			body, err := client.Get("/api/v1/projects", query)
+			resp, err := c.ListProjects(cmd.Context(), &client.ListOptions{Limit: limit, Offset: offset})
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(resp)
 				return nil
 			}
 
-			var resp PaginatedResponse[ProjectResponse]
-			if err := json.Unmarshal(body, &resp); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			headers := []string{"ID", "NAME", "DESCRIPTION", "CREATED AT"}
 			var rows [][]string
 			for _, p := range resp.Items {
@@ -88,33 +77,24 @@ func newProjectsCreateCmd() *cobra.Command {
 		Use:   "create",
 		Short: "Create a new project",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			req := CreateProjectRequest{
+			p, err := c.CreateProject(cmd.Context(), client.CreateProjectRequest{
 				Name:        name,
 				Description: description,
-			}
-
-			body, err := client.Post("/api/v1/projects", req)
+			})
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(p)
 				return nil
 			}
 
-			var p ProjectResponse
-			if err := json.Unmarshal(body, &p); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			printMessage(fmt.Sprintf("Project created: %s (%s)", p.Name, p.ID))
 			return nil
 		},
@@ -133,26 +113,24 @@ func newProjectsGetCmd() *cobra.Command {
 		Use:   "get",
 		Short: "Get a project by ID",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			projectID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			body, err := client.Get(fmt.Sprintf("/api/v1/projects/%s", id), nil)
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
-				return nil
+			p, err := c.GetProject(cmd.Context(), projectID)
+			if err != nil {
+				return err
 			}
 
-			var p ProjectResponse
-			if err := json.Unmarshal(body, &p); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
+			if flagJSON {
+				printJSON(p)
+				return nil
 			}
 
 			headers := []string{"FIELD", "VALUE"}
@@ -182,12 +160,17 @@ func newProjectsUpdateCmd() *cobra.Command {
 		Use:   "update",
 		Short: "Update a project",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			projectID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			req := UpdateProjectRequest{}
+			req := client.UpdateProjectRequest{}
 			if cmd.Flags().Changed("name") {
 				req.Name = &name
 			}
@@ -195,23 +178,16 @@ func newProjectsUpdateCmd() *cobra.Command {
 				req.Description = &description
 			}
 
-			body, err := client.Put(fmt.Sprintf("/api/v1/projects/%s", id), req)
+			p, err := c.UpdateProject(cmd.Context(), projectID, req)
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(p)
 				return nil
 			}
 
-			var p ProjectResponse
-			if err := json.Unmarshal(body, &p); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			printMessage(fmt.Sprintf("Project updated: %s (%s)", p.Name, p.ID))
 			return nil
 		},
@@ -232,18 +208,22 @@ func newProjectsDeleteCmd() *cobra.Command {
 		Use:   "delete",
 		Short: "Delete a project",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
 			if !confirmAction(fmt.Sprintf("Delete project %s?", id), yes) {
 				printMessage("Aborted.")
 				return nil
 			}
 
-			client, err := getClient()
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			_, err = client.Delete(fmt.Sprintf("/api/v1/projects/%s", id))
-			if err != nil {
+			if err := c.DeleteProject(cmd.Context(), projectID); err != nil {
 				return err
 			}
 
@@ -258,6 +238,218 @@ func newProjectsDeleteCmd() *cobra.Command {
 	return cmd
 }
 
+func newProjectsExportCmd() *cobra.Command {
+	var id, output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a project, with all procedure versions and runs, to an archive file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			archive, err := c.ExportProject(cmd.Context(), projectID)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				output = fmt.Sprintf("project-%s.json", projectID)
+			}
+			if err := os.WriteFile(output, archive, 0o644); err != nil {
+				return fmt.Errorf("failed to write archive: %w", err)
+			}
+
+			printMessage(fmt.Sprintf("Project exported to %s", output))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Project ID (required)")
+	cmd.MarkFlagRequired("id")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Archive file to write (default project-<id>.json)")
+	return cmd
+}
+
+func newProjectsImportCmd() *cobra.Command {
+	var file, name string
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create a new project from an exported archive",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			archive, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read archive: %w", err)
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			result, err := c.ImportProject(cmd.Context(), archive, name)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(result)
+				return nil
+			}
+
+			printMessage(fmt.Sprintf("Project imported: %s (%s)", result.Project.Name, result.Project.ID))
+			printMessage(fmt.Sprintf("%d procedure versions, %d runs, %d step notes, %d assets",
+				result.Procedures, result.Runs, result.StepNotes, result.Assets))
+			if result.MissingAssets > 0 {
+				printMessage(fmt.Sprintf("Warning: the content of %d assets could not be copied", result.MissingAssets))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Archive file to import (required)")
+	cmd.MarkFlagRequired("file")
+	cmd.Flags().StringVar(&name, "name", "", "Name for the imported project (default: the archived name)")
+	return cmd
+}
+
+func newProjectsUsageCmd() *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show the storage a project uses and its quota",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			usage, err := c.GetProjectStorageUsage(cmd.Context(), projectID)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(usage)
+				return nil
+			}
+
+			quotaStr := "unlimited"
+			if usage.QuotaBytes > 0 {
+				quotaStr = fmt.Sprintf("%s (%.1f%% used)", quota.FormatBytes(usage.QuotaBytes),
+					float64(usage.TotalBytes)/float64(usage.QuotaBytes)*100)
+			}
+
+			headers := []string{"STORED", "SIZE"}
+			rows := [][]string{
+				{"Run assets", quota.FormatBytes(usage.AssetBytes)},
+				{"Scripts", quota.FormatBytes(usage.ScriptBytes)},
+				{"Step images", quota.FormatBytes(usage.StepImageBytes)},
+				{"Step attachments", quota.FormatBytes(usage.StepAttachmentBytes)},
+				{"Total", quota.FormatBytes(usage.TotalBytes)},
+				{"Quota", quotaStr},
+			}
+			printTable(headers, rows)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Project ID (required)")
+	cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+func newProjectsRetentionCmd() *cobra.Command {
+	var (
+		id      string
+		days    int
+		preview bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "retention",
+		Short: "Show or set how long a project's run assets are kept",
+		Long: `Show or set how long a project's run assets are kept.
+
+With --days the policy is changed; 0 keeps assets forever. With --preview the
+assets the policy would delete now are reported without deleting anything, or
+with --days too, the assets a policy of that many days would delete.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			if preview {
+				var daysOverride *int
+				if cmd.Flags().Changed("days") {
+					daysOverride = &days
+				}
+				report, err := c.PreviewRetention(cmd.Context(), projectID, daysOverride)
+				if err != nil {
+					return err
+				}
+
+				if flagJSON {
+					printJSON(report)
+					return nil
+				}
+
+				fmt.Printf("Assets uploaded before %s would be deleted:\n", report.Cutoff.Format("2006-01-02 15:04"))
+				fmt.Printf("  %d assets in %d runs, %s\n", report.Assets, report.Runs, quota.FormatBytes(report.Bytes))
+				return nil
+			}
+
+			var policy *client.RetentionPolicy
+			if cmd.Flags().Changed("days") {
+				policy, err = c.SetRetentionPolicy(cmd.Context(), projectID, days)
+			} else {
+				policy, err = c.GetRetentionPolicy(cmd.Context(), projectID)
+			}
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(policy)
+				return nil
+			}
+
+			if policy.AssetRetentionDays == 0 {
+				fmt.Println("Run assets are kept forever")
+			} else {
+				fmt.Printf("Run assets are deleted %d days after upload\n", policy.AssetRetentionDays)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Project ID (required)")
+	cmd.Flags().IntVar(&days, "days", 0, "Days to keep run assets after upload; 0 keeps them forever")
+	cmd.Flags().BoolVar(&preview, "preview", false, "Report what would be deleted without deleting anything")
+	cmd.MarkFlagRequired("id")
+	return cmd
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
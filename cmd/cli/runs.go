@@ -1,11 +1,13 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/url"
-	"strconv"
+	"os"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
 	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
 	"github.com/spf13/cobra"
 )
@@ -20,8 +22,20 @@ func newRunsCmd() *cobra.Command {
 	cmd.AddCommand(newRunsCreateCmd())
 	cmd.AddCommand(newRunsGetCmd())
 	cmd.AddCommand(newRunsUpdateCmd())
+	cmd.AddCommand(newRunsClaimCmd())
+	cmd.AddCommand(newRunsQueueCmd())
 	cmd.AddCommand(newRunsStartCmd())
 	cmd.AddCommand(newRunsCompleteCmd())
+	cmd.AddCommand(newRunsStatusCmd("pause", "Pause a running test run", true, (*client.Client).PauseRun))
+	cmd.AddCommand(newRunsStatusCmd("resume", "Resume a paused test run", false, func(c *client.Client, ctx context.Context, id uuid.UUID, _ string) (*client.TestRun, error) {
+		return c.ResumeRun(ctx, id)
+	}))
+	cmd.AddCommand(newRunsStatusCmd("block", "Mark a test run as blocked", true, (*client.Client).BlockRun))
+	cmd.AddCommand(newRunsStatusCmd("unblock", "Unblock a blocked test run", false, func(c *client.Client, ctx context.Context, id uuid.UUID, _ string) (*client.TestRun, error) {
+		return c.UnblockRun(ctx, id)
+	}))
+	cmd.AddCommand(newRunsExecuteCmd())
+	cmd.AddCommand(newRunsReportCmd())
 	return cmd
 }
 
@@ -33,36 +47,26 @@ func newRunsListCmd() *cobra.Command {
 		Use:   "list",
 		Short: "List test runs for a procedure",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			pid, err := parseID("procedure-id", procedureID)
 			if err != nil {
 				return err
 			}
 
-			query := url.Values{}
-			if limit > 0 {
-				query.Set("limit", strconv.Itoa(limit))
-			}
-			if offset > 0 {
-				query.Set("offset", strconv.Itoa(offset))
+			c, err := getClient()
+			if err != nil {
+				return err
 			}
 
-			body, err := client.Get(fmt.Sprintf("/api/v1/procedures/%s/runs", procedureID), query)
+			resp, err := c.ListRuns(cmd.Context(), pid, &client.ListOptions{Limit: limit, Offset: offset})
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(resp)
 				return nil
 			}
 
-			var resp PaginatedResponse[TestRunResponse]
-			if err := json.Unmarshal(body, &resp); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			headers := []string{"ID", "PROCEDURE ID", "STATUS", "VERSION", "STARTED AT", "COMPLETED AT"}
 			var rows [][]string
 			for _, r := range resp.Items {
@@ -97,32 +101,47 @@ func newRunsListCmd() *cobra.Command {
 }
 
 func newRunsCreateCmd() *cobra.Command {
-	var procedureID string
+	var procedureID, assignedTo, due string
+	var params, env map[string]string
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new test run",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			pid, err := parseID("procedure-id", procedureID)
 			if err != nil {
 				return err
 			}
 
-			body, err := client.Post(fmt.Sprintf("/api/v1/procedures/%s/runs", procedureID), nil)
+			req := client.CreateRunRequest{Parameters: params, EnvironmentDetails: env}
+			if assignedTo != "" {
+				userID, err := parseID("assigned-to", assignedTo)
+				if err != nil {
+					return err
+				}
+				req.AssignedTo = &userID
+			}
+			if due != "" {
+				dueAt, err := time.Parse(time.RFC3339, due)
+				if err != nil {
+					return fmt.Errorf("invalid --due: %w", err)
+				}
+				req.DueAt = &dueAt
+			}
+
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
-				return nil
+			r, err := c.CreateRunWith(cmd.Context(), pid, req)
+			if err != nil {
+				return err
 			}
 
-			var r TestRunResponse
-			if err := json.Unmarshal(body, &r); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
+			if flagJSON {
+				printJSON(r)
+				return nil
 			}
 
 			printMessage(fmt.Sprintf("Test run created: %s (status: %s)", r.ID, r.Status))
@@ -132,6 +151,10 @@ func newRunsCreateCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&procedureID, "procedure-id", "", "Test procedure ID (required)")
 	cmd.MarkFlagRequired("procedure-id")
+	cmd.Flags().StringToStringVar(&params, "param", nil, "Procedure parameter value as name=value (repeatable)")
+	cmd.Flags().StringToStringVar(&env, "env", nil, "Run template environment field value as name=value (repeatable)")
+	cmd.Flags().StringVar(&assignedTo, "assigned-to", "", "User ID to assign the run to (unassigned if omitted)")
+	cmd.Flags().StringVar(&due, "due", "", "When the run is due, as an RFC 3339 time")
 	return cmd
 }
 
@@ -142,26 +165,24 @@ func newRunsGetCmd() *cobra.Command {
 		Use:   "get",
 		Short: "Get a test run by ID",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			runID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			body, err := client.Get(fmt.Sprintf("/api/v1/runs/%s", id), nil)
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
-				return nil
+			r, err := c.GetRun(cmd.Context(), runID)
+			if err != nil {
+				return err
 			}
 
-			var r TestRunResponse
-			if err := json.Unmarshal(body, &r); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
+			if flagJSON {
+				printJSON(r)
+				return nil
 			}
 
 			startedAt := "-"
@@ -176,14 +197,20 @@ func newRunsGetCmd() *cobra.Command {
 			if r.AssignedTo != nil {
 				assignedTo = r.AssignedTo.String()
 			}
+			dueAt := "-"
+			if r.DueAt != nil {
+				dueAt = r.DueAt.Format("2006-01-02 15:04:05")
+			}
 
 			headers := []string{"FIELD", "VALUE"}
 			rows := [][]string{
 				{"ID", r.ID.String()},
 				{"Procedure ID", r.TestProcedureID.String()},
 				{"Status", string(r.Status)},
+				{"Status Reason", r.StatusReason},
 				{"Executed By", r.ExecutedBy.String()},
 				{"Assigned To", assignedTo},
+				{"Due At", dueAt},
 				{"Notes", r.Notes},
 				{"Started At", startedAt},
 				{"Completed At", completedAt},
@@ -200,42 +227,43 @@ func newRunsGetCmd() *cobra.Command {
 }
 
 func newRunsUpdateCmd() *cobra.Command {
-	var id, notes, assignedTo string
+	var id, notes, assignedTo, due string
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update a test run",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			runID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			req := UpdateTestRunRequest{}
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			req := client.UpdateTestRunRequest{}
 			if cmd.Flags().Changed("notes") {
 				req.Notes = &notes
 			}
 			if cmd.Flags().Changed("assigned-to") {
 				req.AssignedTo = &assignedTo
 			}
+			if cmd.Flags().Changed("due") {
+				req.DueAt = &due
+			}
 
-			body, err := client.Put(fmt.Sprintf("/api/v1/runs/%s", id), req)
+			r, err := c.UpdateRun(cmd.Context(), runID, req)
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(r)
 				return nil
 			}
 
-			var r TestRunResponse
-			if err := json.Unmarshal(body, &r); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			printMessage(fmt.Sprintf("Test run updated: %s (status: %s)", r.ID, r.Status))
 			return nil
 		},
@@ -245,36 +273,180 @@ func newRunsUpdateCmd() *cobra.Command {
 	cmd.MarkFlagRequired("id")
 	cmd.Flags().StringVar(&notes, "notes", "", "Test run notes")
 	cmd.Flags().StringVar(&assignedTo, "assigned-to", "", "User ID to assign to (empty string to unassign)")
+	cmd.Flags().StringVar(&due, "due", "", "When the run is due, as an RFC 3339 time (empty string to clear)")
+	return cmd
+}
+
+// newRunsStatusCmd returns a command that changes a test run's status with
+// change. withReason adds a --reason flag passed to it.
+func newRunsStatusCmd(use, short string, withReason bool, change func(c *client.Client, ctx context.Context, id uuid.UUID, reason string) (*client.TestRun, error)) *cobra.Command {
+	var id, reason string
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			r, err := change(c, cmd.Context(), runID, reason)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(r)
+				return nil
+			}
+
+			printMessage(fmt.Sprintf("Test run %s is now %s", r.ID, r.Status))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Test run ID (required)")
+	cmd.MarkFlagRequired("id")
+	if withReason {
+		cmd.Flags().StringVar(&reason, "reason", "", "Why the test run is stopped")
+	}
+	return cmd
+}
+
+func newRunsClaimCmd() *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "claim",
+		Short: "Assign an unassigned test run to yourself",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runID, err := parseID("id", id)
+			if err != nil {
+				return err
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			r, err := c.ClaimRun(cmd.Context(), runID)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(r)
+				return nil
+			}
+
+			printMessage(fmt.Sprintf("Test run claimed: %s (status: %s)", r.ID, r.Status))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "Test run ID (required)")
+	cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+func newRunsQueueCmd() *cobra.Command {
+	var limit, offset int
+	var includeCompleted bool
+	var dueBefore string
+
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "List the test runs assigned to you",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := &client.RunQueueOptions{
+				ListOptions:      client.ListOptions{Limit: limit, Offset: offset},
+				IncludeCompleted: includeCompleted,
+			}
+			if dueBefore != "" {
+				t, err := time.Parse(time.RFC3339, dueBefore)
+				if err != nil {
+					return fmt.Errorf("invalid --due-before: %w", err)
+				}
+				opts.DueBefore = &t
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			resp, err := c.ListRunQueue(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+
+			if flagJSON {
+				printJSON(resp)
+				return nil
+			}
+
+			headers := []string{"ID", "PROJECT ID", "PROCEDURE", "VERSION", "STATUS", "DUE AT"}
+			var rows [][]string
+			for _, r := range resp.Items {
+				dueAt := "-"
+				if r.DueAt != nil {
+					dueAt = r.DueAt.Format("2006-01-02 15:04:05")
+				}
+				rows = append(rows, []string{
+					r.ID.String(),
+					r.ProjectID.String(),
+					r.ProcedureName,
+					fmt.Sprintf("v%d", r.ProcedureVersion),
+					string(r.Status),
+					dueAt,
+				})
+			}
+			printTable(headers, rows)
+			printMessage(fmt.Sprintf("\nShowing %d of %d runs", len(resp.Items), resp.Total))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of results")
+	cmd.Flags().IntVar(&offset, "offset", 0, "Offset for pagination")
+	cmd.Flags().BoolVar(&includeCompleted, "include-completed", false, "Include completed runs")
+	cmd.Flags().StringVar(&dueBefore, "due-before", "", "Only list runs due before this RFC 3339 time")
 	return cmd
 }
 
 func newRunsStartCmd() *cobra.Command {
 	var id string
+	var acknowledgePreconditions bool
 
 	cmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start a test run",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			runID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			body, err := client.Post(fmt.Sprintf("/api/v1/runs/%s/start", id), nil)
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
-				return nil
+			r, err := c.StartRun(cmd.Context(), runID, client.StartTestRunRequest{AcknowledgePreconditions: acknowledgePreconditions})
+			if err != nil {
+				return err
 			}
 
-			var r TestRunResponse
-			if err := json.Unmarshal(body, &r); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
+			if flagJSON {
+				printJSON(r)
+				return nil
 			}
 
 			printMessage(fmt.Sprintf("Test run started: %s", r.ID))
@@ -283,6 +455,7 @@ func newRunsStartCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&id, "id", "", "Test run ID (required)")
+	cmd.Flags().BoolVar(&acknowledgePreconditions, "acknowledge-preconditions", false, "Confirm the procedure's preconditions are in place and the run's checklist is complete")
 	cmd.MarkFlagRequired("id")
 	return cmd
 }
@@ -299,33 +472,29 @@ func newRunsCompleteCmd() *cobra.Command {
 				return fmt.Errorf("invalid status: must be passed, failed, or skipped")
 			}
 
-			client, err := getClient()
+			runID, err := parseID("id", id)
 			if err != nil {
 				return err
 			}
 
-			req := CompleteTestRunRequest{
-				Status: s,
-				Notes:  notes,
+			c, err := getClient()
+			if err != nil {
+				return err
 			}
 
-			body, err := client.Post(fmt.Sprintf("/api/v1/runs/%s/complete", id), req)
+			r, err := c.CompleteRun(cmd.Context(), runID, client.CompleteTestRunRequest{
+				Status: s,
+				Notes:  notes,
+			})
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(r)
 				return nil
 			}
 
-			var r TestRunResponse
-			if err := json.Unmarshal(body, &r); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			printMessage(fmt.Sprintf("Test run completed: %s (status: %s)", r.ID, r.Status))
 			return nil
 		},
@@ -338,3 +507,129 @@ func newRunsCompleteCmd() *cobra.Command {
 	cmd.Flags().StringVar(&notes, "notes", "", "Completion notes")
 	return cmd
 }
+
+func newRunsReportCmd() *cobra.Command {
+	var ids []string
+	var format, output string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report the results of test runs",
+		Long: `Report the results of test runs.
+
+With --format github-actions, failed and unfinished runs are printed as
+workflow commands, which GitHub Actions turns into annotations shown inline
+in pull request checks, and a Markdown summary of every run is appended to
+the job summary ($GITHUB_STEP_SUMMARY), or printed when it is not set.
+
+With --format junit, the runs are written as JUnit XML, a test suite per run
+and a test case per step, to --output or stdout, for CI systems and test
+dashboards that import JUnit results.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "github-actions" && format != "junit" {
+				return fmt.Errorf("invalid --format: must be table, github-actions or junit")
+			}
+
+			runIDs := make([]uuid.UUID, 0, len(ids))
+			for _, id := range ids {
+				runID, err := parseID("id", id)
+				if err != nil {
+					return err
+				}
+				runIDs = append(runIDs, runID)
+			}
+
+			c, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			if format == "github-actions" {
+				report, err := c.GitHubActionsReport(cmd.Context(), runIDs)
+				if err != nil {
+					return err
+				}
+				if flagJSON {
+					printJSON(report)
+					return nil
+				}
+				return printGitHubActionsReport(report)
+			}
+
+			if format == "junit" {
+				report, err := c.JUnitReport(cmd.Context(), runIDs)
+				if err != nil {
+					return err
+				}
+				if output == "" {
+					fmt.Print(string(report))
+					return nil
+				}
+				if err := os.WriteFile(output, report, 0o644); err != nil {
+					return fmt.Errorf("failed to write JUnit report: %w", err)
+				}
+				printMessage(fmt.Sprintf("JUnit report written to %s", output))
+				return nil
+			}
+
+			headers := []string{"ID", "PROCEDURE", "VERSION", "STATUS", "FAILED STEP", "DURATION"}
+			var rows [][]string
+			for _, runID := range runIDs {
+				r, err := c.GetRun(cmd.Context(), runID)
+				if err != nil {
+					return err
+				}
+				p, err := c.GetRunProcedure(cmd.Context(), runID)
+				if err != nil {
+					return err
+				}
+
+				failedStep := "-"
+				if r.FailedStepIndex != nil {
+					failedStep = fmt.Sprintf("%d", *r.FailedStepIndex+1)
+				}
+				duration := "-"
+				if r.StartedAt != nil && r.CompletedAt != nil {
+					duration = r.CompletedAt.Sub(*r.StartedAt).Round(time.Second).String()
+				}
+				rows = append(rows, []string{
+					r.ID.String(),
+					p.Name,
+					fmt.Sprintf("v%d", r.ProcedureVersion),
+					string(r.Status),
+					failedStep,
+					duration,
+				})
+			}
+			printTable(headers, rows)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&ids, "id", nil, "Test run IDs, repeated or comma-separated (required)")
+	cmd.MarkFlagRequired("id")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format: table, github-actions or junit")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write the JUnit report to (default stdout)")
+	return cmd
+}
+
+// printGitHubActionsReport prints a report's workflow commands and appends
+// its summary to the job summary file of the GitHub Actions step.
+func printGitHubActionsReport(report *client.GitHubActionsReport) error {
+	fmt.Print(report.Commands)
+
+	summaryPath := os.Getenv("GITHUB_STEP_SUMMARY")
+	if summaryPath == "" {
+		fmt.Print("\n" + report.Summary)
+		return nil
+	}
+	f, err := os.OpenFile(summaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open job summary: %w", err)
+	}
+	if _, err := f.WriteString(report.Summary); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write job summary: %w", err)
+	}
+	return f.Close()
+}
@@ -1,9 +1,9 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 
+	"github.com/hairizuanbinnoorazman/ui-automation/client"
 	"github.com/spf13/cobra"
 )
 
@@ -24,28 +24,21 @@ func newTokensListCmd() *cobra.Command {
 		Use:   "list",
 		Short: "List API tokens",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			body, err := client.Get("/api/v1/tokens", nil)
+			resp, err := c.ListTokens(cmd.Context())
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(resp)
 				return nil
 			}
 
-			var resp TokenListResponse
-			if err := json.Unmarshal(body, &resp); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			headers := []string{"ID", "NAME", "SCOPE", "ACTIVE", "EXPIRES AT", "CREATED AT"}
 			var rows [][]string
 			for _, t := range resp.Tokens {
@@ -73,34 +66,25 @@ func newTokensCreateCmd() *cobra.Command {
 		Use:   "create",
 		Short: "Create a new API token",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := getClient()
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			req := CreateTokenRequest{
+			resp, err := c.CreateToken(cmd.Context(), client.CreateTokenRequest{
 				Name:           name,
 				Scope:          scope,
 				ExpiresInHours: expiresInHours,
-			}
-
-			body, err := client.Post("/api/v1/tokens", req)
+			})
 			if err != nil {
 				return err
 			}
 
 			if flagJSON {
-				var raw json.RawMessage
-				json.Unmarshal(body, &raw)
-				printJSON(raw)
+				printJSON(resp)
 				return nil
 			}
 
-			var resp CreateTokenResponse
-			if err := json.Unmarshal(body, &resp); err != nil {
-				return fmt.Errorf("failed to parse response: %w", err)
-			}
-
 			printMessage("Token created successfully!")
 			printMessage(fmt.Sprintf("  ID:         %s", resp.ID))
 			printMessage(fmt.Sprintf("  Name:       %s", resp.Name))
@@ -134,13 +118,12 @@ func newTokensRevokeCmd() *cobra.Command {
 				return nil
 			}
 
-			client, err := getClient()
+			c, err := getClient()
 			if err != nil {
 				return err
 			}
 
-			_, err = client.Delete(fmt.Sprintf("/api/v1/tokens/%s", id))
-			if err != nil {
+			if err := c.RevokeToken(cmd.Context(), id); err != nil {
 				return err
 			}
 
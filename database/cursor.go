@@ -0,0 +1,67 @@
+package database
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a list ordered newest first, by created_at and
+// then id. Unlike an offset, a cursor is found through the index instead of
+// by skipping rows, so deep pages cost as little as the first, and rows
+// added while paging do not shift later pages.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// CursorOf returns the cursor after the row created at createdAt with id.
+func CursorOf(createdAt time.Time, id uuid.UUID) *Cursor {
+	return &Cursor{CreatedAt: createdAt, ID: id}
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + "." + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor returned by Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: time.Unix(0, n).UTC(), ID: parsed}, nil
+}
+
+// After is a GORM scope that orders rows newest first, by created_at and
+// then id, and keeps those after c. A nil cursor starts at the newest row.
+func After(c *Cursor) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if c != nil {
+			db = db.Where("(created_at < ? OR (created_at = ? AND id < ?))", c.CreatedAt, c.CreatedAt, c.ID)
+		}
+		return db.Order("created_at DESC").Order("id DESC")
+	}
+}
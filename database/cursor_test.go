@@ -0,0 +1,37 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	c := CursorOf(time.Date(2026, 3, 1, 12, 30, 0, 123456789, time.UTC), uuid.New())
+
+	decoded, err := DecodeCursor(c.Encode())
+	require.NoError(t, err)
+	assert.True(t, c.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, c.ID, decoded.ID)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "no separator", cursor: "MTIzNDU"},
+		{name: "bad time", cursor: "YWJjLjEyMw"},
+		{name: "bad id", cursor: "MTIzLmFiYw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeCursor(tt.cursor)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
@@ -5,12 +5,22 @@ import (
 	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 )
 
+// Supported database drivers.
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
 // Config holds database connection configuration.
 type Config struct {
+	// Driver is the database driver, DriverMySQL or DriverSQLite. It
+	// defaults to DriverMySQL.
+	Driver       string
 	Host         string
 	Port         int
 	User         string
@@ -18,21 +28,46 @@ type Config struct {
 	Database     string
 	MaxOpenConns int
 	MaxIdleConns int
+	// PasswordFunc, when set, is called for the MySQL password each time a
+	// connection is opened, in place of Password, so that a rotated
+	// password is used without reopening the pool.
+	PasswordFunc func() string
+	// SQLitePath is the database file used by the SQLite driver.
+	SQLitePath string
+	// Replicas are MySQL read replicas for queries scoped with ReadReplica.
+	Replicas []ReplicaConfig
+	// ReplicaMaxLag is the most a replica may lag the primary and still be
+	// read from.
+	ReplicaMaxLag time.Duration
 }
 
-// Connect establishes a connection to the MySQL database with connection pooling.
+// Connect establishes a connection to the configured database with connection pooling.
 func Connect(cfg Config) (*gorm.DB, error) {
-	// Construct DSN
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Database,
-	)
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case "", DriverMySQL:
+		if cfg.PasswordFunc == nil {
+			dialector = mysql.Open(mysqlDSN(cfg))
+			break
+		}
+		sqlDB, err := openMySQL(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dialector = mysql.New(mysql.Config{DSN: mysqlDSN(cfg), Conn: sqlDB})
+	case DriverSQLite:
+		if cfg.SQLitePath == "" {
+			return nil, fmt.Errorf("sqlite path is required")
+		}
+		// WAL lets readers run alongside the single writer, and the busy
+		// timeout makes concurrent writers wait rather than fail.
+		dialector = sqlite.Open(cfg.SQLitePath + "?_journal_mode=WAL&_busy_timeout=5000")
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
 
 	// Open GORM connection
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
 	})
 	if err != nil {
@@ -52,3 +87,24 @@ func Connect(cfg Config) (*gorm.DB, error) {
 
 	return db, nil
 }
+
+// mysqlDSN builds the MySQL data source name for cfg.
+func mysqlDSN(cfg Config) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Database,
+	)
+}
+
+// CreateSchema creates or updates the tables for models with GORM
+// auto-migration. It is used for SQLite databases, which the MySQL SQL
+// migrations cannot be applied to.
+func CreateSchema(db *gorm.DB, models ...interface{}) error {
+	if err := db.AutoMigrate(models...); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testWidget struct {
+	ID   uint
+	Name string
+}
+
+func TestConnect(t *testing.T) {
+	t.Run("sqlite creates the database file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.db")
+		db, err := Connect(Config{Driver: DriverSQLite, SQLitePath: path, MaxOpenConns: 2, MaxIdleConns: 1})
+		require.NoError(t, err)
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		require.NoError(t, CreateSchema(db, &testWidget{}))
+		require.NoError(t, db.Create(&testWidget{Name: "a"}).Error)
+		// Creating the schema again leaves existing rows in place.
+		require.NoError(t, CreateSchema(db, &testWidget{}))
+
+		var count int64
+		require.NoError(t, db.Model(&testWidget{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+		assert.FileExists(t, path)
+	})
+
+	t.Run("sqlite requires a path", func(t *testing.T) {
+		_, err := Connect(Config{Driver: DriverSQLite})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown driver", func(t *testing.T) {
+		_, err := Connect(Config{Driver: "postgres"})
+		assert.ErrorContains(t, err, "unsupported database driver")
+	})
+}
@@ -2,18 +2,52 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// RunMigrations applies all pending migrations from the given path.
-func RunMigrations(db *sql.DB, migrationsPath string) error {
+// migrationsTable is the table in which golang-migrate records the applied
+// version.
+const migrationsTable = "schema_migrations"
+
+// Migration is a versioned schema change made of an up and a down SQL file.
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// MigrationStatus describes how far the database schema has been migrated.
+type MigrationStatus struct {
+	// Version is the last applied migration, or 0 if none has been applied.
+	Version uint
+	// Dirty is true when the last migration failed part way through. The
+	// schema must be repaired by hand and the version forced before
+	// migrating again.
+	Dirty bool
+	// Available lists every migration in the migrations directory.
+	Available []Migration
+	// Pending lists the migrations that have not been applied yet.
+	Pending []Migration
+}
+
+// UpToDate reports whether every migration has been applied cleanly.
+func (s *MigrationStatus) UpToDate() bool {
+	return !s.Dirty && len(s.Pending) == 0
+}
+
+// newMigrate creates a migrate instance for the MySQL database and the
+// migrations in migrationsPath.
+func newMigrate(db *sql.DB, migrationsPath string) (*migrate.Migrate, error) {
 	driver, err := mysql.WithInstance(db, &mysql.Config{})
 	if err != nil {
-		return fmt.Errorf("failed to create migration driver: %w", err)
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
@@ -22,35 +56,160 @@ func RunMigrations(db *sql.DB, migrationsPath string) error {
 		driver,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create migration instance: %w", err)
+		return nil, fmt.Errorf("failed to create migration instance: %w", err)
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+	return m, nil
+}
+
+// RunMigrations applies pending migrations from the given path. If steps is
+// greater than zero only that many migrations are applied; otherwise all
+// pending migrations are.
+func RunMigrations(db *sql.DB, migrationsPath string, steps int) error {
+	m, err := newMigrate(db, migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	if steps > 0 {
+		err = m.Steps(steps)
+	} else {
+		err = m.Up()
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	return nil
 }
 
-// RollbackMigration rolls back the most recent migration.
-func RollbackMigration(db *sql.DB, migrationsPath string) error {
-	driver, err := mysql.WithInstance(db, &mysql.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to create migration driver: %w", err)
+// RollbackMigrations rolls back the given number of most recent migrations.
+func RollbackMigrations(db *sql.DB, migrationsPath string, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1")
 	}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		"mysql",
-		driver,
-	)
+	m, err := newMigrate(db, migrationsPath)
 	if err != nil {
-		return fmt.Errorf("failed to create migration instance: %w", err)
+		return err
 	}
 
-	if err := m.Steps(-1); err != nil && err != migrate.ErrNoChange {
+	if err := m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		return fmt.Errorf("failed to rollback migration: %w", err)
 	}
 
 	return nil
 }
+
+// ForceMigrationVersion records version as the applied migration and clears
+// the dirty flag without running any SQL. It is used after repairing a
+// migration that failed part way through.
+func ForceMigrationVersion(db *sql.DB, migrationsPath string, version int) error {
+	m, err := newMigrate(db, migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("failed to force migration version: %w", err)
+	}
+
+	return nil
+}
+
+// GetMigrationStatus compares the database schema version with the
+// migrations in migrationsPath. The version is read directly from the
+// schema_migrations table so the check holds no connection or lock and can
+// run while the server is serving requests.
+func GetMigrationStatus(db *sql.DB, migrationsPath string) (*MigrationStatus, error) {
+	available, err := ListMigrations(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables int
+	err = db.QueryRow(
+		"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?",
+		migrationsTable,
+	).Scan(&tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	if tables == 0 {
+		return newMigrationStatus(available, 0, false), nil
+	}
+
+	var version uint
+	var dirty bool
+	err = db.QueryRow("SELECT version, dirty FROM "+migrationsTable+" LIMIT 1").Scan(&version, &dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	return newMigrationStatus(available, version, dirty), nil
+}
+
+// newMigrationStatus builds the status of a database at version.
+func newMigrationStatus(available []Migration, version uint, dirty bool) *MigrationStatus {
+	status := &MigrationStatus{
+		Version:   version,
+		Dirty:     dirty,
+		Available: available,
+		Pending:   []Migration{},
+	}
+	for _, migration := range available {
+		if migration.Version > version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status
+}
+
+// ListMigrations returns the migrations in migrationsPath ordered by
+// version. Every migration must have both an up and a down file.
+func ListMigrations(migrationsPath string) ([]Migration, error) {
+	entries, err := os.ReadDir(migrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	type files struct {
+		name     string
+		up, down bool
+	}
+	byVersion := make(map[uint]*files)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		parsed, err := source.Parse(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		f, ok := byVersion[parsed.Version]
+		if !ok {
+			f = &files{name: parsed.Identifier}
+			byVersion[parsed.Version] = f
+		}
+		if f.name != parsed.Identifier {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", parsed.Version, f.name, parsed.Identifier)
+		}
+		switch parsed.Direction {
+		case source.Up:
+			f.up = true
+		case source.Down:
+			f.down = true
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, f := range byVersion {
+		if !f.up || !f.down {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down file", version, f.name)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: f.name})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
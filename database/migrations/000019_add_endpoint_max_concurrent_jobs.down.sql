@@ -0,0 +1 @@
+ALTER TABLE endpoints DROP COLUMN max_concurrent_jobs
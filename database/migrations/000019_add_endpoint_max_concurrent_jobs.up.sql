@@ -0,0 +1 @@
+ALTER TABLE endpoints ADD COLUMN max_concurrent_jobs INT NOT NULL DEFAULT 1
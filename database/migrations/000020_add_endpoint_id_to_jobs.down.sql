@@ -0,0 +1 @@
+ALTER TABLE jobs DROP INDEX idx_jobs_endpoint_status, DROP COLUMN endpoint_id
@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN endpoint_id CHAR(36) NULL DEFAULT NULL, ADD INDEX idx_jobs_endpoint_status (endpoint_id, status)
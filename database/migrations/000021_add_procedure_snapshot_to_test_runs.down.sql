@@ -0,0 +1 @@
+ALTER TABLE test_runs DROP COLUMN procedure_snapshot
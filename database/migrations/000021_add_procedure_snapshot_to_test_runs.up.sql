@@ -0,0 +1 @@
+ALTER TABLE test_runs ADD COLUMN procedure_snapshot JSON NULL DEFAULT NULL
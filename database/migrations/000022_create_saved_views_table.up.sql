@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS saved_views (
+    id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    owner_id CHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    resource VARCHAR(20) NOT NULL,
+    filters JSON,
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_saved_views_project_id (project_id),
+    INDEX idx_saved_views_owner_id (owner_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1 @@
+ALTER TABLE test_procedures DROP COLUMN needs_review
@@ -0,0 +1 @@
+ALTER TABLE test_procedures ADD COLUMN needs_review BOOLEAN NOT NULL DEFAULT FALSE
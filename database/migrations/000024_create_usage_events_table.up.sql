@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS usage_events (
+    id CHAR(36) PRIMARY KEY,
+    account_id CHAR(36) NOT NULL,
+    project_id CHAR(36) NOT NULL,
+    actor_id CHAR(36) NOT NULL,
+    metric VARCHAR(50) NOT NULL,
+    quantity DOUBLE NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_usage_events_account_time (account_id, occurred_at),
+    INDEX idx_usage_events_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
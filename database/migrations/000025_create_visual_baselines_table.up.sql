@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS visual_baselines (
+    id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    endpoint_id CHAR(36) NOT NULL,
+    page_name VARCHAR(100) NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    image_path VARCHAR(512) NOT NULL,
+    file_size BIGINT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    source_job_id CHAR(36) NULL,
+    approved_by CHAR(36) NOT NULL,
+    approved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_visual_baselines_page (project_id, endpoint_id, page_name),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (endpoint_id) REFERENCES endpoints(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,7 @@
+ALTER TABLE endpoints
+    DROP COLUMN last_health_check_at,
+    DROP COLUMN health_status,
+    DROP COLUMN health_check_expected_text,
+    DROP COLUMN health_check_expected_status,
+    DROP COLUMN health_check_interval_seconds,
+    DROP COLUMN health_check_enabled
@@ -0,0 +1,7 @@
+ALTER TABLE endpoints
+    ADD COLUMN health_check_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN health_check_interval_seconds INT NOT NULL DEFAULT 300,
+    ADD COLUMN health_check_expected_status INT NOT NULL DEFAULT 200,
+    ADD COLUMN health_check_expected_text VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN health_status VARCHAR(20) NOT NULL DEFAULT 'unknown',
+    ADD COLUMN last_health_check_at TIMESTAMP NULL
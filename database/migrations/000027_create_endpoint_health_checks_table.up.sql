@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS endpoint_health_checks (
+    id CHAR(36) PRIMARY KEY,
+    endpoint_id CHAR(36) NOT NULL,
+    up BOOLEAN NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    response_time_ms BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    checked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_endpoint_health_checks_endpoint_time (endpoint_id, checked_at),
+    FOREIGN KEY (endpoint_id) REFERENCES endpoints(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS endpoint_secrets (
+    id CHAR(36) PRIMARY KEY,
+    endpoint_id CHAR(36) NOT NULL,
+    `key` VARCHAR(100) NOT NULL,
+    encrypted_value BLOB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_endpoint_secrets_key (endpoint_id, `key`),
+    FOREIGN KEY (endpoint_id) REFERENCES endpoints(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,4 @@
+ALTER TABLE endpoints
+    DROP INDEX idx_endpoints_group_environment,
+    DROP COLUMN environment,
+    DROP COLUMN endpoint_group
@@ -0,0 +1,4 @@
+ALTER TABLE endpoints
+    ADD COLUMN endpoint_group VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN environment VARCHAR(50) NOT NULL DEFAULT '',
+    ADD INDEX idx_endpoints_group_environment (created_by, endpoint_group, environment)
@@ -0,0 +1,5 @@
+ALTER TABLE test_runs
+    DROP INDEX idx_test_runs_endpoint_id,
+    DROP COLUMN base_url,
+    DROP COLUMN environment,
+    DROP COLUMN endpoint_id
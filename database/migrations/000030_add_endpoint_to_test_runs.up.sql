@@ -0,0 +1,5 @@
+ALTER TABLE test_runs
+    ADD COLUMN endpoint_id CHAR(36) NULL,
+    ADD COLUMN environment VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN base_url VARCHAR(2048) NOT NULL DEFAULT '',
+    ADD INDEX idx_test_runs_endpoint_id (endpoint_id)
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS job_logs (
+    id CHAR(36) PRIMARY KEY,
+    job_id CHAR(36) NOT NULL,
+    seq INT NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_job_logs_job_seq (job_id, seq),
+    FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
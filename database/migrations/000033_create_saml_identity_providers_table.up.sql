@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS saml_identity_providers (
+    id CHAR(36) PRIMARY KEY,
+    entity_id VARCHAR(1024) NOT NULL,
+    metadata_xml MEDIUMTEXT NOT NULL,
+    updated_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id CHAR(36) PRIMARY KEY,
+    events JSON NOT NULL,
+    encrypted_slack_webhook BLOB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
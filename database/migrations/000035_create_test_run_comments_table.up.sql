@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS test_run_comments (
+    id CHAR(36) PRIMARY KEY,
+    test_run_id CHAR(36) NOT NULL,
+    step_index INT NULL,
+    parent_id CHAR(36) NULL,
+    author_id CHAR(36) NOT NULL,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_run_id) REFERENCES test_runs(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES test_run_comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_test_run_comments_run_created (test_run_id, created_at),
+    INDEX idx_test_run_comments_parent_id (parent_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
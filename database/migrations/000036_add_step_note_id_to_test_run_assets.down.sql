@@ -0,0 +1,4 @@
+ALTER TABLE test_run_assets
+    DROP FOREIGN KEY fk_test_run_assets_step_note_id,
+    DROP INDEX idx_test_run_assets_step_note_id,
+    DROP COLUMN step_note_id;
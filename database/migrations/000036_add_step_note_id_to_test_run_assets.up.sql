@@ -0,0 +1,4 @@
+ALTER TABLE test_run_assets
+    ADD COLUMN step_note_id CHAR(36) NULL,
+    ADD INDEX idx_test_run_assets_step_note_id (step_note_id),
+    ADD CONSTRAINT fk_test_run_assets_step_note_id FOREIGN KEY (step_note_id) REFERENCES test_run_step_notes(id) ON DELETE SET NULL;
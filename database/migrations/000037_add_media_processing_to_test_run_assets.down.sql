@@ -0,0 +1,8 @@
+ALTER TABLE test_run_assets
+    DROP FOREIGN KEY fk_test_run_assets_source_asset_id,
+    DROP INDEX idx_test_run_assets_processing_status,
+    DROP INDEX idx_test_run_assets_source_asset_id,
+    DROP COLUMN processing_error,
+    DROP COLUMN processing_status,
+    DROP COLUMN variant,
+    DROP COLUMN source_asset_id;
@@ -0,0 +1,8 @@
+ALTER TABLE test_run_assets
+    ADD COLUMN source_asset_id CHAR(36) NULL,
+    ADD COLUMN variant VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN processing_status VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN processing_error TEXT NULL,
+    ADD INDEX idx_test_run_assets_source_asset_id (source_asset_id),
+    ADD INDEX idx_test_run_assets_processing_status (processing_status),
+    ADD CONSTRAINT fk_test_run_assets_source_asset_id FOREIGN KEY (source_asset_id) REFERENCES test_run_assets(id) ON DELETE CASCADE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS test_run_asset_annotations;
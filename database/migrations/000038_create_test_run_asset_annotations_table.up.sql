@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS test_run_asset_annotations (
+    id CHAR(36) PRIMARY KEY,
+    asset_id CHAR(36) NOT NULL,
+    shape VARCHAR(20) NOT NULL,
+    x INT NOT NULL,
+    y INT NOT NULL,
+    width INT NOT NULL DEFAULT 0,
+    height INT NOT NULL DEFAULT 0,
+    end_x INT NOT NULL DEFAULT 0,
+    end_y INT NOT NULL DEFAULT 0,
+    text VARCHAR(800) NULL,
+    color VARCHAR(7) NOT NULL,
+    position INT NOT NULL,
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (asset_id) REFERENCES test_run_assets(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_test_run_asset_annotations_asset_id (asset_id, position)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    target VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(128) NULL,
+    file_size BIGINT NOT NULL,
+    part_size BIGINT NOT NULL,
+    test_run_id CHAR(36) NULL,
+    asset_type VARCHAR(20) NULL,
+    description TEXT NULL,
+    step_index INT NULL,
+    step_note_id CHAR(36) NULL,
+    test_procedure_id CHAR(36) NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_upload_sessions_user_id (user_id),
+    INDEX idx_upload_sessions_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
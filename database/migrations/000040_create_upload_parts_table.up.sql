@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS upload_parts (
+    session_id CHAR(36) NOT NULL,
+    number INT NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, number),
+    FOREIGN KEY (session_id) REFERENCES upload_sessions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS project_retention_policies (
+    project_id CHAR(36) PRIMARY KEY,
+    asset_retention_days INT NOT NULL DEFAULT 0,
+    updated_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+ALTER TABLE test_procedures
+    DROP INDEX idx_test_procedures_deleted_at,
+    DROP COLUMN deleted_at;
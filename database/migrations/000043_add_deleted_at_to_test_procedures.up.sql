@@ -0,0 +1,3 @@
+ALTER TABLE test_procedures
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_test_procedures_deleted_at (deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE test_runs ADD COLUMN failed_step_index INT NULL DEFAULT NULL
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS procedure_run_stats;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS procedure_run_stats (
+    procedure_id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    procedure_name VARCHAR(255) NOT NULL,
+    last_status VARCHAR(20) NOT NULL,
+    last_outcome VARCHAR(20) NOT NULL DEFAULT '',
+    last_completed_at TIMESTAMP NULL,
+    current_failure_streak INT NOT NULL DEFAULT 0,
+    longest_failure_streak INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    INDEX idx_procedure_run_stats_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
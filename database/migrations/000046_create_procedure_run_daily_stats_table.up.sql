@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS procedure_run_daily_stats (
+    procedure_id CHAR(36) NOT NULL,
+    day DATE NOT NULL,
+    project_id CHAR(36) NOT NULL,
+    passed INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    skipped INT NOT NULL DEFAULT 0,
+    flips INT NOT NULL DEFAULT 0,
+    timed_runs INT NOT NULL DEFAULT 0,
+    duration_seconds DOUBLE NOT NULL DEFAULT 0,
+    PRIMARY KEY (procedure_id, day),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    INDEX idx_procedure_run_daily_stats_project_day (project_id, day)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
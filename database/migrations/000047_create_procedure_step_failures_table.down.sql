@@ -0,0 +1 @@
+DROP TABLE IF EXISTS procedure_step_failures;
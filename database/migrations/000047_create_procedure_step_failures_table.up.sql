@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS procedure_step_failures (
+    procedure_id CHAR(36) NOT NULL,
+    day DATE NOT NULL,
+    step_index INT NOT NULL,
+    step_name VARCHAR(255) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (procedure_id, day, step_index)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS labels (
+    id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    resource_type VARCHAR(20) NOT NULL,
+    resource_id CHAR(36) NOT NULL,
+    `key` VARCHAR(63) NOT NULL,
+    value VARCHAR(255) NOT NULL DEFAULT '',
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_labels_resource_key (resource_type, resource_id, `key`),
+    INDEX idx_labels_project_key (project_id, `key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
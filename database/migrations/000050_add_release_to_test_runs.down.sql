@@ -0,0 +1,4 @@
+ALTER TABLE test_runs
+    DROP FOREIGN KEY fk_test_runs_release_id,
+    DROP INDEX idx_test_runs_release_id,
+    DROP COLUMN release_id;
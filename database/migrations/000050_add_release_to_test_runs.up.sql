@@ -0,0 +1,4 @@
+ALTER TABLE test_runs
+    ADD COLUMN release_id CHAR(36) NULL DEFAULT NULL,
+    ADD INDEX idx_test_runs_release_id (release_id),
+    ADD CONSTRAINT fk_test_runs_release_id FOREIGN KEY (release_id) REFERENCES releases(id) ON DELETE SET NULL;
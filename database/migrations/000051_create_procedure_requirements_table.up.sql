@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS procedure_requirements (
+    id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    procedure_id CHAR(36) NOT NULL,
+    requirement VARCHAR(255) NOT NULL,
+    title VARCHAR(500) NOT NULL DEFAULT '',
+    url VARCHAR(1000) NOT NULL DEFAULT '',
+    integration_id CHAR(36) NULL,
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (integration_id) REFERENCES integrations(id) ON DELETE SET NULL,
+    UNIQUE INDEX idx_procedure_requirements_procedure_requirement (procedure_id, requirement),
+    INDEX idx_procedure_requirements_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
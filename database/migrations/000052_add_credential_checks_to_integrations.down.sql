@@ -0,0 +1,6 @@
+ALTER TABLE integrations
+    DROP COLUMN notified_credential_status,
+    DROP COLUMN last_check_error,
+    DROP COLUMN last_checked_at,
+    DROP COLUMN credentials_expire_at,
+    DROP COLUMN credentials_updated_at;
@@ -0,0 +1,11 @@
+ALTER TABLE integrations
+    ADD COLUMN credentials_updated_at TIMESTAMP NULL DEFAULT NULL AFTER is_active,
+    ADD COLUMN credentials_expire_at TIMESTAMP NULL DEFAULT NULL AFTER credentials_updated_at,
+    ADD COLUMN last_checked_at TIMESTAMP NULL DEFAULT NULL AFTER credentials_expire_at,
+    ADD COLUMN last_check_error VARCHAR(1000) NOT NULL DEFAULT '' AFTER last_checked_at,
+    ADD COLUMN notified_credential_status VARCHAR(20) NOT NULL DEFAULT '' AFTER last_check_error;
+
+UPDATE integrations SET credentials_updated_at = updated_at;
+
+ALTER TABLE integrations
+    MODIFY COLUMN credentials_updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
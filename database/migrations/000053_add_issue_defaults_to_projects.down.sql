@@ -0,0 +1,5 @@
+ALTER TABLE projects
+    DROP FOREIGN KEY fk_projects_default_integration_id,
+    DROP COLUMN default_issue_repository,
+    DROP COLUMN default_issue_project_key,
+    DROP COLUMN default_integration_id;
@@ -0,0 +1,5 @@
+ALTER TABLE projects
+    ADD COLUMN default_integration_id CHAR(36) NULL DEFAULT NULL AFTER is_active,
+    ADD COLUMN default_issue_project_key VARCHAR(255) NOT NULL DEFAULT '' AFTER default_integration_id,
+    ADD COLUMN default_issue_repository VARCHAR(255) NOT NULL DEFAULT '' AFTER default_issue_project_key,
+    ADD CONSTRAINT fk_projects_default_integration_id FOREIGN KEY (default_integration_id) REFERENCES integrations(id) ON DELETE SET NULL;
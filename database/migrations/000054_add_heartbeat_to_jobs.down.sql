@@ -0,0 +1 @@
+ALTER TABLE jobs DROP INDEX idx_jobs_status_heartbeat, DROP COLUMN heartbeat_at
@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN heartbeat_at TIMESTAMP NULL DEFAULT NULL AFTER duration, ADD INDEX idx_jobs_status_heartbeat (status, heartbeat_at)
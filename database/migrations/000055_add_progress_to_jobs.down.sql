@@ -0,0 +1 @@
+ALTER TABLE jobs DROP COLUMN progress_total, DROP COLUMN progress_done
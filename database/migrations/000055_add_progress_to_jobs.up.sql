@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN progress_done INT NOT NULL DEFAULT 0 AFTER heartbeat_at, ADD COLUMN progress_total INT NOT NULL DEFAULT 0 AFTER progress_done
@@ -0,0 +1 @@
+ALTER TABLE endpoints DROP COLUMN use_runner
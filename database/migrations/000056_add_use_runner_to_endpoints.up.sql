@@ -0,0 +1 @@
+ALTER TABLE endpoints ADD COLUMN use_runner BOOLEAN NOT NULL DEFAULT FALSE AFTER last_health_check_at
@@ -0,0 +1 @@
+ALTER TABLE endpoints DROP COLUMN runner_labels
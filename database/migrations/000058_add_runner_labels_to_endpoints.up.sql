@@ -0,0 +1 @@
+ALTER TABLE endpoints ADD COLUMN runner_labels JSON AFTER use_runner
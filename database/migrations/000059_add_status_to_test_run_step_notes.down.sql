@@ -0,0 +1 @@
+ALTER TABLE test_run_step_notes DROP COLUMN status
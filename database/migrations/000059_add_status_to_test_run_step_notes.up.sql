@@ -0,0 +1 @@
+ALTER TABLE test_run_step_notes ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT '' AFTER notes
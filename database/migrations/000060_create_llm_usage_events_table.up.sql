@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS llm_usage_events (
+    id CHAR(36) PRIMARY KEY,
+    account_id CHAR(36) NOT NULL,
+    project_id CHAR(36) NOT NULL,
+    actor_id CHAR(36) NOT NULL,
+    feature VARCHAR(50) NOT NULL,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd DOUBLE NOT NULL DEFAULT 0,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_llm_usage_events_account_time (account_id, occurred_at),
+    INDEX idx_llm_usage_events_project_time (project_id, occurred_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
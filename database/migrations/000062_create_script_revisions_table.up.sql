@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS script_revisions (
+    id CHAR(36) PRIMARY KEY,
+    procedure_id CHAR(36) NOT NULL,
+    framework ENUM('selenium', 'playwright') NOT NULL,
+    revision INT NOT NULL,
+    test_procedure_id CHAR(36) NOT NULL,
+    procedure_version INT UNSIGNED NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT UNSIGNED NOT NULL,
+    content MEDIUMTEXT NOT NULL,
+    generated_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_procedure_id) REFERENCES test_procedures(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_script_revisions_number (procedure_id, framework, revision)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS script_repositories (
+    id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    base_url VARCHAR(500) NOT NULL DEFAULT '',
+    repository VARCHAR(255) NOT NULL,
+    branch VARCHAR(255) NOT NULL,
+    base_branch VARCHAR(255) NOT NULL DEFAULT '',
+    directory VARCHAR(500) NOT NULL,
+    commit_message VARCHAR(1000) NOT NULL,
+    create_pull_request BOOLEAN NOT NULL DEFAULT FALSE,
+    auto_push BOOLEAN NOT NULL DEFAULT FALSE,
+    encrypted_token BLOB NOT NULL,
+    last_pushed_at TIMESTAMP NULL,
+    last_push_error VARCHAR(1000) NOT NULL DEFAULT '',
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_script_repositories_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
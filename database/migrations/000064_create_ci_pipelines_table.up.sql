@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS ci_pipelines (
+    id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    base_url VARCHAR(500) NOT NULL DEFAULT '',
+    repository VARCHAR(255) NOT NULL DEFAULT '',
+    workflow VARCHAR(255) NOT NULL DEFAULT '',
+    ref VARCHAR(255) NOT NULL DEFAULT '',
+    job VARCHAR(500) NOT NULL DEFAULT '',
+    username VARCHAR(255) NOT NULL DEFAULT '',
+    encrypted_token BLOB NOT NULL,
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    INDEX idx_ci_pipelines_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
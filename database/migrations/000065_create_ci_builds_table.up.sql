@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS ci_builds (
+    id CHAR(36) PRIMARY KEY,
+    test_run_id CHAR(36) NOT NULL,
+    pipeline_id CHAR(36) NOT NULL,
+    pipeline_name VARCHAR(255) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    build_url VARCHAR(2048) NOT NULL DEFAULT '',
+    triggered_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_run_id) REFERENCES test_runs(id) ON DELETE CASCADE,
+    INDEX idx_ci_builds_test_run_id (test_run_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS test_management_connections (
+    id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    base_url VARCHAR(500) NOT NULL DEFAULT '',
+    username VARCHAR(255) NOT NULL,
+    push_on_complete BOOLEAN NOT NULL DEFAULT FALSE,
+    encrypted_token BLOB NOT NULL,
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_test_management_connections_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
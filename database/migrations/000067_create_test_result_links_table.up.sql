@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS test_result_links (
+    id CHAR(36) PRIMARY KEY,
+    test_run_id CHAR(36) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    target VARCHAR(255) NOT NULL,
+    test_case VARCHAR(255) NOT NULL,
+    last_pushed_at TIMESTAMP NULL,
+    last_push_error VARCHAR(1000) NOT NULL DEFAULT '',
+    result_url VARCHAR(2048) NOT NULL DEFAULT '',
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_run_id) REFERENCES test_runs(id) ON DELETE CASCADE,
+    INDEX idx_test_result_links_test_run_id (test_run_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS share_links (
+    id CHAR(36) PRIMARY KEY,
+    test_run_id CHAR(36) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    view_count BIGINT NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP NULL,
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_run_id) REFERENCES test_runs(id) ON DELETE CASCADE,
+    INDEX idx_share_links_test_run_id (test_run_id),
+    UNIQUE INDEX idx_share_links_token_hash (token_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS activity_events (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    action VARCHAR(30) NOT NULL,
+    project_id CHAR(36) NOT NULL,
+    subject_id CHAR(36) NOT NULL,
+    summary VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    INDEX idx_activity_events_user_created (user_id, created_at),
+    INDEX idx_activity_events_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1 @@
+ALTER TABLE api_tokens DROP COLUMN allowed_cidrs, DROP COLUMN last_used_at, DROP COLUMN last_used_ip
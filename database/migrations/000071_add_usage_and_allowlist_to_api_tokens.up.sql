@@ -0,0 +1 @@
+ALTER TABLE api_tokens ADD COLUMN allowed_cidrs JSON NULL AFTER is_active, ADD COLUMN last_used_at TIMESTAMP NULL DEFAULT NULL AFTER allowed_cidrs, ADD COLUMN last_used_ip VARCHAR(45) NOT NULL DEFAULT '' AFTER last_used_at
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS service_account_grants (
+    service_account_id CHAR(36) NOT NULL,
+    project_id CHAR(36) NOT NULL,
+    scope VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (service_account_id, project_id),
+    FOREIGN KEY (service_account_id) REFERENCES service_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    INDEX idx_service_account_grants_project_id (project_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1 @@
+ALTER TABLE test_runs DROP INDEX idx_test_runs_procedure_created
@@ -0,0 +1 @@
+ALTER TABLE test_runs ADD INDEX idx_test_runs_procedure_created (test_procedure_id, created_at, id)
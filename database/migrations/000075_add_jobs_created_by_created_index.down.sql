@@ -0,0 +1 @@
+ALTER TABLE jobs DROP INDEX idx_jobs_created_by_created
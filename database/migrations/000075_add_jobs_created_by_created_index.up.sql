@@ -0,0 +1 @@
+ALTER TABLE jobs ADD INDEX idx_jobs_created_by_created (created_by, created_at, id)
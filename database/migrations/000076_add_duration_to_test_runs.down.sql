@@ -0,0 +1,5 @@
+ALTER TABLE test_runs
+    DROP INDEX idx_test_runs_procedure_status,
+    DROP INDEX idx_test_runs_procedure_duration,
+    DROP INDEX idx_test_runs_procedure_started,
+    DROP COLUMN duration
@@ -0,0 +1,5 @@
+ALTER TABLE test_runs
+    ADD COLUMN duration BIGINT NULL DEFAULT NULL AFTER completed_at,
+    ADD INDEX idx_test_runs_procedure_started (test_procedure_id, started_at),
+    ADD INDEX idx_test_runs_procedure_duration (test_procedure_id, duration),
+    ADD INDEX idx_test_runs_procedure_status (test_procedure_id, status)
@@ -0,0 +1 @@
+UPDATE test_runs SET duration = NULL
@@ -0,0 +1 @@
+UPDATE test_runs SET duration = TIMESTAMPDIFF(MICROSECOND, started_at, completed_at) DIV 1000 WHERE started_at IS NOT NULL AND completed_at IS NOT NULL
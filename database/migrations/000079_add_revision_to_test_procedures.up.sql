@@ -0,0 +1 @@
+ALTER TABLE test_procedures ADD COLUMN revision INT UNSIGNED NOT NULL DEFAULT 0
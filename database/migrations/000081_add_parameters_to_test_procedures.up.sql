@@ -0,0 +1 @@
+ALTER TABLE test_procedures ADD COLUMN parameters JSON NULL DEFAULT NULL
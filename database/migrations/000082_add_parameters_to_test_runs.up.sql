@@ -0,0 +1 @@
+ALTER TABLE test_runs ADD COLUMN parameters JSON NULL DEFAULT NULL
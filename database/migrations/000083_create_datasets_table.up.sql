@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS datasets (
+    id CHAR(36) PRIMARY KEY,
+    test_procedure_id CHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    columns JSON,
+    data_rows JSON,
+    row_count INT NOT NULL DEFAULT 0,
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    FOREIGN KEY (test_procedure_id) REFERENCES test_procedures(id) ON DELETE CASCADE,
+    INDEX idx_datasets_test_procedure_id (test_procedure_id),
+    INDEX idx_datasets_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
@@ -0,0 +1,5 @@
+ALTER TABLE test_runs
+    DROP FOREIGN KEY fk_test_runs_group_id,
+    DROP INDEX idx_test_runs_group_id,
+    DROP COLUMN group_id,
+    DROP COLUMN dataset_row;
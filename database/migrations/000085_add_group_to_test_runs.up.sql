@@ -0,0 +1,5 @@
+ALTER TABLE test_runs
+    ADD COLUMN group_id CHAR(36) NULL DEFAULT NULL,
+    ADD COLUMN dataset_row INT NULL DEFAULT NULL,
+    ADD INDEX idx_test_runs_group_id (group_id),
+    ADD CONSTRAINT fk_test_runs_group_id FOREIGN KEY (group_id) REFERENCES test_run_groups(id) ON DELETE SET NULL;
@@ -0,0 +1 @@
+ALTER TABLE test_procedures ADD COLUMN preconditions JSON NULL DEFAULT NULL
@@ -0,0 +1 @@
+ALTER TABLE test_runs DROP COLUMN preconditions_acknowledged_by
@@ -0,0 +1 @@
+ALTER TABLE test_runs ADD COLUMN preconditions_acknowledged_by CHAR(36) NULL DEFAULT NULL
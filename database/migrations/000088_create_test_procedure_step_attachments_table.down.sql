@@ -0,0 +1 @@
+DROP TABLE IF EXISTS test_procedure_step_attachments;
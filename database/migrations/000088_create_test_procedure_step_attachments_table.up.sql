@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS test_procedure_step_attachments (
+    path VARCHAR(512) PRIMARY KEY,
+    test_procedure_id CHAR(36) NOT NULL,
+    file_size BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_procedure_id) REFERENCES test_procedures(id) ON DELETE CASCADE,
+    INDEX idx_test_procedure_step_attachments_test_procedure_id (test_procedure_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN require_procedure_review BOOLEAN NOT NULL DEFAULT FALSE AFTER default_issue_repository;
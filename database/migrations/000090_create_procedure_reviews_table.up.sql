@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS procedure_reviews (
+    id CHAR(36) PRIMARY KEY,
+    project_id CHAR(36) NOT NULL,
+    procedure_id CHAR(36) NOT NULL,
+    version_id CHAR(36) NOT NULL,
+    version INT UNSIGNED NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by CHAR(36) NOT NULL,
+    reviewer_id CHAR(36) NULL DEFAULT NULL,
+    message TEXT,
+    decided_by CHAR(36) NULL DEFAULT NULL,
+    comment TEXT,
+    decided_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (procedure_id) REFERENCES test_procedures(id) ON DELETE CASCADE,
+    FOREIGN KEY (version_id) REFERENCES test_procedures(id) ON DELETE CASCADE,
+    INDEX idx_procedure_reviews_project_id (project_id),
+    INDEX idx_procedure_reviews_procedure_id (procedure_id),
+    INDEX idx_procedure_reviews_reviewer_id (reviewer_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE test_procedures DROP COLUMN change_summary, DROP COLUMN commit_message
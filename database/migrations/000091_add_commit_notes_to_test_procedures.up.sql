@@ -0,0 +1 @@
+ALTER TABLE test_procedures ADD COLUMN commit_message VARCHAR(255) NOT NULL DEFAULT '', ADD COLUMN change_summary TEXT NULL
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS test_procedure_draft_snapshots;
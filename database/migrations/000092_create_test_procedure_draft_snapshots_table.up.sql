@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS test_procedure_draft_snapshots (
+    id CHAR(36) PRIMARY KEY,
+    draft_id CHAR(36) NOT NULL,
+    revision INT UNSIGNED NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    steps JSON NULL DEFAULT NULL,
+    parameters JSON NULL DEFAULT NULL,
+    preconditions JSON NULL DEFAULT NULL,
+    needs_review BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (draft_id) REFERENCES test_procedures(id) ON DELETE CASCADE,
+    INDEX idx_test_procedure_draft_snapshots_draft_id (draft_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+ALTER TABLE test_procedures
+    DROP INDEX idx_archived_at,
+    DROP COLUMN archived_at;
@@ -0,0 +1,3 @@
+ALTER TABLE test_procedures
+    ADD COLUMN archived_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_archived_at (archived_at);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS test_run_templates (
+    id CHAR(36) PRIMARY KEY,
+    test_procedure_id CHAR(36) NOT NULL,
+    notes TEXT,
+    environment_fields JSON NULL DEFAULT NULL,
+    checklist JSON NULL DEFAULT NULL,
+    updated_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_procedure_id) REFERENCES test_procedures(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_test_run_templates_test_procedure_id (test_procedure_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+ALTER TABLE test_runs
+    DROP COLUMN checklist,
+    DROP COLUMN environment_details;
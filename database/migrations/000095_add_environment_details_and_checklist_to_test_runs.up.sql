@@ -0,0 +1,3 @@
+ALTER TABLE test_runs
+    ADD COLUMN environment_details JSON NULL DEFAULT NULL,
+    ADD COLUMN checklist JSON NULL DEFAULT NULL;
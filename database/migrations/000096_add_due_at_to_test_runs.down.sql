@@ -0,0 +1,3 @@
+ALTER TABLE test_runs
+    DROP INDEX idx_test_runs_due_at,
+    DROP COLUMN due_at;
@@ -0,0 +1,3 @@
+ALTER TABLE test_runs
+    ADD COLUMN due_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_test_runs_due_at (due_at);
@@ -0,0 +1,4 @@
+ALTER TABLE test_runs
+    DROP COLUMN status_reason,
+    DROP COLUMN paused_at,
+    DROP COLUMN paused_duration;
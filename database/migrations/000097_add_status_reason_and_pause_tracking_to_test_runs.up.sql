@@ -0,0 +1,4 @@
+ALTER TABLE test_runs
+    ADD COLUMN status_reason VARCHAR(500) NOT NULL DEFAULT '',
+    ADD COLUMN paused_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN paused_duration BIGINT NOT NULL DEFAULT 0;
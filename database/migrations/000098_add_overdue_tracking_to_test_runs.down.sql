@@ -0,0 +1,5 @@
+ALTER TABLE test_runs
+    DROP INDEX idx_test_runs_overdue_after,
+    DROP COLUMN max_duration_minutes,
+    DROP COLUMN overdue_after,
+    DROP COLUMN overdue_at;
@@ -0,0 +1,5 @@
+ALTER TABLE test_runs
+    ADD COLUMN max_duration_minutes INT NULL DEFAULT NULL,
+    ADD COLUMN overdue_after TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN overdue_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_test_runs_overdue_after (overdue_after);
@@ -0,0 +1,2 @@
+ALTER TABLE test_run_templates
+    DROP COLUMN max_duration_minutes;
@@ -0,0 +1,2 @@
+ALTER TABLE test_run_templates
+    ADD COLUMN max_duration_minutes INT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE test_run_templates
+    DROP COLUMN prevent_concurrent_runs;
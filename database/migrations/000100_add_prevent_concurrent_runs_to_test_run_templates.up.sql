@@ -0,0 +1,2 @@
+ALTER TABLE test_run_templates
+    ADD COLUMN prevent_concurrent_runs BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS procedure_shares (
+    id CHAR(36) PRIMARY KEY,
+    procedure_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    access VARCHAR(20) NOT NULL DEFAULT 'read_only',
+    shared_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (procedure_id) REFERENCES test_procedures(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_procedure_shares_procedure_user (procedure_id, user_id),
+    INDEX idx_procedure_shares_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigrationFiles creates empty migration files in a temporary directory.
+func writeMigrationFiles(t *testing.T, names ...string) string {
+	dir := t.TempDir()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1\n"), 0o644))
+	}
+	return dir
+}
+
+func TestListMigrations(t *testing.T) {
+	t.Run("repository migrations are complete and ordered", func(t *testing.T) {
+		migrations, err := ListMigrations("migrations")
+		require.NoError(t, err)
+		require.NotEmpty(t, migrations)
+		for i, m := range migrations {
+			assert.Equal(t, uint(i+1), m.Version, "migration versions must be contiguous")
+		}
+	})
+
+	t.Run("sorted by version", func(t *testing.T) {
+		dir := writeMigrationFiles(t,
+			"000002_add_b.up.sql", "000002_add_b.down.sql",
+			"000001_add_a.up.sql", "000001_add_a.down.sql",
+			"README.md",
+		)
+		migrations, err := ListMigrations(dir)
+		require.NoError(t, err)
+		assert.Equal(t, []Migration{{Version: 1, Name: "add_a"}, {Version: 2, Name: "add_b"}}, migrations)
+	})
+
+	t.Run("missing down file", func(t *testing.T) {
+		dir := writeMigrationFiles(t, "000001_add_a.up.sql")
+		_, err := ListMigrations(dir)
+		assert.Error(t, err)
+	})
+
+	t.Run("two names for one version", func(t *testing.T) {
+		dir := writeMigrationFiles(t,
+			"000001_add_a.up.sql", "000001_add_a.down.sql",
+			"000001_add_b.up.sql", "000001_add_b.down.sql",
+		)
+		_, err := ListMigrations(dir)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := ListMigrations(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewMigrationStatus(t *testing.T) {
+	available := []Migration{{Version: 1, Name: "a"}, {Version: 2, Name: "b"}, {Version: 3, Name: "c"}}
+
+	t.Run("fresh database has everything pending", func(t *testing.T) {
+		status := newMigrationStatus(available, 0, false)
+		assert.Len(t, status.Pending, 3)
+		assert.False(t, status.UpToDate())
+	})
+
+	t.Run("partially migrated", func(t *testing.T) {
+		status := newMigrationStatus(available, 2, false)
+		assert.Equal(t, []Migration{{Version: 3, Name: "c"}}, status.Pending)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		status := newMigrationStatus(available, 3, false)
+		assert.Empty(t, status.Pending)
+		assert.True(t, status.UpToDate())
+	})
+
+	t.Run("dirty is never up to date", func(t *testing.T) {
+		status := newMigrationStatus(available, 3, true)
+		assert.False(t, status.UpToDate())
+	})
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	gomysql "github.com/go-sql-driver/mysql"
+)
+
+// passwordConnector opens MySQL connections with the password current at
+// the time of connecting, so that new connections pick up a rotated
+// password without the pool being reopened.
+type passwordConnector struct {
+	cfg      *gomysql.Config
+	password func() string
+}
+
+// Connect implements driver.Connector.
+func (c *passwordConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	cfg := c.cfg.Clone()
+	cfg.Passwd = c.password()
+	connector, err := gomysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+// Driver implements driver.Connector.
+func (c *passwordConnector) Driver() driver.Driver {
+	return gomysql.MySQLDriver{}
+}
+
+// openMySQL opens a MySQL connection pool for cfg. Connections take their
+// password from cfg.PasswordFunc when it is set.
+func openMySQL(cfg Config) (*sql.DB, error) {
+	if cfg.PasswordFunc == nil {
+		return sql.Open("mysql", mysqlDSN(cfg))
+	}
+
+	dsnCfg, err := gomysql.ParseDSN(mysqlDSN(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("invalid mysql configuration: %w", err)
+	}
+	return sql.OpenDB(&passwordConnector{cfg: dsnCfg, password: cfg.PasswordFunc}), nil
+}
@@ -0,0 +1,252 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// readReplicaKey is the statement setting that marks a query as safe to
+// serve from a read replica.
+const readReplicaKey = "database:read_replica"
+
+// ReplicaConfig holds the address of a MySQL read replica. Replicas are
+// connected to with the primary's user, password and database name.
+type ReplicaConfig struct {
+	Host string
+	Port int
+}
+
+// ReadReplica is a GORM scope that lets a query be served by a read
+// replica. It is meant for heavy list, search and count queries that can
+// tolerate data up to the configured maximum replica lag behind the primary.
+// Queries run inside a transaction always use the primary, and the scope
+// has no effect when no replicas are configured.
+func ReadReplica(db *gorm.DB) *gorm.DB {
+	return db.Set(readReplicaKey, true)
+}
+
+// LagFunc reports how far a replica is behind its primary.
+type LagFunc func(ctx context.Context, db *sql.DB) (time.Duration, error)
+
+// replica is a read replica connection and whether it is usable.
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// ReplicaRouter is a GORM plugin that sends queries scoped with ReadReplica
+// to healthy read replicas in turn. A replica is healthy when its lag was
+// last measured at or below the maximum lag; queries fall back to the
+// primary when no replica is healthy. Replicas start unhealthy until their
+// lag has been checked.
+type ReplicaRouter struct {
+	replicas []*replica
+	maxLag   time.Duration
+	lag      LagFunc
+	next     atomic.Uint64
+	logger   logger.Logger
+	stopCh   chan struct{}
+}
+
+// NewReplicaRouter creates a router for replicas whose lag is measured with
+// lag. Replicas lagging more than maxLag are not used.
+func NewReplicaRouter(maxLag time.Duration, lag LagFunc, log logger.Logger) *ReplicaRouter {
+	return &ReplicaRouter{
+		maxLag: maxLag,
+		lag:    lag,
+		logger: log,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// AddReplica adds a replica connection identified by name in logs.
+func (r *ReplicaRouter) AddReplica(name string, db *sql.DB) {
+	r.replicas = append(r.replicas, &replica{name: name, db: db})
+}
+
+// Name implements gorm.Plugin.
+func (r *ReplicaRouter) Name() string {
+	return "database:replica_router"
+}
+
+// Initialize implements gorm.Plugin, routing queries before they run.
+func (r *ReplicaRouter) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("database:route_query", r.route); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("database:route_row", r.route)
+}
+
+// route switches a statement scoped with ReadReplica to a healthy replica.
+func (r *ReplicaRouter) route(db *gorm.DB) {
+	if use, ok := db.Get(readReplicaKey); !ok || use != true {
+		return
+	}
+	// Reads in a transaction must see its writes.
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return
+	}
+	if rep := r.pick(); rep != nil {
+		db.Statement.ConnPool = rep.db
+	}
+}
+
+// pick returns the next healthy replica, or nil if there is none.
+func (r *ReplicaRouter) pick() *replica {
+	n := uint64(len(r.replicas))
+	start := r.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		rep := r.replicas[(start+i)%n]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// Check measures the lag of every replica and updates which are used.
+func (r *ReplicaRouter) Check(ctx context.Context) {
+	for _, rep := range r.replicas {
+		lag, err := r.lag(ctx, rep.db)
+		healthy := err == nil && lag <= r.maxLag
+		if rep.healthy.Swap(healthy) == healthy {
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"replica": rep.name,
+			"lag":     lag.String(),
+			"max_lag": r.maxLag.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		if healthy {
+			r.logger.Info(ctx, "read replica in use", fields)
+		} else {
+			r.logger.Warn(ctx, "read replica unavailable, reading from primary", fields)
+		}
+	}
+}
+
+// Start checks replica lag now and then every interval in a background
+// goroutine.
+func (r *ReplicaRouter) Start(interval time.Duration) {
+	r.Check(context.Background())
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				r.Check(context.Background())
+			case <-r.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the lag checks and closes the replica connections.
+func (r *ReplicaRouter) Stop() {
+	close(r.stopCh)
+	for _, rep := range r.replicas {
+		rep.db.Close()
+	}
+}
+
+// ConnectReplicas connects to the read replicas in cfg and registers a
+// router for them on db. It returns nil if no replicas are configured. The
+// caller starts the router's lag checks; until then every query uses the
+// primary.
+func ConnectReplicas(db *gorm.DB, cfg Config, log logger.Logger) (*ReplicaRouter, error) {
+	if len(cfg.Replicas) == 0 {
+		return nil, nil
+	}
+	if cfg.Driver != "" && cfg.Driver != DriverMySQL {
+		return nil, fmt.Errorf("read replicas require the mysql driver")
+	}
+
+	router := NewReplicaRouter(cfg.ReplicaMaxLag, MySQLReplicaLag, log)
+	for _, rc := range cfg.Replicas {
+		replicaCfg := cfg
+		replicaCfg.Host = rc.Host
+		replicaCfg.Port = rc.Port
+
+		// Opening does not connect, so an unreachable replica does not stop
+		// the server starting; it stays unused until its lag can be checked.
+		sqlDB, err := openMySQL(replicaCfg)
+		if err != nil {
+			router.Stop()
+			return nil, fmt.Errorf("failed to open replica %s:%d: %w", rc.Host, rc.Port, err)
+		}
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(time.Hour)
+		router.AddReplica(fmt.Sprintf("%s:%d", rc.Host, rc.Port), sqlDB)
+	}
+
+	if err := db.Use(router); err != nil {
+		router.Stop()
+		return nil, fmt.Errorf("failed to register replica router: %w", err)
+	}
+	return router, nil
+}
+
+// MySQLReplicaLag reads a MySQL replica's lag from its replication status.
+// A replica whose replication is not running is reported as an error.
+func MySQLReplicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	// SHOW REPLICA STATUS replaced SHOW SLAVE STATUS in MySQL 8.0.22.
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = db.QueryContext(ctx, "SHOW SLAVE STATUS")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read replica status: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read replica status: %w", err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, fmt.Errorf("failed to read replica status: %w", err)
+		}
+		return 0, errors.New("server is not a replica")
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, fmt.Errorf("failed to read replica status: %w", err)
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, errors.New("replication is not running")
+		}
+		seconds, err := strconv.Atoi(values[i].String)
+		if err != nil {
+			return 0, fmt.Errorf("invalid replica lag %q: %w", values[i].String, err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, errors.New("replica status has no lag")
+}
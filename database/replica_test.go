@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// openTestSQLite opens a SQLite database holding a single widget named name.
+func openTestSQLite(t *testing.T, name string) *gorm.DB {
+	db, err := Connect(Config{Driver: DriverSQLite, SQLitePath: filepath.Join(t.TempDir(), name+".db"), MaxOpenConns: 2, MaxIdleConns: 1})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, CreateSchema(db, &testWidget{}))
+	require.NoError(t, db.Create(&testWidget{Name: name}).Error)
+	return db
+}
+
+func TestReplicaRouter(t *testing.T) {
+	ctx := context.Background()
+	primary := openTestSQLite(t, "primary")
+	replicaDB, err := openTestSQLite(t, "replica").DB()
+	require.NoError(t, err)
+
+	var lag time.Duration
+	var lagErr error
+	router := NewReplicaRouter(5*time.Second, func(ctx context.Context, db *sql.DB) (time.Duration, error) {
+		return lag, lagErr
+	}, logger.NewTestLogger())
+	router.AddReplica("replica", replicaDB)
+	require.NoError(t, primary.Use(router))
+
+	widgetName := func(db *gorm.DB) string {
+		var w testWidget
+		require.NoError(t, db.First(&w).Error)
+		return w.Name
+	}
+
+	t.Run("replicas are unused until checked", func(t *testing.T) {
+		assert.Equal(t, "primary", widgetName(primary.Scopes(ReadReplica)))
+	})
+
+	router.Check(ctx)
+
+	t.Run("scoped queries read from a healthy replica", func(t *testing.T) {
+		assert.Equal(t, "replica", widgetName(primary.Scopes(ReadReplica)))
+
+		var count int64
+		require.NoError(t, primary.Model(&testWidget{}).Scopes(ReadReplica).Where("name = ?", "replica").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("unscoped queries read from the primary", func(t *testing.T) {
+		assert.Equal(t, "primary", widgetName(primary))
+	})
+
+	t.Run("writes go to the primary", func(t *testing.T) {
+		require.NoError(t, primary.Scopes(ReadReplica).Create(&testWidget{Name: "written"}).Error)
+		var count int64
+		require.NoError(t, primary.Model(&testWidget{}).Where("name = ?", "written").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("transactions read from the primary", func(t *testing.T) {
+		err := primary.Transaction(func(tx *gorm.DB) error {
+			assert.Equal(t, "primary", widgetName(tx.Scopes(ReadReplica)))
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("lagging replica falls back to the primary", func(t *testing.T) {
+		lag = time.Minute
+		router.Check(ctx)
+		assert.Equal(t, "primary", widgetName(primary.Scopes(ReadReplica)))
+
+		lag = time.Second
+		router.Check(ctx)
+		assert.Equal(t, "replica", widgetName(primary.Scopes(ReadReplica)))
+	})
+
+	t.Run("failed lag check falls back to the primary", func(t *testing.T) {
+		lagErr = errors.New("replication is not running")
+		router.Check(ctx)
+		assert.Equal(t, "primary", widgetName(primary.Scopes(ReadReplica)))
+	})
+}
+
+func TestConnectReplicas(t *testing.T) {
+	t.Run("no replicas returns nil", func(t *testing.T) {
+		router, err := ConnectReplicas(nil, Config{}, logger.NewTestLogger())
+		require.NoError(t, err)
+		assert.Nil(t, router)
+	})
+
+	t.Run("replicas require mysql", func(t *testing.T) {
+		_, err := ConnectReplicas(nil, Config{Driver: DriverSQLite, Replicas: []ReplicaConfig{{Host: "replica", Port: 3306}}}, logger.NewTestLogger())
+		assert.Error(t, err)
+	})
+}
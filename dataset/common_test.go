@@ -0,0 +1,32 @@
+package dataset
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and dataset store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Dataset{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestDataset creates a dataset of a procedure with the given rows.
+func createTestDataset(name string, procedureID uuid.UUID, rows ...Row) *Dataset {
+	return &Dataset{
+		TestProcedureID: procedureID,
+		Name:            name,
+		Columns:         Columns{"username"},
+		Rows:            rows,
+		CreatedBy:       uuid.New(),
+	}
+}
@@ -0,0 +1,153 @@
+// Package dataset holds the datasets attached to test procedures: tables of
+// parameter values, uploaded as CSV or JSON, from which a batch of runs is
+// launched, one per row, with the row's values filled into the procedure's
+// placeholders.
+package dataset
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxRows is the maximum number of rows in a dataset, and so of runs
+// launched from it at once.
+const MaxRows = 500
+
+var (
+	// ErrDatasetNotFound is returned when a dataset is not found.
+	ErrDatasetNotFound = errors.New("dataset not found")
+
+	// ErrInvalidName is returned when a dataset name is empty.
+	ErrInvalidName = errors.New("dataset name is required")
+
+	// ErrInvalidProcedureID is returned when test_procedure_id is not set.
+	ErrInvalidProcedureID = errors.New("test_procedure_id is required")
+
+	// ErrInvalidCreatedBy is returned when created_by is not set.
+	ErrInvalidCreatedBy = errors.New("created_by is required")
+
+	// ErrNoRows is returned when a dataset has no rows.
+	ErrNoRows = errors.New("dataset must have at least one row")
+
+	// ErrTooManyRows is returned when a dataset has more than MaxRows rows.
+	ErrTooManyRows = errors.New("dataset has too many rows")
+
+	// ErrInvalidData is returned when uploaded CSV or JSON cannot be read
+	// as rows of values.
+	ErrInvalidData = errors.New("invalid dataset")
+)
+
+// Row holds the values of one row of a dataset, by column.
+type Row map[string]string
+
+// Rows represents the JSON rows of a dataset.
+type Rows []Row
+
+// Value implements the driver.Valuer interface for database storage.
+func (r Rows) Value() (driver.Value, error) {
+	if r == nil {
+		return json.Marshal([]Row{})
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (r *Rows) Scan(value interface{}) error {
+	if value == nil {
+		*r = Rows{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan Rows: not a byte slice")
+	}
+
+	var rows []Row
+	if err := json.Unmarshal(bytes, &rows); err != nil {
+		return err
+	}
+	*r = rows
+	return nil
+}
+
+// Columns represents the JSON column names of a dataset.
+type Columns []string
+
+// Value implements the driver.Valuer interface for database storage.
+func (c Columns) Value() (driver.Value, error) {
+	if c == nil {
+		return json.Marshal([]string{})
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (c *Columns) Scan(value interface{}) error {
+	if value == nil {
+		*c = Columns{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan Columns: not a byte slice")
+	}
+
+	var columns []string
+	if err := json.Unmarshal(bytes, &columns); err != nil {
+		return err
+	}
+	*c = columns
+	return nil
+}
+
+// Dataset is a table of parameter values attached to a test procedure. It
+// belongs to the procedure's first version, so it is shared by every
+// version. Rows are stored in data_rows, as ROWS is reserved in MySQL.
+type Dataset struct {
+	ID              uuid.UUID      `json:"id" gorm:"type:char(36);primaryKey"`
+	TestProcedureID uuid.UUID      `json:"test_procedure_id" gorm:"type:char(36);not null;index:idx_datasets_test_procedure_id"`
+	Name            string         `json:"name" gorm:"type:varchar(255);not null"`
+	Columns         Columns        `json:"columns" gorm:"type:json"`
+	Rows            Rows           `json:"rows,omitempty" gorm:"column:data_rows;type:json"`
+	RowCount        int            `json:"row_count" gorm:"not null;default:0"`
+	CreatedBy       uuid.UUID      `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new dataset.
+func (d *Dataset) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	d.RowCount = len(d.Rows)
+	return nil
+}
+
+// Validate checks if the dataset has valid required fields.
+func (d *Dataset) Validate() error {
+	if d.Name == "" {
+		return ErrInvalidName
+	}
+	if d.TestProcedureID == uuid.Nil {
+		return ErrInvalidProcedureID
+	}
+	if d.CreatedBy == uuid.Nil {
+		return ErrInvalidCreatedBy
+	}
+	if len(d.Rows) == 0 {
+		return ErrNoRows
+	}
+	if len(d.Rows) > MaxRows {
+		return ErrTooManyRows
+	}
+	return nil
+}
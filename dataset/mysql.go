@@ -0,0 +1,112 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed dataset store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new dataset in the database.
+func (s *MySQLStore) Create(ctx context.Context, dataset *Dataset) error {
+	if err := dataset.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(dataset).Error; err != nil {
+		s.logger.Error(ctx, "failed to create dataset", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": dataset.TestProcedureID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "dataset created", map[string]interface{}{
+		"dataset_id":        dataset.ID.String(),
+		"test_procedure_id": dataset.TestProcedureID.String(),
+		"rows":              len(dataset.Rows),
+	})
+
+	return nil
+}
+
+// GetByID retrieves a dataset by its ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*Dataset, error) {
+	var dataset Dataset
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&dataset).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDatasetNotFound
+		}
+		s.logger.Error(ctx, "failed to get dataset by ID", map[string]interface{}{
+			"error":      err.Error(),
+			"dataset_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &dataset, nil
+}
+
+// Delete soft-deletes a dataset by its ID.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&Dataset{}, "id = ?", id)
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete dataset", map[string]interface{}{
+			"error":      result.Error.Error(),
+			"dataset_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrDatasetNotFound
+	}
+
+	s.logger.Info(ctx, "dataset deleted", map[string]interface{}{
+		"dataset_id": id.String(),
+	})
+
+	return nil
+}
+
+// ListByTestProcedure retrieves the datasets attached to a procedure, by
+// name, without their rows.
+func (s *MySQLStore) ListByTestProcedure(ctx context.Context, testProcedureID uuid.UUID) ([]*Dataset, error) {
+	var datasets []*Dataset
+	err := s.db.WithContext(ctx).
+		Omit("data_rows").
+		Where("test_procedure_id = ?", testProcedureID).
+		Order("name ASC").
+		Order("id ASC").
+		Find(&datasets).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list datasets", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": testProcedureID.String(),
+		})
+		return nil, err
+	}
+
+	return datasets, nil
+}
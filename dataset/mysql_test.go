@@ -0,0 +1,64 @@
+package dataset
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Create(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("create dataset", func(t *testing.T) {
+		ds := createTestDataset("Users", uuid.New(), Row{"username": "alice"}, Row{"username": "bob"})
+		require.NoError(t, store.Create(ctx, ds))
+
+		retrieved, err := store.GetByID(ctx, ds.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Users", retrieved.Name)
+		assert.Equal(t, Columns{"username"}, retrieved.Columns)
+		assert.Equal(t, Rows{{"username": "alice"}, {"username": "bob"}}, retrieved.Rows)
+		assert.Equal(t, 2, retrieved.RowCount)
+	})
+
+	t.Run("invalid datasets return errors", func(t *testing.T) {
+		assert.ErrorIs(t, store.Create(ctx, createTestDataset("", uuid.New(), Row{"username": "alice"})), ErrInvalidName)
+		assert.ErrorIs(t, store.Create(ctx, createTestDataset("Empty", uuid.New())), ErrNoRows)
+		assert.ErrorIs(t, store.Create(ctx, createTestDataset("No procedure", uuid.Nil, Row{"username": "alice"})), ErrInvalidProcedureID)
+	})
+}
+
+func TestMySQLStore_ListByTestProcedure(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	procedureID := uuid.New()
+
+	require.NoError(t, store.Create(ctx, createTestDataset("Staging users", procedureID, Row{"username": "alice"})))
+	require.NoError(t, store.Create(ctx, createTestDataset("Admins", procedureID, Row{"username": "root"}, Row{"username": "admin"})))
+	require.NoError(t, store.Create(ctx, createTestDataset("Other", uuid.New(), Row{"username": "carol"})))
+
+	datasets, err := store.ListByTestProcedure(ctx, procedureID)
+	require.NoError(t, err)
+	require.Len(t, datasets, 2)
+	assert.Equal(t, "Admins", datasets[0].Name)
+	assert.Equal(t, 2, datasets[0].RowCount)
+	assert.Empty(t, datasets[0].Rows, "rows are left out of lists")
+	assert.Equal(t, "Staging users", datasets[1].Name)
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	ds := createTestDataset("Users", uuid.New(), Row{"username": "alice"})
+	require.NoError(t, store.Create(ctx, ds))
+
+	require.NoError(t, store.Delete(ctx, ds.ID))
+	_, err := store.GetByID(ctx, ds.ID)
+	assert.ErrorIs(t, err, ErrDatasetNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, ds.ID), ErrDatasetNotFound)
+}
@@ -0,0 +1,113 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ParseCSV reads rows from CSV whose first record names the columns. Empty
+// cells are left out of their row, so the parameter's default applies.
+func ParseCSV(r io.Reader) (Columns, Rows, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, ErrNoRows
+		}
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	columns := make(Columns, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, nil, fmt.Errorf("%w: column %d has no name", ErrInvalidData, i+1)
+		}
+		if seen[name] {
+			return nil, nil, fmt.Errorf("%w: column %s appears more than once", ErrInvalidData, name)
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+
+	var rows Rows
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		if len(rows) == MaxRows {
+			return nil, nil, fmt.Errorf("%w: at most %d are allowed", ErrTooManyRows, MaxRows)
+		}
+		row := make(Row, len(record))
+		for i, value := range record {
+			if value != "" {
+				row[columns[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, nil, ErrNoRows
+	}
+	return columns, rows, nil
+}
+
+// ParseJSON reads rows from a JSON array of objects whose values are
+// strings, numbers or booleans. Null values are left out of their row, so
+// the parameter's default applies. The columns are every key used, sorted.
+func ParseJSON(data []byte) (Columns, Rows, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var objects []map[string]interface{}
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, nil, fmt.Errorf("%w: expected an array of objects: %v", ErrInvalidData, err)
+	}
+	if len(objects) == 0 {
+		return nil, nil, ErrNoRows
+	}
+	if len(objects) > MaxRows {
+		return nil, nil, fmt.Errorf("%w: at most %d are allowed", ErrTooManyRows, MaxRows)
+	}
+
+	seen := make(map[string]bool)
+	rows := make(Rows, len(objects))
+	for i, object := range objects {
+		row := make(Row, len(object))
+		for name, value := range object {
+			switch v := value.(type) {
+			case nil:
+				continue
+			case string:
+				row[name] = v
+			case json.Number:
+				row[name] = v.String()
+			case bool:
+				row[name] = fmt.Sprint(v)
+			default:
+				return nil, nil, fmt.Errorf("%w: row %d: %s must be a string, number or boolean", ErrInvalidData, i+1, name)
+			}
+			seen[name] = true
+		}
+		rows[i] = row
+	}
+
+	columns := make(Columns, 0, len(seen))
+	for name := range seen {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+	return columns, rows, nil
+}
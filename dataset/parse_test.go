@@ -0,0 +1,69 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("rows by header", func(t *testing.T) {
+		columns, rows, err := ParseCSV(strings.NewReader("username, password,retries\nalice,secret,3\nbob,\"pa,ss\",\n"))
+		require.NoError(t, err)
+		assert.Equal(t, Columns{"username", "password", "retries"}, columns)
+		assert.Equal(t, Rows{
+			{"username": "alice", "password": "secret", "retries": "3"},
+			{"username": "bob", "password": "pa,ss"},
+		}, rows)
+	})
+
+	t.Run("header only", func(t *testing.T) {
+		_, _, err := ParseCSV(strings.NewReader("username\n"))
+		assert.ErrorIs(t, err, ErrNoRows)
+	})
+
+	t.Run("duplicate column", func(t *testing.T) {
+		_, _, err := ParseCSV(strings.NewReader("username,username\na,b\n"))
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	t.Run("ragged row", func(t *testing.T) {
+		_, _, err := ParseCSV(strings.NewReader("username,password\nalice\n"))
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	t.Run("too many rows", func(t *testing.T) {
+		csv := "username\n" + strings.Repeat("alice\n", MaxRows+1)
+		_, _, err := ParseCSV(strings.NewReader(csv))
+		assert.ErrorIs(t, err, ErrTooManyRows)
+	})
+}
+
+func TestParseJSON(t *testing.T) {
+	t.Run("rows of scalars", func(t *testing.T) {
+		columns, rows, err := ParseJSON([]byte(`[{"username":"alice","retries":3,"admin":true},{"username":"bob","retries":1.5,"admin":null}]`))
+		require.NoError(t, err)
+		assert.Equal(t, Columns{"admin", "retries", "username"}, columns)
+		assert.Equal(t, Rows{
+			{"username": "alice", "retries": "3", "admin": "true"},
+			{"username": "bob", "retries": "1.5"},
+		}, rows)
+	})
+
+	t.Run("not an array of objects", func(t *testing.T) {
+		_, _, err := ParseJSON([]byte(`{"username":"alice"}`))
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	t.Run("nested value", func(t *testing.T) {
+		_, _, err := ParseJSON([]byte(`[{"user":{"name":"alice"}}]`))
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, _, err := ParseJSON([]byte(`[]`))
+		assert.ErrorIs(t, err, ErrNoRows)
+	})
+}
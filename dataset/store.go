@@ -0,0 +1,23 @@
+package dataset
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for dataset persistence operations.
+type Store interface {
+	// Create creates a new dataset in the store.
+	Create(ctx context.Context, dataset *Dataset) error
+
+	// GetByID retrieves a dataset by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*Dataset, error)
+
+	// Delete soft-deletes a dataset by its ID.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListByTestProcedure retrieves the datasets attached to a procedure,
+	// by name, without their rows.
+	ListByTestProcedure(ctx context.Context, testProcedureID uuid.UUID) ([]*Dataset, error)
+}
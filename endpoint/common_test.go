@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
 	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
 	"gorm.io/gorm"
@@ -20,6 +21,25 @@ func setupTestStore(t *testing.T) (*gorm.DB, Store) {
 	return db, store
 }
 
+// setupTestHealthStores creates a test database with endpoint and health check
+// stores for testing.
+func setupTestHealthStores(t *testing.T) (Store, HealthStore) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Endpoint{}, &HealthCheck{})
+
+	log := logger.NewTestLogger()
+	return NewMySQLStore(db, log), NewMySQLHealthStore(db, log)
+}
+
+// setupTestSecretStore creates a test database and secret store for testing.
+func setupTestSecretStore(t *testing.T) (Store, SecretStore) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Endpoint{}, &Secret{})
+
+	log := logger.NewTestLogger()
+	return NewMySQLStore(db, log), NewMySQLSecretStore(db, integration.NewKeyring(integration.DeriveKey("test-encryption-key")), log)
+}
+
 // createTestEndpoint creates an endpoint with default values.
 func createTestEndpoint(name, url string, createdBy uuid.UUID, creds Credentials) *Endpoint {
 	return &Endpoint{
@@ -4,17 +4,55 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/runner"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrEndpointNotFound    = errors.New("endpoint not found")
-	ErrInvalidEndpointName = errors.New("endpoint name is required")
-	ErrInvalidEndpointURL  = errors.New("endpoint URL is required")
-	ErrInvalidCreatedBy    = errors.New("created_by is required")
+	ErrEndpointNotFound           = errors.New("endpoint not found")
+	ErrInvalidEndpointName        = errors.New("endpoint name is required")
+	ErrInvalidEndpointURL         = errors.New("endpoint URL is required")
+	ErrInvalidCreatedBy           = errors.New("created_by is required")
+	ErrInvalidMaxJobs             = errors.New("max_concurrent_jobs must be at least 1")
+	ErrInvalidHealthCheckInterval = fmt.Errorf("health_check_interval_seconds must be between %d and %d", MinHealthCheckInterval, MaxHealthCheckInterval)
+	ErrInvalidExpectedStatus      = errors.New("health_check_expected_status must be a valid HTTP status code")
+	ErrInvalidGroup               = fmt.Errorf("group must be at most %d characters", MaxGroupLength)
+	ErrInvalidEnvironment         = errors.New("environment must be up to 50 lowercase letters, digits, '-' or '_', starting with a letter")
+	ErrEnvironmentRequired        = errors.New("environment is required for endpoints in a group")
+	ErrDuplicateEnvironment       = errors.New("group already has an endpoint for this environment")
+)
+
+// MaxGroupLength is the longest endpoint group name.
+const MaxGroupLength = 100
+
+// environmentPattern restricts environment names to short slugs such as
+// dev, staging or prod.
+var environmentPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+// DefaultMaxConcurrentJobs is the number of jobs allowed to run against an
+// endpoint at the same time when no limit is configured.
+const DefaultMaxConcurrentJobs = 1
+
+// Health check defaults and limits, in seconds for intervals.
+const (
+	DefaultHealthCheckInterval = 300
+	MinHealthCheckInterval     = 30
+	MaxHealthCheckInterval     = 86400
+	DefaultExpectedStatus      = 200
+)
+
+// HealthStatus is the outcome of the most recent health check of an endpoint.
+type HealthStatus string
+
+const (
+	HealthStatusUnknown HealthStatus = "unknown"
+	HealthStatusUp      HealthStatus = "up"
+	HealthStatusDown    HealthStatus = "down"
 )
 
 type Credential struct {
@@ -51,13 +89,38 @@ func (c *Credentials) Scan(value interface{}) error {
 }
 
 type Endpoint struct {
-	ID          uuid.UUID   `json:"id" gorm:"type:char(36);primaryKey"`
-	Name        string      `json:"name" gorm:"not null"`
-	URL         string      `json:"url" gorm:"not null"`
-	Credentials Credentials `json:"credentials" gorm:"type:json"`
-	CreatedBy   uuid.UUID   `json:"created_by" gorm:"type:char(36);not null;index:idx_endpoints_created_by"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID                uuid.UUID   `json:"id" gorm:"type:char(36);primaryKey"`
+	Name              string      `json:"name" gorm:"not null"`
+	URL               string      `json:"url" gorm:"not null"`
+	Credentials       Credentials `json:"credentials" gorm:"type:json"`
+	MaxConcurrentJobs int         `json:"max_concurrent_jobs" gorm:"not null;default:1"`
+	CreatedBy         uuid.UUID   `json:"created_by" gorm:"type:char(36);not null;index:idx_endpoints_created_by;index:idx_endpoints_group_environment"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+
+	// Group ties together the endpoints of one application deployed to
+	// several environments, such as the dev, staging and prod instances of a
+	// shop. A group has at most one endpoint per environment.
+	Group       string `json:"group" gorm:"column:endpoint_group;type:varchar(100);not null;default:'';index:idx_endpoints_group_environment"`
+	Environment string `json:"environment" gorm:"type:varchar(50);not null;default:'';index:idx_endpoints_group_environment"`
+
+	// Periodic health checks request URL and expect the configured status
+	// code and, when set, the expected text somewhere in the response body.
+	HealthCheckEnabled  bool         `json:"health_check_enabled" gorm:"not null;default:false"`
+	HealthCheckInterval int          `json:"health_check_interval_seconds" gorm:"column:health_check_interval_seconds;not null;default:300"`
+	ExpectedStatus      int          `json:"health_check_expected_status" gorm:"column:health_check_expected_status;not null;default:200"`
+	ExpectedText        string       `json:"health_check_expected_text" gorm:"column:health_check_expected_text;type:varchar(255);not null;default:''"`
+	HealthStatus        HealthStatus `json:"health_status" gorm:"type:varchar(20);not null;default:'unknown'"`
+	LastHealthCheckAt   *time.Time   `json:"last_health_check_at,omitempty"`
+
+	// UseRunner hands the endpoint's exploration jobs to agent runners
+	// (cmd/agent-runner) instead of the server's workers, for applications
+	// only reachable from a private network.
+	UseRunner bool `json:"use_runner" gorm:"not null;default:false"`
+
+	// RunnerLabels are the labels a runner must have to run the endpoint's
+	// jobs, such as needs-vpn for a runner inside the right network.
+	RunnerLabels runner.Labels `json:"runner_labels" gorm:"type:json"`
 }
 
 // BeforeCreate hook to generate UUID before creating a new endpoint.
@@ -65,6 +128,18 @@ func (e *Endpoint) BeforeCreate(tx *gorm.DB) error {
 	if e.ID == uuid.Nil {
 		e.ID = uuid.New()
 	}
+	if e.MaxConcurrentJobs == 0 {
+		e.MaxConcurrentJobs = DefaultMaxConcurrentJobs
+	}
+	if e.HealthCheckInterval == 0 {
+		e.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	if e.ExpectedStatus == 0 {
+		e.ExpectedStatus = DefaultExpectedStatus
+	}
+	if e.HealthStatus == "" {
+		e.HealthStatus = HealthStatusUnknown
+	}
 	return nil
 }
 
@@ -79,9 +154,58 @@ func (e *Endpoint) Validate() error {
 	if e.CreatedBy == uuid.Nil {
 		return ErrInvalidCreatedBy
 	}
+	if e.MaxConcurrentJobs < 0 {
+		return ErrInvalidMaxJobs
+	}
+	if e.HealthCheckInterval != 0 && !validHealthCheckInterval(e.HealthCheckInterval) {
+		return ErrInvalidHealthCheckInterval
+	}
+	if e.ExpectedStatus != 0 && !validExpectedStatus(e.ExpectedStatus) {
+		return ErrInvalidExpectedStatus
+	}
+	if err := e.RunnerLabels.Validate(); err != nil {
+		return err
+	}
+	return e.validateGroup()
+}
+
+// validateGroup checks the group and environment of the endpoint.
+func (e *Endpoint) validateGroup() error {
+	if len(e.Group) > MaxGroupLength {
+		return ErrInvalidGroup
+	}
+	if e.Environment != "" && !environmentPattern.MatchString(e.Environment) {
+		return ErrInvalidEnvironment
+	}
+	if e.Group != "" && e.Environment == "" {
+		return ErrEnvironmentRequired
+	}
 	return nil
 }
 
+func validHealthCheckInterval(seconds int) bool {
+	return seconds >= MinHealthCheckInterval && seconds <= MaxHealthCheckInterval
+}
+
+func validExpectedStatus(code int) bool {
+	return code >= 100 && code <= 599
+}
+
+// HealthCheckDue reports whether the endpoint's next health check is due at now.
+func (e *Endpoint) HealthCheckDue(now time.Time) bool {
+	if !e.HealthCheckEnabled {
+		return false
+	}
+	if e.LastHealthCheckAt == nil {
+		return true
+	}
+	interval := e.HealthCheckInterval
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	return !now.Before(e.LastHealthCheckAt.Add(time.Duration(interval) * time.Second))
+}
+
 // DefaultCredentials returns the default credential template.
 func DefaultCredentials() Credentials {
 	return Credentials{
@@ -0,0 +1,47 @@
+package endpoint
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidEndpointID is returned when a health check has no endpoint.
+var ErrInvalidEndpointID = errors.New("endpoint_id is required")
+
+// HealthCheck is the result of a single health check of an endpoint.
+type HealthCheck struct {
+	ID             uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	EndpointID     uuid.UUID `json:"endpoint_id" gorm:"type:char(36);not null;index:idx_endpoint_health_checks_endpoint_time"`
+	Up             bool      `json:"up" gorm:"not null"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	ResponseTimeMs int64     `json:"response_time_ms" gorm:"not null"`
+	Error          string    `json:"error,omitempty" gorm:"type:text"`
+	CheckedAt      time.Time `json:"checked_at" gorm:"not null;index:idx_endpoint_health_checks_endpoint_time"`
+}
+
+// TableName specifies the table name for HealthCheck.
+func (HealthCheck) TableName() string {
+	return "endpoint_health_checks"
+}
+
+// BeforeCreate hook to generate UUID before creating a new health check.
+func (h *HealthCheck) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	if h.CheckedAt.IsZero() {
+		h.CheckedAt = time.Now()
+	}
+	return nil
+}
+
+// UptimeStats summarises the health checks of an endpoint over a window.
+type UptimeStats struct {
+	Checks            int     `json:"checks"`
+	UpChecks          int     `json:"up_checks"`
+	UptimePercent     float64 `json:"uptime_percent"`
+	AvgResponseTimeMs float64 `json:"avg_response_time_ms"`
+}
@@ -0,0 +1,111 @@
+package endpoint
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLHealthStore implements the HealthStore interface using GORM and MySQL.
+type MySQLHealthStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLHealthStore creates a new MySQL-backed health check store.
+func NewMySQLHealthStore(db *gorm.DB, log logger.Logger) *MySQLHealthStore {
+	return &MySQLHealthStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create records a health check result.
+func (s *MySQLHealthStore) Create(ctx context.Context, check *HealthCheck) error {
+	if check.EndpointID == uuid.Nil {
+		return ErrInvalidEndpointID
+	}
+
+	if err := s.db.WithContext(ctx).Create(check).Error; err != nil {
+		s.logger.Error(ctx, "failed to create health check", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": check.EndpointID.String(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// ListRecent retrieves the most recent health checks of an endpoint, newest first.
+func (s *MySQLHealthStore) ListRecent(ctx context.Context, endpointID uuid.UUID, limit int) ([]*HealthCheck, error) {
+	var checks []*HealthCheck
+	err := s.db.WithContext(ctx).
+		Where("endpoint_id = ?", endpointID).
+		Order("checked_at DESC").
+		Limit(limit).
+		Find(&checks).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list health checks", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": endpointID.String(),
+		})
+		return nil, err
+	}
+
+	return checks, nil
+}
+
+// Uptime summarises the health checks of an endpoint made at or after since.
+func (s *MySQLHealthStore) Uptime(ctx context.Context, endpointID uuid.UUID, since time.Time) (*UptimeStats, error) {
+	var row struct {
+		Checks      int64
+		UpChecks    int64
+		AvgResponse float64
+	}
+	err := s.db.WithContext(ctx).
+		Model(&HealthCheck{}).
+		Select("COUNT(*) AS checks, COALESCE(SUM(CASE WHEN up THEN 1 ELSE 0 END), 0) AS up_checks, COALESCE(AVG(response_time_ms), 0) AS avg_response").
+		Where("endpoint_id = ? AND checked_at >= ?", endpointID, since).
+		Scan(&row).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to compute endpoint uptime", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": endpointID.String(),
+		})
+		return nil, err
+	}
+
+	stats := &UptimeStats{
+		Checks:            int(row.Checks),
+		UpChecks:          int(row.UpChecks),
+		AvgResponseTimeMs: math.Round(row.AvgResponse*10) / 10,
+	}
+	if stats.Checks > 0 {
+		stats.UptimePercent = math.Round(float64(stats.UpChecks)/float64(stats.Checks)*10000) / 100
+	}
+	return stats, nil
+}
+
+// DeleteBefore deletes health checks made before the given time.
+func (s *MySQLHealthStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("checked_at < ?", before).
+		Delete(&HealthCheck{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to prune health checks", map[string]interface{}{
+			"error":  result.Error.Error(),
+			"before": before,
+		})
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
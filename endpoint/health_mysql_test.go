@@ -0,0 +1,125 @@
+package endpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLHealthStore_Create(t *testing.T) {
+	store, healthStore := setupTestHealthStores(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Health", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+
+	t.Run("successfully record health check", func(t *testing.T) {
+		check := &HealthCheck{EndpointID: ep.ID, Up: true, StatusCode: 200, ResponseTimeMs: 42}
+		require.NoError(t, healthStore.Create(ctx, check))
+		assert.NotEqual(t, uuid.Nil, check.ID)
+		assert.False(t, check.CheckedAt.IsZero())
+	})
+
+	t.Run("missing endpoint returns error", func(t *testing.T) {
+		err := healthStore.Create(ctx, &HealthCheck{Up: true})
+		assert.ErrorIs(t, err, ErrInvalidEndpointID)
+	})
+}
+
+func TestMySQLHealthStore_ListRecent(t *testing.T) {
+	store, healthStore := setupTestHealthStores(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Health", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, healthStore.Create(ctx, &HealthCheck{
+			EndpointID:     ep.ID,
+			Up:             true,
+			ResponseTimeMs: int64(i),
+			CheckedAt:      now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	checks, err := healthStore.ListRecent(ctx, ep.ID, 3)
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+	assert.Equal(t, int64(4), checks[0].ResponseTimeMs)
+	assert.Equal(t, int64(2), checks[2].ResponseTimeMs)
+
+	t.Run("other endpoint has no checks", func(t *testing.T) {
+		checks, err := healthStore.ListRecent(ctx, uuid.New(), 3)
+		require.NoError(t, err)
+		assert.Empty(t, checks)
+	})
+}
+
+func TestMySQLHealthStore_Uptime(t *testing.T) {
+	store, healthStore := setupTestHealthStores(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Health", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+
+	now := time.Now()
+	results := []struct {
+		up       bool
+		duration int64
+		age      time.Duration
+	}{
+		{true, 100, time.Hour},
+		{true, 200, 2 * time.Hour},
+		{false, 300, 3 * time.Hour},
+		{false, 0, 48 * time.Hour},
+	}
+	for _, r := range results {
+		require.NoError(t, healthStore.Create(ctx, &HealthCheck{
+			EndpointID:     ep.ID,
+			Up:             r.up,
+			ResponseTimeMs: r.duration,
+			CheckedAt:      now.Add(-r.age),
+		}))
+	}
+
+	t.Run("only checks in the window are counted", func(t *testing.T) {
+		stats, err := healthStore.Uptime(ctx, ep.ID, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats.Checks)
+		assert.Equal(t, 2, stats.UpChecks)
+		assert.Equal(t, 66.67, stats.UptimePercent)
+		assert.Equal(t, 200.0, stats.AvgResponseTimeMs)
+	})
+
+	t.Run("no checks reports zero", func(t *testing.T) {
+		stats, err := healthStore.Uptime(ctx, uuid.New(), now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, stats.Checks)
+		assert.Equal(t, 0.0, stats.UptimePercent)
+	})
+}
+
+func TestMySQLHealthStore_DeleteBefore(t *testing.T) {
+	store, healthStore := setupTestHealthStores(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Health", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+
+	now := time.Now()
+	require.NoError(t, healthStore.Create(ctx, &HealthCheck{EndpointID: ep.ID, Up: true, CheckedAt: now.Add(-48 * time.Hour)}))
+	require.NoError(t, healthStore.Create(ctx, &HealthCheck{EndpointID: ep.ID, Up: true, CheckedAt: now}))
+
+	removed, err := healthStore.DeleteBefore(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	checks, err := healthStore.ListRecent(ctx, ep.ID, 10)
+	require.NoError(t, err)
+	assert.Len(t, checks, 1)
+}
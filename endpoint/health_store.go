@@ -0,0 +1,24 @@
+package endpoint
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HealthStore defines the interface for endpoint health check history.
+type HealthStore interface {
+	// Create records a health check result.
+	Create(ctx context.Context, check *HealthCheck) error
+
+	// ListRecent retrieves the most recent health checks of an endpoint, newest first.
+	ListRecent(ctx context.Context, endpointID uuid.UUID, limit int) ([]*HealthCheck, error)
+
+	// Uptime summarises the health checks of an endpoint made at or after since.
+	Uptime(ctx context.Context, endpointID uuid.UUID, since time.Time) (*UptimeStats, error)
+
+	// DeleteBefore deletes health checks made before the given time and returns
+	// the number of rows removed.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
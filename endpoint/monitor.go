@@ -0,0 +1,180 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+const (
+	// maxHealthBodyBytes caps how much of a response is searched for the expected text.
+	maxHealthBodyBytes = 1 << 20
+
+	// maxConcurrentHealthChecks bounds how many endpoints are checked at once.
+	maxConcurrentHealthChecks = 8
+
+	// pruneInterval is how often old health check history is deleted.
+	pruneInterval = time.Hour
+)
+
+// Monitor periodically checks the health of endpoints that have health
+// checks enabled and records the results.
+type Monitor struct {
+	store       Store
+	healthStore HealthStore
+	client      *http.Client
+	retention   time.Duration
+	logger      logger.Logger
+	stopCh      chan struct{}
+	lastPrune   time.Time
+}
+
+// NewMonitor creates a health monitor whose checks time out after timeout.
+// History older than retention is deleted.
+func NewMonitor(store Store, healthStore HealthStore, timeout, retention time.Duration, log logger.Logger) *Monitor {
+	return &Monitor{
+		store:       store,
+		healthStore: healthStore,
+		client:      &http.Client{Timeout: timeout},
+		retention:   retention,
+		logger:      log,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Check performs a health check of the endpoint now, records it in the
+// history and updates the endpoint's health status.
+func (m *Monitor) Check(ctx context.Context, ep *Endpoint) (*HealthCheck, error) {
+	check := m.probe(ctx, ep)
+
+	if err := m.healthStore.Create(ctx, check); err != nil {
+		return nil, err
+	}
+
+	status := HealthStatusDown
+	if check.Up {
+		status = HealthStatusUp
+	}
+	if err := m.store.SetHealthStatus(ctx, ep.ID, status, check.CheckedAt); err != nil {
+		return nil, err
+	}
+
+	if ep.HealthStatus != status {
+		m.logger.Info(ctx, "endpoint health changed", map[string]interface{}{
+			"endpoint_id": ep.ID.String(),
+			"from":        ep.HealthStatus,
+			"to":          status,
+			"error":       check.Error,
+		})
+	}
+
+	return check, nil
+}
+
+// probe requests the endpoint URL and evaluates the response.
+func (m *Monitor) probe(ctx context.Context, ep *Endpoint) *HealthCheck {
+	check := &HealthCheck{EndpointID: ep.ID, CheckedAt: time.Now()}
+
+	expectedStatus := ep.ExpectedStatus
+	if expectedStatus == 0 {
+		expectedStatus = DefaultExpectedStatus
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.URL, nil)
+	if err != nil {
+		check.Error = fmt.Sprintf("invalid endpoint URL: %v", err)
+		return check
+	}
+
+	start := time.Now()
+	resp, err := m.client.Do(req)
+	if err != nil {
+		check.ResponseTimeMs = time.Since(start).Milliseconds()
+		check.Error = err.Error()
+		return check
+	}
+	defer resp.Body.Close()
+
+	check.StatusCode = resp.StatusCode
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHealthBodyBytes))
+	check.ResponseTimeMs = time.Since(start).Milliseconds()
+
+	switch {
+	case resp.StatusCode != expectedStatus:
+		check.Error = fmt.Sprintf("unexpected status %d (expected %d)", resp.StatusCode, expectedStatus)
+	case ep.ExpectedText == "":
+		check.Up = true
+	case err != nil:
+		check.Error = fmt.Sprintf("failed to read response: %v", err)
+	case !strings.Contains(string(body), ep.ExpectedText):
+		check.Error = "response does not contain the expected text"
+	default:
+		check.Up = true
+	}
+	return check
+}
+
+// CheckDue checks every enabled endpoint whose interval has elapsed.
+func (m *Monitor) CheckDue(ctx context.Context, now time.Time) {
+	endpoints, err := m.store.ListHealthCheckEnabled(ctx)
+	if err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentHealthChecks)
+	for _, ep := range endpoints {
+		if !ep.HealthCheckDue(now) {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ep *Endpoint) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, err := m.Check(ctx, ep); err != nil {
+				m.logger.Error(ctx, "failed to record endpoint health check", map[string]interface{}{
+					"error":       err.Error(),
+					"endpoint_id": ep.ID.String(),
+				})
+			}
+		}(ep)
+	}
+	wg.Wait()
+
+	if m.retention > 0 && now.Sub(m.lastPrune) >= pruneInterval {
+		m.lastPrune = now
+		if removed, err := m.healthStore.DeleteBefore(ctx, now.Add(-m.retention)); err == nil && removed > 0 {
+			m.logger.Info(ctx, "pruned endpoint health history", map[string]interface{}{
+				"removed_count": removed,
+			})
+		}
+	}
+}
+
+// Start starts a background goroutine that checks due endpoints every interval.
+func (m *Monitor) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				m.CheckDue(context.Background(), now)
+			case <-m.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the monitoring goroutine.
+func (m *Monitor) Stop() {
+	close(m.stopCh)
+}
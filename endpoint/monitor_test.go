@@ -0,0 +1,129 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestMonitor(t *testing.T) (Store, HealthStore, *Monitor) {
+	store, healthStore := setupTestHealthStores(t)
+	monitor := NewMonitor(store, healthStore, 5*time.Second, 24*time.Hour, logger.NewTestLogger())
+	return store, healthStore, monitor
+}
+
+func TestMonitor_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			fmt.Fprint(w, "<html><body>Welcome back</body></html>")
+		}
+	}))
+	defer server.Close()
+
+	store, _, monitor := setupTestMonitor(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		url       string
+		status    int
+		text      string
+		wantUp    bool
+		wantError string
+	}{
+		{name: "up", url: server.URL, wantUp: true},
+		{name: "expected text present", url: server.URL, text: "Welcome", wantUp: true},
+		{name: "expected text missing", url: server.URL, text: "Maintenance", wantError: "expected text"},
+		{name: "unexpected status", url: server.URL + "/missing", wantError: "unexpected status 404"},
+		{name: "expected non-200 status", url: server.URL + "/missing", status: http.StatusNotFound, wantUp: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := createTestEndpoint(tt.name, tt.url, uuid.New(), nil)
+			ep.ExpectedStatus = tt.status
+			ep.ExpectedText = tt.text
+			require.NoError(t, store.Create(ctx, ep))
+
+			check, err := monitor.Check(ctx, ep)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUp, check.Up)
+			if tt.wantError != "" {
+				assert.Contains(t, check.Error, tt.wantError)
+			}
+
+			updated, err := store.GetByID(ctx, ep.ID)
+			require.NoError(t, err)
+			want := HealthStatusDown
+			if tt.wantUp {
+				want = HealthStatusUp
+			}
+			assert.Equal(t, want, updated.HealthStatus)
+			assert.NotNil(t, updated.LastHealthCheckAt)
+		})
+	}
+
+	t.Run("unreachable endpoint is down", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		ep := createTestEndpoint("Unreachable", closed.URL, uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, ep))
+
+		check, err := monitor.Check(ctx, ep)
+		require.NoError(t, err)
+		assert.False(t, check.Up)
+		assert.Equal(t, 0, check.StatusCode)
+		assert.NotEmpty(t, check.Error)
+	})
+}
+
+func TestMonitor_CheckDue(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	store, healthStore, monitor := setupTestMonitor(t)
+	ctx := context.Background()
+
+	enabled := createTestEndpoint("Enabled", server.URL, uuid.New(), nil)
+	enabled.HealthCheckEnabled = true
+	enabled.HealthCheckInterval = 60
+	require.NoError(t, store.Create(ctx, enabled))
+
+	disabled := createTestEndpoint("Disabled", server.URL, uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, disabled))
+
+	now := time.Now()
+	monitor.CheckDue(ctx, now)
+	assert.Equal(t, int32(1), hits.Load())
+
+	// The interval has not elapsed since the last check.
+	monitor.CheckDue(ctx, now.Add(30*time.Second))
+	assert.Equal(t, int32(1), hits.Load())
+
+	monitor.CheckDue(ctx, now.Add(2*time.Minute))
+	assert.Equal(t, int32(2), hits.Load())
+
+	checks, err := healthStore.ListRecent(ctx, enabled.ID, 10)
+	require.NoError(t, err)
+	assert.Len(t, checks, 2)
+
+	checks, err = healthStore.ListRecent(ctx, disabled.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, checks)
+}
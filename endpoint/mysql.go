@@ -3,6 +3,7 @@ package endpoint
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
@@ -33,6 +34,10 @@ func (s *MySQLStore) Create(ctx context.Context, endpoint *Endpoint) error {
 		endpoint.Credentials = DefaultCredentials()
 	}
 
+	if err := s.checkEnvironmentAvailable(ctx, endpoint); err != nil {
+		return err
+	}
+
 	result := s.db.WithContext(ctx).Create(endpoint)
 	if result.Error != nil {
 		s.logger.Error(ctx, "failed to create endpoint", map[string]interface{}{
@@ -78,6 +83,13 @@ func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...Update
 		}
 	}
 
+	if err := ep.validateGroup(); err != nil {
+		return err
+	}
+	if err := s.checkEnvironmentAvailable(ctx, ep); err != nil {
+		return err
+	}
+
 	if err := s.db.WithContext(ctx).Save(ep).Error; err != nil {
 		s.logger.Error(ctx, "failed to update endpoint", map[string]interface{}{
 			"error":       err.Error(),
@@ -93,6 +105,34 @@ func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...Update
 	return nil
 }
 
+// checkEnvironmentAvailable returns ErrDuplicateEnvironment when another
+// endpoint of the creator's group already serves the endpoint's environment.
+func (s *MySQLStore) checkEnvironmentAvailable(ctx context.Context, ep *Endpoint) error {
+	if ep.Group == "" {
+		return nil
+	}
+
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&Endpoint{}).
+		Where("created_by = ? AND endpoint_group = ? AND environment = ? AND id <> ?", ep.CreatedBy, ep.Group, ep.Environment, ep.ID).
+		Count(&count).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to check endpoint environment", map[string]interface{}{
+			"error":       err.Error(),
+			"group":       ep.Group,
+			"environment": ep.Environment,
+		})
+		return err
+	}
+
+	if count > 0 {
+		return ErrDuplicateEnvironment
+	}
+	return nil
+}
+
 // Delete deletes an endpoint (hard delete).
 func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
 	result := s.db.WithContext(ctx).
@@ -159,3 +199,105 @@ func (s *MySQLStore) CountByCreator(ctx context.Context, createdBy uuid.UUID) (i
 
 	return int(count), nil
 }
+
+// GetByGroupEnvironment retrieves the endpoint of a creator's group for an environment.
+func (s *MySQLStore) GetByGroupEnvironment(ctx context.Context, createdBy uuid.UUID, group, environment string) (*Endpoint, error) {
+	var ep Endpoint
+	err := s.db.WithContext(ctx).
+		Where("created_by = ? AND endpoint_group = ? AND environment = ?", createdBy, group, environment).
+		First(&ep).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEndpointNotFound
+		}
+		s.logger.Error(ctx, "failed to get endpoint by group environment", map[string]interface{}{
+			"error":       err.Error(),
+			"group":       group,
+			"environment": environment,
+		})
+		return nil, err
+	}
+
+	return &ep, nil
+}
+
+// ListGrouped retrieves a creator's endpoints that belong to a group,
+// ordered by group and environment.
+func (s *MySQLStore) ListGrouped(ctx context.Context, createdBy uuid.UUID) ([]*Endpoint, error) {
+	var endpoints []*Endpoint
+	err := s.db.WithContext(ctx).
+		Where("created_by = ? AND endpoint_group <> ''", createdBy).
+		Order("endpoint_group ASC, environment ASC").
+		Find(&endpoints).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list grouped endpoints", map[string]interface{}{
+			"error":      err.Error(),
+			"created_by": createdBy.String(),
+		})
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+// ListHealthCheckEnabled retrieves all endpoints with periodic health checks enabled.
+func (s *MySQLStore) ListHealthCheckEnabled(ctx context.Context) ([]*Endpoint, error) {
+	var endpoints []*Endpoint
+	err := s.db.WithContext(ctx).
+		Where("health_check_enabled = ?", true).
+		Find(&endpoints).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list health checked endpoints", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+// ListUsingRunner retrieves all endpoints whose jobs run on agent runners.
+func (s *MySQLStore) ListUsingRunner(ctx context.Context) ([]*Endpoint, error) {
+	var endpoints []*Endpoint
+	err := s.db.WithContext(ctx).
+		Where("use_runner = ?", true).
+		Find(&endpoints).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list runner endpoints", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+// SetHealthStatus records the outcome of the latest health check of an endpoint.
+// Only the health columns are written so concurrent edits are not overwritten.
+func (s *MySQLStore) SetHealthStatus(ctx context.Context, id uuid.UUID, status HealthStatus, checkedAt time.Time) error {
+	result := s.db.WithContext(ctx).
+		Model(&Endpoint{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"health_status":        status,
+			"last_health_check_at": checkedAt,
+		})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to set endpoint health status", map[string]interface{}{
+			"error":       result.Error.Error(),
+			"endpoint_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrEndpointNotFound
+	}
+
+	return nil
+}
@@ -3,8 +3,10 @@ package endpoint
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/runner"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -67,6 +69,23 @@ func TestMySQLStore_Create(t *testing.T) {
 		err := store.Create(ctx, ep)
 		assert.ErrorIs(t, err, ErrInvalidCreatedBy)
 	})
+
+	t.Run("max concurrent jobs defaults to one", func(t *testing.T) {
+		ep := createTestEndpoint("Default Limit", "https://example.com", uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, ep))
+		assert.Equal(t, DefaultMaxConcurrentJobs, ep.MaxConcurrentJobs)
+
+		retrieved, err := store.GetByID(ctx, ep.ID)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultMaxConcurrentJobs, retrieved.MaxConcurrentJobs)
+	})
+
+	t.Run("negative max concurrent jobs returns error", func(t *testing.T) {
+		ep := createTestEndpoint("Bad Limit", "https://example.com", uuid.New(), nil)
+		ep.MaxConcurrentJobs = -1
+		err := store.Create(ctx, ep)
+		assert.ErrorIs(t, err, ErrInvalidMaxJobs)
+	})
 }
 
 func TestMySQLStore_GetByID(t *testing.T) {
@@ -169,6 +188,64 @@ func TestMySQLStore_Update(t *testing.T) {
 		assert.ErrorIs(t, err, ErrInvalidEndpointName)
 	})
 
+	t.Run("update max concurrent jobs", func(t *testing.T) {
+		ep := createTestEndpoint("Test", "https://example.com", uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, ep))
+
+		err := store.Update(ctx, ep.ID, SetMaxConcurrentJobs(3))
+		require.NoError(t, err)
+
+		retrieved, err := store.GetByID(ctx, ep.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, retrieved.MaxConcurrentJobs)
+	})
+
+	t.Run("update with zero max concurrent jobs returns error", func(t *testing.T) {
+		ep := createTestEndpoint("Test", "https://example.com", uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, ep))
+
+		err := store.Update(ctx, ep.ID, SetMaxConcurrentJobs(0))
+		assert.ErrorIs(t, err, ErrInvalidMaxJobs)
+	})
+
+	t.Run("update health check settings", func(t *testing.T) {
+		ep := createTestEndpoint("Test", "https://example.com", uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, ep))
+		assert.Equal(t, DefaultHealthCheckInterval, ep.HealthCheckInterval)
+		assert.Equal(t, HealthStatusUnknown, ep.HealthStatus)
+
+		err := store.Update(ctx, ep.ID,
+			SetHealthCheckEnabled(true),
+			SetHealthCheckInterval(60),
+			SetExpectedStatus(204),
+			SetExpectedText("ok"),
+		)
+		require.NoError(t, err)
+
+		retrieved, err := store.GetByID(ctx, ep.ID)
+		require.NoError(t, err)
+		assert.True(t, retrieved.HealthCheckEnabled)
+		assert.Equal(t, 60, retrieved.HealthCheckInterval)
+		assert.Equal(t, 204, retrieved.ExpectedStatus)
+		assert.Equal(t, "ok", retrieved.ExpectedText)
+	})
+
+	t.Run("update with out of range health check interval returns error", func(t *testing.T) {
+		ep := createTestEndpoint("Test", "https://example.com", uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, ep))
+
+		err := store.Update(ctx, ep.ID, SetHealthCheckInterval(MinHealthCheckInterval-1))
+		assert.ErrorIs(t, err, ErrInvalidHealthCheckInterval)
+	})
+
+	t.Run("update with invalid expected status returns error", func(t *testing.T) {
+		ep := createTestEndpoint("Test", "https://example.com", uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, ep))
+
+		err := store.Update(ctx, ep.ID, SetExpectedStatus(700))
+		assert.ErrorIs(t, err, ErrInvalidExpectedStatus)
+	})
+
 	t.Run("update with empty URL returns error", func(t *testing.T) {
 		createdBy := uuid.New()
 		ep := createTestEndpoint("Test", "https://example.com", createdBy, nil)
@@ -285,3 +362,154 @@ func TestMySQLStore_CountByCreator(t *testing.T) {
 		assert.Equal(t, 0, count)
 	})
 }
+
+func TestMySQLStore_ListHealthCheckEnabled(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	enabled := createTestEndpoint("Enabled", "https://example.com", uuid.New(), nil)
+	enabled.HealthCheckEnabled = true
+	require.NoError(t, store.Create(ctx, enabled))
+	require.NoError(t, store.Create(ctx, createTestEndpoint("Disabled", "https://example.com", uuid.New(), nil)))
+
+	endpoints, err := store.ListHealthCheckEnabled(ctx)
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, enabled.ID, endpoints[0].ID)
+}
+
+func TestMySQLStore_ListUsingRunner(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	onRunner := createTestEndpoint("Runner", "http://10.0.0.5", uuid.New(), nil)
+	onRunner.UseRunner = true
+	onRunner.RunnerLabels = runner.Labels{"needs-vpn"}
+	require.NoError(t, store.Create(ctx, onRunner))
+	require.NoError(t, store.Create(ctx, createTestEndpoint("Server", "https://example.com", uuid.New(), nil)))
+
+	endpoints, err := store.ListUsingRunner(ctx)
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, onRunner.ID, endpoints[0].ID)
+	assert.Equal(t, runner.Labels{"needs-vpn"}, endpoints[0].RunnerLabels)
+}
+
+func TestMySQLStore_Update_RunnerLabels(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Runner", "http://10.0.0.5", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+
+	require.NoError(t, store.Update(ctx, ep.ID, SetRunnerLabels(runner.Labels{"windows", "needs-vpn", "windows"})))
+	retrieved, err := store.GetByID(ctx, ep.ID)
+	require.NoError(t, err)
+	assert.Equal(t, runner.Labels{"needs-vpn", "windows"}, retrieved.RunnerLabels)
+
+	err = store.Update(ctx, ep.ID, SetRunnerLabels(runner.Labels{"Needs VPN"}))
+	assert.ErrorIs(t, err, runner.ErrInvalidLabel)
+}
+
+func TestMySQLStore_SetHealthStatus(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("records status and check time", func(t *testing.T) {
+		ep := createTestEndpoint("Test", "https://example.com", uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, ep))
+
+		checkedAt := time.Now().Truncate(time.Second)
+		require.NoError(t, store.SetHealthStatus(ctx, ep.ID, HealthStatusDown, checkedAt))
+
+		retrieved, err := store.GetByID(ctx, ep.ID)
+		require.NoError(t, err)
+		assert.Equal(t, HealthStatusDown, retrieved.HealthStatus)
+		require.NotNil(t, retrieved.LastHealthCheckAt)
+		assert.True(t, checkedAt.Equal(*retrieved.LastHealthCheckAt))
+	})
+
+	t.Run("non-existent returns error", func(t *testing.T) {
+		err := store.SetHealthStatus(ctx, uuid.New(), HealthStatusUp, time.Now())
+		assert.ErrorIs(t, err, ErrEndpointNotFound)
+	})
+}
+
+func TestMySQLStore_Groups(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	owner := uuid.New()
+
+	newGrouped := func(name, group, environment string) *Endpoint {
+		ep := createTestEndpoint(name, "https://"+environment+".example.com", owner, nil)
+		ep.Group = group
+		ep.Environment = environment
+		return ep
+	}
+
+	staging := newGrouped("Shop staging", "shop", "staging")
+	require.NoError(t, store.Create(ctx, staging))
+	prod := newGrouped("Shop prod", "shop", "prod")
+	require.NoError(t, store.Create(ctx, prod))
+	require.NoError(t, store.Create(ctx, newGrouped("Blog dev", "blog", "dev")))
+	require.NoError(t, store.Create(ctx, createTestEndpoint("Ungrouped", "https://example.com", owner, nil)))
+
+	t.Run("get by group and environment", func(t *testing.T) {
+		ep, err := store.GetByGroupEnvironment(ctx, owner, "shop", "staging")
+		require.NoError(t, err)
+		assert.Equal(t, staging.ID, ep.ID)
+
+		_, err = store.GetByGroupEnvironment(ctx, owner, "shop", "dev")
+		assert.ErrorIs(t, err, ErrEndpointNotFound)
+
+		_, err = store.GetByGroupEnvironment(ctx, uuid.New(), "shop", "staging")
+		assert.ErrorIs(t, err, ErrEndpointNotFound)
+	})
+
+	t.Run("list grouped endpoints", func(t *testing.T) {
+		endpoints, err := store.ListGrouped(ctx, owner)
+		require.NoError(t, err)
+		require.Len(t, endpoints, 3)
+		assert.Equal(t, "blog", endpoints[0].Group)
+		assert.Equal(t, "prod", endpoints[1].Environment)
+		assert.Equal(t, "staging", endpoints[2].Environment)
+	})
+
+	t.Run("duplicate environment in group returns error", func(t *testing.T) {
+		err := store.Create(ctx, newGrouped("Another staging", "shop", "staging"))
+		assert.ErrorIs(t, err, ErrDuplicateEnvironment)
+
+		err = store.Update(ctx, prod.ID, SetEnvironment("staging"))
+		assert.ErrorIs(t, err, ErrDuplicateEnvironment)
+	})
+
+	t.Run("same environment in another user's group is allowed", func(t *testing.T) {
+		ep := newGrouped("Other shop", "shop", "staging")
+		ep.CreatedBy = uuid.New()
+		assert.NoError(t, store.Create(ctx, ep))
+	})
+
+	t.Run("group requires environment", func(t *testing.T) {
+		err := store.Create(ctx, newGrouped("No env", "shop", ""))
+		assert.ErrorIs(t, err, ErrEnvironmentRequired)
+
+		err = store.Update(ctx, prod.ID, SetEnvironment(""))
+		assert.ErrorIs(t, err, ErrEnvironmentRequired)
+	})
+
+	t.Run("invalid environment returns error", func(t *testing.T) {
+		err := store.Create(ctx, newGrouped("Bad env", "shop", "Prod Env"))
+		assert.ErrorIs(t, err, ErrInvalidEnvironment)
+
+		err = store.Update(ctx, prod.ID, SetEnvironment("UAT"))
+		assert.ErrorIs(t, err, ErrInvalidEnvironment)
+	})
+
+	t.Run("leaving a group", func(t *testing.T) {
+		err := store.Update(ctx, prod.ID, SetGroup(""), SetEnvironment(""))
+		require.NoError(t, err)
+
+		_, err = store.GetByGroupEnvironment(ctx, owner, "shop", "prod")
+		assert.ErrorIs(t, err, ErrEndpointNotFound)
+	})
+}
@@ -0,0 +1,78 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxSecretValueLength is the longest secret value that can be stored.
+const MaxSecretValueLength = 4096
+
+var (
+	ErrSecretNotFound      = errors.New("secret not found")
+	ErrInvalidSecretKey    = errors.New("secret key must be 1-100 characters of A-Z, 0-9 and _, starting with a letter")
+	ErrInvalidSecretValue  = fmt.Errorf("secret value must be between 1 and %d characters", MaxSecretValueLength)
+	ErrSecretDecryptFailed = errors.New("failed to decrypt endpoint secret")
+)
+
+// secretKeyPattern restricts keys to names usable as environment variables.
+var secretKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,99}$`)
+
+// Secret is an encrypted key/value pair attached to an endpoint, such as a
+// test user's password or an API key. The value is never serialized; it is
+// only decrypted when injected into an agent job.
+type Secret struct {
+	ID             uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	EndpointID     uuid.UUID `json:"endpoint_id" gorm:"type:char(36);not null;uniqueIndex:idx_endpoint_secrets_key"`
+	Key            string    `json:"key" gorm:"type:varchar(100);not null;uniqueIndex:idx_endpoint_secrets_key"`
+	EncryptedValue []byte    `json:"-" gorm:"type:blob;not null"`
+	Placeholder    string    `json:"placeholder" gorm:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Secret.
+func (Secret) TableName() string {
+	return "endpoint_secrets"
+}
+
+// BeforeCreate hook to generate UUID before creating a new secret.
+func (s *Secret) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind fills in the placeholder of a loaded secret.
+func (s *Secret) AfterFind(tx *gorm.DB) error {
+	s.Placeholder = Placeholder(s.Key)
+	return nil
+}
+
+// Placeholder returns the token that stands in for a secret in test
+// procedure steps and generated scripts.
+func Placeholder(key string) string {
+	return "{{" + key + "}}"
+}
+
+// ValidateSecretKey checks that key can be used as an environment variable name.
+func ValidateSecretKey(key string) error {
+	if !secretKeyPattern.MatchString(key) {
+		return ErrInvalidSecretKey
+	}
+	return nil
+}
+
+// ValidateSecretValue checks the length of a secret value.
+func ValidateSecretValue(value string) error {
+	if value == "" || len(value) > MaxSecretValueLength {
+		return ErrInvalidSecretValue
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLSecretStore implements the SecretStore interface using GORM and MySQL.
+// Values are encrypted with AES-256-GCM, the same scheme used for
+// integration credentials.
+type MySQLSecretStore struct {
+	db      *gorm.DB
+	keyring *integration.Keyring
+	logger  logger.Logger
+}
+
+// NewMySQLSecretStore creates a new MySQL-backed secret store that encrypts
+// values with the keys in keyring.
+func NewMySQLSecretStore(db *gorm.DB, keyring *integration.Keyring, log logger.Logger) *MySQLSecretStore {
+	return &MySQLSecretStore{
+		db:      db,
+		keyring: keyring,
+		logger:  log,
+	}
+}
+
+// Set creates or replaces the secret with the given key on an endpoint.
+func (s *MySQLSecretStore) Set(ctx context.Context, endpointID uuid.UUID, key, value string) (*Secret, error) {
+	if err := ValidateSecretKey(key); err != nil {
+		return nil, err
+	}
+	if err := ValidateSecretValue(value); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := s.keyring.Encrypt(map[string]string{key: value})
+	if err != nil {
+		return nil, err
+	}
+
+	var secret Secret
+	err = s.db.WithContext(ctx).
+		Where("endpoint_id = ? AND `key` = ?", endpointID, key).
+		First(&secret).Error
+
+	switch {
+	case err == nil:
+		secret.EncryptedValue = encrypted
+		err = s.db.WithContext(ctx).Save(&secret).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		secret = Secret{EndpointID: endpointID, Key: key, EncryptedValue: encrypted}
+		err = s.db.WithContext(ctx).Create(&secret).Error
+	}
+	if err != nil {
+		s.logger.Error(ctx, "failed to set endpoint secret", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": endpointID.String(),
+			"key":         key,
+		})
+		return nil, err
+	}
+
+	secret.Placeholder = Placeholder(key)
+	return &secret, nil
+}
+
+// List retrieves the secrets of an endpoint ordered by key, without values.
+func (s *MySQLSecretStore) List(ctx context.Context, endpointID uuid.UUID) ([]*Secret, error) {
+	var secrets []*Secret
+	err := s.db.WithContext(ctx).
+		Omit("encrypted_value").
+		Where("endpoint_id = ?", endpointID).
+		Order("`key` ASC").
+		Find(&secrets).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list endpoint secrets", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": endpointID.String(),
+		})
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// Delete removes a secret from an endpoint.
+func (s *MySQLSecretStore) Delete(ctx context.Context, endpointID uuid.UUID, key string) error {
+	result := s.db.WithContext(ctx).
+		Where("endpoint_id = ? AND `key` = ?", endpointID, key).
+		Delete(&Secret{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete endpoint secret", map[string]interface{}{
+			"error":       result.Error.Error(),
+			"endpoint_id": endpointID.String(),
+			"key":         key,
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrSecretNotFound
+	}
+
+	return nil
+}
+
+// Values decrypts every secret of an endpoint into a key to value map.
+func (s *MySQLSecretStore) Values(ctx context.Context, endpointID uuid.UUID) (map[string]string, error) {
+	var secrets []*Secret
+	err := s.db.WithContext(ctx).
+		Where("endpoint_id = ?", endpointID).
+		Find(&secrets).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to load endpoint secrets", map[string]interface{}{
+			"error":       err.Error(),
+			"endpoint_id": endpointID.String(),
+		})
+		return nil, err
+	}
+
+	values := make(map[string]string, len(secrets))
+	for _, secret := range secrets {
+		decrypted, err := s.keyring.Decrypt(secret.EncryptedValue)
+		if err != nil {
+			s.logger.Error(ctx, "failed to decrypt endpoint secret", map[string]interface{}{
+				"error":       err.Error(),
+				"endpoint_id": endpointID.String(),
+				"key":         secret.Key,
+			})
+			return nil, fmt.Errorf("%w: %s", ErrSecretDecryptFailed, secret.Key)
+		}
+		values[secret.Key] = decrypted[secret.Key]
+	}
+
+	return values, nil
+}
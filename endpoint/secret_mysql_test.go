@@ -0,0 +1,164 @@
+package endpoint
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLSecretStore_Set(t *testing.T) {
+	store, secretStore := setupTestSecretStore(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Secrets", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+
+	t.Run("successfully set secret", func(t *testing.T) {
+		secret, err := secretStore.Set(ctx, ep.ID, "TEST_PASSWORD", "hunter2")
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, secret.ID)
+		assert.Equal(t, "TEST_PASSWORD", secret.Key)
+		assert.Equal(t, "{{TEST_PASSWORD}}", secret.Placeholder)
+		assert.NotContains(t, string(secret.EncryptedValue), "hunter2")
+	})
+
+	t.Run("setting existing key replaces value", func(t *testing.T) {
+		first, err := secretStore.Set(ctx, ep.ID, "API_KEY", "old")
+		require.NoError(t, err)
+		second, err := secretStore.Set(ctx, ep.ID, "API_KEY", "new")
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, second.ID)
+
+		values, err := secretStore.Values(ctx, ep.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "new", values["API_KEY"])
+	})
+
+	t.Run("invalid keys return error", func(t *testing.T) {
+		for _, key := range []string{"", "lower", "1STARTS_WITH_DIGIT", "HAS-DASH", strings.Repeat("A", 101)} {
+			_, err := secretStore.Set(ctx, ep.ID, key, "value")
+			assert.ErrorIs(t, err, ErrInvalidSecretKey, key)
+		}
+	})
+
+	t.Run("invalid values return error", func(t *testing.T) {
+		_, err := secretStore.Set(ctx, ep.ID, "EMPTY", "")
+		assert.ErrorIs(t, err, ErrInvalidSecretValue)
+
+		_, err = secretStore.Set(ctx, ep.ID, "TOO_LONG", strings.Repeat("x", MaxSecretValueLength+1))
+		assert.ErrorIs(t, err, ErrInvalidSecretValue)
+	})
+}
+
+func TestMySQLSecretStore_List(t *testing.T) {
+	store, secretStore := setupTestSecretStore(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Secrets", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+	other := createTestEndpoint("Other", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, other))
+
+	_, err := secretStore.Set(ctx, ep.ID, "ZETA", "z")
+	require.NoError(t, err)
+	_, err = secretStore.Set(ctx, ep.ID, "ALPHA", "a")
+	require.NoError(t, err)
+	_, err = secretStore.Set(ctx, other.ID, "OTHER", "o")
+	require.NoError(t, err)
+
+	secrets, err := secretStore.List(ctx, ep.ID)
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	assert.Equal(t, "ALPHA", secrets[0].Key)
+	assert.Equal(t, "{{ALPHA}}", secrets[0].Placeholder)
+	assert.Empty(t, secrets[0].EncryptedValue)
+	assert.Equal(t, "ZETA", secrets[1].Key)
+}
+
+func TestMySQLSecretStore_Delete(t *testing.T) {
+	store, secretStore := setupTestSecretStore(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Secrets", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+
+	_, err := secretStore.Set(ctx, ep.ID, "TOKEN", "value")
+	require.NoError(t, err)
+
+	require.NoError(t, secretStore.Delete(ctx, ep.ID, "TOKEN"))
+
+	secrets, err := secretStore.List(ctx, ep.ID)
+	require.NoError(t, err)
+	assert.Empty(t, secrets)
+
+	t.Run("non-existent returns error", func(t *testing.T) {
+		err := secretStore.Delete(ctx, ep.ID, "TOKEN")
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+}
+
+func TestMySQLSecretStore_Values(t *testing.T) {
+	store, secretStore := setupTestSecretStore(t)
+	ctx := context.Background()
+
+	ep := createTestEndpoint("Secrets", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+
+	_, err := secretStore.Set(ctx, ep.ID, "USERNAME", "tester")
+	require.NoError(t, err)
+	_, err = secretStore.Set(ctx, ep.ID, "PASSWORD", "p@ss word")
+	require.NoError(t, err)
+
+	values, err := secretStore.Values(ctx, ep.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"USERNAME": "tester", "PASSWORD": "p@ss word"}, values)
+
+	t.Run("wrong key fails to decrypt", func(t *testing.T) {
+		other := NewMySQLSecretStore(secretStore.(*MySQLSecretStore).db, integration.NewKeyring([]byte("0123456789abcdef0123456789abcdef")), secretStore.(*MySQLSecretStore).logger)
+		_, err := other.Values(ctx, ep.ID)
+		assert.ErrorIs(t, err, ErrSecretDecryptFailed)
+	})
+
+	t.Run("endpoint without secrets", func(t *testing.T) {
+		values, err := secretStore.Values(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+}
+
+func TestRekeySecrets(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Endpoint{}, &Secret{})
+	log := logger.NewTestLogger()
+	ctx := context.Background()
+	oldKey := integration.DeriveKey("old-passphrase")
+	newKey := integration.DeriveKey("new-passphrase")
+
+	store := NewMySQLStore(db, log)
+	ep := createTestEndpoint("Secrets", "https://example.com", uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, ep))
+	_, err := NewMySQLSecretStore(db, integration.NewKeyring(oldKey), log).Set(ctx, ep.ID, "TEST_PASSWORD", "hunter2")
+	require.NoError(t, err)
+
+	count, err := RekeySecrets(ctx, db, oldKey, newKey)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	values, err := NewMySQLSecretStore(db, integration.NewKeyring(newKey), log).Values(ctx, ep.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", values["TEST_PASSWORD"])
+
+	count, err = RekeySecrets(ctx, db, oldKey, newKey)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	_, err = RekeySecrets(ctx, db, integration.DeriveKey("wrong"), integration.DeriveKey("other"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"gorm.io/gorm"
+)
+
+// RekeySecrets re-encrypts every endpoint secret from oldKey to newKey and
+// returns how many were re-encrypted. Secrets already encrypted with newKey
+// are left alone. It stops at the first value that decrypts with neither
+// key; run it in a transaction so that nothing is left half rotated.
+func RekeySecrets(ctx context.Context, db *gorm.DB, oldKey, newKey []byte) (int, error) {
+	var secrets []Secret
+	if err := db.WithContext(ctx).Select("id", "encrypted_value").Find(&secrets).Error; err != nil {
+		return 0, fmt.Errorf("failed to list endpoint secrets: %w", err)
+	}
+
+	count := 0
+	for _, secret := range secrets {
+		encrypted, changed, err := integration.Reencrypt(oldKey, newKey, secret.EncryptedValue)
+		if err != nil {
+			return 0, fmt.Errorf("endpoint secret %s: %w", secret.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		err = db.WithContext(ctx).Model(&Secret{}).
+			Where("id = ?", secret.ID).
+			UpdateColumn("encrypted_value", encrypted).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to update endpoint secret %s: %w", secret.ID, err)
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,23 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// SecretStore defines the interface for endpoint secret persistence.
+// Implementations encrypt values at rest.
+type SecretStore interface {
+	// Set creates or replaces the secret with the given key on an endpoint.
+	Set(ctx context.Context, endpointID uuid.UUID, key, value string) (*Secret, error)
+
+	// List retrieves the secrets of an endpoint ordered by key, without values.
+	List(ctx context.Context, endpointID uuid.UUID) ([]*Secret, error)
+
+	// Delete removes a secret from an endpoint.
+	Delete(ctx context.Context, endpointID uuid.UUID, key string) error
+
+	// Values decrypts every secret of an endpoint into a key to value map.
+	Values(ctx context.Context, endpointID uuid.UUID) (map[string]string, error)
+}
@@ -1,5 +1,7 @@
 package endpoint
 
+import "github.com/hairizuanbinnoorazman/ui-automation/runner"
+
 // SetName returns an UpdateSetter that sets the endpoint's name.
 func SetName(name string) UpdateSetter {
 	return func(e *Endpoint) error {
@@ -29,3 +31,96 @@ func SetCredentials(creds Credentials) UpdateSetter {
 		return nil
 	}
 }
+
+// SetMaxConcurrentJobs returns an UpdateSetter that sets the endpoint's concurrency limit.
+func SetMaxConcurrentJobs(max int) UpdateSetter {
+	return func(e *Endpoint) error {
+		if max < 1 {
+			return ErrInvalidMaxJobs
+		}
+		e.MaxConcurrentJobs = max
+		return nil
+	}
+}
+
+// SetHealthCheckEnabled returns an UpdateSetter that turns periodic health checks on or off.
+func SetHealthCheckEnabled(enabled bool) UpdateSetter {
+	return func(e *Endpoint) error {
+		e.HealthCheckEnabled = enabled
+		return nil
+	}
+}
+
+// SetHealthCheckInterval returns an UpdateSetter that sets the seconds between health checks.
+func SetHealthCheckInterval(seconds int) UpdateSetter {
+	return func(e *Endpoint) error {
+		if !validHealthCheckInterval(seconds) {
+			return ErrInvalidHealthCheckInterval
+		}
+		e.HealthCheckInterval = seconds
+		return nil
+	}
+}
+
+// SetExpectedStatus returns an UpdateSetter that sets the status code a healthy endpoint returns.
+func SetExpectedStatus(code int) UpdateSetter {
+	return func(e *Endpoint) error {
+		if !validExpectedStatus(code) {
+			return ErrInvalidExpectedStatus
+		}
+		e.ExpectedStatus = code
+		return nil
+	}
+}
+
+// SetExpectedText returns an UpdateSetter that sets the text a healthy response must contain.
+// An empty string disables the body check.
+func SetExpectedText(text string) UpdateSetter {
+	return func(e *Endpoint) error {
+		e.ExpectedText = text
+		return nil
+	}
+}
+
+// SetGroup returns an UpdateSetter that sets the endpoint group.
+// An empty string removes the endpoint from its group.
+func SetGroup(group string) UpdateSetter {
+	return func(e *Endpoint) error {
+		if len(group) > MaxGroupLength {
+			return ErrInvalidGroup
+		}
+		e.Group = group
+		return nil
+	}
+}
+
+// SetEnvironment returns an UpdateSetter that sets the endpoint environment.
+func SetEnvironment(environment string) UpdateSetter {
+	return func(e *Endpoint) error {
+		if environment != "" && !environmentPattern.MatchString(environment) {
+			return ErrInvalidEnvironment
+		}
+		e.Environment = environment
+		return nil
+	}
+}
+
+// SetUseRunner returns an UpdateSetter that sets whether the endpoint's jobs run on agent runners.
+func SetUseRunner(useRunner bool) UpdateSetter {
+	return func(e *Endpoint) error {
+		e.UseRunner = useRunner
+		return nil
+	}
+}
+
+// SetRunnerLabels returns an UpdateSetter that sets the labels a runner must
+// have to run the endpoint's jobs.
+func SetRunnerLabels(labels runner.Labels) UpdateSetter {
+	return func(e *Endpoint) error {
+		if err := labels.Validate(); err != nil {
+			return err
+		}
+		e.RunnerLabels = labels.Normalize()
+		return nil
+	}
+}
@@ -2,6 +2,7 @@ package endpoint
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -25,6 +26,22 @@ type Store interface {
 
 	// CountByCreator returns the total count of endpoints for a specific creator.
 	CountByCreator(ctx context.Context, createdBy uuid.UUID) (int, error)
+
+	// GetByGroupEnvironment retrieves the endpoint of a creator's group for an environment.
+	GetByGroupEnvironment(ctx context.Context, createdBy uuid.UUID, group, environment string) (*Endpoint, error)
+
+	// ListGrouped retrieves a creator's endpoints that belong to a group,
+	// ordered by group and environment.
+	ListGrouped(ctx context.Context, createdBy uuid.UUID) ([]*Endpoint, error)
+
+	// ListHealthCheckEnabled retrieves all endpoints with periodic health checks enabled.
+	ListHealthCheckEnabled(ctx context.Context) ([]*Endpoint, error)
+
+	// ListUsingRunner retrieves all endpoints whose jobs run on agent runners.
+	ListUsingRunner(ctx context.Context) ([]*Endpoint, error)
+
+	// SetHealthStatus records the outcome of the latest health check of an endpoint.
+	SetHealthStatus(ctx context.Context, id uuid.UUID, status HealthStatus, checkedAt time.Time) error
 }
 
 // UpdateSetter is a function that updates an endpoint field.
@@ -0,0 +1,163 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnv wires a runner to in-memory stores, local blob storage and a fake
+// executor.
+type testEnv struct {
+	runner         *Runner
+	executor       *fakeExecutor
+	jobStore       job.Store
+	procedureStore testprocedure.Store
+	testRunStore   testrun.Store
+	stepNoteStore  testrun.StepNoteStore
+	assetStore     testrun.AssetStore
+	storage        storage.BlobStorage
+	endpoint       *endpoint.Endpoint
+	procedure      *testprocedure.TestProcedure
+	projectID      uuid.UUID
+	userID         uuid.UUID
+}
+
+// setupTestEnv creates the stores, an endpoint, a committed procedure with
+// three steps and the runner under test.
+func setupTestEnv(t *testing.T) *testEnv {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db,
+		&job.Job{},
+		&job.LogEntry{},
+		&endpoint.Endpoint{},
+		&endpoint.Secret{},
+		&testprocedure.TestProcedure{},
+		&testprocedure.DraftSnapshot{},
+		&testrun.TestRun{},
+		&testrun.TestRunAsset{},
+		&testrun.StepNote{},
+	)
+
+	log := logger.NewTestLogger()
+	ctx := context.Background()
+	blobStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	projectID := uuid.New()
+	endpointStore := endpoint.NewMySQLStore(db, log)
+	ep := &endpoint.Endpoint{Name: "Staging", URL: "https://staging.example.com/", CreatedBy: userID}
+	require.NoError(t, endpointStore.Create(ctx, ep))
+
+	procedureStore := testprocedure.NewMySQLStore(db, log)
+	proc, err := procedureStore.CreateWithDraft(ctx, &testprocedure.TestProcedure{
+		ProjectID: projectID,
+		Name:      "Checkout",
+		Steps: testprocedure.Steps{
+			{Name: "Open cart", Instructions: "Open the cart page"},
+			{Name: "Pay", Instructions: "Pay with the test card"},
+			{Name: "Confirm", Instructions: "Check the confirmation page"},
+		},
+		CreatedBy: userID,
+	})
+	require.NoError(t, err)
+
+	jobStore := job.NewMySQLStore(db, log)
+	logStore := job.NewMySQLLogStore(db, log)
+	testRunStore := testrun.NewMySQLStore(db, log)
+	stepNoteStore := testrun.NewMySQLStepNoteStore(db, log)
+	assetStore := testrun.NewMySQLAssetStore(db, log)
+	executor := &fakeExecutor{}
+
+	return &testEnv{
+		runner: NewRunner(
+			procedureStore,
+			endpointStore,
+			endpoint.NewMySQLSecretStore(db, integration.NewKeyring(integration.DeriveKey("test-encryption-key")), log),
+			jobStore,
+			logStore,
+			testRunStore,
+			stepNoteStore,
+			assetStore,
+			blobStorage,
+			executor,
+			nil,
+			nil,
+			nil,
+			log,
+		),
+		executor:       executor,
+		jobStore:       jobStore,
+		procedureStore: procedureStore,
+		testRunStore:   testRunStore,
+		stepNoteStore:  stepNoteStore,
+		assetStore:     assetStore,
+		storage:        blobStorage,
+		endpoint:       ep,
+		procedure:      proc,
+		projectID:      projectID,
+		userID:         userID,
+	}
+}
+
+// createJob creates a procedure_execution job of procedureID in projectID.
+func (e *testEnv) createJob(t *testing.T, procedureID, projectID uuid.UUID) *job.Job {
+	t.Helper()
+
+	j := &job.Job{
+		Type: job.JobTypeProcedureExecution,
+		Config: job.JSONMap{
+			"procedure_id": procedureID.String(),
+			"project_id":   projectID.String(),
+			"endpoint_id":  e.endpoint.ID.String(),
+		},
+		CreatedBy: e.userID,
+	}
+	require.NoError(t, e.jobStore.Create(context.Background(), j))
+	return j
+}
+
+// fakeExecutor reports preconfigured step outcomes instead of driving a
+// browser, writing a screenshot for every outcome that names one.
+type fakeExecutor struct {
+	outcome  *Outcome
+	err      error
+	requests []Request
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, req Request, stderr io.Writer) (*Outcome, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	for i, step := range f.outcome.Steps {
+		fmt.Fprintf(stderr, "[progress] %d/%d steps\n", i+1, len(req.Steps))
+		if step.Screenshot == "" {
+			continue
+		}
+		path := filepath.Join(req.OutputDir, step.Screenshot)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte("png"), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return f.outcome, nil
+}
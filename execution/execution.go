@@ -0,0 +1,107 @@
+// Package execution runs committed test procedures automatically: the
+// procedure execution agent follows each step's instructions in a browser
+// and the outcome is recorded as a test run with per-step results and
+// screenshots.
+package execution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Keys under which execution data is stored in a job result.
+const (
+	ResultKeyTestRunID = "test_run_id"
+	ResultKeySteps     = "steps"
+	ResultKeyPassed    = "passed"
+	ResultKeySummary   = "summary"
+)
+
+var (
+	// ErrInvalidConfig is returned when a procedure_execution job config is malformed.
+	ErrInvalidConfig = errors.New("invalid procedure_execution config")
+
+	// ErrProcedureRequired is returned when the config names no procedure.
+	ErrProcedureRequired = errors.New("procedure_id is required in config for procedure_execution jobs")
+
+	// ErrProcedureNotInProject is returned when the procedure belongs to
+	// another project than the job.
+	ErrProcedureNotInProject = errors.New("procedure does not belong to the job's project")
+
+	// ErrNoSteps is returned when the procedure has no steps to execute.
+	ErrNoSteps = errors.New("procedure has no steps to execute")
+)
+
+// Config is the configuration of a procedure_execution job. The latest
+// committed version of the procedure is executed, with Parameters filled
+// into its placeholders.
+type Config struct {
+	ProcedureID uuid.UUID         `json:"procedure_id"`
+	ProjectID   uuid.UUID         `json:"project_id"`
+	EndpointID  uuid.UUID         `json:"endpoint_id"`
+	Parameters  map[string]string `json:"parameters,omitempty"`
+
+	// AcknowledgePreconditions confirms, on behalf of the job's creator,
+	// that the procedure's preconditions are in place. Jobs of procedures
+	// with preconditions fail without it.
+	AcknowledgePreconditions bool `json:"acknowledge_preconditions,omitempty"`
+}
+
+// ParseConfig decodes and validates a procedure_execution job config.
+func ParseConfig(raw job.JSONMap) (*Config, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if cfg.ProcedureID == uuid.Nil {
+		return nil, ErrProcedureRequired
+	}
+
+	return &cfg, nil
+}
+
+// StepResult is the outcome of one step of an executed procedure.
+type StepResult struct {
+	Index             int                `json:"index"`
+	Name              string             `json:"name"`
+	Status            testrun.StepStatus `json:"status"`
+	Notes             string             `json:"notes,omitempty"`
+	ScreenshotAssetID *uuid.UUID         `json:"screenshot_asset_id,omitempty"`
+}
+
+// Summary counts the outcomes of the steps of an executed procedure.
+type Summary struct {
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// StepsFromResult decodes the step results stored in a procedure_execution
+// job result.
+func StepsFromResult(result job.JSONMap) ([]StepResult, error) {
+	raw, ok := result[ResultKeySteps]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode step results: %w", err)
+	}
+
+	var steps []StepResult
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("failed to decode step results: %w", err)
+	}
+	return steps, nil
+}
@@ -0,0 +1,54 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Run("parses ids", func(t *testing.T) {
+		procedureID, projectID, endpointID := uuid.New(), uuid.New(), uuid.New()
+		cfg, err := ParseConfig(job.JSONMap{
+			"procedure_id": procedureID.String(),
+			"project_id":   projectID.String(),
+			"endpoint_id":  endpointID.String(),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, procedureID, cfg.ProcedureID)
+		assert.Equal(t, projectID, cfg.ProjectID)
+		assert.Equal(t, endpointID, cfg.EndpointID)
+	})
+
+	t.Run("requires a procedure", func(t *testing.T) {
+		_, err := ParseConfig(job.JSONMap{"project_id": uuid.New().String()})
+		assert.ErrorIs(t, err, ErrProcedureRequired)
+	})
+
+	t.Run("rejects a malformed procedure id", func(t *testing.T) {
+		_, err := ParseConfig(job.JSONMap{"procedure_id": "not-a-uuid"})
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
+
+func TestStepsFromResult(t *testing.T) {
+	steps, err := StepsFromResult(job.JSONMap{})
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	steps, err = StepsFromResult(job.JSONMap{
+		ResultKeySteps: []interface{}{
+			map[string]interface{}{"index": 0, "name": "Open cart", "status": "passed"},
+			map[string]interface{}{"index": 1, "name": "Pay", "status": "failed", "notes": "Card declined"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, testrun.StepStatusPassed, steps[0].Status)
+	assert.Equal(t, "Card declined", steps[1].Notes)
+}
@@ -0,0 +1,97 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Step is a procedure step for the agent to perform.
+type Step struct {
+	Index          int    `json:"index"`
+	Name           string `json:"name"`
+	Instructions   string `json:"instructions"`
+	ExpectedResult string `json:"expected_result,omitempty"`
+}
+
+// Request is the JSON config sent to the procedure execution agent via
+// stdin. PlaywrightMCPURL is filled in by the executor.
+type Request struct {
+	TargetURL        string             `json:"target_url"`
+	Credentials      []agent.Credential `json:"credentials,omitempty"`
+	Secrets          []agent.Credential `json:"secrets,omitempty"`
+	ProcedureName    string             `json:"procedure_name"`
+	Preconditions    []string           `json:"preconditions,omitempty"`
+	Steps            []Step             `json:"steps"`
+	JobID            string             `json:"job_id"`
+	OutputDir        string             `json:"output_dir"`
+	PlaywrightMCPURL string             `json:"playwright_mcp_url"`
+}
+
+// StepOutcome is the agent's report of one step. Screenshot is the path of
+// the screenshot taken after the step, relative to the output directory.
+type StepOutcome struct {
+	Index      int                `json:"index"`
+	Status     testrun.StepStatus `json:"status"`
+	Notes      string             `json:"notes"`
+	Screenshot string             `json:"screenshot,omitempty"`
+}
+
+// Outcome is the result the agent writes to result.json, along with the
+// tokens and cost of its model calls.
+type Outcome struct {
+	Steps   []StepOutcome  `json:"steps"`
+	Summary string         `json:"summary"`
+	Usage   llmusage.Usage `json:"usage"`
+}
+
+// Executor performs the steps of a procedure in a browser.
+type Executor interface {
+	// Execute performs the steps of req, writing screenshots to
+	// req.OutputDir and its progress log to stderr. A failing step is
+	// reported in its StepOutcome; an error is returned only when the steps
+	// could not be executed at all.
+	Execute(ctx context.Context, req Request, stderr io.Writer) (*Outcome, error)
+}
+
+// ScriptExecutor executes procedures by running the procedure execution
+// agent script, which drives the browser of the Playwright MCP server.
+type ScriptExecutor struct {
+	process          agent.Process
+	playwrightMCPURL string
+}
+
+// NewScriptExecutor creates an executor that runs the agent script with
+// process against the Playwright MCP server at playwrightMCPURL.
+func NewScriptExecutor(process agent.Process, playwrightMCPURL string) *ScriptExecutor {
+	return &ScriptExecutor{
+		process:          process,
+		playwrightMCPURL: playwrightMCPURL,
+	}
+}
+
+// Execute runs the agent script and reads the result it writes.
+func (e *ScriptExecutor) Execute(ctx context.Context, req Request, stderr io.Writer) (*Outcome, error) {
+	req.PlaywrightMCPURL = e.playwrightMCPURL + "/sse"
+	if err := e.process.Run(ctx, req, stderr); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(req.OutputDir, "result.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution result: %w", err)
+	}
+
+	var outcome Outcome
+	if err := json.Unmarshal(data, &outcome); err != nil {
+		return nil, fmt.Errorf("failed to parse execution result: %w", err)
+	}
+	return &outcome, nil
+}
@@ -0,0 +1,353 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/agent"
+	"github.com/hairizuanbinnoorazman/ui-automation/analytics"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Runner executes procedure_execution jobs: it starts a test run of the
+// latest committed version of the procedure against the job's endpoint, has
+// the executor perform every step and records each step's outcome as a
+// step note with its screenshot as a run asset.
+type Runner struct {
+	procedureStore testprocedure.Store
+	endpointStore  endpoint.Store
+	secretStore    endpoint.SecretStore
+	jobStore       job.Store
+	logStore       job.LogStore
+	testRunStore   testrun.Store
+	stepNoteStore  testrun.StepNoteStore
+	assetStore     testrun.AssetStore
+	storage        storage.BlobStorage
+	executor       Executor
+	recorder       *metering.Recorder
+	meter          *llmusage.Meter
+	analytics      *analytics.Recorder
+	logger         logger.Logger
+}
+
+// NewRunner creates a new procedure execution job runner.
+func NewRunner(
+	procedureStore testprocedure.Store,
+	endpointStore endpoint.Store,
+	secretStore endpoint.SecretStore,
+	jobStore job.Store,
+	logStore job.LogStore,
+	testRunStore testrun.Store,
+	stepNoteStore testrun.StepNoteStore,
+	assetStore testrun.AssetStore,
+	blobStorage storage.BlobStorage,
+	executor Executor,
+	recorder *metering.Recorder,
+	meter *llmusage.Meter,
+	analyticsRecorder *analytics.Recorder,
+	log logger.Logger,
+) *Runner {
+	return &Runner{
+		procedureStore: procedureStore,
+		endpointStore:  endpointStore,
+		secretStore:    secretStore,
+		jobStore:       jobStore,
+		logStore:       logStore,
+		testRunStore:   testRunStore,
+		stepNoteStore:  stepNoteStore,
+		assetStore:     assetStore,
+		storage:        blobStorage,
+		executor:       executor,
+		recorder:       recorder,
+		meter:          meter,
+		analytics:      analyticsRecorder,
+		logger:         log,
+	}
+}
+
+// Run executes the procedure and returns the job result. Failing steps fail
+// the test run but not the job; the job fails only when the procedure could
+// not be executed at all, in which case the run is failed too.
+func (r *Runner) Run(ctx context.Context, j *job.Job) (job.JSONMap, error) {
+	cfg, err := ParseConfig(j.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	proc, err := r.procedureStore.GetLatestCommitted(ctx, cfg.ProcedureID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch procedure: %w", err)
+	}
+	if proc.ProjectID != cfg.ProjectID {
+		return nil, ErrProcedureNotInProject
+	}
+	if proc.IsArchived() {
+		return nil, testprocedure.ErrProcedureArchived
+	}
+	if len(proc.Steps) == 0 {
+		return nil, ErrNoSteps
+	}
+	if len(proc.Preconditions) > 0 && !cfg.AcknowledgePreconditions {
+		return nil, testrun.ErrPreconditionsNotAcknowledged
+	}
+
+	ep, err := r.endpointStore.GetByID(ctx, cfg.EndpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch endpoint: %w", err)
+	}
+	creds, secrets, err := agent.EndpointCredentials(ctx, r.secretStore, ep)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := proc.Parameters.ResolveWithBaseURL(cfg.Parameters, ep.URL)
+	if err != nil {
+		return nil, err
+	}
+	proc = proc.WithParameters(values)
+
+	tr, err := r.startRun(ctx, j, proc, ep, values)
+	if err != nil {
+		return nil, err
+	}
+	r.jobLog(ctx, j.ID, "Executing %q (version %d) against %s in test run %s", proc.Name, proc.Version, ep.URL, tr.ID)
+
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("procedure-execution-%s-", j.ID.String()))
+	if err != nil {
+		return nil, r.abortRun(ctx, tr.ID, fmt.Errorf("failed to create temp directory: %w", err))
+	}
+	defer os.RemoveAll(tmpDir)
+
+	preconditions := make([]string, len(proc.Preconditions))
+	for i, precondition := range proc.Preconditions {
+		preconditions[i] = precondition.String()
+	}
+	steps := make([]Step, len(proc.Steps))
+	for i, step := range proc.Steps {
+		steps[i] = Step{Index: i, Name: step.Name, Instructions: step.Instructions, ExpectedResult: step.ExpectedResult}
+	}
+
+	logWriter := job.NewLogWriter(ctx, r.logStore, j.ID, r.logger)
+	outcome, err := r.executor.Execute(ctx, Request{
+		TargetURL:     ep.URL,
+		Credentials:   creds,
+		Secrets:       secrets,
+		ProcedureName: proc.Name,
+		Preconditions: preconditions,
+		Steps:         steps,
+		JobID:         j.ID.String(),
+		OutputDir:     tmpDir,
+	}, io.MultiWriter(logWriter, agent.NewProgressWriter(ctx, r.jobStore, j.ID, r.logger)))
+	logWriter.Close()
+	if err != nil {
+		return nil, r.abortRun(ctx, tr.ID, err)
+	}
+	r.meter.Record(ctx, cfg.ProjectID, j.CreatedBy, llmusage.FeatureProcedureExecution, outcome.Usage)
+
+	results := r.recordSteps(ctx, j, cfg.ProjectID, tr.ID, proc, outcome, tmpDir)
+	passed, err := r.completeRun(ctx, tr.ID, proc, results, outcome.Summary)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary Summary
+	for _, result := range results {
+		switch result.Status {
+		case testrun.StepStatusPassed:
+			summary.Passed++
+		case testrun.StepStatusFailed:
+			summary.Failed++
+		default:
+			summary.Skipped++
+		}
+	}
+	r.jobLog(ctx, j.ID, "%d passed, %d failed, %d skipped", summary.Passed, summary.Failed, summary.Skipped)
+
+	return job.JSONMap{
+		ResultKeyTestRunID: tr.ID.String(),
+		"procedure_id":     proc.ID.String(),
+		"procedure_name":   proc.Name,
+		ResultKeyPassed:    passed,
+		ResultKeySteps:     results,
+		ResultKeySummary:   summary,
+	}, nil
+}
+
+// startRun creates and starts a test run of proc against ep, with the given
+// parameter values, on behalf of the job's creator, who has acknowledged any
+// preconditions.
+func (r *Runner) startRun(ctx context.Context, j *job.Job, proc *testprocedure.TestProcedure, ep *endpoint.Endpoint, values testprocedure.ParameterValues) (*testrun.TestRun, error) {
+	tr := &testrun.TestRun{
+		TestProcedureID: proc.ID,
+		ExecutedBy:      j.CreatedBy,
+		Status:          testrun.StatusPending,
+		EndpointID:      &ep.ID,
+		Environment:     ep.Environment,
+		BaseURL:         ep.URL,
+		Parameters:      values,
+	}
+	if err := r.testRunStore.Create(ctx, tr); err != nil {
+		return nil, fmt.Errorf("failed to create test run: %w", err)
+	}
+	if err := r.testRunStore.Start(ctx, tr.ID, testrun.NewProcedureSnapshot(proc), &j.CreatedBy); err != nil {
+		return nil, fmt.Errorf("failed to start test run: %w", err)
+	}
+	return tr, nil
+}
+
+// recordSteps saves the outcome of every step of proc as a step note with
+// the step's screenshot attached. Steps the agent did not report on are
+// recorded as skipped.
+func (r *Runner) recordSteps(ctx context.Context, j *job.Job, projectID, runID uuid.UUID, proc *testprocedure.TestProcedure, outcome *Outcome, dir string) []StepResult {
+	byIndex := make(map[int]StepOutcome, len(outcome.Steps))
+	for _, step := range outcome.Steps {
+		byIndex[step.Index] = step
+	}
+
+	results := make([]StepResult, len(proc.Steps))
+	for i, step := range proc.Steps {
+		reported, ok := byIndex[i]
+		if !ok {
+			reported = StepOutcome{Index: i, Status: testrun.StepStatusSkipped, Notes: "The agent did not report on this step."}
+		}
+		if !reported.Status.IsValid() {
+			reported.Status = testrun.StepStatusFailed
+		}
+		results[i] = StepResult{Index: i, Name: step.Name, Status: reported.Status, Notes: reported.Notes}
+
+		note := &testrun.StepNote{TestRunID: runID, StepIndex: i, Notes: reported.Notes, Status: reported.Status}
+		if err := r.stepNoteStore.Upsert(ctx, note); err != nil {
+			r.logger.Warn(ctx, "failed to record step result", map[string]interface{}{
+				"error":       err.Error(),
+				"job_id":      j.ID.String(),
+				"test_run_id": runID.String(),
+				"step_index":  i,
+			})
+			continue
+		}
+
+		if reported.Screenshot == "" {
+			continue
+		}
+		asset, err := r.uploadScreenshot(ctx, runID, note, filepath.Join(dir, filepath.Clean("/"+reported.Screenshot)))
+		if err != nil {
+			r.logger.Warn(ctx, "failed to save step screenshot", map[string]interface{}{
+				"error":       err.Error(),
+				"job_id":      j.ID.String(),
+				"test_run_id": runID.String(),
+				"step_index":  i,
+			})
+			continue
+		}
+		results[i].ScreenshotAssetID = &asset.ID
+		r.recorder.RecordStorage(ctx, projectID, j.CreatedBy, asset.FileSize)
+	}
+	return results
+}
+
+// uploadScreenshot stores the screenshot at path as an image asset of the
+// run, attached to the step's note.
+func (r *Runner) uploadScreenshot(ctx context.Context, runID uuid.UUID, note *testrun.StepNote, path string) (*testrun.TestRunAsset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("step-%d-%s", note.StepIndex+1, filepath.Base(path))
+	storagePath := fmt.Sprintf("test-runs/%s/%s/%s", runID, testrun.AssetTypeImage, filename)
+	if err := r.storage.Upload(ctx, storagePath, f); err != nil {
+		return nil, err
+	}
+
+	stepIndex := note.StepIndex
+	asset := &testrun.TestRunAsset{
+		TestRunID:   runID,
+		AssetType:   testrun.AssetTypeImage,
+		AssetPath:   storagePath,
+		FileName:    filename,
+		FileSize:    info.Size(),
+		MimeType:    "image/png",
+		Description: fmt.Sprintf("Screenshot after step %d", note.StepIndex+1),
+		StepIndex:   &stepIndex,
+		StepNoteID:  &note.ID,
+	}
+	if err := r.assetStore.Create(ctx, asset); err != nil {
+		r.storage.Delete(ctx, storagePath)
+		return nil, err
+	}
+	return asset, nil
+}
+
+// completeRun completes the run as passed when every step passed, and as
+// failed at its first failing step otherwise. It returns whether the run
+// passed.
+func (r *Runner) completeRun(ctx context.Context, runID uuid.UUID, proc *testprocedure.TestProcedure, results []StepResult, summary string) (bool, error) {
+	passed := true
+	var failedStep *int
+	for i := range results {
+		if results[i].Status == testrun.StepStatusPassed {
+			continue
+		}
+		passed = false
+		if results[i].Status == testrun.StepStatusFailed && failedStep == nil {
+			failedStep = &results[i].Index
+		}
+	}
+
+	status := testrun.StatusPassed
+	if !passed {
+		status = testrun.StatusFailed
+	}
+	if err := r.testRunStore.Complete(ctx, runID, status, summary); err != nil {
+		return false, fmt.Errorf("failed to complete test run: %w", err)
+	}
+	if failedStep != nil {
+		if err := r.testRunStore.Update(ctx, runID, testrun.SetFailedStepIndex(*failedStep)); err != nil {
+			return false, fmt.Errorf("failed to record failed step: %w", err)
+		}
+	}
+
+	if tr, err := r.testRunStore.GetByID(ctx, runID); err == nil {
+		r.analytics.RecordCompletion(ctx, tr, proc)
+	}
+	return passed, nil
+}
+
+// abortRun fails a run whose procedure could not be executed and returns
+// err. The run is failed even when the job was stopped or timed out.
+func (r *Runner) abortRun(ctx context.Context, runID uuid.UUID, err error) error {
+	ctx = context.WithoutCancel(ctx)
+	if cerr := r.testRunStore.Complete(ctx, runID, testrun.StatusFailed, "Automated execution failed: "+err.Error()); cerr != nil {
+		r.logger.Warn(ctx, "failed to fail test run", map[string]interface{}{
+			"error":       cerr.Error(),
+			"test_run_id": runID.String(),
+		})
+	}
+	return err
+}
+
+// jobLog appends a progress line to a job's log.
+func (r *Runner) jobLog(ctx context.Context, jobID uuid.UUID, format string, args ...interface{}) {
+	if _, err := r.logStore.Append(ctx, jobID, fmt.Sprintf(format, args...)); err != nil {
+		r.logger.Warn(ctx, "failed to append job log", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID.String(),
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_Run(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	env.executor.outcome = &Outcome{
+		Steps: []StepOutcome{
+			{Index: 0, Status: testrun.StepStatusPassed, Notes: "Cart shows one item", Screenshot: "screenshots/step-1.png"},
+			{Index: 1, Status: testrun.StepStatusFailed, Notes: "Card was declined", Screenshot: "screenshots/step-2.png"},
+		},
+		Summary: "Payment failed",
+	}
+	j := env.createJob(t, env.procedure.ID, env.projectID)
+
+	result, err := env.runner.Run(ctx, j)
+	require.NoError(t, err)
+	assert.Equal(t, false, result[ResultKeyPassed])
+
+	require.Len(t, env.executor.requests, 1)
+	req := env.executor.requests[0]
+	assert.Equal(t, "https://staging.example.com/", req.TargetURL)
+	require.Len(t, req.Steps, 3)
+	assert.Equal(t, "Pay with the test card", req.Steps[1].Instructions)
+
+	steps, err := StepsFromResult(result)
+	require.NoError(t, err)
+	require.Len(t, steps, 3)
+	assert.Equal(t, testrun.StepStatusPassed, steps[0].Status)
+	assert.NotNil(t, steps[0].ScreenshotAssetID)
+	assert.Equal(t, testrun.StepStatusFailed, steps[1].Status)
+	assert.Equal(t, testrun.StepStatusSkipped, steps[2].Status)
+	assert.Nil(t, steps[2].ScreenshotAssetID)
+	assert.Equal(t, Summary{Passed: 1, Failed: 1, Skipped: 1}, result[ResultKeySummary])
+
+	runID, err := uuid.Parse(result[ResultKeyTestRunID].(string))
+	require.NoError(t, err)
+	tr, err := env.testRunStore.GetByID(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, testrun.StatusFailed, tr.Status)
+	assert.Equal(t, "Payment failed", tr.Notes)
+	require.NotNil(t, tr.FailedStepIndex)
+	assert.Equal(t, 1, *tr.FailedStepIndex)
+	assert.Equal(t, env.userID, tr.ExecutedBy)
+	require.NotNil(t, tr.EndpointID)
+	assert.Equal(t, env.endpoint.ID, *tr.EndpointID)
+
+	notes, err := env.stepNoteStore.ListByTestRun(ctx, runID)
+	require.NoError(t, err)
+	require.Len(t, notes, 3)
+	assert.Equal(t, testrun.StepStatusFailed, notes[1].Status)
+	assert.Equal(t, "Card was declined", notes[1].Notes)
+
+	assets, err := env.assetStore.ListByTestRun(ctx, runID)
+	require.NoError(t, err)
+	require.Len(t, assets, 2)
+	for _, asset := range assets {
+		assert.Equal(t, testrun.AssetTypeImage, asset.AssetType)
+		require.NotNil(t, asset.StepNoteID)
+		exists, err := env.storage.Exists(ctx, asset.AssetPath)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	}
+}
+
+func TestRunner_Run_AllStepsPass(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	env.executor.outcome = &Outcome{
+		Steps: []StepOutcome{
+			{Index: 0, Status: testrun.StepStatusPassed},
+			{Index: 1, Status: testrun.StepStatusPassed},
+			{Index: 2, Status: testrun.StepStatusPassed},
+		},
+	}
+	j := env.createJob(t, env.procedure.ID, env.projectID)
+
+	result, err := env.runner.Run(ctx, j)
+	require.NoError(t, err)
+	assert.Equal(t, true, result[ResultKeyPassed])
+
+	runID, err := uuid.Parse(result[ResultKeyTestRunID].(string))
+	require.NoError(t, err)
+	tr, err := env.testRunStore.GetByID(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, testrun.StatusPassed, tr.Status)
+	assert.Nil(t, tr.FailedStepIndex)
+}
+
+func TestRunner_Run_ExecutorFails(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	env.executor.err = errors.New("browser crashed")
+	j := env.createJob(t, env.procedure.ID, env.projectID)
+
+	_, err := env.runner.Run(ctx, j)
+	require.Error(t, err)
+
+	runs, err := env.testRunStore.ListByTestProcedure(ctx, env.procedure.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, testrun.StatusFailed, runs[0].Status)
+	assert.Contains(t, runs[0].Notes, "browser crashed")
+}
+
+func TestRunner_Run_ProcedureNotInProject(t *testing.T) {
+	env := setupTestEnv(t)
+
+	j := env.createJob(t, env.procedure.ID, uuid.New())
+	_, err := env.runner.Run(context.Background(), j)
+	assert.ErrorIs(t, err, ErrProcedureNotInProject)
+	assert.Empty(t, env.executor.requests)
+}
+
+func TestRunner_Run_ProcedureArchived(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	require.NoError(t, env.procedureStore.Archive(ctx, env.procedure.ID))
+
+	j := env.createJob(t, env.procedure.ID, env.projectID)
+	_, err := env.runner.Run(ctx, j)
+	assert.ErrorIs(t, err, testprocedure.ErrProcedureArchived)
+	assert.Empty(t, env.executor.requests)
+}
+
+func TestRunner_Run_Preconditions(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	preconditions := testprocedure.Preconditions{{Kind: testprocedure.PreconditionTestData, Description: "A product in stock"}}
+	require.NoError(t, env.procedureStore.UpdateDraft(ctx, env.procedure.ID, testprocedure.SetPreconditions(preconditions)))
+	_, err := env.procedureStore.CommitDraft(ctx, env.procedure.ID, testprocedure.CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+
+	t.Run("jobs must acknowledge them", func(t *testing.T) {
+		j := env.createJob(t, env.procedure.ID, env.projectID)
+		_, err := env.runner.Run(ctx, j)
+		assert.ErrorIs(t, err, testrun.ErrPreconditionsNotAcknowledged)
+		assert.Empty(t, env.executor.requests)
+	})
+
+	t.Run("acknowledged by the job's creator", func(t *testing.T) {
+		env.executor.outcome = &Outcome{Steps: []StepOutcome{{Index: 0, Status: testrun.StepStatusPassed}}}
+		j := env.createJob(t, env.procedure.ID, env.projectID)
+		j.Config["acknowledge_preconditions"] = true
+
+		result, err := env.runner.Run(ctx, j)
+		require.NoError(t, err)
+		require.Len(t, env.executor.requests, 1)
+		assert.Equal(t, []string{"Test data: A product in stock"}, env.executor.requests[0].Preconditions)
+
+		runID, err := uuid.Parse(result[ResultKeyTestRunID].(string))
+		require.NoError(t, err)
+		tr, err := env.testRunStore.GetByID(ctx, runID)
+		require.NoError(t, err)
+		require.NotNil(t, tr.PreconditionsAcknowledgedBy)
+		assert.Equal(t, env.userID, *tr.PreconditionsAcknowledgedBy)
+	})
+}
@@ -0,0 +1,23 @@
+package exploration
+
+import (
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+)
+
+// setupTestConverter creates a test database with job and procedure stores
+// and a converter backed by them.
+func setupTestConverter(t *testing.T) (*Converter, job.Store, testprocedure.Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &job.Job{}, &testprocedure.TestProcedure{})
+
+	log := logger.NewTestLogger()
+	jobStore := job.NewMySQLStore(db, log)
+	procedureStore := testprocedure.NewMySQLStore(db, log)
+
+	return NewConverter(jobStore, procedureStore, log), jobStore, procedureStore
+}
@@ -0,0 +1,130 @@
+package exploration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// Converter turns the flows discovered by a ui_exploration job into draft
+// test procedures that are flagged for human review.
+type Converter struct {
+	jobStore           job.Store
+	testProcedureStore testprocedure.Store
+	logger             logger.Logger
+}
+
+// NewConverter creates a new exploration result converter.
+func NewConverter(jobStore job.Store, testProcedureStore testprocedure.Store, log logger.Logger) *Converter {
+	return &Converter{
+		jobStore:           jobStore,
+		testProcedureStore: testProcedureStore,
+		logger:             log,
+	}
+}
+
+// Convert creates one procedure per discovered flow under projectID and records
+// the created procedure IDs on the job so that it cannot be converted twice.
+// If any procedure fails to save, the ones already created are removed again.
+func (c *Converter) Convert(ctx context.Context, jobID, projectID, createdBy uuid.UUID) ([]*testprocedure.TestProcedure, error) {
+	j, err := c.jobStore.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if j.Type != job.JobTypeUIExploration || j.Status != job.StatusSuccess {
+		return nil, ErrJobNotConvertible
+	}
+	if _, ok := j.Result[ResultKeyConvertedProcedureIDs]; ok {
+		return nil, ErrAlreadyConverted
+	}
+
+	flows, err := FlowsFromResult(j.Result)
+	if err != nil {
+		return nil, err
+	}
+
+	procedures := make([]*testprocedure.TestProcedure, 0, len(flows))
+	for i, flow := range flows {
+		tp := procedureFromFlow(flow, i, projectID, createdBy)
+		if err := c.testProcedureStore.Create(ctx, tp); err != nil {
+			c.rollback(ctx, procedures)
+			return nil, fmt.Errorf("failed to create procedure for flow %d: %w", i+1, err)
+		}
+		procedures = append(procedures, tp)
+	}
+
+	ids := make([]string, len(procedures))
+	for i, tp := range procedures {
+		ids[i] = tp.ID.String()
+	}
+	result := job.JSONMap{}
+	for k, v := range j.Result {
+		result[k] = v
+	}
+	result[ResultKeyConvertedProcedureIDs] = ids
+
+	if err := c.jobStore.Update(ctx, jobID, job.SetResult(result)); err != nil {
+		c.rollback(ctx, procedures)
+		return nil, err
+	}
+
+	c.logger.Info(ctx, "exploration job converted to procedures", map[string]interface{}{
+		"job_id":          jobID.String(),
+		"project_id":      projectID.String(),
+		"procedure_count": len(procedures),
+	})
+
+	return procedures, nil
+}
+
+// rollback deletes procedures created during a failed conversion.
+func (c *Converter) rollback(ctx context.Context, procedures []*testprocedure.TestProcedure) {
+	for _, tp := range procedures {
+		if err := c.testProcedureStore.Delete(ctx, tp.ID); err != nil {
+			c.logger.Error(ctx, "failed to roll back converted procedure", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": tp.ID.String(),
+			})
+		}
+	}
+}
+
+// procedureFromFlow builds a review-flagged procedure from a discovered flow,
+// filling in a name and a placeholder step where the agent left them empty.
+func procedureFromFlow(flow Flow, index int, projectID, createdBy uuid.UUID) *testprocedure.TestProcedure {
+	name := flow.Name
+	if name == "" {
+		name = fmt.Sprintf("Discovered flow %d", index+1)
+	}
+
+	steps := make(testprocedure.Steps, 0, len(flow.Steps))
+	for i, step := range flow.Steps {
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("Step %d", i+1)
+		}
+		if step.ImagePaths == nil {
+			step.ImagePaths = []string{}
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		steps = append(steps, testprocedure.TestStep{
+			Name:         "Review flow",
+			Instructions: flow.Description,
+			ImagePaths:   []string{},
+		})
+	}
+
+	return &testprocedure.TestProcedure{
+		ProjectID:   projectID,
+		Name:        name,
+		Description: "Generated from UI exploration: " + flow.Description,
+		Steps:       steps,
+		CreatedBy:   createdBy,
+		NeedsReview: true,
+	}
+}
@@ -0,0 +1,137 @@
+package exploration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createFinishedJob creates a ui_exploration job that completed with result.
+func createFinishedJob(t *testing.T, store job.Store, status job.Status, result job.JSONMap) *job.Job {
+	t.Helper()
+	ctx := context.Background()
+
+	j := &job.Job{
+		Type:      job.JobTypeUIExploration,
+		CreatedBy: uuid.New(),
+	}
+	require.NoError(t, store.Create(ctx, j))
+	require.NoError(t, store.Start(ctx, j.ID))
+	require.NoError(t, store.Complete(ctx, j.ID, status, result))
+	return j
+}
+
+func exampleFlows() []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"name":        "Log in",
+			"description": "Sign in with valid credentials",
+			"steps": []interface{}{
+				map[string]interface{}{
+					"name":         "Open login page",
+					"instructions": "Navigate to /login",
+					"image_paths":  []interface{}{"test-procedures/p/01_login.png"},
+				},
+			},
+		},
+		map[string]interface{}{
+			"name":        "",
+			"description": "Browse the catalogue",
+		},
+	}
+}
+
+func TestFlowsFromResult(t *testing.T) {
+	t.Run("decodes flows", func(t *testing.T) {
+		flows, err := FlowsFromResult(job.JSONMap{ResultKeyFlows: exampleFlows()})
+		require.NoError(t, err)
+		require.Len(t, flows, 2)
+		assert.Equal(t, "Log in", flows[0].Name)
+		require.Len(t, flows[0].Steps, 1)
+		assert.Equal(t, []string{"test-procedures/p/01_login.png"}, flows[0].Steps[0].ImagePaths)
+	})
+
+	t.Run("missing flows", func(t *testing.T) {
+		_, err := FlowsFromResult(job.JSONMap{"procedure_id": "x"})
+		assert.ErrorIs(t, err, ErrNoFlows)
+	})
+
+	t.Run("empty flows", func(t *testing.T) {
+		_, err := FlowsFromResult(job.JSONMap{ResultKeyFlows: []interface{}{}})
+		assert.ErrorIs(t, err, ErrNoFlows)
+	})
+}
+
+func TestConverter_Convert(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates one review-flagged procedure per flow", func(t *testing.T) {
+		converter, jobStore, procedureStore := setupTestConverter(t)
+		j := createFinishedJob(t, jobStore, job.StatusSuccess, job.JSONMap{ResultKeyFlows: exampleFlows()})
+		projectID := uuid.New()
+		userID := uuid.New()
+
+		procedures, err := converter.Convert(ctx, j.ID, projectID, userID)
+		require.NoError(t, err)
+		require.Len(t, procedures, 2)
+
+		first, err := procedureStore.GetByID(ctx, procedures[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Log in", first.Name)
+		assert.Equal(t, projectID, first.ProjectID)
+		assert.Equal(t, userID, first.CreatedBy)
+		assert.True(t, first.NeedsReview)
+
+		draft, err := procedureStore.GetDraft(ctx, procedures[0].ID)
+		require.NoError(t, err)
+		assert.True(t, draft.NeedsReview)
+
+		second, err := procedureStore.GetByID(ctx, procedures[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Discovered flow 2", second.Name)
+		require.Len(t, second.Steps, 1)
+		assert.Equal(t, "Browse the catalogue", second.Steps[0].Instructions)
+
+		updated, err := jobStore.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Len(t, updated.Result[ResultKeyConvertedProcedureIDs], 2)
+	})
+
+	t.Run("cannot convert twice", func(t *testing.T) {
+		converter, jobStore, _ := setupTestConverter(t)
+		j := createFinishedJob(t, jobStore, job.StatusSuccess, job.JSONMap{ResultKeyFlows: exampleFlows()})
+
+		_, err := converter.Convert(ctx, j.ID, uuid.New(), uuid.New())
+		require.NoError(t, err)
+
+		_, err = converter.Convert(ctx, j.ID, uuid.New(), uuid.New())
+		assert.ErrorIs(t, err, ErrAlreadyConverted)
+	})
+
+	t.Run("failed job is not convertible", func(t *testing.T) {
+		converter, jobStore, _ := setupTestConverter(t)
+		j := createFinishedJob(t, jobStore, job.StatusFailed, job.JSONMap{"error": "boom"})
+
+		_, err := converter.Convert(ctx, j.ID, uuid.New(), uuid.New())
+		assert.ErrorIs(t, err, ErrJobNotConvertible)
+	})
+
+	t.Run("job without flows", func(t *testing.T) {
+		converter, jobStore, _ := setupTestConverter(t)
+		j := createFinishedJob(t, jobStore, job.StatusSuccess, job.JSONMap{"procedure_id": uuid.NewString()})
+
+		_, err := converter.Convert(ctx, j.ID, uuid.New(), uuid.New())
+		assert.ErrorIs(t, err, ErrNoFlows)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		converter, _, _ := setupTestConverter(t)
+
+		_, err := converter.Convert(ctx, uuid.New(), uuid.New(), uuid.New())
+		assert.ErrorIs(t, err, job.ErrJobNotFound)
+	})
+}
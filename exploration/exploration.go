@@ -0,0 +1,65 @@
+package exploration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// Keys under which exploration data is stored in a ui_exploration job result.
+const (
+	ResultKeyPages                 = "pages"
+	ResultKeyFlows                 = "flows"
+	ResultKeyConvertedProcedureIDs = "converted_procedure_ids"
+)
+
+var (
+	// ErrJobNotConvertible is returned when the job is not a successful ui_exploration job.
+	ErrJobNotConvertible = errors.New("only successful ui_exploration jobs can be converted")
+
+	// ErrNoFlows is returned when the job result contains no discovered flows.
+	ErrNoFlows = errors.New("job result has no discovered flows")
+
+	// ErrAlreadyConverted is returned when the job has already been converted.
+	ErrAlreadyConverted = errors.New("job has already been converted to procedures")
+)
+
+// Page is a page discovered during exploration.
+type Page struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Flow is a user flow discovered during exploration. Step image paths point
+// at screenshots that have already been uploaded to blob storage.
+type Flow struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Steps       testprocedure.Steps `json:"steps"`
+}
+
+// FlowsFromResult decodes the discovered flows stored in a job result.
+func FlowsFromResult(result job.JSONMap) ([]Flow, error) {
+	raw, ok := result[ResultKeyFlows]
+	if !ok || raw == nil {
+		return nil, ErrNoFlows
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode flows: %w", err)
+	}
+
+	var flows []Flow
+	if err := json.Unmarshal(data, &flows); err != nil {
+		return nil, fmt.Errorf("failed to decode flows: %w", err)
+	}
+	if len(flows) == 0 {
+		return nil, ErrNoFlows
+	}
+	return flows, nil
+}
@@ -5,6 +5,7 @@ import Http
 import Json.Decode as Decode
 import Json.Encode as Encode
 import Types exposing (..)
+import Url
 
 
 baseUrl : String
@@ -195,11 +196,17 @@ resetDraft procedureId toMsg =
         }
 
 
-commitDraft : String -> (Result Http.Error TestProcedure -> msg) -> Cmd msg
-commitDraft procedureId toMsg =
+commitDraft : String -> String -> String -> (Result Http.Error TestProcedure -> msg) -> Cmd msg
+commitDraft procedureId message summary toMsg =
     Http.post
         { url = baseUrl ++ "/procedures/" ++ procedureId ++ "/draft/commit"
-        , body = Http.emptyBody
+        , body =
+            Http.jsonBody
+                (Encode.object
+                    [ ( "message", Encode.string message )
+                    , ( "summary", Encode.string summary )
+                    ]
+                )
         , expect = Http.expectJson toMsg testProcedureDecoder
         }
 
@@ -570,11 +577,20 @@ testIntegrationConnection id toMsg =
         }
 
 
-searchExternalIssues : String -> String -> (Result Http.Error (List ExternalIssue) -> msg) -> Cmd msg
-searchExternalIssues integrationId query toMsg =
+searchExternalIssues : String -> String -> Int -> Maybe String -> (Result Http.Error ExternalIssuePage -> msg) -> Cmd msg
+searchExternalIssues integrationId query offset cursor toMsg =
+    let
+        pageParam =
+            case cursor of
+                Just c ->
+                    "&cursor=" ++ Url.percentEncode c
+
+                Nothing ->
+                    "&offset=" ++ String.fromInt offset
+    in
     Http.get
-        { url = baseUrl ++ "/integrations/" ++ integrationId ++ "/issues?query=" ++ query
-        , expect = Http.expectJson toMsg (Decode.field "items" (Decode.list externalIssueDecoder))
+        { url = baseUrl ++ "/integrations/" ++ integrationId ++ "/issues?query=" ++ Url.percentEncode query ++ pageParam
+        , expect = Http.expectJson toMsg externalIssuePageDecoder
         }
 
 
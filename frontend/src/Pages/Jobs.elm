@@ -193,7 +193,7 @@ viewJobRow selectedJob job =
         , Html.Events.onClick (SelectJob job)
         ]
         [ Html.td [ Html.Attributes.style "padding" "12px" ] [ Html.text job.jobType ]
-        , Html.td [ Html.Attributes.style "padding" "12px" ] [ viewStatusBadge job.status ]
+        , Html.td [ Html.Attributes.style "padding" "12px" ] [ viewStatusBadge job.status, viewProgress job ]
         , Html.td [ Html.Attributes.style "padding" "12px" ]
             [ Html.text
                 (case job.startTime of
@@ -237,6 +237,9 @@ viewStatusBadge status =
 
                 JobSuccess ->
                     ( "#c8e6c9", "#2e7d32", "success" )
+
+                JobInterrupted ->
+                    ( "#fff9c4", "#f57f17", "interrupted" )
     in
     Html.span
         [ Html.Attributes.style "display" "inline-block"
@@ -250,6 +253,46 @@ viewStatusBadge status =
         [ Html.text label ]
 
 
+viewProgress : Job -> Html msg
+viewProgress job =
+    if job.status == JobRunning && job.progressTotal > 0 then
+        let
+            percent =
+                min 99 (job.progressDone * 100 // job.progressTotal)
+        in
+        Html.div
+            [ Html.Attributes.style "margin-top" "6px"
+            , Html.Attributes.style "font-size" "12px"
+            , Html.Attributes.style "color" "#616161"
+            ]
+            [ Html.div
+                [ Html.Attributes.style "width" "160px"
+                , Html.Attributes.style "height" "6px"
+                , Html.Attributes.style "background-color" "#e0e0e0"
+                , Html.Attributes.style "border-radius" "3px"
+                , Html.Attributes.style "overflow" "hidden"
+                ]
+                [ Html.div
+                    [ Html.Attributes.style "width" (String.fromInt percent ++ "%")
+                    , Html.Attributes.style "height" "100%"
+                    , Html.Attributes.style "background-color" "#1565c0"
+                    ]
+                    []
+                ]
+            , Html.text
+                (String.fromInt percent
+                    ++ "% ("
+                    ++ String.fromInt job.progressDone
+                    ++ " of ~"
+                    ++ String.fromInt job.progressTotal
+                    ++ " pages)"
+                )
+            ]
+
+    else
+        Html.text ""
+
+
 viewJobDetail : Job -> Html Msg
 viewJobDetail job =
     Html.div
@@ -295,7 +338,17 @@ viewJobDetail job =
         , Html.div [ Html.Attributes.style "margin-bottom" "16px" ]
             [ Html.strong [] [ Html.text "Status: " ]
             , viewStatusBadge job.status
+            , viewProgress job
             ]
+        , case ( job.status, job.heartbeatAt ) of
+            ( JobRunning, Just heartbeat ) ->
+                Html.div [ Html.Attributes.style "margin-bottom" "16px" ]
+                    [ Html.strong [] [ Html.text "Last heartbeat: " ]
+                    , Html.text (formatTime heartbeat)
+                    ]
+
+            _ ->
+                Html.text ""
         , Html.div [ Html.Attributes.style "margin-bottom" "16px" ]
             [ Html.strong [] [ Html.text "Config:" ]
             , Html.pre
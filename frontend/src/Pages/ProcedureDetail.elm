@@ -40,6 +40,8 @@ type alias Model =
     , loading : Bool
     , error : Maybe String
     , showExportDropdown : Bool
+    , commitMessage : String
+    , commitSummary : String
     }
 
 
@@ -57,6 +59,8 @@ init projectId procedureId =
       , loading = False
       , error = Nothing
       , showExportDropdown = False
+      , commitMessage = ""
+      , commitSummary = ""
       }
     , Cmd.batch
         [ API.getTestProcedure projectId procedureId True DraftResponse
@@ -89,6 +93,8 @@ type Msg
     | DraftSaved (Result Http.Error TestProcedure)
     | ClearChanges
     | DraftReset (Result Http.Error ())
+    | UpdateCommitMessage String
+    | UpdateCommitSummary String
     | CommitVersion
     | VersionCommitted (Result Http.Error TestProcedure)
 
@@ -320,9 +326,15 @@ update msg model =
                     , Cmd.none
                     )
 
+        UpdateCommitMessage message ->
+            ( { model | commitMessage = message }, Cmd.none )
+
+        UpdateCommitSummary summary ->
+            ( { model | commitSummary = summary }, Cmd.none )
+
         CommitVersion ->
             ( { model | loading = True, error = Nothing }
-            , API.commitDraft model.procedureId VersionCommitted
+            , API.commitDraft model.procedureId model.commitMessage model.commitSummary VersionCommitted
             )
 
         VersionCommitted result ->
@@ -333,6 +345,8 @@ update msg model =
                         , viewMode = ViewMode
                         , loading = False
                         , error = Nothing
+                        , commitMessage = ""
+                        , commitSummary = ""
                       }
                     , Cmd.none
                     )
@@ -949,13 +963,31 @@ viewNewVersionMode model =
                         p [ class "mdc-typography--body1" ] [ text "No draft" ]
                 ]
             ]
+        , div [ style "margin-top" "16px" ]
+            [ Components.viewFormField "Commit Message"
+                [ type_ "text"
+                , placeholder "What does this version change?"
+                , value model.commitMessage
+                , onInput UpdateCommitMessage
+                ]
+            , Components.viewTextArea "Change Summary (optional)"
+                [ placeholder "Describe the change in more detail"
+                , value model.commitSummary
+                , onInput UpdateCommitSummary
+                ]
+            ]
         , div
             [ style "display" "flex"
             , style "gap" "8px"
             , style "margin-top" "16px"
             ]
             [ button [ onClick SwitchToViewMode, class "mdc-button" ] [ text "Cancel" ]
-            , button [ onClick CommitVersion, class "mdc-button mdc-button--raised" ] [ text "Create New Version" ]
+            , button
+                [ onClick CommitVersion
+                , class "mdc-button mdc-button--raised"
+                , disabled (String.isEmpty (String.trim model.commitMessage))
+                ]
+                [ text "Create New Version" ]
             ]
         ]
 
@@ -9,7 +9,7 @@ import Html.Events
 import Http
 import Json.Decode as Decode
 import Time
-import Types exposing (CompleteTestRunInput, CreateIssueLinkInput, ExternalIssue, Integration, IntegrationListResponse, IssueLink, LinkExistingIssueInput, TestProcedure, TestRun, TestRunAsset, TestRunStepNote, TestRunStatus, User, UserListResponse)
+import Types exposing (CompleteTestRunInput, CreateIssueLinkInput, ExternalIssue, ExternalIssuePage, Integration, IntegrationListResponse, IssueLink, LinkExistingIssueInput, TestProcedure, TestRun, TestRunAsset, TestRunStepNote, TestRunStatus, User, UserListResponse)
 
 
 
@@ -44,6 +44,10 @@ type alias LinkIssueDialogState =
     { integrationId : String
     , searchQuery : String
     , searchResults : List ExternalIssue
+    , searchedQuery : String
+    , searchTotal : Maybe Int
+    , hasMoreResults : Bool
+    , nextCursor : Maybe String
     , selectedIssue : Maybe ExternalIssue
     , loading : Bool
     }
@@ -150,7 +154,8 @@ type Msg
     | SetLinkIssueIntegration String
     | SetLinkIssueSearchQuery String
     | SearchExternalIssues
-    | SearchExternalIssuesResponse (Result Http.Error (List ExternalIssue))
+    | LoadMoreExternalIssues
+    | SearchExternalIssuesResponse Bool (Result Http.Error ExternalIssuePage)
     | SelectExternalIssue ExternalIssue
     | SubmitLinkIssue
     | LinkIssueResponse (Result Http.Error IssueLink)
@@ -670,6 +675,10 @@ update msg model =
                         { integrationId = defaultIntegrationId
                         , searchQuery = ""
                         , searchResults = []
+                        , searchedQuery = ""
+                        , searchTotal = Nothing
+                        , hasMoreResults = False
+                        , nextCursor = Nothing
                         , selectedIssue = Nothing
                         , loading = False
                         }
@@ -685,7 +694,7 @@ update msg model =
         SetLinkIssueIntegration integrationId ->
             case model.linkIssueDialog of
                 Just dialog ->
-                    ( { model | linkIssueDialog = Just { dialog | integrationId = integrationId, searchResults = [], selectedIssue = Nothing } }
+                    ( { model | linkIssueDialog = Just { dialog | integrationId = integrationId, searchResults = [], hasMoreResults = False, nextCursor = Nothing, selectedIssue = Nothing } }
                     , Cmd.none
                     )
 
@@ -706,8 +715,22 @@ update msg model =
             case model.linkIssueDialog of
                 Just dialog ->
                     if String.length dialog.searchQuery >= 2 then
+                        ( { model | linkIssueDialog = Just { dialog | searchedQuery = dialog.searchQuery, loading = True } }
+                        , API.searchExternalIssues dialog.integrationId dialog.searchQuery 0 Nothing (SearchExternalIssuesResponse False)
+                        )
+
+                    else
+                        ( model, Cmd.none )
+
+                Nothing ->
+                    ( model, Cmd.none )
+
+        LoadMoreExternalIssues ->
+            case model.linkIssueDialog of
+                Just dialog ->
+                    if dialog.hasMoreResults && not dialog.loading then
                         ( { model | linkIssueDialog = Just { dialog | loading = True } }
-                        , API.searchExternalIssues dialog.integrationId dialog.searchQuery SearchExternalIssuesResponse
+                        , API.searchExternalIssues dialog.integrationId dialog.searchedQuery (List.length dialog.searchResults) dialog.nextCursor (SearchExternalIssuesResponse True)
                         )
 
                     else
@@ -716,17 +739,35 @@ update msg model =
                 Nothing ->
                     ( model, Cmd.none )
 
-        SearchExternalIssuesResponse (Ok results) ->
+        SearchExternalIssuesResponse append (Ok page) ->
             case model.linkIssueDialog of
                 Just dialog ->
-                    ( { model | linkIssueDialog = Just { dialog | searchResults = results, loading = False } }
+                    let
+                        results =
+                            if append then
+                                dialog.searchResults ++ page.items
+
+                            else
+                                page.items
+                    in
+                    ( { model
+                        | linkIssueDialog =
+                            Just
+                                { dialog
+                                    | searchResults = results
+                                    , searchTotal = page.total
+                                    , hasMoreResults = page.hasMore
+                                    , nextCursor = page.nextCursor
+                                    , loading = False
+                                }
+                      }
                     , Cmd.none
                     )
 
                 Nothing ->
                     ( model, Cmd.none )
 
-        SearchExternalIssuesResponse (Err _) ->
+        SearchExternalIssuesResponse _ (Err _) ->
             case model.linkIssueDialog of
                 Just dialog ->
                     ( { model | linkIssueDialog = Just { dialog | loading = False } }
@@ -1851,7 +1892,7 @@ viewLinkIssueDialog integrations dialog =
                         [ Html.text "Search" ]
                     ]
                 ]
-            , if dialog.loading then
+            , if dialog.loading && List.isEmpty dialog.searchResults then
                 Html.p
                     [ Html.Attributes.style "color" "#999"
                     , Html.Attributes.style "font-size" "14px"
@@ -1861,13 +1902,42 @@ viewLinkIssueDialog integrations dialog =
 
               else if not (List.isEmpty dialog.searchResults) then
                 Html.div
-                    [ Html.Attributes.style "max-height" "200px"
-                    , Html.Attributes.style "overflow-y" "auto"
-                    , Html.Attributes.style "border" "1px solid #ddd"
-                    , Html.Attributes.style "border-radius" "4px"
-                    , Html.Attributes.style "margin-bottom" "16px"
+                    [ Html.Attributes.style "margin-bottom" "16px" ]
+                    [ Html.div
+                        [ Html.Attributes.style "max-height" "200px"
+                        , Html.Attributes.style "overflow-y" "auto"
+                        , Html.Attributes.style "border" "1px solid #ddd"
+                        , Html.Attributes.style "border-radius" "4px"
+                        ]
+                        (List.map (viewExternalIssueResult dialog.selectedIssue) dialog.searchResults)
+                    , Html.div
+                        [ Html.Attributes.style "display" "flex"
+                        , Html.Attributes.style "justify-content" "space-between"
+                        , Html.Attributes.style "align-items" "center"
+                        , Html.Attributes.style "margin-top" "4px"
+                        , Html.Attributes.style "color" "#666"
+                        , Html.Attributes.style "font-size" "12px"
+                        ]
+                        [ Html.text (viewExternalIssueCount dialog)
+                        , if dialog.hasMoreResults then
+                            Html.button
+                                [ Html.Events.onClick LoadMoreExternalIssues
+                                , Html.Attributes.class "mdc-button"
+                                , Html.Attributes.disabled dialog.loading
+                                ]
+                                [ Html.text
+                                    (if dialog.loading then
+                                        "Loading..."
+
+                                     else
+                                        "Load more"
+                                    )
+                                ]
+
+                          else
+                            Html.text ""
+                        ]
                     ]
-                    (List.map (viewExternalIssueResult dialog.selectedIssue) dialog.searchResults)
 
               else
                 Html.text ""
@@ -1904,6 +1974,20 @@ viewLinkIssueDialog integrations dialog =
         ]
 
 
+viewExternalIssueCount : LinkIssueDialogState -> String
+viewExternalIssueCount dialog =
+    let
+        shown =
+            String.fromInt (List.length dialog.searchResults)
+    in
+    case dialog.searchTotal of
+        Just total ->
+            "Showing " ++ shown ++ " of " ++ String.fromInt total ++ " issues"
+
+        Nothing ->
+            "Showing " ++ shown ++ " issues"
+
+
 viewExternalIssueResult : Maybe ExternalIssue -> ExternalIssue -> Html Msg
 viewExternalIssueResult selectedIssue issue =
     let
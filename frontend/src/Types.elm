@@ -170,6 +170,7 @@ type JobStatus
     | JobStopped
     | JobFailed
     | JobSuccess
+    | JobInterrupted
 
 
 type alias Job =
@@ -184,6 +185,9 @@ type alias Job =
     , createdBy : String
     , createdAt : Time.Posix
     , updatedAt : Time.Posix
+    , heartbeatAt : Maybe Time.Posix
+    , progressDone : Int
+    , progressTotal : Int
     }
 
 
@@ -296,6 +300,15 @@ type alias ExternalIssue =
     }
 
 
+type alias ExternalIssuePage =
+    { items : List ExternalIssue
+    , total : Maybe Int
+    , offset : Int
+    , hasMore : Bool
+    , nextCursor : Maybe String
+    }
+
+
 type alias CreateIssueLinkInput =
     { integrationId : String
     , title : String
@@ -559,6 +572,9 @@ jobStatusDecoder =
                     "success" ->
                         Decode.succeed JobSuccess
 
+                    "interrupted" ->
+                        Decode.succeed JobInterrupted
+
                     _ ->
                         Decode.fail ("Unknown job status: " ++ str)
             )
@@ -568,8 +584,8 @@ jobDecoder : Decoder Job
 jobDecoder =
     Decode.map8
         (\id jobType status config result startTime endTime createdBy ->
-            \duration createdAt updatedAt ->
-                Job id jobType status config result startTime endTime duration createdBy createdAt updatedAt
+            \duration createdAt updatedAt heartbeatAt progressDone progressTotal ->
+                Job id jobType status config result startTime endTime duration createdBy createdAt updatedAt heartbeatAt progressDone progressTotal
         )
         (Decode.field "id" Decode.string)
         (Decode.field "type" Decode.string)
@@ -581,10 +597,13 @@ jobDecoder =
         (Decode.field "created_by" Decode.string)
         |> Decode.andThen
             (\fn ->
-                Decode.map3 fn
+                Decode.map6 fn
                     (Decode.maybe (Decode.field "duration" Decode.int))
                     (Decode.field "created_at" timeDecoder)
                     (Decode.field "updated_at" timeDecoder)
+                    (Decode.maybe (Decode.field "heartbeat_at" timeDecoder))
+                    (Decode.oneOf [ Decode.field "progress_done" Decode.int, Decode.succeed 0 ])
+                    (Decode.oneOf [ Decode.field "progress_total" Decode.int, Decode.succeed 0 ])
             )
 
 
@@ -726,6 +745,9 @@ jobStatusToString status =
         JobSuccess ->
             "success"
 
+        JobInterrupted ->
+            "interrupted"
+
 
 apiTokenDecoder : Decoder APIToken
 apiTokenDecoder =
@@ -799,6 +821,16 @@ externalIssueDecoder =
         (Decode.field "url" Decode.string)
 
 
+externalIssuePageDecoder : Decoder ExternalIssuePage
+externalIssuePageDecoder =
+    Decode.map5 ExternalIssuePage
+        (Decode.field "items" (Decode.oneOf [ Decode.list externalIssueDecoder, Decode.null [] ]))
+        (Decode.field "total" (Decode.nullable Decode.int))
+        (Decode.field "offset" Decode.int)
+        (Decode.field "has_more" Decode.bool)
+        (Decode.maybe (Decode.field "next_cursor" Decode.string))
+
+
 integrationListResponseDecoder : Decoder IntegrationListResponse
 integrationListResponseDecoder =
     Decode.map2 IntegrationListResponse
@@ -0,0 +1,59 @@
+package handover
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and handover store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &user.User{}, &project.Project{}, &integration.Integration{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestUser creates a user and returns its ID.
+func createTestUser(t *testing.T, db *gorm.DB, email string, active bool) uuid.UUID {
+	u := &user.User{Email: email, Username: email, PasswordHash: "hash", IsActive: true}
+	require.NoError(t, db.Create(u).Error)
+	if !active {
+		require.NoError(t, db.Model(u).Update("is_active", false).Error)
+	}
+	return u.ID
+}
+
+// createTestProject creates a project owned by ownerID.
+func createTestProject(t *testing.T, db *gorm.DB, ownerID uuid.UUID, active bool) uuid.UUID {
+	p := &project.Project{Name: "Project", OwnerID: ownerID, IsActive: true}
+	require.NoError(t, db.Create(p).Error)
+	if !active {
+		require.NoError(t, db.Model(p).Update("is_active", false).Error)
+	}
+	return p.ID
+}
+
+// createTestIntegration creates an integration owned by userID.
+func createTestIntegration(t *testing.T, db *gorm.DB, userID uuid.UUID) uuid.UUID {
+	i := &integration.Integration{
+		UserID:               userID,
+		Name:                 "Tracker",
+		Provider:             issuetracker.ProviderGitHub,
+		EncryptedCredentials: []byte("secret"),
+		IsActive:             true,
+	}
+	require.NoError(t, db.Create(i).Error)
+	return i.ID
+}
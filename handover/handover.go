@@ -0,0 +1,38 @@
+// Package handover moves what a user owns to another user, so that leaving
+// users do not orphan their projects and integrations.
+package handover
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSameUser is returned when a user's holdings are transferred to
+	// themselves.
+	ErrSameUser = errors.New("cannot transfer to the same user")
+
+	// ErrRecipientNotFound is returned when holdings are transferred to a
+	// user that does not exist, is deactivated or is a service account.
+	ErrRecipientNotFound = errors.New("new owner must be an active user")
+)
+
+// Holdings counts the active projects and integrations a user owns.
+type Holdings struct {
+	Projects     int `json:"projects"`
+	Integrations int `json:"integrations"`
+}
+
+// IsEmpty reports whether the user owns nothing.
+func (h Holdings) IsEmpty() bool {
+	return h.Projects == 0 && h.Integrations == 0
+}
+
+// Transfer lists what a transfer moved to the new owner.
+type Transfer struct {
+	FromUserID     uuid.UUID   `json:"from_user_id"`
+	ToUserID       uuid.UUID   `json:"to_user_id"`
+	ProjectIDs     []uuid.UUID `json:"project_ids"`
+	IntegrationIDs []uuid.UUID `json:"integration_ids"`
+}
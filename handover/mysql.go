@@ -0,0 +1,125 @@
+package handover
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed handover store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Holdings counts the active projects and integrations of a user.
+func (s *MySQLStore) Holdings(ctx context.Context, userID uuid.UUID) (Holdings, error) {
+	db := s.db.WithContext(ctx)
+
+	var projects, integrations int64
+	err := db.Model(&project.Project{}).
+		Where("owner_id = ? AND is_active = ?", userID, true).
+		Count(&projects).Error
+	if err == nil {
+		err = db.Model(&integration.Integration{}).
+			Where("user_id = ? AND is_active = ?", userID, true).
+			Count(&integrations).Error
+	}
+	if err != nil {
+		s.logger.Error(ctx, "failed to count user holdings", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		return Holdings{}, err
+	}
+
+	return Holdings{Projects: int(projects), Integrations: int(integrations)}, nil
+}
+
+// Transfer makes toUserID the owner of every project and integration of
+// fromUserID in one transaction. Projects keep their default integrations,
+// which move along with them.
+func (s *MySQLStore) Transfer(ctx context.Context, fromUserID, toUserID uuid.UUID) (*Transfer, error) {
+	if fromUserID == toUserID {
+		return nil, ErrSameUser
+	}
+
+	transfer := &Transfer{
+		FromUserID:     fromUserID,
+		ToUserID:       toUserID,
+		ProjectIDs:     []uuid.UUID{},
+		IntegrationIDs: []uuid.UUID{},
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var recipients int64
+		if err := tx.Model(&user.User{}).
+			Where("id = ? AND is_active = ? AND role <> ?", toUserID, true, user.RoleService).
+			Count(&recipients).Error; err != nil {
+			return err
+		}
+		if recipients == 0 {
+			return ErrRecipientNotFound
+		}
+
+		if err := tx.Model(&project.Project{}).
+			Where("owner_id = ?", fromUserID).
+			Pluck("id", &transfer.ProjectIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&integration.Integration{}).
+			Where("user_id = ?", fromUserID).
+			Pluck("id", &transfer.IntegrationIDs).Error; err != nil {
+			return err
+		}
+
+		if len(transfer.ProjectIDs) > 0 {
+			if err := tx.Model(&project.Project{}).
+				Where("id IN ?", transfer.ProjectIDs).
+				Update("owner_id", toUserID).Error; err != nil {
+				return err
+			}
+		}
+		if len(transfer.IntegrationIDs) > 0 {
+			if err := tx.Model(&integration.Integration{}).
+				Where("id IN ?", transfer.IntegrationIDs).
+				Update("user_id", toUserID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrRecipientNotFound) {
+			return nil, err
+		}
+		s.logger.Error(ctx, "failed to transfer user holdings", map[string]interface{}{
+			"error":        err.Error(),
+			"from_user_id": fromUserID.String(),
+			"to_user_id":   toUserID.String(),
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "user holdings transferred", map[string]interface{}{
+		"from_user_id": fromUserID.String(),
+		"to_user_id":   toUserID.String(),
+		"projects":     len(transfer.ProjectIDs),
+		"integrations": len(transfer.IntegrationIDs),
+	})
+
+	return transfer, nil
+}
@@ -0,0 +1,108 @@
+package handover
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Holdings(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	owner := createTestUser(t, db, "owner@example.com", true)
+	createTestProject(t, db, owner, true)
+	createTestProject(t, db, owner, false)
+	createTestIntegration(t, db, owner)
+
+	t.Run("counts active projects and integrations", func(t *testing.T) {
+		holdings, err := store.Holdings(ctx, owner)
+		require.NoError(t, err)
+		assert.Equal(t, Holdings{Projects: 1, Integrations: 1}, holdings)
+		assert.False(t, holdings.IsEmpty())
+	})
+
+	t.Run("user without holdings", func(t *testing.T) {
+		holdings, err := store.Holdings(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.True(t, holdings.IsEmpty())
+	})
+}
+
+func TestMySQLStore_Transfer(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	owner := createTestUser(t, db, "owner@example.com", true)
+	recipient := createTestUser(t, db, "recipient@example.com", true)
+	inactive := createTestUser(t, db, "inactive@example.com", false)
+	bystander := createTestUser(t, db, "bystander@example.com", true)
+
+	activeProject := createTestProject(t, db, owner, true)
+	deletedProject := createTestProject(t, db, owner, false)
+	integrationID := createTestIntegration(t, db, owner)
+	otherProject := createTestProject(t, db, bystander, true)
+
+	t.Run("rejects the same user", func(t *testing.T) {
+		_, err := store.Transfer(ctx, owner, owner)
+		assert.ErrorIs(t, err, ErrSameUser)
+	})
+
+	t.Run("rejects an inactive recipient", func(t *testing.T) {
+		_, err := store.Transfer(ctx, owner, inactive)
+		assert.ErrorIs(t, err, ErrRecipientNotFound)
+
+		holdings, err := store.Holdings(ctx, owner)
+		require.NoError(t, err)
+		assert.Equal(t, Holdings{Projects: 1, Integrations: 1}, holdings)
+	})
+
+	t.Run("rejects a missing recipient", func(t *testing.T) {
+		_, err := store.Transfer(ctx, owner, uuid.New())
+		assert.ErrorIs(t, err, ErrRecipientNotFound)
+	})
+
+	t.Run("rejects a service account recipient", func(t *testing.T) {
+		serviceAccount := createTestUser(t, db, "sa@service-accounts.invalid", true)
+		require.NoError(t, db.Model(&user.User{}).Where("id = ?", serviceAccount).Update("role", user.RoleService).Error)
+
+		_, err := store.Transfer(ctx, owner, serviceAccount)
+		assert.ErrorIs(t, err, ErrRecipientNotFound)
+	})
+
+	t.Run("moves projects and integrations", func(t *testing.T) {
+		transfer, err := store.Transfer(ctx, owner, recipient)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{activeProject, deletedProject}, transfer.ProjectIDs)
+		assert.Equal(t, []uuid.UUID{integrationID}, transfer.IntegrationIDs)
+
+		holdings, err := store.Holdings(ctx, owner)
+		require.NoError(t, err)
+		assert.True(t, holdings.IsEmpty())
+
+		var p project.Project
+		require.NoError(t, db.First(&p, "id = ?", deletedProject).Error)
+		assert.Equal(t, recipient, p.OwnerID)
+
+		var i integration.Integration
+		require.NoError(t, db.First(&i, "id = ?", integrationID).Error)
+		assert.Equal(t, recipient, i.UserID)
+
+		var other project.Project
+		require.NoError(t, db.First(&other, "id = ?", otherProject).Error)
+		assert.Equal(t, bystander, other.OwnerID)
+	})
+
+	t.Run("nothing left to move", func(t *testing.T) {
+		transfer, err := store.Transfer(ctx, owner, recipient)
+		require.NoError(t, err)
+		assert.Empty(t, transfer.ProjectIDs)
+		assert.Empty(t, transfer.IntegrationIDs)
+	})
+}
@@ -0,0 +1,17 @@
+package handover
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for transferring what users own.
+type Store interface {
+	// Holdings counts the active projects and integrations of a user.
+	Holdings(ctx context.Context, userID uuid.UUID) (Holdings, error)
+
+	// Transfer makes toUserID the owner of every project and integration of
+	// fromUserID, including deleted ones, in one transaction.
+	Transfer(ctx context.Context, fromUserID, toUserID uuid.UUID) (*Transfer, error)
+}
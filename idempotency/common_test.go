@@ -0,0 +1,17 @@
+package idempotency
+
+import (
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+)
+
+// setupTestStore creates a test database and idempotency record store for
+// testing.
+func setupTestStore(t *testing.T) *MySQLStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Record{})
+
+	return NewMySQLStore(db, logger.NewTestLogger())
+}
@@ -0,0 +1,88 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRecordNotFound is returned when no unexpired record holds a key.
+	ErrRecordNotFound = errors.New("idempotency record not found")
+
+	// ErrKeyInUse is returned when a key is reserved while an unexpired
+	// record already holds it.
+	ErrKeyInUse = errors.New("idempotency key already in use")
+
+	// ErrInvalidKey is returned when a key is empty or too long.
+	ErrInvalidKey = errors.New("idempotency key must be 1 to 255 characters")
+)
+
+const (
+	// DefaultTTL is how long a response is kept for retries when no TTL is
+	// configured.
+	DefaultTTL = 24 * time.Hour
+
+	// MaxKeyLength is the longest key a client may send.
+	MaxKeyLength = 255
+)
+
+// ValidateKey checks that a key sent by a client can be stored.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > MaxKeyLength {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// HashRequest returns the hash of a request, so that a key reused for a
+// different request can be told apart from a retry.
+func HashRequest(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + uri + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Record holds a key a caller sent with a request and, once the request
+// completed, its response, so that a retry with the same key is answered
+// with that response instead of being carried out again. Keys belong to a
+// caller: the same key sent by two callers is two records.
+type Record struct {
+	ID uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	// Scope is the caller, "user:<id>" for sessions or "token:<id>" for
+	// API tokens.
+	Scope       string `json:"scope" gorm:"type:varchar(80);not null;uniqueIndex:idx_idempotency_records_scope_key"`
+	Key         string `json:"key" gorm:"column:idempotency_key;type:varchar(255);not null;uniqueIndex:idx_idempotency_records_scope_key"`
+	RequestHash string `json:"request_hash" gorm:"type:char(64);not null"`
+	// StatusCode is 0 while the request is in progress.
+	StatusCode  int       `json:"status_code" gorm:"not null;default:0"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(255);not null;default:''"`
+	Body        []byte    `json:"-" gorm:"type:mediumblob"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index:idx_idempotency_records_expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (r *Record) TableName() string {
+	return "idempotency_records"
+}
+
+// BeforeCreate hook to generate UUID before creating a new record
+func (r *Record) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Completed reports whether the request finished and its response was
+// kept.
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
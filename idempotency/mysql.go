@@ -0,0 +1,140 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed idempotency record store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Reserve creates an in-progress record for a key, replacing an expired
+// one.
+func (s *MySQLStore) Reserve(ctx context.Context, record *Record) error {
+	if err := ValidateKey(record.Key); err != nil {
+		return err
+	}
+	record.StatusCode = 0
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scope = ? AND idempotency_key = ? AND expires_at <= ?", record.Scope, record.Key, time.Now()).
+			Delete(&Record{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		// Check for duplicate key error (MySQL and SQLite)
+		if errors.Is(err, gorm.ErrDuplicatedKey) ||
+			strings.Contains(err.Error(), "UNIQUE constraint failed") ||
+			strings.Contains(err.Error(), "Duplicate entry") {
+			return ErrKeyInUse
+		}
+		s.logger.Error(ctx, "failed to reserve idempotency key", map[string]interface{}{
+			"error": err.Error(),
+			"scope": record.Scope,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// Get retrieves the unexpired record holding a caller's key.
+func (s *MySQLStore) Get(ctx context.Context, scope, key string) (*Record, error) {
+	var record Record
+	err := s.db.WithContext(ctx).
+		Where("scope = ? AND idempotency_key = ? AND expires_at > ?", scope, key, time.Now()).
+		First(&record).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		s.logger.Error(ctx, "failed to get idempotency record", map[string]interface{}{
+			"error": err.Error(),
+			"scope": scope,
+		})
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response of a reserved request.
+func (s *MySQLStore) Complete(ctx context.Context, id uuid.UUID, statusCode int, contentType string, body []byte) error {
+	result := s.db.WithContext(ctx).
+		Model(&Record{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status_code":  statusCode,
+			"content_type": contentType,
+			"body":         body,
+		})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to complete idempotency record", map[string]interface{}{
+			"error":                 result.Error.Error(),
+			"idempotency_record_id": id.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a record, releasing its key.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&Record{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete idempotency record", map[string]interface{}{
+			"error":                 result.Error.Error(),
+			"idempotency_record_id": id.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes the records expired at now.
+func (s *MySQLStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("expires_at <= ?", now).
+		Delete(&Record{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete expired idempotency records", map[string]interface{}{
+			"error": result.Error.Error(),
+		})
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Reserve(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	newRecord := func(scope, key string, expiresAt time.Time) *Record {
+		return &Record{Scope: scope, Key: key, RequestHash: HashRequest("POST", "/api/v1/jobs", nil), ExpiresAt: expiresAt}
+	}
+
+	t.Run("reserves a new key", func(t *testing.T) {
+		require.NoError(t, store.Reserve(ctx, newRecord("user:a", "k1", time.Now().Add(time.Hour))))
+
+		record, err := store.Get(ctx, "user:a", "k1")
+		require.NoError(t, err)
+		assert.False(t, record.Completed())
+	})
+
+	t.Run("held key is in use", func(t *testing.T) {
+		require.NoError(t, store.Reserve(ctx, newRecord("user:a", "k2", time.Now().Add(time.Hour))))
+		err := store.Reserve(ctx, newRecord("user:a", "k2", time.Now().Add(time.Hour)))
+		assert.ErrorIs(t, err, ErrKeyInUse)
+	})
+
+	t.Run("keys belong to a caller", func(t *testing.T) {
+		require.NoError(t, store.Reserve(ctx, newRecord("user:a", "k3", time.Now().Add(time.Hour))))
+		assert.NoError(t, store.Reserve(ctx, newRecord("token:b", "k3", time.Now().Add(time.Hour))))
+	})
+
+	t.Run("expired key is replaced", func(t *testing.T) {
+		require.NoError(t, store.Reserve(ctx, newRecord("user:a", "k4", time.Now().Add(-time.Minute))))
+		_, err := store.Get(ctx, "user:a", "k4")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+
+		assert.NoError(t, store.Reserve(ctx, newRecord("user:a", "k4", time.Now().Add(time.Hour))))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		err := store.Reserve(ctx, newRecord("user:a", strings.Repeat("k", MaxKeyLength+1), time.Now().Add(time.Hour)))
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestMySQLStore_CompleteAndDelete(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	record := &Record{Scope: "user:a", Key: "k", RequestHash: HashRequest("POST", "/api/v1/jobs", []byte("{}")), ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, store.Reserve(ctx, record))
+	require.NoError(t, store.Complete(ctx, record.ID, 201, "application/json", []byte(`{"id":"1"}`)))
+
+	got, err := store.Get(ctx, "user:a", "k")
+	require.NoError(t, err)
+	assert.True(t, got.Completed())
+	assert.Equal(t, 201, got.StatusCode)
+	assert.Equal(t, "application/json", got.ContentType)
+	assert.Equal(t, `{"id":"1"}`, string(got.Body))
+
+	require.NoError(t, store.Delete(ctx, record.ID))
+	_, err = store.Get(ctx, "user:a", "k")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, record.ID), ErrRecordNotFound)
+}
+
+func TestMySQLStore_DeleteExpired(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.Reserve(ctx, &Record{Scope: "user:a", Key: "old", ExpiresAt: now.Add(-time.Hour)}))
+	require.NoError(t, store.Reserve(ctx, &Record{Scope: "user:a", Key: "new", ExpiresAt: now.Add(time.Hour)}))
+
+	deleted, err := store.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	_, err = store.Get(ctx, "user:a", "new")
+	assert.NoError(t, err)
+}
+
+func TestHashRequest(t *testing.T) {
+	a := HashRequest("POST", "/api/v1/jobs", []byte(`{"type":"ui_exploration"}`))
+	assert.Equal(t, a, HashRequest("POST", "/api/v1/jobs", []byte(`{"type":"ui_exploration"}`)))
+	assert.NotEqual(t, a, HashRequest("POST", "/api/v1/jobs", []byte(`{"type":"visual_regression"}`)))
+	assert.NotEqual(t, a, HashRequest("POST", "/api/v1/procedures", []byte(`{"type":"ui_exploration"}`)))
+}
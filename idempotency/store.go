@@ -0,0 +1,30 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for idempotency record persistence
+// operations.
+type Store interface {
+	// Reserve creates an in-progress record for a key. It returns
+	// ErrKeyInUse if an unexpired record already holds the key; an expired
+	// one is replaced.
+	Reserve(ctx context.Context, record *Record) error
+
+	// Get retrieves the unexpired record holding a caller's key.
+	Get(ctx context.Context, scope, key string) (*Record, error)
+
+	// Complete stores the response of a reserved request.
+	Complete(ctx context.Context, id uuid.UUID, statusCode int, contentType string, body []byte) error
+
+	// Delete deletes a record, releasing its key.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// DeleteExpired deletes the records expired at now and returns how
+	// many were deleted.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
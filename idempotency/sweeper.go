@@ -0,0 +1,55 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// Sweeper periodically deletes expired records, which are otherwise only
+// replaced when their key is sent again.
+type Sweeper struct {
+	store  Store
+	logger logger.Logger
+	stopCh chan struct{}
+}
+
+// NewSweeper creates a sweeper of the records in store.
+func NewSweeper(store Store, log logger.Logger) *Sweeper {
+	return &Sweeper{
+		store:  store,
+		logger: log,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start starts a background goroutine that deletes expired records every
+// interval.
+func (s *Sweeper) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				deleted, err := s.store.DeleteExpired(context.Background(), now)
+				if err != nil {
+					continue
+				}
+				if deleted > 0 {
+					s.logger.Info(context.Background(), "deleted expired idempotency records", map[string]interface{}{
+						"deleted_count": deleted,
+					})
+				}
+			case <-s.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the sweeper goroutine.
+func (s *Sweeper) Stop() {
+	close(s.stopCh)
+}
@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+)
+
+// DefaultExpiryWarning is how long before their expiry credentials are
+// reported as expiring when no window is configured.
+const DefaultExpiryWarning = 7 * 24 * time.Hour
+
+// AlertFunc is called when an integration's credentials start needing
+// attention, once per status.
+type AlertFunc func(ctx context.Context, integ *Integration, status CredentialStatus)
+
+// Checker periodically validates the credentials of active integrations
+// against their trackers and alerts their owners when credentials are about
+// to expire, have expired or start being rejected.
+type Checker struct {
+	store         Store
+	clientFactory issuetracker.ClientFactory
+	breakers      *resilience.Registry
+	keyring       *Keyring
+	warnWithin    time.Duration
+	alert         AlertFunc
+	logger        logger.Logger
+	stopCh        chan struct{}
+}
+
+// NewChecker creates a checker warning warnWithin before credentials expire.
+// Tracker calls run under the integration's circuit breaker from breakers.
+func NewChecker(store Store, clientFactory issuetracker.ClientFactory, breakers *resilience.Registry, keyring *Keyring, warnWithin time.Duration, alert AlertFunc, log logger.Logger) *Checker {
+	if warnWithin <= 0 {
+		warnWithin = DefaultExpiryWarning
+	}
+	return &Checker{
+		store:         store,
+		clientFactory: clientFactory,
+		breakers:      breakers,
+		keyring:       keyring,
+		warnWithin:    warnWithin,
+		alert:         alert,
+		logger:        log,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// WarnWithin returns how long before their expiry credentials are reported
+// as expiring.
+func (c *Checker) WarnWithin() time.Duration {
+	return c.warnWithin
+}
+
+// Validate checks the integration's credentials against its tracker. It
+// returns resilience.ErrCircuitOpen, without calling the tracker, while the
+// tracker is considered down.
+func (c *Checker) Validate(ctx context.Context, integ *Integration) error {
+	creds, err := c.keyring.Decrypt(integ.EncryptedCredentials)
+	if err != nil {
+		return err
+	}
+	client, err := c.clientFactory.NewClient(integ.Provider, creds)
+	if err != nil {
+		return err
+	}
+	return issuetracker.NewResilientClient(client, c.breakers.Get(integ.ID.String())).ValidateConnection(ctx)
+}
+
+// IsUnreachable reports whether a validation error means the tracker could
+// not be reached, which says nothing about the credentials.
+func IsUnreachable(err error) bool {
+	return errors.Is(err, resilience.ErrCircuitOpen) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Check validates the credentials of one integration at now, records the
+// result and alerts the owner if the credential status newly needs
+// attention. An unreachable tracker is not held against the credentials.
+func (c *Checker) Check(ctx context.Context, integ *Integration, now time.Time) error {
+	checkErr := c.Validate(ctx, integ)
+	if IsUnreachable(checkErr) {
+		c.logger.Warn(ctx, "skipped credential check of unreachable tracker", map[string]interface{}{
+			"error":          checkErr.Error(),
+			"integration_id": integ.ID.String(),
+		})
+		return nil
+	}
+
+	record := SetCheckResult(now, checkErr)
+	if err := record(integ); err != nil {
+		return err
+	}
+	setters := []IntegrationSetter{record}
+
+	status := integ.CredentialStatusAt(now, c.warnWithin)
+	notified := integ.NotifiedCredentialStatus
+	switch {
+	case status.NeedsAttention() && status != notified:
+		c.alert(ctx, integ, status)
+		setters = append(setters, SetNotifiedCredentialStatus(status))
+	case !status.NeedsAttention() && notified != "":
+		setters = append(setters, SetNotifiedCredentialStatus(""))
+	}
+
+	return c.store.UpdateIntegration(ctx, integ.ID, setters...)
+}
+
+// Run checks every active integration at now. An integration that fails
+// to be checked does not stop the others.
+func (c *Checker) Run(ctx context.Context, now time.Time) {
+	integrations, err := c.store.ListActiveIntegrations(ctx)
+	if err != nil {
+		c.logger.Error(ctx, "failed to list integrations for credential checks", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, integ := range integrations {
+		if err := c.Check(ctx, integ, now); err != nil {
+			c.logger.Error(ctx, "failed to check integration credentials", map[string]interface{}{
+				"error":          err.Error(),
+				"integration_id": integ.ID.String(),
+			})
+		}
+	}
+}
+
+// Start starts a background goroutine that checks credentials every
+// interval.
+func (c *Checker) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				c.Run(context.Background(), now)
+			case <-c.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the check goroutine.
+func (c *Checker) Stop() {
+	close(c.stopCh)
+}
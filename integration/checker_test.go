@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubClient is an issue tracker client whose connection check fails with
+// validateErr.
+type stubClient struct {
+	issuetracker.Client
+	validateErr error
+}
+
+func (c *stubClient) ValidateConnection(ctx context.Context) error {
+	return c.validateErr
+}
+
+type stubFactory struct {
+	client *stubClient
+}
+
+func (f *stubFactory) NewClient(provider issuetracker.ProviderType, credentials map[string]string) (issuetracker.Client, error) {
+	return f.client, nil
+}
+
+type alert struct {
+	integrationID uuid.UUID
+	status        CredentialStatus
+}
+
+func TestChecker(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Integration{})
+	store := NewMySQLStore(db, logger.NewTestLogger())
+	key := DeriveKey("test-passphrase")
+
+	client := &stubClient{}
+	var alerts []alert
+	checker := NewChecker(store, &stubFactory{client: client}, resilience.NewRegistry(resilience.Config{}), NewKeyring(key), 7*24*time.Hour,
+		func(ctx context.Context, integ *Integration, status CredentialStatus) {
+			alerts = append(alerts, alert{integ.ID, status})
+		}, logger.NewTestLogger())
+
+	newIntegration := func(t *testing.T, expireAt *time.Time) *Integration {
+		encrypted, err := EncryptCredentials(key, map[string]string{"token": "ghp_test"})
+		require.NoError(t, err)
+		integ := &Integration{
+			UserID:               uuid.New(),
+			Name:                 "GitHub",
+			Provider:             issuetracker.ProviderGitHub,
+			EncryptedCredentials: encrypted,
+			IsActive:             true,
+			CredentialsExpireAt:  expireAt,
+		}
+		require.NoError(t, store.CreateIntegration(ctx, integ))
+		return integ
+	}
+	check := func(t *testing.T, id uuid.UUID, now time.Time) *Integration {
+		integ, err := store.GetIntegrationByID(ctx, id)
+		require.NoError(t, err)
+		require.NoError(t, checker.Check(ctx, integ, now))
+		integ, err = store.GetIntegrationByID(ctx, id)
+		require.NoError(t, err)
+		return integ
+	}
+
+	t.Run("failing credentials alert once", func(t *testing.T) {
+		alerts = nil
+		integ := newIntegration(t, nil)
+		now := time.Now()
+
+		client.validateErr = errors.New("401 Unauthorized")
+		got := check(t, integ.ID, now)
+		assert.Equal(t, "401 Unauthorized", got.LastCheckError)
+		assert.Equal(t, CredentialStatusFailing, got.NotifiedCredentialStatus)
+		check(t, integ.ID, now.Add(time.Hour))
+		assert.Equal(t, []alert{{integ.ID, CredentialStatusFailing}}, alerts)
+
+		client.validateErr = nil
+		got = check(t, integ.ID, now.Add(2*time.Hour))
+		assert.Empty(t, got.LastCheckError)
+		assert.Empty(t, got.NotifiedCredentialStatus)
+		assert.Equal(t, CredentialStatusValid, got.CredentialStatusAt(now.Add(2*time.Hour), checker.WarnWithin()))
+	})
+
+	t.Run("expiring then expired credentials", func(t *testing.T) {
+		alerts = nil
+		client.validateErr = nil
+		now := time.Now()
+		expireAt := now.Add(3 * 24 * time.Hour)
+		integ := newIntegration(t, &expireAt)
+
+		check(t, integ.ID, now)
+		check(t, integ.ID, now.Add(24*time.Hour))
+		check(t, integ.ID, now.Add(4*24*time.Hour))
+		assert.Equal(t, []alert{
+			{integ.ID, CredentialStatusExpiring},
+			{integ.ID, CredentialStatusExpired},
+		}, alerts)
+	})
+
+	t.Run("unreachable tracker is not held against the credentials", func(t *testing.T) {
+		alerts = nil
+		integ := newIntegration(t, nil)
+
+		client.validateErr = context.DeadlineExceeded
+		got := check(t, integ.ID, time.Now())
+		assert.Nil(t, got.LastCheckedAt)
+		assert.Empty(t, alerts)
+	})
+
+	t.Run("run skips inactive integrations", func(t *testing.T) {
+		alerts = nil
+		client.validateErr = errors.New("403 Forbidden")
+		inactive := newIntegration(t, nil)
+		require.NoError(t, store.UpdateIntegration(ctx, inactive.ID, SetIsActive(false)))
+
+		checker.Run(ctx, time.Now())
+		for _, a := range alerts {
+			assert.NotEqual(t, inactive.ID, a.integrationID)
+		}
+		got, err := store.GetIntegrationByID(ctx, inactive.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.LastCheckedAt)
+	})
+}
@@ -0,0 +1,53 @@
+package integration
+
+import "time"
+
+// CredentialStatus is the health of an integration's credentials.
+type CredentialStatus string
+
+const (
+	// CredentialStatusUnchecked means the credentials have not been
+	// validated since they were set.
+	CredentialStatusUnchecked CredentialStatus = "unchecked"
+
+	// CredentialStatusValid means the last validation succeeded and the
+	// credentials are not about to expire.
+	CredentialStatusValid CredentialStatus = "valid"
+
+	// CredentialStatusExpiring means the credentials expire within the
+	// warning window.
+	CredentialStatusExpiring CredentialStatus = "expiring"
+
+	// CredentialStatusExpired means the credentials are past their expiry.
+	CredentialStatusExpired CredentialStatus = "expired"
+
+	// CredentialStatusFailing means the last validation was rejected.
+	CredentialStatusFailing CredentialStatus = "failing"
+)
+
+// NeedsAttention reports whether the owner should be warned about the
+// status.
+func (s CredentialStatus) NeedsAttention() bool {
+	return s == CredentialStatusExpiring || s == CredentialStatusExpired || s == CredentialStatusFailing
+}
+
+// CredentialStatusAt returns the status of the integration's credentials at
+// now. Credentials expiring within warnWithin are reported as expiring. An
+// expiry takes precedence over a failing validation, since it explains it.
+func (i *Integration) CredentialStatusAt(now time.Time, warnWithin time.Duration) CredentialStatus {
+	if i.CredentialsExpireAt != nil {
+		if !now.Before(*i.CredentialsExpireAt) {
+			return CredentialStatusExpired
+		}
+	}
+	if i.LastCheckError != "" {
+		return CredentialStatusFailing
+	}
+	if i.CredentialsExpireAt != nil && i.CredentialsExpireAt.Sub(now) <= warnWithin {
+		return CredentialStatusExpiring
+	}
+	if i.LastCheckedAt == nil {
+		return CredentialStatusUnchecked
+	}
+	return CredentialStatusValid
+}
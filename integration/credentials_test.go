@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialStatusAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	warn := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name  string
+		integ Integration
+		want  CredentialStatus
+	}{
+		{
+			name:  "never checked",
+			integ: Integration{},
+			want:  CredentialStatusUnchecked,
+		},
+		{
+			name:  "checked and no expiry",
+			integ: Integration{LastCheckedAt: at(-time.Hour)},
+			want:  CredentialStatusValid,
+		},
+		{
+			name:  "expiry outside the warning window",
+			integ: Integration{LastCheckedAt: at(-time.Hour), CredentialsExpireAt: at(30 * 24 * time.Hour)},
+			want:  CredentialStatusValid,
+		},
+		{
+			name:  "expiry inside the warning window",
+			integ: Integration{LastCheckedAt: at(-time.Hour), CredentialsExpireAt: at(3 * 24 * time.Hour)},
+			want:  CredentialStatusExpiring,
+		},
+		{
+			name:  "expired",
+			integ: Integration{CredentialsExpireAt: at(-time.Minute), LastCheckError: "401 Unauthorized"},
+			want:  CredentialStatusExpired,
+		},
+		{
+			name:  "rejected",
+			integ: Integration{LastCheckedAt: at(-time.Hour), LastCheckError: "401 Unauthorized", CredentialsExpireAt: at(3 * 24 * time.Hour)},
+			want:  CredentialStatusFailing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.integ.CredentialStatusAt(now, warn))
+		})
+	}
+}
+
+func TestCredentialSetters(t *testing.T) {
+	t.Parallel()
+
+	t.Run("new credentials are unchecked", func(t *testing.T) {
+		t.Parallel()
+		checked := time.Now().Add(-time.Hour)
+		integ := &Integration{
+			LastCheckedAt:            &checked,
+			LastCheckError:           "401",
+			NotifiedCredentialStatus: CredentialStatusFailing,
+		}
+		require.NoError(t, SetEncryptedCredentials([]byte("new"))(integ))
+		assert.Nil(t, integ.LastCheckedAt)
+		assert.Empty(t, integ.LastCheckError)
+		assert.Empty(t, integ.NotifiedCredentialStatus)
+		assert.WithinDuration(t, time.Now(), integ.CredentialsUpdatedAt, time.Minute)
+	})
+
+	t.Run("check error is truncated", func(t *testing.T) {
+		t.Parallel()
+		integ := &Integration{}
+		long := make([]byte, maxCheckErrorLength+100)
+		for i := range long {
+			long[i] = 'x'
+		}
+		require.NoError(t, SetCheckResult(time.Now(), errors.New(string(long)))(integ))
+		assert.Len(t, integ.LastCheckError, maxCheckErrorLength)
+		require.NoError(t, SetCheckResult(time.Now(), nil)(integ))
+		assert.Empty(t, integ.LastCheckError)
+	})
+}
@@ -72,3 +72,22 @@ func DecryptCredentials(key []byte, ciphertext []byte) (map[string]string, error
 
 	return creds, nil
 }
+
+// Reencrypt decrypts ciphertext with oldKey and encrypts the credentials
+// again with newKey, for rotating the encryption key. Ciphertext that
+// already decrypts with newKey is returned as is and reported unchanged, so
+// an interrupted or repeated rotation can be run again.
+func Reencrypt(oldKey, newKey, ciphertext []byte) ([]byte, bool, error) {
+	if _, err := DecryptCredentials(newKey, ciphertext); err == nil {
+		return ciphertext, false, nil
+	}
+	creds, err := DecryptCredentials(oldKey, ciphertext)
+	if err != nil {
+		return nil, false, err
+	}
+	encrypted, err := EncryptCredentials(newKey, creds)
+	if err != nil {
+		return nil, false, err
+	}
+	return encrypted, true, nil
+}
@@ -83,3 +83,62 @@ func TestEncryptProducesDifferentCiphertexts(t *testing.T) {
 	// Due to random nonce, same plaintext should produce different ciphertexts
 	assert.NotEqual(t, enc1, enc2)
 }
+
+func TestReencrypt(t *testing.T) {
+	t.Parallel()
+	oldKey := DeriveKey("old")
+	newKey := DeriveKey("new")
+	creds := map[string]string{"token": "value"}
+
+	encrypted, err := EncryptCredentials(oldKey, creds)
+	require.NoError(t, err)
+
+	reencrypted, changed, err := Reencrypt(oldKey, newKey, encrypted)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	decrypted, err := DecryptCredentials(newKey, reencrypted)
+	require.NoError(t, err)
+	assert.Equal(t, creds, decrypted)
+
+	_, err = DecryptCredentials(oldKey, reencrypted)
+	assert.Error(t, err)
+
+	again, changed, err := Reencrypt(oldKey, newKey, reencrypted)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, reencrypted, again)
+
+	_, _, err = Reencrypt(DeriveKey("wrong"), DeriveKey("other"), encrypted)
+	assert.Error(t, err)
+}
+
+func TestKeyring(t *testing.T) {
+	t.Parallel()
+	oldKey := DeriveKey("old")
+	newKey := DeriveKey("new")
+	creds := map[string]string{"token": "value"}
+
+	keyring := NewKeyring(oldKey)
+	before, err := keyring.Encrypt(creds)
+	require.NoError(t, err)
+
+	keyring.Rotate(newKey)
+	assert.Equal(t, newKey, keyring.Current())
+
+	after, err := keyring.Encrypt(creds)
+	require.NoError(t, err)
+	_, err = DecryptCredentials(newKey, after)
+	assert.NoError(t, err)
+
+	for _, ciphertext := range [][]byte{before, after} {
+		decrypted, err := keyring.Decrypt(ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, creds, decrypted)
+	}
+
+	foreign, err := EncryptCredentials(DeriveKey("other"), creds)
+	require.NoError(t, err)
+	_, err = keyring.Decrypt(foreign)
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// Keyring holds the key that secrets are encrypted with and the keys it
+// replaced, so that values encrypted before a key rotation still decrypt
+// until they have been re-encrypted. It is safe for concurrent use.
+type Keyring struct {
+	mu       sync.RWMutex
+	current  []byte
+	previous [][]byte
+}
+
+// NewKeyring creates a keyring that encrypts with key and also decrypts
+// with the previous keys.
+func NewKeyring(key []byte, previous ...[]byte) *Keyring {
+	return &Keyring{current: key, previous: previous}
+}
+
+// Current returns the key values are encrypted with.
+func (k *Keyring) Current() []byte {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// Rotate makes key the encryption key, keeping the replaced key for
+// decryption. Rotating to the current key does nothing.
+func (k *Keyring) Rotate(key []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if bytes.Equal(key, k.current) {
+		return
+	}
+	k.previous = append([][]byte{k.current}, k.previous...)
+	k.current = key
+}
+
+// Encrypt encrypts creds with the current key.
+func (k *Keyring) Encrypt(creds map[string]string) ([]byte, error) {
+	return EncryptCredentials(k.Current(), creds)
+}
+
+// Decrypt decrypts ciphertext with the current key, falling back to the
+// previous keys from the most recent.
+func (k *Keyring) Decrypt(ciphertext []byte) (map[string]string, error) {
+	k.mu.RLock()
+	keys := append([][]byte{k.current}, k.previous...)
+	k.mu.RUnlock()
+
+	var lastErr error
+	for _, key := range keys {
+		creds, err := DecryptCredentials(key, ciphertext)
+		if err == nil {
+			return creds, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("no key in the keyring decrypts the value: %w", lastErr)
+}
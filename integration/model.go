@@ -27,14 +27,28 @@ type Integration struct {
 	Provider             issuetracker.ProviderType `json:"provider" gorm:"type:varchar(20);not null"`
 	EncryptedCredentials []byte                    `json:"-" gorm:"type:blob;not null"`
 	IsActive             bool                      `json:"is_active" gorm:"not null;default:true"`
-	CreatedAt            time.Time                 `json:"created_at"`
-	UpdatedAt            time.Time                 `json:"updated_at"`
+	// CredentialsUpdatedAt is when the credentials were last set, and
+	// CredentialsExpireAt when the owner said they expire, if they do.
+	CredentialsUpdatedAt time.Time  `json:"credentials_updated_at"`
+	CredentialsExpireAt  *time.Time `json:"credentials_expire_at,omitempty"`
+	// LastCheckedAt and LastCheckError record the last validation of the
+	// credentials against the tracker; the error is empty if it succeeded.
+	LastCheckedAt  *time.Time `json:"last_checked_at,omitempty"`
+	LastCheckError string     `json:"last_check_error,omitempty" gorm:"type:varchar(1000);not null;default:''"`
+	// NotifiedCredentialStatus is the status the owner was last warned
+	// about, so each problem is reported once.
+	NotifiedCredentialStatus CredentialStatus `json:"-" gorm:"type:varchar(20);not null;default:''"`
+	CreatedAt                time.Time        `json:"created_at"`
+	UpdatedAt                time.Time        `json:"updated_at"`
 }
 
 func (i *Integration) BeforeCreate(tx *gorm.DB) error {
 	if i.ID == uuid.Nil {
 		i.ID = uuid.New()
 	}
+	if i.CredentialsUpdatedAt.IsZero() {
+		i.CredentialsUpdatedAt = time.Now()
+	}
 	return nil
 }
 
@@ -85,6 +85,24 @@ func (s *MySQLStore) ListIntegrationsByUser(ctx context.Context, userID uuid.UUI
 	return integrations, nil
 }
 
+// ListActiveIntegrations retrieves all active integrations.
+func (s *MySQLStore) ListActiveIntegrations(ctx context.Context) ([]*Integration, error) {
+	var integrations []*Integration
+	err := s.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("created_at ASC").
+		Find(&integrations).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list active integrations", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return integrations, nil
+}
+
 // UpdateIntegration updates an integration with the given setters.
 func (s *MySQLStore) UpdateIntegration(ctx context.Context, id uuid.UUID, setters ...IntegrationSetter) error {
 	integ, err := s.GetIntegrationByID(ctx, id)
@@ -198,6 +216,25 @@ func (s *MySQLStore) ListIssueLinksByTestRun(ctx context.Context, testRunID uuid
 	return links, nil
 }
 
+// ListIssueLinksByExternalID retrieves all links to an issue of an integration.
+func (s *MySQLStore) ListIssueLinksByExternalID(ctx context.Context, integrationID uuid.UUID, externalID string) ([]*IssueLink, error) {
+	var links []*IssueLink
+	err := s.db.WithContext(ctx).
+		Where("integration_id = ? AND external_id = ?", integrationID, externalID).
+		Find(&links).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list issue links by external ID", map[string]interface{}{
+			"error":          err.Error(),
+			"integration_id": integrationID.String(),
+			"external_id":    externalID,
+		})
+		return nil, err
+	}
+
+	return links, nil
+}
+
 // UpdateIssueLink updates an issue link with the given setters.
 func (s *MySQLStore) UpdateIssueLink(ctx context.Context, id uuid.UUID, setters ...IssueLinkSetter) error {
 	link, err := s.GetIssueLinkByID(ctx, id)
@@ -0,0 +1,41 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// RekeyCredentials re-encrypts the credentials of every integration from
+// oldKey to newKey and returns how many were re-encrypted. Credentials
+// already encrypted with newKey are left alone. It stops at the first value
+// that decrypts with neither key; run it in a transaction so that nothing is
+// left half rotated.
+func RekeyCredentials(ctx context.Context, db *gorm.DB, oldKey, newKey []byte) (int, error) {
+	var integrations []Integration
+	if err := db.WithContext(ctx).Select("id", "encrypted_credentials").Find(&integrations).Error; err != nil {
+		return 0, fmt.Errorf("failed to list integrations: %w", err)
+	}
+
+	count := 0
+	for _, integ := range integrations {
+		encrypted, changed, err := Reencrypt(oldKey, newKey, integ.EncryptedCredentials)
+		if err != nil {
+			return 0, fmt.Errorf("integration %s: %w", integ.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		// UpdateColumn leaves updated_at and credentials_updated_at alone;
+		// the credentials themselves have not changed.
+		err = db.WithContext(ctx).Model(&Integration{}).
+			Where("id = ?", integ.ID).
+			UpdateColumn("encrypted_credentials", encrypted).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to update integration %s: %w", integ.ID, err)
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRekeyCredentials(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Integration{})
+	store := NewMySQLStore(db, logger.NewTestLogger())
+	oldKey := DeriveKey("old-passphrase")
+	newKey := DeriveKey("new-passphrase")
+
+	var ids []uuid.UUID
+	for _, token := range []string{"ghp_one", "ghp_two"} {
+		encrypted, err := EncryptCredentials(oldKey, map[string]string{"token": token})
+		require.NoError(t, err)
+		integ := &Integration{
+			UserID:               uuid.New(),
+			Name:                 "GitHub",
+			Provider:             issuetracker.ProviderGitHub,
+			EncryptedCredentials: encrypted,
+			IsActive:             true,
+		}
+		require.NoError(t, store.CreateIntegration(ctx, integ))
+		ids = append(ids, integ.ID)
+	}
+
+	t.Run("wrong old key changes nothing", func(t *testing.T) {
+		_, err := RekeyCredentials(ctx, db, DeriveKey("wrong"), newKey)
+		assert.Error(t, err)
+
+		integ, err := store.GetIntegrationByID(ctx, ids[0])
+		require.NoError(t, err)
+		_, err = DecryptCredentials(oldKey, integ.EncryptedCredentials)
+		assert.NoError(t, err)
+	})
+
+	t.Run("re-encrypts every integration", func(t *testing.T) {
+		before, err := store.GetIntegrationByID(ctx, ids[0])
+		require.NoError(t, err)
+
+		count, err := RekeyCredentials(ctx, db, oldKey, newKey)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		for i, token := range []string{"ghp_one", "ghp_two"} {
+			integ, err := store.GetIntegrationByID(ctx, ids[i])
+			require.NoError(t, err)
+			creds, err := DecryptCredentials(newKey, integ.EncryptedCredentials)
+			require.NoError(t, err)
+			assert.Equal(t, token, creds["token"])
+		}
+
+		after, err := store.GetIntegrationByID(ctx, ids[0])
+		require.NoError(t, err)
+		assert.True(t, before.CredentialsUpdatedAt.Equal(after.CredentialsUpdatedAt))
+	})
+
+	t.Run("skips credentials already re-encrypted", func(t *testing.T) {
+		count, err := RekeyCredentials(ctx, db, oldKey, newKey)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
@@ -1,5 +1,13 @@
 package integration
 
+import (
+	"strings"
+	"time"
+)
+
+// maxCheckErrorLength is the size of the last_check_error column.
+const maxCheckErrorLength = 1000
+
 // SetName returns an IntegrationSetter that sets the integration's name.
 func SetName(name string) IntegrationSetter {
 	return func(i *Integration) error {
@@ -20,9 +28,49 @@ func SetIsActive(isActive bool) IntegrationSetter {
 }
 
 // SetEncryptedCredentials returns an IntegrationSetter that sets the encrypted credentials.
+// The new credentials are unchecked until they are validated again.
 func SetEncryptedCredentials(creds []byte) IntegrationSetter {
 	return func(i *Integration) error {
 		i.EncryptedCredentials = creds
+		i.CredentialsUpdatedAt = time.Now()
+		i.LastCheckedAt = nil
+		i.LastCheckError = ""
+		i.NotifiedCredentialStatus = ""
+		return nil
+	}
+}
+
+// SetCredentialsExpireAt returns an IntegrationSetter that sets when the
+// credentials expire. A nil time means they do not expire.
+func SetCredentialsExpireAt(expireAt *time.Time) IntegrationSetter {
+	return func(i *Integration) error {
+		i.CredentialsExpireAt = expireAt
+		return nil
+	}
+}
+
+// SetCheckResult returns an IntegrationSetter that records a validation of
+// the credentials at the given time. A nil error means it succeeded.
+func SetCheckResult(at time.Time, checkErr error) IntegrationSetter {
+	return func(i *Integration) error {
+		i.LastCheckedAt = &at
+		i.LastCheckError = ""
+		if checkErr != nil {
+			msg := checkErr.Error()
+			if len(msg) > maxCheckErrorLength {
+				msg = strings.ToValidUTF8(msg[:maxCheckErrorLength], "")
+			}
+			i.LastCheckError = msg
+		}
+		return nil
+	}
+}
+
+// SetNotifiedCredentialStatus returns an IntegrationSetter that records the
+// credential status the owner was last warned about.
+func SetNotifiedCredentialStatus(status CredentialStatus) IntegrationSetter {
+	return func(i *Integration) error {
+		i.NotifiedCredentialStatus = status
 		return nil
 	}
 }
@@ -17,6 +17,9 @@ type Store interface {
 	// ListIntegrationsByUser retrieves all integrations for a user.
 	ListIntegrationsByUser(ctx context.Context, userID uuid.UUID) ([]*Integration, error)
 
+	// ListActiveIntegrations retrieves all active integrations.
+	ListActiveIntegrations(ctx context.Context) ([]*Integration, error)
+
 	// UpdateIntegration updates an integration with the given setters.
 	UpdateIntegration(ctx context.Context, id uuid.UUID, setters ...IntegrationSetter) error
 
@@ -32,6 +35,10 @@ type Store interface {
 	// ListIssueLinksByTestRun retrieves all issue links for a test run.
 	ListIssueLinksByTestRun(ctx context.Context, testRunID uuid.UUID) ([]*IssueLink, error)
 
+	// ListIssueLinksByExternalID retrieves all links to an issue of an
+	// integration.
+	ListIssueLinksByExternalID(ctx context.Context, integrationID uuid.UUID, externalID string) ([]*IssueLink, error)
+
 	// UpdateIssueLink updates an issue link with the given setters.
 	UpdateIssueLink(ctx context.Context, id uuid.UUID, setters ...IssueLinkSetter) error
 
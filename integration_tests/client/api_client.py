@@ -29,8 +29,17 @@ class UIAutomationClient:
     def _url(self, path: str) -> str:
         return f"{self.base_url}/api/v1{path}"
 
+    def _csrf_headers(self, kwargs: dict) -> dict:
+        """Echo the CSRF cookie of the session, as browsers must."""
+        headers = dict(kwargs.pop("headers", None) or {})
+        token = self.session.cookies.get("csrf_token")
+        if token is not None:
+            headers.setdefault("X-CSRF-Token", token)
+        return headers
+
     def _request(self, method: str, path: str, **kwargs) -> dict | list:
-        resp = self.session.request(method, self._url(path), **kwargs)
+        headers = self._csrf_headers(kwargs)
+        resp = self.session.request(method, self._url(path), headers=headers, **kwargs)
         if not resp.ok:
             try:
                 body = resp.json()
@@ -42,7 +51,8 @@ class UIAutomationClient:
         return resp.json()
 
     def _raw_request(self, method: str, path: str, **kwargs) -> requests.Response:
-        resp = self.session.request(method, self._url(path), **kwargs)
+        headers = self._csrf_headers(kwargs)
+        resp = self.session.request(method, self._url(path), headers=headers, **kwargs)
         if not resp.ok:
             try:
                 body = resp.json()
@@ -95,6 +105,13 @@ class UIAutomationClient:
     def delete_project(self, project_id: str) -> dict:
         return self._request("DELETE", f"/projects/{project_id}")
 
+    def export_project(self, project_id: str) -> dict:
+        return self._request("GET", f"/projects/{project_id}/export")
+
+    def import_project(self, archive: dict, name: str | None = None) -> dict:
+        params = {"name": name} if name else None
+        return self._request("POST", "/projects/import", json=archive, params=params)
+
     # --- Test Procedures ---
 
     def create_procedure(
@@ -111,6 +128,20 @@ class UIAutomationClient:
             "POST", f"/projects/{project_id}/procedures", json=payload,
         )
 
+    def generate_procedure(
+        self,
+        project_id: str,
+        description: str,
+        name: str = "",
+        endpoint_id: str | None = None,
+    ) -> dict:
+        payload: dict = {"description": description, "name": name}
+        if endpoint_id is not None:
+            payload["endpoint_id"] = endpoint_id
+        return self._request(
+            "POST", f"/projects/{project_id}/procedures/generate", json=payload,
+        )
+
     def list_procedures(
         self, project_id: str, limit: int = 20, offset: int = 0,
     ) -> dict:
@@ -146,10 +177,72 @@ class UIAutomationClient:
             f"/projects/{project_id}/procedures/{procedure_id}/versions",
         )
 
+    def suggest_steps(
+        self,
+        procedure_id: str,
+        image_path: str | None = None,
+        endpoint_id: str | None = None,
+        path: str = "",
+        hint: str = "",
+    ) -> dict:
+        url = f"/procedures/{procedure_id}/steps/suggest"
+        if image_path is not None:
+            with open(image_path, "rb") as f:
+                files = {"image": (os.path.basename(image_path), f)}
+                data = {"hint": hint} if hint else {}
+                return self._request("POST", url, files=files, data=data)
+        payload: dict = {"path": path, "hint": hint}
+        if endpoint_id is not None:
+            payload["endpoint_id"] = endpoint_id
+        return self._request("POST", url, json=payload)
+
+    # --- Saved Views ---
+
+    def create_saved_view(
+        self,
+        project_id: str,
+        name: str,
+        resource: str,
+        filters: dict | None = None,
+        shared: bool = False,
+    ) -> dict:
+        payload: dict = {"name": name, "resource": resource, "shared": shared}
+        if filters is not None:
+            payload["filters"] = filters
+        return self._request(
+            "POST", f"/projects/{project_id}/views", json=payload,
+        )
+
+    def list_saved_views(
+        self, project_id: str, resource: str | None = None,
+    ) -> dict:
+        params = {"resource": resource} if resource else None
+        return self._request(
+            "GET", f"/projects/{project_id}/views", params=params,
+        )
+
+    def get_saved_view(self, project_id: str, view_id: str) -> dict:
+        return self._request("GET", f"/projects/{project_id}/views/{view_id}")
+
+    def update_saved_view(
+        self, project_id: str, view_id: str, **fields,
+    ) -> dict:
+        return self._request(
+            "PUT", f"/projects/{project_id}/views/{view_id}", json=fields,
+        )
+
+    def delete_saved_view(self, project_id: str, view_id: str) -> dict:
+        return self._request(
+            "DELETE", f"/projects/{project_id}/views/{view_id}",
+        )
+
     # --- Test Runs ---
 
-    def create_run(self, procedure_id: str) -> dict:
-        return self._request("POST", f"/procedures/{procedure_id}/runs")
+    def create_run(self, procedure_id: str, **target) -> dict:
+        """Create a run, optionally targeting endpoint_id or endpoint_group + environment."""
+        return self._request(
+            "POST", f"/procedures/{procedure_id}/runs", json=target or None,
+        )
 
     def list_runs(
         self, procedure_id: str, limit: int = 20, offset: int = 0,
@@ -174,6 +267,9 @@ class UIAutomationClient:
             payload["notes"] = notes
         return self._request("POST", f"/runs/{run_id}/complete", json=payload)
 
+    def get_run_procedure(self, run_id: str) -> dict:
+        return self._request("GET", f"/runs/{run_id}/procedure")
+
     # --- Assets ---
 
     def upload_asset(
@@ -205,6 +301,23 @@ class UIAutomationClient:
     def delete_asset(self, run_id: str, asset_id: str) -> dict:
         return self._request("DELETE", f"/runs/{run_id}/assets/{asset_id}")
 
+    def download_guide(
+        self,
+        run_id: str,
+        narrate: bool = False,
+        tone: str = "",
+        language: str = "",
+    ) -> bytes:
+        params: dict = {}
+        if narrate:
+            params["narrate"] = "true"
+        if tone:
+            params["tone"] = tone
+        if language:
+            params["language"] = language
+        resp = self._raw_request("GET", f"/runs/{run_id}/guide", params=params)
+        return resp.content
+
     # --- Users ---
 
     def list_users(self, search: str = "", limit: int = 20, offset: int = 0) -> dict:
@@ -226,10 +339,14 @@ class UIAutomationClient:
         name: str,
         url: str,
         credentials: list[dict] | None = None,
+        max_concurrent_jobs: int | None = None,
+        **fields,
     ) -> dict:
-        payload: dict = {"name": name, "url": url}
+        payload: dict = {"name": name, "url": url, **fields}
         if credentials is not None:
             payload["credentials"] = credentials
+        if max_concurrent_jobs is not None:
+            payload["max_concurrent_jobs"] = max_concurrent_jobs
         return self._request("POST", "/endpoints", json=payload)
 
     def list_endpoints(self, limit: int = 20, offset: int = 0) -> dict:
@@ -249,6 +366,26 @@ class UIAutomationClient:
     def delete_endpoint(self, endpoint_id: str) -> dict:
         return self._request("DELETE", f"/endpoints/{endpoint_id}")
 
+    def get_endpoint_health(self, endpoint_id: str) -> dict:
+        return self._request("GET", f"/endpoints/{endpoint_id}/health")
+
+    def check_endpoint_health(self, endpoint_id: str) -> dict:
+        return self._request("POST", f"/endpoints/{endpoint_id}/health/check")
+
+    def list_endpoint_groups(self) -> dict:
+        return self._request("GET", "/endpoint-groups")
+
+    def list_endpoint_secrets(self, endpoint_id: str) -> dict:
+        return self._request("GET", f"/endpoints/{endpoint_id}/secrets")
+
+    def set_endpoint_secret(self, endpoint_id: str, key: str, value: str) -> dict:
+        return self._request(
+            "PUT", f"/endpoints/{endpoint_id}/secrets/{key}", json={"value": value},
+        )
+
+    def delete_endpoint_secret(self, endpoint_id: str, key: str) -> dict:
+        return self._request("DELETE", f"/endpoints/{endpoint_id}/secrets/{key}")
+
     # --- Jobs ---
 
     def create_job(
@@ -273,6 +410,130 @@ class UIAutomationClient:
     def stop_job(self, job_id: str) -> dict:
         return self._request("POST", f"/jobs/{job_id}/stop")
 
+    def get_job_logs(self, job_id: str, after: int = 0, limit: int | None = None) -> dict:
+        params: dict = {"after": after}
+        if limit is not None:
+            params["limit"] = limit
+        return self._request("GET", f"/jobs/{job_id}/logs", params=params)
+
+    def convert_job_to_procedures(self, job_id: str, project_id: str) -> dict:
+        return self._request("POST", f"/jobs/{job_id}/convert-to-procedures", json={
+            "project_id": project_id,
+        })
+
+    # --- Agent Runners ---
+
+    def register_runner(
+        self, name: str, labels: list[str] | None = None, version: str = "",
+    ) -> dict:
+        return self._request("POST", "/runner/runners", json={
+            "name": name,
+            "labels": labels or [],
+            "version": version,
+        })
+
+    def list_runners(self) -> list:
+        return self._request("GET", "/runner/runners")
+
+    def ping_runner(self, runner_id: str) -> dict:
+        return self._request("POST", f"/runner/runners/{runner_id}/heartbeat")
+
+    def deregister_runner(self, runner_id: str) -> dict:
+        return self._request("DELETE", f"/runner/runners/{runner_id}")
+
+    def claim_runner_job(self, runner_id: str | None = None) -> dict:
+        if runner_id is None:
+            return self._request("POST", "/runner/jobs/claim")
+        return self._request("POST", "/runner/jobs/claim", json={"runner_id": runner_id})
+
+    def send_runner_heartbeat(
+        self,
+        job_id: str,
+        progress_done: int | None = None,
+        progress_total: int | None = None,
+    ) -> dict:
+        payload: dict = {}
+        if progress_done is not None and progress_total is not None:
+            payload = {"progress_done": progress_done, "progress_total": progress_total}
+        return self._request("POST", f"/runner/jobs/{job_id}/heartbeat", json=payload)
+
+    def append_runner_logs(self, job_id: str, lines: list[str]) -> dict:
+        return self._request("POST", f"/runner/jobs/{job_id}/logs", json={"lines": lines})
+
+    def fail_runner_job(self, job_id: str, error: str) -> dict:
+        return self._request("POST", f"/runner/jobs/{job_id}/fail", json={"error": error})
+
+    # --- Visual Regression ---
+
+    def approve_baselines(self, job_id: str, pages: list[str] | None = None) -> dict:
+        return self._request("POST", f"/jobs/{job_id}/baselines", json={
+            "pages": pages or [],
+        })
+
+    def get_job_page_image(self, job_id: str, page: str, kind: str) -> requests.Response:
+        return self._raw_request("GET", f"/jobs/{job_id}/pages/{page}/{kind}")
+
+    def list_baselines(self, project_id: str, endpoint_id: str | None = None) -> dict:
+        params = {"endpoint_id": endpoint_id} if endpoint_id else None
+        return self._request("GET", f"/projects/{project_id}/baselines", params=params)
+
+    def get_baseline_image(self, project_id: str, baseline_id: str) -> requests.Response:
+        return self._raw_request("GET", f"/projects/{project_id}/baselines/{baseline_id}/image")
+
+    def delete_baseline(self, project_id: str, baseline_id: str) -> dict:
+        return self._request("DELETE", f"/projects/{project_id}/baselines/{baseline_id}")
+
+    # --- Link Checks ---
+
+    def export_link_report(self, job_id: str) -> requests.Response:
+        return self._raw_request("GET", f"/jobs/{job_id}/link-report")
+
+    def attach_link_report(self, run_id: str, job_id: str) -> dict:
+        return self._request("POST", f"/runs/{run_id}/link-reports", json={
+            "job_id": job_id,
+        })
+
+    # --- Usage ---
+
+    def get_usage_report(self, month: str | None = None) -> dict:
+        params = {"month": month} if month else None
+        return self._request("GET", "/usage", params=params)
+
+    def export_usage_report(
+        self, month: str | None = None, fmt: str = "csv",
+    ) -> requests.Response:
+        params: dict = {"format": fmt}
+        if month:
+            params["month"] = month
+        return self._raw_request("GET", "/usage/export", params=params)
+
+    def get_llm_usage_report(self, month: str | None = None) -> dict:
+        params = {"month": month} if month else None
+        return self._request("GET", "/usage/llm", params=params)
+
+    def set_llm_budget(self, monthly_limit_usd: float) -> dict:
+        return self._request(
+            "PUT", "/usage/llm/budget",
+            json={"monthly_limit_usd": monthly_limit_usd},
+        )
+
+    def delete_llm_budget(self) -> dict:
+        return self._request("DELETE", "/usage/llm/budget")
+
+    def get_project_llm_budget(self, project_id: str) -> dict:
+        return self._request("GET", f"/projects/{project_id}/llm-budget")
+
+    def set_project_llm_budget(
+        self, project_id: str, monthly_limit_usd: float,
+    ) -> dict:
+        return self._request(
+            "PUT", f"/projects/{project_id}/llm-budget",
+            json={"monthly_limit_usd": monthly_limit_usd},
+        )
+
+    def delete_project_llm_budget(self, project_id: str) -> dict:
+        return self._request("DELETE", f"/projects/{project_id}/llm-budget")
+
     # --- API Tokens ---
 
     def create_api_token(
@@ -324,6 +585,9 @@ class UIAutomationClient:
     def test_integration_connection(self, integration_id: str) -> dict:
         return self._request("POST", f"/integrations/{integration_id}/test")
 
+    def get_integration_health(self, integration_id: str) -> dict:
+        return self._request("GET", f"/integrations/{integration_id}/health")
+
     def search_external_issues(
         self,
         integration_id: str,
@@ -398,6 +662,11 @@ class UIAutomationClient:
             "POST", f"/runs/{run_id}/issues/{link_id}/sync",
         )
 
+    # --- Provider Health ---
+
+    def get_provider_health(self) -> dict:
+        return self._request("GET", "/health/providers")
+
     def request_with_token(self, method: str, path: str, token: str, **kwargs) -> dict:
         """Make an API request using a Bearer token instead of session cookies."""
         headers = {"Authorization": f"Bearer {token}"}
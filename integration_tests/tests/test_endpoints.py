@@ -34,6 +34,7 @@ class TestCreateEndpoint:
         # Should have default credentials
         assert isinstance(resp["credentials"], list)
         assert len(resp["credentials"]) >= 2
+        assert resp["max_concurrent_jobs"] == 1
         # Clean up
         authenticated_client.delete_endpoint(resp["id"])
 
@@ -58,6 +59,31 @@ class TestCreateEndpoint:
         # Clean up
         authenticated_client.delete_endpoint(resp["id"])
 
+    def test_create_endpoint_with_max_concurrent_jobs(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        resp = authenticated_client.create_endpoint(
+            name="Limited Endpoint",
+            url="https://example.com",
+            max_concurrent_jobs=3,
+        )
+        assert resp["max_concurrent_jobs"] == 3
+        # Clean up
+        authenticated_client.delete_endpoint(resp["id"])
+
+    def test_create_endpoint_negative_max_concurrent_jobs(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_endpoint(
+                name="Bad Limit",
+                url="https://example.com",
+                max_concurrent_jobs=-1,
+            )
+        assert exc_info.value.status_code == 400
+
     def test_create_endpoint_missing_name(
         self,
         authenticated_client: UIAutomationClient,
@@ -163,6 +189,27 @@ class TestUpdateEndpoint:
         assert len(resp["credentials"]) == 1
         assert resp["credentials"][0]["key"] == "token"
 
+    def test_update_endpoint_max_concurrent_jobs(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        resp = authenticated_client.update_endpoint(
+            endpoint["id"], max_concurrent_jobs=2,
+        )
+        assert resp["max_concurrent_jobs"] == 2
+
+    def test_update_endpoint_zero_max_concurrent_jobs(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.update_endpoint(
+                endpoint["id"], max_concurrent_jobs=0,
+            )
+        assert exc_info.value.status_code == 400
+
 
 class TestDeleteEndpoint:
     def test_delete_endpoint(
@@ -210,3 +257,213 @@ class TestEndpointOwnership:
         with pytest.raises(APIError) as exc_info:
             second_authenticated_client.delete_endpoint(endpoint["id"])
         assert exc_info.value.status_code == 403
+
+
+class TestEndpointHealth:
+    def test_new_endpoint_health_is_unknown(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        resp = authenticated_client.get_endpoint_health(endpoint["id"])
+        assert resp["endpoint_id"] == endpoint["id"]
+        assert resp["status"] == "unknown"
+        assert resp["health_check_enabled"] is False
+        assert resp["last_checked_at"] is None
+        for window in ("24h", "7d", "30d"):
+            assert resp["uptime"][window]["checks"] == 0
+        assert resp["recent"] == []
+
+    def test_create_endpoint_with_health_check(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        resp = authenticated_client.create_endpoint(
+            name="Monitored Endpoint",
+            url="https://example.com",
+            health_check_enabled=True,
+            health_check_interval_seconds=60,
+            health_check_expected_status=200,
+            health_check_expected_text="Example",
+        )
+        assert resp["health_check_enabled"] is True
+        assert resp["health_check_interval_seconds"] == 60
+        assert resp["health_check_expected_text"] == "Example"
+        authenticated_client.delete_endpoint(resp["id"])
+
+    def test_invalid_health_check_interval(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.update_endpoint(
+                endpoint["id"], health_check_interval_seconds=1,
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_check_now_records_result(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        check = authenticated_client.check_endpoint_health(endpoint["id"])
+        assert check["endpoint_id"] == endpoint["id"]
+        assert isinstance(check["up"], bool)
+
+        resp = authenticated_client.get_endpoint_health(endpoint["id"])
+        assert resp["status"] in ("up", "down")
+        assert resp["last_checked_at"] is not None
+        assert resp["uptime"]["24h"]["checks"] == 1
+        assert len(resp["recent"]) == 1
+        assert resp["recent"][0]["id"] == check["id"]
+
+    def test_other_user_cannot_view_health(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.get_endpoint_health(endpoint["id"])
+        assert exc_info.value.status_code == 403
+
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.check_endpoint_health(endpoint["id"])
+        assert exc_info.value.status_code == 403
+
+
+class TestEndpointSecrets:
+    def test_set_and_list_secrets_hides_values(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        resp = authenticated_client.set_endpoint_secret(
+            endpoint["id"], "TEST_PASSWORD", "hunter2",
+        )
+        assert resp["key"] == "TEST_PASSWORD"
+        assert resp["placeholder"] == "{{TEST_PASSWORD}}"
+        assert "value" not in resp
+
+        listed = authenticated_client.list_endpoint_secrets(endpoint["id"])
+        assert listed["total"] == 1
+        assert listed["items"][0]["key"] == "TEST_PASSWORD"
+        assert "hunter2" not in str(listed)
+
+    def test_set_existing_secret_replaces_it(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        first = authenticated_client.set_endpoint_secret(endpoint["id"], "API_KEY", "old")
+        second = authenticated_client.set_endpoint_secret(endpoint["id"], "API_KEY", "new")
+        assert first["id"] == second["id"]
+        assert authenticated_client.list_endpoint_secrets(endpoint["id"])["total"] == 1
+
+    def test_invalid_secret_key(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.set_endpoint_secret(endpoint["id"], "lower-case", "value")
+        assert exc_info.value.status_code == 400
+
+    def test_empty_secret_value(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.set_endpoint_secret(endpoint["id"], "EMPTY", "")
+        assert exc_info.value.status_code == 400
+
+    def test_delete_secret(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        authenticated_client.set_endpoint_secret(endpoint["id"], "TOKEN", "value")
+        authenticated_client.delete_endpoint_secret(endpoint["id"], "TOKEN")
+        assert authenticated_client.list_endpoint_secrets(endpoint["id"])["total"] == 0
+
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.delete_endpoint_secret(endpoint["id"], "TOKEN")
+        assert exc_info.value.status_code == 404
+
+    def test_other_user_cannot_access_secrets(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.list_endpoint_secrets(endpoint["id"])
+        assert exc_info.value.status_code == 403
+
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.set_endpoint_secret(endpoint["id"], "STOLEN", "x")
+        assert exc_info.value.status_code == 403
+
+
+class TestEndpointGroups:
+    def test_group_lists_environments(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        created = [
+            authenticated_client.create_endpoint(
+                name=f"Grouped {env}", url=f"https://{env}.example.com",
+                group="grouped-app", environment=env,
+            )
+            for env in ("staging", "dev")
+        ]
+        try:
+            assert created[0]["group"] == "grouped-app"
+            assert created[0]["environment"] == "staging"
+
+            resp = authenticated_client.list_endpoint_groups()
+            group = next(g for g in resp["items"] if g["group"] == "grouped-app")
+            assert [e["environment"] for e in group["environments"]] == ["dev", "staging"]
+            assert group["environments"][0]["url"] == "https://dev.example.com"
+        finally:
+            for ep in created:
+                authenticated_client.delete_endpoint(ep["id"])
+
+    def test_duplicate_environment_in_group(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        ep = authenticated_client.create_endpoint(
+            name="Dup A", url="https://a.example.com",
+            group="dup-app", environment="prod",
+        )
+        try:
+            with pytest.raises(APIError) as exc_info:
+                authenticated_client.create_endpoint(
+                    name="Dup B", url="https://b.example.com",
+                    group="dup-app", environment="prod",
+                )
+            assert exc_info.value.status_code == 409
+        finally:
+            authenticated_client.delete_endpoint(ep["id"])
+
+    def test_group_requires_environment(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_endpoint(
+                name="No Env", url="https://example.com", group="no-env-app",
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_invalid_environment(
+        self,
+        authenticated_client: UIAutomationClient,
+        endpoint: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.update_endpoint(endpoint["id"], environment="Prod Env")
+        assert exc_info.value.status_code == 400
@@ -151,6 +151,39 @@ class TestDeleteIntegration:
         assert exc_info.value.status_code == 404
 
 
+class TestIntegrationHealth:
+    def test_health_for_unused_integration_is_closed(
+        self, authenticated_client: UIAutomationClient,
+    ):
+        created = authenticated_client.create_integration(
+            name="Health Test",
+            provider="github",
+            credentials=[{"key": "token", "value": "test"}],
+        )
+        resp = authenticated_client.get_integration_health(created["id"])
+        assert resp["name"] == created["id"]
+        assert resp["state"] == "closed"
+        authenticated_client.delete_integration(created["id"])
+
+    def test_health_not_found(self, authenticated_client: UIAutomationClient):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.get_integration_health(str(uuid.uuid4()))
+        assert exc_info.value.status_code == 404
+
+    def test_provider_health(self, authenticated_client: UIAutomationClient):
+        resp = authenticated_client.get_provider_health()
+        names = [p["name"] for p in resp["items"]]
+        assert "llm" in names
+        assert "playwright_mcp" in names
+        for provider in resp["items"]:
+            assert provider["state"] in ("closed", "open", "half_open")
+
+    def test_provider_health_unauthenticated(self, fresh_client: UIAutomationClient):
+        with pytest.raises(APIError) as exc_info:
+            fresh_client.get_provider_health()
+        assert exc_info.value.status_code == 401
+
+
 class TestIssueLinks:
     """Test issue link operations. Note: creating/resolving actual external issues
     requires real provider credentials, so we test the link/unlink flow using
@@ -1,4 +1,5 @@
 import time
+import uuid
 
 import pytest
 
@@ -56,6 +57,43 @@ class TestCreateJob:
         assert resp["config"]["endpoint_id"] == endpoint_for_jobs["id"]
         assert resp["config"]["project_id"] == project_for_jobs["id"]
 
+    def test_create_job_by_group_environment(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+    ):
+        ep = authenticated_client.create_endpoint(
+            name="Job Group Staging",
+            url="https://staging.example.com",
+            group="job-group",
+            environment="staging",
+        )
+        try:
+            resp = authenticated_client.create_job(
+                job_type="ui_exploration",
+                config={
+                    "endpoint_group": "job-group",
+                    "environment": "staging",
+                    "project_id": project_for_jobs["id"],
+                },
+            )
+            assert resp["endpoint_id"] == ep["id"]
+            assert resp["config"]["endpoint_id"] == ep["id"]
+            assert resp["config"]["environment"] == "staging"
+
+            with pytest.raises(APIError) as exc_info:
+                authenticated_client.create_job(
+                    job_type="ui_exploration",
+                    config={
+                        "endpoint_group": "job-group",
+                        "environment": "prod",
+                        "project_id": project_for_jobs["id"],
+                    },
+                )
+            assert exc_info.value.status_code == 404
+        finally:
+            authenticated_client.delete_endpoint(ep["id"])
+
     def test_create_job_invalid_type(
         self,
         authenticated_client: UIAutomationClient,
@@ -103,6 +141,97 @@ class TestCreateJob:
         assert exc_info.value.status_code == 401
 
 
+class TestCreateProcedureExecutionJob:
+    def _config(self, project: dict, endpoint: dict, procedure_id: str) -> dict:
+        return {
+            "endpoint_id": endpoint["id"],
+            "project_id": project["id"],
+            "procedure_id": procedure_id,
+        }
+
+    def test_create_job(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        procedure = authenticated_client.create_procedure(
+            project_id=project_for_jobs["id"],
+            name="Executed Procedure",
+            steps=[{"name": "Open home", "instructions": "Open the home page"}],
+        )
+        resp = authenticated_client.create_job(
+            job_type="procedure_execution",
+            config=self._config(project_for_jobs, endpoint_for_jobs, procedure["id"]),
+        )
+        assert resp["type"] == "procedure_execution"
+        assert resp["config"]["procedure_id"] == procedure["id"]
+
+    def test_missing_procedure_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        config = self._config(project_for_jobs, endpoint_for_jobs, "")
+        del config["procedure_id"]
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_job(job_type="procedure_execution", config=config)
+        assert exc_info.value.status_code == 400
+
+    def test_unknown_procedure_returns_404(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_job(
+                job_type="procedure_execution",
+                config=self._config(project_for_jobs, endpoint_for_jobs, str(uuid.uuid4())),
+            )
+        assert exc_info.value.status_code == 404
+
+    def test_procedure_of_other_project_returns_404(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        other = authenticated_client.create_project(name="Other Job Project")
+        try:
+            procedure = authenticated_client.create_procedure(
+                project_id=other["id"],
+                name="Other Procedure",
+                steps=[{"name": "Open home", "instructions": "Open the home page"}],
+            )
+            with pytest.raises(APIError) as exc_info:
+                authenticated_client.create_job(
+                    job_type="procedure_execution",
+                    config=self._config(project_for_jobs, endpoint_for_jobs, procedure["id"]),
+                )
+            assert exc_info.value.status_code == 404
+        finally:
+            authenticated_client.delete_project(other["id"])
+
+    def test_procedure_without_steps_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        procedure = authenticated_client.create_procedure(
+            project_id=project_for_jobs["id"],
+            name="Empty Procedure",
+        )
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_job(
+                job_type="procedure_execution",
+                config=self._config(project_for_jobs, endpoint_for_jobs, procedure["id"]),
+            )
+        assert exc_info.value.status_code == 400
+
+
 class TestListJobs:
     def test_list_jobs(
         self,
@@ -184,6 +313,127 @@ class TestStopJob:
         assert exc_info.value.status_code == 400
 
 
+class TestJobLogs:
+    def test_get_job_logs(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": endpoint_for_jobs["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+        resp = authenticated_client.get_job_logs(job["id"])
+        assert isinstance(resp["items"], list)
+        assert resp["total"] == len(resp["items"])
+        assert resp["status"] in ("created", "running", "failed", "success", "stopped")
+        seqs = [entry["seq"] for entry in resp["items"]]
+        assert seqs == sorted(seqs)
+
+        # Lines after the last sequence number seen are never repeated.
+        last = seqs[-1] if seqs else 0
+        later = authenticated_client.get_job_logs(job["id"], after=last)
+        assert all(entry["seq"] > last for entry in later["items"])
+
+    def test_get_job_logs_invalid_after(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": endpoint_for_jobs["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.get_job_logs(job["id"], after=-1)
+        assert exc_info.value.status_code == 400
+
+    def test_other_user_cannot_read_job_logs(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": endpoint_for_jobs["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.get_job_logs(job["id"])
+        assert exc_info.value.status_code == 403
+
+
+class TestConvertJobToProcedures:
+    def test_convert_unfinished_job_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": endpoint_for_jobs["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+        # Job has not completed successfully, so it has no flows to convert
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.convert_job_to_procedures(
+                job["id"], project_for_jobs["id"],
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_convert_invalid_project_id(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": endpoint_for_jobs["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.convert_job_to_procedures(job["id"], "not-a-uuid")
+        assert exc_info.value.status_code == 400
+
+    def test_other_user_cannot_convert_job(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        endpoint_for_jobs: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": endpoint_for_jobs["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.convert_job_to_procedures(
+                job["id"], project_for_jobs["id"],
+            )
+        assert exc_info.value.status_code == 403
+
+
 class TestJobStatusTransition:
     def test_job_transitions_to_running_after_creation(
         self,
@@ -237,3 +487,171 @@ class TestJobOwnership:
         with pytest.raises(APIError) as exc_info:
             second_authenticated_client.get_job(job["id"])
         assert exc_info.value.status_code == 403
+
+
+class TestAgentRunner:
+    @pytest.fixture()
+    def runner_endpoint(self, authenticated_client: UIAutomationClient):
+        ep = authenticated_client.create_endpoint(
+            name="Runner Endpoint",
+            url="http://10.0.0.5",
+            use_runner=True,
+        )
+        yield ep
+        try:
+            authenticated_client.delete_endpoint(ep["id"])
+        except APIError:
+            pass
+
+    def test_runner_claims_and_reports_job(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        runner_endpoint: dict,
+    ):
+        assert runner_endpoint["use_runner"] is True
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": runner_endpoint["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+
+        claimed = authenticated_client.claim_runner_job()
+        assert claimed["job"]["id"] == job["id"]
+        assert claimed["job"]["status"] == "running"
+        assert claimed["agent_config"]["target_url"] == "http://10.0.0.5"
+        assert claimed["time_limit_seconds"] > 0
+
+        authenticated_client.append_runner_logs(job["id"], ["[progress] 1/4 pages"])
+        authenticated_client.send_runner_heartbeat(job["id"], progress_done=1, progress_total=4)
+        updated = authenticated_client.get_job(job["id"])
+        assert updated["progress_done"] == 1
+        assert updated["progress_total"] == 4
+
+        authenticated_client.fail_runner_job(job["id"], "playwright MCP unavailable")
+        failed = authenticated_client.get_job(job["id"])
+        assert failed["status"] == "failed"
+        logs = authenticated_client.get_job_logs(job["id"])
+        assert "[progress] 1/4 pages" in [line["message"] for line in logs["items"]]
+
+        # The job is no longer running, so the runner must abandon it.
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.send_runner_heartbeat(job["id"])
+        assert exc_info.value.status_code == 409
+
+    def test_runner_jobs_are_not_run_by_server_workers(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        runner_endpoint: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": runner_endpoint["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+        time.sleep(1)
+        assert authenticated_client.get_job(job["id"])["status"] == "created"
+
+    def test_other_job_types_rejected_on_runner_endpoint(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        runner_endpoint: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_job(
+                job_type="link_check",
+                config={
+                    "endpoint_id": runner_endpoint["id"],
+                    "project_id": project_for_jobs["id"],
+                },
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_other_user_cannot_report_on_job(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+        runner_endpoint: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": runner_endpoint["id"],
+                "project_id": project_for_jobs["id"],
+            },
+        )
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.fail_runner_job(job["id"], "not mine")
+        assert exc_info.value.status_code == 403
+
+    def test_runner_registration_lifecycle(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+    ):
+        runner = authenticated_client.register_runner(
+            "office-lab", labels=["windows", "needs-vpn"], version="1.2.0",
+        )
+        assert runner["labels"] == ["needs-vpn", "windows"]
+        assert runner["online"] is True
+
+        listed = authenticated_client.list_runners()
+        assert runner["id"] in [r["id"] for r in listed]
+        assert runner["id"] not in [r["id"] for r in second_authenticated_client.list_runners()]
+
+        authenticated_client.ping_runner(runner["id"])
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.ping_runner(runner["id"])
+        assert exc_info.value.status_code == 404
+
+        authenticated_client.deregister_runner(runner["id"])
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.ping_runner(runner["id"])
+        assert exc_info.value.status_code == 404
+
+    def test_invalid_runner_label_rejected(self, authenticated_client: UIAutomationClient):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.register_runner("office-lab", labels=["Needs VPN"])
+        assert exc_info.value.status_code == 400
+
+    def test_jobs_routed_by_runner_labels(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_for_jobs: dict,
+    ):
+        ep = authenticated_client.create_endpoint(
+            name="VPN Endpoint",
+            url="http://10.0.0.6",
+            use_runner=True,
+            runner_labels=["needs-vpn"],
+        )
+        assert ep["runner_labels"] == ["needs-vpn"]
+        plain = authenticated_client.register_runner("plain")
+        vpn = authenticated_client.register_runner("vpn", labels=["needs-vpn"])
+        try:
+            job = authenticated_client.create_job(
+                job_type="ui_exploration",
+                config={
+                    "endpoint_id": ep["id"],
+                    "project_id": project_for_jobs["id"],
+                },
+            )
+
+            # Neither an unregistered runner nor one without the label gets the job.
+            assert authenticated_client.claim_runner_job() == {}
+            assert authenticated_client.claim_runner_job(plain["id"]) == {}
+
+            claimed = authenticated_client.claim_runner_job(vpn["id"])
+            assert claimed["job"]["id"] == job["id"]
+            authenticated_client.fail_runner_job(job["id"], "done testing")
+        finally:
+            authenticated_client.deregister_runner(plain["id"])
+            authenticated_client.deregister_runner(vpn["id"])
+            authenticated_client.delete_endpoint(ep["id"])
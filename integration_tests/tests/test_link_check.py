@@ -0,0 +1,152 @@
+import uuid
+
+import pytest
+
+from client import APIError, UIAutomationClient
+
+pytestmark = pytest.mark.linkcheck
+
+
+@pytest.fixture()
+def link_project(authenticated_client: UIAutomationClient):
+    """Create a temporary project for link check tests."""
+    p = authenticated_client.create_project(
+        name="Link Check Test Project",
+        description="For link check integration tests",
+    )
+    yield p
+    try:
+        authenticated_client.delete_project(p["id"])
+    except APIError:
+        pass
+
+
+@pytest.fixture()
+def link_endpoint(authenticated_client: UIAutomationClient):
+    """Create a temporary endpoint for link check tests."""
+    ep = authenticated_client.create_endpoint(
+        name="Link Check Test Endpoint",
+        url="https://example.com",
+    )
+    yield ep
+    try:
+        authenticated_client.delete_endpoint(ep["id"])
+    except APIError:
+        pass
+
+
+@pytest.fixture()
+def link_run(authenticated_client: UIAutomationClient, link_project: dict):
+    """Create a test run to attach link reports to."""
+    procedure = authenticated_client.create_procedure(
+        project_id=link_project["id"],
+        name="Link Check Procedure",
+    )
+    return authenticated_client.create_run(procedure["id"])
+
+
+class TestCreateLinkCheckJob:
+    def test_create_job(
+        self,
+        authenticated_client: UIAutomationClient,
+        link_project: dict,
+        link_endpoint: dict,
+    ):
+        resp = authenticated_client.create_job(
+            job_type="link_check",
+            config={
+                "endpoint_id": link_endpoint["id"],
+                "project_id": link_project["id"],
+                "max_pages": 5,
+                "max_depth": 1,
+            },
+        )
+        assert resp["type"] == "link_check"
+        assert resp["config"]["max_pages"] == 5
+
+    @pytest.mark.parametrize("overrides", [
+        {"max_pages": 0},
+        {"max_pages": 100000},
+        {"max_depth": -1},
+    ])
+    def test_invalid_limits_return_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        link_project: dict,
+        link_endpoint: dict,
+        overrides: dict,
+    ):
+        config = {
+            "endpoint_id": link_endpoint["id"],
+            "project_id": link_project["id"],
+            **overrides,
+        }
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_job(job_type="link_check", config=config)
+        assert exc_info.value.status_code == 400
+
+    def test_missing_endpoint_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        link_project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_job(
+                job_type="link_check",
+                config={"project_id": link_project["id"]},
+            )
+        assert exc_info.value.status_code == 400
+
+
+class TestLinkReport:
+    def test_export_unfinished_job_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        link_project: dict,
+        link_endpoint: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="ui_exploration",
+            config={
+                "endpoint_id": link_endpoint["id"],
+                "project_id": link_project["id"],
+            },
+        )
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.export_link_report(job["id"])
+        assert exc_info.value.status_code == 400
+
+    def test_export_nonexistent_job_returns_404(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.export_link_report(str(uuid.uuid4()))
+        assert exc_info.value.status_code == 404
+
+    def test_attach_nonexistent_job_returns_404(
+        self,
+        authenticated_client: UIAutomationClient,
+        link_run: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.attach_link_report(link_run["id"], str(uuid.uuid4()))
+        assert exc_info.value.status_code == 404
+
+    def test_attach_to_other_users_run_returns_403(
+        self,
+        second_authenticated_client: UIAutomationClient,
+        link_run: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.attach_link_report(link_run["id"], str(uuid.uuid4()))
+        assert exc_info.value.status_code == 403
+
+    def test_attach_invalid_job_id_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        link_run: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.attach_link_report(link_run["id"], "not-a-uuid")
+        assert exc_info.value.status_code == 400
@@ -65,6 +65,7 @@ class TestCreateProcedure:
         assert resp["description"] == "A test procedure"
         assert resp["version"] == 1
         assert resp["is_latest"] is True
+        assert resp["needs_review"] is False
 
 
 class TestListProcedures:
@@ -112,6 +113,19 @@ class TestUpdateProcedure:
         # Update endpoint now returns the draft (version 0)
         assert resp["version"] == 0
 
+    def test_update_needs_review(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_id: str,
+        procedure: dict,
+    ):
+        resp = authenticated_client.update_procedure(
+            project_id,
+            procedure["id"],
+            needs_review=True,
+        )
+        assert resp["needs_review"] is True
+
 
 class TestVersioning:
     def test_create_version(
@@ -151,3 +165,100 @@ class TestVersioning:
         versions = [h["version"] for h in history]
         assert 1 in versions
         assert 2 in versions
+
+
+class TestSuggestSteps:
+    def test_requires_image_or_endpoint(
+        self,
+        authenticated_client: UIAutomationClient,
+        procedure: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.suggest_steps(procedure["id"])
+        assert exc_info.value.status_code == 400
+
+    def test_invalid_image_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        procedure: dict,
+        tmp_path,
+    ):
+        not_an_image = tmp_path / "page.png"
+        not_an_image.write_text("not an image")
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.suggest_steps(
+                procedure["id"], image_path=str(not_an_image),
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_other_users_endpoint_returns_404(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        procedure: dict,
+    ):
+        ep = second_authenticated_client.create_endpoint(
+            name="Other User Endpoint", url="https://example.com",
+        )
+        try:
+            with pytest.raises(APIError) as exc_info:
+                authenticated_client.suggest_steps(
+                    procedure["id"], endpoint_id=ep["id"],
+                )
+            assert exc_info.value.status_code == 404
+        finally:
+            second_authenticated_client.delete_endpoint(ep["id"])
+
+    def test_other_users_procedure_returns_403(
+        self,
+        second_authenticated_client: UIAutomationClient,
+        procedure: dict,
+        test_image_path: str,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.suggest_steps(
+                procedure["id"], image_path=test_image_path,
+            )
+        assert exc_info.value.status_code == 403
+
+
+class TestGenerateProcedure:
+    def test_requires_description(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_id: str,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.generate_procedure(project_id, description="  ")
+        assert exc_info.value.status_code == 400
+
+    def test_other_users_endpoint_returns_404(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        project_id: str,
+    ):
+        ep = second_authenticated_client.create_endpoint(
+            name="Other User Endpoint", url="https://example.com",
+        )
+        try:
+            with pytest.raises(APIError) as exc_info:
+                authenticated_client.generate_procedure(
+                    project_id,
+                    description="Log in and open the dashboard",
+                    endpoint_id=ep["id"],
+                )
+            assert exc_info.value.status_code == 404
+        finally:
+            second_authenticated_client.delete_endpoint(ep["id"])
+
+    def test_other_users_project_returns_403(
+        self,
+        second_authenticated_client: UIAutomationClient,
+        project_id: str,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.generate_procedure(
+                project_id, description="Log in and open the dashboard",
+            )
+        assert exc_info.value.status_code == 403
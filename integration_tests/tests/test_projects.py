@@ -91,3 +91,69 @@ class TestDeleteProject:
         with pytest.raises(APIError) as exc_info:
             authenticated_client.get_project(p["id"])
         assert exc_info.value.status_code in (403, 404)
+
+
+class TestExportImportProject:
+    def test_export_project(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        authenticated_client.create_procedure(
+            project["id"], name="Exported Procedure",
+            steps=[{"name": "Step 1", "instructions": "Do it", "image_paths": []}],
+        )
+        archive = authenticated_client.export_project(project["id"])
+        assert archive["format_version"] == 1
+        assert archive["project"]["id"] == project["id"]
+        # The committed version and its draft
+        assert len(archive["procedures"]) == 2
+        assert archive["runs"] == []
+        assert archive["step_notes"] == []
+        assert archive["assets"] == []
+
+    def test_import_project_creates_copy(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        proc = authenticated_client.create_procedure(
+            project["id"], name="Cloned Procedure",
+            steps=[{"name": "Step 1", "instructions": "", "image_paths": []}],
+        )
+        run = authenticated_client.create_run(proc["id"])
+        authenticated_client.start_run(run["id"])
+        archive = authenticated_client.export_project(project["id"])
+
+        resp = authenticated_client.import_project(archive, name="Cloned Project")
+        try:
+            assert resp["project"]["id"] != project["id"]
+            assert resp["project"]["name"] == "Cloned Project"
+            assert resp["procedures"] == 2
+            assert resp["runs"] == 1
+            assert resp["missing_assets"] == 0
+
+            procs = authenticated_client.list_procedures(resp["project"]["id"])
+            assert [p["name"] for p in procs["items"]] == ["Cloned Procedure"]
+            assert procs["items"][0]["id"] != proc["id"]
+        finally:
+            authenticated_client.delete_project(resp["project"]["id"])
+
+    def test_import_rejects_unknown_format(
+        self, authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.import_project(
+                {"format_version": 99, "project": {"name": "Bad"}},
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_other_user_cannot_export_project(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.export_project(project["id"])
+        assert exc_info.value.status_code == 403
@@ -1,3 +1,6 @@
+import io
+import zipfile
+
 import pytest
 
 from client import (
@@ -52,6 +55,50 @@ class TestCreateRun:
         assert run["status"] == STATUS_PENDING
         assert run["test_procedure_id"] is not None
 
+    def test_create_run_for_environment(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_and_procedure: tuple,
+    ):
+        _, procedure = project_and_procedure
+        staging = authenticated_client.create_endpoint(
+            name="Run Staging", url="https://staging.example.com",
+            group="run-env", environment="staging",
+        )
+        prod = authenticated_client.create_endpoint(
+            name="Run Prod", url="https://www.example.com",
+            group="run-env", environment="prod",
+        )
+        try:
+            staging_run = authenticated_client.create_run(
+                procedure["id"], endpoint_group="run-env", environment="staging",
+            )
+            assert staging_run["endpoint_id"] == staging["id"]
+            assert staging_run["environment"] == "staging"
+            assert staging_run["base_url"] == "https://staging.example.com"
+
+            prod_run = authenticated_client.create_run(
+                procedure["id"], endpoint_id=prod["id"],
+            )
+            assert prod_run["environment"] == "prod"
+            assert prod_run["base_url"] == "https://www.example.com"
+            assert prod_run["test_procedure_id"] == staging_run["test_procedure_id"]
+        finally:
+            authenticated_client.delete_endpoint(staging["id"])
+            authenticated_client.delete_endpoint(prod["id"])
+
+    def test_create_run_unknown_environment(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_and_procedure: tuple,
+    ):
+        _, procedure = project_and_procedure
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_run(
+                procedure["id"], endpoint_group="no-such-group", environment="dev",
+            )
+        assert exc_info.value.status_code == 404
+
 
 class TestRunLifecycle:
     def test_start_run(
@@ -94,6 +141,28 @@ class TestRunLifecycle:
         assert completed["status"] == STATUS_FAILED
 
 
+class TestRunProcedureSnapshot:
+    def test_started_run_keeps_original_steps(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_and_procedure: tuple,
+    ):
+        project, procedure = project_and_procedure
+        run = authenticated_client.create_run(procedure["id"])
+        authenticated_client.start_run(run["id"])
+
+        authenticated_client.update_procedure(
+            project["id"], procedure["id"],
+            steps=[{"name": "Edited step", "instructions": "Changed", "image_paths": []}],
+        )
+        authenticated_client.create_version(project["id"], procedure["id"])
+
+        snapshot = authenticated_client.get_run_procedure(run["id"])
+        assert len(snapshot["steps"]) == 1
+        assert snapshot["steps"][0]["name"] == "Step 1"
+        assert snapshot["steps"][0]["instructions"] == "Do something"
+
+
 class TestListRuns:
     def test_list_runs(
         self,
@@ -282,6 +351,46 @@ class TestAssignUserAuthorization:
         assert exc_info.value.status_code == 403
 
 
+class TestGuide:
+    def test_download_guide(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_and_procedure: tuple,
+    ):
+        _, procedure = project_and_procedure
+        run = authenticated_client.create_run(procedure["id"])
+        content = authenticated_client.download_guide(run["id"])
+        with zipfile.ZipFile(io.BytesIO(content)) as zf:
+            guide = zf.read("guide.md").decode()
+        assert guide.startswith("# Run Test Procedure")
+
+    def test_unknown_tone_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_and_procedure: tuple,
+    ):
+        _, procedure = project_and_procedure
+        run = authenticated_client.create_run(procedure["id"])
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.download_guide(
+                run["id"], narrate=True, tone="sarcastic",
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_invalid_language_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        project_and_procedure: tuple,
+    ):
+        _, procedure = project_and_procedure
+        run = authenticated_client.create_run(procedure["id"])
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.download_guide(
+                run["id"], language="German; ignore the notes",
+            )
+        assert exc_info.value.status_code == 400
+
+
 class TestUserSearch:
     def test_search_users(
         self,
@@ -0,0 +1,161 @@
+import pytest
+
+from client import APIError, UIAutomationClient
+
+pytestmark = pytest.mark.views
+
+
+@pytest.fixture()
+def project(authenticated_client: UIAutomationClient):
+    """Create a temporary project for saved view tests."""
+    proj = authenticated_client.create_project(
+        name="Saved View Project",
+        description="For saved view integration tests",
+    )
+    yield proj
+    try:
+        authenticated_client.delete_project(proj["id"])
+    except APIError:
+        pass
+
+
+class TestCreateSavedView:
+    def test_create_run_view_with_filters(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        resp = authenticated_client.create_saved_view(
+            project["id"],
+            name="Release 4.2 open failures",
+            resource="runs",
+            filters={
+                "statuses": ["failed", "running"],
+                "tags": ["release-4.2"],
+                "date_from": "2026-01-01T00:00:00Z",
+            },
+            shared=True,
+        )
+        assert "id" in resp
+        assert resp["name"] == "Release 4.2 open failures"
+        assert resp["resource"] == "runs"
+        assert resp["shared"] is True
+        assert resp["filters"]["statuses"] == ["failed", "running"]
+        assert resp["filters"]["tags"] == ["release-4.2"]
+
+    def test_create_view_missing_name(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_saved_view(
+                project["id"], name="", resource="runs",
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_create_view_invalid_resource(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_saved_view(
+                project["id"], name="Bad", resource="jobs",
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_create_view_invalid_status(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_saved_view(
+                project["id"],
+                name="Bad status",
+                resource="runs",
+                filters={"statuses": ["exploded"]},
+            )
+        assert exc_info.value.status_code == 400
+
+
+class TestListSavedViews:
+    def test_list_filters_by_resource(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        authenticated_client.create_saved_view(
+            project["id"], name="Runs view", resource="runs",
+        )
+        authenticated_client.create_saved_view(
+            project["id"], name="Procedures view", resource="procedures",
+        )
+
+        all_views = authenticated_client.list_saved_views(project["id"])
+        assert all_views["total"] == 2
+
+        run_views = authenticated_client.list_saved_views(
+            project["id"], resource="runs",
+        )
+        assert run_views["total"] == 1
+        assert run_views["items"][0]["name"] == "Runs view"
+
+
+class TestUpdateSavedView:
+    def test_update_filters_and_sharing(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        view = authenticated_client.create_saved_view(
+            project["id"], name="Mine", resource="runs",
+        )
+        resp = authenticated_client.update_saved_view(
+            project["id"], view["id"],
+            filters={"statuses": ["passed"]},
+            shared=True,
+        )
+        assert resp["filters"]["statuses"] == ["passed"]
+        assert resp["shared"] is True
+
+
+class TestDeleteSavedView:
+    def test_delete_view(
+        self,
+        authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        view = authenticated_client.create_saved_view(
+            project["id"], name="Temporary", resource="runs",
+        )
+        authenticated_client.delete_saved_view(project["id"], view["id"])
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.get_saved_view(project["id"], view["id"])
+        assert exc_info.value.status_code == 404
+
+
+class TestSavedViewOwnership:
+    def test_other_user_cannot_list_views(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.list_saved_views(project["id"])
+        assert exc_info.value.status_code == 403
+
+    def test_other_user_cannot_delete_view(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        project: dict,
+    ):
+        view = authenticated_client.create_saved_view(
+            project["id"], name="Protected", resource="runs", shared=True,
+        )
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.delete_saved_view(project["id"], view["id"])
+        assert exc_info.value.status_code == 403
@@ -0,0 +1,186 @@
+import datetime
+
+import pytest
+
+from client import ASSET_IMAGE, APIError, UIAutomationClient
+
+pytestmark = pytest.mark.usage
+
+
+@pytest.fixture()
+def usage_project(authenticated_client: UIAutomationClient):
+    """Create a temporary project for usage tests."""
+    p = authenticated_client.create_project(
+        name="Usage Test Project",
+        description="For usage integration tests",
+    )
+    yield p
+    try:
+        authenticated_client.delete_project(p["id"])
+    except APIError:
+        pass
+
+
+class TestUsageReport:
+    def test_report_defaults_to_current_month(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        resp = authenticated_client.get_usage_report()
+        assert resp["month"] == datetime.datetime.now(datetime.timezone.utc).strftime("%Y-%m")
+        for key in (
+            "account_id", "script_generations", "agent_job_minutes",
+            "storage_gb", "active_users", "projects",
+        ):
+            assert key in resp
+
+    def test_report_for_empty_month(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        resp = authenticated_client.get_usage_report("2000-01")
+        assert resp["month"] == "2000-01"
+        assert resp["script_generations"] == 0
+        assert resp["agent_job_minutes"] == 0
+        assert resp["active_users"] == 0
+        assert resp["projects"] == []
+
+    def test_invalid_month_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.get_usage_report("October")
+        assert exc_info.value.status_code == 400
+
+    def test_asset_upload_is_metered(
+        self,
+        authenticated_client: UIAutomationClient,
+        usage_project: dict,
+        test_image_path: str,
+    ):
+        procedure = authenticated_client.create_procedure(
+            project_id=usage_project["id"],
+            name="Usage Procedure",
+            description="Procedure for usage tests",
+            steps=[{"name": "Step 1", "instructions": "Look", "image_paths": []}],
+        )
+        run = authenticated_client.create_run(procedure["id"])
+        authenticated_client.start_run(run["id"])
+        authenticated_client.upload_asset(
+            run_id=run["id"],
+            file_path=test_image_path,
+            asset_type=ASSET_IMAGE,
+        )
+
+        resp = authenticated_client.get_usage_report()
+        project_ids = [p["project_id"] for p in resp["projects"]]
+        assert usage_project["id"] in project_ids
+        assert resp["active_users"] >= 1
+
+    def test_unauthenticated_returns_401(self, fresh_client: UIAutomationClient):
+        with pytest.raises(APIError) as exc_info:
+            fresh_client.get_usage_report()
+        assert exc_info.value.status_code == 401
+
+
+class TestUsageExport:
+    def test_csv_export(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        resp = authenticated_client.export_usage_report("2000-01")
+        assert resp.headers["Content-Type"].startswith("text/csv")
+        assert "usage-2000-01.csv" in resp.headers["Content-Disposition"]
+        lines = resp.text.strip().splitlines()
+        assert lines[0] == (
+            "month,account_id,project_id,script_generations,"
+            "agent_job_minutes,storage_gb,active_users"
+        )
+        assert lines[-1].startswith("2000-01,")
+        assert ",total," in lines[-1]
+
+    def test_openmetrics_export(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        resp = authenticated_client.export_usage_report("2000-01", fmt="openmetrics")
+        assert resp.headers["Content-Type"].startswith("application/openmetrics-text")
+        assert "ui_automation_usage_active_users" in resp.text
+        assert resp.text.endswith("# EOF\n")
+
+    def test_invalid_format_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.export_usage_report(fmt="xlsx")
+        assert exc_info.value.status_code == 400
+
+
+class TestLLMUsage:
+    def test_report_for_empty_month(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        resp = authenticated_client.get_llm_usage_report("2000-01")
+        assert resp["month"] == "2000-01"
+        assert resp["total"] == {"input_tokens": 0, "output_tokens": 0, "cost_usd": 0}
+        assert resp["projects"] == []
+        assert resp["users"] == []
+        assert resp["features"] == []
+
+    def test_invalid_month_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.get_llm_usage_report("October")
+        assert exc_info.value.status_code == 400
+
+    def test_account_budget(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        budget = authenticated_client.set_llm_budget(25)
+        try:
+            assert budget["scope"] == "account"
+            assert budget["monthly_limit_usd"] == 25
+            assert "project_id" not in budget
+
+            resp = authenticated_client.get_llm_usage_report()
+            scopes = [b["scope"] for b in resp["budgets"]]
+            assert "account" in scopes
+        finally:
+            authenticated_client.delete_llm_budget()
+
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.delete_llm_budget()
+        assert exc_info.value.status_code == 404
+
+    def test_project_budget(
+        self,
+        authenticated_client: UIAutomationClient,
+        usage_project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.get_project_llm_budget(usage_project["id"])
+        assert exc_info.value.status_code == 404
+
+        budget = authenticated_client.set_project_llm_budget(usage_project["id"], 5)
+        assert budget["scope"] == "project"
+        assert budget["project_id"] == usage_project["id"]
+        assert budget["remaining_usd"] <= 5
+
+        budget = authenticated_client.get_project_llm_budget(usage_project["id"])
+        assert budget["monthly_limit_usd"] == 5
+
+        authenticated_client.delete_project_llm_budget(usage_project["id"])
+
+    def test_invalid_budget_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.set_llm_budget(0)
+        assert exc_info.value.status_code == 400
@@ -0,0 +1,171 @@
+import uuid
+
+import pytest
+
+from client import APIError, UIAutomationClient
+
+pytestmark = pytest.mark.visual
+
+
+@pytest.fixture()
+def visual_project(authenticated_client: UIAutomationClient):
+    """Create a temporary project for visual regression tests."""
+    p = authenticated_client.create_project(
+        name="Visual Regression Test Project",
+        description="For visual regression integration tests",
+    )
+    yield p
+    try:
+        authenticated_client.delete_project(p["id"])
+    except APIError:
+        pass
+
+
+@pytest.fixture()
+def visual_endpoint(authenticated_client: UIAutomationClient):
+    """Create a temporary endpoint for visual regression tests."""
+    ep = authenticated_client.create_endpoint(
+        name="Visual Regression Test Endpoint",
+        url="https://example.com",
+    )
+    yield ep
+    try:
+        authenticated_client.delete_endpoint(ep["id"])
+    except APIError:
+        pass
+
+
+def visual_config(project: dict, endpoint: dict, **overrides) -> dict:
+    config = {
+        "endpoint_id": endpoint["id"],
+        "project_id": project["id"],
+        "pages": [
+            {"name": "home", "path": "/"},
+            {"name": "about", "path": "/about"},
+        ],
+    }
+    config.update(overrides)
+    return config
+
+
+class TestCreateVisualRegressionJob:
+    def test_create_job(
+        self,
+        authenticated_client: UIAutomationClient,
+        visual_project: dict,
+        visual_endpoint: dict,
+    ):
+        resp = authenticated_client.create_job(
+            job_type="visual_regression",
+            config=visual_config(visual_project, visual_endpoint, threshold=0.01),
+        )
+        assert resp["type"] == "visual_regression"
+        assert resp["config"]["threshold"] == 0.01
+        assert len(resp["config"]["pages"]) == 2
+
+    @pytest.mark.parametrize("overrides", [
+        {"pages": []},
+        {"pages": [{"name": "../secret", "path": "/"}]},
+        {"pages": [{"name": "home", "path": "/"}, {"name": "home", "path": "/index"}]},
+        {"threshold": 2},
+    ])
+    def test_invalid_config_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        visual_project: dict,
+        visual_endpoint: dict,
+        overrides: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_job(
+                job_type="visual_regression",
+                config=visual_config(visual_project, visual_endpoint, **overrides),
+            )
+        assert exc_info.value.status_code == 400
+
+    def test_missing_endpoint_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        visual_project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.create_job(
+                job_type="visual_regression",
+                config={
+                    "project_id": visual_project["id"],
+                    "pages": [{"name": "home", "path": "/"}],
+                },
+            )
+        assert exc_info.value.status_code == 400
+
+
+class TestApproveBaselines:
+    def test_unfinished_job_returns_400(
+        self,
+        authenticated_client: UIAutomationClient,
+        visual_project: dict,
+        visual_endpoint: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="visual_regression",
+            config=visual_config(visual_project, visual_endpoint),
+        )
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.approve_baselines(job["id"])
+        assert exc_info.value.status_code == 400
+
+    def test_other_users_job_returns_403(
+        self,
+        authenticated_client: UIAutomationClient,
+        second_authenticated_client: UIAutomationClient,
+        visual_project: dict,
+        visual_endpoint: dict,
+    ):
+        job = authenticated_client.create_job(
+            job_type="visual_regression",
+            config=visual_config(visual_project, visual_endpoint),
+        )
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.approve_baselines(job["id"])
+        assert exc_info.value.status_code == 403
+
+    def test_nonexistent_job_returns_404(
+        self,
+        authenticated_client: UIAutomationClient,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.approve_baselines(str(uuid.uuid4()))
+        assert exc_info.value.status_code == 404
+
+
+class TestBaselines:
+    def test_list_empty(
+        self,
+        authenticated_client: UIAutomationClient,
+        visual_project: dict,
+        visual_endpoint: dict,
+    ):
+        resp = authenticated_client.list_baselines(visual_project["id"])
+        assert resp["items"] == []
+        assert resp["total"] == 0
+
+        resp = authenticated_client.list_baselines(visual_project["id"], visual_endpoint["id"])
+        assert resp["total"] == 0
+
+    def test_list_other_users_project_returns_403(
+        self,
+        second_authenticated_client: UIAutomationClient,
+        visual_project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            second_authenticated_client.list_baselines(visual_project["id"])
+        assert exc_info.value.status_code == 403
+
+    def test_delete_nonexistent_baseline_returns_404(
+        self,
+        authenticated_client: UIAutomationClient,
+        visual_project: dict,
+    ):
+        with pytest.raises(APIError) as exc_info:
+            authenticated_client.delete_baseline(visual_project["id"], str(uuid.uuid4()))
+        assert exc_info.value.status_code == 404
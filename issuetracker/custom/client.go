@@ -0,0 +1,343 @@
+package custom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+)
+
+// Client implements the issuetracker.Client interface for a tracker
+// described entirely by its Config, so internal trackers with a JSON REST
+// API can be integrated without a dedicated client.
+type Client struct {
+	httpClient *http.Client
+	config     *Config
+}
+
+// NewClient creates a new custom issue tracker client from the
+// integration's credentials. See ParseConfig for the recognized keys.
+func NewClient(credentials map[string]string) (*Client, error) {
+	cfg, err := ParseConfig(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		config:     cfg,
+	}, nil
+}
+
+// do calls an endpoint and decodes its JSON response into a generic value.
+// A nil result means the endpoint answered without a body.
+func (c *Client) do(ctx context.Context, name string, values map[string]string, body map[string]interface{}) (interface{}, error) {
+	e, ok := c.config.Endpoints[name]
+	if !ok {
+		return nil, fmt.Errorf("custom: %s endpoint is not configured", name)
+	}
+
+	var bodyReader io.Reader
+	if body != nil && e.Method != http.MethodGet {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("custom: failed to marshal request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, e.Method, c.config.BaseURL+e.expand(values), bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("custom: failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if bodyReader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range c.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, issuetracker.ErrIssueNotFound
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("custom: %s failed with status %d: %w", name, resp.StatusCode, issuetracker.ErrUnauthorized)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("custom: %s failed with status %d: %s", name, resp.StatusCode, string(data))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("custom: failed to read response: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var result interface{}
+	if err := dec.Decode(&result); err != nil {
+		return nil, fmt.Errorf("custom: failed to decode response: %w", err)
+	}
+	return result, nil
+}
+
+// lookup returns the value at a dot-separated path, with numeric segments
+// indexing arrays. An empty path returns the value itself.
+func lookup(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, true
+	}
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// lookupString returns the value at path as a string. Missing values and
+// objects return "".
+func lookupString(value interface{}, path string) string {
+	v, ok := lookup(value, path)
+	if !ok || v == nil {
+		return ""
+	}
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// assign sets the value at a dot-separated path in body, creating nested
+// objects as needed. An empty path leaves body unchanged.
+func assign(body map[string]interface{}, path string, value interface{}) {
+	if path == "" {
+		return
+	}
+	segments := strings.Split(path, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := body[segment].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			body[segment] = next
+		}
+		body = next
+	}
+	body[segments[len(segments)-1]] = value
+}
+
+// parseTime parses RFC 3339 timestamps, returning the zero time otherwise.
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+// toIssue maps an issue object to an issue.
+func (c *Client) toIssue(value interface{}) (*issuetracker.Issue, error) {
+	fields := c.config.Response
+	issue := &issuetracker.Issue{
+		ExternalID:  lookupString(value, fields["id"]),
+		Title:       lookupString(value, fields["title"]),
+		Description: lookupString(value, fields["description"]),
+		Status:      lookupString(value, fields["status"]),
+		URL:         lookupString(value, fields["url"]),
+		Provider:    issuetracker.ProviderCustom,
+		CreatedAt:   parseTime(lookupString(value, fields["created_at"])),
+		UpdatedAt:   parseTime(lookupString(value, fields["updated_at"])),
+	}
+	if issue.ExternalID == "" {
+		return nil, fmt.Errorf("custom: response has no issue ID at %q", fields["id"])
+	}
+	if issue.URL == "" && c.config.IssueURL != "" {
+		issue.URL = endpoint{Path: c.config.IssueURL}.expand(map[string]string{"id": issue.ExternalID})
+	}
+	return issue, nil
+}
+
+// responseIssue maps the issue object in a create, get or resolve response.
+func (c *Client) responseIssue(result interface{}) (*issuetracker.Issue, error) {
+	value, ok := lookup(result, c.config.Issue)
+	if !ok {
+		return nil, fmt.Errorf("custom: response has no issue at %q", c.config.Issue)
+	}
+	return c.toIssue(value)
+}
+
+// requestBody builds a create or resolve request body from input fields
+// using the configured request mapping. Empty values are left out.
+func (c *Client) requestBody(values map[string]interface{}) map[string]interface{} {
+	body := make(map[string]interface{})
+	for field, value := range values {
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+		case []string:
+			if len(v) == 0 {
+				continue
+			}
+		}
+		assign(body, c.config.Request[field], value)
+	}
+	return body
+}
+
+// CreateIssue creates an issue through the create endpoint. The endpoint
+// may use {project_key} and {repository}; the issue is read from its
+// response.
+func (c *Client) CreateIssue(ctx context.Context, input issuetracker.CreateIssueInput) (*issuetracker.Issue, error) {
+	body := c.requestBody(map[string]interface{}{
+		"title":       input.Title,
+		"description": input.Description,
+		"project_key": input.ProjectKey,
+		"issue_type":  input.IssueType,
+		"repository":  input.Repository,
+		"labels":      input.Labels,
+	})
+	result, err := c.do(ctx, endpointCreate, map[string]string{
+		"project_key": input.ProjectKey,
+		"repository":  input.Repository,
+	}, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.responseIssue(result)
+}
+
+// GetIssue gets an issue through the get endpoint, which uses {id}.
+func (c *Client) GetIssue(ctx context.Context, externalID string) (*issuetracker.Issue, error) {
+	result, err := c.do(ctx, endpointGet, map[string]string{"id": externalID}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.responseIssue(result)
+}
+
+// ListIssues lists issues through the list endpoint, which may use {query},
+// {status}, {project_key}, {repository}, {limit}, {offset} and {cursor}.
+// Whether more issues follow is taken from the next cursor if it is mapped,
+// then from the total, and otherwise from whether the page is full.
+func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesInput) (*issuetracker.IssuePage, error) {
+	result, err := c.do(ctx, endpointList, map[string]string{
+		"query":       input.Query,
+		"status":      input.Status,
+		"project_key": input.ProjectKey,
+		"repository":  input.Repository,
+		"limit":       strconv.Itoa(input.Limit),
+		"offset":      strconv.Itoa(input.Offset),
+		"cursor":      input.Cursor,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := lookup(result, c.config.Items)
+	if !ok {
+		return nil, fmt.Errorf("custom: list response has no issues at %q", c.config.Items)
+	}
+	list, ok := items.([]interface{})
+	if !ok && items != nil {
+		return nil, fmt.Errorf("custom: list response issues at %q are not an array", c.config.Items)
+	}
+
+	issues := make([]*issuetracker.Issue, 0, len(list))
+	for _, item := range list {
+		issue, err := c.toIssue(item)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+
+	page := &issuetracker.IssuePage{
+		Issues:  issues,
+		Total:   issuetracker.UnknownTotal,
+		HasMore: input.Limit > 0 && len(issues) >= input.Limit,
+	}
+	if c.config.Total != "" {
+		if n, err := strconv.Atoi(lookupString(result, c.config.Total)); err == nil {
+			page.Total = n
+			page.HasMore = input.Offset+len(issues) < n
+		}
+	}
+	if c.config.NextCursor != "" {
+		page.NextCursor = lookupString(result, c.config.NextCursor)
+		page.HasMore = page.NextCursor != ""
+	}
+	return page, nil
+}
+
+// ResolveIssue resolves an issue through the resolve endpoint, which uses
+// {id}. If the endpoint does not answer with the issue, it is fetched again.
+func (c *Client) ResolveIssue(ctx context.Context, externalID string, input issuetracker.ResolveInput) (*issuetracker.Issue, error) {
+	body := c.requestBody(map[string]interface{}{
+		"resolution": input.Resolution,
+		"comment":    input.Comment,
+	})
+	result, err := c.do(ctx, endpointResolve, map[string]string{"id": externalID}, body)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil {
+		if issue, err := c.responseIssue(result); err == nil {
+			return issue, nil
+		}
+	}
+	return c.GetIssue(ctx, externalID)
+}
+
+// ValidateConnection calls the validate endpoint, or the list endpoint if
+// none is configured, and checks that it succeeds.
+func (c *Client) ValidateConnection(ctx context.Context) error {
+	name := endpointValidate
+	if _, ok := c.config.Endpoints[name]; !ok {
+		name = endpointList
+	}
+	if _, ok := c.config.Endpoints[name]; !ok {
+		return fmt.Errorf("%w: no validate or list endpoint configured", issuetracker.ErrConnectionFailed)
+	}
+
+	values := map[string]string{"query": "", "status": "", "project_key": "", "repository": "", "limit": "1", "offset": "0", "cursor": ""}
+	if _, err := c.do(ctx, name, values, nil); err != nil {
+		return fmt.Errorf("%w: %w", issuetracker.ErrConnectionFailed, err)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package custom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trackerCredentials describes a tracker that wraps responses in "data" and
+// names fields differently from the defaults.
+func trackerCredentials(baseURL string) map[string]string {
+	return map[string]string{
+		"base_url":             baseURL,
+		"header.X-Api-Key":     "secret",
+		"create":               "POST /projects/{project_key}/tickets",
+		"get":                  "GET /tickets/{id}",
+		"list":                 "GET /tickets?q={query}&state={status}&limit={limit}&skip={offset}",
+		"resolve":              "POST /tickets/{id}/close",
+		"validate":             "GET /me",
+		"response.issue":       "data",
+		"response.id":          "key",
+		"response.title":       "summary",
+		"response.status":      "state.name",
+		"response.url":         "",
+		"response.created_at":  "created",
+		"response.items":       "data",
+		"response.total":       "meta.total",
+		"request.title":        "ticket.summary",
+		"request.description":  "ticket.body",
+		"request.project_key":  "",
+		"request.comment":      "note",
+		"issue_url":            "https://tracker.example.com/t/{id}",
+		"unrelated_credential": "ignored",
+	}
+}
+
+func newTestClient(t *testing.T, handler http.Handler) (*Client, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	client, err := NewClient(trackerCredentials(server.URL))
+	require.NoError(t, err)
+	return client, server
+}
+
+func ticket(key, state string) map[string]interface{} {
+	return map[string]interface{}{
+		"key":     key,
+		"summary": "Login fails",
+		"state":   map[string]interface{}{"name": state},
+		"created": "2026-01-02T03:04:05Z",
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		credentials map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "minimal",
+			credentials: map[string]string{"base_url": "https://tracker.example.com", "get": "GET /issues/{id}"},
+		},
+		{
+			name:        "missing base_url",
+			credentials: map[string]string{"get": "GET /issues/{id}"},
+			wantErr:     true,
+		},
+		{
+			name:        "base_url not http",
+			credentials: map[string]string{"base_url": "ftp://tracker.example.com", "get": "GET /issues/{id}"},
+			wantErr:     true,
+		},
+		{
+			name:        "missing get endpoint",
+			credentials: map[string]string{"base_url": "https://tracker.example.com"},
+			wantErr:     true,
+		},
+		{
+			name:        "endpoint without method",
+			credentials: map[string]string{"base_url": "https://tracker.example.com", "get": "/issues/{id}"},
+			wantErr:     true,
+		},
+		{
+			name:        "unsupported method",
+			credentials: map[string]string{"base_url": "https://tracker.example.com", "get": "FETCH /issues/{id}"},
+			wantErr:     true,
+		},
+		{
+			name: "unknown response field",
+			credentials: map[string]string{
+				"base_url": "https://tracker.example.com", "get": "GET /issues/{id}", "response.priority": "p",
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty id mapping",
+			credentials: map[string]string{
+				"base_url": "https://tracker.example.com", "get": "GET /issues/{id}", "response.id": "",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseConfig(tt.credentials)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateIssue(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/projects/QA%20TEAM/tickets", r.URL.EscapedPath())
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{
+			"ticket":     map[string]interface{}{"summary": "Login fails", "body": "Steps..."},
+			"issue_type": "Bug",
+			"labels":     []interface{}{"ui"},
+		}, body)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": ticket("QA-1", "open")})
+	}))
+	defer server.Close()
+
+	issue, err := client.CreateIssue(context.Background(), issuetracker.CreateIssueInput{
+		Title:       "Login fails",
+		Description: "Steps...",
+		ProjectKey:  "QA TEAM",
+		IssueType:   "Bug",
+		Labels:      []string{"ui"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "QA-1", issue.ExternalID)
+	assert.Equal(t, "Login fails", issue.Title)
+	assert.Equal(t, "open", issue.Status)
+	assert.Equal(t, "https://tracker.example.com/t/QA-1", issue.URL)
+	assert.Equal(t, issuetracker.ProviderCustom, issue.Provider)
+	assert.Equal(t, 2026, issue.CreatedAt.Year())
+}
+
+func TestGetIssue(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tickets/QA-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": ticket("QA-1", "closed")})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	issue, err := client.GetIssue(context.Background(), "QA-1")
+	require.NoError(t, err)
+	assert.Equal(t, "closed", issue.Status)
+
+	_, err = client.GetIssue(context.Background(), "QA-404")
+	assert.ErrorIs(t, err, issuetracker.ErrIssueNotFound)
+}
+
+func TestListIssues(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "login page", r.URL.Query().Get("q"))
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		assert.Equal(t, "20", r.URL.Query().Get("skip"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []interface{}{ticket("QA-1", "open"), ticket("QA-2", "open")},
+			"meta": map[string]interface{}{"total": 42},
+		})
+	}))
+	defer server.Close()
+
+	page, err := client.ListIssues(context.Background(), issuetracker.ListIssuesInput{
+		Query:  "login page",
+		Status: "open",
+		Limit:  10,
+		Offset: 20,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Issues, 2)
+	assert.Equal(t, "QA-2", page.Issues[1].ExternalID)
+	assert.Equal(t, 42, page.Total)
+	assert.True(t, page.HasMore)
+}
+
+func TestListIssuesCursor(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next := ""
+		if r.URL.Query().Get("after") == "" {
+			next = "abc+1"
+		} else {
+			assert.Equal(t, "abc+1", r.URL.Query().Get("after"))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issues": []interface{}{map[string]interface{}{"id": "1"}},
+			"next":   next,
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(map[string]string{
+		"base_url":             server.URL,
+		"get":                  "GET /issues/{id}",
+		"list":                 "GET /issues?after={cursor}",
+		"response.items":       "issues",
+		"response.next_cursor": "next",
+	})
+	require.NoError(t, err)
+
+	page, err := client.ListIssues(context.Background(), issuetracker.ListIssuesInput{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, issuetracker.UnknownTotal, page.Total)
+	assert.Equal(t, "abc+1", page.NextCursor)
+	assert.True(t, page.HasMore)
+
+	page, err = client.ListIssues(context.Background(), issuetracker.ListIssuesInput{Limit: 1, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Empty(t, page.NextCursor)
+	assert.False(t, page.HasMore)
+}
+
+func TestResolveIssue(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/tickets/QA-1/close":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "Fixed in build 12", body["note"])
+			// Answers without the issue, so it is fetched again.
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/tickets/QA-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": ticket("QA-1", "closed")})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	issue, err := client.ResolveIssue(context.Background(), "QA-1", issuetracker.ResolveInput{Comment: "Fixed in build 12"})
+	require.NoError(t, err)
+	assert.Equal(t, "closed", issue.Status)
+}
+
+func TestValidateConnection(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/me" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	assert.NoError(t, client.ValidateConnection(context.Background()))
+}
+
+func TestValidateConnectionFailed(t *testing.T) {
+	t.Parallel()
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := client.ValidateConnection(context.Background())
+	assert.ErrorIs(t, err, issuetracker.ErrConnectionFailed)
+	assert.ErrorIs(t, err, issuetracker.ErrUnauthorized)
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"id": json.Number("7"), "done": true},
+		},
+	}
+
+	assert.Equal(t, "7", lookupString(value, "data.0.id"))
+	assert.Equal(t, "true", lookupString(value, "data.0.done"))
+	assert.Equal(t, "", lookupString(value, "data.1.id"))
+	assert.Equal(t, "", lookupString(value, "data.0.missing"))
+	assert.Equal(t, "", lookupString(value, "data"))
+}
@@ -0,0 +1,198 @@
+package custom
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Credential keys. Headers and field mappings use prefixed keys, such as
+// "header.Authorization" or "response.status".
+const (
+	keyBaseURL        = "base_url"
+	keyIssueURL       = "issue_url"
+	headerPrefix      = "header."
+	responsePrefix    = "response."
+	requestPrefix     = "request."
+	responseIssueKey  = "response.issue"
+	responseItemsKey  = "response.items"
+	responseTotalKey  = "response.total"
+	responseCursorKey = "response.next_cursor"
+	endpointCreate    = "create"
+	endpointGet       = "get"
+	endpointList      = "list"
+	endpointResolve   = "resolve"
+	endpointValidate  = "validate"
+)
+
+// defaultResponseFields maps issue fields to where they are found in an
+// issue object when no response.<field> mapping is given.
+var defaultResponseFields = map[string]string{
+	"id":          "id",
+	"title":       "title",
+	"description": "description",
+	"status":      "status",
+	"url":         "url",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+}
+
+// defaultRequestFields maps input fields to where they are written in
+// create and resolve request bodies when no request.<field> mapping is
+// given. Fields mapped to "" are left out.
+var defaultRequestFields = map[string]string{
+	"title":       "title",
+	"description": "description",
+	"project_key": "project_key",
+	"issue_type":  "issue_type",
+	"repository":  "repository",
+	"labels":      "labels",
+	"resolution":  "resolution",
+	"comment":     "comment",
+}
+
+// endpoint is a request to the tracker: a method and a URL template
+// relative to the base URL, with {placeholders} filled in per call.
+type endpoint struct {
+	Method string
+	Path   string
+}
+
+// parseEndpoint parses an endpoint written as "METHOD /path".
+func parseEndpoint(name, value string) (endpoint, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(value), " ")
+	path = strings.TrimSpace(path)
+	if !ok || path == "" {
+		return endpoint{}, fmt.Errorf("custom: %s must be \"METHOD /path\"", name)
+	}
+	method = strings.ToUpper(method)
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return endpoint{}, fmt.Errorf("custom: %s has unsupported method %q", name, method)
+	}
+	return endpoint{Method: method, Path: path}, nil
+}
+
+// expand fills the endpoint's placeholders. Values in the path are
+// path-escaped and values in the query string are query-escaped.
+func (e endpoint) expand(values map[string]string) string {
+	path, query, hasQuery := strings.Cut(e.Path, "?")
+	path = fill(path, values, url.PathEscape)
+	if !hasQuery {
+		return path
+	}
+	return path + "?" + fill(query, values, url.QueryEscape)
+}
+
+// fill replaces each {name} in template with the escaped value.
+func fill(template string, values map[string]string, escape func(string) string) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+	pairs := make([]string, 0, 2*len(values))
+	for name, value := range values {
+		pairs = append(pairs, "{"+name+"}", escape(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// Config describes how to talk to a custom tracker. It is read from the
+// integration's credentials.
+type Config struct {
+	BaseURL string
+	// IssueURL is a template for the issue's web page, used when the
+	// response has no URL field.
+	IssueURL  string
+	Headers   map[string]string
+	Endpoints map[string]endpoint
+	// Response maps issue fields to dot-separated paths in an issue object.
+	Response map[string]string
+	// Issue is the path to the issue object in create, get and resolve
+	// responses. An empty path means the response is the issue.
+	Issue string
+	// Items, Total and NextCursor are the paths to the issue array, the
+	// total count and the next page's cursor in list responses. An empty
+	// Items path means the response is the array.
+	Items      string
+	Total      string
+	NextCursor string
+	// Request maps input fields to dot-separated paths in request bodies.
+	Request map[string]string
+}
+
+// ParseConfig reads a tracker configuration from integration credentials.
+// base_url and the get endpoint are required.
+func ParseConfig(credentials map[string]string) (*Config, error) {
+	baseURL := strings.TrimRight(credentials[keyBaseURL], "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("custom: base_url is required")
+	}
+	if u, err := url.Parse(baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("custom: base_url must be an http(s) URL")
+	}
+
+	cfg := &Config{
+		BaseURL:    baseURL,
+		IssueURL:   credentials[keyIssueURL],
+		Headers:    make(map[string]string),
+		Endpoints:  make(map[string]endpoint),
+		Response:   make(map[string]string, len(defaultResponseFields)),
+		Issue:      credentials[responseIssueKey],
+		Items:      credentials[responseItemsKey],
+		Total:      credentials[responseTotalKey],
+		NextCursor: credentials[responseCursorKey],
+		Request:    make(map[string]string, len(defaultRequestFields)),
+	}
+	for field, path := range defaultResponseFields {
+		cfg.Response[field] = path
+	}
+	for field, path := range defaultRequestFields {
+		cfg.Request[field] = path
+	}
+
+	for key, value := range credentials {
+		switch {
+		case strings.HasPrefix(key, headerPrefix):
+			name := strings.TrimPrefix(key, headerPrefix)
+			if name == "" {
+				return nil, fmt.Errorf("custom: header name is required")
+			}
+			cfg.Headers[name] = value
+		case key == responseIssueKey || key == responseItemsKey || key == responseTotalKey || key == responseCursorKey:
+		case strings.HasPrefix(key, responsePrefix):
+			field := strings.TrimPrefix(key, responsePrefix)
+			if _, ok := defaultResponseFields[field]; !ok {
+				return nil, fmt.Errorf("custom: unknown response field %q", field)
+			}
+			cfg.Response[field] = value
+		case strings.HasPrefix(key, requestPrefix):
+			field := strings.TrimPrefix(key, requestPrefix)
+			if _, ok := defaultRequestFields[field]; !ok {
+				return nil, fmt.Errorf("custom: unknown request field %q", field)
+			}
+			cfg.Request[field] = value
+		}
+	}
+
+	for _, name := range []string{endpointCreate, endpointGet, endpointList, endpointResolve, endpointValidate} {
+		value, ok := credentials[name]
+		if !ok || value == "" {
+			continue
+		}
+		e, err := parseEndpoint(name, value)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Endpoints[name] = e
+	}
+	if _, ok := cfg.Endpoints[endpointGet]; !ok {
+		return nil, fmt.Errorf("custom: get endpoint is required")
+	}
+	if cfg.Response["id"] == "" {
+		return nil, fmt.Errorf("custom: response.id must not be empty")
+	}
+
+	return cfg, nil
+}
@@ -203,24 +203,30 @@ func (c *Client) GetIssue(ctx context.Context, externalID string) (*issuetracker
 }
 
 // ListIssues lists GitHub issues.
-func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesInput) ([]*issuetracker.Issue, int, error) {
+func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesInput) (*issuetracker.IssuePage, error) {
 	repository := input.Repository
 	if repository == "" {
 		if c.defaultOwner != "" && c.defaultRepo != "" {
 			repository = c.defaultOwner + "/" + c.defaultRepo
 		} else {
-			return nil, 0, fmt.Errorf("github: repository is required")
+			return nil, fmt.Errorf("github: repository is required")
 		}
 	}
 
 	owner, repo, err := parseOwnerRepo(repository)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
 	}
 
+	// GitHub pages by page number, so the offset is rounded down to a
+	// multiple of the limit.
 	url := fmt.Sprintf("%s/repos/%s/%s/issues?per_page=%d&page=%d",
-		c.baseURL, owner, repo,
-		input.Limit, (input.Offset/max(input.Limit, 1))+1)
+		c.baseURL, owner, repo, limit, input.Offset/limit+1)
 
 	if input.Status != "" {
 		url += "&state=" + input.Status
@@ -228,18 +234,18 @@ func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesIn
 
 	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, 0, fmt.Errorf("github: list issues failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("github: list issues failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var issues []githubIssue
 	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
-		return nil, 0, fmt.Errorf("github: failed to decode response: %w", err)
+		return nil, fmt.Errorf("github: failed to decode response: %w", err)
 	}
 
 	result := make([]*issuetracker.Issue, 0, len(issues))
@@ -247,8 +253,13 @@ func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesIn
 		result = append(result, c.toIssue(&issues[i], owner, repo))
 	}
 
-	// GitHub API doesn't return total count in list endpoint; approximate with result length.
-	return result, len(result), nil
+	// GitHub API doesn't return a total count in the list endpoint; the Link
+	// header tells whether there is a next page.
+	return &issuetracker.IssuePage{
+		Issues:  result,
+		Total:   issuetracker.UnknownTotal,
+		HasMore: strings.Contains(resp.Header.Get("Link"), `rel="next"`),
+	}, nil
 }
 
 // ResolveIssue closes a GitHub issue.
@@ -307,6 +318,9 @@ func (c *Client) ValidateConnection(ctx context.Context) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %w: status %d", issuetracker.ErrConnectionFailed, issuetracker.ErrUnauthorized, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("%w: unexpected status %d", issuetracker.ErrConnectionFailed, resp.StatusCode)
 	}
@@ -194,7 +194,10 @@ func TestListIssues(t *testing.T) {
 	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "GET", r.Method)
 		assert.Contains(t, r.URL.Path, "/repos/owner/repo/issues")
+		assert.Equal(t, "2", r.URL.Query().Get("per_page"))
+		assert.Equal(t, "3", r.URL.Query().Get("page"))
 
+		w.Header().Set("Link", `<https://api.github.com/repos/owner/repo/issues?page=4>; rel="next"`)
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode([]map[string]interface{}{
 			{
@@ -219,15 +222,16 @@ func TestListIssues(t *testing.T) {
 	}))
 	defer server.Close()
 
-	issues, total, err := client.ListIssues(context.Background(), issuetracker.ListIssuesInput{
-		Limit:  20,
-		Offset: 0,
+	page, err := client.ListIssues(context.Background(), issuetracker.ListIssuesInput{
+		Limit:  2,
+		Offset: 4,
 	})
 	require.NoError(t, err)
-	assert.Equal(t, 2, total)
-	assert.Len(t, issues, 2)
-	assert.Equal(t, "owner/repo#1", issues[0].ExternalID)
-	assert.Equal(t, "owner/repo#2", issues[1].ExternalID)
+	assert.Equal(t, issuetracker.UnknownTotal, page.Total)
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Issues, 2)
+	assert.Equal(t, "owner/repo#1", page.Issues[0].ExternalID)
+	assert.Equal(t, "owner/repo#2", page.Issues[1].ExternalID)
 }
 
 func TestResolveIssue(t *testing.T) {
@@ -290,6 +294,7 @@ func TestValidateConnectionFailed(t *testing.T) {
 	err := client.ValidateConnection(context.Background())
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, issuetracker.ErrConnectionFailed)
+	assert.ErrorIs(t, err, issuetracker.ErrUnauthorized)
 }
 
 func TestParseExternalID(t *testing.T) {
@@ -0,0 +1,49 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+)
+
+// issuesEvent is the payload of GitHub's "issues" webhook event.
+type issuesEvent struct {
+	Action     string      `json:"action"`
+	Issue      githubIssue `json:"issue"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// ParseWebhook verifies the X-Hub-Signature-256 header of a GitHub webhook
+// delivery and returns the issue of an "issues" event. Other events, such
+// as the ping sent when the webhook is created, return
+// issuetracker.ErrUnsupportedEvent.
+func ParseWebhook(secret string, header http.Header, body []byte) (*issuetracker.Issue, error) {
+	if err := issuetracker.VerifySignature(secret, body, header.Get("X-Hub-Signature-256")); err != nil {
+		return nil, err
+	}
+	if header.Get("X-GitHub-Event") != "issues" {
+		return nil, issuetracker.ErrUnsupportedEvent
+	}
+
+	var event issuesEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("github: failed to decode webhook payload: %w", err)
+	}
+	if event.Action == "deleted" {
+		return nil, issuetracker.ErrUnsupportedEvent
+	}
+
+	owner, repo, err := parseOwnerRepo(event.Repository.FullName)
+	if err != nil {
+		return nil, err
+	}
+	if event.Issue.Number == 0 {
+		return nil, fmt.Errorf("github: webhook payload has no issue")
+	}
+
+	return (&Client{}).toIssue(&event.Issue, owner, repo), nil
+}
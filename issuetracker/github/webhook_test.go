@@ -0,0 +1,63 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func webhookHeader(secret, event string, body []byte) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	h := http.Header{}
+	h.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	h.Set("X-GitHub-Event", event)
+	return h
+}
+
+func TestParseWebhook(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{
+		"action": "closed",
+		"issue": {"number": 42, "title": "Login fails", "state": "closed", "html_url": "https://github.com/owner/repo/issues/42"},
+		"repository": {"full_name": "owner/repo"}
+	}`)
+
+	t.Run("issues event", func(t *testing.T) {
+		t.Parallel()
+		issue, err := ParseWebhook("s3cret", webhookHeader("s3cret", "issues", body), body)
+		require.NoError(t, err)
+		assert.Equal(t, "owner/repo#42", issue.ExternalID)
+		assert.Equal(t, "Login fails", issue.Title)
+		assert.Equal(t, "closed", issue.Status)
+		assert.Equal(t, "https://github.com/owner/repo/issues/42", issue.URL)
+		assert.Equal(t, issuetracker.ProviderGitHub, issue.Provider)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseWebhook("s3cret", webhookHeader("other", "issues", body), body)
+		assert.ErrorIs(t, err, issuetracker.ErrInvalidSignature)
+	})
+
+	t.Run("ping event", func(t *testing.T) {
+		t.Parallel()
+		ping := []byte(`{"zen":"Keep it logically awesome."}`)
+		_, err := ParseWebhook("s3cret", webhookHeader("s3cret", "ping", ping), ping)
+		assert.ErrorIs(t, err, issuetracker.ErrUnsupportedEvent)
+	})
+
+	t.Run("malformed payload", func(t *testing.T) {
+		t.Parallel()
+		bad := []byte(`{`)
+		_, err := ParseWebhook("s3cret", webhookHeader("s3cret", "issues", bad), bad)
+		assert.Error(t, err)
+	})
+}
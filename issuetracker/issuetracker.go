@@ -10,6 +10,9 @@ var (
 	ErrIssueNotFound    = errors.New("issue not found")
 	ErrInvalidProvider  = errors.New("invalid provider type")
 	ErrConnectionFailed = errors.New("connection validation failed")
+	// ErrUnauthorized is returned when the tracker refuses the configured
+	// credentials (401 or 403).
+	ErrUnauthorized = errors.New("tracker refused the credentials")
 )
 
 type ProviderType string
@@ -17,10 +20,13 @@ type ProviderType string
 const (
 	ProviderJira   ProviderType = "jira"
 	ProviderGitHub ProviderType = "github"
+	// ProviderCustom is a tracker with a JSON REST API described by the
+	// integration's credentials.
+	ProviderCustom ProviderType = "custom"
 )
 
 func (p ProviderType) IsValid() bool {
-	return p == ProviderJira || p == ProviderGitHub
+	return p == ProviderJira || p == ProviderGitHub || p == ProviderCustom
 }
 
 type Issue struct {
@@ -50,6 +56,25 @@ type ListIssuesInput struct {
 	Query      string `json:"query"`
 	Limit      int    `json:"limit"`
 	Offset     int    `json:"offset"`
+	// Cursor continues a listing from IssuePage.NextCursor on trackers that
+	// page by cursor. It takes the place of Offset when set.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// UnknownTotal is the IssuePage.Total of trackers that do not report how
+// many issues match.
+const UnknownTotal = -1
+
+// IssuePage is a page of issues returned by ListIssues.
+type IssuePage struct {
+	Issues []*Issue
+	// Total is the number of matching issues, or UnknownTotal.
+	Total int
+	// HasMore reports whether another page follows.
+	HasMore bool
+	// NextCursor is the cursor of the next page on trackers that page by
+	// cursor, and empty otherwise.
+	NextCursor string
 }
 
 type ResolveInput struct {
@@ -60,7 +85,7 @@ type ResolveInput struct {
 type Client interface {
 	CreateIssue(ctx context.Context, input CreateIssueInput) (*Issue, error)
 	GetIssue(ctx context.Context, externalID string) (*Issue, error)
-	ListIssues(ctx context.Context, input ListIssuesInput) ([]*Issue, int, error)
+	ListIssues(ctx context.Context, input ListIssuesInput) (*IssuePage, error)
 	ResolveIssue(ctx context.Context, externalID string, input ResolveInput) (*Issue, error)
 	ValidateConnection(ctx context.Context) error
 }
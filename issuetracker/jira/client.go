@@ -203,7 +203,7 @@ func (c *Client) GetIssue(ctx context.Context, externalID string) (*issuetracker
 }
 
 // ListIssues lists Jira issues using JQL search.
-func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesInput) ([]*issuetracker.Issue, int, error) {
+func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesInput) (*issuetracker.IssuePage, error) {
 	projectKey := input.ProjectKey
 	if projectKey == "" {
 		projectKey = c.defaultProject
@@ -238,13 +238,13 @@ func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesIn
 
 	resp, err := c.doRequest(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, 0, fmt.Errorf("jira: search issues failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("jira: search issues failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var searchResult struct {
@@ -254,7 +254,7 @@ func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesIn
 		StartAt    int         `json:"startAt"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&searchResult); err != nil {
-		return nil, 0, fmt.Errorf("jira: failed to decode response: %w", err)
+		return nil, fmt.Errorf("jira: failed to decode response: %w", err)
 	}
 
 	result := make([]*issuetracker.Issue, 0, len(searchResult.Issues))
@@ -262,7 +262,11 @@ func (c *Client) ListIssues(ctx context.Context, input issuetracker.ListIssuesIn
 		result = append(result, c.toIssue(&searchResult.Issues[i]))
 	}
 
-	return result, searchResult.Total, nil
+	return &issuetracker.IssuePage{
+		Issues:  result,
+		Total:   searchResult.Total,
+		HasMore: searchResult.StartAt+len(result) < searchResult.Total,
+	}, nil
 }
 
 // ResolveIssue transitions a Jira issue to Done/Resolved status.
@@ -367,6 +371,9 @@ func (c *Client) ValidateConnection(ctx context.Context) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %w: status %d", issuetracker.ErrConnectionFailed, issuetracker.ErrUnauthorized, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("%w: unexpected status %d", issuetracker.ErrConnectionFailed, resp.StatusCode)
 	}
@@ -223,6 +223,8 @@ func TestListIssues(t *testing.T) {
 	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "GET", r.Method)
 		assert.Contains(t, r.URL.Path, "/rest/api/3/search")
+		assert.Equal(t, "2", r.URL.Query().Get("maxResults"))
+		assert.Equal(t, "4", r.URL.Query().Get("startAt"))
 
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -250,22 +252,23 @@ func TestListIssues(t *testing.T) {
 					},
 				},
 			},
-			"total":      2,
-			"maxResults": 20,
-			"startAt":    0,
+			"total":      7,
+			"maxResults": 2,
+			"startAt":    4,
 		})
 	}))
 	defer server.Close()
 
-	issues, total, err := client.ListIssues(context.Background(), issuetracker.ListIssuesInput{
-		Limit:  20,
-		Offset: 0,
+	page, err := client.ListIssues(context.Background(), issuetracker.ListIssuesInput{
+		Limit:  2,
+		Offset: 4,
 	})
 	require.NoError(t, err)
-	assert.Equal(t, 2, total)
-	assert.Len(t, issues, 2)
-	assert.Equal(t, "TEST-1", issues[0].ExternalID)
-	assert.Equal(t, "TEST-2", issues[1].ExternalID)
+	assert.Equal(t, 7, page.Total)
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Issues, 2)
+	assert.Equal(t, "TEST-1", page.Issues[0].ExternalID)
+	assert.Equal(t, "TEST-2", page.Issues[1].ExternalID)
 }
 
 func TestResolveIssue(t *testing.T) {
@@ -360,4 +363,5 @@ func TestValidateConnectionFailed(t *testing.T) {
 	err := client.ValidateConnection(context.Background())
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, issuetracker.ErrConnectionFailed)
+	assert.ErrorIs(t, err, issuetracker.ErrUnauthorized)
 }
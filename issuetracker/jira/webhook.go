@@ -0,0 +1,45 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+)
+
+// webhookEvent is the payload of a Jira issue webhook event.
+type webhookEvent struct {
+	WebhookEvent string    `json:"webhookEvent"`
+	Issue        jiraIssue `json:"issue"`
+}
+
+// ParseWebhook verifies the X-Hub-Signature header of a Jira webhook
+// delivery and returns the issue of a "jira:issue_created" or
+// "jira:issue_updated" event. Other events return
+// issuetracker.ErrUnsupportedEvent.
+func ParseWebhook(secret string, header http.Header, body []byte) (*issuetracker.Issue, error) {
+	if err := issuetracker.VerifySignature(secret, body, header.Get("X-Hub-Signature")); err != nil {
+		return nil, err
+	}
+
+	var event webhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("jira: failed to decode webhook payload: %w", err)
+	}
+	if event.WebhookEvent != "jira:issue_created" && event.WebhookEvent != "jira:issue_updated" {
+		return nil, issuetracker.ErrUnsupportedEvent
+	}
+	if event.Issue.Key == "" {
+		return nil, fmt.Errorf("jira: webhook payload has no issue")
+	}
+
+	// The payload carries the issue's REST URL; the site's base URL is
+	// what precedes its /rest/ path.
+	i := strings.Index(event.Issue.Self, "/rest/")
+	if i < 0 {
+		return nil, fmt.Errorf("jira: webhook payload has no issue URL")
+	}
+	return (&Client{baseURL: event.Issue.Self[:i]}).toIssue(&event.Issue), nil
+}
@@ -0,0 +1,59 @@
+package jira
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/issuetracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func webhookHeader(secret string, body []byte) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	h := http.Header{}
+	h.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return h
+}
+
+func TestParseWebhook(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{
+		"webhookEvent": "jira:issue_updated",
+		"issue": {
+			"id": "10001",
+			"key": "PROJ-7",
+			"self": "https://example.atlassian.net/rest/api/3/issue/10001",
+			"fields": {"summary": "Login fails", "status": {"name": "Done"}}
+		}
+	}`)
+
+	t.Run("issue updated", func(t *testing.T) {
+		t.Parallel()
+		issue, err := ParseWebhook("s3cret", webhookHeader("s3cret", body), body)
+		require.NoError(t, err)
+		assert.Equal(t, "PROJ-7", issue.ExternalID)
+		assert.Equal(t, "Login fails", issue.Title)
+		assert.Equal(t, "Done", issue.Status)
+		assert.Equal(t, "https://example.atlassian.net/browse/PROJ-7", issue.URL)
+		assert.Equal(t, issuetracker.ProviderJira, issue.Provider)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseWebhook("s3cret", webhookHeader("other", body), body)
+		assert.ErrorIs(t, err, issuetracker.ErrInvalidSignature)
+	})
+
+	t.Run("issue deleted", func(t *testing.T) {
+		t.Parallel()
+		deleted := []byte(`{"webhookEvent":"jira:issue_deleted","issue":{"key":"PROJ-7"}}`)
+		_, err := ParseWebhook("s3cret", webhookHeader("s3cret", deleted), deleted)
+		assert.ErrorIs(t, err, issuetracker.ErrUnsupportedEvent)
+	})
+}
@@ -0,0 +1,92 @@
+package issuetracker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+)
+
+// ResilientClient wraps a Client so that every call runs under a circuit
+// breaker with a per-call deadline. A slow or failing tracker instance trips
+// its breaker and subsequent calls fail fast with resilience.ErrCircuitOpen.
+type ResilientClient struct {
+	client  Client
+	breaker *resilience.Breaker
+}
+
+// NewResilientClient wraps client with the given breaker.
+func NewResilientClient(client Client, breaker *resilience.Breaker) *ResilientClient {
+	return &ResilientClient{
+		client:  client,
+		breaker: breaker,
+	}
+}
+
+// call runs fn under the breaker. "Not found" answers and refused
+// credentials are healthy responses from the tracker, so they are passed
+// through without counting as failures.
+func (c *ResilientClient) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	var answer error
+	err := c.breaker.Execute(ctx, func(ctx context.Context) error {
+		err := fn(ctx)
+		if errors.Is(err, ErrIssueNotFound) || errors.Is(err, ErrUnauthorized) {
+			answer = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return answer
+}
+
+// CreateIssue creates an issue through the breaker.
+func (c *ResilientClient) CreateIssue(ctx context.Context, input CreateIssueInput) (*Issue, error) {
+	var issue *Issue
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		issue, err = c.client.CreateIssue(ctx, input)
+		return err
+	})
+	return issue, err
+}
+
+// GetIssue gets an issue through the breaker.
+func (c *ResilientClient) GetIssue(ctx context.Context, externalID string) (*Issue, error) {
+	var issue *Issue
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		issue, err = c.client.GetIssue(ctx, externalID)
+		return err
+	})
+	return issue, err
+}
+
+// ListIssues lists issues through the breaker.
+func (c *ResilientClient) ListIssues(ctx context.Context, input ListIssuesInput) (*IssuePage, error) {
+	var page *IssuePage
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		page, err = c.client.ListIssues(ctx, input)
+		return err
+	})
+	return page, err
+}
+
+// ResolveIssue resolves an issue through the breaker.
+func (c *ResilientClient) ResolveIssue(ctx context.Context, externalID string, input ResolveInput) (*Issue, error) {
+	var issue *Issue
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		issue, err = c.client.ResolveIssue(ctx, externalID, input)
+		return err
+	})
+	return issue, err
+}
+
+// ValidateConnection validates the connection through the breaker.
+func (c *ResilientClient) ValidateConnection(ctx context.Context) error {
+	return c.call(ctx, c.client.ValidateConnection)
+}
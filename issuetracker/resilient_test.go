@@ -0,0 +1,102 @@
+package issuetracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubClient struct {
+	getErr      error
+	getCalls    int
+	validateErr error
+}
+
+func (s *stubClient) CreateIssue(ctx context.Context, input CreateIssueInput) (*Issue, error) {
+	return &Issue{Title: input.Title}, nil
+}
+
+func (s *stubClient) GetIssue(ctx context.Context, externalID string) (*Issue, error) {
+	s.getCalls++
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	return &Issue{ExternalID: externalID}, nil
+}
+
+func (s *stubClient) ListIssues(ctx context.Context, input ListIssuesInput) (*IssuePage, error) {
+	return &IssuePage{Issues: []*Issue{{ExternalID: "1"}}, Total: 1}, nil
+}
+
+func (s *stubClient) ResolveIssue(ctx context.Context, externalID string, input ResolveInput) (*Issue, error) {
+	return &Issue{ExternalID: externalID, Status: "closed"}, nil
+}
+
+func (s *stubClient) ValidateConnection(ctx context.Context) error {
+	return s.validateErr
+}
+
+func TestResilientClient(t *testing.T) {
+	t.Parallel()
+
+	cfg := resilience.Config{FailureThreshold: 2, OpenDuration: time.Minute}
+
+	t.Run("passes results through", func(t *testing.T) {
+		t.Parallel()
+		c := NewResilientClient(&stubClient{}, resilience.NewBreaker("t", cfg))
+
+		issue, err := c.GetIssue(context.Background(), "ABC-1")
+		require.NoError(t, err)
+		assert.Equal(t, "ABC-1", issue.ExternalID)
+
+		page, err := c.ListIssues(context.Background(), ListIssuesInput{})
+		require.NoError(t, err)
+		assert.Len(t, page.Issues, 1)
+		assert.Equal(t, 1, page.Total)
+	})
+
+	t.Run("not found does not trip the breaker", func(t *testing.T) {
+		t.Parallel()
+		b := resilience.NewBreaker("t", cfg)
+		c := NewResilientClient(&stubClient{getErr: ErrIssueNotFound}, b)
+
+		for i := 0; i < 3; i++ {
+			_, err := c.GetIssue(context.Background(), "ABC-1")
+			assert.ErrorIs(t, err, ErrIssueNotFound)
+		}
+		assert.Equal(t, resilience.StateClosed, b.State())
+	})
+
+	t.Run("refused credentials do not trip the breaker", func(t *testing.T) {
+		t.Parallel()
+		b := resilience.NewBreaker("t", cfg)
+		refused := fmt.Errorf("%w: %w: status 401", ErrConnectionFailed, ErrUnauthorized)
+		c := NewResilientClient(&stubClient{validateErr: refused}, b)
+
+		for i := 0; i < 3; i++ {
+			err := c.ValidateConnection(context.Background())
+			assert.ErrorIs(t, err, ErrUnauthorized)
+		}
+		assert.Equal(t, resilience.StateClosed, b.State())
+	})
+
+	t.Run("failures open the circuit", func(t *testing.T) {
+		t.Parallel()
+		stub := &stubClient{getErr: errors.New("503 service unavailable")}
+		c := NewResilientClient(stub, resilience.NewBreaker("t", cfg))
+
+		for i := 0; i < 2; i++ {
+			_, err := c.GetIssue(context.Background(), "ABC-1")
+			require.Error(t, err)
+		}
+		_, err := c.GetIssue(context.Background(), "ABC-1")
+		assert.ErrorIs(t, err, resilience.ErrCircuitOpen)
+		assert.Equal(t, 2, stub.getCalls)
+	})
+}
@@ -0,0 +1,48 @@
+package issuetracker
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnsupportedEvent = errors.New("unsupported webhook event")
+)
+
+// WebhookParser verifies and parses the deliveries issue trackers push to
+// their webhook receivers.
+type WebhookParser interface {
+	// ParseWebhook verifies a delivery's signature with the webhook secret
+	// and returns the issue it reports. It returns ErrInvalidSignature if
+	// the signature does not match, and ErrUnsupportedEvent for events that
+	// do not report an issue's state.
+	ParseWebhook(provider ProviderType, secret string, header http.Header, body []byte) (*Issue, error)
+}
+
+// VerifySignature checks a "sha256=<hex>" signature header against the
+// HMAC-SHA256 of the body keyed with the secret, as sent by GitHub and Jira.
+func VerifySignature(secret string, body []byte, signature string) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
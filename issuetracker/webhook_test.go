@@ -0,0 +1,71 @@
+package issuetracker
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"action":"closed"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		wantErr   bool
+	}{
+		{
+			name:      "valid signature",
+			secret:    "s3cret",
+			signature: sign("s3cret", body),
+		},
+		{
+			name:      "wrong secret",
+			secret:    "s3cret",
+			signature: sign("other", body),
+			wantErr:   true,
+		},
+		{
+			name:      "missing prefix",
+			secret:    "s3cret",
+			signature: sign("s3cret", body)[len("sha256="):],
+			wantErr:   true,
+		},
+		{
+			name:      "not hex",
+			secret:    "s3cret",
+			signature: "sha256=zz",
+			wantErr:   true,
+		},
+		{
+			name:      "empty secret",
+			secret:    "",
+			signature: sign("", body),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := VerifySignature(tt.secret, body, tt.signature)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package job
 import (
 	"testing"
 
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
 	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
 	"gorm.io/gorm"
@@ -11,10 +12,18 @@ import (
 // setupTestStore creates a test database and job store for testing.
 func setupTestStore(t *testing.T) (*gorm.DB, Store) {
 	db := testutil.SetupTestDB(t)
-	testutil.AutoMigrate(t, db, &Job{})
+	testutil.AutoMigrate(t, db, &Job{}, &endpoint.Endpoint{})
 
 	log := logger.NewTestLogger()
 	store := NewMySQLStore(db, log)
 
 	return db, store
 }
+
+// setupTestLogStore creates a test database and job log store for testing.
+func setupTestLogStore(t *testing.T) *MySQLLogStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &LogEntry{})
+
+	return NewMySQLLogStore(db, logger.NewTestLogger())
+}
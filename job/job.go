@@ -17,8 +17,12 @@ var (
 	ErrInvalidStatus    = errors.New("invalid job status")
 	ErrJobAlreadyStarted = errors.New("job already started")
 	ErrJobNotRunning    = errors.New("job is not running")
+	ErrInvalidStaleAction = errors.New("stale job action must be fail or requeue")
 )
 
+// StaleJobReason is the error recorded on jobs failed by the reaper.
+const StaleJobReason = "job stopped sending heartbeats; the server running it probably stopped"
+
 type Status string
 
 const (
@@ -27,25 +31,49 @@ const (
 	StatusStopped Status = "stopped"
 	StatusFailed  Status = "failed"
 	StatusSuccess Status = "success"
+	// StatusInterrupted marks a job that was running when the server shut
+	// down. Interrupted jobs are queued again on the next start.
+	StatusInterrupted Status = "interrupted"
 )
 
 func (s Status) IsValid() bool {
 	switch s {
-	case StatusCreated, StatusRunning, StatusStopped, StatusFailed, StatusSuccess:
+	case StatusCreated, StatusRunning, StatusStopped, StatusFailed, StatusSuccess, StatusInterrupted:
 		return true
 	}
 	return false
 }
 
+// IsFinal reports whether a job in this status has finished.
+func (s Status) IsFinal() bool {
+	return s == StatusStopped || s == StatusFailed || s == StatusSuccess
+}
+
+// StaleAction is what the reaper does with a running job whose heartbeat
+// is stale.
+type StaleAction string
+
+const (
+	StaleActionFail    StaleAction = "fail"
+	StaleActionRequeue StaleAction = "requeue"
+)
+
+func (a StaleAction) IsValid() bool {
+	return a == StaleActionFail || a == StaleActionRequeue
+}
+
 type JobType string
 
 const (
-	JobTypeUIExploration JobType = "ui_exploration"
+	JobTypeUIExploration      JobType = "ui_exploration"
+	JobTypeVisualRegression   JobType = "visual_regression"
+	JobTypeLinkCheck          JobType = "link_check"
+	JobTypeProcedureExecution JobType = "procedure_execution"
 )
 
 func (jt JobType) IsValid() bool {
 	switch jt {
-	case JobTypeUIExploration:
+	case JobTypeUIExploration, JobTypeVisualRegression, JobTypeLinkCheck, JobTypeProcedureExecution:
 		return true
 	}
 	return false
@@ -79,17 +107,26 @@ func (j *JSONMap) Scan(value interface{}) error {
 }
 
 type Job struct {
-	ID        uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
-	Type      JobType    `json:"type" gorm:"column:type;type:varchar(50);not null"`
-	Status    Status     `json:"status" gorm:"type:varchar(20);not null;default:'created'"`
-	Config    JSONMap    `json:"config" gorm:"type:json"`
-	Result    JSONMap    `json:"result" gorm:"type:json"`
-	StartTime *time.Time `json:"start_time,omitempty"`
-	EndTime   *time.Time `json:"end_time,omitempty"`
-	Duration  *int64     `json:"duration,omitempty"`
-	CreatedBy uuid.UUID  `json:"created_by" gorm:"type:char(36);not null;index:idx_jobs_created_by"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID         uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	Type       JobType    `json:"type" gorm:"column:type;type:varchar(50);not null"`
+	Status     Status     `json:"status" gorm:"type:varchar(20);not null;default:'created'"`
+	Config     JSONMap    `json:"config" gorm:"type:json"`
+	Result     JSONMap    `json:"result" gorm:"type:json"`
+	EndpointID *uuid.UUID `json:"endpoint_id,omitempty" gorm:"type:char(36)"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	EndTime    *time.Time `json:"end_time,omitempty"`
+	Duration   *int64     `json:"duration,omitempty"`
+	CreatedBy  uuid.UUID  `json:"created_by" gorm:"type:char(36);not null;index:idx_jobs_created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	// HeartbeatAt is when the worker running the job last reported that it
+	// is alive. Running jobs with a stale heartbeat are reaped.
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
+	// ProgressDone and ProgressTotal report how far a running job has got,
+	// such as pages explored out of the pages the agent expects to explore.
+	// ProgressTotal is 0 until the job has reported an estimate.
+	ProgressDone  int `json:"progress_done" gorm:"not null;default:0"`
+	ProgressTotal int `json:"progress_total" gorm:"not null;default:0"`
 }
 
 func (j *Job) BeforeCreate(tx *gorm.DB) error {
@@ -117,6 +154,7 @@ func (j *Job) Start() error {
 	now := time.Now()
 	j.Status = StatusRunning
 	j.StartTime = &now
+	j.HeartbeatAt = &now
 	return nil
 }
 
@@ -129,9 +167,61 @@ func (j *Job) Complete(status Status, result JSONMap) error {
 	j.Status = status
 	j.EndTime = &now
 	j.Result = result
+	if status == StatusSuccess && j.ProgressTotal > 0 {
+		j.ProgressDone = j.ProgressTotal
+	}
 	if j.StartTime != nil {
 		duration := now.Sub(*j.StartTime).Milliseconds()
 		j.Duration = &duration
 	}
 	return nil
 }
+
+// Interrupt marks a running job as interrupted by a server shutdown.
+func (j *Job) Interrupt() error {
+	if j.Status != StatusRunning {
+		return ErrJobNotRunning
+	}
+	j.Status = StatusInterrupted
+	return nil
+}
+
+// Requeue moves a running or interrupted job back to created so that a
+// worker claims and runs it again.
+func (j *Job) Requeue() error {
+	if j.Status != StatusRunning && j.Status != StatusInterrupted {
+		return ErrJobNotRunning
+	}
+	j.Status = StatusCreated
+	j.StartTime = nil
+	j.HeartbeatAt = nil
+	j.ProgressDone = 0
+	j.ProgressTotal = 0
+	return nil
+}
+
+// LastSeen returns when the job last showed signs of life: its latest
+// heartbeat, or its start time for jobs that have not sent one.
+func (j *Job) LastSeen() *time.Time {
+	if j.HeartbeatAt != nil {
+		return j.HeartbeatAt
+	}
+	return j.StartTime
+}
+
+// ProgressPercent returns the share of the job's estimated work that is
+// done, from 0 to 100. Running jobs stay below 100 until they finish, since
+// the estimate may turn out too low.
+func (j *Job) ProgressPercent() int {
+	if j.Status == StatusSuccess {
+		return 100
+	}
+	if j.ProgressTotal <= 0 {
+		return 0
+	}
+	percent := j.ProgressDone * 100 / j.ProgressTotal
+	if percent > 99 {
+		percent = 99
+	}
+	return percent
+}
@@ -0,0 +1,52 @@
+package job
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidJobID is returned when a log line has no job.
+var ErrInvalidJobID = errors.New("job_id is required")
+
+// MaxLogLineLength is the longest log line stored; longer lines are truncated.
+const MaxLogLineLength = 4096
+
+// LogEntry is one line of a job's log. Seq numbers the lines of a job from 1
+// so readers can ask for the lines after the last one they saw.
+type LogEntry struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	JobID     uuid.UUID `json:"job_id" gorm:"type:char(36);not null;uniqueIndex:idx_job_logs_job_seq"`
+	Seq       int       `json:"seq" gorm:"not null;uniqueIndex:idx_job_logs_job_seq"`
+	Message   string    `json:"message" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for LogEntry.
+func (LogEntry) TableName() string {
+	return "job_logs"
+}
+
+// BeforeCreate hook to generate UUID before creating a new log entry.
+func (e *LogEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// truncateLogLine shortens line to MaxLogLineLength bytes without splitting a
+// UTF-8 character.
+func truncateLogLine(line string) string {
+	if len(line) <= MaxLogLineLength {
+		return line
+	}
+	cut := MaxLogLineLength
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut]
+}
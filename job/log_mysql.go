@@ -0,0 +1,75 @@
+package job
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLLogStore implements the LogStore interface using GORM and MySQL.
+type MySQLLogStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLLogStore creates a new MySQL-backed job log store.
+func NewMySQLLogStore(db *gorm.DB, log logger.Logger) *MySQLLogStore {
+	return &MySQLLogStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Append adds a line to the end of a job's log. The line is numbered one past
+// the job's current last line; the unique (job_id, seq) index rejects a
+// concurrent writer that picked the same number.
+func (s *MySQLLogStore) Append(ctx context.Context, jobID uuid.UUID, message string) (*LogEntry, error) {
+	if jobID == uuid.Nil {
+		return nil, ErrInvalidJobID
+	}
+	message = truncateLogLine(message)
+
+	entry := &LogEntry{JobID: jobID, Message: message}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last int
+		if err := tx.Model(&LogEntry{}).
+			Where("job_id = ?", jobID).
+			Select("COALESCE(MAX(seq), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		entry.Seq = last + 1
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		s.logger.Error(ctx, "failed to append job log", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID.String(),
+		})
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// ListAfter retrieves up to limit lines of a job's log after afterSeq, oldest first.
+func (s *MySQLLogStore) ListAfter(ctx context.Context, jobID uuid.UUID, afterSeq, limit int) ([]*LogEntry, error) {
+	var entries []*LogEntry
+	err := s.db.WithContext(ctx).
+		Where("job_id = ? AND seq > ?", jobID, afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&entries).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list job logs", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": jobID.String(),
+		})
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,100 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLLogStore_Append(t *testing.T) {
+	logStore := setupTestLogStore(t)
+	ctx := context.Background()
+
+	t.Run("numbers lines per job", func(t *testing.T) {
+		jobA, jobB := uuid.New(), uuid.New()
+
+		first, err := logStore.Append(ctx, jobA, "first")
+		require.NoError(t, err)
+		second, err := logStore.Append(ctx, jobA, "second")
+		require.NoError(t, err)
+		other, err := logStore.Append(ctx, jobB, "other")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, first.Seq)
+		assert.Equal(t, 2, second.Seq)
+		assert.Equal(t, 1, other.Seq)
+	})
+
+	t.Run("truncates long lines", func(t *testing.T) {
+		entry, err := logStore.Append(ctx, uuid.New(), strings.Repeat("é", MaxLogLineLength))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(entry.Message), MaxLogLineLength)
+		assert.True(t, strings.HasSuffix(entry.Message, "é"))
+	})
+
+	t.Run("missing job returns error", func(t *testing.T) {
+		_, err := logStore.Append(ctx, uuid.Nil, "line")
+		assert.ErrorIs(t, err, ErrInvalidJobID)
+	})
+}
+
+func TestMySQLLogStore_ListAfter(t *testing.T) {
+	logStore := setupTestLogStore(t)
+	ctx := context.Background()
+
+	jobID := uuid.New()
+	for i := 1; i <= 5; i++ {
+		_, err := logStore.Append(ctx, jobID, fmt.Sprintf("line %d", i))
+		require.NoError(t, err)
+	}
+
+	t.Run("returns lines after cursor in order", func(t *testing.T) {
+		entries, err := logStore.ListAfter(ctx, jobID, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, "line 3", entries[0].Message)
+		assert.Equal(t, 5, entries[2].Seq)
+	})
+
+	t.Run("respects limit", func(t *testing.T) {
+		entries, err := logStore.ListAfter(ctx, jobID, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, 2, entries[1].Seq)
+	})
+
+	t.Run("unknown job returns empty list", func(t *testing.T) {
+		entries, err := logStore.ListAfter(ctx, uuid.New(), 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestLogWriter(t *testing.T) {
+	logStore := setupTestLogStore(t)
+	ctx := context.Background()
+	jobID := uuid.New()
+
+	w := NewLogWriter(ctx, logStore, jobID, logger.NewTestLogger())
+	fmt.Fprint(w, "starting\npartial ")
+	fmt.Fprint(w, "line\r\n\n")
+	fmt.Fprint(w, "trailing")
+
+	entries, err := logStore.ListAfter(ctx, jobID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "starting", entries[0].Message)
+	assert.Equal(t, "partial line", entries[1].Message)
+
+	require.NoError(t, w.Close())
+	entries, err = logStore.ListAfter(ctx, jobID, 2, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "trailing", entries[0].Message)
+}
@@ -0,0 +1,17 @@
+package job
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// LogStore defines the interface for job log persistence.
+type LogStore interface {
+	// Append adds a line to the end of a job's log.
+	Append(ctx context.Context, jobID uuid.UUID, message string) (*LogEntry, error)
+
+	// ListAfter retrieves up to limit lines of a job's log with a sequence
+	// number greater than afterSeq, oldest first.
+	ListAfter(ctx context.Context, jobID uuid.UUID, afterSeq, limit int) ([]*LogEntry, error)
+}
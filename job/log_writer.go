@@ -0,0 +1,77 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// LogWriter is an io.Writer that appends each complete line written to it to
+// a job's log, e.g. the output of a subprocess. Failures to store a line are
+// logged and otherwise ignored so they never interrupt the job.
+type LogWriter struct {
+	ctx    context.Context
+	store  LogStore
+	jobID  uuid.UUID
+	logger logger.Logger
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewLogWriter creates a writer appending to the log of jobID.
+func NewLogWriter(ctx context.Context, store LogStore, jobID uuid.UUID, log logger.Logger) *LogWriter {
+	return &LogWriter{
+		ctx:    ctx,
+		store:  store,
+		jobID:  jobID,
+		logger: log,
+	}
+}
+
+// Write buffers p and stores every complete line.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.buf.Next(i + 1))
+		w.append(line)
+	}
+	return len(p), nil
+}
+
+// Close stores any trailing partial line.
+func (w *LogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.append(w.buf.String())
+		w.buf.Reset()
+	}
+	return nil
+}
+
+// append stores a single line, dropping blank ones. Callers must hold w.mu.
+func (w *LogWriter) append(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if _, err := w.store.Append(w.ctx, w.jobID, line); err != nil {
+		w.logger.Warn(w.ctx, "failed to store job log line", map[string]interface{}{
+			"error":  err.Error(),
+			"job_id": w.jobID.String(),
+		})
+	}
+}
@@ -3,8 +3,11 @@ package job
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
 	"gorm.io/gorm"
 )
@@ -98,6 +101,7 @@ func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...Update
 func (s *MySQLStore) ListByCreator(ctx context.Context, createdBy uuid.UUID, limit, offset int) ([]*Job, error) {
 	var jobs []*Job
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
 		Where("created_by = ?", createdBy).
 		Order("created_at DESC").
 		Limit(limit).
@@ -117,10 +121,33 @@ func (s *MySQLStore) ListByCreator(ctx context.Context, createdBy uuid.UUID, lim
 	return jobs, nil
 }
 
+// ListByCreatorAfter retrieves up to limit jobs created by a specific user,
+// newest first, after the cursor.
+func (s *MySQLStore) ListByCreatorAfter(ctx context.Context, createdBy uuid.UUID, after *database.Cursor, limit int) ([]*Job, error) {
+	var jobs []*Job
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica, database.After(after)).
+		Where("created_by = ?", createdBy).
+		Limit(limit).
+		Find(&jobs).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list jobs by creator after cursor", map[string]interface{}{
+			"error":      err.Error(),
+			"created_by": createdBy.String(),
+			"limit":      limit,
+		})
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
 // CountByCreator returns the total count of jobs created by a specific user.
 func (s *MySQLStore) CountByCreator(ctx context.Context, createdBy uuid.UUID) (int, error) {
 	var count int64
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
 		Model(&Job{}).
 		Where("created_by = ?", createdBy).
 		Count(&count).Error
@@ -140,6 +167,7 @@ func (s *MySQLStore) CountByCreator(ctx context.Context, createdBy uuid.UUID) (i
 func (s *MySQLStore) ListByType(ctx context.Context, jobType JobType, limit, offset int) ([]*Job, error) {
 	var jobs []*Job
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
 		Where("type = ?", jobType).
 		Order("created_at DESC").
 		Limit(limit).
@@ -195,13 +223,55 @@ func (s *MySQLStore) Start(ctx context.Context, id uuid.UUID) error {
 }
 
 // ClaimNextCreated atomically finds the oldest created job and transitions it to running.
+// Jobs against endpoints that use agent runners, or that already run as
+// many jobs as their max_concurrent_jobs allows, are skipped and stay queued.
 // Returns nil, nil if no created jobs are available.
 func (s *MySQLStore) ClaimNextCreated(ctx context.Context) (*Job, error) {
+	return s.claim(ctx, " AND (endpoints.use_runner IS NULL OR endpoints.use_runner = ?)", false)
+}
+
+// ClaimNextCreatedOn atomically finds the oldest created job of jobType that
+// createdBy created against one of endpoints, skipping endpoints that
+// already run as many jobs as they allow, and transitions it to running.
+// Returns nil, nil if no such job is available.
+func (s *MySQLStore) ClaimNextCreatedOn(ctx context.Context, jobType JobType, createdBy uuid.UUID, endpoints []uuid.UUID) (*Job, error) {
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+	return s.claim(ctx, " AND jobs.type = ? AND jobs.created_by = ? AND jobs.endpoint_id IN ?", jobType, createdBy, endpointIDStrings(endpoints))
+}
+
+// ClaimCreated atomically transitions the job to running if it is still
+// created and its endpoint, if any, runs fewer jobs than it allows.
+// Returns nil, nil if it is not.
+func (s *MySQLStore) ClaimCreated(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return s.claim(ctx, " AND jobs.id = ?", id)
+}
+
+// claim starts the oldest created job matching condition, inside a
+// transaction. Jobs against an endpoint are only started while it runs
+// fewer jobs than its max_concurrent_jobs: the running jobs are counted in
+// the same locking query, so two claims cannot both take an endpoint's last
+// slot. condition may refer to the job's endpoint as endpoints.
+func (s *MySQLStore) claim(ctx context.Context, condition string, conditionArgs ...interface{}) (*Job, error) {
 	var claimed *Job
 
+	// SQLite has no row locks; it serializes writers on its own.
+	lock := ""
+	if s.db.Dialector.Name() != "sqlite" {
+		lock = " FOR UPDATE"
+	}
+	query := "SELECT jobs.* FROM jobs LEFT JOIN endpoints ON endpoints.id = jobs.endpoint_id" +
+		" WHERE jobs.status = ? AND (jobs.endpoint_id IS NULL OR" +
+		" (SELECT COUNT(*) FROM jobs AS running WHERE running.endpoint_id = jobs.endpoint_id AND running.status = ?" + lock + ")" +
+		" < COALESCE(endpoints.max_concurrent_jobs, ?))" +
+		condition +
+		" ORDER BY jobs.created_at ASC LIMIT 1" + lock
+	args := append([]interface{}{StatusCreated, StatusRunning, endpoint.DefaultMaxConcurrentJobs}, conditionArgs...)
+
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var j Job
-		err := tx.Raw("SELECT * FROM jobs WHERE status = ? ORDER BY created_at ASC LIMIT 1 FOR UPDATE", StatusCreated).
+		err := tx.Raw(query, args...).
 			Scan(&j).Error
 		if err != nil {
 			return err
@@ -239,6 +309,43 @@ func (s *MySQLStore) ClaimNextCreated(ctx context.Context) (*Job, error) {
 	return claimed, nil
 }
 
+// endpointIDStrings formats endpoint IDs for an IN clause.
+func endpointIDStrings(ids []uuid.UUID) []string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strs
+}
+
+// CountRunningByEndpoint returns the number of running jobs for each endpoint
+// that currently has at least one running job.
+func (s *MySQLStore) CountRunningByEndpoint(ctx context.Context) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		EndpointID uuid.UUID
+		Count      int
+	}
+	err := s.db.WithContext(ctx).
+		Model(&Job{}).
+		Select("endpoint_id, COUNT(*) AS count").
+		Where("status = ? AND endpoint_id IS NOT NULL", StatusRunning).
+		Group("endpoint_id").
+		Scan(&rows).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to count running jobs by endpoint", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.EndpointID] = row.Count
+	}
+	return counts, nil
+}
+
 // Complete marks a job as finished with the given status and result.
 func (s *MySQLStore) Complete(ctx context.Context, id uuid.UUID, status Status, result JSONMap) error {
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -275,3 +382,229 @@ func (s *MySQLStore) Complete(ctx context.Context, id uuid.UUID, status Status,
 
 	return nil
 }
+
+// Interrupt marks a running job as interrupted by a server shutdown.
+func (s *MySQLStore) Interrupt(ctx context.Context, id uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var j Job
+		if err := tx.WithContext(ctx).Where("id = ?", id).First(&j).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrJobNotFound
+			}
+			return err
+		}
+
+		if err := j.Interrupt(); err != nil {
+			return err
+		}
+
+		return tx.WithContext(ctx).Save(&j).Error
+	})
+
+	if err != nil {
+		if !errors.Is(err, ErrJobNotFound) && !errors.Is(err, ErrJobNotRunning) {
+			s.logger.Error(ctx, "failed to interrupt job", map[string]interface{}{
+				"error":  err.Error(),
+				"job_id": id.String(),
+			})
+		}
+		return err
+	}
+
+	s.logger.Info(ctx, "job interrupted", map[string]interface{}{
+		"job_id": id.String(),
+	})
+
+	return nil
+}
+
+// RequeueInterrupted moves every interrupted job back to created so that
+// workers claim and run it again. It returns the number of jobs requeued.
+func (s *MySQLStore) RequeueInterrupted(ctx context.Context) (int, error) {
+	result := s.db.WithContext(ctx).
+		Model(&Job{}).
+		Where("status = ?", StatusInterrupted).
+		Updates(map[string]interface{}{
+			"status":         StatusCreated,
+			"start_time":     nil,
+			"heartbeat_at":   nil,
+			"progress_done":  0,
+			"progress_total": 0,
+		})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to requeue interrupted jobs", map[string]interface{}{
+			"error": result.Error.Error(),
+		})
+		return 0, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		s.logger.Info(ctx, "requeued interrupted jobs", map[string]interface{}{
+			"count": result.RowsAffected,
+		})
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// Requeue moves a running or interrupted job back to created so that it is
+// run again.
+func (s *MySQLStore) Requeue(ctx context.Context, id uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var j Job
+		if err := tx.WithContext(ctx).Where("id = ?", id).First(&j).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrJobNotFound
+			}
+			return err
+		}
+
+		if err := j.Requeue(); err != nil {
+			return err
+		}
+
+		return tx.WithContext(ctx).Save(&j).Error
+	})
+
+	if err != nil {
+		if !errors.Is(err, ErrJobNotFound) && !errors.Is(err, ErrJobNotRunning) {
+			s.logger.Error(ctx, "failed to requeue job", map[string]interface{}{
+				"error":  err.Error(),
+				"job_id": id.String(),
+			})
+		}
+		return err
+	}
+
+	s.logger.Info(ctx, "job requeued", map[string]interface{}{
+		"job_id": id.String(),
+	})
+
+	return nil
+}
+
+// ListIDsByStatus returns the IDs of every job in the given status, oldest
+// first.
+func (s *MySQLStore) ListIDsByStatus(ctx context.Context, status Status) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := s.db.WithContext(ctx).
+		Model(&Job{}).
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Pluck("id", &ids).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list job IDs by status", map[string]interface{}{
+			"error":  err.Error(),
+			"status": string(status),
+		})
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Heartbeat records that the worker running a job is still alive. It
+// returns ErrJobNotRunning when the job is not running.
+func (s *MySQLStore) Heartbeat(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Model(&Job{}).
+		Where("id = ? AND status = ?", id, StatusRunning).
+		Update("heartbeat_at", time.Now())
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to record job heartbeat", map[string]interface{}{
+			"error":  result.Error.Error(),
+			"job_id": id.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobNotRunning
+	}
+
+	return nil
+}
+
+// ReportProgress records how far a running job has got, which also counts
+// as a heartbeat. A total of 0 leaves the estimate unknown; a total below
+// done is raised to it. It returns ErrJobNotRunning when the job is not
+// running.
+func (s *MySQLStore) ReportProgress(ctx context.Context, id uuid.UUID, done, total int) error {
+	if total > 0 && total < done {
+		total = done
+	}
+
+	result := s.db.WithContext(ctx).
+		Model(&Job{}).
+		Where("id = ? AND status = ?", id, StatusRunning).
+		Updates(map[string]interface{}{
+			"progress_done":  done,
+			"progress_total": total,
+			"heartbeat_at":   time.Now(),
+		})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to record job progress", map[string]interface{}{
+			"error":  result.Error.Error(),
+			"job_id": id.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobNotRunning
+	}
+
+	return nil
+}
+
+// ReapStale fails or requeues, depending on action, every running job whose
+// last heartbeat, or start time if it has sent none, is before the given
+// time. It returns the reaped jobs.
+func (s *MySQLStore) ReapStale(ctx context.Context, before time.Time, action StaleAction) ([]*Job, error) {
+	if !action.IsValid() {
+		return nil, ErrInvalidStaleAction
+	}
+
+	query := "SELECT * FROM jobs WHERE status = ? AND COALESCE(heartbeat_at, start_time) < ?"
+	// SQLite has no row locks; it serializes writers on its own.
+	if s.db.Dialector.Name() != "sqlite" {
+		query += " FOR UPDATE"
+	}
+
+	var reaped []*Job
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var jobs []*Job
+		if err := tx.Raw(query, StatusRunning, before).Scan(&jobs).Error; err != nil {
+			return err
+		}
+
+		for _, j := range jobs {
+			var err error
+			if action == StaleActionRequeue {
+				err = j.Requeue()
+			} else {
+				err = j.Complete(StatusFailed, JSONMap{"error": StaleJobReason})
+			}
+			if err != nil {
+				return err
+			}
+			if err := tx.Save(j).Error; err != nil {
+				return err
+			}
+		}
+
+		reaped = jobs
+		return nil
+	})
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to reap stale jobs", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return reaped, nil
+}
@@ -3,10 +3,14 @@ package job
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestMySQLStore_Create(t *testing.T) {
@@ -193,6 +197,36 @@ func TestMySQLStore_ListByCreator(t *testing.T) {
 		assert.Empty(t, jobs)
 	})
 
+	t.Run("list after cursor pages without gaps or overlap", func(t *testing.T) {
+		createdBy := uuid.New()
+		for i := 0; i < 5; i++ {
+			require.NoError(t, store.Create(ctx, &Job{Type: JobTypeUIExploration, CreatedBy: createdBy}))
+		}
+
+		var seen []uuid.UUID
+		var after *database.Cursor
+		for {
+			page, err := store.ListByCreatorAfter(ctx, createdBy, after, 2)
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			for _, j := range page {
+				seen = append(seen, j.ID)
+			}
+			last := page[len(page)-1]
+			after = database.CursorOf(last.CreatedAt, last.ID)
+		}
+
+		all, err := store.ListByCreator(ctx, createdBy, 10, 0)
+		require.NoError(t, err)
+		want := make([]uuid.UUID, len(all))
+		for i, j := range all {
+			want[i] = j.ID
+		}
+		assert.ElementsMatch(t, want, seen)
+	})
+
 	t.Run("list does not return other users jobs", func(t *testing.T) {
 		user1 := uuid.New()
 		user2 := uuid.New()
@@ -312,6 +346,168 @@ func TestMySQLStore_Start(t *testing.T) {
 	})
 }
 
+// createEndpoint creates an endpoint allowing maxJobs concurrent jobs.
+func createEndpoint(t *testing.T, db *gorm.DB, maxJobs int, useRunner bool) *endpoint.Endpoint {
+	ep := &endpoint.Endpoint{
+		Name:              "shop",
+		URL:               "https://shop.example.com",
+		MaxConcurrentJobs: maxJobs,
+		UseRunner:         useRunner,
+		CreatedBy:         uuid.New(),
+	}
+	require.NoError(t, db.Create(ep).Error)
+	return ep
+}
+
+func TestMySQLStore_ClaimNextCreated(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("no created jobs returns nil", func(t *testing.T) {
+		claimed, err := store.ClaimNextCreated(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, claimed)
+	})
+
+	t.Run("claims a created job and skips saturated endpoints", func(t *testing.T) {
+		busy := createEndpoint(t, db, 2, false)
+		for i := 0; i < 2; i++ {
+			running := &Job{Type: JobTypeUIExploration, EndpointID: &busy.ID, CreatedBy: uuid.New()}
+			require.NoError(t, store.Create(ctx, running))
+			require.NoError(t, store.Start(ctx, running.ID))
+		}
+		queued := &Job{Type: JobTypeUIExploration, EndpointID: &busy.ID, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, queued))
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+
+		claimed, err := store.ClaimNextCreated(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, j.ID, claimed.ID)
+		assert.Equal(t, StatusRunning, claimed.Status)
+
+		claimed, err = store.ClaimNextCreated(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, claimed)
+
+		retrieved, err := store.GetByID(ctx, queued.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, retrieved.Status)
+
+		require.NoError(t, db.Model(busy).Update("max_concurrent_jobs", 3).Error)
+		claimed, err = store.ClaimNextCreated(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, queued.ID, claimed.ID)
+	})
+
+	t.Run("skips endpoints using agent runners", func(t *testing.T) {
+		remote := createEndpoint(t, db, 1, true)
+		j := &Job{Type: JobTypeUIExploration, EndpointID: &remote.ID, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+
+		claimed, err := store.ClaimNextCreated(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, claimed)
+	})
+}
+
+func TestMySQLStore_ClaimCreated(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	ep := createEndpoint(t, db, 1, false)
+
+	first := &Job{Type: JobTypeLinkCheck, EndpointID: &ep.ID, CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, first))
+	second := &Job{Type: JobTypeLinkCheck, EndpointID: &ep.ID, CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, second))
+
+	claimed, err := store.ClaimCreated(ctx, second.ID)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, second.ID, claimed.ID)
+
+	// The endpoint's only slot is taken
+	claimed, err = store.ClaimCreated(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// Running jobs are not claimed again
+	claimed, err = store.ClaimCreated(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+}
+
+func TestMySQLStore_ClaimNextCreatedOn(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	owner := uuid.New()
+	runnerEndpoint, otherEndpoint := uuid.New(), uuid.New()
+	others := []*Job{
+		{Type: JobTypeUIExploration, EndpointID: &runnerEndpoint, CreatedBy: uuid.New()},
+		{Type: JobTypeLinkCheck, EndpointID: &runnerEndpoint, CreatedBy: owner},
+		{Type: JobTypeUIExploration, EndpointID: &otherEndpoint, CreatedBy: owner},
+	}
+	for _, j := range others {
+		require.NoError(t, store.Create(ctx, j))
+	}
+	j := &Job{Type: JobTypeUIExploration, EndpointID: &runnerEndpoint, CreatedBy: owner}
+	require.NoError(t, store.Create(ctx, j))
+
+	claimed, err := store.ClaimNextCreatedOn(ctx, JobTypeUIExploration, owner, nil)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	claimed, err = store.ClaimNextCreatedOn(ctx, JobTypeUIExploration, owner, []uuid.UUID{runnerEndpoint})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, j.ID, claimed.ID)
+	assert.Equal(t, StatusRunning, claimed.Status)
+
+	claimed, err = store.ClaimNextCreatedOn(ctx, JobTypeUIExploration, owner, []uuid.UUID{runnerEndpoint})
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	for _, other := range others {
+		retrieved, err := store.GetByID(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, retrieved.Status)
+	}
+}
+
+func TestMySQLStore_CountRunningByEndpoint(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	endpointA := uuid.New()
+	endpointB := uuid.New()
+	createJob := func(endpointID *uuid.UUID, start bool) {
+		j := &Job{
+			Type:       JobTypeUIExploration,
+			EndpointID: endpointID,
+			CreatedBy:  uuid.New(),
+		}
+		require.NoError(t, store.Create(ctx, j))
+		if start {
+			require.NoError(t, store.Start(ctx, j.ID))
+		}
+	}
+
+	createJob(&endpointA, true)
+	createJob(&endpointA, true)
+	createJob(&endpointA, false)
+	createJob(&endpointB, true)
+	createJob(nil, true)
+
+	counts, err := store.CountRunningByEndpoint(ctx)
+	require.NoError(t, err)
+	assert.Len(t, counts, 2)
+	assert.Equal(t, 2, counts[endpointA])
+	assert.Equal(t, 1, counts[endpointB])
+}
+
 func TestMySQLStore_Complete(t *testing.T) {
 	_, store := setupTestStore(t)
 	ctx := context.Background()
@@ -450,3 +646,301 @@ func TestMySQLStore_StatusTransitions(t *testing.T) {
 		assert.Equal(t, StatusStopped, completed.Status)
 	})
 }
+
+func TestMySQLStore_Interrupt(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("interrupt running job", func(t *testing.T) {
+		j := &Job{
+			Type:      JobTypeUIExploration,
+			CreatedBy: uuid.New(),
+		}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+
+		require.NoError(t, store.Interrupt(ctx, j.ID))
+
+		retrieved, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusInterrupted, retrieved.Status)
+		assert.Nil(t, retrieved.EndTime)
+	})
+
+	t.Run("interrupt non-running job returns error", func(t *testing.T) {
+		j := &Job{
+			Type:      JobTypeUIExploration,
+			CreatedBy: uuid.New(),
+		}
+		require.NoError(t, store.Create(ctx, j))
+
+		err := store.Interrupt(ctx, j.ID)
+		assert.ErrorIs(t, err, ErrJobNotRunning)
+	})
+
+	t.Run("interrupt non-existent job returns error", func(t *testing.T) {
+		err := store.Interrupt(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
+
+func TestMySQLStore_RequeueInterrupted(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	interrupted := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, interrupted))
+	require.NoError(t, store.Start(ctx, interrupted.ID))
+	require.NoError(t, store.Interrupt(ctx, interrupted.ID))
+
+	running := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, running))
+	require.NoError(t, store.Start(ctx, running.ID))
+
+	count, err := store.RequeueInterrupted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	requeued, err := store.GetByID(ctx, interrupted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCreated, requeued.Status)
+	assert.Nil(t, requeued.StartTime)
+
+	stillRunning, err := store.GetByID(ctx, running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, stillRunning.Status)
+
+	claimed, err := store.ClaimNextCreated(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, interrupted.ID, claimed.ID)
+
+	count, err = store.RequeueInterrupted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestMySQLStore_Requeue(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("requeue running job", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+		require.NoError(t, store.ReportProgress(ctx, j.ID, 2, 5))
+
+		require.NoError(t, store.Requeue(ctx, j.ID))
+
+		retrieved, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, retrieved.Status)
+		assert.Nil(t, retrieved.StartTime)
+		assert.Nil(t, retrieved.HeartbeatAt)
+		assert.Zero(t, retrieved.ProgressDone)
+	})
+
+	t.Run("requeue interrupted job", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+		require.NoError(t, store.Interrupt(ctx, j.ID))
+
+		require.NoError(t, store.Requeue(ctx, j.ID))
+
+		retrieved, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, retrieved.Status)
+	})
+
+	t.Run("requeue finished job returns error", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+		require.NoError(t, store.Complete(ctx, j.ID, StatusSuccess, nil))
+
+		assert.ErrorIs(t, store.Requeue(ctx, j.ID), ErrJobNotRunning)
+	})
+
+	t.Run("requeue non-existent job returns error", func(t *testing.T) {
+		assert.ErrorIs(t, store.Requeue(ctx, uuid.New()), ErrJobNotFound)
+	})
+}
+
+func TestMySQLStore_ListIDsByStatus(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	first := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, first))
+	running := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, running))
+	require.NoError(t, store.Start(ctx, running.ID))
+	time.Sleep(10 * time.Millisecond)
+	second := &Job{Type: JobTypeLinkCheck, CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, second))
+
+	ids, err := store.ListIDsByStatus(ctx, StatusCreated)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{first.ID, second.ID}, ids)
+
+	ids, err = store.ListIDsByStatus(ctx, StatusFailed)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestMySQLStore_Heartbeat(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("heartbeat of running job", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+		started, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		require.NotNil(t, started.HeartbeatAt)
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, store.Heartbeat(ctx, j.ID))
+
+		retrieved, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.HeartbeatAt)
+		assert.True(t, retrieved.HeartbeatAt.After(*started.HeartbeatAt))
+	})
+
+	t.Run("heartbeat of non-running job returns error", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+
+		err := store.Heartbeat(ctx, j.ID)
+		assert.ErrorIs(t, err, ErrJobNotRunning)
+	})
+}
+
+func TestMySQLStore_ReapStale(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	createRunning := func(lastSeen time.Time) *Job {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+		require.NoError(t, db.Model(&Job{}).Where("id = ?", j.ID).
+			Updates(map[string]interface{}{"start_time": lastSeen, "heartbeat_at": lastSeen}).Error)
+		return j
+	}
+
+	t.Run("fail stale jobs", func(t *testing.T) {
+		stale := createRunning(time.Now().Add(-time.Hour))
+		fresh := createRunning(time.Now())
+
+		reaped, err := store.ReapStale(ctx, time.Now().Add(-5*time.Minute), StaleActionFail)
+		require.NoError(t, err)
+		require.Len(t, reaped, 1)
+		assert.Equal(t, stale.ID, reaped[0].ID)
+
+		failed, err := store.GetByID(ctx, stale.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Equal(t, StaleJobReason, failed.Result["error"])
+		assert.NotNil(t, failed.EndTime)
+
+		running, err := store.GetByID(ctx, fresh.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusRunning, running.Status)
+
+		require.NoError(t, store.Complete(ctx, fresh.ID, StatusSuccess, nil))
+	})
+
+	t.Run("requeue stale jobs", func(t *testing.T) {
+		stale := createRunning(time.Now().Add(-time.Hour))
+
+		reaped, err := store.ReapStale(ctx, time.Now().Add(-5*time.Minute), StaleActionRequeue)
+		require.NoError(t, err)
+		require.Len(t, reaped, 1)
+
+		requeued, err := store.GetByID(ctx, stale.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, requeued.Status)
+		assert.Nil(t, requeued.StartTime)
+		assert.Nil(t, requeued.HeartbeatAt)
+	})
+
+	t.Run("jobs without a heartbeat use their start time", func(t *testing.T) {
+		j := createRunning(time.Now().Add(-time.Hour))
+		require.NoError(t, db.Model(&Job{}).Where("id = ?", j.ID).Update("heartbeat_at", nil).Error)
+
+		reaped, err := store.ReapStale(ctx, time.Now().Add(-5*time.Minute), StaleActionFail)
+		require.NoError(t, err)
+		require.Len(t, reaped, 1)
+		assert.Equal(t, j.ID, reaped[0].ID)
+	})
+
+	t.Run("invalid action returns error", func(t *testing.T) {
+		_, err := store.ReapStale(ctx, time.Now(), StaleAction("retry"))
+		assert.ErrorIs(t, err, ErrInvalidStaleAction)
+	})
+}
+
+func TestMySQLStore_ReportProgress(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("report progress of running job", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+
+		require.NoError(t, store.ReportProgress(ctx, j.ID, 3, 12))
+
+		retrieved, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, retrieved.ProgressDone)
+		assert.Equal(t, 12, retrieved.ProgressTotal)
+		assert.Equal(t, 25, retrieved.ProgressPercent())
+		assert.NotNil(t, retrieved.HeartbeatAt)
+
+		require.NoError(t, store.Complete(ctx, j.ID, StatusSuccess, nil))
+		completed, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 12, completed.ProgressDone)
+		assert.Equal(t, 100, completed.ProgressPercent())
+	})
+
+	t.Run("total below done is raised", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+
+		require.NoError(t, store.ReportProgress(ctx, j.ID, 5, 4))
+
+		retrieved, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, retrieved.ProgressTotal)
+		assert.Equal(t, 99, retrieved.ProgressPercent())
+	})
+
+	t.Run("unknown total stays unknown", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+		require.NoError(t, store.Start(ctx, j.ID))
+
+		require.NoError(t, store.ReportProgress(ctx, j.ID, 2, 0))
+
+		retrieved, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, retrieved.ProgressDone)
+		assert.Equal(t, 0, retrieved.ProgressTotal)
+		assert.Equal(t, 0, retrieved.ProgressPercent())
+	})
+
+	t.Run("report progress of non-running job returns error", func(t *testing.T) {
+		j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+		require.NoError(t, store.Create(ctx, j))
+
+		err := store.ReportProgress(ctx, j.ID, 1, 2)
+		assert.ErrorIs(t, err, ErrJobNotRunning)
+	})
+}
@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// Reaper finds running jobs whose heartbeat has gone stale, such as jobs
+// stranded by a crashed server, and fails or requeues them. It is run once
+// on startup and then periodically.
+type Reaper struct {
+	store      Store
+	logStore   LogStore
+	staleAfter time.Duration
+	action     StaleAction
+	requeued   func(ctx context.Context, jobs []*Job)
+	logger     logger.Logger
+	stopCh     chan struct{}
+}
+
+// NewReaper creates a reaper for jobs without a heartbeat for staleAfter.
+// requeued, if not nil, is called with the jobs that were requeued so that
+// they can be dispatched to workers.
+func NewReaper(store Store, logStore LogStore, staleAfter time.Duration, action StaleAction, requeued func(ctx context.Context, jobs []*Job), log logger.Logger) *Reaper {
+	return &Reaper{
+		store:      store,
+		logStore:   logStore,
+		staleAfter: staleAfter,
+		action:     action,
+		requeued:   requeued,
+		logger:     log,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Run reaps the jobs that are stale at now and returns how many were reaped.
+func (r *Reaper) Run(ctx context.Context, now time.Time) (int, error) {
+	jobs, err := r.store.ReapStale(ctx, now.Add(-r.staleAfter), r.action)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, j := range jobs {
+		fields := map[string]interface{}{
+			"job_id": j.ID.String(),
+			"action": string(r.action),
+		}
+		if lastSeen := j.LastSeen(); lastSeen != nil {
+			fields["last_seen"] = lastSeen.Format(time.RFC3339)
+		}
+		r.logger.Warn(ctx, "reaped stale job", fields)
+
+		message := "Job failed: " + StaleJobReason
+		if r.action == StaleActionRequeue {
+			message = "Job stopped sending heartbeats and was queued again"
+		}
+		if _, err := r.logStore.Append(ctx, j.ID, message); err != nil {
+			r.logger.Warn(ctx, "failed to append job log", map[string]interface{}{
+				"error":  err.Error(),
+				"job_id": j.ID.String(),
+			})
+		}
+	}
+
+	if len(jobs) > 0 && r.action == StaleActionRequeue && r.requeued != nil {
+		r.requeued(ctx, jobs)
+	}
+	return len(jobs), nil
+}
+
+// Start starts a background goroutine that reaps stale jobs every interval.
+func (r *Reaper) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				if _, err := r.Run(context.Background(), now); err != nil {
+					r.logger.Error(context.Background(), "failed to reap stale jobs", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
+			case <-r.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the reaper goroutine.
+func (r *Reaper) Stop() {
+	close(r.stopCh)
+}
@@ -0,0 +1,56 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaper_Run(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Job{}, &LogEntry{})
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+	logStore := NewMySQLLogStore(db, log)
+	ctx := context.Background()
+
+	j := &Job{Type: JobTypeUIExploration, CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, j))
+	require.NoError(t, store.Start(ctx, j.ID))
+
+	var requeued []uuid.UUID
+	reaper := NewReaper(store, logStore, 5*time.Minute, StaleActionRequeue, func(ctx context.Context, jobs []*Job) {
+		for _, j := range jobs {
+			requeued = append(requeued, j.ID)
+		}
+	}, log)
+
+	t.Run("fresh jobs are kept", func(t *testing.T) {
+		count, err := reaper.Run(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Empty(t, requeued)
+	})
+
+	t.Run("stale jobs are requeued", func(t *testing.T) {
+		count, err := reaper.Run(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, []uuid.UUID{j.ID}, requeued)
+
+		retrieved, err := store.GetByID(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, retrieved.Status)
+
+		entries, err := logStore.ListAfter(ctx, j.ID, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Contains(t, entries[0].Message, "queued again")
+	})
+}
@@ -2,8 +2,10 @@ package job
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
 )
 
 type Store interface {
@@ -11,11 +13,22 @@ type Store interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*Job, error)
 	Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error
 	ListByCreator(ctx context.Context, createdBy uuid.UUID, limit, offset int) ([]*Job, error)
+	ListByCreatorAfter(ctx context.Context, createdBy uuid.UUID, after *database.Cursor, limit int) ([]*Job, error)
 	CountByCreator(ctx context.Context, createdBy uuid.UUID) (int, error)
 	ListByType(ctx context.Context, jobType JobType, limit, offset int) ([]*Job, error)
 	Start(ctx context.Context, id uuid.UUID) error
 	Complete(ctx context.Context, id uuid.UUID, status Status, result JSONMap) error
 	ClaimNextCreated(ctx context.Context) (*Job, error)
+	ClaimNextCreatedOn(ctx context.Context, jobType JobType, createdBy uuid.UUID, endpoints []uuid.UUID) (*Job, error)
+	ClaimCreated(ctx context.Context, id uuid.UUID) (*Job, error)
+	CountRunningByEndpoint(ctx context.Context) (map[uuid.UUID]int, error)
+	Interrupt(ctx context.Context, id uuid.UUID) error
+	RequeueInterrupted(ctx context.Context) (int, error)
+	Requeue(ctx context.Context, id uuid.UUID) error
+	ListIDsByStatus(ctx context.Context, status Status) ([]uuid.UUID, error)
+	Heartbeat(ctx context.Context, id uuid.UUID) error
+	ReportProgress(ctx context.Context, id uuid.UUID, done, total int) error
+	ReapStale(ctx context.Context, before time.Time, action StaleAction) ([]*Job, error)
 }
 
 type UpdateSetter func(*Job) error
@@ -0,0 +1,33 @@
+package label
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and label store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Label{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestLabel creates a label with default values.
+func createTestLabel(projectID uuid.UUID, resourceType ResourceType, resourceID uuid.UUID, key, value string) *Label {
+	return &Label{
+		ProjectID:    projectID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Key:          key,
+		Value:        value,
+		CreatedBy:    uuid.New(),
+	}
+}
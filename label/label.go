@@ -0,0 +1,159 @@
+// Package label attaches labels to procedures and runs so that suites can be
+// sliced by area, priority or release. A label is a bare key, such as
+// "smoke", or a key with a value, such as "priority=high".
+package label
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxValueLength is the longest label value that can be stored.
+const MaxValueLength = 255
+
+var (
+	// ErrLabelNotFound is returned when a resource does not have a label.
+	ErrLabelNotFound = errors.New("label not found")
+
+	// ErrInvalidKey is returned when a label key is malformed.
+	ErrInvalidKey = errors.New("label key must be 1-63 lowercase letters, digits, '.', '_' or '-', starting with a letter or digit")
+
+	// ErrInvalidValue is returned when a label value is too long.
+	ErrInvalidValue = errors.New("label value must be at most 255 characters")
+
+	// ErrInvalidResourceType is returned when a label is attached to an
+	// unknown kind of resource.
+	ErrInvalidResourceType = errors.New("resource type must be one of: procedure, run")
+
+	// ErrInvalidResourceID is returned when resource_id is not set.
+	ErrInvalidResourceID = errors.New("resource_id is required")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+// ResourceType identifies the kind of resource a label is attached to.
+type ResourceType string
+
+const (
+	// ResourceProcedure labels a test procedure. Labels are attached to the
+	// first version, so they apply to every version of the procedure.
+	ResourceProcedure ResourceType = "procedure"
+
+	// ResourceRun labels a test run.
+	ResourceRun ResourceType = "run"
+)
+
+// IsValid checks if the resource type is valid.
+func (t ResourceType) IsValid() bool {
+	switch t {
+	case ResourceProcedure, ResourceRun:
+		return true
+	default:
+		return false
+	}
+}
+
+// Label is a label attached to a procedure or run. A resource has at most
+// one label per key.
+type Label struct {
+	ID           uuid.UUID    `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID    uuid.UUID    `json:"project_id" gorm:"type:char(36);not null;index:idx_labels_project_key,priority:1"`
+	ResourceType ResourceType `json:"resource_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_labels_resource_key,priority:1"`
+	ResourceID   uuid.UUID    `json:"resource_id" gorm:"type:char(36);not null;uniqueIndex:idx_labels_resource_key,priority:2"`
+	Key          string       `json:"key" gorm:"type:varchar(63);not null;uniqueIndex:idx_labels_resource_key,priority:3;index:idx_labels_project_key,priority:2"`
+	Value        string       `json:"value" gorm:"type:varchar(255);not null;default:''"`
+	CreatedBy    uuid.UUID    `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new label.
+func (l *Label) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the label has valid required fields.
+func (l *Label) Validate() error {
+	if l.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if !l.ResourceType.IsValid() {
+		return ErrInvalidResourceType
+	}
+	if l.ResourceID == uuid.Nil {
+		return ErrInvalidResourceID
+	}
+	if !keyPattern.MatchString(l.Key) {
+		return ErrInvalidKey
+	}
+	if len(l.Value) > MaxValueLength {
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+// String returns the label as key or key=value.
+func (l *Label) String() string {
+	if l.Value == "" {
+		return l.Key
+	}
+	return l.Key + "=" + l.Value
+}
+
+// Selector matches labels by key, and by value if one is given.
+type Selector struct {
+	Key   string
+	Value string
+	// HasValue is set when the selector was given as key=value.
+	HasValue bool
+}
+
+// ParseSelector parses a label selector of the form key or key=value.
+func ParseSelector(s string) (Selector, error) {
+	key, value, hasValue := strings.Cut(strings.TrimSpace(s), "=")
+	sel := Selector{Key: strings.ToLower(key), Value: value, HasValue: hasValue}
+	if !keyPattern.MatchString(sel.Key) {
+		return Selector{}, ErrInvalidKey
+	}
+	if len(sel.Value) > MaxValueLength {
+		return Selector{}, ErrInvalidValue
+	}
+	return sel, nil
+}
+
+// ParseSelectors parses label selectors, dropping duplicates. A resource
+// matches the selectors if it matches every one of them.
+func ParseSelectors(values []string) ([]Selector, error) {
+	seen := make(map[Selector]bool, len(values))
+	selectors := make([]Selector, 0, len(values))
+	for _, v := range values {
+		sel, err := ParseSelector(v)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[sel] {
+			seen[sel] = true
+			selectors = append(selectors, sel)
+		}
+	}
+	return selectors, nil
+}
+
+// Usage counts the procedures and runs in a project that carry a label.
+type Usage struct {
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	Procedures int    `json:"procedures"`
+	Runs       int    `json:"runs"`
+}
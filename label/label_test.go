@@ -0,0 +1,97 @@
+package label
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabel_Validate(t *testing.T) {
+	valid := func() *Label {
+		return createTestLabel(uuid.New(), ResourceProcedure, uuid.New(), "priority", "high")
+	}
+
+	t.Run("valid label", func(t *testing.T) {
+		assert.NoError(t, valid().Validate())
+	})
+
+	t.Run("bare key", func(t *testing.T) {
+		l := valid()
+		l.Value = ""
+		assert.NoError(t, l.Validate())
+	})
+
+	tests := []struct {
+		name   string
+		modify func(*Label)
+		want   error
+	}{
+		{"missing project", func(l *Label) { l.ProjectID = uuid.Nil }, ErrInvalidProjectID},
+		{"unknown resource type", func(l *Label) { l.ResourceType = "project" }, ErrInvalidResourceType},
+		{"missing resource", func(l *Label) { l.ResourceID = uuid.Nil }, ErrInvalidResourceID},
+		{"empty key", func(l *Label) { l.Key = "" }, ErrInvalidKey},
+		{"uppercase key", func(l *Label) { l.Key = "Smoke" }, ErrInvalidKey},
+		{"key with space", func(l *Label) { l.Key = "smoke test" }, ErrInvalidKey},
+		{"key starting with dash", func(l *Label) { l.Key = "-smoke" }, ErrInvalidKey},
+		{"key too long", func(l *Label) { l.Key = strings.Repeat("a", 64) }, ErrInvalidKey},
+		{"value too long", func(l *Label) { l.Value = strings.Repeat("a", MaxValueLength+1) }, ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := valid()
+			tt.modify(l)
+			assert.ErrorIs(t, l.Validate(), tt.want)
+		})
+	}
+}
+
+func TestLabel_String(t *testing.T) {
+	assert.Equal(t, "smoke", (&Label{Key: "smoke"}).String())
+	assert.Equal(t, "priority=high", (&Label{Key: "priority", Value: "high"}).String())
+}
+
+func TestParseSelector(t *testing.T) {
+	t.Run("bare key", func(t *testing.T) {
+		sel, err := ParseSelector("smoke")
+		require.NoError(t, err)
+		assert.Equal(t, Selector{Key: "smoke"}, sel)
+	})
+
+	t.Run("key and value", func(t *testing.T) {
+		sel, err := ParseSelector("release=4.2")
+		require.NoError(t, err)
+		assert.Equal(t, Selector{Key: "release", Value: "4.2", HasValue: true}, sel)
+	})
+
+	t.Run("empty value", func(t *testing.T) {
+		sel, err := ParseSelector("release=")
+		require.NoError(t, err)
+		assert.Equal(t, Selector{Key: "release", HasValue: true}, sel)
+	})
+
+	t.Run("key is lowercased", func(t *testing.T) {
+		sel, err := ParseSelector(" Area=Checkout ")
+		require.NoError(t, err)
+		assert.Equal(t, Selector{Key: "area", Value: "Checkout", HasValue: true}, sel)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := ParseSelector("=high")
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestParseSelectors(t *testing.T) {
+	selectors, err := ParseSelectors([]string{"smoke", "priority=high", "smoke"})
+	require.NoError(t, err)
+	assert.Equal(t, []Selector{
+		{Key: "smoke"},
+		{Key: "priority", Value: "high", HasValue: true},
+	}, selectors)
+
+	_, err = ParseSelectors([]string{"smoke", "bad key"})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,156 @@
+package label
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed label store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Set attaches a label to a resource, replacing the value of a label with
+// the same key.
+func (s *MySQLStore) Set(ctx context.Context, label *Label) error {
+	if err := label.Validate(); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "resource_type"}, {Name: "resource_id"}, {Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+		}).
+		Create(label).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to set label", map[string]interface{}{
+			"error":         err.Error(),
+			"resource_type": label.ResourceType,
+			"resource_id":   label.ResourceID.String(),
+			"key":           label.Key,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// Remove detaches the label with a key from a resource.
+func (s *MySQLStore) Remove(ctx context.Context, resourceType ResourceType, resourceID uuid.UUID, key string) error {
+	result := s.db.WithContext(ctx).
+		Where("resource_type = ? AND resource_id = ? AND `key` = ?", resourceType, resourceID, key).
+		Delete(&Label{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to remove label", map[string]interface{}{
+			"error":         result.Error.Error(),
+			"resource_type": resourceType,
+			"resource_id":   resourceID.String(),
+			"key":           key,
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrLabelNotFound
+	}
+
+	return nil
+}
+
+// List retrieves the labels of a resource, ordered by key.
+func (s *MySQLStore) List(ctx context.Context, resourceType ResourceType, resourceID uuid.UUID) ([]*Label, error) {
+	var labels []*Label
+	err := s.db.WithContext(ctx).
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+		Order("`key` ASC").
+		Find(&labels).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list labels", map[string]interface{}{
+			"error":         err.Error(),
+			"resource_type": resourceType,
+			"resource_id":   resourceID.String(),
+		})
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// Match retrieves the IDs of the resources in a project that match every
+// selector. Each selector narrows the resources matched by the previous ones.
+func (s *MySQLStore) Match(ctx context.Context, projectID uuid.UUID, resourceType ResourceType, selectors []Selector) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for i, sel := range selectors {
+		query := s.db.WithContext(ctx).
+			Model(&Label{}).
+			Where("project_id = ? AND resource_type = ? AND `key` = ?", projectID, resourceType, sel.Key)
+		if sel.HasValue {
+			query = query.Where("value = ?", sel.Value)
+		}
+		if i > 0 {
+			query = query.Where("resource_id IN ?", ids)
+		}
+
+		var matched []uuid.UUID
+		if err := query.Pluck("resource_id", &matched).Error; err != nil {
+			s.logger.Error(ctx, "failed to match labels", map[string]interface{}{
+				"error":         err.Error(),
+				"project_id":    projectID.String(),
+				"resource_type": resourceType,
+			})
+			return nil, err
+		}
+		ids = matched
+		if len(ids) == 0 {
+			break
+		}
+	}
+
+	if ids == nil {
+		ids = []uuid.UUID{}
+	}
+	return ids, nil
+}
+
+// ListUsage retrieves the labels used in a project with the number of
+// procedures and runs carrying each, ordered by key and value.
+func (s *MySQLStore) ListUsage(ctx context.Context, projectID uuid.UUID) ([]*Usage, error) {
+	var usage []*Usage
+	err := s.db.WithContext(ctx).
+		Model(&Label{}).
+		Select("`key`, value, "+
+			"SUM(CASE WHEN resource_type = ? THEN 1 ELSE 0 END) AS procedures, "+
+			"SUM(CASE WHEN resource_type = ? THEN 1 ELSE 0 END) AS runs",
+			ResourceProcedure, ResourceRun).
+		Where("project_id = ?", projectID).
+		Group("`key`, value").
+		Order("`key` ASC, value ASC").
+		Scan(&usage).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list label usage", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,139 @@
+package label
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Set(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	procID := uuid.New()
+
+	t.Run("set label", func(t *testing.T) {
+		require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, procID, "priority", "low")))
+
+		labels, err := store.List(ctx, ResourceProcedure, procID)
+		require.NoError(t, err)
+		require.Len(t, labels, 1)
+		assert.Equal(t, "priority=low", labels[0].String())
+	})
+
+	t.Run("set existing key replaces value", func(t *testing.T) {
+		require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, procID, "priority", "high")))
+
+		labels, err := store.List(ctx, ResourceProcedure, procID)
+		require.NoError(t, err)
+		require.Len(t, labels, 1)
+		assert.Equal(t, "high", labels[0].Value)
+	})
+
+	t.Run("invalid label returns error", func(t *testing.T) {
+		err := store.Set(ctx, createTestLabel(projectID, ResourceProcedure, procID, "Bad Key", ""))
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestMySQLStore_Remove(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	runID := uuid.New()
+
+	require.NoError(t, store.Set(ctx, createTestLabel(uuid.New(), ResourceRun, runID, "smoke", "")))
+
+	t.Run("remove label", func(t *testing.T) {
+		require.NoError(t, store.Remove(ctx, ResourceRun, runID, "smoke"))
+
+		labels, err := store.List(ctx, ResourceRun, runID)
+		require.NoError(t, err)
+		assert.Empty(t, labels)
+	})
+
+	t.Run("remove missing label returns error", func(t *testing.T) {
+		err := store.Remove(ctx, ResourceRun, runID, "smoke")
+		assert.ErrorIs(t, err, ErrLabelNotFound)
+	})
+}
+
+func TestMySQLStore_List(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	id := uuid.New()
+
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceRun, id, "release", "4.2")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceRun, id, "area", "checkout")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, id, "smoke", "")))
+
+	labels, err := store.List(ctx, ResourceRun, id)
+	require.NoError(t, err)
+	require.Len(t, labels, 2)
+	assert.Equal(t, "area", labels[0].Key)
+	assert.Equal(t, "release", labels[1].Key)
+}
+
+func TestMySQLStore_Match(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	login := uuid.New()
+	checkout := uuid.New()
+	search := uuid.New()
+
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, login, "smoke", "")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, login, "priority", "high")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, checkout, "smoke", "")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, checkout, "priority", "low")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, search, "priority", "high")))
+	require.NoError(t, store.Set(ctx, createTestLabel(uuid.New(), ResourceProcedure, uuid.New(), "smoke", "")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceRun, uuid.New(), "smoke", "")))
+
+	match := func(t *testing.T, values ...string) []uuid.UUID {
+		selectors, err := ParseSelectors(values)
+		require.NoError(t, err)
+		ids, err := store.Match(ctx, projectID, ResourceProcedure, selectors)
+		require.NoError(t, err)
+		return ids
+	}
+
+	t.Run("match by key", func(t *testing.T) {
+		assert.ElementsMatch(t, []uuid.UUID{login, checkout}, match(t, "smoke"))
+	})
+
+	t.Run("match by key and value", func(t *testing.T) {
+		assert.ElementsMatch(t, []uuid.UUID{login, search}, match(t, "priority=high"))
+	})
+
+	t.Run("match every selector", func(t *testing.T) {
+		assert.Equal(t, []uuid.UUID{login}, match(t, "smoke", "priority=high"))
+	})
+
+	t.Run("no match returns empty list", func(t *testing.T) {
+		ids := match(t, "smoke", "priority=medium")
+		assert.NotNil(t, ids)
+		assert.Empty(t, ids)
+	})
+}
+
+func TestMySQLStore_ListUsage(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, uuid.New(), "smoke", "")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceProcedure, uuid.New(), "smoke", "")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceRun, uuid.New(), "smoke", "")))
+	require.NoError(t, store.Set(ctx, createTestLabel(projectID, ResourceRun, uuid.New(), "release", "4.2")))
+	require.NoError(t, store.Set(ctx, createTestLabel(uuid.New(), ResourceRun, uuid.New(), "release", "4.1")))
+
+	usage, err := store.ListUsage(ctx, projectID)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	assert.Equal(t, Usage{Key: "release", Value: "4.2", Procedures: 0, Runs: 1}, *usage[0])
+	assert.Equal(t, Usage{Key: "smoke", Value: "", Procedures: 2, Runs: 1}, *usage[1])
+}
@@ -0,0 +1,28 @@
+package label
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for label persistence operations.
+type Store interface {
+	// Set attaches a label to a resource, replacing the value of a label
+	// with the same key.
+	Set(ctx context.Context, label *Label) error
+
+	// Remove detaches the label with a key from a resource.
+	Remove(ctx context.Context, resourceType ResourceType, resourceID uuid.UUID, key string) error
+
+	// List retrieves the labels of a resource, ordered by key.
+	List(ctx context.Context, resourceType ResourceType, resourceID uuid.UUID) ([]*Label, error)
+
+	// Match retrieves the IDs of the resources in a project that match every
+	// selector.
+	Match(ctx context.Context, projectID uuid.UUID, resourceType ResourceType, selectors []Selector) ([]uuid.UUID, error)
+
+	// ListUsage retrieves the labels used in a project with the number of
+	// procedures and runs carrying each, ordered by key and value.
+	ListUsage(ctx context.Context, projectID uuid.UUID) ([]*Usage, error)
+}
@@ -0,0 +1,314 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRequestTimeout bounds each request made by the crawler.
+	DefaultRequestTimeout = 15 * time.Second
+
+	// maxPageBytes caps how much of a page is read when looking for links.
+	maxPageBytes = 5 << 20
+
+	userAgent = "ui-automation-link-checker/1.0"
+)
+
+var (
+	tagPattern  = regexp.MustCompile(`(?is)<(a|area|iframe|img|script|source|link|video|audio|embed)\b([^>]*)>`)
+	attrPattern = regexp.MustCompile(`(?s)([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// link is a URL referenced by a page.
+type link struct {
+	url   string
+	asset bool
+}
+
+// Crawler walks the pages of a site and checks every link and asset it finds.
+type Crawler struct {
+	client *http.Client
+}
+
+// NewCrawler creates a crawler whose requests time out after timeout.
+func NewCrawler(timeout time.Duration) *Crawler {
+	return &Crawler{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// crawl holds the state of a single crawl.
+type crawl struct {
+	crawler *Crawler
+	start   *url.URL
+
+	queued  map[string]bool
+	pending map[string][]string // referrers of queued pages not yet visited
+	checked map[string]*Issue   // nil value means the URL is fine
+	order   []string
+	summary Summary
+}
+
+type queuedPage struct {
+	url    string
+	depth  int
+	source string
+}
+
+// Crawl checks the site at startURL. Pages on the start URL's host are
+// crawled breadth-first up to cfg.MaxPages and cfg.MaxDepth; other links and
+// assets are only requested to confirm they resolve. An error is returned
+// only when the start page itself cannot be fetched.
+func (c *Crawler) Crawl(ctx context.Context, startURL string, cfg *Config) (*Report, error) {
+	start, err := url.Parse(startURL)
+	if err != nil || (start.Scheme != "http" && start.Scheme != "https") || start.Host == "" {
+		return nil, fmt.Errorf("invalid start URL %q", startURL)
+	}
+	start.Fragment = ""
+
+	cr := &crawl{
+		crawler: c,
+		start:   start,
+		queued:  map[string]bool{start.String(): true},
+		pending: make(map[string][]string),
+		checked: make(map[string]*Issue),
+	}
+
+	queue := []queuedPage{{url: start.String()}}
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page := queue[0]
+		queue = queue[1:]
+
+		links, err := cr.visit(ctx, page)
+		if err != nil {
+			if page.source == "" {
+				return nil, fmt.Errorf("failed to fetch start page: %w", err)
+			}
+			continue
+		}
+
+		for _, l := range links {
+			internal := cr.sameHost(l.url)
+			if internal && !l.asset {
+				if cr.queued[l.url] {
+					// Crawled pages are checked when they are visited.
+					if issue, visited := cr.checked[l.url]; !visited {
+						cr.pending[l.url] = append(cr.pending[l.url], page.url)
+					} else if issue != nil {
+						issue.addSource(page.url)
+					}
+					continue
+				}
+				if page.depth < cfg.MaxDepth {
+					if len(cr.queued) < cfg.MaxPages {
+						cr.queued[l.url] = true
+						queue = append(queue, queuedPage{url: l.url, depth: page.depth + 1, source: page.url})
+						continue
+					}
+					cr.summary.Truncated = true
+				}
+			}
+			if !internal && !cfg.CheckExternal {
+				continue
+			}
+			cr.check(ctx, l, page.url)
+		}
+	}
+
+	report := &Report{Summary: cr.summary}
+	for _, u := range cr.order {
+		if issue := cr.checked[u]; issue != nil {
+			report.Issues = append(report.Issues, *issue)
+			switch issue.Kind {
+			case IssueKindBrokenLink:
+				report.Summary.BrokenLinks++
+			case IssueKindMissingAsset:
+				report.Summary.MissingAssets++
+			case IssueKindUnreachable:
+				report.Summary.Unreachable++
+			}
+		}
+	}
+	return report, nil
+}
+
+// visit fetches a crawled page, records its outcome and returns its links.
+// Pages that failed are recorded as issues and return no links.
+func (cr *crawl) visit(ctx context.Context, page queuedPage) ([]link, error) {
+	cr.summary.PagesCrawled++
+	cr.summary.LinksChecked++
+
+	resp, err := cr.crawler.do(ctx, http.MethodGet, page.url)
+	if err != nil {
+		cr.record(page.url, page.source, &Issue{Kind: IssueKindUnreachable, Error: err.Error()})
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		cr.record(page.url, page.source, &Issue{Kind: IssueKindBrokenLink, StatusCode: resp.StatusCode})
+		return nil, nil
+	}
+	cr.record(page.url, page.source, nil)
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, nil
+	}
+	// Relative links resolve against the final URL after redirects.
+	return extractLinks(resp.Request.URL, string(body)), nil
+}
+
+// check requests a link that is not crawled and records the outcome once.
+func (cr *crawl) check(ctx context.Context, l link, source string) {
+	if _, done := cr.checked[l.url]; done {
+		if issue := cr.checked[l.url]; issue != nil {
+			issue.addSource(source)
+		}
+		return
+	}
+	cr.summary.LinksChecked++
+
+	kind := IssueKindBrokenLink
+	if l.asset {
+		kind = IssueKindMissingAsset
+	}
+
+	resp, err := cr.crawler.do(ctx, http.MethodHead, l.url)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		// Some servers refuse HEAD; fall back to GET before reporting.
+		resp.Body.Close()
+		resp, err = cr.crawler.do(ctx, http.MethodGet, l.url)
+	}
+	if err != nil {
+		cr.record(l.url, source, &Issue{Kind: IssueKindUnreachable, Error: err.Error()})
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		cr.record(l.url, source, &Issue{Kind: kind, StatusCode: resp.StatusCode})
+		return
+	}
+	cr.record(l.url, source, nil)
+}
+
+// record stores the outcome of a URL the first time it is checked.
+func (cr *crawl) record(u, source string, issue *Issue) {
+	if issue != nil {
+		issue.URL = u
+		issue.FoundOn = []string{}
+		issue.addSource(source)
+		for _, s := range cr.pending[u] {
+			issue.addSource(s)
+		}
+	}
+	delete(cr.pending, u)
+	cr.checked[u] = issue
+	cr.order = append(cr.order, u)
+}
+
+func (cr *crawl) sameHost(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && strings.EqualFold(u.Host, cr.start.Host)
+}
+
+func (c *Crawler) do(ctx context.Context, method, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return c.client.Do(req)
+}
+
+// addSource records a referring page, ignoring the start page's empty source.
+func (i *Issue) addSource(source string) {
+	if source == "" || len(i.FoundOn) >= maxFoundOn {
+		return
+	}
+	for _, s := range i.FoundOn {
+		if s == source {
+			return
+		}
+	}
+	i.FoundOn = append(i.FoundOn, source)
+}
+
+// extractLinks returns the http(s) links and assets referenced by an HTML
+// page, resolved against base and without fragments.
+func extractLinks(base *url.URL, body string) []link {
+	var links []link
+	seen := make(map[string]bool)
+
+	for _, tag := range tagPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(tag[1])
+		attrs := parseAttrs(tag[2])
+
+		var raw string
+		asset := true
+		switch name {
+		case "a", "area":
+			raw, asset = attrs["href"], false
+		case "iframe":
+			raw, asset = attrs["src"], false
+		case "link":
+			rel := strings.ToLower(attrs["rel"])
+			if !strings.Contains(rel, "stylesheet") && !strings.Contains(rel, "icon") {
+				continue
+			}
+			raw = attrs["href"]
+		default:
+			raw = attrs["src"]
+		}
+
+		u, ok := resolve(base, raw)
+		if !ok || seen[u] {
+			continue
+		}
+		seen[u] = true
+		links = append(links, link{url: u, asset: asset})
+	}
+	return links
+}
+
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrPattern.FindAllStringSubmatch(s, -1) {
+		attrs[strings.ToLower(m[1])] = m[2] + m[3] + m[4]
+	}
+	return attrs
+}
+
+// resolve turns a raw attribute value into an absolute http(s) URL.
+func resolve(base *url.URL, raw string) (string, bool) {
+	raw = strings.TrimSpace(html.UnescapeString(raw))
+	if raw == "" || strings.HasPrefix(raw, "#") {
+		return "", false
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	u := base.ResolveReference(ref)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	u.Fragment = ""
+	return u.String(), true
+}
@@ -0,0 +1,194 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSite serves a small site with a broken page, a missing image and a
+// link to an external server.
+func newTestSite(t *testing.T, external string) *httptest.Server {
+	t.Helper()
+
+	pages := map[string]string{
+		"/": `<html><body>
+			<a href="/about">About</a>
+			<a href='/missing#section'>Missing</a>
+			<a href="mailto:team@example.com">Mail</a>
+			<a href="#top">Top</a>
+			<img src="/logo.png">
+			<link rel="stylesheet" href="/style.css">
+			<a href="` + external + `/gone">Partner</a>
+		</body></html>`,
+		"/about": `<html><body>
+			<a href="/">Home</a>
+			<a href="/missing">Missing again</a>
+			<img src="/broken.png" alt="broken">
+			<a href="/deep">Deeper</a>
+		</body></html>`,
+		"/deep":   `<html><body><a href="/deeper">Deeper still</a></body></html>`,
+		"/deeper": `<html><body>End</body></html>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logo.png", "/style.css":
+			if r.Method == http.MethodHead {
+				// Exercise the GET fallback for servers that refuse HEAD.
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newExternalSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func issuesByURL(report *Report) map[string]Issue {
+	issues := make(map[string]Issue, len(report.Issues))
+	for _, issue := range report.Issues {
+		issues[issue.URL] = issue
+	}
+	return issues
+}
+
+func TestCrawler_Crawl(t *testing.T) {
+	external := newExternalSite(t)
+	site := newTestSite(t, external.URL)
+	crawler := NewCrawler(5 * time.Second)
+
+	report, err := crawler.Crawl(context.Background(), site.URL+"/", &Config{MaxPages: 10, MaxDepth: 3, CheckExternal: true})
+	require.NoError(t, err)
+
+	issues := issuesByURL(report)
+	require.Len(t, issues, 3)
+
+	missing := issues[site.URL+"/missing"]
+	assert.Equal(t, IssueKindBrokenLink, missing.Kind)
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+	assert.ElementsMatch(t, []string{site.URL + "/", site.URL + "/about"}, missing.FoundOn)
+
+	broken := issues[site.URL+"/broken.png"]
+	assert.Equal(t, IssueKindMissingAsset, broken.Kind)
+	assert.Equal(t, []string{site.URL + "/about"}, broken.FoundOn)
+
+	gone := issues[external.URL+"/gone"]
+	assert.Equal(t, IssueKindBrokenLink, gone.Kind)
+	assert.Equal(t, http.StatusGone, gone.StatusCode)
+
+	assert.Equal(t, 5, report.Summary.PagesCrawled) // /, /about, /missing, /deep, /deeper
+	assert.Equal(t, 2, report.Summary.BrokenLinks)
+	assert.Equal(t, 1, report.Summary.MissingAssets)
+	assert.False(t, report.Summary.Truncated)
+}
+
+func TestCrawler_Crawl_Limits(t *testing.T) {
+	external := newExternalSite(t)
+	site := newTestSite(t, external.URL)
+	crawler := NewCrawler(5 * time.Second)
+
+	t.Run("max depth checks deeper pages without crawling them", func(t *testing.T) {
+		report, err := crawler.Crawl(context.Background(), site.URL, &Config{MaxPages: 10, MaxDepth: 1, CheckExternal: true})
+		require.NoError(t, err)
+
+		issues := issuesByURL(report)
+		assert.Contains(t, issues, site.URL+"/missing")
+		assert.Contains(t, issues, site.URL+"/broken.png")
+		assert.Equal(t, 3, report.Summary.PagesCrawled) // /, /about, /missing
+	})
+
+	t.Run("max pages truncates the crawl", func(t *testing.T) {
+		report, err := crawler.Crawl(context.Background(), site.URL, &Config{MaxPages: 2, MaxDepth: 3, CheckExternal: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Summary.PagesCrawled)
+		assert.True(t, report.Summary.Truncated)
+		assert.Contains(t, issuesByURL(report), site.URL+"/missing")
+	})
+
+	t.Run("external links can be skipped", func(t *testing.T) {
+		report, err := crawler.Crawl(context.Background(), site.URL, &Config{MaxPages: 10, MaxDepth: 3, CheckExternal: false})
+		require.NoError(t, err)
+
+		assert.NotContains(t, issuesByURL(report), external.URL+"/gone")
+	})
+}
+
+func TestCrawler_Crawl_StartPage(t *testing.T) {
+	crawler := NewCrawler(time.Second)
+
+	t.Run("unreachable start page fails", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		addr := server.URL
+		server.Close()
+
+		_, err := crawler.Crawl(context.Background(), addr, &Config{MaxPages: 10, MaxDepth: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("broken start page is reported", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(server.Close)
+
+		report, err := crawler.Crawl(context.Background(), server.URL, &Config{MaxPages: 10, MaxDepth: 1})
+		require.NoError(t, err)
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, http.StatusNotFound, report.Issues[0].StatusCode)
+		assert.Empty(t, report.Issues[0].FoundOn)
+	})
+
+	t.Run("invalid start URL", func(t *testing.T) {
+		_, err := crawler.Crawl(context.Background(), "ftp://example.com", &Config{MaxPages: 10})
+		assert.Error(t, err)
+	})
+}
+
+func TestExtractLinks(t *testing.T) {
+	base, err := url.Parse("https://example.com/docs/")
+	require.NoError(t, err)
+
+	body := `<A HREF="guide.html?a=1&amp;b=2">Guide</A>
+		<img data-src="/lazy.png" src="/img/a.png">
+		<script src="//cdn.example.org/app.js"></script>
+		<link rel="canonical" href="/docs/">
+		<link rel="icon" href="/favicon.ico">
+		<iframe src="/embed"></iframe>
+		<a href="javascript:void(0)">Nothing</a>
+		<a href="/docs/guide.html?a=1&b=2#intro">Duplicate</a>`
+
+	links := extractLinks(base, body)
+
+	assert.Equal(t, []link{
+		{url: "https://example.com/docs/guide.html?a=1&b=2", asset: false},
+		{url: "https://example.com/img/a.png", asset: true},
+		{url: "https://cdn.example.org/app.js", asset: true},
+		{url: "https://example.com/favicon.ico", asset: true},
+		{url: "https://example.com/embed", asset: false},
+	}, links)
+}
@@ -0,0 +1,154 @@
+package linkcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+)
+
+// Keys under which link check data is stored in a job result.
+const (
+	ResultKeyIssues  = "issues"
+	ResultKeySummary = "summary"
+	ResultKeyPassed  = "passed"
+)
+
+const (
+	// DefaultMaxPages is the number of pages crawled when the job does not set max_pages.
+	DefaultMaxPages = 100
+
+	// MaxPagesLimit is the largest max_pages a job may request.
+	MaxPagesLimit = 1000
+
+	// DefaultMaxDepth is how many links deep the crawl follows from the start page.
+	DefaultMaxDepth = 3
+
+	// MaxDepthLimit is the largest max_depth a job may request.
+	MaxDepthLimit = 10
+
+	// maxFoundOn caps the number of referring pages kept per issue.
+	maxFoundOn = 10
+)
+
+var (
+	// ErrInvalidConfig is returned when a link_check job config is malformed.
+	ErrInvalidConfig = errors.New("invalid link_check config")
+
+	// ErrInvalidMaxPages is returned when max_pages is out of range.
+	ErrInvalidMaxPages = fmt.Errorf("max_pages must be between 1 and %d", MaxPagesLimit)
+
+	// ErrInvalidMaxDepth is returned when max_depth is out of range.
+	ErrInvalidMaxDepth = fmt.Errorf("max_depth must be between 0 and %d", MaxDepthLimit)
+
+	// ErrJobNotReportable is returned when a report is requested for a job that
+	// is not a successful link_check job.
+	ErrJobNotReportable = errors.New("only successful link_check jobs have a report")
+)
+
+// Config is the configuration of a link_check job.
+type Config struct {
+	EndpointID    uuid.UUID `json:"endpoint_id"`
+	ProjectID     uuid.UUID `json:"project_id"`
+	MaxPages      int       `json:"max_pages"`
+	MaxDepth      int       `json:"max_depth"`
+	CheckExternal bool      `json:"check_external"`
+}
+
+// ParseConfig decodes and validates a link_check job config, filling in
+// defaults for the crawl limits. External links are checked unless
+// check_external is explicitly false.
+func ParseConfig(raw job.JSONMap) (*Config, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	cfg := Config{MaxPages: DefaultMaxPages, MaxDepth: DefaultMaxDepth, CheckExternal: true}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	if cfg.MaxPages < 1 || cfg.MaxPages > MaxPagesLimit {
+		return nil, ErrInvalidMaxPages
+	}
+	if cfg.MaxDepth < 0 || cfg.MaxDepth > MaxDepthLimit {
+		return nil, ErrInvalidMaxDepth
+	}
+
+	return &cfg, nil
+}
+
+// IssueKind classifies a problem found by the crawl.
+type IssueKind string
+
+const (
+	// IssueKindBrokenLink is a link to a page that answered with a 4xx or 5xx status.
+	IssueKindBrokenLink IssueKind = "broken_link"
+
+	// IssueKindMissingAsset is an image, script, stylesheet or media file that
+	// answered with a 4xx or 5xx status.
+	IssueKindMissingAsset IssueKind = "missing_asset"
+
+	// IssueKindUnreachable is a link or asset that could not be fetched at all.
+	IssueKindUnreachable IssueKind = "unreachable"
+)
+
+// Issue is a URL that failed, with the pages that reference it.
+type Issue struct {
+	URL        string    `json:"url"`
+	Kind       IssueKind `json:"kind"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	FoundOn    []string  `json:"found_on"`
+}
+
+// Summary counts what a crawl visited and found.
+type Summary struct {
+	PagesCrawled  int  `json:"pages_crawled"`
+	LinksChecked  int  `json:"links_checked"`
+	BrokenLinks   int  `json:"broken_links"`
+	MissingAssets int  `json:"missing_assets"`
+	Unreachable   int  `json:"unreachable"`
+	Truncated     bool `json:"truncated"`
+}
+
+// Report is the outcome of a crawl.
+type Report struct {
+	Issues  []Issue
+	Summary Summary
+}
+
+// Result converts the report into a job result.
+func (r *Report) Result() job.JSONMap {
+	issues := r.Issues
+	if issues == nil {
+		issues = []Issue{}
+	}
+	return job.JSONMap{
+		ResultKeyIssues:  issues,
+		ResultKeySummary: r.Summary,
+		ResultKeyPassed:  len(r.Issues) == 0,
+	}
+}
+
+// IssuesFromResult decodes the issues stored in a job result.
+func IssuesFromResult(result job.JSONMap) ([]Issue, error) {
+	raw, ok := result[ResultKeyIssues]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode issues: %w", err)
+	}
+
+	var issues []Issue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, fmt.Errorf("failed to decode issues: %w", err)
+	}
+	return issues, nil
+}
@@ -0,0 +1,90 @@
+package linkcheck
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	endpointID, projectID := uuid.New(), uuid.New()
+	base := func() job.JSONMap {
+		return job.JSONMap{
+			"endpoint_id": endpointID.String(),
+			"project_id":  projectID.String(),
+		}
+	}
+
+	t.Run("applies defaults", func(t *testing.T) {
+		cfg, err := ParseConfig(base())
+		require.NoError(t, err)
+
+		assert.Equal(t, endpointID, cfg.EndpointID)
+		assert.Equal(t, projectID, cfg.ProjectID)
+		assert.Equal(t, DefaultMaxPages, cfg.MaxPages)
+		assert.Equal(t, DefaultMaxDepth, cfg.MaxDepth)
+		assert.True(t, cfg.CheckExternal)
+	})
+
+	t.Run("keeps explicit settings", func(t *testing.T) {
+		raw := base()
+		raw["max_pages"] = 10
+		raw["max_depth"] = 0
+		raw["check_external"] = false
+
+		cfg, err := ParseConfig(raw)
+		require.NoError(t, err)
+
+		assert.Equal(t, 10, cfg.MaxPages)
+		assert.Equal(t, 0, cfg.MaxDepth)
+		assert.False(t, cfg.CheckExternal)
+	})
+
+	tests := []struct {
+		name    string
+		key     string
+		value   interface{}
+		wantErr error
+	}{
+		{name: "zero max pages", key: "max_pages", value: 0, wantErr: ErrInvalidMaxPages},
+		{name: "too many pages", key: "max_pages", value: MaxPagesLimit + 1, wantErr: ErrInvalidMaxPages},
+		{name: "negative depth", key: "max_depth", value: -1, wantErr: ErrInvalidMaxDepth},
+		{name: "too deep", key: "max_depth", value: MaxDepthLimit + 1, wantErr: ErrInvalidMaxDepth},
+		{name: "malformed max pages", key: "max_pages", value: "ten", wantErr: ErrInvalidConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := base()
+			raw[tt.key] = tt.value
+
+			_, err := ParseConfig(raw)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReport_Result(t *testing.T) {
+	report := &Report{
+		Issues: []Issue{
+			{URL: "https://example.com/missing", Kind: IssueKindBrokenLink, StatusCode: 404, FoundOn: []string{"https://example.com/"}},
+		},
+		Summary: Summary{PagesCrawled: 1, LinksChecked: 2, BrokenLinks: 1},
+	}
+
+	result := report.Result()
+	assert.Equal(t, false, result[ResultKeyPassed])
+
+	issues, err := IssuesFromResult(result)
+	require.NoError(t, err)
+	assert.Equal(t, report.Issues, issues)
+
+	empty := (&Report{}).Result()
+	assert.Equal(t, true, empty[ResultKeyPassed])
+	issues, err = IssuesFromResult(empty)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
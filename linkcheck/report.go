@@ -0,0 +1,34 @@
+package linkcheck
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV writes issues as CSV with one row per failing URL. Referring pages
+// are separated by spaces.
+func WriteCSV(w io.Writer, issues []Issue) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"url", "kind", "status_code", "error", "found_on"}}
+	for _, issue := range issues {
+		status := ""
+		if issue.StatusCode != 0 {
+			status = strconv.Itoa(issue.StatusCode)
+		}
+		rows = append(rows, []string{
+			issue.URL,
+			string(issue.Kind),
+			status,
+			issue.Error,
+			strings.Join(issue.FoundOn, " "),
+		})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write link report csv: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package linkcheck
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	issues := []Issue{
+		{
+			URL:        "https://example.com/missing",
+			Kind:       IssueKindBrokenLink,
+			StatusCode: 404,
+			FoundOn:    []string{"https://example.com/", "https://example.com/about"},
+		},
+		{
+			URL:     "https://down.example.org/",
+			Kind:    IssueKindUnreachable,
+			Error:   "dial tcp: no such host, retrying",
+			FoundOn: []string{"https://example.com/"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, issues))
+
+	expected := "url,kind,status_code,error,found_on\n" +
+		"https://example.com/missing,broken_link,404,,https://example.com/ https://example.com/about\n" +
+		"https://down.example.org/,unreachable,,\"dial tcp: no such host, retrying\",https://example.com/\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteCSV_NoIssues(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, nil))
+	assert.Equal(t, "url,kind,status_code,error,found_on\n", buf.String())
+}
@@ -0,0 +1,55 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// Runner executes link_check jobs by crawling the job's endpoint.
+type Runner struct {
+	endpointStore endpoint.Store
+	crawler       *Crawler
+	logger        logger.Logger
+}
+
+// NewRunner creates a new link check job runner.
+func NewRunner(endpointStore endpoint.Store, crawler *Crawler, log logger.Logger) *Runner {
+	return &Runner{
+		endpointStore: endpointStore,
+		crawler:       crawler,
+		logger:        log,
+	}
+}
+
+// Run crawls the endpoint and returns the report as the job result. Broken
+// links do not fail the job; it fails only when the endpoint cannot be
+// crawled at all.
+func (r *Runner) Run(ctx context.Context, j *job.Job) (job.JSONMap, error) {
+	cfg, err := ParseConfig(j.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := r.endpointStore.GetByID(ctx, cfg.EndpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch endpoint: %w", err)
+	}
+
+	report, err := r.crawler.Crawl(ctx, ep.URL, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info(ctx, "link check completed", map[string]interface{}{
+		"job_id":        j.ID.String(),
+		"pages_crawled": report.Summary.PagesCrawled,
+		"links_checked": report.Summary.LinksChecked,
+		"issues":        len(report.Issues),
+	})
+
+	return report.Result(), nil
+}
@@ -0,0 +1,21 @@
+package llmusage
+
+import (
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and LLM usage store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Event{}, &Budget{}, &project.Project{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
@@ -0,0 +1,206 @@
+// Package llmusage meters the tokens and cost of model calls, such as script
+// generation and agent jobs, per user and per project, and enforces monthly
+// budgets that block further model calls once they are spent.
+//
+// Model clients add the usage of each call to the context they are
+// called with, and callers collect it with Track and record it with a
+// Meter once the call is done, attributing it to a project and user.
+package llmusage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidFeature is returned when an event has an unknown feature.
+	ErrInvalidFeature = errors.New("feature must be one of: script_generation, step_suggestion, procedure_authoring, guide_narration, ui_exploration, procedure_execution")
+
+	// ErrInvalidAccountID is returned when account_id is not set.
+	ErrInvalidAccountID = errors.New("account_id is required")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidLimit is returned when a budget's monthly limit is not positive.
+	ErrInvalidLimit = errors.New("monthly_limit_usd must be greater than 0")
+
+	// ErrBudgetNotFound is returned when a budget does not exist.
+	ErrBudgetNotFound = errors.New("budget not found")
+
+	// ErrBudgetExceeded is returned when a monthly budget is spent.
+	ErrBudgetExceeded = errors.New("monthly LLM budget exceeded")
+)
+
+// Feature identifies what a model call was made for.
+type Feature string
+
+const (
+	FeatureScriptGeneration   Feature = "script_generation"
+	FeatureStepSuggestion     Feature = "step_suggestion"
+	FeatureProcedureAuthoring Feature = "procedure_authoring"
+	FeatureGuideNarration     Feature = "guide_narration"
+	FeatureUIExploration      Feature = "ui_exploration"
+	FeatureProcedureExecution Feature = "procedure_execution"
+)
+
+// IsValid checks if the feature is valid.
+func (f Feature) IsValid() bool {
+	switch f {
+	case FeatureScriptGeneration, FeatureStepSuggestion, FeatureProcedureAuthoring,
+		FeatureGuideNarration, FeatureUIExploration, FeatureProcedureExecution:
+		return true
+	default:
+		return false
+	}
+}
+
+// Usage is the tokens consumed by one or more model calls. CostUSD is set
+// when the provider reports the cost itself, as the agents do; otherwise it
+// is priced from the tokens.
+type Usage struct {
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+}
+
+// IsZero reports whether no usage was recorded.
+func (u Usage) IsZero() bool {
+	return u.InputTokens == 0 && u.OutputTokens == 0 && u.CostUSD == 0
+}
+
+// Add returns the sum of two usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:  u.InputTokens + other.InputTokens,
+		OutputTokens: u.OutputTokens + other.OutputTokens,
+		CostUSD:      u.CostUSD + other.CostUSD,
+	}
+}
+
+// Pricing is the price of model tokens, in US dollars per million tokens.
+type Pricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// Cost returns the cost of u, or the cost it reports if it has one.
+func (p Pricing) Cost(u Usage) float64 {
+	if u.CostUSD > 0 {
+		return u.CostUSD
+	}
+	return (float64(u.InputTokens)*p.InputPerMillion + float64(u.OutputTokens)*p.OutputPerMillion) / 1e6
+}
+
+// Event is the usage of the model calls of one action. Like usage events,
+// it is billed to the account that owns the project the action happened in;
+// the actor is the user who performed it.
+type Event struct {
+	ID           uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	AccountID    uuid.UUID `json:"account_id" gorm:"type:char(36);not null;index:idx_llm_usage_events_account_time"`
+	ProjectID    uuid.UUID `json:"project_id" gorm:"type:char(36);not null;index:idx_llm_usage_events_project_time"`
+	ActorID      uuid.UUID `json:"actor_id" gorm:"type:char(36);not null"`
+	Feature      Feature   `json:"feature" gorm:"type:varchar(50);not null"`
+	InputTokens  int64     `json:"input_tokens" gorm:"not null;default:0"`
+	OutputTokens int64     `json:"output_tokens" gorm:"not null;default:0"`
+	CostUSD      float64   `json:"cost_usd" gorm:"column:cost_usd;not null;default:0"`
+	OccurredAt   time.Time `json:"occurred_at" gorm:"not null;index:idx_llm_usage_events_account_time;index:idx_llm_usage_events_project_time"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Event.
+func (Event) TableName() string {
+	return "llm_usage_events"
+}
+
+// BeforeCreate hook to generate UUID and default the occurrence time.
+func (e *Event) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// Validate checks if the event has valid required fields.
+func (e *Event) Validate() error {
+	if e.AccountID == uuid.Nil {
+		return ErrInvalidAccountID
+	}
+	if e.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if !e.Feature.IsValid() {
+		return ErrInvalidFeature
+	}
+	return nil
+}
+
+// Budget limits the cost of the model calls of an account in a calendar
+// month (UTC). A budget with a nil ProjectID covers all of the account's
+// projects; otherwise it covers that project alone. The nil ProjectID is
+// stored rather than NULL so that the unique index holds for account-wide
+// budgets too.
+type Budget struct {
+	ID              uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	AccountID       uuid.UUID `json:"account_id" gorm:"type:char(36);not null;uniqueIndex:idx_llm_budgets_scope"`
+	ProjectID       uuid.UUID `json:"-" gorm:"type:char(36);not null;uniqueIndex:idx_llm_budgets_scope"`
+	MonthlyLimitUSD float64   `json:"monthly_limit_usd" gorm:"column:monthly_limit_usd;not null"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Budget.
+func (Budget) TableName() string {
+	return "llm_budgets"
+}
+
+// BeforeCreate hook to generate UUID.
+func (b *Budget) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the budget has valid required fields.
+func (b *Budget) Validate() error {
+	if b.AccountID == uuid.Nil {
+		return ErrInvalidAccountID
+	}
+	if b.MonthlyLimitUSD <= 0 {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// Scope describes what the budget covers, for error messages.
+func (b *Budget) Scope() string {
+	if b.ProjectID == uuid.Nil {
+		return "account"
+	}
+	return "project"
+}
+
+// ExceededError describes a model call blocked by a spent budget.
+type ExceededError struct {
+	Budget   *Budget
+	SpentUSD float64
+	Month    string
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s: the %s has spent $%.2f of its $%.2f budget for %s",
+		ErrBudgetExceeded, e.Budget.Scope(), e.SpentUSD, e.Budget.MonthlyLimitUSD, e.Month)
+}
+
+// Is reports whether target is ErrBudgetExceeded.
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
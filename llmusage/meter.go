@@ -0,0 +1,110 @@
+package llmusage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+)
+
+// Meter records the usage of model calls against the account owning the
+// project they were made in, and checks the account's budgets before new
+// calls are made. Recording is best-effort like usage metering: failures are
+// logged and never surface to the caller. A nil Meter records nothing and
+// allows every call.
+type Meter struct {
+	store        Store
+	projectStore project.Store
+	pricing      Pricing
+	logger       logger.Logger
+}
+
+// NewMeter creates a new LLM usage meter pricing tokens with pricing.
+func NewMeter(store Store, projectStore project.Store, pricing Pricing, log logger.Logger) *Meter {
+	return &Meter{
+		store:        store,
+		projectStore: projectStore,
+		pricing:      pricing,
+		logger:       log,
+	}
+}
+
+// Check returns an *ExceededError if the account-wide budget of the account
+// owning the project, or the budget of the project itself, is spent for the
+// current month.
+func (m *Meter) Check(ctx context.Context, projectID uuid.UUID) error {
+	if m == nil {
+		return nil
+	}
+
+	proj, err := m.projectStore.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	budgets, err := m.store.ListBudgets(ctx, proj.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	start := MonthStart(time.Now().UTC())
+	end := start.AddDate(0, 1, 0)
+	for _, budget := range budgets {
+		if budget.ProjectID != uuid.Nil && budget.ProjectID != projectID {
+			continue
+		}
+		spent, err := m.store.SpentUSD(ctx, proj.OwnerID, budget.ProjectID, start, end)
+		if err != nil {
+			return err
+		}
+		if spent >= budget.MonthlyLimitUSD {
+			m.logger.Info(ctx, "model call rejected by llm budget", map[string]interface{}{
+				"project_id":        projectID.String(),
+				"scope":             budget.Scope(),
+				"spent_usd":         spent,
+				"monthly_limit_usd": budget.MonthlyLimitUSD,
+			})
+			return &ExceededError{Budget: budget, SpentUSD: spent, Month: start.Format(monthLayout)}
+		}
+	}
+	return nil
+}
+
+// Record records the usage of the model calls of one action.
+func (m *Meter) Record(ctx context.Context, projectID, actorID uuid.UUID, feature Feature, usage Usage) {
+	if m == nil || usage.IsZero() {
+		return
+	}
+
+	// Usage is recorded after the model calls have been made; it must still
+	// be recorded when the request that made them has been cancelled.
+	ctx = context.WithoutCancel(ctx)
+
+	proj, err := m.projectStore.GetByID(ctx, projectID)
+	if err != nil {
+		m.logger.Error(ctx, "failed to resolve account for llm usage event", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+			"feature":    feature,
+		})
+		return
+	}
+
+	event := &Event{
+		AccountID:    proj.OwnerID,
+		ProjectID:    projectID,
+		ActorID:      actorID,
+		Feature:      feature,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		CostUSD:      m.pricing.Cost(usage),
+	}
+	if err := m.store.Record(ctx, event); err != nil {
+		m.logger.Error(ctx, "failed to record llm usage event", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+			"feature":    feature,
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package llmusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPricing_Cost(t *testing.T) {
+	pricing := Pricing{InputPerMillion: 3, OutputPerMillion: 15}
+	assert.InDelta(t, 0.0105, pricing.Cost(Usage{InputTokens: 1000, OutputTokens: 500}), 1e-9)
+	assert.Equal(t, 0.42, pricing.Cost(Usage{InputTokens: 1000, CostUSD: 0.42}))
+}
+
+func TestTrack(t *testing.T) {
+	// Reports outside a tracked context are dropped.
+	Add(context.Background(), Usage{InputTokens: 1})
+
+	ctx, tracker := Track(context.Background())
+	Add(ctx, Usage{InputTokens: 100, OutputTokens: 10})
+	Add(ctx, Usage{InputTokens: 50, OutputTokens: 5})
+	assert.Equal(t, Usage{InputTokens: 150, OutputTokens: 15}, tracker.Usage())
+}
+
+func TestMeter(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	owner, collaborator := uuid.New(), uuid.New()
+	proj := &project.Project{Name: "Billing", OwnerID: owner}
+	other := &project.Project{Name: "Marketing", OwnerID: owner}
+	require.NoError(t, db.Create(proj).Error)
+	require.NoError(t, db.Create(other).Error)
+
+	meter := NewMeter(store, project.NewMySQLStore(db, logger.NewTestLogger()), Pricing{InputPerMillion: 3, OutputPerMillion: 15}, logger.NewTestLogger())
+	meter.Record(ctx, proj.ID, owner, FeatureScriptGeneration, Usage{InputTokens: 1_000_000, OutputTokens: 100_000})
+	meter.Record(ctx, proj.ID, collaborator, FeatureProcedureExecution, Usage{InputTokens: 20, CostUSD: 1.5})
+	meter.Record(ctx, other.ID, owner, FeatureStepSuggestion, Usage{})
+
+	// Usage in unknown projects cannot be attributed and is dropped.
+	meter.Record(ctx, uuid.New(), owner, FeatureScriptGeneration, Usage{InputTokens: 1})
+
+	// A nil meter records nothing and allows everything.
+	var disabled *Meter
+	disabled.Record(ctx, proj.ID, owner, FeatureScriptGeneration, Usage{InputTokens: 1})
+	require.NoError(t, disabled.Check(ctx, proj.ID))
+
+	report, err := BuildReport(ctx, store, owner, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, Spend{InputTokens: 1_000_020, OutputTokens: 100_000, CostUSD: 6}, report.Total)
+	require.Len(t, report.Projects, 1)
+	assert.Equal(t, proj.ID, report.Projects[0].ProjectID)
+	assert.Len(t, report.Users, 2)
+	assert.Len(t, report.Features, 2)
+	assert.Empty(t, report.Budgets)
+
+	t.Run("no budget allows calls", func(t *testing.T) {
+		assert.NoError(t, meter.Check(ctx, proj.ID))
+	})
+
+	t.Run("spent project budget blocks only its project", func(t *testing.T) {
+		require.NoError(t, store.SetBudget(ctx, &Budget{AccountID: owner, ProjectID: proj.ID, MonthlyLimitUSD: 5}))
+		t.Cleanup(func() { store.DeleteBudget(ctx, owner, proj.ID) })
+
+		err := meter.Check(ctx, proj.ID)
+		require.ErrorIs(t, err, ErrBudgetExceeded)
+		var exceeded *ExceededError
+		require.ErrorAs(t, err, &exceeded)
+		assert.Equal(t, float64(6), exceeded.SpentUSD)
+		assert.Contains(t, err.Error(), "the project has spent $6.00 of its $5.00 budget")
+
+		assert.NoError(t, meter.Check(ctx, other.ID))
+	})
+
+	t.Run("spent account budget blocks every project", func(t *testing.T) {
+		require.NoError(t, store.SetBudget(ctx, &Budget{AccountID: owner, MonthlyLimitUSD: 6}))
+		t.Cleanup(func() { store.DeleteBudget(ctx, owner, uuid.Nil) })
+
+		assert.ErrorIs(t, meter.Check(ctx, other.ID), ErrBudgetExceeded)
+	})
+
+	t.Run("budget with room allows calls", func(t *testing.T) {
+		require.NoError(t, store.SetBudget(ctx, &Budget{AccountID: owner, MonthlyLimitUSD: 10}))
+		t.Cleanup(func() { store.DeleteBudget(ctx, owner, uuid.Nil) })
+
+		assert.NoError(t, meter.Check(ctx, proj.ID))
+
+		report, err := BuildReport(ctx, store, owner, time.Now().UTC())
+		require.NoError(t, err)
+		require.Len(t, report.Budgets, 1)
+		assert.Equal(t, BudgetStatus{Scope: "account", MonthlyLimitUSD: 10, SpentUSD: 6, RemainingUSD: 4}, report.Budgets[0])
+	})
+}
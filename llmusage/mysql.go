@@ -0,0 +1,162 @@
+package llmusage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed LLM usage store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Record stores a usage event in the database.
+func (s *MySQLStore) Record(ctx context.Context, event *Event) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(event).Error; err != nil {
+		s.logger.Error(ctx, "failed to record llm usage event", map[string]interface{}{
+			"error":      err.Error(),
+			"account_id": event.AccountID.String(),
+			"feature":    event.Feature,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// Totals sums the usage of an account by project, actor and feature.
+func (s *MySQLStore) Totals(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]Total, error) {
+	var totals []Total
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
+		Model(&Event{}).
+		Select("project_id, actor_id, feature, SUM(input_tokens) AS input_tokens, SUM(output_tokens) AS output_tokens, SUM(cost_usd) AS cost_usd").
+		Where("account_id = ? AND occurred_at >= ? AND occurred_at < ?", accountID, from, to).
+		Group("project_id, actor_id, feature").
+		Scan(&totals).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to sum llm usage events", map[string]interface{}{
+			"error":      err.Error(),
+			"account_id": accountID.String(),
+		})
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// SpentUSD sums the cost of an account's events in a period.
+func (s *MySQLStore) SpentUSD(ctx context.Context, accountID, projectID uuid.UUID, from, to time.Time) (float64, error) {
+	var spent float64
+	query := s.db.WithContext(ctx).
+		Model(&Event{}).
+		Select("COALESCE(SUM(cost_usd), 0)").
+		Where("account_id = ? AND occurred_at >= ? AND occurred_at < ?", accountID, from, to)
+	if projectID != uuid.Nil {
+		query = query.Where("project_id = ?", projectID)
+	}
+
+	if err := query.Scan(&spent).Error; err != nil {
+		s.logger.Error(ctx, "failed to sum llm spend", map[string]interface{}{
+			"error":      err.Error(),
+			"account_id": accountID.String(),
+			"project_id": projectID.String(),
+		})
+		return 0, err
+	}
+
+	return spent, nil
+}
+
+// SetBudget creates or replaces the budget of its account and project.
+func (s *MySQLStore) SetBudget(ctx context.Context, budget *Budget) error {
+	if err := budget.Validate(); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "account_id"}, {Name: "project_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"monthly_limit_usd", "updated_at"}),
+		}).
+		Create(budget).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to save llm budget", map[string]interface{}{
+			"error":      err.Error(),
+			"account_id": budget.AccountID.String(),
+			"project_id": budget.ProjectID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "llm budget saved", map[string]interface{}{
+		"account_id":        budget.AccountID.String(),
+		"project_id":        budget.ProjectID.String(),
+		"monthly_limit_usd": budget.MonthlyLimitUSD,
+	})
+
+	return nil
+}
+
+// ListBudgets retrieves the budgets of an account.
+func (s *MySQLStore) ListBudgets(ctx context.Context, accountID uuid.UUID) ([]*Budget, error) {
+	var budgets []*Budget
+	err := s.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("project_id ASC").
+		Find(&budgets).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to list llm budgets", map[string]interface{}{
+			"error":      err.Error(),
+			"account_id": accountID.String(),
+		})
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// DeleteBudget deletes a budget of an account.
+func (s *MySQLStore) DeleteBudget(ctx context.Context, accountID, projectID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("account_id = ? AND project_id = ?", accountID, projectID).
+		Delete(&Budget{})
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete llm budget", map[string]interface{}{
+			"error":      result.Error.Error(),
+			"account_id": accountID.String(),
+			"project_id": projectID.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBudgetNotFound
+	}
+
+	s.logger.Info(ctx, "llm budget deleted", map[string]interface{}{
+		"account_id": accountID.String(),
+		"project_id": projectID.String(),
+	})
+
+	return nil
+}
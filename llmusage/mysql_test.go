@@ -0,0 +1,109 @@
+package llmusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Record(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("defaults id and occurrence time", func(t *testing.T) {
+		event := &Event{AccountID: uuid.New(), ProjectID: uuid.New(), ActorID: uuid.New(), Feature: FeatureScriptGeneration, InputTokens: 100}
+		require.NoError(t, store.Record(ctx, event))
+		assert.NotEqual(t, uuid.Nil, event.ID)
+		assert.False(t, event.OccurredAt.IsZero())
+	})
+
+	t.Run("invalid feature returns error", func(t *testing.T) {
+		event := &Event{AccountID: uuid.New(), ProjectID: uuid.New(), Feature: "chat"}
+		assert.ErrorIs(t, store.Record(ctx, event), ErrInvalidFeature)
+	})
+
+	t.Run("missing project returns error", func(t *testing.T) {
+		event := &Event{AccountID: uuid.New(), Feature: FeatureStepSuggestion}
+		assert.ErrorIs(t, store.Record(ctx, event), ErrInvalidProjectID)
+	})
+}
+
+func TestMySQLStore_Totals(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	account, user := uuid.New(), uuid.New()
+	projectA, projectB := uuid.New(), uuid.New()
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	november := october.AddDate(0, 1, 0)
+
+	record := func(accountID, projectID, actorID uuid.UUID, feature Feature, cost float64, at time.Time) {
+		require.NoError(t, store.Record(ctx, &Event{
+			AccountID: accountID, ProjectID: projectID, ActorID: actorID, Feature: feature,
+			InputTokens: 1000, OutputTokens: 100, CostUSD: cost, OccurredAt: at,
+		}))
+	}
+	record(account, projectA, account, FeatureScriptGeneration, 0.5, october.Add(time.Hour))
+	record(account, projectA, account, FeatureScriptGeneration, 0.25, october.Add(48*time.Hour))
+	record(account, projectA, user, FeatureScriptGeneration, 1, october.Add(48*time.Hour))
+	record(account, projectB, account, FeatureUIExploration, 2, october.Add(72*time.Hour))
+	record(account, projectA, account, FeatureScriptGeneration, 4, october.Add(-time.Hour))
+	record(account, projectA, account, FeatureScriptGeneration, 8, november)
+	record(uuid.New(), projectA, account, FeatureScriptGeneration, 16, october.Add(time.Hour))
+
+	totals, err := store.Totals(ctx, account, october, november)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Total{
+		{ProjectID: projectA, ActorID: account, Feature: FeatureScriptGeneration, InputTokens: 2000, OutputTokens: 200, CostUSD: 0.75},
+		{ProjectID: projectA, ActorID: user, Feature: FeatureScriptGeneration, InputTokens: 1000, OutputTokens: 100, CostUSD: 1},
+		{ProjectID: projectB, ActorID: account, Feature: FeatureUIExploration, InputTokens: 1000, OutputTokens: 100, CostUSD: 2},
+	}, totals)
+
+	t.Run("spent by account and by project", func(t *testing.T) {
+		spent, err := store.SpentUSD(ctx, account, uuid.Nil, october, november)
+		require.NoError(t, err)
+		assert.Equal(t, 3.75, spent)
+
+		spent, err = store.SpentUSD(ctx, account, projectB, october, november)
+		require.NoError(t, err)
+		assert.Equal(t, float64(2), spent)
+
+		spent, err = store.SpentUSD(ctx, uuid.New(), uuid.Nil, october, november)
+		require.NoError(t, err)
+		assert.Zero(t, spent)
+	})
+}
+
+func TestMySQLStore_Budgets(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	account, projectID := uuid.New(), uuid.New()
+
+	t.Run("invalid limit returns error", func(t *testing.T) {
+		assert.ErrorIs(t, store.SetBudget(ctx, &Budget{AccountID: account}), ErrInvalidLimit)
+	})
+
+	require.NoError(t, store.SetBudget(ctx, &Budget{AccountID: account, ProjectID: projectID, MonthlyLimitUSD: 10}))
+	require.NoError(t, store.SetBudget(ctx, &Budget{AccountID: account, MonthlyLimitUSD: 50}))
+	require.NoError(t, store.SetBudget(ctx, &Budget{AccountID: account, MonthlyLimitUSD: 100}))
+	require.NoError(t, store.SetBudget(ctx, &Budget{AccountID: uuid.New(), MonthlyLimitUSD: 5}))
+
+	budgets, err := store.ListBudgets(ctx, account)
+	require.NoError(t, err)
+	require.Len(t, budgets, 2)
+	assert.Equal(t, "account", budgets[0].Scope())
+	assert.Equal(t, float64(100), budgets[0].MonthlyLimitUSD)
+	assert.Equal(t, projectID, budgets[1].ProjectID)
+
+	require.NoError(t, store.DeleteBudget(ctx, account, projectID))
+	assert.ErrorIs(t, store.DeleteBudget(ctx, account, projectID), ErrBudgetNotFound)
+
+	budgets, err = store.ListBudgets(ctx, account)
+	require.NoError(t, err)
+	assert.Len(t, budgets, 1)
+}
@@ -0,0 +1,176 @@
+package llmusage
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const monthLayout = "2006-01"
+
+// Spend is the tokens and cost of a group of model calls.
+type Spend struct {
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+func (s *Spend) add(t Total) {
+	s.InputTokens += t.InputTokens
+	s.OutputTokens += t.OutputTokens
+	s.CostUSD += t.CostUSD
+}
+
+// ProjectSpend is the spend of a single project in a report.
+type ProjectSpend struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Spend
+}
+
+// UserSpend is the spend of a single user in a report.
+type UserSpend struct {
+	UserID uuid.UUID `json:"user_id"`
+	Spend
+}
+
+// FeatureSpend is the spend of a single feature in a report.
+type FeatureSpend struct {
+	Feature Feature `json:"feature"`
+	Spend
+}
+
+// BudgetStatus is how much of a budget was spent in a report's month.
+type BudgetStatus struct {
+	Scope           string     `json:"scope"`
+	ProjectID       *uuid.UUID `json:"project_id,omitempty"`
+	MonthlyLimitUSD float64    `json:"monthly_limit_usd"`
+	SpentUSD        float64    `json:"spent_usd"`
+	RemainingUSD    float64    `json:"remaining_usd"`
+	Exceeded        bool       `json:"exceeded"`
+}
+
+// NewBudgetStatus reports how much of budget is spent.
+func NewBudgetStatus(budget *Budget, spentUSD float64) BudgetStatus {
+	status := BudgetStatus{
+		Scope:           budget.Scope(),
+		MonthlyLimitUSD: budget.MonthlyLimitUSD,
+		SpentUSD:        roundTo(spentUSD, 4),
+		RemainingUSD:    roundTo(math.Max(budget.MonthlyLimitUSD-spentUSD, 0), 4),
+		Exceeded:        spentUSD >= budget.MonthlyLimitUSD,
+	}
+	if budget.ProjectID != uuid.Nil {
+		projectID := budget.ProjectID
+		status.ProjectID = &projectID
+	}
+	return status
+}
+
+// Report is the monthly LLM usage of an account, by project, by user and by
+// feature, with the status of its budgets.
+type Report struct {
+	AccountID   uuid.UUID      `json:"account_id"`
+	Month       string         `json:"month"`
+	PeriodStart time.Time      `json:"period_start"`
+	PeriodEnd   time.Time      `json:"period_end"`
+	Total       Spend          `json:"total"`
+	Projects    []ProjectSpend `json:"projects"`
+	Users       []UserSpend    `json:"users"`
+	Features    []FeatureSpend `json:"features"`
+	Budgets     []BudgetStatus `json:"budgets"`
+}
+
+// BuildReport aggregates the LLM usage of an account for the month starting
+// at month.
+func BuildReport(ctx context.Context, store Store, accountID uuid.UUID, month time.Time) (*Report, error) {
+	start := MonthStart(month)
+	end := start.AddDate(0, 1, 0)
+
+	totals, err := store.Totals(ctx, accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := store.ListBudgets(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		AccountID:   accountID,
+		Month:       start.Format(monthLayout),
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Projects:    []ProjectSpend{},
+		Users:       []UserSpend{},
+		Features:    []FeatureSpend{},
+		Budgets:     make([]BudgetStatus, 0, len(budgets)),
+	}
+
+	byProject := make(map[uuid.UUID]*Spend)
+	byUser := make(map[uuid.UUID]*Spend)
+	byFeature := make(map[Feature]*Spend)
+	for _, t := range totals {
+		report.Total.add(t)
+		spendFor(byProject, t.ProjectID).add(t)
+		spendFor(byUser, t.ActorID).add(t)
+		spendFor(byFeature, t.Feature).add(t)
+	}
+	report.Total.CostUSD = roundTo(report.Total.CostUSD, 4)
+
+	for projectID, spend := range byProject {
+		spend.CostUSD = roundTo(spend.CostUSD, 4)
+		report.Projects = append(report.Projects, ProjectSpend{ProjectID: projectID, Spend: *spend})
+	}
+	for userID, spend := range byUser {
+		spend.CostUSD = roundTo(spend.CostUSD, 4)
+		report.Users = append(report.Users, UserSpend{UserID: userID, Spend: *spend})
+	}
+	for feature, spend := range byFeature {
+		spend.CostUSD = roundTo(spend.CostUSD, 4)
+		report.Features = append(report.Features, FeatureSpend{Feature: feature, Spend: *spend})
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		return report.Projects[i].ProjectID.String() < report.Projects[j].ProjectID.String()
+	})
+	sort.Slice(report.Users, func(i, j int) bool {
+		return report.Users[i].UserID.String() < report.Users[j].UserID.String()
+	})
+	sort.Slice(report.Features, func(i, j int) bool {
+		return report.Features[i].Feature < report.Features[j].Feature
+	})
+
+	for _, budget := range budgets {
+		spent := report.Total.CostUSD
+		if budget.ProjectID != uuid.Nil {
+			spent = 0
+			if spend, ok := byProject[budget.ProjectID]; ok {
+				spent = spend.CostUSD
+			}
+		}
+		report.Budgets = append(report.Budgets, NewBudgetStatus(budget, spent))
+	}
+
+	return report, nil
+}
+
+func spendFor[K comparable](spends map[K]*Spend, key K) *Spend {
+	spend, ok := spends[key]
+	if !ok {
+		spend = &Spend{}
+		spends[key] = spend
+	}
+	return spend
+}
+
+// MonthStart returns the first instant, in UTC, of the month t falls in.
+// Budgets are counted per calendar month.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
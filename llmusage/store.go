@@ -0,0 +1,46 @@
+package llmusage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Total is the usage of an account's model calls by one actor in one
+// project for one feature.
+type Total struct {
+	ProjectID    uuid.UUID
+	ActorID      uuid.UUID
+	Feature      Feature
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// Store defines the interface for LLM usage and budget persistence
+// operations.
+type Store interface {
+	// Record stores a usage event.
+	Record(ctx context.Context, event *Event) error
+
+	// Totals sums the usage of an account over events that occurred in
+	// [from, to), by project, actor and feature.
+	Totals(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]Total, error)
+
+	// SpentUSD sums the cost of an account's events that occurred in
+	// [from, to), of one project or, when projectID is uuid.Nil, of all of
+	// them.
+	SpentUSD(ctx context.Context, accountID, projectID uuid.UUID, from, to time.Time) (float64, error)
+
+	// SetBudget creates or replaces the budget of its account and project.
+	SetBudget(ctx context.Context, budget *Budget) error
+
+	// ListBudgets retrieves the budgets of an account, the account-wide one
+	// first.
+	ListBudgets(ctx context.Context, accountID uuid.UUID) ([]*Budget, error)
+
+	// DeleteBudget deletes the budget of an account's project or, when
+	// projectID is uuid.Nil, the account-wide one.
+	DeleteBudget(ctx context.Context, accountID, projectID uuid.UUID) error
+}
@@ -0,0 +1,41 @@
+package llmusage
+
+import (
+	"context"
+	"sync"
+)
+
+type trackerKey struct{}
+
+// Tracker collects the usage model clients report to a context.
+type Tracker struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// Track returns a context whose model calls are collected by the returned
+// tracker.
+func Track(ctx context.Context) (context.Context, *Tracker) {
+	t := &Tracker{}
+	return context.WithValue(ctx, trackerKey{}, t), t
+}
+
+// Add adds the usage of a model call to the tracker of ctx, if any.
+// Model clients call it for every call, including ones whose reply turned
+// out to be unusable, since the tokens were spent all the same.
+func Add(ctx context.Context, u Usage) {
+	t, ok := ctx.Value(trackerKey{}).(*Tracker)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage = t.usage.Add(u)
+}
+
+// Usage returns the usage collected so far.
+func (t *Tracker) Usage() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usage
+}
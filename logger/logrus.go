@@ -32,6 +32,17 @@ func NewLogrusLogger(level string) *LogrusLogger {
 	}
 }
 
+// SetLevel changes the minimum level of messages logged, such as when the
+// configuration is reloaded.
+func (l *LogrusLogger) SetLevel(level string) error {
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.logger.SetLevel(logLevel)
+	return nil
+}
+
 // Debug logs a debug-level message.
 func (l *LogrusLogger) Debug(ctx context.Context, msg string, fields map[string]interface{}) {
 	if fields != nil {
@@ -0,0 +1,90 @@
+// Package markdown handles the Markdown that procedure descriptions and step
+// instructions are written in. Sanitize strips what is unsafe to show from
+// the source before it is saved, ToHTML renders it for previews and HTML
+// guides, and Demote nests it under a heading in an exported document.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// safeSchemes are the URL schemes links and images may use. URLs without a
+// scheme, such as "./images/step1.png" or "#setup", are always allowed.
+var safeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// schemePattern matches the scheme of an absolute URL.
+var schemePattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):`)
+
+// SafeURL reports whether a link or image may point to url: it must be
+// relative or use http, https or mailto. Character references are decoded
+// and control characters and spaces dropped first, as browsers do, so that
+// "jav&#x61;script:" is not mistaken for a relative URL.
+func SafeURL(url string) bool {
+	url = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(url))
+	m := schemePattern.FindStringSubmatch(url)
+	if m == nil {
+		// A colon before any slash would still be read as a scheme
+		slash := strings.IndexAny(url, "/?#")
+		colon := strings.IndexByte(url, ':')
+		return colon < 0 || (slash >= 0 && slash < colon)
+	}
+	return safeSchemes[strings.ToLower(m[1])]
+}
+
+// fencePattern matches the opening line of a fenced code block.
+var fencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+
+// closesFence reports whether line closes a code block opened by fence.
+func closesFence(line, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	run := strings.TrimRight(trimmed, " \t")
+	return len(run) >= len(fence) && strings.Trim(run, fence[:1]) == ""
+}
+
+// headingPattern matches an ATX heading.
+var headingPattern = regexp.MustCompile(`^( {0,3})(#{1,6})([ \t].*)?$`)
+
+// Demote pushes the headings of src down by levels, so that a step's
+// instructions can sit under the step's own heading in an exported
+// document. Headings never go below level 6, and code blocks are left
+// alone.
+func Demote(src string, levels int) string {
+	lines := strings.Split(src, "\n")
+	fence := ""
+	for i, line := range lines {
+		if fence != "" {
+			if closesFence(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			continue
+		}
+		m := headingPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		level := len(m[2]) + levels
+		if level > 6 {
+			level = 6
+		}
+		lines[i] = m[1] + strings.Repeat("#", level) + m[3]
+	}
+	return strings.Join(lines, "\n")
+}
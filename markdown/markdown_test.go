@@ -0,0 +1,13 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDemote(t *testing.T) {
+	src := "# Setup\n\nText\n\n```sh\n# a comment\n```\n\n##### Deep\n#hashtag"
+	want := "### Setup\n\nText\n\n```sh\n# a comment\n```\n\n###### Deep\n#hashtag"
+	assert.Equal(t, want, Demote(src, 2))
+}
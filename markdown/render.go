@@ -0,0 +1,544 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// blankPattern matches a line with nothing on it.
+	blankPattern = regexp.MustCompile(`^[ \t]*$`)
+
+	// setextPattern matches the underline of a setext heading.
+	setextPattern = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+
+	// quotePattern matches a block quote line.
+	quotePattern = regexp.MustCompile(`^ {0,3}> ?`)
+
+	// itemPattern matches the first line of a list item.
+	itemPattern = regexp.MustCompile(`^( {0,3})([-*+]|(\d{1,9})[.)])([ \t]+|$)`)
+
+	// entityPattern matches a character reference such as "&amp;".
+	entityPattern = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+
+	// inlineAutolinkPattern matches an autolink such as <https://example.com>.
+	inlineAutolinkPattern = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*|[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9.-]+)>`)
+)
+
+// ToHTML renders the Markdown in src as HTML. It supports headings,
+// paragraphs, emphasis, code, links, images, block quotes, lists and
+// thematic breaks; HTML in src is shown as text rather than passed
+// through, and links and images to unsafe URLs are dropped, so the result
+// is safe to show as is.
+func ToHTML(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var out strings.Builder
+	renderBlocks(&out, lines, false)
+	return out.String()
+}
+
+// renderBlocks renders lines as a sequence of blocks. In a tight list
+// item, paragraphs are not wrapped in <p>.
+func renderBlocks(out *strings.Builder, lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case blankPattern.MatchString(line):
+			i++
+		case fencePattern.MatchString(line):
+			i = renderFence(out, lines, i)
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			level := len(m[2])
+			text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(m[3]), "#"))
+			fmt.Fprintf(out, "<h%d>%s</h%d>\n", level, renderInline(text), level)
+			i++
+		case isBreak(line):
+			out.WriteString("<hr>\n")
+			i++
+		case quotePattern.MatchString(line):
+			i = renderQuote(out, lines, i)
+		case itemPattern.MatchString(line):
+			i = renderList(out, lines, i)
+		default:
+			i = renderParagraph(out, lines, i, tight)
+		}
+	}
+}
+
+// renderFence renders the fenced code block starting at lines[i] and
+// returns the index of the line after it.
+func renderFence(out *strings.Builder, lines []string, i int) int {
+	m := fencePattern.FindStringSubmatch(lines[i])
+	fence, info := m[1], strings.Fields(m[2])
+	indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " "))
+	var code []string
+	for i++; i < len(lines) && !closesFence(lines[i], fence); i++ {
+		line := lines[i]
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		code = append(code, line)
+	}
+	if len(info) > 0 {
+		fmt.Fprintf(out, `<pre><code class="language-%s">`, html.EscapeString(info[0]))
+	} else {
+		out.WriteString("<pre><code>")
+	}
+	if len(code) > 0 {
+		out.WriteString(html.EscapeString(strings.Join(code, "\n")) + "\n")
+	}
+	out.WriteString("</code></pre>\n")
+	return i + 1
+}
+
+// renderQuote renders the block quote starting at lines[i] and returns the
+// index of the line after it.
+func renderQuote(out *strings.Builder, lines []string, i int) int {
+	var quoted []string
+	for ; i < len(lines); i++ {
+		loc := quotePattern.FindStringIndex(lines[i])
+		if loc != nil {
+			quoted = append(quoted, lines[i][loc[1]:])
+			continue
+		}
+		// A paragraph carries on into unmarked lines that follow it
+		if blankPattern.MatchString(lines[i]) || len(quoted) == 0 || blankPattern.MatchString(quoted[len(quoted)-1]) || startsBlock(lines[i]) {
+			break
+		}
+		quoted = append(quoted, lines[i])
+	}
+	out.WriteString("<blockquote>\n")
+	renderBlocks(out, quoted, false)
+	out.WriteString("</blockquote>\n")
+	return i
+}
+
+// renderList renders the list starting at lines[i] and returns the index
+// of the line after it.
+func renderList(out *strings.Builder, lines []string, i int) int {
+	first := itemPattern.FindStringSubmatch(lines[i])
+	ordered := first[3] != ""
+	delimiter := first[2][len(first[2])-1:]
+
+	var items [][]string
+	loose, gap := false, false
+	for i < len(lines) {
+		m := itemPattern.FindStringSubmatch(lines[i])
+		if m == nil || (m[3] != "") != ordered || m[2][len(m[2])-1:] != delimiter {
+			break
+		}
+		// Blank lines between items make the list loose
+		loose = loose || gap
+		// The item's content is indented to its text
+		width := len(m[0])
+		if blankPattern.MatchString(lines[i][len(m[0]):]) {
+			width = len(m[1]) + len(m[2]) + 1
+		}
+		item := []string{lines[i][len(m[0]):]}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if blankPattern.MatchString(line) {
+				item = append(item, "")
+				continue
+			}
+			if indentOf(line) >= width {
+				item = append(item, dedent(line, width))
+				continue
+			}
+			// An unindented line carries on the item's paragraph
+			if !blankPattern.MatchString(item[len(item)-1]) && !startsBlock(line) {
+				item = append(item, line)
+				continue
+			}
+			break
+		}
+		gap = false
+		for len(item) > 0 && item[len(item)-1] == "" {
+			item = item[:len(item)-1]
+			gap = true
+		}
+		for _, line := range item {
+			if line == "" {
+				loose = true
+			}
+		}
+		items = append(items, item)
+	}
+
+	if ordered {
+		if start := strings.TrimLeft(first[3], "0"); start != "1" {
+			if start == "" {
+				start = "0"
+			}
+			fmt.Fprintf(out, "<ol start=\"%s\">\n", start)
+		} else {
+			out.WriteString("<ol>\n")
+		}
+	} else {
+		out.WriteString("<ul>\n")
+	}
+	for _, item := range items {
+		var content strings.Builder
+		renderBlocks(&content, item, !loose)
+		out.WriteString("<li>" + strings.TrimSuffix(content.String(), "\n") + "</li>\n")
+	}
+	if ordered {
+		out.WriteString("</ol>\n")
+	} else {
+		out.WriteString("</ul>\n")
+	}
+	return i
+}
+
+// renderParagraph renders the paragraph starting at lines[i], or the
+// setext heading it turns out to be, and returns the index of the line
+// after it.
+func renderParagraph(out *strings.Builder, lines []string, i int, tight bool) int {
+	var text []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if len(text) > 0 {
+			if m := setextPattern.FindStringSubmatch(line); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				fmt.Fprintf(out, "<h%d>%s</h%d>\n", level, renderInline(strings.Join(text, "\n")), level)
+				return i + 1
+			}
+			if blankPattern.MatchString(line) || startsBlock(line) {
+				break
+			}
+		}
+		text = append(text, strings.TrimLeft(line, " \t"))
+	}
+	content := renderInline(strings.TrimRight(strings.Join(text, "\n"), " \t"))
+	if tight {
+		out.WriteString(content + "\n")
+	} else {
+		out.WriteString("<p>" + content + "</p>\n")
+	}
+	return i
+}
+
+// startsBlock reports whether line starts a block that ends a paragraph.
+func startsBlock(line string) bool {
+	return fencePattern.MatchString(line) || headingPattern.MatchString(line) ||
+		isBreak(line) || quotePattern.MatchString(line) || itemPattern.MatchString(line)
+}
+
+// isBreak reports whether line is a thematic break such as "---" or
+// "* * *".
+func isBreak(line string) bool {
+	if indentOf(line) > 3 {
+		return false
+	}
+	marks := strings.NewReplacer(" ", "", "\t", "").Replace(line)
+	return len(marks) >= 3 && strings.Trim(marks, marks[:1]) == "" && strings.ContainsAny(marks[:1], "-*_")
+}
+
+// indentOf returns the number of leading spaces in line, counting a tab as
+// four.
+func indentOf(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// dedent removes up to width columns of leading whitespace from line.
+func dedent(line string, width int) string {
+	n := 0
+	for n < width && line != "" {
+		switch line[0] {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return line
+		}
+		line = line[1:]
+	}
+	return line
+}
+
+// renderInline renders the inline Markdown in s: code spans, emphasis,
+// links, images and line breaks.
+func renderInline(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			out.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			out.WriteString("<br>\n")
+			i += 2
+			continue
+		case c == '\n':
+			if strings.HasSuffix(s[:i], "  ") {
+				trimmed := strings.TrimRight(out.String(), " ")
+				out.Reset()
+				out.WriteString(trimmed + "<br>")
+			}
+			out.WriteString("\n")
+			i++
+			continue
+		case c == '`':
+			if rendered, n := codeSpan(s[i:]); n > 0 {
+				out.WriteString(rendered)
+				i += n
+				continue
+			}
+			run := delimiterRun(s[i:], '`')
+			out.WriteString(s[i : i+run])
+			i += run
+			continue
+		case c == '!' && strings.HasPrefix(s[i+1:], "["):
+			if rendered, n := link(s[i+1:], true); n > 0 {
+				out.WriteString(rendered)
+				i += n + 1
+				continue
+			}
+		case c == '[':
+			if rendered, n := link(s[i:], false); n > 0 {
+				out.WriteString(rendered)
+				i += n
+				continue
+			}
+		case c == '<':
+			if m := inlineAutolinkPattern.FindStringSubmatch(s[i:]); m != nil {
+				href := m[1]
+				if !strings.Contains(href, ":") {
+					href = "mailto:" + href
+				}
+				if SafeURL(href) {
+					fmt.Fprintf(&out, `<a href="%s">%s</a>`, html.EscapeString(href), html.EscapeString(m[1]))
+				} else {
+					out.WriteString(html.EscapeString(m[0]))
+				}
+				i += len(m[0])
+				continue
+			}
+		case c == '&':
+			if m := entityPattern.FindString(s[i:]); m != "" {
+				out.WriteString(html.EscapeString(html.UnescapeString(m)))
+				i += len(m)
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			if rendered, n := emphasis(s, i); n > 0 {
+				out.WriteString(rendered)
+				i += n
+				continue
+			}
+			run := delimiterRun(s[i:], c)
+			out.WriteString(s[i : i+run])
+			i += run
+			continue
+		}
+		out.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return out.String()
+}
+
+// isPunct reports whether c is ASCII punctuation, which a backslash
+// escapes.
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// delimiterRun returns the length of the run of c at the start of s.
+func delimiterRun(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+// codeSpan renders the code span at the start of s, returning the HTML and
+// the length of the span, or 0 if the backticks open none.
+func codeSpan(s string) (string, int) {
+	open := delimiterRun(s, '`')
+	for i := open; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		run := delimiterRun(s[i:], '`')
+		if run == open {
+			code := strings.ReplaceAll(s[open:i], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			return "<code>" + html.EscapeString(code) + "</code>", i + run
+		}
+		i += run
+	}
+	return "", 0
+}
+
+// link renders the link, or image if image is set, whose text starts at
+// the start of s, returning the HTML and the length of the link, or 0 if
+// s does not start one. A link to an unsafe URL is rendered as its text.
+func link(s string, image bool) (string, int) {
+	// The text runs to the matching "]"
+	depth, end := 0, -1
+	for i := 0; i < len(s) && end < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 || end+1 >= len(s) || s[end+1] != '(' {
+		return "", 0
+	}
+	text := s[1:end]
+
+	// The destination, then an optional title, up to the closing ")"
+	rest := s[end+2:]
+	j := skipSpace(rest, 0)
+	dest := ""
+	if strings.HasPrefix(rest[j:], "<") {
+		gt := strings.IndexAny(rest[j:], ">\n")
+		if gt < 0 || rest[j+gt] != '>' {
+			return "", 0
+		}
+		dest = rest[j+1 : j+gt]
+		j += gt + 1
+	} else {
+		// Parentheses in the destination must be balanced
+		from, depth := j, 0
+		for ; j < len(rest) && !isSpace(rest[j]); j++ {
+			if rest[j] == '(' {
+				depth++
+			} else if rest[j] == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		dest = rest[from:j]
+	}
+	j = skipSpace(rest, j)
+	title := ""
+	if j < len(rest) && (rest[j] == '"' || rest[j] == '\'') {
+		quote := strings.IndexByte(rest[j+1:], rest[j])
+		if quote < 0 {
+			return "", 0
+		}
+		title = html.UnescapeString(rest[j+1 : j+1+quote])
+		j = skipSpace(rest, j+quote+2)
+	}
+	if j >= len(rest) || rest[j] != ')' {
+		return "", 0
+	}
+	length := end + 2 + j + 1
+
+	attrs := ""
+	if title != "" {
+		attrs = fmt.Sprintf(` title="%s"`, html.EscapeString(title))
+	}
+	href := html.EscapeString(html.UnescapeString(dest))
+	if image {
+		alt := html.EscapeString(plainText(text))
+		if !SafeURL(dest) {
+			return alt, length
+		}
+		return fmt.Sprintf(`<img src="%s" alt="%s"%s>`, href, alt, attrs), length
+	}
+	if !SafeURL(dest) {
+		return renderInline(text), length
+	}
+	return fmt.Sprintf(`<a href="%s"%s>%s</a>`, href, attrs, renderInline(text)), length
+}
+
+// plainText returns the inline Markdown in s without its markup, for an
+// image's alt text.
+func plainText(s string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "[", "", "]", "").Replace(s)
+}
+
+// emphasis renders the emphasis, strong emphasis or strikethrough whose
+// delimiters start at s[i], returning the HTML and the length of the
+// span, or 0 if the delimiters open none.
+func emphasis(s string, i int) (string, int) {
+	c := s[i]
+	run := delimiterRun(s[i:], c)
+	if run > 2 || (c == '~' && run != 2) {
+		return "", 0
+	}
+	open := i + run
+	// An opening run is followed by text, and "_" does not open inside a
+	// word
+	if open >= len(s) || isSpace(s[open]) || (c == '_' && i > 0 && isWordChar(s[i-1])) {
+		return "", 0
+	}
+	delim := s[i:open]
+	for j := open + 1; j < len(s); j++ {
+		if s[j] == '`' {
+			if _, n := codeSpan(s[j:]); n > 0 {
+				j += n - 1
+				continue
+			}
+		}
+		if !strings.HasPrefix(s[j:], delim) || delimiterRun(s[j:], c) != run || isSpace(s[j-1]) {
+			continue
+		}
+		if c == '_' && j+run < len(s) && isWordChar(s[j+run]) {
+			continue
+		}
+		tag := "em"
+		switch {
+		case c == '~':
+			tag = "del"
+		case run == 2:
+			tag = "strong"
+		}
+		return fmt.Sprintf("<%s>%s</%s>", tag, renderInline(s[open:j]), tag), j + run - i
+	}
+	return "", 0
+}
+
+// skipSpace returns the index of the first character in s from i on that
+// is not whitespace.
+func skipSpace(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+// isSpace reports whether c is whitespace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+// isWordChar reports whether c is an ASCII letter or digit.
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
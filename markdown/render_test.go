@@ -0,0 +1,62 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "paragraphs and emphasis",
+			src:  "Open **Settings** and _pick_ `a<b`.\nThen ~~wait~~ save.\n\nDone",
+			want: "<p>Open <strong>Settings</strong> and <em>pick</em> <code>a&lt;b</code>.\nThen <del>wait</del> save.</p>\n<p>Done</p>\n",
+		},
+		{
+			name: "headings and breaks",
+			src:  "# Title #\n\nIntro\n---\n\n***",
+			want: "<h1>Title</h1>\n<h2>Intro</h2>\n<hr>\n",
+		},
+		{
+			name: "html is shown as text",
+			src:  "<script>alert(1)</script> & <b>bold</b>",
+			want: "<p>&lt;script&gt;alert(1)&lt;/script&gt; &amp; &lt;b&gt;bold&lt;/b&gt;</p>\n",
+		},
+		{
+			name: "links and images",
+			src:  `[Docs](https://example.com/?a=1&b=2 "The docs") ![Logo](./logo.png) [bad](javascript:alert(1)) <https://example.com>`,
+			want: `<p><a href="https://example.com/?a=1&amp;b=2" title="The docs">Docs</a> <img src="./logo.png" alt="Logo"> bad <a href="https://example.com">https://example.com</a></p>` + "\n",
+		},
+		{
+			name: "tight and loose lists",
+			src:  "- one\n- two\n  more\n\n3. three\n\n4. four",
+			want: "<ul>\n<li>one</li>\n<li>two\nmore</li>\n</ul>\n<ol start=\"3\">\n<li><p>three</p></li>\n<li><p>four</p></li>\n</ol>\n",
+		},
+		{
+			name: "nested list",
+			src:  "1. Log in\n   - as admin\n2. Log out",
+			want: "<ol>\n<li>Log in\n<ul>\n<li>as admin</li>\n</ul></li>\n<li>Log out</li>\n</ol>\n",
+		},
+		{
+			name: "fenced code and quotes",
+			src:  "```js\nif (a < b) {}\n```\n> Note: *careful*\nstill quoted",
+			want: "<pre><code class=\"language-js\">if (a &lt; b) {}\n</code></pre>\n<blockquote>\n<p>Note: <em>careful</em>\nstill quoted</p>\n</blockquote>\n",
+		},
+		{
+			name: "escapes and hard breaks",
+			src:  "\\*not em\\* snake_case_name  \nnext",
+			want: "<p>*not em* snake_case_name<br>\nnext</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ToHTML(tt.src))
+		})
+	}
+}
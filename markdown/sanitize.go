@@ -0,0 +1,263 @@
+package markdown
+
+import (
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// allowedTags are the HTML elements kept in Markdown, with the attributes
+// each may have. Other elements are removed, keeping their text.
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"details":    nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"s":          nil,
+	"samp":       nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"summary":    nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"align", "colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"align", "colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// droppedTags are the elements removed along with everything in them:
+// scripts, embedded documents and plugins, and the elements whose content
+// browsers do not parse as HTML.
+var droppedTags = map[string]bool{
+	"applet":    true,
+	"embed":     true,
+	"frame":     true,
+	"frameset":  true,
+	"iframe":    true,
+	"math":      true,
+	"noembed":   true,
+	"noframes":  true,
+	"noscript":  true,
+	"object":    true,
+	"plaintext": true,
+	"script":    true,
+	"style":     true,
+	"svg":       true,
+	"template":  true,
+	"textarea":  true,
+	"title":     true,
+	"xmp":       true,
+}
+
+// urlAttributes are the attributes whose values are URLs.
+var urlAttributes = map[string]bool{
+	"href": true,
+	"src":  true,
+}
+
+// autolinkPattern matches a Markdown autolink such as
+// <https://example.com>, which an HTML tokenizer would read as a tag.
+var autolinkPattern = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>"'` + "`" + `]*|[a-zA-Z0-9.!#$%&*+/=?^_{|}~-]+@[a-zA-Z0-9.-]+)>`)
+
+// linkDestinationPattern matches the start of an inline link or image's
+// destination, and referencePattern a link reference definition.
+var (
+	linkDestinationPattern = regexp.MustCompile(`\]\([ \t]*(?:\n[ \t]*)?(<[^<>\n]*>|[^\s()]*(?:\([^\s()]*\)[^\s()]*)*)`)
+	referencePattern       = regexp.MustCompile(`(?m)^( {0,3}\[[^\]\n]+\]:[ \t]*(?:\n[ \t]*)?)(<[^<>\n]*>|\S+)`)
+)
+
+// Sanitize removes what is unsafe to show from the Markdown in src, so
+// that it can be rendered by any Markdown renderer that passes HTML
+// through. Scripts, styles, frames and plugins are removed with their
+// content, other HTML is kept only for a set of formatting elements and
+// attributes, event handlers are dropped, and links and images may only
+// point to relative, http, https or mailto URLs; other destinations are
+// replaced with "#". Everything else is left as written.
+func Sanitize(src string) string {
+	return sanitizeLinks(sanitizeHTML(src))
+}
+
+// sanitizeHTML removes the HTML in src that is not allowed.
+func sanitizeHTML(src string) string {
+	var out strings.Builder
+	rest := src
+	for {
+		i := strings.IndexByte(rest, '<')
+		if i < 0 {
+			out.WriteString(rest)
+			return out.String()
+		}
+		out.WriteString(rest[:i])
+		rest = rest[i:]
+
+		// Autolinks are Markdown, not tags; keep the safe ones as written
+		if m := autolinkPattern.FindStringSubmatch(rest); m != nil {
+			email := strings.Contains(m[1], "@") && !strings.Contains(m[1], ":")
+			if email || SafeURL(m[1]) {
+				out.WriteString(m[0])
+			}
+			rest = rest[len(m[0]):]
+			continue
+		}
+
+		z := nethtml.NewTokenizer(strings.NewReader(rest))
+		rest = rest[sanitizeToken(z, &out):]
+	}
+}
+
+// sanitizeToken writes the allowed form of the token at the start of z's
+// input, and of the element's content if it is dropped, to out. It returns
+// how many bytes of input it consumed.
+func sanitizeToken(z *nethtml.Tokenizer, out *strings.Builder) int {
+	tt := z.Next()
+	consumed := len(z.Raw())
+	switch tt {
+	case nethtml.ErrorToken:
+		// An unfinished tag: escape it, so that nothing added after the
+		// text can complete it
+		if z.Err() == io.EOF && consumed > 0 {
+			out.WriteString("&lt;" + string(z.Raw()[1:]))
+			return consumed
+		}
+		out.WriteString("&lt;")
+		return 1
+	case nethtml.TextToken:
+		// A "<" that starts no tag, such as in "a < b"
+		out.Write(z.Raw())
+		return consumed
+	case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+		token := z.Token()
+		if droppedTags[token.Data] {
+			if tt == nethtml.StartTagToken {
+				consumed += skipElement(z, token.Data)
+			}
+			return consumed
+		}
+		if attrs, ok := allowedTags[token.Data]; ok {
+			writeTag(out, token, attrs)
+		}
+		return consumed
+	case nethtml.EndTagToken:
+		name, _ := z.TagName()
+		if _, ok := allowedTags[string(name)]; ok {
+			out.WriteString("</" + string(name) + ">")
+		}
+		return consumed
+	}
+	// Comments, doctypes and other declarations are dropped
+	return consumed
+}
+
+// skipElement reads z up to and including the end tag of the element name,
+// returning how many bytes it read. Nested elements of the same name are
+// skipped with it.
+func skipElement(z *nethtml.Tokenizer, name string) int {
+	consumed, depth := 0, 1
+	for depth > 0 {
+		tt := z.Next()
+		consumed += len(z.Raw())
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		tagName, _ := z.TagName()
+		if string(tagName) != name {
+			continue
+		}
+		switch tt {
+		case nethtml.StartTagToken:
+			depth++
+		case nethtml.EndTagToken:
+			depth--
+		}
+	}
+	return consumed
+}
+
+// writeTag writes token with only the allowed attrs, dropping URLs that are
+// not safe.
+func writeTag(out *strings.Builder, token nethtml.Token, attrs []string) {
+	out.WriteString("<" + token.Data)
+	for _, attr := range token.Attr {
+		if attr.Namespace != "" || !containsString(attrs, attr.Key) {
+			continue
+		}
+		if urlAttributes[attr.Key] && !SafeURL(attr.Val) {
+			continue
+		}
+		out.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if token.Type == nethtml.SelfClosingTagToken {
+		out.WriteString(" /")
+	}
+	out.WriteString(">")
+}
+
+// sanitizeLinks replaces the destinations of links, images and link
+// reference definitions that are not safe with "#".
+func sanitizeLinks(src string) string {
+	src = replaceSubmatch(linkDestinationPattern, src, 1)
+	return replaceSubmatch(referencePattern, src, 2)
+}
+
+// replaceSubmatch replaces group n of each match of pattern in src with "#"
+// when it is not a safe URL.
+func replaceSubmatch(pattern *regexp.Regexp, src string, n int) string {
+	var out strings.Builder
+	last := 0
+	for _, m := range pattern.FindAllStringSubmatchIndex(src, -1) {
+		start, end := m[2*n], m[2*n+1]
+		url := strings.TrimSuffix(strings.TrimPrefix(src[start:end], "<"), ">")
+		if SafeURL(url) {
+			continue
+		}
+		out.WriteString(src[last:start])
+		out.WriteString("#")
+		last = end
+	}
+	out.WriteString(src[last:])
+	return out.String()
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "plain markdown is unchanged",
+			src:  "Open **Settings** and pick `Billing`.\n\n- one\n- two\n\n[Docs](https://example.com/docs \"Docs\") and a < b",
+			want: "Open **Settings** and pick `Billing`.\n\n- one\n- two\n\n[Docs](https://example.com/docs \"Docs\") and a < b",
+		},
+		{
+			name: "scripts are removed with their content",
+			src:  "Click save<script>alert(1)</script> and wait",
+			want: "Click save and wait",
+		},
+		{
+			name: "iframes and styles are removed",
+			src:  "<iframe src=\"https://evil.example\">x</iframe><style>body{display:none}</style>Done",
+			want: "Done",
+		},
+		{
+			name: "event handlers and unsafe URLs are dropped",
+			src:  `<img src="javascript:alert(1)" alt="logo" onerror="alert(1)"><a href="https://example.com" onclick="x()">ok</a>`,
+			want: `<img alt="logo"><a href="https://example.com">ok</a>`,
+		},
+		{
+			name: "unknown elements keep their text",
+			src:  "<form action=\"/x\"><label>Name</label><input name=\"q\"></form>",
+			want: "Name",
+		},
+		{
+			name: "comments are removed",
+			src:  "a<!-- <script>alert(1)</script> -->b",
+			want: "ab",
+		},
+		{
+			name: "unsafe link destinations are replaced",
+			src:  "[x](javascript:alert(1)) ![y](jav&#x61;script:alert(1)) [z](./page.md)\n\n[ref]: vbscript:msgbox",
+			want: "[x](#) ![y](#) [z](./page.md)\n\n[ref]: #",
+		},
+		{
+			name: "safe autolinks are kept",
+			src:  "See <https://example.com/a?b=1> or <qa@example.com>, not <javascript:alert(1)>",
+			want: "See <https://example.com/a?b=1> or <qa@example.com>, not ",
+		},
+		{
+			name: "unfinished tags are escaped",
+			src:  "if a<b then",
+			want: "if a&lt;b then",
+		},
+		{
+			name: "unclosed scripts remove the rest",
+			src:  "before<script>alert(1)",
+			want: "before",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.src))
+		})
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://example.com":      true,
+		"mailto:qa@example.com":    true,
+		"./images/step1.png":       true,
+		"#setup":                   true,
+		"/docs/a:b":                true,
+		"javascript:alert(1)":      false,
+		" JavaScript:alert(1)":     false,
+		"java\tscript:alert(1)":    false,
+		"jav&#x61;script:alert(1)": false,
+		"data:text/html,hi":        false,
+	} {
+		assert.Equal(t, want, SafeURL(url), url)
+	}
+}
@@ -0,0 +1,94 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnv wires a processor to an in-memory asset store, local blob storage
+// and a fake transcoder.
+type testEnv struct {
+	processor  *Processor
+	transcoder *fakeTranscoder
+	assetStore testrun.AssetStore
+	storage    storage.BlobStorage
+}
+
+// setupTestEnv creates the stores and the processor under test, transcoding
+// to formats.
+func setupTestEnv(t *testing.T, formats ...Format) *testEnv {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &testrun.TestRunAsset{})
+
+	log := logger.NewTestLogger()
+	blobStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	assetStore := testrun.NewMySQLAssetStore(db, log)
+	transcoder := &fakeTranscoder{}
+
+	return &testEnv{
+		processor:  NewProcessor(assetStore, blobStorage, transcoder, Config{Formats: formats, Timeout: time.Minute, QueueSize: 10}, log),
+		transcoder: transcoder,
+		assetStore: assetStore,
+		storage:    blobStorage,
+	}
+}
+
+// createVideo uploads a video and records it as a pending asset.
+func (e *testEnv) createVideo(t *testing.T, fileName string) *testrun.TestRunAsset {
+	t.Helper()
+	ctx := context.Background()
+
+	runID := uuid.New()
+	asset := &testrun.TestRunAsset{
+		TestRunID:        runID,
+		AssetType:        testrun.AssetTypeVideo,
+		AssetPath:        "test-runs/" + runID.String() + "/video/" + fileName,
+		FileName:         fileName,
+		FileSize:         5,
+		MimeType:         "video/quicktime",
+		ProcessingStatus: testrun.ProcessingStatusPending,
+		UploadedAt:       time.Now(),
+	}
+	require.NoError(t, e.storage.Upload(ctx, asset.AssetPath, strings.NewReader("video")))
+	require.NoError(t, e.assetStore.Create(ctx, asset))
+	return asset
+}
+
+// fakeTranscoder writes a description of each derived artifact instead of
+// running ffmpeg. Formats in fail are reported as failed transcodes.
+type fakeTranscoder struct {
+	fail map[Format]bool
+}
+
+func (f *fakeTranscoder) Thumbnail(ctx context.Context, input, output string) error {
+	return derive(input, output, "thumbnail")
+}
+
+func (f *fakeTranscoder) Transcode(ctx context.Context, input, output string, format Format) error {
+	if f.fail[format] {
+		return errors.New("encoder not found")
+	}
+	return derive(input, output, string(format))
+}
+
+func derive(input, output, kind string) error {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, append([]byte(kind+":"), bytes.TrimSpace(data)...), 0o644)
+}
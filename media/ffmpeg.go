@@ -0,0 +1,101 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// DefaultThumbnailWidth is the width in pixels of generated thumbnails.
+const DefaultThumbnailWidth = 320
+
+// Transcoder derives artifacts from video files on local disk.
+type Transcoder interface {
+	// Thumbnail writes a JPEG still of the video at input to output.
+	Thumbnail(ctx context.Context, input, output string) error
+
+	// Transcode writes the video at input to output in the given format.
+	Transcode(ctx context.Context, input, output string, format Format) error
+}
+
+// FFmpeg is a Transcoder that runs the ffmpeg binary.
+type FFmpeg struct {
+	path           string
+	thumbnailWidth int
+}
+
+// NewFFmpeg creates a transcoder that runs the ffmpeg binary at path and
+// scales thumbnails to thumbnailWidth pixels wide.
+func NewFFmpeg(path string, thumbnailWidth int) *FFmpeg {
+	if thumbnailWidth <= 0 {
+		thumbnailWidth = DefaultThumbnailWidth
+	}
+	return &FFmpeg{
+		path:           path,
+		thumbnailWidth: thumbnailWidth,
+	}
+}
+
+// Thumbnail writes a representative frame of the video as a JPEG.
+func (f *FFmpeg) Thumbnail(ctx context.Context, input, output string) error {
+	return f.run(ctx, thumbnailArgs(input, output, f.thumbnailWidth))
+}
+
+// Transcode re-encodes the video in the given format.
+func (f *FFmpeg) Transcode(ctx context.Context, input, output string, format Format) error {
+	args, err := transcodeArgs(input, output, format)
+	if err != nil {
+		return err
+	}
+	return f.run(ctx, args)
+}
+
+func (f *FFmpeg) run(ctx context.Context, args []string) error {
+	cmd := exec.CommandContext(ctx, f.path, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		stderrStr := stderr.String()
+		if len(stderrStr) > 500 {
+			stderrStr = stderrStr[len(stderrStr)-500:]
+		}
+		return fmt.Errorf("ffmpeg failed: %v; stderr: %s", err, stderrStr)
+	}
+	return nil
+}
+
+// thumbnailArgs picks a representative frame among the first frames rather
+// than seeking to a fixed time, which fails on very short recordings.
+func thumbnailArgs(input, output string, width int) []string {
+	return []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-i", input,
+		"-vf", "thumbnail,scale=" + strconv.Itoa(width) + ":-2",
+		"-frames:v", "1",
+		output,
+	}
+}
+
+func transcodeArgs(input, output string, format Format) ([]string, error) {
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", input}
+	switch format {
+	case FormatMP4:
+		args = append(args,
+			"-c:v", "libx264", "-preset", "veryfast", "-crf", "28", "-pix_fmt", "yuv420p",
+			"-c:a", "aac", "-movflags", "+faststart",
+		)
+	case FormatWebM:
+		args = append(args,
+			"-c:v", "libvpx-vp9", "-crf", "35", "-b:v", "0",
+			"-c:a", "libopus",
+		)
+	default:
+		return nil, fmt.Errorf("unsupported transcode format %q", format)
+	}
+	// Odd dimensions are rejected by the yuv420p encoders.
+	args = append(args, "-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2", output)
+	return args, nil
+}
@@ -0,0 +1,42 @@
+// Package media generates thumbnails and web-friendly transcodes of video
+// assets uploaded to test runs.
+package media
+
+import (
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Format is a web-friendly video format assets can be transcoded to.
+type Format string
+
+const (
+	FormatMP4  Format = "mp4"
+	FormatWebM Format = "webm"
+)
+
+// ParseFormat parses a transcode format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatMP4, FormatWebM:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported transcode format %q", s)
+	}
+}
+
+// MimeType returns the MIME type of videos in the format.
+func (f Format) MimeType() string {
+	return "video/" + string(f)
+}
+
+// Variant returns the asset variant of transcodes in the format.
+func (f Format) Variant() testrun.AssetVariant {
+	switch f {
+	case FormatWebM:
+		return testrun.AssetVariantWebM
+	default:
+		return testrun.AssetVariantMP4
+	}
+}
@@ -0,0 +1,68 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{"mp4", "mp4", FormatMP4, false},
+		{"webm", "webm", FormatWebM, false},
+		{"unsupported", "avi", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormat_Variant(t *testing.T) {
+	assert.Equal(t, testrun.AssetVariantMP4, FormatMP4.Variant())
+	assert.Equal(t, testrun.AssetVariantWebM, FormatWebM.Variant())
+	assert.Equal(t, "video/webm", FormatWebM.MimeType())
+}
+
+func TestTranscodeArgs(t *testing.T) {
+	t.Run("mp4 is playable while downloading", func(t *testing.T) {
+		args, err := transcodeArgs("in.mov", "out.mp4", FormatMP4)
+		require.NoError(t, err)
+		assert.Contains(t, args, "libx264")
+		assert.Contains(t, args, "+faststart")
+		assert.Equal(t, "out.mp4", args[len(args)-1])
+	})
+
+	t.Run("webm uses vp9", func(t *testing.T) {
+		args, err := transcodeArgs("in.mov", "out.webm", FormatWebM)
+		require.NoError(t, err)
+		assert.Contains(t, args, "libvpx-vp9")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := transcodeArgs("in.mov", "out.avi", Format("avi"))
+		assert.Error(t, err)
+	})
+}
+
+func TestThumbnailArgs(t *testing.T) {
+	args := thumbnailArgs("in.mov", "thumb.jpg", 320)
+	assert.Contains(t, args, "thumbnail,scale=320:-2")
+	assert.Equal(t, "thumb.jpg", args[len(args)-1])
+}
@@ -0,0 +1,295 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Config holds settings for processing video assets.
+type Config struct {
+	// Formats are the formats videos are transcoded to. Only a thumbnail
+	// is generated if empty.
+	Formats []Format
+	// Timeout bounds the processing of a single asset.
+	Timeout   time.Duration
+	QueueSize int
+}
+
+// Processor generates the derived artifacts of uploaded video assets in the
+// background. Artifacts are stored next to the original blob and recorded as
+// assets of the same test run whose SourceAssetID is the original. A nil
+// Processor processes nothing.
+type Processor struct {
+	assetStore testrun.AssetStore
+	storage    storage.BlobStorage
+	transcoder Transcoder
+	config     Config
+	queue      chan uuid.UUID
+	ctx        context.Context
+	cancel     context.CancelFunc
+	mu         sync.RWMutex
+	stopped    bool
+	wg         sync.WaitGroup
+	logger     logger.Logger
+}
+
+// NewProcessor creates a processor that queues up to cfg.QueueSize assets.
+func NewProcessor(assetStore testrun.AssetStore, blobStorage storage.BlobStorage, transcoder Transcoder, cfg Config, log logger.Logger) *Processor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Processor{
+		assetStore: assetStore,
+		storage:    blobStorage,
+		transcoder: transcoder,
+		config:     cfg,
+		queue:      make(chan uuid.UUID, cfg.QueueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		logger:     log,
+	}
+}
+
+// Start spawns workers that process queued assets.
+func (p *Processor) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for {
+				select {
+				case <-p.ctx.Done():
+					return
+				case id, ok := <-p.queue:
+					if !ok {
+						return
+					}
+					p.Process(p.ctx, id)
+				}
+			}
+		}()
+	}
+}
+
+// Stop stops accepting assets, interrupts the assets being processed and
+// waits for the workers to exit. Interrupted and queued assets are left for
+// Resume to pick up on the next start.
+func (p *Processor) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	close(p.queue)
+	p.mu.Unlock()
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Enqueue queues an asset for processing. If the queue is full the asset is
+// left pending and picked up by Resume on the next start.
+func (p *Processor) Enqueue(ctx context.Context, assetID uuid.UUID) {
+	if p == nil {
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		return
+	}
+
+	select {
+	case p.queue <- assetID:
+	default:
+		p.logger.Warn(ctx, "media processing queue full, leaving asset pending", map[string]interface{}{
+			"asset_id": assetID.String(),
+		})
+	}
+}
+
+// Resume queues assets left pending or interrupted mid-processing by a
+// previous run of the server.
+func (p *Processor) Resume(ctx context.Context) error {
+	assets, err := p.assetStore.ListByProcessingStatus(ctx, testrun.ProcessingStatusPending, testrun.ProcessingStatusProcessing)
+	if err != nil {
+		return err
+	}
+	for _, a := range assets {
+		p.Enqueue(ctx, a.ID)
+	}
+	return nil
+}
+
+// Process generates the derived artifacts of a pending video asset and
+// records the outcome in its processing status. Assets that are not pending
+// are skipped.
+func (p *Processor) Process(ctx context.Context, assetID uuid.UUID) {
+	asset, err := p.assetStore.GetByID(ctx, assetID)
+	if err != nil {
+		p.logger.Error(ctx, "failed to get asset for media processing", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": assetID.String(),
+		})
+		return
+	}
+	if asset.ProcessingStatus != testrun.ProcessingStatusPending && asset.ProcessingStatus != testrun.ProcessingStatusProcessing {
+		return
+	}
+
+	if err := p.assetStore.UpdateProcessingStatus(ctx, asset.ID, testrun.ProcessingStatusProcessing, ""); err != nil {
+		return
+	}
+
+	status, processingError := testrun.ProcessingStatusCompleted, ""
+	if err := p.process(ctx, asset); err != nil {
+		if ctx.Err() == context.Canceled {
+			return
+		}
+		p.logger.Error(ctx, "failed to process video asset", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": asset.ID.String(),
+		})
+		status, processingError = testrun.ProcessingStatusFailed, err.Error()
+	}
+
+	if err := p.assetStore.UpdateProcessingStatus(ctx, asset.ID, status, processingError); err != nil {
+		return
+	}
+	p.logger.Info(ctx, "video asset processed", map[string]interface{}{
+		"asset_id": asset.ID.String(),
+		"status":   status,
+	})
+}
+
+func (p *Processor) process(ctx context.Context, asset *testrun.TestRunAsset) error {
+	if p.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+
+	// Artifacts of an interrupted attempt are replaced.
+	if err := p.deleteDerived(ctx, asset.ID); err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "media-*")
+	if err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "source"+filepath.Ext(asset.FileName))
+	if err := p.download(ctx, asset.AssetPath, input); err != nil {
+		return err
+	}
+
+	stem := strings.TrimSuffix(asset.FileName, filepath.Ext(asset.FileName))
+
+	thumbnail := filepath.Join(dir, "thumbnail.jpg")
+	if err := p.transcoder.Thumbnail(ctx, input, thumbnail); err != nil {
+		return fmt.Errorf("failed to generate thumbnail: %w", err)
+	}
+	if err := p.store(ctx, asset, thumbnail, &testrun.TestRunAsset{
+		AssetType: testrun.AssetTypeImage,
+		AssetPath: asset.AssetPath + ".thumbnail.jpg",
+		FileName:  stem + "_thumbnail.jpg",
+		MimeType:  "image/jpeg",
+		Variant:   testrun.AssetVariantThumbnail,
+	}); err != nil {
+		return err
+	}
+
+	for _, format := range p.config.Formats {
+		output := filepath.Join(dir, "transcode."+string(format))
+		if err := p.transcoder.Transcode(ctx, input, output, format); err != nil {
+			return fmt.Errorf("failed to transcode to %s: %w", format, err)
+		}
+		if err := p.store(ctx, asset, output, &testrun.TestRunAsset{
+			AssetType: testrun.AssetTypeVideo,
+			AssetPath: asset.AssetPath + ".web." + string(format),
+			FileName:  stem + "_web." + string(format),
+			MimeType:  format.MimeType(),
+			Variant:   format.Variant(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// download copies the blob at path to the local file dst.
+func (p *Processor) download(ctx context.Context, path, dst string) error {
+	reader, err := p.storage.Download(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+	defer reader.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create local copy of video: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, reader); err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+	return f.Close()
+}
+
+// store uploads the local file src as the derived asset of source.
+func (p *Processor) store(ctx context.Context, source *testrun.TestRunAsset, src string, derived *testrun.TestRunAsset) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", derived.Variant, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", derived.Variant, err)
+	}
+
+	if err := p.storage.Upload(ctx, derived.AssetPath, f); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", derived.Variant, err)
+	}
+
+	derived.TestRunID = source.TestRunID
+	derived.FileSize = info.Size()
+	derived.SourceAssetID = &source.ID
+	derived.UploadedAt = time.Now()
+	if err := p.assetStore.Create(ctx, derived); err != nil {
+		p.storage.Delete(ctx, derived.AssetPath)
+		return fmt.Errorf("failed to record %s: %w", derived.Variant, err)
+	}
+	return nil
+}
+
+// deleteDerived removes the artifacts derived from an asset.
+func (p *Processor) deleteDerived(ctx context.Context, sourceID uuid.UUID) error {
+	derived, err := p.assetStore.ListDerived(ctx, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to list derived assets: %w", err)
+	}
+	for _, d := range derived {
+		if err := p.assetStore.Delete(ctx, d.ID); err != nil {
+			return fmt.Errorf("failed to delete derived asset: %w", err)
+		}
+		if err := p.storage.Delete(ctx, d.AssetPath); err != nil {
+			p.logger.Warn(ctx, "failed to delete derived asset from storage", map[string]interface{}{
+				"error": err.Error(),
+				"path":  d.AssetPath,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package media
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_Process(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("generates thumbnail and transcodes", func(t *testing.T) {
+		env := setupTestEnv(t, FormatMP4, FormatWebM)
+		video := env.createVideo(t, "checkout.mov")
+
+		env.processor.Process(ctx, video.ID)
+
+		processed, err := env.assetStore.GetByID(ctx, video.ID)
+		require.NoError(t, err)
+		assert.Equal(t, testrun.ProcessingStatusCompleted, processed.ProcessingStatus)
+		assert.Empty(t, processed.ProcessingError)
+
+		derived, err := env.assetStore.ListDerived(ctx, video.ID)
+		require.NoError(t, err)
+		require.Len(t, derived, 3)
+
+		byVariant := map[testrun.AssetVariant]*testrun.TestRunAsset{}
+		for _, d := range derived {
+			byVariant[d.Variant] = d
+			assert.Equal(t, video.TestRunID, d.TestRunID)
+		}
+
+		thumbnail := byVariant[testrun.AssetVariantThumbnail]
+		require.NotNil(t, thumbnail)
+		assert.Equal(t, testrun.AssetTypeImage, thumbnail.AssetType)
+		assert.Equal(t, "checkout_thumbnail.jpg", thumbnail.FileName)
+		assert.Equal(t, video.AssetPath+".thumbnail.jpg", thumbnail.AssetPath)
+		assert.Equal(t, "image/jpeg", thumbnail.MimeType)
+		assert.Equal(t, "thumbnail:video", readBlob(t, env, thumbnail.AssetPath))
+		assert.Equal(t, int64(len("thumbnail:video")), thumbnail.FileSize)
+
+		webm := byVariant[testrun.AssetVariantWebM]
+		require.NotNil(t, webm)
+		assert.Equal(t, testrun.AssetTypeVideo, webm.AssetType)
+		assert.Equal(t, "checkout_web.webm", webm.FileName)
+		assert.Equal(t, "video/webm", webm.MimeType)
+		assert.Equal(t, "webm:video", readBlob(t, env, webm.AssetPath))
+
+		require.NotNil(t, byVariant[testrun.AssetVariantMP4])
+	})
+
+	t.Run("only thumbnail without formats", func(t *testing.T) {
+		env := setupTestEnv(t)
+		video := env.createVideo(t, "login.webm")
+
+		env.processor.Process(ctx, video.ID)
+
+		derived, err := env.assetStore.ListDerived(ctx, video.ID)
+		require.NoError(t, err)
+		require.Len(t, derived, 1)
+		assert.Equal(t, testrun.AssetVariantThumbnail, derived[0].Variant)
+	})
+
+	t.Run("failed transcode fails processing", func(t *testing.T) {
+		env := setupTestEnv(t, FormatWebM)
+		env.transcoder.fail = map[Format]bool{FormatWebM: true}
+		video := env.createVideo(t, "checkout.mov")
+
+		env.processor.Process(ctx, video.ID)
+
+		processed, err := env.assetStore.GetByID(ctx, video.ID)
+		require.NoError(t, err)
+		assert.Equal(t, testrun.ProcessingStatusFailed, processed.ProcessingStatus)
+		assert.Contains(t, processed.ProcessingError, "encoder not found")
+	})
+
+	t.Run("reprocessing replaces derived assets", func(t *testing.T) {
+		env := setupTestEnv(t)
+		video := env.createVideo(t, "checkout.mov")
+
+		env.processor.Process(ctx, video.ID)
+		require.NoError(t, env.assetStore.UpdateProcessingStatus(ctx, video.ID, testrun.ProcessingStatusProcessing, ""))
+		env.processor.Process(ctx, video.ID)
+
+		derived, err := env.assetStore.ListDerived(ctx, video.ID)
+		require.NoError(t, err)
+		assert.Len(t, derived, 1)
+	})
+
+	t.Run("skips assets that are not pending", func(t *testing.T) {
+		env := setupTestEnv(t)
+		video := env.createVideo(t, "checkout.mov")
+		require.NoError(t, env.assetStore.UpdateProcessingStatus(ctx, video.ID, testrun.ProcessingStatusCompleted, ""))
+
+		env.processor.Process(ctx, video.ID)
+
+		derived, err := env.assetStore.ListDerived(ctx, video.ID)
+		require.NoError(t, err)
+		assert.Empty(t, derived)
+	})
+}
+
+func TestProcessor_Resume(t *testing.T) {
+	ctx := context.Background()
+	env := setupTestEnv(t)
+	pending := env.createVideo(t, "pending.mov")
+	done := env.createVideo(t, "done.mov")
+	require.NoError(t, env.assetStore.UpdateProcessingStatus(ctx, done.ID, testrun.ProcessingStatusCompleted, ""))
+
+	require.NoError(t, env.processor.Resume(ctx))
+
+	require.Len(t, env.processor.queue, 1)
+	assert.Equal(t, pending.ID, <-env.processor.queue)
+}
+
+func TestProcessor_Enqueue(t *testing.T) {
+	t.Run("nil processor is a no-op", func(t *testing.T) {
+		var p *Processor
+		p.Enqueue(context.Background(), uuid.New())
+	})
+
+	t.Run("dropped after stop", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.processor.Start(1)
+		env.processor.Stop()
+		env.processor.Enqueue(context.Background(), uuid.New())
+	})
+}
+
+// readBlob returns the contents of the blob at path.
+func readBlob(t *testing.T, env *testEnv, path string) string {
+	t.Helper()
+	reader, err := env.storage.Download(context.Background(), path)
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,92 @@
+package metering
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidMetric is returned when an event has an unknown metric.
+	ErrInvalidMetric = errors.New("metric must be one of: script_generations, agent_job_minutes, storage_bytes")
+
+	// ErrInvalidAccountID is returned when account_id is not set.
+	ErrInvalidAccountID = errors.New("account_id is required")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidMonth is returned when a report month is not in YYYY-MM form.
+	ErrInvalidMonth = errors.New("month must be in YYYY-MM format")
+)
+
+// Metric identifies a billable action.
+type Metric string
+
+const (
+	// MetricScriptGenerations counts completed automation script generations.
+	MetricScriptGenerations Metric = "script_generations"
+
+	// MetricAgentJobMinutes accumulates the wall-clock minutes agent jobs ran for.
+	MetricAgentJobMinutes Metric = "agent_job_minutes"
+
+	// MetricStorageBytes records changes in stored bytes. Uploads are positive
+	// and deletions negative, so the running sum is the current footprint.
+	MetricStorageBytes Metric = "storage_bytes"
+)
+
+// IsValid checks if the metric is valid.
+func (m Metric) IsValid() bool {
+	switch m {
+	case MetricScriptGenerations, MetricAgentJobMinutes, MetricStorageBytes:
+		return true
+	default:
+		return false
+	}
+}
+
+// Event is a single metered action. Events are billed to the account that
+// owns the project the action happened in, which is the tenancy boundary of
+// the application; the actor is the user who performed the action.
+type Event struct {
+	ID         uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	AccountID  uuid.UUID `json:"account_id" gorm:"type:char(36);not null;index:idx_usage_events_account_time"`
+	ProjectID  uuid.UUID `json:"project_id" gorm:"type:char(36);not null"`
+	ActorID    uuid.UUID `json:"actor_id" gorm:"type:char(36);not null"`
+	Metric     Metric    `json:"metric" gorm:"type:varchar(50);not null"`
+	Quantity   float64   `json:"quantity" gorm:"not null"`
+	OccurredAt time.Time `json:"occurred_at" gorm:"not null;index:idx_usage_events_account_time"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Event.
+func (Event) TableName() string {
+	return "usage_events"
+}
+
+// BeforeCreate hook to generate UUID and default the occurrence time.
+func (e *Event) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// Validate checks if the event has valid required fields.
+func (e *Event) Validate() error {
+	if e.AccountID == uuid.Nil {
+		return ErrInvalidAccountID
+	}
+	if e.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if !e.Metric.IsValid() {
+		return ErrInvalidMetric
+	}
+	return nil
+}
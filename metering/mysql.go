@@ -0,0 +1,95 @@
+package metering
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed usage event store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Record stores a usage event in the database.
+func (s *MySQLStore) Record(ctx context.Context, event *Event) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(event).Error; err != nil {
+		s.logger.Error(ctx, "failed to record usage event", map[string]interface{}{
+			"error":      err.Error(),
+			"account_id": event.AccountID.String(),
+			"metric":     event.Metric,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// SumByProject totals the quantity of a metric per project for an account.
+func (s *MySQLStore) SumByProject(ctx context.Context, accountID uuid.UUID, metric Metric, from, to time.Time) (map[uuid.UUID]float64, error) {
+	var rows []struct {
+		ProjectID uuid.UUID
+		Total     float64
+	}
+
+	query := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
+		Model(&Event{}).
+		Select("project_id, SUM(quantity) AS total").
+		Where("account_id = ? AND metric = ? AND occurred_at < ?", accountID, metric, to)
+	if !from.IsZero() {
+		query = query.Where("occurred_at >= ?", from)
+	}
+
+	if err := query.Group("project_id").Scan(&rows).Error; err != nil {
+		s.logger.Error(ctx, "failed to sum usage events", map[string]interface{}{
+			"error":      err.Error(),
+			"account_id": accountID.String(),
+			"metric":     metric,
+		})
+		return nil, err
+	}
+
+	totals := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		totals[row.ProjectID] = row.Total
+	}
+	return totals, nil
+}
+
+// CountActiveUsers counts the distinct actors for an account in a period.
+func (s *MySQLStore) CountActiveUsers(ctx context.Context, accountID uuid.UUID, from, to time.Time) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
+		Model(&Event{}).
+		Where("account_id = ? AND occurred_at >= ? AND occurred_at < ?", accountID, from, to).
+		Distinct("actor_id").
+		Count(&count).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to count active users", map[string]interface{}{
+			"error":      err.Error(),
+			"account_id": accountID.String(),
+		})
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,89 @@
+package metering
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Record(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("defaults id and occurrence time", func(t *testing.T) {
+		event := &Event{AccountID: uuid.New(), ProjectID: uuid.New(), ActorID: uuid.New(), Metric: MetricScriptGenerations, Quantity: 1}
+		require.NoError(t, store.Record(ctx, event))
+		assert.NotEqual(t, uuid.Nil, event.ID)
+		assert.False(t, event.OccurredAt.IsZero())
+	})
+
+	t.Run("invalid metric returns error", func(t *testing.T) {
+		event := &Event{AccountID: uuid.New(), ProjectID: uuid.New(), Metric: "api_calls", Quantity: 1}
+		assert.ErrorIs(t, store.Record(ctx, event), ErrInvalidMetric)
+	})
+
+	t.Run("missing account returns error", func(t *testing.T) {
+		event := &Event{ProjectID: uuid.New(), Metric: MetricStorageBytes, Quantity: 1}
+		assert.ErrorIs(t, store.Record(ctx, event), ErrInvalidAccountID)
+	})
+}
+
+func TestMySQLStore_SumByProject(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	account := uuid.New()
+	projectA, projectB := uuid.New(), uuid.New()
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	november := october.AddDate(0, 1, 0)
+
+	record := func(accountID, projectID uuid.UUID, metric Metric, quantity float64, at time.Time) {
+		require.NoError(t, store.Record(ctx, &Event{
+			AccountID: accountID, ProjectID: projectID, ActorID: accountID,
+			Metric: metric, Quantity: quantity, OccurredAt: at,
+		}))
+	}
+	record(account, projectA, MetricScriptGenerations, 1, october.Add(time.Hour))
+	record(account, projectA, MetricScriptGenerations, 1, october.Add(48*time.Hour))
+	record(account, projectB, MetricScriptGenerations, 1, october.Add(72*time.Hour))
+	record(account, projectA, MetricScriptGenerations, 1, october.Add(-time.Hour))
+	record(account, projectA, MetricScriptGenerations, 1, november)
+	record(uuid.New(), projectA, MetricScriptGenerations, 1, october.Add(time.Hour))
+	record(account, projectA, MetricAgentJobMinutes, 12.5, october.Add(time.Hour))
+
+	totals, err := store.SumByProject(ctx, account, MetricScriptGenerations, october, november)
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]float64{projectA: 2, projectB: 1}, totals)
+
+	totals, err = store.SumByProject(ctx, account, MetricScriptGenerations, time.Time{}, november)
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), totals[projectA])
+}
+
+func TestMySQLStore_CountActiveUsers(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	account := uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, actor := range []uuid.UUID{alice, alice, bob} {
+		require.NoError(t, store.Record(ctx, &Event{
+			AccountID: account, ProjectID: uuid.New(), ActorID: actor,
+			Metric: MetricScriptGenerations, Quantity: 1, OccurredAt: october.Add(time.Hour),
+		}))
+	}
+	require.NoError(t, store.Record(ctx, &Event{
+		AccountID: account, ProjectID: uuid.New(), ActorID: uuid.New(),
+		Metric: MetricScriptGenerations, Quantity: 1, OccurredAt: october.AddDate(0, -1, 0),
+	}))
+
+	count, err := store.CountActiveUsers(ctx, account, october, october.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,86 @@
+package metering
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+)
+
+// Recorder records billable actions against the account owning the project
+// they happened in. Metering is best-effort: failures are logged and never
+// surface to the caller, so a metering outage cannot block the action itself.
+// A nil Recorder records nothing.
+type Recorder struct {
+	store        Store
+	projectStore project.Store
+	logger       logger.Logger
+}
+
+// NewRecorder creates a new usage recorder.
+func NewRecorder(store Store, projectStore project.Store, log logger.Logger) *Recorder {
+	return &Recorder{
+		store:        store,
+		projectStore: projectStore,
+		logger:       log,
+	}
+}
+
+// RecordScriptGeneration records one completed script generation.
+func (r *Recorder) RecordScriptGeneration(ctx context.Context, projectID, actorID uuid.UUID) {
+	r.record(ctx, projectID, actorID, MetricScriptGenerations, 1)
+}
+
+// RecordJobDuration records the time an agent job ran for, in minutes.
+func (r *Recorder) RecordJobDuration(ctx context.Context, projectID, actorID uuid.UUID, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	r.record(ctx, projectID, actorID, MetricAgentJobMinutes, d.Minutes())
+}
+
+// RecordStorage records a change in stored bytes. Use a negative delta when
+// an object is deleted.
+func (r *Recorder) RecordStorage(ctx context.Context, projectID, actorID uuid.UUID, deltaBytes int64) {
+	if deltaBytes == 0 {
+		return
+	}
+	r.record(ctx, projectID, actorID, MetricStorageBytes, float64(deltaBytes))
+}
+
+func (r *Recorder) record(ctx context.Context, projectID, actorID uuid.UUID, metric Metric, quantity float64) {
+	if r == nil {
+		return
+	}
+
+	// Metering runs after the action has happened; it must still be recorded
+	// when the request that triggered it has already been cancelled.
+	ctx = context.WithoutCancel(ctx)
+
+	proj, err := r.projectStore.GetByID(ctx, projectID)
+	if err != nil {
+		r.logger.Error(ctx, "failed to resolve account for usage event", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+			"metric":     metric,
+		})
+		return
+	}
+
+	event := &Event{
+		AccountID: proj.OwnerID,
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Metric:    metric,
+		Quantity:  quantity,
+	}
+	if err := r.store.Record(ctx, event); err != nil {
+		r.logger.Error(ctx, "failed to record usage event", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+			"metric":     metric,
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package metering
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	monthLayout = "2006-01"
+
+	// bytesPerGB converts stored bytes into the GB unit used for billing.
+	bytesPerGB = 1 << 30
+)
+
+// ParseMonth parses a YYYY-MM string into the first instant of that month in UTC.
+func ParseMonth(s string) (time.Time, error) {
+	month, err := time.Parse(monthLayout, s)
+	if err != nil {
+		return time.Time{}, ErrInvalidMonth
+	}
+	return month, nil
+}
+
+// ProjectUsage is the usage attributed to a single project in a report.
+type ProjectUsage struct {
+	ProjectID         uuid.UUID `json:"project_id"`
+	ScriptGenerations int64     `json:"script_generations"`
+	AgentJobMinutes   float64   `json:"agent_job_minutes"`
+	StorageGB         float64   `json:"storage_gb"`
+}
+
+// Report is the monthly usage of an account. Storage is the footprint at the
+// end of the month rather than the amount uploaded during it.
+type Report struct {
+	AccountID         uuid.UUID      `json:"account_id"`
+	Month             string         `json:"month"`
+	PeriodStart       time.Time      `json:"period_start"`
+	PeriodEnd         time.Time      `json:"period_end"`
+	ScriptGenerations int64          `json:"script_generations"`
+	AgentJobMinutes   float64        `json:"agent_job_minutes"`
+	StorageGB         float64        `json:"storage_gb"`
+	ActiveUsers       int            `json:"active_users"`
+	Projects          []ProjectUsage `json:"projects"`
+}
+
+// BuildReport aggregates the usage of an account for the month starting at month.
+func BuildReport(ctx context.Context, store Store, accountID uuid.UUID, month time.Time) (*Report, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	generations, err := store.SumByProject(ctx, accountID, MetricScriptGenerations, start, end)
+	if err != nil {
+		return nil, err
+	}
+	minutes, err := store.SumByProject(ctx, accountID, MetricAgentJobMinutes, start, end)
+	if err != nil {
+		return nil, err
+	}
+	storedBytes, err := store.SumByProject(ctx, accountID, MetricStorageBytes, time.Time{}, end)
+	if err != nil {
+		return nil, err
+	}
+	activeUsers, err := store.CountActiveUsers(ctx, accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	byProject := make(map[uuid.UUID]*ProjectUsage)
+	usageFor := func(projectID uuid.UUID) *ProjectUsage {
+		usage, ok := byProject[projectID]
+		if !ok {
+			usage = &ProjectUsage{ProjectID: projectID}
+			byProject[projectID] = usage
+		}
+		return usage
+	}
+	for projectID, total := range generations {
+		usageFor(projectID).ScriptGenerations = int64(total)
+	}
+	for projectID, total := range minutes {
+		usageFor(projectID).AgentJobMinutes = roundTo(total, 2)
+	}
+	for projectID, total := range storedBytes {
+		// Deletions of objects uploaded before metering existed can push
+		// the running sum below zero.
+		if total <= 0 {
+			continue
+		}
+		usageFor(projectID).StorageGB = roundTo(total/bytesPerGB, 4)
+	}
+
+	report := &Report{
+		AccountID:   accountID,
+		Month:       start.Format(monthLayout),
+		PeriodStart: start,
+		PeriodEnd:   end,
+		ActiveUsers: activeUsers,
+		Projects:    make([]ProjectUsage, 0, len(byProject)),
+	}
+	for _, usage := range byProject {
+		report.ScriptGenerations += usage.ScriptGenerations
+		report.AgentJobMinutes += usage.AgentJobMinutes
+		report.StorageGB += usage.StorageGB
+		report.Projects = append(report.Projects, *usage)
+	}
+	report.AgentJobMinutes = roundTo(report.AgentJobMinutes, 2)
+	report.StorageGB = roundTo(report.StorageGB, 4)
+	sort.Slice(report.Projects, func(i, j int) bool {
+		return report.Projects[i].ProjectID.String() < report.Projects[j].ProjectID.String()
+	})
+
+	return report, nil
+}
+
+// WriteCSV writes the report as CSV with one row per project followed by a
+// total row for the account.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"month", "account_id", "project_id", "script_generations", "agent_job_minutes", "storage_gb", "active_users"}}
+	for _, p := range r.Projects {
+		rows = append(rows, []string{
+			r.Month,
+			r.AccountID.String(),
+			p.ProjectID.String(),
+			strconv.FormatInt(p.ScriptGenerations, 10),
+			formatFloat(p.AgentJobMinutes),
+			formatFloat(p.StorageGB),
+			"",
+		})
+	}
+	rows = append(rows, []string{
+		r.Month,
+		r.AccountID.String(),
+		"total",
+		strconv.FormatInt(r.ScriptGenerations, 10),
+		formatFloat(r.AgentJobMinutes),
+		formatFloat(r.StorageGB),
+		strconv.Itoa(r.ActiveUsers),
+	})
+
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write usage csv: %w", err)
+	}
+	return nil
+}
+
+// WriteOpenMetrics writes the report in the OpenMetrics text exposition format
+// so it can be ingested by metrics-based billing pipelines.
+func (r *Report) WriteOpenMetrics(w io.Writer) error {
+	families := []struct {
+		name  string
+		help  string
+		value func(ProjectUsage) string
+	}{
+		{"ui_automation_usage_script_generations", "Script generations completed in the month.", func(p ProjectUsage) string {
+			return strconv.FormatInt(p.ScriptGenerations, 10)
+		}},
+		{"ui_automation_usage_agent_job_minutes", "Minutes agent jobs ran for in the month.", func(p ProjectUsage) string {
+			return formatFloat(p.AgentJobMinutes)
+		}},
+		{"ui_automation_usage_storage_gb", "Stored data in GB at the end of the month.", func(p ProjectUsage) string {
+			return formatFloat(p.StorageGB)
+		}},
+	}
+
+	for _, f := range families {
+		if _, err := fmt.Fprintf(w, "# TYPE %s gauge\n# HELP %s %s\n", f.name, f.name, f.help); err != nil {
+			return err
+		}
+		for _, p := range r.Projects {
+			if _, err := fmt.Fprintf(w, "%s{account_id=%q,project_id=%q,month=%q} %s\n",
+				f.name, r.AccountID.String(), p.ProjectID.String(), r.Month, f.value(p)); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "# TYPE ui_automation_usage_active_users gauge\n"+
+		"# HELP ui_automation_usage_active_users Distinct users that performed a billable action in the month.\n"+
+		"ui_automation_usage_active_users{account_id=%q,month=%q} %d\n# EOF\n",
+		r.AccountID.String(), r.Month, r.ActiveUsers)
+	return err
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,89 @@
+package metering
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMonth(t *testing.T) {
+	month, err := ParseMonth("2026-10")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), month)
+
+	for _, invalid := range []string{"", "2026-13", "10-2026", "2026-10-01"} {
+		_, err := ParseMonth(invalid)
+		assert.ErrorIs(t, err, ErrInvalidMonth, invalid)
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	owner, collaborator := uuid.New(), uuid.New()
+	proj := &project.Project{Name: "Billing", OwnerID: owner}
+	require.NoError(t, db.Create(proj).Error)
+
+	recorder := NewRecorder(store, project.NewMySQLStore(db, logger.NewTestLogger()), logger.NewTestLogger())
+	recorder.RecordScriptGeneration(ctx, proj.ID, owner)
+	recorder.RecordScriptGeneration(ctx, proj.ID, collaborator)
+	recorder.RecordJobDuration(ctx, proj.ID, owner, 90*time.Second)
+	recorder.RecordStorage(ctx, proj.ID, owner, 3<<30)
+	recorder.RecordStorage(ctx, proj.ID, owner, -(1 << 30))
+	recorder.RecordStorage(ctx, proj.ID, owner, 0)
+
+	// Events for unknown projects cannot be attributed and are dropped.
+	recorder.RecordScriptGeneration(ctx, uuid.New(), owner)
+
+	// A nil recorder is a no-op.
+	var disabled *Recorder
+	disabled.RecordScriptGeneration(ctx, proj.ID, owner)
+
+	report, err := BuildReport(ctx, store, owner, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, owner, report.AccountID)
+	assert.Equal(t, time.Now().UTC().Format("2006-01"), report.Month)
+	assert.Equal(t, int64(2), report.ScriptGenerations)
+	assert.Equal(t, 1.5, report.AgentJobMinutes)
+	assert.Equal(t, float64(2), report.StorageGB)
+	assert.Equal(t, 2, report.ActiveUsers)
+	require.Len(t, report.Projects, 1)
+	assert.Equal(t, proj.ID, report.Projects[0].ProjectID)
+
+	t.Run("storage carries over into later months", func(t *testing.T) {
+		next, err := BuildReport(ctx, store, owner, time.Now().UTC().AddDate(0, 1, 0))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), next.ScriptGenerations)
+		assert.Equal(t, float64(2), next.StorageGB)
+		assert.Equal(t, 0, next.ActiveUsers)
+	})
+
+	t.Run("csv export", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteCSV(&buf))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "month,account_id,project_id,script_generations,agent_job_minutes,storage_gb,active_users", lines[0])
+		assert.Equal(t, strings.Join([]string{report.Month, owner.String(), proj.ID.String(), "2", "1.5", "2", ""}, ","), lines[1])
+		assert.Equal(t, strings.Join([]string{report.Month, owner.String(), "total", "2", "1.5", "2", "2"}, ","), lines[2])
+	})
+
+	t.Run("openmetrics export", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteOpenMetrics(&buf))
+		out := buf.String()
+		assert.Contains(t, out, "# TYPE ui_automation_usage_script_generations gauge\n")
+		assert.Contains(t, out, `ui_automation_usage_script_generations{account_id="`+owner.String()+`",project_id="`+proj.ID.String()+`",month="`+report.Month+`"} 2`)
+		assert.Contains(t, out, `ui_automation_usage_active_users{account_id="`+owner.String()+`",month="`+report.Month+`"} 2`)
+		assert.True(t, strings.HasSuffix(out, "# EOF\n"))
+	})
+}
@@ -0,0 +1,22 @@
+package metering
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for usage event persistence operations.
+type Store interface {
+	// Record stores a usage event.
+	Record(ctx context.Context, event *Event) error
+
+	// SumByProject totals the quantity of a metric per project for an account
+	// over events that occurred in [from, to). A zero from sums from the start.
+	SumByProject(ctx context.Context, accountID uuid.UUID, metric Metric, from, to time.Time) (map[uuid.UUID]float64, error)
+
+	// CountActiveUsers counts the distinct users that performed a metered
+	// action for an account in [from, to).
+	CountActiveUsers(ctx context.Context, accountID uuid.UUID, from, to time.Time) (int, error)
+}
@@ -0,0 +1,86 @@
+package narration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+)
+
+// BedrockNarrator implements Narrator using a model on AWS Bedrock.
+type BedrockNarrator struct {
+	client    *bedrockruntime.Client
+	modelID   string
+	maxTokens int
+}
+
+// NewBedrockNarrator creates a new Bedrock-based guide narrator.
+func NewBedrockNarrator(region, modelID string, maxTokens int) (*BedrockNarrator, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &BedrockNarrator{
+		client:    bedrockruntime.NewFromConfig(cfg),
+		modelID:   modelID,
+		maxTokens: maxTokens,
+	}, nil
+}
+
+// Narrate sends the notes to the model and parses the rewritten ones.
+func (n *BedrockNarrator) Narrate(ctx context.Context, req Request) (*Narration, error) {
+	requestBody := map[string]interface{}{
+		"anthropic_version": "bedrock-2023-05-31",
+		"max_tokens":        n.maxTokens,
+		"messages": []map[string]interface{}{
+			{
+				"role": "user",
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": BuildPrompt(req),
+					},
+				},
+			},
+		},
+	}
+
+	payloadBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	output, err := n.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(n.modelID),
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+		Body:        payloadBytes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke Bedrock model: %w", err)
+	}
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage llmusage.Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(output.Body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	llmusage.Add(ctx, response.Usage)
+	if len(response.Content) == 0 {
+		return nil, ErrNoNarration
+	}
+
+	return ParseNarration(response.Content[0].Text, len(req.Steps))
+}
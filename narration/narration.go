@@ -0,0 +1,201 @@
+// Package narration rewrites the text of the user-facing guide generated
+// from a run: a model turns the terse notes testers leave into polished
+// prose in a chosen tone, translates the guide into another language, or
+// both. It returns one text per note, so the guide keeps its structure and
+// asset references.
+package narration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxLanguageLength is the longest target language name accepted.
+const MaxLanguageLength = 50
+
+var (
+	// ErrInvalidTone is returned for an unknown tone.
+	ErrInvalidTone = errors.New("tone must be one of professional, friendly or concise")
+
+	// ErrInvalidLanguage is returned for a target language that is not a
+	// language name or tag such as "Japanese" or "de-DE".
+	ErrInvalidLanguage = fmt.Errorf("language must be a language name or tag of at most %d letters, spaces and hyphens", MaxLanguageLength)
+
+	// ErrNoNarration is returned when the model's reply does not rewrite
+	// every note.
+	ErrNoNarration = errors.New("the guide could not be narrated")
+)
+
+// Tone is the voice the guide is written in.
+type Tone string
+
+const (
+	ToneProfessional Tone = "professional"
+	ToneFriendly     Tone = "friendly"
+	ToneConcise      Tone = "concise"
+)
+
+// DefaultTone is the tone used when none is given.
+const DefaultTone = ToneProfessional
+
+// IsValid checks if the tone is valid.
+func (t Tone) IsValid() bool {
+	switch t {
+	case ToneProfessional, ToneFriendly, ToneConcise:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseTone parses a tone, returning DefaultTone for an empty string.
+func ParseTone(s string) (Tone, error) {
+	if s == "" {
+		return DefaultTone, nil
+	}
+	t := Tone(strings.ToLower(strings.TrimSpace(s)))
+	if !t.IsValid() {
+		return "", ErrInvalidTone
+	}
+	return t, nil
+}
+
+// ParseLanguage checks a target language, such as "Japanese" or "de-DE".
+// An empty string means the guide is not translated.
+func ParseLanguage(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len([]rune(s)) > MaxLanguageLength {
+		return "", ErrInvalidLanguage
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' {
+			return "", ErrInvalidLanguage
+		}
+	}
+	return s, nil
+}
+
+// Request holds the text of a guide: the procedure's name and description,
+// the overview and the text of each step, any of which may be empty. With
+// Rewrite set, the notes are rewritten in Tone; with Language set, all of
+// the text is translated into it.
+type Request struct {
+	ProcedureName        string
+	ProcedureDescription string
+	Overview             string
+	Steps                []string
+	Rewrite              bool
+	Tone                 Tone
+	Language             string
+}
+
+// Headings are the fixed labels of a guide, translated along with its text.
+type Headings struct {
+	Overview    string `json:"overview"`
+	Step        string `json:"step"`
+	Attachments string `json:"attachments"`
+}
+
+// DefaultHeadings are the labels of an untranslated guide.
+var DefaultHeadings = Headings{
+	Overview:    "Overview",
+	Step:        "Step",
+	Attachments: "Attachments",
+}
+
+// Narration is the rewritten text of a guide, with one text per step of the
+// request.
+type Narration struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Overview    string   `json:"overview"`
+	Steps       []string `json:"steps"`
+	Headings    Headings `json:"headings"`
+}
+
+// Unchanged returns the text of req as it is, for a guide that is neither
+// rewritten nor translated.
+func Unchanged(req Request) Narration {
+	return Narration{
+		Name:        req.ProcedureName,
+		Description: req.ProcedureDescription,
+		Overview:    req.Overview,
+		Steps:       req.Steps,
+		Headings:    DefaultHeadings,
+	}
+}
+
+// Narrator rewrites the text of a guide.
+// Implementations can use different backends (AWS Bedrock, OpenAI, etc.)
+type Narrator interface {
+	// Narrate returns the rewritten text. It returns ErrNoNarration when
+	// the reply does not cover every step.
+	Narrate(ctx context.Context, req Request) (*Narration, error)
+}
+
+// Apply returns the narration for req with text left empty by the model
+// falling back to the original, so nothing is lost. The procedure's name,
+// description and the headings are only taken from the model when the
+// guide is translated.
+func (n Narration) Apply(req Request) Narration {
+	if req.Language == "" {
+		n.Name, n.Description, n.Headings = "", "", Headings{}
+	}
+	out := Narration{
+		Name:        fallback(n.Name, req.ProcedureName),
+		Description: fallback(n.Description, req.ProcedureDescription),
+		Overview:    fallback(n.Overview, req.Overview),
+		Steps:       make([]string, len(req.Steps)),
+		Headings: Headings{
+			Overview:    fallback(n.Headings.Overview, DefaultHeadings.Overview),
+			Step:        fallback(n.Headings.Step, DefaultHeadings.Step),
+			Attachments: fallback(n.Headings.Attachments, DefaultHeadings.Attachments),
+		},
+	}
+	for i, original := range req.Steps {
+		out.Steps[i] = original
+		if i < len(n.Steps) {
+			out.Steps[i] = fallback(n.Steps[i], original)
+		}
+	}
+	return out
+}
+
+// fallback returns s, or original if s is empty.
+func fallback(s, original string) string {
+	if s == "" {
+		return original
+	}
+	return s
+}
+
+// ParseNarration extracts the narration from the model's reply, which
+// should be a JSON object, possibly wrapped in a code fence or prose, with
+// exactly steps step texts.
+func ParseNarration(reply string, steps int) (*Narration, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, ErrNoNarration
+	}
+
+	var n Narration
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &n); err != nil {
+		return nil, fmt.Errorf("failed to parse narration: %w", err)
+	}
+	if len(n.Steps) != steps {
+		return nil, fmt.Errorf("%w: %d step texts for %d steps", ErrNoNarration, len(n.Steps), steps)
+	}
+
+	for _, s := range []*string{&n.Name, &n.Description, &n.Overview, &n.Headings.Overview, &n.Headings.Step, &n.Headings.Attachments} {
+		*s = strings.TrimSpace(*s)
+	}
+	for i := range n.Steps {
+		n.Steps[i] = strings.TrimSpace(n.Steps[i])
+	}
+	return &n, nil
+}
@@ -0,0 +1,43 @@
+package narration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+)
+
+// ResilientNarrator wraps a Narrator so that model calls run under a
+// circuit breaker with a per-call deadline.
+type ResilientNarrator struct {
+	narrator Narrator
+	breaker  *resilience.Breaker
+}
+
+// NewResilientNarrator wraps narrator with the given breaker.
+func NewResilientNarrator(narrator Narrator, breaker *resilience.Breaker) *ResilientNarrator {
+	return &ResilientNarrator{
+		narrator: narrator,
+		breaker:  breaker,
+	}
+}
+
+// Narrate rewrites the notes through the breaker. Replies that do not
+// cover every step do not count against the model.
+func (n *ResilientNarrator) Narrate(ctx context.Context, req Request) (*Narration, error) {
+	var narration *Narration
+	var incomplete error
+	err := n.breaker.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		narration, err = n.narrator.Narrate(ctx, req)
+		if errors.Is(err, ErrNoNarration) {
+			incomplete = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return narration, incomplete
+}
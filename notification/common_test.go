@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testSlackWebhook = "https://hooks.slack.com/services/T000/B000/XXXX"
+
+// setupTestStore creates a test database and preference store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Preferences{}, &user.User{})
+
+	store := NewMySQLStore(db, integration.NewKeyring(integration.DeriveKey("test-encryption-key")), logger.NewTestLogger())
+	return db, store
+}
+
+// createTestUser creates an active user and returns its ID.
+func createTestUser(t *testing.T, db *gorm.DB, email string) uuid.UUID {
+	u := &user.User{Email: email, Username: email, PasswordHash: "hash", IsActive: true}
+	require.NoError(t, db.Create(u).Error)
+	return u.ID
+}
+
+// sentMessage is a message recorded by fakeSender.
+type sentMessage struct {
+	To      string
+	Message Message
+}
+
+// fakeSender records the messages it is asked to send.
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []sentMessage
+	err  error
+}
+
+func (s *fakeSender) Send(ctx context.Context, to string, m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, sentMessage{To: to, Message: m})
+	return s.err
+}
+
+func (s *fakeSender) messages() []sentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sentMessage(nil), s.sent...)
+}
@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// slackWebhookKey is the credentials map key the webhook is encrypted under.
+const slackWebhookKey = "slack_webhook_url"
+
+// MySQLStore implements the Store interface using GORM and MySQL. Slack
+// webhook URLs are encrypted with AES-256-GCM, the same scheme used for
+// integration credentials.
+type MySQLStore struct {
+	db      *gorm.DB
+	keyring *integration.Keyring
+	logger  logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed preference store that encrypts
+// webhook URLs with the keys in keyring.
+func NewMySQLStore(db *gorm.DB, keyring *integration.Keyring, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:      db,
+		keyring: keyring,
+		logger:  log,
+	}
+}
+
+// Get retrieves a user's preferences with the Slack webhook URL decrypted.
+func (s *MySQLStore) Get(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	var prefs Preferences
+	err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&prefs).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPreferencesNotFound
+		}
+		s.logger.Error(ctx, "failed to get notification preferences", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		return nil, err
+	}
+
+	if len(prefs.EncryptedSlackWebhook) > 0 {
+		decrypted, err := s.keyring.Decrypt(prefs.EncryptedSlackWebhook)
+		if err != nil {
+			s.logger.Error(ctx, "failed to decrypt slack webhook URL", map[string]interface{}{
+				"error":   err.Error(),
+				"user_id": userID.String(),
+			})
+			return nil, ErrDecryptFailed
+		}
+		prefs.SlackWebhookURL = decrypted[slackWebhookKey]
+		prefs.SlackConfigured = prefs.SlackWebhookURL != ""
+	}
+
+	return &prefs, nil
+}
+
+// Save creates or replaces a user's preferences.
+func (s *MySQLStore) Save(ctx context.Context, prefs *Preferences) error {
+	if err := prefs.Validate(); err != nil {
+		return err
+	}
+
+	prefs.EncryptedSlackWebhook = nil
+	if prefs.SlackWebhookURL != "" {
+		encrypted, err := s.keyring.Encrypt(map[string]string{slackWebhookKey: prefs.SlackWebhookURL})
+		if err != nil {
+			return err
+		}
+		prefs.EncryptedSlackWebhook = encrypted
+	}
+	prefs.SlackConfigured = prefs.SlackWebhookURL != ""
+
+	if err := s.db.WithContext(ctx).Save(prefs).Error; err != nil {
+		s.logger.Error(ctx, "failed to save notification preferences", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": prefs.UserID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "notification preferences saved", map[string]interface{}{
+		"user_id": prefs.UserID.String(),
+	})
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Save(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	userID := createTestUser(t, db, "jane@example.com")
+
+	t.Run("not saved", func(t *testing.T) {
+		_, err := store.Get(ctx, userID)
+		assert.ErrorIs(t, err, ErrPreferencesNotFound)
+	})
+
+	t.Run("webhook is encrypted at rest", func(t *testing.T) {
+		prefs := &Preferences{
+			UserID:          userID,
+			Events:          EventChannels{EventRunFailed: {ChannelSlack}},
+			SlackWebhookURL: testSlackWebhook,
+		}
+		require.NoError(t, store.Save(ctx, prefs))
+
+		var raw Preferences
+		require.NoError(t, db.Where("user_id = ?", userID).First(&raw).Error)
+		assert.NotEmpty(t, raw.EncryptedSlackWebhook)
+		assert.NotContains(t, string(raw.EncryptedSlackWebhook), "hooks.slack.com")
+
+		retrieved, err := store.Get(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, testSlackWebhook, retrieved.SlackWebhookURL)
+		assert.True(t, retrieved.SlackConfigured)
+		assert.Equal(t, []Channel{ChannelSlack}, retrieved.Channels(EventRunFailed))
+		assert.Empty(t, retrieved.Channels(EventJobFailed))
+	})
+
+	t.Run("save replaces preferences", func(t *testing.T) {
+		require.NoError(t, store.Save(ctx, DefaultPreferences(userID)))
+
+		retrieved, err := store.Get(ctx, userID)
+		require.NoError(t, err)
+		assert.Empty(t, retrieved.SlackWebhookURL)
+		assert.False(t, retrieved.SlackConfigured)
+		assert.Equal(t, []Channel{ChannelEmail}, retrieved.Channels(EventJobFailed))
+	})
+
+	t.Run("invalid preferences are not saved", func(t *testing.T) {
+		err := store.Save(ctx, &Preferences{UserID: userID, Events: EventChannels{EventJobFailed: {ChannelSlack}}})
+		assert.ErrorIs(t, err, ErrSlackWebhookRequired)
+
+		retrieved, err := store.Get(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, []Channel{ChannelEmail}, retrieved.Channels(EventJobFailed))
+	})
+}
+
+func TestRekeySlackWebhooks(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	oldKey := integration.DeriveKey("test-encryption-key")
+	newKey := integration.DeriveKey("new-encryption-key")
+
+	withSlack := createTestUser(t, db, "jane@example.com")
+	require.NoError(t, store.Save(ctx, &Preferences{
+		UserID:          withSlack,
+		Events:          EventChannels{EventRunFailed: {ChannelSlack}},
+		SlackWebhookURL: testSlackWebhook,
+	}))
+	withoutSlack := createTestUser(t, db, "john@example.com")
+	require.NoError(t, store.Save(ctx, DefaultPreferences(withoutSlack)))
+
+	count, err := RekeySlackWebhooks(ctx, db, oldKey, newKey)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	retrieved, err := NewMySQLStore(db, integration.NewKeyring(newKey), logger.NewTestLogger()).Get(ctx, withSlack)
+	require.NoError(t, err)
+	assert.Equal(t, testSlackWebhook, retrieved.SlackWebhookURL)
+}
@@ -0,0 +1,194 @@
+package notification
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPreferencesNotFound is returned when a user has not saved preferences.
+	ErrPreferencesNotFound = errors.New("notification preferences not found")
+
+	// ErrInvalidUserID is returned when user_id is not set.
+	ErrInvalidUserID = errors.New("user_id is required")
+
+	// ErrInvalidEventType is returned for an unknown event type.
+	ErrInvalidEventType = errors.New("event must be one of: run_failed, job_failed, script_generation_failed, scheduled_run_finished, mentioned, integration_credentials, run_overdue")
+
+	// ErrInvalidChannel is returned for an unknown channel.
+	ErrInvalidChannel = errors.New("channel must be one of: email, slack")
+
+	// ErrInvalidSlackWebhook is returned when a Slack webhook URL is not an
+	// https URL on a Slack webhook host.
+	ErrInvalidSlackWebhook = errors.New("slack webhook URL must be an https://hooks.slack.com/ URL")
+
+	// ErrSlackWebhookRequired is returned when Slack is chosen for an event
+	// without a webhook to send to.
+	ErrSlackWebhookRequired = errors.New("slack webhook URL is required to notify via slack")
+
+	// ErrDecryptFailed is returned when a stored webhook cannot be decrypted.
+	ErrDecryptFailed = errors.New("failed to decrypt slack webhook URL")
+)
+
+// EventType identifies something a user can be alerted about.
+type EventType string
+
+const (
+	EventRunFailed              EventType = "run_failed"
+	EventJobFailed              EventType = "job_failed"
+	EventScriptGenerationFailed EventType = "script_generation_failed"
+	EventScheduledRunFinished   EventType = "scheduled_run_finished"
+	EventMentioned              EventType = "mentioned"
+	EventIntegrationCredentials EventType = "integration_credentials"
+	EventRunOverdue             EventType = "run_overdue"
+)
+
+// EventTypes lists every event type.
+var EventTypes = []EventType{
+	EventRunFailed,
+	EventJobFailed,
+	EventScriptGenerationFailed,
+	EventScheduledRunFinished,
+	EventMentioned,
+	EventIntegrationCredentials,
+	EventRunOverdue,
+}
+
+// IsValid checks if the event type is valid.
+func (e EventType) IsValid() bool {
+	switch e {
+	case EventRunFailed, EventJobFailed, EventScriptGenerationFailed, EventScheduledRunFinished, EventMentioned, EventIntegrationCredentials, EventRunOverdue:
+		return true
+	default:
+		return false
+	}
+}
+
+// Channel is a way of delivering notifications.
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSlack Channel = "slack"
+)
+
+// IsValid checks if the channel is valid.
+func (c Channel) IsValid() bool {
+	switch c {
+	case ChannelEmail, ChannelSlack:
+		return true
+	default:
+		return false
+	}
+}
+
+// slackWebhookHosts are the hosts Slack incoming webhooks are served from.
+// Webhooks are restricted to them so users cannot make the server post to
+// arbitrary URLs.
+var slackWebhookHosts = map[string]bool{
+	"hooks.slack.com":     true,
+	"hooks.slack-gov.com": true,
+}
+
+// ValidateSlackWebhookURL checks that raw is a Slack incoming webhook URL.
+func ValidateSlackWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || !slackWebhookHosts[u.Hostname()] || u.Port() != "" || u.User != nil {
+		return ErrInvalidSlackWebhook
+	}
+	return nil
+}
+
+// EventChannels maps each event type to the channels it is sent through. An
+// event type that is absent or has no channels is not sent.
+type EventChannels map[EventType][]Channel
+
+// Value implements driver.Valuer for database storage.
+func (c EventChannels) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner for database retrieval.
+func (c *EventChannels) Scan(value interface{}) error {
+	if value == nil {
+		*c = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan EventChannels: unexpected type %T", value)
+	}
+
+	return json.Unmarshal(bytes, c)
+}
+
+// Preferences are a user's choices of which events to be notified about and
+// how. Users who have not saved preferences get DefaultPreferences.
+type Preferences struct {
+	UserID uuid.UUID     `json:"user_id" gorm:"type:char(36);primaryKey"`
+	Events EventChannels `json:"events" gorm:"type:json;not null"`
+	// SlackWebhookURL is stored encrypted and never serialized.
+	SlackWebhookURL       string    `json:"-" gorm:"-"`
+	EncryptedSlackWebhook []byte    `json:"-" gorm:"type:blob"`
+	SlackConfigured       bool      `json:"slack_configured" gorm:"-"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Preferences.
+func (Preferences) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultPreferences returns the preferences of a user who has not saved
+// any: every event by email.
+func DefaultPreferences(userID uuid.UUID) *Preferences {
+	events := make(EventChannels, len(EventTypes))
+	for _, e := range EventTypes {
+		events[e] = []Channel{ChannelEmail}
+	}
+	return &Preferences{UserID: userID, Events: events}
+}
+
+// Channels returns the channels an event type is sent through.
+func (p *Preferences) Channels(e EventType) []Channel {
+	return p.Events[e]
+}
+
+// Validate checks if the preferences have valid fields.
+func (p *Preferences) Validate() error {
+	if p.UserID == uuid.Nil {
+		return ErrInvalidUserID
+	}
+	for e, channels := range p.Events {
+		if !e.IsValid() {
+			return ErrInvalidEventType
+		}
+		for _, c := range channels {
+			if !c.IsValid() {
+				return ErrInvalidChannel
+			}
+			if c == ChannelSlack && p.SlackWebhookURL == "" {
+				return ErrSlackWebhookRequired
+			}
+		}
+	}
+	if p.SlackWebhookURL != "" {
+		if err := ValidateSlackWebhookURL(p.SlackWebhookURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
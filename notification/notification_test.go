@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSlackWebhookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "slack webhook", url: testSlackWebhook},
+		{name: "slack gov webhook", url: "https://hooks.slack-gov.com/services/T000/B000/XXXX"},
+		{name: "http", url: "http://hooks.slack.com/services/T000/B000/XXXX", wantErr: true},
+		{name: "other host", url: "https://example.com/services/T000", wantErr: true},
+		{name: "lookalike host", url: "https://hooks.slack.com.example.com/services/T000", wantErr: true},
+		{name: "explicit port", url: "https://hooks.slack.com:8443/services/T000", wantErr: true},
+		{name: "userinfo", url: "https://user@hooks.slack.com/services/T000", wantErr: true},
+		{name: "not a URL", url: "::", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSlackWebhookURL(tt.url)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSlackWebhook)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPreferences_Validate(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		prefs   *Preferences
+		wantErr error
+	}{
+		{
+			name:  "defaults",
+			prefs: DefaultPreferences(userID),
+		},
+		{
+			name: "slack with a webhook",
+			prefs: &Preferences{
+				UserID:          userID,
+				Events:          EventChannels{EventRunFailed: {ChannelEmail, ChannelSlack}},
+				SlackWebhookURL: testSlackWebhook,
+			},
+		},
+		{
+			name:    "missing user",
+			prefs:   &Preferences{Events: EventChannels{}},
+			wantErr: ErrInvalidUserID,
+		},
+		{
+			name:    "unknown event",
+			prefs:   &Preferences{UserID: userID, Events: EventChannels{"run_passed": {ChannelEmail}}},
+			wantErr: ErrInvalidEventType,
+		},
+		{
+			name:    "unknown channel",
+			prefs:   &Preferences{UserID: userID, Events: EventChannels{EventJobFailed: {"sms"}}},
+			wantErr: ErrInvalidChannel,
+		},
+		{
+			name:    "slack without a webhook",
+			prefs:   &Preferences{UserID: userID, Events: EventChannels{EventJobFailed: {ChannelSlack}}},
+			wantErr: ErrSlackWebhookRequired,
+		},
+		{
+			name: "invalid webhook",
+			prefs: &Preferences{
+				UserID:          userID,
+				Events:          EventChannels{},
+				SlackWebhookURL: "https://example.com/hook",
+			},
+			wantErr: ErrInvalidSlackWebhook,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.prefs.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDefaultPreferences(t *testing.T) {
+	prefs := DefaultPreferences(uuid.New())
+	for _, e := range EventTypes {
+		assert.Equal(t, []Channel{ChannelEmail}, prefs.Channels(e))
+	}
+}
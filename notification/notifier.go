@@ -0,0 +1,267 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+)
+
+// Event is something that happened that users may be notified about.
+type Event struct {
+	Type EventType
+	// UserIDs are the users to notify. Duplicates are notified once.
+	UserIDs []uuid.UUID
+	Subject string
+	Text    string
+	// Path is the frontend path of the resource the event is about.
+	Path string
+}
+
+// RunFailedEvent returns the event for a test run completed as failed.
+func RunFailedEvent(runID uuid.UUID, procedureName, notes string, userIDs ...uuid.UUID) Event {
+	text := fmt.Sprintf("Test run of %q failed.", procedureName)
+	if notes != "" {
+		text += "\n\nNotes: " + notes
+	}
+	return Event{
+		Type:    EventRunFailed,
+		UserIDs: userIDs,
+		Subject: fmt.Sprintf("Test run failed: %s", procedureName),
+		Text:    text,
+		Path:    "/runs/" + runID.String(),
+	}
+}
+
+// RunOverdueEvent returns the event for a test run still running after the
+// maximum duration its procedure expects.
+func RunOverdueEvent(runID uuid.UUID, procedureName string, maxDurationMinutes int, userIDs ...uuid.UUID) Event {
+	return Event{
+		Type:    EventRunOverdue,
+		UserIDs: userIDs,
+		Subject: fmt.Sprintf("Test run overdue: %s", procedureName),
+		Text:    fmt.Sprintf("Test run of %q is still running after the %d minutes it is expected to take at most.", procedureName, maxDurationMinutes),
+		Path:    "/runs/" + runID.String(),
+	}
+}
+
+// JobFailedEvent returns the event for an agent job that failed.
+func JobFailedEvent(jobID uuid.UUID, jobType, reason string, userIDs ...uuid.UUID) Event {
+	return Event{
+		Type:    EventJobFailed,
+		UserIDs: userIDs,
+		Subject: fmt.Sprintf("Job failed: %s", jobType),
+		Text:    fmt.Sprintf("Job %s (%s) failed: %s", jobID, jobType, reason),
+		Path:    "/jobs",
+	}
+}
+
+// ScriptGenerationFailedEvent returns the event for a script generation that
+// failed.
+func ScriptGenerationFailedEvent(projectID, procedureID uuid.UUID, procedureName, framework, reason string, userIDs ...uuid.UUID) Event {
+	return Event{
+		Type:    EventScriptGenerationFailed,
+		UserIDs: userIDs,
+		Subject: fmt.Sprintf("Script generation failed: %s", procedureName),
+		Text:    fmt.Sprintf("Generating a %s script for %q failed: %s", framework, procedureName, reason),
+		Path:    fmt.Sprintf("/projects/%s/procedures/%s", projectID, procedureID),
+	}
+}
+
+// MentionedEvent returns the event for users @mentioned in a comment on a
+// test run.
+func MentionedEvent(runID uuid.UUID, author, body string, userIDs ...uuid.UUID) Event {
+	return Event{
+		Type:    EventMentioned,
+		UserIDs: userIDs,
+		Subject: fmt.Sprintf("%s mentioned you on a test run", author),
+		Text:    fmt.Sprintf("%s commented on test run %s:\n\n%s", author, runID, body),
+		Path:    "/runs/" + runID.String(),
+	}
+}
+
+// IntegrationCredentialsEvent returns the event for integration credentials
+// that are about to expire, have expired or are rejected by the tracker.
+func IntegrationCredentialsEvent(integ *integration.Integration, status integration.CredentialStatus, userIDs ...uuid.UUID) Event {
+	var text string
+	switch status {
+	case integration.CredentialStatusExpiring:
+		text = fmt.Sprintf("The credentials of integration %q expire on %s. Rotate them before they stop working.",
+			integ.Name, integ.CredentialsExpireAt.Format("2006-01-02"))
+	case integration.CredentialStatusExpired:
+		text = fmt.Sprintf("The credentials of integration %q expired on %s. Issue links are not updated until they are rotated.",
+			integ.Name, integ.CredentialsExpireAt.Format("2006-01-02"))
+	default:
+		text = fmt.Sprintf("The credentials of integration %q were rejected by %s: %s",
+			integ.Name, integ.Provider, integ.LastCheckError)
+	}
+	return Event{
+		Type:    EventIntegrationCredentials,
+		UserIDs: userIDs,
+		Subject: fmt.Sprintf("Integration credentials %s: %s", status, integ.Name),
+		Text:    text,
+		Path:    "/integrations",
+	}
+}
+
+// Notifier delivers events to users through the channels their preferences
+// select. Delivery is best-effort and asynchronous: Notify queues the event
+// and returns, workers send it, and failures are logged and never surface to
+// the caller. A nil Notifier notifies no one.
+type Notifier struct {
+	store     Store
+	userStore user.Store
+	baseURL   string
+	senders   map[Channel]Sender
+	queue     chan Event
+	mu        sync.RWMutex
+	stopped   bool
+	wg        sync.WaitGroup
+	logger    logger.Logger
+}
+
+// NewNotifier creates a notifier that queues up to queueSize events. Links
+// in notifications are made absolute with baseURL; they are left out if it
+// is empty.
+func NewNotifier(store Store, userStore user.Store, baseURL string, queueSize int, log logger.Logger) *Notifier {
+	return &Notifier{
+		store:     store,
+		userStore: userStore,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		senders:   make(map[Channel]Sender),
+		queue:     make(chan Event, queueSize),
+		logger:    log,
+	}
+}
+
+// RegisterSender sets the sender for a channel. Events for channels without
+// a sender are not delivered through them. It must be called before Start.
+func (n *Notifier) RegisterSender(channel Channel, sender Sender) {
+	n.senders[channel] = sender
+}
+
+// Start spawns workers that deliver queued events.
+func (n *Notifier) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			for e := range n.queue {
+				n.deliver(context.Background(), e)
+			}
+		}()
+	}
+}
+
+// Stop stops accepting events and waits for queued events to be delivered.
+// Events notified after Stop are dropped.
+func (n *Notifier) Stop() {
+	n.mu.Lock()
+	n.stopped = true
+	close(n.queue)
+	n.mu.Unlock()
+	n.wg.Wait()
+}
+
+// Notify queues an event for delivery. If the queue is full the event is
+// dropped so that a slow mail server cannot hold up the caller.
+func (n *Notifier) Notify(ctx context.Context, e Event) {
+	if n == nil || len(e.UserIDs) == 0 {
+		return
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.stopped {
+		return
+	}
+
+	select {
+	case n.queue <- e:
+	default:
+		n.logger.Warn(ctx, "notification queue full, dropping event", map[string]interface{}{
+			"event": e.Type,
+		})
+	}
+}
+
+// deliver sends an event to each of its users through their chosen
+// channels.
+func (n *Notifier) deliver(ctx context.Context, e Event) {
+	m := Message{Subject: e.Subject, Text: e.Text}
+	if n.baseURL != "" && e.Path != "" {
+		m.Link = n.baseURL + e.Path
+	}
+
+	seen := make(map[uuid.UUID]bool, len(e.UserIDs))
+	for _, userID := range e.UserIDs {
+		if userID == uuid.Nil || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		n.deliverTo(ctx, userID, e.Type, m)
+	}
+}
+
+func (n *Notifier) deliverTo(ctx context.Context, userID uuid.UUID, eventType EventType, m Message) {
+	u, err := n.userStore.GetByID(ctx, userID)
+	if err != nil {
+		n.logger.Error(ctx, "failed to get notification recipient", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+			"event":   eventType,
+		})
+		return
+	}
+	if !u.IsActive {
+		return
+	}
+
+	prefs, err := n.store.Get(ctx, userID)
+	if errors.Is(err, ErrPreferencesNotFound) {
+		prefs = DefaultPreferences(userID)
+	} else if err != nil {
+		n.logger.Error(ctx, "failed to get notification preferences", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+			"event":   eventType,
+		})
+		return
+	}
+
+	for _, channel := range prefs.Channels(eventType) {
+		sender, ok := n.senders[channel]
+		if !ok {
+			continue
+		}
+
+		to := u.Email
+		if channel == ChannelSlack {
+			to = prefs.SlackWebhookURL
+		}
+		if to == "" {
+			continue
+		}
+
+		if err := sender.Send(ctx, to, m); err != nil {
+			n.logger.Error(ctx, "failed to send notification", map[string]interface{}{
+				"error":   err.Error(),
+				"user_id": userID.String(),
+				"event":   eventType,
+				"channel": channel,
+			})
+			continue
+		}
+		n.logger.Info(ctx, "notification sent", map[string]interface{}{
+			"user_id": userID.String(),
+			"event":   eventType,
+			"channel": channel,
+		})
+	}
+}
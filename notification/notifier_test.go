@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	userStore := user.NewMySQLStore(db, logger.NewTestLogger())
+
+	jane := createTestUser(t, db, "jane@example.com")
+	bob := createTestUser(t, db, "bob@example.com")
+	require.NoError(t, store.Save(ctx, &Preferences{
+		UserID: bob,
+		Events: EventChannels{
+			EventRunFailed: {ChannelSlack},
+			EventJobFailed: {},
+		},
+		SlackWebhookURL: testSlackWebhook,
+	}))
+
+	// notify delivers events through a fresh notifier and waits for them.
+	notify := func(t *testing.T, events ...Event) (email, slack *fakeSender) {
+		email, slack = &fakeSender{}, &fakeSender{}
+		n := NewNotifier(store, userStore, "https://qa.example.com/", 10, logger.NewTestLogger())
+		n.RegisterSender(ChannelEmail, email)
+		n.RegisterSender(ChannelSlack, slack)
+		n.Start(2)
+		for _, e := range events {
+			n.Notify(ctx, e)
+		}
+		n.Stop()
+		return email, slack
+	}
+
+	t.Run("users without preferences get email", func(t *testing.T) {
+		runID := uuid.New()
+		email, slack := notify(t, RunFailedEvent(runID, "Checkout", "button missing", jane))
+
+		sent := email.messages()
+		require.Len(t, sent, 1)
+		assert.Equal(t, "jane@example.com", sent[0].To)
+		assert.Equal(t, "Test run failed: Checkout", sent[0].Message.Subject)
+		assert.Contains(t, sent[0].Message.Text, "button missing")
+		assert.Equal(t, "https://qa.example.com/runs/"+runID.String(), sent[0].Message.Link)
+		assert.Empty(t, slack.messages())
+	})
+
+	t.Run("saved preferences choose channels", func(t *testing.T) {
+		email, slack := notify(t,
+			RunFailedEvent(uuid.New(), "Checkout", "", bob),
+			JobFailedEvent(uuid.New(), "link_check", "timeout", bob),
+			ScriptGenerationFailedEvent(uuid.New(), uuid.New(), "Checkout", "playwright", "llm error", bob),
+		)
+
+		sent := slack.messages()
+		require.Len(t, sent, 1)
+		assert.Equal(t, testSlackWebhook, sent[0].To)
+		assert.Equal(t, "Test run failed: Checkout", sent[0].Message.Subject)
+
+		// Script generation was left out of bob's preferences entirely.
+		assert.Empty(t, email.messages())
+	})
+
+	t.Run("each user is notified once", func(t *testing.T) {
+		email, _ := notify(t, JobFailedEvent(uuid.New(), "link_check", "timeout", jane, jane, uuid.Nil))
+		assert.Len(t, email.messages(), 1)
+	})
+
+	t.Run("inactive and unknown users are skipped", func(t *testing.T) {
+		gone := createTestUser(t, db, "gone@example.com")
+		require.NoError(t, userStore.Delete(ctx, gone))
+
+		email, _ := notify(t, JobFailedEvent(uuid.New(), "link_check", "timeout", gone, uuid.New(), jane))
+		sent := email.messages()
+		require.Len(t, sent, 1)
+		assert.Equal(t, "jane@example.com", sent[0].To)
+	})
+
+	t.Run("send failures do not stop delivery", func(t *testing.T) {
+		email := &fakeSender{err: errors.New("smtp down")}
+		n := NewNotifier(store, userStore, "", 10, logger.NewTestLogger())
+		n.RegisterSender(ChannelEmail, email)
+		n.Start(1)
+		n.Notify(ctx, RunFailedEvent(uuid.New(), "Checkout", "", jane))
+		n.Notify(ctx, RunFailedEvent(uuid.New(), "Checkout", "", jane))
+		n.Stop()
+
+		sent := email.messages()
+		require.Len(t, sent, 2)
+		assert.Empty(t, sent[0].Message.Link)
+	})
+
+	t.Run("channels without a sender are skipped", func(t *testing.T) {
+		n := NewNotifier(store, userStore, "", 10, logger.NewTestLogger())
+		n.Start(1)
+		n.Notify(ctx, RunFailedEvent(uuid.New(), "Checkout", "", jane, bob))
+		n.Stop()
+	})
+
+	t.Run("full queue drops events", func(t *testing.T) {
+		email := &fakeSender{}
+		n := NewNotifier(store, userStore, "", 1, logger.NewTestLogger())
+		n.RegisterSender(ChannelEmail, email)
+		n.Notify(ctx, JobFailedEvent(uuid.New(), "link_check", "timeout", jane))
+		n.Notify(ctx, JobFailedEvent(uuid.New(), "link_check", "timeout", jane))
+		n.Start(1)
+		n.Stop()
+		assert.Len(t, email.messages(), 1)
+	})
+
+	t.Run("stopped and nil notifiers drop events", func(t *testing.T) {
+		n := NewNotifier(store, userStore, "", 1, logger.NewTestLogger())
+		n.Start(1)
+		n.Stop()
+		n.Notify(ctx, JobFailedEvent(uuid.New(), "link_check", "timeout", jane))
+
+		var nilNotifier *Notifier
+		nilNotifier.Notify(ctx, JobFailedEvent(uuid.New(), "link_check", "timeout", jane))
+	})
+}
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"gorm.io/gorm"
+)
+
+// RekeySlackWebhooks re-encrypts every saved Slack webhook URL from oldKey
+// to newKey and returns how many were re-encrypted. URLs already encrypted
+// with newKey are left alone. It stops at the first value that decrypts with
+// neither key; run it in a transaction so that nothing is left half rotated.
+func RekeySlackWebhooks(ctx context.Context, db *gorm.DB, oldKey, newKey []byte) (int, error) {
+	var prefs []Preferences
+	err := db.WithContext(ctx).
+		Select("user_id", "encrypted_slack_webhook").
+		Where("encrypted_slack_webhook IS NOT NULL").
+		Find(&prefs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list notification preferences: %w", err)
+	}
+
+	count := 0
+	for _, p := range prefs {
+		if len(p.EncryptedSlackWebhook) == 0 {
+			continue
+		}
+		encrypted, changed, err := integration.Reencrypt(oldKey, newKey, p.EncryptedSlackWebhook)
+		if err != nil {
+			return 0, fmt.Errorf("slack webhook of user %s: %w", p.UserID, err)
+		}
+		if !changed {
+			continue
+		}
+		err = db.WithContext(ctx).Model(&Preferences{}).
+			Where("user_id = ?", p.UserID).
+			UpdateColumn("encrypted_slack_webhook", encrypted).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to update slack webhook of user %s: %w", p.UserID, err)
+		}
+		count++
+	}
+	return count, nil
+}
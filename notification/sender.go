@@ -0,0 +1,194 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a rendered notification.
+type Message struct {
+	Subject string
+	Text    string
+	// Link is an absolute URL to the resource the notification is about.
+	// It is empty if no base URL is configured.
+	Link string
+}
+
+// Sender delivers messages through one channel. The recipient is an email
+// address for email and a webhook URL for Slack.
+type Sender interface {
+	Send(ctx context.Context, to string, m Message) error
+}
+
+// SMTPConfig configures the SMTP server used to send email.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// EmailSender sends messages as plain-text email over SMTP. STARTTLS is used
+// whenever the server offers it, and is required before authenticating.
+type EmailSender struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+// NewEmailSender creates a new SMTP email sender.
+func NewEmailSender(cfg SMTPConfig) (*EmailSender, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp from address: %w", err)
+	}
+	return &EmailSender{cfg: cfg, from: from}, nil
+}
+
+// Send sends m to the email address to.
+func (s *EmailSender) Send(ctx context.Context, to string, m Message) error {
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Timeout: s.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if s.cfg.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.cfg.Timeout))
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		// smtp.PlainAuth refuses to send credentials over an unencrypted
+		// connection to anything but localhost.
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := c.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	if err := c.Rcpt(rcpt.Address); err != nil {
+		return fmt.Errorf("smtp RCPT TO failed: %w", err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(s.buildEmail(rcpt, m)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return c.Quit()
+}
+
+// buildEmail renders the headers and body of an email.
+func (s *EmailSender) buildEmail(to *mail.Address, m Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", s.from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", stripNewlines(m.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	body := m.Text
+	if m.Link != "" {
+		body += "\n\n" + m.Link
+	}
+	// SMTP requires CRLF line endings.
+	body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	b.WriteString(body)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+// stripNewlines removes line breaks so a value cannot inject email headers.
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// SlackSender posts messages to Slack incoming webhooks.
+type SlackSender struct {
+	client *http.Client
+}
+
+// NewSlackSender creates a new Slack webhook sender.
+func NewSlackSender(timeout time.Duration) *SlackSender {
+	return &SlackSender{client: &http.Client{
+		Timeout: timeout,
+		// Webhook URLs are validated when saved; a redirect could lead
+		// anywhere, so it is not followed.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Send posts m to the webhook URL to.
+func (s *SlackSender) Send(ctx context.Context, to string, m Message) error {
+	text := "*" + m.Subject + "*\n" + m.Text
+	if m.Link != "" {
+		text += "\n<" + m.Link + ">"
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, to, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
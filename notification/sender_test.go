@@ -0,0 +1,157 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts one SMTP session and returns the message data it
+// received, without dot-stuffing or the terminating line.
+func fakeSMTPServer(t *testing.T) (host string, port int, received <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				ch <- data.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, ch
+}
+
+func TestEmailSender_Send(t *testing.T) {
+	host, port, received := fakeSMTPServer(t)
+	sender, err := NewEmailSender(SMTPConfig{
+		Host:    host,
+		Port:    port,
+		From:    "QA Alerts <alerts@example.com>",
+		Timeout: 5 * time.Second,
+	})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), "jane@example.com", Message{
+		Subject: "Test run failed: Checkout\r\nBcc: attacker@example.com",
+		Text:    "Test run of \"Checkout\" failed.\nSee notes.",
+		Link:    "https://qa.example.com/runs/1",
+	})
+	require.NoError(t, err)
+
+	select {
+	case data := <-received:
+		headers, body, ok := strings.Cut(data, "\r\n\r\n")
+		require.True(t, ok)
+		assert.Contains(t, headers, "From: \"QA Alerts\" <alerts@example.com>\r\n")
+		assert.Contains(t, headers, "To: <jane@example.com>\r\n")
+		assert.NotContains(t, headers, "\r\nBcc:")
+		assert.Equal(t, "Test run of \"Checkout\" failed.\r\nSee notes.\r\n\r\nhttps://qa.example.com/runs/1\r\n", body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("email was not received")
+	}
+}
+
+func TestNewEmailSender(t *testing.T) {
+	_, err := NewEmailSender(SMTPConfig{Host: "smtp.example.com", Port: 587, From: "not an address"})
+	assert.Error(t, err)
+
+	_, err = NewEmailSender(SMTPConfig{Port: 587, From: "alerts@example.com"})
+	assert.Error(t, err)
+}
+
+func TestSlackSender_Send(t *testing.T) {
+	t.Run("posts the message", func(t *testing.T) {
+		var payload map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		err := NewSlackSender(5*time.Second).Send(context.Background(), server.URL, Message{
+			Subject: "Job failed: link_check",
+			Text:    "Job failed: timeout",
+			Link:    "https://qa.example.com/jobs",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "*Job failed: link_check*\nJob failed: timeout\n<https://qa.example.com/jobs>", payload["text"])
+	})
+
+	t.Run("reports errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid_token", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := NewSlackSender(5*time.Second).Send(context.Background(), server.URL, Message{Subject: "s"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), strconv.Itoa(http.StatusForbidden))
+		assert.Contains(t, err.Error(), "invalid_token")
+	})
+
+	t.Run("does not follow redirects", func(t *testing.T) {
+		followed := false
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			followed = true
+		}))
+		defer target.Close()
+		server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer server.Close()
+
+		err := NewSlackSender(5*time.Second).Send(context.Background(), server.URL, Message{Subject: "s"})
+		assert.Error(t, err)
+		assert.False(t, followed)
+	})
+}
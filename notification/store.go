@@ -0,0 +1,17 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for notification preference persistence.
+type Store interface {
+	// Get retrieves a user's preferences with the Slack webhook URL
+	// decrypted. It returns ErrPreferencesNotFound if none were saved.
+	Get(ctx context.Context, userID uuid.UUID) (*Preferences, error)
+
+	// Save creates or replaces a user's preferences.
+	Save(ctx context.Context, prefs *Preferences) error
+}
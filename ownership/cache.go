@@ -0,0 +1,123 @@
+package ownership
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cache stores resolved IDs by key. Caches are best effort: a failed lookup
+// is reported as a miss and a failed write is dropped, so the resolver falls
+// back to the database.
+type Cache interface {
+	Get(ctx context.Context, key string) (uuid.UUID, bool)
+	Set(ctx context.Context, key string, value uuid.UUID)
+	Delete(ctx context.Context, keys ...string)
+}
+
+// NoopCache is a Cache that stores nothing, disabling caching.
+type NoopCache struct{}
+
+// Get implements Cache.
+func (NoopCache) Get(ctx context.Context, key string) (uuid.UUID, bool) { return uuid.Nil, false }
+
+// Set implements Cache.
+func (NoopCache) Set(ctx context.Context, key string, value uuid.UUID) {}
+
+// Delete implements Cache.
+func (NoopCache) Delete(ctx context.Context, keys ...string) {}
+
+// memoryEntry is a cached value and when it expires.
+type memoryEntry struct {
+	value   uuid.UUID
+	expires time.Time
+}
+
+// MemoryCache is an in-process Cache. Entries expire after the TTL, and once
+// the cache holds maxEntries an arbitrary entry is evicted for each new one.
+// It is not shared between server instances, so an invalidation on one
+// instance only takes effect on the others when their entries expire.
+type MemoryCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryCache creates an in-process cache. A maxEntries of 0 or less
+// leaves the cache unbounded.
+func NewMemoryCache(ttl time.Duration, maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]memoryEntry),
+	}
+}
+
+// Get implements Cache.
+func (c *MemoryCache) Get(ctx context.Context, key string) (uuid.UUID, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return uuid.Nil, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return uuid.Nil, false
+	}
+	return e.value, true
+}
+
+// Set implements Cache.
+func (c *MemoryCache) Set(ctx context.Context, key string, value uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+	if _, ok := c.entries[key]; !ok && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = memoryEntry{value: value, expires: now.Add(c.ttl)}
+}
+
+// Delete implements Cache.
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet swept.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// sweep drops expired entries, at most once per TTL. Callers must hold mu.
+func (c *MemoryCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
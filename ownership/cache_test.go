@@ -0,0 +1,70 @@
+package ownership
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get, set and delete", func(t *testing.T) {
+		c := NewMemoryCache(time.Minute, 0)
+		value := uuid.New()
+
+		_, ok := c.Get(ctx, "a")
+		assert.False(t, ok)
+
+		c.Set(ctx, "a", value)
+		got, ok := c.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, value, got)
+
+		c.Delete(ctx, "a", "missing")
+		_, ok = c.Get(ctx, "a")
+		assert.False(t, ok)
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		now := time.Now()
+		c := NewMemoryCache(time.Minute, 0)
+		c.now = func() time.Time { return now }
+
+		c.Set(ctx, "a", uuid.New())
+		now = now.Add(59 * time.Second)
+		_, ok := c.Get(ctx, "a")
+		assert.True(t, ok)
+
+		now = now.Add(time.Second)
+		_, ok = c.Get(ctx, "a")
+		assert.False(t, ok)
+	})
+
+	t.Run("expired entries are swept", func(t *testing.T) {
+		now := time.Now()
+		c := NewMemoryCache(time.Minute, 0)
+		c.now = func() time.Time { return now }
+
+		c.Set(ctx, "a", uuid.New())
+		now = now.Add(2 * time.Minute)
+		c.Set(ctx, "b", uuid.New())
+		assert.Equal(t, 1, c.Len())
+	})
+
+	t.Run("bounded by max entries", func(t *testing.T) {
+		c := NewMemoryCache(time.Minute, 2)
+		c.Set(ctx, "a", uuid.New())
+		c.Set(ctx, "b", uuid.New())
+		c.Set(ctx, "b", uuid.New())
+		assert.Equal(t, 2, c.Len())
+
+		c.Set(ctx, "c", uuid.New())
+		assert.Equal(t, 2, c.Len())
+		_, ok := c.Get(ctx, "c")
+		assert.True(t, ok)
+	})
+}
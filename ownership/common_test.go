@@ -0,0 +1,96 @@
+package ownership
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProjectStore counts project lookups.
+type countingProjectStore struct {
+	project.Store
+	gets int
+}
+
+func (s *countingProjectStore) GetByID(ctx context.Context, id uuid.UUID) (*project.Project, error) {
+	s.gets++
+	return s.Store.GetByID(ctx, id)
+}
+
+// countingProcedureStore counts test procedure lookups.
+type countingProcedureStore struct {
+	testprocedure.Store
+	gets int
+}
+
+func (s *countingProcedureStore) GetByID(ctx context.Context, id uuid.UUID) (*testprocedure.TestProcedure, error) {
+	s.gets++
+	return s.Store.GetByID(ctx, id)
+}
+
+// countingRunStore counts test run lookups.
+type countingRunStore struct {
+	testrun.Store
+	gets int
+}
+
+func (s *countingRunStore) GetByID(ctx context.Context, id uuid.UUID) (*testrun.TestRun, error) {
+	s.gets++
+	return s.Store.GetByID(ctx, id)
+}
+
+// testStores holds the stores behind a resolver under test.
+type testStores struct {
+	projects   *countingProjectStore
+	procedures *countingProcedureStore
+	runs       *countingRunStore
+}
+
+// lookups returns the total number of store lookups made.
+func (s *testStores) lookups() int {
+	return s.projects.gets + s.procedures.gets + s.runs.gets
+}
+
+// reset clears the lookup counts.
+func (s *testStores) reset() {
+	s.projects.gets, s.procedures.gets, s.runs.gets = 0, 0, 0
+}
+
+// setupTestResolver creates a test database and a resolver with the given cache.
+func setupTestResolver(t *testing.T, cache Cache) (*Resolver, *testStores) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &project.Project{}, &testprocedure.TestProcedure{}, &testrun.TestRun{})
+
+	log := logger.NewTestLogger()
+	stores := &testStores{
+		projects:   &countingProjectStore{Store: project.NewMySQLStore(db, log)},
+		procedures: &countingProcedureStore{Store: testprocedure.NewMySQLStore(db, log)},
+		runs:       &countingRunStore{Store: testrun.NewMySQLStore(db, log)},
+	}
+	return NewResolver(stores.runs, stores.procedures, stores.projects, cache), stores
+}
+
+// seedRun creates a project owned by ownerID with a procedure and an
+// unstarted run of it.
+func seedRun(t *testing.T, stores *testStores, ownerID uuid.UUID) (*project.Project, *testprocedure.TestProcedure, *testrun.TestRun) {
+	ctx := context.Background()
+
+	proj := &project.Project{Name: "Checkout", OwnerID: ownerID, IsActive: true}
+	require.NoError(t, stores.projects.Create(ctx, proj))
+
+	tp := &testprocedure.TestProcedure{ProjectID: proj.ID, Name: "Pay", CreatedBy: ownerID}
+	require.NoError(t, stores.procedures.Create(ctx, tp))
+
+	tr := &testrun.TestRun{TestProcedureID: tp.ID, ExecutedBy: ownerID}
+	require.NoError(t, stores.runs.Create(ctx, tr))
+
+	stores.reset()
+	return proj, tp, tr
+}
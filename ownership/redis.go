@@ -0,0 +1,84 @@
+package ownership
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/redisclient"
+)
+
+// RedisConfig holds the Redis server used by RedisCache.
+type RedisConfig = redisclient.Config
+
+// RedisCache is a Cache stored in Redis, so entries and invalidations are
+// shared by every server instance. Entries expire after the TTL.
+type RedisCache struct {
+	client *redisclient.Client
+	ttl    time.Duration
+	logger logger.Logger
+}
+
+// NewRedisCache creates a cache in the Redis server described by cfg.
+// Connections are made on first use.
+func NewRedisCache(cfg RedisConfig, ttl time.Duration, log logger.Logger) *RedisCache {
+	return &RedisCache{
+		client: redisclient.New(cfg),
+		ttl:    ttl,
+		logger: log,
+	}
+}
+
+// Get implements Cache.
+func (c *RedisCache) Get(ctx context.Context, key string) (uuid.UUID, bool) {
+	reply, err := c.client.Do(ctx, "GET", key)
+	if err != nil {
+		if !errors.Is(err, redisclient.ErrNil) {
+			c.logFailure(ctx, "GET", err)
+		}
+		return uuid.Nil, false
+	}
+	s, ok := reply.(string)
+	if !ok {
+		return uuid.Nil, false
+	}
+	value, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return value, true
+}
+
+// Set implements Cache.
+func (c *RedisCache) Set(ctx context.Context, key string, value uuid.UUID) {
+	if _, err := c.client.Do(ctx, "SET", key, value.String(), "PX", strconv.FormatInt(c.ttl.Milliseconds(), 10)); err != nil {
+		c.logFailure(ctx, "SET", err)
+	}
+}
+
+// Delete implements Cache.
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if _, err := c.client.Do(ctx, append([]string{"DEL"}, keys...)...); err != nil {
+		c.logFailure(ctx, "DEL", err)
+	}
+}
+
+// Close closes the idle connections.
+func (c *RedisCache) Close() {
+	c.client.Close()
+}
+
+// logFailure logs a failed Redis command.
+func (c *RedisCache) logFailure(ctx context.Context, command string, err error) {
+	c.logger.Warn(ctx, "ownership cache request failed", map[string]interface{}{
+		"error":   err.Error(),
+		"command": command,
+		"addr":    c.client.Addr(),
+	})
+}
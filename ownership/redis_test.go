@@ -0,0 +1,175 @@
+package ownership
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is a minimal in-memory Redis server supporting the commands
+// RedisCache uses.
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string]string
+	ttls     map[string]string
+	commands []string
+}
+
+// startFakeRedis starts a fake Redis server that requires password if set.
+func startFakeRedis(t *testing.T, password string) *fakeRedis {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeRedis{listener: l, password: password, data: map[string]string{}, ttls: map[string]string{}}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := s.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, args[0])
+		var reply string
+		switch {
+		case args[0] == "AUTH":
+			authed = args[1] == s.password
+			reply = "+OK\r\n"
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "GET":
+			if v, ok := s.data[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case args[0] == "SET":
+			s.data[args[1]] = args[2]
+			s.ttls[args[1]] = strings.Join(args[3:], " ")
+			reply = "+OK\r\n"
+		case args[0] == "DEL":
+			n := 0
+			for _, key := range args[1:] {
+				if _, ok := s.data[key]; ok {
+					delete(s.data, key)
+					n++
+				}
+			}
+			reply = fmt.Sprintf(":%d\r\n", n)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get, set and delete", func(t *testing.T) {
+		server := startFakeRedis(t, "secret")
+		c := NewRedisCache(RedisConfig{Addr: server.listener.Addr().String(), Password: "secret"}, time.Minute, logger.NewTestLogger())
+		defer c.Close()
+		value := uuid.New()
+
+		_, ok := c.Get(ctx, "a")
+		assert.False(t, ok)
+
+		c.Set(ctx, "a", value)
+		got, ok := c.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, value, got)
+		assert.Equal(t, "PX 60000", server.ttls["a"])
+
+		c.Delete(ctx, "a")
+		_, ok = c.Get(ctx, "a")
+		assert.False(t, ok)
+
+		// The connection is reused, so AUTH is only sent once.
+		assert.Equal(t, []string{"AUTH", "GET", "SET", "GET", "DEL", "GET"}, server.commands)
+	})
+
+	t.Run("wrong password is a miss", func(t *testing.T) {
+		server := startFakeRedis(t, "secret")
+		c := NewRedisCache(RedisConfig{Addr: server.listener.Addr().String(), Password: "wrong"}, time.Minute, logger.NewTestLogger())
+		defer c.Close()
+
+		c.Set(ctx, "a", uuid.New())
+		_, ok := c.Get(ctx, "a")
+		assert.False(t, ok)
+		assert.Empty(t, server.data)
+	})
+
+	t.Run("unreachable server is a miss", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := l.Addr().String()
+		l.Close()
+
+		c := NewRedisCache(RedisConfig{Addr: addr, Timeout: 100 * time.Millisecond}, time.Minute, logger.NewTestLogger())
+		_, ok := c.Get(ctx, "a")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,131 @@
+package ownership
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Owner identifies the project a resource belongs to and the user who owns
+// that project.
+type Owner struct {
+	ProjectID uuid.UUID
+	UserID    uuid.UUID
+}
+
+// Resolver resolves which project and user own projects, test procedures
+// and test runs, caching each step of the run → procedure → project → owner
+// chain.
+//
+// Runs and procedures never move between projects, so those links only
+// need invalidating when a procedure is deleted; runs cannot be deleted, and
+// a cached run keeps resolving to its project. A project's owner
+// is cached on its own, so deleting or updating a project invalidates one
+// entry and every run and procedure in it is resolved afresh.
+type Resolver struct {
+	testRunStore       testrun.Store
+	testProcedureStore testprocedure.Store
+	projectStore       project.Store
+	cache              Cache
+}
+
+// NewResolver creates a resolver that looks up uncached links in the stores.
+func NewResolver(testRunStore testrun.Store, testProcedureStore testprocedure.Store, projectStore project.Store, cache Cache) *Resolver {
+	return &Resolver{
+		testRunStore:       testRunStore,
+		testProcedureStore: testProcedureStore,
+		projectStore:       projectStore,
+		cache:              cache,
+	}
+}
+
+// ProjectOwner returns the owner of a project. It returns
+// project.ErrProjectNotFound if the project does not exist or was deleted.
+func (r *Resolver) ProjectOwner(ctx context.Context, projectID uuid.UUID) (Owner, error) {
+	key := projectKey(projectID)
+	if userID, ok := r.cache.Get(ctx, key); ok {
+		return Owner{ProjectID: projectID, UserID: userID}, nil
+	}
+
+	proj, err := r.projectStore.GetByID(ctx, projectID)
+	if err != nil {
+		return Owner{}, err
+	}
+	r.cache.Set(ctx, key, proj.OwnerID)
+	return Owner{ProjectID: projectID, UserID: proj.OwnerID}, nil
+}
+
+// ProcedureOwner returns the owner of a test procedure. It returns
+// testprocedure.ErrTestProcedureNotFound or project.ErrProjectNotFound if
+// the procedure or its project does not exist.
+func (r *Resolver) ProcedureOwner(ctx context.Context, procedureID uuid.UUID) (Owner, error) {
+	key := procedureKey(procedureID)
+	projectID, ok := r.cache.Get(ctx, key)
+	if !ok {
+		tp, err := r.testProcedureStore.GetByID(ctx, procedureID)
+		if err != nil {
+			return Owner{}, err
+		}
+		projectID = tp.ProjectID
+		r.cache.Set(ctx, key, projectID)
+	}
+	return r.ProjectOwner(ctx, projectID)
+}
+
+// RunOwner returns the owner of a test run. A started run belongs to the
+// project in its procedure snapshot; otherwise the project is found through
+// its procedure. It returns testrun.ErrTestRunNotFound,
+// testprocedure.ErrTestProcedureNotFound or project.ErrProjectNotFound if
+// any link in the chain does not exist.
+func (r *Resolver) RunOwner(ctx context.Context, runID uuid.UUID) (Owner, error) {
+	key := runKey(runID)
+	projectID, ok := r.cache.Get(ctx, key)
+	if !ok {
+		tr, err := r.testRunStore.GetByID(ctx, runID)
+		if err != nil {
+			return Owner{}, err
+		}
+		if tr.ProcedureSnapshot != nil {
+			projectID = tr.ProcedureSnapshot.ProjectID
+		} else {
+			owner, err := r.ProcedureOwner(ctx, tr.TestProcedureID)
+			if err != nil {
+				return Owner{}, err
+			}
+			projectID = owner.ProjectID
+		}
+		r.cache.Set(ctx, key, projectID)
+	}
+	return r.ProjectOwner(ctx, projectID)
+}
+
+// ForgetProject invalidates a project's cached owner. It must be called
+// after a project is updated or deleted.
+func (r *Resolver) ForgetProject(ctx context.Context, projectID uuid.UUID) {
+	r.cache.Delete(ctx, projectKey(projectID))
+}
+
+// ForgetProcedures invalidates the cached projects of procedures. It must
+// be called after procedures are deleted.
+func (r *Resolver) ForgetProcedures(ctx context.Context, procedureIDs ...uuid.UUID) {
+	keys := make([]string, len(procedureIDs))
+	for i, id := range procedureIDs {
+		keys[i] = procedureKey(id)
+	}
+	r.cache.Delete(ctx, keys...)
+}
+
+func projectKey(id uuid.UUID) string {
+	return "ownership:project:" + id.String()
+}
+
+func procedureKey(id uuid.UUID) string {
+	return "ownership:procedure:" + id.String()
+}
+
+func runKey(id uuid.UUID) string {
+	return "ownership:run:" + id.String()
+}
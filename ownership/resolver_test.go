@@ -0,0 +1,110 @@
+package ownership
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_RunOwner(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("resolves and caches the chain", func(t *testing.T) {
+		resolver, stores := setupTestResolver(t, NewMemoryCache(time.Minute, 0))
+		ownerID := uuid.New()
+		proj, _, tr := seedRun(t, stores, ownerID)
+
+		owner, err := resolver.RunOwner(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, Owner{ProjectID: proj.ID, UserID: ownerID}, owner)
+		assert.Equal(t, 3, stores.lookups())
+
+		stores.reset()
+		owner, err = resolver.RunOwner(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, ownerID, owner.UserID)
+		assert.Equal(t, 0, stores.lookups())
+	})
+
+	t.Run("started run uses its snapshot", func(t *testing.T) {
+		resolver, stores := setupTestResolver(t, NewMemoryCache(time.Minute, 0))
+		ownerID := uuid.New()
+		proj, tp, tr := seedRun(t, stores, ownerID)
+		require.NoError(t, stores.runs.Start(ctx, tr.ID, testrun.NewProcedureSnapshot(tp), nil))
+		stores.reset()
+
+		owner, err := resolver.RunOwner(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, proj.ID, owner.ProjectID)
+		assert.Equal(t, 0, stores.procedures.gets)
+	})
+
+	t.Run("missing run", func(t *testing.T) {
+		resolver, _ := setupTestResolver(t, NewMemoryCache(time.Minute, 0))
+		_, err := resolver.RunOwner(ctx, uuid.New())
+		assert.ErrorIs(t, err, testrun.ErrTestRunNotFound)
+	})
+
+	t.Run("deleted project is not found once forgotten", func(t *testing.T) {
+		resolver, stores := setupTestResolver(t, NewMemoryCache(time.Minute, 0))
+		proj, _, tr := seedRun(t, stores, uuid.New())
+		_, err := resolver.RunOwner(ctx, tr.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, stores.projects.Delete(ctx, proj.ID))
+		resolver.ForgetProject(ctx, proj.ID)
+
+		_, err = resolver.RunOwner(ctx, tr.ID)
+		assert.ErrorIs(t, err, project.ErrProjectNotFound)
+	})
+}
+
+func TestResolver_ProcedureOwner(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("resolves and caches", func(t *testing.T) {
+		resolver, stores := setupTestResolver(t, NewMemoryCache(time.Minute, 0))
+		ownerID := uuid.New()
+		proj, tp, _ := seedRun(t, stores, ownerID)
+
+		owner, err := resolver.ProcedureOwner(ctx, tp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, Owner{ProjectID: proj.ID, UserID: ownerID}, owner)
+
+		stores.reset()
+		_, err = resolver.ProcedureOwner(ctx, tp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, stores.lookups())
+	})
+
+	t.Run("deleted procedure is not found once forgotten", func(t *testing.T) {
+		resolver, stores := setupTestResolver(t, NewMemoryCache(time.Minute, 0))
+		_, tp, _ := seedRun(t, stores, uuid.New())
+		_, err := resolver.ProcedureOwner(ctx, tp.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, stores.procedures.Delete(ctx, tp.ID))
+		resolver.ForgetProcedures(ctx, tp.ID)
+
+		_, err = resolver.ProcedureOwner(ctx, tp.ID)
+		assert.ErrorIs(t, err, testprocedure.ErrTestProcedureNotFound)
+	})
+
+	t.Run("noop cache always reads the stores", func(t *testing.T) {
+		resolver, stores := setupTestResolver(t, NoopCache{})
+		_, tp, _ := seedRun(t, stores, uuid.New())
+
+		for i := 0; i < 2; i++ {
+			_, err := resolver.ProcedureOwner(ctx, tp.ID)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 4, stores.lookups())
+	})
+}
@@ -0,0 +1,260 @@
+// Package presence tracks who has a procedure draft open and who holds its
+// edit lock, so editors can see each other before their changes conflict.
+//
+// Editors poll with heartbeats rather than holding a connection open; a
+// viewer who stops sending them drops out after the timeout. State is kept
+// in memory, like sessions.
+package presence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+var (
+	// ErrLocked is returned when another user holds the edit lock of a
+	// draft.
+	ErrLocked = errors.New("draft is locked by another user")
+
+	// ErrNotLockHolder is returned when releasing a lock held by another
+	// user.
+	ErrNotLockHolder = errors.New("draft lock is held by another user")
+)
+
+const (
+	// DefaultTimeout is how long a viewer stays present after their last
+	// heartbeat.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultLockTTL is how long an edit lock lasts unless it is renewed.
+	DefaultLockTTL = 5 * time.Minute
+)
+
+// Viewer is a user who has a draft open.
+type Viewer struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// Lock is the edit lock of a draft. While it is held, only its holder can
+// change the draft.
+type Lock struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Tracker tracks the viewers and edit locks of drafts, by procedure ID.
+type Tracker struct {
+	mu      sync.Mutex
+	viewers map[uuid.UUID]map[uuid.UUID]Viewer
+	locks   map[uuid.UUID]Lock
+	timeout time.Duration
+	lockTTL time.Duration
+	now     func() time.Time
+	logger  logger.Logger
+	stopCh  chan struct{}
+}
+
+// NewTracker creates a new tracker. Viewers drop out timeout after their
+// last heartbeat and locks expire lockTTL after they were last acquired.
+func NewTracker(timeout, lockTTL time.Duration, log logger.Logger) *Tracker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if lockTTL <= 0 {
+		lockTTL = DefaultLockTTL
+	}
+	return &Tracker{
+		viewers: make(map[uuid.UUID]map[uuid.UUID]Viewer),
+		locks:   make(map[uuid.UUID]Lock),
+		timeout: timeout,
+		lockTTL: lockTTL,
+		now:     time.Now,
+		logger:  log,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Heartbeat records that a user has the draft of a procedure open.
+func (t *Tracker) Heartbeat(procedureID, userID uuid.UUID, username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	viewers, ok := t.viewers[procedureID]
+	if !ok {
+		viewers = make(map[uuid.UUID]Viewer)
+		t.viewers[procedureID] = viewers
+	}
+	viewers[userID] = Viewer{UserID: userID, Username: username, LastSeenAt: t.now()}
+}
+
+// Leave records that a user closed the draft of a procedure, releasing
+// their lock on it.
+func (t *Tracker) Leave(procedureID, userID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if viewers, ok := t.viewers[procedureID]; ok {
+		delete(viewers, userID)
+		if len(viewers) == 0 {
+			delete(t.viewers, procedureID)
+		}
+	}
+	if lock, ok := t.locks[procedureID]; ok && lock.UserID == userID {
+		delete(t.locks, procedureID)
+	}
+}
+
+// Viewers returns the users who have the draft of a procedure open, by
+// username.
+func (t *Tracker) Viewers(procedureID uuid.UUID) []Viewer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := t.now().Add(-t.timeout)
+	viewers := []Viewer{}
+	for _, v := range t.viewers[procedureID] {
+		if v.LastSeenAt.After(cutoff) {
+			viewers = append(viewers, v)
+		}
+	}
+	sort.Slice(viewers, func(i, j int) bool {
+		if viewers[i].Username != viewers[j].Username {
+			return viewers[i].Username < viewers[j].Username
+		}
+		return viewers[i].UserID.String() < viewers[j].UserID.String()
+	})
+	return viewers
+}
+
+// Lock returns the edit lock of the draft of a procedure, nil if it is not
+// locked.
+func (t *Tracker) Lock(procedureID uuid.UUID) *Lock {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lockLocked(procedureID)
+}
+
+// lockLocked returns the unexpired lock of a procedure. t.mu must be held.
+func (t *Tracker) lockLocked(procedureID uuid.UUID) *Lock {
+	lock, ok := t.locks[procedureID]
+	if !ok || !t.now().Before(lock.ExpiresAt) {
+		return nil
+	}
+	return &lock
+}
+
+// AcquireLock locks the draft of a procedure for a user, or renews their
+// lock. Returns ErrLocked with the current lock if another user holds it.
+func (t *Tracker) AcquireLock(procedureID, userID uuid.UUID, username string) (*Lock, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	lock := t.lockLocked(procedureID)
+	if lock != nil && lock.UserID != userID {
+		return lock, ErrLocked
+	}
+	if lock == nil {
+		lock = &Lock{UserID: userID, Username: username, AcquiredAt: now}
+	}
+	lock.ExpiresAt = now.Add(t.lockTTL)
+	t.locks[procedureID] = *lock
+	return lock, nil
+}
+
+// ReleaseLock releases the lock of a user on the draft of a procedure.
+// Releasing a draft that is not locked does nothing. Returns
+// ErrNotLockHolder if another user holds the lock.
+func (t *Tracker) ReleaseLock(procedureID, userID uuid.UUID) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lock := t.lockLocked(procedureID)
+	if lock == nil {
+		delete(t.locks, procedureID)
+		return nil
+	}
+	if lock.UserID != userID {
+		return ErrNotLockHolder
+	}
+	delete(t.locks, procedureID)
+	return nil
+}
+
+// CheckLock returns ErrLocked with the current lock if a user other than
+// userID holds the lock of the draft of a procedure.
+func (t *Tracker) CheckLock(procedureID, userID uuid.UUID) (*Lock, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lock := t.lockLocked(procedureID)
+	if lock != nil && lock.UserID != userID {
+		return lock, ErrLocked
+	}
+	return lock, nil
+}
+
+// Cleanup removes viewers who stopped sending heartbeats and expired
+// locks. Returns how many were removed.
+func (t *Tracker) Cleanup() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	cutoff := now.Add(-t.timeout)
+	removed := 0
+	for procedureID, viewers := range t.viewers {
+		for userID, v := range viewers {
+			if !v.LastSeenAt.After(cutoff) {
+				delete(viewers, userID)
+				removed++
+			}
+		}
+		if len(viewers) == 0 {
+			delete(t.viewers, procedureID)
+		}
+	}
+	for procedureID, lock := range t.locks {
+		if !now.Before(lock.ExpiresAt) {
+			delete(t.locks, procedureID)
+			removed++
+		}
+	}
+	return removed
+}
+
+// StartCleanup starts a background goroutine that periodically removes
+// stale viewers and expired locks.
+func (t *Tracker) StartCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if removed := t.Cleanup(); removed > 0 {
+					t.logger.Debug(context.Background(), "cleaned up draft presence", map[string]interface{}{
+						"removed_count": removed,
+					})
+				}
+			case <-t.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// StopCleanup stops the cleanup goroutine.
+func (t *Tracker) StopCleanup() {
+	close(t.stopCh)
+}
@@ -0,0 +1,133 @@
+package presence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTracker returns a tracker with a clock the test moves by hand.
+func newTestTracker(t *testing.T) (*Tracker, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(30*time.Second, 5*time.Minute, logger.NewTestLogger())
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestTracker_Viewers(t *testing.T) {
+	tracker, now := newTestTracker(t)
+	procedureID := uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+
+	tracker.Heartbeat(procedureID, bob, "bob")
+	tracker.Heartbeat(procedureID, alice, "alice")
+	tracker.Heartbeat(uuid.New(), uuid.New(), "carol")
+
+	viewers := tracker.Viewers(procedureID)
+	require.Len(t, viewers, 2)
+	assert.Equal(t, "alice", viewers[0].Username)
+	assert.Equal(t, "bob", viewers[1].Username)
+
+	t.Run("viewers drop out after the timeout", func(t *testing.T) {
+		*now = now.Add(20 * time.Second)
+		tracker.Heartbeat(procedureID, alice, "alice")
+		*now = now.Add(20 * time.Second)
+
+		viewers := tracker.Viewers(procedureID)
+		require.Len(t, viewers, 1)
+		assert.Equal(t, alice, viewers[0].UserID)
+	})
+
+	t.Run("leaving removes the viewer", func(t *testing.T) {
+		tracker.Leave(procedureID, alice)
+		assert.Empty(t, tracker.Viewers(procedureID))
+	})
+}
+
+func TestTracker_Lock(t *testing.T) {
+	procedureID := uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+
+	t.Run("only one user holds the lock", func(t *testing.T) {
+		tracker, _ := newTestTracker(t)
+
+		lock, err := tracker.AcquireLock(procedureID, alice, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, alice, lock.UserID)
+
+		held, err := tracker.AcquireLock(procedureID, bob, "bob")
+		assert.ErrorIs(t, err, ErrLocked)
+		assert.Equal(t, alice, held.UserID)
+
+		_, err = tracker.CheckLock(procedureID, bob)
+		assert.ErrorIs(t, err, ErrLocked)
+		_, err = tracker.CheckLock(procedureID, alice)
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, tracker.ReleaseLock(procedureID, bob), ErrNotLockHolder)
+		require.NoError(t, tracker.ReleaseLock(procedureID, alice))
+		assert.Nil(t, tracker.Lock(procedureID))
+
+		_, err = tracker.AcquireLock(procedureID, bob, "bob")
+		assert.NoError(t, err)
+	})
+
+	t.Run("renewing extends the lock", func(t *testing.T) {
+		tracker, now := newTestTracker(t)
+
+		first, err := tracker.AcquireLock(procedureID, alice, "alice")
+		require.NoError(t, err)
+		*now = now.Add(4 * time.Minute)
+		renewed, err := tracker.AcquireLock(procedureID, alice, "alice")
+		require.NoError(t, err)
+
+		assert.Equal(t, first.AcquiredAt, renewed.AcquiredAt)
+		assert.Equal(t, now.Add(5*time.Minute), renewed.ExpiresAt)
+	})
+
+	t.Run("expired locks are free", func(t *testing.T) {
+		tracker, now := newTestTracker(t)
+
+		_, err := tracker.AcquireLock(procedureID, alice, "alice")
+		require.NoError(t, err)
+		*now = now.Add(5 * time.Minute)
+
+		assert.Nil(t, tracker.Lock(procedureID))
+		_, err = tracker.CheckLock(procedureID, bob)
+		assert.NoError(t, err)
+		lock, err := tracker.AcquireLock(procedureID, bob, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, bob, lock.UserID)
+	})
+
+	t.Run("leaving releases the lock", func(t *testing.T) {
+		tracker, _ := newTestTracker(t)
+
+		tracker.Heartbeat(procedureID, alice, "alice")
+		_, err := tracker.AcquireLock(procedureID, alice, "alice")
+		require.NoError(t, err)
+		tracker.Leave(procedureID, alice)
+
+		assert.Nil(t, tracker.Lock(procedureID))
+	})
+}
+
+func TestTracker_Cleanup(t *testing.T) {
+	tracker, now := newTestTracker(t)
+	procedureID := uuid.New()
+
+	tracker.Heartbeat(procedureID, uuid.New(), "alice")
+	_, err := tracker.AcquireLock(procedureID, uuid.New(), "bob")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, tracker.Cleanup())
+	*now = now.Add(5 * time.Minute)
+	assert.Equal(t, 2, tracker.Cleanup())
+	assert.Empty(t, tracker.viewers)
+	assert.Empty(t, tracker.locks)
+}
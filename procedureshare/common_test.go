@@ -0,0 +1,17 @@
+package procedureshare
+
+import (
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+)
+
+// setupTestStore creates a test database and procedure share store for
+// testing.
+func setupTestStore(t *testing.T) Store {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Share{})
+
+	return NewMySQLStore(db, logger.NewTestLogger())
+}
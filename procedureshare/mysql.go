@@ -0,0 +1,156 @@
+package procedureshare
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed procedure share store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Save shares a procedure with a user, or changes the access of the share
+// it has with them.
+func (s *MySQLStore) Save(ctx context.Context, share *Share) error {
+	if err := share.Validate(); err != nil {
+		return err
+	}
+
+	existing, err := s.Get(ctx, share.ProcedureID, share.UserID)
+	if err != nil && !errors.Is(err, ErrShareNotFound) {
+		return err
+	}
+
+	if existing != nil {
+		existing.Access = share.Access
+		existing.SharedBy = share.SharedBy
+		if err := s.db.WithContext(ctx).Save(existing).Error; err != nil {
+			s.logger.Error(ctx, "failed to update procedure share", map[string]interface{}{
+				"error":        err.Error(),
+				"procedure_id": share.ProcedureID.String(),
+				"user_id":      share.UserID.String(),
+			})
+			return err
+		}
+		*share = *existing
+		return nil
+	}
+
+	if err := s.db.WithContext(ctx).Create(share).Error; err != nil {
+		s.logger.Error(ctx, "failed to create procedure share", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": share.ProcedureID.String(),
+			"user_id":      share.UserID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "procedure shared", map[string]interface{}{
+		"procedure_id": share.ProcedureID.String(),
+		"user_id":      share.UserID.String(),
+		"access":       string(share.Access),
+	})
+
+	return nil
+}
+
+// Get retrieves the share of a procedure with a user.
+func (s *MySQLStore) Get(ctx context.Context, procedureID, userID uuid.UUID) (*Share, error) {
+	var share Share
+	err := s.db.WithContext(ctx).
+		Where("procedure_id = ? AND user_id = ?", procedureID, userID).
+		First(&share).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		s.logger.Error(ctx, "failed to get procedure share", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+			"user_id":      userID.String(),
+		})
+		return nil, err
+	}
+
+	return &share, nil
+}
+
+// ListByProcedure retrieves the shares of a procedure, oldest first.
+func (s *MySQLStore) ListByProcedure(ctx context.Context, procedureID uuid.UUID) ([]*Share, error) {
+	var shares []*Share
+	err := s.db.WithContext(ctx).
+		Where("procedure_id = ?", procedureID).
+		Order("created_at ASC").
+		Find(&shares).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list procedure shares", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+		})
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// ListByUser retrieves the procedures shared with a user, most recent
+// first.
+func (s *MySQLStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*Share, error) {
+	var shares []*Share
+	err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&shares).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list procedures shared with user", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// Delete stops sharing a procedure with a user.
+func (s *MySQLStore) Delete(ctx context.Context, procedureID, userID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("procedure_id = ? AND user_id = ?", procedureID, userID).
+		Delete(&Share{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete procedure share", map[string]interface{}{
+			"error":        result.Error.Error(),
+			"procedure_id": procedureID.String(),
+			"user_id":      userID.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareNotFound
+	}
+
+	s.logger.Info(ctx, "procedure unshared", map[string]interface{}{
+		"procedure_id": procedureID.String(),
+		"user_id":      userID.String(),
+	})
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package procedureshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShare_Validate(t *testing.T) {
+	valid := func() *Share {
+		return &Share{ProcedureID: uuid.New(), UserID: uuid.New(), SharedBy: uuid.New(), Access: AccessReadOnly}
+	}
+	assert.NoError(t, valid().Validate())
+
+	missingUser := valid()
+	missingUser.UserID = uuid.Nil
+	assert.ErrorIs(t, missingUser.Validate(), ErrInvalidShare)
+
+	invalidAccess := valid()
+	invalidAccess.Access = "admin"
+	assert.ErrorIs(t, invalidAccess.Validate(), ErrInvalidAccess)
+}
+
+func TestMySQLStore(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+	procedureID, userID, ownerID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("share a procedure", func(t *testing.T) {
+		share := &Share{ProcedureID: procedureID, UserID: userID, SharedBy: ownerID, Access: AccessReadOnly}
+		require.NoError(t, store.Save(ctx, share))
+		assert.NotEqual(t, uuid.Nil, share.ID)
+
+		retrieved, err := store.Get(ctx, procedureID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, AccessReadOnly, retrieved.Access)
+		assert.False(t, retrieved.AllowsWrites())
+	})
+
+	t.Run("sharing again changes the access", func(t *testing.T) {
+		share := &Share{ProcedureID: procedureID, UserID: userID, SharedBy: ownerID, Access: AccessReadWrite}
+		require.NoError(t, store.Save(ctx, share))
+
+		shares, err := store.ListByProcedure(ctx, procedureID)
+		require.NoError(t, err)
+		require.Len(t, shares, 1)
+		assert.True(t, shares[0].AllowsWrites())
+	})
+
+	t.Run("list the procedures shared with a user", func(t *testing.T) {
+		other := uuid.New()
+		require.NoError(t, store.Save(ctx, &Share{ProcedureID: other, UserID: userID, SharedBy: ownerID, Access: AccessReadOnly}))
+
+		shares, err := store.ListByUser(ctx, userID)
+		require.NoError(t, err)
+		assert.Len(t, shares, 2)
+	})
+
+	t.Run("stop sharing", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, procedureID, userID))
+
+		_, err := store.Get(ctx, procedureID, userID)
+		assert.ErrorIs(t, err, ErrShareNotFound)
+		assert.ErrorIs(t, store.Delete(ctx, procedureID, userID), ErrShareNotFound)
+	})
+}
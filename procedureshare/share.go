@@ -0,0 +1,85 @@
+// Package procedureshare shares individual test procedures with users
+// outside their project. A shared procedure can be read, or also edited,
+// by the user it is shared with, without access to the rest of its
+// project.
+package procedureshare
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrShareNotFound is returned when a procedure is not shared with a
+	// user.
+	ErrShareNotFound = errors.New("procedure share not found")
+
+	// ErrInvalidAccess is returned when a share's access is neither
+	// read_only nor read_write.
+	ErrInvalidAccess = errors.New("access must be read_only or read_write")
+
+	// ErrInvalidShare is returned when a share is missing the procedure,
+	// the user it is shared with or the user sharing it.
+	ErrInvalidShare = errors.New("share needs a procedure, a user and who shared it")
+)
+
+// Access is what a user a procedure is shared with may do with it.
+type Access string
+
+const (
+	// AccessReadOnly lets the user view the procedure.
+	AccessReadOnly Access = "read_only"
+
+	// AccessReadWrite also lets the user edit the procedure.
+	AccessReadWrite Access = "read_write"
+)
+
+// IsValid reports whether a is a known access.
+func (a Access) IsValid() bool {
+	return a == AccessReadOnly || a == AccessReadWrite
+}
+
+// Share gives a user access to a test procedure outside their projects.
+// ProcedureID is the procedure's first version, so the share covers all
+// its versions.
+type Share struct {
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	ProcedureID uuid.UUID `json:"procedure_id" gorm:"type:char(36);not null;uniqueIndex:idx_procedure_shares_procedure_user"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:char(36);not null;uniqueIndex:idx_procedure_shares_procedure_user;index:idx_procedure_shares_user_id"`
+	Access      Access    `json:"access" gorm:"type:varchar(20);not null;default:'read_only'"`
+	SharedBy    uuid.UUID `json:"shared_by" gorm:"type:char(36);not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Share.
+func (Share) TableName() string {
+	return "procedure_shares"
+}
+
+// BeforeCreate hook to generate UUID before creating a new share.
+func (s *Share) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the share has valid required fields.
+func (s *Share) Validate() error {
+	if s.ProcedureID == uuid.Nil || s.UserID == uuid.Nil || s.SharedBy == uuid.Nil {
+		return ErrInvalidShare
+	}
+	if !s.Access.IsValid() {
+		return ErrInvalidAccess
+	}
+	return nil
+}
+
+// AllowsWrites reports whether the share lets its user edit the procedure.
+func (s *Share) AllowsWrites() bool {
+	return s.Access == AccessReadWrite
+}
@@ -0,0 +1,30 @@
+package procedureshare
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for procedure share persistence operations.
+type Store interface {
+	// Save shares a procedure with a user, or changes the access of the
+	// share it has with them.
+	Save(ctx context.Context, share *Share) error
+
+	// Get retrieves the share of a procedure, identified by its first
+	// version, with a user. It returns ErrShareNotFound if there is none.
+	Get(ctx context.Context, procedureID, userID uuid.UUID) (*Share, error)
+
+	// ListByProcedure retrieves the shares of a procedure, identified by
+	// its first version, oldest first.
+	ListByProcedure(ctx context.Context, procedureID uuid.UUID) ([]*Share, error)
+
+	// ListByUser retrieves the procedures shared with a user, most recent
+	// first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Share, error)
+
+	// Delete stops sharing a procedure with a user. It returns
+	// ErrShareNotFound if it was not shared with them.
+	Delete(ctx context.Context, procedureID, userID uuid.UUID) error
+}
@@ -166,3 +166,43 @@ func (s *MySQLStore) CountByOwner(ctx context.Context, ownerID uuid.UUID) (int,
 
 	return int(count), nil
 }
+
+// ListAll retrieves a paginated list of the active projects of all owners.
+func (s *MySQLStore) ListAll(ctx context.Context, limit, offset int) ([]*Project, error) {
+	var projects []*Project
+	err := s.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&projects).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list all projects", map[string]interface{}{
+			"error":  err.Error(),
+			"limit":  limit,
+			"offset": offset,
+		})
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// CountAll returns the total count of the active projects of all owners.
+func (s *MySQLStore) CountAll(ctx context.Context) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&Project{}).
+		Where("is_active = ?", true).
+		Count(&count).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to count all projects", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -248,3 +248,39 @@ func TestMySQLStore_ListByOwner(t *testing.T) {
 		assert.Empty(t, projects)
 	})
 }
+
+func TestMySQLStore_ListAll(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	owner1 := uuid.New()
+	owner2 := uuid.New()
+	project1 := createTestProject("Owner 1 Project", "Description", owner1)
+	require.NoError(t, store.Create(ctx, project1))
+	project2 := createTestProject("Owner 2 Project", "Description", owner2)
+	require.NoError(t, store.Create(ctx, project2))
+	deleted := createTestProject("Deleted Project", "Description", owner2)
+	require.NoError(t, store.Create(ctx, deleted))
+	require.NoError(t, store.Delete(ctx, deleted.ID))
+
+	t.Run("lists active projects of every owner", func(t *testing.T) {
+		projects, err := store.ListAll(ctx, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, projects, 2)
+
+		ids := []uuid.UUID{projects[0].ID, projects[1].ID}
+		assert.ElementsMatch(t, []uuid.UUID{project1.ID, project2.ID}, ids)
+	})
+
+	t.Run("paginates", func(t *testing.T) {
+		projects, err := store.ListAll(ctx, 1, 1)
+		require.NoError(t, err)
+		assert.Len(t, projects, 1)
+	})
+
+	t.Run("counts active projects", func(t *testing.T) {
+		count, err := store.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
@@ -17,6 +17,10 @@ var (
 
 	// ErrInvalidOwner is returned when owner_id is not set.
 	ErrInvalidOwner = errors.New("owner_id is required")
+
+	// ErrInvalidIssueRepository is returned when a default issue repository
+	// is not in owner/repo form.
+	ErrInvalidIssueRepository = errors.New("default issue repository must be in owner/repo form")
 )
 
 // Project represents a test procedure project in the system.
@@ -26,8 +30,17 @@ type Project struct {
 	Description string    `json:"description" gorm:"type:text"`
 	OwnerID     uuid.UUID `json:"owner_id" gorm:"type:char(36);not null;index:idx_owner_id"`
 	IsActive    bool      `json:"is_active" gorm:"default:true;index:idx_is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// DefaultIntegrationID, DefaultIssueProjectKey and DefaultIssueRepository
+	// are used for issues created from the project's runs when the request
+	// leaves them out.
+	DefaultIntegrationID   *uuid.UUID `json:"default_integration_id,omitempty" gorm:"type:char(36)"`
+	DefaultIssueProjectKey string     `json:"default_issue_project_key,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	DefaultIssueRepository string     `json:"default_issue_repository,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	// RequireProcedureReview holds committed procedure changes for review
+	// before they become the latest version.
+	RequireProcedureReview bool      `json:"require_procedure_review" gorm:"not null;default:false"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
 }
 
 // BeforeCreate hook to generate UUID before creating a new project
@@ -63,3 +63,33 @@ func TestProject_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDefaultIssueRepository(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		want       string
+		wantErr    error
+	}{
+		{name: "owner/repo", repository: "acme/web", want: "acme/web"},
+		{name: "trimmed", repository: "  acme/web ", want: "acme/web"},
+		{name: "empty clears the default", repository: "", want: ""},
+		{name: "missing owner", repository: "web", wantErr: ErrInvalidIssueRepository},
+		{name: "empty repo", repository: "acme/", wantErr: ErrInvalidIssueRepository},
+		{name: "too many parts", repository: "acme/web/extra", wantErr: ErrInvalidIssueRepository},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Project{DefaultIssueRepository: "old/repo"}
+			err := SetDefaultIssueRepository(tt.repository)(p)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, "old/repo", p.DefaultIssueRepository)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, p.DefaultIssueRepository)
+		})
+	}
+}
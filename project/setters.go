@@ -1,5 +1,11 @@
 package project
 
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
 // SetName returns an UpdateSetter that sets the project's name.
 func SetName(name string) UpdateSetter {
 	return func(p *Project) error {
@@ -26,3 +32,61 @@ func SetActive(active bool) UpdateSetter {
 		return nil
 	}
 }
+
+// SetOwner returns an UpdateSetter that transfers the project to another
+// owner.
+func SetOwner(ownerID uuid.UUID) UpdateSetter {
+	return func(p *Project) error {
+		if ownerID == uuid.Nil {
+			return ErrInvalidOwner
+		}
+		p.OwnerID = ownerID
+		return nil
+	}
+}
+
+// SetDefaultIntegration returns an UpdateSetter that sets the integration
+// issues are created in by default. A nil ID removes the default.
+func SetDefaultIntegration(integrationID *uuid.UUID) UpdateSetter {
+	return func(p *Project) error {
+		p.DefaultIntegrationID = integrationID
+		return nil
+	}
+}
+
+// SetDefaultIssueProjectKey returns an UpdateSetter that sets the issue
+// tracker project key issues are created in by default.
+func SetDefaultIssueProjectKey(key string) UpdateSetter {
+	return func(p *Project) error {
+		p.DefaultIssueProjectKey = strings.TrimSpace(key)
+		return nil
+	}
+}
+
+// SetDefaultIssueRepository returns an UpdateSetter that sets the owner/repo
+// repository issues are created in by default.
+func SetDefaultIssueRepository(repository string) UpdateSetter {
+	return func(p *Project) error {
+		repository = strings.TrimSpace(repository)
+		if repository != "" && !validRepository(repository) {
+			return ErrInvalidIssueRepository
+		}
+		p.DefaultIssueRepository = repository
+		return nil
+	}
+}
+
+// SetRequireProcedureReview returns an UpdateSetter that sets whether
+// committed procedure changes need an approved review to be published.
+func SetRequireProcedureReview(required bool) UpdateSetter {
+	return func(p *Project) error {
+		p.RequireProcedureReview = required
+		return nil
+	}
+}
+
+// validRepository checks that a repository is in owner/repo form.
+func validRepository(repository string) bool {
+	owner, repo, ok := strings.Cut(repository, "/")
+	return ok && owner != "" && repo != "" && !strings.Contains(repo, "/")
+}
@@ -25,6 +25,12 @@ type Store interface {
 
 	// CountByOwner returns the total count of active projects for a specific owner.
 	CountByOwner(ctx context.Context, ownerID uuid.UUID) (int, error)
+
+	// ListAll retrieves a paginated list of the active projects of all owners.
+	ListAll(ctx context.Context, limit, offset int) ([]*Project, error)
+
+	// CountAll returns the total count of the active projects of all owners.
+	CountAll(ctx context.Context) (int, error)
 }
 
 // UpdateSetter is a function that updates a project field.
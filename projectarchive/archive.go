@@ -0,0 +1,141 @@
+package projectarchive
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// FormatVersion is the archive format written by Export. Import rejects
+// archives written in any other format.
+const FormatVersion = 1
+
+var (
+	// ErrUnsupportedFormat is returned when an archive's format version is
+	// not FormatVersion.
+	ErrUnsupportedFormat = errors.New("unsupported archive format version")
+
+	// ErrInvalidArchive is returned when an archive is inconsistent, for
+	// example when a run refers to a procedure the archive does not contain.
+	ErrInvalidArchive = errors.New("invalid archive")
+)
+
+// Archive is a complete copy of a project: every version of its test
+// procedures, including drafts, and their test runs with step notes. Assets
+// are listed as a manifest with their annotations; their content stays in
+// blob storage.
+type Archive struct {
+	FormatVersion int                            `json:"format_version"`
+	ExportedAt    time.Time                      `json:"exported_at"`
+	Project       project.Project                `json:"project"`
+	Procedures    []*testprocedure.TestProcedure `json:"procedures"`
+	Runs          []*Run                         `json:"runs"`
+	StepNotes     []*testrun.StepNote            `json:"step_notes"`
+	Assets        []*testrun.TestRunAsset        `json:"assets"`
+	Annotations   []*testrun.AssetAnnotation     `json:"annotations"`
+}
+
+// Run is a test run together with the procedure snapshot it executed,
+// which the API does not otherwise expose.
+type Run struct {
+	testrun.TestRun
+	ProcedureSnapshot *testrun.ProcedureSnapshot `json:"procedure_snapshot,omitempty"`
+}
+
+// AssetCopy is a blob that must be copied for an imported asset, since the
+// imported asset gets its own storage path. Copies are only listed for blobs
+// the importing user already owns.
+type AssetCopy struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ImportResult is the outcome of an import.
+type ImportResult struct {
+	Project     *project.Project `json:"project"`
+	Procedures  int              `json:"procedures"`
+	Runs        int              `json:"runs"`
+	StepNotes   int              `json:"step_notes"`
+	Assets      int              `json:"assets"`
+	Annotations int              `json:"annotations"`
+	AssetCopies []AssetCopy      `json:"-"`
+}
+
+// AssetBytes returns the total size of the archive's assets.
+func (a *Archive) AssetBytes() int64 {
+	var total int64
+	for _, asset := range a.Assets {
+		if asset != nil {
+			total += asset.FileSize
+		}
+	}
+	return total
+}
+
+// Validate checks that the archive can be imported: it must be in the
+// current format and every reference must resolve within the archive.
+func (a *Archive) Validate() error {
+	if a.FormatVersion != FormatVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedFormat, a.FormatVersion)
+	}
+	if a.Project.Name == "" {
+		return fmt.Errorf("%w: %w", ErrInvalidArchive, project.ErrInvalidProjectName)
+	}
+
+	procedures := make(map[uuid.UUID]bool, len(a.Procedures))
+	for _, p := range a.Procedures {
+		if p == nil || p.ID == uuid.Nil {
+			return fmt.Errorf("%w: procedure without an ID", ErrInvalidArchive)
+		}
+		procedures[p.ID] = true
+	}
+	for _, p := range a.Procedures {
+		if p.ParentID != nil && !procedures[*p.ParentID] {
+			return fmt.Errorf("%w: procedure %s has unknown parent %s", ErrInvalidArchive, p.ID, *p.ParentID)
+		}
+	}
+
+	runs := make(map[uuid.UUID]bool, len(a.Runs))
+	for _, r := range a.Runs {
+		if r == nil || r.ID == uuid.Nil {
+			return fmt.Errorf("%w: run without an ID", ErrInvalidArchive)
+		}
+		if !procedures[r.TestProcedureID] {
+			return fmt.Errorf("%w: run %s has unknown procedure %s", ErrInvalidArchive, r.ID, r.TestProcedureID)
+		}
+		if !r.Status.IsValid() {
+			return fmt.Errorf("%w: run %s: %w", ErrInvalidArchive, r.ID, testrun.ErrInvalidStatus)
+		}
+		runs[r.ID] = true
+	}
+
+	for _, n := range a.StepNotes {
+		if n == nil || !runs[n.TestRunID] {
+			return fmt.Errorf("%w: step note for unknown run", ErrInvalidArchive)
+		}
+	}
+	assets := make(map[uuid.UUID]bool, len(a.Assets))
+	for _, asset := range a.Assets {
+		if asset == nil || !runs[asset.TestRunID] {
+			return fmt.Errorf("%w: asset for unknown run", ErrInvalidArchive)
+		}
+		if err := asset.Validate(); err != nil {
+			return fmt.Errorf("%w: asset %s: %w", ErrInvalidArchive, asset.ID, err)
+		}
+		assets[asset.ID] = true
+	}
+	for _, annotation := range a.Annotations {
+		if annotation == nil || !assets[annotation.AssetID] {
+			return fmt.Errorf("%w: annotation for unknown asset", ErrInvalidArchive)
+		}
+		if err := annotation.Validate(); err != nil {
+			return fmt.Errorf("%w: annotation %s: %w", ErrInvalidArchive, annotation.ID, err)
+		}
+	}
+	return nil
+}
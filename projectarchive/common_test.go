@@ -0,0 +1,122 @@
+package projectarchive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and project archive store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db,
+		&user.User{},
+		&project.Project{},
+		&testprocedure.TestProcedure{},
+		&testprocedure.DraftSnapshot{},
+		&testrun.TestRun{},
+		&testrun.TestRunAsset{},
+		&testrun.StepNote{},
+		&testrun.AssetAnnotation{},
+	)
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestUser creates a user and returns its ID.
+func createTestUser(t *testing.T, db *gorm.DB, email string) uuid.UUID {
+	u := &user.User{Email: email, Username: email, PasswordHash: "hash", IsActive: true}
+	require.NoError(t, db.Create(u).Error)
+	return u.ID
+}
+
+// seedProject creates a project owned by ownerID with one procedure that has
+// two committed versions and a draft, and one completed run of the second
+// version with a step note, an annotated asset attached to it and a video
+// with a thumbnail.
+func seedProject(t *testing.T, db *gorm.DB, ownerID uuid.UUID) (*project.Project, *testrun.TestRun) {
+	ctx := context.Background()
+	log := logger.NewTestLogger()
+
+	proj := &project.Project{Name: "Checkout", Description: "Checkout flows", OwnerID: ownerID, IsActive: true}
+	require.NoError(t, project.NewMySQLStore(db, log).Create(ctx, proj))
+
+	procedureStore := testprocedure.NewMySQLStore(db, log)
+	tp := &testprocedure.TestProcedure{
+		ProjectID: proj.ID,
+		Name:      "Pay by card",
+		Steps:     testprocedure.Steps{{Name: "Open cart", ImagePaths: []string{"test-procedures/x/steps/cart.png"}}},
+		CreatedBy: ownerID,
+	}
+	v1, err := procedureStore.CreateWithDraft(ctx, tp)
+	require.NoError(t, err)
+	require.NoError(t, procedureStore.UpdateDraft(ctx, v1.ID, testprocedure.SetSteps(testprocedure.Steps{
+		{Name: "Open cart", ImagePaths: []string{"test-procedures/x/steps/cart.png"}},
+		{Name: "Pay"},
+	})))
+	v2, err := procedureStore.CommitDraft(ctx, v1.ID, testprocedure.CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+
+	endpointID := uuid.New()
+	runStore := testrun.NewMySQLStore(db, log)
+	run := &testrun.TestRun{
+		TestProcedureID: v2.ID,
+		ExecutedBy:      ownerID,
+		Status:          testrun.StatusPending,
+		EndpointID:      &endpointID,
+		Environment:     "staging",
+		BaseURL:         "https://staging.example.com",
+	}
+	require.NoError(t, runStore.Create(ctx, run))
+	require.NoError(t, runStore.Start(ctx, run.ID, testrun.NewProcedureSnapshot(v2), nil))
+	require.NoError(t, runStore.Complete(ctx, run.ID, testrun.StatusPassed, "all good"))
+
+	note := &testrun.StepNote{TestRunID: run.ID, StepIndex: 1, Notes: "paid"}
+	require.NoError(t, testrun.NewMySQLStepNoteStore(db, log).Upsert(ctx, note))
+	assetStore := testrun.NewMySQLAssetStore(db, log)
+	receipt := &testrun.TestRunAsset{
+		TestRunID:  run.ID,
+		AssetType:  testrun.AssetTypeImage,
+		AssetPath:  "test-runs/old/image/receipt.png",
+		FileName:   "receipt.png",
+		FileSize:   42,
+		StepIndex:  &note.StepIndex,
+		StepNoteID: &note.ID,
+	}
+	require.NoError(t, assetStore.Create(ctx, receipt))
+	require.NoError(t, testrun.NewMySQLAnnotationStore(db, log).Replace(ctx, receipt.ID, []*testrun.AssetAnnotation{
+		{Shape: testrun.AnnotationShapeRectangle, X: 1, Y: 2, Width: 30, Height: 10, CreatedBy: ownerID},
+	}))
+	video := &testrun.TestRunAsset{
+		TestRunID:        run.ID,
+		AssetType:        testrun.AssetTypeVideo,
+		AssetPath:        "test-runs/old/video/checkout.mov",
+		FileName:         "checkout.mov",
+		FileSize:         4096,
+		ProcessingStatus: testrun.ProcessingStatusCompleted,
+	}
+	require.NoError(t, assetStore.Create(ctx, video))
+	require.NoError(t, assetStore.Create(ctx, &testrun.TestRunAsset{
+		TestRunID:     run.ID,
+		AssetType:     testrun.AssetTypeImage,
+		AssetPath:     "test-runs/old/video/checkout.mov.thumbnail.jpg",
+		FileName:      "checkout_thumbnail.jpg",
+		FileSize:      128,
+		SourceAssetID: &video.ID,
+		Variant:       testrun.AssetVariantThumbnail,
+	}))
+
+	return proj, run
+}
@@ -0,0 +1,484 @@
+package projectarchive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed project archive store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Export builds an archive of the project with the given ID.
+func (s *MySQLStore) Export(ctx context.Context, projectID uuid.UUID) (*Archive, error) {
+	db := s.db.WithContext(ctx)
+
+	var proj project.Project
+	if err := db.Where("id = ? AND is_active = ?", projectID, true).First(&proj).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, project.ErrProjectNotFound
+		}
+		return nil, s.exportFailed(ctx, projectID, err)
+	}
+
+	archive := &Archive{
+		FormatVersion: FormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Project:       proj,
+		Procedures:    []*testprocedure.TestProcedure{},
+		Runs:          []*Run{},
+		StepNotes:     []*testrun.StepNote{},
+		Assets:        []*testrun.TestRunAsset{},
+		Annotations:   []*testrun.AssetAnnotation{},
+	}
+
+	if err := db.Where("project_id = ?", projectID).Order("created_at, version").Find(&archive.Procedures).Error; err != nil {
+		return nil, s.exportFailed(ctx, projectID, err)
+	}
+	if len(archive.Procedures) == 0 {
+		return archive, nil
+	}
+
+	procedureIDs := make([]uuid.UUID, len(archive.Procedures))
+	for i, p := range archive.Procedures {
+		procedureIDs[i] = p.ID
+	}
+	var runs []*testrun.TestRun
+	if err := db.Where("test_procedure_id IN ?", procedureIDs).Order("created_at").Find(&runs).Error; err != nil {
+		return nil, s.exportFailed(ctx, projectID, err)
+	}
+	if len(runs) == 0 {
+		return archive, nil
+	}
+
+	runIDs := make([]uuid.UUID, len(runs))
+	for i, r := range runs {
+		runIDs[i] = r.ID
+		archive.Runs = append(archive.Runs, &Run{TestRun: *r, ProcedureSnapshot: r.ProcedureSnapshot})
+	}
+	if err := db.Where("test_run_id IN ?", runIDs).Order("test_run_id, step_index").Find(&archive.StepNotes).Error; err != nil {
+		return nil, s.exportFailed(ctx, projectID, err)
+	}
+	if err := db.Where("test_run_id IN ?", runIDs).Order("uploaded_at").Find(&archive.Assets).Error; err != nil {
+		return nil, s.exportFailed(ctx, projectID, err)
+	}
+	if len(archive.Assets) == 0 {
+		return archive, nil
+	}
+
+	assetIDs := make([]uuid.UUID, len(archive.Assets))
+	for i, a := range archive.Assets {
+		assetIDs[i] = a.ID
+	}
+	if err := db.Where("asset_id IN ?", assetIDs).Order("asset_id, position").Find(&archive.Annotations).Error; err != nil {
+		return nil, s.exportFailed(ctx, projectID, err)
+	}
+
+	return archive, nil
+}
+
+// exportFailed logs a failed export and returns err.
+func (s *MySQLStore) exportFailed(ctx context.Context, projectID uuid.UUID, err error) error {
+	s.logger.Error(ctx, "failed to export project", map[string]interface{}{
+		"error":      err.Error(),
+		"project_id": projectID.String(),
+	})
+	return err
+}
+
+// Import creates a new project owned by ownerID from the archive in a single
+// transaction. Users referenced by the archive that do not exist in this
+// database are replaced by the owner, and runs lose their endpoint link since
+// endpoints belong to their owner; the base URL and environment are kept.
+// Archives are untrusted: asset blobs are only copied, and step images only
+// kept, when they belong to projects ownerID owns.
+func (s *MySQLStore) Import(ctx context.Context, archive *Archive, ownerID uuid.UUID, name string) (*ImportResult, error) {
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = archive.Project.Name
+	}
+
+	result := &ImportResult{AssetCopies: []AssetCopy{}}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		users, err := existingUsers(tx, archive)
+		if err != nil {
+			return fmt.Errorf("failed to look up users: %w", err)
+		}
+		userOrOwner := func(id uuid.UUID) uuid.UUID {
+			if users[id] {
+				return id
+			}
+			return ownerID
+		}
+
+		ownedAssets, err := ownedAssetPaths(tx, archive, ownerID)
+		if err != nil {
+			return fmt.Errorf("failed to look up asset owners: %w", err)
+		}
+		ownedImages, err := ownedStepImagePaths(tx, archive, ownerID)
+		if err != nil {
+			return fmt.Errorf("failed to look up step image owners: %w", err)
+		}
+
+		proj := &project.Project{
+			Name:        name,
+			Description: archive.Project.Description,
+			OwnerID:     ownerID,
+			IsActive:    true,
+		}
+		if err := proj.Validate(); err != nil {
+			return err
+		}
+		if err := tx.Create(proj).Error; err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+		result.Project = proj
+
+		procedureIDs := make(map[uuid.UUID]uuid.UUID, len(archive.Procedures))
+		for _, p := range archive.Procedures {
+			procedureIDs[p.ID] = uuid.New()
+		}
+		// Roots are created first so versions can refer to their new parent.
+		for _, roots := range []bool{true, false} {
+			for _, p := range archive.Procedures {
+				if (p.ParentID == nil) != roots {
+					continue
+				}
+				tp := *p
+				tp.ID = procedureIDs[p.ID]
+				tp.ProjectID = proj.ID
+				tp.CreatedBy = userOrOwner(p.CreatedBy)
+				tp.Steps = keepStepImages(p.Steps, ownedImages)
+				if p.ParentID != nil {
+					parentID := procedureIDs[*p.ParentID]
+					tp.ParentID = &parentID
+				}
+				// Select all columns so false and zero values are written
+				// rather than replaced by column defaults.
+				if err := tx.Select("*").Create(&tp).Error; err != nil {
+					return fmt.Errorf("failed to create procedure: %w", err)
+				}
+				result.Procedures++
+			}
+		}
+
+		runIDs := make(map[uuid.UUID]uuid.UUID, len(archive.Runs))
+		for _, r := range archive.Runs {
+			run := r.TestRun
+			run.ID = uuid.New()
+			run.TestProcedureID = procedureIDs[r.TestProcedureID]
+			run.ExecutedBy = userOrOwner(r.ExecutedBy)
+			if r.AssignedTo != nil && !users[*r.AssignedTo] {
+				run.AssignedTo = nil
+			}
+			run.EndpointID = nil
+			run.ProcedureSnapshot = remapSnapshot(r.ProcedureSnapshot, proj.ID, procedureIDs, userOrOwner)
+			if run.ProcedureSnapshot != nil {
+				run.ProcedureSnapshot.Steps = keepStepImages(run.ProcedureSnapshot.Steps, ownedImages)
+			}
+			if err := tx.Select("*").Create(&run).Error; err != nil {
+				return fmt.Errorf("failed to create run: %w", err)
+			}
+			runIDs[r.ID] = run.ID
+			result.Runs++
+		}
+
+		noteIDs := make(map[uuid.UUID]uuid.UUID, len(archive.StepNotes))
+		for _, n := range archive.StepNotes {
+			note := *n
+			note.ID = uuid.New()
+			note.TestRunID = runIDs[n.TestRunID]
+			if err := tx.Create(&note).Error; err != nil {
+				return fmt.Errorf("failed to create step note: %w", err)
+			}
+			noteIDs[n.ID] = note.ID
+			result.StepNotes++
+		}
+
+		// Uploaded assets are created before the artifacts derived from them.
+		assetIDs := make(map[uuid.UUID]uuid.UUID, len(archive.Assets))
+		for _, a := range archive.Assets {
+			assetIDs[a.ID] = uuid.New()
+		}
+		assets := make([]*testrun.TestRunAsset, 0, len(archive.Assets))
+		for _, a := range archive.Assets {
+			if !a.IsDerived() {
+				assets = append(assets, a)
+			}
+		}
+		for _, a := range archive.Assets {
+			if a.IsDerived() {
+				assets = append(assets, a)
+			}
+		}
+
+		for _, a := range assets {
+			asset := *a
+			asset.ID = assetIDs[a.ID]
+			asset.TestRunID = runIDs[a.TestRunID]
+			if a.StepNoteID != nil {
+				// Attachments to notes missing from the archive are kept as
+				// plain run assets.
+				asset.StepNoteID = nil
+				if noteID, ok := noteIDs[*a.StepNoteID]; ok {
+					asset.StepNoteID = &noteID
+				}
+			}
+			if a.SourceAssetID != nil {
+				// Artifacts derived from assets missing from the archive are
+				// kept as plain run assets.
+				asset.SourceAssetID = nil
+				asset.Variant = ""
+				if sourceID, ok := assetIDs[*a.SourceAssetID]; ok {
+					asset.SourceAssetID = &sourceID
+					asset.Variant = a.Variant
+				}
+			}
+			asset.FileName = safeFileName(a.FileName)
+			asset.AssetPath = fmt.Sprintf("test-runs/%s/%s/%s_%s", asset.TestRunID, asset.AssetType, asset.ID, asset.FileName)
+			if err := tx.Create(&asset).Error; err != nil {
+				return fmt.Errorf("failed to create asset: %w", err)
+			}
+			if ownedAssets[a.AssetPath] {
+				result.AssetCopies = append(result.AssetCopies, AssetCopy{From: a.AssetPath, To: asset.AssetPath})
+			}
+			result.Assets++
+		}
+
+		for _, a := range archive.Annotations {
+			annotation := *a
+			annotation.ID = uuid.New()
+			annotation.AssetID = assetIDs[a.AssetID]
+			annotation.CreatedBy = userOrOwner(a.CreatedBy)
+			if err := tx.Create(&annotation).Error; err != nil {
+				return fmt.Errorf("failed to create annotation: %w", err)
+			}
+			result.Annotations++
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(ctx, "failed to import project", map[string]interface{}{
+			"error":    err.Error(),
+			"owner_id": ownerID.String(),
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "project imported", map[string]interface{}{
+		"project_id": result.Project.ID.String(),
+		"procedures": result.Procedures,
+		"runs":       result.Runs,
+	})
+
+	return result, nil
+}
+
+// existingUsers returns which of the users referenced by the archive exist.
+func existingUsers(tx *gorm.DB, archive *Archive) (map[uuid.UUID]bool, error) {
+	var ids []uuid.UUID
+	for _, p := range archive.Procedures {
+		ids = append(ids, p.CreatedBy)
+	}
+	for _, r := range archive.Runs {
+		ids = append(ids, r.ExecutedBy)
+		if r.AssignedTo != nil {
+			ids = append(ids, *r.AssignedTo)
+		}
+	}
+	for _, a := range archive.Annotations {
+		ids = append(ids, a.CreatedBy)
+	}
+
+	users := make(map[uuid.UUID]bool)
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	var found []uuid.UUID
+	if err := tx.Model(&user.User{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		users[id] = true
+	}
+	return users, nil
+}
+
+// ownedAssetPaths returns which of the storage paths of the archive's assets
+// belong to runs in projects owned by ownerID.
+func ownedAssetPaths(tx *gorm.DB, archive *Archive, ownerID uuid.UUID) (map[string]bool, error) {
+	owned := make(map[string]bool)
+	if len(archive.Assets) == 0 {
+		return owned, nil
+	}
+
+	paths := make([]string, len(archive.Assets))
+	for i, a := range archive.Assets {
+		paths[i] = a.AssetPath
+	}
+
+	var found []string
+	err := tx.Model(&testrun.TestRunAsset{}).
+		Joins("JOIN test_runs ON test_runs.id = test_run_assets.test_run_id").
+		Joins("JOIN test_procedures ON test_procedures.id = test_runs.test_procedure_id").
+		Joins("JOIN projects ON projects.id = test_procedures.project_id").
+		Where("projects.owner_id = ? AND test_run_assets.asset_path IN ?", ownerID, paths).
+		Pluck("test_run_assets.asset_path", &found).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range found {
+		owned[path] = true
+	}
+	return owned, nil
+}
+
+// ownedStepImagePaths returns which of the step image and attachment paths
+// referenced by the archive's procedures and snapshots were uploaded to
+// procedures in projects owned by ownerID.
+func ownedStepImagePaths(tx *gorm.DB, archive *Archive, ownerID uuid.UUID) (map[string]bool, error) {
+	byProcedure := make(map[uuid.UUID][]string)
+	collect := func(steps testprocedure.Steps) {
+		for _, step := range steps {
+			for _, path := range step.ImagePaths {
+				if id, ok := stepImageProcedure(path); ok {
+					byProcedure[id] = append(byProcedure[id], path)
+				}
+			}
+			for _, attachment := range step.Attachments {
+				if id, ok := testprocedure.AttachmentProcedure(attachment.Path); ok {
+					byProcedure[id] = append(byProcedure[id], attachment.Path)
+				}
+			}
+		}
+	}
+	for _, p := range archive.Procedures {
+		collect(p.Steps)
+	}
+	for _, r := range archive.Runs {
+		if r.ProcedureSnapshot != nil {
+			collect(r.ProcedureSnapshot.Steps)
+		}
+	}
+
+	owned := make(map[string]bool)
+	if len(byProcedure) == 0 {
+		return owned, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(byProcedure))
+	for id := range byProcedure {
+		ids = append(ids, id)
+	}
+	var found []uuid.UUID
+	err := tx.Model(&testprocedure.TestProcedure{}).
+		Joins("JOIN projects ON projects.id = test_procedures.project_id").
+		Where("projects.owner_id = ? AND test_procedures.id IN ?", ownerID, ids).
+		Pluck("test_procedures.id", &found).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		for _, path := range byProcedure[id] {
+			owned[path] = true
+		}
+	}
+	return owned, nil
+}
+
+// stepImageProcedure returns the procedure a step image path of the form
+// test-procedures/{id}/steps/{file} was uploaded to.
+func stepImageProcedure(path string) (uuid.UUID, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[0] != "test-procedures" || parts[2] != "steps" || safeFileName(parts[3]) != parts[3] {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// keepStepImages copies steps, dropping image paths and attachments that are
+// not owned.
+func keepStepImages(steps testprocedure.Steps, owned map[string]bool) testprocedure.Steps {
+	if steps == nil {
+		return nil
+	}
+	kept := make(testprocedure.Steps, len(steps))
+	for i, step := range steps {
+		var paths []string
+		for _, path := range step.ImagePaths {
+			if owned[path] {
+				paths = append(paths, path)
+			}
+		}
+		step.ImagePaths = paths
+		var attachments []testprocedure.Attachment
+		for _, attachment := range step.Attachments {
+			if owned[attachment.Path] {
+				attachments = append(attachments, attachment)
+			}
+		}
+		step.Attachments = attachments
+		kept[i] = step
+	}
+	return kept
+}
+
+// safeFileName strips any directory components from an archived file name so
+// the imported asset's storage path stays within its run.
+func safeFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "." || name == ".." || name == "/" || name == "" {
+		return "asset"
+	}
+	return name
+}
+
+// remapSnapshot copies a run's procedure snapshot, pointing it at the
+// imported project and procedure.
+func remapSnapshot(snapshot *testrun.ProcedureSnapshot, projectID uuid.UUID, procedureIDs map[uuid.UUID]uuid.UUID, userOrOwner func(uuid.UUID) uuid.UUID) *testrun.ProcedureSnapshot {
+	if snapshot == nil {
+		return nil
+	}
+	remapped := testrun.NewProcedureSnapshot(snapshot.Procedure())
+	remapped.ProjectID = projectID
+	remapped.CreatedBy = userOrOwner(snapshot.CreatedBy)
+	if id, ok := procedureIDs[snapshot.ID]; ok {
+		remapped.ID = id
+	}
+	if snapshot.ParentID != nil {
+		if id, ok := procedureIDs[*snapshot.ParentID]; ok {
+			remapped.ParentID = &id
+		}
+	}
+	return remapped
+}
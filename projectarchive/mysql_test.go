@@ -0,0 +1,272 @@
+package projectarchive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Export(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	ownerID := createTestUser(t, db, "owner@example.com")
+	proj, run := seedProject(t, db, ownerID)
+
+	t.Run("export includes every version, run, note and asset", func(t *testing.T) {
+		archive, err := store.Export(ctx, proj.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, FormatVersion, archive.FormatVersion)
+		assert.Equal(t, "Checkout", archive.Project.Name)
+		// v1, the draft and v2
+		assert.Len(t, archive.Procedures, 3)
+		require.Len(t, archive.Runs, 1)
+		assert.Equal(t, run.ID, archive.Runs[0].ID)
+		assert.Equal(t, testrun.StatusPassed, archive.Runs[0].Status)
+		require.NotNil(t, archive.Runs[0].ProcedureSnapshot)
+		assert.Len(t, archive.Runs[0].ProcedureSnapshot.Steps, 2)
+		require.Len(t, archive.StepNotes, 1)
+		assert.Equal(t, "paid", archive.StepNotes[0].Notes)
+		require.Len(t, archive.Assets, 3)
+		assert.Equal(t, "test-runs/old/image/receipt.png", assetsByName(archive)["receipt.png"].AssetPath)
+		require.Len(t, archive.Annotations, 1)
+		assert.Equal(t, assetsByName(archive)["receipt.png"].ID, archive.Annotations[0].AssetID)
+	})
+
+	t.Run("empty project", func(t *testing.T) {
+		empty := &project.Project{Name: "Empty", OwnerID: ownerID, IsActive: true}
+		require.NoError(t, db.Create(empty).Error)
+
+		archive, err := store.Export(ctx, empty.ID)
+		require.NoError(t, err)
+		assert.Empty(t, archive.Procedures)
+		assert.NotNil(t, archive.Runs)
+	})
+
+	t.Run("non-existent project returns error", func(t *testing.T) {
+		_, err := store.Export(ctx, uuid.New())
+		assert.ErrorIs(t, err, project.ErrProjectNotFound)
+	})
+}
+
+func TestMySQLStore_Import(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	ownerID := createTestUser(t, db, "owner@example.com")
+	importerID := createTestUser(t, db, "importer@example.com")
+	proj, run := seedProject(t, db, ownerID)
+
+	archive, err := store.Export(ctx, proj.ID)
+	require.NoError(t, err)
+
+	t.Run("import creates a copy with new IDs", func(t *testing.T) {
+		result, err := store.Import(ctx, archive, importerID, "Checkout copy")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, proj.ID, result.Project.ID)
+		assert.Equal(t, "Checkout copy", result.Project.Name)
+		assert.Equal(t, importerID, result.Project.OwnerID)
+		assert.Equal(t, 3, result.Procedures)
+		assert.Equal(t, 1, result.Runs)
+		assert.Equal(t, 1, result.StepNotes)
+		assert.Equal(t, 3, result.Assets)
+		assert.Equal(t, 1, result.Annotations)
+
+		copied, err := store.Export(ctx, result.Project.ID)
+		require.NoError(t, err)
+		require.Len(t, copied.Procedures, 3)
+
+		versions := make(map[uint]*testprocedure.TestProcedure)
+		for _, p := range copied.Procedures {
+			versions[p.Version] = p
+			assert.Equal(t, ownerID, p.CreatedBy, "existing users are kept")
+		}
+		require.Contains(t, versions, uint(0))
+		require.Contains(t, versions, uint(2))
+		root := versions[1]
+		assert.Nil(t, root.ParentID)
+		assert.True(t, versions[2].IsLatest)
+		assert.False(t, root.IsLatest)
+		assert.False(t, versions[0].IsLatest)
+		require.NotNil(t, versions[0].ParentID)
+		assert.Equal(t, root.ID, *versions[0].ParentID)
+
+		require.Len(t, copied.Runs, 1)
+		copiedRun := copied.Runs[0]
+		assert.NotEqual(t, run.ID, copiedRun.ID)
+		assert.Equal(t, versions[2].ID, copiedRun.TestProcedureID)
+		assert.Equal(t, testrun.StatusPassed, copiedRun.Status)
+		assert.Equal(t, "all good", copiedRun.Notes)
+		assert.Nil(t, copiedRun.EndpointID)
+		assert.Equal(t, "https://staging.example.com", copiedRun.BaseURL)
+		require.NotNil(t, copiedRun.ProcedureSnapshot)
+		assert.Equal(t, versions[2].ID, copiedRun.ProcedureSnapshot.ID)
+		assert.Equal(t, result.Project.ID, copiedRun.ProcedureSnapshot.ProjectID)
+
+		require.Len(t, copied.StepNotes, 1)
+		assert.Equal(t, copiedRun.ID, copied.StepNotes[0].TestRunID)
+
+		require.Len(t, copied.Assets, 3)
+		copiedAssets := assetsByName(copied)
+		receipt := copiedAssets["receipt.png"]
+		assert.Equal(t, copiedRun.ID, receipt.TestRunID)
+		require.NotNil(t, receipt.StepNoteID)
+		assert.Equal(t, copied.StepNotes[0].ID, *receipt.StepNoteID)
+		assert.Empty(t, result.AssetCopies, "blobs of another user's project are not copied")
+
+		thumbnail := copiedAssets["checkout_thumbnail.jpg"]
+		require.NotNil(t, thumbnail.SourceAssetID)
+		assert.Equal(t, copiedAssets["checkout.mov"].ID, *thumbnail.SourceAssetID)
+		assert.Equal(t, testrun.AssetVariantThumbnail, thumbnail.Variant)
+
+		require.Len(t, copied.Annotations, 1)
+		assert.Equal(t, receipt.ID, copied.Annotations[0].AssetID)
+		assert.Equal(t, testrun.AnnotationShapeRectangle, copied.Annotations[0].Shape)
+	})
+
+	t.Run("owner's own blobs are copied", func(t *testing.T) {
+		result, err := store.Import(ctx, archive, ownerID, "Checkout copy")
+		require.NoError(t, err)
+
+		copied, err := store.Export(ctx, result.Project.ID)
+		require.NoError(t, err)
+		receipt := assetsByName(copied)["receipt.png"]
+		require.Len(t, result.AssetCopies, 3)
+		assert.Contains(t, result.AssetCopies, AssetCopy{From: "test-runs/old/image/receipt.png", To: receipt.AssetPath})
+	})
+
+	t.Run("file names cannot escape the run directory", func(t *testing.T) {
+		hostile := *archive
+		hostile.Assets = nil
+		for _, a := range archive.Assets {
+			cp := *a
+			cp.FileName = "../../../test-runs/victim/image/" + a.FileName
+			hostile.Assets = append(hostile.Assets, &cp)
+		}
+
+		result, err := store.Import(ctx, &hostile, ownerID, "")
+		require.NoError(t, err)
+		copied, err := store.Export(ctx, result.Project.ID)
+		require.NoError(t, err)
+		for _, a := range copied.Assets {
+			assert.NotContains(t, a.FileName, "/")
+			assert.NotContains(t, a.AssetPath, "..")
+			assert.True(t, strings.HasPrefix(a.AssetPath, "test-runs/"+a.TestRunID.String()+"/"), a.AssetPath)
+		}
+	})
+
+	t.Run("only owned step images and attachments are kept", func(t *testing.T) {
+		owned := "test-procedures/" + archive.Procedures[0].ID.String() + "/steps/cart.png"
+		foreign := "test-procedures/" + uuid.New().String() + "/steps/cart.png"
+		ownedAttachment := testprocedure.AttachmentPath(archive.Procedures[0].ID, "items.csv")
+		foreignAttachment := testprocedure.AttachmentPath(uuid.New(), "items.csv")
+		withImages := *archive
+		withImages.Procedures = nil
+		for _, p := range archive.Procedures {
+			cp := *p
+			cp.Steps = testprocedure.Steps{{
+				Name:       "Open cart",
+				ImagePaths: []string{owned, foreign, "test-procedures/../test-runs/x/steps/a.png"},
+				Attachments: []testprocedure.Attachment{
+					{Path: ownedAttachment, FileName: "items.csv"},
+					{Path: foreignAttachment, FileName: "items.csv"},
+				},
+			}}
+			withImages.Procedures = append(withImages.Procedures, &cp)
+		}
+
+		imagePaths := func(importer uuid.UUID) []string {
+			result, err := store.Import(ctx, &withImages, importer, "")
+			require.NoError(t, err)
+			copied, err := store.Export(ctx, result.Project.ID)
+			require.NoError(t, err)
+			var paths []string
+			for _, p := range copied.Procedures {
+				for _, step := range p.Steps {
+					paths = append(paths, step.ImagePaths...)
+					for _, attachment := range step.Attachments {
+						paths = append(paths, attachment.Path)
+					}
+				}
+			}
+			return paths
+		}
+
+		ownerPaths := imagePaths(ownerID)
+		assert.Contains(t, ownerPaths, owned)
+		assert.NotContains(t, ownerPaths, foreign)
+		assert.Contains(t, ownerPaths, ownedAttachment)
+		assert.NotContains(t, ownerPaths, foreignAttachment)
+		assert.Empty(t, imagePaths(importerID))
+	})
+
+	t.Run("unknown users are replaced by the importer", func(t *testing.T) {
+		foreign := *archive
+		foreign.Procedures = nil
+		stranger := uuid.New()
+		for _, p := range archive.Procedures {
+			cp := *p
+			cp.CreatedBy = stranger
+			foreign.Procedures = append(foreign.Procedures, &cp)
+		}
+		foreign.Runs = nil
+		for _, r := range archive.Runs {
+			cp := *r
+			cp.ExecutedBy = stranger
+			cp.AssignedTo = &stranger
+			foreign.Runs = append(foreign.Runs, &cp)
+		}
+
+		result, err := store.Import(ctx, &foreign, importerID, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Checkout", result.Project.Name)
+
+		copied, err := store.Export(ctx, result.Project.ID)
+		require.NoError(t, err)
+		for _, p := range copied.Procedures {
+			assert.Equal(t, importerID, p.CreatedBy)
+		}
+		require.Len(t, copied.Runs, 1)
+		assert.Equal(t, importerID, copied.Runs[0].ExecutedBy)
+		assert.Nil(t, copied.Runs[0].AssignedTo)
+	})
+
+	t.Run("unsupported format is rejected", func(t *testing.T) {
+		bad := *archive
+		bad.FormatVersion = 99
+		_, err := store.Import(ctx, &bad, importerID, "")
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+
+	t.Run("dangling references are rejected", func(t *testing.T) {
+		bad := *archive
+		bad.Procedures = archive.Procedures[:0:0]
+		_, err := store.Import(ctx, &bad, importerID, "")
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("annotations of unknown assets are rejected", func(t *testing.T) {
+		bad := *archive
+		bad.Annotations = []*testrun.AssetAnnotation{
+			{AssetID: uuid.New(), Shape: testrun.AnnotationShapeText, Text: "lost"},
+		}
+		_, err := store.Import(ctx, &bad, importerID, "")
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+}
+
+// assetsByName indexes the assets of an archive by file name.
+func assetsByName(a *Archive) map[string]*testrun.TestRunAsset {
+	assets := make(map[string]*testrun.TestRunAsset, len(a.Assets))
+	for _, asset := range a.Assets {
+		assets[asset.FileName] = asset
+	}
+	return assets
+}
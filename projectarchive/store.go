@@ -0,0 +1,19 @@
+package projectarchive
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for exporting and importing whole projects.
+type Store interface {
+	// Export builds an archive of the project with the given ID.
+	Export(ctx context.Context, projectID uuid.UUID) (*Archive, error)
+
+	// Import creates a new project owned by ownerID from the archive. All
+	// records get new IDs. If name is not empty it replaces the archived
+	// project name. The caller must copy the blobs listed in the result's
+	// AssetCopies.
+	Import(ctx context.Context, archive *Archive, ownerID uuid.UUID, name string) (*ImportResult, error)
+}
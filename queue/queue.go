@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported queue types.
+const (
+	TypeRedis = "redis"
+	TypeSQS   = "sqs"
+)
+
+// ErrMessageNotFound is returned when a message is no longer in flight,
+// usually because its visibility timeout passed and it was redelivered.
+var ErrMessageNotFound = errors.New("message not in flight")
+
+// Message is a job received from a queue. It stays invisible to other
+// receivers until its visibility timeout passes, after which it is delivered
+// again unless it was deleted.
+type Message struct {
+	JobID uuid.UUID
+	// handle identifies this delivery of the message to the queue.
+	handle string
+}
+
+// Queue dispatches jobs to workers, which may run on other machines than the
+// API server. Delivery is at least once: a worker that dies while running a
+// job stops extending its message's visibility, and the job is delivered to
+// another worker.
+type Queue interface {
+	// Send enqueues a job.
+	Send(ctx context.Context, jobID uuid.UUID) error
+	// Receive waits up to the queue's wait time for a message, returning nil
+	// when none arrived. The message is hidden for the visibility timeout.
+	Receive(ctx context.Context) (*Message, error)
+	// ChangeVisibility hides msg for d from now. A d of 0 makes the message
+	// available to other receivers right away.
+	ChangeVisibility(ctx context.Context, msg *Message, d time.Duration) error
+	// Delete removes msg from the queue once its job no longer needs running.
+	Delete(ctx context.Context, msg *Message) error
+	// VisibilityTimeout returns how long received messages are hidden.
+	VisibilityTimeout() time.Duration
+	// Close releases the queue's connections.
+	Close()
+}
@@ -0,0 +1,153 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/redisclient"
+)
+
+// pollInterval is how often an empty Redis queue is polled while receiving.
+const pollInterval = 500 * time.Millisecond
+
+// receiveScript moves the in-flight jobs whose visibility timeout has passed
+// back to the ready list, then pops the next ready job and marks it in flight
+// until ARGV[2].
+const receiveScript = `
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(expired) do
+  redis.call('ZREM', KEYS[2], id)
+  redis.call('RPUSH', KEYS[1], id)
+end
+local id = redis.call('RPOP', KEYS[1])
+if not id then
+  return false
+end
+redis.call('ZADD', KEYS[2], ARGV[2], id)
+return id
+`
+
+// visibilityScript moves an in-flight job's visibility deadline to ARGV[2],
+// returning 0 when the job is no longer in flight.
+const visibilityScript = `
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+  return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`
+
+// RedisQueue is a Queue stored in Redis. Ready jobs are kept in the list
+// <key>:ready and jobs in flight in the sorted set <key>:inflight, scored by
+// the time their visibility timeout passes. Deadlines use the workers'
+// clocks, which should be kept in sync.
+type RedisQueue struct {
+	client            *redisclient.Client
+	readyKey          string
+	inflightKey       string
+	visibilityTimeout time.Duration
+	waitTime          time.Duration
+	now               func() time.Time
+}
+
+// NewRedisQueue creates a queue under key in the Redis server described by
+// cfg. Receive waits up to waitTime for a job.
+func NewRedisQueue(cfg redisclient.Config, key string, visibilityTimeout, waitTime time.Duration) (*RedisQueue, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("redis queue address cannot be empty")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("redis queue key cannot be empty")
+	}
+
+	return &RedisQueue{
+		client:            redisclient.New(cfg),
+		readyKey:          key + ":ready",
+		inflightKey:       key + ":inflight",
+		visibilityTimeout: visibilityTimeout,
+		waitTime:          waitTime,
+		now:               time.Now,
+	}, nil
+}
+
+// Send implements Queue.
+func (q *RedisQueue) Send(ctx context.Context, jobID uuid.UUID) error {
+	if _, err := q.client.Do(ctx, "LPUSH", q.readyKey, jobID.String()); err != nil {
+		return fmt.Errorf("failed to send job to redis queue: %w", err)
+	}
+	return nil
+}
+
+// Receive implements Queue.
+func (q *RedisQueue) Receive(ctx context.Context) (*Message, error) {
+	waitUntil := q.now().Add(q.waitTime)
+	for {
+		now := q.now()
+		reply, err := q.client.Do(ctx, "EVAL", receiveScript, "2", q.readyKey, q.inflightKey,
+			millis(now), millis(now.Add(q.visibilityTimeout)))
+		if err == nil {
+			id, ok := reply.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected redis queue reply %v", reply)
+			}
+			jobID, err := uuid.Parse(id)
+			if err != nil {
+				// Drop entries that are not jobs rather than redeliver them forever.
+				q.client.Do(ctx, "ZREM", q.inflightKey, id)
+				return nil, fmt.Errorf("invalid job ID %q in redis queue", id)
+			}
+			return &Message{JobID: jobID, handle: id}, nil
+		}
+		if !errors.Is(err, redisclient.ErrNil) {
+			return nil, fmt.Errorf("failed to receive from redis queue: %w", err)
+		}
+
+		if !now.Before(waitUntil) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// ChangeVisibility implements Queue.
+func (q *RedisQueue) ChangeVisibility(ctx context.Context, msg *Message, d time.Duration) error {
+	reply, err := q.client.Do(ctx, "EVAL", visibilityScript, "1", q.inflightKey, msg.handle, millis(q.now().Add(d)))
+	if err != nil {
+		return fmt.Errorf("failed to change visibility in redis queue: %w", err)
+	}
+	if n, ok := reply.(int64); !ok || n == 0 {
+		return ErrMessageNotFound
+	}
+	return nil
+}
+
+// Delete implements Queue.
+func (q *RedisQueue) Delete(ctx context.Context, msg *Message) error {
+	if _, err := q.client.Do(ctx, "ZREM", q.inflightKey, msg.handle); err != nil {
+		return fmt.Errorf("failed to delete from redis queue: %w", err)
+	}
+	return nil
+}
+
+// VisibilityTimeout implements Queue.
+func (q *RedisQueue) VisibilityTimeout() time.Duration {
+	return q.visibilityTimeout
+}
+
+// Close implements Queue.
+func (q *RedisQueue) Close() {
+	q.client.Close()
+}
+
+// millis formats t as Unix milliseconds.
+func millis(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
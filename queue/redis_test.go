@@ -0,0 +1,235 @@
+package queue
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/redisclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is a minimal in-memory Redis server supporting the commands and
+// scripts RedisQueue uses.
+type fakeRedis struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	ready    []string
+	inflight map[string]int64
+}
+
+func startFakeRedis(t *testing.T) *fakeRedis {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeRedis{listener: l, inflight: map[string]int64{}}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		var reply string
+		switch {
+		case args[0] == "LPUSH":
+			s.ready = append([]string{args[2]}, s.ready...)
+			reply = fmt.Sprintf(":%d\r\n", len(s.ready))
+		case args[0] == "ZREM":
+			_, ok := s.inflight[args[2]]
+			delete(s.inflight, args[2])
+			reply = ":0\r\n"
+			if ok {
+				reply = ":1\r\n"
+			}
+		case args[0] == "EVAL" && args[1] == receiveScript:
+			now, _ := strconv.ParseInt(args[5], 10, 64)
+			deadline, _ := strconv.ParseInt(args[6], 10, 64)
+			for id, score := range s.inflight {
+				if score <= now {
+					delete(s.inflight, id)
+					s.ready = append(s.ready, id)
+				}
+			}
+			reply = "$-1\r\n"
+			if n := len(s.ready); n > 0 {
+				id := s.ready[n-1]
+				s.ready = s.ready[:n-1]
+				s.inflight[id] = deadline
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(id), id)
+			}
+		case args[0] == "EVAL" && args[1] == visibilityScript:
+			reply = ":0\r\n"
+			if _, ok := s.inflight[args[4]]; ok {
+				s.inflight[args[4]], _ = strconv.ParseInt(args[5], 10, 64)
+				reply = ":1\r\n"
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func newTestRedisQueue(t *testing.T, server *fakeRedis, now *time.Time) *RedisQueue {
+	q, err := NewRedisQueue(redisclient.Config{Addr: server.listener.Addr().String()}, "jobs", time.Minute, 0)
+	require.NoError(t, err)
+	q.now = func() time.Time { return *now }
+	t.Cleanup(q.Close)
+	return q
+}
+
+func TestRedisQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers jobs in order", func(t *testing.T) {
+		now := time.Now()
+		q := newTestRedisQueue(t, startFakeRedis(t), &now)
+		first, second := uuid.New(), uuid.New()
+		require.NoError(t, q.Send(ctx, first))
+		require.NoError(t, q.Send(ctx, second))
+
+		msg, err := q.Receive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, msg)
+		assert.Equal(t, first, msg.JobID)
+		require.NoError(t, q.Delete(ctx, msg))
+
+		msg, err = q.Receive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, msg)
+		assert.Equal(t, second, msg.JobID)
+
+		msg, err = q.Receive(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, msg)
+	})
+
+	t.Run("redelivers after the visibility timeout", func(t *testing.T) {
+		now := time.Now()
+		q := newTestRedisQueue(t, startFakeRedis(t), &now)
+		jobID := uuid.New()
+		require.NoError(t, q.Send(ctx, jobID))
+
+		msg, err := q.Receive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, msg)
+
+		// Extending the visibility keeps the message hidden.
+		now = now.Add(50 * time.Second)
+		require.NoError(t, q.ChangeVisibility(ctx, msg, time.Minute))
+		now = now.Add(50 * time.Second)
+		again, err := q.Receive(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, again)
+
+		now = now.Add(20 * time.Second)
+		again, err = q.Receive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, again)
+		assert.Equal(t, jobID, again.JobID)
+	})
+
+	t.Run("zero visibility releases the message", func(t *testing.T) {
+		now := time.Now()
+		q := newTestRedisQueue(t, startFakeRedis(t), &now)
+		jobID := uuid.New()
+		require.NoError(t, q.Send(ctx, jobID))
+
+		msg, err := q.Receive(ctx)
+		require.NoError(t, err)
+		require.NoError(t, q.ChangeVisibility(ctx, msg, 0))
+
+		again, err := q.Receive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, again)
+		assert.Equal(t, jobID, again.JobID)
+	})
+
+	t.Run("deleted message is not found", func(t *testing.T) {
+		now := time.Now()
+		q := newTestRedisQueue(t, startFakeRedis(t), &now)
+		require.NoError(t, q.Send(ctx, uuid.New()))
+
+		msg, err := q.Receive(ctx)
+		require.NoError(t, err)
+		require.NoError(t, q.Delete(ctx, msg))
+		assert.ErrorIs(t, q.ChangeVisibility(ctx, msg, time.Minute), ErrMessageNotFound)
+	})
+
+	t.Run("receive waits for a job", func(t *testing.T) {
+		server := startFakeRedis(t)
+		q, err := NewRedisQueue(redisclient.Config{Addr: server.listener.Addr().String()}, "jobs", time.Minute, 5*time.Second)
+		require.NoError(t, err)
+		defer q.Close()
+
+		jobID := uuid.New()
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			q.Send(ctx, jobID)
+		}()
+		msg, err := q.Receive(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, msg)
+		assert.Equal(t, jobID, msg.JobID)
+	})
+}
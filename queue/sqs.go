@@ -0,0 +1,182 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/google/uuid"
+)
+
+// MaxSQSWaitTime is the longest long poll SQS allows.
+const MaxSQSWaitTime = 20 * time.Second
+
+// SQSQueue is a Queue backed by an Amazon SQS standard queue. It uses AWS
+// SDK v2's default credential chain (IAM role on EC2) to sign requests.
+type SQSQueue struct {
+	httpClient        *http.Client
+	credentials       aws.CredentialsProvider
+	signer            *v4.Signer
+	region            string
+	endpoint          string
+	queueURL          string
+	visibilityTimeout time.Duration
+	waitTime          time.Duration
+}
+
+// NewSQSQueue creates a queue for the SQS queue at queueURL in region.
+// Receive long polls for up to waitTime, which SQS caps at 20 seconds.
+func NewSQSQueue(ctx context.Context, queueURL, region string, visibilityTimeout, waitTime time.Duration) (*SQSQueue, error) {
+	if queueURL == "" {
+		return nil, fmt.Errorf("sqs queue URL cannot be empty")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("sqs region cannot be empty")
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &SQSQueue{
+		httpClient:        &http.Client{Timeout: MaxSQSWaitTime + 10*time.Second},
+		credentials:       cfg.Credentials,
+		signer:            v4.NewSigner(),
+		region:            region,
+		endpoint:          fmt.Sprintf("https://sqs.%s.amazonaws.com", region),
+		queueURL:          queueURL,
+		visibilityTimeout: visibilityTimeout,
+		waitTime:          waitTime,
+	}, nil
+}
+
+// Send implements Queue.
+func (q *SQSQueue) Send(ctx context.Context, jobID uuid.UUID) error {
+	return q.call(ctx, "SendMessage", map[string]interface{}{
+		"QueueUrl":    q.queueURL,
+		"MessageBody": jobID.String(),
+	}, nil)
+}
+
+// Receive implements Queue.
+func (q *SQSQueue) Receive(ctx context.Context) (*Message, error) {
+	var result struct {
+		Messages []struct {
+			Body          string `json:"Body"`
+			ReceiptHandle string `json:"ReceiptHandle"`
+		} `json:"Messages"`
+	}
+	err := q.call(ctx, "ReceiveMessage", map[string]interface{}{
+		"QueueUrl":            q.queueURL,
+		"MaxNumberOfMessages": 1,
+		"WaitTimeSeconds":     int(q.waitTime / time.Second),
+		"VisibilityTimeout":   seconds(q.visibilityTimeout),
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Messages) == 0 {
+		return nil, nil
+	}
+
+	m := result.Messages[0]
+	jobID, err := uuid.Parse(m.Body)
+	if err != nil {
+		// Drop messages that are not jobs rather than redeliver them forever.
+		q.Delete(ctx, &Message{handle: m.ReceiptHandle})
+		return nil, fmt.Errorf("invalid job ID %q in sqs queue", m.Body)
+	}
+	return &Message{JobID: jobID, handle: m.ReceiptHandle}, nil
+}
+
+// ChangeVisibility implements Queue.
+func (q *SQSQueue) ChangeVisibility(ctx context.Context, msg *Message, d time.Duration) error {
+	return q.call(ctx, "ChangeMessageVisibility", map[string]interface{}{
+		"QueueUrl":          q.queueURL,
+		"ReceiptHandle":     msg.handle,
+		"VisibilityTimeout": seconds(d),
+	}, nil)
+}
+
+// Delete implements Queue.
+func (q *SQSQueue) Delete(ctx context.Context, msg *Message) error {
+	return q.call(ctx, "DeleteMessage", map[string]interface{}{
+		"QueueUrl":      q.queueURL,
+		"ReceiptHandle": msg.handle,
+	}, nil)
+}
+
+// VisibilityTimeout implements Queue.
+func (q *SQSQueue) VisibilityTimeout() time.Duration {
+	return q.visibilityTimeout
+}
+
+// Close implements Queue.
+func (q *SQSQueue) Close() {}
+
+// call makes a signed request to an SQS action using the JSON protocol and
+// decodes the response into result, if not nil.
+func (q *SQSQueue) call(ctx context.Context, action string, params map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, q.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("sqs: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "AmazonSQS."+action)
+
+	creds, err := q.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("sqs: failed to get credentials: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := q.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "sqs", q.region, time.Now()); err != nil {
+		return fmt.Errorf("sqs: failed to sign request: %w", err)
+	}
+
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Type string `json:"__type"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil &&
+			(strings.HasSuffix(apiErr.Type, "MessageNotInflight") || strings.HasSuffix(apiErr.Type, "ReceiptHandleIsInvalid")) {
+			return ErrMessageNotFound
+		}
+		return fmt.Errorf("sqs: %s failed with status %d: %s", action, resp.StatusCode, string(data))
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("sqs: failed to decode %s response: %w", action, err)
+	}
+	return nil
+}
+
+// seconds rounds d up to whole seconds, the unit SQS uses for timeouts.
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQSQueue(t *testing.T) {
+	ctx := context.Background()
+	jobID := uuid.New()
+	queueURL := "https://sqs.us-east-1.amazonaws.com/123456789012/jobs"
+
+	var actions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-amz-json-1.0", r.Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, queueURL, body["QueueUrl"])
+
+		action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AmazonSQS.")
+		actions = append(actions, action)
+		switch action {
+		case "SendMessage":
+			assert.Equal(t, jobID.String(), body["MessageBody"])
+			json.NewEncoder(w).Encode(map[string]string{"MessageId": "m-1"})
+		case "ReceiveMessage":
+			assert.EqualValues(t, 120, body["VisibilityTimeout"])
+			assert.EqualValues(t, 20, body["WaitTimeSeconds"])
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"Messages": []map[string]string{{"Body": jobID.String(), "ReceiptHandle": "r-1"}},
+			})
+		case "ChangeMessageVisibility":
+			if body["ReceiptHandle"] != "r-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"__type":"com.amazonaws.sqs#MessageNotInflight","message":"not in flight"}`))
+				return
+			}
+			assert.EqualValues(t, 30, body["VisibilityTimeout"])
+			w.Write([]byte(`{}`))
+		case "DeleteMessage":
+			assert.Equal(t, "r-1", body["ReceiptHandle"])
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	q := &SQSQueue{
+		httpClient: server.Client(),
+		credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+		}),
+		signer:            v4.NewSigner(),
+		region:            "us-east-1",
+		endpoint:          server.URL,
+		queueURL:          queueURL,
+		visibilityTimeout: 2 * time.Minute,
+		waitTime:          MaxSQSWaitTime,
+	}
+
+	require.NoError(t, q.Send(ctx, jobID))
+
+	msg, err := q.Receive(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, msg)
+	assert.Equal(t, jobID, msg.JobID)
+
+	require.NoError(t, q.ChangeVisibility(ctx, msg, 30*time.Second))
+	assert.ErrorIs(t, q.ChangeVisibility(ctx, &Message{JobID: jobID, handle: "expired"}, 30*time.Second), ErrMessageNotFound)
+	require.NoError(t, q.Delete(ctx, msg))
+
+	assert.Equal(t, []string{"SendMessage", "ReceiveMessage", "ChangeMessageVisibility", "ChangeMessageVisibility", "DeleteMessage"}, actions)
+}
@@ -0,0 +1,75 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/scriptgen"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database with the tables whose objects are
+// counted and a storage usage store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db,
+		&testprocedure.TestProcedure{},
+		&testprocedure.StepImage{},
+		&testprocedure.StepAttachment{},
+		&testrun.TestRun{},
+		&testrun.TestRunAsset{},
+		&scriptgen.GeneratedScript{},
+	)
+
+	return db, NewMySQLStore(db, logger.NewTestLogger())
+}
+
+// seedProject creates a procedure in a new project with a run holding an
+// asset of assetBytes, a script of scriptBytes, a step image of
+// stepImageBytes and a step attachment of stepAttachmentBytes. It returns the
+// project ID.
+func seedProject(t *testing.T, db *gorm.DB, assetBytes, scriptBytes, stepImageBytes, stepAttachmentBytes int64) uuid.UUID {
+	t.Helper()
+	projectID, userID := uuid.New(), uuid.New()
+
+	procedure := &testprocedure.TestProcedure{Name: "Login", ProjectID: projectID, CreatedBy: userID}
+	require.NoError(t, db.Create(procedure).Error)
+	run := &testrun.TestRun{TestProcedureID: procedure.ID, ExecutedBy: userID, Status: testrun.StatusPending}
+	require.NoError(t, db.Create(run).Error)
+
+	require.NoError(t, db.Create(&testrun.TestRunAsset{
+		TestRunID:  run.ID,
+		AssetType:  testrun.AssetTypeVideo,
+		AssetPath:  "test-runs/" + run.ID.String() + "/video/session.mp4",
+		FileName:   "session.mp4",
+		FileSize:   assetBytes,
+		UploadedAt: time.Now(),
+	}).Error)
+	require.NoError(t, db.Create(&scriptgen.GeneratedScript{
+		TestProcedureID:  procedure.ID,
+		Framework:        scriptgen.FrameworkPlaywright,
+		ScriptPath:       "scripts/" + procedure.ID.String() + "/login.spec.ts",
+		FileName:         "login.spec.ts",
+		FileSize:         scriptBytes,
+		GenerationStatus: scriptgen.StatusCompleted,
+		GeneratedBy:      userID,
+	}).Error)
+	require.NoError(t, db.Create(&testprocedure.StepImage{
+		Path:            "test-procedures/" + procedure.ID.String() + "/steps/abc.png",
+		TestProcedureID: procedure.ID,
+		FileSize:        stepImageBytes,
+	}).Error)
+	require.NoError(t, db.Create(&testprocedure.StepAttachment{
+		Path:            testprocedure.AttachmentPath(procedure.ID, "def.csv"),
+		TestProcedureID: procedure.ID,
+		FileSize:        stepAttachmentBytes,
+	}).Error)
+
+	return projectID
+}
@@ -0,0 +1,65 @@
+package quota
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/scriptgen"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL. Usage is
+// summed from the recorded file sizes rather than the blobs themselves.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed storage usage store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// ProjectUsage totals the sizes of the run assets, generated scripts, step
+// images and step attachments of every procedure version in a project. Deleted procedures are
+// included, since their runs and files are kept.
+func (s *MySQLStore) ProjectUsage(ctx context.Context, projectID uuid.UUID) (*Usage, error) {
+	db := s.db.WithContext(ctx)
+	procedures := db.Unscoped().Model(&testprocedure.TestProcedure{}).Select("id").Where("project_id = ?", projectID)
+	runs := db.Model(&testrun.TestRun{}).Select("id").Where("test_procedure_id IN (?)", procedures)
+
+	usage := &Usage{ProjectID: projectID}
+	sums := []struct {
+		model interface{}
+		where string
+		in    *gorm.DB
+		total *int64
+	}{
+		{&testrun.TestRunAsset{}, "test_run_id IN (?)", runs, &usage.AssetBytes},
+		{&scriptgen.GeneratedScript{}, "test_procedure_id IN (?)", procedures, &usage.ScriptBytes},
+		{&testprocedure.StepImage{}, "test_procedure_id IN (?)", procedures, &usage.StepImageBytes},
+		{&testprocedure.StepAttachment{}, "test_procedure_id IN (?)", procedures, &usage.StepAttachmentBytes},
+	}
+	for _, sum := range sums {
+		err := db.Model(sum.model).
+			Select("COALESCE(SUM(file_size), 0)").
+			Where(sum.where, sum.in).
+			Scan(sum.total).Error
+		if err != nil {
+			s.logger.Error(ctx, "failed to sum project storage usage", map[string]interface{}{
+				"error":      err.Error(),
+				"project_id": projectID.String(),
+			})
+			return nil, err
+		}
+	}
+
+	usage.TotalBytes = usage.AssetBytes + usage.ScriptBytes + usage.StepImageBytes + usage.StepAttachmentBytes
+	return usage, nil
+}
@@ -0,0 +1,35 @@
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_ProjectUsage(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	projectID := seedProject(t, db, 1000, 20, 300, 4000)
+	seedProject(t, db, 5000, 5000, 5000, 5000)
+
+	t.Run("sums objects of the project", func(t *testing.T) {
+		usage, err := store.ProjectUsage(ctx, projectID)
+		require.NoError(t, err)
+		assert.Equal(t, projectID, usage.ProjectID)
+		assert.Equal(t, int64(1000), usage.AssetBytes)
+		assert.Equal(t, int64(20), usage.ScriptBytes)
+		assert.Equal(t, int64(300), usage.StepImageBytes)
+		assert.Equal(t, int64(4000), usage.StepAttachmentBytes)
+		assert.Equal(t, int64(5320), usage.TotalBytes)
+	})
+
+	t.Run("empty project uses nothing", func(t *testing.T) {
+		usage, err := store.ProjectUsage(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.Zero(t, usage.TotalBytes)
+	})
+}
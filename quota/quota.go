@@ -0,0 +1,125 @@
+// Package quota reports how much blob storage projects use and enforces a
+// per-project storage quota on uploads.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// ErrQuotaExceeded is returned when an upload would take a project over its
+// storage quota.
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// Usage is the storage used by a project, by kind of stored object.
+type Usage struct {
+	ProjectID           uuid.UUID `json:"project_id"`
+	AssetBytes          int64     `json:"asset_bytes"`
+	ScriptBytes         int64     `json:"script_bytes"`
+	StepImageBytes      int64     `json:"step_image_bytes"`
+	StepAttachmentBytes int64     `json:"step_attachment_bytes"`
+	TotalBytes          int64     `json:"total_bytes"`
+	// QuotaBytes is the project's storage quota, or 0 if it is unlimited.
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+// ExceededError describes an upload rejected by the storage quota.
+type ExceededError struct {
+	Usage          *Usage
+	RequestedBytes int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s: project uses %s of its %s quota and the upload needs %s",
+		ErrQuotaExceeded, FormatBytes(e.Usage.TotalBytes), FormatBytes(e.Usage.QuotaBytes), FormatBytes(e.RequestedBytes))
+}
+
+// Is reports whether target is ErrQuotaExceeded.
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// Enforcer checks uploads against the storage quota of projects.
+type Enforcer struct {
+	store        Store
+	projectBytes int64
+	logger       logger.Logger
+}
+
+// NewEnforcer creates an enforcer limiting every project to projectBytes of
+// storage. A projectBytes of 0 or less leaves storage unlimited.
+func NewEnforcer(store Store, projectBytes int64, log logger.Logger) *Enforcer {
+	if projectBytes < 0 {
+		projectBytes = 0
+	}
+	return &Enforcer{
+		store:        store,
+		projectBytes: projectBytes,
+		logger:       log,
+	}
+}
+
+// Usage returns the storage used by a project along with its quota.
+func (e *Enforcer) Usage(ctx context.Context, projectID uuid.UUID) (*Usage, error) {
+	usage, err := e.store.ProjectUsage(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	usage.QuotaBytes = e.projectBytes
+	return usage, nil
+}
+
+// Check returns an *ExceededError if storing incomingBytes more would take
+// the project over its quota.
+func (e *Enforcer) Check(ctx context.Context, projectID uuid.UUID, incomingBytes int64) error {
+	if e.projectBytes == 0 {
+		return nil
+	}
+
+	usage, err := e.Usage(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if usage.TotalBytes+incomingBytes > usage.QuotaBytes {
+		e.logger.Info(ctx, "upload rejected by storage quota", map[string]interface{}{
+			"project_id":      projectID.String(),
+			"used_bytes":      usage.TotalBytes,
+			"quota_bytes":     usage.QuotaBytes,
+			"requested_bytes": incomingBytes,
+		})
+		return &ExceededError{Usage: usage, RequestedBytes: incomingBytes}
+	}
+	return nil
+}
+
+// CheckNew returns an *ExceededError if a new, empty project could not store
+// incomingBytes, such as the assets of an imported project.
+func (e *Enforcer) CheckNew(ctx context.Context, incomingBytes int64) error {
+	if e.projectBytes == 0 || incomingBytes <= e.projectBytes {
+		return nil
+	}
+
+	e.logger.Info(ctx, "new project rejected by storage quota", map[string]interface{}{
+		"quota_bytes":     e.projectBytes,
+		"requested_bytes": incomingBytes,
+	})
+	return &ExceededError{Usage: &Usage{QuotaBytes: e.projectBytes}, RequestedBytes: incomingBytes}
+}
+
+// FormatBytes formats a byte count with a binary unit, such as "1.5 GB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTP"[exp])
+}
@@ -0,0 +1,91 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforcer_Check(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := seedProject(t, db, 1000, 0, 0, 0)
+
+	tests := []struct {
+		name         string
+		projectBytes int64
+		incoming     int64
+		wantExceeded bool
+	}{
+		{name: "unlimited", projectBytes: 0, incoming: 1 << 40},
+		{name: "fits", projectBytes: 2000, incoming: 1000},
+		{name: "exceeds", projectBytes: 2000, incoming: 1001, wantExceeded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enforcer := NewEnforcer(store, tt.projectBytes, logger.NewTestLogger())
+			err := enforcer.Check(ctx, projectID, tt.incoming)
+			if !tt.wantExceeded {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrQuotaExceeded)
+			var exceeded *ExceededError
+			require.True(t, errors.As(err, &exceeded))
+			assert.Equal(t, int64(1000), exceeded.Usage.TotalBytes)
+			assert.Equal(t, int64(2000), exceeded.Usage.QuotaBytes)
+			assert.Equal(t, tt.incoming, exceeded.RequestedBytes)
+			assert.Contains(t, err.Error(), "1000 B of its 2.0 KB quota")
+		})
+	}
+}
+
+func TestEnforcer_CheckNew(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	assert.NoError(t, NewEnforcer(store, 0, logger.NewTestLogger()).CheckNew(ctx, 1<<40))
+	assert.NoError(t, NewEnforcer(store, 2000, logger.NewTestLogger()).CheckNew(ctx, 2000))
+
+	err := NewEnforcer(store, 2000, logger.NewTestLogger()).CheckNew(ctx, 2001)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	var exceeded *ExceededError
+	require.True(t, errors.As(err, &exceeded))
+	assert.Zero(t, exceeded.Usage.TotalBytes)
+	assert.Equal(t, int64(2001), exceeded.RequestedBytes)
+}
+
+func TestEnforcer_Usage(t *testing.T) {
+	db, store := setupTestStore(t)
+	projectID := seedProject(t, db, 1000, 0, 0, 0)
+
+	usage, err := NewEnforcer(store, 4096, logger.NewTestLogger()).Usage(context.Background(), projectID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), usage.TotalBytes)
+	assert.Equal(t, int64(4096), usage.QuotaBytes)
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1536, want: "1.5 KB"},
+		{n: 100 << 20, want: "100.0 MB"},
+		{n: 5 << 30, want: "5.0 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatBytes(tt.n))
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package quota
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for measuring the storage used by projects.
+type Store interface {
+	// ProjectUsage totals the sizes of the run assets, generated scripts and
+	// step images of every procedure version in a project.
+	ProjectUsage(ctx context.Context, projectID uuid.UUID) (*Usage, error)
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Rule describes a token bucket: Burst requests may be made at once and the
+// bucket refills at RequestsPerMinute.
+type Rule struct {
+	RequestsPerMinute int // Sustained rate (0 or less disables limiting)
+	Burst             int // Bucket capacity (defaults to RequestsPerMinute)
+}
+
+// bucket holds the remaining tokens for a single key.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter applies a Rule independently to each key, e.g. one bucket per user
+// or per API token. Buckets are created lazily and dropped once they have
+// been idle long enough to refill completely.
+type Limiter struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	rule      Rule
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter creates a new limiter for the given rule.
+func NewLimiter(rule Rule) *Limiter {
+	return &Limiter{
+		rule:    withDefaults(rule),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// withDefaults fills in the defaults of rule.
+func withDefaults(rule Rule) Rule {
+	if rule.Burst <= 0 {
+		rule.Burst = rule.RequestsPerMinute
+	}
+	return rule
+}
+
+// Rule returns the rule the limiter enforces.
+func (l *Limiter) Rule() Rule {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rule
+}
+
+// SetRule changes the rule the limiter enforces, such as when the
+// configuration is reloaded. Existing buckets keep their tokens, capped at
+// the new burst.
+func (l *Limiter) SetRule(rule Rule) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rule = withDefaults(rule)
+	for _, b := range l.buckets {
+		b.tokens = math.Min(float64(l.rule.Burst), b.tokens)
+	}
+}
+
+// Enabled reports whether the limiter rejects any requests at all.
+func (l *Limiter) Enabled() bool {
+	return l.Rule().RequestsPerMinute > 0
+}
+
+// Allow takes a token from the bucket for key. When the bucket is empty it
+// returns false along with how long the caller should wait before retrying.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rule.RequestsPerMinute <= 0 {
+		return true, 0
+	}
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.rule.Burst), updated: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(float64(l.rule.Burst), b.tokens+l.perSecond()*now.Sub(b.updated).Seconds())
+		b.updated = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / l.perSecond() * float64(time.Second))
+	return false, wait
+}
+
+// perSecond returns the refill rate in tokens per second.
+func (l *Limiter) perSecond() float64 {
+	return float64(l.rule.RequestsPerMinute) / 60
+}
+
+// refillDuration returns how long an empty bucket takes to fill completely.
+func (l *Limiter) refillDuration() time.Duration {
+	return time.Duration(float64(l.rule.Burst) / l.perSecond() * float64(time.Second))
+}
+
+// sweep drops buckets that would be full by now, at most once per refill
+// period. Callers must hold l.mu.
+func (l *Limiter) sweep(now time.Time) {
+	idle := l.refillDuration()
+	if now.Sub(l.lastSweep) < idle {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= idle {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestLimiter returns a limiter whose clock is advanced by the returned func.
+func newTestLimiter(rule Rule) (*Limiter, func(time.Duration)) {
+	l := NewLimiter(rule)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return l, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("allows burst then rejects with retry after", func(t *testing.T) {
+		t.Parallel()
+		l, _ := newTestLimiter(Rule{RequestsPerMinute: 60, Burst: 3})
+
+		for i := 0; i < 3; i++ {
+			ok, _ := l.Allow("user:a")
+			assert.True(t, ok, "request %d should be allowed", i)
+		}
+
+		ok, retryAfter := l.Allow("user:a")
+		assert.False(t, ok)
+		assert.Equal(t, time.Second, retryAfter)
+	})
+
+	t.Run("refills over time", func(t *testing.T) {
+		t.Parallel()
+		l, advance := newTestLimiter(Rule{RequestsPerMinute: 6, Burst: 1})
+
+		ok, _ := l.Allow("user:a")
+		assert.True(t, ok)
+		ok, retryAfter := l.Allow("user:a")
+		assert.False(t, ok)
+		assert.Equal(t, 10*time.Second, retryAfter)
+
+		advance(5 * time.Second)
+		ok, retryAfter = l.Allow("user:a")
+		assert.False(t, ok)
+		assert.Equal(t, 5*time.Second, retryAfter)
+
+		advance(5 * time.Second)
+		ok, _ = l.Allow("user:a")
+		assert.True(t, ok)
+	})
+
+	t.Run("keys are independent", func(t *testing.T) {
+		t.Parallel()
+		l, _ := newTestLimiter(Rule{RequestsPerMinute: 60, Burst: 1})
+
+		ok, _ := l.Allow("user:a")
+		assert.True(t, ok)
+		ok, _ = l.Allow("user:a")
+		assert.False(t, ok)
+
+		ok, _ = l.Allow("token:b")
+		assert.True(t, ok)
+	})
+
+	t.Run("zero rate disables limiting", func(t *testing.T) {
+		t.Parallel()
+		l, _ := newTestLimiter(Rule{})
+
+		assert.False(t, l.Enabled())
+		for i := 0; i < 100; i++ {
+			ok, _ := l.Allow("user:a")
+			assert.True(t, ok)
+		}
+	})
+
+	t.Run("burst defaults to rate", func(t *testing.T) {
+		t.Parallel()
+		l := NewLimiter(Rule{RequestsPerMinute: 30})
+		assert.Equal(t, 30, l.Rule().Burst)
+	})
+}
+
+func TestLimiter_SetRule(t *testing.T) {
+	t.Parallel()
+
+	l, _ := newTestLimiter(Rule{})
+	ok, _ := l.Allow("user:a")
+	assert.True(t, ok)
+
+	l.SetRule(Rule{RequestsPerMinute: 60, Burst: 2})
+	assert.True(t, l.Enabled())
+	for i := 0; i < 2; i++ {
+		ok, _ := l.Allow("user:a")
+		assert.True(t, ok, "request %d should be allowed", i)
+	}
+	ok, _ = l.Allow("user:a")
+	assert.False(t, ok)
+
+	l.SetRule(Rule{RequestsPerMinute: 30})
+	assert.Equal(t, 30, l.Rule().Burst)
+
+	l.SetRule(Rule{})
+	assert.False(t, l.Enabled())
+	ok, _ = l.Allow("user:a")
+	assert.True(t, ok)
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	t.Parallel()
+
+	l, advance := newTestLimiter(Rule{RequestsPerMinute: 60, Burst: 10})
+
+	l.Allow("user:a")
+	l.Allow("user:b")
+	assert.Len(t, l.buckets, 2)
+
+	// After a full refill period idle buckets are dropped on the next call.
+	advance(10 * time.Second)
+	l.Allow("user:c")
+	assert.Len(t, l.buckets, 1)
+	_, ok := l.buckets["user:c"]
+	assert.True(t, ok)
+}
@@ -0,0 +1,206 @@
+package redisclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// maxIdleConns is how many idle connections are kept for reuse.
+const maxIdleConns = 8
+
+// ErrNil is returned for a Redis nil reply, i.e. a missing key.
+var ErrNil = errors.New("redis: nil")
+
+// Config holds the Redis server to connect to.
+type Config struct {
+	Addr     string
+	Password string
+	DB       int
+	// Timeout bounds connecting and each command.
+	Timeout time.Duration
+}
+
+// Client runs commands on a Redis server over a small pool of connections.
+// It speaks just enough of the Redis serialization protocol for simple,
+// integer and bulk string replies.
+type Client struct {
+	cfg  Config
+	idle chan *conn
+}
+
+// New creates a client for the Redis server described by cfg. Connections
+// are made on first use.
+func New(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+	return &Client{
+		cfg:  cfg,
+		idle: make(chan *conn, maxIdleConns),
+	}
+}
+
+// Addr returns the address of the Redis server.
+func (c *Client) Addr() string {
+	return c.cfg.Addr
+}
+
+// Do runs a command on a pooled connection. Simple and bulk strings are
+// returned as strings and integers as int64; a nil bulk string returns
+// ErrNil. Connections that fail are closed rather than reused, since their
+// stream state is unknown.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(c.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	cn.SetDeadline(deadline)
+
+	reply, err := cn.do(args...)
+	if err != nil && !errors.Is(err, ErrNil) && !IsServerError(err) {
+		cn.Close()
+		return nil, err
+	}
+
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+	return reply, err
+}
+
+// Close closes the idle connections.
+func (c *Client) Close() {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// conn returns an idle connection or dials a new one.
+func (c *Client) conn(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: c.cfg.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	cn := &conn{Conn: netConn, r: bufio.NewReader(netConn)}
+	cn.SetDeadline(time.Now().Add(c.cfg.Timeout))
+
+	if c.cfg.Password != "" {
+		if _, err := cn.do("AUTH", c.cfg.Password); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("failed to authenticate to redis: %w", err)
+		}
+	}
+	if c.cfg.DB != 0 {
+		if _, err := cn.do("SELECT", strconv.Itoa(c.cfg.DB)); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("failed to select redis database: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+// Error is an error reply from the server. The connection remains usable
+// after one.
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// IsServerError reports whether err is an error reply from the server.
+func IsServerError(err error) bool {
+	var re Error
+	return errors.As(err, &re)
+}
+
+// conn is a connection speaking the Redis serialization protocol.
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// do sends a command and reads its reply.
+func (c *conn) do(args ...string) (interface{}, error) {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.Write(buf); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply reads one non-array reply.
+func (c *conn) readReply() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line[1:])
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+// readLine reads a CRLF terminated line without the terminator.
+func (c *conn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed reply %q", line)
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,33 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and release store for testing.
+// Procedures and runs are migrated too, for reports and untagging runs.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Release{}, &testprocedure.TestProcedure{}, &testrun.TestRun{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestRelease creates a release with default values.
+func createTestRelease(name string, projectID uuid.UUID) *Release {
+	return &Release{
+		Name:      name,
+		ProjectID: projectID,
+		CreatedBy: uuid.New(),
+	}
+}
@@ -0,0 +1,207 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed release store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// isDuplicateKey reports whether err is a unique constraint violation
+// (MySQL and SQLite).
+func isDuplicateKey(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey) ||
+		strings.Contains(err.Error(), "UNIQUE constraint failed") ||
+		strings.Contains(err.Error(), "Duplicate entry")
+}
+
+// Create creates a new release in the database.
+func (s *MySQLStore) Create(ctx context.Context, release *Release) error {
+	if err := release.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(release).Error; err != nil {
+		if isDuplicateKey(err) {
+			return ErrDuplicateName
+		}
+		s.logger.Error(ctx, "failed to create release", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": release.ProjectID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "release created", map[string]interface{}{
+		"release_id": release.ID.String(),
+		"project_id": release.ProjectID.String(),
+	})
+
+	return nil
+}
+
+// GetByID retrieves a release by its ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*Release, error) {
+	var release Release
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&release).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReleaseNotFound
+		}
+		s.logger.Error(ctx, "failed to get release by ID", map[string]interface{}{
+			"error":      err.Error(),
+			"release_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &release, nil
+}
+
+// Update updates a release with the given setters.
+func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error {
+	release, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, setter := range setters {
+		if err := setter(release); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Save(release).Error; err != nil {
+		if isDuplicateKey(err) {
+			return ErrDuplicateName
+		}
+		s.logger.Error(ctx, "failed to update release", map[string]interface{}{
+			"error":      err.Error(),
+			"release_id": id.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "release updated", map[string]interface{}{
+		"release_id": id.String(),
+	})
+
+	return nil
+}
+
+// Delete deletes a release by its ID. Runs tagged with the release are kept
+// and untagged.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&testrun.TestRun{}).
+			Where("release_id = ?", id).
+			Update("release_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&Release{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReleaseNotFound
+		}
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrReleaseNotFound) {
+			return err
+		}
+		s.logger.Error(ctx, "failed to delete release", map[string]interface{}{
+			"error":      err.Error(),
+			"release_id": id.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "release deleted", map[string]interface{}{
+		"release_id": id.String(),
+	})
+
+	return nil
+}
+
+// ListByProject retrieves the releases of a project, most recently created
+// first.
+func (s *MySQLStore) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*Release, error) {
+	var releases []*Release
+	err := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("created_at DESC").
+		Find(&releases).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list releases", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// Report reports on the runs tagged with a release across the latest
+// versions of the project's procedures.
+func (s *MySQLStore) Report(ctx context.Context, release *Release) (*Report, error) {
+	var procedures []ProcedureRef
+	err := s.db.WithContext(ctx).
+		Model(&testprocedure.TestProcedure{}).
+		Select("COALESCE(parent_id, id) AS id, name").
+		Where("project_id = ? AND is_latest = ?", release.ProjectID, true).
+		Scan(&procedures).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list release procedures", map[string]interface{}{
+			"error":      err.Error(),
+			"release_id": release.ID.String(),
+		})
+		return nil, err
+	}
+
+	var runs []RunRef
+	err = s.db.WithContext(ctx).
+		Table("test_runs AS tr").
+		Select("tr.id, COALESCE(tp.parent_id, tp.id) AS procedure_id, tr.status, tr.created_at").
+		Joins("JOIN test_procedures AS tp ON tp.id = tr.test_procedure_id").
+		Where("tr.release_id = ?", release.ID).
+		Scan(&runs).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list release runs", map[string]interface{}{
+			"error":      err.Error(),
+			"release_id": release.ID.String(),
+		})
+		return nil, err
+	}
+
+	return BuildReport(release, procedures, runs), nil
+}
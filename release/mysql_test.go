@@ -0,0 +1,170 @@
+package release
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Create(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	t.Run("create release", func(t *testing.T) {
+		due := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+		rel := createTestRelease("4.2", projectID)
+		rel.DueDate = &due
+		require.NoError(t, store.Create(ctx, rel))
+		assert.NotEqual(t, uuid.Nil, rel.ID)
+
+		retrieved, err := store.GetByID(ctx, rel.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "4.2", retrieved.Name)
+		require.NotNil(t, retrieved.DueDate)
+		assert.True(t, due.Equal(*retrieved.DueDate))
+	})
+
+	t.Run("duplicate name returns error", func(t *testing.T) {
+		err := store.Create(ctx, createTestRelease("4.2", projectID))
+		assert.ErrorIs(t, err, ErrDuplicateName)
+	})
+
+	t.Run("same name in another project", func(t *testing.T) {
+		assert.NoError(t, store.Create(ctx, createTestRelease("4.2", uuid.New())))
+	})
+
+	t.Run("invalid release returns error", func(t *testing.T) {
+		err := store.Create(ctx, createTestRelease("", projectID))
+		assert.ErrorIs(t, err, ErrInvalidName)
+	})
+}
+
+func TestMySQLStore_GetByID(t *testing.T) {
+	_, store := setupTestStore(t)
+
+	_, err := store.GetByID(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrReleaseNotFound)
+}
+
+func TestMySQLStore_Update(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	rel := createTestRelease("4.2", projectID)
+	rel.DueDate = &time.Time{}
+	require.NoError(t, store.Create(ctx, rel))
+	require.NoError(t, store.Create(ctx, createTestRelease("4.3", projectID)))
+
+	t.Run("update release", func(t *testing.T) {
+		require.NoError(t, store.Update(ctx, rel.ID, SetName("4.2.1"), SetDescription("Hotfix"), ClearDueDate()))
+
+		retrieved, err := store.GetByID(ctx, rel.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "4.2.1", retrieved.Name)
+		assert.Equal(t, "Hotfix", retrieved.Description)
+		assert.Nil(t, retrieved.DueDate)
+	})
+
+	t.Run("rename to an existing name returns error", func(t *testing.T) {
+		err := store.Update(ctx, rel.ID, SetName("4.3"))
+		assert.ErrorIs(t, err, ErrDuplicateName)
+	})
+
+	t.Run("update missing release returns error", func(t *testing.T) {
+		err := store.Update(ctx, uuid.New(), SetName("5.0"))
+		assert.ErrorIs(t, err, ErrReleaseNotFound)
+	})
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	rel := createTestRelease("4.2", uuid.New())
+	require.NoError(t, store.Create(ctx, rel))
+	run := &testrun.TestRun{TestProcedureID: uuid.New(), ExecutedBy: uuid.New(), Status: testrun.StatusPassed, ReleaseID: &rel.ID}
+	require.NoError(t, db.Create(run).Error)
+
+	t.Run("delete untags runs", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, rel.ID))
+
+		_, err := store.GetByID(ctx, rel.ID)
+		assert.ErrorIs(t, err, ErrReleaseNotFound)
+
+		var retrieved testrun.TestRun
+		require.NoError(t, db.First(&retrieved, "id = ?", run.ID).Error)
+		assert.Nil(t, retrieved.ReleaseID)
+	})
+
+	t.Run("delete missing release returns error", func(t *testing.T) {
+		err := store.Delete(ctx, rel.ID)
+		assert.ErrorIs(t, err, ErrReleaseNotFound)
+	})
+}
+
+func TestMySQLStore_ListByProject(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	older := createTestRelease("4.1", projectID)
+	require.NoError(t, store.Create(ctx, older))
+	newer := createTestRelease("4.2", projectID)
+	newer.CreatedAt = older.CreatedAt.Add(time.Hour)
+	require.NoError(t, store.Create(ctx, newer))
+	require.NoError(t, store.Create(ctx, createTestRelease("4.2", uuid.New())))
+
+	releases, err := store.ListByProject(ctx, projectID)
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+	assert.Equal(t, newer.ID, releases[0].ID)
+	assert.Equal(t, older.ID, releases[1].ID)
+}
+
+func TestMySQLStore_Report(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	rel := createTestRelease("4.2", projectID)
+	require.NoError(t, store.Create(ctx, rel))
+
+	procedures := testprocedure.NewMySQLStore(db, logger.NewTestLogger())
+	login := &testprocedure.TestProcedure{Name: "Login", ProjectID: projectID, CreatedBy: userID}
+	require.NoError(t, procedures.Create(ctx, login))
+	checkout := &testprocedure.TestProcedure{Name: "Checkout", ProjectID: projectID, CreatedBy: userID}
+	require.NoError(t, procedures.Create(ctx, checkout))
+
+	// Login's second version ran against the release and passed; runs of
+	// other releases do not count.
+	loginV2, err := procedures.CommitDraft(ctx, login.ID, testprocedure.CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+
+	other := uuid.New()
+	require.NoError(t, db.Create(&testrun.TestRun{TestProcedureID: loginV2.ID, ExecutedBy: userID, Status: testrun.StatusPassed, ReleaseID: &rel.ID}).Error)
+	require.NoError(t, db.Create(&testrun.TestRun{TestProcedureID: checkout.ID, ExecutedBy: userID, Status: testrun.StatusFailed, ReleaseID: &other}).Error)
+
+	report, err := store.Report(ctx, rel)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Procedures)
+	assert.Equal(t, 1, report.Covered)
+	assert.Equal(t, 1, report.Runs)
+	require.NotNil(t, report.PassRate)
+	assert.InDelta(t, 1.0, *report.PassRate, 1e-9)
+
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, "Checkout", report.Results[0].ProcedureName)
+	assert.False(t, report.Results[0].Covered)
+	assert.Equal(t, login.ID, report.Results[1].ProcedureID)
+	assert.Equal(t, testrun.StatusPassed, report.Results[1].Status)
+}
@@ -0,0 +1,68 @@
+// Package release groups the runs of a project by the release or milestone
+// they were executed for, and reports how much of the project a release's
+// runs covered and how many of the covered procedures passed.
+package release
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxNameLength is the longest release name that can be stored.
+const MaxNameLength = 255
+
+var (
+	// ErrReleaseNotFound is returned when a release is not found.
+	ErrReleaseNotFound = errors.New("release not found")
+
+	// ErrInvalidName is returned when a release name is empty or too long.
+	ErrInvalidName = errors.New("release name must be 1-255 characters")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidCreatedBy is returned when created_by is not set.
+	ErrInvalidCreatedBy = errors.New("created_by is required")
+
+	// ErrDuplicateName is returned when a project already has a release with
+	// the same name.
+	ErrDuplicateName = errors.New("a release with this name already exists in the project")
+)
+
+// Release is a release or milestone of a project that runs can be tagged
+// with.
+type Release struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID   uuid.UUID  `json:"project_id" gorm:"type:char(36);not null;uniqueIndex:idx_releases_project_name,priority:1"`
+	Name        string     `json:"name" gorm:"type:varchar(255);not null;uniqueIndex:idx_releases_project_name,priority:2"`
+	Description string     `json:"description" gorm:"type:text"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	CreatedBy   uuid.UUID  `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new release.
+func (r *Release) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the release has valid required fields.
+func (r *Release) Validate() error {
+	if r.Name == "" || len(r.Name) > MaxNameLength {
+		return ErrInvalidName
+	}
+	if r.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if r.CreatedBy == uuid.Nil {
+		return ErrInvalidCreatedBy
+	}
+	return nil
+}
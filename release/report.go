@@ -0,0 +1,134 @@
+package release
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// ProcedureRef is a procedure of the project a release report covers. ID is
+// the first version of the procedure.
+type ProcedureRef struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// RunRef is a run tagged with a release. ProcedureID is the first version of
+// the run's procedure.
+type RunRef struct {
+	ID          uuid.UUID
+	ProcedureID uuid.UUID
+	Status      testrun.Status
+	CreatedAt   time.Time
+}
+
+// ProcedureResult is the outcome of a procedure in a release.
+type ProcedureResult struct {
+	ProcedureID   uuid.UUID `json:"procedure_id"`
+	ProcedureName string    `json:"procedure_name"`
+	// Status is the status of the latest tagged run that passed or failed,
+	// or of the latest tagged run if none has. It is empty if the procedure
+	// has no tagged runs.
+	Status testrun.Status `json:"status,omitempty"`
+	// LastRunID is the run Status was taken from.
+	LastRunID *uuid.UUID `json:"last_run_id,omitempty"`
+	Runs      int        `json:"runs"`
+	// Covered reports whether a tagged run of the procedure passed or failed.
+	Covered bool `json:"covered"`
+}
+
+// Report summarizes the runs tagged with a release across the procedures of
+// its project. Rates are omitted when there is nothing to compute them from.
+type Report struct {
+	Release *Release `json:"release"`
+	// Procedures is the number of procedures in the project, and Covered
+	// the number whose tagged runs passed or failed.
+	Procedures int `json:"procedures"`
+	Covered    int `json:"covered"`
+	// Passed and Failed count the covered procedures by their latest
+	// outcome.
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// Coverage is the share of procedures that are covered, and PassRate the
+	// share of covered procedures that passed.
+	Coverage *float64 `json:"coverage,omitempty"`
+	PassRate *float64 `json:"pass_rate,omitempty"`
+	// Runs counts the tagged runs by status.
+	Runs     int                    `json:"runs"`
+	ByStatus map[testrun.Status]int `json:"runs_by_status"`
+	Results  []*ProcedureResult     `json:"results"`
+}
+
+// BuildReport builds the report of a release from the procedures of its
+// project and the runs tagged with it. Runs of procedures that are not
+// listed, such as deleted ones, are left out. Results are ordered by
+// procedure name.
+func BuildReport(rel *Release, procedures []ProcedureRef, runs []RunRef) *Report {
+	report := &Report{
+		Release:    rel,
+		Procedures: len(procedures),
+		ByStatus:   make(map[testrun.Status]int),
+		Results:    make([]*ProcedureResult, 0, len(procedures)),
+	}
+
+	results := make(map[uuid.UUID]*ProcedureResult, len(procedures))
+	for _, p := range procedures {
+		result := &ProcedureResult{ProcedureID: p.ID, ProcedureName: p.Name}
+		results[p.ID] = result
+		report.Results = append(report.Results, result)
+	}
+
+	// Oldest first, so later runs override earlier ones.
+	sorted := make([]RunRef, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	for _, run := range sorted {
+		result, ok := results[run.ProcedureID]
+		if !ok {
+			continue
+		}
+		report.Runs++
+		report.ByStatus[run.Status]++
+		result.Runs++
+
+		decisive := run.Status == testrun.StatusPassed || run.Status == testrun.StatusFailed
+		if decisive || !result.Covered {
+			runID := run.ID
+			result.Status = run.Status
+			result.LastRunID = &runID
+			result.Covered = result.Covered || decisive
+		}
+	}
+
+	for _, result := range report.Results {
+		if !result.Covered {
+			continue
+		}
+		report.Covered++
+		if result.Status == testrun.StatusPassed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+
+	if report.Procedures > 0 {
+		coverage := float64(report.Covered) / float64(report.Procedures)
+		report.Coverage = &coverage
+	}
+	if report.Covered > 0 {
+		rate := float64(report.Passed) / float64(report.Covered)
+		report.PassRate = &rate
+	}
+
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		return report.Results[i].ProcedureName < report.Results[j].ProcedureName
+	})
+
+	return report
+}
@@ -0,0 +1,86 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildReport(t *testing.T) {
+	rel := createTestRelease("4.2", uuid.New())
+	login := ProcedureRef{ID: uuid.New(), Name: "Login"}
+	checkout := ProcedureRef{ID: uuid.New(), Name: "Checkout"}
+	search := ProcedureRef{ID: uuid.New(), Name: "Search"}
+	profile := ProcedureRef{ID: uuid.New(), Name: "Profile"}
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	run := func(proc ProcedureRef, status testrun.Status, minutes int) RunRef {
+		return RunRef{ID: uuid.New(), ProcedureID: proc.ID, Status: status, CreatedAt: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+	retest := run(login, testrun.StatusPassed, 2)
+	runs := []RunRef{
+		// Login failed, then passed on a retest; a later pending run does not
+		// replace the outcome.
+		retest,
+		run(login, testrun.StatusFailed, 1),
+		run(login, testrun.StatusPending, 3),
+		run(checkout, testrun.StatusFailed, 1),
+		// Search was only skipped, so it is not covered.
+		run(search, testrun.StatusSkipped, 1),
+		// Runs of procedures outside the project are left out.
+		run(ProcedureRef{ID: uuid.New()}, testrun.StatusPassed, 1),
+	}
+
+	report := BuildReport(rel, []ProcedureRef{login, checkout, search, profile}, runs)
+
+	assert.Equal(t, 4, report.Procedures)
+	assert.Equal(t, 2, report.Covered)
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+	require.NotNil(t, report.Coverage)
+	assert.InDelta(t, 0.5, *report.Coverage, 1e-9)
+	require.NotNil(t, report.PassRate)
+	assert.InDelta(t, 0.5, *report.PassRate, 1e-9)
+	assert.Equal(t, 5, report.Runs)
+	assert.Equal(t, map[testrun.Status]int{
+		testrun.StatusPassed:  1,
+		testrun.StatusFailed:  2,
+		testrun.StatusPending: 1,
+		testrun.StatusSkipped: 1,
+	}, report.ByStatus)
+
+	require.Len(t, report.Results, 4)
+	names := make([]string, len(report.Results))
+	for i, result := range report.Results {
+		names[i] = result.ProcedureName
+	}
+	assert.Equal(t, []string{"Checkout", "Login", "Profile", "Search"}, names)
+
+	loginResult := report.Results[1]
+	assert.Equal(t, testrun.StatusPassed, loginResult.Status)
+	assert.True(t, loginResult.Covered)
+	assert.Equal(t, 3, loginResult.Runs)
+	require.NotNil(t, loginResult.LastRunID)
+	assert.Equal(t, retest.ID, *loginResult.LastRunID)
+
+	profileResult := report.Results[2]
+	assert.Empty(t, profileResult.Status)
+	assert.False(t, profileResult.Covered)
+	assert.Nil(t, profileResult.LastRunID)
+
+	searchResult := report.Results[3]
+	assert.Equal(t, testrun.StatusSkipped, searchResult.Status)
+	assert.False(t, searchResult.Covered)
+}
+
+func TestBuildReport_Empty(t *testing.T) {
+	report := BuildReport(createTestRelease("4.2", uuid.New()), nil, nil)
+	assert.Equal(t, 0, report.Procedures)
+	assert.Nil(t, report.Coverage)
+	assert.Nil(t, report.PassRate)
+	assert.NotNil(t, report.Results)
+}
@@ -0,0 +1,38 @@
+package release
+
+import "time"
+
+// SetName returns an UpdateSetter that sets the release's name.
+func SetName(name string) UpdateSetter {
+	return func(r *Release) error {
+		if name == "" || len(name) > MaxNameLength {
+			return ErrInvalidName
+		}
+		r.Name = name
+		return nil
+	}
+}
+
+// SetDescription returns an UpdateSetter that sets the release's description.
+func SetDescription(description string) UpdateSetter {
+	return func(r *Release) error {
+		r.Description = description
+		return nil
+	}
+}
+
+// SetDueDate returns an UpdateSetter that sets the release's due date.
+func SetDueDate(dueDate time.Time) UpdateSetter {
+	return func(r *Release) error {
+		r.DueDate = &dueDate
+		return nil
+	}
+}
+
+// ClearDueDate returns an UpdateSetter that removes the release's due date.
+func ClearDueDate() UpdateSetter {
+	return func(r *Release) error {
+		r.DueDate = nil
+		return nil
+	}
+}
@@ -0,0 +1,33 @@
+package release
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for release persistence operations.
+type Store interface {
+	// Create creates a new release in the store.
+	Create(ctx context.Context, release *Release) error
+
+	// GetByID retrieves a release by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*Release, error)
+
+	// Update updates a release with the given setters.
+	Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error
+
+	// Delete deletes a release by its ID. Runs tagged with the release are
+	// kept and untagged.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListByProject retrieves the releases of a project, most recently
+	// created first.
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*Release, error)
+
+	// Report reports on the runs tagged with a release.
+	Report(ctx context.Context, release *Release) (*Report, error)
+}
+
+// UpdateSetter is a function that updates a release field.
+type UpdateSetter func(*Release) error
@@ -0,0 +1,34 @@
+package requirement
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and requirement link store for
+// testing. Procedures and runs are migrated too, for the matrix.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Link{}, &testprocedure.TestProcedure{}, &testrun.TestRun{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestLink creates a requirement link with default values.
+func createTestLink(projectID, procedureID uuid.UUID, requirement string) *Link {
+	return &Link{
+		ProjectID:   projectID,
+		ProcedureID: procedureID,
+		Requirement: requirement,
+		CreatedBy:   uuid.New(),
+	}
+}
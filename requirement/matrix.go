@@ -0,0 +1,173 @@
+package requirement
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Coverage is how well a requirement is verified by its procedures' latest
+// runs.
+type Coverage string
+
+const (
+	// CoveragePassed means the latest run of every procedure passed.
+	CoveragePassed Coverage = "passed"
+
+	// CoverageFailed means the latest run of a procedure failed.
+	CoverageFailed Coverage = "failed"
+
+	// CoverageIncomplete means a procedure has not run, or its latest run
+	// has not passed or failed yet.
+	CoverageIncomplete Coverage = "incomplete"
+
+	// CoverageUncovered means no procedure is linked to the requirement.
+	CoverageUncovered Coverage = "uncovered"
+)
+
+// ProcedureRef is a procedure of the project a matrix covers. ID is the
+// first version of the procedure.
+type ProcedureRef struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// RunRef is the latest run of a procedure. ProcedureID is the first version
+// of the run's procedure.
+type RunRef struct {
+	ID          uuid.UUID
+	ProcedureID uuid.UUID
+	Status      testrun.Status
+	CreatedAt   time.Time
+}
+
+// TracedProcedure is a procedure in the matrix with its latest run.
+type TracedProcedure struct {
+	ProcedureID     uuid.UUID      `json:"procedure_id"`
+	ProcedureName   string         `json:"procedure_name"`
+	LatestRunID     *uuid.UUID     `json:"latest_run_id,omitempty"`
+	LatestRunStatus testrun.Status `json:"latest_run_status,omitempty"`
+	LatestRunAt     *time.Time     `json:"latest_run_at,omitempty"`
+}
+
+// Trace maps a requirement to the procedures that verify it.
+type Trace struct {
+	Requirement string             `json:"requirement"`
+	Title       string             `json:"title,omitempty"`
+	URL         string             `json:"url,omitempty"`
+	Coverage    Coverage           `json:"coverage"`
+	Procedures  []*TracedProcedure `json:"procedures"`
+}
+
+// Matrix is the traceability matrix of a project: every linked requirement
+// with its procedures and their latest runs, and the procedures that verify
+// no requirement.
+type Matrix struct {
+	ProjectID          uuid.UUID          `json:"project_id"`
+	Requirements       []*Trace           `json:"requirements"`
+	UntracedProcedures []*TracedProcedure `json:"untraced_procedures"`
+	// Coverage counts the requirements by coverage.
+	Coverage map[Coverage]int `json:"coverage"`
+}
+
+// BuildMatrix builds the traceability matrix of a project from its
+// requirement links, its procedures and their latest runs. Links to
+// procedures that are not listed, such as deleted ones, are left out, so
+// their requirements can become uncovered. Requirements are ordered by
+// identifier and procedures by name.
+func BuildMatrix(projectID uuid.UUID, links []*Link, procedures []ProcedureRef, latestRuns []RunRef) *Matrix {
+	latest := make(map[uuid.UUID]RunRef, len(latestRuns))
+	for _, run := range latestRuns {
+		if current, ok := latest[run.ProcedureID]; !ok || run.CreatedAt.After(current.CreatedAt) {
+			latest[run.ProcedureID] = run
+		}
+	}
+
+	traced := make(map[uuid.UUID]*TracedProcedure, len(procedures))
+	for _, p := range procedures {
+		tp := &TracedProcedure{ProcedureID: p.ID, ProcedureName: p.Name}
+		if run, ok := latest[p.ID]; ok {
+			runID, at := run.ID, run.CreatedAt
+			tp.LatestRunID = &runID
+			tp.LatestRunStatus = run.Status
+			tp.LatestRunAt = &at
+		}
+		traced[p.ID] = tp
+	}
+
+	matrix := &Matrix{
+		ProjectID:          projectID,
+		Requirements:       []*Trace{},
+		UntracedProcedures: []*TracedProcedure{},
+		Coverage:           make(map[Coverage]int),
+	}
+
+	traces := make(map[string]*Trace)
+	linked := make(map[uuid.UUID]bool)
+	for _, link := range links {
+		trace, ok := traces[link.Requirement]
+		if !ok {
+			trace = &Trace{Requirement: link.Requirement, Procedures: []*TracedProcedure{}}
+			traces[link.Requirement] = trace
+			matrix.Requirements = append(matrix.Requirements, trace)
+		}
+		if trace.Title == "" {
+			trace.Title = link.Title
+		}
+		if trace.URL == "" {
+			trace.URL = link.URL
+		}
+		if tp, ok := traced[link.ProcedureID]; ok {
+			trace.Procedures = append(trace.Procedures, tp)
+			linked[link.ProcedureID] = true
+		}
+	}
+
+	for _, trace := range matrix.Requirements {
+		sortProcedures(trace.Procedures)
+		trace.Coverage = coverageOf(trace.Procedures)
+		matrix.Coverage[trace.Coverage]++
+	}
+	sort.SliceStable(matrix.Requirements, func(i, j int) bool {
+		return matrix.Requirements[i].Requirement < matrix.Requirements[j].Requirement
+	})
+
+	for id, tp := range traced {
+		if !linked[id] {
+			matrix.UntracedProcedures = append(matrix.UntracedProcedures, tp)
+		}
+	}
+	sortProcedures(matrix.UntracedProcedures)
+
+	return matrix
+}
+
+// coverageOf derives a requirement's coverage from its procedures.
+func coverageOf(procedures []*TracedProcedure) Coverage {
+	if len(procedures) == 0 {
+		return CoverageUncovered
+	}
+	coverage := CoveragePassed
+	for _, tp := range procedures {
+		switch tp.LatestRunStatus {
+		case testrun.StatusFailed:
+			return CoverageFailed
+		case testrun.StatusPassed:
+		default:
+			coverage = CoverageIncomplete
+		}
+	}
+	return coverage
+}
+
+// sortProcedures orders procedures by name, then ID.
+func sortProcedures(procedures []*TracedProcedure) {
+	sort.Slice(procedures, func(i, j int) bool {
+		if procedures[i].ProcedureName != procedures[j].ProcedureName {
+			return procedures[i].ProcedureName < procedures[j].ProcedureName
+		}
+		return procedures[i].ProcedureID.String() < procedures[j].ProcedureID.String()
+	})
+}
@@ -0,0 +1,78 @@
+package requirement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMatrix(t *testing.T) {
+	projectID := uuid.New()
+	login := ProcedureRef{ID: uuid.New(), Name: "Login"}
+	logout := ProcedureRef{ID: uuid.New(), Name: "Logout"}
+	checkout := ProcedureRef{ID: uuid.New(), Name: "Checkout"}
+	search := ProcedureRef{ID: uuid.New(), Name: "Search"}
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	links := []*Link{
+		createTestLink(projectID, login.ID, "REQ-1"),
+		createTestLink(projectID, logout.ID, "REQ-1"),
+		createTestLink(projectID, checkout.ID, "REQ-2"),
+		createTestLink(projectID, search.ID, "REQ-3"),
+		// REQ-4 is only linked to a deleted procedure.
+		createTestLink(projectID, uuid.New(), "REQ-4"),
+	}
+	links[2].Title = "Customers can pay by card"
+	links[2].URL = "https://tracker.example.com/REQ-2"
+
+	latestLogin := RunRef{ID: uuid.New(), ProcedureID: login.ID, Status: testrun.StatusPassed, CreatedAt: base.Add(time.Hour)}
+	runs := []RunRef{
+		latestLogin,
+		{ID: uuid.New(), ProcedureID: login.ID, Status: testrun.StatusFailed, CreatedAt: base},
+		{ID: uuid.New(), ProcedureID: logout.ID, Status: testrun.StatusPassed, CreatedAt: base},
+		{ID: uuid.New(), ProcedureID: checkout.ID, Status: testrun.StatusFailed, CreatedAt: base},
+	}
+
+	matrix := BuildMatrix(projectID, links, []ProcedureRef{login, logout, checkout, search, {ID: uuid.New(), Name: "Profile"}}, runs)
+
+	require.Len(t, matrix.Requirements, 4)
+	req1, req2, req3, req4 := matrix.Requirements[0], matrix.Requirements[1], matrix.Requirements[2], matrix.Requirements[3]
+
+	assert.Equal(t, "REQ-1", req1.Requirement)
+	assert.Equal(t, CoveragePassed, req1.Coverage)
+	require.Len(t, req1.Procedures, 2)
+	assert.Equal(t, "Login", req1.Procedures[0].ProcedureName)
+	require.NotNil(t, req1.Procedures[0].LatestRunID)
+	assert.Equal(t, latestLogin.ID, *req1.Procedures[0].LatestRunID)
+
+	assert.Equal(t, CoverageFailed, req2.Coverage)
+	assert.Equal(t, "Customers can pay by card", req2.Title)
+	assert.Equal(t, "https://tracker.example.com/REQ-2", req2.URL)
+
+	assert.Equal(t, CoverageIncomplete, req3.Coverage)
+	assert.Empty(t, req3.Procedures[0].LatestRunStatus)
+
+	assert.Equal(t, CoverageUncovered, req4.Coverage)
+	assert.Empty(t, req4.Procedures)
+
+	assert.Equal(t, map[Coverage]int{
+		CoveragePassed:     1,
+		CoverageFailed:     1,
+		CoverageIncomplete: 1,
+		CoverageUncovered:  1,
+	}, matrix.Coverage)
+
+	require.Len(t, matrix.UntracedProcedures, 1)
+	assert.Equal(t, "Profile", matrix.UntracedProcedures[0].ProcedureName)
+}
+
+func TestBuildMatrix_Empty(t *testing.T) {
+	matrix := BuildMatrix(uuid.New(), nil, nil, nil)
+	assert.NotNil(t, matrix.Requirements)
+	assert.NotNil(t, matrix.UntracedProcedures)
+	assert.Empty(t, matrix.Coverage)
+}
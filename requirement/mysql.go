@@ -0,0 +1,144 @@
+package requirement
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed requirement link store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create links a procedure to a requirement.
+func (s *MySQLStore) Create(ctx context.Context, link *Link) error {
+	if err := link.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(link).Error; err != nil {
+		// Check for duplicate key error (MySQL and SQLite)
+		if errors.Is(err, gorm.ErrDuplicatedKey) ||
+			strings.Contains(err.Error(), "UNIQUE constraint failed") ||
+			strings.Contains(err.Error(), "Duplicate entry") {
+			return ErrDuplicateLink
+		}
+		s.logger.Error(ctx, "failed to create requirement link", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": link.ProcedureID.String(),
+			"requirement":  link.Requirement,
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "requirement linked", map[string]interface{}{
+		"link_id":      link.ID.String(),
+		"procedure_id": link.ProcedureID.String(),
+		"requirement":  link.Requirement,
+	})
+
+	return nil
+}
+
+// Delete removes a link of a procedure.
+func (s *MySQLStore) Delete(ctx context.Context, procedureID, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&Link{}, "id = ? AND procedure_id = ?", id, procedureID)
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete requirement link", map[string]interface{}{
+			"error":   result.Error.Error(),
+			"link_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrLinkNotFound
+	}
+
+	return nil
+}
+
+// ListByProcedure retrieves the links of a procedure, ordered by requirement.
+func (s *MySQLStore) ListByProcedure(ctx context.Context, procedureID uuid.UUID) ([]*Link, error) {
+	var links []*Link
+	err := s.db.WithContext(ctx).
+		Where("procedure_id = ?", procedureID).
+		Order("requirement ASC").
+		Find(&links).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list requirement links", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+		})
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// Matrix builds the traceability matrix of a project from the latest
+// versions of its procedures and the latest run across all versions of
+// each.
+func (s *MySQLStore) Matrix(ctx context.Context, projectID uuid.UUID) (*Matrix, error) {
+	var links []*Link
+	if err := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Find(&links).Error; err != nil {
+		s.logger.Error(ctx, "failed to list project requirement links", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	var procedures []ProcedureRef
+	if err := s.db.WithContext(ctx).
+		Model(&testprocedure.TestProcedure{}).
+		Select("COALESCE(parent_id, id) AS id, name").
+		Where("project_id = ? AND is_latest = ?", projectID, true).
+		Scan(&procedures).Error; err != nil {
+		s.logger.Error(ctx, "failed to list traceability procedures", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	// The latest run of each procedure, across all of its versions.
+	var runs []RunRef
+	if err := s.db.WithContext(ctx).
+		Table("test_runs AS tr").
+		Select("tr.id, COALESCE(tp.parent_id, tp.id) AS procedure_id, tr.status, tr.created_at").
+		Joins("JOIN test_procedures AS tp ON tp.id = tr.test_procedure_id").
+		Where("tp.project_id = ?", projectID).
+		Where("tr.created_at = (?)", s.db.
+			Table("test_runs AS tr2").
+			Select("MAX(tr2.created_at)").
+			Joins("JOIN test_procedures AS tp2 ON tp2.id = tr2.test_procedure_id").
+			Where("COALESCE(tp2.parent_id, tp2.id) = COALESCE(tp.parent_id, tp.id)")).
+		Scan(&runs).Error; err != nil {
+		s.logger.Error(ctx, "failed to list latest runs", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	return BuildMatrix(projectID, links, procedures, runs), nil
+}
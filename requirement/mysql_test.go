@@ -0,0 +1,120 @@
+package requirement
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Create(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	procedureID := uuid.New()
+
+	t.Run("create link", func(t *testing.T) {
+		link := createTestLink(projectID, procedureID, "  REQ-12 ")
+		require.NoError(t, store.Create(ctx, link))
+		assert.NotEqual(t, uuid.Nil, link.ID)
+		assert.Equal(t, "REQ-12", link.Requirement)
+	})
+
+	t.Run("duplicate link returns error", func(t *testing.T) {
+		err := store.Create(ctx, createTestLink(projectID, procedureID, "REQ-12"))
+		assert.ErrorIs(t, err, ErrDuplicateLink)
+	})
+
+	t.Run("invalid requirement returns error", func(t *testing.T) {
+		err := store.Create(ctx, createTestLink(projectID, procedureID, " "))
+		assert.ErrorIs(t, err, ErrInvalidRequirement)
+
+		err = store.Create(ctx, createTestLink(projectID, procedureID, strings.Repeat("a", MaxRequirementLength+1)))
+		assert.ErrorIs(t, err, ErrInvalidRequirement)
+	})
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	procedureID := uuid.New()
+
+	link := createTestLink(uuid.New(), procedureID, "REQ-1")
+	require.NoError(t, store.Create(ctx, link))
+
+	t.Run("delete link of another procedure returns error", func(t *testing.T) {
+		err := store.Delete(ctx, uuid.New(), link.ID)
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+	})
+
+	t.Run("delete link", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, procedureID, link.ID))
+
+		links, err := store.ListByProcedure(ctx, procedureID)
+		require.NoError(t, err)
+		assert.Empty(t, links)
+	})
+}
+
+func TestMySQLStore_ListByProcedure(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	procedureID := uuid.New()
+
+	require.NoError(t, store.Create(ctx, createTestLink(projectID, procedureID, "REQ-2")))
+	require.NoError(t, store.Create(ctx, createTestLink(projectID, procedureID, "REQ-1")))
+	require.NoError(t, store.Create(ctx, createTestLink(projectID, uuid.New(), "REQ-3")))
+
+	links, err := store.ListByProcedure(ctx, procedureID)
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.Equal(t, "REQ-1", links[0].Requirement)
+	assert.Equal(t, "REQ-2", links[1].Requirement)
+}
+
+func TestMySQLStore_Matrix(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	procedures := testprocedure.NewMySQLStore(db, logger.NewTestLogger())
+	login := &testprocedure.TestProcedure{Name: "Login", ProjectID: projectID, CreatedBy: userID}
+	require.NoError(t, procedures.Create(ctx, login))
+	checkout := &testprocedure.TestProcedure{Name: "Checkout", ProjectID: projectID, CreatedBy: userID}
+	require.NoError(t, procedures.Create(ctx, checkout))
+	loginV2, err := procedures.CommitDraft(ctx, login.ID, testprocedure.CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Create(ctx, createTestLink(projectID, login.ID, "REQ-1")))
+	require.NoError(t, store.Create(ctx, createTestLink(projectID, checkout.ID, "REQ-1")))
+
+	// Login failed on its first version, then passed on its second.
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&testrun.TestRun{TestProcedureID: login.ID, ExecutedBy: userID, Status: testrun.StatusFailed, CreatedAt: base}).Error)
+	latest := &testrun.TestRun{TestProcedureID: loginV2.ID, ExecutedBy: userID, Status: testrun.StatusPassed, CreatedAt: base.Add(time.Hour)}
+	require.NoError(t, db.Create(latest).Error)
+	require.NoError(t, db.Create(&testrun.TestRun{TestProcedureID: checkout.ID, ExecutedBy: userID, Status: testrun.StatusPending, CreatedAt: base}).Error)
+
+	matrix, err := store.Matrix(ctx, projectID)
+	require.NoError(t, err)
+	require.Len(t, matrix.Requirements, 1)
+
+	trace := matrix.Requirements[0]
+	assert.Equal(t, CoverageIncomplete, trace.Coverage)
+	require.Len(t, trace.Procedures, 2)
+	assert.Equal(t, testrun.StatusPending, trace.Procedures[0].LatestRunStatus)
+	assert.Equal(t, login.ID, trace.Procedures[1].ProcedureID)
+	require.NotNil(t, trace.Procedures[1].LatestRunID)
+	assert.Equal(t, latest.ID, *trace.Procedures[1].LatestRunID)
+	assert.Equal(t, testrun.StatusPassed, trace.Procedures[1].LatestRunStatus)
+	assert.Empty(t, matrix.UntracedProcedures)
+}
@@ -0,0 +1,88 @@
+// Package requirement links test procedures to the requirements they
+// verify and builds the traceability matrix from requirements to
+// procedures to the status of their latest runs.
+package requirement
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxRequirementLength is the longest requirement identifier that can be
+// stored.
+const MaxRequirementLength = 255
+
+var (
+	// ErrLinkNotFound is returned when a requirement link is not found.
+	ErrLinkNotFound = errors.New("requirement link not found")
+
+	// ErrInvalidRequirement is returned when a requirement identifier is
+	// empty or too long.
+	ErrInvalidRequirement = errors.New("requirement must be 1-255 characters")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidProcedureID is returned when procedure_id is not set.
+	ErrInvalidProcedureID = errors.New("procedure_id is required")
+
+	// ErrInvalidCreatedBy is returned when created_by is not set.
+	ErrInvalidCreatedBy = errors.New("created_by is required")
+
+	// ErrDuplicateLink is returned when a procedure is already linked to a
+	// requirement.
+	ErrDuplicateLink = errors.New("procedure is already linked to this requirement")
+)
+
+// Link records that a procedure verifies a requirement. Requirements are
+// free-form identifiers, such as "REQ-12", or issues of a linked issue
+// tracker, in which case IntegrationID is set and Title and URL are copied
+// from the issue. Links are attached to the first version of the procedure,
+// so they apply to every version.
+type Link struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID     uuid.UUID  `json:"project_id" gorm:"type:char(36);not null;index:idx_procedure_requirements_project_id"`
+	ProcedureID   uuid.UUID  `json:"procedure_id" gorm:"type:char(36);not null;uniqueIndex:idx_procedure_requirements_procedure_requirement,priority:1"`
+	Requirement   string     `json:"requirement" gorm:"type:varchar(255);not null;uniqueIndex:idx_procedure_requirements_procedure_requirement,priority:2"`
+	Title         string     `json:"title,omitempty" gorm:"type:varchar(500);not null;default:''"`
+	URL           string     `json:"url,omitempty" gorm:"type:varchar(1000);not null;default:''"`
+	IntegrationID *uuid.UUID `json:"integration_id,omitempty" gorm:"type:char(36)"`
+	CreatedBy     uuid.UUID  `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (l *Link) TableName() string {
+	return "procedure_requirements"
+}
+
+// BeforeCreate hook to generate UUID before creating a new link.
+func (l *Link) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the link has valid required fields. It trims the
+// requirement identifier.
+func (l *Link) Validate() error {
+	l.Requirement = strings.TrimSpace(l.Requirement)
+	if l.Requirement == "" || len(l.Requirement) > MaxRequirementLength {
+		return ErrInvalidRequirement
+	}
+	if l.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if l.ProcedureID == uuid.Nil {
+		return ErrInvalidProcedureID
+	}
+	if l.CreatedBy == uuid.Nil {
+		return ErrInvalidCreatedBy
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package requirement
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for requirement link persistence operations.
+type Store interface {
+	// Create links a procedure to a requirement.
+	Create(ctx context.Context, link *Link) error
+
+	// Delete removes a link of a procedure.
+	Delete(ctx context.Context, procedureID, id uuid.UUID) error
+
+	// ListByProcedure retrieves the links of a procedure, ordered by
+	// requirement.
+	ListByProcedure(ctx context.Context, procedureID uuid.UUID) ([]*Link, error)
+
+	// Matrix builds the traceability matrix of a project.
+	Matrix(ctx context.Context, projectID uuid.UUID) (*Matrix, error)
+}
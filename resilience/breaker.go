@@ -0,0 +1,247 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen is returned when a call is rejected because the breaker is open.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
+
+// State is the state of a circuit breaker.
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open"
+)
+
+// Config holds the timeout and trip settings for a breaker.
+type Config struct {
+	Timeout          time.Duration // Per-call deadline (0 disables)
+	FailureThreshold int           // Consecutive failures before the breaker opens
+	OpenDuration     time.Duration // How long the breaker stays open before a trial call
+}
+
+// Status is a point-in-time snapshot of a breaker, suitable for health endpoints.
+type Status struct {
+	Name                string     `json:"name"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// Breaker guards calls to an external dependency with a per-call deadline and
+// a consecutive-failure circuit breaker. While open, calls fail immediately
+// with ErrCircuitOpen instead of tying up the caller until a timeout.
+type Breaker struct {
+	name string
+	cfg  Config
+	now  func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	lastError string
+	probing   bool
+}
+
+// NewBreaker creates a new breaker in the closed state.
+func NewBreaker(name string, cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 1
+	}
+	return &Breaker{
+		name:  name,
+		cfg:   cfg,
+		now:   time.Now,
+		state: StateClosed,
+	}
+}
+
+// Name returns the breaker name.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Execute runs fn under the breaker. fn receives a context carrying the
+// configured per-call deadline. Errors caused by the caller cancelling ctx do
+// not count as failures of the dependency.
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	callCtx := ctx
+	if b.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, b.cfg.Timeout)
+		defer cancel()
+	}
+
+	err := fn(callCtx)
+
+	// The caller gave up; this says nothing about the dependency's health.
+	if err != nil && ctx.Err() != nil {
+		b.release()
+		return err
+	}
+
+	b.record(err)
+	return err
+}
+
+// Status returns a snapshot of the breaker state.
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := Status{
+		Name:                b.name,
+		State:               b.currentState(),
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if s.State != StateClosed {
+		openedAt := b.openedAt
+		s.OpenedAt = &openedAt
+	}
+	return s
+}
+
+// State returns the current breaker state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState()
+}
+
+// currentState reports an open breaker whose cool-down has elapsed as half-open.
+// Callers must hold b.mu.
+func (b *Breaker) currentState() State {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenDuration {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.currentState() {
+	case StateOpen:
+		return ErrCircuitOpen
+	case StateHalfOpen:
+		// Only a single trial call is let through while half-open.
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+	}
+	return nil
+}
+
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if err == nil {
+		b.state = StateClosed
+		b.failures = 0
+		b.lastError = ""
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == StateHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Registry holds named breakers so that their state can be shared across
+// requests and reported by health endpoints.
+type Registry struct {
+	defaults Config
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewRegistry creates a registry whose lazily-created breakers use defaults.
+func NewRegistry(defaults Config) *Registry {
+	return &Registry{
+		defaults: defaults,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Register creates (or replaces) a breaker with a specific configuration.
+func (r *Registry) Register(name string, cfg Config) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := NewBreaker(name, cfg)
+	r.breakers[name] = b
+	return b
+}
+
+// Get returns the named breaker, creating it with the registry defaults if needed.
+func (r *Registry) Get(name string) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if b, ok := r.breakers[name]; ok {
+		return b
+	}
+	b := NewBreaker(name, r.defaults)
+	r.breakers[name] = b
+	return b
+}
+
+// Lookup returns the named breaker if it has been created.
+func (r *Registry) Lookup(name string) (*Breaker, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[name]
+	return b, ok
+}
+
+// Statuses returns snapshots of every breaker, sorted by name.
+func (r *Registry) Statuses() []Status {
+	r.mu.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		breakers = append(breakers, b)
+	}
+	r.mu.Unlock()
+
+	statuses := make([]Status, len(breakers))
+	for i, b := range breakers {
+		statuses[i] = b.Status()
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,129 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUpstream = errors.New("upstream failure")
+
+func failing(ctx context.Context) error    { return errUpstream }
+func succeeding(ctx context.Context) error { return nil }
+
+func TestBreaker_Execute(t *testing.T) {
+	t.Parallel()
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		t.Parallel()
+		b := NewBreaker("jira", Config{FailureThreshold: 2, OpenDuration: time.Minute})
+		ctx := context.Background()
+
+		assert.ErrorIs(t, b.Execute(ctx, failing), errUpstream)
+		assert.Equal(t, StateClosed, b.State())
+		assert.ErrorIs(t, b.Execute(ctx, failing), errUpstream)
+		assert.Equal(t, StateOpen, b.State())
+
+		called := false
+		err := b.Execute(ctx, func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.False(t, called)
+	})
+
+	t.Run("success resets failure count", func(t *testing.T) {
+		t.Parallel()
+		b := NewBreaker("llm", Config{FailureThreshold: 2, OpenDuration: time.Minute})
+		ctx := context.Background()
+
+		_ = b.Execute(ctx, failing)
+		require.NoError(t, b.Execute(ctx, succeeding))
+		_ = b.Execute(ctx, failing)
+		assert.Equal(t, StateClosed, b.State())
+		assert.Equal(t, 1, b.Status().ConsecutiveFailures)
+	})
+
+	t.Run("half-open trial closes on success", func(t *testing.T) {
+		t.Parallel()
+		b := NewBreaker("mcp", Config{FailureThreshold: 1, OpenDuration: time.Minute})
+		now := time.Now()
+		b.now = func() time.Time { return now }
+		ctx := context.Background()
+
+		_ = b.Execute(ctx, failing)
+		assert.Equal(t, StateOpen, b.State())
+
+		now = now.Add(2 * time.Minute)
+		assert.Equal(t, StateHalfOpen, b.State())
+		require.NoError(t, b.Execute(ctx, succeeding))
+		assert.Equal(t, StateClosed, b.State())
+	})
+
+	t.Run("half-open trial failure reopens", func(t *testing.T) {
+		t.Parallel()
+		b := NewBreaker("mcp", Config{FailureThreshold: 3, OpenDuration: time.Minute})
+		now := time.Now()
+		b.now = func() time.Time { return now }
+		ctx := context.Background()
+
+		for i := 0; i < 3; i++ {
+			_ = b.Execute(ctx, failing)
+		}
+		now = now.Add(2 * time.Minute)
+		_ = b.Execute(ctx, failing)
+		assert.Equal(t, StateOpen, b.State())
+	})
+
+	t.Run("applies per-call timeout", func(t *testing.T) {
+		t.Parallel()
+		b := NewBreaker("slow", Config{Timeout: 10 * time.Millisecond, FailureThreshold: 1, OpenDuration: time.Minute})
+
+		err := b.Execute(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, StateOpen, b.State())
+	})
+
+	t.Run("caller cancellation does not count as failure", func(t *testing.T) {
+		t.Parallel()
+		b := NewBreaker("cancel", Config{FailureThreshold: 1, OpenDuration: time.Minute})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := b.Execute(ctx, func(ctx context.Context) error {
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, StateClosed, b.State())
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry(Config{FailureThreshold: 1, OpenDuration: time.Minute})
+	_, ok := r.Lookup("b")
+	assert.False(t, ok)
+
+	b := r.Get("b")
+	assert.Same(t, b, r.Get("b"))
+	r.Register("a", Config{FailureThreshold: 5})
+	_ = b.Execute(context.Background(), failing)
+
+	statuses := r.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "a", statuses[0].Name)
+	assert.Equal(t, StateClosed, statuses[0].State)
+	assert.Equal(t, "b", statuses[1].Name)
+	assert.Equal(t, StateOpen, statuses[1].State)
+	assert.NotNil(t, statuses[1].OpenedAt)
+	assert.Equal(t, errUpstream.Error(), statuses[1].LastError)
+}
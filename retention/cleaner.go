@@ -0,0 +1,225 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// DefaultBatchSize is how many expired assets are deleted per query when
+// no batch size is configured.
+const DefaultBatchSize = 100
+
+// ErrAssetNotRemoved is returned when an expired asset is still listed after
+// it was deleted, which would otherwise make a cleanup loop forever.
+var ErrAssetNotRemoved = errors.New("expired asset still listed after deletion")
+
+// Cleaner enforces retention policies by deleting expired run assets, along
+// with their stored files, annotations and derived artifacts. In dry-run mode
+// it only reports what it would delete.
+type Cleaner struct {
+	store           Store
+	assetStore      testrun.AssetStore
+	annotationStore testrun.AnnotationStore
+	storage         storage.BlobStorage
+	recorder        *metering.Recorder
+	batchSize       int
+	dryRun          bool
+	logger          logger.Logger
+	stopCh          chan struct{}
+}
+
+// NewCleaner creates a cleaner deleting batchSize assets at a time. When
+// dryRun is set the cleanup goroutine reports expired assets but keeps them.
+func NewCleaner(store Store, assetStore testrun.AssetStore, annotationStore testrun.AnnotationStore, blobStorage storage.BlobStorage, recorder *metering.Recorder, batchSize int, dryRun bool, log logger.Logger) *Cleaner {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Cleaner{
+		store:           store,
+		assetStore:      assetStore,
+		annotationStore: annotationStore,
+		storage:         blobStorage,
+		recorder:        recorder,
+		batchSize:       batchSize,
+		dryRun:          dryRun,
+		logger:          log,
+		stopCh:          make(chan struct{}),
+	}
+}
+
+// Preview reports what the policy would delete at now without deleting
+// anything.
+func (c *Cleaner) Preview(ctx context.Context, policy *Policy, now time.Time) (*Report, error) {
+	cutoff := policy.AssetCutoff(now)
+	if !policy.Enabled() {
+		return &Report{ProjectID: policy.ProjectID, DryRun: true, Cutoff: cutoff}, nil
+	}
+
+	report, err := c.store.SummarizeExpired(ctx, policy.ProjectID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = true
+	return report, nil
+}
+
+// Apply deletes the assets the policy has expired at now. Assets deleted
+// before a failure are included in the returned report.
+func (c *Cleaner) Apply(ctx context.Context, policy *Policy, now time.Time) (*Report, error) {
+	report := &Report{ProjectID: policy.ProjectID, Cutoff: policy.AssetCutoff(now)}
+	if !policy.Enabled() {
+		return report, nil
+	}
+
+	runs := make(map[uuid.UUID]bool)
+	deleted := make(map[uuid.UUID]bool)
+	for {
+		assets, err := c.store.ListExpiredAssets(ctx, policy.ProjectID, report.Cutoff, c.batchSize)
+		if err != nil {
+			return report, err
+		}
+
+		for _, asset := range assets {
+			if deleted[asset.ID] {
+				return report, fmt.Errorf("%w: %s", ErrAssetNotRemoved, asset.ID)
+			}
+			deleted[asset.ID] = true
+
+			freed, err := c.deleteAsset(ctx, asset)
+			if err != nil {
+				return report, err
+			}
+			report.Assets++
+			report.Bytes += freed
+			runs[asset.TestRunID] = true
+			report.Runs = len(runs)
+
+			c.recorder.RecordStorage(ctx, policy.ProjectID, policy.UpdatedBy, -asset.FileSize)
+		}
+
+		if len(assets) < c.batchSize {
+			return report, nil
+		}
+	}
+}
+
+// deleteAsset deletes an asset and the artifacts derived from it, returning
+// the bytes freed. Stored files are removed on a best-effort basis once the
+// records are gone, as when assets are deleted through the API.
+func (c *Cleaner) deleteAsset(ctx context.Context, asset *testrun.TestRunAsset) (int64, error) {
+	derived, err := c.assetStore.ListDerived(ctx, asset.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.assetStore.Delete(ctx, asset.ID); err != nil && !errors.Is(err, testrun.ErrAssetNotFound) {
+		return 0, err
+	}
+	c.deleteFile(ctx, asset.AssetPath)
+
+	if err := c.annotationStore.Replace(ctx, asset.ID, nil); err != nil {
+		c.logger.Warn(ctx, "failed to delete asset annotations", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": asset.ID.String(),
+		})
+	}
+
+	freed := asset.FileSize
+	for _, d := range derived {
+		if err := c.assetStore.Delete(ctx, d.ID); err != nil && !errors.Is(err, testrun.ErrAssetNotFound) {
+			c.logger.Warn(ctx, "failed to delete derived asset record", map[string]interface{}{
+				"error":    err.Error(),
+				"asset_id": d.ID.String(),
+			})
+			continue
+		}
+		c.deleteFile(ctx, d.AssetPath)
+		freed += d.FileSize
+	}
+
+	return freed, nil
+}
+
+func (c *Cleaner) deleteFile(ctx context.Context, path string) {
+	if err := c.storage.Delete(ctx, path); err != nil {
+		c.logger.Warn(ctx, "failed to delete file from storage", map[string]interface{}{
+			"error": err.Error(),
+			"path":  path,
+		})
+	}
+}
+
+// Run enforces every enabled policy at now, or only reports what would be
+// deleted in dry-run mode. A policy that fails does not stop the others.
+func (c *Cleaner) Run(ctx context.Context, now time.Time) []*Report {
+	policies, err := c.store.ListEnabled(ctx)
+	if err != nil {
+		c.logger.Error(ctx, "failed to list retention policies for cleanup", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+
+	var reports []*Report
+	for _, policy := range policies {
+		var report *Report
+		if c.dryRun {
+			report, err = c.Preview(ctx, policy, now)
+		} else {
+			report, err = c.Apply(ctx, policy, now)
+		}
+		if err != nil {
+			c.logger.Error(ctx, "failed to enforce retention policy", map[string]interface{}{
+				"error":      err.Error(),
+				"project_id": policy.ProjectID.String(),
+			})
+		}
+		if report == nil || report.Assets == 0 {
+			continue
+		}
+
+		message := "deleted expired run assets"
+		if report.DryRun {
+			message = "run assets would be deleted by retention policy"
+		}
+		c.logger.Info(ctx, message, map[string]interface{}{
+			"project_id": report.ProjectID.String(),
+			"cutoff":     report.Cutoff.Format(time.RFC3339),
+			"assets":     report.Assets,
+			"runs":       report.Runs,
+			"bytes":      report.Bytes,
+		})
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// Start starts a background goroutine that enforces retention policies
+// every interval.
+func (c *Cleaner) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				c.Run(context.Background(), now)
+			case <-c.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the cleanup goroutine.
+func (c *Cleaner) Stop() {
+	close(c.stopCh)
+}
@@ -0,0 +1,108 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleaner_Apply(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t, 2)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	run := env.createRun(t, projectID)
+	video := env.createAsset(t, run, "session.mp4", 1000, days(100), nil)
+	thumbnail := env.createAsset(t, run, "session_thumbnail.jpg", 50, days(100), video)
+	env.createAsset(t, run, "a.png", 10, days(95), nil)
+	env.createAsset(t, env.createRun(t, projectID), "b.png", 20, days(91), nil)
+	recent := env.createAsset(t, run, "recent.png", 30, days(5), nil)
+
+	policy := &Policy{ProjectID: projectID, AssetRetentionDays: 90, UpdatedBy: uuid.New()}
+	report, err := env.cleaner.Apply(ctx, policy, time.Now())
+	require.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Equal(t, 3, report.Assets)
+	assert.Equal(t, 2, report.Runs)
+	assert.Equal(t, int64(1080), report.Bytes)
+
+	assets, err := env.assetStore.ListByTestRun(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, assets, 1)
+	assert.Equal(t, recent.ID, assets[0].ID)
+
+	for _, path := range []string{video.AssetPath, thumbnail.AssetPath} {
+		exists, err := env.storage.Exists(ctx, path)
+		require.NoError(t, err)
+		assert.False(t, exists, path)
+	}
+
+	// Run metadata is kept
+	var runs int64
+	require.NoError(t, env.db.Model(&testrun.TestRun{}).Count(&runs).Error)
+	assert.Equal(t, int64(2), runs)
+}
+
+func TestCleaner_Preview(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t, 0)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	run := env.createRun(t, projectID)
+	old := env.createAsset(t, run, "old.png", 100, days(40), nil)
+
+	t.Run("reports without deleting", func(t *testing.T) {
+		report, err := env.cleaner.Preview(ctx, &Policy{ProjectID: projectID, AssetRetentionDays: 30}, time.Now())
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.Assets)
+		assert.Equal(t, int64(100), report.Bytes)
+
+		_, err = env.assetStore.GetByID(ctx, old.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("disabled policy expires nothing", func(t *testing.T) {
+		report, err := env.cleaner.Preview(ctx, &Policy{ProjectID: projectID}, time.Now())
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Zero(t, report.Assets)
+	})
+}
+
+func TestCleaner_Run(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t, 0)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	run := env.createRun(t, projectID)
+	old := env.createAsset(t, run, "old.png", 100, days(40), nil)
+	require.NoError(t, env.store.Upsert(ctx, &Policy{ProjectID: projectID, AssetRetentionDays: 30, UpdatedBy: uuid.New()}))
+
+	t.Run("dry run keeps assets", func(t *testing.T) {
+		dryRun := NewCleaner(env.store, env.cleaner.assetStore, env.cleaner.annotationStore, env.storage, nil, 0, true, env.cleaner.logger)
+		reports := dryRun.Run(ctx, time.Now())
+		require.Len(t, reports, 1)
+		assert.True(t, reports[0].DryRun)
+
+		_, err := env.assetStore.GetByID(ctx, old.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deletes expired assets", func(t *testing.T) {
+		reports := env.cleaner.Run(ctx, time.Now())
+		require.Len(t, reports, 1)
+		assert.Equal(t, 1, reports[0].Assets)
+
+		_, err := env.assetStore.GetByID(ctx, old.ID)
+		assert.ErrorIs(t, err, testrun.ErrAssetNotFound)
+	})
+}
@@ -0,0 +1,93 @@
+package retention
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// testEnv wires a cleaner to a test database and local blob storage.
+type testEnv struct {
+	db         *gorm.DB
+	store      Store
+	assetStore testrun.AssetStore
+	storage    storage.BlobStorage
+	cleaner    *Cleaner
+}
+
+// setupTestEnv creates the stores and a cleaner deleting batchSize assets at
+// a time.
+func setupTestEnv(t *testing.T, batchSize int) *testEnv {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db,
+		&Policy{},
+		&testprocedure.TestProcedure{},
+		&testrun.TestRun{},
+		&testrun.TestRunAsset{},
+		&testrun.AssetAnnotation{},
+	)
+
+	log := logger.NewTestLogger()
+	blobStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	store := NewMySQLStore(db, log)
+	assetStore := testrun.NewMySQLAssetStore(db, log)
+	annotationStore := testrun.NewMySQLAnnotationStore(db, log)
+
+	return &testEnv{
+		db:         db,
+		store:      store,
+		assetStore: assetStore,
+		storage:    blobStorage,
+		cleaner:    NewCleaner(store, assetStore, annotationStore, blobStorage, nil, batchSize, false, log),
+	}
+}
+
+// createRun creates a run of a procedure in the project.
+func (e *testEnv) createRun(t *testing.T, projectID uuid.UUID) *testrun.TestRun {
+	t.Helper()
+	userID := uuid.New()
+
+	procedure := &testprocedure.TestProcedure{Name: "Login", ProjectID: projectID, CreatedBy: userID}
+	require.NoError(t, e.db.Create(procedure).Error)
+	run := &testrun.TestRun{TestProcedureID: procedure.ID, ExecutedBy: userID, Status: testrun.StatusPassed}
+	require.NoError(t, e.db.Create(run).Error)
+	return run
+}
+
+// createAsset uploads and records an asset of the run that was uploaded age
+// ago. A non-nil source makes it a derived artifact of source.
+func (e *testEnv) createAsset(t *testing.T, run *testrun.TestRun, fileName string, size int64, age time.Duration, source *testrun.TestRunAsset) *testrun.TestRunAsset {
+	t.Helper()
+	asset := &testrun.TestRunAsset{
+		TestRunID:  run.ID,
+		AssetType:  testrun.AssetTypeImage,
+		AssetPath:  "test-runs/" + run.ID.String() + "/image/" + fileName,
+		FileName:   fileName,
+		FileSize:   size,
+		UploadedAt: time.Now().Add(-age),
+	}
+	if source != nil {
+		asset.SourceAssetID = &source.ID
+		asset.Variant = testrun.AssetVariantThumbnail
+	}
+	require.NoError(t, e.storage.Upload(context.Background(), asset.AssetPath, strings.NewReader(fileName)))
+	require.NoError(t, e.assetStore.Create(context.Background(), asset))
+	return asset
+}
+
+// days returns n days as a duration.
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
@@ -0,0 +1,171 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed retention policy store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Get retrieves the retention policy of a project.
+func (s *MySQLStore) Get(ctx context.Context, projectID uuid.UUID) (*Policy, error) {
+	var policy Policy
+	err := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		First(&policy).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPolicyNotFound
+		}
+		s.logger.Error(ctx, "failed to get retention policy", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Upsert creates or replaces the retention policy of a project.
+func (s *MySQLStore) Upsert(ctx context.Context, policy *Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"asset_retention_days", "updated_by", "updated_at"}),
+		}).
+		Create(policy).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to save retention policy", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": policy.ProjectID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "retention policy saved", map[string]interface{}{
+		"project_id":           policy.ProjectID.String(),
+		"asset_retention_days": policy.AssetRetentionDays,
+	})
+
+	return nil
+}
+
+// ListEnabled retrieves the policies that delete anything.
+func (s *MySQLStore) ListEnabled(ctx context.Context) ([]*Policy, error) {
+	var policies []*Policy
+	err := s.db.WithContext(ctx).
+		Where("asset_retention_days > 0").
+		Find(&policies).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list retention policies", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// expiredAssets scopes a query on test_run_assets to the source assets of a
+// project uploaded before the given time, including runs of deleted
+// procedures.
+func (s *MySQLStore) expiredAssets(db *gorm.DB, projectID uuid.UUID, before time.Time) *gorm.DB {
+	procedures := db.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&testprocedure.TestProcedure{}).Select("id").Where("project_id = ?", projectID)
+	runs := db.Session(&gorm.Session{NewDB: true}).Model(&testrun.TestRun{}).Select("id").Where("test_procedure_id IN (?)", procedures)
+	return db.Model(&testrun.TestRunAsset{}).
+		Where("test_run_id IN (?)", runs).
+		Where("source_asset_id IS NULL").
+		Where("uploaded_at < ?", before)
+}
+
+// ListExpiredAssets retrieves up to limit assets of a project uploaded before
+// the given time, oldest first.
+func (s *MySQLStore) ListExpiredAssets(ctx context.Context, projectID uuid.UUID, before time.Time, limit int) ([]*testrun.TestRunAsset, error) {
+	var assets []*testrun.TestRunAsset
+	err := s.expiredAssets(s.db.WithContext(ctx), projectID, before).
+		Order("uploaded_at ASC").
+		Limit(limit).
+		Find(&assets).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list expired assets", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// SummarizeExpired reports the assets of a project uploaded before the given
+// time without deleting them.
+func (s *MySQLStore) SummarizeExpired(ctx context.Context, projectID uuid.UUID, before time.Time) (*Report, error) {
+	db := s.db.WithContext(ctx)
+
+	var counts struct {
+		Assets int
+		Runs   int
+	}
+	err := s.expiredAssets(db, projectID, before).
+		Select("COUNT(*) AS assets, COUNT(DISTINCT test_run_id) AS runs").
+		Scan(&counts).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to summarize expired assets", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	// Derived assets are deleted along with their source
+	var bytes int64
+	expired := s.expiredAssets(db, projectID, before).Select("id")
+	err = db.Model(&testrun.TestRunAsset{}).
+		Select("COALESCE(SUM(file_size), 0)").
+		Where("id IN (?) OR source_asset_id IN (?)", expired, expired).
+		Scan(&bytes).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to summarize expired assets", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	return &Report{
+		ProjectID: projectID,
+		Cutoff:    before,
+		Assets:    counts.Assets,
+		Runs:      counts.Runs,
+		Bytes:     bytes,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Upsert(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t, 0)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	_, err := env.store.Get(ctx, projectID)
+	assert.ErrorIs(t, err, ErrPolicyNotFound)
+
+	require.NoError(t, env.store.Upsert(ctx, &Policy{ProjectID: projectID, AssetRetentionDays: 90, UpdatedBy: uuid.New()}))
+	updatedBy := uuid.New()
+	require.NoError(t, env.store.Upsert(ctx, &Policy{ProjectID: projectID, AssetRetentionDays: 30, UpdatedBy: updatedBy}))
+
+	policy, err := env.store.Get(ctx, projectID)
+	require.NoError(t, err)
+	assert.Equal(t, 30, policy.AssetRetentionDays)
+	assert.Equal(t, updatedBy, policy.UpdatedBy)
+
+	err = env.store.Upsert(ctx, &Policy{ProjectID: projectID, AssetRetentionDays: -5, UpdatedBy: updatedBy})
+	assert.ErrorIs(t, err, ErrInvalidRetentionDays)
+}
+
+func TestMySQLStore_ListEnabled(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t, 0)
+	ctx := context.Background()
+
+	enabled := uuid.New()
+	require.NoError(t, env.store.Upsert(ctx, &Policy{ProjectID: enabled, AssetRetentionDays: 90, UpdatedBy: uuid.New()}))
+	require.NoError(t, env.store.Upsert(ctx, &Policy{ProjectID: uuid.New(), UpdatedBy: uuid.New()}))
+
+	policies, err := env.store.ListEnabled(ctx)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, enabled, policies[0].ProjectID)
+}
+
+func TestMySQLStore_ExpiredAssets(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t, 0)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	run := env.createRun(t, projectID)
+	oldVideo := env.createAsset(t, run, "old.mp4", 1000, days(120), nil)
+	env.createAsset(t, run, "old_thumbnail.jpg", 50, days(120), oldVideo)
+	env.createAsset(t, run, "new.png", 300, days(10), nil)
+	env.createAsset(t, env.createRun(t, uuid.New()), "other.png", 700, days(120), nil)
+
+	cutoff := time.Now().Add(-days(90))
+
+	t.Run("lists source assets of the project", func(t *testing.T) {
+		assets, err := env.store.ListExpiredAssets(ctx, projectID, cutoff, 10)
+		require.NoError(t, err)
+		require.Len(t, assets, 1)
+		assert.Equal(t, oldVideo.ID, assets[0].ID)
+	})
+
+	t.Run("summary includes derived bytes", func(t *testing.T) {
+		report, err := env.store.SummarizeExpired(ctx, projectID, cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Assets)
+		assert.Equal(t, 1, report.Runs)
+		assert.Equal(t, int64(1050), report.Bytes)
+	})
+}
@@ -0,0 +1,76 @@
+// Package retention deletes the files of old test runs according to
+// per-project retention policies. Run metadata, step notes and comments are
+// always kept; only the stored assets are removed.
+package retention
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxRetentionDays is the longest retention period that can be configured.
+const MaxRetentionDays = 3650
+
+var (
+	// ErrPolicyNotFound is returned when a project has no retention policy.
+	ErrPolicyNotFound = errors.New("retention policy not found")
+
+	// ErrInvalidRetentionDays is returned when a retention period is out of range.
+	ErrInvalidRetentionDays = errors.New("asset_retention_days must be between 0 and 3650")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+)
+
+// Policy is the retention policy of a project.
+type Policy struct {
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:char(36);primaryKey"`
+	// AssetRetentionDays is how many days run assets are kept after they
+	// are uploaded. Assets are kept forever when it is 0.
+	AssetRetentionDays int `json:"asset_retention_days" gorm:"not null;default:0"`
+	// UpdatedBy is the user who last changed the policy. Deletions made by
+	// the policy are metered against them.
+	UpdatedBy uuid.UUID `json:"updated_by" gorm:"type:char(36);not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (p *Policy) TableName() string {
+	return "project_retention_policies"
+}
+
+// Validate checks if the policy has valid required fields.
+func (p *Policy) Validate() error {
+	if p.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if p.AssetRetentionDays < 0 || p.AssetRetentionDays > MaxRetentionDays {
+		return ErrInvalidRetentionDays
+	}
+	return nil
+}
+
+// Enabled reports whether the policy deletes anything.
+func (p *Policy) Enabled() bool {
+	return p.AssetRetentionDays > 0
+}
+
+// AssetCutoff returns the time before which assets uploaded are expired at
+// now.
+func (p *Policy) AssetCutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -p.AssetRetentionDays)
+}
+
+// Report describes the assets a policy deleted, or would delete in a dry run.
+// Bytes include the thumbnails and transcodes deleted along with videos.
+type Report struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	DryRun    bool      `json:"dry_run"`
+	Cutoff    time.Time `json:"cutoff"`
+	Assets    int       `json:"assets"`
+	Runs      int       `json:"runs"`
+	Bytes     int64     `json:"bytes"`
+}
@@ -0,0 +1,45 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr error
+	}{
+		{"keeps forever", Policy{ProjectID: uuid.New()}, nil},
+		{"ninety days", Policy{ProjectID: uuid.New(), AssetRetentionDays: 90}, nil},
+		{"longest period", Policy{ProjectID: uuid.New(), AssetRetentionDays: MaxRetentionDays}, nil},
+		{"negative", Policy{ProjectID: uuid.New(), AssetRetentionDays: -1}, ErrInvalidRetentionDays},
+		{"too long", Policy{ProjectID: uuid.New(), AssetRetentionDays: MaxRetentionDays + 1}, ErrInvalidRetentionDays},
+		{"missing project", Policy{AssetRetentionDays: 30}, ErrInvalidProjectID},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.ErrorIs(t, tt.policy.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestPolicy_AssetCutoff(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+	policy := &Policy{AssetRetentionDays: 90}
+
+	assert.True(t, policy.Enabled())
+	assert.Equal(t, time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC), policy.AssetCutoff(now))
+	assert.False(t, (&Policy{}).Enabled())
+}
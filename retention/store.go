@@ -0,0 +1,30 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Store defines the interface for retention policy persistence operations.
+type Store interface {
+	// Get retrieves the retention policy of a project.
+	Get(ctx context.Context, projectID uuid.UUID) (*Policy, error)
+
+	// Upsert creates or replaces the retention policy of a project.
+	Upsert(ctx context.Context, policy *Policy) error
+
+	// ListEnabled retrieves the policies that delete anything.
+	ListEnabled(ctx context.Context) ([]*Policy, error)
+
+	// ListExpiredAssets retrieves up to limit assets of a project uploaded
+	// before the given time, oldest first. Derived assets are not listed;
+	// they expire with the asset they were derived from.
+	ListExpiredAssets(ctx context.Context, projectID uuid.UUID, before time.Time, limit int) ([]*testrun.TestRunAsset, error)
+
+	// SummarizeExpired reports the assets of a project uploaded before the
+	// given time without deleting them.
+	SummarizeExpired(ctx context.Context, projectID uuid.UUID, before time.Time) (*Report, error)
+}
@@ -0,0 +1,34 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and review store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Review{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestReview returns a review of a new procedure's second version.
+func createTestReview(reviewerID *uuid.UUID) *Review {
+	return &Review{
+		ProjectID:   uuid.New(),
+		ProcedureID: uuid.New(),
+		VersionID:   uuid.New(),
+		Version:     2,
+		RequestedBy: uuid.New(),
+		ReviewerID:  reviewerID,
+		Message:     "Added the checkout steps",
+	}
+}
@@ -0,0 +1,204 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed review store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a pending review. It returns ErrReviewPending if the
+// procedure already has one.
+func (s *MySQLStore) Create(ctx context.Context, review *Review) error {
+	if err := review.Validate(); err != nil {
+		return err
+	}
+	review.Status = StatusPending
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		if err := tx.Model(&Review{}).
+			Where("procedure_id = ? AND status = ?", review.ProcedureID, StatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrReviewPending
+		}
+		return tx.Create(review).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrReviewPending) {
+			return err
+		}
+		s.logger.Error(ctx, "failed to create review", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": review.ProcedureID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "review requested", map[string]interface{}{
+		"review_id":    review.ID.String(),
+		"procedure_id": review.ProcedureID.String(),
+		"version":      review.Version,
+	})
+
+	return nil
+}
+
+// GetByID retrieves a review by its ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*Review, error) {
+	var review Review
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&review).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		s.logger.Error(ctx, "failed to get review by ID", map[string]interface{}{
+			"error":     err.Error(),
+			"review_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// GetPending retrieves the pending review of a procedure.
+func (s *MySQLStore) GetPending(ctx context.Context, procedureID uuid.UUID) (*Review, error) {
+	var review Review
+	err := s.db.WithContext(ctx).
+		Where("procedure_id = ? AND status = ?", procedureID, StatusPending).
+		First(&review).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		s.logger.Error(ctx, "failed to get pending review", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+		})
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// ListByProcedure retrieves the reviews of a procedure, most recent first.
+func (s *MySQLStore) ListByProcedure(ctx context.Context, procedureID uuid.UUID) ([]*Review, error) {
+	var reviews []*Review
+	err := s.db.WithContext(ctx).
+		Where("procedure_id = ?", procedureID).
+		Order("created_at DESC").
+		Find(&reviews).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list reviews by procedure", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+		})
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// ListByReviewer retrieves the reviews a user was asked for, most recent
+// first.
+func (s *MySQLStore) ListByReviewer(ctx context.Context, reviewerID uuid.UUID, status Status) ([]*Review, error) {
+	query := s.db.WithContext(ctx).Where("reviewer_id = ?", reviewerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var reviews []*Review
+	if err := query.Order("created_at DESC").Find(&reviews).Error; err != nil {
+		s.logger.Error(ctx, "failed to list reviews by reviewer", map[string]interface{}{
+			"error":       err.Error(),
+			"reviewer_id": reviewerID.String(),
+		})
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// Decide approves or rejects a pending review.
+func (s *MySQLStore) Decide(ctx context.Context, id uuid.UUID, decision Status, decidedBy uuid.UUID, comment string) (*Review, error) {
+	if decision != StatusApproved && decision != StatusRejected {
+		return nil, ErrInvalidDecision
+	}
+	if len(comment) > MaxCommentLength {
+		return nil, ErrCommentTooLong
+	}
+
+	now := time.Now()
+	return s.close(ctx, id, map[string]interface{}{
+		"status":     decision,
+		"decided_by": decidedBy,
+		"comment":    comment,
+		"decided_at": now,
+		"updated_at": now,
+	})
+}
+
+// Withdraw withdraws a pending review.
+func (s *MySQLStore) Withdraw(ctx context.Context, id uuid.UUID) (*Review, error) {
+	return s.close(ctx, id, map[string]interface{}{
+		"status":     StatusWithdrawn,
+		"updated_at": time.Now(),
+	})
+}
+
+// close applies updates to a review if it is still pending, and returns
+// the updated review.
+func (s *MySQLStore) close(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*Review, error) {
+	result := s.db.WithContext(ctx).
+		Model(&Review{}).
+		Where("id = ? AND status = ?", id, StatusPending).
+		Updates(updates)
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to update review", map[string]interface{}{
+			"error":     result.Error.Error(),
+			"review_id": id.String(),
+		})
+		return nil, result.Error
+	}
+
+	review, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAlreadyDecided
+	}
+
+	s.logger.Info(ctx, "review closed", map[string]interface{}{
+		"review_id": id.String(),
+		"status":    review.Status,
+	})
+
+	return review, nil
+}
@@ -0,0 +1,115 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Create(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("creates a pending review", func(t *testing.T) {
+		r := createTestReview(nil)
+		r.Status = StatusApproved
+		require.NoError(t, store.Create(ctx, r))
+		assert.NotEqual(t, uuid.Nil, r.ID)
+
+		pending, err := store.GetPending(ctx, r.ProcedureID)
+		require.NoError(t, err)
+		assert.Equal(t, r.ID, pending.ID)
+		assert.Equal(t, StatusPending, pending.Status)
+	})
+
+	t.Run("one pending review per procedure", func(t *testing.T) {
+		first := createTestReview(nil)
+		require.NoError(t, store.Create(ctx, first))
+
+		second := createTestReview(nil)
+		second.ProcedureID = first.ProcedureID
+		assert.ErrorIs(t, store.Create(ctx, second), ErrReviewPending)
+
+		_, err := store.Withdraw(ctx, first.ID)
+		require.NoError(t, err)
+		assert.NoError(t, store.Create(ctx, second))
+	})
+
+	t.Run("invalid review", func(t *testing.T) {
+		r := createTestReview(nil)
+		r.VersionID = uuid.Nil
+		assert.ErrorIs(t, store.Create(ctx, r), ErrInvalidReview)
+	})
+}
+
+func TestMySQLStore_Decide(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("approves once", func(t *testing.T) {
+		r := createTestReview(nil)
+		require.NoError(t, store.Create(ctx, r))
+
+		decidedBy := uuid.New()
+		decided, err := store.Decide(ctx, r.ID, StatusApproved, decidedBy, "Looks good")
+		require.NoError(t, err)
+		assert.Equal(t, StatusApproved, decided.Status)
+		require.NotNil(t, decided.DecidedBy)
+		assert.Equal(t, decidedBy, *decided.DecidedBy)
+		assert.Equal(t, "Looks good", decided.Comment)
+		assert.NotNil(t, decided.DecidedAt)
+
+		_, err = store.Decide(ctx, r.ID, StatusRejected, decidedBy, "")
+		assert.ErrorIs(t, err, ErrAlreadyDecided)
+		_, err = store.Withdraw(ctx, r.ID)
+		assert.ErrorIs(t, err, ErrAlreadyDecided)
+
+		_, err = store.GetPending(ctx, r.ProcedureID)
+		assert.ErrorIs(t, err, ErrReviewNotFound)
+	})
+
+	t.Run("invalid decision", func(t *testing.T) {
+		r := createTestReview(nil)
+		require.NoError(t, store.Create(ctx, r))
+		_, err := store.Decide(ctx, r.ID, StatusWithdrawn, uuid.New(), "")
+		assert.ErrorIs(t, err, ErrInvalidDecision)
+	})
+
+	t.Run("missing review", func(t *testing.T) {
+		_, err := store.Decide(ctx, uuid.New(), StatusRejected, uuid.New(), "")
+		assert.ErrorIs(t, err, ErrReviewNotFound)
+	})
+}
+
+func TestMySQLStore_List(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	reviewerID := uuid.New()
+	first := createTestReview(&reviewerID)
+	require.NoError(t, store.Create(ctx, first))
+	_, err := store.Decide(ctx, first.ID, StatusRejected, reviewerID, "Step 3 is wrong")
+	require.NoError(t, err)
+
+	second := createTestReview(&reviewerID)
+	second.ProcedureID = first.ProcedureID
+	require.NoError(t, store.Create(ctx, second))
+
+	require.NoError(t, store.Create(ctx, createTestReview(nil)))
+
+	reviews, err := store.ListByProcedure(ctx, first.ProcedureID)
+	require.NoError(t, err)
+	assert.Len(t, reviews, 2)
+
+	reviews, err = store.ListByReviewer(ctx, reviewerID, "")
+	require.NoError(t, err)
+	assert.Len(t, reviews, 2)
+
+	reviews, err = store.ListByReviewer(ctx, reviewerID, StatusPending)
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, second.ID, reviews[0].ID)
+}
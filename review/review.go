@@ -0,0 +1,123 @@
+// Package review gates changes to test procedures behind a second pair of
+// eyes. Committing a draft under review creates a pending version, which
+// only becomes the procedure's latest version once someone other than its
+// author approves it.
+package review
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxCommentLength is the longest request message or decision comment that
+// can be stored.
+const MaxCommentLength = 2000
+
+var (
+	// ErrReviewNotFound is returned when a review is not found.
+	ErrReviewNotFound = errors.New("review not found")
+
+	// ErrReviewPending is returned when a review is requested for a
+	// procedure that already has one pending.
+	ErrReviewPending = errors.New("a review is already pending for this procedure")
+
+	// ErrAlreadyDecided is returned when a review that is no longer pending
+	// is decided or withdrawn.
+	ErrAlreadyDecided = errors.New("review has already been decided")
+
+	// ErrInvalidDecision is returned when a review is decided with a status
+	// other than approved or rejected.
+	ErrInvalidDecision = errors.New("decision must be approved or rejected")
+
+	// ErrCommentTooLong is returned when a request message or decision
+	// comment is longer than MaxCommentLength.
+	ErrCommentTooLong = errors.New("comment must be at most 2000 characters")
+
+	// ErrInvalidReview is returned when a review is missing the procedure,
+	// version or user it is for.
+	ErrInvalidReview = errors.New("review needs a project, procedure, version and requester")
+)
+
+// Status is where a review stands.
+type Status string
+
+const (
+	// StatusPending awaits a decision.
+	StatusPending Status = "pending"
+
+	// StatusApproved made the version the procedure's latest.
+	StatusApproved Status = "approved"
+
+	// StatusRejected left the version unpublished.
+	StatusRejected Status = "rejected"
+
+	// StatusWithdrawn was withdrawn by its requester before a decision.
+	StatusWithdrawn Status = "withdrawn"
+)
+
+// IsValid reports whether s is a known status.
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusPending, StatusApproved, StatusRejected, StatusWithdrawn:
+		return true
+	}
+	return false
+}
+
+// Review is a request to approve a pending version of a test procedure.
+// ProcedureID is the procedure's first version, which identifies it across
+// versions, and VersionID the pending version. ReviewerID, if set, is the
+// user asked to review it, and Message is the version's commit message.
+type Review struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID   uuid.UUID  `json:"project_id" gorm:"type:char(36);not null;index:idx_procedure_reviews_project_id"`
+	ProcedureID uuid.UUID  `json:"procedure_id" gorm:"type:char(36);not null;index:idx_procedure_reviews_procedure_id"`
+	VersionID   uuid.UUID  `json:"version_id" gorm:"type:char(36);not null"`
+	Version     uint       `json:"version" gorm:"not null"`
+	Status      Status     `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	RequestedBy uuid.UUID  `json:"requested_by" gorm:"type:char(36);not null"`
+	ReviewerID  *uuid.UUID `json:"reviewer_id,omitempty" gorm:"type:char(36);index:idx_procedure_reviews_reviewer_id"`
+	Message     string     `json:"message,omitempty" gorm:"type:text"`
+	DecidedBy   *uuid.UUID `json:"decided_by,omitempty" gorm:"type:char(36)"`
+	Comment     string     `json:"comment,omitempty" gorm:"type:text"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Review.
+func (Review) TableName() string {
+	return "procedure_reviews"
+}
+
+// BeforeCreate hook to generate UUID before creating a new review.
+func (r *Review) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the review has valid required fields.
+func (r *Review) Validate() error {
+	if r.ProjectID == uuid.Nil || r.ProcedureID == uuid.Nil || r.VersionID == uuid.Nil || r.RequestedBy == uuid.Nil {
+		return ErrInvalidReview
+	}
+	if len(r.Message) > MaxCommentLength {
+		return ErrCommentTooLong
+	}
+	return nil
+}
+
+// CanReview reports whether userID may decide the review: anyone but its
+// requester, provided they can access the project or were asked to review
+// it.
+func (r *Review) CanReview(userID uuid.UUID, canAccessProject bool) bool {
+	if userID == r.RequestedBy {
+		return false
+	}
+	return canAccessProject || (r.ReviewerID != nil && *r.ReviewerID == userID)
+}
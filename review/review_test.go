@@ -0,0 +1,18 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReview_CanReview(t *testing.T) {
+	reviewerID := uuid.New()
+	r := createTestReview(&reviewerID)
+
+	assert.False(t, r.CanReview(r.RequestedBy, true), "requester")
+	assert.True(t, r.CanReview(reviewerID, false), "requested reviewer")
+	assert.True(t, r.CanReview(uuid.New(), true), "project member")
+	assert.False(t, r.CanReview(uuid.New(), false), "outsider")
+}
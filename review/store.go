@@ -0,0 +1,37 @@
+package review
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for review persistence operations.
+type Store interface {
+	// Create creates a pending review. It returns ErrReviewPending if the
+	// procedure already has one.
+	Create(ctx context.Context, review *Review) error
+
+	// GetByID retrieves a review by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*Review, error)
+
+	// GetPending retrieves the pending review of a procedure, identified
+	// by its first version. It returns ErrReviewNotFound if there is none.
+	GetPending(ctx context.Context, procedureID uuid.UUID) (*Review, error)
+
+	// ListByProcedure retrieves the reviews of a procedure, identified by
+	// its first version, most recent first.
+	ListByProcedure(ctx context.Context, procedureID uuid.UUID) ([]*Review, error)
+
+	// ListByReviewer retrieves the reviews a user was asked for, most
+	// recent first. An empty status lists them all.
+	ListByReviewer(ctx context.Context, reviewerID uuid.UUID, status Status) ([]*Review, error)
+
+	// Decide approves or rejects a pending review on behalf of decidedBy.
+	// It returns ErrAlreadyDecided if the review is no longer pending.
+	Decide(ctx context.Context, id uuid.UUID, decision Status, decidedBy uuid.UUID, comment string) (*Review, error)
+
+	// Withdraw withdraws a pending review. It returns ErrAlreadyDecided if
+	// the review is no longer pending.
+	Withdraw(ctx context.Context, id uuid.UUID) (*Review, error)
+}
@@ -0,0 +1,31 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and runner store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Runner{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestRunner creates a runner with default values.
+func createTestRunner(name string, createdBy uuid.UUID, labels ...string) *Runner {
+	return &Runner{
+		Name:      name,
+		Labels:    labels,
+		Version:   "dev",
+		CreatedBy: createdBy,
+	}
+}
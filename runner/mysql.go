@@ -0,0 +1,133 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed runner store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create registers a new runner.
+func (s *MySQLStore) Create(ctx context.Context, r *Runner) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	r.Labels = r.Labels.Normalize()
+
+	if err := s.db.WithContext(ctx).Create(r).Error; err != nil {
+		s.logger.Error(ctx, "failed to create runner", map[string]interface{}{
+			"error":      err.Error(),
+			"created_by": r.CreatedBy.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "runner registered", map[string]interface{}{
+		"runner_id": r.ID.String(),
+		"name":      r.Name,
+	})
+
+	return nil
+}
+
+// GetByID retrieves a runner by its ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*Runner, error) {
+	var r Runner
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&r).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRunnerNotFound
+		}
+		s.logger.Error(ctx, "failed to get runner by ID", map[string]interface{}{
+			"error":     err.Error(),
+			"runner_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Heartbeat records that the runner was seen at seenAt.
+func (s *MySQLStore) Heartbeat(ctx context.Context, id uuid.UUID, seenAt time.Time) error {
+	result := s.db.WithContext(ctx).
+		Model(&Runner{}).
+		Where("id = ?", id).
+		Update("last_seen_at", seenAt)
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to record runner heartbeat", map[string]interface{}{
+			"error":     result.Error.Error(),
+			"runner_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrRunnerNotFound
+	}
+
+	return nil
+}
+
+// Delete deregisters a runner by its ID.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&Runner{}, "id = ?", id)
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete runner", map[string]interface{}{
+			"error":     result.Error.Error(),
+			"runner_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrRunnerNotFound
+	}
+
+	s.logger.Info(ctx, "runner deregistered", map[string]interface{}{
+		"runner_id": id.String(),
+	})
+
+	return nil
+}
+
+// ListByCreator retrieves the runners registered by a user, by name.
+func (s *MySQLStore) ListByCreator(ctx context.Context, createdBy uuid.UUID) ([]*Runner, error) {
+	var runners []*Runner
+	err := s.db.WithContext(ctx).
+		Where("created_by = ?", createdBy).
+		Order("name ASC, created_at ASC").
+		Find(&runners).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list runners", map[string]interface{}{
+			"error":      err.Error(),
+			"created_by": createdBy.String(),
+		})
+		return nil, err
+	}
+
+	return runners, nil
+}
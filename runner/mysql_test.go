@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Create(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("create runner with labels", func(t *testing.T) {
+		r := createTestRunner("office-vpn", uuid.New(), "windows", "needs-vpn", "windows")
+		require.NoError(t, store.Create(ctx, r))
+		assert.NotEqual(t, uuid.Nil, r.ID)
+
+		retrieved, err := store.GetByID(ctx, r.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "office-vpn", retrieved.Name)
+		assert.Equal(t, Labels{"needs-vpn", "windows"}, retrieved.Labels)
+		assert.True(t, retrieved.Online(time.Now()))
+	})
+
+	t.Run("invalid label returns error", func(t *testing.T) {
+		r := createTestRunner("office-vpn", uuid.New(), "Needs VPN")
+		assert.ErrorIs(t, store.Create(ctx, r), ErrInvalidLabel)
+	})
+
+	t.Run("missing name returns error", func(t *testing.T) {
+		r := createTestRunner("", uuid.New())
+		assert.ErrorIs(t, store.Create(ctx, r), ErrInvalidRunnerName)
+	})
+}
+
+func TestMySQLStore_GetByID(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	_, err := store.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrRunnerNotFound)
+}
+
+func TestMySQLStore_Heartbeat(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("records last seen time", func(t *testing.T) {
+		r := createTestRunner("office-vpn", uuid.New())
+		r.LastSeenAt = time.Now().Add(-time.Hour)
+		require.NoError(t, store.Create(ctx, r))
+
+		retrieved, err := store.GetByID(ctx, r.ID)
+		require.NoError(t, err)
+		assert.False(t, retrieved.Online(time.Now()))
+
+		seenAt := time.Now().Truncate(time.Second)
+		require.NoError(t, store.Heartbeat(ctx, r.ID, seenAt))
+
+		retrieved, err = store.GetByID(ctx, r.ID)
+		require.NoError(t, err)
+		assert.True(t, seenAt.Equal(retrieved.LastSeenAt))
+		assert.True(t, retrieved.Online(time.Now()))
+	})
+
+	t.Run("unknown runner returns error", func(t *testing.T) {
+		assert.ErrorIs(t, store.Heartbeat(ctx, uuid.New(), time.Now()), ErrRunnerNotFound)
+	})
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	r := createTestRunner("office-vpn", uuid.New())
+	require.NoError(t, store.Create(ctx, r))
+
+	require.NoError(t, store.Delete(ctx, r.ID))
+	_, err := store.GetByID(ctx, r.ID)
+	assert.ErrorIs(t, err, ErrRunnerNotFound)
+
+	assert.ErrorIs(t, store.Delete(ctx, r.ID), ErrRunnerNotFound)
+}
+
+func TestMySQLStore_ListByCreator(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	require.NoError(t, store.Create(ctx, createTestRunner("lab-windows", userID, "windows")))
+	require.NoError(t, store.Create(ctx, createTestRunner("office-vpn", userID, "needs-vpn")))
+	require.NoError(t, store.Create(ctx, createTestRunner("other", uuid.New())))
+
+	runners, err := store.ListByCreator(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, runners, 2)
+	assert.Equal(t, "lab-windows", runners[0].Name)
+	assert.Equal(t, "office-vpn", runners[1].Name)
+}
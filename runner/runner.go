@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRunnerNotFound is returned when a runner is not found.
+	ErrRunnerNotFound = errors.New("runner not found")
+
+	// ErrInvalidRunnerName is returned when a runner name is empty or too long.
+	ErrInvalidRunnerName = errors.New("runner name is required and must be at most 100 characters")
+
+	// ErrInvalidCreatedBy is returned when created_by is not set.
+	ErrInvalidCreatedBy = errors.New("created_by is required")
+
+	// ErrInvalidLabel is returned when a runner label is not a valid slug.
+	ErrInvalidLabel = errors.New("runner labels must be up to 63 lowercase letters, digits, '.', '_' or '-', starting with a letter or digit")
+)
+
+// MaxNameLength is the longest runner name.
+const MaxNameLength = 100
+
+// OfflineAfter is how long a runner may go without a heartbeat before it is
+// considered offline.
+const OfflineAfter = 2 * time.Minute
+
+// labelPattern restricts labels to short slugs such as needs-vpn or windows.
+var labelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+// Labels are the capabilities of a runner, such as the networks it can
+// reach or the platform it runs on. Endpoints list the labels a runner
+// needs to run their jobs.
+type Labels []string
+
+// Validate checks that every label is a valid slug.
+func (l Labels) Validate() error {
+	for _, label := range l {
+		if !labelPattern.MatchString(label) {
+			return ErrInvalidLabel
+		}
+	}
+	return nil
+}
+
+// Satisfies reports whether l includes every one of the required labels.
+func (l Labels) Satisfies(required Labels) bool {
+	for _, label := range required {
+		if !slices.Contains(l, label) {
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize returns the labels sorted and without duplicates.
+func (l Labels) Normalize() Labels {
+	normalized := append(Labels{}, l...)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// Value implements the driver.Valuer interface for database storage.
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return json.Marshal([]string{})
+	}
+	return json.Marshal([]string(l))
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (l *Labels) Scan(value interface{}) error {
+	if value == nil {
+		*l = Labels{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("failed to scan Labels: not a byte slice")
+	}
+
+	var labels []string
+	if err := json.Unmarshal(bytes, &labels); err != nil {
+		return err
+	}
+	*l = labels
+	return nil
+}
+
+// Runner is a self-hosted agent runner (cmd/agent-runner) registered by a
+// user. Runners claim the jobs of the user who registered them, limited to
+// endpoints whose required labels they all have.
+type Runner struct {
+	ID         uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	Name       string    `json:"name" gorm:"type:varchar(100);not null"`
+	Labels     Labels    `json:"labels" gorm:"type:json"`
+	Version    string    `json:"version" gorm:"type:varchar(50);not null;default:''"`
+	CreatedBy  uuid.UUID `json:"created_by" gorm:"type:char(36);not null;index:idx_runners_created_by"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new runner.
+func (r *Runner) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.LastSeenAt.IsZero() {
+		r.LastSeenAt = time.Now()
+	}
+	return nil
+}
+
+// Validate checks if the runner has valid required fields.
+func (r *Runner) Validate() error {
+	if r.Name == "" || len(r.Name) > MaxNameLength {
+		return ErrInvalidRunnerName
+	}
+	if r.CreatedBy == uuid.Nil {
+		return ErrInvalidCreatedBy
+	}
+	return r.Labels.Validate()
+}
+
+// Online reports whether the runner has sent a heartbeat recently enough at
+// now to be considered running.
+func (r *Runner) Online(now time.Time) bool {
+	return now.Sub(r.LastSeenAt) < OfflineAfter
+}
@@ -0,0 +1,17 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabels_Satisfies(t *testing.T) {
+	labels := Labels{"needs-vpn", "windows"}
+
+	assert.True(t, labels.Satisfies(nil))
+	assert.True(t, labels.Satisfies(Labels{"windows"}))
+	assert.True(t, labels.Satisfies(Labels{"needs-vpn", "windows"}))
+	assert.False(t, labels.Satisfies(Labels{"linux"}))
+	assert.False(t, Labels{}.Satisfies(Labels{"needs-vpn"}))
+}
@@ -0,0 +1,26 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for runner persistence operations.
+type Store interface {
+	// Create registers a new runner.
+	Create(ctx context.Context, r *Runner) error
+
+	// GetByID retrieves a runner by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*Runner, error)
+
+	// Heartbeat records that the runner was seen at seenAt.
+	Heartbeat(ctx context.Context, id uuid.UUID, seenAt time.Time) error
+
+	// Delete deregisters a runner by its ID.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListByCreator retrieves the runners registered by a user, by name.
+	ListByCreator(ctx context.Context, createdBy uuid.UUID) ([]*Runner, error)
+}
@@ -0,0 +1,209 @@
+package saml
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIdPEntityID = "https://idp.example.com/metadata"
+	testSSOURL      = "https://idp.example.com/sso"
+	testSPEntityID  = "https://qa.example.com/api/v1/auth/saml/metadata"
+	testACSURL      = "https://qa.example.com/api/v1/auth/saml/acs"
+)
+
+// setupTestStore creates a test database and identity provider store for
+// testing.
+func setupTestStore(t *testing.T) Store {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &IdentityProvider{})
+
+	return NewMySQLStore(db, logger.NewTestLogger())
+}
+
+// testIdP is an identity provider with a self-signed signing certificate.
+type testIdP struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testIdP{key: key, cert: cert}
+}
+
+// metadata returns the identity provider's parsed metadata.
+func (idp *testIdP) metadata() *IdPMetadata {
+	return &IdPMetadata{
+		EntityID:     testIdPEntityID,
+		SSOURL:       testSSOURL,
+		Certificates: []*x509.Certificate{idp.cert},
+	}
+}
+
+// metadataXML returns the identity provider's metadata document.
+func (idp *testIdP) metadataXML() string {
+	return `<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="` + testIdPEntityID + `">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data>
+          <ds:X509Certificate>
+            ` + base64.StdEncoding.EncodeToString(idp.cert.Raw) + `
+          </ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.com/sso/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="` + testSSOURL + `"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+}
+
+// sign adds an enveloped signature to the element with the given ID,
+// placed after the element's Issuer as SAML requires.
+func (idp *testIdP) sign(t *testing.T, doc, id string) string {
+	root, err := parseXML([]byte(doc))
+	require.NoError(t, err)
+	el := findByID(root, id)
+	require.NotNil(t, el, "no element with ID %s", id)
+
+	digest := sha256.Sum256(canonicalize(el, nil, nil))
+	signedInfo := `<ds:SignedInfo xmlns:ds="` + nsDSig + `">` +
+		`<ds:CanonicalizationMethod Algorithm="` + algExcC14N + `"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="` + algRSASHA256 + `"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#` + id + `"><ds:Transforms>` +
+		`<ds:Transform Algorithm="` + algEnveloped + `"></ds:Transform>` +
+		`<ds:Transform Algorithm="` + algExcC14N + `"></ds:Transform>` +
+		`</ds:Transforms><ds:DigestMethod Algorithm="` + algDigestSHA256 + `"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference></ds:SignedInfo>`
+
+	// Standalone, the SignedInfo above is already canonical.
+	hashed := sha256.Sum256([]byte(signedInfo))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	require.NoError(t, err)
+
+	signature := `<ds:Signature xmlns:ds="` + nsDSig + `">` +
+		strings.Replace(signedInfo, ` xmlns:ds="`+nsDSig+`"`, "", 1) +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(sig) + `</ds:SignatureValue>` +
+		`</ds:Signature>`
+
+	start := strings.Index(doc, `ID="`+id+`"`)
+	require.NotEqual(t, -1, start)
+	issuerEnd := strings.Index(doc[start:], "</saml:Issuer>")
+	require.NotEqual(t, -1, issuerEnd)
+	at := start + issuerEnd + len("</saml:Issuer>")
+	return doc[:at] + signature + doc[at:]
+}
+
+// findByID returns the element with the given ID attribute, or nil.
+func findByID(e *element, id string) *element {
+	if e.attr("ID") == id {
+		return e
+	}
+	for _, c := range e.children {
+		if el, ok := c.(*element); ok {
+			if found := findByID(el, id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// responseParams are the values of a test SAML response.
+type responseParams struct {
+	RequestID    string
+	Destination  string
+	Issuer       string
+	Status       string
+	Recipient    string
+	Audience     string
+	NotBefore    string
+	NotOnOrAfter string
+	Email        string
+}
+
+// newResponseParams returns the values of a valid response to requestID.
+func newResponseParams(requestID string, now time.Time) responseParams {
+	return responseParams{
+		RequestID:    requestID,
+		Destination:  testACSURL,
+		Issuer:       testIdPEntityID,
+		Status:       statusSuccess,
+		Recipient:    testACSURL,
+		Audience:     testSPEntityID,
+		NotBefore:    now.Add(-time.Minute).UTC().Format(time.RFC3339),
+		NotOnOrAfter: now.Add(5 * time.Minute).UTC().Format(time.RFC3339),
+		Email:        "jane@example.com",
+	}
+}
+
+var responseTemplate = template.Must(template.New("response").Parse(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response" Version="2.0" IssueInstant="{{.NotBefore}}" Destination="{{.Destination}}" InResponseTo="{{.RequestID}}">
+  <saml:Issuer>{{.Issuer}}</saml:Issuer>
+  <samlp:Status><samlp:StatusCode Value="{{.Status}}"/></samlp:Status>
+  <saml:Assertion ID="_assertion" Version="2.0" IssueInstant="{{.NotBefore}}">
+    <saml:Issuer>{{.Issuer}}</saml:Issuer>
+    <saml:Subject>
+      <saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">{{.Email}}</saml:NameID>
+      <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+        <saml:SubjectConfirmationData InResponseTo="{{.RequestID}}" NotOnOrAfter="{{.NotOnOrAfter}}" Recipient="{{.Recipient}}"/>
+      </saml:SubjectConfirmation>
+    </saml:Subject>
+    <saml:Conditions NotBefore="{{.NotBefore}}" NotOnOrAfter="{{.NotOnOrAfter}}">
+      <saml:AudienceRestriction><saml:Audience>{{.Audience}}</saml:Audience></saml:AudienceRestriction>
+    </saml:Conditions>
+    <saml:AuthnStatement AuthnInstant="{{.NotBefore}}" SessionIndex="_session"/>
+    <saml:AttributeStatement>
+      <saml:Attribute Name="http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress" FriendlyName="email">
+        <saml:AttributeValue>{{.Email}}</saml:AttributeValue>
+      </saml:Attribute>
+      <saml:Attribute Name="groups">
+        <saml:AttributeValue>qa</saml:AttributeValue>
+        <saml:AttributeValue>admins</saml:AttributeValue>
+      </saml:Attribute>
+    </saml:AttributeStatement>
+  </saml:Assertion>
+</samlp:Response>`))
+
+// buildResponse renders an unsigned response.
+func buildResponse(t *testing.T, p responseParams) string {
+	var b bytes.Buffer
+	require.NoError(t, responseTemplate.Execute(&b, p))
+	return b.String()
+}
+
+// encodeResponse base64 encodes a response as the HTTP-POST binding does.
+func encodeResponse(doc string) string {
+	return base64.StdEncoding.EncodeToString([]byte(doc))
+}
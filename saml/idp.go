@@ -0,0 +1,44 @@
+package saml
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIdentityProviderNotFound is returned when no identity provider has
+	// been configured.
+	ErrIdentityProviderNotFound = errors.New("identity provider not configured")
+)
+
+// IdentityProvider is the deployment's SAML identity provider, configured
+// by uploading its metadata. A deployment has at most one.
+type IdentityProvider struct {
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	EntityID    string    `json:"entity_id" gorm:"type:varchar(1024);not null"`
+	MetadataXML string    `json:"-" gorm:"type:mediumtext;not null"`
+	UpdatedBy   uuid.UUID `json:"updated_by" gorm:"type:char(36);not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name.
+func (IdentityProvider) TableName() string {
+	return "saml_identity_providers"
+}
+
+// BeforeCreate hook to generate UUID before creating a new identity provider.
+func (p *IdentityProvider) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// Metadata parses the identity provider's stored metadata.
+func (p *IdentityProvider) Metadata() (*IdPMetadata, error) {
+	return ParseMetadata([]byte(p.MetadataXML))
+}
@@ -0,0 +1,60 @@
+package saml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetadata(t *testing.T) {
+	idp := newTestIdP(t)
+
+	t.Run("entity descriptor", func(t *testing.T) {
+		md, err := ParseMetadata([]byte(idp.metadataXML()))
+		require.NoError(t, err)
+		assert.Equal(t, testIdPEntityID, md.EntityID)
+		assert.Equal(t, testSSOURL, md.SSOURL)
+		require.Len(t, md.Certificates, 1)
+		assert.True(t, md.Certificates[0].Equal(idp.cert))
+	})
+
+	t.Run("entities descriptor uses the first identity provider", func(t *testing.T) {
+		entity := strings.Replace(idp.metadataXML(), `<?xml version="1.0"?>`, "", 1)
+		doc := `<md:EntitiesDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata">` +
+			`<md:EntityDescriptor entityID="https://sp.example.com"><md:SPSSODescriptor/></md:EntityDescriptor>` +
+			entity + `</md:EntitiesDescriptor>`
+		md, err := ParseMetadata([]byte(doc))
+		require.NoError(t, err)
+		assert.Equal(t, testIdPEntityID, md.EntityID)
+	})
+
+	t.Run("missing redirect binding", func(t *testing.T) {
+		doc := strings.Replace(idp.metadataXML(), "bindings:HTTP-Redirect", "bindings:SOAP", 1)
+		_, err := ParseMetadata([]byte(doc))
+		assert.ErrorIs(t, err, ErrInvalidMetadata)
+	})
+
+	t.Run("encryption keys are not signing certificates", func(t *testing.T) {
+		doc := strings.Replace(idp.metadataXML(), `use="signing"`, `use="encryption"`, 1)
+		_, err := ParseMetadata([]byte(doc))
+		assert.ErrorIs(t, err, ErrInvalidMetadata)
+	})
+
+	t.Run("invalid certificate", func(t *testing.T) {
+		doc := strings.Replace(idp.metadataXML(), "<ds:X509Certificate>", "<ds:X509Certificate>AAAA", 1)
+		_, err := ParseMetadata([]byte(doc))
+		assert.ErrorIs(t, err, ErrInvalidMetadata)
+	})
+
+	t.Run("not metadata", func(t *testing.T) {
+		_, err := ParseMetadata([]byte(`<html><body>login</body></html>`))
+		assert.ErrorIs(t, err, ErrInvalidMetadata)
+	})
+
+	t.Run("not XML", func(t *testing.T) {
+		_, err := ParseMetadata([]byte(`{"entity_id": "x"}`))
+		assert.ErrorIs(t, err, ErrInvalidMetadata)
+	})
+}
@@ -0,0 +1,93 @@
+package saml
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed identity provider store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Get retrieves the configured identity provider.
+func (s *MySQLStore) Get(ctx context.Context) (*IdentityProvider, error) {
+	var idp IdentityProvider
+	err := s.db.WithContext(ctx).
+		Order("updated_at DESC").
+		First(&idp).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdentityProviderNotFound
+		}
+		s.logger.Error(ctx, "failed to get identity provider", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return &idp, nil
+}
+
+// Save replaces the configured identity provider with idp. The metadata is
+// parsed first so an unusable document is never stored.
+func (s *MySQLStore) Save(ctx context.Context, idp *IdentityProvider) error {
+	md, err := idp.Metadata()
+	if err != nil {
+		return err
+	}
+	idp.EntityID = md.EntityID
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&IdentityProvider{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(idp).Error
+	})
+	if err != nil {
+		s.logger.Error(ctx, "failed to save identity provider", map[string]interface{}{
+			"error":     err.Error(),
+			"entity_id": idp.EntityID,
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "identity provider saved", map[string]interface{}{
+		"entity_id":  idp.EntityID,
+		"updated_by": idp.UpdatedBy.String(),
+	})
+
+	return nil
+}
+
+// Delete removes the configured identity provider.
+func (s *MySQLStore) Delete(ctx context.Context) error {
+	result := s.db.WithContext(ctx).Where("1 = 1").Delete(&IdentityProvider{})
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete identity provider", map[string]interface{}{
+			"error": result.Error.Error(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrIdentityProviderNotFound
+	}
+
+	s.logger.Info(ctx, "identity provider deleted", nil)
+
+	return nil
+}
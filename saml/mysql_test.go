@@ -0,0 +1,70 @@
+package saml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Save(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+	idp := newTestIdP(t)
+
+	t.Run("not configured", func(t *testing.T) {
+		_, err := store.Get(ctx)
+		assert.ErrorIs(t, err, ErrIdentityProviderNotFound)
+	})
+
+	t.Run("save records the entity ID", func(t *testing.T) {
+		saved := &IdentityProvider{MetadataXML: idp.metadataXML(), UpdatedBy: uuid.New()}
+		require.NoError(t, store.Save(ctx, saved))
+
+		retrieved, err := store.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, saved.ID, retrieved.ID)
+		assert.Equal(t, testIdPEntityID, retrieved.EntityID)
+
+		md, err := retrieved.Metadata()
+		require.NoError(t, err)
+		assert.Equal(t, testSSOURL, md.SSOURL)
+	})
+
+	t.Run("save replaces the identity provider", func(t *testing.T) {
+		replacement := &IdentityProvider{MetadataXML: newTestIdP(t).metadataXML(), UpdatedBy: uuid.New()}
+		require.NoError(t, store.Save(ctx, replacement))
+
+		retrieved, err := store.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, replacement.ID, retrieved.ID)
+		assert.Equal(t, replacement.UpdatedBy, retrieved.UpdatedBy)
+	})
+
+	t.Run("invalid metadata is not saved", func(t *testing.T) {
+		current, err := store.Get(ctx)
+		require.NoError(t, err)
+
+		err = store.Save(ctx, &IdentityProvider{MetadataXML: "<nope/>", UpdatedBy: uuid.New()})
+		assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+		retrieved, err := store.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, current.ID, retrieved.ID)
+	})
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, store.Delete(ctx), ErrIdentityProviderNotFound)
+
+	require.NoError(t, store.Save(ctx, &IdentityProvider{MetadataXML: newTestIdP(t).metadataXML(), UpdatedBy: uuid.New()}))
+	require.NoError(t, store.Delete(ctx))
+
+	_, err := store.Get(ctx)
+	assert.ErrorIs(t, err, ErrIdentityProviderNotFound)
+}
@@ -0,0 +1,165 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	// Register the hashes used by supported signature algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// XML signature namespaces and algorithms.
+const (
+	nsDSig = "http://www.w3.org/2000/09/xmldsig#"
+
+	algExcC14N      = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnveloped    = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA256    = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512    = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algDigestSHA256 = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algDigestSHA512 = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+var (
+	// errNotSigned is returned when an element has no signature.
+	errNotSigned = errors.New("element is not signed")
+
+	signatureHashes = map[string]crypto.Hash{
+		algRSASHA256: crypto.SHA256,
+		algRSASHA512: crypto.SHA512,
+	}
+	digestHashes = map[string]crypto.Hash{
+		algDigestSHA256: crypto.SHA256,
+		algDigestSHA512: crypto.SHA512,
+	}
+)
+
+// verifySignature verifies the enveloped XML signature that is a direct
+// child of e and whose single reference is e itself, against any of certs.
+// Only exclusive canonicalization with RSA SHA-256 or SHA-512 signatures is
+// supported, which is what current identity providers produce by default.
+// It returns errNotSigned if e has no signature.
+func verifySignature(e *element, certs []*x509.Certificate) error {
+	sigs := e.childElements(nsDSig, "Signature")
+	if len(sigs) == 0 {
+		return errNotSigned
+	}
+	if len(sigs) > 1 {
+		return errors.New("element has more than one signature")
+	}
+	sig := sigs[0]
+
+	signedInfo := sig.child(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("signature has no SignedInfo")
+	}
+	canonMethod := signedInfo.child(nsDSig, "CanonicalizationMethod")
+	if canonMethod == nil || canonMethod.attr("Algorithm") != algExcC14N {
+		return errors.New("unsupported canonicalization method")
+	}
+	sigMethod := signedInfo.child(nsDSig, "SignatureMethod")
+	if sigMethod == nil {
+		return errors.New("signature has no SignatureMethod")
+	}
+	sigHash, ok := signatureHashes[sigMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported signature method %q", sigMethod.attr("Algorithm"))
+	}
+
+	refs := signedInfo.childElements(nsDSig, "Reference")
+	if len(refs) != 1 {
+		return errors.New("signature must have exactly one reference")
+	}
+	ref := refs[0]
+	id := e.attr("ID")
+	if id == "" || ref.attr("URI") != "#"+id {
+		return errors.New("signature does not reference the signed element")
+	}
+
+	var prefixes []string
+	hasC14N := false
+	if transforms := ref.child(nsDSig, "Transforms"); transforms != nil {
+		for _, t := range transforms.childElements(nsDSig, "Transform") {
+			switch t.attr("Algorithm") {
+			case algEnveloped:
+			case algExcC14N:
+				hasC14N = true
+				prefixes = inclusivePrefixes(t)
+			default:
+				return fmt.Errorf("unsupported transform %q", t.attr("Algorithm"))
+			}
+		}
+	}
+	if !hasC14N {
+		return errors.New("reference is not canonicalized with exclusive canonicalization")
+	}
+
+	digestMethod := ref.child(nsDSig, "DigestMethod")
+	if digestMethod == nil {
+		return errors.New("reference has no DigestMethod")
+	}
+	digestHash, ok := digestHashes[digestMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported digest method %q", digestMethod.attr("Algorithm"))
+	}
+	digestValue := ref.child(nsDSig, "DigestValue")
+	if digestValue == nil {
+		return errors.New("reference has no DigestValue")
+	}
+	wantDigest, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return fmt.Errorf("invalid DigestValue: %w", err)
+	}
+
+	h := digestHash.New()
+	h.Write(canonicalize(e, sig, prefixes))
+	if subtle.ConstantTimeCompare(h.Sum(nil), wantDigest) != 1 {
+		return errors.New("digest does not match the signed element")
+	}
+
+	sigValue := sig.child(nsDSig, "SignatureValue")
+	if sigValue == nil {
+		return errors.New("signature has no SignatureValue")
+	}
+	signature, err := decodeBase64(sigValue.text())
+	if err != nil {
+		return fmt.Errorf("invalid SignatureValue: %w", err)
+	}
+
+	h = sigHash.New()
+	h.Write(canonicalize(signedInfo, nil, inclusivePrefixes(canonMethod)))
+	hashed := h.Sum(nil)
+	for _, cert := range certs {
+		pub, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		if rsa.VerifyPKCS1v15(pub, sigHash, hashed, signature) == nil {
+			return nil
+		}
+	}
+	return errors.New("signature was not made by a trusted certificate")
+}
+
+// inclusivePrefixes returns the PrefixList of a canonicalization method or
+// transform's InclusiveNamespaces child.
+func inclusivePrefixes(method *element) []string {
+	in := method.child(algExcC14N, "InclusiveNamespaces")
+	if in == nil {
+		return nil
+	}
+	return strings.Fields(in.attr("PrefixList"))
+}
+
+// decodeBase64 decodes standard base64, ignoring the whitespace XML
+// documents wrap it with.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
@@ -0,0 +1,379 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	statusSuccess        = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	confirmationBearer   = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	nameIDFormatEmail    = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	nameIDFormatUnspec   = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	defaultClockSkew     = 3 * time.Minute
+	defaultRequestMaxAge = 10 * time.Minute
+)
+
+var (
+	// ErrInvalidResponse is returned when a SAML response is rejected.
+	ErrInvalidResponse = errors.New("invalid SAML response")
+)
+
+// Config holds the service provider's identity.
+type Config struct {
+	// EntityID identifies this service provider to the identity provider.
+	EntityID string
+	// ACSURL is the assertion consumer service URL responses are posted to.
+	ACSURL string
+	// ClockSkew is the leeway allowed when checking validity periods.
+	ClockSkew time.Duration
+	// RequestMaxAge is how long a login may take at the identity provider.
+	RequestMaxAge time.Duration
+}
+
+// Assertion is the authenticated subject of a SAML response.
+type Assertion struct {
+	NameID       string
+	SessionIndex string
+	// Attributes maps attribute names, and friendly names where given, to
+	// their values.
+	Attributes map[string][]string
+}
+
+// Attribute returns the first value of an attribute, or "" if it is absent.
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// pendingRequest is an authentication request awaiting its response.
+type pendingRequest struct {
+	redirectTo string
+	expires    time.Time
+}
+
+// ServiceProvider runs SP-initiated logins with the HTTP-Redirect binding
+// for requests and the HTTP-POST binding for responses. Unsolicited
+// responses are rejected: every response must answer a request made by this
+// service provider, and each request can be answered once. Pending requests
+// are kept in memory, like sessions, so a login must complete on the
+// instance that started it.
+type ServiceProvider struct {
+	cfg Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]pendingRequest
+}
+
+// NewServiceProvider creates a service provider. Zero durations in cfg take
+// their defaults.
+func NewServiceProvider(cfg Config) *ServiceProvider {
+	if cfg.ClockSkew <= 0 {
+		cfg.ClockSkew = defaultClockSkew
+	}
+	if cfg.RequestMaxAge <= 0 {
+		cfg.RequestMaxAge = defaultRequestMaxAge
+	}
+	return &ServiceProvider{
+		cfg:     cfg,
+		now:     time.Now,
+		pending: make(map[string]pendingRequest),
+	}
+}
+
+// Metadata returns the service provider's metadata document for
+// registering it with an identity provider.
+func (sp *ServiceProvider) Metadata() []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<md:EntityDescriptor xmlns:md="` + nsMetadata + `" entityID="` + escapeXML(sp.cfg.EntityID) + `">`)
+	b.WriteString(`<md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="` + nsProtocol + `">`)
+	b.WriteString(`<md:NameIDFormat>` + nameIDFormatEmail + `</md:NameIDFormat>`)
+	b.WriteString(`<md:AssertionConsumerService Binding="` + bindingHTTPPost + `" Location="` + escapeXML(sp.cfg.ACSURL) + `" index="0" isDefault="true"/>`)
+	b.WriteString(`</md:SPSSODescriptor></md:EntityDescriptor>` + "\n")
+	return b.Bytes()
+}
+
+// AuthnRequestURL starts a login, returning the identity provider URL to
+// redirect the browser to. redirectTo is returned with the assertion once
+// the login completes.
+func (sp *ServiceProvider) AuthnRequestURL(idp *IdPMetadata, redirectTo string) (string, error) {
+	id, err := newRequestID()
+	if err != nil {
+		return "", err
+	}
+	now := sp.now()
+
+	var req bytes.Buffer
+	req.WriteString(`<samlp:AuthnRequest xmlns:samlp="` + nsProtocol + `" xmlns:saml="` + nsAssertion + `"`)
+	req.WriteString(` ID="` + id + `" Version="2.0" IssueInstant="` + now.UTC().Format(time.RFC3339) + `"`)
+	req.WriteString(` Destination="` + escapeXML(idp.SSOURL) + `" AssertionConsumerServiceURL="` + escapeXML(sp.cfg.ACSURL) + `"`)
+	req.WriteString(` ProtocolBinding="` + bindingHTTPPost + `">`)
+	req.WriteString(`<saml:Issuer>` + escapeXML(sp.cfg.EntityID) + `</saml:Issuer>`)
+	req.WriteString(`<samlp:NameIDPolicy Format="` + nameIDFormatUnspec + `" AllowCreate="true"/>`)
+	req.WriteString(`</samlp:AuthnRequest>`)
+
+	var deflated bytes.Buffer
+	w, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	w.Write(req.Bytes())
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(idp.SSOURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid SSO URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	u.RawQuery = q.Encode()
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	for pendingID, p := range sp.pending {
+		if now.After(p.expires) {
+			delete(sp.pending, pendingID)
+		}
+	}
+	sp.pending[id] = pendingRequest{redirectTo: redirectTo, expires: now.Add(sp.cfg.RequestMaxAge)}
+
+	return u.String(), nil
+}
+
+// ParseResponse validates a base64 encoded SAMLResponse posted to the
+// assertion consumer service by idp. It returns the assertion and the
+// redirectTo of the request it answers. The response or its assertion must
+// be signed by one of idp's certificates; encrypted assertions are not
+// supported.
+func (sp *ServiceProvider) ParseResponse(idp *IdPMetadata, encoded string) (*Assertion, string, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: invalid encoding", ErrInvalidResponse)
+	}
+	resp, err := parseXML(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if !resp.is(nsProtocol, "Response") {
+		return nil, "", fmt.Errorf("%w: not a Response", ErrInvalidResponse)
+	}
+
+	responseSigned := false
+	switch err := verifySignature(resp, idp.Certificates); {
+	case err == nil:
+		responseSigned = true
+	case !errors.Is(err, errNotSigned):
+		return nil, "", fmt.Errorf("%w: response signature: %v", ErrInvalidResponse, err)
+	}
+
+	// The request is consumed before anything else is checked, so a
+	// response can only ever be tried once.
+	requestID := resp.attr("InResponseTo")
+	redirectTo, ok := sp.consumeRequest(requestID)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: does not answer a pending request", ErrInvalidResponse)
+	}
+
+	if dest := resp.attr("Destination"); dest != "" && dest != sp.cfg.ACSURL {
+		return nil, "", fmt.Errorf("%w: wrong destination %q", ErrInvalidResponse, dest)
+	}
+	if issuer := resp.child(nsAssertion, "Issuer"); issuer != nil && strings.TrimSpace(issuer.text()) != idp.EntityID {
+		return nil, "", fmt.Errorf("%w: wrong issuer", ErrInvalidResponse)
+	}
+	if status := statusCode(resp); status != statusSuccess {
+		return nil, "", fmt.Errorf("%w: identity provider returned status %q", ErrInvalidResponse, status)
+	}
+
+	if resp.child(nsAssertion, "EncryptedAssertion") != nil {
+		return nil, "", fmt.Errorf("%w: encrypted assertions are not supported", ErrInvalidResponse)
+	}
+	assertions := resp.childElements(nsAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, "", fmt.Errorf("%w: expected exactly one assertion", ErrInvalidResponse)
+	}
+	assertion := assertions[0]
+
+	switch err := verifySignature(assertion, idp.Certificates); {
+	case errors.Is(err, errNotSigned):
+		if !responseSigned {
+			return nil, "", fmt.Errorf("%w: neither the response nor the assertion is signed", ErrInvalidResponse)
+		}
+	case err != nil:
+		return nil, "", fmt.Errorf("%w: assertion signature: %v", ErrInvalidResponse, err)
+	}
+
+	a, err := sp.checkAssertion(idp, assertion, requestID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return a, redirectTo, nil
+}
+
+// consumeRequest removes a pending request, reporting whether it existed
+// and had not expired.
+func (sp *ServiceProvider) consumeRequest(id string) (string, bool) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	p, ok := sp.pending[id]
+	if !ok {
+		return "", false
+	}
+	delete(sp.pending, id)
+	if sp.now().After(p.expires) {
+		return "", false
+	}
+	return p.redirectTo, true
+}
+
+// checkAssertion checks an assertion's issuer, subject confirmation and
+// conditions, and extracts its subject and attributes.
+func (sp *ServiceProvider) checkAssertion(idp *IdPMetadata, assertion *element, requestID string) (*Assertion, error) {
+	now := sp.now()
+
+	issuer := assertion.child(nsAssertion, "Issuer")
+	if issuer == nil || strings.TrimSpace(issuer.text()) != idp.EntityID {
+		return nil, errors.New("wrong assertion issuer")
+	}
+
+	subject := assertion.child(nsAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("assertion has no subject")
+	}
+	nameID := subject.child(nsAssertion, "NameID")
+	if nameID == nil || strings.TrimSpace(nameID.text()) == "" {
+		return nil, errors.New("assertion has no NameID")
+	}
+
+	confirmed := false
+	for _, sc := range subject.childElements(nsAssertion, "SubjectConfirmation") {
+		if sc.attr("Method") != confirmationBearer {
+			continue
+		}
+		data := sc.child(nsAssertion, "SubjectConfirmationData")
+		if data == nil || data.attr("Recipient") != sp.cfg.ACSURL {
+			continue
+		}
+		if irt := data.attr("InResponseTo"); irt != "" && irt != requestID {
+			continue
+		}
+		notOnOrAfter, err := parseTime(data.attr("NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(sp.cfg.ClockSkew)) {
+			continue
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return nil, errors.New("no valid bearer subject confirmation")
+	}
+
+	conditions := assertion.child(nsAssertion, "Conditions")
+	if conditions == nil {
+		return nil, errors.New("assertion has no conditions")
+	}
+	if v := conditions.attr("NotBefore"); v != "" {
+		notBefore, err := parseTime(v)
+		if err != nil || now.Add(sp.cfg.ClockSkew).Before(notBefore) {
+			return nil, errors.New("assertion is not yet valid")
+		}
+	}
+	if v := conditions.attr("NotOnOrAfter"); v != "" {
+		notOnOrAfter, err := parseTime(v)
+		if err != nil || !now.Before(notOnOrAfter.Add(sp.cfg.ClockSkew)) {
+			return nil, errors.New("assertion has expired")
+		}
+	}
+	restrictions := conditions.childElements(nsAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, errors.New("assertion has no audience restriction")
+	}
+	for _, r := range restrictions {
+		if !hasAudience(r, sp.cfg.EntityID) {
+			return nil, errors.New("assertion is not intended for this service provider")
+		}
+	}
+
+	a := &Assertion{
+		NameID:     strings.TrimSpace(nameID.text()),
+		Attributes: make(map[string][]string),
+	}
+	if authn := assertion.child(nsAssertion, "AuthnStatement"); authn != nil {
+		a.SessionIndex = authn.attr("SessionIndex")
+	}
+	for _, stmt := range assertion.childElements(nsAssertion, "AttributeStatement") {
+		for _, attr := range stmt.childElements(nsAssertion, "Attribute") {
+			var values []string
+			for _, v := range attr.childElements(nsAssertion, "AttributeValue") {
+				values = append(values, strings.TrimSpace(v.text()))
+			}
+			for _, name := range []string{attr.attr("Name"), attr.attr("FriendlyName")} {
+				if name != "" {
+					a.Attributes[name] = append(a.Attributes[name], values...)
+				}
+			}
+		}
+	}
+	return a, nil
+}
+
+// statusCode returns the top-level status code of a response.
+func statusCode(resp *element) string {
+	status := resp.child(nsProtocol, "Status")
+	if status == nil {
+		return ""
+	}
+	code := status.child(nsProtocol, "StatusCode")
+	if code == nil {
+		return ""
+	}
+	return code.attr("Value")
+}
+
+// hasAudience reports whether an AudienceRestriction lists entityID.
+func hasAudience(restriction *element, entityID string) bool {
+	for _, aud := range restriction.childElements(nsAssertion, "Audience") {
+		if strings.TrimSpace(aud.text()) == entityID {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTime parses an xs:dateTime.
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(s))
+}
+
+// newRequestID returns a random request ID. IDs must not start with a
+// digit, so they are prefixed with an underscore.
+func newRequestID() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "_" + hex.EncodeToString(b), nil
+}
+
+// escapeXML escapes s for use in XML text or a quoted attribute.
+func escapeXML(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,263 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServiceProvider creates a service provider whose clock is now.
+func newTestServiceProvider(now time.Time) *ServiceProvider {
+	sp := NewServiceProvider(Config{EntityID: testSPEntityID, ACSURL: testACSURL})
+	sp.now = func() time.Time { return now }
+	return sp
+}
+
+// startLogin starts a login and returns the ID of its request.
+func startLogin(t *testing.T, sp *ServiceProvider, idp *IdPMetadata, redirectTo string) string {
+	loginURL, err := sp.AuthnRequestURL(idp, redirectTo)
+	require.NoError(t, err)
+
+	u, err := url.Parse(loginURL)
+	require.NoError(t, err)
+	deflated, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	req, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.NoError(t, err)
+
+	root, err := parseXML(req)
+	require.NoError(t, err)
+	require.True(t, root.is(nsProtocol, "AuthnRequest"))
+	return root.attr("ID")
+}
+
+func TestServiceProvider_AuthnRequestURL(t *testing.T) {
+	idp := newTestIdP(t)
+	sp := newTestServiceProvider(time.Now())
+	md := idp.metadata()
+	md.SSOURL = testSSOURL + "?tenant=acme"
+
+	loginURL, err := sp.AuthnRequestURL(md, "/projects")
+	require.NoError(t, err)
+
+	u, err := url.Parse(loginURL)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", u.Host)
+	assert.Equal(t, "acme", u.Query().Get("tenant"))
+
+	deflated, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	req, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.NoError(t, err)
+
+	root, err := parseXML(req)
+	require.NoError(t, err)
+	assert.True(t, root.is(nsProtocol, "AuthnRequest"))
+	assert.Equal(t, testACSURL, root.attr("AssertionConsumerServiceURL"))
+	assert.Equal(t, bindingHTTPPost, root.attr("ProtocolBinding"))
+	assert.True(t, strings.HasPrefix(root.attr("ID"), "_"))
+	issuer := root.child(nsAssertion, "Issuer")
+	require.NotNil(t, issuer)
+	assert.Equal(t, testSPEntityID, issuer.text())
+}
+
+func TestServiceProvider_Metadata(t *testing.T) {
+	sp := newTestServiceProvider(time.Now())
+
+	root, err := parseXML(sp.Metadata())
+	require.NoError(t, err)
+	assert.Equal(t, testSPEntityID, root.attr("entityID"))
+	desc := root.child(nsMetadata, "SPSSODescriptor")
+	require.NotNil(t, desc)
+	acs := desc.child(nsMetadata, "AssertionConsumerService")
+	require.NotNil(t, acs)
+	assert.Equal(t, testACSURL, acs.attr("Location"))
+}
+
+func TestServiceProvider_ParseResponse(t *testing.T) {
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	idp := newTestIdP(t)
+	md := idp.metadata()
+
+	t.Run("signed assertion", func(t *testing.T) {
+		sp := newTestServiceProvider(now)
+		requestID := startLogin(t, sp, md, "/projects/1")
+		doc := idp.sign(t, buildResponse(t, newResponseParams(requestID, now)), "_assertion")
+
+		a, redirectTo, err := sp.ParseResponse(md, encodeResponse(doc))
+		require.NoError(t, err)
+		assert.Equal(t, "/projects/1", redirectTo)
+		assert.Equal(t, "jane@example.com", a.NameID)
+		assert.Equal(t, "_session", a.SessionIndex)
+		assert.Equal(t, "jane@example.com", a.Attribute("email"))
+		assert.Equal(t, "jane@example.com", a.Attribute("http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress"))
+		assert.Equal(t, []string{"qa", "admins"}, a.Attributes["groups"])
+		assert.Equal(t, "", a.Attribute("missing"))
+	})
+
+	t.Run("signed response", func(t *testing.T) {
+		sp := newTestServiceProvider(now)
+		requestID := startLogin(t, sp, md, "/")
+		doc := idp.sign(t, buildResponse(t, newResponseParams(requestID, now)), "_response")
+
+		a, _, err := sp.ParseResponse(md, encodeResponse(doc))
+		require.NoError(t, err)
+		assert.Equal(t, "jane@example.com", a.NameID)
+	})
+
+	t.Run("a response is accepted once", func(t *testing.T) {
+		sp := newTestServiceProvider(now)
+		requestID := startLogin(t, sp, md, "/")
+		encoded := encodeResponse(idp.sign(t, buildResponse(t, newResponseParams(requestID, now)), "_assertion"))
+
+		_, _, err := sp.ParseResponse(md, encoded)
+		require.NoError(t, err)
+		_, _, err = sp.ParseResponse(md, encoded)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("request expires", func(t *testing.T) {
+		sp := newTestServiceProvider(now)
+		requestID := startLogin(t, sp, md, "/")
+		later := now.Add(defaultRequestMaxAge + time.Minute)
+		sp.now = func() time.Time { return later }
+		doc := idp.sign(t, buildResponse(t, newResponseParams(requestID, later)), "_assertion")
+
+		_, _, err := sp.ParseResponse(md, encodeResponse(doc))
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	rejected := []struct {
+		name   string
+		modify func(p *responseParams)
+		build  func(t *testing.T, doc string) string
+	}{
+		{
+			name: "unsigned",
+			build: func(t *testing.T, doc string) string {
+				return doc
+			},
+		},
+		{
+			name: "unsolicited",
+			modify: func(p *responseParams) {
+				p.RequestID = "_unknown"
+			},
+		},
+		{
+			name: "signed by another identity provider",
+			build: func(t *testing.T, doc string) string {
+				return newTestIdP(t).sign(t, doc, "_assertion")
+			},
+		},
+		{
+			name: "assertion changed after signing",
+			build: func(t *testing.T, doc string) string {
+				signed := idp.sign(t, doc, "_assertion")
+				return strings.Replace(signed, "<saml:AttributeValue>jane@example.com", "<saml:AttributeValue>admin@example.com", 1)
+			},
+		},
+		{
+			name: "signed assertion wrapped beside an unsigned one",
+			build: func(t *testing.T, doc string) string {
+				signed := idp.sign(t, doc, "_assertion")
+				start := strings.Index(signed, "<saml:Assertion")
+				end := strings.Index(signed, "</saml:Assertion>") + len("</saml:Assertion>")
+				forged := strings.ReplaceAll(doc[strings.Index(doc, "<saml:Assertion"):strings.Index(doc, "</saml:Assertion>")+len("</saml:Assertion>")],
+					"jane@example.com", "admin@example.com")
+				forged = strings.Replace(forged, `ID="_assertion"`, `ID="_forged"`, 1)
+				return signed[:start] + forged + signed[start:end] + signed[end:]
+			},
+		},
+		{
+			name: "failure status",
+			modify: func(p *responseParams) {
+				p.Status = "urn:oasis:names:tc:SAML:2.0:status:Requester"
+			},
+		},
+		{
+			name: "wrong issuer",
+			modify: func(p *responseParams) {
+				p.Issuer = "https://evil.example.com"
+			},
+		},
+		{
+			name: "wrong destination",
+			modify: func(p *responseParams) {
+				p.Destination = "https://other.example.com/acs"
+			},
+		},
+		{
+			name: "wrong recipient",
+			modify: func(p *responseParams) {
+				p.Recipient = "https://other.example.com/acs"
+			},
+		},
+		{
+			name: "wrong audience",
+			modify: func(p *responseParams) {
+				p.Audience = "https://other.example.com"
+			},
+		},
+		{
+			name: "expired",
+			modify: func(p *responseParams) {
+				p.NotOnOrAfter = now.Add(-time.Hour).Format(time.RFC3339)
+			},
+		},
+		{
+			name: "not yet valid",
+			modify: func(p *responseParams) {
+				p.NotBefore = now.Add(time.Hour).Format(time.RFC3339)
+			},
+		},
+	}
+
+	for _, tt := range rejected {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			sp := newTestServiceProvider(now)
+			requestID := startLogin(t, sp, md, "/")
+			p := newResponseParams(requestID, now)
+			if tt.modify != nil {
+				tt.modify(&p)
+			}
+			doc := buildResponse(t, p)
+			if tt.build != nil {
+				doc = tt.build(t, doc)
+			} else {
+				doc = idp.sign(t, doc, "_assertion")
+			}
+
+			_, _, err := sp.ParseResponse(md, encodeResponse(doc))
+			assert.ErrorIs(t, err, ErrInvalidResponse)
+		})
+	}
+
+	t.Run("rejects encrypted assertions", func(t *testing.T) {
+		sp := newTestServiceProvider(now)
+		requestID := startLogin(t, sp, md, "/")
+		doc := buildResponse(t, newResponseParams(requestID, now))
+		start := strings.Index(doc, "<saml:Assertion")
+		end := strings.Index(doc, "</saml:Assertion>") + len("</saml:Assertion>")
+		doc = doc[:start] + `<saml:EncryptedAssertion></saml:EncryptedAssertion>` + doc[end:]
+		doc = idp.sign(t, doc, "_response")
+
+		_, _, err := sp.ParseResponse(md, encodeResponse(doc))
+		require.ErrorIs(t, err, ErrInvalidResponse)
+		assert.Contains(t, err.Error(), "encrypted")
+	})
+
+	t.Run("rejects invalid encoding", func(t *testing.T) {
+		sp := newTestServiceProvider(now)
+		_, _, err := sp.ParseResponse(md, "not base64!")
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
@@ -0,0 +1,17 @@
+package saml
+
+import (
+	"context"
+)
+
+// Store defines the interface for identity provider persistence operations.
+type Store interface {
+	// Get retrieves the configured identity provider.
+	Get(ctx context.Context) (*IdentityProvider, error)
+
+	// Save replaces the configured identity provider with idp.
+	Save(ctx context.Context, idp *IdentityProvider) error
+
+	// Delete removes the configured identity provider.
+	Delete(ctx context.Context) error
+}
@@ -0,0 +1,302 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmlNamespace is the namespace bound to the reserved xml prefix.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// element is an XML element parsed with its namespace prefixes kept as
+// written, which signature verification needs to canonicalize it exactly.
+// Comments and processing instructions are dropped while parsing.
+type element struct {
+	parent   *element
+	prefix   string
+	local    string
+	attrs    []xml.Attr
+	children []xmlNode
+}
+
+// xmlNode is a child of an element: an *element or a text string.
+type xmlNode interface{}
+
+// parseXML parses a document into its root element. Documents with a
+// DOCTYPE are rejected, since SAML messages never need one.
+func parseXML(data []byte) (*element, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root, cur *element
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if root != nil && cur == nil {
+				return nil, errors.New("invalid XML: multiple root elements")
+			}
+			el := &element{parent: cur, prefix: t.Name.Space, local: t.Name.Local, attrs: t.Attr}
+			if cur == nil {
+				root = el
+			} else {
+				cur.children = append(cur.children, el)
+			}
+			cur = el
+		case xml.EndElement:
+			if cur == nil || t.Name.Space != cur.prefix || t.Name.Local != cur.local {
+				return nil, fmt.Errorf("invalid XML: unexpected end element %s", t.Name.Local)
+			}
+			cur = cur.parent
+		case xml.CharData:
+			if cur != nil {
+				cur.children = append(cur.children, string(t))
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, errors.New("invalid XML: text outside the root element")
+			}
+		case xml.Directive:
+			return nil, errors.New("invalid XML: directives are not allowed")
+		}
+	}
+	if root == nil {
+		return nil, errors.New("invalid XML: no root element")
+	}
+	if cur != nil {
+		return nil, errors.New("invalid XML: unclosed element")
+	}
+	return root, nil
+}
+
+// lookupNamespace returns the namespace bound to prefix in scope at e. The
+// empty prefix looks up the default namespace.
+func (e *element) lookupNamespace(prefix string) string {
+	if prefix == "xml" {
+		return xmlNamespace
+	}
+	for el := e; el != nil; el = el.parent {
+		for _, a := range el.attrs {
+			if prefix == "" && a.Name.Space == "" && a.Name.Local == "xmlns" {
+				return a.Value
+			}
+			if prefix != "" && a.Name.Space == "xmlns" && a.Name.Local == prefix {
+				return a.Value
+			}
+		}
+	}
+	return ""
+}
+
+// is reports whether e is the element local in namespace ns.
+func (e *element) is(ns, local string) bool {
+	return e.local == local && e.lookupNamespace(e.prefix) == ns
+}
+
+// attr returns the value of the unqualified attribute name.
+func (e *element) attr(name string) string {
+	for _, a := range e.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// childElements returns the child elements local in namespace ns.
+func (e *element) childElements(ns, local string) []*element {
+	var found []*element
+	for _, c := range e.children {
+		if el, ok := c.(*element); ok && el.is(ns, local) {
+			found = append(found, el)
+		}
+	}
+	return found
+}
+
+// child returns the first child element local in namespace ns, or nil.
+func (e *element) child(ns, local string) *element {
+	if found := e.childElements(ns, local); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// text returns the concatenated text content of e and its descendants.
+func (e *element) text() string {
+	var sb strings.Builder
+	for _, c := range e.children {
+		switch n := c.(type) {
+		case string:
+			sb.WriteString(n)
+		case *element:
+			sb.WriteString(n.text())
+		}
+	}
+	return sb.String()
+}
+
+// isNamespaceDecl reports whether a is an xmlns or xmlns:prefix attribute.
+func isNamespaceDecl(a xml.Attr) bool {
+	return a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns")
+}
+
+// canonicalize serializes e with Exclusive XML Canonicalization 1.0,
+// omitting comments. The exclude element, if not nil, is left out along with
+// its descendants, which implements the enveloped signature transform.
+// inclusivePrefixes lists prefixes ("#default" for the default namespace)
+// to treat as in inclusive canonicalization.
+func canonicalize(e, exclude *element, inclusivePrefixes []string) []byte {
+	c := canonicalizer{exclude: exclude, inclusive: make(map[string]bool)}
+	for _, p := range inclusivePrefixes {
+		if p == "#default" {
+			p = ""
+		}
+		c.inclusive[p] = true
+	}
+	c.element(e, map[string]string{})
+	return c.buf.Bytes()
+}
+
+type canonicalizer struct {
+	buf       bytes.Buffer
+	exclude   *element
+	inclusive map[string]bool
+}
+
+// element writes e given the namespace declarations already rendered by its
+// output ancestors.
+func (c *canonicalizer) element(e *element, rendered map[string]string) {
+	// Exclusive canonicalization renders only the namespaces an element
+	// visibly uses, plus any listed as inclusive, unless an output ancestor
+	// already rendered the same binding.
+	used := map[string]bool{e.prefix: true}
+	for _, a := range e.attrs {
+		if !isNamespaceDecl(a) && a.Name.Space != "" && a.Name.Space != "xml" {
+			used[a.Name.Space] = true
+		}
+	}
+	for p := range c.inclusive {
+		if p == "" || e.hasNamespaceInScope(p) {
+			used[p] = true
+		}
+	}
+
+	scope := make(map[string]string, len(rendered))
+	for p, ns := range rendered {
+		scope[p] = ns
+	}
+	var prefixes []string
+	for p := range used {
+		ns := e.lookupNamespace(p)
+		prev, ok := rendered[p]
+		if p == "" && ns == "" && (!ok || prev == "") {
+			// An empty default namespace only needs undeclaring if an
+			// ancestor rendered a non-empty one.
+			continue
+		}
+		if ok && prev == ns {
+			continue
+		}
+		scope[p] = ns
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+
+	var attrs []xml.Attr
+	for _, a := range e.attrs {
+		if !isNamespaceDecl(a) {
+			attrs = append(attrs, a)
+		}
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		nsi, nsj := e.attrNamespace(attrs[i]), e.attrNamespace(attrs[j])
+		if nsi != nsj {
+			return nsi < nsj
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+
+	name := qualifiedName(e.prefix, e.local)
+	c.buf.WriteString("<" + name)
+	for _, p := range prefixes {
+		if p == "" {
+			c.buf.WriteString(` xmlns="`)
+		} else {
+			c.buf.WriteString(" xmlns:" + p + `="`)
+		}
+		writeEscaped(&c.buf, scope[p], true)
+		c.buf.WriteString(`"`)
+	}
+	for _, a := range attrs {
+		c.buf.WriteString(" " + qualifiedName(a.Name.Space, a.Name.Local) + `="`)
+		writeEscaped(&c.buf, a.Value, true)
+		c.buf.WriteString(`"`)
+	}
+	c.buf.WriteString(">")
+
+	for _, child := range e.children {
+		switch n := child.(type) {
+		case string:
+			writeEscaped(&c.buf, n, false)
+		case *element:
+			if n != c.exclude {
+				c.element(n, scope)
+			}
+		}
+	}
+	c.buf.WriteString("</" + name + ">")
+}
+
+// hasNamespaceInScope reports whether prefix is bound at e.
+func (e *element) hasNamespaceInScope(prefix string) bool {
+	return e.lookupNamespace(prefix) != ""
+}
+
+// attrNamespace returns the namespace of an attribute. Unprefixed
+// attributes have no namespace.
+func (e *element) attrNamespace(a xml.Attr) string {
+	if a.Name.Space == "" {
+		return ""
+	}
+	return e.lookupNamespace(a.Name.Space)
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+// writeEscaped writes s escaped as canonical XML text or attribute content.
+func writeEscaped(buf *bytes.Buffer, s string, attr bool) {
+	for _, r := range s {
+		switch {
+		case r == '&':
+			buf.WriteString("&amp;")
+		case r == '<':
+			buf.WriteString("&lt;")
+		case r == '>' && !attr:
+			buf.WriteString("&gt;")
+		case r == '"' && attr:
+			buf.WriteString("&quot;")
+		case r == '\t' && attr:
+			buf.WriteString("&#x9;")
+		case r == '\n' && attr:
+			buf.WriteString("&#xA;")
+		case r == '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package saml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseXML(t *testing.T) {
+	t.Run("rejects a DOCTYPE", func(t *testing.T) {
+		_, err := parseXML([]byte(`<!DOCTYPE r [<!ENTITY e "x">]><r>&e;</r>`))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects mismatched end elements", func(t *testing.T) {
+		_, err := parseXML([]byte(`<a:r xmlns:a="urn:a"></b:r>`))
+		assert.Error(t, err)
+	})
+
+	t.Run("resolves namespaces through ancestors", func(t *testing.T) {
+		root, err := parseXML([]byte(`<r xmlns="urn:d" xmlns:a="urn:a"><a:c><d/></a:c></r>`))
+		require.NoError(t, err)
+		c := root.child("urn:a", "c")
+		require.NotNil(t, c)
+		assert.NotNil(t, c.child("urn:d", "d"))
+	})
+}
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		path      []string
+		exclude   string
+		inclusive []string
+		want      string
+	}{
+		{
+			name: "namespaces used by a subset are rendered on it",
+			doc:  `<a:Root xmlns:a="urn:a" xmlns:b="urn:b" xmlns:c="urn:c"><a:Child z="2" b:attr="1" y='q"q'>x &amp; y &gt; z</a:Child></a:Root>`,
+			path: []string{"Child"},
+			want: `<a:Child xmlns:a="urn:a" xmlns:b="urn:b" y="q&quot;q" z="2" b:attr="1">x &amp; y &gt; z</a:Child>`,
+		},
+		{
+			name: "unused namespaces are dropped and empty elements expanded",
+			doc:  `<Root xmlns="urn:d" xmlns:u="urn:unused"><!-- note --><Empty/></Root>`,
+			want: `<Root xmlns="urn:d"><Empty></Empty></Root>`,
+		},
+		{
+			name:      "inclusive prefixes are rendered when in scope",
+			doc:       `<Root xmlns="urn:d" xmlns:u="urn:unused"><Empty/></Root>`,
+			inclusive: []string{"u", "missing"},
+			want:      `<Root xmlns="urn:d" xmlns:u="urn:unused"><Empty></Empty></Root>`,
+		},
+		{
+			name: "declarations are not repeated on descendants",
+			doc:  `<a:Root xmlns:a="urn:a"><a:Child xmlns:a="urn:a"><a:Leaf xmlns:a="urn:other"/></a:Child></a:Root>`,
+			want: `<a:Root xmlns:a="urn:a"><a:Child><a:Leaf xmlns:a="urn:other"></a:Leaf></a:Child></a:Root>`,
+		},
+		{
+			name: "default namespace is undeclared below a rendered one",
+			doc:  `<Root xmlns="urn:d"><Child xmlns=""/></Root>`,
+			want: `<Root xmlns="urn:d"><Child xmlns=""></Child></Root>`,
+		},
+		{
+			name:    "excluded element is left out",
+			doc:     `<Root ID="r">before<Signature><Value>v</Value></Signature>after</Root>`,
+			exclude: "Signature",
+			want:    `<Root ID="r">beforeafter</Root>`,
+		},
+		{
+			name: "attribute whitespace and carriage returns are escaped",
+			doc:  "<Root a=\"x&#9;y&#10;z&#13;\">t&#13;</Root>",
+			want: `<Root a="x&#x9;y&#xA;z&#xD;">t&#xD;</Root>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseXML([]byte(tt.doc))
+			require.NoError(t, err)
+
+			el := root
+			for _, local := range tt.path {
+				el = childByLocal(el, local)
+				require.NotNil(t, el)
+			}
+			var exclude *element
+			if tt.exclude != "" {
+				exclude = childByLocal(el, tt.exclude)
+				require.NotNil(t, exclude)
+			}
+
+			assert.Equal(t, tt.want, string(canonicalize(el, exclude, tt.inclusive)))
+		})
+	}
+}
+
+// childByLocal returns the first child element with the given local name.
+func childByLocal(e *element, local string) *element {
+	for _, c := range e.children {
+		if el, ok := c.(*element); ok && el.local == local {
+			return el
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package savedview
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and saved view store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &SavedView{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestView creates a saved view with default values.
+func createTestView(name string, projectID, ownerID uuid.UUID, resource Resource, shared bool) *SavedView {
+	return &SavedView{
+		Name:      name,
+		ProjectID: projectID,
+		OwnerID:   ownerID,
+		Resource:  resource,
+		Shared:    shared,
+	}
+}
@@ -0,0 +1,144 @@
+package savedview
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed saved view store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new saved view in the database.
+func (s *MySQLStore) Create(ctx context.Context, view *SavedView) error {
+	if err := view.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(view).Error; err != nil {
+		s.logger.Error(ctx, "failed to create saved view", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": view.ProjectID.String(),
+			"owner_id":   view.OwnerID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "saved view created", map[string]interface{}{
+		"saved_view_id": view.ID.String(),
+		"project_id":    view.ProjectID.String(),
+	})
+
+	return nil
+}
+
+// GetByID retrieves a saved view by its ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*SavedView, error) {
+	var view SavedView
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&view).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSavedViewNotFound
+		}
+		s.logger.Error(ctx, "failed to get saved view by ID", map[string]interface{}{
+			"error":         err.Error(),
+			"saved_view_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &view, nil
+}
+
+// Update updates a saved view with the given setters.
+func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error {
+	view, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, setter := range setters {
+		if err := setter(view); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Save(view).Error; err != nil {
+		s.logger.Error(ctx, "failed to update saved view", map[string]interface{}{
+			"error":         err.Error(),
+			"saved_view_id": id.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "saved view updated", map[string]interface{}{
+		"saved_view_id": id.String(),
+	})
+
+	return nil
+}
+
+// Delete deletes a saved view by its ID.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&SavedView{}, "id = ?", id)
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete saved view", map[string]interface{}{
+			"error":         result.Error.Error(),
+			"saved_view_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrSavedViewNotFound
+	}
+
+	s.logger.Info(ctx, "saved view deleted", map[string]interface{}{
+		"saved_view_id": id.String(),
+	})
+
+	return nil
+}
+
+// ListVisible retrieves the views in a project that the user owns or that are shared.
+func (s *MySQLStore) ListVisible(ctx context.Context, projectID, userID uuid.UUID, resource Resource) ([]*SavedView, error) {
+	query := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Where("owner_id = ? OR shared = ?", userID, true)
+	if resource != "" {
+		query = query.Where("resource = ?", resource)
+	}
+
+	var views []*SavedView
+	err := query.
+		Order("name ASC").
+		Find(&views).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list saved views", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+			"user_id":    userID.String(),
+		})
+		return nil, err
+	}
+
+	return views, nil
+}
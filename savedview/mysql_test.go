@@ -0,0 +1,135 @@
+package savedview
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Create(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("create view with filters", func(t *testing.T) {
+		from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		assignee := uuid.New()
+		view := createTestView("Release 4.2 open failures", uuid.New(), uuid.New(), ResourceRuns, true)
+		view.Filters = Filters{
+			Statuses:  []string{"failed", "running"},
+			Tags:      []string{"release-4.2"},
+			Assignees: []uuid.UUID{assignee},
+			DateFrom:  &from,
+		}
+		require.NoError(t, store.Create(ctx, view))
+		assert.NotEqual(t, uuid.Nil, view.ID)
+
+		retrieved, err := store.GetByID(ctx, view.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Release 4.2 open failures", retrieved.Name)
+		assert.True(t, retrieved.Shared)
+		assert.Equal(t, []string{"failed", "running"}, retrieved.Filters.Statuses)
+		assert.Equal(t, []string{"release-4.2"}, retrieved.Filters.Tags)
+		assert.Equal(t, []uuid.UUID{assignee}, retrieved.Filters.Assignees)
+		require.NotNil(t, retrieved.Filters.DateFrom)
+		assert.True(t, from.Equal(*retrieved.Filters.DateFrom))
+		assert.Nil(t, retrieved.Filters.DateTo)
+	})
+
+	t.Run("invalid view returns error", func(t *testing.T) {
+		view := createTestView("", uuid.New(), uuid.New(), ResourceRuns, false)
+		err := store.Create(ctx, view)
+		assert.ErrorIs(t, err, ErrInvalidName)
+	})
+}
+
+func TestMySQLStore_GetByID(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	_, err := store.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrSavedViewNotFound)
+}
+
+func TestMySQLStore_Update(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("update name, filters and sharing", func(t *testing.T) {
+		view := createTestView("Old", uuid.New(), uuid.New(), ResourceRuns, false)
+		require.NoError(t, store.Create(ctx, view))
+
+		err := store.Update(ctx, view.ID,
+			SetName("New"),
+			SetFilters(Filters{Statuses: []string{"passed"}}),
+			SetShared(true),
+		)
+		require.NoError(t, err)
+
+		retrieved, err := store.GetByID(ctx, view.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "New", retrieved.Name)
+		assert.Equal(t, []string{"passed"}, retrieved.Filters.Statuses)
+		assert.True(t, retrieved.Shared)
+	})
+
+	t.Run("invalid status filter returns error", func(t *testing.T) {
+		view := createTestView("View", uuid.New(), uuid.New(), ResourceRuns, false)
+		require.NoError(t, store.Create(ctx, view))
+
+		err := store.Update(ctx, view.ID, SetFilters(Filters{Statuses: []string{"nope"}}))
+		assert.ErrorIs(t, err, ErrInvalidStatusFilter)
+	})
+
+	t.Run("update non-existent returns error", func(t *testing.T) {
+		err := store.Update(ctx, uuid.New(), SetName("New"))
+		assert.ErrorIs(t, err, ErrSavedViewNotFound)
+	})
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	view := createTestView("Doomed", uuid.New(), uuid.New(), ResourceRuns, false)
+	require.NoError(t, store.Create(ctx, view))
+
+	require.NoError(t, store.Delete(ctx, view.ID))
+	_, err := store.GetByID(ctx, view.ID)
+	assert.ErrorIs(t, err, ErrSavedViewNotFound)
+
+	err = store.Delete(ctx, view.ID)
+	assert.ErrorIs(t, err, ErrSavedViewNotFound)
+}
+
+func TestMySQLStore_ListVisible(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	projectID := uuid.New()
+	me := uuid.New()
+	teammate := uuid.New()
+
+	require.NoError(t, store.Create(ctx, createTestView("A mine", projectID, me, ResourceRuns, false)))
+	require.NoError(t, store.Create(ctx, createTestView("B shared", projectID, teammate, ResourceRuns, true)))
+	require.NoError(t, store.Create(ctx, createTestView("C private", projectID, teammate, ResourceRuns, false)))
+	require.NoError(t, store.Create(ctx, createTestView("D procedures", projectID, me, ResourceProcedures, false)))
+	require.NoError(t, store.Create(ctx, createTestView("E other project", uuid.New(), me, ResourceRuns, false)))
+
+	t.Run("own and shared views for a resource", func(t *testing.T) {
+		views, err := store.ListVisible(ctx, projectID, me, ResourceRuns)
+		require.NoError(t, err)
+		require.Len(t, views, 2)
+		assert.Equal(t, "A mine", views[0].Name)
+		assert.Equal(t, "B shared", views[1].Name)
+	})
+
+	t.Run("all resources", func(t *testing.T) {
+		views, err := store.ListVisible(ctx, projectID, me, "")
+		require.NoError(t, err)
+		assert.Len(t, views, 3)
+	})
+}
@@ -0,0 +1,144 @@
+package savedview
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSavedViewNotFound is returned when a saved view is not found.
+	ErrSavedViewNotFound = errors.New("saved view not found")
+
+	// ErrInvalidName is returned when a saved view name is empty.
+	ErrInvalidName = errors.New("saved view name is required")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidOwnerID is returned when owner_id is not set.
+	ErrInvalidOwnerID = errors.New("owner_id is required")
+
+	// ErrInvalidResource is returned when the listing a view applies to is unknown.
+	ErrInvalidResource = errors.New("resource must be one of: runs, procedures")
+
+	// ErrInvalidStatusFilter is returned when a status filter is not a valid run status.
+	ErrInvalidStatusFilter = errors.New("invalid status filter")
+
+	// ErrInvalidDateRange is returned when date_from is after date_to.
+	ErrInvalidDateRange = errors.New("date_from must not be after date_to")
+)
+
+// Resource identifies the listing a saved view applies to.
+type Resource string
+
+const (
+	ResourceRuns       Resource = "runs"
+	ResourceProcedures Resource = "procedures"
+)
+
+// IsValid checks if the resource is valid.
+func (r Resource) IsValid() bool {
+	switch r {
+	case ResourceRuns, ResourceProcedures:
+		return true
+	default:
+		return false
+	}
+}
+
+// Filters holds the filter selections of a saved view. Each list is a
+// multi-select; an empty list means no filtering on that field.
+type Filters struct {
+	Statuses  []string    `json:"statuses,omitempty"`
+	Tags      []string    `json:"tags,omitempty"`
+	Assignees []uuid.UUID `json:"assignees,omitempty"`
+	DateFrom  *time.Time  `json:"date_from,omitempty"`
+	DateTo    *time.Time  `json:"date_to,omitempty"`
+}
+
+// Value implements the driver.Valuer interface for database storage.
+func (f Filters) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (f *Filters) Scan(value interface{}) error {
+	if value == nil {
+		*f = Filters{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan Filters: not a byte slice")
+	}
+
+	return json.Unmarshal(bytes, f)
+}
+
+// SavedView is a named set of listing filters. Views are private to their
+// owner unless shared, in which case everyone with access to the project
+// can use them.
+type SavedView struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:char(36);not null;index:idx_saved_views_project_id"`
+	OwnerID   uuid.UUID `json:"owner_id" gorm:"type:char(36);not null;index:idx_saved_views_owner_id"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
+	Resource  Resource  `json:"resource" gorm:"type:varchar(20);not null"`
+	Filters   Filters   `json:"filters" gorm:"type:json"`
+	Shared    bool      `json:"shared" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new saved view.
+func (v *SavedView) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the saved view has valid required fields.
+func (v *SavedView) Validate() error {
+	if v.Name == "" {
+		return ErrInvalidName
+	}
+	if v.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if v.OwnerID == uuid.Nil {
+		return ErrInvalidOwnerID
+	}
+	if !v.Resource.IsValid() {
+		return ErrInvalidResource
+	}
+	return v.Filters.validate(v.Resource)
+}
+
+// validate checks the filters against the resource they apply to.
+func (f Filters) validate(resource Resource) error {
+	if resource == ResourceRuns {
+		for _, status := range f.Statuses {
+			if !testrun.Status(status).IsValid() {
+				return ErrInvalidStatusFilter
+			}
+		}
+	}
+	if f.DateFrom != nil && f.DateTo != nil && f.DateFrom.After(*f.DateTo) {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// VisibleTo reports whether the user may see the view. Callers are expected
+// to have already checked the user's access to the view's project.
+func (v *SavedView) VisibleTo(userID uuid.UUID) bool {
+	return v.Shared || v.OwnerID == userID
+}
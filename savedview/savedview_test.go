@@ -0,0 +1,65 @@
+package savedview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedView_Validate(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		modify  func(v *SavedView)
+		wantErr error
+	}{
+		{"valid view", func(v *SavedView) {}, nil},
+		{"missing name", func(v *SavedView) { v.Name = "" }, ErrInvalidName},
+		{"missing project", func(v *SavedView) { v.ProjectID = uuid.Nil }, ErrInvalidProjectID},
+		{"missing owner", func(v *SavedView) { v.OwnerID = uuid.Nil }, ErrInvalidOwnerID},
+		{"unknown resource", func(v *SavedView) { v.Resource = "jobs" }, ErrInvalidResource},
+		{"valid run statuses", func(v *SavedView) {
+			v.Filters.Statuses = []string{"failed", "running"}
+		}, nil},
+		{"invalid run status", func(v *SavedView) {
+			v.Filters.Statuses = []string{"broken"}
+		}, ErrInvalidStatusFilter},
+		{"procedure statuses are not run statuses", func(v *SavedView) {
+			v.Resource = ResourceProcedures
+			v.Filters.Statuses = []string{"draft"}
+		}, nil},
+		{"date range reversed", func(v *SavedView) {
+			v.Filters.DateFrom = &now
+			v.Filters.DateTo = &earlier
+		}, ErrInvalidDateRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := createTestView("Open failures", uuid.New(), uuid.New(), ResourceRuns, false)
+			tt.modify(v)
+			err := v.Validate()
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSavedView_VisibleTo(t *testing.T) {
+	owner := uuid.New()
+	other := uuid.New()
+
+	private := createTestView("Mine", uuid.New(), owner, ResourceRuns, false)
+	assert.True(t, private.VisibleTo(owner))
+	assert.False(t, private.VisibleTo(other))
+
+	shared := createTestView("Team", uuid.New(), owner, ResourceRuns, true)
+	assert.True(t, shared.VisibleTo(other))
+}
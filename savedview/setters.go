@@ -0,0 +1,32 @@
+package savedview
+
+// SetName returns an UpdateSetter that sets the saved view's name.
+func SetName(name string) UpdateSetter {
+	return func(v *SavedView) error {
+		if name == "" {
+			return ErrInvalidName
+		}
+		v.Name = name
+		return nil
+	}
+}
+
+// SetFilters returns an UpdateSetter that replaces the saved view's filters.
+func SetFilters(filters Filters) UpdateSetter {
+	return func(v *SavedView) error {
+		if err := filters.validate(v.Resource); err != nil {
+			return err
+		}
+		v.Filters = filters
+		return nil
+	}
+}
+
+// SetShared returns an UpdateSetter that shares or unshares the saved view
+// with the rest of the project.
+func SetShared(shared bool) UpdateSetter {
+	return func(v *SavedView) error {
+		v.Shared = shared
+		return nil
+	}
+}
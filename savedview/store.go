@@ -0,0 +1,29 @@
+package savedview
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for saved view persistence operations.
+type Store interface {
+	// Create creates a new saved view in the store.
+	Create(ctx context.Context, view *SavedView) error
+
+	// GetByID retrieves a saved view by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*SavedView, error)
+
+	// Update updates a saved view with the given setters.
+	Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error
+
+	// Delete deletes a saved view by its ID.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListVisible retrieves the views in a project that the user owns or that
+	// are shared. An empty resource returns views for every listing.
+	ListVisible(ctx context.Context, projectID, userID uuid.UUID, resource Resource) ([]*SavedView, error)
+}
+
+// UpdateSetter is a function that updates a saved view field.
+type UpdateSetter func(*SavedView) error
@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
 	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
 )
 
@@ -46,11 +47,11 @@ func (g *BedrockGenerator) SetValidationConfig(cfg *ValidationConfig) {
 }
 
 // Generate creates a Python automation script using AWS Bedrock.
-func (g *BedrockGenerator) Generate(ctx context.Context, procedure *testprocedure.TestProcedure, framework Framework) ([]byte, error) {
+func (g *BedrockGenerator) Generate(ctx context.Context, procedure *testprocedure.TestProcedure, framework Framework, secretKeys []string) ([]byte, error) {
 	// Build the prompt with validation and sanitization
-	prompt, err := BuildPrompt(procedure, framework, g.validationCfg)
+	prompt, err := BuildPrompt(procedure, framework, secretKeys, g.validationCfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build prompt: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrPromptRejected, err)
 	}
 
 	// TODO: Add security logging here if logger is available
@@ -97,12 +98,14 @@ func (g *BedrockGenerator) Generate(ctx context.Context, procedure *testprocedur
 			Type string `json:"type"`
 			Text string `json:"text"`
 		} `json:"content"`
-		StopReason string `json:"stop_reason"`
+		StopReason string         `json:"stop_reason"`
+		Usage      llmusage.Usage `json:"usage"`
 	}
 
 	if err := json.Unmarshal(output.Body, &response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
+	llmusage.Add(ctx, response.Usage)
 
 	// Extract the generated code
 	if len(response.Content) == 0 {
@@ -0,0 +1,124 @@
+package scriptgen
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// bundleWorkflowPath is where the sample CI workflow is put in a bundle.
+const bundleWorkflowPath = ".github/workflows/ui-automation.yml"
+
+// Bundle is a generated script with the scaffolding needed to run it
+// outside of the service.
+type Bundle struct {
+	Script        *GeneratedScript
+	Content       []byte
+	ProcedureName string
+	// SecretKeys name the environment variables the script reads its
+	// endpoint's secrets from; it may be nil.
+	SecretKeys []string
+}
+
+// WriteZip writes the bundle as a ZIP holding the script, its
+// requirements.txt, a README with run instructions and a sample GitHub
+// Actions workflow.
+func (b *Bundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{b.Script.FileName, b.Content},
+		{"requirements.txt", []byte(b.requirements())},
+		{"README.md", []byte(b.readme())},
+		{bundleWorkflowPath, []byte(b.workflow())},
+	}
+	for _, file := range files {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("failed to create %s in bundle: %w", file.name, err)
+		}
+		if _, err := fw.Write(file.content); err != nil {
+			return fmt.Errorf("failed to write %s to bundle: %w", file.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+func (b *Bundle) requirements() string {
+	if b.Script.Framework == FrameworkSelenium {
+		// Selenium Manager, bundled since 4.6, fetches the browser driver.
+		return "selenium>=4.6\n"
+	}
+	return "playwright>=1.40\n"
+}
+
+// setupCommands are the shell commands installing the script's
+// dependencies.
+func (b *Bundle) setupCommands() []string {
+	commands := []string{"pip install -r requirements.txt"}
+	if b.Script.Framework == FrameworkPlaywright {
+		commands = append(commands, "playwright install --with-deps chromium")
+	}
+	return commands
+}
+
+func (b *Bundle) readme() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "# %s\n\n", b.ProcedureName)
+	fmt.Fprintf(&s, "A %s script generated from the test procedure \"%s\".\n\n", b.Script.Framework, b.ProcedureName)
+
+	s.WriteString("## Setup\n\nWith Python 3.9 or later:\n\n```sh\npython -m venv .venv\n. .venv/bin/activate\n")
+	for _, command := range b.setupCommands() {
+		s.WriteString(command + "\n")
+	}
+	s.WriteString("```\n\n")
+
+	if len(b.SecretKeys) > 0 {
+		s.WriteString("## Environment\n\nThe script reads these secrets from environment variables, which must be set before it runs:\n\n")
+		for _, key := range b.SecretKeys {
+			fmt.Fprintf(&s, "- `%s`\n", key)
+		}
+		s.WriteString("\n")
+	}
+
+	fmt.Fprintf(&s, "## Run\n\n```sh\npython %s\n```\n\n", b.Script.FileName)
+	s.WriteString("The script exits with a non-zero status when a step fails.\n\n")
+
+	s.WriteString("## CI\n\n")
+	fmt.Fprintf(&s, "`%s` runs the script with GitHub Actions on every push and pull request.", bundleWorkflowPath)
+	if len(b.SecretKeys) > 0 {
+		s.WriteString(" Add the secrets above to the repository's Actions secrets under the same names.")
+	}
+	s.WriteString(" CI runners have no display, so the browser must be launched headless there.\n")
+	return s.String()
+}
+
+func (b *Bundle) workflow() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "name: %s\n\n", yamlQuote(b.ProcedureName))
+	s.WriteString("on:\n  push:\n  pull_request:\n  workflow_dispatch:\n\n")
+	s.WriteString("jobs:\n  ui-test:\n    runs-on: ubuntu-latest\n    steps:\n")
+	s.WriteString("      - uses: actions/checkout@v4\n")
+	s.WriteString("      - uses: actions/setup-python@v5\n        with:\n          python-version: \"3.12\"\n")
+	s.WriteString("      - name: Install dependencies\n        run: |\n")
+	for _, command := range b.setupCommands() {
+		s.WriteString("          " + command + "\n")
+	}
+	s.WriteString("      - name: Run script\n")
+	if len(b.SecretKeys) > 0 {
+		s.WriteString("        env:\n")
+		for _, key := range b.SecretKeys {
+			fmt.Fprintf(&s, "          %s: ${{ secrets.%s }}\n", key, key)
+		}
+	}
+	fmt.Fprintf(&s, "        run: python %s\n", b.Script.FileName)
+	return s.String()
+}
+
+// yamlQuote quotes s as a double-quoted YAML scalar.
+func yamlQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+}
@@ -0,0 +1,70 @@
+package scriptgen
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readBundle(t *testing.T, b *Bundle) map[string]string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, b.WriteZip(&buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestBundle_WriteZip(t *testing.T) {
+	t.Run("playwright with secrets", func(t *testing.T) {
+		files := readBundle(t, &Bundle{
+			Script:        &GeneratedScript{Framework: FrameworkPlaywright, FileName: "login_playwright.py"},
+			Content:       []byte("print('login')\n"),
+			ProcedureName: `Login "happy" path`,
+			SecretKeys:    []string{"API_TOKEN", "PASSWORD"},
+		})
+
+		require.Len(t, files, 4)
+		assert.Equal(t, "print('login')\n", files["login_playwright.py"])
+		assert.Equal(t, "playwright>=1.40\n", files["requirements.txt"])
+
+		readme := files["README.md"]
+		assert.Contains(t, readme, "playwright install --with-deps chromium")
+		assert.Contains(t, readme, "- `API_TOKEN`")
+		assert.Contains(t, readme, "python login_playwright.py")
+
+		workflow := files[bundleWorkflowPath]
+		assert.Contains(t, workflow, `name: "Login \"happy\" path"`)
+		assert.Contains(t, workflow, "PASSWORD: ${{ secrets.PASSWORD }}")
+		assert.Contains(t, workflow, "run: python login_playwright.py")
+	})
+
+	t.Run("selenium without secrets", func(t *testing.T) {
+		files := readBundle(t, &Bundle{
+			Script:        &GeneratedScript{Framework: FrameworkSelenium, FileName: "login_selenium.py"},
+			Content:       []byte("print('login')\n"),
+			ProcedureName: "Login",
+		})
+
+		assert.Equal(t, "selenium>=4.6\n", files["requirements.txt"])
+		assert.NotContains(t, files["README.md"], "playwright install")
+		assert.NotContains(t, files["README.md"], "## Environment")
+		assert.NotContains(t, files[bundleWorkflowPath], "env:")
+	})
+}
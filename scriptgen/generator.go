@@ -9,6 +9,7 @@ import (
 // ScriptGenerator defines the interface for generating automation scripts.
 // Implementations can use different backends (AWS Bedrock, OpenAI, local templates, etc.)
 type ScriptGenerator interface {
-	// Generate creates a Python automation script from a test procedure
-	Generate(ctx context.Context, procedure *testprocedure.TestProcedure, framework Framework) ([]byte, error)
+	// Generate creates a Python automation script from a test procedure.
+	// secretKeys name the environment secrets the script may read; it may be nil.
+	Generate(ctx context.Context, procedure *testprocedure.TestProcedure, framework Framework, secretKeys []string) ([]byte, error)
 }
@@ -29,6 +29,9 @@ var (
 
 	// ErrScriptAlreadyExists is returned when a script already exists for the procedure and framework.
 	ErrScriptAlreadyExists = errors.New("script already exists for this procedure and framework")
+
+	// ErrPromptRejected is returned when a procedure fails validation before it reaches the LLM.
+	ErrPromptRejected = errors.New("failed to build prompt")
 )
 
 // Framework represents the automation framework type.
@@ -0,0 +1,294 @@
+package scriptgen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// maxFormattedScriptSize bounds the response read from the formatter service.
+const maxFormattedScriptSize = 5 * 1024 * 1024
+
+// ErrFormatterFailed is returned when the formatter service cannot format a
+// script. Formatting is cosmetic, so a PostProcessChain keeps the script as it
+// was instead of failing the generation.
+var ErrFormatterFailed = errors.New("formatter failed")
+
+// ScriptInfo describes the script being post-processed. It is the data
+// available to header and boilerplate templates.
+type ScriptInfo struct {
+	ProcedureName    string
+	ProcedureVersion uint
+	Framework        Framework
+	Year             int
+}
+
+// NewScriptInfo describes the script generated from procedure.
+func NewScriptInfo(procedure *testprocedure.TestProcedure, framework Framework) ScriptInfo {
+	return ScriptInfo{
+		ProcedureName:    procedure.Name,
+		ProcedureVersion: procedure.Version,
+		Framework:        framework,
+		Year:             time.Now().UTC().Year(),
+	}
+}
+
+// PostProcessor transforms a generated script before it is stored.
+type PostProcessor interface {
+	Process(ctx context.Context, script []byte, info ScriptInfo) ([]byte, error)
+}
+
+// PostProcessChain runs post-processors in order, each on the output of the
+// one before it. An empty chain returns scripts unchanged.
+type PostProcessChain struct {
+	processors []PostProcessor
+	logger     logger.Logger
+}
+
+// NewPostProcessChain creates a chain running processors in order.
+func NewPostProcessChain(log logger.Logger, processors ...PostProcessor) *PostProcessChain {
+	return &PostProcessChain{
+		processors: processors,
+		logger:     log,
+	}
+}
+
+// Process runs every processor of the chain on script.
+func (c *PostProcessChain) Process(ctx context.Context, script []byte, info ScriptInfo) ([]byte, error) {
+	if c == nil {
+		return script, nil
+	}
+
+	for _, processor := range c.processors {
+		processed, err := processor.Process(ctx, script, info)
+		if errors.Is(err, ErrFormatterFailed) {
+			c.logger.Warn(ctx, "script left unformatted", map[string]interface{}{
+				"error":     err.Error(),
+				"procedure": info.ProcedureName,
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		script = processed
+	}
+	return script, nil
+}
+
+// HeaderProcessor puts a comment, typically a license header, at the top of
+// scripts, after any shebang line.
+type HeaderProcessor struct {
+	header *template.Template
+}
+
+// NewHeaderProcessor creates a header processor from a text/template
+// rendered with a ScriptInfo. Lines of the rendered header that are not
+// already comments are commented out.
+func NewHeaderProcessor(header string) (*HeaderProcessor, error) {
+	tmpl, err := template.New("header").Option("missingkey=error").Parse(header)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script header template: %w", err)
+	}
+	return &HeaderProcessor{header: tmpl}, nil
+}
+
+// Process puts the header at the top of script.
+func (p *HeaderProcessor) Process(ctx context.Context, script []byte, info ScriptInfo) ([]byte, error) {
+	rendered, err := render(p.header, info)
+	if err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	for _, line := range strings.Split(strings.TrimRight(rendered, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "#"):
+			header.WriteString(line)
+		case strings.TrimSpace(line) == "":
+			header.WriteString("#")
+		default:
+			header.WriteString("# " + line)
+		}
+		header.WriteByte('\n')
+	}
+
+	var shebang []byte
+	if bytes.HasPrefix(script, []byte("#!")) {
+		end := bytes.IndexByte(script, '\n')
+		if end < 0 {
+			end = len(script) - 1
+		}
+		shebang, script = script[:end+1], script[end+1:]
+	}
+
+	out := make([]byte, 0, len(shebang)+header.Len()+1+len(script))
+	out = append(out, shebang...)
+	out = append(out, header.Bytes()...)
+	out = append(out, '\n')
+	return append(out, script...), nil
+}
+
+// BoilerplateProcessor injects company boilerplate into scripts: the
+// prologue right after their imports, so it can rely on them, and the
+// epilogue at their end.
+type BoilerplateProcessor struct {
+	prologue *template.Template
+	epilogue *template.Template
+}
+
+// NewBoilerplateProcessor creates a boilerplate processor from
+// text/templates rendered with a ScriptInfo. Either may be empty.
+func NewBoilerplateProcessor(prologue, epilogue string) (*BoilerplateProcessor, error) {
+	p := &BoilerplateProcessor{}
+	var err error
+	if prologue != "" {
+		if p.prologue, err = template.New("prologue").Option("missingkey=error").Parse(prologue); err != nil {
+			return nil, fmt.Errorf("invalid script prologue template: %w", err)
+		}
+	}
+	if epilogue != "" {
+		if p.epilogue, err = template.New("epilogue").Option("missingkey=error").Parse(epilogue); err != nil {
+			return nil, fmt.Errorf("invalid script epilogue template: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Process injects the boilerplate into script.
+func (p *BoilerplateProcessor) Process(ctx context.Context, script []byte, info ScriptInfo) ([]byte, error) {
+	lines := strings.SplitAfter(string(script), "\n")
+
+	var out strings.Builder
+	if p.prologue != nil {
+		prologue, err := render(p.prologue, info)
+		if err != nil {
+			return nil, err
+		}
+		at := afterImports(lines)
+		for _, line := range lines[:at] {
+			out.WriteString(line)
+		}
+		if at > 0 {
+			ensureNewline(&out)
+			out.WriteString("\n")
+		}
+		out.WriteString(strings.TrimRight(prologue, "\n") + "\n")
+		lines = lines[at:]
+		if strings.Join(lines, "") != "" {
+			out.WriteString("\n")
+		}
+	}
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+
+	if p.epilogue != nil {
+		epilogue, err := render(p.epilogue, info)
+		if err != nil {
+			return nil, err
+		}
+		ensureNewline(&out)
+		out.WriteString("\n" + strings.TrimRight(epilogue, "\n") + "\n")
+	}
+	return []byte(out.String()), nil
+}
+
+// afterImports returns the index of the line following the leading block of
+// top-level imports, which may be preceded and interleaved by comments and
+// blank lines, or 0 when the script does not start with imports.
+func afterImports(lines []string) int {
+	end := 0
+	inParens := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inParens:
+			if strings.Contains(line, ")") {
+				inParens = false
+				end = i + 1
+			}
+		case strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "from "):
+			inParens = strings.Contains(line, "(") && !strings.Contains(line, ")")
+			end = i + 1
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+func ensureNewline(b *strings.Builder) {
+	if s := b.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		b.WriteString("\n")
+	}
+}
+
+func render(tmpl *template.Template, info ScriptInfo) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, info); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", tmpl.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// FormatterProcessor formats scripts with an external formatter service,
+// such as black running in a sandboxed container, so formatters never run
+// inside the backend. The service receives the script as the body of a POST
+// with the framework in the X-Script-Framework header, and answers 200 with
+// the formatted script.
+type FormatterProcessor struct {
+	url    string
+	client *http.Client
+}
+
+// NewFormatterProcessor creates a processor calling the formatter service
+// at url, giving up after timeout.
+func NewFormatterProcessor(url string, timeout time.Duration) *FormatterProcessor {
+	return &FormatterProcessor{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Process formats script. Failures wrap ErrFormatterFailed.
+func (p *FormatterProcessor) Process(ctx context.Context, script []byte, info ScriptInfo) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(script))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormatterFailed, err)
+	}
+	req.Header.Set("Content-Type", "text/x-python")
+	req.Header.Set("X-Script-Framework", string(info.Framework))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormatterFailed, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFormattedScriptSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormatterFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d: %s", ErrFormatterFailed, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if len(body) > maxFormattedScriptSize {
+		return nil, fmt.Errorf("%w: formatted script exceeds %d bytes", ErrFormatterFailed, maxFormattedScriptSize)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, fmt.Errorf("%w: empty response", ErrFormatterFailed)
+	}
+	return body, nil
+}
@@ -0,0 +1,170 @@
+package scriptgen
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testScriptInfo = ScriptInfo{
+	ProcedureName:    "Login",
+	ProcedureVersion: 3,
+	Framework:        FrameworkPlaywright,
+	Year:             2026,
+}
+
+const testScript = `import os
+from playwright.sync_api import (
+    sync_playwright,
+)
+
+def main():
+    pass
+`
+
+func TestHeaderProcessor(t *testing.T) {
+	t.Run("comments out header lines", func(t *testing.T) {
+		p, err := NewHeaderProcessor("Copyright {{.Year}} Example Corp.\n\n# Generated from {{.ProcedureName}} v{{.ProcedureVersion}}\n")
+		require.NoError(t, err)
+
+		out, err := p.Process(context.Background(), []byte(testScript), testScriptInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "# Copyright 2026 Example Corp.\n#\n# Generated from Login v3\n\n"+testScript, string(out))
+	})
+
+	t.Run("keeps shebang first", func(t *testing.T) {
+		p, err := NewHeaderProcessor("License: MIT")
+		require.NoError(t, err)
+
+		out, err := p.Process(context.Background(), []byte("#!/usr/bin/env python3\nimport os\n"), testScriptInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "#!/usr/bin/env python3\n# License: MIT\n\nimport os\n", string(out))
+	})
+
+	t.Run("rejects invalid template", func(t *testing.T) {
+		_, err := NewHeaderProcessor("{{.Year")
+		assert.Error(t, err)
+	})
+
+	t.Run("fails on unknown field", func(t *testing.T) {
+		p, err := NewHeaderProcessor("{{.Company}}")
+		require.NoError(t, err)
+
+		_, err = p.Process(context.Background(), []byte(testScript), testScriptInfo)
+		assert.Error(t, err)
+	})
+}
+
+func TestBoilerplateProcessor(t *testing.T) {
+	t.Run("injects prologue after imports and epilogue at end", func(t *testing.T) {
+		p, err := NewBoilerplateProcessor("import company_fixtures\n", "if __name__ == \"__main__\":\n    main()")
+		require.NoError(t, err)
+
+		out, err := p.Process(context.Background(), []byte(testScript), testScriptInfo)
+		require.NoError(t, err)
+		assert.Equal(t, `import os
+from playwright.sync_api import (
+    sync_playwright,
+)
+
+import company_fixtures
+
+
+def main():
+    pass
+
+if __name__ == "__main__":
+    main()
+`, string(out))
+	})
+
+	t.Run("prologue goes first without imports", func(t *testing.T) {
+		p, err := NewBoilerplateProcessor("FRAMEWORK = \"{{.Framework}}\"", "")
+		require.NoError(t, err)
+
+		out, err := p.Process(context.Background(), []byte("print('hi')"), testScriptInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "FRAMEWORK = \"playwright\"\n\nprint('hi')", string(out))
+	})
+
+	t.Run("empty boilerplate leaves script unchanged", func(t *testing.T) {
+		p, err := NewBoilerplateProcessor("", "")
+		require.NoError(t, err)
+
+		out, err := p.Process(context.Background(), []byte(testScript), testScriptInfo)
+		require.NoError(t, err)
+		assert.Equal(t, testScript, string(out))
+	})
+}
+
+func TestFormatterProcessor(t *testing.T) {
+	t.Run("returns formatted script", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "playwright", r.Header.Get("X-Script-Framework"))
+			body, _ := io.ReadAll(r.Body)
+			w.Write([]byte(strings.ToUpper(string(body))))
+		}))
+		defer server.Close()
+
+		p := NewFormatterProcessor(server.URL, time.Second)
+		out, err := p.Process(context.Background(), []byte("x = 1\n"), testScriptInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "X = 1\n", string(out))
+	})
+
+	t.Run("wraps service errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "cannot parse", http.StatusUnprocessableEntity)
+		}))
+		defer server.Close()
+
+		p := NewFormatterProcessor(server.URL, time.Second)
+		_, err := p.Process(context.Background(), []byte("x = (\n"), testScriptInfo)
+		assert.ErrorIs(t, err, ErrFormatterFailed)
+		assert.Contains(t, err.Error(), "cannot parse")
+	})
+
+	t.Run("rejects empty response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		p := NewFormatterProcessor(server.URL, time.Second)
+		_, err := p.Process(context.Background(), []byte("x = 1\n"), testScriptInfo)
+		assert.ErrorIs(t, err, ErrFormatterFailed)
+	})
+}
+
+func TestPostProcessChain(t *testing.T) {
+	header, err := NewHeaderProcessor("Copyright {{.Year}}")
+	require.NoError(t, err)
+	boilerplate, err := NewBoilerplateProcessor("", "main()")
+	require.NoError(t, err)
+
+	t.Run("keeps unformatted script when formatter fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		chain := NewPostProcessChain(logger.NewTestLogger(), boilerplate, NewFormatterProcessor(server.URL, time.Second), header)
+		out, err := chain.Process(context.Background(), []byte("x = 1\n"), testScriptInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "# Copyright 2026\n\nx = 1\n\nmain()\n", string(out))
+	})
+
+	t.Run("nil chain leaves script unchanged", func(t *testing.T) {
+		var chain *PostProcessChain
+		out, err := chain.Process(context.Background(), []byte("x = 1\n"), testScriptInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "x = 1\n", string(out))
+	})
+}
@@ -4,14 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
 )
 
 // BuildPrompt constructs a prompt for the LLM to generate an automation script.
 // It validates and sanitizes all user-provided content before embedding it in the prompt
-// to prevent prompt injection attacks.
-func BuildPrompt(procedure *testprocedure.TestProcedure, framework Framework, config *ValidationConfig) (string, error) {
+// to prevent prompt injection attacks. secretKeys are the names of the target
+// endpoint's secrets; only the names are sent, and the script is told to read
+// the values from environment variables.
+func BuildPrompt(procedure *testprocedure.TestProcedure, framework Framework, secretKeys []string, config *ValidationConfig) (string, error) {
 	if config == nil {
 		config = DefaultValidationConfig()
 	}
@@ -46,6 +49,16 @@ func BuildPrompt(procedure *testprocedure.TestProcedure, framework Framework, co
 		return "", fmt.Errorf("failed to marshal steps: %w", err)
 	}
 
+	preconditionInstructions, err := getPreconditionInstructions(procedure.Preconditions)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal preconditions: %w", err)
+	}
+
+	conditionInstructions, err := getConditionInstructions(procedure.Steps)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal step conditions: %w", err)
+	}
+
 	frameworkName := "Selenium"
 	if framework == FrameworkPlaywright {
 		frameworkName = "Playwright"
@@ -64,7 +77,7 @@ func BuildPrompt(procedure *testprocedure.TestProcedure, framework Framework, co
 %s
 </test_steps>
 </test_procedure>
-
+%s%s%s
 <requirements>
 - Use Python 3.x syntax
 - Include proper error handling and try-except blocks
@@ -81,6 +94,10 @@ Action types and their meanings:
 - assert_text: Verify text content of element (requires "selector" and "value" fields)
 - screenshot: Capture screenshot (requires "value" field as filename)
 
+A step's optional "expected_result" describes what the page should show once the step is done.
+After performing such a step, emit assertions that check it (for example an element is visible,
+a text is shown or the URL changed), failing with a message that quotes the expected result.
+
 %s
 
 The script should:
@@ -96,12 +113,127 @@ The script should:
 		procedure.Version,
 		sanitizedDescription,
 		string(stepsJSON),
+		preconditionInstructions,
+		conditionInstructions,
+		getSecretInstructions(secretKeys),
 		getFrameworkSpecificInstructions(framework),
 	)
 
 	return prompt, nil
 }
 
+// getSecretInstructions describes the placeholders that stand in for
+// endpoint secrets in the test steps.
+func getSecretInstructions(secretKeys []string) string {
+	if len(secretKeys) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n<environment_secrets>\n")
+	for _, key := range secretKeys {
+		fmt.Fprintf(&b, "- %s\n", key)
+	}
+	b.WriteString(`</environment_secrets>
+
+Values written as {{NAME}} in the test steps are placeholders for the environment secrets above.
+Read each secret at runtime with os.environ["NAME"], fail with a clear message if it is not set,
+and never hard-code, log or print secret values.
+`)
+	return b.String()
+}
+
+// getPreconditionInstructions lists what must be in place before the script
+// runs, so the script documents it rather than setting it up. Like the step
+// conditions, the preconditions are given as JSON.
+func getPreconditionInstructions(preconditions testprocedure.Preconditions) (string, error) {
+	if len(preconditions) == 0 {
+		return "", nil
+	}
+
+	sanitized := make(testprocedure.Preconditions, len(preconditions))
+	for i, precondition := range preconditions {
+		sanitized[i] = testprocedure.Precondition{
+			Kind:        precondition.Kind,
+			Description: SanitizeTestProcedureDescription(precondition.Description),
+		}
+	}
+	preconditionsJSON, err := json.MarshalIndent(sanitized, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`
+<preconditions>
+%s
+</preconditions>
+
+The preconditions above are in place before the script runs: test data that exists, the state of the
+environment, or the user role of the account the steps log in with. List them in the module docstring,
+but do not create, change or check them in the script.
+`, string(preconditionsJSON)), nil
+}
+
+// promptCondition is a conditional step as described to the model, with
+// steps numbered from 1.
+type promptCondition struct {
+	Step      int                          `json:"step"`
+	Check     testprocedure.ConditionCheck `json:"check"`
+	Selector  string                       `json:"selector,omitempty"`
+	Text      string                       `json:"text,omitempty"`
+	ThenSteps []int                        `json:"then_steps"`
+	ElseSteps []int                        `json:"else_steps,omitempty"`
+}
+
+// getConditionInstructions describes the branches of the conditional steps
+// and asks for them to be scripted as if/else blocks. The conditions are
+// given as JSON, which escapes angle brackets, so their text cannot close
+// the surrounding tags.
+func getConditionInstructions(steps testprocedure.Steps) (string, error) {
+	branches := steps.Branches()
+	if len(branches) == 0 {
+		return "", nil
+	}
+
+	numbers := func(from, to int) []int {
+		var out []int
+		for i := from; i <= to; i++ {
+			out = append(out, i+1)
+		}
+		return out
+	}
+	conditions := make([]promptCondition, len(branches))
+	for i, b := range branches {
+		conditions[i] = promptCondition{
+			Step:      b.Step + 1,
+			Check:     b.Condition.Check,
+			Selector:  sanitizeStepStringField("selector", b.Condition.Selector),
+			Text:      sanitizeStepStringField("value", b.Condition.Text),
+			ThenSteps: numbers(b.ThenFrom, b.ThenTo),
+			ElseSteps: numbers(b.ElseFrom, b.ElseTo),
+		}
+	}
+	conditionsJSON, err := json.MarshalIndent(conditions, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`
+<step_conditions>
+%s
+</step_conditions>
+
+Steps are numbered from 1 in the order of test_steps. Each entry in step_conditions is a branch point:
+the step performs no action of its own but checks the page, and must be scripted as an if/else block.
+If the check holds, run the then_steps; otherwise run the else_steps, if any. Either way, continue with
+the step after both branches. Branch points may be nested inside a branch.
+- element_exists: an element matches "selector" (do not wait for it or fail if it is missing)
+- element_visible: an element matching "selector" is visible
+- text_present: the page shows "text"
+- url_contains: the current URL contains "text"
+`, string(conditionsJSON)), nil
+}
+
 func getFrameworkSpecificInstructions(framework Framework) string {
 	if framework == FrameworkSelenium {
 		return `For Selenium:
@@ -170,7 +170,7 @@ func TestBuildPrompt(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			prompt, err := BuildPrompt(tt.procedure, tt.framework, config)
+			prompt, err := BuildPrompt(tt.procedure, tt.framework, nil, config)
 			if tt.expectError {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errorMsg)
@@ -199,7 +199,7 @@ func TestBuildPrompt_XMLStructure(t *testing.T) {
 		},
 	}
 
-	prompt, err := BuildPrompt(procedure, FrameworkSelenium, DefaultValidationConfig())
+	prompt, err := BuildPrompt(procedure, FrameworkSelenium, nil, DefaultValidationConfig())
 	require.NoError(t, err)
 
 	// Verify proper XML tag ordering and nesting
@@ -289,7 +289,7 @@ func TestBuildPrompt_InjectionAttempts(t *testing.T) {
 
 	for _, tt := range injectionTests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := BuildPrompt(tt.procedure, FrameworkSelenium, config)
+			_, err := BuildPrompt(tt.procedure, FrameworkSelenium, nil, config)
 			if tt.shouldFail {
 				require.Error(t, err, tt.description)
 			} else {
@@ -319,7 +319,7 @@ func TestBuildPrompt_LengthLimits(t *testing.T) {
 			},
 		}
 
-		_, err := BuildPrompt(procedure, FrameworkSelenium, config)
+		_, err := BuildPrompt(procedure, FrameworkSelenium, nil, config)
 		require.NoError(t, err)
 	})
 
@@ -333,7 +333,7 @@ func TestBuildPrompt_LengthLimits(t *testing.T) {
 			Steps:       testprocedure.Steps{},
 		}
 
-		_, err := BuildPrompt(procedure, FrameworkSelenium, config)
+		_, err := BuildPrompt(procedure, FrameworkSelenium, nil, config)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "name exceeds maximum length")
 	})
@@ -348,7 +348,7 @@ func TestBuildPrompt_LengthLimits(t *testing.T) {
 			Steps:       testprocedure.Steps{},
 		}
 
-		_, err := BuildPrompt(procedure, FrameworkSelenium, config)
+		_, err := BuildPrompt(procedure, FrameworkSelenium, nil, config)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "description exceeds maximum length")
 	})
@@ -368,7 +368,7 @@ func TestBuildPrompt_LengthLimits(t *testing.T) {
 			Steps:       steps,
 		}
 
-		_, err := BuildPrompt(procedure, FrameworkSelenium, config)
+		_, err := BuildPrompt(procedure, FrameworkSelenium, nil, config)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "validation failed")
 	})
@@ -387,7 +387,7 @@ func TestBuildPrompt_NilConfig(t *testing.T) {
 		},
 	}
 
-	prompt, err := BuildPrompt(procedure, FrameworkSelenium, nil)
+	prompt, err := BuildPrompt(procedure, FrameworkSelenium, nil, nil)
 	require.NoError(t, err)
 	assert.NotEmpty(t, prompt)
 }
@@ -447,7 +447,7 @@ func TestBuildPrompt_ComplexSteps(t *testing.T) {
 		},
 	}
 
-	prompt, err := BuildPrompt(procedure, FrameworkPlaywright, DefaultValidationConfig())
+	prompt, err := BuildPrompt(procedure, FrameworkPlaywright, nil, DefaultValidationConfig())
 	require.NoError(t, err)
 	assert.NotEmpty(t, prompt)
 
@@ -497,7 +497,7 @@ func TestBuildPrompt_SanitizationEffectiveness(t *testing.T) {
 		},
 	}
 
-	prompt, err := BuildPrompt(procedure, FrameworkSelenium, DefaultValidationConfig())
+	prompt, err := BuildPrompt(procedure, FrameworkSelenium, nil, DefaultValidationConfig())
 	require.NoError(t, err)
 
 	// Verify sanitization results
@@ -506,3 +506,33 @@ func TestBuildPrompt_SanitizationEffectiveness(t *testing.T) {
 	assert.Contains(t, prompt, "https://example.com")          // Added protocol
 	assert.NotContains(t, prompt, "\x00")                      // No control characters
 }
+
+func TestBuildPrompt_SecretPlaceholders(t *testing.T) {
+	procedure := &testprocedure.TestProcedure{
+		Name:        "Login",
+		Description: "Log in as the test user",
+		Version:     1,
+		ProjectID:   uuid.New(),
+		CreatedBy:   uuid.New(),
+		Steps: testprocedure.Steps{
+			{"action": "navigate", "url": "https://example.com/login"},
+			{"action": "type", "selector": "#password", "value": "{{TEST_PASSWORD}}"},
+		},
+	}
+
+	t.Run("secret names are listed", func(t *testing.T) {
+		prompt, err := BuildPrompt(procedure, FrameworkPlaywright, []string{"TEST_PASSWORD", "API_KEY"}, DefaultValidationConfig())
+		require.NoError(t, err)
+		assert.Contains(t, prompt, "<environment_secrets>\n- TEST_PASSWORD\n- API_KEY\n</environment_secrets>")
+		assert.Contains(t, prompt, `os.environ["NAME"]`)
+		assert.Contains(t, prompt, "{{TEST_PASSWORD}}")
+		assert.Less(t, strings.Index(prompt, "</test_procedure>"), strings.Index(prompt, "<environment_secrets>"))
+		assert.Less(t, strings.Index(prompt, "</environment_secrets>"), strings.Index(prompt, "<requirements>"))
+	})
+
+	t.Run("no secrets omits the section", func(t *testing.T) {
+		prompt, err := BuildPrompt(procedure, FrameworkPlaywright, nil, DefaultValidationConfig())
+		require.NoError(t, err)
+		assert.NotContains(t, prompt, "environment_secrets")
+	})
+}
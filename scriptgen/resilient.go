@@ -0,0 +1,44 @@
+package scriptgen
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// ResilientGenerator wraps a ScriptGenerator so that LLM calls run under a
+// circuit breaker with a per-call deadline.
+type ResilientGenerator struct {
+	generator ScriptGenerator
+	breaker   *resilience.Breaker
+}
+
+// NewResilientGenerator wraps generator with the given breaker.
+func NewResilientGenerator(generator ScriptGenerator, breaker *resilience.Breaker) *ResilientGenerator {
+	return &ResilientGenerator{
+		generator: generator,
+		breaker:   breaker,
+	}
+}
+
+// Generate creates a script through the breaker. Procedures rejected during
+// prompt validation never reach the model and do not count against it.
+func (g *ResilientGenerator) Generate(ctx context.Context, procedure *testprocedure.TestProcedure, framework Framework, secretKeys []string) ([]byte, error) {
+	var script []byte
+	var rejected error
+	err := g.breaker.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		script, err = g.generator.Generate(ctx, procedure, framework, secretKeys)
+		if errors.Is(err, ErrPromptRejected) {
+			rejected = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return script, rejected
+}
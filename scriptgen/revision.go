@@ -0,0 +1,114 @@
+package scriptgen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmezard/go-difflib/difflib"
+	"gorm.io/gorm"
+)
+
+// ErrRevisionNotFound is returned when a script revision is not found.
+var ErrRevisionNotFound = errors.New("script revision not found")
+
+// diffContextLines is the number of unchanged lines around each hunk of a
+// revision diff.
+const diffContextLines = 3
+
+// Revision is the code of a completed script generation. Scripts belong to a
+// single version of a procedure and are replaced when regenerated, so their
+// code is kept as revisions numbered per framework across all versions of the
+// procedure, which lets reviewers see what the automation changed.
+type Revision struct {
+	ID               uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	ProcedureID      uuid.UUID `json:"procedure_id" gorm:"type:char(36);not null;uniqueIndex:idx_script_revisions_number,priority:1"`
+	Framework        Framework `json:"framework" gorm:"type:varchar(20);not null;uniqueIndex:idx_script_revisions_number,priority:2"`
+	Number           int       `json:"revision" gorm:"column:revision;not null;uniqueIndex:idx_script_revisions_number,priority:3"`
+	TestProcedureID  uuid.UUID `json:"test_procedure_id" gorm:"type:char(36);not null"`
+	ProcedureVersion uint      `json:"procedure_version" gorm:"not null"`
+	FileName         string    `json:"file_name" gorm:"type:varchar(255);not null"`
+	FileSize         int64     `json:"file_size" gorm:"not null"`
+	Content          string    `json:"-" gorm:"type:mediumtext;not null"`
+	GeneratedBy      uuid.UUID `json:"generated_by" gorm:"type:char(36);not null"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (r *Revision) TableName() string {
+	return "script_revisions"
+}
+
+// BeforeCreate hook to generate UUID before creating a new revision
+func (r *Revision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the revision has valid required fields.
+func (r *Revision) Validate() error {
+	if r.ProcedureID == uuid.Nil || r.TestProcedureID == uuid.Nil {
+		return ErrInvalidTestProcedureID
+	}
+	if !r.Framework.IsValid() {
+		return ErrInvalidFramework
+	}
+	if r.FileName == "" {
+		return ErrInvalidFileName
+	}
+	if r.GeneratedBy == uuid.Nil {
+		return ErrInvalidGeneratedBy
+	}
+	return nil
+}
+
+// RevisionDiff is the unified diff of the code of two revisions.
+type RevisionDiff struct {
+	Framework Framework `json:"framework"`
+	From      *Revision `json:"from"`
+	To        *Revision `json:"to"`
+	Diff      string    `json:"diff"`
+}
+
+// DiffRevisions returns the unified diff of the code of from and to, which
+// is empty when the code is the same.
+func DiffRevisions(from, to *Revision) (*RevisionDiff, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(from.Content),
+		B:        splitLines(to.Content),
+		FromFile: revisionLabel(from),
+		ToFile:   revisionLabel(to),
+		Context:  diffContextLines,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff script revisions: %w", err)
+	}
+
+	return &RevisionDiff{
+		Framework: to.Framework,
+		From:      from,
+		To:        to,
+		Diff:      diff,
+	}, nil
+}
+
+func revisionLabel(r *Revision) string {
+	return fmt.Sprintf("%s (revision %d, procedure v%d)", r.FileName, r.Number, r.ProcedureVersion)
+}
+
+// splitLines splits code into lines that each end with a newline, unlike
+// difflib.SplitLines, which adds an empty line after code ending with one.
+func splitLines(code string) []string {
+	if code == "" {
+		return nil
+	}
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	lines := strings.SplitAfter(code, "\n")
+	return lines[:len(lines)-1]
+}
@@ -0,0 +1,106 @@
+package scriptgen
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLRevisionStore implements RevisionStore using GORM and MySQL.
+type MySQLRevisionStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLRevisionStore creates a new MySQL-backed script revision store.
+func NewMySQLRevisionStore(db *gorm.DB, log logger.Logger) *MySQLRevisionStore {
+	return &MySQLRevisionStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create records a revision. It is numbered one past the last revision of
+// its procedure and framework; the unique (procedure_id, framework,
+// revision) index rejects a concurrent writer that picked the same number.
+func (s *MySQLRevisionStore) Create(ctx context.Context, revision *Revision) error {
+	if err := revision.Validate(); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last int
+		if err := tx.Model(&Revision{}).
+			Where("procedure_id = ? AND framework = ?", revision.ProcedureID, revision.Framework).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		revision.Number = last + 1
+		return tx.Create(revision).Error
+	})
+	if err != nil {
+		s.logger.Error(ctx, "failed to create script revision", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": revision.ProcedureID.String(),
+			"framework":    revision.Framework,
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "script revision created", map[string]interface{}{
+		"procedure_id": revision.ProcedureID.String(),
+		"framework":    revision.Framework,
+		"revision":     revision.Number,
+	})
+
+	return nil
+}
+
+// List retrieves the revisions of a procedure for a framework, newest first,
+// without their code.
+func (s *MySQLRevisionStore) List(ctx context.Context, procedureID uuid.UUID, framework Framework) ([]*Revision, error) {
+	var revisions []*Revision
+	err := s.db.WithContext(ctx).
+		Omit("content").
+		Where("procedure_id = ? AND framework = ?", procedureID, framework).
+		Order("revision DESC").
+		Find(&revisions).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list script revisions", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+			"framework":    framework,
+		})
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Get retrieves a revision of a procedure for a framework with its code.
+func (s *MySQLRevisionStore) Get(ctx context.Context, procedureID uuid.UUID, framework Framework, number int) (*Revision, error) {
+	var revision Revision
+	err := s.db.WithContext(ctx).
+		Where("procedure_id = ? AND framework = ? AND revision = ?", procedureID, framework, number).
+		First(&revision).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		s.logger.Error(ctx, "failed to get script revision", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+			"framework":    framework,
+			"revision":     number,
+		})
+		return nil, err
+	}
+
+	return &revision, nil
+}
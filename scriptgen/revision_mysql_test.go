@@ -0,0 +1,78 @@
+package scriptgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRevisionStore creates a test database and script revision store for
+// testing.
+func setupRevisionStore(t *testing.T) RevisionStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Revision{})
+
+	return NewMySQLRevisionStore(db, logger.NewTestLogger())
+}
+
+func newTestRevision(procedureID uuid.UUID, framework Framework, version uint, content string) *Revision {
+	return &Revision{
+		ProcedureID:      procedureID,
+		Framework:        framework,
+		TestProcedureID:  uuid.New(),
+		ProcedureVersion: version,
+		FileName:         "login_" + string(framework) + ".py",
+		FileSize:         int64(len(content)),
+		Content:          content,
+		GeneratedBy:      uuid.New(),
+	}
+}
+
+func TestMySQLRevisionStore(t *testing.T) {
+	store := setupRevisionStore(t)
+	ctx := context.Background()
+	procedureID := uuid.New()
+
+	t.Run("revisions are numbered per framework", func(t *testing.T) {
+		first := newTestRevision(procedureID, FrameworkPlaywright, 1, "v1\n")
+		require.NoError(t, store.Create(ctx, first))
+		second := newTestRevision(procedureID, FrameworkPlaywright, 2, "v2\n")
+		require.NoError(t, store.Create(ctx, second))
+		other := newTestRevision(procedureID, FrameworkSelenium, 2, "selenium\n")
+		require.NoError(t, store.Create(ctx, other))
+
+		assert.Equal(t, 1, first.Number)
+		assert.Equal(t, 2, second.Number)
+		assert.Equal(t, 1, other.Number)
+	})
+
+	t.Run("list is newest first without code", func(t *testing.T) {
+		revisions, err := store.List(ctx, procedureID, FrameworkPlaywright)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, 2, revisions[0].Number)
+		assert.Equal(t, uint(2), revisions[0].ProcedureVersion)
+		assert.Empty(t, revisions[0].Content)
+	})
+
+	t.Run("get returns code", func(t *testing.T) {
+		revision, err := store.Get(ctx, procedureID, FrameworkPlaywright, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "v1\n", revision.Content)
+	})
+
+	t.Run("missing revision returns error", func(t *testing.T) {
+		_, err := store.Get(ctx, procedureID, FrameworkSelenium, 2)
+		assert.ErrorIs(t, err, ErrRevisionNotFound)
+	})
+
+	t.Run("invalid revision is rejected", func(t *testing.T) {
+		revision := newTestRevision(procedureID, Framework("cypress"), 1, "x\n")
+		assert.ErrorIs(t, store.Create(ctx, revision), ErrInvalidFramework)
+	})
+}
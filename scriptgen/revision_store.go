@@ -0,0 +1,23 @@
+package scriptgen
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RevisionStore defines the interface for script revision persistence
+// operations. Revisions are addressed by the ID of the first version of
+// their procedure, which all of its versions share as root.
+type RevisionStore interface {
+	// Create records a revision, numbered one past the last revision of its
+	// procedure and framework.
+	Create(ctx context.Context, revision *Revision) error
+
+	// List retrieves the revisions of a procedure for a framework, newest
+	// first, without their code.
+	List(ctx context.Context, procedureID uuid.UUID, framework Framework) ([]*Revision, error)
+
+	// Get retrieves a revision of a procedure for a framework with its code.
+	Get(ctx context.Context, procedureID uuid.UUID, framework Framework, number int) (*Revision, error)
+}
@@ -0,0 +1,38 @@
+package scriptgen
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRevisions(t *testing.T) {
+	procedureID := uuid.New()
+	from := newTestRevision(procedureID, FrameworkPlaywright, 1, "import os\n\npage.click('#login')\nprint('done')\n")
+	from.Number = 1
+	to := newTestRevision(procedureID, FrameworkPlaywright, 2, "import os\n\npage.click('#sign-in')\nprint('done')\n")
+	to.Number = 2
+
+	t.Run("changed code", func(t *testing.T) {
+		diff, err := DiffRevisions(from, to)
+		require.NoError(t, err)
+		assert.Equal(t, FrameworkPlaywright, diff.Framework)
+		assert.Equal(t, `--- login_playwright.py (revision 1, procedure v1)
++++ login_playwright.py (revision 2, procedure v2)
+@@ -1,4 +1,4 @@
+ import os
+ 
+-page.click('#login')
++page.click('#sign-in')
+ print('done')
+`, diff.Diff)
+	})
+
+	t.Run("same code", func(t *testing.T) {
+		diff, err := DiffRevisions(from, from)
+		require.NoError(t, err)
+		assert.Empty(t, diff.Diff)
+	})
+}
@@ -0,0 +1,138 @@
+package scriptrepo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Commit is a file to commit to a branch of a repository.
+type Commit struct {
+	Path    string
+	Content []byte
+	Message string
+	Branch  string
+	// BaseBranch is the branch Branch is created from when it does not
+	// exist; empty requires Branch to exist.
+	BaseBranch string
+	// PullRequest, when set, is opened from Branch into BaseBranch unless
+	// one is already open.
+	PullRequest *PullRequest
+}
+
+// PullRequest is the pull (or merge) request to open for a commit.
+type PullRequest struct {
+	Title string
+	Body  string
+}
+
+// PushResult is where a commit landed.
+type PushResult struct {
+	Path           string `json:"path"`
+	Branch         string `json:"branch"`
+	CommitSHA      string `json:"commit_sha"`
+	CommitURL      string `json:"commit_url,omitempty"`
+	PullRequestURL string `json:"pull_request_url,omitempty"`
+}
+
+// Client commits files to a repository of a Git hosting service.
+type Client interface {
+	// Push commits a file, creating or updating it.
+	Push(ctx context.Context, commit *Commit) (*PushResult, error)
+}
+
+// NewClient creates the client of the repository's provider, authenticated
+// with its decrypted token.
+func NewClient(repo *Repository) (Client, error) {
+	if repo.Token == "" {
+		return nil, ErrTokenRequired
+	}
+	switch repo.Provider {
+	case ProviderGitHub:
+		return newGitHubClient(repo), nil
+	case ProviderGitLab:
+		return newGitLabClient(repo), nil
+	default:
+		return nil, ErrInvalidProvider
+	}
+}
+
+// apiError is an unexpected response of a provider's API.
+type apiError struct {
+	provider   Provider
+	operation  string
+	statusCode int
+	body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s failed with status %d: %s", e.provider, e.operation, e.statusCode, e.body)
+}
+
+// apiClient makes JSON requests to a provider's API.
+type apiClient struct {
+	provider   Provider
+	httpClient *http.Client
+	baseURL    string
+	headers    map[string]string
+}
+
+func newAPIClient(provider Provider, baseURL string, headers map[string]string) *apiClient {
+	return &apiClient{
+		provider:   provider,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    baseURL,
+		headers:    headers,
+	}
+}
+
+// do sends a request to the API path and decodes a response with one of the
+// expected statuses into out, which may be nil. It returns the status, or
+// an *apiError for any other status.
+func (c *apiClient) do(ctx context.Context, operation, method, path string, body, out interface{}, expected ...int) (int, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("%s: failed to marshal request body: %w", c.provider, err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to create request: %w", c.provider, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s failed: %w", c.provider, operation, err)
+	}
+	defer resp.Body.Close()
+
+	for _, status := range expected {
+		if resp.StatusCode != status {
+			continue
+		}
+		if out != nil && status < http.StatusMultipleChoices {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return 0, fmt.Errorf("%s: failed to decode %s response: %w", c.provider, operation, err)
+			}
+		}
+		return resp.StatusCode, nil
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1000))
+	return 0, &apiError{provider: c.provider, operation: operation, statusCode: resp.StatusCode, body: string(data)}
+}
@@ -0,0 +1,34 @@
+package scriptrepo
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and script repository store for
+// testing.
+func setupTestStore(t *testing.T) (*gorm.DB, *MySQLStore) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Repository{})
+
+	store := NewMySQLStore(db, integration.NewKeyring(integration.DeriveKey("test-encryption-key")), logger.NewTestLogger())
+	return db, store
+}
+
+// newTestRepository creates a valid GitHub repository for a new project.
+func newTestRepository() *Repository {
+	return &Repository{
+		ProjectID:  uuid.New(),
+		Provider:   ProviderGitHub,
+		Repository: "acme/web-tests",
+		Branch:     "ui-automation/scripts",
+		BaseBranch: "main",
+		Token:      "ghp_secret",
+		CreatedBy:  uuid.New(),
+	}
+}
@@ -0,0 +1,161 @@
+package scriptrepo
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultGitHubBaseURL = "https://api.github.com"
+
+// gitHubClient commits files with the GitHub REST API.
+type gitHubClient struct {
+	api  *apiClient
+	repo string
+}
+
+func newGitHubClient(repo *Repository) *gitHubClient {
+	baseURL := repo.BaseURL
+	if baseURL == "" {
+		baseURL = defaultGitHubBaseURL
+	}
+	return &gitHubClient{
+		api: newAPIClient(ProviderGitHub, baseURL, map[string]string{
+			"Authorization":        "Bearer " + repo.Token,
+			"Accept":               "application/vnd.github+json",
+			"X-GitHub-Api-Version": "2022-11-28",
+		}),
+		repo: escapePath(repo.Repository),
+	}
+}
+
+// Push commits a file with the contents API, creating the branch first if
+// needed, and opens a pull request if asked to.
+func (c *gitHubClient) Push(ctx context.Context, commit *Commit) (*PushResult, error) {
+	if err := c.ensureBranch(ctx, commit.Branch, commit.BaseBranch); err != nil {
+		return nil, err
+	}
+
+	contentsPath := "/repos/" + c.repo + "/contents/" + escapePath(commit.Path)
+
+	// Updating a file requires the blob SHA of its current version.
+	var existing struct {
+		SHA string `json:"sha"`
+	}
+	if _, err := c.api.do(ctx, "get file", http.MethodGet,
+		contentsPath+"?ref="+url.QueryEscape(commit.Branch), nil, &existing,
+		http.StatusOK, http.StatusNotFound); err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"message": commit.Message,
+		"content": base64.StdEncoding.EncodeToString(commit.Content),
+		"branch":  commit.Branch,
+	}
+	if existing.SHA != "" {
+		body["sha"] = existing.SHA
+	}
+	var committed struct {
+		Commit struct {
+			SHA     string `json:"sha"`
+			HTMLURL string `json:"html_url"`
+		} `json:"commit"`
+	}
+	if _, err := c.api.do(ctx, "commit file", http.MethodPut, contentsPath, body, &committed,
+		http.StatusOK, http.StatusCreated); err != nil {
+		return nil, err
+	}
+
+	result := &PushResult{
+		Path:      commit.Path,
+		Branch:    commit.Branch,
+		CommitSHA: committed.Commit.SHA,
+		CommitURL: committed.Commit.HTMLURL,
+	}
+	if commit.PullRequest != nil {
+		prURL, err := c.openPullRequest(ctx, commit)
+		if err != nil {
+			return nil, err
+		}
+		result.PullRequestURL = prURL
+	}
+	return result, nil
+}
+
+// ensureBranch creates branch from base when it does not exist.
+func (c *gitHubClient) ensureBranch(ctx context.Context, branch, base string) error {
+	status, err := c.api.do(ctx, "get branch", http.MethodGet,
+		"/repos/"+c.repo+"/git/ref/heads/"+escapePath(branch), nil, nil,
+		http.StatusOK, http.StatusNotFound)
+	if err != nil || status == http.StatusOK {
+		return err
+	}
+	if base == "" {
+		return &apiError{provider: ProviderGitHub, operation: "get branch", statusCode: status, body: "branch " + branch + " does not exist"}
+	}
+
+	var baseRef struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if _, err := c.api.do(ctx, "get base branch", http.MethodGet,
+		"/repos/"+c.repo+"/git/ref/heads/"+escapePath(base), nil, &baseRef,
+		http.StatusOK); err != nil {
+		return err
+	}
+
+	_, err = c.api.do(ctx, "create branch", http.MethodPost, "/repos/"+c.repo+"/git/refs",
+		map[string]string{"ref": "refs/heads/" + branch, "sha": baseRef.Object.SHA}, nil,
+		http.StatusCreated)
+	return err
+}
+
+// openPullRequest opens a pull request for the commit, or returns the URL of
+// the one already open for its branch.
+func (c *gitHubClient) openPullRequest(ctx context.Context, commit *Commit) (string, error) {
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	status, err := c.api.do(ctx, "create pull request", http.MethodPost, "/repos/"+c.repo+"/pulls",
+		map[string]string{
+			"title": commit.PullRequest.Title,
+			"body":  commit.PullRequest.Body,
+			"head":  commit.Branch,
+			"base":  commit.BaseBranch,
+		}, &created,
+		http.StatusCreated, http.StatusUnprocessableEntity)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusCreated {
+		return created.HTMLURL, nil
+	}
+
+	// 422 is returned when a pull request is already open for the branch.
+	owner := strings.SplitN(c.repo, "/", 2)[0]
+	var open []struct {
+		HTMLURL string `json:"html_url"`
+	}
+	query := url.Values{"state": {"open"}, "head": {owner + ":" + commit.Branch}, "base": {commit.BaseBranch}}
+	if _, err := c.api.do(ctx, "list pull requests", http.MethodGet,
+		"/repos/"+c.repo+"/pulls?"+query.Encode(), nil, &open, http.StatusOK); err != nil {
+		return "", err
+	}
+	if len(open) == 0 {
+		return "", &apiError{provider: ProviderGitHub, operation: "create pull request", statusCode: status, body: "pull request rejected"}
+	}
+	return open[0].HTMLURL, nil
+}
+
+// escapePath escapes each segment of a slash-separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,139 @@
+package scriptrepo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGitHubClient(t *testing.T, handler http.Handler) Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	repo := newTestRepository()
+	repo.BaseURL = server.URL
+	client, err := NewClient(repo)
+	require.NoError(t, err)
+	return client
+}
+
+func TestGitHubClient_Push(t *testing.T) {
+	commit := &Commit{
+		Path:        "ui-automation/login_playwright.py",
+		Content:     []byte("print('hi')\n"),
+		Message:     "Update login",
+		Branch:      "ui-automation/scripts",
+		BaseBranch:  "main",
+		PullRequest: &PullRequest{Title: "Update login", Body: "Generated"},
+	}
+
+	t.Run("creates branch, file and pull request", func(t *testing.T) {
+		var put map[string]string
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /repos/acme/web-tests/git/ref/heads/ui-automation/scripts", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer ghp_secret", r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusNotFound)
+		})
+		mux.HandleFunc("GET /repos/acme/web-tests/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"object":{"sha":"base-sha"}}`))
+		})
+		mux.HandleFunc("POST /repos/acme/web-tests/git/refs", func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "refs/heads/ui-automation/scripts", body["ref"])
+			assert.Equal(t, "base-sha", body["sha"])
+			w.WriteHeader(http.StatusCreated)
+		})
+		mux.HandleFunc("GET /repos/acme/web-tests/contents/ui-automation/login_playwright.py", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "ui-automation/scripts", r.URL.Query().Get("ref"))
+			w.WriteHeader(http.StatusNotFound)
+		})
+		mux.HandleFunc("PUT /repos/acme/web-tests/contents/ui-automation/login_playwright.py", func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&put))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"commit":{"sha":"abc123","html_url":"https://github.com/acme/web-tests/commit/abc123"}}`))
+		})
+		mux.HandleFunc("POST /repos/acme/web-tests/pulls", func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "ui-automation/scripts", body["head"])
+			assert.Equal(t, "main", body["base"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"html_url":"https://github.com/acme/web-tests/pull/7"}`))
+		})
+
+		result, err := newTestGitHubClient(t, mux).Push(context.Background(), commit)
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", result.CommitSHA)
+		assert.Equal(t, "https://github.com/acme/web-tests/pull/7", result.PullRequestURL)
+
+		assert.Equal(t, "Update login", put["message"])
+		assert.Equal(t, "ui-automation/scripts", put["branch"])
+		assert.Equal(t, base64.StdEncoding.EncodeToString(commit.Content), put["content"])
+		assert.Empty(t, put["sha"])
+	})
+
+	t.Run("updates file and reuses open pull request", func(t *testing.T) {
+		var put map[string]string
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /repos/acme/web-tests/git/ref/heads/ui-automation/scripts", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"object":{"sha":"branch-sha"}}`))
+		})
+		mux.HandleFunc("GET /repos/acme/web-tests/contents/ui-automation/login_playwright.py", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"sha":"blob-sha"}`))
+		})
+		mux.HandleFunc("PUT /repos/acme/web-tests/contents/ui-automation/login_playwright.py", func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&put))
+			w.Write([]byte(`{"commit":{"sha":"def456"}}`))
+		})
+		mux.HandleFunc("POST /repos/acme/web-tests/pulls", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message":"A pull request already exists"}`))
+		})
+		mux.HandleFunc("GET /repos/acme/web-tests/pulls", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "acme:ui-automation/scripts", r.URL.Query().Get("head"))
+			w.Write([]byte(`[{"html_url":"https://github.com/acme/web-tests/pull/3"}]`))
+		})
+
+		result, err := newTestGitHubClient(t, mux).Push(context.Background(), commit)
+		require.NoError(t, err)
+		assert.Equal(t, "def456", result.CommitSHA)
+		assert.Equal(t, "https://github.com/acme/web-tests/pull/3", result.PullRequestURL)
+		assert.Equal(t, "blob-sha", put["sha"])
+	})
+
+	t.Run("missing branch without base", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /repos/acme/web-tests/git/ref/heads/ui-automation/scripts", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		noBase := *commit
+		noBase.BaseBranch = ""
+		_, err := newTestGitHubClient(t, mux).Push(context.Background(), &noBase)
+		assert.ErrorContains(t, err, "does not exist")
+	})
+
+	t.Run("rejected commit", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /repos/acme/web-tests/git/ref/heads/ui-automation/scripts", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{}`))
+		})
+		mux.HandleFunc("GET /repos/acme/web-tests/contents/ui-automation/login_playwright.py", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		mux.HandleFunc("PUT /repos/acme/web-tests/contents/ui-automation/login_playwright.py", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Resource not accessible by integration", http.StatusForbidden)
+		})
+
+		_, err := newTestGitHubClient(t, mux).Push(context.Background(), commit)
+		assert.ErrorContains(t, err, "commit file failed with status 403")
+	})
+}
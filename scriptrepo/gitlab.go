@@ -0,0 +1,138 @@
+package scriptrepo
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+)
+
+const defaultGitLabBaseURL = "https://gitlab.com/api/v4"
+
+// gitLabClient commits files with the GitLab REST API.
+type gitLabClient struct {
+	api     *apiClient
+	project string
+}
+
+func newGitLabClient(repo *Repository) *gitLabClient {
+	baseURL := repo.BaseURL
+	if baseURL == "" {
+		baseURL = defaultGitLabBaseURL
+	}
+	return &gitLabClient{
+		api: newAPIClient(ProviderGitLab, baseURL, map[string]string{
+			"PRIVATE-TOKEN": repo.Token,
+		}),
+		// Projects are addressed by their URL-encoded path.
+		project: url.PathEscape(repo.Repository),
+	}
+}
+
+// Push commits a file with the commits API, creating the branch first if
+// needed, and opens a merge request if asked to.
+func (c *gitLabClient) Push(ctx context.Context, commit *Commit) (*PushResult, error) {
+	if err := c.ensureBranch(ctx, commit.Branch, commit.BaseBranch); err != nil {
+		return nil, err
+	}
+
+	// The commit action depends on whether the file already exists.
+	status, err := c.api.do(ctx, "get file", http.MethodHead,
+		"/projects/"+c.project+"/repository/files/"+url.PathEscape(commit.Path)+"?ref="+url.QueryEscape(commit.Branch),
+		nil, nil, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return nil, err
+	}
+	action := "update"
+	if status == http.StatusNotFound {
+		action = "create"
+	}
+
+	var committed struct {
+		ID     string `json:"id"`
+		WebURL string `json:"web_url"`
+	}
+	if _, err := c.api.do(ctx, "commit file", http.MethodPost, "/projects/"+c.project+"/repository/commits",
+		map[string]interface{}{
+			"branch":         commit.Branch,
+			"commit_message": commit.Message,
+			"actions": []map[string]string{{
+				"action":    action,
+				"file_path": commit.Path,
+				"content":   base64.StdEncoding.EncodeToString(commit.Content),
+				"encoding":  "base64",
+			}},
+		}, &committed, http.StatusCreated); err != nil {
+		return nil, err
+	}
+
+	result := &PushResult{
+		Path:      commit.Path,
+		Branch:    commit.Branch,
+		CommitSHA: committed.ID,
+		CommitURL: committed.WebURL,
+	}
+	if commit.PullRequest != nil {
+		mrURL, err := c.openMergeRequest(ctx, commit)
+		if err != nil {
+			return nil, err
+		}
+		result.PullRequestURL = mrURL
+	}
+	return result, nil
+}
+
+// ensureBranch creates branch from base when it does not exist.
+func (c *gitLabClient) ensureBranch(ctx context.Context, branch, base string) error {
+	status, err := c.api.do(ctx, "get branch", http.MethodGet,
+		"/projects/"+c.project+"/repository/branches/"+url.PathEscape(branch), nil, nil,
+		http.StatusOK, http.StatusNotFound)
+	if err != nil || status == http.StatusOK {
+		return err
+	}
+	if base == "" {
+		return &apiError{provider: ProviderGitLab, operation: "get branch", statusCode: status, body: "branch " + branch + " does not exist"}
+	}
+
+	query := url.Values{"branch": {branch}, "ref": {base}}
+	_, err = c.api.do(ctx, "create branch", http.MethodPost,
+		"/projects/"+c.project+"/repository/branches?"+query.Encode(), nil, nil,
+		http.StatusCreated)
+	return err
+}
+
+// openMergeRequest opens a merge request for the commit, or returns the URL
+// of the one already open for its branch.
+func (c *gitLabClient) openMergeRequest(ctx context.Context, commit *Commit) (string, error) {
+	var created struct {
+		WebURL string `json:"web_url"`
+	}
+	status, err := c.api.do(ctx, "create merge request", http.MethodPost, "/projects/"+c.project+"/merge_requests",
+		map[string]string{
+			"title":         commit.PullRequest.Title,
+			"description":   commit.PullRequest.Body,
+			"source_branch": commit.Branch,
+			"target_branch": commit.BaseBranch,
+		}, &created,
+		http.StatusCreated, http.StatusConflict)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusCreated {
+		return created.WebURL, nil
+	}
+
+	// 409 is returned when a merge request is already open for the branch.
+	var open []struct {
+		WebURL string `json:"web_url"`
+	}
+	query := url.Values{"state": {"opened"}, "source_branch": {commit.Branch}, "target_branch": {commit.BaseBranch}}
+	if _, err := c.api.do(ctx, "list merge requests", http.MethodGet,
+		"/projects/"+c.project+"/merge_requests?"+query.Encode(), nil, &open, http.StatusOK); err != nil {
+		return "", err
+	}
+	if len(open) == 0 {
+		return "", &apiError{provider: ProviderGitLab, operation: "create merge request", statusCode: status, body: "merge request rejected"}
+	}
+	return open[0].WebURL, nil
+}
@@ -0,0 +1,107 @@
+package scriptrepo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGitLabClient(t *testing.T, handler http.Handler) Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	repo := newTestRepository()
+	repo.Provider = ProviderGitLab
+	repo.Repository = "acme/qa/web-tests"
+	repo.BaseURL = server.URL
+	client, err := NewClient(repo)
+	require.NoError(t, err)
+	return client
+}
+
+func TestGitLabClient_Push(t *testing.T) {
+	commit := &Commit{
+		Path:        "ui-automation/login_selenium.py",
+		Content:     []byte("print('hi')\n"),
+		Message:     "Update login",
+		Branch:      "ui-automation/scripts",
+		BaseBranch:  "main",
+		PullRequest: &PullRequest{Title: "Update login", Body: "Generated"},
+	}
+
+	t.Run("creates branch, file and merge request", func(t *testing.T) {
+		var committed map[string]interface{}
+		var paths []string
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "ghp_secret", r.Header.Get("PRIVATE-TOKEN"))
+			paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+
+			switch r.Method + " " + r.URL.EscapedPath() {
+			case "GET /projects/acme%2Fqa%2Fweb-tests/repository/branches/ui-automation%2Fscripts":
+				w.WriteHeader(http.StatusNotFound)
+			case "POST /projects/acme%2Fqa%2Fweb-tests/repository/branches":
+				assert.Equal(t, "ui-automation/scripts", r.URL.Query().Get("branch"))
+				assert.Equal(t, "main", r.URL.Query().Get("ref"))
+				w.WriteHeader(http.StatusCreated)
+			case "HEAD /projects/acme%2Fqa%2Fweb-tests/repository/files/ui-automation%2Flogin_selenium.py":
+				w.WriteHeader(http.StatusNotFound)
+			case "POST /projects/acme%2Fqa%2Fweb-tests/repository/commits":
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&committed))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"abc123","web_url":"https://gitlab.com/acme/qa/web-tests/-/commit/abc123"}`))
+			case "POST /projects/acme%2Fqa%2Fweb-tests/merge_requests":
+				w.WriteHeader(http.StatusConflict)
+			case "GET /projects/acme%2Fqa%2Fweb-tests/merge_requests":
+				assert.Equal(t, "opened", r.URL.Query().Get("state"))
+				w.Write([]byte(`[{"web_url":"https://gitlab.com/acme/qa/web-tests/-/merge_requests/2"}]`))
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+				w.WriteHeader(http.StatusTeapot)
+			}
+		})
+
+		result, err := newTestGitLabClient(t, handler).Push(context.Background(), commit)
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", result.CommitSHA)
+		assert.Equal(t, "https://gitlab.com/acme/qa/web-tests/-/merge_requests/2", result.PullRequestURL)
+		assert.Len(t, paths, 6)
+
+		assert.Equal(t, "ui-automation/scripts", committed["branch"])
+		actions := committed["actions"].([]interface{})
+		require.Len(t, actions, 1)
+		assert.Equal(t, "create", actions[0].(map[string]interface{})["action"])
+	})
+
+	t.Run("updates existing file", func(t *testing.T) {
+		var committed map[string]interface{}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.URL.EscapedPath() {
+			case "GET /projects/acme%2Fqa%2Fweb-tests/repository/branches/ui-automation%2Fscripts":
+				w.Write([]byte(`{}`))
+			case "HEAD /projects/acme%2Fqa%2Fweb-tests/repository/files/ui-automation%2Flogin_selenium.py":
+				w.WriteHeader(http.StatusOK)
+			case "POST /projects/acme%2Fqa%2Fweb-tests/repository/commits":
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&committed))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"def456"}`))
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+				w.WriteHeader(http.StatusTeapot)
+			}
+		})
+
+		noPR := *commit
+		noPR.PullRequest = nil
+		result, err := newTestGitLabClient(t, handler).Push(context.Background(), &noPR)
+		require.NoError(t, err)
+		assert.Equal(t, "def456", result.CommitSHA)
+		assert.Empty(t, result.PullRequestURL)
+		assert.Equal(t, "update", committed["actions"].([]interface{})[0].(map[string]interface{})["action"])
+	})
+}
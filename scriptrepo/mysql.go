@@ -0,0 +1,179 @@
+package scriptrepo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// tokenKey is the credentials map key the token is encrypted under.
+const tokenKey = "token"
+
+// maxPushErrorLength bounds the push error kept on a repository.
+const maxPushErrorLength = 1000
+
+// MySQLStore implements the Store interface using GORM and MySQL. Tokens
+// are encrypted with AES-256-GCM, the same scheme used for integration
+// credentials.
+type MySQLStore struct {
+	db      *gorm.DB
+	keyring *integration.Keyring
+	logger  logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed script repository store that
+// encrypts tokens with the keys in keyring.
+func NewMySQLStore(db *gorm.DB, keyring *integration.Keyring, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:      db,
+		keyring: keyring,
+		logger:  log,
+	}
+}
+
+// GetByProject retrieves the repository of a project with its token
+// decrypted.
+func (s *MySQLStore) GetByProject(ctx context.Context, projectID uuid.UUID) (*Repository, error) {
+	var repo Repository
+	err := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		First(&repo).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRepositoryNotFound
+		}
+		s.logger.Error(ctx, "failed to get script repository", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	decrypted, err := s.keyring.Decrypt(repo.EncryptedToken)
+	if err != nil {
+		s.logger.Error(ctx, "failed to decrypt script repository token", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, ErrDecryptFailed
+	}
+	repo.Token = decrypted[tokenKey]
+	repo.TokenConfigured = repo.Token != ""
+
+	return &repo, nil
+}
+
+// Save creates or replaces the repository of its project.
+func (s *MySQLStore) Save(ctx context.Context, repo *Repository) error {
+	repo.Normalize()
+	if err := repo.Validate(); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing Repository
+		err := tx.Where("project_id = ?", repo.ProjectID).First(&existing).Error
+		switch {
+		case err == nil:
+			repo.ID = existing.ID
+			repo.CreatedBy = existing.CreatedBy
+			repo.CreatedAt = existing.CreatedAt
+			repo.LastPushedAt = existing.LastPushedAt
+			repo.LastPushError = existing.LastPushError
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		if repo.Token != "" {
+			encrypted, err := s.keyring.Encrypt(map[string]string{tokenKey: repo.Token})
+			if err != nil {
+				return err
+			}
+			repo.EncryptedToken = encrypted
+		} else if len(existing.EncryptedToken) > 0 {
+			repo.EncryptedToken = existing.EncryptedToken
+		} else {
+			return ErrTokenRequired
+		}
+		repo.TokenConfigured = true
+
+		return tx.Save(repo).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrTokenRequired) {
+			return err
+		}
+		s.logger.Error(ctx, "failed to save script repository", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": repo.ProjectID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "script repository saved", map[string]interface{}{
+		"project_id": repo.ProjectID.String(),
+		"provider":   repo.Provider,
+		"repository": repo.Repository,
+	})
+
+	return nil
+}
+
+// Delete deletes the repository of a project.
+func (s *MySQLStore) Delete(ctx context.Context, projectID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Delete(&Repository{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete script repository", map[string]interface{}{
+			"error":      result.Error.Error(),
+			"project_id": projectID.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrRepositoryNotFound
+	}
+
+	s.logger.Info(ctx, "script repository deleted", map[string]interface{}{
+		"project_id": projectID.String(),
+	})
+
+	return nil
+}
+
+// RecordPush records the outcome of a push.
+func (s *MySQLStore) RecordPush(ctx context.Context, id uuid.UUID, at time.Time, pushErr error) error {
+	message := ""
+	if pushErr != nil {
+		message = pushErr.Error()
+		if len(message) > maxPushErrorLength {
+			message = message[:maxPushErrorLength]
+		}
+	}
+
+	err := s.db.WithContext(ctx).
+		Model(&Repository{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"last_pushed_at":  at,
+			"last_push_error": message,
+		}).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to record script push", map[string]interface{}{
+			"error":         err.Error(),
+			"repository_id": id.String(),
+		})
+		return err
+	}
+
+	return nil
+}
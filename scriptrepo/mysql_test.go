@@ -0,0 +1,94 @@
+package scriptrepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+	repo := newTestRepository()
+
+	t.Run("not configured", func(t *testing.T) {
+		_, err := store.GetByProject(ctx, repo.ProjectID)
+		assert.ErrorIs(t, err, ErrRepositoryNotFound)
+	})
+
+	t.Run("token is required", func(t *testing.T) {
+		noToken := newTestRepository()
+		noToken.Token = ""
+		assert.ErrorIs(t, store.Save(ctx, noToken), ErrTokenRequired)
+	})
+
+	t.Run("token is encrypted at rest", func(t *testing.T) {
+		require.NoError(t, store.Save(ctx, repo))
+
+		var raw Repository
+		require.NoError(t, db.Where("project_id = ?", repo.ProjectID).First(&raw).Error)
+		assert.NotEmpty(t, raw.EncryptedToken)
+		assert.NotContains(t, string(raw.EncryptedToken), "ghp_secret")
+
+		retrieved, err := store.GetByProject(ctx, repo.ProjectID)
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_secret", retrieved.Token)
+		assert.True(t, retrieved.TokenConfigured)
+		assert.Equal(t, DefaultDirectory, retrieved.Directory)
+		assert.Equal(t, DefaultCommitMessage, retrieved.CommitMessage)
+	})
+
+	t.Run("saving without token keeps it", func(t *testing.T) {
+		update := newTestRepository()
+		update.ProjectID = repo.ProjectID
+		update.Token = ""
+		update.Branch = "qa/scripts"
+		require.NoError(t, store.Save(ctx, update))
+		assert.Equal(t, repo.ID, update.ID)
+
+		retrieved, err := store.GetByProject(ctx, repo.ProjectID)
+		require.NoError(t, err)
+		assert.Equal(t, "qa/scripts", retrieved.Branch)
+		assert.Equal(t, "ghp_secret", retrieved.Token)
+	})
+
+	t.Run("record push", func(t *testing.T) {
+		at := time.Now().UTC().Truncate(time.Second)
+		require.NoError(t, store.RecordPush(ctx, repo.ID, at, errors.New("rejected")))
+
+		retrieved, err := store.GetByProject(ctx, repo.ProjectID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.LastPushedAt)
+		assert.Equal(t, "rejected", retrieved.LastPushError)
+
+		require.NoError(t, store.RecordPush(ctx, repo.ID, at, nil))
+		retrieved, err = store.GetByProject(ctx, repo.ProjectID)
+		require.NoError(t, err)
+		assert.Empty(t, retrieved.LastPushError)
+	})
+
+	t.Run("rekey", func(t *testing.T) {
+		oldKey := integration.DeriveKey("test-encryption-key")
+		newKey := integration.DeriveKey("new-encryption-key")
+		count, err := RekeyTokens(ctx, db, oldKey, newKey)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		rekeyed := NewMySQLStore(db, integration.NewKeyring(newKey), store.logger)
+		retrieved, err := rekeyed.GetByProject(ctx, repo.ProjectID)
+		require.NoError(t, err)
+		assert.Equal(t, "ghp_secret", retrieved.Token)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, repo.ProjectID))
+		assert.ErrorIs(t, store.Delete(ctx, repo.ProjectID), ErrRepositoryNotFound)
+		assert.ErrorIs(t, store.Delete(ctx, uuid.New()), ErrRepositoryNotFound)
+	})
+}
@@ -0,0 +1,101 @@
+package scriptrepo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// Pusher commits generated scripts to the repository configured for their
+// project and records the outcome on it.
+type Pusher struct {
+	store     Store
+	newClient func(repo *Repository) (Client, error)
+	logger    logger.Logger
+}
+
+// NewPusher creates a new pusher of the repositories in store.
+func NewPusher(store Store, log logger.Logger) *Pusher {
+	return &Pusher{
+		store:     store,
+		newClient: NewClient,
+		logger:    log,
+	}
+}
+
+// Push commits a script to the project's repository. It returns
+// ErrRepositoryNotFound if the project has none.
+func (p *Pusher) Push(ctx context.Context, projectID uuid.UUID, content []byte, info ScriptInfo) (*PushResult, error) {
+	repo, err := p.store.GetByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return p.push(ctx, repo, content, info)
+}
+
+// AutoPush commits a script that was just generated to the project's
+// repository if it has one with auto-push enabled. Failures are logged and
+// recorded on the repository, never returned: the script itself is stored.
+// A nil Pusher pushes nothing.
+func (p *Pusher) AutoPush(ctx context.Context, projectID uuid.UUID, content []byte, info ScriptInfo) {
+	if p == nil {
+		return
+	}
+
+	repo, err := p.store.GetByProject(ctx, projectID)
+	if err != nil {
+		if !errors.Is(err, ErrRepositoryNotFound) {
+			p.logger.Warn(ctx, "failed to get script repository for auto-push", map[string]interface{}{
+				"error":      err.Error(),
+				"project_id": projectID.String(),
+			})
+		}
+		return
+	}
+	if !repo.AutoPush {
+		return
+	}
+
+	if _, err := p.push(ctx, repo, content, info); err != nil {
+		p.logger.Warn(ctx, "failed to auto-push script", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+			"file_name":  info.FileName,
+		})
+	}
+}
+
+func (p *Pusher) push(ctx context.Context, repo *Repository, content []byte, info ScriptInfo) (*PushResult, error) {
+	commit, err := repo.NewCommit(content, info)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := p.newClient(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	result, pushErr := client.Push(ctx, commit)
+	// The outcome is recorded even when the request was cancelled.
+	if err := p.store.RecordPush(context.WithoutCancel(ctx), repo.ID, time.Now(), pushErr); err != nil {
+		p.logger.Warn(ctx, "failed to record script push", map[string]interface{}{
+			"error":         err.Error(),
+			"repository_id": repo.ID.String(),
+		})
+	}
+	if pushErr != nil {
+		return nil, pushErr
+	}
+
+	p.logger.Info(ctx, "script pushed to repository", map[string]interface{}{
+		"project_id": repo.ProjectID.String(),
+		"repository": repo.Repository,
+		"path":       result.Path,
+		"commit_sha": result.CommitSHA,
+	})
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package scriptrepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient records the commits pushed to it.
+type fakeClient struct {
+	commits []*Commit
+	err     error
+}
+
+func (c *fakeClient) Push(ctx context.Context, commit *Commit) (*PushResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.commits = append(c.commits, commit)
+	return &PushResult{Path: commit.Path, Branch: commit.Branch, CommitSHA: "abc123"}, nil
+}
+
+func newTestPusher(store Store, client *fakeClient) *Pusher {
+	pusher := NewPusher(store, logger.NewTestLogger())
+	pusher.newClient = func(repo *Repository) (Client, error) { return client, nil }
+	return pusher
+}
+
+func TestPusher(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	info := ScriptInfo{ProcedureName: "Login", ProcedureVersion: 1, Framework: "selenium", FileName: "login_selenium.py"}
+
+	repo := newTestRepository()
+	require.NoError(t, store.Save(ctx, repo))
+
+	t.Run("push", func(t *testing.T) {
+		client := &fakeClient{}
+		result, err := newTestPusher(store, client).Push(ctx, repo.ProjectID, []byte("x"), info)
+		require.NoError(t, err)
+		assert.Equal(t, "ui-automation/login_selenium.py", result.Path)
+		require.Len(t, client.commits, 1)
+	})
+
+	t.Run("failed push is recorded", func(t *testing.T) {
+		client := &fakeClient{err: errors.New("github: commit file failed with status 403")}
+		_, err := newTestPusher(store, client).Push(ctx, repo.ProjectID, []byte("x"), info)
+		assert.Error(t, err)
+
+		retrieved, err := store.GetByProject(ctx, repo.ProjectID)
+		require.NoError(t, err)
+		assert.Contains(t, retrieved.LastPushError, "403")
+	})
+
+	t.Run("auto-push only when enabled", func(t *testing.T) {
+		client := &fakeClient{}
+		pusher := newTestPusher(store, client)
+		pusher.AutoPush(ctx, repo.ProjectID, []byte("x"), info)
+		assert.Empty(t, client.commits)
+
+		repo.AutoPush = true
+		repo.Token = ""
+		require.NoError(t, store.Save(ctx, repo))
+		pusher.AutoPush(ctx, repo.ProjectID, []byte("x"), info)
+		assert.Len(t, client.commits, 1)
+	})
+
+	t.Run("no repository", func(t *testing.T) {
+		_, err := newTestPusher(store, &fakeClient{}).Push(ctx, newTestRepository().ProjectID, []byte("x"), info)
+		assert.ErrorIs(t, err, ErrRepositoryNotFound)
+	})
+}
@@ -0,0 +1,39 @@
+package scriptrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"gorm.io/gorm"
+)
+
+// RekeyTokens re-encrypts every script repository token from oldKey to
+// newKey and returns how many were re-encrypted. Tokens already encrypted
+// with newKey are left alone. It stops at the first value that decrypts with
+// neither key; run it in a transaction so that nothing is left half rotated.
+func RekeyTokens(ctx context.Context, db *gorm.DB, oldKey, newKey []byte) (int, error) {
+	var repos []Repository
+	if err := db.WithContext(ctx).Select("id", "encrypted_token").Find(&repos).Error; err != nil {
+		return 0, fmt.Errorf("failed to list script repositories: %w", err)
+	}
+
+	count := 0
+	for _, repo := range repos {
+		encrypted, changed, err := integration.Reencrypt(oldKey, newKey, repo.EncryptedToken)
+		if err != nil {
+			return 0, fmt.Errorf("script repository %s: %w", repo.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		err = db.WithContext(ctx).Model(&Repository{}).
+			Where("id = ?", repo.ID).
+			UpdateColumn("encrypted_token", encrypted).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to update script repository %s: %w", repo.ID, err)
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,207 @@
+package scriptrepo
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRepositoryNotFound is returned when a project has no script
+	// repository configured.
+	ErrRepositoryNotFound = errors.New("script repository not found")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidProvider is returned when provider is not github or gitlab.
+	ErrInvalidProvider = errors.New("provider must be github or gitlab")
+
+	// ErrInvalidRepository is returned when repository is not a path such
+	// as owner/name.
+	ErrInvalidRepository = errors.New("repository must be a path such as owner/name")
+
+	// ErrInvalidBranch is returned when a branch name is not usable.
+	ErrInvalidBranch = errors.New("invalid branch name")
+
+	// ErrInvalidDirectory is returned when directory leaves the repository.
+	ErrInvalidDirectory = errors.New("directory must be a relative path inside the repository")
+
+	// ErrInvalidCommitMessage is returned when the commit message template
+	// does not parse.
+	ErrInvalidCommitMessage = errors.New("invalid commit message template")
+
+	// ErrPullRequestBaseRequired is returned when pull requests are enabled
+	// without a different base branch to open them against.
+	ErrPullRequestBaseRequired = errors.New("base_branch, different from branch, is required to create pull requests")
+
+	// ErrTokenRequired is returned when a repository is saved without an
+	// access token.
+	ErrTokenRequired = errors.New("token is required")
+
+	// ErrDecryptFailed is returned when a stored token cannot be decrypted.
+	ErrDecryptFailed = errors.New("failed to decrypt script repository token")
+)
+
+// DefaultCommitMessage is the commit message template used when none is
+// configured.
+const DefaultCommitMessage = "Update {{.FileName}} from {{.ProcedureName}} v{{.ProcedureVersion}}"
+
+// DefaultDirectory is the directory of the repository scripts are committed
+// to when none is configured.
+const DefaultDirectory = "ui-automation"
+
+// Provider is the Git hosting service of a repository.
+type Provider string
+
+const (
+	ProviderGitHub Provider = "github"
+	ProviderGitLab Provider = "gitlab"
+)
+
+// IsValid checks if the provider is supported.
+func (p Provider) IsValid() bool {
+	return p == ProviderGitHub || p == ProviderGitLab
+}
+
+// Repository is the Git repository a project's generated scripts are
+// committed to. Scripts are committed to Branch, which is created from
+// BaseBranch when it does not exist yet; with CreatePullRequest, a pull (or
+// merge) request from Branch into BaseBranch is opened so that the scripts
+// go through code review. With AutoPush, scripts are committed as soon as
+// they are generated.
+type Repository struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:char(36);not null;uniqueIndex:idx_script_repositories_project_id"`
+	Provider  Provider  `json:"provider" gorm:"type:varchar(20);not null"`
+	// BaseURL is the API of a self-hosted GitHub Enterprise or GitLab
+	// instance; empty uses github.com or gitlab.com.
+	BaseURL           string `json:"base_url,omitempty" gorm:"type:varchar(500);not null;default:''"`
+	Repository        string `json:"repository" gorm:"type:varchar(255);not null"`
+	Branch            string `json:"branch" gorm:"type:varchar(255);not null"`
+	BaseBranch        string `json:"base_branch,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	Directory         string `json:"directory" gorm:"type:varchar(500);not null"`
+	CommitMessage     string `json:"commit_message" gorm:"type:varchar(1000);not null"`
+	CreatePullRequest bool   `json:"create_pull_request" gorm:"not null;default:false"`
+	AutoPush          bool   `json:"auto_push" gorm:"not null;default:false"`
+	// Token is stored encrypted and never serialized.
+	Token           string `json:"-" gorm:"-"`
+	EncryptedToken  []byte `json:"-" gorm:"type:blob;not null"`
+	TokenConfigured bool   `json:"token_configured" gorm:"-"`
+	// LastPushedAt and LastPushError record the last push; the error is
+	// empty if it succeeded.
+	LastPushedAt  *time.Time `json:"last_pushed_at,omitempty"`
+	LastPushError string     `json:"last_push_error,omitempty" gorm:"type:varchar(1000);not null;default:''"`
+	CreatedBy     uuid.UUID  `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (r *Repository) TableName() string {
+	return "script_repositories"
+}
+
+// BeforeCreate hook to generate UUID before creating a new repository
+func (r *Repository) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Normalize fills in the defaults of unset fields.
+func (r *Repository) Normalize() {
+	r.BaseURL = strings.TrimRight(strings.TrimSpace(r.BaseURL), "/")
+	r.Repository = strings.Trim(strings.TrimSpace(r.Repository), "/")
+	r.Directory = strings.Trim(strings.TrimSpace(r.Directory), "/")
+	if r.Directory == "" {
+		r.Directory = DefaultDirectory
+	}
+	if strings.TrimSpace(r.CommitMessage) == "" {
+		r.CommitMessage = DefaultCommitMessage
+	}
+}
+
+// Validate checks if the repository has valid required fields.
+func (r *Repository) Validate() error {
+	if r.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if !r.Provider.IsValid() {
+		return ErrInvalidProvider
+	}
+	if !strings.Contains(r.Repository, "/") || strings.ContainsAny(r.Repository, " \t\n") {
+		return ErrInvalidRepository
+	}
+	if !validBranch(r.Branch) || (r.BaseBranch != "" && !validBranch(r.BaseBranch)) {
+		return ErrInvalidBranch
+	}
+	if path.IsAbs(r.Directory) || path.Clean(r.Directory) != r.Directory || strings.HasPrefix(r.Directory, "..") {
+		return ErrInvalidDirectory
+	}
+	if _, err := parseCommitMessage(r.CommitMessage); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCommitMessage, err)
+	}
+	if r.CreatePullRequest && (r.BaseBranch == "" || r.BaseBranch == r.Branch) {
+		return ErrPullRequestBaseRequired
+	}
+	return nil
+}
+
+// validBranch checks a branch name against the rules of git check-ref-format
+// that matter in practice.
+func validBranch(name string) bool {
+	if name == "" || len(name) > 255 || strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") ||
+		strings.HasSuffix(name, ".lock") || strings.HasPrefix(name, "-") || strings.Contains(name, "..") {
+		return false
+	}
+	return !strings.ContainsAny(name, " \t\n~^:?*[\\")
+}
+
+func parseCommitMessage(message string) (*template.Template, error) {
+	return template.New("commit_message").Option("missingkey=error").Parse(message)
+}
+
+// ScriptInfo describes a generated script to commit. It is the data
+// available to commit message templates.
+type ScriptInfo struct {
+	ProcedureName    string
+	ProcedureVersion uint
+	Framework        string
+	FileName         string
+}
+
+// NewCommit builds the commit of a script to the repository.
+func (r *Repository) NewCommit(content []byte, info ScriptInfo) (*Commit, error) {
+	tmpl, err := parseCommitMessage(r.CommitMessage)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCommitMessage, err)
+	}
+	var message strings.Builder
+	if err := tmpl.Execute(&message, info); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCommitMessage, err)
+	}
+
+	commit := &Commit{
+		Path:       path.Join(r.Directory, info.FileName),
+		Content:    content,
+		Message:    strings.TrimSpace(message.String()),
+		Branch:     r.Branch,
+		BaseBranch: r.BaseBranch,
+	}
+	if r.CreatePullRequest {
+		commit.PullRequest = &PullRequest{
+			Title: strings.SplitN(commit.Message, "\n", 2)[0],
+			Body: fmt.Sprintf("Generated %s script for the test procedure \"%s\" (version %d).",
+				info.Framework, info.ProcedureName, info.ProcedureVersion),
+		}
+	}
+	return commit, nil
+}
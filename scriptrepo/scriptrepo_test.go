@@ -0,0 +1,83 @@
+package scriptrepo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *Repository)
+		err    error
+	}{
+		{"valid", func(r *Repository) {}, nil},
+		{"unknown provider", func(r *Repository) { r.Provider = "bitbucket" }, ErrInvalidProvider},
+		{"repository without owner", func(r *Repository) { r.Repository = "web-tests" }, ErrInvalidRepository},
+		{"nested gitlab group", func(r *Repository) { r.Provider = ProviderGitLab; r.Repository = "acme/qa/web-tests" }, nil},
+		{"branch with space", func(r *Repository) { r.Branch = "ui tests" }, ErrInvalidBranch},
+		{"branch with dots", func(r *Repository) { r.Branch = "a..b" }, ErrInvalidBranch},
+		{"directory outside repository", func(r *Repository) { r.Directory = "../etc" }, ErrInvalidDirectory},
+		{"bad commit message", func(r *Repository) { r.CommitMessage = "{{.FileName" }, ErrInvalidCommitMessage},
+		{"pull request without base", func(r *Repository) { r.CreatePullRequest = true; r.BaseBranch = "" }, ErrPullRequestBaseRequired},
+		{"pull request into same branch", func(r *Repository) { r.CreatePullRequest = true; r.BaseBranch = r.Branch }, ErrPullRequestBaseRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepository()
+			tt.modify(repo)
+			repo.Normalize()
+
+			err := repo.Validate()
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRepository_NewCommit(t *testing.T) {
+	info := ScriptInfo{ProcedureName: "Login", ProcedureVersion: 4, Framework: "playwright", FileName: "login_playwright.py"}
+
+	t.Run("defaults", func(t *testing.T) {
+		repo := newTestRepository()
+		repo.Normalize()
+
+		commit, err := repo.NewCommit([]byte("print('hi')\n"), info)
+		require.NoError(t, err)
+		assert.Equal(t, "ui-automation/login_playwright.py", commit.Path)
+		assert.Equal(t, "Update login_playwright.py from Login v4", commit.Message)
+		assert.Equal(t, "ui-automation/scripts", commit.Branch)
+		assert.Equal(t, "main", commit.BaseBranch)
+		assert.Nil(t, commit.PullRequest)
+	})
+
+	t.Run("templated message and pull request", func(t *testing.T) {
+		repo := newTestRepository()
+		repo.Directory = "/tests/e2e/"
+		repo.CommitMessage = "test({{.Framework}}): regenerate {{.ProcedureName}}\n\nVersion {{.ProcedureVersion}}"
+		repo.CreatePullRequest = true
+		repo.Normalize()
+
+		commit, err := repo.NewCommit([]byte("print('hi')\n"), info)
+		require.NoError(t, err)
+		assert.Equal(t, "tests/e2e/login_playwright.py", commit.Path)
+		assert.Equal(t, "test(playwright): regenerate Login\n\nVersion 4", commit.Message)
+		require.NotNil(t, commit.PullRequest)
+		assert.Equal(t, "test(playwright): regenerate Login", commit.PullRequest.Title)
+		assert.Contains(t, commit.PullRequest.Body, "\"Login\" (version 4)")
+	})
+
+	t.Run("unknown template field", func(t *testing.T) {
+		repo := newTestRepository()
+		repo.CommitMessage = "{{.Author}}"
+
+		_, err := repo.NewCommit([]byte("x"), info)
+		assert.ErrorIs(t, err, ErrInvalidCommitMessage)
+	})
+}
@@ -0,0 +1,26 @@
+package scriptrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for script repository persistence operations.
+type Store interface {
+	// GetByProject retrieves the repository of a project with its token
+	// decrypted.
+	GetByProject(ctx context.Context, projectID uuid.UUID) (*Repository, error)
+
+	// Save creates or replaces the repository of its project. An empty
+	// Token keeps the token already saved, if any.
+	Save(ctx context.Context, repo *Repository) error
+
+	// Delete deletes the repository of a project.
+	Delete(ctx context.Context, projectID uuid.UUID) error
+
+	// RecordPush records the outcome of a push; pushErr is nil if it
+	// succeeded.
+	RecordPush(ctx context.Context, id uuid.UUID, at time.Time, pushErr error) error
+}
@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// AWSProvider reads secrets from AWS Secrets Manager. It uses AWS SDK v2's
+// default credential chain (IAM role on EC2) to sign requests.
+type AWSProvider struct {
+	httpClient  *http.Client
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	region      string
+	endpoint    string
+}
+
+// NewAWSProvider creates a provider for AWS Secrets Manager in region.
+func NewAWSProvider(ctx context.Context, region string) (*AWSProvider, error) {
+	if region == "" {
+		return nil, fmt.Errorf("aws secrets manager region cannot be empty")
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &AWSProvider{
+		httpClient:  newHTTPClient(),
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		region:      region,
+		endpoint:    fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region),
+	}, nil
+}
+
+// GetSecret returns the string value of the current version of a secret,
+// given by name or ARN.
+func (p *AWSProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": name})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	creds, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("aws secrets manager: failed to get credentials: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := p.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "secretsmanager", p.region, time.Now()); err != nil {
+		return "", fmt.Errorf("aws secrets manager: failed to sign request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Type string `json:"__type"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && strings.HasSuffix(apiErr.Type, "ResourceNotFoundException") {
+			return "", ErrSecretNotFound
+		}
+		return "", fmt.Errorf("aws secrets manager: get secret failed with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var result struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("aws secrets manager: failed to decode response: %w", err)
+	}
+	if result.SecretString == nil {
+		return "", fmt.Errorf("aws secrets manager: secret has no string value")
+	}
+	return *result.SecretString, nil
+}
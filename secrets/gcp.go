@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcpSecretManagerURL = "https://secretmanager.googleapis.com"
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// GCPProvider reads secrets from Google Secret Manager. It authenticates as
+// the service account attached to the instance or workload, with access
+// tokens from the metadata server.
+type GCPProvider struct {
+	httpClient  *http.Client
+	project     string
+	baseURL     string
+	metadataURL string
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewGCPProvider creates a provider for Google Secret Manager secrets in
+// project.
+func NewGCPProvider(project string) (*GCPProvider, error) {
+	if project == "" {
+		return nil, fmt.Errorf("gcp secret manager project cannot be empty")
+	}
+
+	return &GCPProvider{
+		httpClient:  newHTTPClient(),
+		project:     project,
+		baseURL:     gcpSecretManagerURL,
+		metadataURL: gcpMetadataTokenURL,
+	}, nil
+}
+
+// secretVersion returns the resource name of the secret version name
+// refers to. Names may be a secret ID, "ID/versions/N" or a full resource
+// name; versions default to the latest.
+func (p *GCPProvider) secretVersion(name string) string {
+	if !strings.HasPrefix(name, "projects/") {
+		name = "projects/" + p.project + "/secrets/" + name
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	return name
+}
+
+// GetSecret returns the payload of a secret version.
+func (p *GCPProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s:access", p.baseURL, p.secretVersion(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrSecretNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("gcp secret manager: access secret failed with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var result struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("gcp secret manager: failed to decode response: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: failed to decode payload: %w", err)
+	}
+	return string(data), nil
+}
+
+// accessToken returns a cached access token, fetching a new one from the
+// metadata server a minute before it expires.
+func (p *GCPProvider) accessToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.metadataURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: failed to create token request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: failed to get access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("gcp secret manager: get access token failed with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("gcp secret manager: failed to decode access token: %w", err)
+	}
+
+	p.token = result.AccessToken
+	p.tokenExpiry = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return p.token, nil
+}
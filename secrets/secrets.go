@@ -0,0 +1,91 @@
+// Package secrets reads configuration secrets, such as the credential
+// encryption key and the database password, from a secrets manager instead
+// of the config file, and keeps them up to date as they are rotated.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSecretNotFound  = errors.New("secret not found")
+	ErrFieldNotFound   = errors.New("secret field not found")
+	ErrInvalidProvider = errors.New("invalid secrets provider")
+)
+
+// Supported secrets managers.
+const (
+	ProviderAWS   = "aws"
+	ProviderGCP   = "gcp"
+	ProviderVault = "vault"
+)
+
+// Provider reads secrets from a secrets manager.
+type Provider interface {
+	// GetSecret returns the current value of the named secret.
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// Config selects a secrets manager and how to reach it.
+type Config struct {
+	Provider string
+	// AWSRegion is the region of AWS Secrets Manager.
+	AWSRegion string
+	// GCPProject is the project of Google Secret Manager secrets given by
+	// name rather than full resource name.
+	GCPProject string
+	// VaultAddress, VaultToken and VaultMount locate a Vault KV version 2
+	// secrets engine.
+	VaultAddress string
+	VaultToken   string
+	VaultMount   string
+}
+
+// NewProvider creates the provider cfg selects.
+func NewProvider(ctx context.Context, cfg Config) (Provider, error) {
+	switch cfg.Provider {
+	case ProviderAWS:
+		return NewAWSProvider(ctx, cfg.AWSRegion)
+	case ProviderGCP:
+		return NewGCPProvider(cfg.GCPProject)
+	case ProviderVault:
+		return NewVaultProvider(cfg.VaultAddress, cfg.VaultToken, cfg.VaultMount)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidProvider, cfg.Provider)
+	}
+}
+
+// Resolve reads the secret ref points to. A reference is a secret name,
+// optionally followed by "#field" to pick a string field of a secret that
+// holds a JSON object.
+func Resolve(ctx context.Context, p Provider, ref string) (string, error) {
+	name, field, hasField := strings.Cut(ref, "#")
+	value, err := p.GetSecret(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", name, err)
+	}
+	if !hasField {
+		return value, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object", name)
+	}
+	s, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s: %w: %s", name, ErrFieldNotFound, field)
+	}
+	return s, nil
+}
+
+// newHTTPClient returns the client providers call their secrets manager with.
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
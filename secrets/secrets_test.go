@@ -0,0 +1,176 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapProvider serves secrets from a map.
+type mapProvider map[string]string
+
+func (p mapProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	value, ok := p[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	provider := mapProvider{
+		"plain": "hunter2",
+		"json":  `{"password":"s3cret","port":3306}`,
+	}
+
+	t.Run("whole secret", func(t *testing.T) {
+		value, err := Resolve(ctx, provider, "plain")
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", value)
+	})
+
+	t.Run("field of a JSON secret", func(t *testing.T) {
+		value, err := Resolve(ctx, provider, "json#password")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", value)
+	})
+
+	t.Run("missing or non-string field", func(t *testing.T) {
+		_, err := Resolve(ctx, provider, "json#user")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+		_, err = Resolve(ctx, provider, "json#port")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+	})
+
+	t.Run("field of a plain secret", func(t *testing.T) {
+		_, err := Resolve(ctx, provider, "plain#password")
+		assert.Error(t, err)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := Resolve(ctx, provider, "missing")
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider(context.Background(), Config{Provider: "keepass"})
+	assert.ErrorIs(t, err, ErrInvalidProvider)
+
+	_, err = NewProvider(context.Background(), Config{Provider: ProviderGCP})
+	assert.Error(t, err)
+
+	_, err = NewProvider(context.Background(), Config{Provider: ProviderVault, VaultAddress: "vault:8200", VaultToken: "token"})
+	assert.Error(t, err)
+
+	provider, err := NewProvider(context.Background(), Config{Provider: ProviderVault, VaultAddress: "https://vault:8200", VaultToken: "token"})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", provider.(*VaultProvider).mount)
+}
+
+func TestAWSProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+
+		var body struct{ SecretId string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body.SecretId != "ui-automation" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"encryption_key":"abc"}`})
+	}))
+	defer server.Close()
+
+	provider := &AWSProvider{
+		httpClient: server.Client(),
+		credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+		}),
+		signer:   v4.NewSigner(),
+		region:   "us-east-1",
+		endpoint: server.URL,
+	}
+
+	value, err := Resolve(context.Background(), provider, "ui-automation#encryption_key")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", value)
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestGCPProvider(t *testing.T) {
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+			tokenRequests++
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "ya29.test", "expires_in": 3600})
+			return
+		}
+
+		assert.Equal(t, "Bearer ya29.test", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/v1/projects/my-project/secrets/db-password/versions/latest:access",
+			"/v1/projects/other/secrets/db-password/versions/3:access":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte("hunter2"))},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewGCPProvider("my-project")
+	require.NoError(t, err)
+	provider.httpClient = server.Client()
+	provider.baseURL = server.URL
+	provider.metadataURL = server.URL + "/token"
+
+	for _, name := range []string{"db-password", "projects/other/secrets/db-password/versions/3"} {
+		value, err := provider.GetSecret(context.Background(), name)
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", value)
+	}
+	assert.Equal(t, 1, tokenRequests)
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "s.token", r.Header.Get("X-Vault-Token"))
+		if r.URL.Path != "/v1/kv/data/ui-automation" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"encryption_key":"abc"},"metadata":{"version":2}}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewVaultProvider(server.URL+"/", "s.token", "/kv/")
+	require.NoError(t, err)
+	provider.httpClient = server.Client()
+
+	value, err := Resolve(context.Background(), provider, "ui-automation#encryption_key")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", value)
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// VaultProvider reads secrets from a HashiCorp Vault KV version 2 secrets
+// engine. Vault secrets are JSON objects, so references to them select a
+// field, as in "ui-automation#encryption_key".
+type VaultProvider struct {
+	httpClient *http.Client
+	address    string
+	token      string
+	mount      string
+}
+
+// NewVaultProvider creates a provider for the KV engine mounted at mount
+// on the Vault server at address, authenticating with token.
+func NewVaultProvider(address, token, mount string) (*VaultProvider, error) {
+	address = strings.TrimRight(address, "/")
+	if u, err := url.Parse(address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("vault address must be an http(s) URL")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("vault token cannot be empty")
+	}
+	if mount == "" {
+		mount = "secret"
+	}
+
+	return &VaultProvider{
+		httpClient: newHTTPClient(),
+		address:    address,
+		token:      token,
+		mount:      strings.Trim(mount, "/"),
+	}, nil
+}
+
+// GetSecret returns the latest version of the secret at path name as a
+// JSON object.
+func (p *VaultProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.address, p.mount, strings.Trim(name, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("vault: failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrSecretNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("vault: read secret failed with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var result struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("vault: failed to decode response: %w", err)
+	}
+	if len(result.Data.Data) == 0 || string(result.Data.Data) == "null" {
+		// A deleted latest version reads as null data.
+		return "", ErrSecretNotFound
+	}
+	return string(result.Data.Data), nil
+}
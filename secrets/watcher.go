@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// Value is a secret that a Watcher keeps current.
+type Value struct {
+	ref string
+
+	mu       sync.RWMutex
+	current  string
+	onChange []func(string)
+}
+
+// Get returns the secret's current value.
+func (v *Value) Get() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.current
+}
+
+// OnChange registers fn to be called with the new value when a refresh
+// finds the secret has been rotated.
+func (v *Value) OnChange(fn func(string)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.onChange = append(v.onChange, fn)
+}
+
+// set stores value and reports whether it changed, with the functions to
+// notify of the change.
+func (v *Value) set(value string) (bool, []func(string)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if value == v.current {
+		return false, nil
+	}
+	v.current = value
+	return true, append([]func(string){}, v.onChange...)
+}
+
+// Watcher re-reads watched secrets periodically so that rotated values are
+// picked up without a restart.
+type Watcher struct {
+	provider Provider
+	logger   logger.Logger
+
+	mu     sync.Mutex
+	values []*Value
+	stopCh chan struct{}
+}
+
+// NewWatcher creates a watcher for secrets read from provider.
+func NewWatcher(provider Provider, log logger.Logger) *Watcher {
+	return &Watcher{
+		provider: provider,
+		logger:   log,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Watch reads the secret ref points to (see Resolve) and returns it as a
+// Value that later refreshes keep current.
+func (w *Watcher) Watch(ctx context.Context, ref string) (*Value, error) {
+	current, err := Resolve(ctx, w.provider, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Value{ref: ref, current: current}
+	w.mu.Lock()
+	w.values = append(w.values, v)
+	w.mu.Unlock()
+	return v, nil
+}
+
+// Refresh re-reads every watched secret and notifies the change functions
+// of those that changed. A secret that cannot be read keeps its last value.
+func (w *Watcher) Refresh(ctx context.Context) {
+	w.mu.Lock()
+	values := append([]*Value{}, w.values...)
+	w.mu.Unlock()
+
+	for _, v := range values {
+		value, err := Resolve(ctx, w.provider, v.ref)
+		if err != nil {
+			w.logger.Error(ctx, "failed to refresh secret", map[string]interface{}{
+				"error":  err.Error(),
+				"secret": v.ref,
+			})
+			continue
+		}
+
+		changed, notify := v.set(value)
+		if !changed {
+			continue
+		}
+		w.logger.Info(ctx, "secret rotated", map[string]interface{}{
+			"secret": v.ref,
+		})
+		for _, fn := range notify {
+			fn(value)
+		}
+	}
+}
+
+// Start starts a background goroutine that refreshes the watched secrets
+// every interval.
+func (w *Watcher) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				w.Refresh(context.Background())
+			case <-w.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the refresh goroutine.
+func (w *Watcher) Stop() {
+	close(w.stopCh)
+}
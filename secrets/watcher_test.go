@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	provider := mapProvider{"db": `{"password":"one"}`}
+	watcher := NewWatcher(provider, logger.NewTestLogger())
+
+	_, err := watcher.Watch(ctx, "missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	value, err := watcher.Watch(ctx, "db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "one", value.Get())
+
+	var changes []string
+	value.OnChange(func(v string) { changes = append(changes, v) })
+
+	watcher.Refresh(ctx)
+	assert.Empty(t, changes)
+
+	provider["db"] = `{"password":"two"}`
+	watcher.Refresh(ctx)
+	assert.Equal(t, "two", value.Get())
+	assert.Equal(t, []string{"two"}, changes)
+
+	// A secret that cannot be read keeps its last value.
+	delete(provider, "db")
+	watcher.Refresh(ctx)
+	assert.Equal(t, "two", value.Get())
+	assert.Equal(t, []string{"two"}, changes)
+}
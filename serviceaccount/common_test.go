@@ -0,0 +1,34 @@
+package serviceaccount
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/project"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and service account store for
+// testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &user.User{}, &project.Project{}, &ServiceAccount{}, &Grant{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestProject creates a project and its owner, returning both IDs.
+func createTestProject(t *testing.T, db *gorm.DB, email string) (uuid.UUID, uuid.UUID) {
+	owner := &user.User{Email: email, Username: email, PasswordHash: "hash", IsActive: true}
+	require.NoError(t, db.Create(owner).Error)
+	p := &project.Project{Name: "Project", OwnerID: owner.ID, IsActive: true}
+	require.NoError(t, db.Create(p).Error)
+	return p.ID, owner.ID
+}
@@ -0,0 +1,200 @@
+package serviceaccount
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed service account store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new service account and, in the same transaction, the
+// user behind it. The user has no password, so it cannot log in.
+func (s *MySQLStore) Create(ctx context.Context, sa *ServiceAccount) error {
+	if err := sa.Validate(); err != nil {
+		return err
+	}
+	if sa.ID == uuid.Nil {
+		sa.ID = uuid.New()
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		u := &user.User{
+			ID:       sa.ID,
+			Email:    sa.Email(),
+			Username: sa.Name,
+			Role:     user.RoleService,
+			IsActive: true,
+		}
+		if err := tx.Create(u).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Grants").Create(sa).Error
+	})
+	if err != nil {
+		s.logger.Error(ctx, "failed to create service account", map[string]interface{}{
+			"error": err.Error(),
+			"name":  sa.Name,
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "service account created", map[string]interface{}{
+		"service_account_id": sa.ID.String(),
+		"created_by":         sa.CreatedBy.String(),
+	})
+
+	return nil
+}
+
+// GetByID retrieves a service account with its grants.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*ServiceAccount, error) {
+	var sa ServiceAccount
+	err := s.db.WithContext(ctx).
+		Preload("Grants").
+		Where("id = ?", id).
+		First(&sa).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServiceAccountNotFound
+		}
+		s.logger.Error(ctx, "failed to get service account by ID", map[string]interface{}{
+			"error":              err.Error(),
+			"service_account_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &sa, nil
+}
+
+// List retrieves every service account with its grants, ordered by name.
+func (s *MySQLStore) List(ctx context.Context) ([]*ServiceAccount, error) {
+	var accounts []*ServiceAccount
+	err := s.db.WithContext(ctx).
+		Preload("Grants").
+		Order("name ASC").
+		Find(&accounts).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list service accounts", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// Grant grants a service account a project, or changes the scope it is
+// granted.
+func (s *MySQLStore) Grant(ctx context.Context, grant *Grant) error {
+	if err := grant.Validate(); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "service_account_id"}, {Name: "project_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"scope", "updated_at"}),
+		}).
+		Create(grant).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to grant service account project", map[string]interface{}{
+			"error":              err.Error(),
+			"service_account_id": grant.ServiceAccountID.String(),
+			"project_id":         grant.ProjectID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "service account granted project", map[string]interface{}{
+		"service_account_id": grant.ServiceAccountID.String(),
+		"project_id":         grant.ProjectID.String(),
+		"scope":              grant.Scope,
+	})
+
+	return nil
+}
+
+// Ungrant revokes a service account's grant of a project.
+func (s *MySQLStore) Ungrant(ctx context.Context, id, projectID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("service_account_id = ? AND project_id = ?", id, projectID).
+		Delete(&Grant{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to revoke service account grant", map[string]interface{}{
+			"error":              result.Error.Error(),
+			"service_account_id": id.String(),
+			"project_id":         projectID.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrGrantNotFound
+	}
+
+	s.logger.Info(ctx, "service account grant revoked", map[string]interface{}{
+		"service_account_id": id.String(),
+		"project_id":         projectID.String(),
+	})
+
+	return nil
+}
+
+// Delete deletes a service account and its grants, and deactivates the user
+// behind it.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("service_account_id = ?", id).Delete(&Grant{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ?", id).Delete(&ServiceAccount{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrServiceAccountNotFound
+		}
+		return tx.Model(&user.User{}).
+			Where("id = ?", id).
+			Update("is_active", false).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrServiceAccountNotFound) {
+			s.logger.Error(ctx, "failed to delete service account", map[string]interface{}{
+				"error":              err.Error(),
+				"service_account_id": id.String(),
+			})
+		}
+		return err
+	}
+
+	s.logger.Info(ctx, "service account deleted", map[string]interface{}{
+		"service_account_id": id.String(),
+	})
+
+	return nil
+}
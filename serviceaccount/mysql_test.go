@@ -0,0 +1,122 @@
+package serviceaccount
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"github.com/hairizuanbinnoorazman/ui-automation/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Create(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	sa := &ServiceAccount{Name: "ci", Description: "Nightly CI", CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, sa))
+	assert.NotEqual(t, uuid.Nil, sa.ID)
+
+	var u user.User
+	require.NoError(t, db.Where("id = ?", sa.ID).First(&u).Error)
+	assert.True(t, u.IsServiceAccount())
+	assert.True(t, u.IsActive)
+	assert.Equal(t, "ci", u.Username)
+	assert.True(t, strings.HasSuffix(u.Email, "@"+emailDomain))
+	assert.Empty(t, u.PasswordHash)
+
+	assert.ErrorIs(t, store.Create(ctx, &ServiceAccount{CreatedBy: uuid.New()}), ErrInvalidName)
+}
+
+func TestMySQLStore_GetByIDAndList(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	projectID, _ := createTestProject(t, db, "owner@example.com")
+	beta := &ServiceAccount{Name: "beta", CreatedBy: uuid.New()}
+	alpha := &ServiceAccount{Name: "alpha", CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, beta))
+	require.NoError(t, store.Create(ctx, alpha))
+	require.NoError(t, store.Grant(ctx, &Grant{ServiceAccountID: beta.ID, ProjectID: projectID, Scope: apitoken.ScopeReadOnly}))
+
+	found, err := store.GetByID(ctx, beta.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "beta", found.Name)
+	require.Len(t, found.Grants, 1)
+	assert.Equal(t, projectID, found.Grants[0].ProjectID)
+
+	_, err = store.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrServiceAccountNotFound)
+
+	accounts, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "alpha", accounts[0].Name)
+	assert.Equal(t, "beta", accounts[1].Name)
+	assert.Len(t, accounts[1].Grants, 1)
+}
+
+func TestMySQLStore_GrantAndUngrant(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	projectID, _ := createTestProject(t, db, "owner@example.com")
+	sa := &ServiceAccount{Name: "ci", CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, sa))
+
+	t.Run("grant and change scope", func(t *testing.T) {
+		require.NoError(t, store.Grant(ctx, &Grant{ServiceAccountID: sa.ID, ProjectID: projectID, Scope: apitoken.ScopeReadOnly}))
+		require.NoError(t, store.Grant(ctx, &Grant{ServiceAccountID: sa.ID, ProjectID: projectID, Scope: apitoken.ScopeReadWrite}))
+
+		found, err := store.GetByID(ctx, sa.ID)
+		require.NoError(t, err)
+		scope, ok := found.Scope(projectID)
+		assert.True(t, ok)
+		assert.Equal(t, apitoken.ScopeReadWrite, scope)
+		assert.Len(t, found.Grants, 1)
+	})
+
+	t.Run("rejects invalid scope", func(t *testing.T) {
+		err := store.Grant(ctx, &Grant{ServiceAccountID: sa.ID, ProjectID: projectID, Scope: "owner"})
+		assert.ErrorIs(t, err, ErrInvalidScope)
+	})
+
+	t.Run("ungrant", func(t *testing.T) {
+		require.NoError(t, store.Ungrant(ctx, sa.ID, projectID))
+		assert.ErrorIs(t, store.Ungrant(ctx, sa.ID, projectID), ErrGrantNotFound)
+
+		found, err := store.GetByID(ctx, sa.ID)
+		require.NoError(t, err)
+		_, ok := found.Scope(projectID)
+		assert.False(t, ok)
+	})
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	db, store := setupTestStore(t)
+	ctx := context.Background()
+
+	projectID, _ := createTestProject(t, db, "owner@example.com")
+	sa := &ServiceAccount{Name: "ci", CreatedBy: uuid.New()}
+	require.NoError(t, store.Create(ctx, sa))
+	require.NoError(t, store.Grant(ctx, &Grant{ServiceAccountID: sa.ID, ProjectID: projectID, Scope: apitoken.ScopeReadOnly}))
+
+	require.NoError(t, store.Delete(ctx, sa.ID))
+
+	_, err := store.GetByID(ctx, sa.ID)
+	assert.ErrorIs(t, err, ErrServiceAccountNotFound)
+
+	var grants int64
+	require.NoError(t, db.Model(&Grant{}).Where("service_account_id = ?", sa.ID).Count(&grants).Error)
+	assert.Zero(t, grants)
+
+	// The user is kept for what it started, but deactivated.
+	var u user.User
+	require.NoError(t, db.Where("id = ?", sa.ID).First(&u).Error)
+	assert.False(t, u.IsActive)
+
+	assert.ErrorIs(t, store.Delete(ctx, sa.ID), ErrServiceAccountNotFound)
+}
@@ -0,0 +1,114 @@
+// Package serviceaccount manages non-human accounts that own API tokens for
+// automation such as CI, so that it keeps working when the person who set
+// it up leaves. Each service account is backed by a user with the service
+// role, which owns its tokens and is recorded as starting its runs and
+// jobs, and can only act in the projects it is granted.
+package serviceaccount
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrServiceAccountNotFound is returned when a service account is not
+	// found.
+	ErrServiceAccountNotFound = errors.New("service account not found")
+
+	// ErrGrantNotFound is returned when a service account is not granted a
+	// project.
+	ErrGrantNotFound = errors.New("service account is not granted the project")
+
+	// ErrInvalidName is returned when a service account has no name.
+	ErrInvalidName = errors.New("service account name is required")
+
+	// ErrInvalidScope is returned when a grant's scope is not read_only or
+	// read_write.
+	ErrInvalidScope = errors.New("invalid scope: must be read_only or read_write")
+)
+
+// emailDomain is the domain of the emails of the users behind service
+// accounts. It is reserved, so no identity provider asserts it.
+const emailDomain = "service-accounts.invalid"
+
+// ServiceAccount is a non-human account. Its ID is the ID of the user
+// behind it.
+type ServiceAccount struct {
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	CreatedBy   uuid.UUID `json:"created_by" gorm:"type:char(36);not null"`
+	Grants      []Grant   `json:"grants" gorm:"foreignKey:ServiceAccountID"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name.
+func (ServiceAccount) TableName() string {
+	return "service_accounts"
+}
+
+// BeforeCreate hook to generate UUID before creating a new service account.
+func (sa *ServiceAccount) BeforeCreate(tx *gorm.DB) error {
+	if sa.ID == uuid.Nil {
+		sa.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the service account has valid required fields.
+func (sa *ServiceAccount) Validate() error {
+	if sa.Name == "" {
+		return ErrInvalidName
+	}
+	if sa.CreatedBy == uuid.Nil {
+		return errors.New("created_by is required")
+	}
+	return nil
+}
+
+// Email returns the email of the user behind the service account.
+func (sa *ServiceAccount) Email() string {
+	return "sa-" + sa.ID.String() + "@" + emailDomain
+}
+
+// Scope returns the scope the service account is granted in a project, and
+// whether it is granted the project at all.
+func (sa *ServiceAccount) Scope(projectID uuid.UUID) (string, bool) {
+	for _, g := range sa.Grants {
+		if g.ProjectID == projectID {
+			return g.Scope, true
+		}
+	}
+	return "", false
+}
+
+// Grant lets a service account act in a project: read_only grants allow
+// reading it, read_write grants also allow changing it.
+type Grant struct {
+	ServiceAccountID uuid.UUID `json:"-" gorm:"type:char(36);primaryKey"`
+	ProjectID        uuid.UUID `json:"project_id" gorm:"type:char(36);primaryKey"`
+	Scope            string    `json:"scope" gorm:"type:varchar(20);not null"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name.
+func (Grant) TableName() string {
+	return "service_account_grants"
+}
+
+// Validate checks if the grant has valid required fields.
+func (g *Grant) Validate() error {
+	if g.Scope != apitoken.ScopeReadOnly && g.Scope != apitoken.ScopeReadWrite {
+		return ErrInvalidScope
+	}
+	if g.ServiceAccountID == uuid.Nil || g.ProjectID == uuid.Nil {
+		return errors.New("service_account_id and project_id are required")
+	}
+	return nil
+}
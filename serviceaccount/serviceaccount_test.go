@@ -0,0 +1,42 @@
+package serviceaccount
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/apitoken"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceAccount_Scope(t *testing.T) {
+	granted := uuid.New()
+	sa := &ServiceAccount{
+		ID:     uuid.New(),
+		Grants: []Grant{{ProjectID: granted, Scope: apitoken.ScopeReadWrite}},
+	}
+
+	scope, ok := sa.Scope(granted)
+	assert.True(t, ok)
+	assert.Equal(t, apitoken.ScopeReadWrite, scope)
+
+	_, ok = sa.Scope(uuid.New())
+	assert.False(t, ok)
+}
+
+func TestServiceAccount_Validate(t *testing.T) {
+	assert.ErrorIs(t, (&ServiceAccount{CreatedBy: uuid.New()}).Validate(), ErrInvalidName)
+	assert.Error(t, (&ServiceAccount{Name: "ci"}).Validate())
+	assert.NoError(t, (&ServiceAccount{Name: "ci", CreatedBy: uuid.New()}).Validate())
+}
+
+func TestGrant_Validate(t *testing.T) {
+	grant := Grant{ServiceAccountID: uuid.New(), ProjectID: uuid.New(), Scope: apitoken.ScopeReadOnly}
+	assert.NoError(t, grant.Validate())
+
+	grant.Scope = "admin"
+	assert.ErrorIs(t, grant.Validate(), ErrInvalidScope)
+
+	grant.Scope = apitoken.ScopeReadWrite
+	grant.ProjectID = uuid.Nil
+	assert.Error(t, grant.Validate())
+}
@@ -0,0 +1,31 @@
+package serviceaccount
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for service account persistence operations.
+type Store interface {
+	// Create creates a new service account and the user behind it.
+	Create(ctx context.Context, sa *ServiceAccount) error
+
+	// GetByID retrieves a service account with its grants.
+	GetByID(ctx context.Context, id uuid.UUID) (*ServiceAccount, error)
+
+	// List retrieves every service account with its grants, ordered by
+	// name.
+	List(ctx context.Context) ([]*ServiceAccount, error)
+
+	// Grant grants a service account a project, or changes the scope it is
+	// granted.
+	Grant(ctx context.Context, grant *Grant) error
+
+	// Ungrant revokes a service account's grant of a project.
+	Ungrant(ctx context.Context, id, projectID uuid.UUID) error
+
+	// Delete deletes a service account and its grants, and deactivates the
+	// user behind it, which is kept for the runs and jobs it started.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,44 @@
+package session
+
+import "strings"
+
+// Device describes the browser and operating system of a user agent, such
+// as "Chrome on macOS", for telling sessions apart.
+func Device(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	case strings.HasPrefix(userAgent, "curl/"):
+		return "curl"
+	}
+
+	// Mobile systems first: their user agents also name desktop ones.
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		return browser + " on iOS"
+	case strings.Contains(userAgent, "Android"):
+		return browser + " on Android"
+	case strings.Contains(userAgent, "Windows"):
+		return browser + " on Windows"
+	case strings.Contains(userAgent, "Mac OS X"), strings.Contains(userAgent, "Macintosh"):
+		return browser + " on macOS"
+	case strings.Contains(userAgent, "CrOS"):
+		return browser + " on ChromeOS"
+	case strings.Contains(userAgent, "Linux"):
+		return browser + " on Linux"
+	}
+	return browser
+}
@@ -0,0 +1,62 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevice(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{
+			name:      "empty",
+			userAgent: "",
+			want:      "Unknown device",
+		},
+		{
+			name:      "chrome on macos",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want:      "Chrome on macOS",
+		},
+		{
+			name:      "edge on windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.0.0",
+			want:      "Edge on Windows",
+		},
+		{
+			name:      "firefox on linux",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			want:      "Firefox on Linux",
+		},
+		{
+			name:      "safari on iphone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			want:      "Safari on iOS",
+		},
+		{
+			name:      "chrome on android",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+			want:      "Chrome on Android",
+		},
+		{
+			name:      "curl",
+			userAgent: "curl/8.4.0",
+			want:      "curl",
+		},
+		{
+			name:      "unknown client",
+			userAgent: "custom-client/1.0",
+			want:      "Unknown browser",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Device(tt.userAgent))
+		})
+	}
+}
@@ -32,11 +32,13 @@ func (m *Manager) Create(userID uuid.UUID, email string) (*Session, error) {
 
 	now := time.Now()
 	session := &Session{
-		ID:        sessionID,
-		UserID:    userID,
-		Email:     email,
-		CreatedAt: now,
-		ExpiresAt: now.Add(m.duration),
+		ID:         sessionID,
+		PublicID:   uuid.New(),
+		UserID:     userID,
+		Email:      email,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(m.duration),
+		LastSeenAt: now,
 	}
 
 	m.store.Set(session)
@@ -63,6 +65,51 @@ func (m *Manager) Delete(sessionID uuid.UUID) {
 	})
 }
 
+// Touch records the client of a request made with a session.
+func (m *Manager) Touch(sessionID uuid.UUID, ip, userAgent string) {
+	m.store.Touch(sessionID, ip, userAgent, time.Now())
+}
+
+// ListByUser returns the active sessions of a user, most recently seen
+// first.
+func (m *Manager) ListByUser(userID uuid.UUID) []*Session {
+	return m.store.ListByUser(userID)
+}
+
+// Revoke deletes the session of a user with the given public ID. Returns
+// ErrSessionNotFound if the user has no such session.
+func (m *Manager) Revoke(userID, publicID uuid.UUID) error {
+	if err := m.store.DeleteByPublicID(userID, publicID); err != nil {
+		return err
+	}
+	m.logger.Info(context.Background(), "session revoked", map[string]interface{}{
+		"user_id":   userID.String(),
+		"public_id": publicID.String(),
+	})
+	return nil
+}
+
+// RevokeOthers deletes every session of a user except keepID, logging them
+// out everywhere else. Returns how many sessions were deleted.
+func (m *Manager) RevokeOthers(userID, keepID uuid.UUID) int {
+	removed := m.store.DeleteOthers(userID, keepID)
+	m.logger.Info(context.Background(), "other sessions revoked", map[string]interface{}{
+		"user_id":       userID.String(),
+		"removed_count": removed,
+	})
+	return removed
+}
+
+// DeleteByUser deletes every session of a user, logging them out
+// everywhere.
+func (m *Manager) DeleteByUser(userID uuid.UUID) {
+	removed := m.store.DeleteByUser(userID)
+	m.logger.Info(context.Background(), "user sessions deleted", map[string]interface{}{
+		"user_id":       userID.String(),
+		"removed_count": removed,
+	})
+}
+
 // StartCleanup starts a background goroutine that periodically cleans up expired sessions.
 func (m *Manager) StartCleanup(interval time.Duration) {
 	ticker := time.NewTicker(interval)
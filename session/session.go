@@ -2,6 +2,7 @@ package session
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,13 +17,20 @@ var (
 	ErrSessionExpired = errors.New("session expired")
 )
 
-// Session represents a user session.
+// Session represents a user session. ID is the session cookie, so it is
+// never shown; sessions are listed and revoked by PublicID instead.
 type Session struct {
 	ID        uuid.UUID
+	PublicID  uuid.UUID
 	UserID    uuid.UUID
 	Email     string
 	CreatedAt time.Time
 	ExpiresAt time.Time
+	// IP, UserAgent and LastSeenAt describe the last request made with the
+	// session.
+	IP         string
+	UserAgent  string
+	LastSeenAt time.Time
 }
 
 // IsExpired checks if the session has expired.
@@ -67,6 +75,42 @@ func (s *Store) Get(sessionID uuid.UUID) (*Session, error) {
 	return session, nil
 }
 
+// Touch records a request made with a session. Sessions are replaced, not
+// changed in place, so sessions returned earlier are safe to read.
+func (s *Store) Touch(sessionID uuid.UUID, ip, userAgent string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return
+	}
+	touched := *session
+	touched.IP = ip
+	touched.UserAgent = userAgent
+	touched.LastSeenAt = at
+	s.sessions[sessionID] = &touched
+}
+
+// ListByUser returns the unexpired sessions of a user, most recently seen
+// first.
+func (s *Store) ListByUser(userID uuid.UUID) []*Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var sessions []*Session
+	for _, session := range s.sessions {
+		if session.UserID == userID && !session.IsExpired() {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions
+}
+
 // Delete removes a session from the store.
 func (s *Store) Delete(sessionID uuid.UUID) {
 	s.mu.Lock()
@@ -74,6 +118,47 @@ func (s *Store) Delete(sessionID uuid.UUID) {
 	delete(s.sessions, sessionID)
 }
 
+// DeleteByUser removes every session of a user, returning how many were
+// removed.
+func (s *Store) DeleteByUser(userID uuid.UUID) int {
+	return s.deleteWhere(func(session *Session) bool {
+		return session.UserID == userID
+	})
+}
+
+// DeleteByPublicID removes the session of a user with the given public ID.
+func (s *Store) DeleteByPublicID(userID, publicID uuid.UUID) error {
+	if s.deleteWhere(func(session *Session) bool {
+		return session.UserID == userID && session.PublicID == publicID
+	}) == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// DeleteOthers removes every session of a user except keepID, returning how
+// many were removed.
+func (s *Store) DeleteOthers(userID, keepID uuid.UUID) int {
+	return s.deleteWhere(func(session *Session) bool {
+		return session.UserID == userID && session.ID != keepID
+	})
+}
+
+func (s *Store) deleteWhere(match func(*Session) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for id, session := range s.sessions {
+		if match(session) {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+
+	return removed
+}
+
 // Cleanup removes expired sessions from the store.
 func (s *Store) Cleanup() int {
 	s.mu.Lock()
@@ -208,6 +208,106 @@ func TestManager_Delete(t *testing.T) {
 	assert.ErrorIs(t, err, ErrSessionNotFound)
 }
 
+func TestManager_DeleteByUser(t *testing.T) {
+	log := logger.NewTestLogger()
+	manager := NewManager(24*time.Hour, log)
+
+	userID := uuid.New()
+	first, err := manager.Create(userID, "test@example.com")
+	require.NoError(t, err)
+	second, err := manager.Create(userID, "test@example.com")
+	require.NoError(t, err)
+	other, err := manager.Create(uuid.New(), "other@example.com")
+	require.NoError(t, err)
+
+	manager.DeleteByUser(userID)
+
+	_, err = manager.Get(first.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = manager.Get(second.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = manager.Get(other.ID)
+	assert.NoError(t, err)
+}
+
+func TestManager_TouchAndListByUser(t *testing.T) {
+	log := logger.NewTestLogger()
+	manager := NewManager(24*time.Hour, log)
+
+	userID := uuid.New()
+	older, err := manager.Create(userID, "test@example.com")
+	require.NoError(t, err)
+	newer, err := manager.Create(userID, "test@example.com")
+	require.NoError(t, err)
+	_, err = manager.Create(uuid.New(), "other@example.com")
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	manager.Touch(newer.ID, "203.0.113.7", "curl/8.0")
+
+	sessions := manager.ListByUser(userID)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, newer.ID, sessions[0].ID)
+	assert.Equal(t, "203.0.113.7", sessions[0].IP)
+	assert.Equal(t, "curl/8.0", sessions[0].UserAgent)
+	assert.True(t, sessions[0].LastSeenAt.After(newer.LastSeenAt))
+	assert.Equal(t, older.ID, sessions[1].ID)
+
+	// Sessions returned before a touch are not changed by it
+	assert.Empty(t, newer.IP)
+}
+
+func TestManager_Revoke(t *testing.T) {
+	log := logger.NewTestLogger()
+	manager := NewManager(24*time.Hour, log)
+
+	userID := uuid.New()
+	created, err := manager.Create(userID, "test@example.com")
+	require.NoError(t, err)
+
+	t.Run("another user's session is not found", func(t *testing.T) {
+		err := manager.Revoke(uuid.New(), created.PublicID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+
+		_, err = manager.Get(created.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("revokes by public ID", func(t *testing.T) {
+		require.NoError(t, manager.Revoke(userID, created.PublicID))
+
+		_, err := manager.Get(created.ID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("revoked session is not found", func(t *testing.T) {
+		err := manager.Revoke(userID, created.PublicID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func TestManager_RevokeOthers(t *testing.T) {
+	log := logger.NewTestLogger()
+	manager := NewManager(24*time.Hour, log)
+
+	userID := uuid.New()
+	current, err := manager.Create(userID, "test@example.com")
+	require.NoError(t, err)
+	other, err := manager.Create(userID, "test@example.com")
+	require.NoError(t, err)
+	otherUser, err := manager.Create(uuid.New(), "other@example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, manager.RevokeOthers(userID, current.ID))
+
+	_, err = manager.Get(current.ID)
+	assert.NoError(t, err)
+	_, err = manager.Get(other.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = manager.Get(otherUser.ID)
+	assert.NoError(t, err)
+}
+
 func TestManager_Cleanup(t *testing.T) {
 	log := logger.NewTestLogger()
 	manager := NewManager(50*time.Millisecond, log)
@@ -0,0 +1,33 @@
+package share
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestStore creates a test database and share link store for testing.
+func setupTestStore(t *testing.T) *MySQLStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Link{})
+
+	return NewMySQLStore(db, logger.NewTestLogger())
+}
+
+// newTestLink creates a valid summary link of runID and returns it with its
+// raw token.
+func newTestLink(t *testing.T, runID uuid.UUID) (*Link, string) {
+	raw, hash, err := GenerateToken()
+	require.NoError(t, err)
+	return &Link{
+		TestRunID: runID,
+		Kind:      KindSummary,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(DefaultExpiry),
+		CreatedBy: uuid.New(),
+	}, raw
+}
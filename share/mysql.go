@@ -0,0 +1,159 @@
+package share
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed share link store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new share link.
+func (s *MySQLStore) Create(ctx context.Context, link *Link) error {
+	if err := link.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(link).Error; err != nil {
+		s.logger.Error(ctx, "failed to create share link", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": link.TestRunID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "share link created", map[string]interface{}{
+		"share_id":    link.ID.String(),
+		"test_run_id": link.TestRunID.String(),
+		"kind":        link.Kind,
+		"expires_at":  link.ExpiresAt,
+	})
+
+	return nil
+}
+
+// GetByID retrieves a share link by ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*Link, error) {
+	var link Link
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&link).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		s.logger.Error(ctx, "failed to get share link", map[string]interface{}{
+			"error":    err.Error(),
+			"share_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// GetByTokenHash retrieves an active share link by the hash of its token.
+func (s *MySQLStore) GetByTokenHash(ctx context.Context, hash string) (*Link, error) {
+	var link Link
+	err := s.db.WithContext(ctx).
+		Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", hash, time.Now()).
+		First(&link).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		s.logger.Error(ctx, "failed to get share link by token", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// ListByRun lists the share links of a test run, newest first.
+func (s *MySQLStore) ListByRun(ctx context.Context, testRunID uuid.UUID) ([]*Link, error) {
+	var links []*Link
+	err := s.db.WithContext(ctx).
+		Where("test_run_id = ?", testRunID).
+		Order("created_at DESC").
+		Find(&links).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list share links", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": testRunID.String(),
+		})
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// Revoke revokes a share link. Links are kept after they are revoked so
+// their view counts remain visible.
+func (s *MySQLStore) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result := s.db.WithContext(ctx).
+		Model(&Link{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to revoke share link", map[string]interface{}{
+			"error":    result.Error.Error(),
+			"share_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		if _, err := s.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrAlreadyRevoked
+	}
+
+	s.logger.Info(ctx, "share link revoked", map[string]interface{}{
+		"share_id": id.String(),
+	})
+
+	return nil
+}
+
+// RecordView counts a view of a share link.
+func (s *MySQLStore) RecordView(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := s.db.WithContext(ctx).
+		Model(&Link{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + ?", 1),
+			"last_viewed_at": at,
+		}).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to record share link view", map[string]interface{}{
+			"error":    err.Error(),
+			"share_id": id.String(),
+		})
+		return err
+	}
+
+	return nil
+}
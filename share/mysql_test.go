@@ -0,0 +1,76 @@
+package share
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+	runID := uuid.New()
+
+	link, raw := newTestLink(t, runID)
+	require.NoError(t, store.Create(ctx, link))
+
+	expired, expiredRaw := newTestLink(t, runID)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, store.Create(ctx, expired))
+
+	other, _ := newTestLink(t, uuid.New())
+	require.NoError(t, store.Create(ctx, other))
+
+	t.Run("invalid link", func(t *testing.T) {
+		invalid, _ := newTestLink(t, runID)
+		invalid.Kind = ""
+		assert.ErrorIs(t, store.Create(ctx, invalid), ErrInvalidKind)
+	})
+
+	t.Run("get by token", func(t *testing.T) {
+		retrieved, err := store.GetByTokenHash(ctx, HashToken(raw))
+		require.NoError(t, err)
+		assert.Equal(t, link.ID, retrieved.ID)
+
+		_, err = store.GetByTokenHash(ctx, HashToken(expiredRaw))
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+
+		_, err = store.GetByTokenHash(ctx, HashToken("shr_unknown"))
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+	})
+
+	t.Run("list by run", func(t *testing.T) {
+		links, err := store.ListByRun(ctx, runID)
+		require.NoError(t, err)
+		assert.Len(t, links, 2)
+	})
+
+	t.Run("record view", func(t *testing.T) {
+		require.NoError(t, store.RecordView(ctx, link.ID, time.Now()))
+		require.NoError(t, store.RecordView(ctx, link.ID, time.Now()))
+
+		retrieved, err := store.GetByID(ctx, link.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), retrieved.ViewCount)
+		assert.NotNil(t, retrieved.LastViewedAt)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		require.NoError(t, store.Revoke(ctx, link.ID, time.Now()))
+
+		_, err := store.GetByTokenHash(ctx, HashToken(raw))
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+
+		retrieved, err := store.GetByID(ctx, link.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, retrieved.RevokedAt)
+		assert.Equal(t, int64(2), retrieved.ViewCount)
+
+		assert.ErrorIs(t, store.Revoke(ctx, link.ID, time.Now()), ErrAlreadyRevoked)
+		assert.ErrorIs(t, store.Revoke(ctx, uuid.New(), time.Now()), ErrLinkNotFound)
+	})
+}
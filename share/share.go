@@ -0,0 +1,140 @@
+package share
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrLinkNotFound is returned when a share link is not found, or is
+	// looked up by a token that has expired or been revoked.
+	ErrLinkNotFound = errors.New("share link not found")
+
+	// ErrInvalidTestRunID is returned when test_run_id is not set.
+	ErrInvalidTestRunID = errors.New("test_run_id is required")
+
+	// ErrInvalidKind is returned when kind is not guide or summary.
+	ErrInvalidKind = errors.New("kind must be guide or summary")
+
+	// ErrAlreadyRevoked is returned when a revoked share link is revoked
+	// again.
+	ErrAlreadyRevoked = errors.New("share link already revoked")
+)
+
+const (
+	// TokenPrefix starts every share token, telling them apart from API
+	// tokens.
+	TokenPrefix = "shr_"
+
+	DefaultExpiry = 7 * 24 * time.Hour  // 1 week
+	MinExpiry     = time.Hour           // 1 hour
+	MaxExpiry     = 90 * 24 * time.Hour // 90 days
+)
+
+// Kind is what a share link gives access to.
+type Kind string
+
+const (
+	// KindGuide shares the run's guide, the ZIP of guide.md and the run's
+	// assets, along with its summary.
+	KindGuide Kind = "guide"
+
+	// KindSummary shares the run's outcome, step notes and assets.
+	KindSummary Kind = "summary"
+)
+
+// IsValid checks if the kind is supported.
+func (k Kind) IsValid() bool {
+	return k == KindGuide || k == KindSummary
+}
+
+// Link is an expiring, revocable URL that shows a test run to anyone who
+// has it, without logging in. Only the hash of its token is stored; the
+// token itself is shown once, when the link is created.
+type Link struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	TestRunID uuid.UUID  `json:"test_run_id" gorm:"type:char(36);not null;index:idx_share_links_test_run_id"`
+	Kind      Kind       `json:"kind" gorm:"type:varchar(20);not null"`
+	TokenHash string     `json:"-" gorm:"type:char(64);not null;uniqueIndex:idx_share_links_token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	// ViewCount and LastViewedAt track how often the link was opened.
+	ViewCount    int64      `json:"view_count" gorm:"not null;default:0"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	CreatedBy    uuid.UUID  `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (l *Link) TableName() string {
+	return "share_links"
+}
+
+// BeforeCreate hook to generate UUID before creating a new share link
+func (l *Link) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the link has valid required fields.
+func (l *Link) Validate() error {
+	if l.TestRunID == uuid.Nil {
+		return ErrInvalidTestRunID
+	}
+	if !l.Kind.IsValid() {
+		return ErrInvalidKind
+	}
+	if l.TokenHash == "" {
+		return errors.New("token_hash is required")
+	}
+	return nil
+}
+
+// IsActive reports whether the link can be opened at the given time: it has
+// neither expired nor been revoked.
+func (l *Link) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// GenerateToken creates a new random token with the shr_ prefix.
+// Returns the raw token string and its SHA-256 hash.
+func GenerateToken() (rawToken string, hash string, err error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	rawToken = TokenPrefix + base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(bytes)
+	return rawToken, HashToken(rawToken), nil
+}
+
+// HashToken returns the SHA-256 hex digest of a raw token.
+func HashToken(raw string) string {
+	h := sha256.Sum256([]byte(raw))
+	return fmt.Sprintf("%x", h)
+}
+
+// ValidateExpiry normalizes how long a link stays valid.
+// If duration is 0, returns the default (1 week).
+// Clamps to min 1 hour and max 90 days.
+func ValidateExpiry(d time.Duration) time.Duration {
+	if d == 0 {
+		return DefaultExpiry
+	}
+	if d < MinExpiry {
+		return MinExpiry
+	}
+	if d > MaxExpiry {
+		return MaxExpiry
+	}
+	return d
+}
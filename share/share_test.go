@@ -0,0 +1,63 @@
+package share
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateToken(t *testing.T) {
+	raw, hash, err := GenerateToken()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, TokenPrefix))
+	assert.Equal(t, HashToken(raw), hash)
+	assert.Len(t, hash, 64)
+
+	other, _, err := GenerateToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, raw, other)
+}
+
+func TestValidateExpiry(t *testing.T) {
+	tests := []struct {
+		name string
+		in   time.Duration
+		want time.Duration
+	}{
+		{name: "default", in: 0, want: DefaultExpiry},
+		{name: "too short", in: time.Minute, want: MinExpiry},
+		{name: "too long", in: 365 * 24 * time.Hour, want: MaxExpiry},
+		{name: "within range", in: 48 * time.Hour, want: 48 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidateExpiry(tt.in))
+		})
+	}
+}
+
+func TestLink_Validate(t *testing.T) {
+	link, _ := newTestLink(t, uuid.New())
+	assert.NoError(t, link.Validate())
+
+	link.Kind = "report"
+	assert.ErrorIs(t, link.Validate(), ErrInvalidKind)
+
+	link.Kind = KindGuide
+	link.TestRunID = uuid.Nil
+	assert.ErrorIs(t, link.Validate(), ErrInvalidTestRunID)
+}
+
+func TestLink_IsActive(t *testing.T) {
+	now := time.Now()
+	link := &Link{ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, link.IsActive(now))
+	assert.False(t, link.IsActive(now.Add(2*time.Hour)))
+
+	link.RevokedAt = &now
+	assert.False(t, link.IsActive(now))
+}
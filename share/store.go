@@ -0,0 +1,30 @@
+package share
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for share link persistence operations.
+type Store interface {
+	// Create creates a new share link.
+	Create(ctx context.Context, link *Link) error
+
+	// GetByID retrieves a share link by ID, active or not.
+	GetByID(ctx context.Context, id uuid.UUID) (*Link, error)
+
+	// GetByTokenHash retrieves an active share link by the hash of its
+	// token. Expired and revoked links are not found.
+	GetByTokenHash(ctx context.Context, hash string) (*Link, error)
+
+	// ListByRun lists the share links of a test run, newest first.
+	ListByRun(ctx context.Context, testRunID uuid.UUID) ([]*Link, error)
+
+	// Revoke revokes a share link so its token no longer opens it.
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+
+	// RecordView counts a view of a share link.
+	RecordView(ctx context.Context, id uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,95 @@
+package share
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Summary is what a share link shows of a test run: its outcome, the
+// procedure's steps with the notes taken on them and the uploaded assets.
+// Who ran it and the URL it ran against are left out.
+type Summary struct {
+	Kind            Kind           `json:"kind"`
+	ExpiresAt       time.Time      `json:"expires_at"`
+	ProcedureName   string         `json:"procedure_name"`
+	Description     string         `json:"description,omitempty"`
+	Version         uint           `json:"version"`
+	Status          testrun.Status `json:"status"`
+	Notes           string         `json:"notes,omitempty"`
+	FailedStepIndex *int           `json:"failed_step_index,omitempty"`
+	Environment     string         `json:"environment,omitempty"`
+	StartedAt       *time.Time     `json:"started_at,omitempty"`
+	CompletedAt     *time.Time     `json:"completed_at,omitempty"`
+	Steps           []SummaryStep  `json:"steps"`
+	Assets          []SummaryAsset `json:"assets"`
+}
+
+// SummaryStep is a step of the shared run's procedure.
+type SummaryStep struct {
+	Index          int                `json:"index"`
+	Name           string             `json:"name"`
+	Instructions   string             `json:"instructions,omitempty"`
+	ExpectedResult string             `json:"expected_result,omitempty"`
+	Status         testrun.StepStatus `json:"status,omitempty"`
+	Notes          string             `json:"notes,omitempty"`
+}
+
+// SummaryAsset is an asset uploaded to the shared run.
+type SummaryAsset struct {
+	ID          uuid.UUID         `json:"id"`
+	AssetType   testrun.AssetType `json:"asset_type"`
+	FileName    string            `json:"file_name"`
+	FileSize    int64             `json:"file_size"`
+	MimeType    string            `json:"mime_type,omitempty"`
+	Description string            `json:"description,omitempty"`
+	StepIndex   *int              `json:"step_index,omitempty"`
+}
+
+// NewSummary builds the summary of a run shown through link.
+func NewSummary(link *Link, run *testrun.ReportedRun, assets []*testrun.TestRunAsset) *Summary {
+	summary := &Summary{
+		Kind:            link.Kind,
+		ExpiresAt:       link.ExpiresAt,
+		ProcedureName:   run.Procedure.Name,
+		Description:     run.Procedure.Description,
+		Version:         run.Procedure.Version,
+		Status:          run.Run.Status,
+		Notes:           run.Run.Notes,
+		FailedStepIndex: run.Run.FailedStepIndex,
+		Environment:     run.Run.Environment,
+		StartedAt:       run.Run.StartedAt,
+		CompletedAt:     run.Run.CompletedAt,
+		Steps:           make([]SummaryStep, len(run.Procedure.Steps)),
+		Assets:          []SummaryAsset{},
+	}
+
+	for i, step := range run.Procedure.Steps {
+		summary.Steps[i] = SummaryStep{Index: i, Name: step.Name, Instructions: step.Instructions, ExpectedResult: step.ExpectedResult}
+	}
+	for _, note := range run.StepNotes {
+		if note.StepIndex < 0 || note.StepIndex >= len(summary.Steps) {
+			continue
+		}
+		summary.Steps[note.StepIndex].Status = note.Status
+		summary.Steps[note.StepIndex].Notes = note.Notes
+	}
+
+	for _, asset := range assets {
+		// Thumbnails and transcodes duplicate the asset they come from.
+		if asset.SourceAssetID != nil {
+			continue
+		}
+		summary.Assets = append(summary.Assets, SummaryAsset{
+			ID:          asset.ID,
+			AssetType:   asset.AssetType,
+			FileName:    asset.FileName,
+			FileSize:    asset.FileSize,
+			MimeType:    asset.MimeType,
+			Description: asset.Description,
+			StepIndex:   asset.StepIndex,
+		})
+	}
+	return summary
+}
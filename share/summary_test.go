@@ -0,0 +1,60 @@
+package share
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSummary(t *testing.T) {
+	failedStep := 1
+	run := &testrun.ReportedRun{
+		Run: &testrun.TestRun{
+			ID:              uuid.New(),
+			Status:          testrun.StatusFailed,
+			Notes:           "Checkout broke",
+			FailedStepIndex: &failedStep,
+			Environment:     "staging",
+			BaseURL:         "https://staging.internal.example.com",
+		},
+		Procedure: &testprocedure.TestProcedure{
+			Name:    "Checkout",
+			Version: 3,
+			Steps: testprocedure.Steps{
+				{Name: "Add to cart", Instructions: "Add an item"},
+				{Name: "Pay", Instructions: "Pay by card", ExpectedResult: "The order is confirmed"},
+			},
+		},
+		StepNotes: []*testrun.StepNote{
+			{StepIndex: 1, Notes: "Card declined", Status: testrun.StepStatusFailed},
+			{StepIndex: 5, Notes: "Step removed since"},
+		},
+	}
+	screenshot := &testrun.TestRunAsset{ID: uuid.New(), AssetType: testrun.AssetTypeImage, FileName: "pay.png", StepIndex: &failedStep}
+	thumbnail := &testrun.TestRunAsset{ID: uuid.New(), AssetType: testrun.AssetTypeImage, FileName: "pay-thumb.png", SourceAssetID: &screenshot.ID}
+	link := &Link{Kind: KindSummary, ExpiresAt: time.Now().Add(time.Hour)}
+
+	summary := NewSummary(link, run, []*testrun.TestRunAsset{screenshot, thumbnail})
+
+	assert.Equal(t, KindSummary, summary.Kind)
+	assert.Equal(t, "Checkout", summary.ProcedureName)
+	assert.Equal(t, uint(3), summary.Version)
+	assert.Equal(t, testrun.StatusFailed, summary.Status)
+	assert.Equal(t, &failedStep, summary.FailedStepIndex)
+	assert.Equal(t, "staging", summary.Environment)
+
+	require.Len(t, summary.Steps, 2)
+	assert.Equal(t, "Add to cart", summary.Steps[0].Name)
+	assert.Empty(t, summary.Steps[0].Notes)
+	assert.Equal(t, "Card declined", summary.Steps[1].Notes)
+	assert.Equal(t, "The order is confirmed", summary.Steps[1].ExpectedResult)
+	assert.Equal(t, testrun.StepStatusFailed, summary.Steps[1].Status)
+
+	require.Len(t, summary.Assets, 1)
+	assert.Equal(t, screenshot.ID, summary.Assets[0].ID)
+}
@@ -0,0 +1,134 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrShuttingDown is returned when work is started after draining began.
+	ErrShuttingDown = errors.New("server is shutting down")
+	// ErrInterrupted is the cancellation cause of work that was still running
+	// when the drain timeout ran out.
+	ErrInterrupted = errors.New("interrupted by server shutdown")
+)
+
+// DefaultInterruptGrace is how long interrupted work is given to record its
+// state after its context is canceled.
+const DefaultInterruptGrace = 10 * time.Second
+
+// Coordinator tracks in-flight background work, such as jobs and script
+// generations, so that the server can let it finish before exiting. Once
+// Drain is called no new work is accepted; work still running when the
+// drain deadline passes has its context canceled with ErrInterrupted.
+type Coordinator struct {
+	interruptGrace time.Duration
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu       sync.Mutex
+	draining chan struct{}
+	inFlight sync.WaitGroup
+}
+
+// NewCoordinator creates a new shutdown coordinator.
+func NewCoordinator() *Coordinator {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &Coordinator{
+		interruptGrace: DefaultInterruptGrace,
+		ctx:            ctx,
+		cancel:         cancel,
+		draining:       make(chan struct{}),
+	}
+}
+
+// Begin registers a unit of work. The returned context is derived from ctx
+// and is also canceled with ErrInterrupted if the work outlives the drain
+// timeout. done must be called when the work has finished. Begin returns
+// ErrShuttingDown once draining has started.
+func (c *Coordinator) Begin(ctx context.Context) (context.Context, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Draining() {
+		return nil, nil, ErrShuttingDown
+	}
+	c.inFlight.Add(1)
+
+	workCtx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(c.ctx, func() {
+		cancel(context.Cause(c.ctx))
+	})
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			stop()
+			cancel(nil)
+			c.inFlight.Done()
+		})
+	}
+	return workCtx, done, nil
+}
+
+// Go runs fn in a new goroutine as a unit of work. It returns
+// ErrShuttingDown, without running fn, once draining has started.
+func (c *Coordinator) Go(fn func(ctx context.Context)) error {
+	ctx, done, err := c.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer done()
+		fn(ctx)
+	}()
+	return nil
+}
+
+// Draining reports whether Drain has been called.
+func (c *Coordinator) Draining() bool {
+	select {
+	case <-c.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// DrainStarted returns a channel that is closed when Drain is called.
+func (c *Coordinator) DrainStarted() <-chan struct{} {
+	return c.draining
+}
+
+// Drain stops new work from being accepted and waits for the work in flight
+// to finish. When ctx is done first, the remaining work is interrupted and
+// given a short grace period to record its state, and ctx's error is
+// returned.
+func (c *Coordinator) Drain(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.Draining() {
+		close(c.draining)
+	}
+	c.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		c.inFlight.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	c.cancel(ErrInterrupted)
+	select {
+	case <-finished:
+	case <-time.After(c.interruptGrace):
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,116 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinator_DrainWaitsForWork(t *testing.T) {
+	c := NewCoordinator()
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	require.NoError(t, c.Go(func(ctx context.Context) {
+		<-release
+		close(finished)
+	}))
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- c.Drain(context.Background())
+	}()
+
+	select {
+	case <-drained:
+		t.Fatal("drain returned while work was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-drained:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("drain did not return after work finished")
+	}
+	<-finished
+}
+
+func TestCoordinator_RejectsWorkWhileDraining(t *testing.T) {
+	c := NewCoordinator()
+	assert.False(t, c.Draining())
+
+	require.NoError(t, c.Drain(context.Background()))
+	assert.True(t, c.Draining())
+
+	_, _, err := c.Begin(context.Background())
+	assert.ErrorIs(t, err, ErrShuttingDown)
+
+	err = c.Go(func(ctx context.Context) {
+		t.Error("work ran after draining started")
+	})
+	assert.ErrorIs(t, err, ErrShuttingDown)
+
+	select {
+	case <-c.DrainStarted():
+	default:
+		t.Fatal("drain started channel not closed")
+	}
+
+	// Draining again is a no-op.
+	assert.NoError(t, c.Drain(context.Background()))
+}
+
+func TestCoordinator_InterruptsWorkAfterTimeout(t *testing.T) {
+	c := NewCoordinator()
+	c.interruptGrace = time.Second
+
+	cause := make(chan error, 1)
+	require.NoError(t, c.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.Drain(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, <-cause, ErrInterrupted)
+}
+
+func TestCoordinator_GraceIsBounded(t *testing.T) {
+	c := NewCoordinator()
+	c.interruptGrace = 20 * time.Millisecond
+
+	// Work that ignores cancellation does not hold up the drain forever.
+	_, done, err := c.Begin(context.Background())
+	require.NoError(t, err)
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Error(t, c.Drain(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestCoordinator_BeginKeepsParentContext(t *testing.T) {
+	c := NewCoordinator()
+
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, done, err := c.Begin(parent)
+	require.NoError(t, err)
+	defer done()
+
+	cancel()
+	<-ctx.Done()
+	assert.ErrorIs(t, context.Cause(ctx), context.Canceled)
+
+	// A finished unit of work no longer holds up draining.
+	done()
+	assert.NoError(t, c.Drain(context.Background()))
+}
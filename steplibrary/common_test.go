@@ -0,0 +1,41 @@
+package steplibrary
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and step group store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &StepGroup{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// createTestGroup creates a step group with the given step names.
+func createTestGroup(name string, projectID uuid.UUID, stepNames ...string) *StepGroup {
+	steps := make(testprocedure.Steps, len(stepNames))
+	for i, stepName := range stepNames {
+		steps[i] = testprocedure.TestStep{Name: stepName, Instructions: stepName + " instructions"}
+	}
+	return &StepGroup{
+		Name:      name,
+		ProjectID: projectID,
+		Steps:     steps,
+		CreatedBy: uuid.New(),
+	}
+}
+
+// reference returns a step referencing a group at a revision.
+func reference(groupID uuid.UUID, revision uint) testprocedure.TestStep {
+	return testprocedure.TestStep{GroupID: &groupID, GroupRevision: revision}
+}
@@ -0,0 +1,145 @@
+package steplibrary
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// groupIDs returns the IDs of the step groups steps reference, in order of
+// first use.
+func groupIDs(steps testprocedure.Steps) []uuid.UUID {
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, step := range steps {
+		if step.IsGroupReference() && !seen[*step.GroupID] {
+			seen[*step.GroupID] = true
+			ids = append(ids, *step.GroupID)
+		}
+	}
+	return ids
+}
+
+// Pin checks the references in the steps of a procedure in a project before
+// they are saved and returns the steps to save. Every reference must name a
+// group of the project; references without a name are named after their
+// group, and new references are pinned to the group's current revision.
+// Returns ErrStepGroupNotFound or ErrInvalidReference, with the step's
+// position, otherwise.
+func Pin(ctx context.Context, store Store, projectID uuid.UUID, steps testprocedure.Steps) (testprocedure.Steps, error) {
+	ids := groupIDs(steps)
+	if len(ids) == 0 {
+		return steps, nil
+	}
+	groups, err := store.GetByIDs(ctx, projectID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make(testprocedure.Steps, len(steps))
+	for i, step := range steps {
+		if step.IsGroupReference() {
+			group, ok := groups[*step.GroupID]
+			if !ok {
+				return nil, fmt.Errorf("step %d: %w", i+1, ErrStepGroupNotFound)
+			}
+			if step.GroupRevision > group.Revision {
+				return nil, fmt.Errorf("step %d: %w: group is at revision %d", i+1, ErrInvalidReference, group.Revision)
+			}
+			if step.Name == "" {
+				step.Name = group.Name
+			}
+			if step.GroupRevision == 0 {
+				step.GroupRevision = group.Revision
+			}
+		}
+		pinned[i] = step
+	}
+	return pinned, nil
+}
+
+// Expand returns a copy of tp with each reference replaced by the current
+// steps of its group, or tp itself if it references none. Branches of
+// conditional steps are resized to cover the expanded steps. Returns
+// ErrStepGroupNotFound, with the step's position, if a group has been
+// deleted.
+func Expand(ctx context.Context, store Store, tp *testprocedure.TestProcedure) (*testprocedure.TestProcedure, error) {
+	ids := groupIDs(tp.Steps)
+	if len(ids) == 0 {
+		return tp, nil
+	}
+	groups, err := store.GetByIDs(ctx, tp.ProjectID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, step := range tp.Steps {
+		if step.IsGroupReference() {
+			if _, ok := groups[*step.GroupID]; !ok {
+				return nil, fmt.Errorf("step %d: %w", i+1, ErrStepGroupNotFound)
+			}
+		}
+	}
+
+	expanded := *tp
+	expanded.Steps = tp.Steps.Regroup(func(step testprocedure.TestStep) testprocedure.Steps {
+		if !step.IsGroupReference() {
+			return testprocedure.Steps{step}
+		}
+		groupSteps := make(testprocedure.Steps, len(groups[*step.GroupID].Steps))
+		for j, groupStep := range groups[*step.GroupID].Steps {
+			groupStep.ImagePaths = append([]string(nil), groupStep.ImagePaths...)
+			groupStep.Attachments = append([]testprocedure.Attachment(nil), groupStep.Attachments...)
+			groupSteps[j] = groupStep
+		}
+		return groupSteps
+	})
+	return &expanded, nil
+}
+
+// References returns the step groups the steps of a procedure in a project
+// reference, in order of first use, with whether each has changed since the
+// procedure was reviewed against it.
+func References(ctx context.Context, store Store, projectID uuid.UUID, steps testprocedure.Steps) ([]Reference, error) {
+	ids := groupIDs(steps)
+	refs := make([]Reference, 0, len(ids))
+	if len(ids) == 0 {
+		return refs, nil
+	}
+	groups, err := store.GetByIDs(ctx, projectID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byGroup := make(map[uuid.UUID]int, len(ids))
+	for i, step := range steps {
+		if !step.IsGroupReference() {
+			continue
+		}
+		n, ok := byGroup[*step.GroupID]
+		if !ok {
+			ref := Reference{GroupID: *step.GroupID, Name: step.Name, PinnedRevision: step.GroupRevision}
+			if group, found := groups[*step.GroupID]; found {
+				ref.Name = group.Name
+				ref.CurrentRevision = group.Revision
+			} else {
+				ref.Deleted = true
+			}
+			refs = append(refs, ref)
+			n = len(refs) - 1
+			byGroup[*step.GroupID] = n
+		}
+		// Several references to a group may be pinned differently; report
+		// the oldest
+		if step.GroupRevision < refs[n].PinnedRevision {
+			refs[n].PinnedRevision = step.GroupRevision
+		}
+		refs[n].StepIndexes = append(refs[n].StepIndexes, i)
+	}
+	for i := range refs {
+		refs[i].Changed = !refs[i].Deleted && refs[i].PinnedRevision < refs[i].CurrentRevision
+	}
+	return refs, nil
+}
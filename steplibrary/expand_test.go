@@ -0,0 +1,150 @@
+package steplibrary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPin(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	group := createTestGroup("Log in as admin", projectID, "Open login page", "Submit credentials")
+	require.NoError(t, store.Create(ctx, group))
+	require.NoError(t, store.Update(ctx, group.ID, SetSteps(testprocedure.Steps{{Name: "Use SSO"}})))
+
+	t.Run("new references are named and pinned", func(t *testing.T) {
+		steps, err := Pin(ctx, store, projectID, testprocedure.Steps{{Name: "Open app"}, reference(group.ID, 0)})
+		require.NoError(t, err)
+		assert.Equal(t, "Log in as admin", steps[1].Name)
+		assert.Equal(t, uint(2), steps[1].GroupRevision)
+	})
+
+	t.Run("existing references keep their revision", func(t *testing.T) {
+		steps, err := Pin(ctx, store, projectID, testprocedure.Steps{reference(group.ID, 1)})
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), steps[0].GroupRevision)
+	})
+
+	t.Run("invalid references return errors", func(t *testing.T) {
+		_, err := Pin(ctx, store, projectID, testprocedure.Steps{reference(group.ID, 3)})
+		assert.ErrorIs(t, err, ErrInvalidReference)
+		_, err = Pin(ctx, store, uuid.New(), testprocedure.Steps{reference(group.ID, 0)})
+		assert.ErrorIs(t, err, ErrStepGroupNotFound)
+	})
+}
+
+func TestExpand(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	group := createTestGroup("Log in as admin", projectID, "Open login page", "Submit credentials")
+	require.NoError(t, store.Create(ctx, group))
+
+	ref := reference(group.ID, 1)
+	ref.Name = "Log in as admin"
+	tp := &testprocedure.TestProcedure{
+		ProjectID: projectID,
+		Steps:     testprocedure.Steps{{Name: "Open app"}, ref, {Name: "Check dashboard"}},
+	}
+
+	expanded, err := Expand(ctx, store, tp)
+	require.NoError(t, err)
+	var names []string
+	for _, step := range expanded.Steps {
+		names = append(names, step.Name)
+	}
+	assert.Equal(t, []string{"Open app", "Open login page", "Submit credentials", "Check dashboard"}, names)
+	assert.Len(t, tp.Steps, 3, "the procedure itself is unchanged")
+
+	t.Run("procedures without references are returned as they are", func(t *testing.T) {
+		plain := &testprocedure.TestProcedure{ProjectID: projectID, Steps: testprocedure.Steps{{Name: "Open app"}}}
+		expanded, err := Expand(ctx, store, plain)
+		require.NoError(t, err)
+		assert.Same(t, plain, expanded)
+	})
+
+	t.Run("deleted groups cannot be expanded", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, group.ID))
+		_, err := Expand(ctx, store, tp)
+		assert.ErrorIs(t, err, ErrStepGroupNotFound)
+	})
+}
+
+func TestReferences(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	changed := createTestGroup("Log in", projectID, "Open login page")
+	unchanged := createTestGroup("Log out", projectID, "Click log out")
+	require.NoError(t, store.Create(ctx, changed))
+	require.NoError(t, store.Create(ctx, unchanged))
+	require.NoError(t, store.Update(ctx, changed.ID, SetSteps(testprocedure.Steps{{Name: "Use SSO"}})))
+	deletedID := uuid.New()
+
+	refs, err := References(ctx, store, projectID, testprocedure.Steps{
+		reference(changed.ID, 1),
+		{Name: "Do work"},
+		reference(unchanged.ID, 1),
+		reference(changed.ID, 2),
+		reference(deletedID, 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, refs, 3)
+
+	assert.Equal(t, changed.ID, refs[0].GroupID)
+	assert.Equal(t, []int{0, 3}, refs[0].StepIndexes)
+	assert.Equal(t, uint(1), refs[0].PinnedRevision)
+	assert.Equal(t, uint(2), refs[0].CurrentRevision)
+	assert.True(t, refs[0].Changed)
+
+	assert.False(t, refs[1].Changed)
+	assert.False(t, refs[1].Deleted)
+
+	assert.Equal(t, deletedID, refs[2].GroupID)
+	assert.True(t, refs[2].Deleted)
+	assert.False(t, refs[2].Changed)
+}
+
+func TestExpand_Conditions(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	group := createTestGroup("Log in as admin", projectID, "Open login page", "Submit credentials")
+	require.NoError(t, store.Create(ctx, group))
+
+	ref := reference(group.ID, 1)
+	ref.Name = "Log in as admin"
+	tp := &testprocedure.TestProcedure{
+		ProjectID: projectID,
+		Steps: testprocedure.Steps{
+			{Name: "Logged out?", Condition: &testprocedure.StepCondition{
+				Check:     testprocedure.ConditionURLContains,
+				Text:      "/login",
+				ThenSteps: 1,
+				ElseSteps: 1,
+			}},
+			ref,
+			{Name: "Open dashboard"},
+			{Name: "Check widgets"},
+		},
+	}
+
+	expanded, err := Expand(ctx, store, tp)
+	require.NoError(t, err)
+	require.Len(t, expanded.Steps, 5)
+	cond := expanded.Steps[0].Condition
+	require.NotNil(t, cond)
+	assert.Equal(t, 2, cond.ThenSteps, "the then branch covers the group's steps")
+	assert.Equal(t, 1, cond.ElseSteps)
+	assert.Equal(t, 1, tp.Steps[0].Condition.ThenSteps, "the procedure itself is unchanged")
+}
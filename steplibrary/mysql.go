@@ -0,0 +1,189 @@
+package steplibrary
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed step group store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new step group in the database, removing what is unsafe
+// to show from the Markdown of its steps' instructions.
+func (s *MySQLStore) Create(ctx context.Context, group *StepGroup) error {
+	if err := group.Validate(); err != nil {
+		return err
+	}
+	group.Steps = group.Steps.SanitizeInstructions()
+
+	if err := s.db.WithContext(ctx).Create(group).Error; err != nil {
+		s.logger.Error(ctx, "failed to create step group", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": group.ProjectID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "step group created", map[string]interface{}{
+		"step_group_id": group.ID.String(),
+		"project_id":    group.ProjectID.String(),
+	})
+
+	return nil
+}
+
+// GetByID retrieves a step group by its ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*StepGroup, error) {
+	var group StepGroup
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&group).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStepGroupNotFound
+		}
+		s.logger.Error(ctx, "failed to get step group by ID", map[string]interface{}{
+			"error":         err.Error(),
+			"step_group_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// GetByIDs retrieves the step groups with the given IDs in a project, keyed
+// by ID.
+func (s *MySQLStore) GetByIDs(ctx context.Context, projectID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]*StepGroup, error) {
+	groups := make(map[uuid.UUID]*StepGroup, len(ids))
+	if len(ids) == 0 {
+		return groups, nil
+	}
+
+	var found []*StepGroup
+	err := s.db.WithContext(ctx).
+		Where("project_id = ? AND id IN ?", projectID, ids).
+		Find(&found).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to get step groups by ID", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	for _, g := range found {
+		groups[g.ID] = g
+	}
+	return groups, nil
+}
+
+// Update updates a step group with the given setters.
+func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error {
+	group, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, setter := range setters {
+		if err := setter(group); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Save(group).Error; err != nil {
+		s.logger.Error(ctx, "failed to update step group", map[string]interface{}{
+			"error":         err.Error(),
+			"step_group_id": id.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "step group updated", map[string]interface{}{
+		"step_group_id": id.String(),
+		"revision":      group.Revision,
+	})
+
+	return nil
+}
+
+// Delete soft-deletes a step group by its ID.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&StepGroup{}, "id = ?", id)
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete step group", map[string]interface{}{
+			"error":         result.Error.Error(),
+			"step_group_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrStepGroupNotFound
+	}
+
+	s.logger.Info(ctx, "step group deleted", map[string]interface{}{
+		"step_group_id": id.String(),
+	})
+
+	return nil
+}
+
+// ListByProject retrieves a paginated list of the step groups of a project,
+// by name.
+func (s *MySQLStore) ListByProject(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]*StepGroup, error) {
+	var groups []*StepGroup
+	err := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("name ASC").
+		Order("id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&groups).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list step groups", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// CountByProject returns the number of step groups in a project.
+func (s *MySQLStore) CountByProject(ctx context.Context, projectID uuid.UUID) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&StepGroup{}).
+		Where("project_id = ?", projectID).
+		Count(&count).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to count step groups", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return 0, err
+	}
+
+	return int(count), nil
+}
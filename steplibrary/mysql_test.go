@@ -0,0 +1,100 @@
+package steplibrary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_Create(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("create group", func(t *testing.T) {
+		group := createTestGroup("Log in as admin", uuid.New(), "Open login page", "Submit admin credentials")
+		require.NoError(t, store.Create(ctx, group))
+
+		retrieved, err := store.GetByID(ctx, group.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Log in as admin", retrieved.Name)
+		assert.Equal(t, uint(1), retrieved.Revision)
+		require.Len(t, retrieved.Steps, 2)
+		assert.Equal(t, "Submit admin credentials", retrieved.Steps[1].Name)
+	})
+
+	t.Run("invalid groups return errors", func(t *testing.T) {
+		assert.ErrorIs(t, store.Create(ctx, createTestGroup("", uuid.New(), "step")), ErrInvalidName)
+		assert.ErrorIs(t, store.Create(ctx, createTestGroup("Empty", uuid.New())), ErrNoSteps)
+
+		nested := createTestGroup("Nested", uuid.New(), "step")
+		nested.Steps = append(nested.Steps, reference(uuid.New(), 1))
+		nested.Steps[1].Name = "Other group"
+		assert.ErrorIs(t, store.Create(ctx, nested), ErrNestedGroup)
+	})
+}
+
+func TestMySQLStore_Update(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	group := createTestGroup("Log in", uuid.New(), "Open login page")
+	require.NoError(t, store.Create(ctx, group))
+
+	t.Run("renaming keeps the revision", func(t *testing.T) {
+		require.NoError(t, store.Update(ctx, group.ID, SetName("Log in as admin")))
+		retrieved, err := store.GetByID(ctx, group.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Log in as admin", retrieved.Name)
+		assert.Equal(t, uint(1), retrieved.Revision)
+	})
+
+	t.Run("changing steps moves to the next revision", func(t *testing.T) {
+		steps := testprocedure.Steps{{Name: "Open login page"}, {Name: "Submit credentials"}}
+		require.NoError(t, store.Update(ctx, group.ID, SetSteps(steps)))
+		retrieved, err := store.GetByID(ctx, group.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), retrieved.Revision)
+
+		require.NoError(t, store.Update(ctx, group.ID, SetSteps(retrieved.Steps)))
+		retrieved, err = store.GetByID(ctx, group.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), retrieved.Revision, "saving the same steps is not a change")
+	})
+
+	t.Run("non-existent group returns error", func(t *testing.T) {
+		assert.ErrorIs(t, store.Update(ctx, uuid.New(), SetName("x")), ErrStepGroupNotFound)
+	})
+}
+
+func TestMySQLStore_ListAndDelete(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	beta := createTestGroup("Beta", projectID, "step")
+	alpha := createTestGroup("Alpha", projectID, "step")
+	other := createTestGroup("Other project", uuid.New(), "step")
+	for _, g := range []*StepGroup{beta, alpha, other} {
+		require.NoError(t, store.Create(ctx, g))
+	}
+
+	groups, err := store.ListByProject(ctx, projectID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "Alpha", groups[0].Name)
+
+	found, err := store.GetByIDs(ctx, projectID, []uuid.UUID{alpha.ID, other.ID})
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+	assert.Contains(t, found, alpha.ID)
+
+	require.NoError(t, store.Delete(ctx, alpha.ID))
+	assert.ErrorIs(t, store.Delete(ctx, alpha.ID), ErrStepGroupNotFound)
+	count, err := store.CountByProject(ctx, projectID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,44 @@
+package steplibrary
+
+import (
+	"reflect"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// SetName returns an UpdateSetter that sets the step group's name.
+func SetName(name string) UpdateSetter {
+	return func(g *StepGroup) error {
+		if name == "" {
+			return ErrInvalidName
+		}
+		g.Name = name
+		return nil
+	}
+}
+
+// SetDescription returns an UpdateSetter that sets the step group's
+// description.
+func SetDescription(description string) UpdateSetter {
+	return func(g *StepGroup) error {
+		g.Description = description
+		return nil
+	}
+}
+
+// SetSteps returns an UpdateSetter that replaces the step group's steps,
+// moving it to its next revision if they changed. What is unsafe to show is
+// removed from the Markdown of their instructions.
+func SetSteps(steps testprocedure.Steps) UpdateSetter {
+	return func(g *StepGroup) error {
+		if err := validateSteps(steps); err != nil {
+			return err
+		}
+		steps = steps.SanitizeInstructions()
+		if !reflect.DeepEqual(g.Steps, steps) {
+			g.Steps = steps
+			g.Revision++
+		}
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+// Package steplibrary holds the step groups of a project: sequences of
+// steps, such as "log in as admin", that procedures reference instead of
+// repeating. References are expanded into the group's current steps when a
+// procedure is run, exported or scripted, so a change to a group reaches
+// every procedure that uses it.
+package steplibrary
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrStepGroupNotFound is returned when a step group is not found.
+	ErrStepGroupNotFound = errors.New("step group not found")
+
+	// ErrInvalidName is returned when a step group name is empty.
+	ErrInvalidName = errors.New("step group name is required")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidCreatedBy is returned when created_by is not set.
+	ErrInvalidCreatedBy = errors.New("created_by is required")
+
+	// ErrNoSteps is returned when a step group has no steps.
+	ErrNoSteps = errors.New("step group must have at least one step")
+
+	// ErrNestedGroup is returned when a step group references another
+	// group. Groups are expanded one level deep, so they cannot form cycles.
+	ErrNestedGroup = errors.New("step groups cannot reference other step groups")
+
+	// ErrInvalidReference is returned when a procedure step references a
+	// step group at a revision the group has not reached.
+	ErrInvalidReference = errors.New("invalid step group reference")
+)
+
+// StepGroup is a reusable sequence of steps shared by the procedures of a
+// project. Revision counts the changes to its steps.
+type StepGroup struct {
+	ID          uuid.UUID           `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID   uuid.UUID           `json:"project_id" gorm:"type:char(36);not null;index:idx_step_groups_project_id"`
+	Name        string              `json:"name" gorm:"type:varchar(255);not null"`
+	Description string              `json:"description" gorm:"type:text"`
+	Steps       testprocedure.Steps `json:"steps" gorm:"type:json"`
+	Revision    uint                `json:"revision" gorm:"not null;default:1"`
+	CreatedBy   uuid.UUID           `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt      `json:"-" gorm:"index"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new step group.
+func (g *StepGroup) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	if g.Revision == 0 {
+		g.Revision = 1
+	}
+	return nil
+}
+
+// Validate checks if the step group has valid required fields.
+func (g *StepGroup) Validate() error {
+	if g.Name == "" {
+		return ErrInvalidName
+	}
+	if g.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if g.CreatedBy == uuid.Nil {
+		return ErrInvalidCreatedBy
+	}
+	return validateSteps(g.Steps)
+}
+
+// validateSteps checks the steps of a group.
+func validateSteps(steps testprocedure.Steps) error {
+	if len(steps) == 0 {
+		return ErrNoSteps
+	}
+	for i, step := range steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d: %w", i+1, testprocedure.ErrInvalidStepName)
+		}
+		if step.IsGroupReference() {
+			return fmt.Errorf("step %d: %w", i+1, ErrNestedGroup)
+		}
+	}
+	if err := steps.ValidateConditions(); err != nil {
+		return err
+	}
+	return steps.ValidateAttachments()
+}
+
+// Reference is a procedure's use of a step group. Changed is set when the
+// group's steps have changed since the procedure was last reviewed against
+// it, and Deleted when the group no longer exists, which stops the
+// procedure from being run until the reference is removed.
+type Reference struct {
+	GroupID         uuid.UUID `json:"group_id"`
+	Name            string    `json:"name"`
+	StepIndexes     []int     `json:"step_indexes"`
+	PinnedRevision  uint      `json:"pinned_revision"`
+	CurrentRevision uint      `json:"current_revision"`
+	Changed         bool      `json:"changed"`
+	Deleted         bool      `json:"deleted"`
+}
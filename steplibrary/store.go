@@ -0,0 +1,37 @@
+package steplibrary
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for step group persistence operations.
+type Store interface {
+	// Create creates a new step group in the store.
+	Create(ctx context.Context, group *StepGroup) error
+
+	// GetByID retrieves a step group by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*StepGroup, error)
+
+	// GetByIDs retrieves the step groups with the given IDs in a project,
+	// keyed by ID. Missing and deleted groups are left out.
+	GetByIDs(ctx context.Context, projectID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]*StepGroup, error)
+
+	// Update updates a step group with the given setters. A change to its
+	// steps increments its revision.
+	Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error
+
+	// Delete soft-deletes a step group by its ID.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListByProject retrieves a paginated list of the step groups of a
+	// project, by name.
+	ListByProject(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]*StepGroup, error)
+
+	// CountByProject returns the number of step groups in a project.
+	CountByProject(ctx context.Context, projectID uuid.UUID) (int, error)
+}
+
+// UpdateSetter is a function that updates a step group field.
+type UpdateSetter func(*StepGroup) error
@@ -0,0 +1,101 @@
+package stepsuggest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/hairizuanbinnoorazman/ui-automation/llmusage"
+)
+
+// BedrockSuggester implements Suggester using a vision-capable model on AWS
+// Bedrock.
+type BedrockSuggester struct {
+	client    *bedrockruntime.Client
+	modelID   string
+	maxTokens int
+}
+
+// NewBedrockSuggester creates a new Bedrock-based step suggester.
+func NewBedrockSuggester(region, modelID string, maxTokens int) (*BedrockSuggester, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &BedrockSuggester{
+		client:    bedrockruntime.NewFromConfig(cfg),
+		modelID:   modelID,
+		maxTokens: maxTokens,
+	}, nil
+}
+
+// Suggest sends the screenshot and prompt to the model and parses the steps
+// it proposes.
+func (s *BedrockSuggester) Suggest(ctx context.Context, req Request) ([]Suggestion, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	requestBody := map[string]interface{}{
+		"anthropic_version": "bedrock-2023-05-31",
+		"max_tokens":        s.maxTokens,
+		"messages": []map[string]interface{}{
+			{
+				"role": "user",
+				"content": []map[string]interface{}{
+					{
+						"type": "image",
+						"source": map[string]interface{}{
+							"type":       "base64",
+							"media_type": req.MediaType,
+							"data":       base64.StdEncoding.EncodeToString(req.Image),
+						},
+					},
+					{
+						"type": "text",
+						"text": BuildPrompt(req),
+					},
+				},
+			},
+		},
+	}
+
+	payloadBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	output, err := s.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(s.modelID),
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+		Body:        payloadBytes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke Bedrock model: %w", err)
+	}
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage llmusage.Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(output.Body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	llmusage.Add(ctx, response.Usage)
+	if len(response.Content) == 0 {
+		return nil, ErrNoSuggestions
+	}
+
+	return ParseSuggestions(response.Content[0].Text)
+}
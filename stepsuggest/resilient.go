@@ -0,0 +1,47 @@
+package stepsuggest
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/resilience"
+)
+
+// ResilientSuggester wraps a Suggester so that model calls run under a
+// circuit breaker with a per-call deadline.
+type ResilientSuggester struct {
+	suggester Suggester
+	breaker   *resilience.Breaker
+}
+
+// NewResilientSuggester wraps suggester with the given breaker.
+func NewResilientSuggester(suggester Suggester, breaker *resilience.Breaker) *ResilientSuggester {
+	return &ResilientSuggester{
+		suggester: suggester,
+		breaker:   breaker,
+	}
+}
+
+// Suggest proposes steps through the breaker. Invalid requests and replies
+// without usable steps do not count against the model.
+func (s *ResilientSuggester) Suggest(ctx context.Context, req Request) ([]Suggestion, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var suggestions []Suggestion
+	var empty error
+	err := s.breaker.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		suggestions, err = s.suggester.Suggest(ctx, req)
+		if errors.Is(err, ErrNoSuggestions) {
+			empty = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, empty
+}
@@ -0,0 +1,161 @@
+// Package stepsuggest proposes procedure steps from a screenshot of the page
+// under test: a vision model looks at the page and the steps written so far
+// and suggests the actions a tester would take next.
+package stepsuggest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+const (
+	// MaxSuggestions is the most steps kept from one suggestion.
+	MaxSuggestions = 10
+
+	// MaxHintLength is the longest hint an author can give.
+	MaxHintLength = 1000
+)
+
+var (
+	// ErrImageRequired is returned when a request has no screenshot.
+	ErrImageRequired = errors.New("a screenshot is required")
+
+	// ErrHintTooLong is returned when the hint exceeds MaxHintLength.
+	ErrHintTooLong = fmt.Errorf("hint must be at most %d characters", MaxHintLength)
+
+	// ErrNoSuggestions is returned when the model proposed no usable steps.
+	ErrNoSuggestions = errors.New("no steps could be suggested for this page")
+)
+
+// Action is the kind of interaction a suggested step performs.
+type Action string
+
+const (
+	ActionNavigate Action = "navigate"
+	ActionClick    Action = "click"
+	ActionType     Action = "type"
+	ActionSelect   Action = "select"
+	ActionCheck    Action = "check"
+	ActionHover    Action = "hover"
+	ActionWait     Action = "wait"
+	ActionAssert   Action = "assert"
+)
+
+// IsValid checks if the action is valid.
+func (a Action) IsValid() bool {
+	switch a {
+	case ActionNavigate, ActionClick, ActionType, ActionSelect, ActionCheck, ActionHover, ActionWait, ActionAssert:
+		return true
+	default:
+		return false
+	}
+}
+
+// Suggestion is a candidate step proposed by the model. Selector is a CSS
+// selector of the element the step acts on, and Value the text typed, option
+// selected or URL navigated to, if any.
+type Suggestion struct {
+	Name         string `json:"name"`
+	Action       Action `json:"action"`
+	Selector     string `json:"selector,omitempty"`
+	Value        string `json:"value,omitempty"`
+	Instructions string `json:"instructions"`
+}
+
+// Step converts the suggestion into a procedure step. The selector and value
+// are kept in the instructions, which is where testers and script
+// generation read them from.
+func (s Suggestion) Step(imagePaths []string) testprocedure.TestStep {
+	instructions := s.Instructions
+	var details []string
+	if s.Selector != "" {
+		details = append(details, fmt.Sprintf("selector: `%s`", s.Selector))
+	}
+	if s.Value != "" {
+		details = append(details, fmt.Sprintf("value: `%s`", s.Value))
+	}
+	if len(details) > 0 {
+		instructions = fmt.Sprintf("%s (%s)", instructions, strings.Join(details, ", "))
+	}
+	if imagePaths == nil {
+		imagePaths = []string{}
+	}
+	return testprocedure.TestStep{
+		Name:         s.Name,
+		Instructions: instructions,
+		ImagePaths:   imagePaths,
+	}
+}
+
+// Request is a screenshot of a page to suggest steps for. Image holds the
+// encoded image and MediaType its MIME type. PageURL, the procedure's name,
+// description and steps so far, and the author's hint give the model
+// context.
+type Request struct {
+	Image                []byte
+	MediaType            string
+	PageURL              string
+	ProcedureName        string
+	ProcedureDescription string
+	ExistingSteps        testprocedure.Steps
+	Hint                 string
+}
+
+// Validate checks the request before it is sent to the model.
+func (r Request) Validate() error {
+	if len(r.Image) == 0 {
+		return ErrImageRequired
+	}
+	if len([]rune(r.Hint)) > MaxHintLength {
+		return ErrHintTooLong
+	}
+	return nil
+}
+
+// Suggester proposes procedure steps for a screenshot.
+// Implementations can use different backends (AWS Bedrock, OpenAI, etc.)
+type Suggester interface {
+	// Suggest returns the steps the model proposes, in order. It returns
+	// ErrNoSuggestions when none are usable.
+	Suggest(ctx context.Context, req Request) ([]Suggestion, error)
+}
+
+// ParseSuggestions extracts the suggestions from the model's reply, which
+// should be a JSON array, possibly wrapped in a code fence or prose.
+// Suggestions without a name, instructions or a known action are dropped,
+// and at most MaxSuggestions are kept.
+func ParseSuggestions(reply string) ([]Suggestion, error) {
+	start := strings.Index(reply, "[")
+	end := strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, ErrNoSuggestions
+	}
+
+	var raw []Suggestion
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse suggestions: %w", err)
+	}
+
+	suggestions := make([]Suggestion, 0, len(raw))
+	for _, s := range raw {
+		s.Name = strings.TrimSpace(s.Name)
+		s.Instructions = strings.TrimSpace(s.Instructions)
+		s.Action = Action(strings.ToLower(strings.TrimSpace(string(s.Action))))
+		if s.Name == "" || s.Instructions == "" || !s.Action.IsValid() {
+			continue
+		}
+		suggestions = append(suggestions, s)
+		if len(suggestions) == MaxSuggestions {
+			break
+		}
+	}
+	if len(suggestions) == 0 {
+		return nil, ErrNoSuggestions
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,113 @@
+package testmanagement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Client pushes results to a test management tool.
+type Client interface {
+	// PushResult records result against the test case of the link and
+	// returns the URL of the result, if the tool reports one.
+	PushResult(ctx context.Context, link *Link, result *Result) (string, error)
+}
+
+// NewClient creates the client of the connection's provider, authenticated
+// with its decrypted token.
+func NewClient(conn *Connection) (Client, error) {
+	if conn.Token == "" {
+		return nil, ErrTokenRequired
+	}
+	switch conn.Provider {
+	case ProviderTestRail:
+		return newTestRailClient(conn), nil
+	case ProviderXray:
+		return newXrayClient(conn), nil
+	default:
+		return nil, ErrInvalidProvider
+	}
+}
+
+// apiError is an unexpected response of a provider's API.
+type apiError struct {
+	provider   Provider
+	operation  string
+	statusCode int
+	body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s failed with status %d: %s", e.provider, e.operation, e.statusCode, e.body)
+}
+
+// apiClient makes JSON requests to a provider's API.
+type apiClient struct {
+	provider   Provider
+	httpClient *http.Client
+	headers    map[string]string
+}
+
+func newAPIClient(provider Provider, headers map[string]string) *apiClient {
+	return &apiClient{
+		provider:   provider,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		headers:    headers,
+	}
+}
+
+// maxResponseSize bounds the response bodies read from providers.
+const maxResponseSize = 1 << 20
+
+// doJSON sends body as JSON to url and decodes a response with one of the
+// expected statuses into out, which may be nil. Any other status is
+// returned as an *apiError.
+func (c *apiClient) doJSON(ctx context.Context, operation, method, url string, headers map[string]string, body, out interface{}, expected ...int) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%s: failed to marshal request body: %w", c.provider, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%s: failed to create request: %w", c.provider, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %s failed: %w", c.provider, operation, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("%s: failed to read %s response: %w", c.provider, operation, err)
+	}
+	for _, status := range expected {
+		if resp.StatusCode != status {
+			continue
+		}
+		if out != nil {
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("%s: failed to decode %s response: %w", c.provider, operation, err)
+			}
+		}
+		return nil
+	}
+
+	if len(respBody) > 1000 {
+		respBody = respBody[:1000]
+	}
+	return &apiError{provider: c.provider, operation: operation, statusCode: resp.StatusCode, body: string(respBody)}
+}
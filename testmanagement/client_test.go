@@ -0,0 +1,117 @@
+package testmanagement
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates the client of conn pointed at handler.
+func newTestClient(t *testing.T, conn *Connection, handler http.Handler) Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	conn.BaseURL = server.URL
+	client, err := NewClient(conn)
+	require.NoError(t, err)
+	return client
+}
+
+// testResult is the result of a failed run that took 90 seconds.
+func testResult() *Result {
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	completed := started.Add(90 * time.Second)
+	return &Result{Status: testrun.StatusFailed, Notes: "Card declined", StartedAt: &started, CompletedAt: &completed}
+}
+
+func TestNewClient(t *testing.T) {
+	conn := newTestConnection()
+	conn.Token = ""
+	_, err := NewClient(conn)
+	assert.ErrorIs(t, err, ErrTokenRequired)
+}
+
+func TestTestRailClient_PushResult(t *testing.T) {
+	link := &Link{TestRunID: uuid.New(), Target: "12", TestCase: "345"}
+
+	t.Run("adds the result", func(t *testing.T) {
+		var body map[string]interface{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /index.php", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v2/add_result_for_case/12/345", r.URL.RawQuery)
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "qa@acme.com", username)
+			assert.Equal(t, "testrail-key", password)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.Write([]byte(`{"id":9001,"test_id":77,"status_id":5}`))
+		})
+
+		conn := newTestConnection()
+		resultURL, err := newTestClient(t, conn, mux).PushResult(context.Background(), link, testResult())
+		require.NoError(t, err)
+		assert.Equal(t, conn.BaseURL+"/index.php?/tests/view/77", resultURL)
+		assert.Equal(t, float64(testRailStatusFailed), body["status_id"])
+		assert.Equal(t, "90s", body["elapsed"])
+		assert.Equal(t, "Card declined", body["comment"])
+	})
+
+	t.Run("rejected result", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /index.php", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"Field :case_id is not a valid test case."}`))
+		})
+
+		_, err := newTestClient(t, newTestConnection(), mux).PushResult(context.Background(), link, testResult())
+		assert.ErrorContains(t, err, "add result failed with status 400")
+		assert.ErrorContains(t, err, "not a valid test case")
+	})
+}
+
+func TestXrayClient_PushResult(t *testing.T) {
+	link := &Link{TestRunID: uuid.New(), Target: "QA-10", TestCase: "QA-7"}
+	conn := newTestConnection()
+	conn.Provider = ProviderXray
+	conn.Username = "client-id"
+	conn.Token = "client-secret"
+
+	var imported struct {
+		TestExecutionKey string     `json:"testExecutionKey"`
+		Tests            []xrayTest `json:"tests"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		var creds map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&creds))
+		assert.Equal(t, map[string]string{"client_id": "client-id", "client_secret": "client-secret"}, creds)
+		w.Write([]byte(`"xray-token"`))
+	})
+	mux.HandleFunc("POST /api/v2/import/execution", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xray-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&imported))
+		w.Write([]byte(`{"id":"10001","key":"QA-10"}`))
+	})
+
+	resultURL, err := newTestClient(t, conn, mux).PushResult(context.Background(), link, testResult())
+	require.NoError(t, err)
+	assert.Empty(t, resultURL)
+	assert.Equal(t, "QA-10", imported.TestExecutionKey)
+	require.Len(t, imported.Tests, 1)
+	assert.Equal(t, xrayTest{
+		TestKey: "QA-7",
+		Status:  "FAILED",
+		Comment: "Card declined",
+		Start:   "2026-03-01T09:00:00Z",
+		Finish:  "2026-03-01T09:01:30Z",
+	}, imported.Tests[0])
+}
@@ -0,0 +1,34 @@
+package testmanagement
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"gorm.io/gorm"
+)
+
+// setupTestStores creates a test database and connection and link stores
+// for testing.
+func setupTestStores(t *testing.T) (*gorm.DB, *MySQLStore, *MySQLLinkStore) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Connection{}, &Link{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, integration.NewKeyring(integration.DeriveKey("test-encryption-key")), log)
+	return db, store, NewMySQLLinkStore(db, log)
+}
+
+// newTestConnection creates a valid TestRail connection for a new project.
+func newTestConnection() *Connection {
+	return &Connection{
+		ProjectID: uuid.New(),
+		Provider:  ProviderTestRail,
+		BaseURL:   "https://acme.testrail.io",
+		Username:  "qa@acme.com",
+		Token:     "testrail-key",
+		CreatedBy: uuid.New(),
+	}
+}
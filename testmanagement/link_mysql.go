@@ -0,0 +1,147 @@
+package testmanagement
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// maxPushErrorLength bounds the push error kept on a link.
+const maxPushErrorLength = 1000
+
+// MySQLLinkStore implements the LinkStore interface using GORM and MySQL.
+type MySQLLinkStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLLinkStore creates a new MySQL-backed result link store.
+func NewMySQLLinkStore(db *gorm.DB, log logger.Logger) *MySQLLinkStore {
+	return &MySQLLinkStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new link.
+func (s *MySQLLinkStore) Create(ctx context.Context, link *Link) error {
+	if link.TestRunID == uuid.Nil {
+		return ErrInvalidTestRunID
+	}
+
+	if err := s.db.WithContext(ctx).Create(link).Error; err != nil {
+		s.logger.Error(ctx, "failed to create result link", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": link.TestRunID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "result link created", map[string]interface{}{
+		"link_id":     link.ID.String(),
+		"test_run_id": link.TestRunID.String(),
+		"provider":    link.Provider,
+	})
+
+	return nil
+}
+
+// GetByID retrieves a link by ID.
+func (s *MySQLLinkStore) GetByID(ctx context.Context, id uuid.UUID) (*Link, error) {
+	var link Link
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&link).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		s.logger.Error(ctx, "failed to get result link", map[string]interface{}{
+			"error":   err.Error(),
+			"link_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// ListByRun lists the links of a test run, oldest first.
+func (s *MySQLLinkStore) ListByRun(ctx context.Context, testRunID uuid.UUID) ([]*Link, error) {
+	var links []*Link
+	err := s.db.WithContext(ctx).
+		Where("test_run_id = ?", testRunID).
+		Order("created_at ASC").
+		Find(&links).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list result links", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": testRunID.String(),
+		})
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// Delete deletes a link.
+func (s *MySQLLinkStore) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&Link{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete result link", map[string]interface{}{
+			"error":   result.Error.Error(),
+			"link_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrLinkNotFound
+	}
+
+	s.logger.Info(ctx, "result link deleted", map[string]interface{}{
+		"link_id": id.String(),
+	})
+
+	return nil
+}
+
+// RecordPush records the outcome of a push.
+func (s *MySQLLinkStore) RecordPush(ctx context.Context, id uuid.UUID, at time.Time, resultURL string, pushErr error) error {
+	columns := map[string]interface{}{
+		"last_pushed_at":  at,
+		"last_push_error": "",
+	}
+	if pushErr != nil {
+		message := pushErr.Error()
+		if len(message) > maxPushErrorLength {
+			message = message[:maxPushErrorLength]
+		}
+		columns["last_push_error"] = message
+	} else {
+		columns["result_url"] = resultURL
+	}
+
+	err := s.db.WithContext(ctx).
+		Model(&Link{}).
+		Where("id = ?", id).
+		UpdateColumns(columns).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to record result push", map[string]interface{}{
+			"error":   err.Error(),
+			"link_id": id.String(),
+		})
+		return err
+	}
+
+	return nil
+}
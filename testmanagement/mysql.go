@@ -0,0 +1,145 @@
+package testmanagement
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// tokenKey is the credentials map key the token is encrypted under.
+const tokenKey = "token"
+
+// MySQLStore implements the ConnectionStore interface using GORM and MySQL.
+// Tokens are encrypted with AES-256-GCM, the same scheme used for
+// integration credentials.
+type MySQLStore struct {
+	db      *gorm.DB
+	keyring *integration.Keyring
+	logger  logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed test management connection store
+// that encrypts tokens with the keys in keyring.
+func NewMySQLStore(db *gorm.DB, keyring *integration.Keyring, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:      db,
+		keyring: keyring,
+		logger:  log,
+	}
+}
+
+// GetByProject retrieves the connection of a project with its token
+// decrypted.
+func (s *MySQLStore) GetByProject(ctx context.Context, projectID uuid.UUID) (*Connection, error) {
+	var conn Connection
+	err := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		First(&conn).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConnectionNotFound
+		}
+		s.logger.Error(ctx, "failed to get test management connection", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	decrypted, err := s.keyring.Decrypt(conn.EncryptedToken)
+	if err != nil {
+		s.logger.Error(ctx, "failed to decrypt test management token", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, ErrDecryptFailed
+	}
+	conn.Token = decrypted[tokenKey]
+	conn.TokenConfigured = conn.Token != ""
+
+	return &conn, nil
+}
+
+// Save creates or replaces the connection of its project.
+func (s *MySQLStore) Save(ctx context.Context, conn *Connection) error {
+	conn.Normalize()
+	if err := conn.Validate(); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing Connection
+		err := tx.Where("project_id = ?", conn.ProjectID).First(&existing).Error
+		switch {
+		case err == nil:
+			conn.ID = existing.ID
+			conn.CreatedBy = existing.CreatedBy
+			conn.CreatedAt = existing.CreatedAt
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		if conn.Token != "" {
+			encrypted, err := s.keyring.Encrypt(map[string]string{tokenKey: conn.Token})
+			if err != nil {
+				return err
+			}
+			conn.EncryptedToken = encrypted
+		} else if len(existing.EncryptedToken) > 0 && existing.Provider == conn.Provider {
+			// A TestRail API key is no Xray client secret.
+			conn.EncryptedToken = existing.EncryptedToken
+		} else {
+			return ErrTokenRequired
+		}
+		conn.TokenConfigured = true
+
+		return tx.Save(conn).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrTokenRequired) {
+			return err
+		}
+		s.logger.Error(ctx, "failed to save test management connection", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": conn.ProjectID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "test management connection saved", map[string]interface{}{
+		"project_id": conn.ProjectID.String(),
+		"provider":   conn.Provider,
+	})
+
+	return nil
+}
+
+// Delete deletes the connection of a project.
+func (s *MySQLStore) Delete(ctx context.Context, projectID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Delete(&Connection{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete test management connection", map[string]interface{}{
+			"error":      result.Error.Error(),
+			"project_id": projectID.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrConnectionNotFound
+	}
+
+	s.logger.Info(ctx, "test management connection deleted", map[string]interface{}{
+		"project_id": projectID.String(),
+	})
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package testmanagement
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore(t *testing.T) {
+	db, store, _ := setupTestStores(t)
+	ctx := context.Background()
+	conn := newTestConnection()
+
+	t.Run("token is required", func(t *testing.T) {
+		noToken := newTestConnection()
+		noToken.Token = ""
+		assert.ErrorIs(t, store.Save(ctx, noToken), ErrTokenRequired)
+	})
+
+	t.Run("token is encrypted at rest", func(t *testing.T) {
+		require.NoError(t, store.Save(ctx, conn))
+
+		var raw Connection
+		require.NoError(t, db.Where("id = ?", conn.ID).First(&raw).Error)
+		assert.NotContains(t, string(raw.EncryptedToken), "testrail-key")
+
+		retrieved, err := store.GetByProject(ctx, conn.ProjectID)
+		require.NoError(t, err)
+		assert.Equal(t, "testrail-key", retrieved.Token)
+		assert.True(t, retrieved.TokenConfigured)
+	})
+
+	t.Run("save keeps token", func(t *testing.T) {
+		update := newTestConnection()
+		update.ProjectID = conn.ProjectID
+		update.Token = ""
+		update.PushOnComplete = true
+		require.NoError(t, store.Save(ctx, update))
+		assert.Equal(t, conn.ID, update.ID)
+
+		retrieved, err := store.GetByProject(ctx, conn.ProjectID)
+		require.NoError(t, err)
+		assert.Equal(t, "testrail-key", retrieved.Token)
+		assert.True(t, retrieved.PushOnComplete)
+		assert.Equal(t, conn.CreatedBy, retrieved.CreatedBy)
+	})
+
+	t.Run("changing provider needs a token", func(t *testing.T) {
+		update := newTestConnection()
+		update.ProjectID = conn.ProjectID
+		update.Provider = ProviderXray
+		update.Token = ""
+		assert.ErrorIs(t, store.Save(ctx, update), ErrTokenRequired)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, conn.ProjectID))
+		_, err := store.GetByProject(ctx, conn.ProjectID)
+		assert.ErrorIs(t, err, ErrConnectionNotFound)
+		assert.ErrorIs(t, store.Delete(ctx, conn.ProjectID), ErrConnectionNotFound)
+	})
+}
+
+func TestMySQLLinkStore(t *testing.T) {
+	_, _, links := setupTestStores(t)
+	ctx := context.Background()
+	runID := uuid.New()
+
+	first := &Link{TestRunID: runID, Provider: ProviderTestRail, Target: "12", TestCase: "345", CreatedBy: uuid.New()}
+	require.NoError(t, links.Create(ctx, first))
+	second := &Link{TestRunID: runID, Provider: ProviderTestRail, Target: "12", TestCase: "346", CreatedBy: uuid.New()}
+	require.NoError(t, links.Create(ctx, second))
+	require.NoError(t, links.Create(ctx, &Link{TestRunID: uuid.New(), Provider: ProviderXray, Target: "QA-1", TestCase: "QA-2"}))
+
+	t.Run("list by run", func(t *testing.T) {
+		listed, err := links.ListByRun(ctx, runID)
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+		assert.Equal(t, first.ID, listed[0].ID)
+	})
+
+	t.Run("record push", func(t *testing.T) {
+		at := time.Now()
+		require.NoError(t, links.RecordPush(ctx, first.ID, at, "https://acme.testrail.io/index.php?/tests/view/77", nil))
+		retrieved, err := links.GetByID(ctx, first.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.LastPushedAt)
+		assert.Equal(t, "https://acme.testrail.io/index.php?/tests/view/77", retrieved.ResultURL)
+
+		// A failed push keeps the result of the last successful one.
+		require.NoError(t, links.RecordPush(ctx, first.ID, at, "", errors.New("testrail: add result failed with status 401")))
+		retrieved, err = links.GetByID(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Contains(t, retrieved.LastPushError, "401")
+		assert.Equal(t, "https://acme.testrail.io/index.php?/tests/view/77", retrieved.ResultURL)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, links.Delete(ctx, second.ID))
+		_, err := links.GetByID(ctx, second.ID)
+		assert.ErrorIs(t, err, ErrLinkNotFound)
+		assert.ErrorIs(t, links.Delete(ctx, second.ID), ErrLinkNotFound)
+	})
+
+	t.Run("test run is required", func(t *testing.T) {
+		assert.ErrorIs(t, links.Create(ctx, &Link{Provider: ProviderTestRail}), ErrInvalidTestRunID)
+	})
+}
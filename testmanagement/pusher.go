@@ -0,0 +1,149 @@
+package testmanagement
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Pusher pushes the results of completed runs to the test cases they are
+// linked to and records the outcome on the links.
+type Pusher struct {
+	connections ConnectionStore
+	links       LinkStore
+	assets      testrun.AssetStore
+	linkBaseURL string
+	newClient   func(conn *Connection) (Client, error)
+	logger      logger.Logger
+}
+
+// NewPusher creates a new pusher. Results link to their run and its assets
+// under linkBaseURL, the external URL of the server; links are left out if
+// it is empty.
+func NewPusher(connections ConnectionStore, links LinkStore, assets testrun.AssetStore, linkBaseURL string, log logger.Logger) *Pusher {
+	return &Pusher{
+		connections: connections,
+		links:       links,
+		assets:      assets,
+		linkBaseURL: linkBaseURL,
+		newClient:   NewClient,
+		logger:      log,
+	}
+}
+
+// Push pushes the result of a completed run to one of its links. It returns
+// ErrConnectionNotFound if the project has no connection.
+func (p *Pusher) Push(ctx context.Context, projectID uuid.UUID, run *testrun.TestRun, link *Link) (*Link, error) {
+	conn, err := p.connections.GetByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := p.result(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	return p.push(ctx, conn, result, link)
+}
+
+// PushCompleted pushes the result of a run that just completed to all of
+// its links if the project's connection pushes on completion. Failures are
+// logged and recorded on the links, never returned. A nil Pusher pushes
+// nothing.
+func (p *Pusher) PushCompleted(ctx context.Context, projectID uuid.UUID, run *testrun.TestRun) {
+	if p == nil {
+		return
+	}
+
+	links, err := p.links.ListByRun(ctx, run.ID)
+	if err != nil || len(links) == 0 {
+		return
+	}
+	conn, err := p.connections.GetByProject(ctx, projectID)
+	if err != nil {
+		if !errors.Is(err, ErrConnectionNotFound) {
+			p.logger.Warn(ctx, "failed to get test management connection for result push", map[string]interface{}{
+				"error":      err.Error(),
+				"project_id": projectID.String(),
+			})
+		}
+		return
+	}
+	if !conn.PushOnComplete {
+		return
+	}
+
+	result, err := p.result(ctx, run)
+	if err != nil {
+		p.logger.Warn(ctx, "failed to build test run result", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": run.ID.String(),
+		})
+		return
+	}
+	for _, link := range links {
+		if _, err := p.push(ctx, conn, result, link); err != nil {
+			p.logger.Warn(ctx, "failed to push test run result", map[string]interface{}{
+				"error":       err.Error(),
+				"test_run_id": run.ID.String(),
+				"link_id":     link.ID.String(),
+			})
+		}
+	}
+}
+
+// result builds the result of the run with its evidence.
+func (p *Pusher) result(ctx context.Context, run *testrun.TestRun) (*Result, error) {
+	if !run.Status.IsFinal() {
+		return nil, ErrRunNotCompleted
+	}
+	var assets []*testrun.TestRunAsset
+	if p.linkBaseURL != "" {
+		var err error
+		assets, err = p.assets.ListByTestRun(ctx, run.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewResult(run, assets, p.linkBaseURL)
+}
+
+// push pushes the result to the link, which must be of the connection's
+// provider, and returns the link with the outcome recorded.
+func (p *Pusher) push(ctx context.Context, conn *Connection, result *Result, link *Link) (*Link, error) {
+	if link.Provider != conn.Provider {
+		return nil, ErrProviderMismatch
+	}
+	client, err := p.newClient(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	resultURL, pushErr := client.PushResult(ctx, link, result)
+	now := time.Now()
+	// The outcome is recorded even when the request was cancelled.
+	if err := p.links.RecordPush(context.WithoutCancel(ctx), link.ID, now, resultURL, pushErr); err != nil {
+		p.logger.Warn(ctx, "failed to record result push", map[string]interface{}{
+			"error":   err.Error(),
+			"link_id": link.ID.String(),
+		})
+	}
+	if pushErr != nil {
+		return nil, pushErr
+	}
+
+	p.logger.Info(ctx, "test run result pushed", map[string]interface{}{
+		"test_run_id": link.TestRunID.String(),
+		"provider":    conn.Provider,
+		"target":      link.Target,
+		"test_case":   link.TestCase,
+	})
+	pushed := *link
+	pushed.LastPushedAt = &now
+	pushed.LastPushError = ""
+	pushed.ResultURL = resultURL
+	return &pushed, nil
+}
@@ -0,0 +1,96 @@
+package testmanagement
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient records the results pushed to it.
+type fakeClient struct {
+	pushed []*Link
+	err    error
+}
+
+func (c *fakeClient) PushResult(ctx context.Context, link *Link, result *Result) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	c.pushed = append(c.pushed, link)
+	return "https://acme.testrail.io/index.php?/tests/view/77", nil
+}
+
+func newTestPusher(store ConnectionStore, links LinkStore, client *fakeClient) *Pusher {
+	pusher := NewPusher(store, links, nil, "", logger.NewTestLogger())
+	pusher.newClient = func(conn *Connection) (Client, error) { return client, nil }
+	return pusher
+}
+
+func TestPusher(t *testing.T) {
+	_, store, links := setupTestStores(t)
+	ctx := context.Background()
+
+	conn := newTestConnection()
+	require.NoError(t, store.Save(ctx, conn))
+	run := &testrun.TestRun{ID: uuid.New(), Status: testrun.StatusPassed}
+	link := &Link{TestRunID: run.ID, Target: "12", TestCase: "345"}
+	require.NoError(t, conn.ValidateLink(link))
+	require.NoError(t, links.Create(ctx, link))
+
+	t.Run("push", func(t *testing.T) {
+		client := &fakeClient{}
+		pushed, err := newTestPusher(store, links, client).Push(ctx, conn.ProjectID, run, link)
+		require.NoError(t, err)
+		assert.Equal(t, "https://acme.testrail.io/index.php?/tests/view/77", pushed.ResultURL)
+		require.Len(t, client.pushed, 1)
+	})
+
+	t.Run("failed push is recorded", func(t *testing.T) {
+		client := &fakeClient{err: errors.New("testrail: add result failed with status 403")}
+		_, err := newTestPusher(store, links, client).Push(ctx, conn.ProjectID, run, link)
+		assert.Error(t, err)
+
+		retrieved, err := links.GetByID(ctx, link.ID)
+		require.NoError(t, err)
+		assert.Contains(t, retrieved.LastPushError, "403")
+	})
+
+	t.Run("run not completed", func(t *testing.T) {
+		running := &testrun.TestRun{ID: run.ID, Status: testrun.StatusRunning}
+		_, err := newTestPusher(store, links, &fakeClient{}).Push(ctx, conn.ProjectID, running, link)
+		assert.ErrorIs(t, err, ErrRunNotCompleted)
+	})
+
+	t.Run("link of another provider", func(t *testing.T) {
+		xrayLink := &Link{ID: uuid.New(), TestRunID: run.ID, Provider: ProviderXray, Target: "QA-1", TestCase: "QA-2"}
+		_, err := newTestPusher(store, links, &fakeClient{}).Push(ctx, conn.ProjectID, run, xrayLink)
+		assert.ErrorIs(t, err, ErrProviderMismatch)
+	})
+
+	t.Run("push on complete only when enabled", func(t *testing.T) {
+		client := &fakeClient{}
+		pusher := newTestPusher(store, links, client)
+		pusher.PushCompleted(ctx, conn.ProjectID, run)
+		assert.Empty(t, client.pushed)
+
+		conn.PushOnComplete = true
+		conn.Token = ""
+		require.NoError(t, store.Save(ctx, conn))
+		pusher.PushCompleted(ctx, conn.ProjectID, run)
+		assert.Len(t, client.pushed, 1)
+	})
+
+	t.Run("no connection", func(t *testing.T) {
+		_, err := newTestPusher(store, links, &fakeClient{}).Push(ctx, uuid.New(), run, link)
+		assert.ErrorIs(t, err, ErrConnectionNotFound)
+
+		var nilPusher *Pusher
+		nilPusher.PushCompleted(ctx, conn.ProjectID, run)
+	})
+}
@@ -0,0 +1,39 @@
+package testmanagement
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/integration"
+	"gorm.io/gorm"
+)
+
+// RekeyTokens re-encrypts every test management token from oldKey to
+// newKey and returns how many were re-encrypted. Tokens already encrypted
+// with newKey are left alone. It stops at the first value that decrypts with
+// neither key; run it in a transaction so that nothing is left half rotated.
+func RekeyTokens(ctx context.Context, db *gorm.DB, oldKey, newKey []byte) (int, error) {
+	var conns []Connection
+	if err := db.WithContext(ctx).Select("id", "encrypted_token").Find(&conns).Error; err != nil {
+		return 0, fmt.Errorf("failed to list test management connections: %w", err)
+	}
+
+	count := 0
+	for _, conn := range conns {
+		encrypted, changed, err := integration.Reencrypt(oldKey, newKey, conn.EncryptedToken)
+		if err != nil {
+			return 0, fmt.Errorf("test management connection %s: %w", conn.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		err = db.WithContext(ctx).Model(&Connection{}).
+			Where("id = ?", conn.ID).
+			UpdateColumn("encrypted_token", encrypted).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to update test management connection %s: %w", conn.ID, err)
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,91 @@
+package testmanagement
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// Evidence is a link to the evidence of a result.
+type Evidence struct {
+	Name string
+	URL  string
+}
+
+// Result is the outcome of a completed run, as pushed to a test case.
+type Result struct {
+	Status      testrun.Status
+	Notes       string
+	FailedStep  *int
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	Evidence    []Evidence
+}
+
+// NewResult builds the result of a completed run. Evidence links to the run
+// and to the assets uploaded to it, under linkBaseURL, the external URL of
+// the server; without one there is no evidence to link to.
+func NewResult(run *testrun.TestRun, assets []*testrun.TestRunAsset, linkBaseURL string) (*Result, error) {
+	if !run.Status.IsFinal() {
+		return nil, ErrRunNotCompleted
+	}
+
+	result := &Result{
+		Status:      run.Status,
+		Notes:       strings.TrimSpace(run.Notes),
+		FailedStep:  run.FailedStepIndex,
+		StartedAt:   run.StartedAt,
+		CompletedAt: run.CompletedAt,
+	}
+	if linkBaseURL == "" {
+		return result, nil
+	}
+
+	result.Evidence = append(result.Evidence, Evidence{Name: "Test run", URL: linkBaseURL + "/runs/" + run.ID.String()})
+	for _, asset := range assets {
+		// Thumbnails and transcodes duplicate the asset they come from.
+		if asset.SourceAssetID != nil {
+			continue
+		}
+		result.Evidence = append(result.Evidence, Evidence{
+			Name: asset.FileName,
+			URL:  fmt.Sprintf("%s/api/v1/runs/%s/assets/%s", linkBaseURL, run.ID, asset.ID),
+		})
+	}
+	return result, nil
+}
+
+// Duration returns how long the run took, or zero if it is not known.
+func (r *Result) Duration() time.Duration {
+	if r.StartedAt == nil || r.CompletedAt == nil {
+		return 0
+	}
+	return r.CompletedAt.Sub(*r.StartedAt)
+}
+
+// Comment returns the text pushed with the result: the failed step, the
+// run notes and the evidence links.
+func (r *Result) Comment() string {
+	var b strings.Builder
+	if r.FailedStep != nil {
+		fmt.Fprintf(&b, "Failed at step %d.\n", *r.FailedStep+1)
+	}
+	if r.Notes != "" {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(r.Notes + "\n")
+	}
+	if len(r.Evidence) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Evidence:\n")
+		for _, evidence := range r.Evidence {
+			fmt.Fprintf(&b, "- %s: %s\n", evidence.Name, evidence.URL)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,43 @@
+package testmanagement
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConnectionStore defines the interface for test management connection
+// persistence operations.
+type ConnectionStore interface {
+	// GetByProject retrieves the connection of a project with its token
+	// decrypted.
+	GetByProject(ctx context.Context, projectID uuid.UUID) (*Connection, error)
+
+	// Save creates or replaces the connection of its project. An empty
+	// Token keeps the token already saved, if any.
+	Save(ctx context.Context, conn *Connection) error
+
+	// Delete deletes the connection of a project. The links of its runs
+	// are kept.
+	Delete(ctx context.Context, projectID uuid.UUID) error
+}
+
+// LinkStore defines the interface for result link persistence operations.
+type LinkStore interface {
+	// Create creates a new link.
+	Create(ctx context.Context, link *Link) error
+
+	// GetByID retrieves a link by ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*Link, error)
+
+	// ListByRun lists the links of a test run, oldest first.
+	ListByRun(ctx context.Context, testRunID uuid.UUID) ([]*Link, error)
+
+	// Delete deletes a link.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// RecordPush records the outcome of a push; pushErr is nil if it
+	// succeeded, and resultURL is then the result it created.
+	RecordPush(ctx context.Context, id uuid.UUID, at time.Time, resultURL string, pushErr error) error
+}
@@ -0,0 +1,214 @@
+package testmanagement
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrConnectionNotFound is returned when a project has no test
+	// management connection configured.
+	ErrConnectionNotFound = errors.New("test management connection not found")
+
+	// ErrLinkNotFound is returned when a result link is not found.
+	ErrLinkNotFound = errors.New("result link not found")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidTestRunID is returned when test_run_id is not set.
+	ErrInvalidTestRunID = errors.New("test_run_id is required")
+
+	// ErrInvalidProvider is returned when provider is not testrail or xray.
+	ErrInvalidProvider = errors.New("provider must be testrail or xray")
+
+	// ErrInvalidBaseURL is returned when base_url is not an http(s) URL, or
+	// is missing for TestRail.
+	ErrInvalidBaseURL = errors.New("base_url must be an http or https URL")
+
+	// ErrUsernameRequired is returned when a connection has no TestRail user
+	// or Xray client ID.
+	ErrUsernameRequired = errors.New("username is required")
+
+	// ErrTokenRequired is returned when a connection is saved without a
+	// TestRail API key or Xray client secret.
+	ErrTokenRequired = errors.New("token is required")
+
+	// ErrInvalidTarget is returned when a link's target is not a TestRail
+	// run ID or an Xray test execution key.
+	ErrInvalidTarget = errors.New("target must be a TestRail run ID or an Xray test execution key")
+
+	// ErrInvalidTestCase is returned when a link's test case is not a
+	// TestRail case ID or an Xray test key.
+	ErrInvalidTestCase = errors.New("test_case must be a TestRail case ID or an Xray test key")
+
+	// ErrProviderMismatch is returned when a link is pushed through a
+	// connection to another provider than the one it was created for.
+	ErrProviderMismatch = errors.New("result link is for another provider than the project's connection")
+
+	// ErrRunNotCompleted is returned when the result of a run that has not
+	// completed is pushed.
+	ErrRunNotCompleted = errors.New("test run has not completed")
+
+	// ErrDecryptFailed is returned when a stored token cannot be decrypted.
+	ErrDecryptFailed = errors.New("failed to decrypt test management token")
+)
+
+// DefaultXrayBaseURL is the Xray cloud API used when a connection has no
+// base URL.
+const DefaultXrayBaseURL = "https://xray.cloud.getxray.app"
+
+// Provider is the test management tool results are pushed to.
+type Provider string
+
+const (
+	ProviderTestRail Provider = "testrail"
+	ProviderXray     Provider = "xray"
+)
+
+// IsValid checks if the provider is supported.
+func (p Provider) IsValid() bool {
+	return p == ProviderTestRail || p == ProviderXray
+}
+
+// Connection is the TestRail instance or Xray cloud tenant a project's run
+// results are pushed to. TestRail authenticates Username, the user's email,
+// with Token, an API key; Xray authenticates Username, an API client ID,
+// with Token, its client secret. With PushOnComplete, results are pushed to
+// every link of a run as soon as it completes.
+type Connection struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID uuid.UUID `json:"project_id" gorm:"type:char(36);not null;uniqueIndex:idx_test_management_connections_project_id"`
+	Provider  Provider  `json:"provider" gorm:"type:varchar(20);not null"`
+	// BaseURL is the TestRail instance, or the Xray API; empty uses Xray
+	// cloud.
+	BaseURL        string `json:"base_url,omitempty" gorm:"type:varchar(500);not null;default:''"`
+	Username       string `json:"username" gorm:"type:varchar(255);not null"`
+	PushOnComplete bool   `json:"push_on_complete" gorm:"not null;default:false"`
+	// Token is stored encrypted and never serialized.
+	Token           string    `json:"-" gorm:"-"`
+	EncryptedToken  []byte    `json:"-" gorm:"type:blob;not null"`
+	TokenConfigured bool      `json:"token_configured" gorm:"-"`
+	CreatedBy       uuid.UUID `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (c *Connection) TableName() string {
+	return "test_management_connections"
+}
+
+// BeforeCreate hook to generate UUID before creating a new connection
+func (c *Connection) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// Normalize trims the connection's fields.
+func (c *Connection) Normalize() {
+	c.BaseURL = strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	c.Username = strings.TrimSpace(c.Username)
+}
+
+// Validate checks if the connection has valid required fields.
+func (c *Connection) Validate() error {
+	if c.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if !c.Provider.IsValid() {
+		return ErrInvalidProvider
+	}
+	if c.BaseURL != "" || c.Provider == ProviderTestRail {
+		u, err := url.Parse(c.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidBaseURL
+		}
+	}
+	if c.Username == "" {
+		return ErrUsernameRequired
+	}
+	return nil
+}
+
+var (
+	testRailIDPattern = regexp.MustCompile(`^[0-9]+$`)
+	xrayKeyPattern    = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+)
+
+// ValidateLink checks that a link's target and test case identify a run
+// and a case of the connection's provider, and sets the link's provider.
+// TestRail run and case IDs may carry their R and C prefixes, which are
+// removed.
+func (c *Connection) ValidateLink(link *Link) error {
+	if link.TestRunID == uuid.Nil {
+		return ErrInvalidTestRunID
+	}
+	link.Provider = c.Provider
+	link.Target = strings.TrimSpace(link.Target)
+	link.TestCase = strings.TrimSpace(link.TestCase)
+
+	switch c.Provider {
+	case ProviderTestRail:
+		link.Target = strings.TrimPrefix(strings.ToUpper(link.Target), "R")
+		link.TestCase = strings.TrimPrefix(strings.ToUpper(link.TestCase), "C")
+		if !testRailIDPattern.MatchString(link.Target) {
+			return ErrInvalidTarget
+		}
+		if !testRailIDPattern.MatchString(link.TestCase) {
+			return ErrInvalidTestCase
+		}
+	case ProviderXray:
+		link.Target = strings.ToUpper(link.Target)
+		link.TestCase = strings.ToUpper(link.TestCase)
+		if !xrayKeyPattern.MatchString(link.Target) {
+			return ErrInvalidTarget
+		}
+		if !xrayKeyPattern.MatchString(link.TestCase) {
+			return ErrInvalidTestCase
+		}
+	default:
+		return ErrInvalidProvider
+	}
+	return nil
+}
+
+// Link ties a test run to the test case of a TestRail run or of an Xray
+// test execution, the Target, that its result is pushed to.
+type Link struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	TestRunID uuid.UUID `json:"test_run_id" gorm:"type:char(36);not null;index:idx_test_result_links_test_run_id"`
+	Provider  Provider  `json:"provider" gorm:"type:varchar(20);not null"`
+	Target    string    `json:"target" gorm:"type:varchar(255);not null"`
+	TestCase  string    `json:"test_case" gorm:"type:varchar(255);not null"`
+	// LastPushedAt and LastPushError record the last push; the error is
+	// empty if it succeeded. ResultURL is the result it created, when the
+	// provider reports one.
+	LastPushedAt  *time.Time `json:"last_pushed_at,omitempty"`
+	LastPushError string     `json:"last_push_error,omitempty" gorm:"type:varchar(1000);not null;default:''"`
+	ResultURL     string     `json:"result_url,omitempty" gorm:"type:varchar(2048);not null;default:''"`
+	CreatedBy     uuid.UUID  `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (l *Link) TableName() string {
+	return "test_result_links"
+}
+
+// BeforeCreate hook to generate UUID before creating a new link
+func (l *Link) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package testmanagement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnection_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Connection)
+		want   error
+	}{
+		{"valid testrail", func(c *Connection) {}, nil},
+		{"valid xray cloud", func(c *Connection) { c.Provider = ProviderXray; c.BaseURL = "" }, nil},
+		{"missing project", func(c *Connection) { c.ProjectID = uuid.Nil }, ErrInvalidProjectID},
+		{"invalid provider", func(c *Connection) { c.Provider = "zephyr" }, ErrInvalidProvider},
+		{"testrail needs base url", func(c *Connection) { c.BaseURL = "" }, ErrInvalidBaseURL},
+		{"invalid base url", func(c *Connection) { c.BaseURL = "acme.testrail.io" }, ErrInvalidBaseURL},
+		{"missing username", func(c *Connection) { c.Username = " " }, ErrUsernameRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestConnection()
+			tt.modify(conn)
+			conn.Normalize()
+			assert.Equal(t, tt.want, conn.Validate())
+		})
+	}
+}
+
+func TestConnection_ValidateLink(t *testing.T) {
+	runID := uuid.New()
+	testRail := newTestConnection()
+	xray := newTestConnection()
+	xray.Provider = ProviderXray
+
+	tests := []struct {
+		name     string
+		conn     *Connection
+		target   string
+		testCase string
+		want     error
+	}{
+		{"testrail ids", testRail, "12", "345", nil},
+		{"testrail prefixed ids", testRail, "R12", " c345 ", nil},
+		{"testrail invalid run", testRail, "QA-12", "345", ErrInvalidTarget},
+		{"testrail invalid case", testRail, "12", "case", ErrInvalidTestCase},
+		{"xray keys", xray, "qa-10", "QA-7", nil},
+		{"xray invalid execution", xray, "10", "QA-7", ErrInvalidTarget},
+		{"xray invalid test", xray, "QA-10", "7", ErrInvalidTestCase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := &Link{TestRunID: runID, Target: tt.target, TestCase: tt.testCase}
+			assert.Equal(t, tt.want, tt.conn.ValidateLink(link))
+			assert.Equal(t, tt.conn.Provider, link.Provider)
+		})
+	}
+
+	link := &Link{TestRunID: runID, Target: "R12", TestCase: "c345"}
+	require.NoError(t, testRail.ValidateLink(link))
+	assert.Equal(t, "12", link.Target)
+	assert.Equal(t, "345", link.TestCase)
+
+	link = &Link{TestRunID: runID, Target: "qa-10", TestCase: "qa-7"}
+	require.NoError(t, xray.ValidateLink(link))
+	assert.Equal(t, "QA-10", link.Target)
+	assert.Equal(t, "QA-7", link.TestCase)
+
+	assert.Equal(t, ErrInvalidTestRunID, testRail.ValidateLink(&Link{Target: "1", TestCase: "2"}))
+}
+
+func TestNewResult(t *testing.T) {
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	completed := started.Add(95 * time.Second)
+	failedStep := 2
+	sourceID := uuid.New()
+	run := &testrun.TestRun{
+		ID:              uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		Status:          testrun.StatusFailed,
+		Notes:           "Card declined\n",
+		FailedStepIndex: &failedStep,
+		StartedAt:       &started,
+		CompletedAt:     &completed,
+	}
+	assets := []*testrun.TestRunAsset{
+		{ID: sourceID, FileName: "checkout.webm"},
+		{ID: uuid.New(), FileName: "checkout.jpg", SourceAssetID: &sourceID},
+	}
+
+	t.Run("with evidence", func(t *testing.T) {
+		result, err := NewResult(run, assets, "https://qa.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, 95*time.Second, result.Duration())
+		require.Len(t, result.Evidence, 2)
+		assert.Equal(t, "https://qa.example.com/runs/"+run.ID.String(), result.Evidence[0].URL)
+		assert.Equal(t, "https://qa.example.com/api/v1/runs/"+run.ID.String()+"/assets/"+sourceID.String(), result.Evidence[1].URL)
+		assert.Equal(t, "Failed at step 3.\n\nCard declined\n\nEvidence:\n"+
+			"- Test run: https://qa.example.com/runs/"+run.ID.String()+"\n"+
+			"- checkout.webm: https://qa.example.com/api/v1/runs/"+run.ID.String()+"/assets/"+sourceID.String(),
+			result.Comment())
+	})
+
+	t.Run("without a base url", func(t *testing.T) {
+		result, err := NewResult(run, assets, "")
+		require.NoError(t, err)
+		assert.Empty(t, result.Evidence)
+		assert.Equal(t, "Failed at step 3.\n\nCard declined", result.Comment())
+	})
+
+	t.Run("run not completed", func(t *testing.T) {
+		_, err := NewResult(&testrun.TestRun{Status: testrun.StatusRunning}, nil, "")
+		assert.ErrorIs(t, err, ErrRunNotCompleted)
+	})
+}
@@ -0,0 +1,73 @@
+package testmanagement
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// TestRail's built-in result statuses.
+const (
+	testRailStatusPassed  = 1
+	testRailStatusBlocked = 2
+	testRailStatusFailed  = 5
+)
+
+// testRailClient adds results with the TestRail API v2.
+type testRailClient struct {
+	api     *apiClient
+	baseURL string
+}
+
+func newTestRailClient(conn *Connection) *testRailClient {
+	auth := base64.StdEncoding.EncodeToString([]byte(conn.Username + ":" + conn.Token))
+	return &testRailClient{
+		api: newAPIClient(ProviderTestRail, map[string]string{
+			"Authorization": "Basic " + auth,
+		}),
+		baseURL: conn.BaseURL,
+	}
+}
+
+// PushResult adds a result for the case in the run. Skipped runs are
+// reported as blocked, since TestRail does not accept results that leave a
+// test untested.
+func (c *testRailClient) PushResult(ctx context.Context, link *Link, result *Result) (string, error) {
+	body := map[string]interface{}{
+		"status_id": testRailStatus(result.Status),
+		"comment":   result.Comment(),
+	}
+	// TestRail rejects elapsed times under a second.
+	if seconds := int(result.Duration().Seconds()); seconds > 0 {
+		body["elapsed"] = strconv.Itoa(seconds) + "s"
+	}
+
+	var added struct {
+		TestID int `json:"test_id"`
+	}
+	err := c.api.doJSON(ctx, "add result", http.MethodPost,
+		fmt.Sprintf("%s/index.php?/api/v2/add_result_for_case/%s/%s", c.baseURL, link.Target, link.TestCase),
+		nil, body, &added, http.StatusOK)
+	if err != nil {
+		return "", err
+	}
+	if added.TestID == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("%s/index.php?/tests/view/%d", c.baseURL, added.TestID), nil
+}
+
+func testRailStatus(status testrun.Status) int {
+	switch status {
+	case testrun.StatusPassed:
+		return testRailStatusPassed
+	case testrun.StatusFailed:
+		return testRailStatusFailed
+	default:
+		return testRailStatusBlocked
+	}
+}
@@ -0,0 +1,78 @@
+package testmanagement
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testrun"
+)
+
+// xrayClient imports results with the Xray cloud REST API v2.
+type xrayClient struct {
+	api          *apiClient
+	baseURL      string
+	clientID     string
+	clientSecret string
+}
+
+func newXrayClient(conn *Connection) *xrayClient {
+	baseURL := conn.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultXrayBaseURL
+	}
+	return &xrayClient{
+		api:          newAPIClient(ProviderXray, nil),
+		baseURL:      baseURL,
+		clientID:     conn.Username,
+		clientSecret: conn.Token,
+	}
+}
+
+// xrayTest is a test result of an Xray JSON execution import.
+type xrayTest struct {
+	TestKey string `json:"testKey"`
+	Status  string `json:"status"`
+	Comment string `json:"comment,omitempty"`
+	Start   string `json:"start,omitempty"`
+	Finish  string `json:"finish,omitempty"`
+}
+
+// PushResult imports the result of the test into the test execution. Xray
+// has no result URL of its own; the result is on the execution's issue.
+func (c *xrayClient) PushResult(ctx context.Context, link *Link, result *Result) (string, error) {
+	// API clients exchange their credentials for a short-lived token.
+	var token string
+	if err := c.api.doJSON(ctx, "authenticate", http.MethodPost, c.baseURL+"/api/v2/authenticate", nil,
+		map[string]string{"client_id": c.clientID, "client_secret": c.clientSecret}, &token,
+		http.StatusOK); err != nil {
+		return "", err
+	}
+
+	test := xrayTest{
+		TestKey: link.TestCase,
+		Status:  xrayStatus(result.Status),
+		Comment: result.Comment(),
+	}
+	if result.StartedAt != nil && result.CompletedAt != nil {
+		test.Start = result.StartedAt.Format(time.RFC3339)
+		test.Finish = result.CompletedAt.Format(time.RFC3339)
+	}
+	return "", c.api.doJSON(ctx, "import execution", http.MethodPost, c.baseURL+"/api/v2/import/execution",
+		map[string]string{"Authorization": "Bearer " + token},
+		map[string]interface{}{
+			"testExecutionKey": link.Target,
+			"tests":            []xrayTest{test},
+		}, nil, http.StatusOK)
+}
+
+func xrayStatus(status testrun.Status) string {
+	switch status {
+	case testrun.StatusPassed:
+		return "PASSED"
+	case testrun.StatusFailed:
+		return "FAILED"
+	default:
+		return "TODO"
+	}
+}
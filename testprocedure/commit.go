@@ -0,0 +1,45 @@
+package testprocedure
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxCommitMessageLength is the longest commit message a version can have.
+const MaxCommitMessageLength = 255
+
+var (
+	// ErrMissingCommitMessage is returned when a draft is committed without
+	// a message.
+	ErrMissingCommitMessage = errors.New("commit message is required")
+
+	// ErrCommitMessageTooLong is returned when a commit message is longer
+	// than MaxCommitMessageLength.
+	ErrCommitMessageTooLong = errors.New("commit message must be at most 255 characters")
+)
+
+// CommitNote describes the change a committed version makes: a required
+// one-line message and an optional longer summary.
+type CommitNote struct {
+	Message string `json:"message"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// Normalize trims the message and summary.
+func (n CommitNote) Normalize() CommitNote {
+	return CommitNote{
+		Message: strings.TrimSpace(n.Message),
+		Summary: strings.TrimSpace(n.Summary),
+	}
+}
+
+// Validate checks that the note has a message that fits on one line.
+func (n CommitNote) Validate() error {
+	if strings.TrimSpace(n.Message) == "" {
+		return ErrMissingCommitMessage
+	}
+	if len(n.Message) > MaxCommitMessageLength {
+		return ErrCommitMessageTooLong
+	}
+	return nil
+}
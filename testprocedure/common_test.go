@@ -12,7 +12,7 @@ import (
 // setupTestStore creates a test database and test procedure store for testing.
 func setupTestStore(t *testing.T) (*gorm.DB, Store) {
 	db := testutil.SetupTestDB(t)
-	testutil.AutoMigrate(t, db, &TestProcedure{})
+	testutil.AutoMigrate(t, db, &TestProcedure{}, &DraftSnapshot{})
 
 	log := logger.NewTestLogger()
 	store := NewMySQLStore(db, log)
@@ -20,6 +20,24 @@ func setupTestStore(t *testing.T) (*gorm.DB, Store) {
 	return db, store
 }
 
+// setupStepImageStore creates a test database and step image store for
+// testing.
+func setupStepImageStore(t *testing.T) StepImageStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &StepImage{})
+
+	return NewMySQLStepImageStore(db, logger.NewTestLogger())
+}
+
+// setupStepAttachmentStore creates a test database and step attachment
+// store for testing.
+func setupStepAttachmentStore(t *testing.T) StepAttachmentStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &StepAttachment{})
+
+	return NewMySQLStepAttachmentStore(db, logger.NewTestLogger())
+}
+
 // createTestProcedure creates a test procedure with default values.
 func createTestProcedure(name, description string, projectID, createdBy uuid.UUID, steps Steps) *TestProcedure {
 	return &TestProcedure{
@@ -0,0 +1,237 @@
+package testprocedure
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCondition is returned when a step's condition is invalid.
+var ErrInvalidCondition = errors.New("invalid step condition")
+
+// ConditionCheck is what a conditional step checks on the page.
+type ConditionCheck string
+
+const (
+	// ConditionElementExists holds when an element matches Selector.
+	ConditionElementExists ConditionCheck = "element_exists"
+
+	// ConditionElementVisible holds when an element matching Selector is
+	// visible.
+	ConditionElementVisible ConditionCheck = "element_visible"
+
+	// ConditionTextPresent holds when the page shows Text.
+	ConditionTextPresent ConditionCheck = "text_present"
+
+	// ConditionURLContains holds when the page's URL contains Text.
+	ConditionURLContains ConditionCheck = "url_contains"
+)
+
+// IsValid reports whether c is a known condition check.
+func (c ConditionCheck) IsValid() bool {
+	switch c {
+	case ConditionElementExists, ConditionElementVisible, ConditionTextPresent, ConditionURLContains:
+		return true
+	}
+	return false
+}
+
+// StepCondition makes a step a branch point. When the check holds, the
+// ThenSteps steps that follow it are run; otherwise the ElseSteps steps
+// after those are. Either way, the procedure carries on after both
+// branches. Branches are counted in steps rather than nested, so every step
+// keeps its position for notes, assets and analytics.
+type StepCondition struct {
+	Check     ConditionCheck `json:"check"`
+	Selector  string         `json:"selector,omitempty"`
+	Text      string         `json:"text,omitempty"`
+	ThenSteps int            `json:"then_steps"`
+	ElseSteps int            `json:"else_steps,omitempty"`
+}
+
+// String describes the check in plain words, such as
+// `element "#cookie-banner" exists`.
+func (c StepCondition) String() string {
+	switch c.Check {
+	case ConditionElementExists:
+		return fmt.Sprintf("element %q exists", c.Selector)
+	case ConditionElementVisible:
+		return fmt.Sprintf("element %q is visible", c.Selector)
+	case ConditionTextPresent:
+		return fmt.Sprintf("the page shows %q", c.Text)
+	case ConditionURLContains:
+		return fmt.Sprintf("the URL contains %q", c.Text)
+	}
+	return string(c.Check)
+}
+
+// Negation describes the check failing, such as
+// `element "#cookie-banner" does not exist`.
+func (c StepCondition) Negation() string {
+	switch c.Check {
+	case ConditionElementExists:
+		return fmt.Sprintf("element %q does not exist", c.Selector)
+	case ConditionElementVisible:
+		return fmt.Sprintf("element %q is not visible", c.Selector)
+	case ConditionTextPresent:
+		return fmt.Sprintf("the page does not show %q", c.Text)
+	case ConditionURLContains:
+		return fmt.Sprintf("the URL does not contain %q", c.Text)
+	}
+	return "not " + string(c.Check)
+}
+
+// validate checks the condition of the step at index in a procedure of
+// count steps.
+func (c StepCondition) validate(index, count int) error {
+	if !c.Check.IsValid() {
+		return fmt.Errorf("%w: unknown check %q", ErrInvalidCondition, c.Check)
+	}
+	switch c.Check {
+	case ConditionElementExists, ConditionElementVisible:
+		if c.Selector == "" {
+			return fmt.Errorf("%w: %s needs a selector", ErrInvalidCondition, c.Check)
+		}
+	case ConditionTextPresent, ConditionURLContains:
+		if c.Text == "" {
+			return fmt.Errorf("%w: %s needs text", ErrInvalidCondition, c.Check)
+		}
+	}
+	if c.ThenSteps < 1 {
+		return fmt.Errorf("%w: then_steps must be at least 1", ErrInvalidCondition)
+	}
+	if c.ElseSteps < 0 {
+		return fmt.Errorf("%w: else_steps cannot be negative", ErrInvalidCondition)
+	}
+	if index+c.ThenSteps+c.ElseSteps >= count {
+		return fmt.Errorf("%w: branches run past the last step", ErrInvalidCondition)
+	}
+	return nil
+}
+
+// Branch is the part of a procedure a conditional step controls. The
+// positions are indexes into the procedure's steps, and Then and Else are
+// inclusive ranges; Else is empty when ElseFrom is past ElseTo.
+type Branch struct {
+	Step      int
+	Condition StepCondition
+	ThenFrom  int
+	ThenTo    int
+	ElseFrom  int
+	ElseTo    int
+}
+
+// branchOf returns the branches of the conditional step at index i.
+func branchOf(i int, c *StepCondition) Branch {
+	return Branch{
+		Step:      i,
+		Condition: *c,
+		ThenFrom:  i + 1,
+		ThenTo:    i + c.ThenSteps,
+		ElseFrom:  i + c.ThenSteps + 1,
+		ElseTo:    i + c.ThenSteps + c.ElseSteps,
+	}
+}
+
+// End returns the index of the last step of the branches.
+func (b Branch) End() int {
+	return b.ElseTo
+}
+
+// Contains reports whether the step at index i is in the branches, and if
+// so whether it is in the then branch.
+func (b Branch) Contains(i int) (inThen, ok bool) {
+	if i < b.ThenFrom || i > b.End() {
+		return false, false
+	}
+	return i <= b.ThenTo, true
+}
+
+// String describes the branches in plain words, with steps numbered from
+// 1, such as `if element "#cookie-banner" exists, do steps 4-5, otherwise
+// do step 6`.
+func (b Branch) String() string {
+	if b.ElseFrom > b.ElseTo {
+		return fmt.Sprintf("if %s, do %s, otherwise skip them", b.Condition, stepRange(b.ThenFrom, b.ThenTo))
+	}
+	return fmt.Sprintf("if %s, do %s, otherwise do %s", b.Condition, stepRange(b.ThenFrom, b.ThenTo), stepRange(b.ElseFrom, b.ElseTo))
+}
+
+// stepRange names the steps from index from to index to, numbered from 1.
+func stepRange(from, to int) string {
+	if from == to {
+		return fmt.Sprintf("step %d", from+1)
+	}
+	return fmt.Sprintf("steps %d-%d", from+1, to+1)
+}
+
+// Branches returns the branches of the conditional steps, in step order.
+func (s Steps) Branches() []Branch {
+	var branches []Branch
+	for i, step := range s {
+		if step.Condition != nil {
+			branches = append(branches, branchOf(i, step.Condition))
+		}
+	}
+	return branches
+}
+
+// ValidateConditions checks the conditions of the steps: each must have a
+// known check with what it needs, its branches must fit within the steps,
+// and a conditional step inside a branch must end within that branch. A
+// step group reference cannot be conditional, but may sit in a branch.
+// Returns ErrInvalidCondition, with the step's position, otherwise.
+func (s Steps) ValidateConditions() error {
+	for i, step := range s {
+		if step.Condition == nil {
+			continue
+		}
+		if step.IsGroupReference() {
+			return fmt.Errorf("step %d: %w: a step group reference cannot be conditional", i+1, ErrInvalidCondition)
+		}
+		if err := step.Condition.validate(i, len(s)); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	branches := s.Branches()
+	for _, outer := range branches {
+		for _, inner := range branches {
+			if inner.Step <= outer.Step || inner.Step > outer.End() {
+				continue
+			}
+			limit := outer.ThenTo
+			if inner.Step > outer.ThenTo {
+				limit = outer.ElseTo
+			}
+			if inner.End() > limit {
+				return fmt.Errorf("step %d: %w: branches must end within the branch of step %d", inner.Step+1, ErrInvalidCondition, outer.Step+1)
+			}
+		}
+	}
+	return nil
+}
+
+// Regroup returns s with each step replaced by the steps expand returns for
+// it, and the branches of conditional steps resized to cover what their
+// steps were replaced by. Conditional steps must expand to themselves.
+func (s Steps) Regroup(expand func(step TestStep) Steps) Steps {
+	starts := make([]int, len(s)+1)
+	out := make(Steps, 0, len(s))
+	for i, step := range s {
+		starts[i] = len(out)
+		out = append(out, expand(step)...)
+	}
+	starts[len(s)] = len(out)
+
+	for _, b := range s.Branches() {
+		at := starts[b.Step]
+		if at >= len(out) || out[at].Condition == nil {
+			continue
+		}
+		cond := *out[at].Condition
+		cond.ThenSteps = starts[b.ThenTo+1] - starts[b.ThenFrom]
+		cond.ElseSteps = starts[b.ElseTo+1] - starts[b.ElseFrom]
+		out[at].Condition = &cond
+	}
+	return out
+}
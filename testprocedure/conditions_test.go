@@ -0,0 +1,148 @@
+package testprocedure
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conditional returns a step that checks for an element and branches.
+func conditional(name string, thenSteps, elseSteps int) TestStep {
+	return TestStep{Name: name, Condition: &StepCondition{
+		Check:     ConditionElementExists,
+		Selector:  "#cookie-banner",
+		ThenSteps: thenSteps,
+		ElseSteps: elseSteps,
+	}}
+}
+
+func TestSteps_ValidateConditions(t *testing.T) {
+	groupID := uuid.New()
+
+	tests := []struct {
+		name    string
+		steps   Steps
+		wantErr bool
+	}{
+		{
+			name:  "if and else",
+			steps: Steps{conditional("Banner shown?", 2, 1), {Name: "Accept"}, {Name: "Close"}, {Name: "Continue"}, {Name: "Log in"}},
+		},
+		{
+			name:  "if without else",
+			steps: Steps{conditional("Banner shown?", 1, 0), {Name: "Accept"}},
+		},
+		{
+			name:  "nested within a branch",
+			steps: Steps{conditional("Outer", 3, 0), conditional("Inner", 1, 1), {Name: "A"}, {Name: "B"}},
+		},
+		{
+			name:  "group reference in a branch",
+			steps: Steps{conditional("Logged out?", 1, 0), {Name: "Log in", GroupID: &groupID}},
+		},
+		{
+			name:    "unknown check",
+			steps:   Steps{{Name: "Check", Condition: &StepCondition{Check: "cookie_set", ThenSteps: 1}}, {Name: "A"}},
+			wantErr: true,
+		},
+		{
+			name:    "element check without selector",
+			steps:   Steps{{Name: "Check", Condition: &StepCondition{Check: ConditionElementVisible, ThenSteps: 1}}, {Name: "A"}},
+			wantErr: true,
+		},
+		{
+			name:    "text check without text",
+			steps:   Steps{{Name: "Check", Condition: &StepCondition{Check: ConditionTextPresent, ThenSteps: 1}}, {Name: "A"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty then branch",
+			steps:   Steps{conditional("Check", 0, 1), {Name: "A"}},
+			wantErr: true,
+		},
+		{
+			name:    "branches past the last step",
+			steps:   Steps{conditional("Check", 1, 1), {Name: "A"}},
+			wantErr: true,
+		},
+		{
+			name:    "nested branches overlap",
+			steps:   Steps{conditional("Outer", 2, 1), conditional("Inner", 1, 1), {Name: "A"}, {Name: "B"}},
+			wantErr: true,
+		},
+		{
+			name:    "conditional group reference",
+			steps:   Steps{{Name: "Log in", GroupID: &groupID, Condition: &StepCondition{Check: ConditionURLContains, Text: "/login", ThenSteps: 1}}, {Name: "A"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.steps.ValidateConditions()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCondition)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSteps_Branches(t *testing.T) {
+	steps := Steps{{Name: "Open app"}, conditional("Banner shown?", 2, 1), {Name: "Accept"}, {Name: "Close"}, {Name: "Continue"}}
+
+	branches := steps.Branches()
+	require.Len(t, branches, 1)
+	b := branches[0]
+	assert.Equal(t, `if element "#cookie-banner" exists, do steps 3-4, otherwise do step 5`, b.String())
+
+	inThen, ok := b.Contains(3)
+	assert.True(t, ok)
+	assert.True(t, inThen)
+	inThen, ok = b.Contains(4)
+	assert.True(t, ok)
+	assert.False(t, inThen)
+	_, ok = b.Contains(0)
+	assert.False(t, ok)
+}
+
+func TestSteps_Regroup(t *testing.T) {
+	groupID := uuid.New()
+	steps := Steps{
+		conditional("Logged out?", 2, 1),
+		{Name: "Log in", GroupID: &groupID},
+		{Name: "Dismiss welcome"},
+		{Name: "Open dashboard"},
+		{Name: "Check widgets"},
+	}
+
+	regrouped := steps.Regroup(func(step TestStep) Steps {
+		if step.IsGroupReference() {
+			return Steps{{Name: "Open login page"}, {Name: "Enter password"}, {Name: "Submit"}}
+		}
+		return Steps{step}
+	})
+
+	require.Len(t, regrouped, 7)
+	assert.Equal(t, 4, regrouped[0].Condition.ThenSteps)
+	assert.Equal(t, 1, regrouped[0].Condition.ElseSteps)
+	assert.NoError(t, regrouped.ValidateConditions())
+	assert.Equal(t, 2, steps[0].Condition.ThenSteps, "the original steps are unchanged")
+}
+
+func TestTestProcedure_ConditionPlaceholders(t *testing.T) {
+	tp := &TestProcedure{
+		Steps: Steps{
+			{Name: "Greeted?", Condition: &StepCondition{Check: ConditionTextPresent, Text: "Hello {{username}}", ThenSteps: 1}},
+			{Name: "Continue"},
+		},
+	}
+
+	assert.Equal(t, []string{"username"}, tp.Placeholders())
+	filled := tp.WithParameters(ParameterValues{"username": "alice"})
+	assert.Equal(t, "Hello alice", filled.Steps[0].Condition.Text)
+	assert.Equal(t, "Hello {{username}}", tp.Steps[0].Condition.Text)
+}
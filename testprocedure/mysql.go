@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/markdown"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +39,9 @@ func (s *MySQLStore) Create(ctx context.Context, testProcedure *TestProcedure) e
 	testProcedure.ID = result.ID
 	testProcedure.Version = result.Version
 	testProcedure.IsLatest = result.IsLatest
+	testProcedure.NeedsReview = result.NeedsReview
+	testProcedure.Parameters = result.Parameters
+	testProcedure.Preconditions = result.Preconditions
 	testProcedure.CreatedAt = result.CreatedAt
 	testProcedure.UpdatedAt = result.UpdatedAt
 
@@ -69,7 +75,8 @@ func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...Update
 	return s.UpdateDraft(ctx, id, setters...)
 }
 
-// Delete deletes all versions of a test procedure chain (hard delete due to CASCADE).
+// Delete soft-deletes all versions of a test procedure chain. The rows are
+// kept so that runs of the procedure, and their snapshots, survive.
 func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
 	proc, err := s.GetByID(ctx, id)
 	if err != nil {
@@ -105,10 +112,72 @@ func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-// ListByProject retrieves a paginated list of latest test procedures for a specific project.
+// Archive archives a test procedure and all of its versions. Archiving an
+// archived procedure keeps when it was first archived.
+func (s *MySQLStore) Archive(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return s.setArchived(ctx, id, &now)
+}
+
+// Unarchive restores an archived test procedure and all of its versions.
+func (s *MySQLStore) Unarchive(ctx context.Context, id uuid.UUID) error {
+	return s.setArchived(ctx, id, nil)
+}
+
+// setArchived sets when every version of a test procedure was archived, or
+// clears it when archivedAt is nil.
+func (s *MySQLStore) setArchived(ctx context.Context, id uuid.UUID, archivedAt *time.Time) error {
+	proc, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	rootID := id
+	if proc.ParentID != nil {
+		rootID = *proc.ParentID
+	}
+
+	query := s.db.WithContext(ctx).
+		Model(&TestProcedure{}).
+		Where("id = ? OR parent_id = ?", rootID, rootID)
+	if archivedAt != nil {
+		query = query.Where("archived_at IS NULL")
+	}
+	if err := query.Update("archived_at", archivedAt).Error; err != nil {
+		s.logger.Error(ctx, "failed to set test procedure archived state", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": id.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "test procedure archived state set", map[string]interface{}{
+		"test_procedure_id": id.String(),
+		"root_id":           rootID.String(),
+		"archived":          archivedAt != nil,
+	})
+
+	return nil
+}
+
+// ListByProject retrieves a paginated list of latest test procedures for a
+// specific project, leaving out archived ones.
 func (s *MySQLStore) ListByProject(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]*TestProcedure, error) {
+	return s.ListByProjectFiltered(ctx, projectID, ListFilter{}, limit, offset)
+}
+
+// CountByProject returns the total count of latest test procedures for a
+// specific project, leaving out archived ones.
+func (s *MySQLStore) CountByProject(ctx context.Context, projectID uuid.UUID) (int, error) {
+	return s.CountByProjectFiltered(ctx, projectID, ListFilter{})
+}
+
+// ListByProjectFiltered retrieves a paginated list of latest test procedures for a
+// specific project that match the filter.
+func (s *MySQLStore) ListByProjectFiltered(ctx context.Context, projectID uuid.UUID, filter ListFilter, limit, offset int) ([]*TestProcedure, error) {
 	var testProcedures []*TestProcedure
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica, filter.scope).
 		Where("project_id = ? AND is_latest = ?", projectID, true).
 		Order("created_at DESC").
 		Limit(limit).
@@ -128,10 +197,12 @@ func (s *MySQLStore) ListByProject(ctx context.Context, projectID uuid.UUID, lim
 	return testProcedures, nil
 }
 
-// CountByProject returns the total count of latest test procedures for a specific project.
-func (s *MySQLStore) CountByProject(ctx context.Context, projectID uuid.UUID) (int, error) {
+// CountByProjectFiltered returns the total count of latest test procedures for a
+// specific project that match the filter.
+func (s *MySQLStore) CountByProjectFiltered(ctx context.Context, projectID uuid.UUID, filter ListFilter) (int, error) {
 	var count int64
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica, filter.scope).
 		Model(&TestProcedure{}).
 		Where("project_id = ? AND is_latest = ?", projectID, true).
 		Count(&count).Error
@@ -187,14 +258,18 @@ func (s *MySQLStore) CreateVersion(ctx context.Context, originalID uuid.UUID) (*
 
 		// 5. Create new record with version=max+1, is_latest=true, parent_id=root
 		newVersion = &TestProcedure{
-			ProjectID:   original.ProjectID,
-			Name:        original.Name,
-			Description: original.Description,
-			Steps:       original.Steps,
-			CreatedBy:   original.CreatedBy,
-			Version:     maxVersion + 1,
-			IsLatest:    true,
-			ParentID:    &rootID,
+			ProjectID:     original.ProjectID,
+			Name:          original.Name,
+			Description:   original.Description,
+			Steps:         original.Steps,
+			CreatedBy:     original.CreatedBy,
+			NeedsReview:   original.NeedsReview,
+			Parameters:    original.Parameters,
+			Preconditions: original.Preconditions,
+			ArchivedAt:    original.ArchivedAt,
+			Version:       maxVersion + 1,
+			IsLatest:      true,
+			ParentID:      &rootID,
 		}
 
 		if err := tx.WithContext(ctx).Create(newVersion).Error; err != nil {
@@ -322,6 +397,8 @@ func (s *MySQLStore) GetLatestCommitted(ctx context.Context, procedureID uuid.UU
 }
 
 // CreateWithDraft creates both a committed version (v1) and a draft (v0).
+// What is unsafe to show is removed from the Markdown of the description
+// and step instructions first.
 func (s *MySQLStore) CreateWithDraft(ctx context.Context, tp *TestProcedure) (*TestProcedure, error) {
 	if err := tp.Validate(); err != nil {
 		return nil, err
@@ -333,14 +410,17 @@ func (s *MySQLStore) CreateWithDraft(ctx context.Context, tp *TestProcedure) (*T
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Create v1 (committed version)
 		v1 = &TestProcedure{
-			ProjectID:   tp.ProjectID,
-			Name:        tp.Name,
-			Description: tp.Description,
-			Steps:       tp.Steps,
-			CreatedBy:   tp.CreatedBy,
-			Version:     1,
-			IsLatest:    true,
-			ParentID:    nil,
+			ProjectID:     tp.ProjectID,
+			Name:          tp.Name,
+			Description:   markdown.Sanitize(tp.Description),
+			Steps:         tp.Steps.SanitizeInstructions(),
+			CreatedBy:     tp.CreatedBy,
+			NeedsReview:   tp.NeedsReview,
+			Parameters:    tp.Parameters,
+			Preconditions: tp.Preconditions,
+			Version:       1,
+			IsLatest:      true,
+			ParentID:      nil,
 		}
 
 		if err := tx.WithContext(ctx).Create(v1).Error; err != nil {
@@ -349,14 +429,17 @@ func (s *MySQLStore) CreateWithDraft(ctx context.Context, tp *TestProcedure) (*T
 
 		// Clone to v0 (draft version)
 		v0 := &TestProcedure{
-			ProjectID:   v1.ProjectID,
-			Name:        v1.Name,
-			Description: v1.Description,
-			Steps:       v1.Steps,
-			CreatedBy:   v1.CreatedBy,
-			Version:     0,
-			IsLatest:    false,
-			ParentID:    &v1.ID,
+			ProjectID:     v1.ProjectID,
+			Name:          v1.Name,
+			Description:   v1.Description,
+			Steps:         v1.Steps,
+			CreatedBy:     v1.CreatedBy,
+			NeedsReview:   v1.NeedsReview,
+			Parameters:    v1.Parameters,
+			Preconditions: v1.Preconditions,
+			Version:       0,
+			IsLatest:      false,
+			ParentID:      &v1.ID,
 		}
 
 		if err := tx.WithContext(ctx).Create(v0).Error; err != nil {
@@ -393,14 +476,21 @@ func (s *MySQLStore) UpdateDraft(ctx context.Context, procedureID uuid.UUID, set
 		if err != nil {
 			return err
 		}
+		before := snapshotDraft(draft)
 
 		for _, setter := range setters {
 			if err := setter(draft); err != nil {
 				return err
 			}
 		}
+		if err := draft.validateParameters(); err != nil {
+			return err
+		}
 
-		if err := tx.WithContext(ctx).Save(draft).Error; err != nil {
+		if err := s.saveDraftWithTx(ctx, tx, draft); err != nil {
+			return err
+		}
+		if err := s.keepSnapshotWithTx(ctx, tx, before, draft); err != nil {
 			return err
 		}
 
@@ -408,6 +498,9 @@ func (s *MySQLStore) UpdateDraft(ctx context.Context, procedureID uuid.UUID, set
 		return nil
 	})
 
+	if errors.Is(err, ErrDraftConflict) {
+		return err
+	}
 	if err != nil {
 		s.logger.Error(ctx, "failed to update draft", map[string]interface{}{
 			"error":        err.Error(),
@@ -438,12 +531,19 @@ func (s *MySQLStore) ResetDraft(ctx context.Context, procedureID uuid.UUID) erro
 		if err != nil {
 			return err
 		}
+		before := snapshotDraft(draft)
 
 		draft.Name = committed.Name
 		draft.Description = committed.Description
 		draft.Steps = committed.Steps
+		draft.NeedsReview = committed.NeedsReview
+		draft.Parameters = committed.Parameters
+		draft.Preconditions = committed.Preconditions
 
-		if err := tx.WithContext(ctx).Save(draft).Error; err != nil {
+		if err := s.saveDraftWithTx(ctx, tx, draft); err != nil {
+			return err
+		}
+		if err := s.keepSnapshotWithTx(ctx, tx, before, draft); err != nil {
 			return err
 		}
 
@@ -468,7 +568,24 @@ func (s *MySQLStore) ResetDraft(ctx context.Context, procedureID uuid.UUID) erro
 }
 
 // CommitDraft creates a new committed version from the draft, incrementing version number.
-func (s *MySQLStore) CommitDraft(ctx context.Context, procedureID uuid.UUID) (*TestProcedure, error) {
+func (s *MySQLStore) CommitDraft(ctx context.Context, procedureID uuid.UUID, note CommitNote) (*TestProcedure, error) {
+	return s.commitDraft(ctx, procedureID, note, true)
+}
+
+// CommitDraftPending creates a new committed version from the draft without
+// making it the latest version.
+func (s *MySQLStore) CommitDraftPending(ctx context.Context, procedureID uuid.UUID, note CommitNote) (*TestProcedure, error) {
+	return s.commitDraft(ctx, procedureID, note, false)
+}
+
+// commitDraft creates a new committed version from the draft, described by
+// note. If publish is set, the new version becomes the latest of its chain.
+func (s *MySQLStore) commitDraft(ctx context.Context, procedureID uuid.UUID, note CommitNote, publish bool) (*TestProcedure, error) {
+	note = note.Normalize()
+	if err := note.Validate(); err != nil {
+		return nil, err
+	}
+
 	var newVersion *TestProcedure
 
 	// Execute in transaction
@@ -496,11 +613,13 @@ func (s *MySQLStore) CommitDraft(ctx context.Context, procedureID uuid.UUID) (*T
 		}
 
 		// Mark all versions in chain as is_latest=false
-		if err := tx.WithContext(ctx).
-			Model(&TestProcedure{}).
-			Where("(id = ? OR parent_id = ?) AND version >= ?", rootID, rootID, 1).
-			Update("is_latest", false).Error; err != nil {
-			return fmt.Errorf("failed to update is_latest flags: %w", err)
+		if publish {
+			if err := tx.WithContext(ctx).
+				Model(&TestProcedure{}).
+				Where("(id = ? OR parent_id = ?) AND version >= ?", rootID, rootID, 1).
+				Update("is_latest", false).Error; err != nil {
+				return fmt.Errorf("failed to update is_latest flags: %w", err)
+			}
 		}
 
 		// Find max version number in chain
@@ -516,14 +635,20 @@ func (s *MySQLStore) CommitDraft(ctx context.Context, procedureID uuid.UUID) (*T
 
 		// Create new committed version from draft
 		newVersion = &TestProcedure{
-			ProjectID:   draft.ProjectID,
-			Name:        draft.Name,
-			Description: draft.Description,
-			Steps:       draft.Steps,
-			CreatedBy:   draft.CreatedBy,
-			Version:     maxVersion + 1,
-			IsLatest:    true,
-			ParentID:    &rootID,
+			ProjectID:     draft.ProjectID,
+			Name:          draft.Name,
+			Description:   draft.Description,
+			Steps:         draft.Steps,
+			CreatedBy:     draft.CreatedBy,
+			NeedsReview:   draft.NeedsReview,
+			Parameters:    draft.Parameters,
+			Preconditions: draft.Preconditions,
+			ArchivedAt:    draft.ArchivedAt,
+			Version:       maxVersion + 1,
+			IsLatest:      publish,
+			ParentID:      &rootID,
+			CommitMessage: note.Message,
+			ChangeSummary: note.Summary,
 		}
 
 		if err := tx.WithContext(ctx).Create(newVersion).Error; err != nil {
@@ -545,11 +670,212 @@ func (s *MySQLStore) CommitDraft(ctx context.Context, procedureID uuid.UUID) (*T
 		"procedure_id":   procedureID.String(),
 		"new_version_id": newVersion.ID.String(),
 		"version":        newVersion.Version,
+		"published":      publish,
 	})
 
 	return newVersion, nil
 }
 
+// PublishVersion makes a committed version the latest version of its chain.
+func (s *MySQLStore) PublishVersion(ctx context.Context, versionID uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := s.getByIDWithTx(ctx, tx, versionID)
+		if err != nil {
+			return err
+		}
+		if version.Version < 1 || version.ParentID == nil {
+			return ErrNoCommittedVersion
+		}
+		rootID := *version.ParentID
+
+		if err := tx.WithContext(ctx).
+			Model(&TestProcedure{}).
+			Where("(id = ? OR parent_id = ?) AND version >= ?", rootID, rootID, 1).
+			Update("is_latest", false).Error; err != nil {
+			return fmt.Errorf("failed to update is_latest flags: %w", err)
+		}
+
+		return tx.WithContext(ctx).
+			Model(&TestProcedure{}).
+			Where("id = ?", versionID).
+			Update("is_latest", true).Error
+	})
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to publish version", map[string]interface{}{
+			"error":      err.Error(),
+			"version_id": versionID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "version published", map[string]interface{}{
+		"version_id": versionID.String(),
+	})
+
+	return nil
+}
+
+// ListByStepGroup retrieves the drafts and latest committed versions of the
+// procedures in a project whose steps reference a step group.
+func (s *MySQLStore) ListByStepGroup(ctx context.Context, projectID, groupID uuid.UUID) ([]*TestProcedure, error) {
+	// Steps are JSON; narrow the candidates by the group's ID and then check
+	// the references themselves
+	var candidates []*TestProcedure
+	err := s.db.WithContext(ctx).
+		Where("project_id = ? AND (version = ? OR is_latest = ?)", projectID, 0, true).
+		Where("steps LIKE ?", "%"+groupID.String()+"%").
+		Order("created_at ASC").
+		Find(&candidates).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list test procedures by step group", map[string]interface{}{
+			"error":         err.Error(),
+			"project_id":    projectID.String(),
+			"step_group_id": groupID.String(),
+		})
+		return nil, err
+	}
+
+	procedures := make([]*TestProcedure, 0, len(candidates))
+	for _, tp := range candidates {
+		for _, step := range tp.Steps {
+			if step.GroupID != nil && *step.GroupID == groupID {
+				procedures = append(procedures, tp)
+				break
+			}
+		}
+	}
+	return procedures, nil
+}
+
+// saveDraftWithTx saves a draft read in the transaction as its next
+// revision. It returns ErrDraftConflict if another update saved the draft
+// since it was read.
+func (s *MySQLStore) saveDraftWithTx(ctx context.Context, tx *gorm.DB, draft *TestProcedure) error {
+	read := draft.Revision
+	draft.Revision++
+	result := tx.WithContext(ctx).
+		Model(draft).
+		Where("revision = ?", read).
+		Select("*").
+		Updates(draft)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDraftConflict
+	}
+	return nil
+}
+
+// keepSnapshotWithTx keeps before, the draft as it was read in the
+// transaction, if saving it as draft changed its content, and removes the
+// snapshots beyond the last MaxDraftSnapshots.
+func (s *MySQLStore) keepSnapshotWithTx(ctx context.Context, tx *gorm.DB, before *DraftSnapshot, draft *TestProcedure) error {
+	if before.sameContent(snapshotDraft(draft)) {
+		return nil
+	}
+	if err := tx.WithContext(ctx).Create(before).Error; err != nil {
+		return fmt.Errorf("failed to create draft snapshot: %w", err)
+	}
+
+	var stale []uuid.UUID
+	if err := tx.WithContext(ctx).
+		Model(&DraftSnapshot{}).
+		Where("draft_id = ?", draft.ID).
+		Order("revision DESC").
+		Offset(MaxDraftSnapshots).
+		Limit(-1).
+		Pluck("id", &stale).Error; err != nil {
+		return fmt.Errorf("failed to find old draft snapshots: %w", err)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	if err := tx.WithContext(ctx).Where("id IN ?", stale).Delete(&DraftSnapshot{}).Error; err != nil {
+		return fmt.Errorf("failed to remove old draft snapshots: %w", err)
+	}
+	return nil
+}
+
+// ListDraftSnapshots retrieves the snapshots of a procedure's draft, newest
+// first.
+func (s *MySQLStore) ListDraftSnapshots(ctx context.Context, procedureID uuid.UUID) ([]*DraftSnapshot, error) {
+	draft, err := s.getDraftWithTx(ctx, s.db, procedureID)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []*DraftSnapshot
+	err = s.db.WithContext(ctx).
+		Where("draft_id = ?", draft.ID).
+		Order("revision DESC").
+		Find(&snapshots).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to list draft snapshots", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+		})
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// RestoreDraftSnapshot sets the content of a procedure's draft back to a
+// snapshot of it, saving it as its next revision. The content it replaces
+// is kept as a snapshot in turn, so a restore can be undone too.
+func (s *MySQLStore) RestoreDraftSnapshot(ctx context.Context, procedureID, snapshotID uuid.UUID) (*TestProcedure, error) {
+	var draft *TestProcedure
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		draft, err = s.getDraftWithTx(ctx, tx, procedureID)
+		if err != nil {
+			return err
+		}
+
+		var snapshot DraftSnapshot
+		err = tx.WithContext(ctx).
+			Where("id = ? AND draft_id = ?", snapshotID, draft.ID).
+			First(&snapshot).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDraftSnapshotNotFound
+			}
+			return err
+		}
+
+		before := snapshotDraft(draft)
+		snapshot.apply(draft)
+		if err := s.saveDraftWithTx(ctx, tx, draft); err != nil {
+			return err
+		}
+		return s.keepSnapshotWithTx(ctx, tx, before, draft)
+	})
+
+	if errors.Is(err, ErrDraftSnapshotNotFound) || errors.Is(err, ErrDraftNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.Error(ctx, "failed to restore draft snapshot", map[string]interface{}{
+			"error":        err.Error(),
+			"procedure_id": procedureID.String(),
+			"snapshot_id":  snapshotID.String(),
+		})
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "draft restored from snapshot", map[string]interface{}{
+		"procedure_id": procedureID.String(),
+		"snapshot_id":  snapshotID.String(),
+		"revision":     draft.Revision,
+	})
+
+	return draft, nil
+}
+
 // getDraftWithTx is a helper to get draft within a transaction.
 func (s *MySQLStore) getDraftWithTx(ctx context.Context, tx *gorm.DB, procedureID uuid.UUID) (*TestProcedure, error) {
 	// First get the procedure to determine root ID
@@ -2,6 +2,8 @@ package testprocedure
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -68,6 +70,28 @@ func TestMySQLStore_Create(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "step")
 	})
+
+	t.Run("unsafe markdown is removed", func(t *testing.T) {
+		steps := Steps{
+			{Name: "Step 1", Instructions: "Click **Save**<script>alert(1)</script>"},
+		}
+		tp := createTestProcedure("Sanitized", "See [docs](javascript:alert(1))", uuid.New(), uuid.New(), steps)
+		require.NoError(t, store.Create(ctx, tp))
+
+		draft, err := store.GetDraft(ctx, tp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "See [docs](#)", draft.Description)
+		assert.Equal(t, "Click **Save**", draft.Steps[0].Instructions)
+
+		err = store.UpdateDraft(ctx, tp.ID, SetDescription(`<iframe src="https://example.com"></iframe>Intro`), SetSteps(Steps{
+			{Name: "Step 1", Instructions: `<img src="x.png" onerror="alert(1)">`},
+		}))
+		require.NoError(t, err)
+		draft, err = store.GetDraft(ctx, tp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Intro", draft.Description)
+		assert.Equal(t, `<img src="x.png">`, draft.Steps[0].Instructions)
+	})
 }
 
 func TestMySQLStore_GetByID(t *testing.T) {
@@ -129,7 +153,7 @@ func TestMySQLStore_Update(t *testing.T) {
 }
 
 func TestMySQLStore_Delete(t *testing.T) {
-	_, store := setupTestStore(t)
+	db, store := setupTestStore(t)
 	ctx := context.Background()
 
 	t.Run("delete existing test procedure", func(t *testing.T) {
@@ -145,12 +169,101 @@ func TestMySQLStore_Delete(t *testing.T) {
 		assert.ErrorIs(t, err, ErrTestProcedureNotFound)
 	})
 
+	t.Run("delete keeps rows of every version", func(t *testing.T) {
+		tp := createTestProcedure("Versioned", "Description", uuid.New(), uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, tp))
+		v1, err := store.CreateVersion(ctx, tp.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Delete(ctx, tp.ID))
+
+		_, err = store.GetByID(ctx, v1.ID)
+		assert.ErrorIs(t, err, ErrTestProcedureNotFound)
+
+		var kept []TestProcedure
+		require.NoError(t, db.Unscoped().Where("id IN ?", []uuid.UUID{tp.ID, v1.ID}).Find(&kept).Error)
+		require.Len(t, kept, 2)
+		for _, p := range kept {
+			assert.True(t, p.DeletedAt.Valid)
+		}
+	})
+
 	t.Run("delete non-existent returns error", func(t *testing.T) {
 		err := store.Delete(ctx, uuid.New())
 		assert.ErrorIs(t, err, ErrTestProcedureNotFound)
 	})
 }
 
+func TestMySQLStore_Archive(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	createdBy := uuid.New()
+
+	kept := createTestProcedure("Kept", "Description", projectID, createdBy, nil)
+	require.NoError(t, store.Create(ctx, kept))
+	tp := createTestProcedure("Archived", "Description", projectID, createdBy, nil)
+	created, err := store.CreateWithDraft(ctx, tp)
+	require.NoError(t, err)
+	v2, err := store.CommitDraft(ctx, created.ID, CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Archive(ctx, v2.ID))
+
+	t.Run("every version is archived", func(t *testing.T) {
+		for _, id := range []uuid.UUID{created.ID, v2.ID} {
+			p, err := store.GetByID(ctx, id)
+			require.NoError(t, err)
+			assert.True(t, p.IsArchived())
+		}
+		draft, err := store.GetDraft(ctx, created.ID)
+		require.NoError(t, err)
+		assert.True(t, draft.IsArchived())
+	})
+
+	t.Run("archived procedures are left out of lists", func(t *testing.T) {
+		procedures, err := store.ListByProject(ctx, projectID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, procedures, 1)
+		assert.Equal(t, "Kept", procedures[0].Name)
+
+		count, err := store.CountByProjectFiltered(ctx, projectID, ListFilter{IncludeArchived: true})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("new versions stay archived", func(t *testing.T) {
+		v3, err := store.CommitDraft(ctx, created.ID, CommitNote{Message: "Update procedure"})
+		require.NoError(t, err)
+		assert.True(t, v3.IsArchived())
+	})
+
+	t.Run("archiving again keeps the first time", func(t *testing.T) {
+		before, err := store.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		require.NoError(t, store.Archive(ctx, created.ID))
+		after, err := store.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.True(t, before.ArchivedAt.Equal(*after.ArchivedAt))
+	})
+
+	t.Run("unarchive restores every version", func(t *testing.T) {
+		require.NoError(t, store.Unarchive(ctx, created.ID))
+
+		latest, err := store.GetLatestCommitted(ctx, created.ID)
+		require.NoError(t, err)
+		assert.False(t, latest.IsArchived())
+
+		count, err := store.CountByProject(ctx, projectID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("archive non-existent returns error", func(t *testing.T) {
+		assert.ErrorIs(t, store.Archive(ctx, uuid.New()), ErrTestProcedureNotFound)
+	})
+}
+
 func TestMySQLStore_ListByProject(t *testing.T) {
 	_, store := setupTestStore(t)
 	ctx := context.Background()
@@ -181,7 +294,7 @@ func TestMySQLStore_ListByProject(t *testing.T) {
 		require.NoError(t, store.Create(ctx, tp))
 
 		// Commit draft to create version 2
-		_, err := store.CommitDraft(ctx, tp.ID)
+		_, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "Update procedure"})
 		require.NoError(t, err)
 
 		procedures, err := store.ListByProject(ctx, projectID, 10, 0)
@@ -219,6 +332,50 @@ func TestMySQLStore_ListByProject(t *testing.T) {
 	})
 }
 
+func TestMySQLStore_ListByProjectFiltered(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	createdBy := uuid.New()
+
+	login := createTestProcedure("Login", "Description", projectID, createdBy, nil)
+	require.NoError(t, store.Create(ctx, login))
+	_, err := store.CommitDraft(ctx, login.ID, CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+	checkout := createTestProcedure("Checkout", "Description", projectID, createdBy, nil)
+	require.NoError(t, store.Create(ctx, checkout))
+
+	t.Run("filter by root matches the latest version", func(t *testing.T) {
+		filter := ListFilter{RootIDs: []uuid.UUID{login.ID}}
+		procedures, err := store.ListByProjectFiltered(ctx, projectID, filter, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, procedures, 1)
+		assert.Equal(t, "Login", procedures[0].Name)
+		assert.Equal(t, uint(2), procedures[0].Version)
+
+		count, err := store.CountByProjectFiltered(ctx, projectID, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("empty roots match nothing", func(t *testing.T) {
+		filter := ListFilter{RootIDs: []uuid.UUID{}}
+		procedures, err := store.ListByProjectFiltered(ctx, projectID, filter, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, procedures)
+
+		count, err := store.CountByProjectFiltered(ctx, projectID, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("zero filter matches everything", func(t *testing.T) {
+		count, err := store.CountByProjectFiltered(ctx, projectID, ListFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
+
 func TestMySQLStore_CreateVersion(t *testing.T) {
 	_, store := setupTestStore(t)
 	ctx := context.Background()
@@ -406,10 +563,10 @@ func TestMySQLStore_GetLatestCommitted(t *testing.T) {
 		require.NoError(t, store.Create(ctx, tp))
 
 		// Create versions
-		v2, err := store.CommitDraft(ctx, tp.ID)
+		v2, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "Update procedure"})
 		require.NoError(t, err)
 
-		_, err = store.CommitDraft(ctx, v2.ID)
+		_, err = store.CommitDraft(ctx, v2.ID, CommitNote{Message: "Update procedure"})
 		require.NoError(t, err)
 
 		// Get latest should be v3
@@ -467,6 +624,25 @@ func TestMySQLStore_UpdateDraft(t *testing.T) {
 		err := store.UpdateDraft(ctx, uuid.New(), SetName("Test"))
 		assert.Error(t, err)
 	})
+
+	t.Run("update from a stale revision conflicts", func(t *testing.T) {
+		tp := createTestProcedure("Test", "Description", uuid.New(), uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, tp))
+		draft, err := store.GetDraft(ctx, tp.ID)
+		require.NoError(t, err)
+		read := draft.Revision
+
+		require.NoError(t, store.UpdateDraft(ctx, tp.ID, RequireRevision(read), SetName("First")))
+		err = store.UpdateDraft(ctx, tp.ID, RequireRevision(read), SetName("Second"))
+		assert.ErrorIs(t, err, ErrDraftConflict)
+
+		draft, err = store.GetDraft(ctx, tp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "First", draft.Name)
+		assert.Equal(t, read+1, draft.Revision)
+
+		require.NoError(t, store.UpdateDraft(ctx, tp.ID, RequireRevision(draft.Revision), SetName("Second")))
+	})
 }
 
 func TestMySQLStore_ResetDraft(t *testing.T) {
@@ -514,7 +690,7 @@ func TestMySQLStore_CommitDraft(t *testing.T) {
 		require.NoError(t, err)
 
 		// Commit draft
-		v2, err := store.CommitDraft(ctx, tp.ID)
+		v2, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "Update procedure"})
 		require.NoError(t, err)
 		assert.Equal(t, uint(2), v2.Version)
 		assert.True(t, v2.IsLatest)
@@ -539,7 +715,7 @@ func TestMySQLStore_CommitDraft(t *testing.T) {
 		require.NoError(t, store.Create(ctx, tp))
 
 		// First commit
-		v2, err := store.CommitDraft(ctx, tp.ID)
+		v2, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "Update procedure"})
 		require.NoError(t, err)
 		assert.Equal(t, uint(2), v2.Version)
 
@@ -548,16 +724,127 @@ func TestMySQLStore_CommitDraft(t *testing.T) {
 		require.NoError(t, err)
 
 		// Second commit
-		v3, err := store.CommitDraft(ctx, v2.ID)
+		v3, err := store.CommitDraft(ctx, v2.ID, CommitNote{Message: "Update procedure"})
 		require.NoError(t, err)
 		assert.Equal(t, uint(3), v3.Version)
 		assert.Equal(t, "Second Modification", v3.Name)
 	})
 
 	t.Run("commit draft for non-existent returns error", func(t *testing.T) {
-		_, err := store.CommitDraft(ctx, uuid.New())
+		_, err := store.CommitDraft(ctx, uuid.New(), CommitNote{Message: "Update procedure"})
 		assert.Error(t, err)
 	})
+
+	t.Run("commit note is stored with the version", func(t *testing.T) {
+		tp := createTestProcedure("Checkout", "Description", uuid.New(), uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, tp))
+
+		_, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "  "})
+		assert.ErrorIs(t, err, ErrMissingCommitMessage)
+		_, err = store.CommitDraft(ctx, tp.ID, CommitNote{Message: strings.Repeat("a", MaxCommitMessageLength+1)})
+		assert.ErrorIs(t, err, ErrCommitMessageTooLong)
+
+		v2, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: " Add coupon step ", Summary: "Covers the 10% coupon."})
+		require.NoError(t, err)
+
+		// History runs from the newest version down to the draft
+		history, err := store.GetVersionHistory(ctx, tp.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.Equal(t, v2.ID, history[0].ID)
+		assert.Equal(t, "Add coupon step", history[0].CommitMessage)
+		assert.Equal(t, "Covers the 10% coupon.", history[0].ChangeSummary)
+		assert.Empty(t, history[1].CommitMessage)
+	})
+}
+
+func TestMySQLStore_CommitDraftPending(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	tp := createTestProcedure("Original", "Description", uuid.New(), uuid.New(), nil)
+	require.NoError(t, store.Create(ctx, tp))
+	require.NoError(t, store.UpdateDraft(ctx, tp.ID, SetName("Modified")))
+
+	// The pending version is not published
+	v2, err := store.CommitDraftPending(ctx, tp.ID, CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), v2.Version)
+	assert.False(t, v2.IsLatest)
+
+	latest, err := store.GetLatestCommitted(ctx, tp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tp.ID, latest.ID)
+	assert.Equal(t, "Original", latest.Name)
+
+	// Publishing makes it the latest
+	require.NoError(t, store.PublishVersion(ctx, v2.ID))
+	latest, err = store.GetLatestCommitted(ctx, tp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, v2.ID, latest.ID)
+	assert.Equal(t, "Modified", latest.Name)
+
+	v1, err := store.GetByID(ctx, tp.ID)
+	require.NoError(t, err)
+	assert.False(t, v1.IsLatest)
+
+	// The first version is not a pending version
+	assert.ErrorIs(t, store.PublishVersion(ctx, tp.ID), ErrNoCommittedVersion)
+}
+
+func TestMySQLStore_ListByStepGroup(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID := uuid.New()
+	groupID := uuid.New()
+	ref := TestStep{Name: "Log in as admin", GroupID: &groupID, GroupRevision: 1}
+
+	committed := createTestProcedure("Uses group", "", projectID, uuid.New(), Steps{ref})
+	require.NoError(t, store.Create(ctx, committed))
+
+	drafted := createTestProcedure("Draft uses group", "", projectID, uuid.New(), Steps{{Name: "Open app"}})
+	require.NoError(t, store.Create(ctx, drafted))
+	require.NoError(t, store.UpdateDraft(ctx, drafted.ID, SetSteps(Steps{{Name: "Open app"}, ref})))
+
+	mentioned := createTestProcedure("Mentions group", "", projectID, uuid.New(), Steps{{Name: "Note", Instructions: groupID.String()}})
+	require.NoError(t, store.Create(ctx, mentioned))
+
+	procedures, err := store.ListByStepGroup(ctx, projectID, groupID)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tp := range procedures {
+		names = append(names, fmt.Sprintf("%s v%d", tp.Name, tp.Version))
+	}
+	assert.ElementsMatch(t, []string{"Uses group v1", "Uses group v0", "Draft uses group v0"}, names)
+
+	others, err := store.ListByStepGroup(ctx, uuid.New(), groupID)
+	require.NoError(t, err)
+	assert.Empty(t, others)
+}
+
+func TestMySQLStore_NeedsReview(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	tp := createTestProcedure("Generated", "From exploration", uuid.New(), uuid.New(), nil)
+	tp.NeedsReview = true
+	require.NoError(t, store.Create(ctx, tp))
+	assert.True(t, tp.NeedsReview)
+
+	draft, err := store.GetDraft(ctx, tp.ID)
+	require.NoError(t, err)
+	assert.True(t, draft.NeedsReview)
+
+	// Clearing the flag on the draft and committing marks the procedure reviewed
+	require.NoError(t, store.UpdateDraft(ctx, tp.ID, SetNeedsReview(false)))
+	v2, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+	assert.False(t, v2.NeedsReview)
+
+	v1, err := store.GetByID(ctx, tp.ID)
+	require.NoError(t, err)
+	assert.True(t, v1.NeedsReview)
 }
 
 func TestMySQLStore_CompleteWorkflow(t *testing.T) {
@@ -598,7 +885,7 @@ func TestMySQLStore_CompleteWorkflow(t *testing.T) {
 		assert.Len(t, committed.Steps, 1)
 
 		// Commit draft
-		v2, err := store.CommitDraft(ctx, tp.ID)
+		v2, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "Update procedure"})
 		require.NoError(t, err)
 		assert.Equal(t, "Modified Procedure", v2.Name)
 		assert.Len(t, v2.Steps, 2)
@@ -628,3 +915,28 @@ func TestMySQLStore_CompleteWorkflow(t *testing.T) {
 		assert.Equal(t, "Modified Procedure", draft.Name)
 	})
 }
+
+func TestMySQLStepImageStore(t *testing.T) {
+	store := setupStepImageStore(t)
+	ctx := context.Background()
+	procedureID := uuid.New()
+	path := "test-procedures/" + procedureID.String() + "/steps/abc.png"
+
+	t.Run("missing image returns error", func(t *testing.T) {
+		_, err := store.GetByPath(ctx, path)
+		assert.ErrorIs(t, err, ErrStepImageNotFound)
+	})
+
+	t.Run("create and get image", func(t *testing.T) {
+		require.NoError(t, store.Create(ctx, &StepImage{Path: path, TestProcedureID: procedureID, FileSize: 2048}))
+
+		image, err := store.GetByPath(ctx, path)
+		require.NoError(t, err)
+		assert.Equal(t, procedureID, image.TestProcedureID)
+		assert.Equal(t, int64(2048), image.FileSize)
+	})
+
+	t.Run("recording an image again is ignored", func(t *testing.T) {
+		require.NoError(t, store.Create(ctx, &StepImage{Path: path, TestProcedureID: procedureID, FileSize: 2048}))
+	})
+}
@@ -0,0 +1,326 @@
+package testprocedure
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var (
+	// ErrInvalidParameter is returned when a parameter declaration is invalid.
+	ErrInvalidParameter = errors.New("invalid parameter")
+
+	// ErrUndeclaredParameter is returned when a placeholder or a value names
+	// a parameter the procedure does not declare.
+	ErrUndeclaredParameter = errors.New("undeclared parameter")
+
+	// ErrMissingParameter is returned when a required parameter has no value
+	// and no default.
+	ErrMissingParameter = errors.New("missing value for required parameter")
+
+	// ErrInvalidParameterValue is returned when a value does not match its
+	// parameter's type.
+	ErrInvalidParameterValue = errors.New("invalid parameter value")
+)
+
+// placeholderPattern matches a {{name}} placeholder, allowing spaces inside
+// the braces.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// BaseURLParameter is the parameter that, if declared, defaults to the URL
+// of the endpoint a run executes against.
+const BaseURLParameter = "base_url"
+
+// parameterNamePattern matches a valid parameter name.
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParameterType is the type of value a parameter takes.
+type ParameterType string
+
+const (
+	ParameterTypeString  ParameterType = "string"
+	ParameterTypeNumber  ParameterType = "number"
+	ParameterTypeBoolean ParameterType = "boolean"
+	ParameterTypeURL     ParameterType = "url"
+)
+
+// IsValid reports whether t is a known parameter type.
+func (t ParameterType) IsValid() bool {
+	switch t {
+	case ParameterTypeString, ParameterTypeNumber, ParameterTypeBoolean, ParameterTypeURL:
+		return true
+	}
+	return false
+}
+
+// check returns ErrInvalidParameterValue if value is not a valid value of
+// type t.
+func (t ParameterType) check(value string) error {
+	switch t {
+	case ParameterTypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%w: %q is not a number", ErrInvalidParameterValue, value)
+		}
+	case ParameterTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%w: %q is not a boolean", ErrInvalidParameterValue, value)
+		}
+	case ParameterTypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%w: %q is not an absolute URL", ErrInvalidParameterValue, value)
+		}
+	}
+	return nil
+}
+
+// Parameter declares a variable a procedure's steps refer to as {{name}},
+// whose value is given when a run is created or a script is generated.
+type Parameter struct {
+	Name        string        `json:"name"`
+	Type        ParameterType `json:"type"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required"`
+	// Default, if set, is used when no value is given.
+	Default *string `json:"default,omitempty"`
+}
+
+// Parameters represents the JSON parameter schema of a test procedure.
+type Parameters []Parameter
+
+// Value implements the driver.Valuer interface for database storage.
+func (p Parameters) Value() (driver.Value, error) {
+	if p == nil {
+		return json.Marshal([]Parameter{})
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (p *Parameters) Scan(value interface{}) error {
+	if value == nil {
+		*p = Parameters{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan Parameters: not a byte slice")
+	}
+
+	var params []Parameter
+	if err := json.Unmarshal(bytes, &params); err != nil {
+		return err
+	}
+	*p = params
+	return nil
+}
+
+// Validate checks that every parameter has a unique, valid name and a known
+// type, and that defaults match their type. Parameters without a type are
+// strings.
+func (p Parameters) Validate() error {
+	seen := make(map[string]bool, len(p))
+	for i := range p {
+		param := &p[i]
+		if !parameterNamePattern.MatchString(param.Name) {
+			return fmt.Errorf("%w: parameter %d: name must be letters, digits and underscores", ErrInvalidParameter, i+1)
+		}
+		if seen[param.Name] {
+			return fmt.Errorf("%w: %s is declared more than once", ErrInvalidParameter, param.Name)
+		}
+		seen[param.Name] = true
+		if param.Type == "" {
+			param.Type = ParameterTypeString
+		}
+		if !param.Type.IsValid() {
+			return fmt.Errorf("%w: %s has unknown type %q", ErrInvalidParameter, param.Name, param.Type)
+		}
+		if param.Default != nil {
+			if err := param.Type.check(*param.Default); err != nil {
+				return fmt.Errorf("%w: default of %s: %v", ErrInvalidParameter, param.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Resolve checks values against the schema and returns the value of every
+// parameter that has one, filling in defaults. Returns
+// ErrUndeclaredParameter, ErrInvalidParameterValue or ErrMissingParameter
+// otherwise.
+func (p Parameters) Resolve(values map[string]string) (ParameterValues, error) {
+	declared := make(map[string]Parameter, len(p))
+	for _, param := range p {
+		declared[param.Name] = param
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resolved := make(ParameterValues, len(p))
+	for _, name := range names {
+		param, ok := declared[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUndeclaredParameter, name)
+		}
+		if err := param.Type.check(values[name]); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		resolved[name] = values[name]
+	}
+
+	for _, param := range p {
+		if _, ok := resolved[param.Name]; ok {
+			continue
+		}
+		if param.Default != nil {
+			resolved[param.Name] = *param.Default
+			continue
+		}
+		if param.Required {
+			return nil, fmt.Errorf("%w: %s", ErrMissingParameter, param.Name)
+		}
+	}
+	return resolved, nil
+}
+
+// ResolveWithBaseURL is Resolve with the base_url parameter, if declared and
+// not given, set to baseURL.
+func (p Parameters) ResolveWithBaseURL(values map[string]string, baseURL string) (ParameterValues, error) {
+	if baseURL != "" && p.Has(BaseURLParameter) {
+		if _, ok := values[BaseURLParameter]; !ok {
+			merged := make(map[string]string, len(values)+1)
+			for name, value := range values {
+				merged[name] = value
+			}
+			merged[BaseURLParameter] = baseURL
+			values = merged
+		}
+	}
+	return p.Resolve(values)
+}
+
+// Has reports whether the schema declares a parameter called name.
+func (p Parameters) Has(name string) bool {
+	for _, param := range p {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParameterValues holds the values given for a procedure's parameters, by
+// name.
+type ParameterValues map[string]string
+
+// Value implements the driver.Valuer interface for database storage.
+func (v ParameterValues) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (v *ParameterValues) Scan(value interface{}) error {
+	if value == nil {
+		*v = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan ParameterValues: not a byte slice")
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(bytes, &values); err != nil {
+		return err
+	}
+	*v = values
+	return nil
+}
+
+// Placeholders returns the names of the parameters the procedure's
+// description, steps and step conditions refer to, in order of first use.
+func (tp *TestProcedure) Placeholders() []string {
+	var names []string
+	seen := make(map[string]bool)
+	collect := func(text string) {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				names = append(names, m[1])
+			}
+		}
+	}
+	collect(tp.Description)
+	for _, step := range tp.Steps {
+		collect(step.Name)
+		collect(step.Instructions)
+		collect(step.ExpectedResult)
+		if step.Condition != nil {
+			collect(step.Condition.Selector)
+			collect(step.Condition.Text)
+		}
+	}
+	return names
+}
+
+// validateParameters checks the parameter schema and that every placeholder
+// refers to a declared parameter.
+func (tp *TestProcedure) validateParameters() error {
+	if err := tp.Parameters.Validate(); err != nil {
+		return err
+	}
+	for _, name := range tp.Placeholders() {
+		if !tp.Parameters.Has(name) {
+			return fmt.Errorf("%w: {{%s}} is used but not declared", ErrUndeclaredParameter, name)
+		}
+	}
+	return nil
+}
+
+// WithParameters returns a copy of tp with each placeholder in its
+// description, steps and step conditions replaced by its value. Placeholders without a value
+// are left as they are.
+func (tp *TestProcedure) WithParameters(values ParameterValues) *TestProcedure {
+	substitute := func(text string) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(m string) string {
+			name := placeholderPattern.FindStringSubmatch(m)[1]
+			if value, ok := values[name]; ok {
+				return value
+			}
+			return m
+		})
+	}
+
+	out := *tp
+	out.Description = substitute(tp.Description)
+	out.Steps = make(Steps, len(tp.Steps))
+	for i, step := range tp.Steps {
+		step.Name = substitute(step.Name)
+		step.Instructions = substitute(step.Instructions)
+		step.ExpectedResult = substitute(step.ExpectedResult)
+		step.ImagePaths = append([]string(nil), step.ImagePaths...)
+		step.Attachments = append([]Attachment(nil), step.Attachments...)
+		if step.Condition != nil {
+			cond := *step.Condition
+			cond.Selector = substitute(cond.Selector)
+			cond.Text = substitute(cond.Text)
+			step.Condition = &cond
+		}
+		out.Steps[i] = step
+	}
+	return &out
+}
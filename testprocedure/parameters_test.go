@@ -0,0 +1,182 @@
+package testprocedure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestParameters_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Parameters
+		wantErr error
+	}{
+		{
+			name: "valid",
+			params: Parameters{
+				{Name: "username", Required: true},
+				{Name: "base_url", Type: ParameterTypeURL, Default: strPtr("https://example.com")},
+				{Name: "retries", Type: ParameterTypeNumber, Default: strPtr("3")},
+			},
+		},
+		{
+			name:    "invalid name",
+			params:  Parameters{{Name: "user name"}},
+			wantErr: ErrInvalidParameter,
+		},
+		{
+			name:    "duplicate name",
+			params:  Parameters{{Name: "username"}, {Name: "username"}},
+			wantErr: ErrInvalidParameter,
+		},
+		{
+			name:    "unknown type",
+			params:  Parameters{{Name: "when", Type: "date"}},
+			wantErr: ErrInvalidParameter,
+		},
+		{
+			name:    "default of the wrong type",
+			params:  Parameters{{Name: "enabled", Type: ParameterTypeBoolean, Default: strPtr("maybe")}},
+			wantErr: ErrInvalidParameter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("untyped parameters are strings", func(t *testing.T) {
+		params := Parameters{{Name: "username"}}
+		require.NoError(t, params.Validate())
+		assert.Equal(t, ParameterTypeString, params[0].Type)
+	})
+}
+
+func TestParameters_Resolve(t *testing.T) {
+	params := Parameters{
+		{Name: "username", Type: ParameterTypeString, Required: true},
+		{Name: "base_url", Type: ParameterTypeURL, Required: true},
+		{Name: "retries", Type: ParameterTypeNumber, Default: strPtr("3")},
+		{Name: "note", Type: ParameterTypeString},
+	}
+
+	t.Run("fills in defaults", func(t *testing.T) {
+		values, err := params.Resolve(map[string]string{"username": "alice", "base_url": "https://staging.example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, ParameterValues{
+			"username": "alice",
+			"base_url": "https://staging.example.com",
+			"retries":  "3",
+		}, values)
+	})
+
+	t.Run("undeclared value", func(t *testing.T) {
+		_, err := params.Resolve(map[string]string{"username": "alice", "base_url": "https://example.com", "password": "x"})
+		assert.ErrorIs(t, err, ErrUndeclaredParameter)
+	})
+
+	t.Run("value of the wrong type", func(t *testing.T) {
+		_, err := params.Resolve(map[string]string{"username": "alice", "base_url": "example"})
+		assert.ErrorIs(t, err, ErrInvalidParameterValue)
+	})
+
+	t.Run("missing required value", func(t *testing.T) {
+		_, err := params.Resolve(map[string]string{"base_url": "https://example.com"})
+		assert.ErrorIs(t, err, ErrMissingParameter)
+	})
+
+	t.Run("base_url defaults to the endpoint", func(t *testing.T) {
+		values, err := params.ResolveWithBaseURL(map[string]string{"username": "alice"}, "https://qa.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "https://qa.example.com", values["base_url"])
+
+		values, err = params.ResolveWithBaseURL(map[string]string{"username": "alice", "base_url": "https://example.com"}, "https://qa.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com", values["base_url"])
+	})
+}
+
+func TestTestProcedure_Placeholders(t *testing.T) {
+	tp := &TestProcedure{
+		Name:        "Login",
+		ProjectID:   uuid.New(),
+		CreatedBy:   uuid.New(),
+		Description: "Log in to {{base_url}}",
+		Steps: Steps{
+			{Name: "Open {{ base_url }}/login", Instructions: "Go to the login page"},
+			{Name: "Log in", Instructions: "Enter {{username}} and {{password}}"},
+		},
+	}
+	assert.Equal(t, []string{"base_url", "username", "password"}, tp.Placeholders())
+
+	assert.ErrorIs(t, tp.Validate(), ErrUndeclaredParameter)
+
+	tp.Parameters = Parameters{{Name: "base_url"}, {Name: "username"}, {Name: "password"}}
+	assert.NoError(t, tp.Validate())
+}
+
+func TestTestProcedure_WithParameters(t *testing.T) {
+	tp := &TestProcedure{
+		Description: "Log in to {{base_url}}",
+		Steps: Steps{
+			{Name: "Open {{ base_url }}/login", Instructions: "Go to the login page", ImagePaths: []string{"a.png"}},
+			{Name: "Log in", Instructions: "Enter {{username}} and {{password}}", ExpectedResult: "Welcome, {{username}}"},
+		},
+	}
+
+	got := tp.WithParameters(ParameterValues{"base_url": "https://example.com", "username": "alice"})
+	assert.Equal(t, "Log in to https://example.com", got.Description)
+	assert.Equal(t, "Open https://example.com/login", got.Steps[0].Name)
+	assert.Equal(t, "Enter alice and {{password}}", got.Steps[1].Instructions)
+	assert.Equal(t, "Welcome, alice", got.Steps[1].ExpectedResult)
+
+	// The procedure itself is unchanged
+	assert.Equal(t, "Open {{ base_url }}/login", tp.Steps[0].Name)
+	got.Steps[0].ImagePaths[0] = "b.png"
+	assert.Equal(t, "a.png", tp.Steps[0].ImagePaths[0])
+}
+
+func TestMySQLStore_Parameters(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	tp := createTestProcedure("Login", "", uuid.New(), uuid.New(), Steps{
+		{Name: "Log in", Instructions: "Enter {{username}}"},
+	})
+	tp.Parameters = Parameters{{Name: "username", Required: true}}
+	require.NoError(t, store.Create(ctx, tp))
+
+	draft, err := store.GetDraft(ctx, tp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tp.Parameters, draft.Parameters)
+
+	// A draft cannot use a placeholder it does not declare
+	err = store.UpdateDraft(ctx, tp.ID, SetSteps(Steps{{Name: "Log in", Instructions: "Enter {{username}} and {{password}}"}}))
+	assert.ErrorIs(t, err, ErrUndeclaredParameter)
+
+	require.NoError(t, store.UpdateDraft(ctx, tp.ID,
+		SetSteps(Steps{{Name: "Log in", Instructions: "Enter {{username}} and {{password}}"}}),
+		SetParameters(Parameters{{Name: "username", Required: true}, {Name: "password", Required: true}}),
+	))
+
+	v2, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+	require.Len(t, v2.Parameters, 2)
+	assert.Equal(t, "password", v2.Parameters[1].Name)
+	assert.Equal(t, ParameterTypeString, v2.Parameters[1].Type)
+}
@@ -0,0 +1,108 @@
+package testprocedure
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPrecondition is returned when a precondition is invalid.
+var ErrInvalidPrecondition = errors.New("invalid precondition")
+
+// PreconditionKind is what a precondition requires to be in place before a
+// run starts.
+type PreconditionKind string
+
+const (
+	// PreconditionTestData requires test data, such as a product in stock.
+	PreconditionTestData PreconditionKind = "test_data"
+
+	// PreconditionEnvironment requires the environment to be in a state,
+	// such as a feature flag being enabled.
+	PreconditionEnvironment PreconditionKind = "environment"
+
+	// PreconditionUserRole requires the tester to have a user role, such as
+	// an admin account.
+	PreconditionUserRole PreconditionKind = "user_role"
+)
+
+// IsValid reports whether k is a known precondition kind.
+func (k PreconditionKind) IsValid() bool {
+	switch k {
+	case PreconditionTestData, PreconditionEnvironment, PreconditionUserRole:
+		return true
+	}
+	return false
+}
+
+// Label returns the kind as shown to readers, such as "Test data".
+func (k PreconditionKind) Label() string {
+	switch k {
+	case PreconditionTestData:
+		return "Test data"
+	case PreconditionEnvironment:
+		return "Environment"
+	case PreconditionUserRole:
+		return "User role"
+	}
+	return string(k)
+}
+
+// Precondition is something that must be in place before a procedure is
+// run, such as a fixture it needs.
+type Precondition struct {
+	Kind        PreconditionKind `json:"kind"`
+	Description string           `json:"description"`
+}
+
+// String describes the precondition as "<kind>: <description>".
+func (p Precondition) String() string {
+	return p.Kind.Label() + ": " + p.Description
+}
+
+// Preconditions represents the JSON preconditions of a test procedure.
+type Preconditions []Precondition
+
+// Value implements the driver.Valuer interface for database storage.
+func (p Preconditions) Value() (driver.Value, error) {
+	if p == nil {
+		return json.Marshal([]Precondition{})
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (p *Preconditions) Scan(value interface{}) error {
+	if value == nil {
+		*p = Preconditions{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan Preconditions: not a byte slice")
+	}
+
+	var preconditions Preconditions
+	if err := json.Unmarshal(bytes, &preconditions); err != nil {
+		return err
+	}
+	*p = preconditions
+	return nil
+}
+
+// Validate checks that every precondition has a known kind and a
+// description.
+func (p Preconditions) Validate() error {
+	for i, precondition := range p {
+		if !precondition.Kind.IsValid() {
+			return fmt.Errorf("%w: precondition %d has unknown kind %q", ErrInvalidPrecondition, i+1, precondition.Kind)
+		}
+		if strings.TrimSpace(precondition.Description) == "" {
+			return fmt.Errorf("%w: precondition %d needs a description", ErrInvalidPrecondition, i+1)
+		}
+	}
+	return nil
+}
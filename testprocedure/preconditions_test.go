@@ -0,0 +1,83 @@
+package testprocedure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreconditions_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		preconditions Preconditions
+		wantErr       error
+	}{
+		{
+			name: "valid",
+			preconditions: Preconditions{
+				{Kind: PreconditionTestData, Description: "A product with stock above 10"},
+				{Kind: PreconditionEnvironment, Description: "The new-checkout flag is enabled"},
+				{Kind: PreconditionUserRole, Description: "An admin account"},
+			},
+		},
+		{
+			name:          "unknown kind",
+			preconditions: Preconditions{{Kind: "weather", Description: "Sunny"}},
+			wantErr:       ErrInvalidPrecondition,
+		},
+		{
+			name:          "empty description",
+			preconditions: Preconditions{{Kind: PreconditionTestData, Description: "  "}},
+			wantErr:       ErrInvalidPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.preconditions.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPrecondition_String(t *testing.T) {
+	p := Precondition{Kind: PreconditionUserRole, Description: "An admin account"}
+	assert.Equal(t, "User role: An admin account", p.String())
+}
+
+func TestMySQLStore_Preconditions(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	tp := createTestProcedure("Checkout", "", uuid.New(), uuid.New(), Steps{{Name: "Pay"}})
+	tp.Preconditions = Preconditions{{Kind: PreconditionTestData, Description: "A product in stock"}}
+	require.NoError(t, store.Create(ctx, tp))
+
+	draft, err := store.GetDraft(ctx, tp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tp.Preconditions, draft.Preconditions)
+
+	err = store.UpdateDraft(ctx, tp.ID, SetPreconditions(Preconditions{{Kind: "weather"}}))
+	assert.ErrorIs(t, err, ErrInvalidPrecondition)
+
+	require.NoError(t, store.UpdateDraft(ctx, tp.ID, SetPreconditions(Preconditions{
+		{Kind: PreconditionTestData, Description: "A product in stock"},
+		{Kind: PreconditionUserRole, Description: "An admin account"},
+	})))
+
+	v2, err := store.CommitDraft(ctx, tp.ID, CommitNote{Message: "Update procedure"})
+	require.NoError(t, err)
+	require.Len(t, v2.Preconditions, 2)
+	assert.Equal(t, PreconditionUserRole, v2.Preconditions[1].Kind)
+
+	got, err := store.GetByID(ctx, v2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, v2.Preconditions, got.Preconditions)
+}
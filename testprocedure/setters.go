@@ -1,5 +1,7 @@
 package testprocedure
 
+import "github.com/hairizuanbinnoorazman/ui-automation/markdown"
+
 // SetName returns an UpdateSetter that sets the test procedure's name.
 func SetName(name string) UpdateSetter {
 	return func(tp *TestProcedure) error {
@@ -11,18 +13,70 @@ func SetName(name string) UpdateSetter {
 	}
 }
 
-// SetDescription returns an UpdateSetter that sets the test procedure's description.
+// SetDescription returns an UpdateSetter that sets the test procedure's
+// description, removing what is unsafe to show from its Markdown.
 func SetDescription(description string) UpdateSetter {
 	return func(tp *TestProcedure) error {
-		tp.Description = description
+		tp.Description = markdown.Sanitize(description)
 		return nil
 	}
 }
 
-// SetSteps returns an UpdateSetter that sets the test procedure's steps.
+// SetSteps returns an UpdateSetter that sets the test procedure's steps,
+// removing what is unsafe to show from the Markdown of their instructions.
 func SetSteps(steps Steps) UpdateSetter {
 	return func(tp *TestProcedure) error {
-		tp.Steps = steps
+		if err := steps.ValidateConditions(); err != nil {
+			return err
+		}
+		if err := steps.ValidateAttachments(); err != nil {
+			return err
+		}
+		tp.Steps = steps.SanitizeInstructions()
+		return nil
+	}
+}
+
+// SetNeedsReview returns an UpdateSetter that flags or clears the procedure
+// for human review.
+func SetNeedsReview(needsReview bool) UpdateSetter {
+	return func(tp *TestProcedure) error {
+		tp.NeedsReview = needsReview
+		return nil
+	}
+}
+
+// RequireRevision returns an UpdateSetter that fails with ErrDraftConflict
+// unless the draft is still at revision, the one the update was made from.
+func RequireRevision(revision uint) UpdateSetter {
+	return func(tp *TestProcedure) error {
+		if tp.Revision != revision {
+			return ErrDraftConflict
+		}
+		return nil
+	}
+}
+
+// SetParameters returns an UpdateSetter that replaces the test procedure's
+// parameter schema.
+func SetParameters(params Parameters) UpdateSetter {
+	return func(tp *TestProcedure) error {
+		if err := params.Validate(); err != nil {
+			return err
+		}
+		tp.Parameters = params
+		return nil
+	}
+}
+
+// SetPreconditions returns an UpdateSetter that replaces the test
+// procedure's preconditions.
+func SetPreconditions(preconditions Preconditions) UpdateSetter {
+	return func(tp *TestProcedure) error {
+		if err := preconditions.Validate(); err != nil {
+			return err
+		}
+		tp.Preconditions = preconditions
 		return nil
 	}
 }
@@ -0,0 +1,91 @@
+package testprocedure
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxDraftSnapshots is how many snapshots of a draft are kept. Taking
+// another removes the oldest.
+const MaxDraftSnapshots = 20
+
+// ErrDraftSnapshotNotFound is returned when a draft snapshot is not found.
+var ErrDraftSnapshotNotFound = errors.New("draft snapshot not found")
+
+// DraftSnapshot is the content of a draft as it was before a save changed
+// it, so that the change can be undone. Revision is the draft revision the
+// snapshot was taken of.
+type DraftSnapshot struct {
+	ID            uuid.UUID     `json:"id" gorm:"type:char(36);primaryKey"`
+	DraftID       uuid.UUID     `json:"draft_id" gorm:"type:char(36);not null;index:idx_test_procedure_draft_snapshots_draft_id"`
+	Revision      uint          `json:"revision" gorm:"not null"`
+	Name          string        `json:"name" gorm:"not null"`
+	Description   string        `json:"description" gorm:"type:text"`
+	Steps         Steps         `json:"steps" gorm:"type:json"`
+	Parameters    Parameters    `json:"parameters" gorm:"type:json"`
+	Preconditions Preconditions `json:"preconditions" gorm:"type:json"`
+	NeedsReview   bool          `json:"needs_review" gorm:"not null;default:false"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (DraftSnapshot) TableName() string {
+	return "test_procedure_draft_snapshots"
+}
+
+// BeforeCreate hook to generate UUID before creating a new snapshot.
+func (s *DraftSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// snapshotDraft returns a snapshot of the content of draft.
+func snapshotDraft(draft *TestProcedure) *DraftSnapshot {
+	return &DraftSnapshot{
+		DraftID:       draft.ID,
+		Revision:      draft.Revision,
+		Name:          draft.Name,
+		Description:   draft.Description,
+		Steps:         draft.Steps,
+		Parameters:    draft.Parameters,
+		Preconditions: draft.Preconditions,
+		NeedsReview:   draft.NeedsReview,
+	}
+}
+
+// apply sets the content of draft to the snapshot's.
+func (s *DraftSnapshot) apply(draft *TestProcedure) {
+	draft.Name = s.Name
+	draft.Description = s.Description
+	draft.Steps = s.Steps
+	draft.Parameters = s.Parameters
+	draft.Preconditions = s.Preconditions
+	draft.NeedsReview = s.NeedsReview
+}
+
+// sameContent reports whether the snapshot has the same content as other.
+func (s *DraftSnapshot) sameContent(other *DraftSnapshot) bool {
+	if s.Name != other.Name || s.Description != other.Description || s.NeedsReview != other.NeedsReview {
+		return false
+	}
+	pairs := [][2]driver.Valuer{
+		{s.Steps, other.Steps},
+		{s.Parameters, other.Parameters},
+		{s.Preconditions, other.Preconditions},
+	}
+	for _, pair := range pairs {
+		a, errA := pair[0].Value()
+		b, errB := pair[1].Value()
+		if errA != nil || errB != nil || !bytes.Equal(a.([]byte), b.([]byte)) {
+			return false
+		}
+	}
+	return true
+}
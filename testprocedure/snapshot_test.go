@@ -0,0 +1,80 @@
+package testprocedure
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_DraftSnapshots(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	steps := Steps{{Name: "Open the cart"}, {Name: "Apply the coupon"}, {Name: "Pay"}}
+	tp := createTestProcedure("Checkout", "Description", uuid.New(), uuid.New(), steps)
+	require.NoError(t, store.Create(ctx, tp))
+
+	t.Run("a step deleted by mistake can be restored", func(t *testing.T) {
+		require.NoError(t, store.UpdateDraft(ctx, tp.ID, SetSteps(Steps{steps[0], steps[2]})))
+
+		snapshots, err := store.ListDraftSnapshots(ctx, tp.ID)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+		assert.Len(t, snapshots[0].Steps, 3)
+
+		draft, err := store.RestoreDraftSnapshot(ctx, tp.ID, snapshots[0].ID)
+		require.NoError(t, err)
+		require.Len(t, draft.Steps, 3)
+		assert.Equal(t, "Apply the coupon", draft.Steps[1].Name)
+
+		// The restore is saved as a revision and can be undone in turn
+		stored, err := store.GetDraft(ctx, tp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, draft.Revision, stored.Revision)
+		assert.Len(t, stored.Steps, 3)
+
+		snapshots, err = store.ListDraftSnapshots(ctx, tp.ID)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+		assert.Len(t, snapshots[0].Steps, 2)
+	})
+
+	t.Run("saves that change nothing are not kept", func(t *testing.T) {
+		before, err := store.ListDraftSnapshots(ctx, tp.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, store.UpdateDraft(ctx, tp.ID, SetName("Checkout")))
+
+		after, err := store.ListDraftSnapshots(ctx, tp.ID)
+		require.NoError(t, err)
+		assert.Len(t, after, len(before))
+	})
+
+	t.Run("only the last snapshots are kept", func(t *testing.T) {
+		for i := 0; i < MaxDraftSnapshots+5; i++ {
+			require.NoError(t, store.UpdateDraft(ctx, tp.ID, SetName(fmt.Sprintf("Checkout %d", i))))
+		}
+
+		snapshots, err := store.ListDraftSnapshots(ctx, tp.ID)
+		require.NoError(t, err)
+		require.Len(t, snapshots, MaxDraftSnapshots)
+		assert.Equal(t, fmt.Sprintf("Checkout %d", MaxDraftSnapshots+3), snapshots[0].Name)
+		assert.Greater(t, snapshots[0].Revision, snapshots[1].Revision)
+	})
+
+	t.Run("snapshots of other drafts cannot be restored", func(t *testing.T) {
+		other := createTestProcedure("Login", "Description", uuid.New(), uuid.New(), nil)
+		require.NoError(t, store.Create(ctx, other))
+		require.NoError(t, store.UpdateDraft(ctx, other.ID, SetName("Sign in")))
+		snapshots, err := store.ListDraftSnapshots(ctx, other.ID)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+
+		_, err = store.RestoreDraftSnapshot(ctx, tp.ID, snapshots[0].ID)
+		assert.ErrorIs(t, err, ErrDraftSnapshotNotFound)
+	})
+}
@@ -0,0 +1,98 @@
+package testprocedure
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrStepAttachmentNotFound is returned when a step attachment is not found.
+	ErrStepAttachmentNotFound = errors.New("step attachment not found")
+
+	// ErrInvalidAttachment is returned when a step's attachment is invalid.
+	ErrInvalidAttachment = errors.New("invalid step attachment")
+)
+
+// Attachment is a file attached to a step for the tester to use, such as a
+// sample CSV to upload or a config file. Path is where the file is stored,
+// as returned when it was uploaded, and FileName is its name when
+// downloaded.
+type Attachment struct {
+	Path        string `json:"path"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	FileSize    int64  `json:"file_size"`
+}
+
+// recordingExtensions are the file types of screen recordings.
+var recordingExtensions = map[string]bool{
+	".mp4":  true,
+	".webm": true,
+	".gif":  true,
+}
+
+// IsRecording reports whether the attachment is a short screen recording
+// showing how to do the step, which is played back rather than downloaded.
+// The stored extension decides, since uploads are checked against it.
+func (a Attachment) IsRecording() bool {
+	return recordingExtensions[strings.ToLower(path.Ext(a.Path))]
+}
+
+// AttachmentPath returns where a step attachment of a procedure is stored.
+// fileName is the name the file is stored under, not the one it is
+// downloaded as.
+func AttachmentPath(procedureID uuid.UUID, fileName string) string {
+	return fmt.Sprintf("test-procedures/%s/attachments/%s", procedureID.String(), fileName)
+}
+
+// AttachmentProcedure returns the procedure a step attachment path of the
+// form test-procedures/{id}/attachments/{file} was uploaded to.
+func AttachmentProcedure(path string) (uuid.UUID, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || parts[0] != "test-procedures" || parts[2] != "attachments" || parts[3] == "" || parts[3] == "." || parts[3] == ".." {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ValidateAttachments checks that every attachment of the steps has a
+// file name and a path attachments are uploaded to. Returns
+// ErrInvalidAttachment, with the step's position, otherwise.
+func (s Steps) ValidateAttachments() error {
+	for i, step := range s {
+		for _, attachment := range step.Attachments {
+			if strings.TrimSpace(attachment.FileName) == "" {
+				return fmt.Errorf("step %d: %w: file_name is required", i+1, ErrInvalidAttachment)
+			}
+			if _, ok := AttachmentProcedure(attachment.Path); !ok {
+				return fmt.Errorf("step %d: %w: %q is not an uploaded attachment", i+1, ErrInvalidAttachment, attachment.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// StepAttachment records a file uploaded for the steps of a test procedure
+// so that the storage it takes up can be accounted for. Like step images,
+// attachments are named after their content, so a file uploaded twice for
+// the same procedure is stored and recorded once.
+type StepAttachment struct {
+	Path            string    `json:"path" gorm:"type:varchar(512);primaryKey"`
+	TestProcedureID uuid.UUID `json:"test_procedure_id" gorm:"type:char(36);not null;index:idx_test_procedure_step_attachments_test_procedure_id"`
+	FileSize        int64     `json:"file_size" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (a *StepAttachment) TableName() string {
+	return "test_procedure_step_attachments"
+}
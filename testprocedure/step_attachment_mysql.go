@@ -0,0 +1,64 @@
+package testprocedure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLStepAttachmentStore implements StepAttachmentStore using GORM and MySQL.
+type MySQLStepAttachmentStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStepAttachmentStore creates a new MySQL-backed step attachment store.
+func NewMySQLStepAttachmentStore(db *gorm.DB, log logger.Logger) *MySQLStepAttachmentStore {
+	return &MySQLStepAttachmentStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create records a step attachment. Recording a path that is already
+// recorded does nothing.
+func (s *MySQLStepAttachmentStore) Create(ctx context.Context, attachment *StepAttachment) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(attachment).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to record step attachment", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": attachment.TestProcedureID.String(),
+			"path":              attachment.Path,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// GetByPath retrieves a step attachment by its storage path.
+func (s *MySQLStepAttachmentStore) GetByPath(ctx context.Context, path string) (*StepAttachment, error) {
+	var attachment StepAttachment
+	err := s.db.WithContext(ctx).
+		Where("path = ?", path).
+		First(&attachment).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStepAttachmentNotFound
+		}
+		s.logger.Error(ctx, "failed to get step attachment", map[string]interface{}{
+			"error": err.Error(),
+			"path":  path,
+		})
+		return nil, err
+	}
+
+	return &attachment, nil
+}
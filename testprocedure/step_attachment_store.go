@@ -0,0 +1,14 @@
+package testprocedure
+
+import "context"
+
+// StepAttachmentStore defines the interface for step attachment persistence
+// operations.
+type StepAttachmentStore interface {
+	// Create records a step attachment. Recording a path that is already
+	// recorded does nothing.
+	Create(ctx context.Context, attachment *StepAttachment) error
+
+	// GetByPath retrieves a step attachment by its storage path.
+	GetByPath(ctx context.Context, path string) (*StepAttachment, error)
+}
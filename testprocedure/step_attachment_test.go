@@ -0,0 +1,110 @@
+package testprocedure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentProcedure(t *testing.T) {
+	procedureID := uuid.New()
+
+	id, ok := AttachmentProcedure(AttachmentPath(procedureID, "abc.csv"))
+	require.True(t, ok)
+	assert.Equal(t, procedureID, id)
+
+	for _, path := range []string{
+		"",
+		"test-procedures/" + procedureID.String() + "/steps/abc.png",
+		"test-procedures/" + procedureID.String() + "/attachments/",
+		"test-procedures/" + procedureID.String() + "/attachments/..",
+		"test-procedures/not-a-uuid/attachments/abc.csv",
+		"test-runs/" + procedureID.String() + "/attachments/abc.csv",
+		"test-procedures/" + procedureID.String() + "/attachments/a/b.csv",
+	} {
+		_, ok := AttachmentProcedure(path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestSteps_ValidateAttachments(t *testing.T) {
+	path := AttachmentPath(uuid.New(), "abc.csv")
+
+	tests := []struct {
+		name    string
+		steps   Steps
+		wantErr error
+	}{
+		{name: "no attachments", steps: Steps{{Name: "Open"}}},
+		{name: "valid", steps: Steps{{Name: "Upload", Attachments: []Attachment{{Path: path, FileName: "items.csv"}}}}},
+		{
+			name:    "missing file name",
+			steps:   Steps{{Name: "Upload", Attachments: []Attachment{{Path: path}}}},
+			wantErr: ErrInvalidAttachment,
+		},
+		{
+			name:    "path that was not uploaded as an attachment",
+			steps:   Steps{{Name: "Upload", Attachments: []Attachment{{Path: "test-runs/x/video/session.mp4", FileName: "session.mp4"}}}},
+			wantErr: ErrInvalidAttachment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.steps.ValidateAttachments()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("SetSteps rejects invalid attachments", func(t *testing.T) {
+		tp := &TestProcedure{}
+		err := SetSteps(Steps{{Name: "Upload", Attachments: []Attachment{{Path: "../etc/passwd", FileName: "passwd"}}}})(tp)
+		assert.ErrorIs(t, err, ErrInvalidAttachment)
+	})
+}
+
+func TestMySQLStepAttachmentStore(t *testing.T) {
+	store := setupStepAttachmentStore(t)
+	ctx := context.Background()
+	procedureID := uuid.New()
+	path := AttachmentPath(procedureID, "abc.csv")
+
+	t.Run("missing attachment returns error", func(t *testing.T) {
+		_, err := store.GetByPath(ctx, path)
+		assert.ErrorIs(t, err, ErrStepAttachmentNotFound)
+	})
+
+	t.Run("create and get attachment", func(t *testing.T) {
+		require.NoError(t, store.Create(ctx, &StepAttachment{Path: path, TestProcedureID: procedureID, FileSize: 512}))
+
+		attachment, err := store.GetByPath(ctx, path)
+		require.NoError(t, err)
+		assert.Equal(t, procedureID, attachment.TestProcedureID)
+		assert.Equal(t, int64(512), attachment.FileSize)
+	})
+
+	t.Run("recording an attachment again is ignored", func(t *testing.T) {
+		require.NoError(t, store.Create(ctx, &StepAttachment{Path: path, TestProcedureID: procedureID, FileSize: 512}))
+	})
+}
+
+func TestAttachment_IsRecording(t *testing.T) {
+	procedureID := uuid.New()
+	for name, want := range map[string]bool{
+		"abc.mp4":  true,
+		"abc.webm": true,
+		"abc.gif":  true,
+		"abc.csv":  false,
+		"abc.png":  false,
+	} {
+		a := Attachment{Path: AttachmentPath(procedureID, name), FileName: "login" + name[3:]}
+		assert.Equal(t, want, a.IsRecording(), name)
+	}
+}
@@ -0,0 +1,27 @@
+package testprocedure
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrStepImageNotFound is returned when a step image is not found.
+var ErrStepImageNotFound = errors.New("step image not found")
+
+// StepImage records an image uploaded for the steps of a test procedure so
+// that the storage it takes up can be accounted for. Images are named after
+// their content, so an image uploaded twice for the same procedure is stored
+// and recorded once.
+type StepImage struct {
+	Path            string    `json:"path" gorm:"type:varchar(512);primaryKey"`
+	TestProcedureID uuid.UUID `json:"test_procedure_id" gorm:"type:char(36);not null;index:idx_test_procedure_step_images_test_procedure_id"`
+	FileSize        int64     `json:"file_size" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (i *StepImage) TableName() string {
+	return "test_procedure_step_images"
+}
@@ -0,0 +1,64 @@
+package testprocedure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLStepImageStore implements StepImageStore using GORM and MySQL.
+type MySQLStepImageStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStepImageStore creates a new MySQL-backed step image store.
+func NewMySQLStepImageStore(db *gorm.DB, log logger.Logger) *MySQLStepImageStore {
+	return &MySQLStepImageStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create records a step image. Recording a path that is already recorded
+// does nothing.
+func (s *MySQLStepImageStore) Create(ctx context.Context, image *StepImage) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(image).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to record step image", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": image.TestProcedureID.String(),
+			"path":              image.Path,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// GetByPath retrieves a step image by its storage path.
+func (s *MySQLStepImageStore) GetByPath(ctx context.Context, path string) (*StepImage, error) {
+	var image StepImage
+	err := s.db.WithContext(ctx).
+		Where("path = ?", path).
+		First(&image).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStepImageNotFound
+		}
+		s.logger.Error(ctx, "failed to get step image", map[string]interface{}{
+			"error": err.Error(),
+			"path":  path,
+		})
+		return nil, err
+	}
+
+	return &image, nil
+}
@@ -0,0 +1,13 @@
+package testprocedure
+
+import "context"
+
+// StepImageStore defines the interface for step image persistence operations.
+type StepImageStore interface {
+	// Create records a step image. Recording a path that is already
+	// recorded does nothing.
+	Create(ctx context.Context, image *StepImage) error
+
+	// GetByPath retrieves a step image by its storage path.
+	GetByPath(ctx context.Context, path string) (*StepImage, error)
+}
@@ -17,15 +17,31 @@ type Store interface {
 	// Update updates a test procedure with the given setters (in-place, doesn't create version).
 	Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error
 
-	// Delete deletes a test procedure (hard delete due to CASCADE).
+	// Delete soft-deletes a test procedure and all of its versions.
 	Delete(ctx context.Context, id uuid.UUID) error
 
-	// ListByProject retrieves a paginated list of latest test procedures for a specific project.
+	// Archive archives a test procedure and all of its versions.
+	Archive(ctx context.Context, id uuid.UUID) error
+
+	// Unarchive restores an archived test procedure and all of its versions.
+	Unarchive(ctx context.Context, id uuid.UUID) error
+
+	// ListByProject retrieves a paginated list of latest test procedures for a
+	// specific project, leaving out archived ones.
 	ListByProject(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]*TestProcedure, error)
 
-	// CountByProject returns the total count of latest test procedures for a specific project.
+	// CountByProject returns the total count of latest test procedures for a
+	// specific project, leaving out archived ones.
 	CountByProject(ctx context.Context, projectID uuid.UUID) (int, error)
 
+	// ListByProjectFiltered retrieves a paginated list of latest test procedures for a
+	// specific project that match the filter.
+	ListByProjectFiltered(ctx context.Context, projectID uuid.UUID, filter ListFilter, limit, offset int) ([]*TestProcedure, error)
+
+	// CountByProjectFiltered returns the total count of latest test procedures for a
+	// specific project that match the filter.
+	CountByProjectFiltered(ctx context.Context, projectID uuid.UUID, filter ListFilter) (int, error)
+
 	// CreateVersion creates a new version of an existing test procedure.
 	// This creates an immutable copy with incremented version number.
 	CreateVersion(ctx context.Context, originalID uuid.UUID) (*TestProcedure, error)
@@ -42,14 +58,39 @@ type Store interface {
 	// CreateWithDraft creates both a committed version (v1) and a draft (v0).
 	CreateWithDraft(ctx context.Context, tp *TestProcedure) (*TestProcedure, error)
 
-	// UpdateDraft updates only the draft version (v0) with the given setters.
+	// UpdateDraft updates only the draft version (v0) with the given setters,
+	// saving it as its next revision. Pass RequireRevision to update only
+	// the revision the changes were made from; ErrDraftConflict is returned
+	// if the draft has moved on.
 	UpdateDraft(ctx context.Context, procedureID uuid.UUID, setters ...UpdateSetter) error
 
 	// ResetDraft resets the draft (v0) to match the latest committed version.
 	ResetDraft(ctx context.Context, procedureID uuid.UUID) error
 
+	// ListDraftSnapshots retrieves the snapshots of the draft's content
+	// before its last MaxDraftSnapshots changes, newest first.
+	ListDraftSnapshots(ctx context.Context, procedureID uuid.UUID) ([]*DraftSnapshot, error)
+
+	// RestoreDraftSnapshot sets the draft's content back to one of its
+	// snapshots and returns the draft. ErrDraftSnapshotNotFound is returned
+	// if the snapshot is not one of the draft's.
+	RestoreDraftSnapshot(ctx context.Context, procedureID, snapshotID uuid.UUID) (*TestProcedure, error)
+
 	// CommitDraft creates a new committed version from the draft, incrementing version number.
-	CommitDraft(ctx context.Context, procedureID uuid.UUID) (*TestProcedure, error)
+	// The note is stored with the version and must have a message.
+	CommitDraft(ctx context.Context, procedureID uuid.UUID, note CommitNote) (*TestProcedure, error)
+
+	// CommitDraftPending creates a new committed version from the draft
+	// without making it the latest version, so that it can be reviewed.
+	CommitDraftPending(ctx context.Context, procedureID uuid.UUID, note CommitNote) (*TestProcedure, error)
+
+	// PublishVersion makes a committed version the latest version of its
+	// procedure.
+	PublishVersion(ctx context.Context, versionID uuid.UUID) error
+
+	// ListByStepGroup retrieves the drafts and latest committed versions of
+	// the procedures in a project whose steps reference a step group.
+	ListByStepGroup(ctx context.Context, projectID, groupID uuid.UUID) ([]*TestProcedure, error)
 }
 
 // UpdateSetter is a function that updates a test procedure field.
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/markdown"
 	"gorm.io/gorm"
 )
 
@@ -35,6 +36,14 @@ var (
 
 	// ErrInvalidStepName is returned when a step name is empty.
 	ErrInvalidStepName = errors.New("step name is required")
+
+	// ErrDraftConflict is returned when a draft is updated from a revision
+	// that another update has since replaced.
+	ErrDraftConflict = errors.New("draft was changed by another update")
+
+	// ErrProcedureArchived is returned when a run is created of an archived
+	// procedure.
+	ErrProcedureArchived = errors.New("test procedure is archived")
 )
 
 // TestStep represents a single step in a test procedure.
@@ -42,12 +51,51 @@ type TestStep struct {
 	Name         string   `json:"name"`
 	Instructions string   `json:"instructions"`
 	ImagePaths   []string `json:"image_paths"`
+
+	// ExpectedResult is what the tester should see once the step is done,
+	// kept apart from the instructions so it can be checked on its own.
+	ExpectedResult string `json:"expected_result,omitempty"`
+
+	// Attachments are files the tester uses in the step, such as a sample
+	// file to upload.
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// GroupID, if set, makes the step a reference to a shared step group
+	// from the project's step library, which is replaced by the group's
+	// steps when the procedure is run, exported or scripted.
+	// GroupRevision is the revision of the group the procedure was last
+	// reviewed against.
+	GroupID       *uuid.UUID `json:"group_id,omitempty"`
+	GroupRevision uint       `json:"group_revision,omitempty"`
+
+	// Condition, if set, makes the step a branch point that decides which
+	// of the steps after it are run.
+	Condition *StepCondition `json:"condition,omitempty"`
+}
+
+// IsGroupReference reports whether the step stands in for a step group.
+func (s TestStep) IsGroupReference() bool {
+	return s.GroupID != nil
 }
 
 // Steps represents the JSON steps for a test procedure.
 // It's a custom type to handle JSON marshaling/unmarshaling.
 type Steps []TestStep
 
+// SanitizeInstructions returns a copy of the steps with what is unsafe to
+// show removed from the Markdown of their instructions.
+func (s Steps) SanitizeInstructions() Steps {
+	if s == nil {
+		return nil
+	}
+	sanitized := make(Steps, len(s))
+	for i, step := range s {
+		step.Instructions = markdown.Sanitize(step.Instructions)
+		sanitized[i] = step
+	}
+	return sanitized
+}
+
 // Value implements the driver.Valuer interface for database storage.
 func (s Steps) Value() (driver.Value, error) {
 	if s == nil {
@@ -78,17 +126,44 @@ func (s *Steps) Scan(value interface{}) error {
 
 // TestProcedure represents a test procedure in the system.
 type TestProcedure struct {
-	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
-	ProjectID   uuid.UUID  `json:"project_id" gorm:"type:char(36);not null;index:idx_project_id"`
-	Name        string     `json:"name" gorm:"not null"`
-	Description string     `json:"description" gorm:"type:text"`
-	Steps       Steps      `json:"steps" gorm:"type:json"`
-	CreatedBy   uuid.UUID  `json:"created_by" gorm:"type:char(36);not null;index:idx_created_by"`
-	Version     uint       `json:"version" gorm:"not null;default:0;index:idx_version"`
-	IsLatest    bool       `json:"is_latest" gorm:"not null;default:false;index:idx_is_latest"`
-	ParentID    *uuid.UUID `json:"parent_id,omitempty" gorm:"type:char(36);index:idx_parent_id"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID          uuid.UUID      `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID   uuid.UUID      `json:"project_id" gorm:"type:char(36);not null;index:idx_project_id"`
+	Name        string         `json:"name" gorm:"not null"`
+	Description string         `json:"description" gorm:"type:text"`
+	Steps       Steps          `json:"steps" gorm:"type:json"`
+	CreatedBy   uuid.UUID      `json:"created_by" gorm:"type:char(36);not null;index:idx_created_by"`
+	Version     uint           `json:"version" gorm:"not null;default:0;index:idx_version"`
+	IsLatest    bool           `json:"is_latest" gorm:"not null;default:false;index:idx_is_latest"`
+	ParentID    *uuid.UUID     `json:"parent_id,omitempty" gorm:"type:char(36);index:idx_parent_id"`
+	NeedsReview bool           `json:"needs_review" gorm:"not null;default:false"`
+	Parameters  Parameters     `json:"parameters" gorm:"type:json"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Revision counts the saves of a draft, so that an update made from a
+	// stale copy is detected instead of overwriting newer edits.
+	Revision uint `json:"revision" gorm:"not null;default:0"`
+
+	// Preconditions must be in place before the procedure is run; a run
+	// only starts once someone acknowledges them.
+	Preconditions Preconditions `json:"preconditions" gorm:"type:json"`
+
+	// CommitMessage and ChangeSummary describe the change the version made
+	// when its draft was committed. Drafts and versions created otherwise
+	// have neither.
+	CommitMessage string `json:"commit_message,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	ChangeSummary string `json:"change_summary,omitempty" gorm:"type:text"`
+
+	// ArchivedAt is when the procedure was archived, and is set on every one
+	// of its versions. Archived procedures are left out of lists and cannot
+	// be run, but their versions and runs stay readable.
+	ArchivedAt *time.Time `json:"archived_at,omitempty" gorm:"index:idx_archived_at"`
+}
+
+// IsArchived reports whether the procedure is archived.
+func (tp *TestProcedure) IsArchived() bool {
+	return tp.ArchivedAt != nil
 }
 
 // BeforeCreate hook to generate UUID before creating a new test procedure
@@ -116,5 +191,37 @@ func (tp *TestProcedure) Validate() error {
 			return fmt.Errorf("step %d: %w", i+1, ErrInvalidStepName)
 		}
 	}
-	return nil
+	if err := tp.Steps.ValidateConditions(); err != nil {
+		return err
+	}
+	if err := tp.Steps.ValidateAttachments(); err != nil {
+		return err
+	}
+	if err := tp.Preconditions.Validate(); err != nil {
+		return err
+	}
+	return tp.validateParameters()
+}
+
+// ListFilter narrows the procedures listed for a project. The zero value
+// matches every procedure that is not archived.
+type ListFilter struct {
+	// IncludeArchived lists archived procedures too.
+	IncludeArchived bool
+
+
+	// RootIDs, if non-nil, limits the list to the procedures whose first
+	// version is one of RootIDs. An empty non-nil slice matches nothing.
+	RootIDs []uuid.UUID
+}
+
+// scope adds the filter's conditions to a query.
+func (f ListFilter) scope(db *gorm.DB) *gorm.DB {
+	if !f.IncludeArchived {
+		db = db.Where("archived_at IS NULL")
+	}
+	if f.RootIDs != nil {
+		db = db.Where("(id IN ? OR parent_id IN ?)", f.RootIDs, f.RootIDs)
+	}
+	return db
 }
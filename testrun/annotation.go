@@ -0,0 +1,140 @@
+package testrun
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxAnnotationsPerAsset is the most annotations an asset can have.
+	MaxAnnotationsPerAsset = 100
+
+	// MaxAnnotationTextLength is the longest text annotation in characters.
+	MaxAnnotationTextLength = 200
+
+	// DefaultAnnotationColor is used for annotations saved without a color.
+	DefaultAnnotationColor = "#FF0000"
+)
+
+var (
+	// ErrInvalidAnnotationShape is returned when an annotation's shape is
+	// unknown.
+	ErrInvalidAnnotationShape = errors.New("invalid annotation shape")
+
+	// ErrInvalidAnnotation is returned when an annotation's geometry or text
+	// does not fit its shape.
+	ErrInvalidAnnotation = errors.New("invalid annotation")
+
+	// ErrInvalidAnnotationColor is returned when a color is not #RRGGBB.
+	ErrInvalidAnnotationColor = errors.New("annotation color must be #RRGGBB")
+
+	// ErrTooManyAnnotations is returned when an asset would have more than
+	// MaxAnnotationsPerAsset annotations.
+	ErrTooManyAnnotations = fmt.Errorf("an asset can have at most %d annotations", MaxAnnotationsPerAsset)
+)
+
+var annotationColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// AnnotationShape is the kind of overlay an annotation draws.
+type AnnotationShape string
+
+const (
+	// AnnotationShapeRectangle outlines Width x Height pixels from (X, Y).
+	AnnotationShapeRectangle AnnotationShape = "rectangle"
+	// AnnotationShapeArrow points from (X, Y) to (EndX, EndY).
+	AnnotationShapeArrow AnnotationShape = "arrow"
+	// AnnotationShapeText writes Text with its top-left corner at (X, Y).
+	AnnotationShapeText AnnotationShape = "text"
+)
+
+// IsValid checks if the annotation shape is valid.
+func (s AnnotationShape) IsValid() bool {
+	switch s {
+	case AnnotationShapeRectangle, AnnotationShapeArrow, AnnotationShapeText:
+		return true
+	default:
+		return false
+	}
+}
+
+// AssetAnnotation is an overlay drawn over an image asset, such as a box
+// around the button to click. Coordinates are in pixels of the original
+// image, with the origin at its top-left corner.
+type AssetAnnotation struct {
+	ID      uuid.UUID       `json:"id" gorm:"type:char(36);primaryKey"`
+	AssetID uuid.UUID       `json:"asset_id" gorm:"type:char(36);not null;index:idx_test_run_asset_annotations_asset_id"`
+	Shape   AnnotationShape `json:"shape" gorm:"type:varchar(20);not null"`
+	X       int             `json:"x" gorm:"not null"`
+	Y       int             `json:"y" gorm:"not null"`
+	Width   int             `json:"width,omitempty"`
+	Height  int             `json:"height,omitempty"`
+	EndX    int             `json:"end_x,omitempty"`
+	EndY    int             `json:"end_y,omitempty"`
+	Text    string          `json:"text,omitempty" gorm:"type:varchar(800)"`
+	Color   string          `json:"color" gorm:"type:varchar(7);not null"`
+	// Position orders annotations; later annotations are drawn on top.
+	Position  int       `json:"position" gorm:"not null"`
+	CreatedBy uuid.UUID `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new annotation.
+func (a *AssetAnnotation) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM.
+func (a *AssetAnnotation) TableName() string {
+	return "test_run_asset_annotations"
+}
+
+// Validate checks the annotation's fields for its shape, defaulting its
+// color.
+func (a *AssetAnnotation) Validate() error {
+	if !a.Shape.IsValid() {
+		return ErrInvalidAnnotationShape
+	}
+	if a.Color == "" {
+		a.Color = DefaultAnnotationColor
+	}
+	if !annotationColorPattern.MatchString(a.Color) {
+		return ErrInvalidAnnotationColor
+	}
+	a.Color = strings.ToUpper(a.Color)
+	if a.X < 0 || a.Y < 0 {
+		return fmt.Errorf("%w: coordinates must not be negative", ErrInvalidAnnotation)
+	}
+
+	switch a.Shape {
+	case AnnotationShapeRectangle:
+		if a.Width <= 0 || a.Height <= 0 {
+			return fmt.Errorf("%w: rectangle width and height must be positive", ErrInvalidAnnotation)
+		}
+	case AnnotationShapeArrow:
+		if a.EndX < 0 || a.EndY < 0 {
+			return fmt.Errorf("%w: coordinates must not be negative", ErrInvalidAnnotation)
+		}
+		if a.EndX == a.X && a.EndY == a.Y {
+			return fmt.Errorf("%w: arrow must have a length", ErrInvalidAnnotation)
+		}
+	case AnnotationShapeText:
+		a.Text = strings.TrimSpace(a.Text)
+		if a.Text == "" {
+			return fmt.Errorf("%w: text is required", ErrInvalidAnnotation)
+		}
+		if utf8.RuneCountInString(a.Text) > MaxAnnotationTextLength {
+			return fmt.Errorf("%w: text must be at most %d characters", ErrInvalidAnnotation, MaxAnnotationTextLength)
+		}
+	}
+	return nil
+}
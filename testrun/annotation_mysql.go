@@ -0,0 +1,109 @@
+package testrun
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLAnnotationStore implements AnnotationStore using GORM and MySQL.
+type MySQLAnnotationStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLAnnotationStore creates a new MySQL-backed annotation store.
+func NewMySQLAnnotationStore(db *gorm.DB, log logger.Logger) *MySQLAnnotationStore {
+	return &MySQLAnnotationStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// ListByAsset retrieves the annotations of an asset in drawing order.
+func (s *MySQLAnnotationStore) ListByAsset(ctx context.Context, assetID uuid.UUID) ([]*AssetAnnotation, error) {
+	var annotations []*AssetAnnotation
+	err := s.db.WithContext(ctx).
+		Where("asset_id = ?", assetID).
+		Order("position ASC").
+		Find(&annotations).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list annotations by asset", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": assetID.String(),
+		})
+		return nil, err
+	}
+
+	return annotations, nil
+}
+
+// ListByAssets retrieves the annotations of several assets in drawing order,
+// grouped by asset ID.
+func (s *MySQLAnnotationStore) ListByAssets(ctx context.Context, assetIDs []uuid.UUID) (map[uuid.UUID][]*AssetAnnotation, error) {
+	byAsset := make(map[uuid.UUID][]*AssetAnnotation)
+	if len(assetIDs) == 0 {
+		return byAsset, nil
+	}
+
+	var annotations []*AssetAnnotation
+	err := s.db.WithContext(ctx).
+		Where("asset_id IN ?", assetIDs).
+		Order("position ASC").
+		Find(&annotations).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list annotations by assets", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	for _, a := range annotations {
+		byAsset[a.AssetID] = append(byAsset[a.AssetID], a)
+	}
+	return byAsset, nil
+}
+
+// Replace replaces the annotations of an asset, drawing them in the given
+// order.
+func (s *MySQLAnnotationStore) Replace(ctx context.Context, assetID uuid.UUID, annotations []*AssetAnnotation) error {
+	if len(annotations) > MaxAnnotationsPerAsset {
+		return ErrTooManyAnnotations
+	}
+	for i, a := range annotations {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+		a.ID = uuid.Nil
+		a.AssetID = assetID
+		a.Position = i
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("asset_id = ?", assetID).Delete(&AssetAnnotation{}).Error; err != nil {
+			return err
+		}
+		if len(annotations) == 0 {
+			return nil
+		}
+		return tx.Create(&annotations).Error
+	})
+	if err != nil {
+		s.logger.Error(ctx, "failed to replace annotations", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": assetID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "annotations replaced", map[string]interface{}{
+		"asset_id": assetID.String(),
+		"count":    len(annotations),
+	})
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package testrun
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// AnnotationStore defines the interface for asset annotation persistence
+// operations.
+type AnnotationStore interface {
+	// ListByAsset retrieves the annotations of an asset in drawing order.
+	ListByAsset(ctx context.Context, assetID uuid.UUID) ([]*AssetAnnotation, error)
+
+	// ListByAssets retrieves the annotations of several assets in drawing
+	// order, grouped by asset ID.
+	ListByAssets(ctx context.Context, assetIDs []uuid.UUID) (map[uuid.UUID][]*AssetAnnotation, error)
+
+	// Replace replaces the annotations of an asset, drawing them in the
+	// given order.
+	Replace(ctx context.Context, assetID uuid.UUID, annotations []*AssetAnnotation) error
+}
@@ -0,0 +1,50 @@
+package testrun
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetAnnotation_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation AssetAnnotation
+		wantErr    error
+	}{
+		{"rectangle", AssetAnnotation{Shape: AnnotationShapeRectangle, X: 10, Y: 20, Width: 100, Height: 40}, nil},
+		{"arrow", AssetAnnotation{Shape: AnnotationShapeArrow, X: 10, Y: 20, EndX: 60, EndY: 20}, nil},
+		{"text", AssetAnnotation{Shape: AnnotationShapeText, X: 10, Y: 20, Text: "Click here"}, nil},
+		{"custom color", AssetAnnotation{Shape: AnnotationShapeRectangle, Width: 1, Height: 1, Color: "#00ff00"}, nil},
+		{"unknown shape", AssetAnnotation{Shape: "circle"}, ErrInvalidAnnotationShape},
+		{"invalid color", AssetAnnotation{Shape: AnnotationShapeRectangle, Width: 1, Height: 1, Color: "red"}, ErrInvalidAnnotationColor},
+		{"negative coordinates", AssetAnnotation{Shape: AnnotationShapeRectangle, X: -1, Width: 1, Height: 1}, ErrInvalidAnnotation},
+		{"empty rectangle", AssetAnnotation{Shape: AnnotationShapeRectangle, Width: 10}, ErrInvalidAnnotation},
+		{"zero-length arrow", AssetAnnotation{Shape: AnnotationShapeArrow, X: 5, Y: 5, EndX: 5, EndY: 5}, ErrInvalidAnnotation},
+		{"blank text", AssetAnnotation{Shape: AnnotationShapeText, Text: "  "}, ErrInvalidAnnotation},
+		{"text too long", AssetAnnotation{Shape: AnnotationShapeText, Text: strings.Repeat("a", MaxAnnotationTextLength+1)}, ErrInvalidAnnotation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.annotation.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("defaults and normalizes color", func(t *testing.T) {
+		a := AssetAnnotation{Shape: AnnotationShapeText, Text: " Pay "}
+		assert.NoError(t, a.Validate())
+		assert.Equal(t, DefaultAnnotationColor, a.Color)
+		assert.Equal(t, "Pay", a.Text)
+
+		a.Color = "#00ff00"
+		assert.NoError(t, a.Validate())
+		assert.Equal(t, "#00FF00", a.Color)
+	})
+}
@@ -45,6 +45,26 @@ func (at AssetType) IsValid() bool {
 	}
 }
 
+// AssetVariant identifies an artifact derived from an uploaded asset.
+type AssetVariant string
+
+const (
+	AssetVariantThumbnail AssetVariant = "thumbnail"
+	AssetVariantMP4       AssetVariant = "mp4"
+	AssetVariantWebM      AssetVariant = "webm"
+)
+
+// ProcessingStatus tracks the generation of derived artifacts for an
+// uploaded video asset.
+type ProcessingStatus string
+
+const (
+	ProcessingStatusPending    ProcessingStatus = "pending"
+	ProcessingStatusProcessing ProcessingStatus = "processing"
+	ProcessingStatusCompleted  ProcessingStatus = "completed"
+	ProcessingStatusFailed     ProcessingStatus = "failed"
+)
+
 // TestRunAsset represents an asset associated with a test run.
 type TestRunAsset struct {
 	ID          uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
@@ -56,7 +76,19 @@ type TestRunAsset struct {
 	MimeType    string    `json:"mime_type,omitempty" gorm:"type:varchar(128)"`
 	Description string    `json:"description,omitempty" gorm:"type:text"`
 	StepIndex   *int      `json:"step_index,omitempty" gorm:"column:step_index"`
-	UploadedAt  time.Time `json:"uploaded_at"`
+	// StepNoteID attaches the asset to a step note.
+	StepNoteID *uuid.UUID `json:"step_note_id,omitempty" gorm:"type:char(36);index:idx_test_run_assets_step_note_id"`
+	// SourceAssetID and Variant are set on artifacts derived from another
+	// asset, such as the thumbnail of a video.
+	SourceAssetID *uuid.UUID   `json:"source_asset_id,omitempty" gorm:"type:char(36);index:idx_test_run_assets_source_asset_id"`
+	Variant       AssetVariant `json:"variant,omitempty" gorm:"type:varchar(20)"`
+	// ProcessingStatus is set on video assets whose derived artifacts are
+	// generated in the background.
+	ProcessingStatus ProcessingStatus `json:"processing_status,omitempty" gorm:"type:varchar(20);index:idx_test_run_assets_processing_status"`
+	ProcessingError  string           `json:"processing_error,omitempty" gorm:"type:text"`
+	UploadedAt       time.Time        `json:"uploaded_at"`
+	// Derivatives are the artifacts derived from the asset.
+	Derivatives []*TestRunAsset `json:"derivatives,omitempty" gorm:"-"`
 }
 
 // BeforeCreate hook to generate UUID before creating a new test run asset
@@ -83,3 +115,32 @@ func (a *TestRunAsset) Validate() error {
 	}
 	return nil
 }
+
+// IsDerived reports whether the asset was derived from another asset.
+func (a *TestRunAsset) IsDerived() bool {
+	return a.SourceAssetID != nil
+}
+
+// NestDerivatives moves derived assets into the Derivatives of their source
+// and returns the remaining assets in their original order. Derived assets
+// whose source is not in assets are returned as is.
+func NestDerivatives(assets []*TestRunAsset) []*TestRunAsset {
+	sources := make(map[uuid.UUID]*TestRunAsset, len(assets))
+	for _, a := range assets {
+		if !a.IsDerived() {
+			sources[a.ID] = a
+		}
+	}
+
+	nested := make([]*TestRunAsset, 0, len(assets))
+	for _, a := range assets {
+		if a.IsDerived() {
+			if source, ok := sources[*a.SourceAssetID]; ok {
+				source.Derivatives = append(source.Derivatives, a)
+				continue
+			}
+		}
+		nested = append(nested, a)
+	}
+	return nested
+}
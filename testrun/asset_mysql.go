@@ -87,6 +87,70 @@ func (s *MySQLAssetStore) ListByTestRun(ctx context.Context, testRunID uuid.UUID
 	return assets, nil
 }
 
+// ListDerived retrieves the artifacts derived from an asset.
+func (s *MySQLAssetStore) ListDerived(ctx context.Context, sourceID uuid.UUID) ([]*TestRunAsset, error) {
+	var assets []*TestRunAsset
+	err := s.db.WithContext(ctx).
+		Where("source_asset_id = ?", sourceID).
+		Order("uploaded_at ASC").
+		Find(&assets).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list derived assets", map[string]interface{}{
+			"error":    err.Error(),
+			"asset_id": sourceID.String(),
+		})
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// ListByProcessingStatus retrieves assets in any of the given processing
+// statuses, oldest first.
+func (s *MySQLAssetStore) ListByProcessingStatus(ctx context.Context, statuses ...ProcessingStatus) ([]*TestRunAsset, error) {
+	var assets []*TestRunAsset
+	err := s.db.WithContext(ctx).
+		Where("processing_status IN ?", statuses).
+		Order("uploaded_at ASC").
+		Find(&assets).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list assets by processing status", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// UpdateProcessingStatus sets the processing status of an asset and the
+// error that failed it, if any.
+func (s *MySQLAssetStore) UpdateProcessingStatus(ctx context.Context, id uuid.UUID, status ProcessingStatus, processingError string) error {
+	result := s.db.WithContext(ctx).
+		Model(&TestRunAsset{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"processing_status": status,
+			"processing_error":  processingError,
+		})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to update asset processing status", map[string]interface{}{
+			"error":    result.Error.Error(),
+			"asset_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrAssetNotFound
+	}
+
+	return nil
+}
+
 // Delete deletes an asset by ID.
 func (s *MySQLAssetStore) Delete(ctx context.Context, id uuid.UUID) error {
 	result := s.db.WithContext(ctx).
@@ -17,6 +17,17 @@ type AssetStore interface {
 	// ListByTestRun retrieves all assets for a specific test run.
 	ListByTestRun(ctx context.Context, testRunID uuid.UUID) ([]*TestRunAsset, error)
 
+	// ListDerived retrieves the artifacts derived from an asset.
+	ListDerived(ctx context.Context, sourceID uuid.UUID) ([]*TestRunAsset, error)
+
+	// ListByProcessingStatus retrieves assets in any of the given processing
+	// statuses, oldest first.
+	ListByProcessingStatus(ctx context.Context, statuses ...ProcessingStatus) ([]*TestRunAsset, error)
+
+	// UpdateProcessingStatus sets the processing status of an asset and the
+	// error that failed it, if any.
+	UpdateProcessingStatus(ctx context.Context, id uuid.UUID, status ProcessingStatus, processingError string) error
+
 	// Delete deletes an asset by ID.
 	Delete(ctx context.Context, id uuid.UUID) error
 }
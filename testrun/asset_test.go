@@ -100,3 +100,18 @@ func TestTestRunAsset_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestNestDerivatives(t *testing.T) {
+	video := &TestRunAsset{ID: uuid.New(), AssetType: AssetTypeVideo}
+	image := &TestRunAsset{ID: uuid.New(), AssetType: AssetTypeImage}
+	thumbnail := &TestRunAsset{ID: uuid.New(), AssetType: AssetTypeImage, SourceAssetID: &video.ID, Variant: AssetVariantThumbnail}
+	mp4 := &TestRunAsset{ID: uuid.New(), AssetType: AssetTypeVideo, SourceAssetID: &video.ID, Variant: AssetVariantMP4}
+	missingID := uuid.New()
+	orphan := &TestRunAsset{ID: uuid.New(), AssetType: AssetTypeImage, SourceAssetID: &missingID, Variant: AssetVariantThumbnail}
+
+	nested := NestDerivatives([]*TestRunAsset{video, thumbnail, image, mp4, orphan})
+
+	assert.Equal(t, []*TestRunAsset{video, image, orphan}, nested)
+	assert.Equal(t, []*TestRunAsset{thumbnail, mp4}, video.Derivatives)
+	assert.Empty(t, image.Derivatives)
+}
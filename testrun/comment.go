@@ -0,0 +1,102 @@
+package testrun
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxCommentLength is the maximum length of a comment body in characters.
+const MaxCommentLength = 10000
+
+var (
+	// ErrCommentNotFound is returned when a comment is not found.
+	ErrCommentNotFound = errors.New("comment not found")
+
+	// ErrInvalidCommentBody is returned when a comment body is empty or too long.
+	ErrInvalidCommentBody = errors.New("comment body must be between 1 and 10000 characters")
+
+	// ErrInvalidCommentAuthor is returned when author_id is not set.
+	ErrInvalidCommentAuthor = errors.New("author_id is required")
+
+	// ErrInvalidCommentParent is returned when a reply's parent is not a
+	// top-level comment on the same run and step.
+	ErrInvalidCommentParent = errors.New("replies must be to a top-level comment on the same run and step")
+)
+
+// mentionPattern matches @username mentions. The @ must not follow a word
+// character, so email addresses are not mistaken for mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]*\w)`)
+
+// Comment is a comment in a test run's discussion. A comment is about the
+// whole run when StepIndex is nil and about one procedure step otherwise.
+// Threads are one level deep: replies set ParentID to a top-level comment.
+type Comment struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	TestRunID uuid.UUID  `json:"test_run_id" gorm:"type:char(36);not null;index:idx_test_run_comments_run_created,priority:1"`
+	StepIndex *int       `json:"step_index"`
+	ParentID  *uuid.UUID `json:"parent_id" gorm:"type:char(36);index:idx_test_run_comments_parent_id"`
+	AuthorID  uuid.UUID  `json:"author_id" gorm:"type:char(36);not null"`
+	Body      string     `json:"body" gorm:"type:text;not null"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index:idx_test_run_comments_run_created,priority:2"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new comment.
+func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM.
+func (c *Comment) TableName() string {
+	return "test_run_comments"
+}
+
+// Validate checks if the comment has valid required fields.
+func (c *Comment) Validate() error {
+	if c.TestRunID == uuid.Nil {
+		return ErrInvalidTestRunID
+	}
+	if c.AuthorID == uuid.Nil {
+		return ErrInvalidCommentAuthor
+	}
+	return ValidateCommentBody(c.Body)
+}
+
+// ValidateCommentBody checks that a comment body is not blank and not too
+// long.
+func ValidateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" || utf8.RuneCountInString(body) > MaxCommentLength {
+		return ErrInvalidCommentBody
+	}
+	return nil
+}
+
+// Mentions returns the usernames @mentioned in the comment, without
+// duplicates, in the order they first appear.
+func (c *Comment) Mentions() []string {
+	return ParseMentions(c.Body)
+}
+
+// ParseMentions returns the usernames @mentioned in text, without
+// duplicates, in the order they first appear.
+func ParseMentions(text string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			mentions = append(mentions, m[1])
+		}
+	}
+	return mentions
+}
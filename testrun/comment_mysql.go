@@ -0,0 +1,165 @@
+package testrun
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLCommentStore implements CommentStore using GORM and MySQL.
+type MySQLCommentStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLCommentStore creates a new MySQL-backed comment store.
+func NewMySQLCommentStore(db *gorm.DB, log logger.Logger) *MySQLCommentStore {
+	return &MySQLCommentStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new comment.
+func (s *MySQLCommentStore) Create(ctx context.Context, comment *Comment) error {
+	if err := comment.Validate(); err != nil {
+		return err
+	}
+
+	if comment.ParentID != nil {
+		parent, err := s.GetByID(ctx, *comment.ParentID)
+		if err != nil {
+			if errors.Is(err, ErrCommentNotFound) {
+				return ErrInvalidCommentParent
+			}
+			return err
+		}
+		if parent.ParentID != nil || parent.TestRunID != comment.TestRunID || !sameStep(parent.StepIndex, comment.StepIndex) {
+			return ErrInvalidCommentParent
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Create(comment).Error; err != nil {
+		s.logger.Error(ctx, "failed to create comment", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": comment.TestRunID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "comment created", map[string]interface{}{
+		"comment_id":  comment.ID.String(),
+		"test_run_id": comment.TestRunID.String(),
+	})
+
+	return nil
+}
+
+// GetByID retrieves a comment by its ID.
+func (s *MySQLCommentStore) GetByID(ctx context.Context, id uuid.UUID) (*Comment, error) {
+	var comment Comment
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&comment).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		s.logger.Error(ctx, "failed to get comment", map[string]interface{}{
+			"error":      err.Error(),
+			"comment_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// ListByTestRun retrieves the comments on a test run, oldest first.
+func (s *MySQLCommentStore) ListByTestRun(ctx context.Context, testRunID uuid.UUID, stepIndex *int) ([]*Comment, error) {
+	query := s.db.WithContext(ctx).Where("test_run_id = ?", testRunID)
+	if stepIndex != nil {
+		query = query.Where("step_index = ?", *stepIndex)
+	}
+
+	var comments []*Comment
+	if err := query.Order("created_at ASC, id ASC").Find(&comments).Error; err != nil {
+		s.logger.Error(ctx, "failed to list comments by test run", map[string]interface{}{
+			"error":       err.Error(),
+			"test_run_id": testRunID.String(),
+		})
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// UpdateBody replaces a comment's body and records when it was edited.
+func (s *MySQLCommentStore) UpdateBody(ctx context.Context, id uuid.UUID, body string) error {
+	if err := ValidateCommentBody(body); err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).
+		Model(&Comment{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"body":      body,
+			"edited_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to update comment", map[string]interface{}{
+			"error":      result.Error.Error(),
+			"comment_id": id.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a comment and its replies.
+func (s *MySQLCommentStore) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("parent_id = ?", id).Delete(&Comment{}).Error; err != nil {
+			s.logger.Error(ctx, "failed to delete comment replies", map[string]interface{}{
+				"error":      err.Error(),
+				"comment_id": id.String(),
+			})
+			return err
+		}
+
+		result := tx.Where("id = ?", id).Delete(&Comment{})
+		if result.Error != nil {
+			s.logger.Error(ctx, "failed to delete comment", map[string]interface{}{
+				"error":      result.Error.Error(),
+				"comment_id": id.String(),
+			})
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCommentNotFound
+		}
+
+		s.logger.Info(ctx, "comment deleted", map[string]interface{}{
+			"comment_id": id.String(),
+		})
+		return nil
+	})
+}
+
+// sameStep reports whether two optional step indexes refer to the same step,
+// treating nil as the run itself.
+func sameStep(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,28 @@
+package testrun
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CommentStore defines the interface for test run comment persistence
+// operations.
+type CommentStore interface {
+	// Create creates a new comment. A reply's parent must be a top-level
+	// comment on the same run and step.
+	Create(ctx context.Context, comment *Comment) error
+
+	// GetByID retrieves a comment by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*Comment, error)
+
+	// ListByTestRun retrieves the comments on a test run, oldest first. If
+	// stepIndex is not nil, only comments on that step are returned.
+	ListByTestRun(ctx context.Context, testRunID uuid.UUID, stepIndex *int) ([]*Comment, error)
+
+	// UpdateBody replaces a comment's body and records when it was edited.
+	UpdateBody(ctx context.Context, id uuid.UUID, body string) error
+
+	// Delete deletes a comment and its replies.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,73 @@
+package testrun
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComment_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment *Comment
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			comment: &Comment{TestRunID: uuid.New(), AuthorID: uuid.New(), Body: "Looks flaky"},
+		},
+		{
+			name:    "missing run",
+			comment: &Comment{AuthorID: uuid.New(), Body: "Looks flaky"},
+			wantErr: ErrInvalidTestRunID,
+		},
+		{
+			name:    "missing author",
+			comment: &Comment{TestRunID: uuid.New(), Body: "Looks flaky"},
+			wantErr: ErrInvalidCommentAuthor,
+		},
+		{
+			name:    "blank body",
+			comment: &Comment{TestRunID: uuid.New(), AuthorID: uuid.New(), Body: " \n "},
+			wantErr: ErrInvalidCommentBody,
+		},
+		{
+			name:    "body too long",
+			comment: &Comment{TestRunID: uuid.New(), AuthorID: uuid.New(), Body: strings.Repeat("é", MaxCommentLength+1)},
+			wantErr: ErrInvalidCommentBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.comment.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "no mentions", want: nil},
+		{text: "@jane can you check?", want: []string{"jane"}},
+		{text: "cc @jane, @bob.smith and @jane again", want: []string{"jane", "bob.smith"}},
+		{text: "(@ops-team) @qa_lead.", want: []string{"ops-team", "qa_lead"}},
+		{text: "mail jane@example.com or @@bob", want: nil},
+		{text: "@ alone", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseMentions(tt.text))
+		})
+	}
+}
@@ -21,6 +21,42 @@ func setupTestStore(t *testing.T) (*gorm.DB, Store, AssetStore) {
 	return db, store, assetStore
 }
 
+// setupCommentStore creates a test database and comment store for testing.
+func setupCommentStore(t *testing.T) CommentStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Comment{})
+
+	return NewMySQLCommentStore(db, logger.NewTestLogger())
+}
+
+// setupRunGroupStore creates a test database and run group store for
+// testing, with a run store over the same database.
+func setupRunGroupStore(t *testing.T) (RunGroupStore, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &TestRun{}, &RunGroup{})
+
+	log := logger.NewTestLogger()
+	return NewMySQLRunGroupStore(db, log), NewMySQLStore(db, log)
+}
+
+// setupAnnotationStore creates a test database and annotation store for
+// testing.
+func setupAnnotationStore(t *testing.T) AnnotationStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &AssetAnnotation{})
+
+	return NewMySQLAnnotationStore(db, logger.NewTestLogger())
+}
+
+// setupRunTemplateStore creates a test database and run template store for
+// testing.
+func setupRunTemplateStore(t *testing.T) RunTemplateStore {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &RunTemplate{})
+
+	return NewMySQLRunTemplateStore(db, logger.NewTestLogger())
+}
+
 // createTestRun creates a test run with default values.
 func createTestRun(testProcedureID, executedBy uuid.UUID, status Status, notes string) *TestRun {
 	return &TestRun{
@@ -0,0 +1,263 @@
+package testrun
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// ErrDifferentProcedures is returned when comparing runs of different test
+// procedures.
+var ErrDifferentProcedures = errors.New("runs belong to different test procedures")
+
+// Change describes how an item differs between the base and target runs of
+// a comparison.
+type Change string
+
+const (
+	ChangeUnchanged Change = "unchanged"
+	ChangeChanged   Change = "changed"
+	ChangeAdded     Change = "added"
+	ChangeRemoved   Change = "removed"
+)
+
+// ComparedRun is a run together with everything a comparison looks at: the
+// procedure version it executes, its step notes and its assets.
+type ComparedRun struct {
+	Run       *TestRun
+	Procedure *testprocedure.TestProcedure
+	StepNotes []*StepNote
+	Assets    []*TestRunAsset
+}
+
+// RunSummary describes one side of a comparison.
+type RunSummary struct {
+	ID               uuid.UUID  `json:"id"`
+	Status           Status     `json:"status"`
+	ProcedureVersion uint       `json:"procedure_version"`
+	Notes            string     `json:"notes"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	DurationSeconds  *float64   `json:"duration_seconds,omitempty"`
+}
+
+// TextDiff holds a text of both runs and whether it differs.
+type TextDiff struct {
+	Base   string `json:"base"`
+	Target string `json:"target"`
+	Change Change `json:"change"`
+}
+
+// StepComparison compares what was recorded for one step in both runs.
+// Steps only present in one run's procedure version are added or removed.
+type StepComparison struct {
+	StepIndex    int    `json:"step_index"`
+	Name         string `json:"name"`
+	Change       Change `json:"change"`
+	BaseAssets   int    `json:"base_assets"`
+	TargetAssets int    `json:"target_assets"`
+	// Notes is the step note diff.
+	Notes TextDiff `json:"notes"`
+}
+
+// AssetComparison pairs an asset of the base run with the asset of the
+// target run that has the same step and file name. Unchanged pairs are not
+// reported.
+type AssetComparison struct {
+	StepIndex *int          `json:"step_index,omitempty"`
+	FileName  string        `json:"file_name"`
+	Change    Change        `json:"change"`
+	Base      *TestRunAsset `json:"base,omitempty"`
+	Target    *TestRunAsset `json:"target,omitempty"`
+}
+
+// Comparison is a structured comparison of two runs of the same procedure.
+type Comparison struct {
+	Base                 RunSummary        `json:"base"`
+	Target               RunSummary        `json:"target"`
+	StatusChanged        bool              `json:"status_changed"`
+	DurationDeltaSeconds *float64          `json:"duration_delta_seconds,omitempty"`
+	Notes                TextDiff          `json:"notes"`
+	Steps                []StepComparison  `json:"steps"`
+	Assets               []AssetComparison `json:"assets"`
+}
+
+// Compare compares two runs of the same procedure, which may execute
+// different versions of it. It returns ErrDifferentProcedures if the runs
+// belong to different procedures.
+func Compare(base, target *ComparedRun) (*Comparison, error) {
+	if procedureRoot(base.Procedure) != procedureRoot(target.Procedure) {
+		return nil, ErrDifferentProcedures
+	}
+
+	c := &Comparison{
+		Base:          summarize(base),
+		Target:        summarize(target),
+		StatusChanged: base.Run.Status != target.Run.Status,
+		Notes:         diffText(base.Run.Notes, target.Run.Notes),
+		Steps:         compareSteps(base, target),
+		Assets:        compareAssets(base.Assets, target.Assets),
+	}
+	if c.Base.DurationSeconds != nil && c.Target.DurationSeconds != nil {
+		delta := *c.Target.DurationSeconds - *c.Base.DurationSeconds
+		c.DurationDeltaSeconds = &delta
+	}
+	return c, nil
+}
+
+// procedureRoot returns the ID shared by every version of a procedure.
+func procedureRoot(tp *testprocedure.TestProcedure) uuid.UUID {
+	if tp.ParentID != nil {
+		return *tp.ParentID
+	}
+	return tp.ID
+}
+
+func summarize(cr *ComparedRun) RunSummary {
+	s := RunSummary{
+		ID:               cr.Run.ID,
+		Status:           cr.Run.Status,
+		ProcedureVersion: cr.Procedure.Version,
+		Notes:            cr.Run.Notes,
+		StartedAt:        cr.Run.StartedAt,
+		CompletedAt:      cr.Run.CompletedAt,
+	}
+	if cr.Run.StartedAt != nil && cr.Run.CompletedAt != nil {
+		seconds := cr.Run.CompletedAt.Sub(*cr.Run.StartedAt).Seconds()
+		s.DurationSeconds = &seconds
+	}
+	return s
+}
+
+func diffText(base, target string) TextDiff {
+	change := ChangeUnchanged
+	if base != target {
+		change = ChangeChanged
+	}
+	return TextDiff{Base: base, Target: target, Change: change}
+}
+
+func compareSteps(base, target *ComparedRun) []StepComparison {
+	baseNotes := notesByStep(base.StepNotes)
+	targetNotes := notesByStep(target.StepNotes)
+	baseAssets := assetsByStep(base.Assets)
+	targetAssets := assetsByStep(target.Assets)
+
+	count := len(base.Procedure.Steps)
+	if len(target.Procedure.Steps) > count {
+		count = len(target.Procedure.Steps)
+	}
+
+	steps := make([]StepComparison, 0, count)
+	for i := 0; i < count; i++ {
+		step := StepComparison{
+			StepIndex:    i,
+			BaseAssets:   baseAssets[i],
+			TargetAssets: targetAssets[i],
+			Notes:        diffText(baseNotes[i], targetNotes[i]),
+		}
+		switch {
+		case i >= len(base.Procedure.Steps):
+			step.Name = target.Procedure.Steps[i].Name
+			step.Change = ChangeAdded
+		case i >= len(target.Procedure.Steps):
+			step.Name = base.Procedure.Steps[i].Name
+			step.Change = ChangeRemoved
+		default:
+			step.Name = target.Procedure.Steps[i].Name
+			step.Change = ChangeUnchanged
+			if step.Notes.Change != ChangeUnchanged || step.BaseAssets != step.TargetAssets {
+				step.Change = ChangeChanged
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func notesByStep(notes []*StepNote) map[int]string {
+	byStep := make(map[int]string, len(notes))
+	for _, n := range notes {
+		byStep[n.StepIndex] = n.Notes
+	}
+	return byStep
+}
+
+func assetsByStep(assets []*TestRunAsset) map[int]int {
+	byStep := make(map[int]int)
+	for _, a := range assets {
+		if a.StepIndex != nil && !a.IsDerived() {
+			byStep[*a.StepIndex]++
+		}
+	}
+	return byStep
+}
+
+type assetKey struct {
+	stepIndex int
+	fileName  string
+}
+
+func keyOf(a *TestRunAsset) assetKey {
+	k := assetKey{stepIndex: -1, fileName: a.FileName}
+	if a.StepIndex != nil {
+		k.stepIndex = *a.StepIndex
+	}
+	return k
+}
+
+// compareAssets pairs the source assets of both runs by step and file name
+// and reports the pairs that differ, ordered by step and file name. Run-level
+// assets are listed before step assets.
+func compareAssets(base, target []*TestRunAsset) []AssetComparison {
+	pairs := make(map[assetKey]*AssetComparison)
+	pair := func(a *TestRunAsset) *AssetComparison {
+		k := keyOf(a)
+		p, ok := pairs[k]
+		if !ok {
+			p = &AssetComparison{StepIndex: a.StepIndex, FileName: a.FileName}
+			pairs[k] = p
+		}
+		return p
+	}
+	for _, a := range base {
+		if !a.IsDerived() {
+			pair(a).Base = a
+		}
+	}
+	for _, a := range target {
+		if !a.IsDerived() {
+			pair(a).Target = a
+		}
+	}
+
+	keys := make([]assetKey, 0, len(pairs))
+	for k, p := range pairs {
+		switch {
+		case p.Base == nil:
+			p.Change = ChangeAdded
+		case p.Target == nil:
+			p.Change = ChangeRemoved
+		case p.Base.FileSize != p.Target.FileSize || p.Base.MimeType != p.Target.MimeType:
+			p.Change = ChangeChanged
+		default:
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].stepIndex != keys[j].stepIndex {
+			return keys[i].stepIndex < keys[j].stepIndex
+		}
+		return keys[i].fileName < keys[j].fileName
+	})
+
+	diffs := make([]AssetComparison, len(keys))
+	for i, k := range keys {
+		diffs[i] = *pairs[k]
+	}
+	return diffs
+}
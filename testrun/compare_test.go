@@ -0,0 +1,155 @@
+package testrun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func comparedRun(proc *testprocedure.TestProcedure, status Status, duration time.Duration) *ComparedRun {
+	started := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	completed := started.Add(duration)
+	return &ComparedRun{
+		Run: &TestRun{
+			ID:              uuid.New(),
+			TestProcedureID: proc.ID,
+			Status:          status,
+			StartedAt:       &started,
+			CompletedAt:     &completed,
+		},
+		Procedure: proc,
+	}
+}
+
+func stepAsset(runID uuid.UUID, stepIndex int, fileName string, size int64) *TestRunAsset {
+	return &TestRunAsset{
+		ID:        uuid.New(),
+		TestRunID: runID,
+		AssetType: AssetTypeImage,
+		FileName:  fileName,
+		FileSize:  size,
+		StepIndex: &stepIndex,
+	}
+}
+
+func TestCompare(t *testing.T) {
+	v1 := &testprocedure.TestProcedure{
+		ID:      uuid.New(),
+		Version: 1,
+		Steps: testprocedure.Steps{
+			{Name: "Open login page"},
+			{Name: "Submit form"},
+		},
+	}
+	v2 := &testprocedure.TestProcedure{
+		ID:       uuid.New(),
+		ParentID: &v1.ID,
+		Version:  2,
+		Steps: testprocedure.Steps{
+			{Name: "Open login page"},
+			{Name: "Submit form"},
+			{Name: "Check dashboard"},
+		},
+	}
+
+	t.Run("compares runs of different versions", func(t *testing.T) {
+		base := comparedRun(v1, StatusPassed, 2*time.Minute)
+		base.Run.Notes = "All good"
+		base.StepNotes = []*StepNote{
+			{StepIndex: 0, Notes: "Loaded"},
+			{StepIndex: 1, Notes: "Submitted"},
+		}
+		base.Assets = []*TestRunAsset{
+			stepAsset(base.Run.ID, 0, "login.png", 100),
+			stepAsset(base.Run.ID, 1, "form.png", 200),
+		}
+
+		target := comparedRun(v2, StatusFailed, 5*time.Minute)
+		target.Run.Notes = "Dashboard broken"
+		target.StepNotes = []*StepNote{
+			{StepIndex: 0, Notes: "Loaded"},
+			{StepIndex: 1, Notes: "Submit hangs"},
+		}
+		target.Assets = []*TestRunAsset{
+			stepAsset(target.Run.ID, 0, "login.png", 100),
+			stepAsset(target.Run.ID, 1, "form.png", 250),
+			stepAsset(target.Run.ID, 2, "dashboard.png", 300),
+		}
+
+		c, err := Compare(base, target)
+		require.NoError(t, err)
+
+		assert.True(t, c.StatusChanged)
+		assert.Equal(t, uint(1), c.Base.ProcedureVersion)
+		assert.Equal(t, uint(2), c.Target.ProcedureVersion)
+		require.NotNil(t, c.DurationDeltaSeconds)
+		assert.Equal(t, 180.0, *c.DurationDeltaSeconds)
+		assert.Equal(t, ChangeChanged, c.Notes.Change)
+
+		require.Len(t, c.Steps, 3)
+		assert.Equal(t, ChangeUnchanged, c.Steps[0].Change)
+		assert.Equal(t, ChangeChanged, c.Steps[1].Change)
+		assert.Equal(t, "Submitted", c.Steps[1].Notes.Base)
+		assert.Equal(t, "Submit hangs", c.Steps[1].Notes.Target)
+		assert.Equal(t, ChangeAdded, c.Steps[2].Change)
+		assert.Equal(t, "Check dashboard", c.Steps[2].Name)
+		assert.Equal(t, 1, c.Steps[2].TargetAssets)
+
+		require.Len(t, c.Assets, 2)
+		assert.Equal(t, "form.png", c.Assets[0].FileName)
+		assert.Equal(t, ChangeChanged, c.Assets[0].Change)
+		assert.Equal(t, "dashboard.png", c.Assets[1].FileName)
+		assert.Equal(t, ChangeAdded, c.Assets[1].Change)
+		assert.Nil(t, c.Assets[1].Base)
+	})
+
+	t.Run("reports removed steps and assets", func(t *testing.T) {
+		base := comparedRun(v2, StatusPassed, time.Minute)
+		base.Assets = []*TestRunAsset{stepAsset(base.Run.ID, 2, "dashboard.png", 300)}
+		target := comparedRun(v1, StatusPassed, time.Minute)
+
+		c, err := Compare(base, target)
+		require.NoError(t, err)
+
+		assert.False(t, c.StatusChanged)
+		require.Len(t, c.Steps, 3)
+		assert.Equal(t, ChangeRemoved, c.Steps[2].Change)
+		require.Len(t, c.Assets, 1)
+		assert.Equal(t, ChangeRemoved, c.Assets[0].Change)
+	})
+
+	t.Run("ignores derived assets", func(t *testing.T) {
+		base := comparedRun(v1, StatusPassed, time.Minute)
+		target := comparedRun(v1, StatusPassed, time.Minute)
+		thumb := stepAsset(target.Run.ID, 0, "video-thumb.jpg", 10)
+		sourceID := uuid.New()
+		thumb.SourceAssetID = &sourceID
+		target.Assets = []*TestRunAsset{thumb}
+
+		c, err := Compare(base, target)
+		require.NoError(t, err)
+		assert.Empty(t, c.Assets)
+		assert.Equal(t, ChangeUnchanged, c.Steps[0].Change)
+	})
+
+	t.Run("omits duration delta for unfinished runs", func(t *testing.T) {
+		base := comparedRun(v1, StatusPassed, time.Minute)
+		target := comparedRun(v1, StatusRunning, 0)
+		target.Run.CompletedAt = nil
+
+		c, err := Compare(base, target)
+		require.NoError(t, err)
+		assert.Nil(t, c.Target.DurationSeconds)
+		assert.Nil(t, c.DurationDeltaSeconds)
+	})
+
+	t.Run("rejects runs of different procedures", func(t *testing.T) {
+		other := &testprocedure.TestProcedure{ID: uuid.New()}
+		_, err := Compare(comparedRun(v1, StatusPassed, time.Minute), comparedRun(other, StatusPassed, time.Minute))
+		assert.ErrorIs(t, err, ErrDifferentProcedures)
+	})
+}
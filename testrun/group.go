@@ -0,0 +1,118 @@
+package testrun
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRunGroupNotFound is returned when a run group is not found.
+	ErrRunGroupNotFound = errors.New("run group not found")
+
+	// ErrInvalidRunGroupName is returned when a run group name is empty.
+	ErrInvalidRunGroupName = errors.New("run group name is required")
+
+	// ErrEmptyRunGroup is returned when a run group is created without runs.
+	ErrEmptyRunGroup = errors.New("run group must have at least one run")
+)
+
+// RunGroup is a batch of runs of a procedure version launched together, one
+// per row of a dataset, and reported on as a whole.
+type RunGroup struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	TestProcedureID uuid.UUID  `json:"test_procedure_id" gorm:"type:char(36);not null;index:idx_test_run_groups_test_procedure_id"`
+	DatasetID       *uuid.UUID `json:"dataset_id,omitempty" gorm:"type:char(36)"`
+	Name            string     `json:"name" gorm:"type:varchar(255);not null"`
+	CreatedBy       uuid.UUID  `json:"created_by" gorm:"type:char(36);not null"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new run group.
+func (g *RunGroup) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM.
+func (g *RunGroup) TableName() string {
+	return "test_run_groups"
+}
+
+// Validate checks if the run group has valid required fields.
+func (g *RunGroup) Validate() error {
+	if g.Name == "" {
+		return ErrInvalidRunGroupName
+	}
+	if g.TestProcedureID == uuid.Nil {
+		return ErrInvalidTestProcedureID
+	}
+	if g.CreatedBy == uuid.Nil {
+		return ErrInvalidExecutedBy
+	}
+	return nil
+}
+
+// GroupSummary combines the outcomes of the runs of a group. Status is
+// pending until a run starts or is blocked, running until every run is
+// completed, and then failed if any run failed, passed if any passed and
+// skipped otherwise. PassRate is the share of completed runs that passed.
+type GroupSummary struct {
+	Status   Status  `json:"status"`
+	Total    int     `json:"total"`
+	Pending  int     `json:"pending"`
+	Running  int     `json:"running"`
+	Paused   int     `json:"paused"`
+	Blocked  int     `json:"blocked"`
+	Passed   int     `json:"passed"`
+	Failed   int     `json:"failed"`
+	Skipped  int     `json:"skipped"`
+	PassRate float64 `json:"pass_rate"`
+}
+
+// Summarize combines the outcomes of the runs of a group.
+func Summarize(runs []*TestRun) GroupSummary {
+	summary := GroupSummary{Total: len(runs)}
+	for _, run := range runs {
+		switch run.Status {
+		case StatusPending:
+			summary.Pending++
+		case StatusRunning:
+			summary.Running++
+		case StatusPaused:
+			summary.Paused++
+		case StatusBlocked:
+			summary.Blocked++
+		case StatusPassed:
+			summary.Passed++
+		case StatusFailed:
+			summary.Failed++
+		case StatusSkipped:
+			summary.Skipped++
+		}
+	}
+
+	completed := summary.Passed + summary.Failed + summary.Skipped
+	if completed > 0 {
+		summary.PassRate = float64(summary.Passed) / float64(completed)
+	}
+
+	switch {
+	case summary.Pending == summary.Total:
+		summary.Status = StatusPending
+	case completed < summary.Total:
+		summary.Status = StatusRunning
+	case summary.Failed > 0:
+		summary.Status = StatusFailed
+	case summary.Passed > 0:
+		summary.Status = StatusPassed
+	default:
+		summary.Status = StatusSkipped
+	}
+	return summary
+}
@@ -0,0 +1,152 @@
+package testrun
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLRunGroupStore implements RunGroupStore using GORM and MySQL.
+type MySQLRunGroupStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLRunGroupStore creates a new MySQL-backed run group store.
+func NewMySQLRunGroupStore(db *gorm.DB, log logger.Logger) *MySQLRunGroupStore {
+	return &MySQLRunGroupStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a run group together with its runs, which are tagged with
+// the group.
+func (s *MySQLRunGroupStore) Create(ctx context.Context, group *RunGroup, runs []*TestRun) error {
+	if err := group.Validate(); err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return ErrEmptyRunGroup
+	}
+	for _, run := range runs {
+		if run.Status == "" {
+			run.Status = StatusPending
+		}
+		if err := run.Validate(); err != nil {
+			return err
+		}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		for _, run := range runs {
+			run.GroupID = &group.ID
+		}
+		return tx.Create(runs).Error
+	})
+	if err != nil {
+		s.logger.Error(ctx, "failed to create run group", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": group.TestProcedureID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "run group created", map[string]interface{}{
+		"run_group_id":      group.ID.String(),
+		"test_procedure_id": group.TestProcedureID.String(),
+		"runs":              len(runs),
+	})
+
+	return nil
+}
+
+// GetByID retrieves a run group by its ID.
+func (s *MySQLRunGroupStore) GetByID(ctx context.Context, id uuid.UUID) (*RunGroup, error) {
+	var group RunGroup
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&group).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRunGroupNotFound
+		}
+		s.logger.Error(ctx, "failed to get run group", map[string]interface{}{
+			"error":        err.Error(),
+			"run_group_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// ListByTestProcedures retrieves a paginated list of the run groups of
+// multiple procedure versions, newest first.
+func (s *MySQLRunGroupStore) ListByTestProcedures(ctx context.Context, ids []uuid.UUID, limit, offset int) ([]*RunGroup, error) {
+	var groups []*RunGroup
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
+		Where("test_procedure_id IN ?", ids).
+		Order("created_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&groups).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list run groups", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// CountByTestProcedures returns the number of run groups of multiple
+// procedure versions.
+func (s *MySQLRunGroupStore) CountByTestProcedures(ctx context.Context, ids []uuid.UUID) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
+		Model(&RunGroup{}).
+		Where("test_procedure_id IN ?", ids).
+		Count(&count).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to count run groups", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// ListRuns retrieves the runs of a group, in dataset row order.
+func (s *MySQLRunGroupStore) ListRuns(ctx context.Context, groupID uuid.UUID) ([]*TestRun, error) {
+	var runs []*TestRun
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
+		Where("group_id = ?", groupID).
+		Order("dataset_row ASC").
+		Order("created_at ASC").
+		Find(&runs).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list run group runs", map[string]interface{}{
+			"error":        err.Error(),
+			"run_group_id": groupID.String(),
+		})
+		return nil, err
+	}
+
+	return runs, nil
+}
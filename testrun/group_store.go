@@ -0,0 +1,28 @@
+package testrun
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RunGroupStore defines the interface for run group persistence operations.
+type RunGroupStore interface {
+	// Create creates a run group together with its runs, which are tagged
+	// with the group.
+	Create(ctx context.Context, group *RunGroup, runs []*TestRun) error
+
+	// GetByID retrieves a run group by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*RunGroup, error)
+
+	// ListByTestProcedures retrieves a paginated list of the run groups of
+	// multiple procedure versions, newest first.
+	ListByTestProcedures(ctx context.Context, testProcedureIDs []uuid.UUID, limit, offset int) ([]*RunGroup, error)
+
+	// CountByTestProcedures returns the number of run groups of multiple
+	// procedure versions.
+	CountByTestProcedures(ctx context.Context, testProcedureIDs []uuid.UUID) (int, error)
+
+	// ListRuns retrieves the runs of a group, in dataset row order.
+	ListRuns(ctx context.Context, groupID uuid.UUID) ([]*TestRun, error)
+}
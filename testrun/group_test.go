@@ -0,0 +1,103 @@
+package testrun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	runs := func(statuses ...Status) []*TestRun {
+		out := make([]*TestRun, len(statuses))
+		for i, status := range statuses {
+			out[i] = &TestRun{Status: status}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		runs     []*TestRun
+		status   Status
+		passRate float64
+	}{
+		{name: "not started", runs: runs(StatusPending, StatusPending), status: StatusPending},
+		{name: "partly done", runs: runs(StatusPassed, StatusPending), status: StatusRunning, passRate: 1},
+		{name: "running", runs: runs(StatusRunning, StatusPending), status: StatusRunning},
+		{name: "blocked", runs: runs(StatusBlocked, StatusPending), status: StatusRunning},
+		{name: "paused", runs: runs(StatusPaused, StatusPassed), status: StatusRunning, passRate: 1},
+		{name: "all passed", runs: runs(StatusPassed, StatusPassed, StatusSkipped), status: StatusPassed, passRate: 2.0 / 3},
+		{name: "one failed", runs: runs(StatusPassed, StatusFailed), status: StatusFailed, passRate: 0.5},
+		{name: "all skipped", runs: runs(StatusSkipped), status: StatusSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := Summarize(tt.runs)
+			assert.Equal(t, tt.status, summary.Status)
+			assert.Equal(t, len(tt.runs), summary.Total)
+			assert.InDelta(t, tt.passRate, summary.PassRate, 0.0001)
+		})
+	}
+}
+
+func TestMySQLRunGroupStore(t *testing.T) {
+	groups, runStore := setupRunGroupStore(t)
+	ctx := context.Background()
+	procedureID := uuid.New()
+	executedBy := uuid.New()
+
+	newRuns := func(n int) []*TestRun {
+		runs := make([]*TestRun, n)
+		for i := range runs {
+			row := i
+			runs[i] = createTestRun(procedureID, executedBy, "", "")
+			runs[i].DatasetRow = &row
+		}
+		return runs
+	}
+
+	group := &RunGroup{TestProcedureID: procedureID, Name: "Users", CreatedBy: executedBy}
+	runs := newRuns(3)
+	require.NoError(t, groups.Create(ctx, group, runs))
+
+	t.Run("runs are tagged with the group", func(t *testing.T) {
+		listed, err := groups.ListRuns(ctx, group.ID)
+		require.NoError(t, err)
+		require.Len(t, listed, 3)
+		for i, run := range listed {
+			assert.Equal(t, StatusPending, run.Status)
+			require.NotNil(t, run.GroupID)
+			assert.Equal(t, group.ID, *run.GroupID)
+			assert.Equal(t, i, *run.DatasetRow)
+		}
+
+		filtered, err := runStore.ListByTestProceduresFiltered(ctx, []uuid.UUID{procedureID}, ListFilter{GroupID: &group.ID}, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, filtered, 3)
+	})
+
+	t.Run("list by procedure", func(t *testing.T) {
+		require.NoError(t, groups.Create(ctx, &RunGroup{TestProcedureID: uuid.New(), Name: "Other", CreatedBy: executedBy}, []*TestRun{createTestRun(uuid.New(), executedBy, "", "")}))
+
+		listed, err := groups.ListByTestProcedures(ctx, []uuid.UUID{procedureID}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, group.ID, listed[0].ID)
+
+		count, err := groups.CountByTestProcedures(ctx, []uuid.UUID{procedureID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("invalid groups return errors", func(t *testing.T) {
+		assert.ErrorIs(t, groups.Create(ctx, &RunGroup{TestProcedureID: procedureID, CreatedBy: executedBy}, newRuns(1)), ErrInvalidRunGroupName)
+		assert.ErrorIs(t, groups.Create(ctx, &RunGroup{TestProcedureID: procedureID, Name: "Empty", CreatedBy: executedBy}, nil), ErrEmptyRunGroup)
+
+		_, err := groups.GetByID(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrRunGroupNotFound)
+	})
+}
@@ -0,0 +1,227 @@
+package testrun
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// JUnitTestSuites is a JUnit XML report of test runs, as ingested by
+// Jenkins, CircleCI and other test result dashboards. Each run is a test
+// suite whose test cases are the steps of the procedure version it executes.
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr,omitempty"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite is the test suite of one run.
+type JUnitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	ID         string          `xml:"id,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr,omitempty"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []JUnitProperty `xml:"properties>property,omitempty"`
+	TestCases  []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitProperty is a property of a test suite.
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// JUnitTestCase is a step of a run, or the run itself when a failed run
+// did not record which step failed.
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// JUnitFailure marks a failed test case.
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitSkipped marks a skipped test case.
+type JUnitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// NewJUnitReport reports runs as JUnit XML test suites. A step's outcome
+// is the one its step note records; otherwise it follows from the run: the
+// steps of a passed run pass, the steps of a failed run pass up to the
+// failed step and are skipped after it, and the steps of other runs are
+// skipped.
+func NewJUnitReport(runs []*ReportedRun) *JUnitTestSuites {
+	report := &JUnitTestSuites{Name: "UI automation test runs"}
+	var total time.Duration
+	for _, rr := range runs {
+		suite, duration := rr.junitSuite()
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		total += duration
+		report.Suites = append(report.Suites, suite)
+	}
+	if total > 0 {
+		report.Time = junitSeconds(total)
+	}
+	return report
+}
+
+// WriteXML writes the report as an XML document.
+func (s *JUnitTestSuites) WriteXML(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSuite returns the test suite of the run and how long the run took.
+func (rr *ReportedRun) junitSuite() (JUnitTestSuite, time.Duration) {
+	run, proc := rr.Run, rr.Procedure
+	suite := JUnitTestSuite{
+		Name: fmt.Sprintf("%s v%d", proc.Name, proc.Version),
+		ID:   run.ID.String(),
+		Properties: []JUnitProperty{
+			{Name: "run_id", Value: run.ID.String()},
+			{Name: "procedure_id", Value: proc.ID.String()},
+			{Name: "procedure_version", Value: strconv.FormatUint(uint64(proc.Version), 10)},
+			{Name: "status", Value: string(run.Status)},
+		},
+	}
+	if run.Environment != "" {
+		suite.Properties = append(suite.Properties, JUnitProperty{Name: "environment", Value: run.Environment})
+	}
+	if run.BaseURL != "" {
+		suite.Properties = append(suite.Properties, JUnitProperty{Name: "base_url", Value: run.BaseURL})
+	}
+
+	var duration time.Duration
+	if run.StartedAt != nil {
+		suite.Timestamp = run.StartedAt.UTC().Format("2006-01-02T15:04:05")
+		if run.CompletedAt != nil {
+			duration = run.CompletedAt.Sub(*run.StartedAt)
+			suite.Time = junitSeconds(duration)
+		}
+	}
+
+	notes := make(map[int]*StepNote, len(rr.StepNotes))
+	for _, note := range rr.StepNotes {
+		notes[note.StepIndex] = note
+	}
+
+	// A failed run without a failed step, or with one no step note
+	// records, fails as a whole.
+	failedStep := -1
+	if run.Status == StatusFailed && run.FailedStepIndex != nil {
+		failedStep = *run.FailedStepIndex
+	}
+	recordedFailure := false
+	for i, step := range proc.Steps {
+		tc := JUnitTestCase{
+			Name:      fmt.Sprintf("Step %d: %s", i+1, step.Name),
+			ClassName: proc.Name,
+		}
+		note := notes[i]
+		if note != nil {
+			tc.SystemOut = note.Notes
+		}
+		switch rr.stepStatus(i, failedStep, note) {
+		case StepStatusFailed:
+			message := run.Notes
+			if note != nil && note.Notes != "" {
+				message = note.Notes
+			}
+			tc.Failure = &JUnitFailure{Message: firstLine(message), Type: "StepFailed", Text: message}
+			suite.Failures++
+			recordedFailure = true
+		case StepStatusSkipped:
+			tc.Skipped = &JUnitSkipped{Message: rr.skipMessage(i, failedStep)}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	if run.Status == StatusFailed && !recordedFailure {
+		suite.TestCases = append(suite.TestCases, JUnitTestCase{
+			Name:      "Test run",
+			ClassName: proc.Name,
+			Failure:   &JUnitFailure{Message: firstLine(run.Notes), Type: "RunFailed", Text: run.Notes},
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.TestCases)
+	return suite, duration
+}
+
+// stepStatus returns the outcome of step i, recorded by its note or
+// following from the run.
+func (rr *ReportedRun) stepStatus(i, failedStep int, note *StepNote) StepStatus {
+	if note != nil && note.Status.IsValid() {
+		return note.Status
+	}
+	switch rr.Run.Status {
+	case StatusPassed:
+		return StepStatusPassed
+	case StatusFailed:
+		switch {
+		case failedStep < 0:
+			return StepStatusSkipped
+		case i < failedStep:
+			return StepStatusPassed
+		case i == failedStep:
+			return StepStatusFailed
+		}
+	}
+	return StepStatusSkipped
+}
+
+// skipMessage explains why step i is skipped.
+func (rr *ReportedRun) skipMessage(i, failedStep int) string {
+	switch rr.Run.Status {
+	case StatusFailed:
+		if failedStep >= 0 && i > failedStep {
+			return "not run after the failed step"
+		}
+		return "step outcome not recorded"
+	case StatusSkipped:
+		return "test run skipped"
+	default:
+		return "test run is " + string(rr.Run.Status)
+	}
+}
+
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+func firstLine(s string) string {
+	for i, r := range s {
+		if r == '\n' || r == '\r' {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package testrun
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewJUnitReport(t *testing.T) {
+	procedure := &testprocedure.TestProcedure{
+		ID:      uuid.New(),
+		Name:    "Checkout",
+		Version: 2,
+		Steps: testprocedure.Steps{
+			{Name: "Open cart"},
+			{Name: "Pay"},
+			{Name: "See receipt"},
+		},
+	}
+	started := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	completed := started.Add(90 * time.Second)
+	failedStep := 1
+
+	passed := &ReportedRun{
+		Run:       &TestRun{ID: uuid.New(), Status: StatusPassed, StartedAt: &started, CompletedAt: &completed, Environment: "staging"},
+		Procedure: procedure,
+	}
+	failed := &ReportedRun{
+		Run: &TestRun{
+			ID: uuid.New(), Status: StatusFailed, StartedAt: &started, CompletedAt: &completed,
+			Notes: "Checkout broken", FailedStepIndex: &failedStep,
+		},
+		Procedure: procedure,
+		StepNotes: []*StepNote{{StepIndex: 1, Notes: "Card declined\nwith code 51"}},
+	}
+	unrecorded := &ReportedRun{
+		Run:       &TestRun{ID: uuid.New(), Status: StatusFailed, Notes: "Flaky"},
+		Procedure: procedure,
+	}
+
+	report := NewJUnitReport([]*ReportedRun{passed, failed, unrecorded})
+	assert.Equal(t, 10, report.Tests)
+	assert.Equal(t, 2, report.Failures)
+	assert.Equal(t, 4, report.Skipped)
+	assert.Equal(t, "180.000", report.Time)
+	require.Len(t, report.Suites, 3)
+
+	suite := report.Suites[0]
+	assert.Equal(t, "Checkout v2", suite.Name)
+	assert.Equal(t, "90.000", suite.Time)
+	assert.Equal(t, "2026-01-02T10:00:00", suite.Timestamp)
+	assert.Contains(t, suite.Properties, JUnitProperty{Name: "environment", Value: "staging"})
+	assert.Equal(t, 3, suite.Tests)
+	assert.Zero(t, suite.Failures)
+
+	suite = report.Suites[1]
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	assert.Nil(t, suite.TestCases[0].Failure)
+	require.NotNil(t, suite.TestCases[1].Failure)
+	assert.Equal(t, "Step 2: Pay", suite.TestCases[1].Name)
+	assert.Equal(t, "Card declined", suite.TestCases[1].Failure.Message)
+	assert.Equal(t, "Card declined\nwith code 51", suite.TestCases[1].Failure.Text)
+	assert.Equal(t, "not run after the failed step", suite.TestCases[2].Skipped.Message)
+
+	suite = report.Suites[2]
+	assert.Equal(t, 4, suite.Tests)
+	assert.Equal(t, 3, suite.Skipped)
+	assert.Equal(t, "Test run", suite.TestCases[3].Name)
+	assert.Equal(t, "Flaky", suite.TestCases[3].Failure.Message)
+	assert.Empty(t, suite.Time)
+}
+
+func TestJUnitReport_StepStatusFromNotes(t *testing.T) {
+	procedure := &testprocedure.TestProcedure{Name: "Login", Version: 1, Steps: testprocedure.Steps{{Name: "Open"}, {Name: "Submit"}}}
+	report := NewJUnitReport([]*ReportedRun{{
+		Run:       &TestRun{ID: uuid.New(), Status: StatusFailed},
+		Procedure: procedure,
+		StepNotes: []*StepNote{
+			{StepIndex: 0, Status: StepStatusPassed},
+			{StepIndex: 1, Status: StepStatusFailed, Notes: "Timed out"},
+		},
+	}})
+
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+	assert.Equal(t, 2, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Zero(t, suite.Skipped)
+	assert.Equal(t, "Timed out", suite.TestCases[1].Failure.Message)
+}
+
+func TestJUnitTestSuites_WriteXML(t *testing.T) {
+	procedure := &testprocedure.TestProcedure{Name: "Login & <logout>", Version: 1, Steps: testprocedure.Steps{{Name: "Open"}}}
+	report := NewJUnitReport([]*ReportedRun{{
+		Run:       &TestRun{ID: uuid.New(), Status: StatusSkipped},
+		Procedure: procedure,
+	}})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteXML(&buf))
+	assert.Contains(t, buf.String(), `<?xml version="1.0" encoding="UTF-8"?>`)
+	assert.Contains(t, buf.String(), `name="Login &amp; &lt;logout&gt; v1"`)
+	assert.Contains(t, buf.String(), `<skipped message="test run skipped"></skipped>`)
+
+	var decoded JUnitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, 1, decoded.Skipped)
+}
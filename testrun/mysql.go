@@ -3,8 +3,10 @@ package testrun
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
 	"github.com/hairizuanbinnoorazman/ui-automation/logger"
 	"gorm.io/gorm"
 )
@@ -107,6 +109,7 @@ func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...Update
 func (s *MySQLStore) ListByTestProcedure(ctx context.Context, testProcedureID uuid.UUID, limit, offset int) ([]*TestRun, error) {
 	var testRuns []*TestRun
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
 		Where("test_procedure_id = ?", testProcedureID).
 		Order("created_at DESC").
 		Limit(limit).
@@ -130,6 +133,7 @@ func (s *MySQLStore) ListByTestProcedure(ctx context.Context, testProcedureID uu
 func (s *MySQLStore) CountByTestProcedure(ctx context.Context, testProcedureID uuid.UUID) (int, error) {
 	var count int64
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica).
 		Model(&TestRun{}).
 		Where("test_procedure_id = ?", testProcedureID).
 		Count(&count).Error
@@ -147,13 +151,24 @@ func (s *MySQLStore) CountByTestProcedure(ctx context.Context, testProcedureID u
 
 // ListByTestProcedures retrieves a paginated list of test runs for multiple procedure versions.
 func (s *MySQLStore) ListByTestProcedures(ctx context.Context, ids []uuid.UUID, limit, offset int) ([]*TestRun, error) {
+	return s.ListByTestProceduresFiltered(ctx, ids, ListFilter{}, limit, offset)
+}
+
+// CountByTestProcedures returns the total count of test runs for multiple procedure versions.
+func (s *MySQLStore) CountByTestProcedures(ctx context.Context, ids []uuid.UUID) (int, error) {
+	return s.CountByTestProceduresFiltered(ctx, ids, ListFilter{})
+}
+
+// ListByTestProceduresFiltered retrieves a paginated list of test runs for multiple
+// procedure versions that match the filter, in the filter's sort order.
+func (s *MySQLStore) ListByTestProceduresFiltered(ctx context.Context, ids []uuid.UUID, filter ListFilter, limit, offset int) ([]*TestRun, error) {
 	if len(ids) == 0 {
 		return []*TestRun{}, nil
 	}
 	var testRuns []*TestRun
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica, filter.scope, filter.Sort.order).
 		Where("test_procedure_id IN ?", ids).
-		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&testRuns).Error
@@ -170,13 +185,39 @@ func (s *MySQLStore) ListByTestProcedures(ctx context.Context, ids []uuid.UUID,
 	return testRuns, nil
 }
 
-// CountByTestProcedures returns the total count of test runs for multiple procedure versions.
-func (s *MySQLStore) CountByTestProcedures(ctx context.Context, ids []uuid.UUID) (int, error) {
+// ListByTestProceduresAfter retrieves up to limit test runs for multiple
+// procedure versions that match the filter, newest first, after the cursor.
+func (s *MySQLStore) ListByTestProceduresAfter(ctx context.Context, ids []uuid.UUID, filter ListFilter, after *database.Cursor, limit int) ([]*TestRun, error) {
+	if len(ids) == 0 {
+		return []*TestRun{}, nil
+	}
+	var testRuns []*TestRun
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica, filter.scope, database.After(after)).
+		Where("test_procedure_id IN ?", ids).
+		Limit(limit).
+		Find(&testRuns).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list test runs by test procedures after cursor", map[string]interface{}{
+			"error": err.Error(),
+			"limit": limit,
+		})
+		return nil, err
+	}
+
+	return testRuns, nil
+}
+
+// CountByTestProceduresFiltered returns the total count of test runs for multiple
+// procedure versions that match the filter.
+func (s *MySQLStore) CountByTestProceduresFiltered(ctx context.Context, ids []uuid.UUID, filter ListFilter) (int, error) {
 	if len(ids) == 0 {
 		return 0, nil
 	}
 	var count int64
 	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica, filter.scope).
 		Model(&TestRun{}).
 		Where("test_procedure_id IN ?", ids).
 		Count(&count).Error
@@ -191,8 +232,11 @@ func (s *MySQLStore) CountByTestProcedures(ctx context.Context, ids []uuid.UUID)
 	return int(count), nil
 }
 
-// Start marks a test run as started (sets started_at, changes status to running).
-func (s *MySQLStore) Start(ctx context.Context, id uuid.UUID) error {
+// Start marks a test run as started (sets started_at, changes status to running)
+// and stores the procedure snapshot the run executes against.
+// acknowledgedBy is the user who confirmed the snapshot's preconditions
+// are in place; it is required if the snapshot has any.
+func (s *MySQLStore) Start(ctx context.Context, id uuid.UUID, snapshot *ProcedureSnapshot, acknowledgedBy *uuid.UUID) error {
 	// Fetch the test run
 	testRun, err := s.GetByID(ctx, id)
 	if err != nil {
@@ -203,6 +247,17 @@ func (s *MySQLStore) Start(ctx context.Context, id uuid.UUID) error {
 	if err := testRun.Start(); err != nil {
 		return err
 	}
+	testRun.ProcedureSnapshot = snapshot
+	hasPreconditions := snapshot != nil && len(snapshot.Preconditions) > 0
+	if hasPreconditions || len(testRun.Checklist) > 0 {
+		if acknowledgedBy == nil {
+			if hasPreconditions {
+				return ErrPreconditionsNotAcknowledged
+			}
+			return ErrChecklistNotCompleted
+		}
+		testRun.PreconditionsAcknowledgedBy = acknowledgedBy
+	}
 
 	// Save the updated test run
 	if err := s.db.WithContext(ctx).Save(testRun).Error; err != nil {
@@ -221,25 +276,40 @@ func (s *MySQLStore) Start(ctx context.Context, id uuid.UUID) error {
 }
 
 // Complete marks a test run as completed (sets completed_at, final status, optional notes).
+// The run is only completed if it is still running as it was read, so that
+// a concurrent pause or block is not overwritten and the duration counts
+// all the time the run was paused.
 func (s *MySQLStore) Complete(ctx context.Context, id uuid.UUID, status Status, notes string) error {
 	// Fetch the test run
 	testRun, err := s.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	pausedDuration := testRun.PausedDuration
 
 	// Call the domain method
 	if err := testRun.Complete(status, notes); err != nil {
 		return err
 	}
 
-	// Save the updated test run
-	if err := s.db.WithContext(ctx).Save(testRun).Error; err != nil {
+	result := s.db.WithContext(ctx).
+		Model(&TestRun{}).
+		Where("id = ? AND status = ? AND paused_duration = ?", id, StatusRunning, pausedDuration).
+		Updates(map[string]interface{}{
+			"status":       testRun.Status,
+			"completed_at": testRun.CompletedAt,
+			"duration":     testRun.Duration,
+			"notes":        testRun.Notes,
+		})
+	if result.Error != nil {
 		s.logger.Error(ctx, "failed to complete test run", map[string]interface{}{
-			"error":       err.Error(),
+			"error":       result.Error.Error(),
 			"test_run_id": id.String(),
 		})
-		return err
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return transitionError(StatusRunning, status)
 	}
 
 	s.logger.Info(ctx, "test run completed", map[string]interface{}{
@@ -249,3 +319,257 @@ func (s *MySQLStore) Complete(ctx context.Context, id uuid.UUID, status Status,
 
 	return nil
 }
+
+// Pause marks a running test run as paused, with an optional reason.
+func (s *MySQLStore) Pause(ctx context.Context, id uuid.UUID, reason string) error {
+	return s.changeStatus(ctx, id, "paused", func(tr *TestRun) error {
+		return tr.Pause(reason)
+	})
+}
+
+// Resume marks a paused test run as running again.
+func (s *MySQLStore) Resume(ctx context.Context, id uuid.UUID) error {
+	return s.changeStatus(ctx, id, "resumed", (*TestRun).Resume)
+}
+
+// Block marks a pending, running or paused test run as blocked for the
+// reason.
+func (s *MySQLStore) Block(ctx context.Context, id uuid.UUID, reason string) error {
+	return s.changeStatus(ctx, id, "blocked", func(tr *TestRun) error {
+		return tr.Block(reason)
+	})
+}
+
+// Unblock returns a blocked test run to running if it had started, or to
+// pending if not.
+func (s *MySQLStore) Unblock(ctx context.Context, id uuid.UUID) error {
+	return s.changeStatus(ctx, id, "unblocked", (*TestRun).Unblock)
+}
+
+// changeStatus applies a status change to a test run and saves it. The run
+// is only saved if its status has not changed since it was read, so that
+// concurrent changes cannot skip a transition.
+func (s *MySQLStore) changeStatus(ctx context.Context, id uuid.UUID, action string, change func(*TestRun) error) error {
+	testRun, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	from := testRun.Status
+
+	if err := change(testRun); err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).
+		Model(&TestRun{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{
+			"status":          testRun.Status,
+			"status_reason":   testRun.StatusReason,
+			"paused_at":       testRun.PausedAt,
+			"paused_duration": testRun.PausedDuration,
+			"overdue_after":   testRun.OverdueAfter,
+		})
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to change test run status", map[string]interface{}{
+			"error":       result.Error.Error(),
+			"test_run_id": id.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return transitionError(from, testRun.Status)
+	}
+
+	s.logger.Info(ctx, "test run "+action, map[string]interface{}{
+		"test_run_id": id.String(),
+		"status":      testRun.Status,
+	})
+
+	return nil
+}
+
+// FlagOverdue marks the running test runs that are past their OverdueAfter
+// at now and not yet marked as overdue, and returns them. Each run is only
+// marked, and returned, by the first of several servers to find it.
+func (s *MySQLStore) FlagOverdue(ctx context.Context, now time.Time) ([]*TestRun, error) {
+	var candidates []*TestRun
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND overdue_after < ? AND overdue_at IS NULL", StatusRunning, now).
+		Find(&candidates).Error
+	if err != nil {
+		s.logger.Error(ctx, "failed to find overdue test runs", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	flagged := make([]*TestRun, 0, len(candidates))
+	for _, tr := range candidates {
+		result := s.db.WithContext(ctx).
+			Model(&TestRun{}).
+			Where("id = ? AND status = ? AND overdue_at IS NULL", tr.ID, StatusRunning).
+			Update("overdue_at", now)
+		if result.Error != nil {
+			s.logger.Error(ctx, "failed to flag overdue test run", map[string]interface{}{
+				"error":       result.Error.Error(),
+				"test_run_id": tr.ID.String(),
+			})
+			return flagged, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		tr.OverdueAt = &now
+		flagged = append(flagged, tr)
+	}
+
+	return flagged, nil
+}
+
+// GetRunning retrieves the oldest running, paused or blocked test run of
+// the procedure versions in the environment.
+func (s *MySQLStore) GetRunning(ctx context.Context, testProcedureIDs []uuid.UUID, environment string) (*TestRun, error) {
+	return s.getRunning(ctx, testProcedureIDs, environment, uuid.Nil)
+}
+
+// Exclusive calls change with a store bound to a transaction, after
+// checking that no other test run of the procedure versions is running,
+// paused or blocked in the environment. The procedure's run template row
+// is locked for the transaction, so that of two runs starting at once only
+// one does.
+func (s *MySQLStore) Exclusive(ctx context.Context, rootID uuid.UUID, testProcedureIDs []uuid.UUID, environment string, exceptID uuid.UUID, change func(Store) error) error {
+	query := "SELECT id FROM test_run_templates WHERE test_procedure_id = ?"
+	// SQLite has no row locks; it serializes writers on its own.
+	if s.db.Dialector.Name() != "sqlite" {
+		query += " FOR UPDATE"
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked []string
+		if err := tx.Raw(query, rootID).Scan(&locked).Error; err != nil {
+			return err
+		}
+
+		txStore := &MySQLStore{db: tx, logger: s.logger}
+		running, err := txStore.getRunning(ctx, testProcedureIDs, environment, exceptID)
+		if err == nil {
+			return &ConcurrentRunError{Run: running}
+		}
+		if !errors.Is(err, ErrTestRunNotFound) {
+			return err
+		}
+		return change(txStore)
+	})
+}
+
+// getRunning retrieves the oldest running, paused or blocked test run of
+// the procedure versions in the environment other than exceptID.
+func (s *MySQLStore) getRunning(ctx context.Context, testProcedureIDs []uuid.UUID, environment string, exceptID uuid.UUID) (*TestRun, error) {
+	var testRun TestRun
+	err := s.db.WithContext(ctx).
+		Where("test_procedure_id IN ? AND environment = ? AND status IN ? AND id <> ?", testProcedureIDs, environment, []Status{StatusRunning, StatusPaused, StatusBlocked}, exceptID).
+		Order("started_at ASC").
+		First(&testRun).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTestRunNotFound
+		}
+		s.logger.Error(ctx, "failed to get running test run", map[string]interface{}{
+			"error":       err.Error(),
+			"environment": environment,
+		})
+		return nil, err
+	}
+
+	return &testRun, nil
+}
+
+// Claim assigns an unassigned test run that has not completed to the user.
+// The run is only assigned if it is still unassigned, so that of two
+// testers claiming it at once only one gets it.
+func (s *MySQLStore) Claim(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	testRun, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if testRun.Status.IsFinal() {
+		return ErrTestRunCompleted
+	}
+	if testRun.AssignedTo != nil {
+		return ErrRunAlreadyAssigned
+	}
+
+	result := s.db.WithContext(ctx).
+		Model(&TestRun{}).
+		Where("id = ? AND assigned_to IS NULL", id).
+		Update("assigned_to", userID)
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to claim test run", map[string]interface{}{
+			"error":       result.Error.Error(),
+			"test_run_id": id.String(),
+		})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRunAlreadyAssigned
+	}
+
+	s.logger.Info(ctx, "test run claimed", map[string]interface{}{
+		"test_run_id": id.String(),
+		"user_id":     userID.String(),
+	})
+
+	return nil
+}
+
+// ListAssigned retrieves a paginated list of the test runs assigned to the
+// user that match the filter, soonest due first; runs without a due date
+// come last, oldest first.
+func (s *MySQLStore) ListAssigned(ctx context.Context, userID uuid.UUID, filter QueueFilter, limit, offset int) ([]*TestRun, error) {
+	var testRuns []*TestRun
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica, filter.scope).
+		Where("assigned_to = ?", userID).
+		Order("due_at IS NULL").
+		Order("due_at ASC").
+		Order("created_at ASC").
+		Order("id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&testRuns).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list assigned test runs", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+			"limit":   limit,
+			"offset":  offset,
+		})
+		return nil, err
+	}
+
+	return testRuns, nil
+}
+
+// CountAssigned returns the total count of test runs assigned to the user
+// that match the filter.
+func (s *MySQLStore) CountAssigned(ctx context.Context, userID uuid.UUID, filter QueueFilter) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Scopes(database.ReadReplica, filter.scope).
+		Model(&TestRun{}).
+		Where("assigned_to = ?", userID).
+		Count(&count).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to count assigned test runs", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+		})
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -3,10 +3,15 @@ package testrun
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestMySQLStore_Create(t *testing.T) {
@@ -35,6 +40,22 @@ func TestMySQLStore_Create(t *testing.T) {
 		assert.Equal(t, StatusPending, tr.Status)
 	})
 
+	t.Run("create test run against an environment", func(t *testing.T) {
+		endpointID := uuid.New()
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+		tr.EndpointID = &endpointID
+		tr.Environment = "staging"
+		tr.BaseURL = "https://staging.example.com"
+		require.NoError(t, store.Create(ctx, tr))
+
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.EndpointID)
+		assert.Equal(t, endpointID, *retrieved.EndpointID)
+		assert.Equal(t, "staging", retrieved.Environment)
+		assert.Equal(t, "https://staging.example.com", retrieved.BaseURL)
+	})
+
 	t.Run("invalid test run returns error", func(t *testing.T) {
 		executedBy := uuid.New()
 		tr := &TestRun{
@@ -156,6 +177,340 @@ func TestMySQLStore_AssignedTo(t *testing.T) {
 	})
 }
 
+func TestMySQLStore_Claim(t *testing.T) {
+	_, store, _ := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("claim an unassigned run", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+		require.NoError(t, store.Create(ctx, tr))
+
+		userID := uuid.New()
+		require.NoError(t, store.Claim(ctx, tr.ID, userID))
+
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.AssignedTo)
+		assert.Equal(t, userID, *retrieved.AssignedTo)
+	})
+
+	t.Run("an assigned run cannot be claimed", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+		require.NoError(t, store.Create(ctx, tr))
+		first := uuid.New()
+		require.NoError(t, store.Claim(ctx, tr.ID, first))
+
+		err := store.Claim(ctx, tr.ID, uuid.New())
+		assert.ErrorIs(t, err, ErrRunAlreadyAssigned)
+
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, first, *retrieved.AssignedTo)
+	})
+
+	t.Run("a completed run cannot be claimed", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPassed, "")
+		require.NoError(t, store.Create(ctx, tr))
+
+		err := store.Claim(ctx, tr.ID, uuid.New())
+		assert.ErrorIs(t, err, ErrTestRunCompleted)
+	})
+
+	t.Run("claim a missing run", func(t *testing.T) {
+		err := store.Claim(ctx, uuid.New(), uuid.New())
+		assert.ErrorIs(t, err, ErrTestRunNotFound)
+	})
+}
+
+func TestMySQLStore_PauseAndBlock(t *testing.T) {
+	_, store, _ := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("pause, block, unblock and complete a run", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+		require.NoError(t, store.Create(ctx, tr))
+		require.NoError(t, store.Start(ctx, tr.ID, nil, nil))
+
+		require.NoError(t, store.Pause(ctx, tr.ID, "lunch"))
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusPaused, retrieved.Status)
+		assert.Equal(t, "lunch", retrieved.StatusReason)
+		require.NotNil(t, retrieved.PausedAt)
+
+		require.NoError(t, store.Block(ctx, tr.ID, "staging is down"))
+		retrieved, err = store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusBlocked, retrieved.Status)
+		assert.Equal(t, "staging is down", retrieved.StatusReason)
+
+		assert.ErrorIs(t, store.Complete(ctx, tr.ID, StatusPassed, ""), ErrTestRunNotRunning)
+
+		require.NoError(t, store.Unblock(ctx, tr.ID))
+		retrieved, err = store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusRunning, retrieved.Status)
+		assert.Empty(t, retrieved.StatusReason)
+		assert.Nil(t, retrieved.PausedAt)
+
+		require.NoError(t, store.Complete(ctx, tr.ID, StatusPassed, ""))
+	})
+
+	t.Run("resume a paused run", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+		require.NoError(t, store.Create(ctx, tr))
+		require.NoError(t, store.Start(ctx, tr.ID, nil, nil))
+		require.NoError(t, store.Pause(ctx, tr.ID, ""))
+
+		require.NoError(t, store.Resume(ctx, tr.ID))
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusRunning, retrieved.Status)
+	})
+
+	t.Run("a blocked run cannot start", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+		require.NoError(t, store.Create(ctx, tr))
+		require.NoError(t, store.Block(ctx, tr.ID, "no test data"))
+
+		assert.ErrorIs(t, store.Start(ctx, tr.ID, nil, nil), ErrInvalidTransition)
+
+		require.NoError(t, store.Unblock(ctx, tr.ID))
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, retrieved.Status)
+	})
+
+	t.Run("disallowed transitions are refused", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPassed, "")
+		require.NoError(t, store.Create(ctx, tr))
+
+		assert.ErrorIs(t, store.Pause(ctx, tr.ID, ""), ErrInvalidTransition)
+		assert.ErrorIs(t, store.Block(ctx, tr.ID, "outage"), ErrInvalidTransition)
+		assert.ErrorIs(t, store.Resume(ctx, tr.ID), ErrInvalidTransition)
+		assert.ErrorIs(t, store.Unblock(ctx, tr.ID), ErrInvalidTransition)
+	})
+
+	t.Run("missing run", func(t *testing.T) {
+		assert.ErrorIs(t, store.Pause(ctx, uuid.New(), ""), ErrTestRunNotFound)
+	})
+}
+
+func TestMySQLStore_GetRunning(t *testing.T) {
+	_, store, _ := setupTestStore(t)
+	ctx := context.Background()
+	v1, v2 := uuid.New(), uuid.New()
+
+	pending := createTestRun(v2, uuid.New(), StatusPending, "")
+	pending.Environment = "staging"
+	require.NoError(t, store.Create(ctx, pending))
+
+	t.Run("no running run", func(t *testing.T) {
+		_, err := store.GetRunning(ctx, []uuid.UUID{v1, v2}, "staging")
+		assert.ErrorIs(t, err, ErrTestRunNotFound)
+	})
+
+	running := createTestRun(v1, uuid.New(), StatusPending, "")
+	running.Environment = "staging"
+	require.NoError(t, store.Create(ctx, running))
+	require.NoError(t, store.Start(ctx, running.ID, nil, nil))
+
+	t.Run("running run of any version in the environment", func(t *testing.T) {
+		retrieved, err := store.GetRunning(ctx, []uuid.UUID{v1, v2}, "staging")
+		require.NoError(t, err)
+		assert.Equal(t, running.ID, retrieved.ID)
+	})
+
+	t.Run("paused runs still count", func(t *testing.T) {
+		require.NoError(t, store.Pause(ctx, running.ID, ""))
+		retrieved, err := store.GetRunning(ctx, []uuid.UUID{v1, v2}, "staging")
+		require.NoError(t, err)
+		assert.Equal(t, running.ID, retrieved.ID)
+	})
+
+	t.Run("blocked runs still count", func(t *testing.T) {
+		require.NoError(t, store.Block(ctx, running.ID, "staging is down"))
+		retrieved, err := store.GetRunning(ctx, []uuid.UUID{v1, v2}, "staging")
+		require.NoError(t, err)
+		assert.Equal(t, running.ID, retrieved.ID)
+	})
+
+	t.Run("other environments are not affected", func(t *testing.T) {
+		_, err := store.GetRunning(ctx, []uuid.UUID{v1, v2}, "production")
+		assert.ErrorIs(t, err, ErrTestRunNotFound)
+	})
+}
+
+func TestMySQLStore_Exclusive(t *testing.T) {
+	db, store, _ := setupTestStore(t)
+	testutil.AutoMigrate(t, db, &RunTemplate{})
+	ctx := context.Background()
+	rootID := uuid.New()
+	require.NoError(t, NewMySQLRunTemplateStore(db, logger.NewTestLogger()).Save(ctx, &RunTemplate{
+		TestProcedureID:       rootID,
+		PreventConcurrentRuns: true,
+		UpdatedBy:             uuid.New(),
+	}))
+
+	first := createTestRun(rootID, uuid.New(), StatusPending, "")
+	first.Environment = "staging"
+	require.NoError(t, store.Create(ctx, first))
+	second := createTestRun(rootID, uuid.New(), StatusPending, "")
+	second.Environment = "staging"
+	require.NoError(t, store.Create(ctx, second))
+
+	start := func(id uuid.UUID) error {
+		return store.Exclusive(ctx, rootID, []uuid.UUID{rootID}, "staging", id, func(tx Store) error {
+			return tx.Start(ctx, id, nil, nil)
+		})
+	}
+
+	t.Run("the first pending run starts", func(t *testing.T) {
+		require.NoError(t, start(first.ID))
+	})
+
+	t.Run("the second pending run is refused", func(t *testing.T) {
+		err := start(second.ID)
+		require.ErrorIs(t, err, ErrConcurrentRun)
+		var concurrent *ConcurrentRunError
+		require.ErrorAs(t, err, &concurrent)
+		assert.Equal(t, first.ID, concurrent.Run.ID)
+
+		retrieved, err := store.GetByID(ctx, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, retrieved.Status)
+	})
+
+	t.Run("the run itself does not count", func(t *testing.T) {
+		require.NoError(t, store.Pause(ctx, first.ID, ""))
+		err := store.Exclusive(ctx, rootID, []uuid.UUID{rootID}, "staging", first.ID, func(tx Store) error {
+			return tx.Resume(ctx, first.ID)
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("the second run starts once the first completes", func(t *testing.T) {
+		require.NoError(t, store.Complete(ctx, first.ID, StatusPassed, ""))
+		require.NoError(t, start(second.ID))
+	})
+}
+
+func TestMySQLStore_FlagOverdue(t *testing.T) {
+	_, store, _ := setupTestStore(t)
+	ctx := context.Background()
+	procedureID := uuid.New()
+	maxDuration := 60
+
+	overdue := createTestRun(procedureID, uuid.New(), StatusPending, "")
+	overdue.MaxDurationMinutes = &maxDuration
+	require.NoError(t, store.Create(ctx, overdue))
+	require.NoError(t, store.Start(ctx, overdue.ID, nil, nil))
+
+	paused := createTestRun(procedureID, uuid.New(), StatusPending, "")
+	paused.MaxDurationMinutes = &maxDuration
+	require.NoError(t, store.Create(ctx, paused))
+	require.NoError(t, store.Start(ctx, paused.ID, nil, nil))
+	require.NoError(t, store.Pause(ctx, paused.ID, ""))
+
+	untimed := createTestRun(procedureID, uuid.New(), StatusPending, "")
+	require.NoError(t, store.Create(ctx, untimed))
+	require.NoError(t, store.Start(ctx, untimed.ID, nil, nil))
+
+	t.Run("runs within their maximum duration are left alone", func(t *testing.T) {
+		flagged, err := store.FlagOverdue(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, flagged)
+	})
+
+	t.Run("running runs past their maximum duration are flagged", func(t *testing.T) {
+		now := time.Now().Add(2 * time.Hour)
+		flagged, err := store.FlagOverdue(ctx, now)
+		require.NoError(t, err)
+		require.Len(t, flagged, 1)
+		assert.Equal(t, overdue.ID, flagged[0].ID)
+
+		retrieved, err := store.GetByID(ctx, overdue.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.OverdueAt)
+	})
+
+	t.Run("runs are only flagged once", func(t *testing.T) {
+		flagged, err := store.FlagOverdue(ctx, time.Now().Add(3*time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, flagged)
+	})
+
+	t.Run("overdue filter", func(t *testing.T) {
+		runs, err := store.ListByTestProceduresFiltered(ctx, []uuid.UUID{procedureID}, ListFilter{Overdue: true}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, overdue.ID, runs[0].ID)
+	})
+}
+
+func TestMySQLStore_ListAssigned(t *testing.T) {
+	_, store, _ := setupTestStore(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	now := time.Now().Truncate(time.Second)
+	later, sooner := now.Add(48*time.Hour), now.Add(24*time.Hour)
+
+	undated := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+	undated.AssignedTo = &userID
+	dueLater := createTestRun(uuid.New(), uuid.New(), StatusRunning, "")
+	dueLater.AssignedTo = &userID
+	dueLater.DueAt = &later
+	dueSooner := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+	dueSooner.AssignedTo = &userID
+	dueSooner.DueAt = &sooner
+	completed := createTestRun(uuid.New(), uuid.New(), StatusPassed, "")
+	completed.AssignedTo = &userID
+	someoneElse := uuid.New()
+	other := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+	other.AssignedTo = &someoneElse
+	for _, tr := range []*TestRun{undated, dueLater, dueSooner, completed, other} {
+		require.NoError(t, store.Create(ctx, tr))
+	}
+
+	ids := func(runs []*TestRun) []uuid.UUID {
+		out := make([]uuid.UUID, len(runs))
+		for i, run := range runs {
+			out[i] = run.ID
+		}
+		return out
+	}
+
+	t.Run("lists runs still to do, soonest due first", func(t *testing.T) {
+		runs, err := store.ListAssigned(ctx, userID, QueueFilter{}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{dueSooner.ID, dueLater.ID, undated.ID}, ids(runs))
+
+		total, err := store.CountAssigned(ctx, userID, QueueFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+	})
+
+	t.Run("includes completed runs on request", func(t *testing.T) {
+		total, err := store.CountAssigned(ctx, userID, QueueFilter{IncludeCompleted: true})
+		require.NoError(t, err)
+		assert.Equal(t, 4, total)
+	})
+
+	t.Run("narrows to runs due before a time", func(t *testing.T) {
+		before := now.Add(36 * time.Hour)
+		runs, err := store.ListAssigned(ctx, userID, QueueFilter{DueBefore: &before}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{dueSooner.ID}, ids(runs))
+	})
+
+	t.Run("pages through the queue", func(t *testing.T) {
+		runs, err := store.ListAssigned(ctx, userID, QueueFilter{}, 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{dueLater.ID}, ids(runs))
+	})
+}
+
 func TestMySQLStore_ListByTestProcedure(t *testing.T) {
 	_, store, _ := setupTestStore(t)
 	ctx := context.Background()
@@ -194,6 +549,123 @@ func TestMySQLStore_ListByTestProcedure(t *testing.T) {
 	})
 }
 
+func TestMySQLStore_ListByTestProceduresFiltered(t *testing.T) {
+	_, store, _ := setupTestStore(t)
+	ctx := context.Background()
+	v1 := uuid.New()
+	v2 := uuid.New()
+	executedBy := uuid.New()
+
+	first := createTestRun(v1, executedBy, StatusPassed, "")
+	require.NoError(t, store.Create(ctx, first))
+	second := createTestRun(v2, executedBy, StatusFailed, "")
+	require.NoError(t, store.Create(ctx, second))
+	require.NoError(t, store.Create(ctx, createTestRun(v2, executedBy, StatusPending, "")))
+
+	t.Run("filter by run IDs", func(t *testing.T) {
+		filter := ListFilter{RunIDs: []uuid.UUID{first.ID, second.ID}}
+		runs, err := store.ListByTestProceduresFiltered(ctx, []uuid.UUID{v1, v2}, filter, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, runs, 2)
+
+		count, err := store.CountByTestProceduresFiltered(ctx, []uuid.UUID{v1, v2}, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("run IDs outside the procedures are ignored", func(t *testing.T) {
+		filter := ListFilter{RunIDs: []uuid.UUID{first.ID, second.ID}}
+		runs, err := store.ListByTestProceduresFiltered(ctx, []uuid.UUID{v2}, filter, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, second.ID, runs[0].ID)
+	})
+
+	t.Run("empty run IDs match nothing", func(t *testing.T) {
+		count, err := store.CountByTestProceduresFiltered(ctx, []uuid.UUID{v1, v2}, ListFilter{RunIDs: []uuid.UUID{}})
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestMySQLStore_ListByTestProceduresFiltered_FiltersAndSort(t *testing.T) {
+	db, store, _ := setupTestStore(t)
+	testutil.AutoMigrate(t, db, &StepNote{})
+	noteStore := NewMySQLStepNoteStore(db, logger.NewTestLogger())
+	ctx := context.Background()
+	procedureID := uuid.New()
+	alice := uuid.New()
+	bob := uuid.New()
+	base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	run := func(executedBy uuid.UUID, status Status, createdAt time.Time, duration int64, failedStepIndex *int) *TestRun {
+		tr := createTestRun(procedureID, executedBy, status, "")
+		tr.CreatedAt = createdAt
+		tr.FailedStepIndex = failedStepIndex
+		if duration > 0 {
+			started := createdAt.Add(time.Minute)
+			tr.StartedAt = &started
+			tr.Duration = &duration
+		}
+		require.NoError(t, store.Create(ctx, tr))
+		return tr
+	}
+	stepTwo := 2
+
+	pending := run(alice, StatusPending, base, 0, nil)
+	slow := run(alice, StatusPassed, base.Add(time.Hour), 90000, nil)
+	failedAt := run(bob, StatusFailed, base.Add(2*time.Hour), 30000, &stepTwo)
+	failedStep := run(bob, StatusPassed, base.Add(3*time.Hour), 60000, nil)
+	require.NoError(t, noteStore.Upsert(ctx, &StepNote{TestRunID: failedStep.ID, StepIndex: 1, Status: StepStatusFailed}))
+
+	list := func(t *testing.T, filter ListFilter) []uuid.UUID {
+		runs, err := store.ListByTestProceduresFiltered(ctx, []uuid.UUID{procedureID}, filter, 10, 0)
+		require.NoError(t, err)
+		count, err := store.CountByTestProceduresFiltered(ctx, []uuid.UUID{procedureID}, filter)
+		require.NoError(t, err)
+		assert.Equal(t, len(runs), count)
+		ids := make([]uuid.UUID, len(runs))
+		for i, r := range runs {
+			ids[i] = r.ID
+		}
+		return ids
+	}
+
+	since, until := base.Add(time.Hour), base.Add(3*time.Hour)
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   []*TestRun
+	}{
+		{name: "newest first by default", want: []*TestRun{failedStep, failedAt, slow, pending}},
+		{name: "status", filter: ListFilter{Status: StatusPassed}, want: []*TestRun{failedStep, slow}},
+		{name: "executor", filter: ListFilter{ExecutedBy: &alice}, want: []*TestRun{slow, pending}},
+		{name: "date range", filter: ListFilter{Since: &since, Until: &until}, want: []*TestRun{failedAt, slow}},
+		{name: "has failed steps", filter: ListFilter{HasFailedSteps: true}, want: []*TestRun{failedStep, failedAt}},
+		{name: "oldest first", filter: ListFilter{Sort: SortCreatedAt}, want: []*TestRun{pending, slow, failedAt, failedStep}},
+		{name: "longest first, unfinished last", filter: ListFilter{Sort: SortDurationDesc}, want: []*TestRun{slow, failedStep, failedAt, pending}},
+		{name: "shortest first, unfinished last", filter: ListFilter{Sort: SortDuration}, want: []*TestRun{failedAt, failedStep, slow, pending}},
+		{name: "started first, unstarted last", filter: ListFilter{Sort: SortStartedAt}, want: []*TestRun{slow, failedAt, failedStep, pending}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make([]uuid.UUID, len(tt.want))
+			for i, r := range tt.want {
+				want[i] = r.ID
+			}
+			assert.Equal(t, want, list(t, tt.filter))
+		})
+	}
+}
+
+func TestListSort_IsValid(t *testing.T) {
+	assert.True(t, ListSort("").IsValid())
+	assert.True(t, SortStartedAtDesc.IsValid())
+	assert.True(t, SortDuration.IsValid())
+	assert.False(t, ListSort("notes").IsValid())
+	assert.False(t, ListSort("--duration").IsValid())
+}
+
 func TestMySQLStore_Start(t *testing.T) {
 	_, store, _ := setupTestStore(t)
 	ctx := context.Background()
@@ -204,7 +676,7 @@ func TestMySQLStore_Start(t *testing.T) {
 		tr := createTestRun(testProcedureID, executedBy, StatusPending, "")
 		require.NoError(t, store.Create(ctx, tr))
 
-		err := store.Start(ctx, tr.ID)
+		err := store.Start(ctx, tr.ID, nil, nil)
 		require.NoError(t, err)
 
 		retrieved, err := store.GetByID(ctx, tr.ID)
@@ -213,21 +685,122 @@ func TestMySQLStore_Start(t *testing.T) {
 		assert.NotNil(t, retrieved.StartedAt)
 	})
 
+	t.Run("start stores procedure snapshot", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+		require.NoError(t, store.Create(ctx, tr))
+
+		proc := &testprocedure.TestProcedure{
+			ID:      tr.TestProcedureID,
+			Name:    "Login flow",
+			Version: 2,
+			Steps: testprocedure.Steps{
+				{Name: "Open login page", Instructions: "Navigate to /login"},
+			},
+		}
+		require.NoError(t, store.Start(ctx, tr.ID, NewProcedureSnapshot(proc), nil))
+
+		// Later edits to the procedure must not leak into the stored snapshot
+		proc.Steps[0].Name = "Edited"
+
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.ProcedureSnapshot)
+		snapshot := retrieved.ProcedureSnapshot.Procedure()
+		assert.Equal(t, "Login flow", snapshot.Name)
+		assert.Equal(t, uint(2), snapshot.Version)
+		require.Len(t, snapshot.Steps, 1)
+		assert.Equal(t, "Open login page", snapshot.Steps[0].Name)
+	})
+
+	t.Run("snapshot survives procedure deletion", func(t *testing.T) {
+		db, store, _ := setupTestStore(t)
+		testutil.AutoMigrate(t, db, &testprocedure.TestProcedure{})
+		procStore := testprocedure.NewMySQLStore(db, logger.NewTestLogger())
+
+		proc := &testprocedure.TestProcedure{
+			Name:      "Checkout flow",
+			ProjectID: uuid.New(),
+			CreatedBy: uuid.New(),
+			Steps: testprocedure.Steps{
+				{Name: "Add item to cart", Instructions: "Click add to cart"},
+			},
+		}
+		require.NoError(t, procStore.Create(ctx, proc))
+
+		tr := createTestRun(proc.ID, uuid.New(), StatusPending, "")
+		require.NoError(t, store.Create(ctx, tr))
+		require.NoError(t, store.Start(ctx, tr.ID, NewProcedureSnapshot(proc), nil))
+
+		require.NoError(t, procStore.Delete(ctx, proc.ID))
+		_, err := procStore.GetByID(ctx, proc.ID)
+		require.ErrorIs(t, err, testprocedure.ErrTestProcedureNotFound)
+
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, proc.ID, retrieved.TestProcedureID)
+		require.NotNil(t, retrieved.ProcedureSnapshot)
+		snapshot := retrieved.ProcedureSnapshot.Procedure()
+		assert.Equal(t, "Checkout flow", snapshot.Name)
+		assert.Equal(t, proc.ProjectID, snapshot.ProjectID)
+		require.Len(t, snapshot.Steps, 1)
+		assert.Equal(t, "Add item to cart", snapshot.Steps[0].Name)
+
+		runs, err := store.ListByTestProcedure(ctx, proc.ID, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, runs, 1)
+	})
+
 	t.Run("cannot start already started run", func(t *testing.T) {
 		testProcedureID := uuid.New()
 		executedBy := uuid.New()
 		tr := createTestRun(testProcedureID, executedBy, StatusPending, "")
 		require.NoError(t, store.Create(ctx, tr))
-		require.NoError(t, store.Start(ctx, tr.ID))
+		require.NoError(t, store.Start(ctx, tr.ID, nil, nil))
 
-		err := store.Start(ctx, tr.ID)
+		err := store.Start(ctx, tr.ID, nil, nil)
 		assert.ErrorIs(t, err, ErrTestRunAlreadyStarted)
 	})
 
 	t.Run("start non-existent returns error", func(t *testing.T) {
-		err := store.Start(ctx, uuid.New())
+		err := store.Start(ctx, uuid.New(), nil, nil)
 		assert.ErrorIs(t, err, ErrTestRunNotFound)
 	})
+
+	t.Run("preconditions must be acknowledged", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), "", "")
+		require.NoError(t, store.Create(ctx, tr))
+		snapshot := NewProcedureSnapshot(&testprocedure.TestProcedure{
+			ID:            tr.TestProcedureID,
+			Name:          "Refund",
+			Preconditions: testprocedure.Preconditions{{Kind: testprocedure.PreconditionUserRole, Description: "Support agent account"}},
+		})
+
+		err := store.Start(ctx, tr.ID, snapshot, nil)
+		assert.ErrorIs(t, err, ErrPreconditionsNotAcknowledged)
+
+		require.NoError(t, store.Start(ctx, tr.ID, snapshot, &tr.ExecutedBy))
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusRunning, retrieved.Status)
+		require.NotNil(t, retrieved.PreconditionsAcknowledgedBy)
+		assert.Equal(t, tr.ExecutedBy, *retrieved.PreconditionsAcknowledgedBy)
+	})
+
+	t.Run("checklist must be completed", func(t *testing.T) {
+		tr := createTestRun(uuid.New(), uuid.New(), "", "")
+		tr.Checklist = Checklist{"Clear the browser cache"}
+		require.NoError(t, store.Create(ctx, tr))
+
+		err := store.Start(ctx, tr.ID, nil, nil)
+		assert.ErrorIs(t, err, ErrChecklistNotCompleted)
+
+		require.NoError(t, store.Start(ctx, tr.ID, nil, &tr.ExecutedBy))
+		retrieved, err := store.GetByID(ctx, tr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, Checklist{"Clear the browser cache"}, retrieved.Checklist)
+		require.NotNil(t, retrieved.PreconditionsAcknowledgedBy)
+		assert.Equal(t, tr.ExecutedBy, *retrieved.PreconditionsAcknowledgedBy)
+	})
 }
 
 func TestMySQLStore_Complete(t *testing.T) {
@@ -239,7 +812,7 @@ func TestMySQLStore_Complete(t *testing.T) {
 		executedBy := uuid.New()
 		tr := createTestRun(testProcedureID, executedBy, StatusPending, "")
 		require.NoError(t, store.Create(ctx, tr))
-		require.NoError(t, store.Start(ctx, tr.ID))
+		require.NoError(t, store.Start(ctx, tr.ID, nil, nil))
 
 		err := store.Complete(ctx, tr.ID, StatusPassed, "All tests passed")
 		require.NoError(t, err)
@@ -248,6 +821,7 @@ func TestMySQLStore_Complete(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, StatusPassed, retrieved.Status)
 		assert.NotNil(t, retrieved.CompletedAt)
+		assert.NotNil(t, retrieved.Duration)
 		assert.Equal(t, "All tests passed", retrieved.Notes)
 	})
 
@@ -256,7 +830,7 @@ func TestMySQLStore_Complete(t *testing.T) {
 		executedBy := uuid.New()
 		tr := createTestRun(testProcedureID, executedBy, StatusPending, "")
 		require.NoError(t, store.Create(ctx, tr))
-		require.NoError(t, store.Start(ctx, tr.ID))
+		require.NoError(t, store.Start(ctx, tr.ID, nil, nil))
 
 		err := store.Complete(ctx, tr.ID, StatusFailed, "Failed at step 3")
 		require.NoError(t, err)
@@ -283,6 +857,33 @@ func TestMySQLStore_Complete(t *testing.T) {
 	})
 }
 
+func TestMySQLStore_CompletePausedMeanwhile(t *testing.T) {
+	db, store, _ := setupTestStore(t)
+	ctx := context.Background()
+
+	tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+	require.NoError(t, store.Create(ctx, tr))
+	require.NoError(t, store.Start(ctx, tr.ID, nil, nil))
+
+	// Pause the run right after Complete reads it
+	paused := false
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:pause", func(*gorm.DB) {
+		if !paused {
+			paused = true
+			require.NoError(t, store.Pause(ctx, tr.ID, "lunch"))
+		}
+	}))
+	err := store.Complete(ctx, tr.ID, StatusPassed, "")
+	require.NoError(t, db.Callback().Query().Remove("test:pause"))
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	retrieved, err := store.GetByID(ctx, tr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPaused, retrieved.Status)
+	assert.Equal(t, "lunch", retrieved.StatusReason)
+	assert.Nil(t, retrieved.CompletedAt)
+}
+
 func TestMySQLAssetStore_Create(t *testing.T) {
 	_, store, assetStore := setupTestStore(t)
 	ctx := context.Background()
@@ -408,3 +1009,320 @@ func TestMySQLAssetStore_Delete(t *testing.T) {
 		assert.ErrorIs(t, err, ErrAssetNotFound)
 	})
 }
+
+func TestMySQLAssetStore_Derived(t *testing.T) {
+	_, store, assetStore := setupTestStore(t)
+	ctx := context.Background()
+
+	tr := createTestRun(uuid.New(), uuid.New(), StatusRunning, "")
+	require.NoError(t, store.Create(ctx, tr))
+
+	video := createTestAsset(tr.ID, AssetTypeVideo, "path/to/video.mov", "video.mov", 4096)
+	video.ProcessingStatus = ProcessingStatusPending
+	require.NoError(t, assetStore.Create(ctx, video))
+	image := createTestAsset(tr.ID, AssetTypeImage, "path/to/image.png", "image.png", 1024)
+	require.NoError(t, assetStore.Create(ctx, image))
+
+	t.Run("list derived assets", func(t *testing.T) {
+		thumbnail := createTestAsset(tr.ID, AssetTypeImage, "path/to/video.mov.thumbnail.jpg", "video_thumbnail.jpg", 128)
+		thumbnail.SourceAssetID = &video.ID
+		thumbnail.Variant = AssetVariantThumbnail
+		require.NoError(t, assetStore.Create(ctx, thumbnail))
+
+		derived, err := assetStore.ListDerived(ctx, video.ID)
+		require.NoError(t, err)
+		require.Len(t, derived, 1)
+		assert.Equal(t, thumbnail.ID, derived[0].ID)
+		assert.Equal(t, AssetVariantThumbnail, derived[0].Variant)
+
+		derived, err = assetStore.ListDerived(ctx, image.ID)
+		require.NoError(t, err)
+		assert.Empty(t, derived)
+	})
+
+	t.Run("list and update by processing status", func(t *testing.T) {
+		pending, err := assetStore.ListByProcessingStatus(ctx, ProcessingStatusPending, ProcessingStatusProcessing)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, video.ID, pending[0].ID)
+
+		require.NoError(t, assetStore.UpdateProcessingStatus(ctx, video.ID, ProcessingStatusFailed, "ffmpeg exited with status 1"))
+
+		pending, err = assetStore.ListByProcessingStatus(ctx, ProcessingStatusPending, ProcessingStatusProcessing)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+
+		retrieved, err := assetStore.GetByID(ctx, video.ID)
+		require.NoError(t, err)
+		assert.Equal(t, ProcessingStatusFailed, retrieved.ProcessingStatus)
+		assert.Equal(t, "ffmpeg exited with status 1", retrieved.ProcessingError)
+	})
+
+	t.Run("update non-existent returns error", func(t *testing.T) {
+		err := assetStore.UpdateProcessingStatus(ctx, uuid.New(), ProcessingStatusCompleted, "")
+		assert.ErrorIs(t, err, ErrAssetNotFound)
+	})
+}
+
+func TestMySQLCommentStore_Create(t *testing.T) {
+	store := setupCommentStore(t)
+	ctx := context.Background()
+	runID := uuid.New()
+	step := 1
+
+	root := &Comment{TestRunID: runID, AuthorID: uuid.New(), Body: "Run is red"}
+	require.NoError(t, store.Create(ctx, root))
+	stepRoot := &Comment{TestRunID: runID, StepIndex: &step, AuthorID: uuid.New(), Body: "Step 2 timed out"}
+	require.NoError(t, store.Create(ctx, stepRoot))
+
+	t.Run("reply to a top-level comment", func(t *testing.T) {
+		reply := &Comment{TestRunID: runID, StepIndex: &step, ParentID: &stepRoot.ID, AuthorID: uuid.New(), Body: "Retrying"}
+		require.NoError(t, store.Create(ctx, reply))
+
+		retrieved, err := store.GetByID(ctx, reply.ID)
+		require.NoError(t, err)
+		assert.Equal(t, stepRoot.ID, *retrieved.ParentID)
+		assert.Equal(t, step, *retrieved.StepIndex)
+		assert.Nil(t, retrieved.EditedAt)
+	})
+
+	otherStep := 2
+	invalidParents := []struct {
+		name    string
+		comment *Comment
+	}{
+		{
+			name:    "unknown parent",
+			comment: &Comment{TestRunID: runID, ParentID: uuidPtr(uuid.New()), AuthorID: uuid.New(), Body: "x"},
+		},
+		{
+			name:    "parent on another run",
+			comment: &Comment{TestRunID: uuid.New(), ParentID: &root.ID, AuthorID: uuid.New(), Body: "x"},
+		},
+		{
+			name:    "parent on another step",
+			comment: &Comment{TestRunID: runID, StepIndex: &otherStep, ParentID: &stepRoot.ID, AuthorID: uuid.New(), Body: "x"},
+		},
+		{
+			name:    "run reply to a step comment",
+			comment: &Comment{TestRunID: runID, ParentID: &stepRoot.ID, AuthorID: uuid.New(), Body: "x"},
+		},
+	}
+	for _, tt := range invalidParents {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, store.Create(ctx, tt.comment), ErrInvalidCommentParent)
+		})
+	}
+
+	t.Run("rejects replies to replies", func(t *testing.T) {
+		reply := &Comment{TestRunID: runID, ParentID: &root.ID, AuthorID: uuid.New(), Body: "first"}
+		require.NoError(t, store.Create(ctx, reply))
+
+		nested := &Comment{TestRunID: runID, ParentID: &reply.ID, AuthorID: uuid.New(), Body: "nested"}
+		assert.ErrorIs(t, store.Create(ctx, nested), ErrInvalidCommentParent)
+	})
+
+	t.Run("rejects invalid comments", func(t *testing.T) {
+		assert.ErrorIs(t, store.Create(ctx, &Comment{TestRunID: runID, AuthorID: uuid.New()}), ErrInvalidCommentBody)
+	})
+}
+
+func TestMySQLCommentStore_ListByTestRun(t *testing.T) {
+	store := setupCommentStore(t)
+	ctx := context.Background()
+	runID := uuid.New()
+	step0, step1 := 0, 1
+
+	for _, c := range []*Comment{
+		{TestRunID: runID, AuthorID: uuid.New(), Body: "first"},
+		{TestRunID: runID, StepIndex: &step0, AuthorID: uuid.New(), Body: "second"},
+		{TestRunID: runID, StepIndex: &step1, AuthorID: uuid.New(), Body: "third"},
+		{TestRunID: uuid.New(), AuthorID: uuid.New(), Body: "other run"},
+	} {
+		require.NoError(t, store.Create(ctx, c))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Run("all comments oldest first", func(t *testing.T) {
+		comments, err := store.ListByTestRun(ctx, runID, nil)
+		require.NoError(t, err)
+		require.Len(t, comments, 3)
+		assert.Equal(t, "first", comments[0].Body)
+		assert.Equal(t, "second", comments[1].Body)
+		assert.Equal(t, "third", comments[2].Body)
+	})
+
+	t.Run("comments on one step", func(t *testing.T) {
+		comments, err := store.ListByTestRun(ctx, runID, &step0)
+		require.NoError(t, err)
+		require.Len(t, comments, 1)
+		assert.Equal(t, "second", comments[0].Body)
+	})
+}
+
+func TestMySQLCommentStore_UpdateBody(t *testing.T) {
+	store := setupCommentStore(t)
+	ctx := context.Background()
+
+	comment := &Comment{TestRunID: uuid.New(), AuthorID: uuid.New(), Body: "typo"}
+	require.NoError(t, store.Create(ctx, comment))
+
+	require.NoError(t, store.UpdateBody(ctx, comment.ID, "fixed"))
+	retrieved, err := store.GetByID(ctx, comment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "fixed", retrieved.Body)
+	assert.NotNil(t, retrieved.EditedAt)
+
+	assert.ErrorIs(t, store.UpdateBody(ctx, comment.ID, ""), ErrInvalidCommentBody)
+	assert.ErrorIs(t, store.UpdateBody(ctx, uuid.New(), "x"), ErrCommentNotFound)
+}
+
+func TestMySQLCommentStore_Delete(t *testing.T) {
+	store := setupCommentStore(t)
+	ctx := context.Background()
+	runID := uuid.New()
+
+	root := &Comment{TestRunID: runID, AuthorID: uuid.New(), Body: "root"}
+	require.NoError(t, store.Create(ctx, root))
+	reply := &Comment{TestRunID: runID, ParentID: &root.ID, AuthorID: uuid.New(), Body: "reply"}
+	require.NoError(t, store.Create(ctx, reply))
+	other := &Comment{TestRunID: runID, AuthorID: uuid.New(), Body: "other"}
+	require.NoError(t, store.Create(ctx, other))
+
+	t.Run("deleting a comment deletes its replies", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, root.ID))
+
+		comments, err := store.ListByTestRun(ctx, runID, nil)
+		require.NoError(t, err)
+		require.Len(t, comments, 1)
+		assert.Equal(t, other.ID, comments[0].ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		assert.ErrorIs(t, store.Delete(ctx, root.ID), ErrCommentNotFound)
+	})
+}
+
+func TestMySQLStepNoteStore_Attachments(t *testing.T) {
+	db, _, assetStore := setupTestStore(t)
+	testutil.AutoMigrate(t, db, &StepNote{})
+	noteStore := NewMySQLStepNoteStore(db, logger.NewTestLogger())
+	ctx := context.Background()
+	runID := uuid.New()
+
+	note := &StepNote{TestRunID: runID, StepIndex: 2, Notes: "Login button missing"}
+	require.NoError(t, noteStore.Upsert(ctx, note))
+
+	t.Run("get by ID", func(t *testing.T) {
+		retrieved, err := noteStore.GetByID(ctx, note.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Login button missing", retrieved.Notes)
+
+		_, err = noteStore.GetByID(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrStepNoteNotFound)
+	})
+
+	t.Run("assets keep their step note", func(t *testing.T) {
+		asset := createTestAsset(runID, AssetTypeImage, "/path/shot.png", "shot.png", 1024)
+		asset.StepIndex = &note.StepIndex
+		asset.StepNoteID = &note.ID
+		require.NoError(t, assetStore.Create(ctx, asset))
+
+		retrieved, err := assetStore.GetByID(ctx, asset.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.StepNoteID)
+		assert.Equal(t, note.ID, *retrieved.StepNoteID)
+	})
+}
+
+func TestMySQLStepNoteStore_Upsert_Status(t *testing.T) {
+	db, _, _ := setupTestStore(t)
+	testutil.AutoMigrate(t, db, &StepNote{})
+	noteStore := NewMySQLStepNoteStore(db, logger.NewTestLogger())
+	ctx := context.Background()
+	runID := uuid.New()
+
+	require.NoError(t, noteStore.Upsert(ctx, &StepNote{TestRunID: runID, StepIndex: 0, Notes: "Dashboard shown", Status: StepStatusPassed}))
+
+	// A manual edit of the notes keeps the recorded status.
+	require.NoError(t, noteStore.Upsert(ctx, &StepNote{TestRunID: runID, StepIndex: 0, Notes: "Dashboard shown after a delay"}))
+	note, err := noteStore.GetByRunAndStep(ctx, runID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Dashboard shown after a delay", note.Notes)
+	assert.Equal(t, StepStatusPassed, note.Status)
+
+	require.NoError(t, noteStore.Upsert(ctx, &StepNote{TestRunID: runID, StepIndex: 0, Notes: "Dashboard missing", Status: StepStatusFailed}))
+	note, err = noteStore.GetByRunAndStep(ctx, runID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, StepStatusFailed, note.Status)
+}
+
+func uuidPtr(id uuid.UUID) *uuid.UUID {
+	return &id
+}
+
+func TestMySQLAnnotationStore_Replace(t *testing.T) {
+	store := setupAnnotationStore(t)
+	ctx := context.Background()
+	assetID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("replace sets annotations in order", func(t *testing.T) {
+		require.NoError(t, store.Replace(ctx, assetID, []*AssetAnnotation{
+			{Shape: AnnotationShapeRectangle, X: 10, Y: 10, Width: 50, Height: 20, CreatedBy: userID},
+			{Shape: AnnotationShapeText, X: 10, Y: 40, Text: "Click", CreatedBy: userID},
+		}))
+		require.NoError(t, store.Replace(ctx, assetID, []*AssetAnnotation{
+			{Shape: AnnotationShapeArrow, X: 0, Y: 0, EndX: 10, EndY: 10, CreatedBy: userID},
+			{Shape: AnnotationShapeText, X: 10, Y: 40, Text: "Pay", Color: "#0000ff", CreatedBy: userID},
+		}))
+
+		annotations, err := store.ListByAsset(ctx, assetID)
+		require.NoError(t, err)
+		require.Len(t, annotations, 2)
+		assert.Equal(t, AnnotationShapeArrow, annotations[0].Shape)
+		assert.Equal(t, 0, annotations[0].Position)
+		assert.Equal(t, DefaultAnnotationColor, annotations[0].Color)
+		assert.Equal(t, "Pay", annotations[1].Text)
+		assert.Equal(t, "#0000FF", annotations[1].Color)
+		assert.Equal(t, 1, annotations[1].Position)
+	})
+
+	t.Run("invalid annotation keeps existing ones", func(t *testing.T) {
+		err := store.Replace(ctx, assetID, []*AssetAnnotation{{Shape: "circle"}})
+		assert.ErrorIs(t, err, ErrInvalidAnnotationShape)
+
+		annotations, err := store.ListByAsset(ctx, assetID)
+		require.NoError(t, err)
+		assert.Len(t, annotations, 2)
+	})
+
+	t.Run("too many annotations", func(t *testing.T) {
+		annotations := make([]*AssetAnnotation, MaxAnnotationsPerAsset+1)
+		for i := range annotations {
+			annotations[i] = &AssetAnnotation{Shape: AnnotationShapeRectangle, Width: 1, Height: 1, CreatedBy: userID}
+		}
+		assert.ErrorIs(t, store.Replace(ctx, assetID, annotations), ErrTooManyAnnotations)
+	})
+
+	t.Run("list by assets groups annotations", func(t *testing.T) {
+		otherID := uuid.New()
+		require.NoError(t, store.Replace(ctx, otherID, []*AssetAnnotation{
+			{Shape: AnnotationShapeRectangle, Width: 5, Height: 5, CreatedBy: userID},
+		}))
+
+		byAsset, err := store.ListByAssets(ctx, []uuid.UUID{assetID, otherID, uuid.New()})
+		require.NoError(t, err)
+		assert.Len(t, byAsset[assetID], 2)
+		assert.Len(t, byAsset[otherID], 1)
+		assert.Len(t, byAsset, 2)
+	})
+
+	t.Run("empty replace clears annotations", func(t *testing.T) {
+		require.NoError(t, store.Replace(ctx, assetID, nil))
+
+		annotations, err := store.ListByAsset(ctx, assetID)
+		require.NoError(t, err)
+		assert.Empty(t, annotations)
+	})
+}
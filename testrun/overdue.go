@@ -0,0 +1,75 @@
+package testrun
+
+import (
+	"context"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+)
+
+// OverdueAlertFunc is called once for each run found still running after
+// its expected maximum duration.
+type OverdueAlertFunc func(ctx context.Context, tr *TestRun)
+
+// OverdueChecker periodically flags runs still running after the maximum
+// duration their procedure's run template expects, and alerts about each
+// of them once.
+type OverdueChecker struct {
+	store  Store
+	alert  OverdueAlertFunc
+	logger logger.Logger
+	stopCh chan struct{}
+}
+
+// NewOverdueChecker creates a checker that calls alert for each run it
+// flags as overdue.
+func NewOverdueChecker(store Store, alert OverdueAlertFunc, log logger.Logger) *OverdueChecker {
+	return &OverdueChecker{
+		store:  store,
+		alert:  alert,
+		logger: log,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Run flags the runs that are overdue at now and returns how many were
+// flagged.
+func (c *OverdueChecker) Run(ctx context.Context, now time.Time) (int, error) {
+	runs, err := c.store.FlagOverdue(ctx, now)
+	for _, tr := range runs {
+		c.logger.Warn(ctx, "test run overdue", map[string]interface{}{
+			"test_run_id":          tr.ID.String(),
+			"max_duration_minutes": tr.MaxDurationMinutes,
+		})
+		if c.alert != nil {
+			c.alert(ctx, tr)
+		}
+	}
+	return len(runs), err
+}
+
+// Start starts a background goroutine that looks for overdue runs every
+// interval.
+func (c *OverdueChecker) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				if _, err := c.Run(context.Background(), now); err != nil {
+					c.logger.Error(context.Background(), "failed to check for overdue test runs", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
+			case <-c.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the checker goroutine.
+func (c *OverdueChecker) Stop() {
+	close(c.stopCh)
+}
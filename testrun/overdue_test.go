@@ -0,0 +1,46 @@
+package testrun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverdueChecker_Run(t *testing.T) {
+	_, store, _ := setupTestStore(t)
+	ctx := context.Background()
+	maxDuration := 30
+
+	tr := createTestRun(uuid.New(), uuid.New(), StatusPending, "")
+	tr.MaxDurationMinutes = &maxDuration
+	require.NoError(t, store.Create(ctx, tr))
+	require.NoError(t, store.Start(ctx, tr.ID, nil, nil))
+
+	var alerted []uuid.UUID
+	checker := NewOverdueChecker(store, func(ctx context.Context, tr *TestRun) {
+		alerted = append(alerted, tr.ID)
+	}, logger.NewTestLogger())
+
+	t.Run("runs on time are not alerted", func(t *testing.T) {
+		count, err := checker.Run(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Empty(t, alerted)
+	})
+
+	t.Run("overdue runs are alerted once", func(t *testing.T) {
+		count, err := checker.Run(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		count, err = checker.Run(ctx, time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, []uuid.UUID{tr.ID}, alerted)
+	})
+}
@@ -0,0 +1,134 @@
+package testrun
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// ReportedRun is a run together with the procedure version it executes and
+// its step notes, as reported to a CI system.
+type ReportedRun struct {
+	Run       *TestRun
+	Procedure *testprocedure.TestProcedure
+	StepNotes []*StepNote
+}
+
+// GitHubActionsReport reports runs to a GitHub Actions job. Commands are
+// workflow commands to print to the job's log, which turn failed and
+// unfinished runs into annotations shown inline in pull request checks.
+// Summary is Markdown to append to the file at $GITHUB_STEP_SUMMARY.
+type GitHubActionsReport struct {
+	Commands string `json:"commands"`
+	Summary  string `json:"summary"`
+}
+
+// NewGitHubActionsReport reports runs to a GitHub Actions job.
+func NewGitHubActionsReport(runs []*ReportedRun) *GitHubActionsReport {
+	var commands, summary strings.Builder
+	counts := make(map[Status]int)
+
+	summary.WriteString("## UI automation test runs\n\n")
+	summary.WriteString("| Procedure | Version | Status | Failed step | Duration | Run |\n")
+	summary.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, rr := range runs {
+		counts[rr.Run.Status]++
+		failedStep := rr.failedStep()
+
+		title := fmt.Sprintf("%s v%d", rr.Procedure.Name, rr.Procedure.Version)
+		switch rr.Run.Status {
+		case StatusFailed:
+			message := "Test run failed"
+			if failedStep != "" {
+				message += " at " + failedStep
+			}
+			if notes := strings.TrimSpace(rr.Run.Notes); notes != "" {
+				message += ": " + notes
+			}
+			writeWorkflowCommand(&commands, "error", title+" failed", message)
+		case StatusPassed:
+		default:
+			message := fmt.Sprintf("Test run %s is %s", rr.Run.ID, rr.Run.Status)
+			if rr.Run.StatusReason != "" {
+				message += ": " + rr.Run.StatusReason
+			}
+			writeWorkflowCommand(&commands, "warning", title+" "+string(rr.Run.Status), message)
+		}
+
+		duration := ""
+		if rr.Run.StartedAt != nil && rr.Run.CompletedAt != nil {
+			duration = rr.Run.CompletedAt.Sub(*rr.Run.StartedAt).Round(time.Second).String()
+		}
+		fmt.Fprintf(&summary, "| %s | v%d | %s %s | %s | %s | `%s` |\n",
+			markdownCell(rr.Procedure.Name), rr.Procedure.Version, statusEmoji(rr.Run.Status), rr.Run.Status,
+			markdownCell(failedStep), duration, rr.Run.ID)
+	}
+
+	fmt.Fprintf(&summary, "\n%d runs: %d passed, %d failed, %d skipped",
+		len(runs), counts[StatusPassed], counts[StatusFailed], counts[StatusSkipped])
+	if unfinished := len(runs) - counts[StatusPassed] - counts[StatusFailed] - counts[StatusSkipped]; unfinished > 0 {
+		fmt.Fprintf(&summary, ", %d not completed", unfinished)
+	}
+	summary.WriteString("\n")
+
+	return &GitHubActionsReport{
+		Commands: commands.String(),
+		Summary:  summary.String(),
+	}
+}
+
+// failedStep describes the step a failed run failed at, if it was recorded.
+func (rr *ReportedRun) failedStep() string {
+	index := rr.Run.FailedStepIndex
+	if rr.Run.Status != StatusFailed || index == nil {
+		return ""
+	}
+	if *index >= 0 && *index < len(rr.Procedure.Steps) {
+		return fmt.Sprintf("step %d (%s)", *index+1, rr.Procedure.Steps[*index].Name)
+	}
+	return fmt.Sprintf("step %d", *index+1)
+}
+
+// writeWorkflowCommand writes a ::command title=title::message workflow
+// command, escaped as the runner expects.
+func writeWorkflowCommand(b *strings.Builder, command, title, message string) {
+	fmt.Fprintf(b, "::%s title=%s::%s\n", command, escapeWorkflowProperty(title), escapeWorkflowData(message))
+}
+
+var (
+	workflowDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	workflowPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeWorkflowData(s string) string {
+	return workflowDataEscaper.Replace(s)
+}
+
+func escapeWorkflowProperty(s string) string {
+	return workflowPropertyEscaper.Replace(s)
+}
+
+// markdownCell makes s safe to put in a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func statusEmoji(s Status) string {
+	switch s {
+	case StatusPassed:
+		return "✅"
+	case StatusFailed:
+		return "❌"
+	case StatusSkipped:
+		return "⏭️"
+	case StatusPaused:
+		return "⏸️"
+	case StatusBlocked:
+		return "🚧"
+	default:
+		return "⏳"
+	}
+}
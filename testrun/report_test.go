@@ -0,0 +1,63 @@
+package testrun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGitHubActionsReport(t *testing.T) {
+	procedure := &testprocedure.TestProcedure{
+		Name:    "Checkout | guest",
+		Version: 2,
+		Steps: testprocedure.Steps{
+			{Name: "Open cart"},
+			{Name: "Pay"},
+		},
+	}
+	started := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	completed := started.Add(90 * time.Second)
+	failedStep := 1
+
+	passedID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	failedID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	runningID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	report := NewGitHubActionsReport([]*ReportedRun{
+		{
+			Run:       &TestRun{ID: passedID, Status: StatusPassed, StartedAt: &started, CompletedAt: &completed},
+			Procedure: procedure,
+		},
+		{
+			Run: &TestRun{
+				ID: failedID, Status: StatusFailed, StartedAt: &started, CompletedAt: &completed,
+				Notes: "Card declined\n100% reproducible", FailedStepIndex: &failedStep,
+			},
+			Procedure: procedure,
+		},
+		{
+			Run:       &TestRun{ID: runningID, Status: StatusRunning, StartedAt: &started},
+			Procedure: procedure,
+		},
+	})
+
+	assert.Equal(t,
+		"::error title=Checkout | guest v2 failed::Test run failed at step 2 (Pay): Card declined%0A100%25 reproducible\n"+
+			"::warning title=Checkout | guest v2 running::Test run 33333333-3333-3333-3333-333333333333 is running\n",
+		report.Commands)
+
+	assert.Equal(t, "## UI automation test runs\n\n"+
+		"| Procedure | Version | Status | Failed step | Duration | Run |\n"+
+		"| --- | --- | --- | --- | --- | --- |\n"+
+		"| Checkout \\| guest | v2 | ✅ passed |  | 1m30s | `11111111-1111-1111-1111-111111111111` |\n"+
+		"| Checkout \\| guest | v2 | ❌ failed | step 2 (Pay) | 1m30s | `22222222-2222-2222-2222-222222222222` |\n"+
+		"| Checkout \\| guest | v2 | ⏳ running |  |  | `33333333-3333-3333-3333-333333333333` |\n"+
+		"\n3 runs: 1 passed, 1 failed, 0 skipped, 1 not completed\n",
+		report.Summary)
+}
+
+func TestEscapeWorkflowProperty(t *testing.T) {
+	assert.Equal(t, "Login%3A admin%2C v1%0A", escapeWorkflowProperty("Login: admin, v1\n"))
+}
@@ -1,6 +1,10 @@
 package testrun
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // SetStatus returns an UpdateSetter that sets the test run's status.
 func SetStatus(status Status) UpdateSetter {
@@ -36,3 +40,50 @@ func ClearAssignedTo() UpdateSetter {
 		return nil
 	}
 }
+
+// SetDueAt returns an UpdateSetter that sets when the test run is due.
+func SetDueAt(dueAt time.Time) UpdateSetter {
+	return func(tr *TestRun) error {
+		tr.DueAt = &dueAt
+		return nil
+	}
+}
+
+// ClearDueAt returns an UpdateSetter that removes the test run's due date.
+func ClearDueAt() UpdateSetter {
+	return func(tr *TestRun) error {
+		tr.DueAt = nil
+		return nil
+	}
+}
+
+// SetRelease returns an UpdateSetter that tags the test run with a release.
+func SetRelease(releaseID uuid.UUID) UpdateSetter {
+	return func(tr *TestRun) error {
+		tr.ReleaseID = &releaseID
+		return nil
+	}
+}
+
+// ClearRelease returns an UpdateSetter that untags the test run from its release.
+func ClearRelease() UpdateSetter {
+	return func(tr *TestRun) error {
+		tr.ReleaseID = nil
+		return nil
+	}
+}
+
+// SetFailedStepIndex returns an UpdateSetter that records the step a failed
+// test run failed at.
+func SetFailedStepIndex(stepIndex int) UpdateSetter {
+	return func(tr *TestRun) error {
+		if tr.Status != StatusFailed {
+			return ErrInvalidStatus
+		}
+		if stepIndex < 0 {
+			return ErrInvalidStepIndex
+		}
+		tr.FailedStepIndex = &stepIndex
+		return nil
+	}
+}
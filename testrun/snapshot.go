@@ -0,0 +1,52 @@
+package testrun
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
+)
+
+// ProcedureSnapshot is a frozen copy of the test procedure version a run
+// executes, captured when the run starts. Later edits to, or deletion of,
+// the procedure do not change what a historical run displays: procedures are
+// soft-deleted, so the run and its snapshot are kept.
+type ProcedureSnapshot testprocedure.TestProcedure
+
+// NewProcedureSnapshot copies tp, including its steps, into a snapshot.
+func NewProcedureSnapshot(tp *testprocedure.TestProcedure) *ProcedureSnapshot {
+	snapshot := ProcedureSnapshot(*tp)
+	snapshot.Steps = make(testprocedure.Steps, len(tp.Steps))
+	for i, step := range tp.Steps {
+		step.ImagePaths = append([]string(nil), step.ImagePaths...)
+		step.Attachments = append([]testprocedure.Attachment(nil), step.Attachments...)
+		snapshot.Steps[i] = step
+	}
+	snapshot.Preconditions = append(testprocedure.Preconditions(nil), tp.Preconditions...)
+	return &snapshot
+}
+
+// Procedure returns the snapshot as a test procedure.
+func (p *ProcedureSnapshot) Procedure() *testprocedure.TestProcedure {
+	return (*testprocedure.TestProcedure)(p)
+}
+
+// Value implements the driver.Valuer interface for database storage.
+func (p ProcedureSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (p *ProcedureSnapshot) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("failed to scan ProcedureSnapshot: not a byte slice")
+	}
+	return json.Unmarshal(bytes, p)
+}
@@ -13,6 +13,25 @@ var (
 	ErrStepNoteNotFound = errors.New("step note not found")
 )
 
+// StepStatus is the outcome of a single step of a test run.
+type StepStatus string
+
+const (
+	StepStatusPassed  StepStatus = "passed"
+	StepStatusFailed  StepStatus = "failed"
+	StepStatusSkipped StepStatus = "skipped"
+)
+
+// IsValid checks if the step status is valid.
+func (s StepStatus) IsValid() bool {
+	switch s {
+	case StepStatusPassed, StepStatusFailed, StepStatusSkipped:
+		return true
+	default:
+		return false
+	}
+}
+
 // StepNote represents notes for a specific test procedure step within a test run.
 type StepNote struct {
 	ID        uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
@@ -21,6 +40,14 @@ type StepNote struct {
 	Notes     string    `json:"notes" gorm:"type:text"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Status is the step's outcome, recorded by automated runs. It is empty
+	// for steps of manual runs, whose outcome is in the notes.
+	Status StepStatus `json:"status,omitempty" gorm:"type:varchar(20);not null;default:''"`
+
+	// Attachments are the run's assets uploaded with this note's ID. They are
+	// not loaded by the store.
+	Attachments []*TestRunAsset `json:"attachments" gorm:"-"`
 }
 
 // BeforeCreate hook to generate UUID before creating a new step note.
@@ -24,6 +24,7 @@ func NewMySQLStepNoteStore(db *gorm.DB, log logger.Logger) *MySQLStepNoteStore {
 }
 
 // Upsert creates or updates a step note for a given (test_run_id, step_index).
+// Updating a note without a status keeps the status it has.
 func (s *MySQLStepNoteStore) Upsert(ctx context.Context, note *StepNote) error {
 	existing, err := s.GetByRunAndStep(ctx, note.TestRunID, note.StepIndex)
 	if err != nil && !errors.Is(err, ErrStepNoteNotFound) {
@@ -32,6 +33,9 @@ func (s *MySQLStepNoteStore) Upsert(ctx context.Context, note *StepNote) error {
 
 	if existing != nil {
 		existing.Notes = note.Notes
+		if note.Status != "" {
+			existing.Status = note.Status
+		}
 		if err := s.db.WithContext(ctx).Save(existing).Error; err != nil {
 			s.logger.Error(ctx, "failed to update step note", map[string]interface{}{
 				"error":       err.Error(),
@@ -75,6 +79,25 @@ func (s *MySQLStepNoteStore) ListByTestRun(ctx context.Context, testRunID uuid.U
 	return notes, nil
 }
 
+// GetByID retrieves a step note by its ID.
+func (s *MySQLStepNoteStore) GetByID(ctx context.Context, id uuid.UUID) (*StepNote, error) {
+	var note StepNote
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&note).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStepNoteNotFound
+		}
+		s.logger.Error(ctx, "failed to get step note", map[string]interface{}{
+			"error":        err.Error(),
+			"step_note_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &note, nil
+}
+
 // GetByRunAndStep retrieves a step note for a specific run and step index.
 func (s *MySQLStepNoteStore) GetByRunAndStep(ctx context.Context, testRunID uuid.UUID, stepIndex int) (*StepNote, error) {
 	var note StepNote
@@ -14,6 +14,9 @@ type StepNoteStore interface {
 	// ListByTestRun retrieves all step notes for a specific test run, ordered by step_index.
 	ListByTestRun(ctx context.Context, testRunID uuid.UUID) ([]*StepNote, error)
 
+	// GetByID retrieves a step note by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*StepNote, error)
+
 	// GetByRunAndStep retrieves a step note for a specific run and step index.
 	GetByRunAndStep(ctx context.Context, testRunID uuid.UUID, stepIndex int) (*StepNote, error)
 }
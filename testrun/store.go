@@ -2,8 +2,10 @@ package testrun
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/database"
 )
 
 // Store defines the interface for test run persistence operations.
@@ -29,11 +31,72 @@ type Store interface {
 	// CountByTestProcedures returns the total count of test runs for multiple procedure versions.
 	CountByTestProcedures(ctx context.Context, testProcedureIDs []uuid.UUID) (int, error)
 
-	// Start marks a test run as started (sets started_at, changes status to running).
-	Start(ctx context.Context, id uuid.UUID) error
+	// ListByTestProceduresFiltered retrieves a paginated list of test runs for multiple
+	// procedure versions that match the filter, in the filter's sort order.
+	ListByTestProceduresFiltered(ctx context.Context, testProcedureIDs []uuid.UUID, filter ListFilter, limit, offset int) ([]*TestRun, error)
+
+	// ListByTestProceduresAfter retrieves up to limit test runs for multiple
+	// procedure versions that match the filter, newest first, after the
+	// cursor. The filter's sort is ignored.
+	ListByTestProceduresAfter(ctx context.Context, testProcedureIDs []uuid.UUID, filter ListFilter, after *database.Cursor, limit int) ([]*TestRun, error)
+
+	// CountByTestProceduresFiltered returns the total count of test runs for multiple
+	// procedure versions that match the filter.
+	CountByTestProceduresFiltered(ctx context.Context, testProcedureIDs []uuid.UUID, filter ListFilter) (int, error)
+
+	// Start marks a test run as started (sets started_at, changes status to running)
+	// and stores the procedure snapshot the run executes against.
+	// acknowledgedBy is the user who confirmed the snapshot's preconditions
+	// are in place and the run's checklist is complete; it is required if
+	// the snapshot has preconditions or the run a checklist.
+	Start(ctx context.Context, id uuid.UUID, snapshot *ProcedureSnapshot, acknowledgedBy *uuid.UUID) error
 
 	// Complete marks a test run as completed (sets completed_at, final status, optional notes).
 	Complete(ctx context.Context, id uuid.UUID, status Status, notes string) error
+
+	// Pause marks a running test run as paused, with an optional reason.
+	Pause(ctx context.Context, id uuid.UUID, reason string) error
+
+	// Resume marks a paused test run as running again.
+	Resume(ctx context.Context, id uuid.UUID) error
+
+	// Block marks a pending, running or paused test run as blocked for the
+	// reason.
+	Block(ctx context.Context, id uuid.UUID, reason string) error
+
+	// Unblock returns a blocked test run to running if it had started, or
+	// to pending if not.
+	Unblock(ctx context.Context, id uuid.UUID) error
+
+	// GetRunning retrieves the oldest running, paused or blocked test run
+	// of the procedure versions in the environment, or returns
+	// ErrTestRunNotFound if there is none.
+	GetRunning(ctx context.Context, testProcedureIDs []uuid.UUID, environment string) (*TestRun, error)
+
+	// Exclusive calls change with a store whose changes are made in one
+	// transaction with the check that no test run of the procedure versions
+	// but exceptID is running, paused or blocked in the environment. If one
+	// is, it returns a *ConcurrentRunError with it and change is not called.
+	// Exclusive changes of the procedure whose first version is rootID are
+	// made one at a time.
+	Exclusive(ctx context.Context, rootID uuid.UUID, testProcedureIDs []uuid.UUID, environment string, exceptID uuid.UUID, change func(Store) error) error
+
+	// FlagOverdue marks the running test runs that are past their
+	// OverdueAfter at now and not yet marked as overdue, and returns them.
+	FlagOverdue(ctx context.Context, now time.Time) ([]*TestRun, error)
+
+	// Claim assigns an unassigned test run that has not completed to the
+	// user. It returns ErrRunAlreadyAssigned if someone else got it first.
+	Claim(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+
+	// ListAssigned retrieves a paginated list of the test runs assigned to
+	// the user that match the filter, soonest due first; runs without a due
+	// date come last, oldest first.
+	ListAssigned(ctx context.Context, userID uuid.UUID, filter QueueFilter, limit, offset int) ([]*TestRun, error)
+
+	// CountAssigned returns the total count of test runs assigned to the
+	// user that match the filter.
+	CountAssigned(ctx context.Context, userID uuid.UUID, filter QueueFilter) (int, error)
 }
 
 // UpdateSetter is a function that updates a test run field.
@@ -0,0 +1,282 @@
+package testrun
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxTemplateNotesLength is the longest notes scaffold a run template
+	// may have, in characters.
+	MaxTemplateNotesLength = 10000
+
+	// MaxEnvironmentFields is the most environment fields a run template
+	// may ask for.
+	MaxEnvironmentFields = 20
+
+	// MaxChecklistItems is the most items a run template's checklist may
+	// have.
+	MaxChecklistItems = 50
+
+	// MaxTemplateItemLength is the longest an environment field name or
+	// checklist item may be, in characters.
+	MaxTemplateItemLength = 200
+
+	// MaxRunDurationMinutes is the longest expected run duration a run
+	// template may set: a week.
+	MaxRunDurationMinutes = 7 * 24 * 60
+)
+
+var (
+	// ErrRunTemplateNotFound is returned when a procedure has no run
+	// template.
+	ErrRunTemplateNotFound = errors.New("run template not found")
+
+	// ErrInvalidRunTemplate is returned when a run template's notes,
+	// environment fields or checklist are invalid.
+	ErrInvalidRunTemplate = errors.New("invalid run template")
+
+	// ErrMissingEnvironmentField is returned when a run is created without
+	// a value for an environment field its template requires.
+	ErrMissingEnvironmentField = errors.New("missing required environment field")
+
+	// ErrUnknownEnvironmentField is returned when a run is created with a
+	// value for an environment field its template does not ask for.
+	ErrUnknownEnvironmentField = errors.New("unknown environment field")
+
+	// ErrConcurrentRun is returned when creating, starting, resuming or
+	// unblocking a run while another run of a procedure that prevents
+	// concurrent runs is running, paused or blocked in the same environment.
+	ErrConcurrentRun = errors.New("another run of the procedure is running in the same environment")
+
+	// ErrChecklistNotCompleted is returned when starting a run whose
+	// pre-run checklist nobody has completed.
+	ErrChecklistNotCompleted = errors.New("the run's checklist must be completed before the run starts")
+)
+
+// ConcurrentRunError describes a run change refused because another run of
+// the procedure is running in the same environment.
+type ConcurrentRunError struct {
+	Run *TestRun
+}
+
+func (e *ConcurrentRunError) Error() string {
+	return ErrConcurrentRun.Error()
+}
+
+// Is reports whether target is ErrConcurrentRun.
+func (e *ConcurrentRunError) Is(target error) bool {
+	return target == ErrConcurrentRun
+}
+
+// EnvironmentField is a detail of the environment a tester records when
+// creating a run, such as the browser or build under test.
+type EnvironmentField struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// EnvironmentFields are the environment fields a run template asks for.
+type EnvironmentFields []EnvironmentField
+
+// Value implements the driver.Valuer interface for database storage.
+func (f EnvironmentFields) Value() (driver.Value, error) {
+	if f == nil {
+		return json.Marshal([]EnvironmentField{})
+	}
+	return json.Marshal([]EnvironmentField(f))
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (f *EnvironmentFields) Scan(value interface{}) error {
+	return scanJSON(value, f, "EnvironmentFields")
+}
+
+// Checklist is a list of things to check before a run starts.
+type Checklist []string
+
+// Value implements the driver.Valuer interface for database storage.
+func (c Checklist) Value() (driver.Value, error) {
+	if c == nil {
+		return json.Marshal([]string{})
+	}
+	return json.Marshal([]string(c))
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (c *Checklist) Scan(value interface{}) error {
+	return scanJSON(value, c, "Checklist")
+}
+
+// EnvironmentDetails are the values a run was created with for its
+// template's environment fields, by field name.
+type EnvironmentDetails map[string]string
+
+// Value implements the driver.Valuer interface for database storage.
+func (d EnvironmentDetails) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return json.Marshal(map[string]string(d))
+}
+
+// Scan implements the sql.Scanner interface for database retrieval.
+func (d *EnvironmentDetails) Scan(value interface{}) error {
+	return scanJSON(value, d, "EnvironmentDetails")
+}
+
+// scanJSON unmarshals the JSON column value into dest, leaving dest as it
+// is for NULL.
+func scanJSON(value interface{}, dest interface{}, name string) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan %s: not a byte slice", name)
+	}
+	return json.Unmarshal(bytes, dest)
+}
+
+// RunTemplate is what every new run of a test procedure starts with: a
+// scaffold for its notes, the environment fields the tester records, a
+// checklist to complete before the run starts, and how long the run is
+// expected to take at most. PreventConcurrentRuns refuses new runs while
+// another run of the procedure is running in the same environment.
+// TestProcedureID is the procedure's first version, so the template applies
+// to all its versions.
+type RunTemplate struct {
+	ID                    uuid.UUID         `json:"id" gorm:"type:char(36);primaryKey"`
+	TestProcedureID       uuid.UUID         `json:"test_procedure_id" gorm:"type:char(36);not null;uniqueIndex:idx_test_run_templates_test_procedure_id"`
+	Notes                 string            `json:"notes" gorm:"type:text"`
+	EnvironmentFields     EnvironmentFields `json:"environment_fields" gorm:"type:json"`
+	Checklist             Checklist         `json:"checklist" gorm:"type:json"`
+	MaxDurationMinutes    int               `json:"max_duration_minutes,omitempty" gorm:"not null;default:0"`
+	PreventConcurrentRuns bool              `json:"prevent_concurrent_runs,omitempty" gorm:"not null;default:false"`
+	UpdatedBy             uuid.UUID         `json:"updated_by" gorm:"type:char(36);not null"`
+	CreatedAt             time.Time         `json:"created_at"`
+	UpdatedAt             time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (RunTemplate) TableName() string {
+	return "test_run_templates"
+}
+
+// BeforeCreate hook to generate UUID before creating a new run template.
+func (t *RunTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Normalize trims the template's field names and checklist items.
+func (t *RunTemplate) Normalize() {
+	for i := range t.EnvironmentFields {
+		t.EnvironmentFields[i].Name = strings.TrimSpace(t.EnvironmentFields[i].Name)
+	}
+	for i := range t.Checklist {
+		t.Checklist[i] = strings.TrimSpace(t.Checklist[i])
+	}
+}
+
+// Validate checks if the run template has valid required fields.
+// Environment field names must be unique and neither they nor checklist
+// items may be blank.
+func (t *RunTemplate) Validate() error {
+	if t.TestProcedureID == uuid.Nil {
+		return ErrInvalidTestProcedureID
+	}
+	if t.UpdatedBy == uuid.Nil {
+		return fmt.Errorf("%w: updated_by is required", ErrInvalidRunTemplate)
+	}
+	if utf8.RuneCountInString(t.Notes) > MaxTemplateNotesLength {
+		return fmt.Errorf("%w: notes must be at most %d characters", ErrInvalidRunTemplate, MaxTemplateNotesLength)
+	}
+	if len(t.EnvironmentFields) > MaxEnvironmentFields {
+		return fmt.Errorf("%w: at most %d environment fields", ErrInvalidRunTemplate, MaxEnvironmentFields)
+	}
+	seen := make(map[string]bool, len(t.EnvironmentFields))
+	for i, field := range t.EnvironmentFields {
+		if field.Name == "" || utf8.RuneCountInString(field.Name) > MaxTemplateItemLength {
+			return fmt.Errorf("%w: environment field %d must be named with at most %d characters", ErrInvalidRunTemplate, i+1, MaxTemplateItemLength)
+		}
+		if seen[field.Name] {
+			return fmt.Errorf("%w: environment field %q is listed twice", ErrInvalidRunTemplate, field.Name)
+		}
+		seen[field.Name] = true
+	}
+	if len(t.Checklist) > MaxChecklistItems {
+		return fmt.Errorf("%w: at most %d checklist items", ErrInvalidRunTemplate, MaxChecklistItems)
+	}
+	for i, item := range t.Checklist {
+		if item == "" || utf8.RuneCountInString(item) > MaxTemplateItemLength {
+			return fmt.Errorf("%w: checklist item %d must have between 1 and %d characters", ErrInvalidRunTemplate, i+1, MaxTemplateItemLength)
+		}
+	}
+	if t.MaxDurationMinutes < 0 || t.MaxDurationMinutes > MaxRunDurationMinutes {
+		return fmt.Errorf("%w: max_duration_minutes must be between 0 and %d", ErrInvalidRunTemplate, MaxRunDurationMinutes)
+	}
+	return nil
+}
+
+// Apply sets up a new run from the template: the run gets the template's
+// notes, unless it already has some, checklist and expected maximum
+// duration, and records values for the template's environment fields.
+// Every required field needs a non-blank value, and values for fields the
+// template does not ask for are refused.
+func (t *RunTemplate) Apply(run *TestRun, values map[string]string) error {
+	known := make(map[string]bool, len(t.EnvironmentFields))
+	details := make(EnvironmentDetails, len(t.EnvironmentFields))
+	for _, field := range t.EnvironmentFields {
+		known[field.Name] = true
+		value := strings.TrimSpace(values[field.Name])
+		if value == "" {
+			if field.Required {
+				return fmt.Errorf("%w: %s", ErrMissingEnvironmentField, field.Name)
+			}
+			continue
+		}
+		details[field.Name] = value
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w: %s", ErrUnknownEnvironmentField, strings.Join(unknown, ", "))
+	}
+
+	if run.Notes == "" {
+		run.Notes = t.Notes
+	}
+	if len(details) > 0 {
+		run.EnvironmentDetails = details
+	}
+	if len(t.Checklist) > 0 {
+		run.Checklist = append(Checklist(nil), t.Checklist...)
+	}
+	if t.MaxDurationMinutes > 0 {
+		maxDuration := t.MaxDurationMinutes
+		run.MaxDurationMinutes = &maxDuration
+	}
+	return nil
+}
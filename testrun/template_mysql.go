@@ -0,0 +1,111 @@
+package testrun
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLRunTemplateStore implements RunTemplateStore using GORM and MySQL.
+type MySQLRunTemplateStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLRunTemplateStore creates a new MySQL-backed run template store.
+func NewMySQLRunTemplateStore(db *gorm.DB, log logger.Logger) *MySQLRunTemplateStore {
+	return &MySQLRunTemplateStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Get retrieves the run template of a test procedure.
+func (s *MySQLRunTemplateStore) Get(ctx context.Context, testProcedureID uuid.UUID) (*RunTemplate, error) {
+	var template RunTemplate
+	err := s.db.WithContext(ctx).Where("test_procedure_id = ?", testProcedureID).First(&template).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRunTemplateNotFound
+		}
+		s.logger.Error(ctx, "failed to get run template", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": testProcedureID.String(),
+		})
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// Save creates the run template of a test procedure, or replaces the one it
+// has.
+func (s *MySQLRunTemplateStore) Save(ctx context.Context, template *RunTemplate) error {
+	template.Normalize()
+	if err := template.Validate(); err != nil {
+		return err
+	}
+
+	existing, err := s.Get(ctx, template.TestProcedureID)
+	if err != nil && !errors.Is(err, ErrRunTemplateNotFound) {
+		return err
+	}
+
+	if existing != nil {
+		existing.Notes = template.Notes
+		existing.EnvironmentFields = template.EnvironmentFields
+		existing.Checklist = template.Checklist
+		existing.MaxDurationMinutes = template.MaxDurationMinutes
+		existing.PreventConcurrentRuns = template.PreventConcurrentRuns
+		existing.UpdatedBy = template.UpdatedBy
+		if err := s.db.WithContext(ctx).Save(existing).Error; err != nil {
+			s.logger.Error(ctx, "failed to update run template", map[string]interface{}{
+				"error":             err.Error(),
+				"test_procedure_id": template.TestProcedureID.String(),
+			})
+			return err
+		}
+		*template = *existing
+		return nil
+	}
+
+	if err := s.db.WithContext(ctx).Create(template).Error; err != nil {
+		s.logger.Error(ctx, "failed to create run template", map[string]interface{}{
+			"error":             err.Error(),
+			"test_procedure_id": template.TestProcedureID.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "run template created", map[string]interface{}{
+		"run_template_id":   template.ID.String(),
+		"test_procedure_id": template.TestProcedureID.String(),
+	})
+
+	return nil
+}
+
+// Delete deletes the run template of a test procedure.
+func (s *MySQLRunTemplateStore) Delete(ctx context.Context, testProcedureID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("test_procedure_id = ?", testProcedureID).
+		Delete(&RunTemplate{})
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete run template", map[string]interface{}{
+			"error":             result.Error.Error(),
+			"test_procedure_id": testProcedureID.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrRunTemplateNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package testrun
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RunTemplateStore defines the interface for run template persistence
+// operations. Templates are looked up by the first version of their
+// procedure.
+type RunTemplateStore interface {
+	// Get retrieves the run template of a test procedure.
+	Get(ctx context.Context, testProcedureID uuid.UUID) (*RunTemplate, error)
+
+	// Save creates the run template of a test procedure, or replaces the
+	// one it has.
+	Save(ctx context.Context, template *RunTemplate) error
+
+	// Delete deletes the run template of a test procedure.
+	Delete(ctx context.Context, testProcedureID uuid.UUID) error
+}
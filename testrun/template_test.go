@@ -0,0 +1,144 @@
+package testrun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTemplate_Validate(t *testing.T) {
+	valid := func() *RunTemplate {
+		return &RunTemplate{
+			TestProcedureID:   uuid.New(),
+			UpdatedBy:         uuid.New(),
+			Notes:             "## Observations\n",
+			EnvironmentFields: EnvironmentFields{{Name: "Browser", Required: true}, {Name: "Build"}},
+			Checklist:         Checklist{"Clear the browser cache"},
+		}
+	}
+
+	assert.NoError(t, valid().Validate())
+
+	tests := map[string]func(*RunTemplate){
+		"missing procedure":    func(tpl *RunTemplate) { tpl.TestProcedureID = uuid.Nil },
+		"blank field name":     func(tpl *RunTemplate) { tpl.EnvironmentFields[1].Name = "" },
+		"duplicate field name": func(tpl *RunTemplate) { tpl.EnvironmentFields[1].Name = "Browser" },
+		"blank checklist item": func(tpl *RunTemplate) { tpl.Checklist = Checklist{""} },
+		"negative max duration": func(tpl *RunTemplate) { tpl.MaxDurationMinutes = -1 },
+		"max duration too long": func(tpl *RunTemplate) { tpl.MaxDurationMinutes = MaxRunDurationMinutes + 1 },
+		"too many checklist items": func(tpl *RunTemplate) {
+			tpl.Checklist = make(Checklist, MaxChecklistItems+1)
+			for i := range tpl.Checklist {
+				tpl.Checklist[i] = "Check"
+			}
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			tpl := valid()
+			mutate(tpl)
+			assert.Error(t, tpl.Validate())
+		})
+	}
+}
+
+func TestRunTemplate_Apply(t *testing.T) {
+	tpl := &RunTemplate{
+		Notes:             "## Observations\n",
+		EnvironmentFields: EnvironmentFields{{Name: "Browser", Required: true}, {Name: "Build"}},
+		Checklist:         Checklist{"Clear the browser cache"},
+	}
+
+	t.Run("sets up the run", func(t *testing.T) {
+		run := &TestRun{}
+		require.NoError(t, tpl.Apply(run, map[string]string{"Browser": " Firefox "}))
+		assert.Equal(t, "## Observations\n", run.Notes)
+		assert.Equal(t, EnvironmentDetails{"Browser": "Firefox"}, run.EnvironmentDetails)
+		assert.Equal(t, Checklist{"Clear the browser cache"}, run.Checklist)
+		assert.Nil(t, run.MaxDurationMinutes)
+	})
+
+	t.Run("copies the expected maximum duration", func(t *testing.T) {
+		timed := &RunTemplate{MaxDurationMinutes: 90}
+		run := &TestRun{}
+		require.NoError(t, timed.Apply(run, nil))
+		require.NotNil(t, run.MaxDurationMinutes)
+		assert.Equal(t, 90, *run.MaxDurationMinutes)
+	})
+
+	t.Run("keeps notes the run has", func(t *testing.T) {
+		run := &TestRun{Notes: "Retest of the login fix"}
+		require.NoError(t, tpl.Apply(run, map[string]string{"Browser": "Firefox"}))
+		assert.Equal(t, "Retest of the login fix", run.Notes)
+	})
+
+	t.Run("required fields need a value", func(t *testing.T) {
+		err := tpl.Apply(&TestRun{}, map[string]string{"Browser": " ", "Build": "1.2.3"})
+		assert.ErrorIs(t, err, ErrMissingEnvironmentField)
+	})
+
+	t.Run("unknown fields are refused", func(t *testing.T) {
+		err := tpl.Apply(&TestRun{}, map[string]string{"Browser": "Firefox", "OS": "Linux"})
+		assert.ErrorIs(t, err, ErrUnknownEnvironmentField)
+	})
+}
+
+func TestMySQLRunTemplateStore(t *testing.T) {
+	store := setupRunTemplateStore(t)
+	ctx := context.Background()
+	procedureID := uuid.New()
+
+	t.Run("get without a template", func(t *testing.T) {
+		_, err := store.Get(ctx, procedureID)
+		assert.ErrorIs(t, err, ErrRunTemplateNotFound)
+	})
+
+	t.Run("save creates and then replaces", func(t *testing.T) {
+		tpl := &RunTemplate{
+			TestProcedureID:   procedureID,
+			UpdatedBy:         uuid.New(),
+			Notes:             "## Observations\n",
+			EnvironmentFields: EnvironmentFields{{Name: " Browser ", Required: true}},
+			Checklist:         Checklist{"Clear the browser cache"},
+		}
+		require.NoError(t, store.Save(ctx, tpl))
+		firstID := tpl.ID
+
+		replacement := &RunTemplate{
+			TestProcedureID: procedureID,
+			UpdatedBy:       uuid.New(),
+			Checklist:       Checklist{"Log out first"},
+		}
+		require.NoError(t, store.Save(ctx, replacement))
+		assert.Equal(t, firstID, replacement.ID)
+
+		got, err := store.Get(ctx, procedureID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Notes)
+		assert.Empty(t, got.EnvironmentFields)
+		assert.Equal(t, Checklist{"Log out first"}, got.Checklist)
+	})
+
+	t.Run("save trims and validates", func(t *testing.T) {
+		tpl := &RunTemplate{
+			TestProcedureID:   uuid.New(),
+			UpdatedBy:         uuid.New(),
+			EnvironmentFields: EnvironmentFields{{Name: " Browser "}},
+		}
+		require.NoError(t, store.Save(ctx, tpl))
+		assert.Equal(t, "Browser", tpl.EnvironmentFields[0].Name)
+
+		err := store.Save(ctx, &RunTemplate{TestProcedureID: uuid.New(), UpdatedBy: uuid.New(), Checklist: Checklist{"  "}})
+		assert.ErrorIs(t, err, ErrInvalidRunTemplate)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, procedureID))
+		_, err := store.Get(ctx, procedureID)
+		assert.ErrorIs(t, err, ErrRunTemplateNotFound)
+		assert.ErrorIs(t, store.Delete(ctx, procedureID), ErrRunTemplateNotFound)
+	})
+}
@@ -2,12 +2,20 @@ package testrun
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/testprocedure"
 	"gorm.io/gorm"
 )
 
+// MaxStatusReasonLength is the longest reason a run can be paused or
+// blocked with, in characters.
+const MaxStatusReasonLength = 500
+
 var (
 	// ErrTestRunNotFound is returned when a test run is not found.
 	ErrTestRunNotFound = errors.New("test run not found")
@@ -26,6 +34,37 @@ var (
 
 	// ErrTestRunAlreadyStarted is returned when trying to start an already started test run.
 	ErrTestRunAlreadyStarted = errors.New("test run already started")
+
+	// ErrPreconditionsNotAcknowledged is returned when starting a run of a
+	// procedure with preconditions that nobody has acknowledged.
+	ErrPreconditionsNotAcknowledged = errors.New("the procedure's preconditions must be acknowledged before the run starts")
+
+	// ErrInvalidStepIndex is returned when a step index is outside the run's
+	// procedure.
+	ErrInvalidStepIndex = errors.New("invalid step index")
+
+	// ErrInvalidTransition is returned when a run cannot move from its
+	// status to the requested one.
+	ErrInvalidTransition = errors.New("invalid status transition")
+
+	// ErrStatusReasonRequired is returned when blocking a run without a
+	// reason.
+	ErrStatusReasonRequired = errors.New("a reason is required to block a test run")
+
+	// ErrStatusReasonTooLong is returned when a run is paused or blocked
+	// with a reason longer than MaxStatusReasonLength.
+	ErrStatusReasonTooLong = errors.New("reason must be at most 500 characters")
+
+	// ErrRunAlreadyAssigned is returned when claiming a run that is already
+	// assigned to someone.
+	ErrRunAlreadyAssigned = errors.New("test run is already assigned")
+
+	// ErrTestRunCompleted is returned when claiming a run that has already
+	// completed.
+	ErrTestRunCompleted = errors.New("test run has already completed")
+
+	// ErrInvalidSort is returned when a run list sort is not supported.
+	ErrInvalidSort = errors.New("sort must be created_at, started_at or duration, optionally prefixed with -")
 )
 
 // Status represents the status of a test run.
@@ -37,12 +76,19 @@ const (
 	StatusPassed  Status = "passed"
 	StatusFailed  Status = "failed"
 	StatusSkipped Status = "skipped"
+
+	// StatusPaused is a started run its tester stepped away from.
+	StatusPaused Status = "paused"
+
+	// StatusBlocked is a run that cannot go on until something outside it,
+	// such as an environment outage, is resolved.
+	StatusBlocked Status = "blocked"
 )
 
 // IsValid checks if the status is valid.
 func (s Status) IsValid() bool {
 	switch s {
-	case StatusPending, StatusRunning, StatusPassed, StatusFailed, StatusSkipped:
+	case StatusPending, StatusRunning, StatusPassed, StatusFailed, StatusSkipped, StatusPaused, StatusBlocked:
 		return true
 	default:
 		return false
@@ -54,6 +100,33 @@ func (s Status) IsFinal() bool {
 	return s == StatusPassed || s == StatusFailed || s == StatusSkipped
 }
 
+// transitions are the statuses a run can move to from each status that is
+// not final. Runs are started from pending and completed from running;
+// only running runs can be paused, and blocked runs go back to pending or
+// running depending on whether they had started.
+var transitions = map[Status][]Status{
+	StatusPending: {StatusRunning, StatusBlocked},
+	StatusRunning: {StatusPassed, StatusFailed, StatusSkipped, StatusPaused, StatusBlocked},
+	StatusPaused:  {StatusRunning, StatusBlocked},
+	StatusBlocked: {StatusPending, StatusRunning},
+}
+
+// CanTransitionTo reports whether a run can move from s to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionError is the error for a run that cannot move from one status
+// to another.
+func transitionError(from, to Status) error {
+	return fmt.Errorf("%w: cannot go from %s to %s", ErrInvalidTransition, from, to)
+}
+
 // TestRun represents a test run in the system.
 type TestRun struct {
 	ID              uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
@@ -66,6 +139,69 @@ type TestRun struct {
 	CompletedAt     *time.Time `json:"completed_at,omitempty"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// DueAt is when the run's assignee is expected to have completed it.
+	DueAt *time.Time `json:"due_at,omitempty" gorm:"index:idx_test_runs_due_at"`
+
+	// StatusReason is why a paused or blocked run was stopped. PausedAt is
+	// when a started run was last paused or blocked, and PausedDuration
+	// the time in milliseconds it spent paused or blocked before that,
+	// which is left out of its Duration.
+	StatusReason   string     `json:"status_reason,omitempty" gorm:"type:varchar(500);not null;default:''"`
+	PausedAt       *time.Time `json:"paused_at,omitempty"`
+	PausedDuration int64      `json:"paused_duration,omitempty" gorm:"not null;default:0"`
+
+	// MaxDurationMinutes is how long the run is expected to take at most,
+	// from its procedure's run template. OverdueAfter is when a started run
+	// goes over it, moved back by the time the run spends paused or
+	// blocked, and OverdueAt when the run was found still running after it.
+	MaxDurationMinutes *int       `json:"max_duration_minutes,omitempty"`
+	OverdueAfter       *time.Time `json:"overdue_after,omitempty" gorm:"index:idx_test_runs_overdue_after"`
+	OverdueAt          *time.Time `json:"overdue_at,omitempty"`
+
+	// EndpointID, Environment and BaseURL record the endpoint the run executes
+	// against. They are copied when the run is created so the run keeps its
+	// target even if the endpoint later changes.
+	EndpointID  *uuid.UUID `json:"endpoint_id,omitempty" gorm:"type:char(36);index:idx_test_runs_endpoint_id"`
+	Environment string     `json:"environment,omitempty" gorm:"type:varchar(50);not null;default:''"`
+	BaseURL     string     `json:"base_url,omitempty" gorm:"type:varchar(2048);not null;default:''"`
+
+	// Parameters holds the values of the procedure's parameters the run
+	// was created with, defaults included. They are substituted into the
+	// procedure's placeholders when the run starts.
+	Parameters testprocedure.ParameterValues `json:"parameters,omitempty" gorm:"type:json"`
+
+	// GroupID is the run group the run was launched in, and DatasetRow the
+	// index of the dataset row its parameter values came from.
+	GroupID    *uuid.UUID `json:"group_id,omitempty" gorm:"type:char(36);index:idx_test_runs_group_id"`
+	DatasetRow *int       `json:"dataset_row,omitempty"`
+
+	// EnvironmentDetails and Checklist come from the procedure's run
+	// template when the run is created: the values recorded for the
+	// template's environment fields, and what must be checked before the
+	// run starts.
+	EnvironmentDetails EnvironmentDetails `json:"environment_details,omitempty" gorm:"type:json"`
+	Checklist          Checklist          `json:"checklist,omitempty" gorm:"type:json"`
+
+	// PreconditionsAcknowledgedBy is the user who confirmed the procedure's
+	// preconditions were in place, and the run's checklist completed, when
+	// the run started. It is nil for runs with neither.
+	PreconditionsAcknowledgedBy *uuid.UUID `json:"preconditions_acknowledged_by,omitempty" gorm:"type:char(36)"`
+
+	// ProcedureSnapshot holds the procedure content as it was when the run
+	// started. It is nil for runs that have not started yet.
+	ProcedureSnapshot *ProcedureSnapshot `json:"-" gorm:"type:json"`
+
+	// FailedStepIndex is the step a failed run failed at, if it was given
+	// when the run was completed.
+	FailedStepIndex *int `json:"failed_step_index,omitempty" gorm:"column:failed_step_index"`
+
+	// Duration is how long a completed run took, from start to completion,
+	// in milliseconds.
+	Duration *int64 `json:"duration,omitempty"`
+
+	// ReleaseID is the release or milestone the run was executed for.
+	ReleaseID *uuid.UUID `json:"release_id,omitempty" gorm:"type:char(36);index:idx_test_runs_release_id"`
 }
 
 // BeforeCreate hook to generate UUID before creating a new test run
@@ -96,9 +232,16 @@ func (tr *TestRun) Start() error {
 	if tr.StartedAt != nil {
 		return ErrTestRunAlreadyStarted
 	}
+	if tr.Status != StatusPending {
+		return fmt.Errorf("%w: %s runs cannot be started", ErrInvalidTransition, tr.Status)
+	}
 	now := time.Now()
 	tr.StartedAt = &now
 	tr.Status = StatusRunning
+	if tr.MaxDurationMinutes != nil {
+		overdueAfter := now.Add(time.Duration(*tr.MaxDurationMinutes) * time.Minute)
+		tr.OverdueAfter = &overdueAfter
+	}
 	return nil
 }
 
@@ -114,8 +257,231 @@ func (tr *TestRun) Complete(status Status, notes string) error {
 	now := time.Now()
 	tr.CompletedAt = &now
 	tr.Status = status
+	if tr.StartedAt != nil {
+		duration := now.Sub(*tr.StartedAt).Milliseconds() - tr.PausedDuration
+		tr.Duration = &duration
+	}
 	if notes != "" {
 		tr.Notes = notes
 	}
 	return nil
 }
+
+// Pause stops a running run for a while, with an optional reason.
+func (tr *TestRun) Pause(reason string) error {
+	if !tr.Status.CanTransitionTo(StatusPaused) {
+		return transitionError(tr.Status, StatusPaused)
+	}
+	if err := validateStatusReason(reason); err != nil {
+		return err
+	}
+	now := time.Now()
+	tr.Status = StatusPaused
+	tr.StatusReason = reason
+	tr.PausedAt = &now
+	return nil
+}
+
+// Resume carries on with a paused run.
+func (tr *TestRun) Resume() error {
+	if tr.Status != StatusPaused {
+		return fmt.Errorf("%w: %s runs cannot be resumed", ErrInvalidTransition, tr.Status)
+	}
+	tr.resume(StatusRunning)
+	return nil
+}
+
+// Block stops a pending, running or paused run until what blocks it, given
+// as the reason, is resolved.
+func (tr *TestRun) Block(reason string) error {
+	if !tr.Status.CanTransitionTo(StatusBlocked) {
+		return transitionError(tr.Status, StatusBlocked)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return ErrStatusReasonRequired
+	}
+	if err := validateStatusReason(reason); err != nil {
+		return err
+	}
+	// A paused run has been stopped since it was paused
+	if tr.Status == StatusRunning {
+		now := time.Now()
+		tr.PausedAt = &now
+	}
+	tr.Status = StatusBlocked
+	tr.StatusReason = reason
+	return nil
+}
+
+// Unblock returns a blocked run to running if it had started, or to
+// pending if not.
+func (tr *TestRun) Unblock() error {
+	if tr.Status != StatusBlocked {
+		return fmt.Errorf("%w: %s runs cannot be unblocked", ErrInvalidTransition, tr.Status)
+	}
+	if tr.StartedAt == nil {
+		tr.resume(StatusPending)
+	} else {
+		tr.resume(StatusRunning)
+	}
+	return nil
+}
+
+// resume moves a paused or blocked run to status, adding the time it was
+// stopped for to its PausedDuration and OverdueAfter.
+func (tr *TestRun) resume(status Status) {
+	if tr.PausedAt != nil {
+		stopped := time.Since(*tr.PausedAt)
+		tr.PausedDuration += stopped.Milliseconds()
+		if tr.OverdueAfter != nil {
+			overdueAfter := tr.OverdueAfter.Add(stopped)
+			tr.OverdueAfter = &overdueAfter
+		}
+		tr.PausedAt = nil
+	}
+	tr.Status = status
+	tr.StatusReason = ""
+}
+
+// validateStatusReason checks the length of a pause or block reason.
+func validateStatusReason(reason string) error {
+	if utf8.RuneCountInString(reason) > MaxStatusReasonLength {
+		return ErrStatusReasonTooLong
+	}
+	return nil
+}
+
+// ListFilter narrows the runs listed for a procedure. The zero value
+// matches every run.
+type ListFilter struct {
+	// RunIDs, if non-nil, limits the list to those runs. An empty non-nil
+	// slice matches nothing.
+	RunIDs []uuid.UUID
+
+	// ReleaseID, if set, limits the list to runs tagged with the release.
+	ReleaseID *uuid.UUID
+
+	// GroupID, if set, limits the list to runs launched in the run group.
+	GroupID *uuid.UUID
+
+	// Status, if set, limits the list to runs with the status.
+	Status Status
+
+	// ExecutedBy, if set, limits the list to runs executed by the user.
+	ExecutedBy *uuid.UUID
+
+	// Since and Until, if set, limit the list to runs created at or after
+	// Since and before Until.
+	Since *time.Time
+	Until *time.Time
+
+	// HasFailedSteps limits the list to runs with a failed step: one
+	// recorded as failed in the run's step notes, or the step a failed run
+	// was completed as failing at.
+	HasFailedSteps bool
+
+	// Overdue limits the list to runs found still running after their
+	// expected maximum duration.
+	Overdue bool
+
+	// Sort orders the list; it does not narrow it. The zero value lists
+	// newest first.
+	Sort ListSort
+}
+
+// scope adds the filter's conditions to a query.
+func (f ListFilter) scope(db *gorm.DB) *gorm.DB {
+	if f.RunIDs != nil {
+		db = db.Where("id IN ?", f.RunIDs)
+	}
+	if f.ReleaseID != nil {
+		db = db.Where("release_id = ?", *f.ReleaseID)
+	}
+	if f.GroupID != nil {
+		db = db.Where("group_id = ?", *f.GroupID)
+	}
+	if f.Status != "" {
+		db = db.Where("status = ?", f.Status)
+	}
+	if f.ExecutedBy != nil {
+		db = db.Where("executed_by = ?", *f.ExecutedBy)
+	}
+	if f.Since != nil {
+		db = db.Where("created_at >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		db = db.Where("created_at < ?", *f.Until)
+	}
+	if f.HasFailedSteps {
+		db = db.Where("(failed_step_index IS NOT NULL OR EXISTS (SELECT 1 FROM test_run_step_notes WHERE test_run_step_notes.test_run_id = test_runs.id AND test_run_step_notes.status = ?))", StepStatusFailed)
+	}
+	if f.Overdue {
+		db = db.Where("overdue_at IS NOT NULL")
+	}
+	return db
+}
+
+// QueueFilter narrows the runs assigned to a user. The zero value matches
+// the runs still to be done: those that have not completed.
+type QueueFilter struct {
+	// IncludeCompleted also matches runs that have completed.
+	IncludeCompleted bool
+
+	// DueBefore, if set, limits the queue to runs due before it.
+	DueBefore *time.Time
+}
+
+// scope adds the filter's conditions to a query.
+func (f QueueFilter) scope(db *gorm.DB) *gorm.DB {
+	if !f.IncludeCompleted {
+		db = db.Where("status NOT IN ?", []Status{StatusPassed, StatusFailed, StatusSkipped})
+	}
+	if f.DueBefore != nil {
+		db = db.Where("due_at < ?", *f.DueBefore)
+	}
+	return db
+}
+
+// ListSort is the order of a run list: a column, prefixed with - to sort
+// descending.
+type ListSort string
+
+const (
+	SortCreatedAt     ListSort = "created_at"
+	SortCreatedAtDesc ListSort = "-created_at"
+	SortStartedAt     ListSort = "started_at"
+	SortStartedAtDesc ListSort = "-started_at"
+	SortDuration      ListSort = "duration"
+	SortDurationDesc  ListSort = "-duration"
+)
+
+// IsValid checks if the sort is supported. The empty sort is valid.
+func (s ListSort) IsValid() bool {
+	switch s {
+	case "", SortCreatedAt, SortCreatedAtDesc, SortStartedAt, SortStartedAtDesc, SortDuration, SortDurationDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+// order is a GORM scope that orders a query by the sort. Runs not yet
+// started or completed, which have no started_at or duration, come last
+// either way; ties are broken newest first.
+func (s ListSort) order(db *gorm.DB) *gorm.DB {
+	column, desc := strings.TrimPrefix(string(s), "-"), strings.HasPrefix(string(s), "-")
+	switch ListSort(column) {
+	case SortStartedAt, SortDuration:
+		db = db.Order(column + " IS NULL")
+		if desc {
+			db = db.Order(column + " DESC")
+		} else {
+			db = db.Order(column + " ASC")
+		}
+	case SortCreatedAt:
+		if !desc {
+			return db.Order("created_at ASC").Order("id ASC")
+		}
+	}
+	return db.Order("created_at DESC").Order("id DESC")
+}
@@ -1,11 +1,13 @@
 package testrun
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStatus_IsValid(t *testing.T) {
@@ -19,6 +21,8 @@ func TestStatus_IsValid(t *testing.T) {
 		{"passed is valid", StatusPassed, true},
 		{"failed is valid", StatusFailed, true},
 		{"skipped is valid", StatusSkipped, true},
+		{"paused is valid", StatusPaused, true},
+		{"blocked is valid", StatusBlocked, true},
 		{"invalid status", Status("invalid"), false},
 		{"empty status", Status(""), false},
 	}
@@ -41,6 +45,8 @@ func TestStatus_IsFinal(t *testing.T) {
 		{"skipped is final", StatusSkipped, true},
 		{"pending is not final", StatusPending, false},
 		{"running is not final", StatusRunning, false},
+		{"paused is not final", StatusPaused, false},
+		{"blocked is not final", StatusBlocked, false},
 	}
 
 	for _, tt := range tests {
@@ -106,6 +112,148 @@ func TestTestRun_Validate(t *testing.T) {
 	}
 }
 
+func TestStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		want     bool
+	}{
+		{StatusPending, StatusRunning, true},
+		{StatusPending, StatusBlocked, true},
+		{StatusPending, StatusPaused, false},
+		{StatusPending, StatusPassed, false},
+		{StatusRunning, StatusPaused, true},
+		{StatusRunning, StatusBlocked, true},
+		{StatusRunning, StatusFailed, true},
+		{StatusPaused, StatusRunning, true},
+		{StatusPaused, StatusBlocked, true},
+		{StatusPaused, StatusPassed, false},
+		{StatusBlocked, StatusPending, true},
+		{StatusBlocked, StatusRunning, true},
+		{StatusBlocked, StatusPaused, false},
+		{StatusBlocked, StatusFailed, false},
+		{StatusPassed, StatusRunning, false},
+		{StatusSkipped, StatusBlocked, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestTestRun_PauseAndResume(t *testing.T) {
+	t.Run("pause and resume a running run", func(t *testing.T) {
+		started := time.Now().Add(-time.Hour)
+		tr := &TestRun{Status: StatusRunning, StartedAt: &started}
+
+		require.NoError(t, tr.Pause("lunch"))
+		assert.Equal(t, StatusPaused, tr.Status)
+		assert.Equal(t, "lunch", tr.StatusReason)
+		require.NotNil(t, tr.PausedAt)
+
+		paused := time.Now().Add(-10 * time.Minute)
+		tr.PausedAt = &paused
+		require.NoError(t, tr.Resume())
+		assert.Equal(t, StatusRunning, tr.Status)
+		assert.Empty(t, tr.StatusReason)
+		assert.Nil(t, tr.PausedAt)
+		assert.InDelta(t, (10 * time.Minute).Milliseconds(), tr.PausedDuration, 1000)
+	})
+
+	t.Run("paused time pushes back when the run is overdue", func(t *testing.T) {
+		paused := time.Now().Add(-10 * time.Minute)
+		overdueAfter := time.Now().Add(time.Hour)
+		tr := &TestRun{Status: StatusPaused, PausedAt: &paused, OverdueAfter: &overdueAfter}
+
+		require.NoError(t, tr.Resume())
+		require.NotNil(t, tr.OverdueAfter)
+		assert.WithinDuration(t, overdueAfter.Add(10*time.Minute), *tr.OverdueAfter, time.Second)
+	})
+
+	t.Run("paused time is left out of the duration", func(t *testing.T) {
+		started := time.Now().Add(-time.Hour)
+		tr := &TestRun{Status: StatusRunning, StartedAt: &started, PausedDuration: (20 * time.Minute).Milliseconds()}
+
+		require.NoError(t, tr.Complete(StatusPassed, ""))
+		require.NotNil(t, tr.Duration)
+		assert.InDelta(t, (40 * time.Minute).Milliseconds(), *tr.Duration, 1000)
+	})
+
+	t.Run("only running runs can be paused", func(t *testing.T) {
+		tr := &TestRun{Status: StatusPending}
+		assert.ErrorIs(t, tr.Pause(""), ErrInvalidTransition)
+	})
+
+	t.Run("only paused runs can be resumed", func(t *testing.T) {
+		tr := &TestRun{Status: StatusBlocked}
+		assert.ErrorIs(t, tr.Resume(), ErrInvalidTransition)
+	})
+
+	t.Run("a paused run cannot be completed", func(t *testing.T) {
+		tr := &TestRun{Status: StatusPaused}
+		assert.ErrorIs(t, tr.Complete(StatusPassed, ""), ErrTestRunNotRunning)
+	})
+
+	t.Run("reason too long", func(t *testing.T) {
+		tr := &TestRun{Status: StatusRunning}
+		assert.ErrorIs(t, tr.Pause(strings.Repeat("a", MaxStatusReasonLength+1)), ErrStatusReasonTooLong)
+	})
+}
+
+func TestTestRun_BlockAndUnblock(t *testing.T) {
+	t.Run("a blocked pending run goes back to pending", func(t *testing.T) {
+		tr := &TestRun{Status: StatusPending}
+
+		require.NoError(t, tr.Block("staging is down"))
+		assert.Equal(t, StatusBlocked, tr.Status)
+		assert.Equal(t, "staging is down", tr.StatusReason)
+		assert.Nil(t, tr.PausedAt)
+		assert.ErrorIs(t, tr.Start(), ErrInvalidTransition)
+
+		require.NoError(t, tr.Unblock())
+		assert.Equal(t, StatusPending, tr.Status)
+		assert.Empty(t, tr.StatusReason)
+		assert.Zero(t, tr.PausedDuration)
+	})
+
+	t.Run("a blocked started run goes back to running", func(t *testing.T) {
+		started := time.Now().Add(-time.Hour)
+		tr := &TestRun{Status: StatusRunning, StartedAt: &started}
+
+		require.NoError(t, tr.Block("staging is down"))
+		require.NotNil(t, tr.PausedAt)
+
+		require.NoError(t, tr.Unblock())
+		assert.Equal(t, StatusRunning, tr.Status)
+		assert.Nil(t, tr.PausedAt)
+	})
+
+	t.Run("blocking a paused run keeps when it was paused", func(t *testing.T) {
+		started := time.Now().Add(-time.Hour)
+		paused := time.Now().Add(-30 * time.Minute)
+		tr := &TestRun{Status: StatusPaused, StartedAt: &started, PausedAt: &paused}
+
+		require.NoError(t, tr.Block("VPN outage"))
+		assert.Equal(t, paused, *tr.PausedAt)
+	})
+
+	t.Run("a reason is required", func(t *testing.T) {
+		tr := &TestRun{Status: StatusPending}
+		assert.ErrorIs(t, tr.Block("  "), ErrStatusReasonRequired)
+	})
+
+	t.Run("completed runs cannot be blocked", func(t *testing.T) {
+		tr := &TestRun{Status: StatusPassed}
+		assert.ErrorIs(t, tr.Block("outage"), ErrInvalidTransition)
+	})
+
+	t.Run("only blocked runs can be unblocked", func(t *testing.T) {
+		tr := &TestRun{Status: StatusRunning}
+		assert.ErrorIs(t, tr.Unblock(), ErrInvalidTransition)
+	})
+}
+
 func TestTestRun_Start(t *testing.T) {
 	t.Run("successfully start test run", func(t *testing.T) {
 		testProcedureID := uuid.New()
@@ -121,6 +269,16 @@ func TestTestRun_Start(t *testing.T) {
 		assert.NotNil(t, tr.StartedAt)
 		assert.Equal(t, StatusRunning, tr.Status)
 		assert.WithinDuration(t, time.Now(), *tr.StartedAt, time.Second)
+		assert.Nil(t, tr.OverdueAfter)
+	})
+
+	t.Run("runs with a maximum duration become overdue after it", func(t *testing.T) {
+		maxDuration := 30
+		tr := &TestRun{Status: StatusPending, MaxDurationMinutes: &maxDuration}
+
+		require.NoError(t, tr.Start())
+		require.NotNil(t, tr.OverdueAfter)
+		assert.Equal(t, tr.StartedAt.Add(30*time.Minute), *tr.OverdueAfter)
 	})
 
 	t.Run("cannot start already started test run", func(t *testing.T) {
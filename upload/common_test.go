@@ -0,0 +1,51 @@
+package upload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestStore creates a test database and upload session store for
+// testing.
+func setupTestStore(t *testing.T) Store {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Session{}, &Part{})
+
+	return NewMySQLStore(db, logger.NewTestLogger())
+}
+
+// setupTestManager creates a manager with local blob storage that splits
+// uploads into parts of partSize bytes.
+func setupTestManager(t *testing.T, partSize int64) (*Manager, Store, storage.BlobStorage) {
+	store := setupTestStore(t)
+	blobStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	manager := NewManager(store, blobStorage, Config{
+		PartSize:   partSize,
+		MaxSize:    1024,
+		SessionTTL: time.Hour,
+	}, logger.NewTestLogger())
+	return manager, store, blobStorage
+}
+
+// createTestSession creates a run asset session with default values.
+func createTestSession(fileSize, partSize int64) *Session {
+	runID := uuid.New()
+	return &Session{
+		UserID:    uuid.New(),
+		Target:    TargetRunAsset,
+		FileName:  "recording.mp4",
+		FileSize:  fileSize,
+		PartSize:  partSize,
+		TestRunID: &runID,
+		AssetType: "video",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
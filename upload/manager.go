@@ -0,0 +1,284 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+)
+
+// Config holds settings for resumable uploads.
+type Config struct {
+	// PartSize is the size of every part but the last.
+	PartSize int64
+	// MaxSize is the largest file that can be uploaded.
+	MaxSize int64
+	// SessionTTL is how long an upload may take before it is abandoned.
+	SessionTTL time.Duration
+}
+
+// Manager stores the parts of upload sessions in blob storage and assembles
+// them once complete. Sessions that are not completed before they expire are
+// removed along with their parts by the cleanup goroutine.
+type Manager struct {
+	store   Store
+	storage storage.BlobStorage
+	config  Config
+	logger  logger.Logger
+	stopCh  chan struct{}
+}
+
+// NewManager creates a new upload manager.
+func NewManager(store Store, blobStorage storage.BlobStorage, cfg Config, log logger.Logger) *Manager {
+	return &Manager{
+		store:   store,
+		storage: blobStorage,
+		config:  cfg,
+		logger:  log,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// PartPath returns the blob storage path of part n of an upload.
+func PartPath(sessionID uuid.UUID, n int) string {
+	return fmt.Sprintf("uploads/%s/%d", sessionID, n)
+}
+
+// Create starts an upload session. The part size and expiry are set from
+// the manager's configuration.
+func (m *Manager) Create(ctx context.Context, session *Session) error {
+	if session.FileSize > m.config.MaxSize {
+		return fmt.Errorf("%w: file must be at most %d bytes", ErrInvalidSize, m.config.MaxSize)
+	}
+	session.PartSize = m.config.PartSize
+	session.Status = StatusActive
+	session.ExpiresAt = time.Now().Add(m.config.SessionTTL)
+	if err := m.store.Create(ctx, session); err != nil {
+		return err
+	}
+	session.ReceivedParts = []int{}
+	return nil
+}
+
+// Get retrieves an upload session along with the numbers of its received
+// parts. Expired sessions are reported as ErrSessionExpired.
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (*Session, error) {
+	session, err := m.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.IsExpired(time.Now()) {
+		return nil, ErrSessionExpired
+	}
+
+	parts, err := m.store.ListParts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	session.ReceivedParts = make([]int, 0, len(parts))
+	for _, p := range parts {
+		session.ReceivedParts = append(session.ReceivedParts, p.Number)
+	}
+	return session, nil
+}
+
+// PutPart stores part n of an upload read from r. Parts may be sent in any
+// order and sending a part again replaces it, so a failed part can simply be
+// retried. The part must have exactly the expected size.
+func (m *Manager) PutPart(ctx context.Context, session *Session, n int, r io.Reader) error {
+	if session.Status != StatusActive {
+		return ErrSessionBusy
+	}
+	size, err := session.ExpectedPartSize(n)
+	if err != nil {
+		return err
+	}
+
+	// Read one byte past the expected size to detect oversized parts
+	counter := &countingReader{r: io.LimitReader(r, size+1)}
+	path := PartPath(session.ID, n)
+	if err := m.storage.Upload(ctx, path, counter); err != nil {
+		m.storage.Delete(ctx, path)
+		return fmt.Errorf("failed to store part: %w", err)
+	}
+	if counter.n != size {
+		m.storage.Delete(ctx, path)
+		return fmt.Errorf("%w: part %d must be %d bytes, got %d", ErrInvalidPart, n, size, counter.n)
+	}
+
+	return m.store.SavePart(ctx, &Part{
+		SessionID: session.ID,
+		Number:    n,
+		Size:      size,
+	})
+}
+
+// Complete assembles an upload and passes the file to fn. The session is
+// claimed first so that concurrent completions of the same upload fail with
+// ErrSessionBusy. If fn fails the session is released and can be completed
+// again; otherwise the session and its parts are removed.
+func (m *Manager) Complete(ctx context.Context, session *Session, fn func(io.Reader) error) error {
+	if err := m.store.SetStatus(ctx, session.ID, StatusActive, StatusCompleting); err != nil {
+		return err
+	}
+
+	err := m.assemble(ctx, session, fn)
+	if err != nil {
+		if releaseErr := m.store.SetStatus(ctx, session.ID, StatusCompleting, StatusActive); releaseErr != nil {
+			m.logger.Warn(ctx, "failed to release upload session", map[string]interface{}{
+				"error":     releaseErr.Error(),
+				"upload_id": session.ID.String(),
+			})
+		}
+		return err
+	}
+
+	m.remove(ctx, session)
+	return nil
+}
+
+func (m *Manager) assemble(ctx context.Context, session *Session, fn func(io.Reader) error) error {
+	parts, err := m.store.ListParts(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+	if len(parts) != session.TotalParts {
+		return fmt.Errorf("%w: received %d of %d parts", ErrIncomplete, len(parts), session.TotalParts)
+	}
+
+	reader := &partsReader{ctx: ctx, storage: m.storage, sessionID: session.ID, total: session.TotalParts}
+	defer reader.Close()
+	return fn(reader)
+}
+
+// Abort cancels an upload and removes its parts.
+func (m *Manager) Abort(ctx context.Context, session *Session) error {
+	if err := m.store.SetStatus(ctx, session.ID, StatusActive, StatusCompleting); err != nil {
+		return err
+	}
+	m.remove(ctx, session)
+	return nil
+}
+
+// Cleanup removes sessions that have expired and their parts. It returns
+// the number of sessions removed.
+func (m *Manager) Cleanup(ctx context.Context) int {
+	sessions, err := m.store.ListExpired(ctx, time.Now())
+	if err != nil {
+		return 0
+	}
+	for _, s := range sessions {
+		s.TotalParts = s.partCount()
+		m.remove(ctx, s)
+	}
+	return len(sessions)
+}
+
+// remove deletes the parts of a session from blob storage, then the session.
+func (m *Manager) remove(ctx context.Context, session *Session) {
+	for n := 1; n <= session.TotalParts; n++ {
+		path := PartPath(session.ID, n)
+		// Parts that were never sent are not in storage
+		if err := m.storage.Delete(ctx, path); err != nil && !errors.Is(err, storage.ErrFileNotFound) {
+			m.logger.Warn(ctx, "failed to delete upload part from storage", map[string]interface{}{
+				"error": err.Error(),
+				"path":  path,
+			})
+		}
+	}
+	if err := m.store.Delete(ctx, session.ID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		m.logger.Warn(ctx, "failed to delete upload session", map[string]interface{}{
+			"error":     err.Error(),
+			"upload_id": session.ID.String(),
+		})
+	}
+}
+
+// StartCleanup starts a background goroutine that periodically removes
+// abandoned uploads.
+func (m *Manager) StartCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				removed := m.Cleanup(context.Background())
+				if removed > 0 {
+					m.logger.Info(context.Background(), "cleaned up abandoned uploads", map[string]interface{}{
+						"removed_count": removed,
+					})
+				}
+			case <-m.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// StopCleanup stops the cleanup goroutine.
+func (m *Manager) StopCleanup() {
+	close(m.stopCh)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// partsReader reads the parts of an upload in order, downloading each part
+// only once the previous one has been read.
+type partsReader struct {
+	ctx       context.Context
+	storage   storage.BlobStorage
+	sessionID uuid.UUID
+	total     int
+	next      int
+	current   io.ReadCloser
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.current == nil {
+			if p.next >= p.total {
+				return 0, io.EOF
+			}
+			p.next++
+			rc, err := p.storage.Download(p.ctx, PartPath(p.sessionID, p.next))
+			if err != nil {
+				return 0, fmt.Errorf("failed to read part %d: %w", p.next, err)
+			}
+			p.current = rc
+		}
+
+		n, err := p.current.Read(b)
+		if err == io.EOF {
+			p.current.Close()
+			p.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (p *partsReader) Close() error {
+	if p.current != nil {
+		return p.current.Close()
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Create(t *testing.T) {
+	manager, _, _ := setupTestManager(t, 10)
+	ctx := context.Background()
+
+	t.Run("sets part size and expiry", func(t *testing.T) {
+		session := createTestSession(25, 0)
+		require.NoError(t, manager.Create(ctx, session))
+		assert.Equal(t, int64(10), session.PartSize)
+		assert.Equal(t, 3, session.TotalParts)
+		assert.Empty(t, session.ReceivedParts)
+		assert.True(t, session.ExpiresAt.After(time.Now()))
+	})
+
+	t.Run("file larger than the maximum is rejected", func(t *testing.T) {
+		session := createTestSession(2048, 0)
+		assert.ErrorIs(t, manager.Create(ctx, session), ErrInvalidSize)
+	})
+}
+
+func TestManager_PutPart(t *testing.T) {
+	manager, _, blobStorage := setupTestManager(t, 10)
+	ctx := context.Background()
+
+	session := createTestSession(25, 0)
+	require.NoError(t, manager.Create(ctx, session))
+
+	t.Run("parts are recorded in any order", func(t *testing.T) {
+		require.NoError(t, manager.PutPart(ctx, session, 3, strings.NewReader("kkkkk")))
+		require.NoError(t, manager.PutPart(ctx, session, 1, strings.NewReader("aaaaaaaaaa")))
+
+		retrieved, err := manager.Get(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 3}, retrieved.ReceivedParts)
+	})
+
+	t.Run("short part is rejected and removed", func(t *testing.T) {
+		err := manager.PutPart(ctx, session, 2, strings.NewReader("short"))
+		assert.ErrorIs(t, err, ErrInvalidPart)
+
+		exists, err := blobStorage.Exists(ctx, PartPath(session.ID, 2))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("oversized part is rejected", func(t *testing.T) {
+		err := manager.PutPart(ctx, session, 3, strings.NewReader("kkkkkk"))
+		assert.ErrorIs(t, err, ErrInvalidPart)
+	})
+
+	t.Run("out of range part is rejected", func(t *testing.T) {
+		err := manager.PutPart(ctx, session, 4, strings.NewReader("kkkkk"))
+		assert.ErrorIs(t, err, ErrInvalidPart)
+	})
+}
+
+func TestManager_Complete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("assembles parts in order and removes the session", func(t *testing.T) {
+		manager, store, blobStorage := setupTestManager(t, 10)
+		session := createTestSession(25, 0)
+		require.NoError(t, manager.Create(ctx, session))
+		require.NoError(t, manager.PutPart(ctx, session, 2, strings.NewReader("bbbbbbbbbb")))
+		require.NoError(t, manager.PutPart(ctx, session, 3, strings.NewReader("ccccc")))
+		require.NoError(t, manager.PutPart(ctx, session, 1, strings.NewReader("aaaaaaaaaa")))
+
+		var assembled bytes.Buffer
+		err := manager.Complete(ctx, session, func(r io.Reader) error {
+			_, err := io.Copy(&assembled, r)
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "aaaaaaaaaabbbbbbbbbbccccc", assembled.String())
+
+		_, err = store.GetByID(ctx, session.ID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+		exists, err := blobStorage.Exists(ctx, PartPath(session.ID, 1))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("missing parts are reported and the session kept", func(t *testing.T) {
+		manager, _, _ := setupTestManager(t, 10)
+		session := createTestSession(25, 0)
+		require.NoError(t, manager.Create(ctx, session))
+		require.NoError(t, manager.PutPart(ctx, session, 1, strings.NewReader("aaaaaaaaaa")))
+
+		err := manager.Complete(ctx, session, func(r io.Reader) error { return nil })
+		assert.ErrorIs(t, err, ErrIncomplete)
+
+		retrieved, err := manager.Get(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusActive, retrieved.Status)
+	})
+
+	t.Run("failed completion can be retried", func(t *testing.T) {
+		manager, _, _ := setupTestManager(t, 10)
+		session := createTestSession(5, 0)
+		require.NoError(t, manager.Create(ctx, session))
+		require.NoError(t, manager.PutPart(ctx, session, 1, strings.NewReader("aaaaa")))
+
+		errStore := errors.New("store failed")
+		err := manager.Complete(ctx, session, func(r io.Reader) error { return errStore })
+		assert.ErrorIs(t, err, errStore)
+
+		err = manager.Complete(ctx, session, func(r io.Reader) error { return nil })
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent completion is rejected", func(t *testing.T) {
+		manager, store, _ := setupTestManager(t, 10)
+		session := createTestSession(5, 0)
+		require.NoError(t, manager.Create(ctx, session))
+		require.NoError(t, store.SetStatus(ctx, session.ID, StatusActive, StatusCompleting))
+
+		err := manager.Complete(ctx, session, func(r io.Reader) error { return nil })
+		assert.ErrorIs(t, err, ErrSessionBusy)
+	})
+}
+
+func TestManager_Abort(t *testing.T) {
+	manager, store, blobStorage := setupTestManager(t, 10)
+	ctx := context.Background()
+
+	session := createTestSession(25, 0)
+	require.NoError(t, manager.Create(ctx, session))
+	require.NoError(t, manager.PutPart(ctx, session, 1, strings.NewReader("aaaaaaaaaa")))
+
+	require.NoError(t, manager.Abort(ctx, session))
+
+	_, err := store.GetByID(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	exists, err := blobStorage.Exists(ctx, PartPath(session.ID, 1))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestManager_Cleanup(t *testing.T) {
+	manager, store, blobStorage := setupTestManager(t, 10)
+	ctx := context.Background()
+
+	abandoned := createTestSession(25, 10)
+	abandoned.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, store.Create(ctx, abandoned))
+	require.NoError(t, manager.PutPart(ctx, abandoned, 2, strings.NewReader("bbbbbbbbbb")))
+
+	active := createTestSession(25, 0)
+	require.NoError(t, manager.Create(ctx, active))
+
+	assert.Equal(t, 1, manager.Cleanup(ctx))
+
+	_, err := manager.Get(ctx, abandoned.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	exists, err := blobStorage.Exists(ctx, PartPath(abandoned.ID, 2))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = manager.Get(ctx, active.ID)
+	assert.NoError(t, err)
+}
+
+func TestManager_Get_Expired(t *testing.T) {
+	manager, store, _ := setupTestManager(t, 10)
+	ctx := context.Background()
+
+	session := createTestSession(25, 10)
+	session.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, store.Create(ctx, session))
+
+	_, err := manager.Get(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrSessionExpired)
+}
@@ -0,0 +1,180 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed upload session store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new upload session.
+func (s *MySQLStore) Create(ctx context.Context, session *Session) error {
+	if err := session.Validate(); err != nil {
+		return err
+	}
+	if session.Status == "" {
+		session.Status = StatusActive
+	}
+
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		s.logger.Error(ctx, "failed to create upload session", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": session.UserID.String(),
+		})
+		return err
+	}
+	session.TotalParts = session.partCount()
+
+	s.logger.Info(ctx, "upload session created", map[string]interface{}{
+		"upload_id": session.ID.String(),
+		"target":    session.Target,
+		"file_size": session.FileSize,
+	})
+
+	return nil
+}
+
+// GetByID retrieves an upload session by its ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*Session, error) {
+	var session Session
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&session).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		s.logger.Error(ctx, "failed to get upload session by ID", map[string]interface{}{
+			"error":     err.Error(),
+			"upload_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// SavePart records a received part, replacing an earlier copy.
+func (s *MySQLStore) SavePart(ctx context.Context, part *Part) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "session_id"}, {Name: "number"}},
+			DoUpdates: clause.AssignmentColumns([]string{"size", "created_at"}),
+		}).
+		Create(part).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to save upload part", map[string]interface{}{
+			"error":     err.Error(),
+			"upload_id": part.SessionID.String(),
+			"part":      part.Number,
+		})
+		return err
+	}
+
+	return nil
+}
+
+// ListParts retrieves the received parts of a session by part number.
+func (s *MySQLStore) ListParts(ctx context.Context, sessionID uuid.UUID) ([]*Part, error) {
+	var parts []*Part
+	err := s.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("number ASC").
+		Find(&parts).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list upload parts", map[string]interface{}{
+			"error":     err.Error(),
+			"upload_id": sessionID.String(),
+		})
+		return nil, err
+	}
+
+	return parts, nil
+}
+
+// SetStatus moves a session from one status to another.
+func (s *MySQLStore) SetStatus(ctx context.Context, id uuid.UUID, from, to Status) error {
+	result := s.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to update upload session status", map[string]interface{}{
+			"error":     result.Error.Error(),
+			"upload_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrSessionBusy
+	}
+
+	return nil
+}
+
+// ListExpired retrieves sessions that expired before the given time.
+func (s *MySQLStore) ListExpired(ctx context.Context, before time.Time) ([]*Session, error) {
+	var sessions []*Session
+	err := s.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Find(&sessions).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list expired upload sessions", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Delete deletes a session and its parts.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	var rowsAffected int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", id).Delete(&Part{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&Session{})
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to delete upload session", map[string]interface{}{
+			"error":     err.Error(),
+			"upload_id": id.String(),
+		})
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package upload
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLStore_CreateAndGet(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("create session", func(t *testing.T) {
+		session := createTestSession(25, 10)
+		require.NoError(t, store.Create(ctx, session))
+		assert.NotEqual(t, uuid.Nil, session.ID)
+		assert.Equal(t, StatusActive, session.Status)
+		assert.Equal(t, 3, session.TotalParts)
+
+		retrieved, err := store.GetByID(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "recording.mp4", retrieved.FileName)
+		assert.Equal(t, int64(25), retrieved.FileSize)
+		assert.Equal(t, 3, retrieved.TotalParts)
+		assert.Equal(t, session.TestRunID, retrieved.TestRunID)
+	})
+
+	t.Run("invalid session returns error", func(t *testing.T) {
+		session := createTestSession(0, 10)
+		assert.ErrorIs(t, store.Create(ctx, session), ErrInvalidSize)
+	})
+
+	t.Run("missing session returns error", func(t *testing.T) {
+		_, err := store.GetByID(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func TestMySQLStore_Parts(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	session := createTestSession(25, 10)
+	require.NoError(t, store.Create(ctx, session))
+
+	require.NoError(t, store.SavePart(ctx, &Part{SessionID: session.ID, Number: 3, Size: 5}))
+	require.NoError(t, store.SavePart(ctx, &Part{SessionID: session.ID, Number: 1, Size: 10}))
+
+	t.Run("parts are listed by number", func(t *testing.T) {
+		parts, err := store.ListParts(ctx, session.ID)
+		require.NoError(t, err)
+		require.Len(t, parts, 2)
+		assert.Equal(t, 1, parts[0].Number)
+		assert.Equal(t, 3, parts[1].Number)
+	})
+
+	t.Run("saving a part again replaces it", func(t *testing.T) {
+		require.NoError(t, store.SavePart(ctx, &Part{SessionID: session.ID, Number: 1, Size: 10}))
+		parts, err := store.ListParts(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Len(t, parts, 2)
+	})
+}
+
+func TestMySQLStore_SetStatus(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	session := createTestSession(25, 10)
+	require.NoError(t, store.Create(ctx, session))
+
+	require.NoError(t, store.SetStatus(ctx, session.ID, StatusActive, StatusCompleting))
+	assert.ErrorIs(t, store.SetStatus(ctx, session.ID, StatusActive, StatusCompleting), ErrSessionBusy)
+
+	retrieved, err := store.GetByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleting, retrieved.Status)
+}
+
+func TestMySQLStore_ListExpired(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	expired := createTestSession(25, 10)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, store.Create(ctx, expired))
+	require.NoError(t, store.Create(ctx, createTestSession(25, 10)))
+
+	sessions, err := store.ListExpired(ctx, time.Now())
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, expired.ID, sessions[0].ID)
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	store := setupTestStore(t)
+	ctx := context.Background()
+
+	session := createTestSession(25, 10)
+	require.NoError(t, store.Create(ctx, session))
+	require.NoError(t, store.SavePart(ctx, &Part{SessionID: session.ID, Number: 1, Size: 10}))
+
+	require.NoError(t, store.Delete(ctx, session.ID))
+
+	_, err := store.GetByID(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	parts, err := store.ListParts(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Empty(t, parts)
+
+	assert.ErrorIs(t, store.Delete(ctx, session.ID), ErrSessionNotFound)
+}
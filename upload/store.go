@@ -0,0 +1,33 @@
+package upload
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for upload session persistence operations.
+type Store interface {
+	// Create creates a new upload session.
+	Create(ctx context.Context, session *Session) error
+
+	// GetByID retrieves an upload session by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*Session, error)
+
+	// SavePart records a received part, replacing an earlier copy.
+	SavePart(ctx context.Context, part *Part) error
+
+	// ListParts retrieves the received parts of a session by part number.
+	ListParts(ctx context.Context, sessionID uuid.UUID) ([]*Part, error)
+
+	// SetStatus moves a session from one status to another. It returns
+	// ErrSessionBusy if the session is not in the from status.
+	SetStatus(ctx context.Context, id uuid.UUID, from, to Status) error
+
+	// ListExpired retrieves sessions that expired before the given time.
+	ListExpired(ctx context.Context, before time.Time) ([]*Session, error)
+
+	// Delete deletes a session and its parts.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,174 @@
+// Package upload implements resumable uploads: a file is sent as numbered
+// parts that can be retried independently, and is assembled in blob storage
+// once every part has arrived.
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSessionNotFound is returned when an upload session is not found.
+	ErrSessionNotFound = errors.New("upload session not found")
+
+	// ErrSessionExpired is returned when an upload session has expired.
+	ErrSessionExpired = errors.New("upload session expired")
+
+	// ErrSessionBusy is returned when an upload session is already being
+	// completed.
+	ErrSessionBusy = errors.New("upload session is being completed")
+
+	// ErrInvalidTarget is returned when the target of an upload is unknown.
+	ErrInvalidTarget = errors.New("invalid upload target")
+
+	// ErrInvalidSize is returned when the size of an upload is out of range.
+	ErrInvalidSize = errors.New("invalid upload size")
+
+	// ErrInvalidFileName is returned when file_name is empty.
+	ErrInvalidFileName = errors.New("file_name is required")
+
+	// ErrInvalidPart is returned when a part number is out of range or a
+	// part does not have the expected size.
+	ErrInvalidPart = errors.New("invalid upload part")
+
+	// ErrIncomplete is returned when completing an upload with missing parts.
+	ErrIncomplete = errors.New("upload is missing parts")
+)
+
+// Target is what the uploaded file becomes once completed.
+type Target string
+
+const (
+	// TargetRunAsset uploads an asset of a test run.
+	TargetRunAsset Target = "run_asset"
+	// TargetStepImage uploads an image for a test procedure step.
+	TargetStepImage Target = "step_image"
+)
+
+// IsValid checks if the target is valid.
+func (t Target) IsValid() bool {
+	switch t {
+	case TargetRunAsset, TargetStepImage:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status is the state of an upload session.
+type Status string
+
+const (
+	// StatusActive sessions accept parts.
+	StatusActive Status = "active"
+	// StatusCompleting sessions are being assembled.
+	StatusCompleting Status = "completing"
+)
+
+// Session is an upload in progress. Metadata fields describe the file the
+// upload becomes and are interpreted by the handler of its target.
+type Session struct {
+	ID       uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
+	UserID   uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index:idx_upload_sessions_user_id"`
+	Target   Target    `json:"target" gorm:"type:varchar(20);not null"`
+	Status   Status    `json:"status" gorm:"type:varchar(20);not null"`
+	FileName string    `json:"file_name" gorm:"type:varchar(255);not null"`
+	MimeType string    `json:"mime_type,omitempty" gorm:"type:varchar(128)"`
+	FileSize int64     `json:"file_size" gorm:"not null"`
+	PartSize int64     `json:"part_size" gorm:"not null"`
+
+	// TestRunID, AssetType, Description, StepIndex and StepNoteID describe
+	// run assets.
+	TestRunID   *uuid.UUID `json:"test_run_id,omitempty" gorm:"type:char(36)"`
+	AssetType   string     `json:"asset_type,omitempty" gorm:"type:varchar(20)"`
+	Description string     `json:"description,omitempty" gorm:"type:text"`
+	StepIndex   *int       `json:"step_index,omitempty"`
+	StepNoteID  *uuid.UUID `json:"step_note_id,omitempty" gorm:"type:char(36)"`
+	// TestProcedureID is the procedure a step image is uploaded for.
+	TestProcedureID *uuid.UUID `json:"test_procedure_id,omitempty" gorm:"type:char(36)"`
+
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index:idx_upload_sessions_expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// TotalParts and ReceivedParts are derived and not stored.
+	TotalParts    int   `json:"total_parts" gorm:"-"`
+	ReceivedParts []int `json:"received_parts" gorm:"-"`
+}
+
+// BeforeCreate hook to generate UUID before creating a new session.
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for GORM.
+func (s *Session) TableName() string {
+	return "upload_sessions"
+}
+
+// AfterFind sets the derived part count of a loaded session.
+func (s *Session) AfterFind(tx *gorm.DB) error {
+	s.TotalParts = s.partCount()
+	return nil
+}
+
+// Validate checks if the session has valid required fields.
+func (s *Session) Validate() error {
+	if !s.Target.IsValid() {
+		return ErrInvalidTarget
+	}
+	if s.FileName == "" {
+		return ErrInvalidFileName
+	}
+	if s.FileSize <= 0 || s.PartSize <= 0 {
+		return ErrInvalidSize
+	}
+	return nil
+}
+
+// partCount returns the number of parts the file is split into.
+func (s *Session) partCount() int {
+	if s.PartSize <= 0 {
+		return 0
+	}
+	return int((s.FileSize + s.PartSize - 1) / s.PartSize)
+}
+
+// ExpectedPartSize returns the size part number n must have. Parts are
+// numbered from 1 and all but the last are PartSize bytes.
+func (s *Session) ExpectedPartSize(n int) (int64, error) {
+	total := s.partCount()
+	if n < 1 || n > total {
+		return 0, fmt.Errorf("%w: part number must be between 1 and %d", ErrInvalidPart, total)
+	}
+	if n < total {
+		return s.PartSize, nil
+	}
+	return s.FileSize - int64(total-1)*s.PartSize, nil
+}
+
+// IsExpired reports whether the session has expired at now.
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// Part is a received part of an upload.
+type Part struct {
+	SessionID uuid.UUID `json:"-" gorm:"type:char(36);primaryKey"`
+	Number    int       `json:"number" gorm:"primaryKey;autoIncrement:false"`
+	Size      int64     `json:"size" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (p *Part) TableName() string {
+	return "upload_parts"
+}
@@ -0,0 +1,67 @@
+package upload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_ExpectedPartSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileSize int64
+		part     int
+		want     int64
+		wantErr  bool
+	}{
+		{name: "first of several parts", fileSize: 25, part: 1, want: 10},
+		{name: "short last part", fileSize: 25, part: 3, want: 5},
+		{name: "full last part", fileSize: 20, part: 2, want: 10},
+		{name: "single part", fileSize: 4, part: 1, want: 4},
+		{name: "part zero", fileSize: 25, part: 0, wantErr: true},
+		{name: "part past the end", fileSize: 25, part: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := createTestSession(tt.fileSize, 10)
+			got, err := s.ExpectedPartSize(tt.part)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidPart)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSession_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Session)
+		wantErr error
+	}{
+		{name: "valid session", modify: func(s *Session) {}},
+		{name: "unknown target", modify: func(s *Session) { s.Target = "avatar" }, wantErr: ErrInvalidTarget},
+		{name: "empty file name", modify: func(s *Session) { s.FileName = "" }, wantErr: ErrInvalidFileName},
+		{name: "empty file", modify: func(s *Session) { s.FileSize = 0 }, wantErr: ErrInvalidSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := createTestSession(25, 10)
+			tt.modify(s)
+			assert.ErrorIs(t, s.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestSession_IsExpired(t *testing.T) {
+	now := time.Now()
+	s := &Session{ExpiresAt: now}
+	assert.True(t, s.IsExpired(now))
+	assert.False(t, s.IsExpired(now.Add(-time.Second)))
+}
@@ -29,6 +29,9 @@ func (s *MySQLStore) Create(ctx context.Context, user *User) error {
 	if err := user.Validate(); err != nil {
 		return err
 	}
+	if user.Role == "" {
+		user.Role = RoleUser
+	}
 
 	if err := s.db.WithContext(ctx).Create(user).Error; err != nil {
 		// Check for duplicate key error (MySQL and SQLite)
@@ -200,3 +203,143 @@ func (s *MySQLStore) Search(ctx context.Context, query string, limit, offset int
 
 	return users, nil
 }
+
+// ListByUsernames retrieves the active users with any of the given usernames.
+func (s *MySQLStore) ListByUsernames(ctx context.Context, usernames []string) ([]*User, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	var users []*User
+	err := s.db.WithContext(ctx).
+		Where("is_active = ? AND username IN ?", true, usernames).
+		Find(&users).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list users by username", map[string]interface{}{
+			"error":     err.Error(),
+			"usernames": usernames,
+		})
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetAnyByID retrieves a user by their ID, whether active or not.
+func (s *MySQLStore) GetAnyByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	var user User
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&user).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.logger.Error(ctx, "failed to get user by ID", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// listAllScope filters the users ListAll lists for query.
+func listAllScope(query string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query == "" {
+			return db
+		}
+		pattern := "%" + query + "%"
+		return db.Where("username LIKE ? OR email LIKE ?", pattern, pattern)
+	}
+}
+
+// ListAll retrieves a paginated list of users, active or not, newest first.
+func (s *MySQLStore) ListAll(ctx context.Context, query string, limit, offset int) ([]*User, error) {
+	var users []*User
+	err := s.db.WithContext(ctx).
+		Scopes(listAllScope(query)).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list all users", map[string]interface{}{
+			"error":  err.Error(),
+			"query":  query,
+			"limit":  limit,
+			"offset": offset,
+		})
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// CountAll returns the total count of users ListAll lists for query.
+func (s *MySQLStore) CountAll(ctx context.Context, query string) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&User{}).
+		Scopes(listAllScope(query)).
+		Count(&count).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to count all users", map[string]interface{}{
+			"error": err.Error(),
+			"query": query,
+		})
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// Reactivate sets a deactivated user's is_active back to true.
+func (s *MySQLStore) Reactivate(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Model(&User{}).
+		Where("id = ? AND is_active = ?", id, false).
+		Update("is_active", true)
+
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to reactivate user", map[string]interface{}{
+			"error":   result.Error.Error(),
+			"user_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	s.logger.Info(ctx, "user reactivated", map[string]interface{}{
+		"user_id": id.String(),
+	})
+
+	return nil
+}
+
+// CountAdmins returns the count of active admins.
+func (s *MySQLStore) CountAdmins(ctx context.Context) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&User{}).
+		Where("role = ? AND is_active = ?", RoleAdmin, true).
+		Count(&count).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to count admins", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -19,6 +19,7 @@ func TestMySQLStore_Create(t *testing.T) {
 		require.NoError(t, err)
 		assert.NotZero(t, user.ID)
 		assert.NotZero(t, user.CreatedAt)
+		assert.Equal(t, RoleUser, user.Role)
 	})
 
 	t.Run("duplicate email returns error", func(t *testing.T) {
@@ -220,6 +221,34 @@ func TestMySQLStore_Search(t *testing.T) {
 	})
 }
 
+func TestMySQLStore_ListByUsernames(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	alice := createTestUser("alice@example.com", "alice", "password123")
+	require.NoError(t, store.Create(ctx, alice))
+	require.NoError(t, store.Create(ctx, createTestUser("bob@example.com", "bob", "password123")))
+	require.NoError(t, store.Create(ctx, createTestUser("alice2@example.com", "alice", "password123")))
+	inactive := createTestUser("carol@example.com", "carol", "password123")
+	require.NoError(t, store.Create(ctx, inactive))
+	require.NoError(t, store.Delete(ctx, inactive.ID))
+
+	t.Run("matches exact usernames", func(t *testing.T) {
+		users, err := store.ListByUsernames(ctx, []string{"alice", "ali", "carol"})
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		for _, u := range users {
+			assert.Equal(t, "alice", u.Username)
+		}
+	})
+
+	t.Run("no usernames", func(t *testing.T) {
+		users, err := store.ListByUsernames(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+}
+
 func TestMySQLStore_List(t *testing.T) {
 	_, store := setupTestStore(t)
 	ctx := context.Background()
@@ -261,3 +290,109 @@ func TestMySQLStore_List(t *testing.T) {
 		}
 	})
 }
+
+func TestMySQLStore_ListAll(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	active := createTestUser("alice@example.com", "alice", "password123")
+	require.NoError(t, store.Create(ctx, active))
+	inactive := createTestUser("bob@example.com", "bob", "password123")
+	require.NoError(t, store.Create(ctx, inactive))
+	require.NoError(t, store.Delete(ctx, inactive.ID))
+
+	t.Run("includes inactive users", func(t *testing.T) {
+		users, err := store.ListAll(ctx, "", 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, users, 2)
+
+		count, err := store.CountAll(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		users, err := store.ListAll(ctx, "bob", 10, 0)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, inactive.ID, users[0].ID)
+		assert.False(t, users[0].IsActive)
+
+		count, err := store.CountAll(ctx, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("get any by ID", func(t *testing.T) {
+		_, err := store.GetByID(ctx, inactive.ID)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		found, err := store.GetAnyByID(ctx, inactive.ID)
+		require.NoError(t, err)
+		assert.False(t, found.IsActive)
+	})
+}
+
+func TestMySQLStore_Reactivate(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	user := createTestUser("carol@example.com", "carol", "password123")
+	require.NoError(t, store.Create(ctx, user))
+
+	assert.ErrorIs(t, store.Reactivate(ctx, user.ID), ErrUserNotFound)
+
+	require.NoError(t, store.Delete(ctx, user.ID))
+	require.NoError(t, store.Reactivate(ctx, user.ID))
+
+	found, err := store.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, found.IsActive)
+}
+
+func TestMySQLStore_CountAdmins(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	admin := createTestUser("admin@example.com", "admin", "password123")
+	admin.Role = RoleAdmin
+	require.NoError(t, store.Create(ctx, admin))
+	require.NoError(t, store.Create(ctx, createTestUser("dave@example.com", "dave", "password123")))
+
+	count, err := store.CountAdmins(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	second := createTestUser("erin@example.com", "erin", "password123")
+	require.NoError(t, store.Create(ctx, second))
+	require.NoError(t, store.Update(ctx, second.ID, SetRole(RoleAdmin)))
+	assert.ErrorIs(t, store.Update(ctx, second.ID, SetRole("owner")), ErrInvalidRole)
+
+	count, err = store.CountAdmins(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Deactivated admins do not count.
+	require.NoError(t, store.Delete(ctx, admin.ID))
+	count, err = store.CountAdmins(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMySQLStore_UpdateServiceAccount(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	sa := &User{Email: "sa-ci@service-accounts.invalid", Username: "ci", Role: RoleService, IsActive: true}
+	require.NoError(t, store.Create(ctx, sa))
+
+	assert.ErrorIs(t, store.Update(ctx, sa.ID, SetPassword("password123")), ErrServiceAccount)
+	assert.ErrorIs(t, store.Update(ctx, sa.ID, SetRole(RoleAdmin)), ErrServiceAccount)
+	assert.ErrorIs(t, store.Update(ctx, sa.ID, SetRole(RoleService)), ErrInvalidRole)
+	require.NoError(t, store.Update(ctx, sa.ID, SetUsername("ci-nightly")))
+
+	found, err := store.GetByID(ctx, sa.ID)
+	require.NoError(t, err)
+	assert.True(t, found.IsServiceAccount())
+	assert.False(t, found.CheckPassword(""))
+}
@@ -23,8 +23,12 @@ func SetUsername(username string) UpdateSetter {
 }
 
 // SetPassword returns an UpdateSetter that sets the user's password.
+// Service accounts have no password.
 func SetPassword(password string) UpdateSetter {
 	return func(u *User) error {
+		if u.IsServiceAccount() {
+			return ErrServiceAccount
+		}
 		return u.SetPassword(password)
 	}
 }
@@ -36,3 +40,18 @@ func SetActive(active bool) UpdateSetter {
 		return nil
 	}
 }
+
+// SetRole returns an UpdateSetter that sets the user's role. Only people's
+// roles can be set, and only to roles people can have.
+func SetRole(role Role) UpdateSetter {
+	return func(u *User) error {
+		if !role.IsAssignable() {
+			return ErrInvalidRole
+		}
+		if u.IsServiceAccount() {
+			return ErrServiceAccount
+		}
+		u.Role = role
+		return nil
+	}
+}
@@ -37,6 +37,26 @@ type Store interface {
 
 	// Search searches for active users by username or email.
 	Search(ctx context.Context, query string, limit, offset int) ([]*User, error)
+
+	// ListByUsernames retrieves the active users with any of the given
+	// usernames. Usernames are not unique, so one name may match several users.
+	ListByUsernames(ctx context.Context, usernames []string) ([]*User, error)
+
+	// GetAnyByID retrieves a user by their ID, whether active or not.
+	GetAnyByID(ctx context.Context, id uuid.UUID) (*User, error)
+
+	// ListAll retrieves a paginated list of users, active or not, newest
+	// first. A non-empty query matches their username or email.
+	ListAll(ctx context.Context, query string, limit, offset int) ([]*User, error)
+
+	// CountAll returns the total count of users ListAll lists for query.
+	CountAll(ctx context.Context, query string) (int, error)
+
+	// Reactivate sets a deactivated user's is_active back to true.
+	Reactivate(ctx context.Context, id uuid.UUID) error
+
+	// CountAdmins returns the count of active admins.
+	CountAdmins(ctx context.Context) (int, error)
 }
 
 // UpdateSetter is a function that updates a user field.
@@ -18,8 +18,48 @@ var (
 
 	// ErrInvalidUsername is returned when a username is empty or invalid.
 	ErrInvalidUsername = errors.New("username is required")
+
+	// ErrInvalidRole is returned when a role is not user, admin or guest.
+	ErrInvalidRole = errors.New("role must be user, admin or guest")
+
+	// ErrServiceAccount is returned when changing what only people have,
+	// such as a password or role, of the user behind a service account.
+	ErrServiceAccount = errors.New("not allowed for service accounts")
+)
+
+// Role is what a user may do across the platform, beyond their own
+// projects.
+type Role string
+
+const (
+	// RoleUser manages their own account and projects.
+	RoleUser Role = "user"
+
+	// RoleAdmin also manages every user and project through the admin API.
+	RoleAdmin Role = "admin"
+
+	// RoleGuest is for external testers, such as outsourced or UAT testers.
+	// Guests own no projects: they only see the procedures shared with them
+	// and execute the runs assigned to them.
+	RoleGuest Role = "guest"
+
+	// RoleService is the role of the user behind a service account. It
+	// cannot log in, and only acts through API tokens in the projects the
+	// service account is granted.
+	RoleService Role = "service"
 )
 
+// IsValid checks if the role is supported.
+func (r Role) IsValid() bool {
+	return r == RoleUser || r == RoleAdmin || r == RoleGuest || r == RoleService
+}
+
+// IsAssignable reports whether people can be given the role. Service
+// accounts are created with their role and keep it.
+func (r Role) IsAssignable() bool {
+	return r == RoleUser || r == RoleAdmin || r == RoleGuest
+}
+
 // User represents a user in the system.
 type User struct {
 	ID           uuid.UUID `json:"id" gorm:"type:char(36);primaryKey"`
@@ -27,6 +67,7 @@ type User struct {
 	Username     string    `json:"username" gorm:"not null"`
 	PasswordHash string    `json:"-" gorm:"not null"`
 	IsActive     bool      `json:"is_active" gorm:"default:true"`
+	Role         Role      `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -61,6 +102,23 @@ func (u *User) CheckPassword(password string) bool {
 	return err == nil
 }
 
+// IsAdmin reports whether the user is a platform admin.
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsGuest reports whether the user is an external tester with limited
+// access.
+func (u *User) IsGuest() bool {
+	return u.Role == RoleGuest
+}
+
+// IsServiceAccount reports whether the user is the user behind a service
+// account rather than a person.
+func (u *User) IsServiceAccount() bool {
+	return u.Role == RoleService
+}
+
 // Validate checks if the user has valid required fields.
 func (u *User) Validate() error {
 	if u.Email == "" {
@@ -69,5 +127,8 @@ func (u *User) Validate() error {
 	if u.Username == "" {
 		return ErrInvalidUsername
 	}
+	if u.Role != "" && !u.Role.IsValid() {
+		return ErrInvalidRole
+	}
 	return nil
 }
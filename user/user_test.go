@@ -120,6 +120,24 @@ func TestUser_Validate(t *testing.T) {
 			user:    User{},
 			wantErr: ErrInvalidEmail,
 		},
+		{
+			name: "guest",
+			user: User{
+				Email:    "tester@example.com",
+				Username: "tester",
+				Role:     RoleGuest,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "invalid role",
+			user: User{
+				Email:    "test@example.com",
+				Username: "testuser",
+				Role:     "owner",
+			},
+			wantErr: ErrInvalidRole,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,171 @@
+package visualregression
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+)
+
+// Approver promotes the captures of a visual_regression job to baselines.
+type Approver struct {
+	jobStore      job.Store
+	baselineStore Store
+	storage       storage.BlobStorage
+	recorder      *metering.Recorder
+	logger        logger.Logger
+}
+
+// NewApprover creates a new baseline approver.
+func NewApprover(jobStore job.Store, baselineStore Store, blobStorage storage.BlobStorage, recorder *metering.Recorder, log logger.Logger) *Approver {
+	return &Approver{
+		jobStore:      jobStore,
+		baselineStore: baselineStore,
+		storage:       blobStorage,
+		recorder:      recorder,
+		logger:        log,
+	}
+}
+
+// Approve makes the captures of the named pages the new baselines for their
+// pages. With no page names, every page that changed or had no baseline is
+// approved. Captures are copied so baselines outlive the job's own images.
+func (a *Approver) Approve(ctx context.Context, jobID uuid.UUID, pageNames []string, approvedBy uuid.UUID) ([]*Baseline, error) {
+	j, err := a.jobStore.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if j.Type != job.JobTypeVisualRegression || j.Status != job.StatusSuccess {
+		return nil, ErrJobNotApprovable
+	}
+
+	cfg, err := ParseConfig(j.Config)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := PagesFromResult(j.Result)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selectPages(pages, pageNames)
+	if err != nil {
+		return nil, err
+	}
+
+	baselines := make([]*Baseline, 0, len(selected))
+	for _, page := range selected {
+		baseline, err := a.approvePage(ctx, j, cfg, page, approvedBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to approve page %q: %w", page.Name, err)
+		}
+		baselines = append(baselines, baseline)
+	}
+	return baselines, nil
+}
+
+// selectPages picks the pages to approve from a job result.
+func selectPages(pages []PageResult, names []string) ([]PageResult, error) {
+	if len(names) == 0 {
+		var selected []PageResult
+		for _, page := range pages {
+			if page.ActualPath != "" && (page.Status == PageStatusChanged || page.Status == PageStatusNew) {
+				selected = append(selected, page)
+			}
+		}
+		return selected, nil
+	}
+
+	byName := make(map[string]PageResult, len(pages))
+	for _, page := range pages {
+		byName[page.Name] = page
+	}
+	selected := make([]PageResult, 0, len(names))
+	for _, name := range names {
+		page, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPageNotFound, name)
+		}
+		if page.ActualPath == "" {
+			return nil, fmt.Errorf("%w: %s", ErrNoCapture, name)
+		}
+		selected = append(selected, page)
+	}
+	return selected, nil
+}
+
+func (a *Approver) approvePage(ctx context.Context, j *job.Job, cfg *Config, page PageResult, approvedBy uuid.UUID) (*Baseline, error) {
+	reader, err := a.storage.Download(ctx, page.ActualPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return nil, err
+	}
+	imgCfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("capture is not a valid PNG: %w", err)
+	}
+
+	imagePath := fmt.Sprintf("visual-baselines/%s/%s/%s-%s.png", cfg.ProjectID.String(), cfg.EndpointID.String(), page.Name, j.ID.String())
+	if err := a.storage.Upload(ctx, imagePath, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	size := int64(len(data))
+
+	setImage := SetImage(imagePath, page.URL, size, imgCfg.Width, imgCfg.Height, &j.ID, approvedBy)
+
+	existing, err := a.baselineStore.GetByPage(ctx, cfg.ProjectID, cfg.EndpointID, page.Name)
+	switch {
+	case errors.Is(err, ErrBaselineNotFound):
+		baseline := &Baseline{
+			ProjectID:  cfg.ProjectID,
+			EndpointID: cfg.EndpointID,
+			PageName:   page.Name,
+		}
+		if err := setImage(baseline); err != nil {
+			return nil, err
+		}
+		if err := a.baselineStore.Create(ctx, baseline); err != nil {
+			a.storage.Delete(ctx, imagePath)
+			return nil, err
+		}
+		a.recorder.RecordStorage(ctx, cfg.ProjectID, approvedBy, size)
+		return baseline, nil
+
+	case err != nil:
+		a.storage.Delete(ctx, imagePath)
+		return nil, err
+	}
+
+	if err := a.baselineStore.Update(ctx, existing.ID, setImage); err != nil {
+		a.storage.Delete(ctx, imagePath)
+		return nil, err
+	}
+
+	// Re-approving the same job overwrites the baseline image in place.
+	if existing.ImagePath != imagePath {
+		a.recorder.RecordStorage(ctx, cfg.ProjectID, approvedBy, size)
+		if err := a.storage.Delete(ctx, existing.ImagePath); err != nil {
+			a.logger.Warn(ctx, "failed to delete replaced baseline image", map[string]interface{}{
+				"error":       err.Error(),
+				"baseline_id": existing.ID.String(),
+				"path":        existing.ImagePath,
+			})
+		} else {
+			a.recorder.RecordStorage(ctx, cfg.ProjectID, approvedBy, -existing.FileSize)
+		}
+	}
+
+	return a.baselineStore.GetByID(ctx, existing.ID)
+}
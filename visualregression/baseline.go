@@ -0,0 +1,72 @@
+package visualregression
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrBaselineNotFound is returned when a baseline is not found.
+	ErrBaselineNotFound = errors.New("baseline not found")
+
+	// ErrInvalidProjectID is returned when project_id is not set.
+	ErrInvalidProjectID = errors.New("project_id is required")
+
+	// ErrInvalidEndpointID is returned when endpoint_id is not set.
+	ErrInvalidEndpointID = errors.New("endpoint_id is required")
+
+	// ErrInvalidImagePath is returned when a baseline has no image.
+	ErrInvalidImagePath = errors.New("image_path is required")
+)
+
+// Baseline is the approved screenshot of a page of an endpoint within a
+// project. New captures of the page are compared against it.
+type Baseline struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primaryKey"`
+	ProjectID   uuid.UUID  `json:"project_id" gorm:"type:char(36);not null;uniqueIndex:idx_visual_baselines_page"`
+	EndpointID  uuid.UUID  `json:"endpoint_id" gorm:"type:char(36);not null;uniqueIndex:idx_visual_baselines_page"`
+	PageName    string     `json:"page_name" gorm:"type:varchar(100);not null;uniqueIndex:idx_visual_baselines_page"`
+	URL         string     `json:"url" gorm:"type:varchar(2048);not null"`
+	ImagePath   string     `json:"image_path" gorm:"type:varchar(512);not null"`
+	FileSize    int64      `json:"file_size" gorm:"not null"`
+	Width       int        `json:"width" gorm:"not null"`
+	Height      int        `json:"height" gorm:"not null"`
+	SourceJobID *uuid.UUID `json:"source_job_id,omitempty" gorm:"type:char(36)"`
+	ApprovedBy  uuid.UUID  `json:"approved_by" gorm:"type:char(36);not null"`
+	ApprovedAt  time.Time  `json:"approved_at" gorm:"not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Baseline.
+func (Baseline) TableName() string {
+	return "visual_baselines"
+}
+
+// BeforeCreate hook to generate UUID before creating a new baseline.
+func (b *Baseline) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks if the baseline has valid required fields.
+func (b *Baseline) Validate() error {
+	if b.ProjectID == uuid.Nil {
+		return ErrInvalidProjectID
+	}
+	if b.EndpointID == uuid.Nil {
+		return ErrInvalidEndpointID
+	}
+	if !pageNamePattern.MatchString(b.PageName) {
+		return ErrInvalidPageName
+	}
+	if b.ImagePath == "" {
+		return ErrInvalidImagePath
+	}
+	return nil
+}
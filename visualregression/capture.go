@@ -0,0 +1,107 @@
+package visualregression
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Target is a page to capture.
+type Target struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Capture is the outcome of capturing a target. File is the screenshot path
+// relative to the output directory and is empty when Error is set.
+type Capture struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	File  string `json:"file,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// CaptureRequest describes a batch of pages to capture with one browser.
+type CaptureRequest struct {
+	Targets   []Target
+	Viewport  Viewport
+	FullPage  bool
+	OutputDir string
+}
+
+// Capturer takes PNG screenshots of web pages.
+type Capturer interface {
+	// Capture screenshots every target into req.OutputDir. A failure to capture
+	// a single page is reported in its Capture; an error is returned only when
+	// no capture could be attempted at all.
+	Capture(ctx context.Context, req CaptureRequest) ([]Capture, error)
+}
+
+// ScriptCapturer captures pages by running the visual capture script, which
+// drives the browser of the Playwright MCP server.
+type ScriptCapturer struct {
+	scriptPath       string
+	playwrightMCPURL string
+}
+
+// NewScriptCapturer creates a capturer that runs the script at scriptPath
+// against the Playwright MCP server at playwrightMCPURL.
+func NewScriptCapturer(scriptPath, playwrightMCPURL string) *ScriptCapturer {
+	return &ScriptCapturer{
+		scriptPath:       scriptPath,
+		playwrightMCPURL: playwrightMCPURL,
+	}
+}
+
+// scriptConfig is the JSON config sent to the capture script via stdin.
+type scriptConfig struct {
+	PlaywrightMCPURL string   `json:"playwright_mcp_url"`
+	OutputDir        string   `json:"output_dir"`
+	Viewport         Viewport `json:"viewport"`
+	FullPage         bool     `json:"full_page"`
+	Pages            []Target `json:"pages"`
+}
+
+// Capture runs the capture script and reads the manifest it writes.
+func (c *ScriptCapturer) Capture(ctx context.Context, req CaptureRequest) ([]Capture, error) {
+	configJSON, err := json.Marshal(scriptConfig{
+		PlaywrightMCPURL: c.playwrightMCPURL + "/sse",
+		OutputDir:        req.OutputDir,
+		Viewport:         req.Viewport,
+		FullPage:         req.FullPage,
+		Pages:            req.Targets,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal capture config: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "python3", c.scriptPath)
+	cmd.Stdin = bytes.NewReader(configJSON)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		stderrStr := stderr.String()
+		if len(stderrStr) > 500 {
+			stderrStr = stderrStr[len(stderrStr)-500:]
+		}
+		return nil, fmt.Errorf("capture script failed: %v; stderr: %s", err, stderrStr)
+	}
+
+	data, err := os.ReadFile(filepath.Join(req.OutputDir, "result.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capture result: %w", err)
+	}
+
+	var manifest struct {
+		Pages []Capture `json:"pages"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse capture result: %w", err)
+	}
+	return manifest.Pages, nil
+}
@@ -0,0 +1,159 @@
+package visualregression
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+	"github.com/hairizuanbinnoorazman/ui-automation/testutil"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupTestStore creates a test database and baseline store for testing.
+func setupTestStore(t *testing.T) (*gorm.DB, Store) {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Baseline{})
+
+	log := logger.NewTestLogger()
+	store := NewMySQLStore(db, log)
+
+	return db, store
+}
+
+// testEnv wires a runner and approver to in-memory stores, local blob storage
+// and a fake capturer.
+type testEnv struct {
+	runner        *Runner
+	approver      *Approver
+	capturer      *fakeCapturer
+	jobStore      job.Store
+	baselineStore Store
+	storage       storage.BlobStorage
+	endpoint      *endpoint.Endpoint
+	projectID     uuid.UUID
+	userID        uuid.UUID
+}
+
+// setupTestEnv creates the stores, an endpoint and the runner and approver
+// under test.
+func setupTestEnv(t *testing.T) *testEnv {
+	db := testutil.SetupTestDB(t)
+	testutil.AutoMigrate(t, db, &Baseline{}, &job.Job{}, &endpoint.Endpoint{})
+
+	log := logger.NewTestLogger()
+	blobStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	endpointStore := endpoint.NewMySQLStore(db, log)
+	ep := &endpoint.Endpoint{Name: "Staging", URL: "https://staging.example.com/", CreatedBy: userID}
+	require.NoError(t, endpointStore.Create(context.Background(), ep))
+
+	baselineStore := NewMySQLStore(db, log)
+	jobStore := job.NewMySQLStore(db, log)
+	capturer := &fakeCapturer{images: map[string]image.Image{}}
+
+	return &testEnv{
+		runner:        NewRunner(endpointStore, baselineStore, blobStorage, capturer, nil, log),
+		approver:      NewApprover(jobStore, baselineStore, blobStorage, nil, log),
+		capturer:      capturer,
+		jobStore:      jobStore,
+		baselineStore: baselineStore,
+		storage:       blobStorage,
+		endpoint:      ep,
+		projectID:     uuid.New(),
+		userID:        userID,
+	}
+}
+
+// createJob creates a visual_regression job capturing the named pages.
+func (e *testEnv) createJob(t *testing.T, pageNames ...string) *job.Job {
+	t.Helper()
+
+	pages := make([]interface{}, len(pageNames))
+	for i, name := range pageNames {
+		pages[i] = map[string]interface{}{"name": name, "path": "/" + name}
+	}
+	j := &job.Job{
+		Type: job.JobTypeVisualRegression,
+		Config: job.JSONMap{
+			"endpoint_id": e.endpoint.ID.String(),
+			"project_id":  e.projectID.String(),
+			"pages":       pages,
+		},
+		CreatedBy: e.userID,
+	}
+	require.NoError(t, e.jobStore.Create(context.Background(), j))
+	return j
+}
+
+// runJob runs the job and stores its result as the pipeline would.
+func (e *testEnv) runJob(t *testing.T, j *job.Job) []PageResult {
+	t.Helper()
+	ctx := context.Background()
+
+	result, err := e.runner.Run(ctx, j)
+	require.NoError(t, err)
+	require.NoError(t, e.jobStore.Start(ctx, j.ID))
+	require.NoError(t, e.jobStore.Complete(ctx, j.ID, job.StatusSuccess, result))
+
+	stored, err := e.jobStore.GetByID(ctx, j.ID)
+	require.NoError(t, err)
+	pages, err := PagesFromResult(stored.Result)
+	require.NoError(t, err)
+	return pages
+}
+
+// fakeCapturer writes preconfigured images instead of driving a browser.
+// Targets without an image are reported as failed captures.
+type fakeCapturer struct {
+	images   map[string]image.Image
+	requests []CaptureRequest
+}
+
+func (f *fakeCapturer) Capture(ctx context.Context, req CaptureRequest) ([]Capture, error) {
+	f.requests = append(f.requests, req)
+
+	captures := make([]Capture, len(req.Targets))
+	for i, target := range req.Targets {
+		captures[i] = Capture{Name: target.Name, URL: target.URL}
+		img, ok := f.images[target.Name]
+		if !ok {
+			captures[i].Error = "navigation timed out"
+			continue
+		}
+		file := fmt.Sprintf("page-%d.png", i)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(req.OutputDir, file), buf.Bytes(), 0o644); err != nil {
+			return nil, err
+		}
+		captures[i].File = file
+	}
+	return captures, nil
+}
+
+// solidImage creates a w x h image filled with c.
+func solidImage(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
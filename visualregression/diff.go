@@ -0,0 +1,108 @@
+package visualregression
+
+import (
+	"image"
+	"image/color"
+)
+
+// DefaultPixelThreshold is the perceptual color distance, from 0 to 1, below
+// which two pixels are considered equal. It absorbs anti-aliasing and
+// compression noise that a human would not notice.
+const DefaultPixelThreshold = 0.1
+
+// maxYIQDelta is the largest possible value returned by colorDelta.
+const maxYIQDelta = 35215.0
+
+var diffHighlight = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+// DiffResult is the outcome of comparing a capture against its baseline.
+type DiffResult struct {
+	DiffPixels  int
+	TotalPixels int
+	Ratio       float64
+	Image       *image.RGBA
+}
+
+// Compare performs a perceptual comparison of actual against baseline. Pixels
+// are compared in the YIQ color space, which weighs differences the way the
+// eye perceives them. When the images differ in size, the area covered by
+// only one of them counts as changed.
+//
+// The returned diff image shows the baseline faded to grey with changed
+// pixels highlighted in red.
+func Compare(baseline, actual image.Image, pixelThreshold float64) *DiffResult {
+	bb, ab := baseline.Bounds(), actual.Bounds()
+	width := max(bb.Dx(), ab.Dx())
+	height := max(bb.Dy(), ab.Dy())
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	limit := maxYIQDelta * pixelThreshold * pixelThreshold
+	result := &DiffResult{TotalPixels: width * height, Image: out}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			inBaseline := x < bb.Dx() && y < bb.Dy()
+			inActual := x < ab.Dx() && y < ab.Dy()
+			if !inBaseline || !inActual {
+				out.SetRGBA(x, y, diffHighlight)
+				result.DiffPixels++
+				continue
+			}
+
+			c1 := baseline.At(bb.Min.X+x, bb.Min.Y+y)
+			c2 := actual.At(ab.Min.X+x, ab.Min.Y+y)
+			if colorDelta(c1, c2) > limit {
+				out.SetRGBA(x, y, diffHighlight)
+				result.DiffPixels++
+				continue
+			}
+			out.SetRGBA(x, y, fadedGray(c1))
+		}
+	}
+
+	if result.TotalPixels > 0 {
+		result.Ratio = float64(result.DiffPixels) / float64(result.TotalPixels)
+	}
+	return result
+}
+
+// colorDelta returns the squared YIQ distance between two colors after
+// blending both onto a white background.
+func colorDelta(c1, c2 color.Color) float64 {
+	r1, g1, b1 := blendOnWhite(c1)
+	r2, g2, b2 := blendOnWhite(c2)
+
+	y := rgbToY(r1, g1, b1) - rgbToY(r2, g2, b2)
+	i := rgbToI(r1, g1, b1) - rgbToI(r2, g2, b2)
+	q := rgbToQ(r1, g1, b1) - rgbToQ(r2, g2, b2)
+
+	return 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+}
+
+// fadedGray renders a pixel as a light grey so highlighted changes stand out.
+func fadedGray(c color.Color) color.RGBA {
+	r, g, b := blendOnWhite(c)
+	v := uint8(255 + (rgbToY(r, g, b)-255)*0.1)
+	return color.RGBA{R: v, G: v, B: v, A: 255}
+}
+
+func blendOnWhite(c color.Color) (r, g, b float64) {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	a := float64(nc.A) / 255
+	blend := func(v uint8) float64 {
+		return 255 + (float64(v)-255)*a
+	}
+	return blend(nc.R), blend(nc.G), blend(nc.B)
+}
+
+func rgbToY(r, g, b float64) float64 {
+	return r*0.29889531 + g*0.58662247 + b*0.11448223
+}
+
+func rgbToI(r, g, b float64) float64 {
+	return r*0.59597799 - g*0.27417610 - b*0.32180189
+}
+
+func rgbToQ(r, g, b float64) float64 {
+	return r*0.21147017 - g*0.52261711 + b*0.31114694
+}
@@ -0,0 +1,48 @@
+package visualregression
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	black := color.RGBA{0, 0, 0, 255}
+
+	t.Run("identical images", func(t *testing.T) {
+		diff := Compare(solidImage(10, 10, white), solidImage(10, 10, white), DefaultPixelThreshold)
+
+		assert.Equal(t, 0, diff.DiffPixels)
+		assert.Equal(t, 100, diff.TotalPixels)
+		assert.Equal(t, 0.0, diff.Ratio)
+	})
+
+	t.Run("changed region", func(t *testing.T) {
+		actual := solidImage(10, 10, white)
+		for x := 0; x < 10; x++ {
+			actual.Set(x, 0, black)
+		}
+
+		diff := Compare(solidImage(10, 10, white), actual, DefaultPixelThreshold)
+
+		assert.Equal(t, 10, diff.DiffPixels)
+		assert.InDelta(t, 0.1, diff.Ratio, 1e-9)
+		assert.Equal(t, color.RGBA{255, 0, 0, 255}, diff.Image.RGBAAt(0, 0))
+		assert.NotEqual(t, color.RGBA{255, 0, 0, 255}, diff.Image.RGBAAt(0, 1))
+	})
+
+	t.Run("imperceptible change is ignored", func(t *testing.T) {
+		diff := Compare(solidImage(10, 10, white), solidImage(10, 10, color.RGBA{254, 254, 254, 255}), DefaultPixelThreshold)
+
+		assert.Equal(t, 0, diff.DiffPixels)
+	})
+
+	t.Run("different sizes count the extra area", func(t *testing.T) {
+		diff := Compare(solidImage(10, 10, white), solidImage(10, 12, white), DefaultPixelThreshold)
+
+		assert.Equal(t, 120, diff.TotalPixels)
+		assert.Equal(t, 20, diff.DiffPixels)
+	})
+}
@@ -0,0 +1,165 @@
+package visualregression
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"gorm.io/gorm"
+)
+
+// MySQLStore implements the Store interface using GORM and MySQL.
+type MySQLStore struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+// NewMySQLStore creates a new MySQL-backed baseline store.
+func NewMySQLStore(db *gorm.DB, log logger.Logger) *MySQLStore {
+	return &MySQLStore{
+		db:     db,
+		logger: log,
+	}
+}
+
+// Create creates a new baseline in the database.
+func (s *MySQLStore) Create(ctx context.Context, baseline *Baseline) error {
+	if err := baseline.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Create(baseline).Error; err != nil {
+		s.logger.Error(ctx, "failed to create baseline", map[string]interface{}{
+			"error":       err.Error(),
+			"project_id":  baseline.ProjectID.String(),
+			"endpoint_id": baseline.EndpointID.String(),
+			"page_name":   baseline.PageName,
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "baseline created", map[string]interface{}{
+		"baseline_id": baseline.ID.String(),
+		"page_name":   baseline.PageName,
+	})
+
+	return nil
+}
+
+// GetByID retrieves a baseline by its ID.
+func (s *MySQLStore) GetByID(ctx context.Context, id uuid.UUID) (*Baseline, error) {
+	var baseline Baseline
+	err := s.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&baseline).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBaselineNotFound
+		}
+		s.logger.Error(ctx, "failed to get baseline by ID", map[string]interface{}{
+			"error":       err.Error(),
+			"baseline_id": id.String(),
+		})
+		return nil, err
+	}
+
+	return &baseline, nil
+}
+
+// GetByPage retrieves the baseline of a page of an endpoint in a project.
+func (s *MySQLStore) GetByPage(ctx context.Context, projectID, endpointID uuid.UUID, pageName string) (*Baseline, error) {
+	var baseline Baseline
+	err := s.db.WithContext(ctx).
+		Where("project_id = ? AND endpoint_id = ? AND page_name = ?", projectID, endpointID, pageName).
+		First(&baseline).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBaselineNotFound
+		}
+		s.logger.Error(ctx, "failed to get baseline by page", map[string]interface{}{
+			"error":       err.Error(),
+			"project_id":  projectID.String(),
+			"endpoint_id": endpointID.String(),
+			"page_name":   pageName,
+		})
+		return nil, err
+	}
+
+	return &baseline, nil
+}
+
+// Update updates a baseline with the given setters.
+func (s *MySQLStore) Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error {
+	baseline, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, setter := range setters {
+		if err := setter(baseline); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Save(baseline).Error; err != nil {
+		s.logger.Error(ctx, "failed to update baseline", map[string]interface{}{
+			"error":       err.Error(),
+			"baseline_id": id.String(),
+		})
+		return err
+	}
+
+	s.logger.Info(ctx, "baseline updated", map[string]interface{}{
+		"baseline_id": id.String(),
+	})
+
+	return nil
+}
+
+// Delete deletes a baseline by its ID.
+func (s *MySQLStore) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&Baseline{}, "id = ?", id)
+	if result.Error != nil {
+		s.logger.Error(ctx, "failed to delete baseline", map[string]interface{}{
+			"error":       result.Error.Error(),
+			"baseline_id": id.String(),
+		})
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrBaselineNotFound
+	}
+
+	s.logger.Info(ctx, "baseline deleted", map[string]interface{}{
+		"baseline_id": id.String(),
+	})
+
+	return nil
+}
+
+// ListByProject retrieves the baselines of a project.
+func (s *MySQLStore) ListByProject(ctx context.Context, projectID, endpointID uuid.UUID) ([]*Baseline, error) {
+	query := s.db.WithContext(ctx).Where("project_id = ?", projectID)
+	if endpointID != uuid.Nil {
+		query = query.Where("endpoint_id = ?", endpointID)
+	}
+
+	var baselines []*Baseline
+	err := query.
+		Order("page_name ASC").
+		Find(&baselines).Error
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to list baselines", map[string]interface{}{
+			"error":      err.Error(),
+			"project_id": projectID.String(),
+		})
+		return nil, err
+	}
+
+	return baselines, nil
+}
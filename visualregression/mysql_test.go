@@ -0,0 +1,145 @@
+package visualregression
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestBaseline creates a baseline with default values.
+func createTestBaseline(projectID, endpointID uuid.UUID, pageName string) *Baseline {
+	return &Baseline{
+		ProjectID:  projectID,
+		EndpointID: endpointID,
+		PageName:   pageName,
+		URL:        "https://example.com/" + pageName,
+		ImagePath:  "visual-baselines/" + pageName + ".png",
+		FileSize:   1024,
+		Width:      1280,
+		Height:     800,
+		ApprovedBy: uuid.New(),
+	}
+}
+
+func TestMySQLStore_Create(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID, endpointID := uuid.New(), uuid.New()
+
+	t.Run("creates baseline", func(t *testing.T) {
+		baseline := createTestBaseline(projectID, endpointID, "home")
+		require.NoError(t, store.Create(ctx, baseline))
+		assert.NotEqual(t, uuid.Nil, baseline.ID)
+
+		found, err := store.GetByID(ctx, baseline.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "home", found.PageName)
+		assert.Equal(t, int64(1024), found.FileSize)
+	})
+
+	t.Run("rejects duplicate page", func(t *testing.T) {
+		err := store.Create(ctx, createTestBaseline(projectID, endpointID, "home"))
+		assert.Error(t, err)
+	})
+
+	t.Run("allows same page on another endpoint", func(t *testing.T) {
+		err := store.Create(ctx, createTestBaseline(projectID, uuid.New(), "home"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("validates required fields", func(t *testing.T) {
+		assert.ErrorIs(t, store.Create(ctx, createTestBaseline(uuid.Nil, endpointID, "a")), ErrInvalidProjectID)
+		assert.ErrorIs(t, store.Create(ctx, createTestBaseline(projectID, uuid.Nil, "a")), ErrInvalidEndpointID)
+		assert.ErrorIs(t, store.Create(ctx, createTestBaseline(projectID, endpointID, "a b")), ErrInvalidPageName)
+
+		noImage := createTestBaseline(projectID, endpointID, "a")
+		noImage.ImagePath = ""
+		assert.ErrorIs(t, store.Create(ctx, noImage), ErrInvalidImagePath)
+	})
+}
+
+func TestMySQLStore_GetByPage(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID, endpointID := uuid.New(), uuid.New()
+
+	baseline := createTestBaseline(projectID, endpointID, "login")
+	require.NoError(t, store.Create(ctx, baseline))
+
+	found, err := store.GetByPage(ctx, projectID, endpointID, "login")
+	require.NoError(t, err)
+	assert.Equal(t, baseline.ID, found.ID)
+
+	_, err = store.GetByPage(ctx, projectID, uuid.New(), "login")
+	assert.ErrorIs(t, err, ErrBaselineNotFound)
+
+	_, err = store.GetByPage(ctx, projectID, endpointID, "home")
+	assert.ErrorIs(t, err, ErrBaselineNotFound)
+}
+
+func TestMySQLStore_Update(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	baseline := createTestBaseline(uuid.New(), uuid.New(), "home")
+	require.NoError(t, store.Create(ctx, baseline))
+
+	jobID, approver := uuid.New(), uuid.New()
+	err := store.Update(ctx, baseline.ID, SetImage("visual-baselines/new.png", "https://example.com/", 2048, 375, 667, &jobID, approver))
+	require.NoError(t, err)
+
+	found, err := store.GetByID(ctx, baseline.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "visual-baselines/new.png", found.ImagePath)
+	assert.Equal(t, int64(2048), found.FileSize)
+	assert.Equal(t, 375, found.Width)
+	assert.Equal(t, 667, found.Height)
+	require.NotNil(t, found.SourceJobID)
+	assert.Equal(t, jobID, *found.SourceJobID)
+	assert.Equal(t, approver, found.ApprovedBy)
+
+	err = store.Update(ctx, baseline.ID, SetImage("", "", 0, 0, 0, nil, approver))
+	assert.ErrorIs(t, err, ErrInvalidImagePath)
+
+	err = store.Update(ctx, uuid.New(), SetImage("x.png", "", 0, 0, 0, nil, approver))
+	assert.ErrorIs(t, err, ErrBaselineNotFound)
+}
+
+func TestMySQLStore_Delete(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+
+	baseline := createTestBaseline(uuid.New(), uuid.New(), "home")
+	require.NoError(t, store.Create(ctx, baseline))
+
+	require.NoError(t, store.Delete(ctx, baseline.ID))
+
+	_, err := store.GetByID(ctx, baseline.ID)
+	assert.ErrorIs(t, err, ErrBaselineNotFound)
+
+	assert.ErrorIs(t, store.Delete(ctx, baseline.ID), ErrBaselineNotFound)
+}
+
+func TestMySQLStore_ListByProject(t *testing.T) {
+	_, store := setupTestStore(t)
+	ctx := context.Background()
+	projectID, endpointA, endpointB := uuid.New(), uuid.New(), uuid.New()
+
+	require.NoError(t, store.Create(ctx, createTestBaseline(projectID, endpointA, "login")))
+	require.NoError(t, store.Create(ctx, createTestBaseline(projectID, endpointA, "home")))
+	require.NoError(t, store.Create(ctx, createTestBaseline(projectID, endpointB, "home")))
+	require.NoError(t, store.Create(ctx, createTestBaseline(uuid.New(), endpointA, "home")))
+
+	all, err := store.ListByProject(ctx, projectID, uuid.Nil)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	onA, err := store.ListByProject(ctx, projectID, endpointA)
+	require.NoError(t, err)
+	require.Len(t, onA, 2)
+	assert.Equal(t, "home", onA[0].PageName)
+	assert.Equal(t, "login", onA[1].PageName)
+}
@@ -0,0 +1,206 @@
+package visualregression
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hairizuanbinnoorazman/ui-automation/endpoint"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/hairizuanbinnoorazman/ui-automation/logger"
+	"github.com/hairizuanbinnoorazman/ui-automation/metering"
+	"github.com/hairizuanbinnoorazman/ui-automation/storage"
+)
+
+// Runner executes visual_regression jobs: it captures the configured pages of
+// the endpoint, compares each against its baseline and stores the captures
+// and diff images in blob storage.
+type Runner struct {
+	endpointStore endpoint.Store
+	baselineStore Store
+	storage       storage.BlobStorage
+	capturer      Capturer
+	recorder      *metering.Recorder
+	logger        logger.Logger
+}
+
+// NewRunner creates a new visual regression job runner.
+func NewRunner(
+	endpointStore endpoint.Store,
+	baselineStore Store,
+	blobStorage storage.BlobStorage,
+	capturer Capturer,
+	recorder *metering.Recorder,
+	log logger.Logger,
+) *Runner {
+	return &Runner{
+		endpointStore: endpointStore,
+		baselineStore: baselineStore,
+		storage:       blobStorage,
+		capturer:      capturer,
+		recorder:      recorder,
+		logger:        log,
+	}
+}
+
+// Run captures and compares every page of the job and returns the job result.
+// Pages that change or have no baseline do not fail the job; the job fails
+// only when the capture itself cannot run.
+func (r *Runner) Run(ctx context.Context, j *job.Job) (job.JSONMap, error) {
+	cfg, err := ParseConfig(j.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := r.endpointStore.GetByID(ctx, cfg.EndpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch endpoint: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("visual-regression-%s-", j.ID.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	targets := make([]Target, len(cfg.Pages))
+	for i, page := range cfg.Pages {
+		targets[i] = Target{Name: page.Name, URL: PageURL(ep.URL, page.Path)}
+	}
+
+	captures, err := r.capturer.Capture(ctx, CaptureRequest{
+		Targets:   targets,
+		Viewport:  cfg.Viewport,
+		FullPage:  cfg.FullPage,
+		OutputDir: tmpDir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Capture, len(captures))
+	for _, c := range captures {
+		byName[c.Name] = c
+	}
+
+	results := make([]PageResult, len(targets))
+	counts := make(map[PageStatus]int)
+	for i, target := range targets {
+		results[i] = r.comparePage(ctx, j, cfg, target, byName[target.Name], tmpDir)
+		counts[results[i].Status]++
+	}
+
+	return job.JSONMap{
+		ResultKeyPages:  results,
+		ResultKeyPassed: counts[PageStatusPassed] == len(results),
+		"threshold":     cfg.Threshold,
+		"summary": map[string]int{
+			string(PageStatusPassed):  counts[PageStatusPassed],
+			string(PageStatusChanged): counts[PageStatusChanged],
+			string(PageStatusNew):     counts[PageStatusNew],
+			string(PageStatusError):   counts[PageStatusError],
+		},
+	}, nil
+}
+
+// comparePage stores the capture of one page and compares it to the page's
+// baseline. Failures are reported in the page result rather than returned.
+func (r *Runner) comparePage(ctx context.Context, j *job.Job, cfg *Config, target Target, capture Capture, tmpDir string) PageResult {
+	result := PageResult{Name: target.Name, URL: target.URL}
+	fail := func(format string, args ...interface{}) PageResult {
+		result.Status = PageStatusError
+		result.Error = fmt.Sprintf(format, args...)
+		r.logger.Warn(ctx, "visual regression page failed", map[string]interface{}{
+			"job_id": j.ID.String(),
+			"page":   target.Name,
+			"error":  result.Error,
+		})
+		return result
+	}
+
+	if capture.Error != "" {
+		return fail("capture failed: %s", capture.Error)
+	}
+	if capture.File == "" {
+		return fail("page was not captured")
+	}
+
+	actualData, err := os.ReadFile(filepath.Join(tmpDir, filepath.Base(capture.File)))
+	if err != nil {
+		return fail("failed to read capture: %v", err)
+	}
+	actual, err := png.Decode(bytes.NewReader(actualData))
+	if err != nil {
+		return fail("capture is not a valid PNG: %v", err)
+	}
+
+	result.ActualPath = fmt.Sprintf("visual-regression/%s/%s-actual.png", j.ID.String(), target.Name)
+	if err := r.storage.Upload(ctx, result.ActualPath, bytes.NewReader(actualData)); err != nil {
+		result.ActualPath = ""
+		return fail("failed to store capture: %v", err)
+	}
+	r.recorder.RecordStorage(ctx, cfg.ProjectID, j.CreatedBy, int64(len(actualData)))
+
+	baseline, err := r.baselineStore.GetByPage(ctx, cfg.ProjectID, cfg.EndpointID, target.Name)
+	if errors.Is(err, ErrBaselineNotFound) {
+		result.Status = PageStatusNew
+		return result
+	}
+	if err != nil {
+		return fail("failed to look up baseline: %v", err)
+	}
+	result.BaselineID = &baseline.ID
+	result.BaselinePath = baseline.ImagePath
+
+	expected, err := r.downloadImage(ctx, baseline.ImagePath)
+	if err != nil {
+		return fail("failed to load baseline: %v", err)
+	}
+
+	diff := Compare(expected, actual, DefaultPixelThreshold)
+	result.DiffPixels = diff.DiffPixels
+	result.DiffRatio = diff.Ratio
+	result.Status = PageStatusPassed
+	if diff.Ratio > cfg.Threshold {
+		result.Status = PageStatusChanged
+	}
+
+	if diff.DiffPixels > 0 {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, diff.Image); err != nil {
+			return fail("failed to encode diff image: %v", err)
+		}
+		size := int64(buf.Len())
+		diffPath := fmt.Sprintf("visual-regression/%s/%s-diff.png", j.ID.String(), target.Name)
+		if err := r.storage.Upload(ctx, diffPath, &buf); err != nil {
+			return fail("failed to store diff image: %v", err)
+		}
+		result.DiffPath = diffPath
+		r.recorder.RecordStorage(ctx, cfg.ProjectID, j.CreatedBy, size)
+	}
+
+	return result
+}
+
+func (r *Runner) downloadImage(ctx context.Context, path string) (image.Image, error) {
+	reader, err := r.storage.Download(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return png.Decode(reader)
+}
+
+// PageURL joins a page path onto the endpoint's base URL.
+func PageURL(baseURL, path string) string {
+	if path == "" {
+		return baseURL
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,155 @@
+package visualregression
+
+import (
+	"context"
+	"image/color"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	white = color.RGBA{255, 255, 255, 255}
+	black = color.RGBA{0, 0, 0, 255}
+)
+
+func TestRunner_Run(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	// First run: every captured page is new.
+	env.capturer.images["home"] = solidImage(20, 20, white)
+	env.capturer.images["login"] = solidImage(20, 20, white)
+	first := env.createJob(t, "home", "login", "broken")
+	pages := env.runJob(t, first)
+
+	require.Len(t, env.capturer.requests, 1)
+	req := env.capturer.requests[0]
+	assert.Equal(t, "https://staging.example.com/home", req.Targets[0].URL)
+	assert.Equal(t, Viewport{Width: DefaultViewportWidth, Height: DefaultViewportHeight}, req.Viewport)
+
+	require.Len(t, pages, 3)
+	assert.Equal(t, PageStatusNew, pages[0].Status)
+	assert.NotEmpty(t, pages[0].ActualPath)
+	assert.Equal(t, PageStatusNew, pages[1].Status)
+	assert.Equal(t, PageStatusError, pages[2].Status)
+	assert.Contains(t, pages[2].Error, "navigation timed out")
+
+	baselines, err := env.approver.Approve(ctx, first.ID, nil, env.userID)
+	require.NoError(t, err)
+	assert.Len(t, baselines, 2)
+
+	// Second run: login changes, home stays the same.
+	changed := solidImage(20, 20, white)
+	for x := 0; x < 20; x++ {
+		changed.Set(x, 0, black)
+	}
+	env.capturer.images["login"] = changed
+	second := env.createJob(t, "home", "login")
+	result, err := env.runner.Run(ctx, second)
+	require.NoError(t, err)
+	assert.Equal(t, false, result[ResultKeyPassed])
+
+	pages, err = PagesFromResult(result)
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+
+	assert.Equal(t, PageStatusPassed, pages[0].Status)
+	assert.Equal(t, 0, pages[0].DiffPixels)
+	assert.Empty(t, pages[0].DiffPath)
+	require.NotNil(t, pages[0].BaselineID)
+
+	assert.Equal(t, PageStatusChanged, pages[1].Status)
+	assert.Equal(t, 20, pages[1].DiffPixels)
+	assert.InDelta(t, 0.05, pages[1].DiffRatio, 1e-9)
+	assert.NotEmpty(t, pages[1].DiffPath)
+	exists, err := env.storage.Exists(ctx, pages[1].DiffPath)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestRunner_Run_AllPassed(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	env.capturer.images["home"] = solidImage(10, 10, white)
+	first := env.createJob(t, "home")
+	env.runJob(t, first)
+	_, err := env.approver.Approve(ctx, first.ID, nil, env.userID)
+	require.NoError(t, err)
+
+	result, err := env.runner.Run(ctx, env.createJob(t, "home"))
+	require.NoError(t, err)
+	assert.Equal(t, true, result[ResultKeyPassed])
+}
+
+func TestApprover_Approve(t *testing.T) {
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	env.capturer.images["home"] = solidImage(10, 10, white)
+	env.capturer.images["login"] = solidImage(10, 10, white)
+	first := env.createJob(t, "home", "login", "broken")
+	env.runJob(t, first)
+
+	t.Run("rejects unknown page", func(t *testing.T) {
+		_, err := env.approver.Approve(ctx, first.ID, []string{"missing"}, env.userID)
+		assert.ErrorIs(t, err, ErrPageNotFound)
+	})
+
+	t.Run("rejects page without capture", func(t *testing.T) {
+		_, err := env.approver.Approve(ctx, first.ID, []string{"broken"}, env.userID)
+		assert.ErrorIs(t, err, ErrNoCapture)
+	})
+
+	t.Run("approves selected pages", func(t *testing.T) {
+		baselines, err := env.approver.Approve(ctx, first.ID, []string{"home"}, env.userID)
+		require.NoError(t, err)
+		require.Len(t, baselines, 1)
+
+		b := baselines[0]
+		assert.Equal(t, "home", b.PageName)
+		assert.Equal(t, env.endpoint.ID, b.EndpointID)
+		assert.Equal(t, env.projectID, b.ProjectID)
+		assert.Equal(t, 10, b.Width)
+		require.NotNil(t, b.SourceJobID)
+		assert.Equal(t, first.ID, *b.SourceJobID)
+
+		_, err = env.baselineStore.GetByPage(ctx, env.projectID, env.endpoint.ID, "login")
+		assert.ErrorIs(t, err, ErrBaselineNotFound)
+	})
+
+	t.Run("replaces baseline from a later job", func(t *testing.T) {
+		old, err := env.baselineStore.GetByPage(ctx, env.projectID, env.endpoint.ID, "home")
+		require.NoError(t, err)
+
+		env.capturer.images["home"] = solidImage(12, 12, black)
+		second := env.createJob(t, "home")
+		env.runJob(t, second)
+
+		baselines, err := env.approver.Approve(ctx, second.ID, nil, env.userID)
+		require.NoError(t, err)
+		require.Len(t, baselines, 1)
+		assert.Equal(t, old.ID, baselines[0].ID)
+		assert.Equal(t, 12, baselines[0].Width)
+		assert.NotEqual(t, old.ImagePath, baselines[0].ImagePath)
+
+		exists, err := env.storage.Exists(ctx, old.ImagePath)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("rejects unfinished job", func(t *testing.T) {
+		pending := env.createJob(t, "home")
+		_, err := env.approver.Approve(ctx, pending.ID, nil, env.userID)
+		assert.ErrorIs(t, err, ErrJobNotApprovable)
+	})
+
+	t.Run("rejects missing job", func(t *testing.T) {
+		_, err := env.approver.Approve(ctx, uuid.New(), nil, env.userID)
+		assert.ErrorIs(t, err, job.ErrJobNotFound)
+	})
+}
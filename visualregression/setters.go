@@ -0,0 +1,25 @@
+package visualregression
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetImage replaces the baseline image with a newly approved capture.
+func SetImage(imagePath, url string, fileSize int64, width, height int, sourceJobID *uuid.UUID, approvedBy uuid.UUID) UpdateSetter {
+	return func(b *Baseline) error {
+		if imagePath == "" {
+			return ErrInvalidImagePath
+		}
+		b.ImagePath = imagePath
+		b.URL = url
+		b.FileSize = fileSize
+		b.Width = width
+		b.Height = height
+		b.SourceJobID = sourceJobID
+		b.ApprovedBy = approvedBy
+		b.ApprovedAt = time.Now()
+		return nil
+	}
+}
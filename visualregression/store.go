@@ -0,0 +1,32 @@
+package visualregression
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store defines the interface for baseline persistence operations.
+type Store interface {
+	// Create creates a new baseline in the store.
+	Create(ctx context.Context, baseline *Baseline) error
+
+	// GetByID retrieves a baseline by its ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*Baseline, error)
+
+	// GetByPage retrieves the baseline of a page of an endpoint in a project.
+	GetByPage(ctx context.Context, projectID, endpointID uuid.UUID, pageName string) (*Baseline, error)
+
+	// Update updates a baseline with the given setters.
+	Update(ctx context.Context, id uuid.UUID, setters ...UpdateSetter) error
+
+	// Delete deletes a baseline by its ID.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListByProject retrieves the baselines of a project, optionally limited to
+	// one endpoint when endpointID is not uuid.Nil.
+	ListByProject(ctx context.Context, projectID, endpointID uuid.UUID) ([]*Baseline, error)
+}
+
+// UpdateSetter is a function that updates a baseline field.
+type UpdateSetter func(*Baseline) error
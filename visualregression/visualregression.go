@@ -0,0 +1,186 @@
+package visualregression
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+)
+
+// Keys under which visual regression data is stored in a job result.
+const (
+	ResultKeyPages  = "pages"
+	ResultKeyPassed = "passed"
+)
+
+const (
+	// DefaultThreshold is the fraction of pixels allowed to differ from the
+	// baseline before a page is reported as changed.
+	DefaultThreshold = 0.001
+
+	// DefaultViewportWidth and DefaultViewportHeight are the browser viewport
+	// used for captures when the job does not configure one.
+	DefaultViewportWidth  = 1280
+	DefaultViewportHeight = 800
+
+	// MaxPages is the maximum number of pages a single job may capture.
+	MaxPages = 50
+)
+
+var (
+	// ErrInvalidConfig is returned when a visual_regression job config is malformed.
+	ErrInvalidConfig = errors.New("invalid visual_regression config")
+
+	// ErrNoPages is returned when a job config lists no pages to capture.
+	ErrNoPages = errors.New("at least one page is required in config for visual_regression jobs")
+
+	// ErrTooManyPages is returned when a job config lists more than MaxPages pages.
+	ErrTooManyPages = fmt.Errorf("visual_regression jobs may capture at most %d pages", MaxPages)
+
+	// ErrInvalidPageName is returned when a page name is not a valid slug.
+	ErrInvalidPageName = errors.New("page name must start with a letter or digit and contain only letters, digits, '-', '_' or '.'")
+
+	// ErrDuplicatePageName is returned when two pages in a config share a name.
+	ErrDuplicatePageName = errors.New("page names must be unique")
+
+	// ErrInvalidThreshold is returned when the threshold is outside [0, 1].
+	ErrInvalidThreshold = errors.New("threshold must be between 0 and 1")
+
+	// ErrJobNotApprovable is returned when baselines are approved from a job that
+	// is not a successful visual_regression job.
+	ErrJobNotApprovable = errors.New("only successful visual_regression jobs can be approved")
+
+	// ErrPageNotFound is returned when a page is not part of a job result.
+	ErrPageNotFound = errors.New("page not found in job result")
+
+	// ErrNoCapture is returned when approving a page that has no screenshot.
+	ErrNoCapture = errors.New("page has no captured screenshot")
+)
+
+var pageNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,99}$`)
+
+// Page is a page of the endpoint to capture. Path is appended to the endpoint
+// URL; Name identifies the page's baseline and must be unique within a job.
+type Page struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Viewport is the browser viewport size used for captures.
+type Viewport struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Config is the configuration of a visual_regression job.
+type Config struct {
+	EndpointID uuid.UUID `json:"endpoint_id"`
+	ProjectID  uuid.UUID `json:"project_id"`
+	Pages      []Page    `json:"pages"`
+	Threshold  float64   `json:"threshold"`
+	Viewport   Viewport  `json:"viewport"`
+	FullPage   bool      `json:"full_page"`
+}
+
+// ParseConfig decodes and validates a visual_regression job config, filling in
+// defaults for the threshold and viewport.
+func ParseConfig(raw job.JSONMap) (*Config, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	cfg := Config{Threshold: -1}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	if len(cfg.Pages) == 0 {
+		return nil, ErrNoPages
+	}
+	if len(cfg.Pages) > MaxPages {
+		return nil, ErrTooManyPages
+	}
+	seen := make(map[string]bool, len(cfg.Pages))
+	for _, page := range cfg.Pages {
+		if !pageNamePattern.MatchString(page.Name) {
+			return nil, ErrInvalidPageName
+		}
+		if seen[page.Name] {
+			return nil, ErrDuplicatePageName
+		}
+		seen[page.Name] = true
+	}
+
+	if cfg.Threshold < 0 {
+		if _, ok := raw["threshold"]; ok {
+			return nil, ErrInvalidThreshold
+		}
+		cfg.Threshold = DefaultThreshold
+	}
+	if cfg.Threshold > 1 {
+		return nil, ErrInvalidThreshold
+	}
+	if cfg.Viewport.Width <= 0 {
+		cfg.Viewport.Width = DefaultViewportWidth
+	}
+	if cfg.Viewport.Height <= 0 {
+		cfg.Viewport.Height = DefaultViewportHeight
+	}
+
+	return &cfg, nil
+}
+
+// PageStatus is the outcome of comparing a page against its baseline.
+type PageStatus string
+
+const (
+	// PageStatusPassed means the page matches its baseline within the threshold.
+	PageStatusPassed PageStatus = "passed"
+
+	// PageStatusChanged means the page differs from its baseline.
+	PageStatusChanged PageStatus = "changed"
+
+	// PageStatusNew means the page has no baseline yet.
+	PageStatusNew PageStatus = "new"
+
+	// PageStatusError means the page could not be captured or compared.
+	PageStatusError PageStatus = "error"
+)
+
+// PageResult is the outcome for a single page of a visual_regression job.
+// Image paths are blob storage paths.
+type PageResult struct {
+	Name         string     `json:"name"`
+	URL          string     `json:"url"`
+	Status       PageStatus `json:"status"`
+	DiffRatio    float64    `json:"diff_ratio"`
+	DiffPixels   int        `json:"diff_pixels"`
+	ActualPath   string     `json:"actual_path,omitempty"`
+	DiffPath     string     `json:"diff_path,omitempty"`
+	BaselineID   *uuid.UUID `json:"baseline_id,omitempty"`
+	BaselinePath string     `json:"baseline_path,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// PagesFromResult decodes the page results stored in a job result.
+func PagesFromResult(result job.JSONMap) ([]PageResult, error) {
+	raw, ok := result[ResultKeyPages]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode page results: %w", err)
+	}
+
+	var pages []PageResult
+	if err := json.Unmarshal(data, &pages); err != nil {
+		return nil, fmt.Errorf("failed to decode page results: %w", err)
+	}
+	return pages, nil
+}
@@ -0,0 +1,119 @@
+package visualregression
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hairizuanbinnoorazman/ui-automation/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() job.JSONMap {
+	return job.JSONMap{
+		"endpoint_id": uuid.New().String(),
+		"project_id":  uuid.New().String(),
+		"pages": []interface{}{
+			map[string]interface{}{"name": "home", "path": "/"},
+			map[string]interface{}{"name": "login", "path": "/login"},
+		},
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Run("applies defaults", func(t *testing.T) {
+		cfg, err := ParseConfig(validConfig())
+		require.NoError(t, err)
+
+		assert.Len(t, cfg.Pages, 2)
+		assert.Equal(t, DefaultThreshold, cfg.Threshold)
+		assert.Equal(t, Viewport{Width: DefaultViewportWidth, Height: DefaultViewportHeight}, cfg.Viewport)
+		assert.False(t, cfg.FullPage)
+	})
+
+	t.Run("keeps explicit settings", func(t *testing.T) {
+		raw := validConfig()
+		raw["threshold"] = 0.0
+		raw["viewport"] = map[string]interface{}{"width": 375, "height": 667}
+		raw["full_page"] = true
+
+		cfg, err := ParseConfig(raw)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0.0, cfg.Threshold)
+		assert.Equal(t, Viewport{Width: 375, Height: 667}, cfg.Viewport)
+		assert.True(t, cfg.FullPage)
+	})
+
+	tests := []struct {
+		name    string
+		mutate  func(job.JSONMap)
+		wantErr error
+	}{
+		{
+			name:    "no pages",
+			mutate:  func(raw job.JSONMap) { raw["pages"] = []interface{}{} },
+			wantErr: ErrNoPages,
+		},
+		{
+			name: "too many pages",
+			mutate: func(raw job.JSONMap) {
+				pages := make([]interface{}, MaxPages+1)
+				for i := range pages {
+					pages[i] = map[string]interface{}{"name": fmt.Sprintf("page-%d", i)}
+				}
+				raw["pages"] = pages
+			},
+			wantErr: ErrTooManyPages,
+		},
+		{
+			name: "invalid page name",
+			mutate: func(raw job.JSONMap) {
+				raw["pages"] = []interface{}{map[string]interface{}{"name": "../etc/passwd"}}
+			},
+			wantErr: ErrInvalidPageName,
+		},
+		{
+			name: "duplicate page name",
+			mutate: func(raw job.JSONMap) {
+				raw["pages"] = []interface{}{
+					map[string]interface{}{"name": "home", "path": "/"},
+					map[string]interface{}{"name": "home", "path": "/index"},
+				}
+			},
+			wantErr: ErrDuplicatePageName,
+		},
+		{
+			name:    "negative threshold",
+			mutate:  func(raw job.JSONMap) { raw["threshold"] = -0.1 },
+			wantErr: ErrInvalidThreshold,
+		},
+		{
+			name:    "threshold above one",
+			mutate:  func(raw job.JSONMap) { raw["threshold"] = 1.5 },
+			wantErr: ErrInvalidThreshold,
+		},
+		{
+			name:    "malformed pages",
+			mutate:  func(raw job.JSONMap) { raw["pages"] = "home" },
+			wantErr: ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := validConfig()
+			tt.mutate(raw)
+
+			_, err := ParseConfig(raw)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	assert.Equal(t, "https://example.com/app", PageURL("https://example.com/app", ""))
+	assert.Equal(t, "https://example.com/login", PageURL("https://example.com/", "/login"))
+	assert.Equal(t, "https://example.com/app/settings", PageURL("https://example.com/app", "settings"))
+}